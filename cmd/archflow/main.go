@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"insightify/internal/runner"
+	workerruntime "insightify/internal/workerruntime"
+)
+
+func main() {
+	exportGraph := flag.String("export-graph", "", "export the phase dependency graph (dot or json)")
+	validate := flag.Bool("validate", false, "check the phase registry for missing dependencies and cycles")
+	printSalt := flag.Bool("print-salt", false, "print the cache model salt for the configured models and prompt versions")
+	planOnly := flag.Bool("plan-only", false, "print which phases of --phase would hit cache or recompute in --out, then exit")
+	dryRun := flag.Bool("dry-run", false, "like --plan-only, but run the cheap phases without LLM calls and write "+runner.DryRunReportName+" to --out")
+	phase := flag.String("phase", "", "phase to plan with --plan-only or --dry-run")
+	repo := flag.String("repo", ".", "repository to plan against with --plan-only or --dry-run")
+	outDir := flag.String("out", ".", "output directory")
+	flag.Parse()
+
+	if strings.TrimSpace(*exportGraph) == "" && !*validate && !*printSalt && !*planOnly && !*dryRun {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *planOnly || *dryRun {
+		if err := printPlan(*repo, *phase, *outDir, *dryRun); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if *printSalt {
+		salt, err := workerruntime.ModelSaltFromEnv()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(salt)
+		if strings.TrimSpace(*exportGraph) == "" && !*validate {
+			return
+		}
+	}
+
+	resolver := runner.BuildAllRegistries(nil)
+	if *validate {
+		if err := runner.ValidateResolver(resolver); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if strings.TrimSpace(*exportGraph) == "" {
+			fmt.Println("registry ok")
+			return
+		}
+	}
+
+	format := strings.ToLower(strings.TrimSpace(*exportGraph))
+	out, err := runner.ExportGraph(resolver, format)
+	if err != nil && !errors.Is(err, runner.ErrPhaseCycle) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	path := filepath.Join(*outDir, "phase_graph."+format)
+	if werr := os.WriteFile(path, out, 0o644); werr != nil {
+		fmt.Fprintln(os.Stderr, werr)
+		os.Exit(1)
+	}
+	fmt.Println(path)
+	if err != nil {
+		// The graph is still written so the cycle can be inspected.
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// printPlan reports, for phase and its dependencies, whether the artifacts in
+// outDir would be reused or recomputed and the estimated token cost. Without
+// dryRun no phase runs; with it the phases marked DryRunExecute run and the
+// report is also written to outDir.
+func printPlan(repo, phase, outDir string, dryRun bool) error {
+	phase = strings.TrimSpace(phase)
+	if phase == "" {
+		return errors.New("--plan-only and --dry-run require --phase")
+	}
+	root, err := filepath.Abs(repo)
+	if err != nil {
+		return err
+	}
+	project, err := workerruntime.NewProjectRuntime(filepath.Base(root), "archflow", workerruntime.RepoEntry{Name: filepath.Base(root), LocalPath: root})
+	if err != nil {
+		return err
+	}
+	defer project.Cleanup()
+
+	rt := project.NewExecutionRuntime(workerruntime.ExecutionOptions{OutDir: outDir})
+	plan := runner.PlanWorker
+	if dryRun {
+		plan = runner.DryRunWorker
+	}
+	report, err := plan(context.Background(), rt, phase, nil)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PHASE\tACTION\tEST. TOKENS\tNOTE")
+	for _, p := range report.Phases {
+		action, tokens := "recompute", fmt.Sprint(p.EstimatedTokens)
+		switch {
+		case p.CacheHit:
+			action, tokens = "cache hit", "-"
+		case p.Executed:
+			action, tokens = "executed", "-"
+		case p.Error != "":
+			tokens = "?" // upstream output missing, so the input is unknown
+		}
+		note := p.Error
+		if p.ExceedsCapacity {
+			note = strings.TrimSpace("exceeds token capacity " + note)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Key, action, tokens, note)
+	}
+	fmt.Fprintf(w, "total\t\t%d\t\n", report.TotalTokens)
+	return w.Flush()
+}
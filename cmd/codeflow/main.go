@@ -0,0 +1,333 @@
+// Command codeflow runs a codebase pipeline worker and the workers it
+// requires against a local repository, without the gateway.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
+	llmmiddleware "insightify/internal/llm/middleware"
+	llmmodel "insightify/internal/llm/model"
+	"insightify/internal/runner"
+	workerruntime "insightify/internal/workerruntime"
+)
+
+// Exit codes; scripts tell a usable cache-only run from a run that did work.
+const (
+	exitOK         = 0
+	exitFailed     = 1
+	exitValidation = 2
+	exitLLM        = 3
+	exitCacheOnly  = 4
+)
+
+const (
+	phaseOK      = "ok"
+	phaseCached  = "cached"
+	phaseFailed  = "failed"
+	phaseSkipped = "skipped"
+)
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// Summary is the --json report of a run.
+type Summary struct {
+	Worker     string                        `json:"worker"`
+	Until      string                        `json:"until,omitempty"`
+	Repo       string                        `json:"repo"`
+	OutDir     string                        `json:"out_dir"`
+	Status     string                        `json:"status"`
+	ExitCode   int                           `json:"exit_code"`
+	Error      string                        `json:"error,omitempty"`
+	DurationMs int64                         `json:"duration_ms"`
+	Phases     []PhaseSummary                `json:"phases"`
+	LLM        llmmiddleware.RunUsageSummary `json:"llm"`
+}
+
+// PhaseSummary reports one worker of the chain.
+type PhaseSummary struct {
+	Key          string `json:"key"`
+	Status       string `json:"status"`
+	DurationMs   int64  `json:"duration_ms"`
+	CacheHit     bool   `json:"cache_hit"`
+	Artifact     string `json:"artifact,omitempty"`
+	LLMCalls     int    `json:"llm_calls"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+	Error        string `json:"error,omitempty"`
+}
+
+type options struct {
+	repo     string
+	outDir   string
+	worker   string
+	until    string
+	parallel int
+	force    bool
+	json     bool
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("codeflow", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var opts options
+	fs.StringVar(&opts.repo, "repo", ".", "repository to analyze")
+	fs.StringVar(&opts.outDir, "out", "out", "artifact directory; outputs cached here are reused")
+	fs.StringVar(&opts.worker, "worker", "", "worker to run, together with the workers it requires")
+	fs.StringVar(&opts.until, "until", "", "stop after this worker of the --worker chain")
+	fs.IntVar(&opts.parallel, "parallel", 1, "run up to N independent workers at once")
+	fs.BoolVar(&opts.force, "force", false, "run the analysis workers even when repo_assessment finds too little code")
+	fs.BoolVar(&opts.json, "json", false, "print a machine-readable run summary to stdout")
+	if err := fs.Parse(args); err != nil {
+		return exitValidation
+	}
+
+	sum, err := execute(ctx, opts)
+	if opts.json {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(sum); encErr != nil {
+			fmt.Fprintln(stderr, encErr)
+		}
+	} else {
+		printSummary(stdout, sum)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+	}
+	return sum.ExitCode
+}
+
+// execute runs the chain selected by opts. The summary is filled in even when
+// the run fails.
+func execute(ctx context.Context, opts options) (Summary, error) {
+	started := time.Now()
+	sum := Summary{
+		Worker: strings.TrimSpace(opts.worker),
+		Until:  strings.TrimSpace(opts.until),
+		Repo:   opts.repo,
+		OutDir: opts.outDir,
+		Phases: []PhaseSummary{},
+	}
+	fail := func(code int, err error) (Summary, error) {
+		sum.Status, sum.ExitCode, sum.Error = phaseFailed, code, err.Error()
+		sum.DurationMs = time.Since(started).Milliseconds()
+		return sum, err
+	}
+
+	if sum.Worker == "" {
+		return fail(exitValidation, errors.New("--worker is required"))
+	}
+	if opts.parallel < 1 {
+		return fail(exitValidation, errors.New("--parallel must be at least 1"))
+	}
+	root, err := filepath.Abs(opts.repo)
+	if err != nil {
+		return fail(exitValidation, err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return fail(exitValidation, fmt.Errorf("--repo %q is not a directory", opts.repo))
+	}
+	sum.Repo = root
+	// Workers look repositories up by name under the repos dir and scan
+	// them through the scan filesystem.
+	reposFS, err := safeio.NewSafeFS(filepath.Dir(root))
+	if err != nil {
+		return fail(exitValidation, err)
+	}
+	scan.SetReposDir(filepath.Dir(root))
+	scan.SetSafeFS(reposFS)
+
+	project, err := workerruntime.NewProjectRuntime(filepath.Base(root), "codeflow", workerruntime.RepoEntry{Name: filepath.Base(root), LocalPath: root})
+	if err != nil {
+		return fail(exitFailed, err)
+	}
+	defer project.Cleanup()
+	rt := project.NewExecutionRuntime(workerruntime.ExecutionOptions{OutDir: opts.outDir})
+
+	keys, err := plan(rt.GetResolver(), sum.Worker, sum.Until)
+	if err != nil {
+		return fail(exitValidation, err)
+	}
+
+	rec := newPhaseRecorder(keys, opts.outDir)
+	usage := llmmiddleware.NewRunUsage(0)
+	ctx = llmmiddleware.WithRunUsage(ctx, usage)
+	// Codebase workers do not pick a model tier; LLM_MODEL_OVERRIDES still
+	// takes precedence per phase.
+	ctx = llmmodel.WithModelSelection(ctx, llmmodel.ModelRoleWorker, llmmodel.ModelLevelMiddle, "", "")
+	ctx = runner.WithParallelism(ctx, opts.parallel)
+	ctx = runner.WithPhaseHooks(ctx, rec.hooks())
+	var params map[string]string
+	if opts.force {
+		params = map[string]string{runner.RunParamForce: "true"}
+	}
+	_, runErr := runner.ExecutePlan(ctx, rt, keys, params)
+
+	sum.LLM = usage.Summary()
+	sum.Phases = rec.summaries(sum.LLM.Phases)
+	sum.DurationMs = time.Since(started).Milliseconds()
+	var skipErr *runner.RepoSkippedError
+	if errors.As(runErr, &skipErr) {
+		// A repository without enough code is an outcome, not a failure;
+		// repo_assessment.json in --out says why.
+		sum.Status, sum.Error = phaseSkipped, skipErr.Error()
+		return sum, nil
+	}
+	if runErr != nil {
+		code := exitFailed
+		if isLLMFailure(runErr, sum.Phases, sum.LLM.Phases) {
+			code = exitLLM
+		}
+		return fail(code, runErr)
+	}
+	sum.Status = phaseOK
+	if rec.cacheOnly() {
+		sum.Status, sum.ExitCode = phaseCached, exitCacheOnly
+	}
+	return sum, nil
+}
+
+// plan returns worker and its requires chain, dependencies first, cut after
+// until when set.
+func plan(resolver runner.SpecResolver, worker, until string) ([]string, error) {
+	if _, ok := resolver.Get(worker); !ok {
+		return nil, fmt.Errorf("unknown worker: %s", worker)
+	}
+	keys := runner.UpstreamOrder(resolver, worker)
+	if until == "" {
+		return keys, nil
+	}
+	if !slices.Contains(keys, until) {
+		return nil, fmt.Errorf("--until %s is not in the chain of %s: %s", until, worker, strings.Join(keys, ", "))
+	}
+	// until's own chain is a prefix of the work; phases of worker it does
+	// not require are left out.
+	return runner.UpstreamOrder(resolver, until), nil
+}
+
+// isLLMFailure reports whether the run failed on the LLM: a budget or retry
+// error, or a failed phase whose LLM calls errored.
+func isLLMFailure(err error, phases []PhaseSummary, usage []llmmiddleware.PhaseUsage) bool {
+	if errors.Is(err, llmmiddleware.ErrBudgetExceeded) || errors.Is(err, llmmiddleware.ErrRetryBudgetExhausted) || errors.Is(err, llmmiddleware.ErrCircuitOpen) {
+		return true
+	}
+	for _, p := range phases {
+		if p.Status != phaseFailed {
+			continue
+		}
+		for _, u := range usage {
+			if u.Phase == p.Key && u.Errors > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// phaseRecorder collects phase outcomes from runner.PhaseHooks; phases may
+// end concurrently under --parallel.
+type phaseRecorder struct {
+	mu      sync.Mutex
+	outDir  string
+	order   []string
+	started map[string]time.Time
+	ended   map[string]PhaseSummary
+}
+
+func newPhaseRecorder(keys []string, outDir string) *phaseRecorder {
+	return &phaseRecorder{
+		outDir:  outDir,
+		order:   keys,
+		started: map[string]time.Time{},
+		ended:   map[string]PhaseSummary{},
+	}
+}
+
+func (r *phaseRecorder) hooks() runner.PhaseHooks {
+	return runner.PhaseHooks{
+		OnStart: func(_ context.Context, spec runner.WorkerSpec, _ runner.Runtime) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.started[spec.Key] = time.Now()
+		},
+		OnEnd: func(_ context.Context, spec runner.WorkerSpec, _ runner.Runtime, _ runner.WorkerOutput, err error, cached bool) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			p := PhaseSummary{Key: spec.Key, Status: phaseOK, CacheHit: cached}
+			if t, ok := r.started[spec.Key]; ok {
+				p.DurationMs = time.Since(t).Milliseconds()
+			}
+			switch {
+			case errors.Is(err, runner.ErrRepoSkipped):
+				p.Status, p.Error = phaseSkipped, err.Error()
+			case err != nil:
+				p.Status, p.Error = phaseFailed, err.Error()
+			case cached:
+				p.Status = phaseCached
+			}
+			if err == nil {
+				p.Artifact = filepath.Join(r.outDir, spec.Key+".json")
+			}
+			r.ended[spec.Key] = p
+		},
+	}
+}
+
+// summaries lists every planned phase in plan order with its LLM usage;
+// phases that never ended are skipped.
+func (r *phaseRecorder) summaries(usage []llmmiddleware.PhaseUsage) []PhaseSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]PhaseSummary, 0, len(r.order))
+	for _, key := range r.order {
+		p, ok := r.ended[key]
+		if !ok {
+			p = PhaseSummary{Key: key, Status: phaseSkipped}
+		}
+		for _, u := range usage {
+			if u.Phase == key {
+				p.LLMCalls, p.InputTokens, p.OutputTokens = u.Calls, u.InputTokens, u.OutputTokens
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// cacheOnly reports whether every phase was loaded from cache.
+func (r *phaseRecorder) cacheOnly() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.order {
+		if p, ok := r.ended[key]; !ok || !p.CacheHit {
+			return false
+		}
+	}
+	return true
+}
+
+func printSummary(w io.Writer, sum Summary) {
+	for _, p := range sum.Phases {
+		line := fmt.Sprintf("%-20s %-8s %6dms", p.Key, p.Status, p.DurationMs)
+		if p.LLMCalls > 0 {
+			line += fmt.Sprintf("  llm calls=%d tokens=%d/%d", p.LLMCalls, p.InputTokens, p.OutputTokens)
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "%s in %dms (llm calls=%d, $%.4f)\n", sum.Status, sum.DurationMs, sum.LLM.Calls, sum.LLM.CostUSD)
+}
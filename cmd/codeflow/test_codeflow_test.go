@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	llmmiddleware "insightify/internal/llm/middleware"
+	"insightify/internal/runner"
+)
+
+// fixtureRepo writes a tiny Go module and points the fake LLM at every phase.
+func fixtureRepo(t *testing.T) (repo, outDir string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("LLM_MODEL_OVERRIDES", `{"*":{"provider":"fake","model":"fake-middle"}}`)
+	repo = filepath.Join(dir, "fixture")
+	if err := os.MkdirAll(filepath.Join(repo, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod":     "module fixture\n",
+		"main.go":    "package main\n\nimport \"fixture/pkg\"\n\nfunc main() { pkg.Hello() }\n",
+		"pkg/pkg.go": "package pkg\n\nfunc Hello() {}\n",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo, filepath.Join(dir, "out")
+}
+
+func runJSON(t *testing.T, args ...string) (Summary, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append(args, "--json"), &stdout, &stderr)
+	var sum Summary
+	if err := json.Unmarshal(stdout.Bytes(), &sum); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s\nstderr: %s", err, stdout.String(), stderr.String())
+	}
+	if sum.ExitCode != code {
+		t.Fatalf("summary exit_code = %d, process exit = %d", sum.ExitCode, code)
+	}
+	return sum, code
+}
+
+func TestCodeflowRunsWorkerWithFakeLLM(t *testing.T) {
+	repo, outDir := fixtureRepo(t)
+
+	sum, code := runJSON(t, "--repo", repo, "--out", outDir, "--worker", "code_roots")
+	if code != exitOK || sum.Status != phaseOK {
+		t.Fatalf("exit = %d, status = %q, error = %q; want ok", code, sum.Status, sum.Error)
+	}
+	if len(sum.Phases) != 1 {
+		t.Fatalf("phases = %+v, want only code_roots", sum.Phases)
+	}
+	p := sum.Phases[0]
+	if p.Key != "code_roots" || p.Status != phaseOK || p.CacheHit || p.LLMCalls == 0 || p.InputTokens == 0 {
+		t.Fatalf("phase = %+v, want code_roots run with LLM usage", p)
+	}
+	if _, err := os.Stat(p.Artifact); err != nil {
+		t.Fatalf("artifact %q: %v", p.Artifact, err)
+	}
+	if sum.LLM.Calls != p.LLMCalls {
+		t.Fatalf("llm calls = %d, phase calls = %d", sum.LLM.Calls, p.LLMCalls)
+	}
+}
+
+func TestCodeflowReportsFailedAndSkippedPhases(t *testing.T) {
+	repo, outDir := fixtureRepo(t)
+
+	// The fake LLM has no answer for code_specs, so its validation fails.
+	sum, code := runJSON(t, "--repo", repo, "--out", outDir, "--worker", "code_imports", "--parallel", "2")
+	if code != exitFailed || sum.Status != phaseFailed || sum.Error == "" {
+		t.Fatalf("exit = %d, status = %q, error = %q; want failure", code, sum.Status, sum.Error)
+	}
+	want := map[string]string{"code_roots": phaseOK, "code_stats": phaseOK, "code_specs": phaseFailed, "code_imports": phaseSkipped}
+	if len(sum.Phases) != len(want) {
+		t.Fatalf("phases = %+v, want %v", sum.Phases, want)
+	}
+	for _, p := range sum.Phases {
+		if p.Status != want[p.Key] {
+			t.Fatalf("phase %s status = %q, want %q", p.Key, p.Status, want[p.Key])
+		}
+	}
+}
+
+func TestCodeflowValidationErrors(t *testing.T) {
+	repo, outDir := fixtureRepo(t)
+	cases := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing worker", args: []string{"--repo", repo, "--out", outDir}},
+		{name: "unknown worker", args: []string{"--repo", repo, "--out", outDir, "--worker", "no_such_worker"}},
+		{name: "until outside chain", args: []string{"--repo", repo, "--out", outDir, "--worker", "code_specs", "--until", "code_graph"}},
+		{name: "missing repo", args: []string{"--repo", filepath.Join(repo, "missing"), "--out", outDir, "--worker", "code_roots"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sum, code := runJSON(t, tc.args...)
+			if code != exitValidation || sum.Error == "" {
+				t.Fatalf("exit = %d, error = %q; want validation failure", code, sum.Error)
+			}
+		})
+	}
+}
+
+func TestPhaseRecorderCacheOnly(t *testing.T) {
+	rec := newPhaseRecorder([]string{"a", "b"}, "out")
+	hooks := rec.hooks()
+	end := func(key string, cached bool) {
+		spec := runner.WorkerSpec{Key: key}
+		hooks.OnStart(context.Background(), spec, nil)
+		hooks.OnEnd(context.Background(), spec, nil, runner.WorkerOutput{}, nil, cached)
+	}
+	end("a", true)
+	if rec.cacheOnly() {
+		t.Fatalf("cacheOnly() = true with b not run")
+	}
+	end("b", true)
+	if !rec.cacheOnly() {
+		t.Fatalf("cacheOnly() = false with every phase cached")
+	}
+	if got := rec.summaries(nil); got[1].Status != phaseCached || got[1].Artifact != filepath.Join("out", "b.json") {
+		t.Fatalf("summaries = %+v", got)
+	}
+}
+
+func TestIsLLMFailure(t *testing.T) {
+	failed := []PhaseSummary{{Key: "a", Status: phaseFailed}}
+	cases := []struct {
+		name  string
+		err   error
+		usage int
+		want  bool
+	}{
+		{name: "worker error", err: errors.New("bad input"), want: false},
+		{name: "llm call errors", err: errors.New("bad output"), usage: 2, want: true},
+		{name: "cost budget", err: fmt.Errorf("phase a: %w", llmmiddleware.ErrBudgetExceeded), want: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage := []llmmiddleware.PhaseUsage{{Phase: "a", Errors: tc.usage}}
+			if got := isLLMFailure(tc.err, failed, usage); got != tc.want {
+				t.Fatalf("isLLMFailure() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Command eval runs phases against golden fixture repositories and scores
+// their outputs with the assertions of each repository's spec.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"insightify/internal/eval"
+)
+
+// Exit codes; CI fails the build on exitBelowThreshold.
+const (
+	exitOK             = 0
+	exitBelowThreshold = 1
+	exitValidation     = 2
+	exitFailed         = 3
+)
+
+// fakeModels routes every phase to the deterministic fake provider.
+const fakeModels = `{"*":{"provider":"fake","model":"fake-middle"}}`
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		specDir   string
+		opts      eval.Options
+		threshold float64
+		fake      bool
+		asJSON    bool
+	)
+	fs.StringVar(&specDir, "specs", "internal/eval/testdata/specs", "directory of *.json repository specs")
+	fs.StringVar(&opts.Phase, "phase", "", "only run the specs of this phase")
+	fs.StringVar(&opts.OutDir, "out", "out/eval", "artifact directory; one subdirectory per spec, reused as a cache")
+	fs.IntVar(&opts.Parallel, "parallel", 1, "run up to N independent phases of a spec at once")
+	fs.Float64Var(&threshold, "threshold", 1, "minimum pass rate, 0-1")
+	fs.BoolVar(&fake, "fake", false, "answer every phase with the fake LLM (deterministic smoke runs)")
+	fs.BoolVar(&asJSON, "json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return exitValidation
+	}
+	if threshold < 0 || threshold > 1 {
+		fmt.Fprintln(stderr, "--threshold must be between 0 and 1")
+		return exitValidation
+	}
+	if fake {
+		os.Setenv("LLM_MODEL_OVERRIDES", fakeModels)
+	}
+
+	specs, err := eval.LoadSpecs(specDir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitValidation
+	}
+	rep, err := eval.Run(ctx, specs, opts)
+	if len(rep.Specs) == 0 {
+		fmt.Fprintln(stderr, err)
+		return exitValidation
+	}
+	if asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(rep); encErr != nil {
+			fmt.Fprintln(stderr, encErr)
+		}
+	} else {
+		printReport(stdout, rep)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailed
+	}
+	if rep.PassRate < threshold {
+		fmt.Fprintf(stderr, "pass rate %.2f is below the threshold %.2f\n", rep.PassRate, threshold)
+		return exitBelowThreshold
+	}
+	return exitOK
+}
+
+func printReport(w io.Writer, rep eval.Report) {
+	for _, s := range rep.Specs {
+		fmt.Fprintf(w, "%s (%s) %dms llm calls=%d tokens=%d/%d\n", s.Name, s.Phase, s.DurationMs, s.LLM.Calls, s.LLM.InputTokens, s.LLM.OutputTokens)
+		if s.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", s.Error)
+		}
+		for _, a := range s.Assertions {
+			status := "PASS"
+			if !a.Passed {
+				status = "FAIL"
+			}
+			fmt.Fprintf(w, "  %s %s\n", status, a.Name)
+			if a.Detail != "" {
+				fmt.Fprintf(w, "       %s\n", a.Detail)
+			}
+		}
+	}
+	fmt.Fprintf(w, "%d/%d passed (%.0f%%) in %dms, $%.4f\n", rep.Passed, rep.Total, rep.PassRate*100, rep.DurationMs, rep.CostUSD)
+}
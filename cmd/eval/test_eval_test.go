@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEvalExitsBelowThreshold(t *testing.T) {
+	repo, err := filepath.Abs(filepath.Join("..", "..", "internal", "eval", "testdata", "repos", "gomod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("LLM_MODEL_OVERRIDES", "")
+	spec := `{"repo": "` + repo + `", "phase": "code_roots", "assertions": [
+		{"path": "main_source_roots", "op": "contains", "value": "internal"},
+		{"path": "main_source_roots", "op": "contains", "value": "web"}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, "gomod.json"), []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		threshold string
+		want      int
+	}{
+		{threshold: "0.5", want: exitOK},
+		{threshold: "0.9", want: exitBelowThreshold},
+		{threshold: "2", want: exitValidation},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), []string{"--fake", "--specs", dir, "--out", filepath.Join(dir, "out"), "--threshold", tc.threshold}, &stdout, &stderr)
+		if code != tc.want {
+			t.Fatalf("threshold %s: exit = %d, want %d\nstdout: %s\nstderr: %s", tc.threshold, code, tc.want, stdout.String(), stderr.String())
+		}
+		if code != exitValidation && !strings.Contains(stdout.String(), "1/2 passed") {
+			t.Fatalf("threshold %s: report = %s", tc.threshold, stdout.String())
+		}
+	}
+}
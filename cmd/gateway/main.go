@@ -7,14 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"os/signal"
+	"strings"
 	"syscall"
-	"time"
 
 	"insightify/internal/gateway/app"
 )
 
 func main() {
-	slog.SetDefault(slog.New(slog.NewJSONHandler(newLogWriter(), &slog.HandlerOptions{})))
+	slog.SetDefault(slog.New(newLogHandler()))
 
 	a, err := app.New()
 	if err != nil {
@@ -34,7 +34,7 @@ func main() {
 
 	slog.Info("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), a.ShutdownTimeout())
 	defer cancel()
 
 	if err := a.Shutdown(ctx); err != nil {
@@ -45,11 +45,19 @@ func main() {
 	slog.Info("Server exiting")
 }
 
-func newLogWriter() io.Writer {
+// newLogHandler logs as text, or as JSON lines when LOG_FORMAT=json.
+func newLogHandler() slog.Handler {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("LOG_FORMAT")), "json") {
+		return slog.NewJSONHandler(newLogWriter("core.jsonl"), nil)
+	}
+	return slog.NewTextHandler(newLogWriter("core.log"), nil)
+}
+
+func newLogWriter(name string) io.Writer {
 	if err := os.MkdirAll("logs", 0o755); err != nil {
 		return os.Stdout
 	}
-	logPath := filepath.Join("logs", "core.jsonl")
+	logPath := filepath.Join("logs", name)
 	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
 		return os.Stdout
@@ -42,6 +42,9 @@ type ArchDesignContradiction struct {
 type ArchDesignOut struct {
 	ArchitectureHypothesis ArchDesignHypothesis      `json:"architecture_hypothesis" prompt_type:"ArchitectureHypothesis" prompt_desc:"What the system does and how it is structured, including external nodes/services."`
 	Contradictions         []ArchDesignContradiction `json:"contradictions" prompt_type:"[]Contradiction" prompt_desc:"Claims with supporting and conflicting evidence."`
+	// FlaggedFiles records the file index entries flagged binary, generated
+	// or vendored, for auditing; the model is asked to skip them.
+	FlaggedFiles []FileIndexEntry `json:"flagged_files,omitempty"`
 }
 
 // ArchDesignIn bundles inputs for the ArchDesign milestone to align with M1's single-arg Run.
@@ -51,4 +54,6 @@ type ArchDesignIn struct {
 	FileIndex    []FileIndexEntry `json:"file_index"`
 	MDDocs       []MDDoc          `json:"md_docs"`
 	Hints        *ArchDesignHints         `json:"hints,omitempty"`
+	// MDDocTokens caps each condensed markdown doc; zero uses the worker default.
+	MDDocTokens int `json:"md_doc_tokens,omitempty"`
 }
@@ -0,0 +1,29 @@
+package artifact
+
+// Rename pairs a name that disappeared between two outputs with the name
+// that replaced it.
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ArchDiffIn pairs the arch_design output of this run with the one it
+// replaced; Base is nil when there was none.
+type ArchDiffIn struct {
+	Base *ArchDesignOut `json:"base,omitempty"`
+	Head ArchDesignOut  `json:"head"`
+}
+
+// ArchDiffOut reports how the architecture hypothesis moved since Base.
+// Modified holds "<component>.<field>" for components and hypothesis paths
+// such as "architecture_hypothesis.purpose" for the rest. Head is the
+// arch_design output compared.
+type ArchDiffOut struct {
+	HasBase  bool          `json:"has_base"`
+	Summary  []string      `json:"summary"`
+	Added    []string      `json:"added"`
+	Removed  []string      `json:"removed"`
+	Renamed  []Rename      `json:"renamed"`
+	Modified []string      `json:"modified"`
+	Head     ArchDesignOut `json:"head"`
+}
@@ -12,6 +12,10 @@ type EvidenceRef struct {
 type OpenedFile struct {
 	Path    string `json:"path"`
 	Content string `json:"content"`
+	// Truncated marks content cut at a sample byte cap.
+	Truncated bool `json:"truncated,omitempty"`
+	// Package names the workspace package the file belongs to, if any.
+	Package string `json:"package,omitempty"`
 }
 
 // FocusQuestion represents a single confirmation target.
@@ -20,19 +24,32 @@ type FocusQuestion struct {
 	Question string `json:"question"`       // natural language question
 }
 
-// FileIndexEntry is a minimal index row for search hints.
+// FileIndexEntry is a minimal index row for search hints. The Is* flags
+// mark files that rarely deserve reading (see fileclass); LineCount is set
+// for text files.
 type FileIndexEntry struct {
-	Path     string `json:"path"`
-	Size     int64  `json:"size,omitempty"`
-	Language string `json:"language,omitempty"`
-	Kind     string `json:"kind,omitempty"` // code|config|doc|test|asset|other
-	Ext      string `json:"ext,omitempty"`
+	Path        string `json:"path"`
+	Size        int64  `json:"size,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Kind        string `json:"kind,omitempty"` // code|config|doc|test|asset|other
+	Ext         string `json:"ext,omitempty"`
+	IsBinary    bool   `json:"is_binary,omitempty"`
+	IsGenerated bool   `json:"is_generated,omitempty"`
+	IsVendored  bool   `json:"is_vendored,omitempty"`
+	LineCount   int    `json:"line_count,omitempty"`
 }
 
-// MDDoc holds extracted markdown text (images omitted).
+// Flagged reports whether e is binary, generated or vendored.
+func (e FileIndexEntry) Flagged() bool {
+	return e.IsBinary || e.IsGenerated || e.IsVendored
+}
+
+// MDDoc holds extracted markdown text (images omitted). Truncated marks text
+// that was cut to fit a token cap, beyond the usual condensing.
 type MDDoc struct {
-	Path string `json:"path"`
-	Text string `json:"text"`
+	Path      string `json:"path"`
+	Text      string `json:"text"`
+	Truncated bool   `json:"truncated,omitempty"`
 }
 
 type ExtCount struct {
@@ -63,11 +80,20 @@ type IdentifierScope struct {
 }
 
 type IdentifierRequirement struct {
-	Path       string `json:"path"`             // target file path
-	Identifier string `json:"identifier"`       // required identifier name
-	Origin     string `json:"origin,omitempty"` // user|library|runtime|vendor|stdlib|framework
+	Path       string `json:"path"`                 // target file path
+	Identifier string `json:"identifier"`           // required identifier name
+	Origin     string `json:"origin,omitempty"`     // user|library|runtime|vendor|stdlib|framework
+	Resolution string `json:"resolution,omitempty"` // one of the Resolved*/StillUnresolved values, user origins only
 }
 
+// Requirement resolution states set by the code_symbols resolution pass.
+const (
+	ResolvedLocally    = "resolved_locally"     // definition found in the same chunk
+	ResolvedCrossChunk = "resolved_cross_chunk" // unique definition found in another chunk
+	ResolvedViaLLM     = "resolved_via_llm"     // ambiguous; picked by a targeted LLM call
+	StillUnresolved    = "still_unresolved"
+)
+
 // ToolSpec documents a tool's contract (name + schemas).
 type ToolSpec struct {
 	Name         string          `json:"name"`
@@ -2,22 +2,48 @@ package artifact
 
 import "strings"
 
+// ConversationTurn is one message of the bootstrap conversation.
+type ConversationTurn struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
 // BootstrapContext is persisted by the bootstrap worker and consumed by downstream workers.
 type BootstrapContext struct {
-	Purpose   string `json:"purpose,omitempty"`
-	RepoURL   string `json:"repo_url,omitempty"`
-	UserInput string `json:"user_input,omitempty"`
+	Purpose      string             `json:"purpose,omitempty"`
+	RepoURL      string             `json:"repo_url,omitempty"`
+	UserInput    string             `json:"user_input,omitempty"`
+	Conversation []ConversationTurn `json:"conversation,omitempty"`
+	// Session identifies the chat the conversation belongs to; see
+	// runner.RunParamSession.
+	Session string `json:"session,omitempty"`
 }
 
 func (c BootstrapContext) Normalize() BootstrapContext {
 	return BootstrapContext{
-		Purpose:   strings.TrimSpace(c.Purpose),
-		RepoURL:   strings.TrimSpace(c.RepoURL),
-		UserInput: strings.TrimSpace(c.UserInput),
+		Purpose:      strings.TrimSpace(c.Purpose),
+		RepoURL:      strings.TrimSpace(c.RepoURL),
+		UserInput:    strings.TrimSpace(c.UserInput),
+		Conversation: NormalizeConversation(c.Conversation),
+		Session:      strings.TrimSpace(c.Session),
 	}
 }
 
 func (c BootstrapContext) IsEmpty() bool {
 	n := c.Normalize()
-	return n.Purpose == "" && n.RepoURL == "" && n.UserInput == ""
+	return n.Purpose == "" && n.RepoURL == "" && n.UserInput == "" && len(n.Conversation) == 0
+}
+
+// NormalizeConversation trims turns and drops empty ones.
+func NormalizeConversation(turns []ConversationTurn) []ConversationTurn {
+	var out []ConversationTurn
+	for _, t := range turns {
+		role := strings.ToLower(strings.TrimSpace(t.Role))
+		content := strings.TrimSpace(t.Content)
+		if role == "" || content == "" {
+			continue
+		}
+		out = append(out, ConversationTurn{Role: role, Content: content})
+	}
+	return out
 }
@@ -4,20 +4,58 @@ package artifact
 type CodeGraphIn struct {
 	Repo         string         `json:"repo"`
 	Dependencies []Dependencies `json:"dependencies"`
+	Pruning      GraphPruning   `json:"pruning,omitempty"`
+	// Package restricts the graph to files of one of Packages, by name or
+	// path; empty keeps the whole repo.
+	Package  string             `json:"package,omitempty"`
+	Packages []WorkspacePackage `json:"packages,omitempty"`
+}
+
+// Edge-pruning strategies accepted by GraphPruning.Strategy.
+const (
+	PruneKeepStronger = "keep_stronger" // keep the heavier direction of a bidirectional pair (default)
+	PruneKeepBoth     = "keep_both"     // keep both directions; cycles are reported
+	PruneDropBoth     = "drop_both"     // drop both directions of a bidirectional pair
+	PruneMinWeight    = "min_weight"    // drop every edge lighter than MinWeight
+)
+
+// GraphPruning configures how CodeGraph reduces edges before cycle detection.
+type GraphPruning struct {
+	Strategy  string `json:"strategy,omitempty"`
+	MinWeight int    `json:"min_weight,omitempty"`
 }
 
 // CodeGraphOut represents a dependency graph with fully materialized node metadata.
+// Cycles lists strongly connected components that survived pruning.
 type CodeGraphOut struct {
-	Repo  string          `json:"repo"`
-	Graph DependencyGraph `json:"graph"`
+	Repo   string          `json:"repo"`
+	Graph  DependencyGraph `json:"graph"`
+	Cycles []CycleReport   `json:"cycles,omitempty"`
 }
 
 type DependencyGraph struct {
 	Nodes     []DependencyNode `json:"nodes"`
 	Adjacency [][]int          `json:"adjacency"`
+	// Edges repeats Adjacency with import-hit weights, sorted by (From, To).
+	// Artifacts written before weights were recorded omit it.
+	Edges []WeightedEdge `json:"edges,omitempty"`
 }
 
 type DependencyNode struct {
 	ID   int     `json:"id"`
 	File FileRef `json:"file"`
 }
+
+// WeightedEdge is a directed edge annotated with the number of import hits behind it.
+type WeightedEdge struct {
+	From   int `json:"from"`
+	To     int `json:"to"`
+	Weight int `json:"weight"`
+}
+
+// CycleReport describes one strongly connected component of the dependency graph.
+type CycleReport struct {
+	Members      []int          `json:"members"`
+	Edges        []WeightedEdge `json:"edges"`
+	SuggestedCut WeightedEdge   `json:"suggested_cut"`
+}
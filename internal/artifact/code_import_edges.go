@@ -0,0 +1,27 @@
+package artifact
+
+import "insightify/internal/common/safeio"
+
+// CodeImportEdgesIn verifies candidate import ranges against extractor specs.
+// A range with EndLine <= 0 extends to the end of the file.
+type CodeImportEdgesIn struct {
+	Repo     string                 `json:"repo"`
+	RepoFS   *safeio.SafeFS         `json:"-"`
+	Families []FamilySpec           `json:"families"`
+	Ranges   []ImportStatementRange `json:"ranges"`
+}
+
+// CodeImportEdgesOut lists deduplicated import edges with provenance.
+type CodeImportEdgesOut struct {
+	Repo  string       `json:"repo"`
+	Edges []ImportEdge `json:"edges"`
+}
+
+// ImportEdge is one module referenced by a file. Target is the repo-relative
+// file the module resolved to, empty for external modules.
+type ImportEdge struct {
+	From   string     `json:"from"`
+	Target string     `json:"target,omitempty"`
+	Module ModuleInfo `json:"module"`
+	Lines  []int      `json:"lines"`
+}
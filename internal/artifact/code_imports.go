@@ -5,11 +5,17 @@ type CodeImportsIn struct {
 	Repo     string       `json:"repo"`
 	Roots    CodeRootsOut `json:"roots"`
 	Families []FamilySpec `json:"families"`
+	// Package restricts the scan to one of Roots.Packages, by name or path;
+	// empty scans the whole repo.
+	Package string `json:"package,omitempty"`
 }
 
 // CodeImportsOut is a minimal dependency graph.
 type CodeImportsOut struct {
 	PossibleDependencies []Dependencies `json:"possible_dependencies,omitempty"`
+	// Warnings lists the configured roots that were skipped because they do
+	// not exist or cannot be read.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type Dependencies struct {
@@ -0,0 +1,22 @@
+package artifact
+
+// CodeMermaidIn renders a code_graph output as a Mermaid diagram. Packages
+// from code_roots, when present, become the diagram's layers.
+type CodeMermaidIn struct {
+	Graph    CodeGraphOut       `json:"graph"`
+	Packages []WorkspacePackage `json:"packages,omitempty"`
+	// MaxNodes caps the rendered files; zero uses the worker default.
+	MaxNodes int `json:"max_nodes,omitempty"`
+}
+
+// CodeMermaidOut is a Mermaid flowchart of the dependency graph: one
+// subgraph per layer (workspace package or top-level directory) and one
+// node class per file extension.
+type CodeMermaidOut struct {
+	Diagram string `json:"diagram"`
+	Nodes   int    `json:"nodes"`
+	Edges   int    `json:"edges"`
+	Layers  int    `json:"layers"`
+	// Omitted counts files dropped by MaxNodes, least connected first.
+	Omitted int `json:"omitted,omitempty"`
+}
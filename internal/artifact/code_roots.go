@@ -1,11 +1,16 @@
 package artifact
 
+import "strings"
+
 // CodeRoots summarizes the repository surface for a lightweight
 // directory classification pass.
 type CodeRootsIn struct {
 	Repo      string         `json:"repo"`
 	ExtCounts map[string]int `json:"ext_counts"`
 	Dirs      []string       `json:"dirs_depth1"` // repo-relative folders encountered during the scan
+	// Packages are the workspace packages found in the repo's monorepo
+	// manifests (see codebase.DetectWorkspacePackages).
+	Packages []WorkspacePackage `json:"packages,omitempty"`
 }
 
 // CodeRoots identifies likely roots for main code, libraries, and configs.
@@ -20,6 +25,28 @@ type CodeRootsOut struct {
 	BuildRoots         []string        `json:"build_roots,omitempty" prompt_desc:"Build or packaging directories."`
 	Notes              []string        `json:"notes,omitempty" prompt_desc:"Short rationale or uncertainty notes."`
 	RuntimeConfigs     []RuntimeConfig `json:"runtime_configs,omitempty" prompt_desc:"Runtime config files with {path, ext}."`
+	// Packages is copied from the detection pass, not asked of the LLM.
+	Packages []WorkspacePackage `json:"packages,omitempty" prompt:"-"`
+}
+
+// WorkspacePackage is one package of a monorepo workspace (pnpm, yarn/npm
+// workspaces, lerna, go.work, Cargo).
+type WorkspacePackage struct {
+	Name     string `json:"name"`               // declared package/module/crate name, else the directory name
+	Path     string `json:"path"`               // repo-relative directory, e.g. "packages/ui"
+	Language string `json:"language,omitempty"` // hint from the manifest: go, rust, typescript, javascript
+	Manifest string `json:"manifest"`           // repo-relative package manifest, e.g. "packages/ui/package.json"
+}
+
+// FindPackage returns the package of pkgs whose name or path is sel.
+func FindPackage(pkgs []WorkspacePackage, sel string) (WorkspacePackage, bool) {
+	sel = strings.Trim(strings.TrimSpace(sel), "/")
+	for _, p := range pkgs {
+		if p.Name == sel || p.Path == sel {
+			return p, true
+		}
+	}
+	return WorkspacePackage{}, false
 }
 
 type RuntimeConfig struct {
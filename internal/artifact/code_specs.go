@@ -4,6 +4,9 @@ type CodeSpecsIn struct {
 	Repo      string     `json:"repo"`
 	ExtCounts []ExtCount `json:"ext_counts"`
 	Roots     CodeRootsOut      `json:"roots"`
+	// ExtReport is the code_stats report, when available; ExtCounts is
+	// taken from it when empty.
+	ExtReport *CodeStatsOut `json:"ext_report,omitempty"`
 }
 
 type CodeSpecsOut struct {
@@ -43,6 +46,28 @@ type AliasPair struct {
 	Normalized string `json:"normalized"`
 }
 
+// Module string kinds produced by module normalization.
+const (
+	ModuleKindPath         = "path"          // relative/absolute file reference, e.g. "./utils/a.ts"
+	ModuleKindPackage      = "package"       // registry package, e.g. "@org/pkg/utils/a"
+	ModuleKindSystemHeader = "system_header" // angle-bracket include, e.g. "<stdio.h>"
+	ModuleKindModule       = "module"        // dotted or host-qualified module, e.g. "os.path"
+)
+
+// ModuleInfo is the structured form of a raw import/include string.
+type ModuleInfo struct {
+	Raw        string `json:"raw"`
+	Normalized string `json:"normalized"`
+	Kind       string `json:"kind"`
+	FolderPath string `json:"folder_path,omitempty"`
+	FileName   string `json:"file_name,omitempty"`
+	FileExt    string `json:"file_ext,omitempty"`
+	Scope      string `json:"scope,omitempty"`
+	Package    string `json:"package,omitempty"`
+	Subpath    string `json:"subpath,omitempty"`
+	Alias      string `json:"alias,omitempty"` // alias prefix that was matched, if any
+}
+
 type FamilySpec struct {
 	Family string        `json:"family"`
 	Key    string        `json:"key"`
@@ -0,0 +1,49 @@
+package artifact
+
+import "insightify/internal/common/safeio"
+
+// CodeStatsIn scans a repository for the extension report code_specs reads.
+// Roots steer sampling toward main sources and away from library roots.
+type CodeStatsIn struct {
+	Repo   string         `json:"repo"`
+	RepoFS *safeio.SafeFS `json:"-"`
+	Roots  CodeRootsOut   `json:"roots"`
+}
+
+// CodeStatsOut is the extension report: one entry per file extension,
+// most frequent first.
+type CodeStatsOut struct {
+	Exts []ExtStats `json:"exts"`
+	// Warnings lists the main source and library roots that were ignored
+	// because they do not exist or cannot be read.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ExtStats describes the files of one extension. Samples, Head and Lines come
+// from files that are not generated, preferring main source roots.
+type ExtStats struct {
+	Ext       string     `json:"ext"` // e.g. ".go"
+	Count     int        `json:"count"`
+	Bytes     int64      `json:"bytes"`
+	Generated int        `json:"generated,omitempty"` // generated files, counted but never sampled
+	TopDirs   []DirCount `json:"top_dirs,omitempty"`
+	Samples   []string   `json:"samples,omitempty"`
+	HeadPath  string     `json:"head_path,omitempty"` // largest non-generated file
+	Head      string     `json:"head,omitempty"`
+	Lines     []string   `json:"lines,omitempty"` // representative code lines
+}
+
+// DirCount counts files under a top-level directory; "." is the repo root.
+type DirCount struct {
+	Dir   string `json:"dir"`
+	Count int    `json:"count"`
+}
+
+// ExtCounts returns the per-extension file counts of the report.
+func (o CodeStatsOut) ExtCounts() []ExtCount {
+	out := make([]ExtCount, 0, len(o.Exts))
+	for _, e := range o.Exts {
+		out = append(out, ExtCount{Ext: e.Ext, Count: e.Count})
+	}
+	return out
+}
@@ -6,12 +6,31 @@ import (
 
 // CodeSymbolsIn drives identifier extraction tasks via the scheduler.
 type CodeSymbolsIn struct {
-	Repo   string         `json:"repo"`
-	RepoFS *safeio.SafeFS `json:"-"`
-	Tasks  CodeTasksOut          `json:"tasks"`
+	Repo       string           `json:"repo"`
+	RepoFS     *safeio.SafeFS   `json:"-"`
+	Tasks      CodeTasksOut     `json:"tasks"`
+	Resolution SymbolResolution `json:"resolution"`
+}
+
+// SymbolResolution configures the pass that resolves requirements pointing
+// at identifiers defined in other chunks. It is part of the input so the
+// fingerprint changes with it.
+type SymbolResolution struct {
+	Version      int  `json:"version"`
+	SkipLLM      bool `json:"skip_llm,omitempty"`
+	LLMBatchSize int  `json:"llm_batch_size"`
 }
 
 type CodeSymbolsOut struct {
-	Repo  string             `json:"repo"`
-	Files []IdentifierReport `json:"files"`
+	Repo       string             `json:"repo"`
+	Files      []IdentifierReport `json:"files"`
+	Resolution ResolutionStats    `json:"resolution"`
+}
+
+// ResolutionStats counts user requirements by IdentifierRequirement.Resolution.
+type ResolutionStats struct {
+	ResolvedLocally    int `json:"resolved_locally"`
+	ResolvedCrossChunk int `json:"resolved_cross_chunk"`
+	ResolvedViaLLM     int `json:"resolved_via_llm"`
+	StillUnresolved    int `json:"still_unresolved"`
 }
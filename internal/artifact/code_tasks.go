@@ -8,6 +8,10 @@ type CodeTasksIn struct {
 	RepoFS      *safeio.SafeFS  `json:"-"`
 	Graph       DependencyGraph `json:"graph"`
 	CapPerChunk int             `json:"cap_per_chunk"`
+	// Cycles carries the code_graph cycle report. Each component is scheduled
+	// as a single unit unless FailOnCycle is set.
+	Cycles      []CycleReport `json:"cycles,omitempty"`
+	FailOnCycle bool          `json:"fail_on_cycle,omitempty"`
 }
 
 // CodeTasksOut encodes nodes with weights and adjacency for scheduler input.
@@ -16,6 +20,7 @@ type CodeTasksOut struct {
 	CapPerChunk int      `json:"cap_per_chunk"`
 	Nodes       []CodeTasksNode `json:"nodes"`
 	Adjacency   [][]int  `json:"adjacency"`
+	Cycles      []CycleReport `json:"cycles,omitempty"`
 }
 
 type CodeTasksNode struct {
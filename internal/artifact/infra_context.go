@@ -9,6 +9,16 @@ type InfraContextIn struct {
 	ConfigSamples       []OpenedFile        `json:"config_samples"`
 	IdentifierSummaries []IdentifierSummary `json:"identifier_summaries"`
 	ConfidenceThreshold float64             `json:"confidence_threshold"`
+	// RelatedRepos carries identifier summaries of the other repositories of
+	// a multi-repo project, so cross-repo calls can be connected.
+	RelatedRepos []RepoIdentifierSummaries `json:"related_repos,omitempty"`
+}
+
+// RepoIdentifierSummaries groups identifier summaries by repository. Paths
+// are prefixed "<repo>:" to keep them distinct from the analyzed repository.
+type RepoIdentifierSummaries struct {
+	Repo        string              `json:"repo"`
+	Identifiers []IdentifierSummary `json:"identifiers"`
 }
 
 // IdentifierSummary captures high-signal identifiers (from C4) that touch external deps.
@@ -6,6 +6,9 @@ type InfraRefineIn struct {
 	Previous InfraContextOut        `json:"previous"`
 	Files    []OpenedFile `json:"files"`
 	Notes    []string     `json:"notes,omitempty"`
+	// Ledger is the question ledger with the infra_context gaps merged in;
+	// only its open questions are put to the model.
+	Ledger QuestionLedger `json:"ledger"`
 }
 
 // InfraRefineOut includes the updated external overview plus a delta summary.
@@ -15,6 +18,8 @@ type InfraRefineOut struct {
 	NeedsInput       []string         `json:"needs_input"`
 	StopWhen         []string         `json:"stop_when"`
 	Notes            []string         `json:"notes"`
+	// QuestionStatus reports which ledger questions the evidence settled.
+	QuestionStatus []QuestionStatusUpdate `json:"question_status,omitempty"`
 }
 
 type InfraRefineDelta struct {
@@ -17,9 +17,44 @@ type PlanDependenciesIn struct {
 	InitPurpose string       `json:"init_purpose,omitempty"`
 	InitRepoURL string       `json:"init_repo_url,omitempty"`
 	Workers     []WorkerMeta `json:"workers"`
+	// Targets limits the plan to these workers and what they require; empty
+	// plans every worker.
+	Targets []string `json:"targets,omitempty"`
+	// StrictRequires reports Requires outside Targets instead of pulling
+	// them into the plan.
+	StrictRequires bool `json:"strict_requires,omitempty"`
 }
 
 type PlanDependenciesOut struct {
 	RuntimeState any                  `json:"artifact"`
 	ClientView   *workerv1.ClientView `json:"client_view"`
+	Diagnostics  []PlanDiagnostic     `json:"diagnostics,omitempty"`
+}
+
+// Plan diagnostic severities.
+const (
+	PlanSeverityError   = "error"
+	PlanSeverityWarning = "warning"
+)
+
+// Plan diagnostic codes.
+const (
+	PlanDiagUnknownTarget    = "unknown_target"
+	PlanDiagMissingProducer  = "missing_producer"
+	PlanDiagOutsideSelection = "outside_selection"
+	PlanDiagCycle            = "cycle"
+)
+
+// PlanDiagnostic explains why a plan is incomplete or cannot run.
+type PlanDiagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	// Worker is the planned worker the diagnostic is about, if any.
+	Worker  string `json:"worker,omitempty"`
+	Missing string `json:"missing,omitempty"`
+	Message string `json:"message"`
+	// Suggestions are the known workers closest to Missing.
+	Suggestions []string `json:"suggestions,omitempty"`
+	// Cycle lists the workers on a Requires cycle, e.g. [a b a].
+	Cycle []string `json:"cycle,omitempty"`
 }
@@ -0,0 +1,38 @@
+package artifact
+
+// Question statuses in a QuestionLedger.
+const (
+	QuestionOpen     = "open"
+	QuestionAnswered = "answered"
+	QuestionObsolete = "obsolete"
+)
+
+// QuestionLedger carries the evidence questions raised by infra_context and
+// their resolution by infra_refine across runs. It is stored as
+// questions.json next to the worker outputs.
+type QuestionLedger struct {
+	// Iteration counts the infra_refine runs merged into the ledger.
+	Iteration int              `json:"iteration"`
+	Questions []LedgerQuestion `json:"questions"`
+}
+
+// LedgerQuestion is one question with a stable ID derived from its path and
+// text, so the same gap raised again maps to the same entry.
+type LedgerQuestion struct {
+	ID       string `json:"id"`
+	Topic    string `json:"topic,omitempty"`
+	Path     string `json:"path,omitempty"` // first suggested lookup path
+	Question string `json:"question"`
+	Status   string `json:"status"` // open|answered|obsolete
+	// Evidence lists the files consulted when the question was closed.
+	Evidence        []string `json:"evidence,omitempty"`
+	ClosedBy        string   `json:"closed_by,omitempty"` // phase, e.g. infra_refine
+	ClosedIteration int      `json:"closed_iteration,omitempty"`
+}
+
+// QuestionStatusUpdate is infra_refine's verdict on one ledger question.
+type QuestionStatusUpdate struct {
+	ID       string   `json:"id"`
+	Status   string   `json:"status"`
+	Evidence []string `json:"evidence,omitempty"`
+}
@@ -0,0 +1,51 @@
+package artifact
+
+// File kinds of a repo assessment.
+const (
+	FileKindCode  = "code"
+	FileKindDocs  = "docs"
+	FileKindData  = "data"
+	FileKindOther = "other"
+)
+
+// RepoAssessmentIn judges from the code_stats report whether a repository
+// holds enough code to analyze. Zero thresholds use the worker defaults.
+type RepoAssessmentIn struct {
+	Stats        CodeStatsOut `json:"stats"`
+	MinCodeRatio float64      `json:"min_code_ratio,omitempty"`
+	MinCodeBytes int64        `json:"min_code_bytes,omitempty"`
+}
+
+// RepoAssessmentOut describes what a repository holds. Skip is set when the
+// share of code files is below MinCodeRatio or the code is smaller than
+// MinCodeBytes; Reasons say which.
+type RepoAssessmentOut struct {
+	Files      int     `json:"files"`
+	Bytes      int64   `json:"bytes"`
+	CodeFiles  int     `json:"code_files"`
+	CodeBytes  int64   `json:"code_bytes"`
+	CodeRatio  float64 `json:"code_ratio"`
+	DocsFiles  int     `json:"docs_files"`
+	DataFiles  int     `json:"data_files"`
+	OtherFiles int     `json:"other_files"`
+	// Exts is the extension histogram, most frequent first.
+	Exts []ExtKind `json:"exts"`
+	// LargestDirs are the top-level directories with the most files.
+	LargestDirs []DirCount `json:"largest_dirs,omitempty"`
+	// Docs and Data list the documentation and data extensions found.
+	Docs []string `json:"docs,omitempty"`
+	Data []string `json:"data,omitempty"`
+
+	MinCodeRatio float64  `json:"min_code_ratio"`
+	MinCodeBytes int64    `json:"min_code_bytes"`
+	Skip         bool     `json:"skip"`
+	Reasons      []string `json:"reasons,omitempty"`
+}
+
+// ExtKind counts the files of one extension and classifies them.
+type ExtKind struct {
+	Ext   string `json:"ext"`
+	Kind  string `json:"kind"` // FileKindCode, FileKindDocs, FileKindData or FileKindOther
+	Count int    `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
@@ -0,0 +1,176 @@
+package artifactdiff
+
+import (
+	"sort"
+	"strings"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/delta"
+)
+
+// ArchDiff compares two arch_design outputs.
+type ArchDiff struct {
+	// Components tracks key_components by name. Added and Removed hold
+	// component names; Modified fields are "<name>.<field>".
+	Components delta.Delta `json:"components"`
+	// Renamed pairs components that disappeared with the added ones they
+	// became, matched by normalized name, then by evidence paths; their
+	// names are not in Components.Added or Removed, and their changes are
+	// under the new name.
+	Renamed []artifact.Rename `json:"renamed"`
+	// Hypothesis holds changes to everything else in the output, with
+	// fields such as "architecture_hypothesis.purpose".
+	Hypothesis delta.Delta `json:"hypothesis"`
+}
+
+// migrateArchDesign decodes an arch_design artifact and normalizes it.
+func migrateArchDesign(raw []byte) (artifact.ArchDesignOut, error) {
+	var out artifact.ArchDesignOut
+	if err := decode(raw, &out); err != nil {
+		return artifact.ArchDesignOut{}, err
+	}
+	return normalizeArchDesign(out), nil
+}
+
+// normalizeArchDesign trims component names (later duplicates win) and turns
+// missing lists into empty ones, so older outputs that wrote null do not
+// show up as changes.
+func normalizeArchDesign(out artifact.ArchDesignOut) artifact.ArchDesignOut {
+	seen := make(map[string]int, len(out.ArchitectureHypothesis.KeyComponents))
+	comps := make([]artifact.ArchDesignKeyComponent, 0, len(out.ArchitectureHypothesis.KeyComponents))
+	for _, c := range out.ArchitectureHypothesis.KeyComponents {
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			continue
+		}
+		c.Evidence = orEmpty(c.Evidence)
+		if i, ok := seen[c.Name]; ok {
+			comps[i] = c
+			continue
+		}
+		seen[c.Name] = len(comps)
+		comps = append(comps, c)
+	}
+	h := &out.ArchitectureHypothesis
+	h.KeyComponents = comps
+	h.TechStack.Platforms = orEmpty(h.TechStack.Platforms)
+	h.TechStack.Languages = orEmpty(h.TechStack.Languages)
+	h.TechStack.BuildTools = orEmpty(h.TechStack.BuildTools)
+	h.Assumptions = orEmpty(h.Assumptions)
+	h.Unknowns = orEmpty(h.Unknowns)
+	out.Contradictions = orEmpty(out.Contradictions)
+	for i := range out.Contradictions {
+		out.Contradictions[i].Supports = orEmpty(out.Contradictions[i].Supports)
+		out.Contradictions[i].Conflicts = orEmpty(out.Contradictions[i].Conflicts)
+	}
+	return out
+}
+
+// DiffArchDesign compares two arch_design outputs as Compare does for
+// KeyArchDesign.
+func DiffArchDesign(before, after artifact.ArchDesignOut) ArchDiff {
+	return diffArchDesign(normalizeArchDesign(before), normalizeArchDesign(after))
+}
+
+func diffArchDesign(before, after artifact.ArchDesignOut) ArchDiff {
+	b := componentsByName(before.ArchitectureHypothesis.KeyComponents)
+	a := componentsByName(after.ArchitectureHypothesis.KeyComponents)
+
+	var d ArchDiff
+	d.Components.Added, d.Components.Removed = diffNames(nameSet(b), nameSet(a))
+	evidence := func(comps map[string]artifact.ArchDesignKeyComponent) func(string) []string {
+		return func(name string) []string {
+			var paths []string
+			for _, ev := range comps[name].Evidence {
+				paths = append(paths, ev.Path)
+			}
+			return paths
+		}
+	}
+	self := func(name string) string { return name }
+	d.Renamed = orEmpty(matchRenames(
+		renameSide{names: d.Components.Removed, key: self, paths: evidence(b)},
+		renameSide{names: d.Components.Added, key: self, paths: evidence(a)},
+		false,
+	))
+	d.Components.Added, d.Components.Removed = withoutRenamed(d.Components.Added, d.Components.Removed, d.Renamed)
+
+	pairs := map[string]string{}
+	for name := range b {
+		if _, ok := a[name]; ok {
+			pairs[name] = name
+		}
+	}
+	for _, r := range d.Renamed {
+		pairs[r.From] = r.To
+	}
+	for from, to := range pairs {
+		bc, ac := b[from], a[to]
+		bc.Name = ac.Name
+		for _, m := range delta.Diff(bc, ac, delta.Options{}).Modified {
+			m.Field = to + "." + m.Field
+			d.Components.Modified = append(d.Components.Modified, m)
+		}
+	}
+	delta.Normalize(&d.Components)
+	sortMods(d.Components.Modified)
+
+	before.ArchitectureHypothesis.KeyComponents = nil
+	after.ArchitectureHypothesis.KeyComponents = nil
+	d.Hypothesis = delta.Diff(before, after, delta.Options{})
+	sort.Strings(d.Hypothesis.Added)
+	sort.Strings(d.Hypothesis.Removed)
+	sortMods(d.Hypothesis.Modified)
+	return d
+}
+
+// Summary lists the changes of d one per line, as Result.Summary does.
+func (d ArchDiff) Summary() []string { return capSummary(d.summary()) }
+
+func (d ArchDiff) summary() []string {
+	var lines []string
+	for _, name := range d.Components.Added {
+		lines = append(lines, "component added: "+name)
+	}
+	for _, name := range d.Components.Removed {
+		lines = append(lines, "component removed: "+name)
+	}
+	for _, r := range d.Renamed {
+		lines = append(lines, "component renamed: "+r.From+" -> "+r.To)
+	}
+	for _, m := range d.Components.Modified {
+		lines = append(lines, "component changed: "+m.Field)
+	}
+	for _, m := range d.Hypothesis.Modified {
+		lines = append(lines, "changed: "+m.Field)
+	}
+	return lines
+}
+
+func componentsByName(comps []artifact.ArchDesignKeyComponent) map[string]artifact.ArchDesignKeyComponent {
+	out := make(map[string]artifact.ArchDesignKeyComponent, len(comps))
+	for _, c := range comps {
+		out[c.Name] = c
+	}
+	return out
+}
+
+func nameSet[V any](m map[string]V) map[string]struct{} {
+	out := make(map[string]struct{}, len(m))
+	for k := range m {
+		out[k] = struct{}{}
+	}
+	return out
+}
+
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// sortMods orders mods by field; delta.Diff walks maps, so its order varies.
+func sortMods(mods []delta.Mod) {
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Field < mods[j].Field })
+}
@@ -0,0 +1,147 @@
+// Package artifactdiff compares two stored worker outputs of the same key and
+// reports type-aware changes: architecture components for arch_design, nodes,
+// edges and weights for code_graph, and identifiers per file for
+// code_symbols. Both sides are migrated to the current artifact shape before
+// diffing, and every list in a Result is sorted so the output is stable.
+package artifactdiff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Keys with a type-aware differ.
+const (
+	KeyArchDesign  = "arch_design"
+	KeyCodeGraph   = "code_graph"
+	KeyCodeSymbols = "code_symbols"
+)
+
+// ErrUnsupportedKey is returned by Compare for keys without a differ.
+var ErrUnsupportedKey = errors.New("artifactdiff: unsupported artifact key")
+
+// DefaultWeightThreshold is used when Options.WeightThreshold is not positive.
+const DefaultWeightThreshold = 1
+
+// maxSummaryLines caps Result.Summary; the rest is folded into one line.
+const maxSummaryLines = 50
+
+type Options struct {
+	// WeightThreshold is the smallest absolute code_graph edge weight change
+	// that is reported.
+	WeightThreshold int
+}
+
+// Result is the diff of one artifact key. Exactly one of Arch, Graph and
+// Symbols is set, matching Key.
+type Result struct {
+	Key     string       `json:"key"`
+	Summary []string     `json:"summary"`
+	Arch    *ArchDiff    `json:"arch,omitempty"`
+	Graph   *GraphDiff   `json:"graph,omitempty"`
+	Symbols *SymbolsDiff `json:"symbols,omitempty"`
+}
+
+// Supported reports whether key has a type-aware differ.
+func Supported(key string) bool {
+	switch strings.TrimSpace(key) {
+	case KeyArchDesign, KeyCodeGraph, KeyCodeSymbols:
+		return true
+	}
+	return false
+}
+
+// Compare diffs two raw artifacts of key. An empty side is treated as an
+// artifact with no content, so everything on the other side is added or
+// removed.
+func Compare(key string, before, after []byte, opts Options) (Result, error) {
+	if opts.WeightThreshold <= 0 {
+		opts.WeightThreshold = DefaultWeightThreshold
+	}
+	key = strings.TrimSpace(key)
+	res := Result{Key: key}
+	var lines []string
+	switch key {
+	case KeyArchDesign:
+		b, err := migrateArchDesign(before)
+		if err != nil {
+			return Result{}, fmt.Errorf("artifactdiff: before: %w", err)
+		}
+		a, err := migrateArchDesign(after)
+		if err != nil {
+			return Result{}, fmt.Errorf("artifactdiff: after: %w", err)
+		}
+		d := diffArchDesign(b, a)
+		res.Arch = &d
+		lines = d.summary()
+	case KeyCodeGraph:
+		b, err := migrateCodeGraph(before)
+		if err != nil {
+			return Result{}, fmt.Errorf("artifactdiff: before: %w", err)
+		}
+		a, err := migrateCodeGraph(after)
+		if err != nil {
+			return Result{}, fmt.Errorf("artifactdiff: after: %w", err)
+		}
+		d := diffCodeGraph(b, a, opts.WeightThreshold)
+		res.Graph = &d
+		lines = d.summary()
+	case KeyCodeSymbols:
+		b, err := migrateCodeSymbols(before)
+		if err != nil {
+			return Result{}, fmt.Errorf("artifactdiff: before: %w", err)
+		}
+		a, err := migrateCodeSymbols(after)
+		if err != nil {
+			return Result{}, fmt.Errorf("artifactdiff: after: %w", err)
+		}
+		d := diffCodeSymbols(b, a)
+		res.Symbols = &d
+		lines = d.summary()
+	default:
+		return Result{}, fmt.Errorf("%w: %q", ErrUnsupportedKey, key)
+	}
+	res.Summary = capSummary(lines)
+	return res, nil
+}
+
+// decode unmarshals raw into v, leaving v untouched for empty input. Unknown
+// fields from newer or older artifact shapes are ignored.
+func decode(raw []byte, v any) error {
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func capSummary(lines []string) []string {
+	if len(lines) == 0 {
+		return []string{"no changes"}
+	}
+	if len(lines) > maxSummaryLines {
+		rest := len(lines) - maxSummaryLines
+		lines = append(lines[:maxSummaryLines:maxSummaryLines], fmt.Sprintf("... and %d more changes", rest))
+	}
+	return lines
+}
+
+// diffNames returns the sorted names present only in after and only in before.
+func diffNames(before, after map[string]struct{}) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
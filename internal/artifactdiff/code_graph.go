@@ -0,0 +1,215 @@
+package artifactdiff
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"insightify/internal/artifact"
+)
+
+// GraphDiff compares two code_graph outputs. Nodes are matched by file path
+// since node IDs are positions in the sorted path list and shift between runs.
+// A removed and an added file with the same base name and a shared neighbour
+// count as moved: they are in RenamedNodes only, and edges are compared
+// with the old path replaced by the new one.
+type GraphDiff struct {
+	AddedNodes    []string          `json:"added_nodes"`
+	RemovedNodes  []string          `json:"removed_nodes"`
+	RenamedNodes  []artifact.Rename `json:"renamed_nodes"`
+	AddedEdges    []Edge            `json:"added_edges"`
+	RemovedEdges  []Edge            `json:"removed_edges"`
+	WeightChanges []WeightChange    `json:"weight_changes"`
+}
+
+// Edge is a dependency edge between two file paths. Weight is 0 when the
+// artifact did not record it.
+type Edge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Weight int    `json:"weight,omitempty"`
+}
+
+type WeightChange struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+type edgeKey struct{ from, to string }
+
+type graphState struct {
+	nodes map[string]struct{}
+	edges map[edgeKey]int
+}
+
+// migrateCodeGraph decodes a code_graph artifact into path-keyed nodes and
+// edges. Artifacts without graph.edges are upgraded from the adjacency list,
+// taking weights from cycle reports where available and 0 otherwise.
+func migrateCodeGraph(raw []byte) (graphState, error) {
+	var out artifact.CodeGraphOut
+	if err := decode(raw, &out); err != nil {
+		return graphState{}, err
+	}
+	st := graphState{nodes: map[string]struct{}{}, edges: map[edgeKey]int{}}
+	pathByID := make(map[int]string, len(out.Graph.Nodes))
+	for _, n := range out.Graph.Nodes {
+		p := filepath.ToSlash(strings.TrimSpace(n.File.Path))
+		if p == "" {
+			continue
+		}
+		pathByID[n.ID] = p
+		st.nodes[p] = struct{}{}
+	}
+	addEdge := func(from, to, weight int) {
+		fp, ok1 := pathByID[from]
+		tp, ok2 := pathByID[to]
+		if !ok1 || !ok2 {
+			return
+		}
+		st.edges[edgeKey{fp, tp}] = weight
+	}
+
+	if len(out.Graph.Edges) > 0 {
+		for _, e := range out.Graph.Edges {
+			addEdge(e.From, e.To, e.Weight)
+		}
+		return st, nil
+	}
+	known := map[[2]int]int{}
+	for _, c := range out.Cycles {
+		for _, e := range c.Edges {
+			known[[2]int{e.From, e.To}] = e.Weight
+		}
+	}
+	for from, tos := range out.Graph.Adjacency {
+		for _, to := range tos {
+			addEdge(from, to, known[[2]int{from, to}])
+		}
+	}
+	return st, nil
+}
+
+// diffCodeGraph reports weight changes only when both sides know the weight
+// and it moved by at least threshold.
+func diffCodeGraph(before, after graphState, threshold int) GraphDiff {
+	d := GraphDiff{
+		AddedEdges:    []Edge{},
+		RemovedEdges:  []Edge{},
+		WeightChanges: []WeightChange{},
+	}
+	d.AddedNodes, d.RemovedNodes = diffNames(before.nodes, after.nodes)
+	d.RenamedNodes = orEmpty(matchRenames(
+		renameSide{names: d.RemovedNodes, key: path.Base, paths: before.neighbours},
+		renameSide{names: d.AddedNodes, key: path.Base, paths: after.neighbours},
+		true,
+	))
+	d.AddedNodes, d.RemovedNodes = withoutRenamed(d.AddedNodes, d.RemovedNodes, d.RenamedNodes)
+	before = before.renamed(d.RenamedNodes)
+	for k, aw := range after.edges {
+		bw, ok := before.edges[k]
+		if !ok {
+			d.AddedEdges = append(d.AddedEdges, Edge{From: k.from, To: k.to, Weight: aw})
+			continue
+		}
+		if bw > 0 && aw > 0 && abs(aw-bw) >= threshold {
+			d.WeightChanges = append(d.WeightChanges, WeightChange{From: k.from, To: k.to, Before: bw, After: aw})
+		}
+	}
+	for k, bw := range before.edges {
+		if _, ok := after.edges[k]; !ok {
+			d.RemovedEdges = append(d.RemovedEdges, Edge{From: k.from, To: k.to, Weight: bw})
+		}
+	}
+	sortEdges(d.AddedEdges)
+	sortEdges(d.RemovedEdges)
+	sort.Slice(d.WeightChanges, func(i, j int) bool {
+		a, b := d.WeightChanges[i], d.WeightChanges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return d
+}
+
+// neighbours lists the paths p has an edge to or from.
+func (st graphState) neighbours(p string) []string {
+	var out []string
+	for k := range st.edges {
+		switch p {
+		case k.from:
+			out = append(out, k.to)
+		case k.to:
+			out = append(out, k.from)
+		}
+	}
+	return out
+}
+
+// renamed returns st with the From path of every rename replaced by its To.
+func (st graphState) renamed(renames []artifact.Rename) graphState {
+	if len(renames) == 0 {
+		return st
+	}
+	to := make(map[string]string, len(renames))
+	for _, r := range renames {
+		to[r.From] = r.To
+	}
+	move := func(p string) string {
+		if np, ok := to[p]; ok {
+			return np
+		}
+		return p
+	}
+	out := graphState{nodes: make(map[string]struct{}, len(st.nodes)), edges: make(map[edgeKey]int, len(st.edges))}
+	for p := range st.nodes {
+		out.nodes[move(p)] = struct{}{}
+	}
+	for k, w := range st.edges {
+		out.edges[edgeKey{move(k.from), move(k.to)}] = w
+	}
+	return out
+}
+
+func (d GraphDiff) summary() []string {
+	var lines []string
+	for _, p := range d.AddedNodes {
+		lines = append(lines, "node added: "+p)
+	}
+	for _, p := range d.RemovedNodes {
+		lines = append(lines, "node removed: "+p)
+	}
+	for _, r := range d.RenamedNodes {
+		lines = append(lines, "node moved: "+r.From+" -> "+r.To)
+	}
+	for _, e := range d.AddedEdges {
+		lines = append(lines, fmt.Sprintf("edge added: %s -> %s", e.From, e.To))
+	}
+	for _, e := range d.RemovedEdges {
+		lines = append(lines, fmt.Sprintf("edge removed: %s -> %s", e.From, e.To))
+	}
+	for _, w := range d.WeightChanges {
+		lines = append(lines, fmt.Sprintf("edge weight: %s -> %s %d => %d", w.From, w.To, w.Before, w.After))
+	}
+	return lines
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,86 @@
+package artifactdiff
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"insightify/internal/artifact"
+)
+
+// SymbolsDiff compares two code_symbols outputs by identifier name per file.
+type SymbolsDiff struct {
+	AddedFiles   []string `json:"added_files"`
+	RemovedFiles []string `json:"removed_files"`
+	// Files lists every file whose identifiers changed, including added and
+	// removed files, sorted by path.
+	Files []FileSymbols `json:"files"`
+}
+
+type FileSymbols struct {
+	Path    string   `json:"path"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// migrateCodeSymbols decodes a code_symbols artifact into identifier names by
+// file path. Reports for the same path (e.g. from separate chunks) are merged.
+func migrateCodeSymbols(raw []byte) (map[string]map[string]struct{}, error) {
+	var out artifact.CodeSymbolsOut
+	if err := decode(raw, &out); err != nil {
+		return nil, err
+	}
+	files := make(map[string]map[string]struct{}, len(out.Files))
+	for _, f := range out.Files {
+		p := filepath.ToSlash(strings.TrimSpace(f.Path))
+		if p == "" {
+			continue
+		}
+		names := files[p]
+		if names == nil {
+			names = map[string]struct{}{}
+			files[p] = names
+		}
+		for _, id := range f.Identifiers {
+			if name := strings.TrimSpace(id.Name); name != "" {
+				names[name] = struct{}{}
+			}
+		}
+	}
+	return files, nil
+}
+
+func diffCodeSymbols(before, after map[string]map[string]struct{}) SymbolsDiff {
+	var d SymbolsDiff
+	d.AddedFiles, d.RemovedFiles = diffNames(nameSet(before), nameSet(after))
+	d.Files = []FileSymbols{}
+
+	paths := nameSet(before)
+	for p := range after {
+		paths[p] = struct{}{}
+	}
+	for p := range paths {
+		added, removed := diffNames(before[p], after[p])
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		d.Files = append(d.Files, FileSymbols{Path: p, Added: added, Removed: removed})
+	}
+	sort.Slice(d.Files, func(i, j int) bool { return d.Files[i].Path < d.Files[j].Path })
+	return d
+}
+
+func (d SymbolsDiff) summary() []string {
+	var lines []string
+	for _, p := range d.AddedFiles {
+		lines = append(lines, "file added: "+p)
+	}
+	for _, p := range d.RemovedFiles {
+		lines = append(lines, "file removed: "+p)
+	}
+	for _, f := range d.Files {
+		lines = append(lines, fmt.Sprintf("%s: +%d -%d identifiers", f.Path, len(f.Added), len(f.Removed)))
+	}
+	return lines
+}
@@ -0,0 +1,159 @@
+package artifactdiff
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"insightify/internal/artifact"
+)
+
+// minPathOverlap is the smallest overlapPaths score that pairs a removed and
+// an added name whose normalized names differ.
+const minPathOverlap = 0.5
+
+// normalizeName folds case and drops everything but letters and digits, so
+// "Auth Service", "auth-service" and "authService" compare equal.
+func normalizeName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// overlapPaths returns the share of the smaller of a and b that is also in
+// the other, or 0 when either is empty.
+func overlapPaths(a, b []string) float64 {
+	as, bs := pathSet(a), pathSet(b)
+	if len(as) == 0 || len(bs) == 0 {
+		return 0
+	}
+	if len(as) > len(bs) {
+		as, bs = bs, as
+	}
+	shared := 0
+	for p := range as {
+		if _, ok := bs[p]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(as))
+}
+
+func pathSet(paths []string) map[string]struct{} {
+	out := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		if p = strings.TrimSpace(p); p != "" {
+			out[p] = struct{}{}
+		}
+	}
+	return out
+}
+
+// renameSide describes the names on one side of a diff: key gives the text
+// compared by normalizeName and paths the paths compared by overlapPaths.
+type renameSide struct {
+	names []string
+	key   func(name string) string
+	paths func(name string) []string
+}
+
+// matchRenames pairs removed names with the added names they most likely
+// became: first those whose keys are equal after normalizeName, then the
+// pairs with the largest path overlap of at least minPathOverlap. strict
+// pairs only names with equal keys and a shared path, for keys as common as
+// file base names. Every name is paired at most once and ties go to the
+// smaller names, so the result is stable; it is sorted by From.
+func matchRenames(removed, added renameSide, strict bool) []artifact.Rename {
+	usedFrom, usedTo := map[string]bool{}, map[string]bool{}
+	var out []artifact.Rename
+	pair := func(from, to string) {
+		usedFrom[from], usedTo[to] = true, true
+		out = append(out, artifact.Rename{From: from, To: to})
+	}
+
+	byKey := map[string][]string{}
+	for _, to := range added.names {
+		if k := normalizeName(added.key(to)); k != "" {
+			byKey[k] = append(byKey[k], to)
+		}
+	}
+	for _, tos := range byKey {
+		sort.Strings(tos)
+	}
+	from := append([]string(nil), removed.names...)
+	sort.Strings(from)
+	for _, f := range from {
+		for _, to := range byKey[normalizeName(removed.key(f))] {
+			if usedTo[to] || (strict && overlapPaths(removed.paths(f), added.paths(to)) == 0) {
+				continue
+			}
+			pair(f, to)
+			break
+		}
+	}
+
+	if strict {
+		sort.Slice(out, func(i, j int) bool { return out[i].From < out[j].From })
+		return out
+	}
+
+	type scored struct {
+		from, to string
+		score    float64
+	}
+	var cands []scored
+	for _, f := range from {
+		if usedFrom[f] {
+			continue
+		}
+		for _, to := range added.names {
+			if usedTo[to] {
+				continue
+			}
+			if s := overlapPaths(removed.paths(f), added.paths(to)); s >= minPathOverlap {
+				cands = append(cands, scored{f, to, s})
+			}
+		}
+	}
+	sort.Slice(cands, func(i, j int) bool {
+		if cands[i].score != cands[j].score {
+			return cands[i].score > cands[j].score
+		}
+		if cands[i].from != cands[j].from {
+			return cands[i].from < cands[j].from
+		}
+		return cands[i].to < cands[j].to
+	})
+	for _, c := range cands {
+		if !usedFrom[c.from] && !usedTo[c.to] {
+			pair(c.from, c.to)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].From < out[j].From })
+	return out
+}
+
+// withoutRenamed drops the names renames consumed from added and removed.
+func withoutRenamed(added, removed []string, renames []artifact.Rename) ([]string, []string) {
+	if len(renames) == 0 {
+		return added, removed
+	}
+	from, to := map[string]bool{}, map[string]bool{}
+	for _, r := range renames {
+		from[r.From], to[r.To] = true, true
+	}
+	keep := func(names []string, drop map[string]bool) []string {
+		out := []string{}
+		for _, n := range names {
+			if !drop[n] {
+				out = append(out, n)
+			}
+		}
+		return out
+	}
+	return keep(added, to), keep(removed, from)
+}
@@ -0,0 +1,95 @@
+package artifactdiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return raw
+}
+
+func checkGolden(t *testing.T, name string, res Result) {
+	t.Helper()
+	got, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	got = append(got, '\n')
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return
+	}
+	want := readTestdata(t, name)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s mismatch (run with -update to accept)\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestCompareGolden(t *testing.T) {
+	for _, key := range []string{KeyArchDesign, KeyCodeGraph, KeyCodeSymbols} {
+		t.Run(key, func(t *testing.T) {
+			before := readTestdata(t, key+"_before.json")
+			after := readTestdata(t, key+"_after.json")
+
+			res, err := Compare(key, before, after, Options{WeightThreshold: 2})
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			checkGolden(t, key+".golden.json", res)
+
+			// Repeated comparisons must be byte-identical despite map iteration.
+			for i := 0; i < 5; i++ {
+				again, _ := Compare(key, before, after, Options{WeightThreshold: 2})
+				a, _ := json.Marshal(res)
+				b, _ := json.Marshal(again)
+				if !bytes.Equal(a, b) {
+					t.Fatalf("non-deterministic result:\n%s\n%s", a, b)
+				}
+			}
+
+			res, err = Compare(key, after, after, Options{})
+			if err != nil {
+				t.Fatalf("Compare(same) error = %v", err)
+			}
+			checkGolden(t, key+"_empty.golden.json", res)
+		})
+	}
+}
+
+func TestCompareCodeGraphThreshold(t *testing.T) {
+	before := readTestdata(t, "code_graph_before.json")
+	after := readTestdata(t, "code_graph_after.json")
+
+	res, err := Compare(KeyCodeGraph, before, after, Options{WeightThreshold: 4})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(res.Graph.WeightChanges) != 0 {
+		t.Fatalf("change of 3 should be below threshold 4: %+v", res.Graph.WeightChanges)
+	}
+}
+
+func TestCompareRejectsUnknownKey(t *testing.T) {
+	if _, err := Compare("code_roots", nil, nil, Options{}); !errors.Is(err, ErrUnsupportedKey) {
+		t.Fatalf("error = %v, want ErrUnsupportedKey", err)
+	}
+	if _, err := Compare(KeyCodeGraph, []byte("{"), nil, Options{}); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
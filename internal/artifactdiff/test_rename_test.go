@@ -0,0 +1,167 @@
+package artifactdiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+func component(name, kind string, paths ...string) artifact.ArchDesignKeyComponent {
+	c := artifact.ArchDesignKeyComponent{Name: name, Kind: kind, Responsibility: name + " duties"}
+	for _, p := range paths {
+		c.Evidence = append(c.Evidence, artifact.EvidenceRef{Path: p})
+	}
+	return c
+}
+
+func archOut(comps ...artifact.ArchDesignKeyComponent) artifact.ArchDesignOut {
+	var out artifact.ArchDesignOut
+	out.ArchitectureHypothesis.KeyComponents = comps
+	return out
+}
+
+func TestDiffArchDesignRenames(t *testing.T) {
+	before := archOut(
+		component("Auth Service", "service", "auth/server.go"),
+		component("Job Runner", "worker", "jobs/run.go", "jobs/queue.go"),
+		component("Billing", "subsystem", "billing/invoice.go", "billing/tax.go"),
+		component("Gateway", "service", "gateway/main.go"),
+	)
+	after := archOut(
+		component("auth-service", "service", "auth/server.go"),
+		component("Task Executor", "worker", "jobs/run.go", "jobs/queue.go", "jobs/retry.go"),
+		component("Gateway", "service", "gateway/main.go"),
+		component("Search", "service", "search/index.go"),
+	)
+	d := DiffArchDesign(before, after)
+
+	wantRenamed := []artifact.Rename{
+		{From: "Auth Service", To: "auth-service"},
+		{From: "Job Runner", To: "Task Executor"},
+	}
+	if !reflect.DeepEqual(d.Renamed, wantRenamed) {
+		t.Fatalf("Renamed = %+v, want %+v", d.Renamed, wantRenamed)
+	}
+	if !reflect.DeepEqual(d.Components.Added, []string{"Search"}) || !reflect.DeepEqual(d.Components.Removed, []string{"Billing"}) {
+		t.Fatalf("added %v, removed %v; want the new Search and the removed Billing subsystem", d.Components.Added, d.Components.Removed)
+	}
+	var fields []string
+	for _, m := range d.Components.Modified {
+		fields = append(fields, m.Field)
+	}
+	want := []string{"Task Executor.evidence", "Task Executor.responsibility", "auth-service.responsibility"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("modified = %v, want %v", fields, want)
+	}
+	wantSummary := []string{
+		"component added: Search",
+		"component removed: Billing",
+		"component renamed: Auth Service -> auth-service",
+		"component renamed: Job Runner -> Task Executor",
+		"component changed: Task Executor.evidence",
+		"component changed: Task Executor.responsibility",
+		"component changed: auth-service.responsibility",
+	}
+	if got := d.Summary(); !reflect.DeepEqual(got, wantSummary) {
+		t.Fatalf("Summary() = %v, want %v", got, wantSummary)
+	}
+}
+
+func TestDiffArchDesignNoRenameBelowOverlap(t *testing.T) {
+	before := archOut(component("Cache", "store", "cache/lru.go", "cache/ttl.go", "cache/shard.go"))
+	after := archOut(component("Store", "store", "cache/lru.go", "store/db.go", "store/tx.go"))
+	d := DiffArchDesign(before, after)
+	if len(d.Renamed) != 0 || len(d.Components.Added) != 1 || len(d.Components.Removed) != 1 {
+		t.Fatalf("diff = %+v, want an add and a remove below the overlap threshold", d)
+	}
+}
+
+func codeGraph(t *testing.T, paths []string, edges [][2]int) []byte {
+	t.Helper()
+	var out artifact.CodeGraphOut
+	for i, p := range paths {
+		n := artifact.DependencyNode{ID: i}
+		n.File.Path = p
+		out.Graph.Nodes = append(out.Graph.Nodes, n)
+	}
+	for _, e := range edges {
+		out.Graph.Edges = append(out.Graph.Edges, artifact.WeightedEdge{From: e[0], To: e[1], Weight: 1})
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestCompareCodeGraphMovesAndSubsystems(t *testing.T) {
+	before := codeGraph(t,
+		[]string{"cmd/main.go", "internal/util/strings.go", "billing/invoice.go", "billing/tax.go", "api/handler.go"},
+		[][2]int{{0, 1}, {0, 4}, {2, 3}, {4, 2}, {4, 1}},
+	)
+	after := codeGraph(t,
+		[]string{"cmd/main.go", "pkg/strings.go", "api/handler.go", "api/routes.go"},
+		[][2]int{{0, 1}, {0, 2}, {2, 1}, {0, 3}},
+	)
+	res, err := Compare(KeyCodeGraph, before, after, Options{})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	g := res.Graph
+	if !reflect.DeepEqual(g.RenamedNodes, []artifact.Rename{{From: "internal/util/strings.go", To: "pkg/strings.go"}}) {
+		t.Fatalf("RenamedNodes = %+v, want the moved strings.go", g.RenamedNodes)
+	}
+	if !reflect.DeepEqual(g.AddedNodes, []string{"api/routes.go"}) {
+		t.Fatalf("AddedNodes = %v", g.AddedNodes)
+	}
+	if !reflect.DeepEqual(g.RemovedNodes, []string{"billing/invoice.go", "billing/tax.go"}) {
+		t.Fatalf("RemovedNodes = %v, want the billing subsystem", g.RemovedNodes)
+	}
+	wantAdded := []Edge{{From: "cmd/main.go", To: "api/routes.go", Weight: 1}}
+	if !reflect.DeepEqual(g.AddedEdges, wantAdded) {
+		t.Fatalf("AddedEdges = %+v, want only the new route edge (moved edges are remapped)", g.AddedEdges)
+	}
+	wantRemoved := []Edge{
+		{From: "api/handler.go", To: "billing/invoice.go", Weight: 1},
+		{From: "billing/invoice.go", To: "billing/tax.go", Weight: 1},
+	}
+	if !reflect.DeepEqual(g.RemovedEdges, wantRemoved) {
+		t.Fatalf("RemovedEdges = %+v, want the billing edges", g.RemovedEdges)
+	}
+}
+
+func TestCompareCodeGraphSameBaseNameWithoutNeighbours(t *testing.T) {
+	before := codeGraph(t, []string{"a/index.ts", "a/app.ts"}, [][2]int{{1, 0}})
+	after := codeGraph(t, []string{"b/index.ts", "b/other.ts"}, [][2]int{{1, 0}})
+	res, err := Compare(KeyCodeGraph, before, after, Options{})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(res.Graph.RenamedNodes) != 0 {
+		t.Fatalf("RenamedNodes = %+v, want none without a shared neighbour", res.Graph.RenamedNodes)
+	}
+}
+
+func TestNormalizeNameAndOverlap(t *testing.T) {
+	for _, s := range []string{"Auth Service", "auth-service", "authService", "AUTH_SERVICE"} {
+		if got := normalizeName(s); got != "authservice" {
+			t.Fatalf("normalizeName(%q) = %q", s, got)
+		}
+	}
+	cases := []struct {
+		a, b []string
+		want float64
+	}{
+		{nil, []string{"x"}, 0},
+		{[]string{"x", "y"}, []string{"x", "y", "z"}, 1},
+		{[]string{"x", "y"}, []string{"y", "z"}, 0.5},
+		{[]string{"x", "x", " "}, []string{"x"}, 1},
+	}
+	for _, tc := range cases {
+		if got := overlapPaths(tc.a, tc.b); got != tc.want {
+			t.Fatalf("overlapPaths(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package artifactsearch indexes the searchable parts of stored worker
+// outputs: identifiers from code_symbols, components from arch_design, file
+// paths from code_symbols and code_roots, and evidence gaps from
+// infra_context. Every hit names the artifact key and the JSON pointer of the
+// matching element so a client can deep-link into the artifact.
+package artifactsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"insightify/internal/artifact"
+)
+
+// Keys whose artifacts are indexed.
+const (
+	KeyCodeSymbols  = "code_symbols"
+	KeyArchDesign   = "arch_design"
+	KeyCodeRoots    = "code_roots"
+	KeyInfraContext = "infra_context"
+)
+
+// Keys lists the indexed artifact keys.
+var Keys = []string{KeyCodeSymbols, KeyArchDesign, KeyCodeRoots, KeyInfraContext}
+
+// Source types of a Doc, usable as search filters.
+const (
+	SourceIdentifier = "identifier"
+	SourceComponent  = "component"
+	SourceFile       = "file"
+	SourceGap        = "gap"
+)
+
+// ErrUnknownSource is returned for a filter naming no source type.
+var ErrUnknownSource = errors.New("artifactsearch: unknown source type")
+
+// ParseSources validates source filters; empty entries are dropped.
+func ParseSources(names []string) ([]string, error) {
+	var out []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+			continue
+		case SourceIdentifier, SourceComponent, SourceFile, SourceGap:
+			out = append(out, name)
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSource, name)
+		}
+	}
+	return out, nil
+}
+
+// Doc is one searchable element of an artifact. Title carries the most
+// weight when ranking; Text is the rest of the element's searchable text.
+type Doc struct {
+	Source  string `json:"source"`
+	Key     string `json:"key"`
+	RunID   string `json:"run_id,omitempty"`
+	Path    string `json:"path,omitempty"` // stored artifact file, e.g. code_symbols_v2.json
+	Pointer string `json:"pointer"`        // RFC 6901 pointer inside the artifact
+	Title   string `json:"title"`
+	Text    string `json:"text,omitempty"`
+}
+
+// Extract decodes a raw artifact of key into Docs. Keys that are not indexed
+// yield no Docs.
+func Extract(key string, raw []byte) ([]Doc, error) {
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return nil, nil
+	}
+	switch key {
+	case KeyCodeSymbols:
+		var out artifact.CodeSymbolsOut
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return nil, fmt.Errorf("artifactsearch: %s: %w", key, err)
+		}
+		return codeSymbolsDocs(out), nil
+	case KeyArchDesign:
+		var out artifact.ArchDesignOut
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return nil, fmt.Errorf("artifactsearch: %s: %w", key, err)
+		}
+		return archDesignDocs(out), nil
+	case KeyCodeRoots:
+		var out artifact.CodeRootsOut
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return nil, fmt.Errorf("artifactsearch: %s: %w", key, err)
+		}
+		return codeRootsDocs(out), nil
+	case KeyInfraContext:
+		var out artifact.InfraContextOut
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return nil, fmt.Errorf("artifactsearch: %s: %w", key, err)
+		}
+		return infraContextDocs(out), nil
+	}
+	return nil, nil
+}
+
+func codeSymbolsDocs(out artifact.CodeSymbolsOut) []Doc {
+	var docs []Doc
+	for i, f := range out.Files {
+		if path := strings.TrimSpace(f.Path); path != "" {
+			docs = append(docs, Doc{
+				Source:  SourceFile,
+				Key:     KeyCodeSymbols,
+				Pointer: fmt.Sprintf("/files/%d", i),
+				Title:   path,
+			})
+		}
+		for j, id := range f.Identifiers {
+			if strings.TrimSpace(id.Name) == "" {
+				continue
+			}
+			docs = append(docs, Doc{
+				Source:  SourceIdentifier,
+				Key:     KeyCodeSymbols,
+				Pointer: fmt.Sprintf("/files/%d/identifiers/%d", i, j),
+				Title:   id.Name,
+				Text:    joinText(id.Summary, id.Role, f.Path),
+			})
+		}
+	}
+	return docs
+}
+
+func archDesignDocs(out artifact.ArchDesignOut) []Doc {
+	var docs []Doc
+	for i, c := range out.ArchitectureHypothesis.KeyComponents {
+		if strings.TrimSpace(c.Name) == "" {
+			continue
+		}
+		docs = append(docs, Doc{
+			Source:  SourceComponent,
+			Key:     KeyArchDesign,
+			Pointer: fmt.Sprintf("/architecture_hypothesis/key_components/%d", i),
+			Title:   c.Name,
+			Text:    joinText(c.Responsibility, c.Kind),
+		})
+	}
+	return docs
+}
+
+func codeRootsDocs(out artifact.CodeRootsOut) []Doc {
+	var docs []Doc
+	add := func(field string, paths []string) {
+		for i, p := range paths {
+			if strings.TrimSpace(p) == "" {
+				continue
+			}
+			docs = append(docs, Doc{
+				Source:  SourceFile,
+				Key:     KeyCodeRoots,
+				Pointer: fmt.Sprintf("/%s/%d", field, i),
+				Title:   p,
+			})
+		}
+	}
+	add("config_files", out.ConfigFiles)
+	add("runtime_config_files", out.RuntimeConfigFiles)
+	return docs
+}
+
+func infraContextDocs(out artifact.InfraContextOut) []Doc {
+	var docs []Doc
+	for i, g := range out.EvidenceGaps {
+		title := strings.TrimSpace(g.Topic)
+		if title == "" {
+			title = strings.TrimSpace(g.Question)
+		}
+		if title == "" {
+			continue
+		}
+		docs = append(docs, Doc{
+			Source:  SourceGap,
+			Key:     KeyInfraContext,
+			Pointer: fmt.Sprintf("/evidence_gaps/%d", i),
+			Title:   title,
+			Text:    joinText(g.Question, g.CurrentGuess, g.Impact),
+		})
+	}
+	return docs
+}
+
+func joinText(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, " — ")
+}
@@ -0,0 +1,306 @@
+package artifactsearch
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"insightify/internal/common/wordidx"
+)
+
+// Defaults for Limits and Query.Limit.
+const (
+	DefaultMaxDocs  = 200_000
+	DefaultMaxBytes = 64 << 20
+	DefaultLimit    = 20
+	MaxLimit        = 200
+)
+
+// snippetWidth is the number of bytes kept around the first match.
+const snippetWidth = 120
+
+// Limits caps an Index. Zero fields use the defaults.
+type Limits struct {
+	MaxDocs  int
+	MaxBytes int
+}
+
+// Query is a multi-term AND search. Sources restricts hits to those source
+// types; empty means all.
+type Query struct {
+	Text    string
+	Sources []string
+	Limit   int
+}
+
+// Hit is a ranked match with the part of the element that matched.
+type Hit struct {
+	Doc
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+// Result lists the best hits. Total counts every match before the limit;
+// Truncated reports that the index hit its Limits and misses some elements.
+type Result struct {
+	Hits      []Hit `json:"hits"`
+	Total     int   `json:"total"`
+	Truncated bool  `json:"truncated,omitempty"`
+}
+
+type posting struct {
+	doc   int32
+	tf    uint16
+	title bool
+}
+
+// Index is an in-memory inverted index over Docs. It is not safe for
+// concurrent Add; Search may run concurrently once building is done.
+type Index struct {
+	limits    Limits
+	docs      []Doc
+	postings  map[string][]posting
+	bytes     int
+	truncated bool
+}
+
+// NewIndex returns an empty index bounded by limits.
+func NewIndex(limits Limits) *Index {
+	if limits.MaxDocs <= 0 {
+		limits.MaxDocs = DefaultMaxDocs
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = DefaultMaxBytes
+	}
+	return &Index{limits: limits, postings: make(map[string][]posting)}
+}
+
+// Add indexes docs until a limit is reached and reports whether all of them
+// fit.
+func (x *Index) Add(docs ...Doc) bool {
+	for _, d := range docs {
+		if len(x.docs) >= x.limits.MaxDocs {
+			x.truncated = true
+			return false
+		}
+		terms := make(map[string]*posting)
+		count := func(s string, title bool) {
+			for _, t := range tokenize(s) {
+				p, ok := terms[t]
+				if !ok {
+					p = &posting{doc: int32(len(x.docs))}
+					terms[t] = p
+				}
+				if p.tf < math.MaxUint16 {
+					p.tf++
+				}
+				p.title = p.title || title
+			}
+		}
+		count(d.Title, true)
+		count(d.Text, false)
+
+		size := len(d.Source) + len(d.Key) + len(d.RunID) + len(d.Path) + len(d.Pointer) + len(d.Title) + len(d.Text) + 96
+		for t := range terms {
+			size += 8
+			if _, ok := x.postings[t]; !ok {
+				size += len(t) + 48
+			}
+		}
+		if x.bytes+size > x.limits.MaxBytes {
+			x.truncated = true
+			return false
+		}
+		x.bytes += size
+		x.docs = append(x.docs, d)
+		for t, p := range terms {
+			x.postings[t] = append(x.postings[t], *p)
+		}
+	}
+	return true
+}
+
+// Len returns the number of indexed docs.
+func (x *Index) Len() int { return len(x.docs) }
+
+// Bytes returns the estimated memory held by the index.
+func (x *Index) Bytes() int { return x.bytes }
+
+// Search returns docs containing every query term, best first. Title matches
+// rank above text matches, rare terms above common ones, and titles spelling
+// the query words (in any case or identifier style) get a bonus.
+func (x *Index) Search(q Query) Result {
+	res := Result{Hits: []Hit{}, Truncated: x.truncated}
+	terms := queryTerms(q.Text)
+	if len(terms) == 0 {
+		return res
+	}
+	lists := make([][]posting, len(terms))
+	for i, t := range terms {
+		lists[i] = x.postings[t]
+		if len(lists[i]) == 0 {
+			return res
+		}
+	}
+	sources := make(map[string]bool, len(q.Sources))
+	for _, s := range q.Sources {
+		sources[s] = true
+	}
+
+	// Walk the rarest term's postings and look the others up.
+	rarest := 0
+	for i := range lists {
+		if len(lists[i]) < len(lists[rarest]) {
+			rarest = i
+		}
+	}
+	n := float64(len(x.docs))
+	phrase := strings.Join(terms, " ")
+	var hits []Hit
+	for _, p := range lists[rarest] {
+		d := x.docs[p.doc]
+		if len(sources) > 0 && !sources[d.Source] {
+			continue
+		}
+		score := 0.0
+		matched := true
+		for i, list := range lists {
+			tp := p
+			if i != rarest {
+				j, ok := slices.BinarySearchFunc(list, p.doc, func(e posting, doc int32) int { return int(e.doc - doc) })
+				if !ok {
+					matched = false
+					break
+				}
+				tp = list[j]
+			}
+			idf := math.Log(1 + n/float64(len(list)))
+			w := float64(tp.tf)
+			if tp.title {
+				w += 3
+			}
+			score += idf * w
+		}
+		if !matched {
+			continue
+		}
+		switch title := strings.Join(queryTerms(d.Title), " "); {
+		case title == phrase:
+			score *= 2
+		case strings.Contains(title, phrase):
+			score *= 1.5
+		}
+		hits = append(hits, Hit{Doc: d, Score: math.Round(score*1000) / 1000})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Pointer < b.Pointer
+	})
+	res.Total = len(hits)
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	for i := range hits {
+		hits[i].Snippet = snippet(hits[i].Doc, terms)
+	}
+	res.Hits = hits
+	return res
+}
+
+// tokenize lowercases s and splits it into words; identifier-like words also
+// contribute their camelCase parts.
+func tokenize(s string) []string {
+	var out []string
+	for _, w := range words(s) {
+		out = append(out, strings.ToLower(w))
+		if parts := wordidx.SplitIdentifier(w); len(parts) > 1 {
+			for _, p := range parts {
+				out = append(out, strings.ToLower(p))
+			}
+		}
+	}
+	return out
+}
+
+// queryTerms splits the query like tokenize, but replaces an identifier by
+// its parts so "TokenBucket" also matches "token bucket".
+func queryTerms(s string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(t string) {
+		t = strings.ToLower(t)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	for _, w := range words(s) {
+		if parts := wordidx.SplitIdentifier(w); len(parts) > 1 {
+			for _, p := range parts {
+				add(p)
+			}
+			continue
+		}
+		add(w)
+	}
+	return out
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// snippet returns the part of the doc text around the first query term, or
+// the title when only the title matched.
+func snippet(d Doc, terms []string) string {
+	for _, text := range []string{d.Text, d.Title} {
+		lower := strings.ToLower(text)
+		if len(lower) != len(text) {
+			continue // offsets would not line up
+		}
+		at := -1
+		for _, t := range terms {
+			if i := strings.Index(lower, t); i >= 0 && (at < 0 || i < at) {
+				at = i
+			}
+		}
+		if at < 0 {
+			continue
+		}
+		if len(text) <= snippetWidth {
+			return text
+		}
+		start := max(0, at-snippetWidth/3)
+		end := min(len(text), start+snippetWidth)
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+		out := text[start:end]
+		if start > 0 {
+			out = "…" + out
+		}
+		if end < len(text) {
+			out += "…"
+		}
+		return out
+	}
+	return d.Title
+}
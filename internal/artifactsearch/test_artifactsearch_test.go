@@ -0,0 +1,117 @@
+package artifactsearch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func fixtureIndex(t *testing.T, limits Limits) *Index {
+	t.Helper()
+	x := NewIndex(limits)
+	for _, key := range Keys {
+		raw, err := os.ReadFile(filepath.Join("testdata", key+".json"))
+		if err != nil {
+			t.Fatalf("read %s: %v", key, err)
+		}
+		docs, err := Extract(key, raw)
+		if err != nil {
+			t.Fatalf("Extract(%s) error = %v", key, err)
+		}
+		x.Add(docs...)
+	}
+	return x
+}
+
+func pointers(res Result) []string {
+	var out []string
+	for _, h := range res.Hits {
+		out = append(out, h.Key+"#"+h.Pointer)
+	}
+	return out
+}
+
+func TestSearchRanksAndFilters(t *testing.T) {
+	x := fixtureIndex(t, Limits{})
+
+	res := x.Search(Query{Text: "token bucket"})
+	if res.Total != 7 {
+		t.Fatalf("Total = %d, want 7: %v", res.Total, pointers(res))
+	}
+	// The identifier named exactly after the query ranks first, then the
+	// other title matches, then text-only matches.
+	top := res.Hits[0]
+	if top.Source != SourceIdentifier || top.Title != "TokenBucket" || top.Pointer != "/files/0/identifiers/0" {
+		t.Fatalf("top hit = %+v", top)
+	}
+	for i := 1; i < len(res.Hits); i++ {
+		if res.Hits[i].Score > res.Hits[i-1].Score {
+			t.Fatalf("hits not sorted by score: %v", res.Hits)
+		}
+	}
+	if last := res.Hits[len(res.Hits)-1]; last.Title == "TokenBucket" || last.Source == SourceFile {
+		t.Fatalf("title match ranked last: %+v", last)
+	}
+
+	// Identifier-style queries match the split words.
+	if got := x.Search(Query{Text: "TokenBucket"}); got.Total != res.Total {
+		t.Fatalf("TokenBucket Total = %d, want %d", got.Total, res.Total)
+	}
+
+	// AND semantics: "bucket replicas" only matches the gap.
+	gap := x.Search(Query{Text: "bucket replicas"})
+	if gap.Total != 1 || gap.Hits[0].Source != SourceGap || gap.Hits[0].Pointer != "/evidence_gaps/0" {
+		t.Fatalf("bucket replicas = %v", pointers(gap))
+	}
+	if gap.Hits[0].Snippet != "Is the token bucket state shared across replicas?" {
+		t.Fatalf("snippet = %q", gap.Hits[0].Snippet)
+	}
+
+	comps := x.Search(Query{Text: "token bucket", Sources: []string{SourceComponent, SourceFile}})
+	want := map[string]bool{
+		"arch_design#/architecture_hypothesis/key_components/0": true,
+		"code_symbols#/files/0":                                 true,
+		"code_roots#/runtime_config_files/0":                    true,
+	}
+	if len(comps.Hits) != len(want) {
+		t.Fatalf("filtered hits = %v", pointers(comps))
+	}
+	for _, p := range pointers(comps) {
+		if !want[p] {
+			t.Fatalf("unexpected filtered hit %s", p)
+		}
+	}
+
+	if got := x.Search(Query{Text: "token", Limit: 2}); len(got.Hits) != 2 || got.Total <= 2 {
+		t.Fatalf("limit: hits=%d total=%d", len(got.Hits), got.Total)
+	}
+	if got := x.Search(Query{Text: "nothing matches"}); got.Total != 0 || got.Hits == nil {
+		t.Fatalf("no match = %+v", got)
+	}
+}
+
+func TestIndexLimits(t *testing.T) {
+	x := fixtureIndex(t, Limits{MaxDocs: 3})
+	if x.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", x.Len())
+	}
+	if res := x.Search(Query{Text: "token"}); !res.Truncated {
+		t.Fatalf("capped index should report Truncated")
+	}
+
+	x = fixtureIndex(t, Limits{MaxBytes: 600})
+	if x.Bytes() > 600 || x.Len() == 0 {
+		t.Fatalf("Bytes() = %d Len() = %d, want within 600 bytes", x.Bytes(), x.Len())
+	}
+}
+
+func TestParseSources(t *testing.T) {
+	got, err := ParseSources([]string{" Identifier", "", "gap"})
+	if err != nil || len(got) != 2 || got[0] != SourceIdentifier || got[1] != SourceGap {
+		t.Fatalf("ParseSources() = %v, %v", got, err)
+	}
+	if _, err := ParseSources([]string{"symbols"}); !errors.Is(err, ErrUnknownSource) {
+		t.Fatalf("ParseSources(symbols) error = %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package artifact
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -144,6 +145,26 @@ func (s *CachedStore) Put(ctx context.Context, runID, path string, content []byt
 	return nil
 }
 
+// Delete removes the artifact from the origin and drops its cached entries.
+// It fails when the origin cannot delete artifacts.
+func (s *CachedStore) Delete(ctx context.Context, runID, path string) error {
+	deleter, ok := s.origin.(artifactrepo.Deleter)
+	if !ok {
+		return fmt.Errorf("artifact store %T cannot delete artifacts", s.origin)
+	}
+	s.metrics.originWrites.Add(1)
+	if err := deleter.Delete(ctx, runID, path); err != nil {
+		s.metrics.originWriteErr.Add(1)
+		return err
+	}
+
+	key := artifactKey(runID, path)
+	s.blobCache.Delete(key)
+	s.listCache.Delete(strings.TrimSpace(runID))
+	s.urlCache.Delete(key)
+	return nil
+}
+
 func (s *CachedStore) Get(ctx context.Context, runID, path string) ([]byte, error) {
 	key := artifactKey(runID, path)
 	if raw, ok := s.blobCache.Get(key); ok {
@@ -37,6 +37,17 @@ func (s *DiskStore) Get(_ context.Context, runID, path string) ([]byte, error) {
 	return os.ReadFile(fullPath)
 }
 
+func (s *DiskStore) Delete(_ context.Context, runID, path string) error {
+	fullPath, err := s.pathFor(runID, path)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func (s *DiskStore) GetURL(_ context.Context, _, _ string) (string, error) {
 	return "", nil
 }
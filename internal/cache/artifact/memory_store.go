@@ -63,6 +63,25 @@ func (s *MemoryStore) Get(_ context.Context, runID, path string) ([]byte, error)
 	return append([]byte(nil), raw...), nil
 }
 
+func (s *MemoryStore) Delete(_ context.Context, runID, path string) error {
+	if s == nil {
+		return fmt.Errorf("store is nil")
+	}
+	runID = strings.TrimSpace(runID)
+	path = strings.TrimSpace(path)
+	if runID == "" {
+		return fmt.Errorf("run_id is required")
+	}
+	if path == "" {
+		return fmt.Errorf("path is required")
+	}
+	key := runID + "/" + strings.TrimLeft(path, "/")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
 func (s *MemoryStore) GetURL(_ context.Context, _, _ string) (string, error) {
 	return "", nil
 }
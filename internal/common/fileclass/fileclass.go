@@ -0,0 +1,242 @@
+// Package fileclass flags files that rarely deserve prompt tokens: binaries,
+// generated code and vendored dependencies. A file is read once, sniffing
+// its first SniffBytes and counting the lines of text files. The sniffed
+// flags are cached by the hash of the sniffed bytes and the size, so files
+// sharing them (copied vendor trees, duplicated binaries) are sniffed once;
+// line counts depend on the whole content and are never cached, so binaries
+// are the files read only up to the sniff.
+package fileclass
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"insightify/internal/common/safeio"
+)
+
+const (
+	// SniffBytes is read from every file to classify it.
+	SniffBytes = 1 << 10
+	// MaxLineCountBytes bounds the bytes read to count lines; larger text
+	// files get no line count.
+	MaxLineCountBytes = 8 << 20
+	// cacheEntries bounds the cache; it is reset when full.
+	cacheEntries = 1 << 16
+)
+
+// Flags classifies one file. Lines counts the lines of text files up to
+// MaxLineCountBytes and is zero otherwise.
+type Flags struct {
+	Binary    bool
+	Generated bool
+	Vendored  bool
+	Lines     int
+}
+
+// Flagged reports whether any of Binary, Generated or Vendored is set.
+func (f Flags) Flagged() bool { return f.Binary || f.Generated || f.Vendored }
+
+// Names lists the set flags by their artifact field names.
+func (f Flags) Names() []string {
+	var out []string
+	if f.Binary {
+		out = append(out, "is_binary")
+	}
+	if f.Generated {
+		out = append(out, "is_generated")
+	}
+	if f.Vendored {
+		out = append(out, "is_vendored")
+	}
+	return out
+}
+
+// generatedMarkers flag generated files when found in the sniffed bytes.
+var generatedMarkers = [][]byte{[]byte("Code generated"), []byte("@generated"), []byte("DO NOT EDIT")}
+
+// generatedFiles are lockfiles and similar tool output, by base name.
+var generatedFiles = map[string]bool{
+	"package-lock.json": true, "pnpm-lock.yaml": true, "yarn.lock": true,
+	"go.sum": true, "Cargo.lock": true, "poetry.lock": true,
+	"composer.lock": true, "Gemfile.lock": true, "Pipfile.lock": true,
+	"npm-shrinkwrap.json": true, "bun.lockb": true,
+}
+
+// generatedDirs and vendoredDirs flag every file below a directory of that
+// name.
+var (
+	generatedDirs = map[string]bool{"dist": true}
+	vendoredDirs  = map[string]bool{"vendor": true, "node_modules": true, "third_party": true, "bower_components": true}
+)
+
+// WithPath adds the flags ByPath derives from the repository-relative path
+// rel.
+func (f Flags) WithPath(rel string) Flags {
+	gen, vendored := ByPath(rel)
+	f.Generated = f.Generated || gen
+	f.Vendored = f.Vendored || vendored
+	return f
+}
+
+// ByPath returns the flags implied by the repository-relative path alone.
+func ByPath(p string) (generated, vendored bool) {
+	p = path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	dir, base := path.Split(p)
+	for _, seg := range strings.Split(strings.Trim(dir, "/"), "/") {
+		vendored = vendored || vendoredDirs[seg]
+		generated = generated || generatedDirs[seg]
+	}
+	lower := strings.ToLower(base)
+	generated = generated || generatedFiles[base] ||
+		strings.Contains(lower, ".pb.") ||
+		strings.HasSuffix(lower, "_pb2.py") ||
+		strings.Contains(lower, "_generated.") ||
+		strings.Contains(lower, ".generated.") ||
+		strings.HasSuffix(lower, ".min.js") ||
+		strings.HasSuffix(lower, ".min.css")
+	return generated, vendored
+}
+
+type cacheKey struct {
+	sum  [sha256.Size]byte
+	size int64
+}
+
+// Classifier classifies files and caches the flags sniffed from their first
+// bytes.
+type Classifier struct {
+	mu    sync.Mutex
+	cache map[cacheKey]Flags
+}
+
+// New returns an empty Classifier.
+func New() *Classifier {
+	return &Classifier{cache: map[cacheKey]Flags{}}
+}
+
+var defaultClassifier = New()
+
+// Classify classifies p with the process-wide Classifier.
+func Classify(fs *safeio.SafeFS, p string) (Flags, error) {
+	return defaultClassifier.Classify(fs, p)
+}
+
+// Classify opens p in fs and classifies it by content; add the flags of
+// its repository-relative path with WithPath.
+func (c *Classifier) Classify(fs *safeio.SafeFS, p string) (Flags, error) {
+	f, err := fs.SafeOpen(p)
+	if err != nil {
+		return Flags{}, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Flags{}, err
+	}
+	return c.classifyReader(f, info.Size())
+}
+
+// classifyReader sniffs r, whose size is known, or takes the sniffed flags
+// from the cache, and counts its lines if it is text, reading each byte at
+// most once.
+func (c *Classifier) classifyReader(r io.Reader, size int64) (Flags, error) {
+	head := make([]byte, SniffBytes)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Flags{}, err
+	}
+	head = head[:n]
+	key := cacheKey{sum: sha256.Sum256(head), size: size}
+	c.mu.Lock()
+	flags, ok := c.cache[key]
+	c.mu.Unlock()
+	if !ok {
+		flags = sniff(head, size)
+		c.mu.Lock()
+		if len(c.cache) >= cacheEntries {
+			c.cache = map[cacheKey]Flags{}
+		}
+		c.cache[key] = flags
+		c.mu.Unlock()
+	}
+
+	if !flags.Binary && len(head) > 0 && size <= MaxLineCountBytes {
+		lines := bytes.Count(head, []byte("\n"))
+		last := head[len(head)-1]
+		rest, err := countLines(r)
+		if err != nil {
+			return Flags{}, err
+		}
+		lines += rest.lines
+		if rest.n > 0 {
+			last = rest.last
+		}
+		if last != '\n' {
+			lines++
+		}
+		flags.Lines = lines
+	}
+	return flags, nil
+}
+
+// sniff classifies the first bytes of a file of the given size. NUL bytes
+// or invalid UTF-8 mark binaries; a generated-code marker, or a first line
+// longer than the sniff (minified output), marks generated files.
+func sniff(head []byte, size int64) Flags {
+	if bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(trimPartialRune(head)) {
+		return Flags{Binary: true}
+	}
+	var flags Flags
+	for _, m := range generatedMarkers {
+		if bytes.Contains(head, m) {
+			flags.Generated = true
+		}
+	}
+	if size > SniffBytes && bytes.IndexByte(head, '\n') < 0 {
+		flags.Generated = true
+	}
+	return flags
+}
+
+// trimPartialRune drops a multi-byte rune cut at the end of the sniff.
+func trimPartialRune(b []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+type lineCount struct {
+	lines int
+	n     int64
+	last  byte
+}
+
+func countLines(r io.Reader) (lineCount, error) {
+	var out lineCount
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			out.lines += bytes.Count(buf[:n], []byte("\n"))
+			out.n += int64(n)
+			out.last = buf[n-1]
+		}
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package fileclass
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"insightify/internal/common/safeio"
+)
+
+func writeFixtures(t *testing.T, files map[string]string) *safeio.SafeFS {
+	t.Helper()
+	root := t.TempDir()
+	for name, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs, err := safeio.NewSafeFS(root)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	return fs
+}
+
+func TestClassifyFixtures(t *testing.T) {
+	files := map[string]string{
+		"main.go":                    "package main\n\nfunc main() {}\n",
+		"no_newline.txt":             "one\ntwo",
+		"logo.png":                   "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"latin1.txt":                 "caf\xe9\n",
+		"api/service.pb.go":          "package api\n",
+		"gen.go":                     "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage api\n",
+		"web/app.min.js":             "var a=1;\n",
+		"web/bundle.js":              strings.Repeat("a", 2*SniffBytes),
+		"web/dist/index.js":          "console.log(1)\n",
+		"models_generated.ts":        "export {}\n",
+		"yarn.lock":                  "# yarn lockfile v1\n",
+		"vendor/lib/lib.go":          "package lib\n",
+		"web/node_modules/x/i.js":    "module.exports = 1\n",
+		"third_party/zlib/zlib.h":    "#define Z 1\n",
+		"docs/vendoring-policy.md":   "# Policy\n",
+		"node_modules_readme.md":     "# Not a vendor dir\n",
+		"internal/vendorish/a.go":    "package vendorish\n",
+		"python/thing_pb2.py":        "x = 1\n",
+		"src/component.generated.cs": "class C {}\n",
+	}
+	fs := writeFixtures(t, files)
+	cases := map[string]Flags{
+		"main.go":                    {Lines: 3},
+		"no_newline.txt":             {Lines: 2},
+		"logo.png":                   {Binary: true},
+		"latin1.txt":                 {Binary: true},
+		"api/service.pb.go":          {Generated: true, Lines: 1},
+		"gen.go":                     {Generated: true, Lines: 2},
+		"web/app.min.js":             {Generated: true, Lines: 1},
+		"web/bundle.js":              {Generated: true, Lines: 1},
+		"web/dist/index.js":          {Generated: true, Lines: 1},
+		"models_generated.ts":        {Generated: true, Lines: 1},
+		"yarn.lock":                  {Generated: true, Lines: 1},
+		"vendor/lib/lib.go":          {Vendored: true, Lines: 1},
+		"web/node_modules/x/i.js":    {Vendored: true, Lines: 1},
+		"third_party/zlib/zlib.h":    {Vendored: true, Lines: 1},
+		"docs/vendoring-policy.md":   {Lines: 1},
+		"node_modules_readme.md":     {Lines: 1},
+		"internal/vendorish/a.go":    {Lines: 1},
+		"python/thing_pb2.py":        {Generated: true, Lines: 1},
+		"src/component.generated.cs": {Generated: true, Lines: 1},
+	}
+	c := New()
+	for name, want := range cases {
+		got, err := c.Classify(fs, name)
+		if err != nil {
+			t.Fatalf("Classify(%s): %v", name, err)
+		}
+		if got = got.WithPath(name); got != want {
+			t.Fatalf("Classify(%s) = %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestClassifyMissingFile(t *testing.T) {
+	fs := writeFixtures(t, nil)
+	if _, err := New().Classify(fs, "missing.go"); err == nil {
+		t.Fatalf("Classify(missing.go) succeeded, want an error")
+	}
+}
+
+func TestClassifyKeepsSplitRuneText(t *testing.T) {
+	// A multi-byte rune straddling the sniff boundary is still text.
+	body := strings.Repeat("a", SniffBytes-1) + "é\n"
+	fs := writeFixtures(t, map[string]string{"a.txt": body})
+	got, err := New().Classify(fs, "a.txt")
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if got.Binary || got.Lines != 1 {
+		t.Fatalf("Classify = %+v, want one line of text", got)
+	}
+}
+
+func TestClassifyCachesByContent(t *testing.T) {
+	body := "package lib\n\nfunc F() {}\n"
+	fs := writeFixtures(t, map[string]string{
+		"vendor/a/lib.go": body,
+		"copy/lib.go":     body,
+	})
+	c := New()
+	a, err := c.Classify(fs, "vendor/a/lib.go")
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if len(c.cache) != 1 {
+		t.Fatalf("cache holds %d entries, want 1", len(c.cache))
+	}
+	b, err := c.Classify(fs, "copy/lib.go")
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+	if len(c.cache) != 1 || a != b || a.Lines != 3 {
+		t.Fatalf("flags = %+v and %+v with %d cache entries, want one shared entry", a, b, len(c.cache))
+	}
+	// Path flags are not cached with the content.
+	if !a.WithPath("vendor/a/lib.go").Vendored || b.WithPath("copy/lib.go").Vendored {
+		t.Fatalf("vendored flag leaked through the cache")
+	}
+}
+
+func TestClassifyReaderCountsPastSniff(t *testing.T) {
+	body := bytes.Repeat([]byte("line\n"), 1000)
+	got, err := New().classifyReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("classifyReader: %v", err)
+	}
+	if got.Lines != 1000 {
+		t.Fatalf("Lines = %d, want 1000", got.Lines)
+	}
+}
+
+func TestClassifyCountsLinesOfFilesSharingTheSniff(t *testing.T) {
+	head := strings.Repeat("line\n", SniffBytes/5+1)
+	c := New()
+	few, err := c.classifyReader(strings.NewReader(head+"aaaa\n"), int64(len(head)+5))
+	if err != nil {
+		t.Fatalf("classifyReader: %v", err)
+	}
+	many, err := c.classifyReader(strings.NewReader(head+"\n\n\n\n\n"), int64(len(head)+5))
+	if err != nil {
+		t.Fatalf("classifyReader: %v", err)
+	}
+	if len(c.cache) != 1 {
+		t.Fatalf("cache holds %d entries, want the shared sniff", len(c.cache))
+	}
+	if many.Lines != few.Lines+4 {
+		t.Fatalf("Lines = %d and %d, want the second file to count its own lines", few.Lines, many.Lines)
+	}
+}
+
+func TestFlagsNames(t *testing.T) {
+	f := Flags{Binary: true, Vendored: true}
+	if got := f.Names(); !reflect.DeepEqual(got, []string{"is_binary", "is_vendored"}) {
+		t.Fatalf("Names() = %v", got)
+	}
+	if (Flags{Lines: 4}).Flagged() {
+		t.Fatalf("Flagged() = true for plain text")
+	}
+}
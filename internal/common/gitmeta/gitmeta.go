@@ -0,0 +1,136 @@
+// Package gitmeta reads the revision a repository checkout is at straight
+// from its .git directory, so runs can record what they analyzed without a
+// git binary.
+package gitmeta
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Info is the revision of a checkout. Branch is empty for a detached HEAD
+// and Commit is empty on a branch without commits.
+type Info struct {
+	Commit string `json:"commit,omitempty"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// IsZero reports whether nothing is known about the revision.
+func (i Info) IsZero() bool { return i.Commit == "" && i.Branch == "" }
+
+const headsPrefix = "refs/heads/"
+
+// Read returns the revision of the checkout at root. ok is false when root is
+// not a git checkout or its HEAD cannot be read.
+func Read(root string) (info Info, ok bool) {
+	gitDir, ok := findGitDir(root)
+	if !ok {
+		return Info{}, false
+	}
+	b, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return Info{}, false
+	}
+	head := strings.TrimSpace(string(b))
+	ref, symbolic := strings.CutPrefix(head, "ref:")
+	if !symbolic {
+		if !isSHA(head) {
+			return Info{}, false
+		}
+		return Info{Commit: head}, true
+	}
+	ref = strings.TrimSpace(ref)
+	info.Branch = strings.TrimPrefix(ref, headsPrefix)
+	info.Commit = resolveRef(gitDir, ref)
+	return info, true
+}
+
+// findGitDir returns the git directory of root: root/.git, or the directory
+// a .git file points to (worktrees and submodules).
+func findGitDir(root string) (string, bool) {
+	if strings.TrimSpace(root) == "" {
+		return "", false
+	}
+	dotGit := filepath.Join(root, ".git")
+	st, err := os.Stat(dotGit)
+	if err != nil {
+		return "", false
+	}
+	if st.IsDir() {
+		return dotGit, true
+	}
+	b, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", false
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(b)), "gitdir:")
+	if !ok {
+		return "", false
+	}
+	dir = filepath.FromSlash(strings.TrimSpace(dir))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return dir, true
+}
+
+// resolveRef returns the commit ref points to, from its loose ref file or
+// packed-refs, or "" when it has none. Worktrees keep shared refs in the
+// directory named by their commondir file.
+func resolveRef(gitDir, ref string) string {
+	dirs := []string{gitDir}
+	if b, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := filepath.FromSlash(strings.TrimSpace(string(b)))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		dirs = append(dirs, common)
+	}
+	for _, dir := range dirs {
+		if b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			if sha := strings.TrimSpace(string(b)); isSHA(sha) {
+				return sha
+			}
+		}
+		if sha := packedRef(filepath.Join(dir, "packed-refs"), ref); sha != "" {
+			return sha
+		}
+	}
+	return ""
+}
+
+// packedRef looks ref up in a packed-refs file.
+func packedRef(path, ref string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		sha, name, ok := strings.Cut(line, " ")
+		if ok && strings.TrimSpace(name) == ref && isSHA(sha) {
+			return sha
+		}
+	}
+	return ""
+}
+
+// isSHA reports whether s is a SHA-1 or SHA-256 object name.
+func isSHA(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package gitmeta
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	shaMain    = "0123456789abcdef0123456789abcdef01234567"
+	shaRelease = "89abcdef0123456789abcdef0123456789abcdef"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	cases := []struct {
+		name   string
+		files  map[string]string
+		want   Info
+		wantOK bool
+	}{
+		{
+			name: "loose branch ref",
+			files: map[string]string{
+				".git/HEAD":            "ref: refs/heads/main\n",
+				".git/refs/heads/main": shaMain + "\n",
+			},
+			want:   Info{Commit: shaMain, Branch: "main"},
+			wantOK: true,
+		},
+		{
+			name: "packed branch ref",
+			files: map[string]string{
+				".git/HEAD": "ref: refs/heads/release/v1\n",
+				".git/packed-refs": "# pack-refs with: peeled fully-peeled sorted\n" +
+					shaMain + " refs/heads/main\n" +
+					shaRelease + " refs/heads/release/v1\n" +
+					"^" + shaMain + "\n",
+			},
+			want:   Info{Commit: shaRelease, Branch: "release/v1"},
+			wantOK: true,
+		},
+		{
+			name:   "detached head",
+			files:  map[string]string{".git/HEAD": shaRelease + "\n"},
+			want:   Info{Commit: shaRelease},
+			wantOK: true,
+		},
+		{
+			name:   "branch without commits",
+			files:  map[string]string{".git/HEAD": "ref: refs/heads/main\n"},
+			want:   Info{Branch: "main"},
+			wantOK: true,
+		},
+		{
+			name: "gitdir file of a worktree",
+			files: map[string]string{
+				".git":                                "gitdir: ../main/.git/worktrees/wt\n",
+				"../main/.git/worktrees/wt/HEAD":      "ref: refs/heads/feature\n",
+				"../main/.git/worktrees/wt/commondir": "../..\n",
+				"../main/.git/refs/heads/feature":     shaMain + "\n",
+			},
+			want:   Info{Commit: shaMain, Branch: "feature"},
+			wantOK: true,
+		},
+		{
+			name:  "not a checkout",
+			files: map[string]string{"main.go": "package main\n"},
+		},
+		{
+			name:  "garbage HEAD",
+			files: map[string]string{".git/HEAD": "not a ref\n"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), "repo")
+			if err := os.MkdirAll(root, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			writeTree(t, root, tc.files)
+			got, ok := Read(root)
+			if got != tc.want || ok != tc.wantOK {
+				t.Fatalf("Read() = %+v, %v; want %+v, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestReadEmptyRoot(t *testing.T) {
+	if got, ok := Read(""); ok || !got.IsZero() {
+		t.Fatalf("Read(\"\") = %+v, %v; want nothing", got, ok)
+	}
+}
@@ -0,0 +1,135 @@
+// Package graphdelta streams a phase's client graph while the phase runs:
+// workers emit Deltas through the Emitter attached to their context, and
+// readers rebuild the graph with Apply.
+package graphdelta
+
+import (
+	"context"
+
+	workerv1 "insightify/gen/go/worker/v1"
+)
+
+// Delta is one change to the graph of Phase. AddedNodes replace nodes with
+// the same Uid, so a node can be re-sent with a final description. Removing
+// a node also removes its edges.
+type Delta struct {
+	Phase        string                `json:"phase"`
+	AddedNodes   []*workerv1.GraphNode `json:"added_nodes,omitempty"`
+	RemovedNodes []string              `json:"removed_nodes,omitempty"`
+	AddedEdges   []*workerv1.GraphEdge `json:"added_edges,omitempty"`
+	RemovedEdges []*workerv1.GraphEdge `json:"removed_edges,omitempty"`
+}
+
+// Empty reports whether d changes nothing.
+func (d Delta) Empty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 && len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// View returns the added nodes and edges as a partial ClientView of Phase.
+func (d Delta) View() *workerv1.ClientView {
+	return &workerv1.ClientView{
+		Phase: d.Phase,
+		Content: &workerv1.ClientView_Graph{Graph: &workerv1.GraphView{
+			Nodes: d.AddedNodes,
+			Edges: d.AddedEdges,
+		}},
+	}
+}
+
+// Emitter receives the deltas of a run in emission order. Workers may run
+// concurrently, so it must be safe for concurrent use.
+type Emitter func(Delta)
+
+type ctxKeyEmitter struct{}
+
+// WithEmitter attaches fn to the phases run with ctx.
+func WithEmitter(ctx context.Context, fn Emitter) context.Context {
+	return context.WithValue(ctx, ctxKeyEmitter{}, fn)
+}
+
+// Enabled reports whether ctx has an Emitter, so workers can skip building
+// deltas nobody reads.
+func Enabled(ctx context.Context) bool {
+	fn, _ := ctx.Value(ctxKeyEmitter{}).(Emitter)
+	return fn != nil
+}
+
+// Emit sends d to the Emitter of ctx. Empty deltas and contexts without an
+// Emitter are ignored.
+func Emit(ctx context.Context, d Delta) {
+	fn, _ := ctx.Value(ctxKeyEmitter{}).(Emitter)
+	if fn == nil || d.Empty() {
+		return
+	}
+	fn(d)
+}
+
+// Apply applies d to view and returns it; a nil view starts empty. Removals
+// apply before additions. Edges are kept once per (From, To).
+func Apply(view *workerv1.GraphView, d Delta) *workerv1.GraphView {
+	if view == nil {
+		view = &workerv1.GraphView{}
+	}
+	if len(d.RemovedNodes) > 0 {
+		gone := make(map[string]bool, len(d.RemovedNodes))
+		for _, uid := range d.RemovedNodes {
+			gone[uid] = true
+		}
+		nodes := view.Nodes[:0]
+		for _, n := range view.Nodes {
+			if !gone[n.GetUid()] {
+				nodes = append(nodes, n)
+			}
+		}
+		view.Nodes = nodes
+		edges := view.Edges[:0]
+		for _, e := range view.Edges {
+			if !gone[e.GetFrom()] && !gone[e.GetTo()] {
+				edges = append(edges, e)
+			}
+		}
+		view.Edges = edges
+	}
+	if len(d.RemovedEdges) > 0 {
+		gone := make(map[[2]string]bool, len(d.RemovedEdges))
+		for _, e := range d.RemovedEdges {
+			gone[edgeKey(e)] = true
+		}
+		edges := view.Edges[:0]
+		for _, e := range view.Edges {
+			if !gone[edgeKey(e)] {
+				edges = append(edges, e)
+			}
+		}
+		view.Edges = edges
+	}
+
+	index := make(map[string]int, len(view.Nodes))
+	for i, n := range view.Nodes {
+		index[n.GetUid()] = i
+	}
+	for _, n := range d.AddedNodes {
+		if i, ok := index[n.GetUid()]; ok {
+			view.Nodes[i] = n
+			continue
+		}
+		index[n.GetUid()] = len(view.Nodes)
+		view.Nodes = append(view.Nodes, n)
+	}
+	seen := make(map[[2]string]bool, len(view.Edges))
+	for _, e := range view.Edges {
+		seen[edgeKey(e)] = true
+	}
+	for _, e := range d.AddedEdges {
+		if seen[edgeKey(e)] {
+			continue
+		}
+		seen[edgeKey(e)] = true
+		view.Edges = append(view.Edges, e)
+	}
+	return view
+}
+
+func edgeKey(e *workerv1.GraphEdge) [2]string {
+	return [2]string{e.GetFrom(), e.GetTo()}
+}
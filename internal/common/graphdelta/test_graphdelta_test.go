@@ -0,0 +1,55 @@
+package graphdelta
+
+import (
+	"context"
+	"testing"
+
+	workerv1 "insightify/gen/go/worker/v1"
+)
+
+func node(uid, desc string) *workerv1.GraphNode {
+	return &workerv1.GraphNode{Uid: uid, Label: uid, Description: desc}
+}
+
+func edge(from, to string) *workerv1.GraphEdge {
+	return &workerv1.GraphEdge{From: from, To: to}
+}
+
+func TestApplyUpsertsAndRemoves(t *testing.T) {
+	deltas := []Delta{
+		{AddedNodes: []*workerv1.GraphNode{node("a", ""), node("b", ""), node("c", "")}},
+		{AddedEdges: []*workerv1.GraphEdge{edge("a", "b"), edge("b", "c"), edge("a", "b"), edge("c", "a")}},
+		{RemovedEdges: []*workerv1.GraphEdge{edge("b", "c")}},
+		{RemovedNodes: []string{"c"}, AddedNodes: []*workerv1.GraphNode{node("a", "final")}},
+	}
+	var view *workerv1.GraphView
+	for _, d := range deltas {
+		view = Apply(view, d)
+	}
+
+	if len(view.Nodes) != 2 || view.Nodes[0].Uid != "a" || view.Nodes[0].Description != "final" || view.Nodes[1].Uid != "b" {
+		t.Fatalf("nodes = %v, want a (re-sent) and b", view.Nodes)
+	}
+	// The duplicate a->b is kept once; c's edges left with it.
+	if len(view.Edges) != 1 || view.Edges[0].From != "a" || view.Edges[0].To != "b" {
+		t.Fatalf("edges = %v, want only a->b", view.Edges)
+	}
+}
+
+func TestEmitSkipsEmptyDeltasAndMissingEmitter(t *testing.T) {
+	Emit(context.Background(), Delta{AddedNodes: []*workerv1.GraphNode{node("a", "")}})
+	if Enabled(context.Background()) {
+		t.Fatalf("Enabled() without an emitter")
+	}
+
+	var got []Delta
+	ctx := WithEmitter(context.Background(), func(d Delta) { got = append(got, d) })
+	Emit(ctx, Delta{Phase: "p"})
+	Emit(ctx, Delta{Phase: "p", RemovedNodes: []string{"a"}})
+	if !Enabled(ctx) || len(got) != 1 || got[0].RemovedNodes[0] != "a" {
+		t.Fatalf("emitted %v, want only the non-empty delta", got)
+	}
+	if v := got[0].View(); v.Phase != "p" || v.GetGraph() == nil {
+		t.Fatalf("View() = %v", v)
+	}
+}
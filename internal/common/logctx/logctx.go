@@ -7,25 +7,80 @@ import (
 	traceutil "insightify/internal/common/trace"
 )
 
+type ctxKeyAttrs struct{}
+
+// With returns a context whose log lines carry the given key/value pairs
+// (e.g. "run_id", id). A key set again replaces the earlier value.
+func With(ctx context.Context, kv ...any) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ctxKeyAttrs{}, merge(Attrs(ctx), kv))
+}
+
+// merge appends kv to base, replacing values of keys base already has.
+func merge(base, kv []any) []any {
+	out := append(make([]any, 0, len(base)+len(kv)), base...)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok || i+1 >= len(kv) {
+			// slog.Attr values and dangling keys pass through unchanged.
+			out = append(out, kv[i])
+			i--
+			continue
+		}
+		replaced := false
+		for j := 0; j+1 < len(out); j += 2 {
+			if out[j] == key {
+				out[j+1] = kv[i+1]
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			out = append(out, key, kv[i+1])
+		}
+	}
+	return out
+}
+
+// Attrs returns the key/value pairs attached with With.
+func Attrs(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKeyAttrs{}).([]any)
+	return append([]any(nil), attrs...)
+}
+
 func Info(ctx context.Context, msg string, kv ...any) {
-	slog.InfoContext(ctx, msg, append(traceKV(ctx), kv...)...)
+	slog.InfoContext(ctx, msg, merge(traceKV(ctx), kv)...)
 }
 
 func Warn(ctx context.Context, msg string, kv ...any) {
-	slog.WarnContext(ctx, msg, append(traceKV(ctx), kv...)...)
+	slog.WarnContext(ctx, msg, merge(traceKV(ctx), kv)...)
 }
 
 func Error(ctx context.Context, msg string, err error, kv ...any) {
-	args := append(traceKV(ctx), kv...)
+	args := merge(traceKV(ctx), kv)
 	if err != nil {
 		args = append(args, "error", err.Error())
 	}
 	slog.ErrorContext(ctx, msg, args...)
 }
 
+// Log writes to logger at level with the context's trace ID and attributes.
+func Log(ctx context.Context, logger *slog.Logger, level slog.Level, msg string, kv ...any) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Log(ctx, level, msg, merge(traceKV(ctx), kv)...)
+}
+
 func traceKV(ctx context.Context) []any {
+	kv := Attrs(ctx)
 	if traceID := traceutil.FromContext(ctx); traceID != "" {
-		return []any{"trace_id", traceID}
+		kv = append([]any{"trace_id", traceID}, kv...)
 	}
-	return nil
+	return kv
 }
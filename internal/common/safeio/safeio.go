@@ -3,17 +3,71 @@ package safeio
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // SafeFS provides read-only helpers that resolve paths relative to a fixed root.
 type SafeFS struct {
-	absRoot string // absolute root with symlinks resolved
+	absRoot string      // absolute root with symlinks resolved
+	budget  *ReadBudget // nil reads without limit
+}
+
+// ErrReadBudgetExceeded is returned by reads through a SafeFS once its
+// ReadBudget is spent; every later read fails the same way.
+var ErrReadBudgetExceeded = errors.New("safeio: read budget exceeded")
+
+// ReadBudget caps the bytes read through the SafeFS views that share it, so a
+// runaway phase fails instead of reading without bound. It is safe for
+// concurrent use.
+type ReadBudget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// NewReadBudget returns a budget of maxBytes, or nil (no limit) when
+// maxBytes <= 0.
+func NewReadBudget(maxBytes int64) *ReadBudget {
+	if maxBytes <= 0 {
+		return nil
+	}
+	return &ReadBudget{limit: maxBytes}
+}
+
+// Limit returns the budget in bytes; zero means unlimited.
+func (b *ReadBudget) Limit() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.limit
+}
+
+// Used returns the bytes charged so far, including the read that went over.
+func (b *ReadBudget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.used.Load()
+}
+
+func (b *ReadBudget) charge(n int64) error {
+	if b == nil {
+		return nil
+	}
+	used := b.used.Load()
+	if n > 0 {
+		used = b.used.Add(n)
+	}
+	if used > b.limit {
+		return fmt.Errorf("%w: %d of %d bytes", ErrReadBudgetExceeded, used, b.limit)
+	}
+	return nil
 }
 
 var (
@@ -66,6 +120,16 @@ func NewSafeFS(root string) (*SafeFS, error) {
 	return &SafeFS{absRoot: abs}, nil
 }
 
+// WithReadBudget returns a view of s whose SafeReadFile and SafeOpen reads
+// count against budget. Views built from one budget share it; a nil budget
+// returns s.
+func (s *SafeFS) WithReadBudget(budget *ReadBudget) *SafeFS {
+	if s == nil || budget == nil {
+		return s
+	}
+	return &SafeFS{absRoot: s.absRoot, budget: budget}
+}
+
 // Root returns the absolute root directory bound to this SafeFS.
 func (s *SafeFS) Root() string {
 	if s == nil {
@@ -87,11 +151,50 @@ func (s *SafeFS) SafeReadFile(userPath string) ([]byte, error) {
 	if info.IsDir() {
 		return nil, errors.New("safeio: path is a directory")
 	}
+	// Charge the size up front so a file past the budget is never read.
+	if err := s.budget.charge(info.Size()); err != nil {
+		return nil, fmt.Errorf("read %s: %w", userPath, err)
+	}
 	return os.ReadFile(p)
 }
 
+// ErrFileTooLarge is returned by SafeReadFileLimited for files above its
+// hard maximum.
+var ErrFileTooLarge = errors.New("safeio: file too large")
+
+// SafeReadFileLimited reads at most maxBytes of a file relative to the root
+// and reports whether the content was cut short. Files larger than hardMax
+// are not read at all; the error wraps ErrFileTooLarge. A limit <= 0 is off.
+func (s *SafeFS) SafeReadFileLimited(userPath string, maxBytes, hardMax int64) ([]byte, bool, error) {
+	f, err := s.SafeOpen(userPath)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	if hardMax > 0 && info.Size() > hardMax {
+		return nil, false, fmt.Errorf("%w: %s is %d bytes, max %d", ErrFileTooLarge, userPath, info.Size(), hardMax)
+	}
+	if maxBytes <= 0 {
+		data, err := io.ReadAll(f)
+		return data, false, err
+	}
+	// One byte past the cap tells a cut file from one of exactly maxBytes.
+	data, err := io.ReadAll(io.LimitReader(f, maxBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(data)) > maxBytes {
+		return data[:maxBytes], true, nil
+	}
+	return data, false, nil
+}
+
 // SafeOpen opens a file relative to the root for reading.
-func (s *SafeFS) SafeOpen(userPath string) (*os.File, error) {
+func (s *SafeFS) SafeOpen(userPath string) (*File, error) {
 	p, err := s.resolve(userPath)
 	if err != nil {
 		return nil, err
@@ -103,7 +206,43 @@ func (s *SafeFS) SafeOpen(userPath string) (*os.File, error) {
 	if info.IsDir() {
 		return nil, errors.New("safeio: path is a directory")
 	}
-	return os.Open(p)
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	return &File{f: f, budget: s.budget}, nil
+}
+
+// File is a file opened through SafeFS. Its reads count against the read
+// budget of the SafeFS it came from.
+type File struct {
+	f      *os.File
+	budget *ReadBudget
+}
+
+// Name returns the resolved path of the file.
+func (f *File) Name() string { return f.f.Name() }
+
+// Stat returns the file's metadata.
+func (f *File) Stat() (fs.FileInfo, error) { return f.f.Stat() }
+
+// Close closes the file.
+func (f *File) Close() error { return f.f.Close() }
+
+// Seek sets the offset of the next Read.
+func (f *File) Seek(offset int64, whence int) (int64, error) { return f.f.Seek(offset, whence) }
+
+// Read reads like os.File.Read; the read that takes the budget over returns
+// its bytes with an error wrapping ErrReadBudgetExceeded.
+func (f *File) Read(p []byte) (int, error) {
+	if err := f.budget.charge(0); err != nil {
+		return 0, err
+	}
+	n, err := f.f.Read(p)
+	if cerr := f.budget.charge(int64(n)); cerr != nil {
+		return n, cerr
+	}
+	return n, err
 }
 
 // SafeStat returns metadata for a file or directory under the root.
@@ -1,6 +1,8 @@
 package safeio
 
 import (
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -20,3 +22,79 @@ func TestSafeFSAllowsAbsoluteUnderRoot(t *testing.T) {
 		t.Fatalf("SafeReadFile absolute: %v", err)
 	}
 }
+
+func TestSafeFSReadBudget(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{"a.txt": "hello", "b.txt": "world!"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	root, err := NewSafeFS(dir)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+
+	budget := NewReadBudget(10)
+	fs := root.WithReadBudget(budget)
+	if data, err := fs.SafeReadFile("a.txt"); err != nil || string(data) != "hello" {
+		t.Fatalf("read under budget = %q, %v", data, err)
+	}
+	// b.txt takes the total to 11 bytes, one over.
+	if _, err := fs.SafeReadFile("b.txt"); !errors.Is(err, ErrReadBudgetExceeded) {
+		t.Fatalf("read over budget error = %v, want ErrReadBudgetExceeded", err)
+	}
+	// Once spent the budget stays spent, also for opened files and for other
+	// views sharing it.
+	f, err := root.WithReadBudget(budget).SafeOpen("a.txt")
+	if err != nil {
+		t.Fatalf("SafeOpen: %v", err)
+	}
+	defer f.Close()
+	if _, err := io.ReadAll(f); !errors.Is(err, ErrReadBudgetExceeded) {
+		t.Fatalf("read after budget spent error = %v, want ErrReadBudgetExceeded", err)
+	}
+	if data, err := root.SafeReadFile("b.txt"); err != nil || string(data) != "world!" {
+		t.Fatalf("read without budget = %q, %v", data, err)
+	}
+}
+
+func TestSafeOpenReadBudget(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	root, err := NewSafeFS(dir)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	cases := []struct {
+		name    string
+		budget  int64
+		wantErr bool
+	}{
+		{name: "exact", budget: 10},
+		{name: "unlimited", budget: 0},
+		{name: "over", budget: 4, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			budget := NewReadBudget(tc.budget)
+			f, err := root.WithReadBudget(budget).SafeOpen("a.txt")
+			if err != nil {
+				t.Fatalf("SafeOpen: %v", err)
+			}
+			defer f.Close()
+			data, err := io.ReadAll(f)
+			if got := errors.Is(err, ErrReadBudgetExceeded); got != tc.wantErr {
+				t.Fatalf("ReadAll error = %v, want budget error %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && string(data) != "0123456789" {
+				t.Fatalf("data = %q", data)
+			}
+			if tc.wantErr && budget.Used() <= budget.Limit() {
+				t.Fatalf("used = %d, limit = %d; want over", budget.Used(), budget.Limit())
+			}
+		})
+	}
+}
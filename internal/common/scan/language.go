@@ -0,0 +1,62 @@
+package scan
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// languageByExt maps lower-case file extensions to language identifiers
+// understood by common syntax highlighters.
+var languageByExt = map[string]string{
+	".go":     "go",
+	".ts":     "typescript",
+	".tsx":    "tsx",
+	".js":     "javascript",
+	".jsx":    "jsx",
+	".mjs":    "javascript",
+	".cjs":    "javascript",
+	".py":     "python",
+	".rs":     "rust",
+	".java":   "java",
+	".kt":     "kotlin",
+	".swift":  "swift",
+	".c":      "c",
+	".h":      "c",
+	".cc":     "cpp",
+	".cpp":    "cpp",
+	".hpp":    "cpp",
+	".cs":     "csharp",
+	".rb":     "ruby",
+	".php":    "php",
+	".sh":     "shell",
+	".bash":   "shell",
+	".sql":    "sql",
+	".proto":  "protobuf",
+	".md":     "markdown",
+	".json":   "json",
+	".yaml":   "yaml",
+	".yml":    "yaml",
+	".toml":   "toml",
+	".html":   "html",
+	".css":    "css",
+	".scss":   "scss",
+	".vue":    "vue",
+	".svelte": "svelte",
+}
+
+// languageByName covers files recognized by name rather than extension.
+var languageByName = map[string]string{
+	"dockerfile": "dockerfile",
+	"makefile":   "makefile",
+	"go.mod":     "go.mod",
+}
+
+// Language returns the language of path from its name or extension, or ""
+// when unknown.
+func Language(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	if lang, ok := languageByName[base]; ok {
+		return lang
+	}
+	return languageByExt[strings.ToLower(filepath.Ext(base))]
+}
@@ -0,0 +1,155 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrTruncated is returned when traversal stopped because Options.MaxFiles or
+// Options.MaxTotalBytes was reached. Entries visited before the limit were
+// delivered normally.
+var ErrTruncated = errors.New("scan: repository exceeds size limits; traversal truncated")
+
+// Stats reports what a scan delivered. Skipped counts files that were seen but
+// not delivered because of MaxPerDir or a size limit; a replay from the
+// whole-tree cache only knows delivered files.
+type Stats struct {
+	FilesScanned int
+	BytesScanned int64
+	FilesSkipped int
+	BytesSkipped int64
+	// Truncated is set when MaxFiles or MaxTotalBytes stopped traversal.
+	Truncated bool
+}
+
+// limiter enforces MaxFiles/MaxTotalBytes and keeps Stats. Callbacks may run
+// concurrently during parallel traversal.
+type limiter struct {
+	mu       sync.Mutex
+	maxFiles int
+	maxBytes int64
+	stats    Stats
+}
+
+var (
+	limitsMu        sync.RWMutex
+	defaultMaxFiles int
+	defaultMaxBytes int64
+)
+
+// SetLimits sets the MaxFiles and MaxTotalBytes applied to scans whose
+// Options leave them zero. Values <= 0 leave that limit off.
+func SetLimits(maxFiles int, maxTotalBytes int64) {
+	limitsMu.Lock()
+	defaultMaxFiles, defaultMaxBytes = maxFiles, maxTotalBytes
+	limitsMu.Unlock()
+}
+
+func newLimiter(opts Options) *limiter {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	l := &limiter{maxFiles: opts.MaxFiles, maxBytes: opts.MaxTotalBytes}
+	if l.maxFiles == 0 {
+		l.maxFiles = defaultMaxFiles
+	}
+	if l.maxBytes == 0 {
+		l.maxBytes = defaultMaxBytes
+	}
+	return l
+}
+
+// admit reports whether fv may be delivered and accounts for it. The first
+// file over a limit truncates the scan; nothing is admitted afterwards.
+func (l *limiter) admit(fv FileVisit) bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if fv.IsDir {
+		return !l.stats.Truncated
+	}
+	if !l.stats.Truncated &&
+		((l.maxFiles > 0 && l.stats.FilesScanned >= l.maxFiles) ||
+			(l.maxBytes > 0 && l.stats.BytesScanned+fv.Size > l.maxBytes)) {
+		l.stats.Truncated = true
+	}
+	if l.stats.Truncated {
+		l.stats.FilesSkipped++
+		l.stats.BytesSkipped += fv.Size
+		return false
+	}
+	l.stats.FilesScanned++
+	l.stats.BytesScanned += fv.Size
+	return true
+}
+
+// skip records a file left out by MaxPerDir.
+func (l *limiter) skip(size int64) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.stats.FilesSkipped++
+	l.stats.BytesSkipped += size
+	l.mu.Unlock()
+}
+
+// stopped reports whether a limit has truncated the scan.
+func (l *limiter) stopped() bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats.Truncated
+}
+
+// wrap returns a VisitFunc delivering only admitted entries to cb.
+func (l *limiter) wrap(cb VisitFunc) VisitFunc {
+	return func(fv FileVisit) {
+		if l.admit(fv) && cb != nil {
+			cb(fv)
+		}
+	}
+}
+
+func (l *limiter) result() (Stats, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stats.Truncated {
+		return l.stats, ErrTruncated
+	}
+	return l.stats, nil
+}
+
+// Truncation describes a scan stopped by a size limit.
+type Truncation struct {
+	Root  string
+	Stats Stats
+}
+
+// TruncationHook is told about every truncated scan reported with
+// ReportTruncated.
+type TruncationHook func(ctx context.Context, t Truncation)
+
+type ctxKeyTruncationHook struct{}
+
+// WithTruncationHook attaches hook to ctx for ReportTruncated.
+func WithTruncationHook(ctx context.Context, hook TruncationHook) context.Context {
+	return context.WithValue(ctx, ctxKeyTruncationHook{}, hook)
+}
+
+// ReportTruncated lets a caller keep the partial result of a truncated scan:
+// for ErrTruncated it tells ctx's TruncationHook and returns nil. Other
+// errors are returned unchanged.
+func ReportTruncated(ctx context.Context, root string, stats Stats, err error) error {
+	if !errors.Is(err, ErrTruncated) {
+		return err
+	}
+	if hook, _ := ctx.Value(ctxKeyTruncationHook{}).(TruncationHook); hook != nil {
+		hook(ctx, Truncation{Root: root, Stats: stats})
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ Package scan walks a repository tree and emits lightweight metadata for each ent
 It supports:
   - MaxDepth (limit descent),
   - IgnoreDirs (skip by basename),
+  - MaxFiles/MaxTotalBytes (stop with ErrTruncated on huge repositories),
   - In-process caching.
 
 	Optional subtree caching enables partial re-scan for specific folders.
@@ -48,6 +49,14 @@ type Options struct {
 	// MaxPerDir limits the number of files (non-directories) scanned per directory.
 	// If <= 0, no limit is applied. Directories are not counted against this limit.
 	MaxPerDir int
+	// MaxFiles stops traversal once this many files were delivered; zero
+	// uses the SetLimits default and < 0 means unlimited. The scan then
+	// returns ErrTruncated.
+	MaxFiles int
+	// MaxTotalBytes stops traversal before the delivered file sizes would exceed
+	// it; zero uses the SetLimits default and < 0 means unlimited. The scan
+	// then returns ErrTruncated.
+	MaxTotalBytes int64
 
 	// BypassCache forces a full scan, ignoring caches.
 	BypassCache bool
@@ -68,6 +77,23 @@ func Scan(root string, cb VisitFunc) error {
 // If CacheSubtrees is false, it uses whole-tree caching (compatible with previous behavior).
 // If CacheSubtrees is true, it uses subtree caching and can re-scan only changed prefixes.
 func ScanWithOptions(root string, opts Options, cb VisitFunc) error {
+	_, err := ScanWithStats(root, opts, cb)
+	return err
+}
+
+// ScanWithStats is ScanWithOptions that also reports how many files and bytes
+// were delivered and skipped. When MaxFiles or MaxTotalBytes is hit it stops
+// traversal and returns ErrTruncated along with the stats so far.
+func ScanWithStats(root string, opts Options, cb VisitFunc) (Stats, error) {
+	lim := newLimiter(opts)
+	if err := scanTree(root, opts, lim.wrap(cb), lim); err != nil {
+		stats, _ := lim.result()
+		return stats, err
+	}
+	return lim.result()
+}
+
+func scanTree(root string, opts Options, cb VisitFunc, lim *limiter) error {
 	resolved, err := ResolveRoot(root)
 	if err != nil {
 		return err
@@ -87,9 +113,10 @@ func ScanWithOptions(root string, opts Options, cb VisitFunc) error {
 		key := wholeCacheKey(rClean, opts)
 		if items, ok := getWholeCache(key); ok {
 			for _, it := range items {
-				if cb != nil {
-					cb(it)
+				if lim.stopped() {
+					break
 				}
+				cb(it)
 			}
 			return nil
 		}
@@ -102,6 +129,9 @@ func ScanWithOptions(root string, opts Options, cb VisitFunc) error {
 			if err != nil {
 				return nil // swallow and continue
 			}
+			if lim.stopped() {
+				return filepath.SkipAll
+			}
 			rel, _ := filepath.Rel(rClean, path)
 			rel = filepath.ToSlash(rel)
 			depth := slashCount(rel)
@@ -126,6 +156,7 @@ func ScanWithOptions(root string, opts Options, cb VisitFunc) error {
 					currentFileCount = 0
 				}
 				if opts.MaxPerDir > 0 && currentFileCount >= opts.MaxPerDir {
+					lim.skip(entrySize(d, path))
 					return nil // skip this file
 				}
 				currentFileCount++
@@ -134,20 +165,15 @@ func ScanWithOptions(root string, opts Options, cb VisitFunc) error {
 			ext := strings.ToLower(filepath.Ext(rel))
 			size := int64(0)
 			if !d.IsDir() {
-				if fi, e := d.Info(); e == nil {
-					size = fi.Size()
-				} else if fi, e2 := fsys.SafeStat(path); e2 == nil {
-					size = fi.Size()
-				}
+				size = entrySize(d, path)
 			}
 			fv := FileVisit{Path: rel, AbsPath: path, IsDir: d.IsDir(), Ext: ext, Size: size}
 			items = append(items, fv)
-			if cb != nil {
-				cb(fv)
-			}
+			cb(fv)
 			return nil
 		})
-		if err == nil {
+		// A truncated listing must not be served to later scans.
+		if err == nil && !lim.stopped() {
 			putWholeCache(key, items)
 		}
 		return err
@@ -168,7 +194,7 @@ func ScanWithOptions(root string, opts Options, cb VisitFunc) error {
 		}
 		// BypassCache means: don't use subtree cache at all; do full recursive traversal and overwrite caches.
 		// Run traversal in parallel.
-		pc := newParallelCtx()
+		pc := newParallelCtx(lim)
 		err = walkSubtreeCached(rClean, ".", 0, opts.MaxDepth, opts.MaxPerDir, ig, igKey, cb, opts.BypassCache, pc)
 		pc.wg.Wait()
 		if err == nil {
@@ -178,7 +204,7 @@ func ScanWithOptions(root string, opts Options, cb VisitFunc) error {
 	}
 
 	// Fallback: full re-scan without caching, in parallel
-	pc := newParallelCtx()
+	pc := newParallelCtx(lim)
 	err = walkSubtreeCached(rClean, ".", 0, opts.MaxDepth, opts.MaxPerDir, normalizeIgnores(opts.IgnoreDirs), "", cb, true, pc)
 	pc.wg.Wait()
 	if err == nil {
@@ -279,21 +305,21 @@ func invalidatePrefix(root, prefix string, maxDepth int, ignoreKey string) {
 // walkSubtreeCached recursively traverses from relPrefix, using subtree cache per-directory.
 // If bypass is true, the cache is ignored and overwritten.
 type parallelCtx struct {
-	wg  *sync.WaitGroup
-	sem chan struct{}
-	mu  sync.Mutex
-	err error
+	wg    *sync.WaitGroup
+	sem   chan struct{}
+	mu    sync.Mutex
+	err   error
+	limit *limiter
 }
 
-func newParallelCtx() *parallelCtx {
+func newParallelCtx(limit *limiter) *parallelCtx {
 	// Limit parallelism to a reasonable number relative to CPUs.
 	n := runtime.GOMAXPROCS(0)
 	if n < 2 {
 		n = 2
 	}
 	// Allow a bit more concurrency for IO-bound directory reads.
-	limit := n * 4
-	return &parallelCtx{wg: &sync.WaitGroup{}, sem: make(chan struct{}, limit)}
+	return &parallelCtx{wg: &sync.WaitGroup{}, sem: make(chan struct{}, n*4), limit: limit}
 }
 
 func (p *parallelCtx) setErr(e error) {
@@ -312,10 +338,22 @@ func (p *parallelCtx) getErr() error {
 	return p.err
 }
 
+// limiter returns the size limiter of the traversal; nil without one.
+func (p *parallelCtx) limiter() *limiter {
+	if p == nil {
+		return nil
+	}
+	return p.limit
+}
+
 func walkSubtreeCached(root string, relPrefix string, depth int, maxDepth int, maxPerDir int, ignores []string, ignoreKey string, cb VisitFunc, bypass bool, pc *parallelCtx) error {
 	fs := safeFS()
 	abs := joinAbs(root, relPrefix)
 	isRoot := relPrefix == "." || relPrefix == ""
+	lim := pc.limiter()
+	if lim.stopped() {
+		return nil
+	}
 
 	// Decide if this directory should be skipped due to ignore or depth.
 	if !isRoot {
@@ -373,6 +411,9 @@ func walkSubtreeCached(root string, relPrefix string, depth int, maxDepth int, m
 
 	fileCount := 0
 	for _, e := range entries {
+		if lim.stopped() {
+			break
+		}
 		childRel := joinRel(relPrefix, e.Name())
 		childAbs := filepath.Join(abs, e.Name())
 		relForExt := childRel
@@ -407,31 +448,26 @@ func walkSubtreeCached(root string, relPrefix string, depth int, maxDepth int, m
 			}
 		} else {
 			if maxPerDir > 0 && fileCount >= maxPerDir {
+				lim.skip(entrySize(e, childAbs))
 				continue
 			}
 			fileCount++
 
-			var size int64
-			if fi, e2 := e.Info(); e2 == nil {
-				size = fi.Size()
-			} else if fi, e3 := fs.SafeStat(childAbs); e3 == nil {
-				size = fi.Size()
-			}
-
 			fileNode := FileVisit{
 				Path:    filepath.Base(childRel), // relative to prefix; fixed by emitWithPrefix
 				AbsPath: childAbs,
 				IsDir:   false,
 				Ext:     ext,
-				Size:    size,
+				Size:    entrySize(e, childAbs),
 			}
 			emitWithPrefix(root, relPrefix, fileNode, cb)
 			collected = append(collected, fileNode)
 		}
 	}
 
-	// Store subtree cache for this directory (except root ".")
-	if !isRoot && !bypass {
+	// Store subtree cache for this directory (except root "."); a listing cut
+	// short by a size limit is incomplete.
+	if !isRoot && !bypass && !lim.stopped() {
 		key := subtreeKey(root, relPrefix, remain, maxPerDir, ignoreKey)
 		putSubtreeCache(key, collected)
 	}
@@ -440,6 +476,18 @@ func walkSubtreeCached(root string, relPrefix string, depth int, maxDepth int, m
 
 /* ---------------- Small helpers ---------------- */
 
+// entrySize returns the size of a directory entry, falling back to a stat of
+// abs; 0 when neither works.
+func entrySize(d fs.DirEntry, abs string) int64 {
+	if fi, err := d.Info(); err == nil {
+		return fi.Size()
+	}
+	if fi, err := safeFS().SafeStat(abs); err == nil {
+		return fi.Size()
+	}
+	return 0
+}
+
 func slashCount(rel string) int {
 	if rel == "." || rel == "" {
 		return 0
@@ -0,0 +1,129 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// mkBigTree writes 3 directories of 4 files of 100 bytes each.
+func mkBigTree(t *testing.T, name string) string {
+	t.Helper()
+	repos := setupTestReposDir(t)
+	root := ensureRepoDir(t, repos, name)
+	for d := 0; d < 3; d++ {
+		for f := 0; f < 4; f++ {
+			write(t, root, fmt.Sprintf("dir%d/f%d.txt", d, f), strings.Repeat("x", 100))
+		}
+	}
+	return root
+}
+
+func countFiles(t *testing.T, root string, opts Options) (int, Stats, error) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		files int
+	)
+	stats, err := ScanWithStats(root, opts, func(fv FileVisit) {
+		if fv.IsDir {
+			return
+		}
+		mu.Lock()
+		files++
+		mu.Unlock()
+	})
+	return files, stats, err
+}
+
+func TestScanWithStats_Limits(t *testing.T) {
+	modes := map[string]Options{
+		"parallel":      {BypassCache: true},
+		"whole-cache":   {},
+		"subtree-cache": {CacheSubtrees: true},
+	}
+	for name, base := range modes {
+		t.Run(name, func(t *testing.T) {
+			ClearCache()
+			root := mkBigTree(t, "big-"+name)
+
+			opts := base
+			opts.MaxFiles = 5
+			files, stats, err := countFiles(t, root, opts)
+			if !errors.Is(err, ErrTruncated) {
+				t.Fatalf("MaxFiles: err = %v, want ErrTruncated", err)
+			}
+			if files != 5 || stats.FilesScanned != 5 || stats.BytesScanned != 500 || !stats.Truncated || stats.FilesSkipped < 1 {
+				t.Fatalf("MaxFiles: files=%d stats=%+v", files, stats)
+			}
+
+			opts = base
+			opts.MaxTotalBytes = 250
+			files, stats, err = countFiles(t, root, opts)
+			if !errors.Is(err, ErrTruncated) {
+				t.Fatalf("MaxTotalBytes: err = %v, want ErrTruncated", err)
+			}
+			if files != 2 || stats.BytesScanned != 200 || stats.BytesSkipped < 100 || !stats.Truncated {
+				t.Fatalf("MaxTotalBytes: files=%d stats=%+v", files, stats)
+			}
+
+			// Truncated scans are not cached; an unlimited scan sees everything.
+			files, stats, err = countFiles(t, root, base)
+			if err != nil || files != 12 || stats.FilesScanned != 12 || stats.BytesScanned != 1200 || stats.Truncated {
+				t.Fatalf("unlimited: files=%d stats=%+v err=%v", files, stats, err)
+			}
+
+			// Limits apply to cache replays too.
+			opts = base
+			opts.MaxFiles = 3
+			if files, _, err := countFiles(t, root, opts); !errors.Is(err, ErrTruncated) || files != 3 {
+				t.Fatalf("cached replay: files=%d err=%v", files, err)
+			}
+		})
+	}
+}
+
+func TestScanWithStats_MaxPerDirCountsSkipped(t *testing.T) {
+	ClearCache()
+	root := mkBigTree(t, "perdir")
+	files, stats, err := countFiles(t, root, Options{BypassCache: true, MaxPerDir: 1})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if files != 3 || stats.FilesScanned != 3 || stats.FilesSkipped != 9 || stats.BytesSkipped != 900 || stats.Truncated {
+		t.Fatalf("files=%d stats=%+v", files, stats)
+	}
+}
+
+func TestSetLimitsAppliesToUnsetOptions(t *testing.T) {
+	ClearCache()
+	root := mkBigTree(t, "defaults")
+	SetLimits(4, 0)
+	t.Cleanup(func() { SetLimits(0, 0) })
+
+	files, stats, err := countFiles(t, root, Options{BypassCache: true})
+	if !errors.Is(err, ErrTruncated) || files != 4 {
+		t.Fatalf("default limit: files=%d err=%v", files, err)
+	}
+	if files, _, err := countFiles(t, root, Options{BypassCache: true, MaxFiles: -1}); err != nil || files != 12 {
+		t.Fatalf("opted out: files=%d err=%v", files, err)
+	}
+
+	var got []Truncation
+	ctx := WithTruncationHook(context.Background(), func(_ context.Context, tr Truncation) {
+		got = append(got, tr)
+	})
+	if err := ReportTruncated(ctx, root, stats, err); err != nil {
+		t.Fatalf("ReportTruncated(ErrTruncated) = %v, want nil", err)
+	}
+	if len(got) != 1 || got[0].Root != root || got[0].Stats.FilesScanned != 4 {
+		t.Fatalf("hook got %+v, want the truncated scan", got)
+	}
+	other := errors.New("boom")
+	if err := ReportTruncated(ctx, root, Stats{}, other); err != other || len(got) != 1 {
+		t.Fatalf("ReportTruncated(other) = %v, hook calls %d", err, len(got))
+	}
+}
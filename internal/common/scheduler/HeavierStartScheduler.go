@@ -58,6 +58,10 @@ type Params struct {
 	// Optional reservation integration: reserve permits before launching each chunk.
 	Broker      llm.PermitBroker
 	ReserveWith ReservePolicy
+
+	// Optional: called with the ranked candidates and resulting chunk before
+	// each launch. Nil skips all explain bookkeeping.
+	Explain ExplainFn
 }
 
 // ReservePolicy decides how many permits to reserve for a chunk.
@@ -115,6 +119,7 @@ func ScheduleHeavierStart(ctx context.Context, p Params) error {
 	type completion struct{ chunk []int }
 	completionCh := make(chan completion, n)
 	inflight := 0
+	launched := 0
 
 	// Launch as many chunks as we can under nParallel.
 	tryLaunch := func() error {
@@ -146,6 +151,14 @@ func ScheduleHeavierStart(ctx context.Context, p Params) error {
 				break
 			}
 
+			if p.Explain != nil {
+				d := explainChunk(cands, chunk, weightOf, desc, capPerChunk)
+				d.Seq = launched
+				d.Inflight = inflight
+				p.Explain(d)
+			}
+			launched++
+
 			for _, u := range chunk {
 				ready.Remove(u)
 			}
@@ -275,6 +288,12 @@ func computeNeededNodes(adj [][]int, targets map[int]struct{}) map[int]struct{}
 	return need
 }
 
+// TopoSort returns any topological order of adj (edges u -> adj[u]) or an
+// error if the graph has a cycle.
+func TopoSort(adj [][]int) ([]int, error) {
+	return toposortAny(adj)
+}
+
 // toposortAny returns any topological order or an error if a cycle exists.
 func toposortAny(adj [][]int) ([]int, error) {
 	n := len(adj)
@@ -368,16 +387,7 @@ func buildChunkDesc(
 			order = append(order, u)
 		}
 		sort.SliceStable(order, func(i, j int) bool {
-			ui, uj := order[i], order[j]
-			di, dj := desc[ui], desc[uj]
-			if di != dj {
-				return di > dj
-			}
-			wi, wj := weightOf(ui), weightOf(uj)
-			if wi != wj {
-				return wi < wj
-			}
-			return ui < uj
+			return priorityLess(order[i], order[j], desc, weightOf)
 		})
 
 		added := false
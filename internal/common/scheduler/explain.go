@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExplainFn receives one ChunkDecision right before the chunk is launched.
+// It is called from the scheduler goroutine and must not block for long.
+type ExplainFn func(ChunkDecision)
+
+// Candidate decisions reported in CandidateTrace.Decision.
+const (
+	DecisionAdmitted  = "admitted"  // ready candidate packed into the chunk
+	DecisionLookahead = "lookahead" // dependent packed after its parents were admitted
+	DecisionDeferred  = "deferred"  // ready candidate left for a later chunk
+)
+
+// Tie-break criteria reported in CandidateTrace.TieBreak.
+const (
+	TieBreakDescendants = "descendants"
+	TieBreakWeight      = "weight"
+	TieBreakNodeID      = "node_id"
+)
+
+// CandidateTrace explains where one node ranked when a chunk was built.
+type CandidateTrace struct {
+	Node        int `json:"node"`
+	Rank        int `json:"rank"`
+	Descendants int `json:"descendants"`
+	Weight      int `json:"weight"`
+	// TieBreak names the criterion that ranked this node after the previous
+	// one; empty for the first candidate.
+	TieBreak string `json:"tie_break,omitempty"`
+	Decision string `json:"decision"`
+}
+
+// ChunkDecision is the scheduler's view right before launching a chunk.
+type ChunkDecision struct {
+	At                time.Time        `json:"at"`
+	Seq               int              `json:"seq"`
+	Candidates        []CandidateTrace `json:"candidates"`
+	Chunk             []int            `json:"chunk"`
+	ChunkWeight       int              `json:"chunk_weight"`
+	RemainingCapacity int              `json:"remaining_capacity"`
+	Inflight          int              `json:"inflight"`
+}
+
+// ScheduleTrace is the JSON form of every decision made by one scheduler run.
+type ScheduleTrace struct {
+	Started     time.Time       `json:"started"`
+	CapPerChunk int             `json:"cap_per_chunk,omitempty"`
+	Chunks      []ChunkDecision `json:"chunks"`
+}
+
+// TraceRecorder collects ChunkDecisions; pass its Record method as Params.Explain.
+type TraceRecorder struct {
+	mu    sync.Mutex
+	trace ScheduleTrace
+}
+
+// NewTraceRecorder creates an empty recorder. capPerChunk is informational.
+func NewTraceRecorder(capPerChunk int) *TraceRecorder {
+	return &TraceRecorder{trace: ScheduleTrace{Started: time.Now(), CapPerChunk: capPerChunk}}
+}
+
+// Record appends d to the trace.
+func (r *TraceRecorder) Record(d ChunkDecision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trace.Chunks = append(r.trace.Chunks, d)
+}
+
+// Trace returns a copy of the collected trace.
+func (r *TraceRecorder) Trace() ScheduleTrace {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.trace
+	out.Chunks = append([]ChunkDecision(nil), r.trace.Chunks...)
+	return out
+}
+
+// explainChunk ranks cands with the same priority buildChunkDesc uses and
+// labels each with the decision that chunk reflects. Only called when
+// Params.Explain is set.
+func explainChunk(cands, chunk []int, weightOf WeightFn, desc []int, capPerChunk int) ChunkDecision {
+	order := append([]int(nil), cands...)
+	sort.SliceStable(order, func(i, j int) bool {
+		return priorityLess(order[i], order[j], desc, weightOf)
+	})
+
+	inChunk := make(map[int]struct{}, len(chunk))
+	total := 0
+	for _, u := range chunk {
+		inChunk[u] = struct{}{}
+		total += weightOf(u)
+	}
+
+	d := ChunkDecision{
+		At:                time.Now(),
+		Chunk:             append([]int(nil), chunk...),
+		ChunkWeight:       total,
+		RemainingCapacity: capPerChunk - total,
+	}
+	isCand := make(map[int]struct{}, len(order))
+	for i, u := range order {
+		isCand[u] = struct{}{}
+		ct := CandidateTrace{Node: u, Rank: i, Descendants: desc[u], Weight: weightOf(u), Decision: DecisionDeferred}
+		if _, ok := inChunk[u]; ok {
+			ct.Decision = DecisionAdmitted
+		}
+		if i > 0 {
+			ct.TieBreak = tieBreak(order[i-1], u, desc, weightOf)
+		}
+		d.Candidates = append(d.Candidates, ct)
+	}
+	for _, u := range chunk {
+		if _, ok := isCand[u]; ok {
+			continue
+		}
+		d.Candidates = append(d.Candidates, CandidateTrace{
+			Node:        u,
+			Rank:        len(d.Candidates),
+			Descendants: desc[u],
+			Weight:      weightOf(u),
+			Decision:    DecisionLookahead,
+		})
+	}
+	return d
+}
+
+// priorityLess orders nodes by descendant count (higher first), then weight
+// (lower first), then node ID (lower first).
+func priorityLess(a, b int, desc []int, weightOf WeightFn) bool {
+	if desc[a] != desc[b] {
+		return desc[a] > desc[b]
+	}
+	wa, wb := weightOf(a), weightOf(b)
+	if wa != wb {
+		return wa < wb
+	}
+	return a < b
+}
+
+// tieBreak names the first criterion that separates a from b.
+func tieBreak(a, b int, desc []int, weightOf WeightFn) string {
+	switch {
+	case desc[a] != desc[b]:
+		return TieBreakDescendants
+	case weightOf(a) != weightOf(b):
+		return TieBreakWeight
+	default:
+		return TieBreakNodeID
+	}
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestScheduleHeavierStart_ExplainRecordsTieBreaks(t *testing.T) {
+	// 0->2; 1, 3, 4 are independent. Node 0 wins on descendants, 3 beats 1 on
+	// weight, 3 beats 4 on node id; 2 only fits via lookahead after 0.
+	adj := [][]int{{2}, {}, {}, {}, {}}
+	weights := []int{2, 2, 1, 1, 1}
+	weight := func(u int) int { return weights[u] }
+	targets := map[int]struct{}{0: {}, 1: {}, 2: {}, 3: {}, 4: {}}
+
+	run := func(ctx context.Context, chunk []int) (<-chan struct{}, error) {
+		ch := make(chan struct{})
+		close(ch)
+		return ch, nil
+	}
+
+	rec := NewTraceRecorder(3)
+	err := ScheduleHeavierStart(context.Background(), Params{
+		Adj:         adj,
+		WeightOf:    WeightFn(weight),
+		Targets:     targets,
+		CapPerChunk: 3,
+		NParallel:   1,
+		Run:         ChunkRunner(run),
+		Explain:     rec.Record,
+	})
+	if err != nil {
+		t.Fatalf("ScheduleHeavierStart() error = %v", err)
+	}
+
+	trace := rec.Trace()
+	if len(trace.Chunks) == 0 {
+		t.Fatalf("expected recorded chunks")
+	}
+	first := trace.Chunks[0]
+	if len(first.Chunk) != 2 || first.Chunk[0] != 0 || first.Chunk[1] != 2 {
+		t.Fatalf("expected first chunk [0 2], got %v", first.Chunk)
+	}
+	if first.ChunkWeight != 3 || first.RemainingCapacity != 0 {
+		t.Fatalf("unexpected chunk weight/remaining: %d/%d", first.ChunkWeight, first.RemainingCapacity)
+	}
+
+	want := []CandidateTrace{
+		{Node: 0, Rank: 0, Descendants: 1, Weight: 2, Decision: DecisionAdmitted},
+		{Node: 3, Rank: 1, Descendants: 0, Weight: 1, TieBreak: TieBreakDescendants, Decision: DecisionDeferred},
+		{Node: 4, Rank: 2, Descendants: 0, Weight: 1, TieBreak: TieBreakNodeID, Decision: DecisionDeferred},
+		{Node: 1, Rank: 3, Descendants: 0, Weight: 2, TieBreak: TieBreakWeight, Decision: DecisionDeferred},
+		{Node: 2, Rank: 4, Descendants: 0, Weight: 1, Decision: DecisionLookahead},
+	}
+	if len(first.Candidates) != len(want) {
+		t.Fatalf("expected %d candidates, got %+v", len(want), first.Candidates)
+	}
+	for i, w := range want {
+		if first.Candidates[i] != w {
+			t.Fatalf("candidate %d = %+v, want %+v", i, first.Candidates[i], w)
+		}
+	}
+
+	for i, c := range trace.Chunks {
+		if c.Seq != i {
+			t.Fatalf("chunk %d has seq %d", i, c.Seq)
+		}
+	}
+	if _, err := json.Marshal(trace); err != nil {
+		t.Fatalf("trace should be JSON-serializable: %v", err)
+	}
+}
@@ -0,0 +1,482 @@
+package snippet
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+)
+
+// ProviderHeuristic is the Provider value of snippets found by
+// HeuristicProvider.
+const ProviderHeuristic = "heuristic"
+
+// DefaultHeuristicSnippetTokens caps one heuristic snippet when
+// HeuristicProvider.MaxSnippetTokens is unset.
+const DefaultHeuristicSnippetTokens = 800
+
+// maxBodyLines bounds how far a declaration body is scanned.
+const maxBodyLines = 400
+
+const truncatedMarker = "... (truncated)\n"
+
+// HeuristicProvider finds declarations by scanning source files for
+// language-specific patterns (func NAME, def NAME, class NAME, ...) and takes
+// the brace- or indentation-balanced body. It needs no prior analysis, so it
+// serves files the codebase phases have not indexed; results are marked
+// Approximate. A seed name may be qualified as Outer.Name to pick a method of
+// one type; every other declaration of the name (overloads, methods of
+// several types, nested functions) is returned in file order.
+type HeuristicProvider struct {
+	FS *safeio.SafeFS
+	// MaxSnippetTokens caps each snippet; longer bodies are truncated.
+	MaxSnippetTokens int
+}
+
+// NewHeuristicProvider returns a HeuristicProvider reading files from fs.
+func NewHeuristicProvider(fs *safeio.SafeFS) *HeuristicProvider {
+	return &HeuristicProvider{FS: fs}
+}
+
+// Collect returns the declarations of each seed until MaxTokens is reached.
+// Seeds whose file cannot be read or has an unknown language yield nothing.
+func (p *HeuristicProvider) Collect(ctx context.Context, q Query) ([]RelatedSnippet, error) {
+	countFn := q.CountTokens
+	if countFn == nil {
+		countFn = func(s string) int { return len([]rune(s)) }
+	}
+	snippetCap := p.MaxSnippetTokens
+	if snippetCap <= 0 {
+		snippetCap = DefaultHeuristicSnippetTokens
+	}
+
+	var results []RelatedSnippet
+	used := 0
+	for _, seed := range q.Seeds {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if p.FS == nil {
+			break
+		}
+		src, err := p.FS.SafeReadFile(seed.Path)
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+		for _, span := range findDeclarations(seed.Path, lines, seed.Name) {
+			code := capLines(lines[span[0]:span[1]+1], snippetCap, countFn)
+			toks := countFn(code)
+			if q.MaxTokens > 0 && used+toks > q.MaxTokens {
+				return results, nil
+			}
+			used += toks
+			results = append(results, RelatedSnippet{
+				Identifier: seed,
+				Signal: artifact.IdentifierSignal{
+					Name:  seed.Name,
+					Lines: [2]int{span[0] + 1, span[1] + 1},
+				},
+				Code:        code,
+				Tokens:      toks,
+				Provider:    ProviderHeuristic,
+				Approximate: true,
+			})
+		}
+	}
+	return results, nil
+}
+
+// WithFallback returns a Provider that serves each query from primary and
+// asks fallback only for the seeds primary returned nothing for, within the
+// tokens primary left over.
+func WithFallback(primary, fallback Provider) Provider {
+	return fallbackProvider{primary: primary, fallback: fallback}
+}
+
+type fallbackProvider struct {
+	primary  Provider
+	fallback Provider
+}
+
+func (f fallbackProvider) Collect(ctx context.Context, q Query) ([]RelatedSnippet, error) {
+	var results []RelatedSnippet
+	if f.primary != nil {
+		var err error
+		if results, err = f.primary.Collect(ctx, q); err != nil {
+			return results, err
+		}
+	}
+	if f.fallback == nil {
+		return results, nil
+	}
+	found := make(map[Identifier]bool, len(results))
+	used := 0
+	for _, r := range results {
+		found[r.Identifier] = true
+		used += r.Tokens
+	}
+	var missing []Identifier
+	for _, s := range q.Seeds {
+		if !found[s] {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+	rest := q
+	rest.Seeds = missing
+	if q.MaxTokens > 0 {
+		if rest.MaxTokens = q.MaxTokens - used; rest.MaxTokens <= 0 {
+			return results, nil
+		}
+	}
+	more, err := f.fallback.Collect(ctx, rest)
+	return append(results, more...), err
+}
+
+// capLines joins lines, dropping trailing ones once the snippet and the
+// truncation marker would exceed maxTokens. The declaration line is always
+// kept.
+func capLines(lines []string, maxTokens int, countFn func(string) int) string {
+	code := ""
+	for i, line := range lines {
+		next := code + line + "\n"
+		limit := next
+		if i < len(lines)-1 {
+			limit += truncatedMarker
+		}
+		if i > 0 && countFn(limit) > maxTokens {
+			return code + truncatedMarker
+		}
+		code = next
+	}
+	return code
+}
+
+// ---------------------------------------------------------------------------
+// Language patterns
+// ---------------------------------------------------------------------------
+
+type bodyStyle int
+
+const (
+	braceBody bodyStyle = iota
+	indentBody
+)
+
+type language struct {
+	body bodyStyle
+	// decls returns the patterns of a declaration of name.
+	decls func(name string) []*regexp.Regexp
+	// member returns the patterns of name declared on owner when the
+	// language spells that outside the owner's body (Go methods).
+	member func(owner, name string) []*regexp.Regexp
+	// check rejects pattern matches that are uses rather than declarations.
+	check func(line string) bool
+}
+
+var languages = map[string]language{
+	".go":  goLanguage,
+	".py":  pythonLanguage,
+	".pyi": pythonLanguage,
+	".ts":  tsLanguage, ".tsx": tsLanguage, ".mts": tsLanguage, ".cts": tsLanguage,
+	".js": tsLanguage, ".jsx": tsLanguage, ".mjs": tsLanguage, ".cjs": tsLanguage,
+	".java": braceLanguage, ".kt": braceLanguage, ".kts": braceLanguage, ".scala": braceLanguage,
+	".rs": braceLanguage, ".c": braceLanguage, ".h": braceLanguage, ".cc": braceLanguage,
+	".cpp": braceLanguage, ".hpp": braceLanguage, ".cs": braceLanguage, ".swift": braceLanguage,
+	".php": braceLanguage, ".dart": braceLanguage,
+}
+
+func compile(patterns ...string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		out[i] = regexp.MustCompile(p)
+	}
+	return out
+}
+
+var goLanguage = language{
+	body: braceBody,
+	decls: func(name string) []*regexp.Regexp {
+		n := regexp.QuoteMeta(name)
+		return compile(
+			`^\s*func\s+(?:\([^)]*\)\s*)?`+n+`\s*[\[(]`,
+			`^\s*type\s+`+n+`\b`,
+			`^\s*(?:const|var)\s+`+n+`\b`,
+		)
+	},
+	member: func(owner, name string) []*regexp.Regexp {
+		return compile(`^\s*func\s+\(\s*(?:\w+\s+)?\*?` + regexp.QuoteMeta(owner) + `(?:\[[^\]]*\])?\s*\)\s*` + regexp.QuoteMeta(name) + `\s*[\[(]`)
+	},
+}
+
+var pythonLanguage = language{
+	body: indentBody,
+	decls: func(name string) []*regexp.Regexp {
+		n := regexp.QuoteMeta(name)
+		return compile(
+			`^\s*(?:async\s+)?def\s+`+n+`\s*[\[(]`,
+			`^\s*class\s+`+n+`\s*[\[(:]`,
+			`^`+n+`\s*(?::[^=]+)?=[^=]`,
+		)
+	},
+}
+
+const tsModifiers = `(?:(?:export|default|declare|abstract|public|private|protected|static|async|readonly|override|get|set)\s+)*`
+
+var tsLanguage = language{
+	body: braceBody,
+	decls: func(name string) []*regexp.Regexp {
+		n := regexp.QuoteMeta(name)
+		return compile(
+			`^\s*`+tsModifiers+`function\s*\*?\s*`+n+`\s*[<(]`,
+			`^\s*`+tsModifiers+`(?:class|interface|enum|namespace|module)\s+`+n+`\b`,
+			`^\s*`+tsModifiers+`type\s+`+n+`\b.*=`,
+			`^\s*`+tsModifiers+`(?:const|let|var)\s+`+n+`\s*[:=]`,
+			// Methods and method signatures.
+			`^\s*`+tsModifiers+`\*?`+n+`\s*\??\s*(?:<[^>]*>)?\s*\(.*(?:\{\s*$|\)\s*:)`,
+			// Class fields holding arrow functions.
+			`^\s*`+tsModifiers+n+`\s*(?::[^=]+)?=\s*(?:async\s+)?(?:\([^)]*\)|\w+)\s*(?::[^=]+)?=>`,
+		)
+	},
+	check: notControlFlow,
+}
+
+var braceLanguage = language{
+	body: braceBody,
+	decls: func(name string) []*regexp.Regexp {
+		n := regexp.QuoteMeta(name)
+		return compile(
+			`\b(?:fn|fun|func|function|def|class|struct|enum|interface|trait|impl|object|record|union|typedef)\s+`+n+`\b`,
+			// Definitions (not prototypes or calls) of C-family functions.
+			`^\s*(?:[\w<>\[\],.?*&:]+\s+)+[*&]*`+n+`\s*\([^;]*$`,
+		)
+	},
+	check: notControlFlow,
+}
+
+var controlFlow = regexp.MustCompile(`^\s*(?:return|if|else|for|while|switch|case|throw|new|await|yield|do)\b`)
+
+func notControlFlow(line string) bool { return !controlFlow.MatchString(line) }
+
+// findDeclarations returns the [start, end] line spans (0-based, inclusive)
+// of the declarations of name in the file at path.
+func findDeclarations(path string, lines []string, name string) [][2]int {
+	lang, ok := languages[strings.ToLower(filepath.Ext(path))]
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return nil
+	}
+	owner, member, qualified := strings.Cut(name, ".")
+	if !qualified {
+		return lang.find(lines, 0, len(lines), name)
+	}
+	if lang.member != nil {
+		if spans := lang.match(lines, 0, len(lines), lang.member(owner, member)); len(spans) > 0 {
+			return spans
+		}
+	}
+	var out [][2]int
+	for _, outer := range lang.find(lines, 0, len(lines), owner) {
+		out = append(out, lang.find(lines, outer[0]+1, outer[1]+1, member)...)
+	}
+	return out
+}
+
+func (l language) find(lines []string, from, to int, name string) [][2]int {
+	return l.match(lines, from, to, l.decls(name))
+}
+
+func (l language) match(lines []string, from, to int, patterns []*regexp.Regexp) [][2]int {
+	var out [][2]int
+	for i := from; i < to; i++ {
+		line := lines[i]
+		if isComment(line) || (l.check != nil && !l.check(line)) {
+			continue
+		}
+		for _, re := range patterns {
+			if !re.MatchString(line) {
+				continue
+			}
+			if l.body == indentBody {
+				out = append(out, [2]int{decoratorStart(lines, i), indentEnd(lines, i)})
+			} else {
+				out = append(out, [2]int{i, braceEnd(lines, i)})
+			}
+			break
+		}
+	}
+	return out
+}
+
+func isComment(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "//") || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "*") || strings.HasPrefix(t, "/*")
+}
+
+// braceEnd returns the line closing the body opened at or after start. A
+// declaration that ends before any brace opens (a signature ending in ';',
+// `const x = 1`) ends on its last line.
+func braceEnd(lines []string, start int) int {
+	var (
+		depth, parens int
+		opened        bool
+		blockComment  bool
+		rawString     bool // Go and JS backtick strings span lines
+	)
+	last := min(len(lines), start+maxBodyLines) - 1
+	for i := start; i <= last; i++ {
+		line := lines[i]
+		quote := byte(0)
+	scan:
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			switch {
+			case blockComment:
+				if c == '*' && j+1 < len(line) && line[j+1] == '/' {
+					blockComment = false
+					j++
+				}
+			case rawString:
+				if c == '`' {
+					rawString = false
+				}
+			case quote != 0:
+				if c == '\\' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '/' && j+1 < len(line) && line[j+1] == '/':
+				break scan
+			case c == '/' && j+1 < len(line) && line[j+1] == '*':
+				blockComment = true
+				j++
+			case c == '`':
+				rawString = true
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '(' || c == '[':
+				parens++
+			case c == ')' || c == ']':
+				parens--
+			case c == '{':
+				depth++
+				opened = true
+			case c == '}':
+				depth--
+				if opened && depth <= 0 {
+					return i
+				}
+			case c == ';' && !opened && parens <= 0:
+				return i
+			}
+		}
+		if !opened && parens <= 0 && !rawString && !blockComment && !continues(line, lines, i) {
+			return i
+		}
+	}
+	return last
+}
+
+// continues reports whether a declaration without a body yet carries on
+// past line i.
+func continues(line string, lines []string, i int) bool {
+	t := strings.TrimSpace(line)
+	for _, suffix := range []string{",", "(", "=", "=>", ":", "|", "&", "+", "->"} {
+		if strings.HasSuffix(t, suffix) {
+			return true
+		}
+	}
+	if i+1 < len(lines) {
+		next := strings.TrimSpace(lines[i+1])
+		for _, prefix := range []string{"{", "|", "&", ".", "?", "=>", "where", "throws", "extends", "implements"} {
+			if strings.HasPrefix(next, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// indentEnd returns the last line of the indentation-delimited block opened
+// at start, after any signature that spans lines.
+func indentEnd(lines []string, start int) int {
+	base := indentOf(lines[start])
+	last := min(len(lines), start+maxBodyLines) - 1
+	i, parens := start, 0
+	for ; i <= last; i++ {
+		parens += bracketDelta(lines[i])
+		if parens <= 0 {
+			break
+		}
+	}
+	end := min(i, last)
+	for j := end + 1; j <= last; j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if indentOf(lines[j]) <= base {
+			break
+		}
+		end = j
+	}
+	return end
+}
+
+// decoratorStart extends a Python declaration upwards over its decorators.
+func decoratorStart(lines []string, start int) int {
+	base := indentOf(lines[start])
+	for start > 0 {
+		prev := lines[start-1]
+		if !strings.HasPrefix(strings.TrimSpace(prev), "@") || indentOf(prev) != base {
+			break
+		}
+		start--
+	}
+	return start
+}
+
+func indentOf(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// bracketDelta counts opened minus closed brackets outside strings and
+// comments.
+func bracketDelta(line string) int {
+	d := 0
+	quote := rune(0)
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '#':
+			return d
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			d++
+		case r == ')' || r == ']' || r == '}':
+			d--
+		}
+	}
+	return d
+}
@@ -27,9 +27,12 @@ type RelatedSnippet struct {
 	Code       string
 	Tokens     int
 	Provider   string // e.g. "c4"
+	// Approximate marks snippets whose bounds were guessed from line
+	// patterns (ProviderHeuristic) rather than indexed symbol metadata.
+	Approximate bool
 }
 
 // Provider resolves identifiers to code snippets, possibly traversing dependencies.
 type Provider interface {
 	Collect(ctx context.Context, q Query) ([]RelatedSnippet, error)
-}
\ No newline at end of file
+}
@@ -0,0 +1,155 @@
+package snippet
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"insightify/internal/common/safeio"
+)
+
+func newFixtureProvider(t *testing.T) *HeuristicProvider {
+	t.Helper()
+	fs, err := safeio.NewSafeFS("testdata")
+	if err != nil {
+		t.Fatalf("NewSafeFS() error = %v", err)
+	}
+	return NewHeuristicProvider(fs)
+}
+
+// collectLines returns the [start, end] lines of each snippet of path#name.
+func collectLines(t *testing.T, p Provider, path, name string) ([][2]int, []RelatedSnippet) {
+	t.Helper()
+	out, err := p.Collect(context.Background(), Query{Seeds: []Identifier{{Path: path, Name: name}}})
+	if err != nil {
+		t.Fatalf("Collect(%s#%s) error = %v", path, name, err)
+	}
+	spans := make([][2]int, 0, len(out))
+	for _, s := range out {
+		if s.Provider != ProviderHeuristic || !s.Approximate {
+			t.Fatalf("%s#%s: snippet not marked approximate: %+v", path, name, s)
+		}
+		spans = append(spans, s.Signal.Lines)
+	}
+	return spans, out
+}
+
+func TestHeuristicProviderFindsDeclarations(t *testing.T) {
+	p := newFixtureProvider(t)
+	cases := []struct {
+		path, name string
+		want       [][2]int
+	}{
+		// Go: methods of two receivers share a name; strings hide braces.
+		{"greeter.go", "Greet", [][2]int{{10, 15}, {19, 19}}},
+		{"greeter.go", "Loud.Greet", [][2]int{{19, 19}}},
+		{"greeter.go", "Greeter.Greet", [][2]int{{10, 15}}},
+		{"greeter.go", "Greeter", [][2]int{{6, 8}}},
+		{"greeter.go", "Join", [][2]int{{21, 25}}},
+		{"greeter.go", "Limit", [][2]int{{27, 27}}},
+		{"greeter.go", "table", [][2]int{{29, 31}}},
+		// Python: a method and a function named get, a nested def, a
+		// decorated method and a module constant.
+		{"cache.py", "get", [][2]int{{10, 14}, {21, 22}}},
+		{"cache.py", "Cache.get", [][2]int{{10, 14}}},
+		{"cache.py", "normalize", [][2]int{{11, 12}}},
+		{"cache.py", "size", [][2]int{{16, 18}}},
+		{"cache.py", "Cache", [][2]int{{4, 18}}},
+		{"cache.py", "TIMEOUT", [][2]int{{25, 25}}},
+		// TypeScript: overload signatures, an interface member and a
+		// method with the same name, a nested arrow and a class field.
+		{"shapes.ts", "parse", [][2]int{{5, 5}, {6, 6}, {7, 12}}},
+		{"shapes.ts", "area", [][2]int{{2, 2}, {17, 22}}},
+		{"shapes.ts", "Circle.area", [][2]int{{17, 22}}},
+		{"shapes.ts", "inner", [][2]int{{18, 20}}},
+		{"shapes.ts", "scale", [][2]int{{24, 26}}},
+		{"shapes.ts", "helper", [][2]int{{29, 29}}},
+		{"shapes.ts", "Circle", [][2]int{{14, 27}}},
+		// Unknown names, languages and files yield nothing.
+		{"shapes.ts", "missing", nil},
+		{"notes.txt", "parse", nil},
+		{"absent.go", "Greet", nil},
+	}
+	for _, tc := range cases {
+		got, _ := collectLines(t, p, tc.path, tc.name)
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s#%s lines = %v, want %v", tc.path, tc.name, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("%s#%s lines = %v, want %v", tc.path, tc.name, got, tc.want)
+			}
+		}
+	}
+
+	_, snips := collectLines(t, p, "cache.py", "size")
+	if !strings.HasPrefix(snips[0].Code, "    @functools.lru_cache\n    def size(self):\n") {
+		t.Fatalf("decorated snippet = %q", snips[0].Code)
+	}
+}
+
+func TestHeuristicProviderCapsTokens(t *testing.T) {
+	p := newFixtureProvider(t)
+	p.MaxSnippetTokens = 80
+	_, snips := collectLines(t, p, "greeter.go", "Greeter.Greet")
+	code := snips[0].Code
+	if !strings.HasPrefix(code, "func (g *Greeter) Greet() string {\n") || !strings.HasSuffix(code, truncatedMarker) || snips[0].Tokens > 80 || strings.Contains(code, "return \"hello \"") {
+		t.Fatalf("capped snippet (%d tokens) = %q", snips[0].Tokens, code)
+	}
+
+	// The query budget stops collection between snippets.
+	out, err := newFixtureProvider(t).Collect(context.Background(), Query{
+		Seeds:     []Identifier{{Path: "shapes.ts", Name: "parse"}},
+		MaxTokens: 100,
+	})
+	if err != nil || len(out) != 2 {
+		t.Fatalf("Collect() with budget = %d snippets, %v; want the two signatures", len(out), err)
+	}
+}
+
+type stubProvider struct {
+	queries []Query
+	serve   map[Identifier]string
+}
+
+func (s *stubProvider) Collect(_ context.Context, q Query) ([]RelatedSnippet, error) {
+	s.queries = append(s.queries, q)
+	var out []RelatedSnippet
+	for _, seed := range q.Seeds {
+		if code, ok := s.serve[seed]; ok {
+			out = append(out, RelatedSnippet{Identifier: seed, Code: code, Tokens: len(code), Provider: "codeSymbols"})
+		}
+	}
+	return out, nil
+}
+
+func TestWithFallbackAsksHeuristicOnlyForMissingSeeds(t *testing.T) {
+	indexed := Identifier{Path: "greeter.go", Name: "Join"}
+	unindexed := Identifier{Path: "cache.py", Name: "Cache.get"}
+	primary := &stubProvider{serve: map[Identifier]string{indexed: "func Join() {}"}}
+	p := WithFallback(primary, newFixtureProvider(t))
+
+	out, err := p.Collect(context.Background(), Query{Seeds: []Identifier{indexed, unindexed}})
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(out) != 2 || out[0].Provider != "codeSymbols" || out[0].Approximate {
+		t.Fatalf("indexed snippet = %+v", out)
+	}
+	if out[1].Identifier != unindexed || out[1].Provider != ProviderHeuristic || !out[1].Approximate || !strings.Contains(out[1].Code, "normalize(key)") {
+		t.Fatalf("fallback snippet = %+v", out[1])
+	}
+
+	// The fallback gets only what the primary left of the budget.
+	fallback := &stubProvider{}
+	p = WithFallback(primary, fallback)
+	if _, err := p.Collect(context.Background(), Query{Seeds: []Identifier{indexed, unindexed}, MaxTokens: 20}); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(fallback.queries) != 1 || fallback.queries[0].MaxTokens != 20-len("func Join() {}") || len(fallback.queries[0].Seeds) != 1 || fallback.queries[0].Seeds[0] != unindexed {
+		t.Fatalf("fallback query = %+v", fallback.queries)
+	}
+	if _, err := p.Collect(context.Background(), Query{Seeds: []Identifier{indexed}}); err != nil || len(fallback.queries) != 1 {
+		t.Fatalf("fallback asked although every seed was served: %+v", fallback.queries)
+	}
+}
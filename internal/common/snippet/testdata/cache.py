@@ -0,0 +1,25 @@
+import functools
+
+
+class Cache:
+    """A tiny cache."""
+
+    def __init__(self):
+        self.items = {}
+
+    def get(self, key):
+        def normalize(k):
+            return k.strip()
+
+        return self.items.get(normalize(key))
+
+    @functools.lru_cache
+    def size(self):
+        return len(self.items)
+
+
+def get(key):
+    return key
+
+
+TIMEOUT = 30
@@ -0,0 +1,31 @@
+package fixture
+
+import "strings"
+
+// Greeter greets.
+type Greeter struct {
+	name string
+}
+
+func (g *Greeter) Greet() string {
+	if g.name == "" {
+		return "hello"
+	}
+	return "hello " + g.name
+}
+
+type Loud struct{}
+
+func (Loud) Greet() string { return "HELLO" }
+
+func Join(parts ...string) string {
+	s := strings.Join(parts, ",")
+	brace := "}" // a brace inside a string
+	return s + brace
+}
+
+const Limit = 10
+
+var table = map[string]int{
+	"a": 1,
+}
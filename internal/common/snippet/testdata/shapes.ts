@@ -0,0 +1,29 @@
+export interface Shape {
+  area(): number;
+}
+
+export function parse(input: string): number;
+export function parse(input: number): number;
+export function parse(input: string | number): number {
+  if (typeof input === "number") {
+    return input;
+  }
+  return Number.parseFloat(input);
+}
+
+export class Circle implements Shape {
+  constructor(private readonly r: number) {}
+
+  area(): number {
+    const inner = () => {
+      return Math.PI * this.r * this.r;
+    };
+    return inner();
+  }
+
+  scale = (k: number): Circle => {
+    return new Circle(this.r * k);
+  };
+}
+
+const helper = (x: number) => x * 2;
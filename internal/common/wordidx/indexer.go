@@ -24,6 +24,8 @@ Rules:
 - Ignore numbers and symbols; quotes are delimiters.
 - Positions are 1-based (line, column counted by runes).
 - Aggregated index builds asynchronously via scan.ScanParallel; Find blocks until completion.
+- Non-exact match modes (see FindMode) need the lowercase and sub-token maps
+  enabled with Builder.Fuzzy.
 */
 
 // Word is a collected token and its position within a file.
@@ -125,14 +127,26 @@ type PosRef struct {
 	Line     int
 }
 
+const (
+	// DefaultMaxFileBytes is how much of each file is indexed unless
+	// Builder.FileLimits says otherwise.
+	DefaultMaxFileBytes = 1 << 20
+	// DefaultSkipFileBytes is the size above which files are not indexed
+	// unless Builder.FileLimits says otherwise.
+	DefaultSkipFileBytes = 16 << 20
+)
+
 // Builder allows fluent configuration of an AggIndex run.
 type Builder struct {
-	roots    []string
-	workers  int
-	allowExt []string
-	opts     scan.Options
-	err      error
-	fs       *safeio.SafeFS
+	roots     []string
+	workers   int
+	allowExt  []string
+	opts      scan.Options
+	err       error
+	fs        *safeio.SafeFS
+	fuzzy     bool
+	maxBytes  int64
+	skipBytes int64
 }
 
 // New returns a Builder with sensible defaults (cache bypass and common ignores).
@@ -142,6 +156,8 @@ func New() *Builder {
 			IgnoreDirs:  []string{".git", "node_modules", "vendor"},
 			BypassCache: true,
 		},
+		maxBytes:  DefaultMaxFileBytes,
+		skipBytes: DefaultSkipFileBytes,
 	}
 }
 
@@ -240,6 +256,27 @@ func (b *Builder) FS(fs *safeio.SafeFS) *Builder {
 	return b
 }
 
+// Fuzzy builds the lowercase and identifier sub-token maps used by the
+// non-exact match modes of FindMode. It is off by default to save memory.
+func (b *Builder) Fuzzy(enabled bool) *Builder {
+	if b == nil {
+		return b
+	}
+	b.fuzzy = enabled
+	return b
+}
+
+// FileLimits indexes at most maxBytes of each file and skips files larger
+// than skipBytes; see Skipped. A limit <= 0 is off.
+func (b *Builder) FileLimits(maxBytes, skipBytes int64) *Builder {
+	if b == nil {
+		return b
+	}
+	b.maxBytes = maxBytes
+	b.skipBytes = skipBytes
+	return b
+}
+
 // Start kicks off indexing with the configured settings and returns the AggIndex.
 func (b *Builder) Start(ctx context.Context) *AggIndex {
 	if b == nil {
@@ -271,6 +308,10 @@ func (b *Builder) Start(ctx context.Context) *AggIndex {
 	if b.fs != nil {
 		agg.fs = b.fs
 	}
+	if b.fuzzy {
+		agg.EnableFuzzy()
+	}
+	agg.maxBytes, agg.skipBytes = b.maxBytes, b.skipBytes
 	agg.StartFromScans(ctx, roots, b.opts, b.workers, filter)
 	return agg
 }
@@ -281,6 +322,8 @@ type AggIndex struct {
 	mu       sync.RWMutex
 	byHash   map[uint64][]PosRef // hash(word) -> postings across files
 	files    []FileIndex
+	skipped  []string   // files over skipBytes
+	fold     *foldIndex // nil unless EnableFuzzy was called
 	doneOnce sync.Once
 	doneCh   chan struct{}
 
@@ -289,6 +332,10 @@ type AggIndex struct {
 
 	fs   *safeio.SafeFS
 	fsMu sync.Mutex
+
+	// maxBytes and skipBytes bound file reads; see Builder.FileLimits.
+	maxBytes  int64
+	skipBytes int64
 }
 
 // NewAgg creates an empty aggregator. Prefer Builder for fluent setup.
@@ -348,7 +395,7 @@ func (a *AggIndex) StartFromScans(ctx context.Context, roots []string, sopts sca
 				root = "."
 			}
 			root = filepath.Clean(root)
-			err := scan.ScanWithOptions(root, sopts, func(fv scan.FileVisit) {
+			stats, err := scan.ScanWithStats(root, sopts, func(fv scan.FileVisit) {
 				if fv.IsDir {
 					return
 				}
@@ -361,7 +408,8 @@ func (a *AggIndex) StartFromScans(ctx context.Context, roots []string, sopts sca
 				case tasks <- fv.AbsPath:
 				}
 			})
-			if err != nil {
+			// Index what a truncated scan reached rather than nothing.
+			if err := scan.ReportTruncated(ctx, root, stats, err); err != nil {
 				a.setErr(err)
 				return
 			}
@@ -456,6 +504,15 @@ func (a *AggIndex) Files(ctx context.Context) []FileIndex {
 	return cp
 }
 
+// Skipped returns the files left out of the index for exceeding the skip
+// size of Builder.FileLimits.
+func (a *AggIndex) Skipped(ctx context.Context) []string {
+	_ = a.Wait(ctx)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]string(nil), a.skipped...)
+}
+
 /* -------- internal helpers -------- */
 
 func (a *AggIndex) indexOne(path string) {
@@ -463,7 +520,13 @@ func (a *AggIndex) indexOne(path string) {
 	if fs == nil {
 		return
 	}
-	data, err := fs.SafeReadFile(path)
+	data, _, err := fs.SafeReadFileLimited(path, a.maxBytes, a.skipBytes)
+	if errors.Is(err, safeio.ErrFileTooLarge) {
+		a.mu.Lock()
+		a.skipped = append(a.skipped, path)
+		a.mu.Unlock()
+		return
+	}
 	if err != nil {
 		a.setErr(fmt.Errorf("wordidx: read %s: %w", path, err))
 		return
@@ -477,6 +540,7 @@ func (a *AggIndex) indexOne(path string) {
 		_, _ = h.Write([]byte(w.Text))
 		key := h.Sum64()
 		a.byHash[key] = append(a.byHash[key], PosRef{FilePath: path, Line: w.Line})
+		a.fold.add(w.Text, PosRef{FilePath: path, Line: w.Line})
 	}
 	a.mu.Unlock()
 }
@@ -0,0 +1,188 @@
+package wordidx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// MatchMode selects how FindMode compares the query with indexed words.
+type MatchMode string
+
+const (
+	// MatchExact matches the word as written (same as Find).
+	MatchExact MatchMode = "exact"
+	// MatchCaseInsensitive matches whole words ignoring case.
+	MatchCaseInsensitive MatchMode = "case_insensitive"
+	// MatchPrefix matches words starting with the query, ignoring case.
+	MatchPrefix MatchMode = "prefix"
+	// MatchIdentifier splits camelCase and snake_case identifiers into
+	// sub-tokens: "authservice", "auth_service" and "AuthService" all match
+	// each other, and "service" matches any of them.
+	MatchIdentifier MatchMode = "identifier"
+)
+
+// ErrFuzzyDisabled is returned by FindMode for non-exact modes when the index
+// was built without Builder.Fuzzy.
+var ErrFuzzyDisabled = errors.New("wordidx: fuzzy maps not built; enable Builder.Fuzzy")
+
+// ParseMatchMode parses a mode name as used by tool and directive arguments.
+func ParseMatchMode(s string) (MatchMode, error) {
+	switch m := MatchMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case MatchExact, MatchCaseInsensitive, MatchPrefix, MatchIdentifier:
+		return m, nil
+	default:
+		return "", fmt.Errorf("wordidx: unknown match mode %q (want exact, case_insensitive, prefix or identifier)", s)
+	}
+}
+
+// SplitIdentifier returns the lowercase sub-tokens of an identifier, split at
+// '_' and at camelCase boundaries; acronyms stay together ("HTTPServer" ->
+// "http", "server") and digits stick to the preceding token.
+func SplitIdentifier(word string) []string {
+	rs := []rune(word)
+	var (
+		parts []string
+		cur   []rune
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			parts = append(parts, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	for i, r := range rs {
+		if r == '_' {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return parts
+}
+
+// identKeys lists the identifier-mode keys of word: its joined sub-tokens and
+// each sub-token, without duplicates.
+func identKeys(word string) []string {
+	parts := SplitIdentifier(word)
+	if len(parts) == 0 {
+		return nil
+	}
+	keys := []string{strings.Join(parts, "")}
+	for _, p := range parts {
+		dup := false
+		for _, k := range keys {
+			if k == p {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			keys = append(keys, p)
+		}
+	}
+	return keys
+}
+
+// foldIndex holds the maps behind the non-exact match modes. Writes happen
+// under AggIndex.mu; a nil foldIndex ignores adds.
+type foldIndex struct {
+	byLower map[string][]PosRef
+	byIdent map[string][]PosRef
+
+	vocabOnce sync.Once
+	vocab     []string // sorted byLower keys, built on the first prefix query
+}
+
+func (f *foldIndex) add(word string, ref PosRef) {
+	if f == nil {
+		return
+	}
+	lower := strings.ToLower(word)
+	f.byLower[lower] = append(f.byLower[lower], ref)
+	for _, k := range identKeys(word) {
+		f.byIdent[k] = append(f.byIdent[k], ref)
+	}
+}
+
+// EnableFuzzy makes indexing also build the maps used by non-exact modes.
+// Call it before StartFromScan, StartFromScans or StartFromPaths.
+func (a *AggIndex) EnableFuzzy() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.fold == nil {
+		a.fold = &foldIndex{
+			byLower: make(map[string][]PosRef),
+			byIdent: make(map[string][]PosRef),
+		}
+	}
+}
+
+// FindMode waits until indexing is completed, then returns postings of word
+// under mode. Exact, case-insensitive and identifier lookups are single map
+// reads; prefix lookups binary-search the sorted vocabulary.
+func (a *AggIndex) FindMode(ctx context.Context, word string, mode MatchMode) ([]PosRef, error) {
+	if err := a.Wait(ctx); err != nil {
+		return nil, err
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var hits []PosRef
+	switch mode {
+	case MatchExact, "":
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(word))
+		hits = a.byHash[h.Sum64()]
+	case MatchCaseInsensitive, MatchPrefix, MatchIdentifier:
+		if a.fold == nil {
+			return nil, ErrFuzzyDisabled
+		}
+		switch mode {
+		case MatchCaseInsensitive:
+			hits = a.fold.byLower[strings.ToLower(word)]
+		case MatchPrefix:
+			return a.fold.prefix(strings.ToLower(word)), nil
+		default:
+			hits = a.fold.byIdent[strings.Join(SplitIdentifier(word), "")]
+		}
+	default:
+		return nil, fmt.Errorf("wordidx: unknown match mode %q", mode)
+	}
+	out := make([]PosRef, len(hits))
+	copy(out, hits)
+	return out, nil
+}
+
+// prefix collects postings of every word starting with lower. The caller
+// holds AggIndex.mu for reading and indexing has completed.
+func (f *foldIndex) prefix(lower string) []PosRef {
+	if lower == "" {
+		return nil
+	}
+	f.vocabOnce.Do(func() {
+		f.vocab = make([]string, 0, len(f.byLower))
+		for w := range f.byLower {
+			f.vocab = append(f.vocab, w)
+		}
+		sort.Strings(f.vocab)
+	})
+	var out []PosRef
+	for i := sort.SearchStrings(f.vocab, lower); i < len(f.vocab) && strings.HasPrefix(f.vocab[i], lower); i++ {
+		out = append(out, f.byLower[f.vocab[i]]...)
+	}
+	return out
+}
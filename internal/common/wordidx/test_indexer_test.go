@@ -183,3 +183,40 @@ func TestBuilder_MultiRoots(t *testing.T) {
 		t.Fatalf("expected to see c.txt from second root, refs=%v", refs)
 	}
 }
+
+func TestAggIndex_FileLimits(t *testing.T) {
+	base := setupWordidxRepos(t)
+	root := mkrepo(t, base)
+	big := strings.Repeat("filler ", 20) + "tailword\n"
+	if err := os.WriteFile(filepath.Join(root, "big.txt"), []byte(big), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	huge := strings.Repeat("hugeword ", 100)
+	if err := os.WriteFile(filepath.Join(root, "huge.txt"), []byte(huge), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	agg := New().
+		Root(root).
+		Allow("txt").
+		FileLimits(64, 512).
+		Start(context.Background())
+
+	ctx := context.Background()
+	if err := agg.Wait(ctx); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if got := agg.Find(ctx, "filler"); len(got) == 0 {
+		t.Fatalf("expected the head of big.txt to be indexed")
+	}
+	if got := agg.Find(ctx, "tailword"); len(got) != 0 {
+		t.Fatalf("tailword lies past the cap, got %v", got)
+	}
+	if got := agg.Find(ctx, "hugeword"); len(got) != 0 {
+		t.Fatalf("huge.txt should be skipped, got %v", got)
+	}
+	skipped := agg.Skipped(ctx)
+	if len(skipped) != 1 || filepath.Base(skipped[0]) != "huge.txt" {
+		t.Fatalf("skipped = %v, want [huge.txt]", skipped)
+	}
+}
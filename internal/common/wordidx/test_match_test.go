@@ -0,0 +1,149 @@
+package wordidx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func mkMixedCaseRepo(t *testing.T, base string) string {
+	t.Helper()
+	root := filepath.Join(base, "mixed")
+	files := map[string]string{
+		"auth/service.go": `package auth
+
+type AuthService struct{}
+
+func NewAuthService() *AuthService { return &AuthService{} }
+
+var HTTPServer = "srv"
+`,
+		"web/auth.ts": `export class authService {}
+const auth_service = new authService();
+const AUTHSERVICE_URL = "/auth";
+`,
+	}
+	for rel, content := range files {
+		p := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	return root
+}
+
+// hitFiles renders refs as sorted "file:line" strings.
+func hitFiles(refs []PosRef) []string {
+	out := make([]string, 0, len(refs))
+	for _, r := range refs {
+		out = append(out, fmt.Sprintf("%s:%d", filepath.Base(r.FilePath), r.Line))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestSplitIdentifier(t *testing.T) {
+	cases := map[string][]string{
+		"AuthService":     {"auth", "service"},
+		"auth_service":    {"auth", "service"},
+		"authService":     {"auth", "service"},
+		"AUTHSERVICE_URL": {"authservice", "url"},
+		"HTTPServer":      {"http", "server"},
+		"base64Encode":    {"base64", "encode"},
+		"__init__":        {"init"},
+		"x":               {"x"},
+	}
+	for in, want := range cases {
+		if got := SplitIdentifier(in); !reflect.DeepEqual(got, want) {
+			t.Fatalf("SplitIdentifier(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestAggIndex_FindModes(t *testing.T) {
+	base := setupWordidxRepos(t)
+	root := mkMixedCaseRepo(t, base)
+	ctx := context.Background()
+	agg := New().Root(root).Allow("go", "ts").Fuzzy(true).Start(ctx)
+
+	cases := []struct {
+		mode MatchMode
+		word string
+		want []string
+	}{
+		{MatchExact, "authservice", []string{}},
+		{MatchExact, "AuthService", []string{"service.go:3", "service.go:5", "service.go:5"}},
+		{MatchCaseInsensitive, "authservice", []string{"auth.ts:1", "auth.ts:2", "service.go:3", "service.go:5", "service.go:5"}},
+		{MatchPrefix, "newauth", []string{"service.go:5"}},
+		{MatchPrefix, "AUTH", []string{"auth.ts:1", "auth.ts:2", "auth.ts:2", "auth.ts:3", "auth.ts:3", "service.go:1", "service.go:3", "service.go:5", "service.go:5"}},
+		// NewAuthService only splits into new/auth/service.
+		{MatchIdentifier, "authservice", []string{"auth.ts:1", "auth.ts:2", "auth.ts:2", "auth.ts:3", "service.go:3", "service.go:5", "service.go:5"}},
+		{MatchIdentifier, "http_server", []string{"service.go:7"}},
+		{MatchIdentifier, "Server", []string{"service.go:7"}},
+	}
+	for _, tc := range cases {
+		refs, err := agg.FindMode(ctx, tc.word, tc.mode)
+		if err != nil {
+			t.Fatalf("FindMode(%q, %s) error = %v", tc.word, tc.mode, err)
+		}
+		if got := hitFiles(refs); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("FindMode(%q, %s) = %v, want %v", tc.word, tc.mode, got, tc.want)
+		}
+	}
+
+	// Without Fuzzy only exact lookups are available.
+	plain := New().Root(root).Allow("go", "ts").Start(ctx)
+	if _, err := plain.FindMode(ctx, "authservice", MatchIdentifier); !errors.Is(err, ErrFuzzyDisabled) {
+		t.Fatalf("FindMode without Fuzzy error = %v, want ErrFuzzyDisabled", err)
+	}
+	if refs, err := plain.FindMode(ctx, "AuthService", MatchExact); err != nil || len(refs) != 3 {
+		t.Fatalf("exact FindMode without Fuzzy = %v, %v", refs, err)
+	}
+}
+
+func TestParseMatchMode(t *testing.T) {
+	if m, err := ParseMatchMode(" Prefix "); err != nil || m != MatchPrefix {
+		t.Fatalf("ParseMatchMode = %q, %v", m, err)
+	}
+	if _, err := ParseMatchMode("regex"); err == nil {
+		t.Fatalf("expected error for unknown mode")
+	}
+}
+
+// BenchmarkAggIndex_FindMode shows per-query cost stays flat as the index
+// grows: compare ns/op across the words= sub-benchmarks.
+func BenchmarkAggIndex_FindMode(b *testing.B) {
+	for _, n := range []int{1_000, 100_000} {
+		agg := NewAgg()
+		agg.EnableFuzzy()
+		for i := 0; i < n; i++ {
+			word := fmt.Sprintf("pkgName%dHandler", i)
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(word))
+			key := h.Sum64()
+			ref := PosRef{FilePath: "f.go", Line: i + 1}
+			agg.byHash[key] = append(agg.byHash[key], ref)
+			agg.fold.add(word, ref)
+		}
+		agg.doneOnce.Do(func() { close(agg.doneCh) })
+		ctx := context.Background()
+		for _, mode := range []MatchMode{MatchExact, MatchCaseInsensitive, MatchIdentifier, MatchPrefix} {
+			b.Run(fmt.Sprintf("words=%d/%s", n, mode), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := agg.FindMode(ctx, "pkgname42handler", mode); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
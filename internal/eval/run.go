@@ -0,0 +1,190 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
+	llmmiddleware "insightify/internal/llm/middleware"
+	llmmodel "insightify/internal/llm/model"
+	"insightify/internal/runner"
+	workerruntime "insightify/internal/workerruntime"
+)
+
+// Options controls an evaluation run.
+type Options struct {
+	// OutDir holds one artifact directory per spec. Outputs cached there by
+	// an earlier run with the same inputs are reused, as in production.
+	OutDir string
+	// Phase, when set, runs only the specs of that phase.
+	Phase string
+	// Parallel runs up to N independent phases of a spec at once.
+	Parallel int
+}
+
+// Report is the scored result of an evaluation run.
+type Report struct {
+	Specs      []SpecResult `json:"specs"`
+	Passed     int          `json:"passed"`
+	Total      int          `json:"total"`
+	PassRate   float64      `json:"pass_rate"`
+	DurationMs int64        `json:"duration_ms"`
+	CostUSD    float64      `json:"cost_usd"`
+}
+
+// SpecResult reports one golden repository.
+type SpecResult struct {
+	Name       string                        `json:"name"`
+	Repo       string                        `json:"repo"`
+	Phase      string                        `json:"phase"`
+	Error      string                        `json:"error,omitempty"`
+	DurationMs int64                         `json:"duration_ms"`
+	LLM        llmmiddleware.RunUsageSummary `json:"llm"`
+	Assertions []AssertionResult             `json:"assertions"`
+}
+
+// AssertionResult reports one assertion; Detail explains a failure.
+type AssertionResult struct {
+	Name   string `json:"name"`
+	Phase  string `json:"phase"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Run evaluates specs one after another through runner.ExecutePlan, so
+// caching and the LLM middleware chain behave as they do in production.
+// The LLM comes from the usual environment; LLM_MODEL_OVERRIDES pointing
+// every phase at the fake provider gives deterministic runs. A spec whose
+// run fails fails all its assertions.
+func Run(ctx context.Context, specs []Spec, opts Options) (Report, error) {
+	started := time.Now()
+	rep := Report{Specs: []SpecResult{}}
+	for _, spec := range specs {
+		if opts.Phase != "" && spec.Phase != opts.Phase {
+			continue
+		}
+		res := runSpec(ctx, spec, opts)
+		for _, a := range res.Assertions {
+			rep.Total++
+			if a.Passed {
+				rep.Passed++
+			}
+		}
+		rep.CostUSD += res.LLM.CostUSD
+		rep.Specs = append(rep.Specs, res)
+		if err := ctx.Err(); err != nil {
+			return rep, err
+		}
+	}
+	if len(rep.Specs) == 0 {
+		return rep, fmt.Errorf("no specs for phase %q", opts.Phase)
+	}
+	if rep.Total > 0 {
+		rep.PassRate = float64(rep.Passed) / float64(rep.Total)
+	}
+	rep.DurationMs = time.Since(started).Milliseconds()
+	return rep, nil
+}
+
+func runSpec(ctx context.Context, spec Spec, opts Options) SpecResult {
+	started := time.Now()
+	res := SpecResult{Name: spec.Name, Repo: spec.RepoPath(), Phase: spec.Phase}
+	usage := llmmiddleware.NewRunUsage(0)
+	docs, err := executeSpec(llmmiddleware.WithRunUsage(ctx, usage), spec, opts)
+	res.LLM = usage.Summary()
+	res.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+	}
+	for _, a := range spec.Assertions {
+		phase := a.Phase
+		if phase == "" {
+			phase = spec.Phase
+		}
+		ar := AssertionResult{Name: a.label(phase), Phase: phase}
+		doc, ok := docs[phase]
+		switch {
+		case ok:
+			ar.Passed, ar.Detail = a.Check(doc)
+		case err != nil:
+			ar.Detail = "run failed: " + err.Error()
+		default:
+			ar.Detail = fmt.Sprintf("phase %s is not in the chain of %s", phase, spec.Phase)
+		}
+		res.Assertions = append(res.Assertions, ar)
+	}
+	return res
+}
+
+// executeSpec runs the spec phase and its requires chain on the fixture and
+// returns the decoded artifact of every phase that finished.
+func executeSpec(ctx context.Context, spec Spec, opts Options) (map[string]any, error) {
+	root, err := filepath.Abs(spec.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("repo %q is not a directory", spec.RepoPath())
+	}
+	// Workers look repositories up by name under the repos dir and scan
+	// them through the scan filesystem.
+	reposFS, err := safeio.NewSafeFS(filepath.Dir(root))
+	if err != nil {
+		return nil, err
+	}
+	scan.SetReposDir(filepath.Dir(root))
+	scan.SetSafeFS(reposFS)
+
+	project, err := workerruntime.NewProjectRuntime(filepath.Base(root), "eval-"+spec.Name, workerruntime.RepoEntry{Name: filepath.Base(root), LocalPath: root})
+	if err != nil {
+		return nil, err
+	}
+	defer project.Cleanup()
+	rt := project.NewExecutionRuntime(workerruntime.ExecutionOptions{OutDir: filepath.Join(opts.OutDir, spec.Name)})
+
+	if _, ok := rt.GetResolver().Get(spec.Phase); !ok {
+		return nil, fmt.Errorf("unknown phase: %s", spec.Phase)
+	}
+	keys := runner.UpstreamOrder(rt.GetResolver(), spec.Phase)
+	ctx = llmmodel.WithModelSelection(ctx, llmmodel.ModelRoleWorker, llmmodel.ModelLevelMiddle, "", "")
+	if opts.Parallel > 1 {
+		ctx = runner.WithParallelism(ctx, opts.Parallel)
+	}
+	// Only phases that finished in this run are scored; an artifact left
+	// over from an earlier run of a failed phase would pass stale output.
+	var (
+		mu       sync.Mutex
+		finished []string
+	)
+	ctx = runner.WithPhaseHooks(ctx, runner.PhaseHooks{
+		OnEnd: func(_ context.Context, spec runner.WorkerSpec, _ runner.Runtime, _ runner.WorkerOutput, err error, _ bool) {
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			finished = append(finished, spec.Key)
+		},
+	})
+	_, runErr := runner.ExecutePlan(ctx, rt, keys, spec.Params)
+
+	docs := map[string]any{}
+	for _, key := range finished {
+		raw, err := runner.ReadArtifact(ctx, rt.Artifacts(), key+".json")
+		if err != nil {
+			continue
+		}
+		var doc any
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return docs, fmt.Errorf("decode %s artifact: %w", key, err)
+		}
+		docs[key] = doc
+	}
+	return docs, runErr
+}
@@ -0,0 +1,257 @@
+// Package eval scores phase outputs against golden fixture repositories, so
+// prompt changes can be measured instead of eyeballed.
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Assertion operators.
+const (
+	OpExists   = "exists"
+	OpEquals   = "equals"
+	OpContains = "contains"
+	OpMatches  = "matches"
+	OpMinCount = "min_count"
+	OpMaxCount = "max_count"
+)
+
+// Spec describes one golden repository: the phase to run on it and what its
+// outputs must contain. Specs are JSON files, one per repository.
+type Spec struct {
+	// Name defaults to the spec file name without extension.
+	Name string `json:"name,omitempty"`
+	// Repo is the fixture repository, relative to the spec file.
+	Repo string `json:"repo"`
+	// Phase is run together with the phases it requires.
+	Phase string `json:"phase"`
+	// Params are passed to the run as StartRun params would be.
+	Params     map[string]string `json:"params,omitempty"`
+	Assertions []Assertion       `json:"assertions"`
+
+	file string
+}
+
+// Assertion checks the values Path selects in the artifact of Phase.
+//
+// Path is a dotted path into the artifact JSON; a "*" segment fans out over
+// every element of an array or object, so "components.*.paths.*" selects all
+// paths of all components. An empty path selects the whole artifact.
+type Assertion struct {
+	Name string `json:"name,omitempty"`
+	// Phase defaults to the spec phase and must be in its chain.
+	Phase string `json:"phase,omitempty"`
+	Path  string `json:"path"`
+	// Op is one of exists, equals, contains, matches, min_count and
+	// max_count. equals, contains and matches pass when any selected value
+	// does; the counts compare the number of selected values with Value.
+	Op    string `json:"op"`
+	Value any    `json:"value,omitempty"`
+}
+
+// LoadSpecs reads every *.json spec in dir, sorted by name.
+func LoadSpecs(dir string) ([]Spec, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	specs := make([]Spec, 0, len(files))
+	for _, file := range files {
+		spec, err := LoadSpec(file)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no specs in %s", dir)
+	}
+	return specs, nil
+}
+
+// LoadSpec reads and validates one spec file.
+func LoadSpec(file string) (Spec, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return Spec{}, err
+	}
+	var spec Spec
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("%s: %w", file, err)
+	}
+	spec.file = file
+	if spec.Name == "" {
+		spec.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if err := spec.validate(); err != nil {
+		return Spec{}, fmt.Errorf("%s: %w", file, err)
+	}
+	return spec, nil
+}
+
+func (s Spec) validate() error {
+	if strings.TrimSpace(s.Repo) == "" {
+		return fmt.Errorf("repo is required")
+	}
+	if strings.TrimSpace(s.Phase) == "" {
+		return fmt.Errorf("phase is required")
+	}
+	if len(s.Assertions) == 0 {
+		return fmt.Errorf("no assertions")
+	}
+	for i, a := range s.Assertions {
+		switch a.Op {
+		case OpExists:
+		case OpEquals, OpContains:
+			if a.Value == nil {
+				return fmt.Errorf("assertion %d: %s needs a value", i, a.Op)
+			}
+		case OpMatches:
+			pattern, ok := a.Value.(string)
+			if !ok {
+				return fmt.Errorf("assertion %d: matches needs a string pattern", i)
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("assertion %d: %w", i, err)
+			}
+		case OpMinCount, OpMaxCount:
+			if _, ok := a.Value.(float64); !ok {
+				return fmt.Errorf("assertion %d: %s needs a number", i, a.Op)
+			}
+		default:
+			return fmt.Errorf("assertion %d: unknown op %q", i, a.Op)
+		}
+	}
+	return nil
+}
+
+// RepoPath resolves Repo against the directory of the spec file.
+func (s Spec) RepoPath() string {
+	if filepath.IsAbs(s.Repo) || s.file == "" {
+		return s.Repo
+	}
+	return filepath.Join(filepath.Dir(s.file), s.Repo)
+}
+
+// label names a for reports: its Name, else "phase path op value".
+func (a Assertion) label(phase string) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	parts := []string{phase, a.Path, a.Op}
+	if a.Value != nil {
+		parts = append(parts, fmt.Sprint(a.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Check evaluates a against the decoded artifact doc and explains a failure.
+func (a Assertion) Check(doc any) (bool, string) {
+	values := selectPath(doc, a.Path)
+	switch a.Op {
+	case OpExists:
+		if len(values) == 0 {
+			return false, fmt.Sprintf("%q selects nothing", a.Path)
+		}
+		return true, ""
+	case OpMinCount, OpMaxCount:
+		n := int(a.Value.(float64))
+		if a.Op == OpMinCount && len(values) < n {
+			return false, fmt.Sprintf("%q selects %d values, want at least %d", a.Path, len(values), n)
+		}
+		if a.Op == OpMaxCount && len(values) > n {
+			return false, fmt.Sprintf("%q selects %d values, want at most %d", a.Path, len(values), n)
+		}
+		return true, ""
+	}
+	for _, v := range values {
+		if a.matchValue(v) {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("no value of %q %s %v (got %s)", a.Path, a.Op, a.Value, preview(values))
+}
+
+func (a Assertion) matchValue(v any) bool {
+	switch a.Op {
+	case OpEquals:
+		return reflect.DeepEqual(v, a.Value)
+	case OpContains:
+		if s, ok := v.(string); ok {
+			want, ok := a.Value.(string)
+			return ok && strings.Contains(s, want)
+		}
+		if list, ok := v.([]any); ok {
+			for _, item := range list {
+				if reflect.DeepEqual(item, a.Value) {
+					return true
+				}
+			}
+		}
+		return false
+	case OpMatches:
+		s, ok := v.(string)
+		return ok && regexp.MustCompile(a.Value.(string)).MatchString(s)
+	}
+	return false
+}
+
+// selectPath returns the non-null values path selects in doc.
+func selectPath(doc any, path string) []any {
+	current := []any{doc}
+	if path = strings.TrimSpace(path); path != "" {
+		for _, seg := range strings.Split(path, ".") {
+			var next []any
+			for _, v := range current {
+				switch node := v.(type) {
+				case map[string]any:
+					if seg == "*" {
+						keys := make([]string, 0, len(node))
+						for k := range node {
+							keys = append(keys, k)
+						}
+						sort.Strings(keys)
+						for _, k := range keys {
+							next = append(next, node[k])
+						}
+					} else if child, ok := node[seg]; ok {
+						next = append(next, child)
+					}
+				case []any:
+					if seg == "*" {
+						next = append(next, node...)
+					}
+				}
+			}
+			current = next
+		}
+	}
+	out := current[:0]
+	for _, v := range current {
+		if v != nil {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// preview renders values for a failure message, cut to a readable length.
+func preview(values []any) string {
+	b, _ := json.Marshal(values)
+	const max = 200
+	if len(b) > max {
+		return string(b[:max]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,98 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssertionCheck(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(`{
+		"components": [
+			{"name": "llm", "paths": ["internal/llm", "internal/llm/model"]},
+			{"name": "gateway", "paths": ["internal/gateway"], "rules": []}
+		],
+		"confidence": 0.8
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		a    Assertion
+		want bool
+	}{
+		{Assertion{Path: "components.*.paths.*", Op: OpEquals, Value: "internal/llm"}, true},
+		{Assertion{Path: "components.*.paths", Op: OpContains, Value: "internal/gateway"}, true},
+		{Assertion{Path: "components.*.name", Op: OpContains, Value: "gate"}, true},
+		{Assertion{Path: "components.*.name", Op: OpMatches, Value: "^l.m$"}, true},
+		{Assertion{Path: "components.*.paths.*", Op: OpMinCount, Value: float64(3)}, true},
+		{Assertion{Path: "components.*.paths.*", Op: OpMaxCount, Value: float64(2)}, false},
+		{Assertion{Path: "components.*.rules.*", Op: OpMinCount, Value: float64(1)}, false},
+		{Assertion{Path: "confidence", Op: OpEquals, Value: 0.8}, true},
+		{Assertion{Path: "components.*.owner", Op: OpExists}, false},
+		{Assertion{Path: "components.*", Op: OpContains, Value: "internal/web"}, false},
+	}
+	for _, tc := range cases {
+		if got, detail := tc.a.Check(doc); got != tc.want {
+			t.Fatalf("%s %s %v = %v (%s), want %v", tc.a.Path, tc.a.Op, tc.a.Value, got, detail, tc.want)
+		}
+	}
+}
+
+func TestLoadSpecRejectsInvalidAssertions(t *testing.T) {
+	cases := []Spec{
+		{Repo: "r", Phase: "p"},
+		{Repo: "r", Phase: "p", Assertions: []Assertion{{Path: "x", Op: "near"}}},
+		{Repo: "r", Phase: "p", Assertions: []Assertion{{Path: "x", Op: OpMatches, Value: "("}}},
+		{Repo: "r", Phase: "p", Assertions: []Assertion{{Path: "x", Op: OpMinCount, Value: "two"}}},
+		{Phase: "p", Assertions: []Assertion{{Path: "x", Op: OpExists}}},
+	}
+	for i, spec := range cases {
+		if err := spec.validate(); err == nil {
+			t.Fatalf("case %d: validate() accepted %+v", i, spec)
+		}
+	}
+}
+
+func TestRunScoresGoldenRepoWithFakeLLM(t *testing.T) {
+	specDir, err := filepath.Abs(filepath.Join("testdata", "specs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("LLM_MODEL_OVERRIDES", `{"*":{"provider":"fake","model":"fake-middle"}}`)
+
+	specs, err := LoadSpecs(specDir)
+	if err != nil {
+		t.Fatalf("LoadSpecs: %v", err)
+	}
+	rep, err := Run(context.Background(), specs, Options{OutDir: filepath.Join(dir, "out")})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if rep.Total != 4 || rep.Passed != rep.Total || rep.PassRate != 1 {
+		t.Fatalf("report = %+v, want every assertion passing", rep)
+	}
+	res := rep.Specs[0]
+	if res.Name != "gomod" || res.Error != "" || res.LLM.Calls == 0 {
+		t.Fatalf("spec result = %+v, want a gomod run with LLM usage", res)
+	}
+
+	// A failing assertion lowers the pass rate and says why.
+	specs[0].Assertions = append(specs[0].Assertions, Assertion{Phase: "code_roots", Path: "main_source_roots", Op: OpContains, Value: "web"})
+	rep, err = Run(context.Background(), specs, Options{OutDir: filepath.Join(dir, "out")})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	last := rep.Specs[0].Assertions[len(rep.Specs[0].Assertions)-1]
+	if rep.Passed != rep.Total-1 || last.Passed || !strings.Contains(last.Detail, "main_source_roots") {
+		t.Fatalf("report = %+v, want only the web assertion failing", rep)
+	}
+
+	if _, err := Run(context.Background(), specs, Options{OutDir: dir, Phase: "arch_design"}); err == nil {
+		t.Fatalf("Run accepted a phase no spec runs")
+	}
+}
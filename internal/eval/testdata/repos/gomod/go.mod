@@ -0,0 +1,3 @@
+module gomod
+
+go 1.24
@@ -0,0 +1,5 @@
+package main
+
+import "gomod/internal/greet"
+
+func main() { greet.Hello() }
@@ -1,9 +1,12 @@
 package app
 
 import (
+	"cmp"
 	"context"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"entgo.io/ent/dialect"
 	entsql "entgo.io/ent/dialect/sql"
@@ -13,12 +16,17 @@ import (
 	projectcache "insightify/internal/cache/project"
 	uicache "insightify/internal/cache/ui"
 	uiworkspacecache "insightify/internal/cache/uiworkspace"
+	"insightify/internal/common/scan"
+	"insightify/internal/gateway/auth"
 	"insightify/internal/gateway/config"
 	"insightify/internal/gateway/ent"
 	"insightify/internal/gateway/handler"
 	"insightify/internal/gateway/handler/rpc"
 	"insightify/internal/gateway/handler/ws"
+	"insightify/internal/gateway/httpserver"
+	"insightify/internal/gateway/middleware"
 	"insightify/internal/gateway/repository/artifact"
+	conversationrepo "insightify/internal/gateway/repository/conversation"
 	projectrepo "insightify/internal/gateway/repository/project"
 	"insightify/internal/gateway/repository/ui"
 	"insightify/internal/gateway/repository/uiworkspace"
@@ -30,13 +38,22 @@ import (
 	gatewayuiworkspace "insightify/internal/gateway/service/uiworkspace"
 	gatewayuserinteraction "insightify/internal/gateway/service/userinteraction"
 	gatewayworker "insightify/internal/gateway/service/worker"
+	runtimepkg "insightify/internal/workerruntime"
 )
 
 type App struct {
-	server    *server.Server
-	entClient *ent.Client // Add Ent client to App struct for proper shutdown
+	server          *httpserver.Server
+	entClient       *ent.Client // Add Ent client to App struct for proper shutdown
+	workerSvc       *gatewayworker.Service
+	interactionSvc  *gatewayuserinteraction.Service
+	shutdownTimeout time.Duration
+	stopSweepers    context.CancelFunc
 }
 
+// storeSweepInterval is how often expired interaction sessions and finished
+// runs are pruned.
+const storeSweepInterval = time.Minute
+
 func New() (*App, error) {
 	cfg, err := config.Load()
 	if err != nil {
@@ -93,6 +110,11 @@ func New() (*App, error) {
 		return nil, fmt.Errorf("failed to create artifact store: %w", err)
 	}
 	artifactStoreWithCache := artifactcache.NewCachedStore(artifactStore, artifactcache.DefaultCacheConfig())
+	blobStore, err := newArtifactBlobStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifact blob store: %w", err)
+	}
+	runtimepkg.SetArtifactBlobStore(blobStore)
 
 	// Project Store (Ent) with Cache (nil for now or initialize if needed)
 	// Passing nil for cache as we haven't initialized it here, or we can use generic LRU if import available.
@@ -117,24 +139,89 @@ func New() (*App, error) {
 	uiEventSvc := gatewayuievent.New(uiStore)
 	userInteractionSvc := gatewayuserinteraction.New(artifactStoreWithCache, cfg.Interaction.ConversationArtifactPath)
 	userInteractionSvc.SetUISync(uiEventSvc)
+	if cfg.Interaction.ChunkCoalesceWindow > 0 {
+		userInteractionSvc.SetChunkCoalesceWindow(cfg.Interaction.ChunkCoalesceWindow)
+	}
+	if cfg.Interaction.SessionIdleTTL > 0 {
+		userInteractionSvc.SetSessionIdleTTL(cfg.Interaction.SessionIdleTTL)
+	}
+	backpressure, err := gatewayuserinteraction.ParseBackpressurePolicy(cfg.Interaction.Backpressure)
+	if err != nil {
+		return nil, fmt.Errorf("invalid INTERACTION_BACKPRESSURE: %w", err)
+	}
+	userInteractionSvc.SetBackpressure(backpressure, cfg.Interaction.SendTimeout)
+	userInteractionSvc.SetConversationStore(conversationrepo.NewPostgresStore(client), conversationrepo.Retention{
+		MaxMessages: cfg.Interaction.HistoryMaxMessages,
+		MaxAge:      cfg.Interaction.HistoryMaxAge,
+	})
 	workerSvc := gatewayworker.New(projectSvc.AsProjectReader(), projectStore, uiWorkspaceSvc, uiSvc, userInteractionSvc, artifactStoreWithCache)
+	workerSvc.SetDrainGrace(cfg.Shutdown.RunDrainGrace)
+	workerSvc.SetRunTimeout(cfg.RunTimeout)
+	workerSvc.SetPhaseTimeout(cfg.PhaseTimeout)
+	workerSvc.SetInputWaitTimeout(cfg.Interaction.InputWaitTimeout)
+	workerSvc.SetRetryBudget(cfg.RetryBudget)
+	workerSvc.SetRunLockWait(cfg.RunLockWait)
+	workerSvc.SetPromptLog(cfg.PromptLog)
+	scan.SetLimits(cfg.Scan.MaxFiles, cfg.Scan.MaxTotalBytes)
+	if cfg.Runs.MaxRuns > 0 || cfg.Runs.Retention > 0 {
+		workerSvc.SetRunRetention(
+			cmp.Or(cfg.Runs.MaxRuns, gatewayworker.DefaultMaxRuns),
+			cmp.Or(cfg.Runs.Retention, gatewayworker.DefaultRunRetention),
+		)
+	}
+	// Served at /debug/vars.
+	expvar.Publish("runs", expvar.Func(func() any { return workerSvc.RunCounts() }))
+	expvar.Publish("interaction_sessions", expvar.Func(func() any { return userInteractionSvc.SessionCount() }))
+	expvar.Publish("interaction_dropped_events", expvar.Func(func() any { return userInteractionSvc.DroppedEvents() }))
 	actSvc := gatewayact.New(uiStore)
 	_ = actSvc // Available for handler wiring in future tickets
 
 	projectHandler := rpc.NewProjectHandler(projectSvc)
 	runHandler := rpc.NewRunHandler(workerSvc)
-	userInteractionHandler := ws.NewUserInteractionHandler(userInteractionSvc)
-	uiHandler := rpc.NewUiHandler(uiSvc)
-	uiWorkspaceHandler := rpc.NewUiWorkspaceHandler(uiSvc)
-	traceHandler := handler.NewTraceHandler(workerSvc)
+	userInteractionHandler := ws.NewUserInteractionHandler(userInteractionSvc, workerSvc.AuthorizeRun)
+	interactionSubmitHandler := handler.NewInteractionSubmitHandler(workerSvc.SubmitInput)
+	uiHandler := rpc.NewUiHandler(uiSvc, workerSvc.AuthorizeRun)
+	uiWorkspaceHandler := rpc.NewUiWorkspaceHandler(uiSvc, workerSvc.AuthorizeProject)
+	modelRegistry, err := runtimepkg.NewModelRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build model registry: %w", err)
+	}
+	traceHandler := handler.NewTraceHandler(workerSvc, modelRegistry)
+	projectArchiveHandler := handler.NewProjectArchiveHandler(projectSvc)
+	projectReposHandler := handler.NewProjectReposHandler(projectSvc)
+	projectSettingsHandler := handler.NewProjectSettingsHandler(projectSvc)
+	projectCompareHandler := handler.NewProjectCompareHandler(projectSvc)
+	projectSearchHandler := handler.NewProjectSearchHandler(projectSvc)
+	repoFileHandler := handler.NewRepoFileHandler(projectSvc.RepoFS)
+	debugHandler := handler.NewDebugHandler(projectSvc.PromptLogDir, runtimepkg.LLMChain)
+	healthHandler := handler.NewHealthHandler(cfg.Readiness.Probe, cfg.Readiness.Timeout, runtimepkg.NewLLMClient)
+	healthHandler.SetCacheTTL(cfg.Readiness.CacheTTL)
+
+	// Auth
+	verifier, err := auth.LoadStaticTokenVerifier(cfg.Auth.TokensJSON, cfg.Auth.TokensFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load auth tokens: %w", err)
+	}
+	authn := middleware.NewAuthenticator(verifier, cfg.Auth.DevAllowlist)
 
 	// Routing & Server
-	mux := server.NewMux(projectHandler, runHandler, userInteractionHandler, uiHandler, uiWorkspaceHandler, traceHandler)
-	srv := server.New(cfg.Port, mux)
+	mux := server.NewMux(projectHandler, runHandler, userInteractionHandler, interactionSubmitHandler, uiHandler, uiWorkspaceHandler, traceHandler, projectArchiveHandler, projectReposHandler, projectSettingsHandler, projectCompareHandler, projectSearchHandler, repoFileHandler, debugHandler, healthHandler, authn, cfg.CORSAllowedOrigins)
+	srv, err := httpserver.New(cfg.Port, mux, cfg.HTTP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build http server: %w", err)
+	}
+
+	sweepCtx, stopSweepers := context.WithCancel(context.Background())
+	userInteractionSvc.StartSweeper(sweepCtx, storeSweepInterval)
+	workerSvc.StartRunSweeper(sweepCtx, storeSweepInterval)
 
 	return &App{
-		server:    srv,
-		entClient: client,
+		server:          srv,
+		entClient:       client,
+		workerSvc:       workerSvc,
+		interactionSvc:  userInteractionSvc,
+		shutdownTimeout: cfg.Shutdown.Timeout,
+		stopSweepers:    stopSweepers,
 	}, nil
 }
 
@@ -142,7 +229,24 @@ func (a *App) Start() error {
 	return a.server.Start()
 }
 
+// ShutdownTimeout is the configured bound for Shutdown.
+func (a *App) ShutdownTimeout() time.Duration {
+	return a.shutdownTimeout
+}
+
+// Shutdown stops accepting runs and drains the active ones, flushes the
+// interaction transcripts, then stops the HTTP server and closes the stores.
 func (a *App) Shutdown(ctx context.Context) error {
+	if a.stopSweepers != nil {
+		a.stopSweepers()
+	}
+	if a.workerSvc != nil {
+		if err := a.workerSvc.Shutdown(ctx); err != nil {
+			slog.Warn("run drain incomplete", "error", err.Error())
+		}
+	}
+	// Transcripts are written in batches; save the last ones.
+	a.interactionSvc.FlushConversations(ctx)
 	if err := a.server.Shutdown(ctx); err != nil {
 		return err
 	}
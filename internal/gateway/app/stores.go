@@ -16,6 +16,8 @@ import (
 	artifactrepo "insightify/internal/gateway/repository/artifact"
 	uirepo "insightify/internal/gateway/repository/ui"
 	uiworkspacerepo "insightify/internal/gateway/repository/uiworkspace"
+	runtimepkg "insightify/internal/workerruntime"
+	"insightify/internal/workerruntime/artifactblob"
 )
 
 type gatewayStores struct {
@@ -33,6 +35,23 @@ func initStores(cfg *config.Config) (*gatewayStores, error) {
 	return initInMemoryStores(cfg, s3Factory)
 }
 
+// newArtifactBlobStore builds the store runs keep their artifacts in, chosen
+// by ARTIFACT_STORE. The local store is rooted at the project OutDirs.
+func newArtifactBlobStore(cfg *config.Config) (artifactblob.Store, error) {
+	if cfg.Artifact.Store != config.ArtifactStoreS3 {
+		return artifactblob.NewLocalStore(runtimepkg.ArtifactsDir), nil
+	}
+	return artifactblob.NewS3Store(artifactblob.S3Config{
+		Endpoint:  cfg.Artifact.Endpoint,
+		Region:    cfg.Artifact.Region,
+		AccessKey: cfg.Artifact.AccessKey,
+		SecretKey: cfg.Artifact.SecretKey,
+		Bucket:    cfg.Artifact.Bucket,
+		Prefix:    cfg.Artifact.Prefix,
+		UseSSL:    cfg.Artifact.UseSSL,
+	})
+}
+
 func newArtifactS3StoreFactory(cfg *config.Config) func() (artifactrepo.Store, error) {
 	return func() (artifactrepo.Store, error) {
 		s3Cfg := artifactrepo.S3Config{
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"insightify/internal/gateway/entity"
+)
+
+// ErrUserMismatch means a request named a user other than the authenticated one.
+var ErrUserMismatch = errors.New("user_id does not match the authenticated user")
+
+type ctxKeyUserID struct{}
+
+// WithUserID attaches the authenticated user to ctx.
+func WithUserID(ctx context.Context, id entity.UserID) context.Context {
+	return context.WithValue(ctx, ctxKeyUserID{}, id)
+}
+
+// UserIDFrom returns the authenticated user, if any.
+func UserIDFrom(ctx context.Context) (entity.UserID, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(ctxKeyUserID{}).(entity.UserID)
+	return id, ok && !id.IsZero()
+}
+
+// ResolveUserID returns the user a handler should act as. The authenticated
+// identity always wins; a request-supplied user ID is optional and must match
+// it. Without an identity (dev allowlist only) the requested ID is returned.
+func ResolveUserID(ctx context.Context, requested string) (entity.UserID, error) {
+	req := entity.NormalizeUserID(requested)
+	id, ok := UserIDFrom(ctx)
+	if !ok {
+		return req, nil
+	}
+	if !req.IsZero() && req != id {
+		return "", ErrUserMismatch
+	}
+	return id, nil
+}
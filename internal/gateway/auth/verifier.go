@@ -0,0 +1,96 @@
+// Package auth resolves the caller identity of gateway requests.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"insightify/internal/gateway/entity"
+)
+
+var (
+	// ErrUnauthenticated means the request carried no credentials.
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrInvalidToken means the credentials were not accepted by the verifier.
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// TokenVerifier maps a bearer token or API key to the user it authenticates.
+// A JWT verifier can implement it later without touching the middleware.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (entity.UserID, error)
+}
+
+// StaticTokenVerifier accepts a fixed token -> user table.
+type StaticTokenVerifier struct {
+	tokens map[string]entity.UserID
+}
+
+// NewStaticTokenVerifier builds a verifier from token -> user ID pairs.
+func NewStaticTokenVerifier(tokens map[string]string) *StaticTokenVerifier {
+	v := &StaticTokenVerifier{tokens: make(map[string]entity.UserID, len(tokens))}
+	for token, user := range tokens {
+		token = strings.TrimSpace(token)
+		id := entity.NormalizeUserID(user)
+		if token == "" || id.IsZero() {
+			continue
+		}
+		v.tokens[token] = id
+	}
+	return v
+}
+
+// LoadStaticTokenVerifier reads a {"token": "user_id"} JSON object from
+// inline, or from the file at path when inline is empty. Neither set yields
+// a verifier that rejects every token.
+func LoadStaticTokenVerifier(inline, path string) (*StaticTokenVerifier, error) {
+	raw := []byte(strings.TrimSpace(inline))
+	if len(raw) == 0 && strings.TrimSpace(path) != "" {
+		b, err := os.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return nil, fmt.Errorf("read auth tokens: %w", err)
+		}
+		raw = bytes.TrimSpace(b)
+	}
+	tokens := map[string]string{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &tokens); err != nil {
+			return nil, fmt.Errorf("parse auth tokens: %w", err)
+		}
+	}
+	return NewStaticTokenVerifier(tokens), nil
+}
+
+// Verify returns the user for token, or ErrInvalidToken.
+func (v *StaticTokenVerifier) Verify(_ context.Context, token string) (entity.UserID, error) {
+	if v != nil {
+		if id, ok := v.tokens[strings.TrimSpace(token)]; ok {
+			return id, nil
+		}
+	}
+	return "", ErrInvalidToken
+}
+
+// TokenFromHeader extracts the credential from "Authorization: Bearer <token>"
+// or "Authorization: ApiKey <key>".
+func TokenFromHeader(h http.Header) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(h.Get("Authorization")), " ")
+	if !ok {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", false
+	}
+	switch strings.ToLower(scheme) {
+	case "bearer", "apikey":
+		return token, true
+	}
+	return "", false
+}
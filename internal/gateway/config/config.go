@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"flag"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"insightify/internal/gateway/httpserver"
+	llmmodel "insightify/internal/llm/model"
 )
 
 type AppEnv string
@@ -23,6 +27,32 @@ type Config struct {
 	DatabaseURL string
 	Artifact    ArtifactConfig
 	Interaction InteractionConfig
+	Auth        AuthConfig
+	Shutdown    ShutdownConfig
+	Readiness   ReadinessConfig
+	Runs        RunStoreConfig
+	Scan        ScanConfig
+	// HTTP holds the server timeouts, HTTP/2 limits and TLS settings; zero
+	// fields take the httpserver defaults.
+	HTTP httpserver.Config
+	// RunTimeout bounds one worker run (RUN_TIMEOUT_MS).
+	RunTimeout time.Duration
+	// PhaseTimeout bounds each phase that sets no timeout of its own
+	// (PHASE_TIMEOUT_MS). Zero leaves phases unbounded.
+	PhaseTimeout time.Duration
+	// RetryBudget caps the LLM retries of one run across all its phases
+	// (RUN_RETRY_BUDGET).
+	RetryBudget int
+	// RunLockWait is how long a run waits for another run of the same
+	// project to release its artifacts (RUN_LOCK_WAIT_MS). Zero refuses the
+	// second run at once.
+	RunLockWait time.Duration
+	// PromptLog saves each run's LLM prompts and responses below
+	// OutDir/prompt/<run_id> (PROMPT_LOG; on by default in local).
+	PromptLog bool
+	// CORSAllowedOrigins lists origins the API answers cross-origin requests
+	// for (CORS_ALLOWED_ORIGINS, comma separated). Empty reflects any origin.
+	CORSAllowedOrigins []string
 }
 
 type ArtifactConfig struct {
@@ -33,8 +63,20 @@ type ArtifactConfig struct {
 	SecretKey string
 	Bucket    string
 	UseSSL    bool
+	// Store selects where runs keep their artifacts, ArtifactStoreLocal
+	// (default) or ArtifactStoreS3 (ARTIFACT_STORE).
+	Store string
+	// Prefix is prepended to the blob keys in the bucket
+	// (ARTIFACT_S3_PREFIX).
+	Prefix string
 }
 
+// Artifact blob store backends.
+const (
+	ArtifactStoreLocal = "local"
+	ArtifactStoreS3    = "s3"
+)
+
 func (c ArtifactConfig) CanUseS3() bool {
 	if !c.Enabled {
 		return false
@@ -47,6 +89,90 @@ func (c ArtifactConfig) CanUseS3() bool {
 
 type InteractionConfig struct {
 	ConversationArtifactPath string
+	// ChunkCoalesceWindow overrides the assistant chunk merge interval when > 0.
+	ChunkCoalesceWindow time.Duration
+	// SessionIdleTTL overrides how long an idle interaction session is kept
+	// when > 0 (INTERACTION_SESSION_IDLE_TTL_MS).
+	SessionIdleTTL time.Duration
+	// Backpressure names the policy for slow subscribers, "block" or
+	// "drop_oldest" (INTERACTION_BACKPRESSURE).
+	Backpressure string
+	// SendTimeout bounds a blocked subscriber send when > 0
+	// (INTERACTION_SEND_TIMEOUT_MS).
+	SendTimeout time.Duration
+	// HistoryMaxMessages and HistoryMaxAge bound stored transcripts when > 0
+	// (INTERACTION_HISTORY_MAX_MESSAGES, INTERACTION_HISTORY_MAX_AGE_MS).
+	HistoryMaxMessages int
+	HistoryMaxAge      time.Duration
+	// InputWaitTimeout bounds how long a run waits on user input when
+	// neither the worker nor the project sets a timeout
+	// (INTERACTION_INPUT_WAIT_TIMEOUT_MS).
+	InputWaitTimeout time.Duration
+}
+
+// RunStoreConfig bounds the in-memory run table. Zero values keep the worker
+// service defaults.
+type RunStoreConfig struct {
+	// MaxRuns caps tracked runs; finished ones are evicted oldest first
+	// (RUN_STORE_MAX_RUNS).
+	MaxRuns int
+	// Retention is how long a finished run stays queryable (RUN_RETENTION_MS).
+	Retention time.Duration
+}
+
+type AuthConfig struct {
+	// TokensJSON is an inline {"token": "user_id"} table (AUTH_TOKENS).
+	TokensJSON string
+	// TokensFile points at the same table on disk (AUTH_TOKENS_FILE).
+	TokensFile string
+	// DevAllowlist lists paths/procedures callable without a token. It is
+	// only honored when Env is local.
+	DevAllowlist []string
+}
+
+// DefaultShutdownTimeout bounds the whole shutdown when SHUTDOWN_TIMEOUT_MS
+// is unset.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// DefaultRunTimeout is the per-run execution deadline when RUN_TIMEOUT_MS is
+// unset.
+const DefaultRunTimeout = 30 * time.Minute
+
+// DefaultInputWaitTimeout is how long a run waits on user input when
+// INTERACTION_INPUT_WAIT_TIMEOUT_MS is unset.
+const DefaultInputWaitTimeout = 30 * time.Second
+
+// DefaultRunRetryBudget is the number of LLM retries one run may spend when
+// RUN_RETRY_BUDGET is unset.
+const DefaultRunRetryBudget = 30
+
+type ShutdownConfig struct {
+	// Timeout bounds run drain, HTTP shutdown and store close together
+	// (SHUTDOWN_TIMEOUT_MS).
+	Timeout time.Duration
+	// RunDrainGrace lets active runs finish before they are cancelled
+	// (RUN_DRAIN_GRACE_MS); zero cancels them right away.
+	RunDrainGrace time.Duration
+}
+
+type ReadinessConfig struct {
+	// Probe is none, count_tokens (default) or generate (READINESS_PROBE).
+	// generate sends a real request and spends quota.
+	Probe string
+	// Timeout bounds one probe (READINESS_TIMEOUT_MS).
+	Timeout time.Duration
+	// CacheTTL is how long a successful probe is reused
+	// (READINESS_CACHE_TTL_MS); zero uses the handler default.
+	CacheTTL time.Duration
+}
+
+type ScanConfig struct {
+	// MaxFiles stops a repository scan after this many files
+	// (SCAN_MAX_FILES); zero is unlimited.
+	MaxFiles int
+	// MaxTotalBytes stops a repository scan before the scanned file sizes
+	// exceed it (SCAN_MAX_TOTAL_BYTES); zero is unlimited.
+	MaxTotalBytes int64
 }
 
 func Load() (*Config, error) {
@@ -67,6 +193,53 @@ func Load() (*Config, error) {
 	cfg := configForEnv(env)
 	cfg.Port = *port
 	cfg.Env = env
+	cfg.Auth = authConfig(env)
+	cfg.Shutdown = shutdownConfig()
+	cfg.RunTimeout = durationMsEnv("RUN_TIMEOUT_MS")
+	if cfg.RunTimeout <= 0 {
+		cfg.RunTimeout = DefaultRunTimeout
+	}
+	cfg.PhaseTimeout = durationMsEnv("PHASE_TIMEOUT_MS")
+	cfg.RetryBudget = intEnv("RUN_RETRY_BUDGET")
+	if cfg.RetryBudget <= 0 {
+		cfg.RetryBudget = DefaultRunRetryBudget
+	}
+	cfg.RunLockWait = durationMsEnv("RUN_LOCK_WAIT_MS")
+	cfg.Readiness = ReadinessConfig{
+		Probe:    strings.TrimSpace(os.Getenv("READINESS_PROBE")),
+		Timeout:  durationMsEnv("READINESS_TIMEOUT_MS"),
+		CacheTTL: durationMsEnv("READINESS_CACHE_TTL_MS"),
+	}
+	cfg.Scan = ScanConfig{
+		MaxFiles:      intEnv("SCAN_MAX_FILES"),
+		MaxTotalBytes: int64Env("SCAN_MAX_TOTAL_BYTES"),
+	}
+	cfg.PromptLog = promptLogEnabled(env)
+	artifactCfg, err := artifactStoreConfig(cfg.Artifact)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Artifact = artifactCfg
+	// Runtimes read LLM_SELECTION_MODE when they build their LLM client;
+	// reject a typo here instead of at the first run.
+	if _, err := llmmodel.ParseModelSelectionMode(os.Getenv("LLM_SELECTION_MODE")); err != nil {
+		return nil, err
+	}
+	cfg.Interaction.SessionIdleTTL = durationMsEnv("INTERACTION_SESSION_IDLE_TTL_MS")
+	cfg.Interaction.Backpressure = strings.TrimSpace(os.Getenv("INTERACTION_BACKPRESSURE"))
+	cfg.Interaction.SendTimeout = durationMsEnv("INTERACTION_SEND_TIMEOUT_MS")
+	cfg.Interaction.HistoryMaxMessages = intEnv("INTERACTION_HISTORY_MAX_MESSAGES")
+	cfg.Interaction.HistoryMaxAge = durationMsEnv("INTERACTION_HISTORY_MAX_AGE_MS")
+	cfg.Interaction.InputWaitTimeout = durationMsEnv("INTERACTION_INPUT_WAIT_TIMEOUT_MS")
+	if cfg.Interaction.InputWaitTimeout <= 0 {
+		cfg.Interaction.InputWaitTimeout = DefaultInputWaitTimeout
+	}
+	cfg.Runs = RunStoreConfig{
+		MaxRuns:   intEnv("RUN_STORE_MAX_RUNS"),
+		Retention: durationMsEnv("RUN_RETENTION_MS"),
+	}
+	cfg.CORSAllowedOrigins = splitList(os.Getenv("CORS_ALLOWED_ORIGINS"))
+	cfg.HTTP = httpConfig()
 	cfg.DatabaseURL = strings.TrimSpace(cfg.DatabaseURL)
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
@@ -101,6 +274,88 @@ func configForEnv(env AppEnv) Config {
 	}
 }
 
+func authConfig(env AppEnv) AuthConfig {
+	cfg := AuthConfig{
+		TokensJSON: strings.TrimSpace(os.Getenv("AUTH_TOKENS")),
+		TokensFile: strings.TrimSpace(os.Getenv("AUTH_TOKENS_FILE")),
+	}
+	if env != AppEnvLocal {
+		return cfg
+	}
+	cfg.DevAllowlist = splitList(os.Getenv("AUTH_DEV_ALLOWLIST"))
+	return cfg
+}
+
+// splitList splits a comma separated env value, dropping empty entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func shutdownConfig() ShutdownConfig {
+	cfg := ShutdownConfig{
+		Timeout:       durationMsEnv("SHUTDOWN_TIMEOUT_MS"),
+		RunDrainGrace: durationMsEnv("RUN_DRAIN_GRACE_MS"),
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultShutdownTimeout
+	}
+	return cfg
+}
+
+func httpConfig() httpserver.Config {
+	return httpserver.Config{
+		ReadHeaderTimeout:    durationMsEnv("HTTP_READ_HEADER_TIMEOUT_MS"),
+		ReadTimeout:          durationMsEnv("HTTP_READ_TIMEOUT_MS"),
+		WriteTimeout:         durationMsEnv("HTTP_WRITE_TIMEOUT_MS"),
+		IdleTimeout:          durationMsEnv("HTTP_IDLE_TIMEOUT_MS"),
+		MaxConcurrentStreams: uint32(intEnv("HTTP2_MAX_CONCURRENT_STREAMS")),
+		TLSCertFile:          strings.TrimSpace(os.Getenv("TLS_CERT_FILE")),
+		TLSKeyFile:           strings.TrimSpace(os.Getenv("TLS_KEY_FILE")),
+		AutocertDomains:      splitList(os.Getenv("TLS_AUTOCERT_DOMAINS")),
+		AutocertCacheDir:     strings.TrimSpace(os.Getenv("TLS_AUTOCERT_CACHE_DIR")),
+	}
+}
+
+// artifactStoreConfig applies ARTIFACT_STORE, ARTIFACT_S3_PREFIX and the
+// ARTIFACT_S3_* connection overrides to cfg. The s3 store needs a complete
+// connection.
+func artifactStoreConfig(cfg ArtifactConfig) (ArtifactConfig, error) {
+	cfg.Store = strings.ToLower(firstNonEmpty(strings.TrimSpace(os.Getenv("ARTIFACT_STORE")), ArtifactStoreLocal))
+	cfg.Prefix = strings.TrimSpace(os.Getenv("ARTIFACT_S3_PREFIX"))
+	cfg.Endpoint = firstNonEmpty(strings.TrimSpace(os.Getenv("ARTIFACT_S3_ENDPOINT")), cfg.Endpoint)
+	cfg.Region = firstNonEmpty(strings.TrimSpace(os.Getenv("ARTIFACT_S3_REGION")), cfg.Region)
+	cfg.AccessKey = firstNonEmpty(strings.TrimSpace(os.Getenv("ARTIFACT_S3_ACCESS_KEY")), cfg.AccessKey)
+	cfg.SecretKey = firstNonEmpty(strings.TrimSpace(os.Getenv("ARTIFACT_S3_SECRET_KEY")), cfg.SecretKey)
+	cfg.Bucket = firstNonEmpty(strings.TrimSpace(os.Getenv("ARTIFACT_S3_BUCKET")), cfg.Bucket)
+	if v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("ARTIFACT_S3_USE_SSL"))); err == nil {
+		cfg.UseSSL = v
+	}
+	switch cfg.Store {
+	case ArtifactStoreLocal:
+	case ArtifactStoreS3:
+		cfg.Enabled = true
+		if !cfg.CanUseS3() {
+			return cfg, fmt.Errorf("ARTIFACT_STORE=s3 needs ARTIFACT_S3_ENDPOINT, ARTIFACT_S3_ACCESS_KEY, ARTIFACT_S3_SECRET_KEY and ARTIFACT_S3_BUCKET")
+		}
+	default:
+		return cfg, fmt.Errorf("ARTIFACT_STORE must be %s or %s, got %q", ArtifactStoreLocal, ArtifactStoreS3, cfg.Store)
+	}
+	return cfg, nil
+}
+
+func promptLogEnabled(env AppEnv) bool {
+	if v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("PROMPT_LOG"))); err == nil {
+		return v
+	}
+	return env == AppEnvLocal
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if strings.TrimSpace(v) != "" {
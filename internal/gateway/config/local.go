@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func localConfig() Config {
@@ -22,6 +24,31 @@ func localConfig() Config {
 				strings.TrimSpace(os.Getenv("INTERACTION_CONVERSATION_ARTIFACT_PATH")),
 				"interaction/conversation_history.json",
 			),
+			ChunkCoalesceWindow: durationMsEnv("INTERACTION_CHUNK_COALESCE_MS"),
 		},
 	}
 }
+
+func durationMsEnv(key string) time.Duration {
+	ms, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+func intEnv(key string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+func int64Env(key string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(os.Getenv(key)), 10, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
@@ -13,6 +13,8 @@ import (
 
 	"insightify/internal/gateway/ent/artifact"
 	"insightify/internal/gateway/ent/artifactfile"
+	"insightify/internal/gateway/ent/conversation"
+	"insightify/internal/gateway/ent/conversationmessage"
 	"insightify/internal/gateway/ent/project"
 	"insightify/internal/gateway/ent/userinteraction"
 	"insightify/internal/gateway/ent/workspace"
@@ -33,6 +35,10 @@ type Client struct {
 	Artifact *ArtifactClient
 	// ArtifactFile is the client for interacting with the ArtifactFile builders.
 	ArtifactFile *ArtifactFileClient
+	// Conversation is the client for interacting with the Conversation builders.
+	Conversation *ConversationClient
+	// ConversationMessage is the client for interacting with the ConversationMessage builders.
+	ConversationMessage *ConversationMessageClient
 	// Project is the client for interacting with the Project builders.
 	Project *ProjectClient
 	// UserInteraction is the client for interacting with the UserInteraction builders.
@@ -54,6 +60,8 @@ func (c *Client) init() {
 	c.Schema = migrate.NewSchema(c.driver)
 	c.Artifact = NewArtifactClient(c.config)
 	c.ArtifactFile = NewArtifactFileClient(c.config)
+	c.Conversation = NewConversationClient(c.config)
+	c.ConversationMessage = NewConversationMessageClient(c.config)
 	c.Project = NewProjectClient(c.config)
 	c.UserInteraction = NewUserInteractionClient(c.config)
 	c.Workspace = NewWorkspaceClient(c.config)
@@ -148,14 +156,16 @@ func (c *Client) Tx(ctx context.Context) (*Tx, error) {
 	cfg := c.config
 	cfg.driver = tx
 	return &Tx{
-		ctx:             ctx,
-		config:          cfg,
-		Artifact:        NewArtifactClient(cfg),
-		ArtifactFile:    NewArtifactFileClient(cfg),
-		Project:         NewProjectClient(cfg),
-		UserInteraction: NewUserInteractionClient(cfg),
-		Workspace:       NewWorkspaceClient(cfg),
-		WorkspaceTab:    NewWorkspaceTabClient(cfg),
+		ctx:                 ctx,
+		config:              cfg,
+		Artifact:            NewArtifactClient(cfg),
+		ArtifactFile:        NewArtifactFileClient(cfg),
+		Conversation:        NewConversationClient(cfg),
+		ConversationMessage: NewConversationMessageClient(cfg),
+		Project:             NewProjectClient(cfg),
+		UserInteraction:     NewUserInteractionClient(cfg),
+		Workspace:           NewWorkspaceClient(cfg),
+		WorkspaceTab:        NewWorkspaceTabClient(cfg),
 	}, nil
 }
 
@@ -173,14 +183,16 @@ func (c *Client) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
 	cfg := c.config
 	cfg.driver = &txDriver{tx: tx, drv: c.driver}
 	return &Tx{
-		ctx:             ctx,
-		config:          cfg,
-		Artifact:        NewArtifactClient(cfg),
-		ArtifactFile:    NewArtifactFileClient(cfg),
-		Project:         NewProjectClient(cfg),
-		UserInteraction: NewUserInteractionClient(cfg),
-		Workspace:       NewWorkspaceClient(cfg),
-		WorkspaceTab:    NewWorkspaceTabClient(cfg),
+		ctx:                 ctx,
+		config:              cfg,
+		Artifact:            NewArtifactClient(cfg),
+		ArtifactFile:        NewArtifactFileClient(cfg),
+		Conversation:        NewConversationClient(cfg),
+		ConversationMessage: NewConversationMessageClient(cfg),
+		Project:             NewProjectClient(cfg),
+		UserInteraction:     NewUserInteractionClient(cfg),
+		Workspace:           NewWorkspaceClient(cfg),
+		WorkspaceTab:        NewWorkspaceTabClient(cfg),
 	}, nil
 }
 
@@ -210,8 +222,8 @@ func (c *Client) Close() error {
 // In order to add hooks to a specific client, call: `client.Node.Use(...)`.
 func (c *Client) Use(hooks ...Hook) {
 	for _, n := range []interface{ Use(...Hook) }{
-		c.Artifact, c.ArtifactFile, c.Project, c.UserInteraction, c.Workspace,
-		c.WorkspaceTab,
+		c.Artifact, c.ArtifactFile, c.Conversation, c.ConversationMessage, c.Project,
+		c.UserInteraction, c.Workspace, c.WorkspaceTab,
 	} {
 		n.Use(hooks...)
 	}
@@ -221,8 +233,8 @@ func (c *Client) Use(hooks ...Hook) {
 // In order to add interceptors to a specific client, call: `client.Node.Intercept(...)`.
 func (c *Client) Intercept(interceptors ...Interceptor) {
 	for _, n := range []interface{ Intercept(...Interceptor) }{
-		c.Artifact, c.ArtifactFile, c.Project, c.UserInteraction, c.Workspace,
-		c.WorkspaceTab,
+		c.Artifact, c.ArtifactFile, c.Conversation, c.ConversationMessage, c.Project,
+		c.UserInteraction, c.Workspace, c.WorkspaceTab,
 	} {
 		n.Intercept(interceptors...)
 	}
@@ -235,6 +247,10 @@ func (c *Client) Mutate(ctx context.Context, m Mutation) (Value, error) {
 		return c.Artifact.mutate(ctx, m)
 	case *ArtifactFileMutation:
 		return c.ArtifactFile.mutate(ctx, m)
+	case *ConversationMutation:
+		return c.Conversation.mutate(ctx, m)
+	case *ConversationMessageMutation:
+		return c.ConversationMessage.mutate(ctx, m)
 	case *ProjectMutation:
 		return c.Project.mutate(ctx, m)
 	case *UserInteractionMutation:
@@ -530,6 +546,304 @@ func (c *ArtifactFileClient) mutate(ctx context.Context, m *ArtifactFileMutation
 	}
 }
 
+// ConversationClient is a client for the Conversation schema.
+type ConversationClient struct {
+	config
+}
+
+// NewConversationClient returns a client for the Conversation from the given config.
+func NewConversationClient(c config) *ConversationClient {
+	return &ConversationClient{config: c}
+}
+
+// Use adds a list of mutation hooks to the hooks stack.
+// A call to `Use(f, g, h)` equals to `conversation.Hooks(f(g(h())))`.
+func (c *ConversationClient) Use(hooks ...Hook) {
+	c.hooks.Conversation = append(c.hooks.Conversation, hooks...)
+}
+
+// Intercept adds a list of query interceptors to the interceptors stack.
+// A call to `Intercept(f, g, h)` equals to `conversation.Intercept(f(g(h())))`.
+func (c *ConversationClient) Intercept(interceptors ...Interceptor) {
+	c.inters.Conversation = append(c.inters.Conversation, interceptors...)
+}
+
+// Create returns a builder for creating a Conversation entity.
+func (c *ConversationClient) Create() *ConversationCreate {
+	mutation := newConversationMutation(c.config, OpCreate)
+	return &ConversationCreate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// CreateBulk returns a builder for creating a bulk of Conversation entities.
+func (c *ConversationClient) CreateBulk(builders ...*ConversationCreate) *ConversationCreateBulk {
+	return &ConversationCreateBulk{config: c.config, builders: builders}
+}
+
+// MapCreateBulk creates a bulk creation builder from the given slice. For each item in the slice, the function creates
+// a builder and applies setFunc on it.
+func (c *ConversationClient) MapCreateBulk(slice any, setFunc func(*ConversationCreate, int)) *ConversationCreateBulk {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice {
+		return &ConversationCreateBulk{err: fmt.Errorf("calling to ConversationClient.MapCreateBulk with wrong type %T, need slice", slice)}
+	}
+	builders := make([]*ConversationCreate, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		builders[i] = c.Create()
+		setFunc(builders[i], i)
+	}
+	return &ConversationCreateBulk{config: c.config, builders: builders}
+}
+
+// Update returns an update builder for Conversation.
+func (c *ConversationClient) Update() *ConversationUpdate {
+	mutation := newConversationMutation(c.config, OpUpdate)
+	return &ConversationUpdate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOne returns an update builder for the given entity.
+func (c *ConversationClient) UpdateOne(_m *Conversation) *ConversationUpdateOne {
+	mutation := newConversationMutation(c.config, OpUpdateOne, withConversation(_m))
+	return &ConversationUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOneID returns an update builder for the given id.
+func (c *ConversationClient) UpdateOneID(id string) *ConversationUpdateOne {
+	mutation := newConversationMutation(c.config, OpUpdateOne, withConversationID(id))
+	return &ConversationUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// Delete returns a delete builder for Conversation.
+func (c *ConversationClient) Delete() *ConversationDelete {
+	mutation := newConversationMutation(c.config, OpDelete)
+	return &ConversationDelete{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// DeleteOne returns a builder for deleting the given entity.
+func (c *ConversationClient) DeleteOne(_m *Conversation) *ConversationDeleteOne {
+	return c.DeleteOneID(_m.ID)
+}
+
+// DeleteOneID returns a builder for deleting the given entity by its id.
+func (c *ConversationClient) DeleteOneID(id string) *ConversationDeleteOne {
+	builder := c.Delete().Where(conversation.ID(id))
+	builder.mutation.id = &id
+	builder.mutation.op = OpDeleteOne
+	return &ConversationDeleteOne{builder}
+}
+
+// Query returns a query builder for Conversation.
+func (c *ConversationClient) Query() *ConversationQuery {
+	return &ConversationQuery{
+		config: c.config,
+		ctx:    &QueryContext{Type: TypeConversation},
+		inters: c.Interceptors(),
+	}
+}
+
+// Get returns a Conversation entity by its id.
+func (c *ConversationClient) Get(ctx context.Context, id string) (*Conversation, error) {
+	return c.Query().Where(conversation.ID(id)).Only(ctx)
+}
+
+// GetX is like Get, but panics if an error occurs.
+func (c *ConversationClient) GetX(ctx context.Context, id string) *Conversation {
+	obj, err := c.Get(ctx, id)
+	if err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+// QueryMessages queries the messages edge of a Conversation.
+func (c *ConversationClient) QueryMessages(_m *Conversation) *ConversationMessageQuery {
+	query := (&ConversationMessageClient{config: c.config}).Query()
+	query.path = func(context.Context) (fromV *sql.Selector, _ error) {
+		id := _m.ID
+		step := sqlgraph.NewStep(
+			sqlgraph.From(conversation.Table, conversation.FieldID, id),
+			sqlgraph.To(conversationmessage.Table, conversationmessage.FieldID),
+			sqlgraph.Edge(sqlgraph.O2M, false, conversation.MessagesTable, conversation.MessagesColumn),
+		)
+		fromV = sqlgraph.Neighbors(_m.driver.Dialect(), step)
+		return fromV, nil
+	}
+	return query
+}
+
+// Hooks returns the client hooks.
+func (c *ConversationClient) Hooks() []Hook {
+	return c.hooks.Conversation
+}
+
+// Interceptors returns the client interceptors.
+func (c *ConversationClient) Interceptors() []Interceptor {
+	return c.inters.Conversation
+}
+
+func (c *ConversationClient) mutate(ctx context.Context, m *ConversationMutation) (Value, error) {
+	switch m.Op() {
+	case OpCreate:
+		return (&ConversationCreate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdate:
+		return (&ConversationUpdate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdateOne:
+		return (&ConversationUpdateOne{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpDelete, OpDeleteOne:
+		return (&ConversationDelete{config: c.config, hooks: c.Hooks(), mutation: m}).Exec(ctx)
+	default:
+		return nil, fmt.Errorf("ent: unknown Conversation mutation op: %q", m.Op())
+	}
+}
+
+// ConversationMessageClient is a client for the ConversationMessage schema.
+type ConversationMessageClient struct {
+	config
+}
+
+// NewConversationMessageClient returns a client for the ConversationMessage from the given config.
+func NewConversationMessageClient(c config) *ConversationMessageClient {
+	return &ConversationMessageClient{config: c}
+}
+
+// Use adds a list of mutation hooks to the hooks stack.
+// A call to `Use(f, g, h)` equals to `conversationmessage.Hooks(f(g(h())))`.
+func (c *ConversationMessageClient) Use(hooks ...Hook) {
+	c.hooks.ConversationMessage = append(c.hooks.ConversationMessage, hooks...)
+}
+
+// Intercept adds a list of query interceptors to the interceptors stack.
+// A call to `Intercept(f, g, h)` equals to `conversationmessage.Intercept(f(g(h())))`.
+func (c *ConversationMessageClient) Intercept(interceptors ...Interceptor) {
+	c.inters.ConversationMessage = append(c.inters.ConversationMessage, interceptors...)
+}
+
+// Create returns a builder for creating a ConversationMessage entity.
+func (c *ConversationMessageClient) Create() *ConversationMessageCreate {
+	mutation := newConversationMessageMutation(c.config, OpCreate)
+	return &ConversationMessageCreate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// CreateBulk returns a builder for creating a bulk of ConversationMessage entities.
+func (c *ConversationMessageClient) CreateBulk(builders ...*ConversationMessageCreate) *ConversationMessageCreateBulk {
+	return &ConversationMessageCreateBulk{config: c.config, builders: builders}
+}
+
+// MapCreateBulk creates a bulk creation builder from the given slice. For each item in the slice, the function creates
+// a builder and applies setFunc on it.
+func (c *ConversationMessageClient) MapCreateBulk(slice any, setFunc func(*ConversationMessageCreate, int)) *ConversationMessageCreateBulk {
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice {
+		return &ConversationMessageCreateBulk{err: fmt.Errorf("calling to ConversationMessageClient.MapCreateBulk with wrong type %T, need slice", slice)}
+	}
+	builders := make([]*ConversationMessageCreate, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		builders[i] = c.Create()
+		setFunc(builders[i], i)
+	}
+	return &ConversationMessageCreateBulk{config: c.config, builders: builders}
+}
+
+// Update returns an update builder for ConversationMessage.
+func (c *ConversationMessageClient) Update() *ConversationMessageUpdate {
+	mutation := newConversationMessageMutation(c.config, OpUpdate)
+	return &ConversationMessageUpdate{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOne returns an update builder for the given entity.
+func (c *ConversationMessageClient) UpdateOne(_m *ConversationMessage) *ConversationMessageUpdateOne {
+	mutation := newConversationMessageMutation(c.config, OpUpdateOne, withConversationMessage(_m))
+	return &ConversationMessageUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// UpdateOneID returns an update builder for the given id.
+func (c *ConversationMessageClient) UpdateOneID(id int) *ConversationMessageUpdateOne {
+	mutation := newConversationMessageMutation(c.config, OpUpdateOne, withConversationMessageID(id))
+	return &ConversationMessageUpdateOne{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// Delete returns a delete builder for ConversationMessage.
+func (c *ConversationMessageClient) Delete() *ConversationMessageDelete {
+	mutation := newConversationMessageMutation(c.config, OpDelete)
+	return &ConversationMessageDelete{config: c.config, hooks: c.Hooks(), mutation: mutation}
+}
+
+// DeleteOne returns a builder for deleting the given entity.
+func (c *ConversationMessageClient) DeleteOne(_m *ConversationMessage) *ConversationMessageDeleteOne {
+	return c.DeleteOneID(_m.ID)
+}
+
+// DeleteOneID returns a builder for deleting the given entity by its id.
+func (c *ConversationMessageClient) DeleteOneID(id int) *ConversationMessageDeleteOne {
+	builder := c.Delete().Where(conversationmessage.ID(id))
+	builder.mutation.id = &id
+	builder.mutation.op = OpDeleteOne
+	return &ConversationMessageDeleteOne{builder}
+}
+
+// Query returns a query builder for ConversationMessage.
+func (c *ConversationMessageClient) Query() *ConversationMessageQuery {
+	return &ConversationMessageQuery{
+		config: c.config,
+		ctx:    &QueryContext{Type: TypeConversationMessage},
+		inters: c.Interceptors(),
+	}
+}
+
+// Get returns a ConversationMessage entity by its id.
+func (c *ConversationMessageClient) Get(ctx context.Context, id int) (*ConversationMessage, error) {
+	return c.Query().Where(conversationmessage.ID(id)).Only(ctx)
+}
+
+// GetX is like Get, but panics if an error occurs.
+func (c *ConversationMessageClient) GetX(ctx context.Context, id int) *ConversationMessage {
+	obj, err := c.Get(ctx, id)
+	if err != nil {
+		panic(err)
+	}
+	return obj
+}
+
+// QueryConversation queries the conversation edge of a ConversationMessage.
+func (c *ConversationMessageClient) QueryConversation(_m *ConversationMessage) *ConversationQuery {
+	query := (&ConversationClient{config: c.config}).Query()
+	query.path = func(context.Context) (fromV *sql.Selector, _ error) {
+		id := _m.ID
+		step := sqlgraph.NewStep(
+			sqlgraph.From(conversationmessage.Table, conversationmessage.FieldID, id),
+			sqlgraph.To(conversation.Table, conversation.FieldID),
+			sqlgraph.Edge(sqlgraph.M2O, true, conversationmessage.ConversationTable, conversationmessage.ConversationColumn),
+		)
+		fromV = sqlgraph.Neighbors(_m.driver.Dialect(), step)
+		return fromV, nil
+	}
+	return query
+}
+
+// Hooks returns the client hooks.
+func (c *ConversationMessageClient) Hooks() []Hook {
+	return c.hooks.ConversationMessage
+}
+
+// Interceptors returns the client interceptors.
+func (c *ConversationMessageClient) Interceptors() []Interceptor {
+	return c.inters.ConversationMessage
+}
+
+func (c *ConversationMessageClient) mutate(ctx context.Context, m *ConversationMessageMutation) (Value, error) {
+	switch m.Op() {
+	case OpCreate:
+		return (&ConversationMessageCreate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdate:
+		return (&ConversationMessageUpdate{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpUpdateOne:
+		return (&ConversationMessageUpdateOne{config: c.config, hooks: c.Hooks(), mutation: m}).Save(ctx)
+	case OpDelete, OpDeleteOne:
+		return (&ConversationMessageDelete{config: c.config, hooks: c.Hooks(), mutation: m}).Exec(ctx)
+	default:
+		return nil, fmt.Errorf("ent: unknown ConversationMessage mutation op: %q", m.Op())
+	}
+}
+
 // ProjectClient is a client for the Project schema.
 type ProjectClient struct {
 	config
@@ -1113,11 +1427,11 @@ func (c *WorkspaceTabClient) mutate(ctx context.Context, m *WorkspaceTabMutation
 // hooks and interceptors per client, for fast access.
 type (
 	hooks struct {
-		Artifact, ArtifactFile, Project, UserInteraction, Workspace,
-		WorkspaceTab []ent.Hook
+		Artifact, ArtifactFile, Conversation, ConversationMessage, Project,
+		UserInteraction, Workspace, WorkspaceTab []ent.Hook
 	}
 	inters struct {
-		Artifact, ArtifactFile, Project, UserInteraction, Workspace,
-		WorkspaceTab []ent.Interceptor
+		Artifact, ArtifactFile, Conversation, ConversationMessage, Project,
+		UserInteraction, Workspace, WorkspaceTab []ent.Interceptor
 	}
 )
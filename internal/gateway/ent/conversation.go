@@ -0,0 +1,152 @@
+// Code generated by ent, DO NOT EDIT.
+
+package ent
+
+import (
+	"fmt"
+	"insightify/internal/gateway/ent/conversation"
+	"strings"
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+)
+
+// Conversation is the model entity for the Conversation schema.
+type Conversation struct {
+	config `json:"-"`
+	// ID of the ent.
+	ID string `json:"id,omitempty"`
+	// RunID holds the value of the "run_id" field.
+	RunID string `json:"run_id,omitempty"`
+	// NodeID holds the value of the "node_id" field.
+	NodeID string `json:"node_id,omitempty"`
+	// CreatedAt holds the value of the "created_at" field.
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	// Edges holds the relations/edges for other nodes in the graph.
+	// The values are being populated by the ConversationQuery when eager-loading is set.
+	Edges        ConversationEdges `json:"edges"`
+	selectValues sql.SelectValues
+}
+
+// ConversationEdges holds the relations/edges for other nodes in the graph.
+type ConversationEdges struct {
+	// Messages holds the value of the messages edge.
+	Messages []*ConversationMessage `json:"messages,omitempty"`
+	// loadedTypes holds the information for reporting if a
+	// type was loaded (or requested) in eager-loading or not.
+	loadedTypes [1]bool
+}
+
+// MessagesOrErr returns the Messages value or an error if the edge
+// was not loaded in eager-loading.
+func (e ConversationEdges) MessagesOrErr() ([]*ConversationMessage, error) {
+	if e.loadedTypes[0] {
+		return e.Messages, nil
+	}
+	return nil, &NotLoadedError{edge: "messages"}
+}
+
+// scanValues returns the types for scanning values from sql.Rows.
+func (*Conversation) scanValues(columns []string) ([]any, error) {
+	values := make([]any, len(columns))
+	for i := range columns {
+		switch columns[i] {
+		case conversation.FieldID, conversation.FieldRunID, conversation.FieldNodeID:
+			values[i] = new(sql.NullString)
+		case conversation.FieldCreatedAt:
+			values[i] = new(sql.NullTime)
+		default:
+			values[i] = new(sql.UnknownType)
+		}
+	}
+	return values, nil
+}
+
+// assignValues assigns the values that were returned from sql.Rows (after scanning)
+// to the Conversation fields.
+func (_m *Conversation) assignValues(columns []string, values []any) error {
+	if m, n := len(values), len(columns); m < n {
+		return fmt.Errorf("mismatch number of scan values: %d != %d", m, n)
+	}
+	for i := range columns {
+		switch columns[i] {
+		case conversation.FieldID:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field id", values[i])
+			} else if value.Valid {
+				_m.ID = value.String
+			}
+		case conversation.FieldRunID:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field run_id", values[i])
+			} else if value.Valid {
+				_m.RunID = value.String
+			}
+		case conversation.FieldNodeID:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field node_id", values[i])
+			} else if value.Valid {
+				_m.NodeID = value.String
+			}
+		case conversation.FieldCreatedAt:
+			if value, ok := values[i].(*sql.NullTime); !ok {
+				return fmt.Errorf("unexpected type %T for field created_at", values[i])
+			} else if value.Valid {
+				_m.CreatedAt = value.Time
+			}
+		default:
+			_m.selectValues.Set(columns[i], values[i])
+		}
+	}
+	return nil
+}
+
+// Value returns the ent.Value that was dynamically selected and assigned to the Conversation.
+// This includes values selected through modifiers, order, etc.
+func (_m *Conversation) Value(name string) (ent.Value, error) {
+	return _m.selectValues.Get(name)
+}
+
+// QueryMessages queries the "messages" edge of the Conversation entity.
+func (_m *Conversation) QueryMessages() *ConversationMessageQuery {
+	return NewConversationClient(_m.config).QueryMessages(_m)
+}
+
+// Update returns a builder for updating this Conversation.
+// Note that you need to call Conversation.Unwrap() before calling this method if this Conversation
+// was returned from a transaction, and the transaction was committed or rolled back.
+func (_m *Conversation) Update() *ConversationUpdateOne {
+	return NewConversationClient(_m.config).UpdateOne(_m)
+}
+
+// Unwrap unwraps the Conversation entity that was returned from a transaction after it was closed,
+// so that all future queries will be executed through the driver which created the transaction.
+func (_m *Conversation) Unwrap() *Conversation {
+	_tx, ok := _m.config.driver.(*txDriver)
+	if !ok {
+		panic("ent: Conversation is not a transactional entity")
+	}
+	_m.config.driver = _tx.drv
+	return _m
+}
+
+// String implements the fmt.Stringer.
+func (_m *Conversation) String() string {
+	var builder strings.Builder
+	builder.WriteString("Conversation(")
+	builder.WriteString(fmt.Sprintf("id=%v, ", _m.ID))
+	builder.WriteString("run_id=")
+	builder.WriteString(_m.RunID)
+	builder.WriteString(", ")
+	builder.WriteString("node_id=")
+	builder.WriteString(_m.NodeID)
+	builder.WriteString(", ")
+	builder.WriteString("created_at=")
+	builder.WriteString(_m.CreatedAt.Format(time.ANSIC))
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+// Conversations is a parsable slice of Conversation.
+type Conversations []*Conversation
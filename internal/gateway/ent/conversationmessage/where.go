@@ -0,0 +1,464 @@
+// Code generated by ent, DO NOT EDIT.
+
+package conversationmessage
+
+import (
+	"insightify/internal/gateway/ent/predicate"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/sqlgraph"
+)
+
+// ID filters vertices based on their ID field.
+func ID(id int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldID, id))
+}
+
+// IDEQ applies the EQ predicate on the ID field.
+func IDEQ(id int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldID, id))
+}
+
+// IDNEQ applies the NEQ predicate on the ID field.
+func IDNEQ(id int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNEQ(FieldID, id))
+}
+
+// IDIn applies the In predicate on the ID field.
+func IDIn(ids ...int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldIn(FieldID, ids...))
+}
+
+// IDNotIn applies the NotIn predicate on the ID field.
+func IDNotIn(ids ...int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNotIn(FieldID, ids...))
+}
+
+// IDGT applies the GT predicate on the ID field.
+func IDGT(id int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGT(FieldID, id))
+}
+
+// IDGTE applies the GTE predicate on the ID field.
+func IDGTE(id int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGTE(FieldID, id))
+}
+
+// IDLT applies the LT predicate on the ID field.
+func IDLT(id int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLT(FieldID, id))
+}
+
+// IDLTE applies the LTE predicate on the ID field.
+func IDLTE(id int) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLTE(FieldID, id))
+}
+
+// ConversationID applies equality check predicate on the "conversation_id" field. It's identical to ConversationIDEQ.
+func ConversationID(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldConversationID, v))
+}
+
+// Seq applies equality check predicate on the "seq" field. It's identical to SeqEQ.
+func Seq(v int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldSeq, v))
+}
+
+// Role applies equality check predicate on the "role" field. It's identical to RoleEQ.
+func Role(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldRole, v))
+}
+
+// Content applies equality check predicate on the "content" field. It's identical to ContentEQ.
+func Content(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldContent, v))
+}
+
+// InteractionID applies equality check predicate on the "interaction_id" field. It's identical to InteractionIDEQ.
+func InteractionID(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldInteractionID, v))
+}
+
+// CreatedAt applies equality check predicate on the "created_at" field. It's identical to CreatedAtEQ.
+func CreatedAt(v time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// ConversationIDEQ applies the EQ predicate on the "conversation_id" field.
+func ConversationIDEQ(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldConversationID, v))
+}
+
+// ConversationIDNEQ applies the NEQ predicate on the "conversation_id" field.
+func ConversationIDNEQ(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNEQ(FieldConversationID, v))
+}
+
+// ConversationIDIn applies the In predicate on the "conversation_id" field.
+func ConversationIDIn(vs ...string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldIn(FieldConversationID, vs...))
+}
+
+// ConversationIDNotIn applies the NotIn predicate on the "conversation_id" field.
+func ConversationIDNotIn(vs ...string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNotIn(FieldConversationID, vs...))
+}
+
+// ConversationIDGT applies the GT predicate on the "conversation_id" field.
+func ConversationIDGT(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGT(FieldConversationID, v))
+}
+
+// ConversationIDGTE applies the GTE predicate on the "conversation_id" field.
+func ConversationIDGTE(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGTE(FieldConversationID, v))
+}
+
+// ConversationIDLT applies the LT predicate on the "conversation_id" field.
+func ConversationIDLT(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLT(FieldConversationID, v))
+}
+
+// ConversationIDLTE applies the LTE predicate on the "conversation_id" field.
+func ConversationIDLTE(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLTE(FieldConversationID, v))
+}
+
+// ConversationIDContains applies the Contains predicate on the "conversation_id" field.
+func ConversationIDContains(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldContains(FieldConversationID, v))
+}
+
+// ConversationIDHasPrefix applies the HasPrefix predicate on the "conversation_id" field.
+func ConversationIDHasPrefix(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldHasPrefix(FieldConversationID, v))
+}
+
+// ConversationIDHasSuffix applies the HasSuffix predicate on the "conversation_id" field.
+func ConversationIDHasSuffix(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldHasSuffix(FieldConversationID, v))
+}
+
+// ConversationIDEqualFold applies the EqualFold predicate on the "conversation_id" field.
+func ConversationIDEqualFold(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEqualFold(FieldConversationID, v))
+}
+
+// ConversationIDContainsFold applies the ContainsFold predicate on the "conversation_id" field.
+func ConversationIDContainsFold(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldContainsFold(FieldConversationID, v))
+}
+
+// SeqEQ applies the EQ predicate on the "seq" field.
+func SeqEQ(v int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldSeq, v))
+}
+
+// SeqNEQ applies the NEQ predicate on the "seq" field.
+func SeqNEQ(v int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNEQ(FieldSeq, v))
+}
+
+// SeqIn applies the In predicate on the "seq" field.
+func SeqIn(vs ...int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldIn(FieldSeq, vs...))
+}
+
+// SeqNotIn applies the NotIn predicate on the "seq" field.
+func SeqNotIn(vs ...int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNotIn(FieldSeq, vs...))
+}
+
+// SeqGT applies the GT predicate on the "seq" field.
+func SeqGT(v int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGT(FieldSeq, v))
+}
+
+// SeqGTE applies the GTE predicate on the "seq" field.
+func SeqGTE(v int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGTE(FieldSeq, v))
+}
+
+// SeqLT applies the LT predicate on the "seq" field.
+func SeqLT(v int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLT(FieldSeq, v))
+}
+
+// SeqLTE applies the LTE predicate on the "seq" field.
+func SeqLTE(v int64) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLTE(FieldSeq, v))
+}
+
+// RoleEQ applies the EQ predicate on the "role" field.
+func RoleEQ(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldRole, v))
+}
+
+// RoleNEQ applies the NEQ predicate on the "role" field.
+func RoleNEQ(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNEQ(FieldRole, v))
+}
+
+// RoleIn applies the In predicate on the "role" field.
+func RoleIn(vs ...string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldIn(FieldRole, vs...))
+}
+
+// RoleNotIn applies the NotIn predicate on the "role" field.
+func RoleNotIn(vs ...string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNotIn(FieldRole, vs...))
+}
+
+// RoleGT applies the GT predicate on the "role" field.
+func RoleGT(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGT(FieldRole, v))
+}
+
+// RoleGTE applies the GTE predicate on the "role" field.
+func RoleGTE(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGTE(FieldRole, v))
+}
+
+// RoleLT applies the LT predicate on the "role" field.
+func RoleLT(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLT(FieldRole, v))
+}
+
+// RoleLTE applies the LTE predicate on the "role" field.
+func RoleLTE(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLTE(FieldRole, v))
+}
+
+// RoleContains applies the Contains predicate on the "role" field.
+func RoleContains(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldContains(FieldRole, v))
+}
+
+// RoleHasPrefix applies the HasPrefix predicate on the "role" field.
+func RoleHasPrefix(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldHasPrefix(FieldRole, v))
+}
+
+// RoleHasSuffix applies the HasSuffix predicate on the "role" field.
+func RoleHasSuffix(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldHasSuffix(FieldRole, v))
+}
+
+// RoleEqualFold applies the EqualFold predicate on the "role" field.
+func RoleEqualFold(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEqualFold(FieldRole, v))
+}
+
+// RoleContainsFold applies the ContainsFold predicate on the "role" field.
+func RoleContainsFold(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldContainsFold(FieldRole, v))
+}
+
+// ContentEQ applies the EQ predicate on the "content" field.
+func ContentEQ(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldContent, v))
+}
+
+// ContentNEQ applies the NEQ predicate on the "content" field.
+func ContentNEQ(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNEQ(FieldContent, v))
+}
+
+// ContentIn applies the In predicate on the "content" field.
+func ContentIn(vs ...string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldIn(FieldContent, vs...))
+}
+
+// ContentNotIn applies the NotIn predicate on the "content" field.
+func ContentNotIn(vs ...string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNotIn(FieldContent, vs...))
+}
+
+// ContentGT applies the GT predicate on the "content" field.
+func ContentGT(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGT(FieldContent, v))
+}
+
+// ContentGTE applies the GTE predicate on the "content" field.
+func ContentGTE(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGTE(FieldContent, v))
+}
+
+// ContentLT applies the LT predicate on the "content" field.
+func ContentLT(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLT(FieldContent, v))
+}
+
+// ContentLTE applies the LTE predicate on the "content" field.
+func ContentLTE(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLTE(FieldContent, v))
+}
+
+// ContentContains applies the Contains predicate on the "content" field.
+func ContentContains(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldContains(FieldContent, v))
+}
+
+// ContentHasPrefix applies the HasPrefix predicate on the "content" field.
+func ContentHasPrefix(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldHasPrefix(FieldContent, v))
+}
+
+// ContentHasSuffix applies the HasSuffix predicate on the "content" field.
+func ContentHasSuffix(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldHasSuffix(FieldContent, v))
+}
+
+// ContentEqualFold applies the EqualFold predicate on the "content" field.
+func ContentEqualFold(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEqualFold(FieldContent, v))
+}
+
+// ContentContainsFold applies the ContainsFold predicate on the "content" field.
+func ContentContainsFold(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldContainsFold(FieldContent, v))
+}
+
+// InteractionIDEQ applies the EQ predicate on the "interaction_id" field.
+func InteractionIDEQ(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldInteractionID, v))
+}
+
+// InteractionIDNEQ applies the NEQ predicate on the "interaction_id" field.
+func InteractionIDNEQ(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNEQ(FieldInteractionID, v))
+}
+
+// InteractionIDIn applies the In predicate on the "interaction_id" field.
+func InteractionIDIn(vs ...string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldIn(FieldInteractionID, vs...))
+}
+
+// InteractionIDNotIn applies the NotIn predicate on the "interaction_id" field.
+func InteractionIDNotIn(vs ...string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNotIn(FieldInteractionID, vs...))
+}
+
+// InteractionIDGT applies the GT predicate on the "interaction_id" field.
+func InteractionIDGT(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGT(FieldInteractionID, v))
+}
+
+// InteractionIDGTE applies the GTE predicate on the "interaction_id" field.
+func InteractionIDGTE(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGTE(FieldInteractionID, v))
+}
+
+// InteractionIDLT applies the LT predicate on the "interaction_id" field.
+func InteractionIDLT(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLT(FieldInteractionID, v))
+}
+
+// InteractionIDLTE applies the LTE predicate on the "interaction_id" field.
+func InteractionIDLTE(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLTE(FieldInteractionID, v))
+}
+
+// InteractionIDContains applies the Contains predicate on the "interaction_id" field.
+func InteractionIDContains(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldContains(FieldInteractionID, v))
+}
+
+// InteractionIDHasPrefix applies the HasPrefix predicate on the "interaction_id" field.
+func InteractionIDHasPrefix(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldHasPrefix(FieldInteractionID, v))
+}
+
+// InteractionIDHasSuffix applies the HasSuffix predicate on the "interaction_id" field.
+func InteractionIDHasSuffix(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldHasSuffix(FieldInteractionID, v))
+}
+
+// InteractionIDEqualFold applies the EqualFold predicate on the "interaction_id" field.
+func InteractionIDEqualFold(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEqualFold(FieldInteractionID, v))
+}
+
+// InteractionIDContainsFold applies the ContainsFold predicate on the "interaction_id" field.
+func InteractionIDContainsFold(v string) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldContainsFold(FieldInteractionID, v))
+}
+
+// CreatedAtEQ applies the EQ predicate on the "created_at" field.
+func CreatedAtEQ(v time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtNEQ applies the NEQ predicate on the "created_at" field.
+func CreatedAtNEQ(v time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNEQ(FieldCreatedAt, v))
+}
+
+// CreatedAtIn applies the In predicate on the "created_at" field.
+func CreatedAtIn(vs ...time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtNotIn applies the NotIn predicate on the "created_at" field.
+func CreatedAtNotIn(vs ...time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldNotIn(FieldCreatedAt, vs...))
+}
+
+// CreatedAtGT applies the GT predicate on the "created_at" field.
+func CreatedAtGT(v time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGT(FieldCreatedAt, v))
+}
+
+// CreatedAtGTE applies the GTE predicate on the "created_at" field.
+func CreatedAtGTE(v time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldGTE(FieldCreatedAt, v))
+}
+
+// CreatedAtLT applies the LT predicate on the "created_at" field.
+func CreatedAtLT(v time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLT(FieldCreatedAt, v))
+}
+
+// CreatedAtLTE applies the LTE predicate on the "created_at" field.
+func CreatedAtLTE(v time.Time) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.FieldLTE(FieldCreatedAt, v))
+}
+
+// HasConversation applies the HasEdge predicate on the "conversation" edge.
+func HasConversation() predicate.ConversationMessage {
+	return predicate.ConversationMessage(func(s *sql.Selector) {
+		step := sqlgraph.NewStep(
+			sqlgraph.From(Table, FieldID),
+			sqlgraph.Edge(sqlgraph.M2O, true, ConversationTable, ConversationColumn),
+		)
+		sqlgraph.HasNeighbors(s, step)
+	})
+}
+
+// HasConversationWith applies the HasEdge predicate on the "conversation" edge with a given conditions (other predicates).
+func HasConversationWith(preds ...predicate.Conversation) predicate.ConversationMessage {
+	return predicate.ConversationMessage(func(s *sql.Selector) {
+		step := newConversationStep()
+		sqlgraph.HasNeighborsWith(s, step, func(s *sql.Selector) {
+			for _, p := range preds {
+				p(s)
+			}
+		})
+	})
+}
+
+// And groups predicates with the AND operator between them.
+func And(predicates ...predicate.ConversationMessage) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.AndPredicates(predicates...))
+}
+
+// Or groups predicates with the OR operator between them.
+func Or(predicates ...predicate.ConversationMessage) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.OrPredicates(predicates...))
+}
+
+// Not applies the not operator on the given predicate.
+func Not(p predicate.ConversationMessage) predicate.ConversationMessage {
+	return predicate.ConversationMessage(sql.NotPredicates(p))
+}
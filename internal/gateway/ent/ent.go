@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"insightify/internal/gateway/ent/artifact"
 	"insightify/internal/gateway/ent/artifactfile"
+	"insightify/internal/gateway/ent/conversation"
+	"insightify/internal/gateway/ent/conversationmessage"
 	"insightify/internal/gateway/ent/project"
 	"insightify/internal/gateway/ent/userinteraction"
 	"insightify/internal/gateway/ent/workspace"
@@ -78,12 +80,14 @@ var (
 func checkColumn(t, c string) error {
 	initCheck.Do(func() {
 		columnCheck = sql.NewColumnCheck(map[string]func(string) bool{
-			artifact.Table:        artifact.ValidColumn,
-			artifactfile.Table:    artifactfile.ValidColumn,
-			project.Table:         project.ValidColumn,
-			userinteraction.Table: userinteraction.ValidColumn,
-			workspace.Table:       workspace.ValidColumn,
-			workspacetab.Table:    workspacetab.ValidColumn,
+			artifact.Table:            artifact.ValidColumn,
+			artifactfile.Table:        artifactfile.ValidColumn,
+			conversation.Table:        conversation.ValidColumn,
+			conversationmessage.Table: conversationmessage.ValidColumn,
+			project.Table:             project.ValidColumn,
+			userinteraction.Table:     userinteraction.ValidColumn,
+			workspace.Table:           workspace.ValidColumn,
+			workspacetab.Table:        workspacetab.ValidColumn,
 		})
 	})
 	return columnCheck(t, c)
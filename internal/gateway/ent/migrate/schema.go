@@ -65,13 +65,75 @@ var (
 			},
 		},
 	}
+	// ConversationsColumns holds the columns for the "conversations" table.
+	ConversationsColumns = []*schema.Column{
+		{Name: "conversation_id", Type: field.TypeString, Unique: true},
+		{Name: "run_id", Type: field.TypeString},
+		{Name: "node_id", Type: field.TypeString},
+		{Name: "created_at", Type: field.TypeTime},
+	}
+	// ConversationsTable holds the schema information for the "conversations" table.
+	ConversationsTable = &schema.Table{
+		Name:       "conversations",
+		Columns:    ConversationsColumns,
+		PrimaryKey: []*schema.Column{ConversationsColumns[0]},
+		Indexes: []*schema.Index{
+			{
+				Name:    "conversation_run_id_node_id",
+				Unique:  true,
+				Columns: []*schema.Column{ConversationsColumns[1], ConversationsColumns[2]},
+			},
+		},
+	}
+	// ConversationMessagesColumns holds the columns for the "conversation_messages" table.
+	ConversationMessagesColumns = []*schema.Column{
+		{Name: "id", Type: field.TypeInt, Increment: true},
+		{Name: "seq", Type: field.TypeInt64},
+		{Name: "role", Type: field.TypeString},
+		{Name: "content", Type: field.TypeString, Size: 2147483647, Default: ""},
+		{Name: "interaction_id", Type: field.TypeString, Default: ""},
+		{Name: "created_at", Type: field.TypeTime},
+		{Name: "conversation_id", Type: field.TypeString},
+	}
+	// ConversationMessagesTable holds the schema information for the "conversation_messages" table.
+	ConversationMessagesTable = &schema.Table{
+		Name:       "conversation_messages",
+		Columns:    ConversationMessagesColumns,
+		PrimaryKey: []*schema.Column{ConversationMessagesColumns[0]},
+		ForeignKeys: []*schema.ForeignKey{
+			{
+				Symbol:     "conversation_messages_conversations_messages",
+				Columns:    []*schema.Column{ConversationMessagesColumns[6]},
+				RefColumns: []*schema.Column{ConversationsColumns[0]},
+				OnDelete:   schema.NoAction,
+			},
+		},
+		Indexes: []*schema.Index{
+			{
+				Name:    "conversationmessage_conversation_id_seq",
+				Unique:  true,
+				Columns: []*schema.Column{ConversationMessagesColumns[6], ConversationMessagesColumns[1]},
+			},
+			{
+				Name:    "conversationmessage_created_at",
+				Unique:  false,
+				Columns: []*schema.Column{ConversationMessagesColumns[5]},
+			},
+		},
+	}
 	// ProjectsColumns holds the columns for the "projects" table.
 	ProjectsColumns = []*schema.Column{
 		{Name: "project_id", Type: field.TypeString, Unique: true},
 		{Name: "project_name", Type: field.TypeString, Default: "Project"},
 		{Name: "user_id", Type: field.TypeString, Default: ""},
 		{Name: "repo", Type: field.TypeString, Default: ""},
+		{Name: "repos", Type: field.TypeJSON, Nullable: true},
 		{Name: "is_active", Type: field.TypeBool, Default: false},
+		{Name: "cost_budget_usd", Type: field.TypeFloat64, Default: 0},
+		{Name: "input_wait_timeout_ms", Type: field.TypeInt64, Default: 0},
+		{Name: "locale", Type: field.TypeString, Default: ""},
+		{Name: "min_code_ratio", Type: field.TypeFloat64, Default: 0},
+		{Name: "min_code_bytes", Type: field.TypeInt64, Default: 0},
 	}
 	// ProjectsTable holds the schema information for the "projects" table.
 	ProjectsTable = &schema.Table{
@@ -144,6 +206,8 @@ var (
 	Tables = []*schema.Table{
 		ArtifactsTable,
 		ArtifactFilesTable,
+		ConversationsTable,
+		ConversationMessagesTable,
 		ProjectsTable,
 		UserInteractionsTable,
 		WorkspacesTable,
@@ -153,5 +217,6 @@ var (
 
 func init() {
 	ArtifactsTable.ForeignKeys[0].RefTable = ProjectsTable
+	ConversationMessagesTable.ForeignKeys[0].RefTable = ConversationsTable
 	WorkspaceTabsTable.ForeignKeys[0].RefTable = WorkspacesTable
 }
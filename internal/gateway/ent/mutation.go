@@ -8,11 +8,14 @@ import (
 	"fmt"
 	"insightify/internal/gateway/ent/artifact"
 	"insightify/internal/gateway/ent/artifactfile"
+	"insightify/internal/gateway/ent/conversation"
+	"insightify/internal/gateway/ent/conversationmessage"
 	"insightify/internal/gateway/ent/predicate"
 	"insightify/internal/gateway/ent/project"
 	"insightify/internal/gateway/ent/userinteraction"
 	"insightify/internal/gateway/ent/workspace"
 	"insightify/internal/gateway/ent/workspacetab"
+	"insightify/internal/gateway/entity"
 	"sync"
 	"time"
 
@@ -29,12 +32,14 @@ const (
 	OpUpdateOne = ent.OpUpdateOne
 
 	// Node types.
-	TypeArtifact        = "Artifact"
-	TypeArtifactFile    = "ArtifactFile"
-	TypeProject         = "Project"
-	TypeUserInteraction = "UserInteraction"
-	TypeWorkspace       = "Workspace"
-	TypeWorkspaceTab    = "WorkspaceTab"
+	TypeArtifact            = "Artifact"
+	TypeArtifactFile        = "ArtifactFile"
+	TypeConversation        = "Conversation"
+	TypeConversationMessage = "ConversationMessage"
+	TypeProject             = "Project"
+	TypeUserInteraction     = "UserInteraction"
+	TypeWorkspace           = "Workspace"
+	TypeWorkspaceTab        = "WorkspaceTab"
 )
 
 // ArtifactMutation represents an operation that mutates the Artifact nodes in the graph.
@@ -1223,23 +1228,1259 @@ func (m *ArtifactFileMutation) ResetEdge(name string) error {
 	return fmt.Errorf("unknown ArtifactFile edge %s", name)
 }
 
+// ConversationMutation represents an operation that mutates the Conversation nodes in the graph.
+type ConversationMutation struct {
+	config
+	op              Op
+	typ             string
+	id              *string
+	run_id          *string
+	node_id         *string
+	created_at      *time.Time
+	clearedFields   map[string]struct{}
+	messages        map[int]struct{}
+	removedmessages map[int]struct{}
+	clearedmessages bool
+	done            bool
+	oldValue        func(context.Context) (*Conversation, error)
+	predicates      []predicate.Conversation
+}
+
+var _ ent.Mutation = (*ConversationMutation)(nil)
+
+// conversationOption allows management of the mutation configuration using functional options.
+type conversationOption func(*ConversationMutation)
+
+// newConversationMutation creates new mutation for the Conversation entity.
+func newConversationMutation(c config, op Op, opts ...conversationOption) *ConversationMutation {
+	m := &ConversationMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeConversation,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withConversationID sets the ID field of the mutation.
+func withConversationID(id string) conversationOption {
+	return func(m *ConversationMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *Conversation
+		)
+		m.oldValue = func(ctx context.Context) (*Conversation, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().Conversation.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withConversation sets the old Conversation of the mutation.
+func withConversation(node *Conversation) conversationOption {
+	return func(m *ConversationMutation) {
+		m.oldValue = func(context.Context) (*Conversation, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m ConversationMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m ConversationMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("ent: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of Conversation entities.
+func (m *ConversationMutation) SetID(id string) {
+	m.id = &id
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *ConversationMutation) ID() (id string, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *ConversationMutation) IDs(ctx context.Context) ([]string, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []string{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().Conversation.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetRunID sets the "run_id" field.
+func (m *ConversationMutation) SetRunID(s string) {
+	m.run_id = &s
+}
+
+// RunID returns the value of the "run_id" field in the mutation.
+func (m *ConversationMutation) RunID() (r string, exists bool) {
+	v := m.run_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRunID returns the old "run_id" field's value of the Conversation entity.
+// If the Conversation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMutation) OldRunID(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRunID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRunID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRunID: %w", err)
+	}
+	return oldValue.RunID, nil
+}
+
+// ResetRunID resets all changes to the "run_id" field.
+func (m *ConversationMutation) ResetRunID() {
+	m.run_id = nil
+}
+
+// SetNodeID sets the "node_id" field.
+func (m *ConversationMutation) SetNodeID(s string) {
+	m.node_id = &s
+}
+
+// NodeID returns the value of the "node_id" field in the mutation.
+func (m *ConversationMutation) NodeID() (r string, exists bool) {
+	v := m.node_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldNodeID returns the old "node_id" field's value of the Conversation entity.
+// If the Conversation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMutation) OldNodeID(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldNodeID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldNodeID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldNodeID: %w", err)
+	}
+	return oldValue.NodeID, nil
+}
+
+// ResetNodeID resets all changes to the "node_id" field.
+func (m *ConversationMutation) ResetNodeID() {
+	m.node_id = nil
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *ConversationMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *ConversationMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the Conversation entity.
+// If the Conversation object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *ConversationMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
+// AddMessageIDs adds the "messages" edge to the ConversationMessage entity by ids.
+func (m *ConversationMutation) AddMessageIDs(ids ...int) {
+	if m.messages == nil {
+		m.messages = make(map[int]struct{})
+	}
+	for i := range ids {
+		m.messages[ids[i]] = struct{}{}
+	}
+}
+
+// ClearMessages clears the "messages" edge to the ConversationMessage entity.
+func (m *ConversationMutation) ClearMessages() {
+	m.clearedmessages = true
+}
+
+// MessagesCleared reports if the "messages" edge to the ConversationMessage entity was cleared.
+func (m *ConversationMutation) MessagesCleared() bool {
+	return m.clearedmessages
+}
+
+// RemoveMessageIDs removes the "messages" edge to the ConversationMessage entity by IDs.
+func (m *ConversationMutation) RemoveMessageIDs(ids ...int) {
+	if m.removedmessages == nil {
+		m.removedmessages = make(map[int]struct{})
+	}
+	for i := range ids {
+		delete(m.messages, ids[i])
+		m.removedmessages[ids[i]] = struct{}{}
+	}
+}
+
+// RemovedMessages returns the removed IDs of the "messages" edge to the ConversationMessage entity.
+func (m *ConversationMutation) RemovedMessagesIDs() (ids []int) {
+	for id := range m.removedmessages {
+		ids = append(ids, id)
+	}
+	return
+}
+
+// MessagesIDs returns the "messages" edge IDs in the mutation.
+func (m *ConversationMutation) MessagesIDs() (ids []int) {
+	for id := range m.messages {
+		ids = append(ids, id)
+	}
+	return
+}
+
+// ResetMessages resets all changes to the "messages" edge.
+func (m *ConversationMutation) ResetMessages() {
+	m.messages = nil
+	m.clearedmessages = false
+	m.removedmessages = nil
+}
+
+// Where appends a list predicates to the ConversationMutation builder.
+func (m *ConversationMutation) Where(ps ...predicate.Conversation) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the ConversationMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *ConversationMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.Conversation, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *ConversationMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *ConversationMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (Conversation).
+func (m *ConversationMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *ConversationMutation) Fields() []string {
+	fields := make([]string, 0, 3)
+	if m.run_id != nil {
+		fields = append(fields, conversation.FieldRunID)
+	}
+	if m.node_id != nil {
+		fields = append(fields, conversation.FieldNodeID)
+	}
+	if m.created_at != nil {
+		fields = append(fields, conversation.FieldCreatedAt)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *ConversationMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case conversation.FieldRunID:
+		return m.RunID()
+	case conversation.FieldNodeID:
+		return m.NodeID()
+	case conversation.FieldCreatedAt:
+		return m.CreatedAt()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *ConversationMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case conversation.FieldRunID:
+		return m.OldRunID(ctx)
+	case conversation.FieldNodeID:
+		return m.OldNodeID(ctx)
+	case conversation.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	}
+	return nil, fmt.Errorf("unknown Conversation field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *ConversationMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case conversation.FieldRunID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRunID(v)
+		return nil
+	case conversation.FieldNodeID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetNodeID(v)
+		return nil
+	case conversation.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	}
+	return fmt.Errorf("unknown Conversation field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *ConversationMutation) AddedFields() []string {
+	return nil
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *ConversationMutation) AddedField(name string) (ent.Value, bool) {
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *ConversationMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	}
+	return fmt.Errorf("unknown Conversation numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *ConversationMutation) ClearedFields() []string {
+	return nil
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *ConversationMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *ConversationMutation) ClearField(name string) error {
+	return fmt.Errorf("unknown Conversation nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *ConversationMutation) ResetField(name string) error {
+	switch name {
+	case conversation.FieldRunID:
+		m.ResetRunID()
+		return nil
+	case conversation.FieldNodeID:
+		m.ResetNodeID()
+		return nil
+	case conversation.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	}
+	return fmt.Errorf("unknown Conversation field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *ConversationMutation) AddedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.messages != nil {
+		edges = append(edges, conversation.EdgeMessages)
+	}
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *ConversationMutation) AddedIDs(name string) []ent.Value {
+	switch name {
+	case conversation.EdgeMessages:
+		ids := make([]ent.Value, 0, len(m.messages))
+		for id := range m.messages {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *ConversationMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.removedmessages != nil {
+		edges = append(edges, conversation.EdgeMessages)
+	}
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *ConversationMutation) RemovedIDs(name string) []ent.Value {
+	switch name {
+	case conversation.EdgeMessages:
+		ids := make([]ent.Value, 0, len(m.removedmessages))
+		for id := range m.removedmessages {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *ConversationMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.clearedmessages {
+		edges = append(edges, conversation.EdgeMessages)
+	}
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *ConversationMutation) EdgeCleared(name string) bool {
+	switch name {
+	case conversation.EdgeMessages:
+		return m.clearedmessages
+	}
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *ConversationMutation) ClearEdge(name string) error {
+	switch name {
+	}
+	return fmt.Errorf("unknown Conversation unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *ConversationMutation) ResetEdge(name string) error {
+	switch name {
+	case conversation.EdgeMessages:
+		m.ResetMessages()
+		return nil
+	}
+	return fmt.Errorf("unknown Conversation edge %s", name)
+}
+
+// ConversationMessageMutation represents an operation that mutates the ConversationMessage nodes in the graph.
+type ConversationMessageMutation struct {
+	config
+	op                  Op
+	typ                 string
+	id                  *int
+	seq                 *int64
+	addseq              *int64
+	role                *string
+	content             *string
+	interaction_id      *string
+	created_at          *time.Time
+	clearedFields       map[string]struct{}
+	conversation        *string
+	clearedconversation bool
+	done                bool
+	oldValue            func(context.Context) (*ConversationMessage, error)
+	predicates          []predicate.ConversationMessage
+}
+
+var _ ent.Mutation = (*ConversationMessageMutation)(nil)
+
+// conversationmessageOption allows management of the mutation configuration using functional options.
+type conversationmessageOption func(*ConversationMessageMutation)
+
+// newConversationMessageMutation creates new mutation for the ConversationMessage entity.
+func newConversationMessageMutation(c config, op Op, opts ...conversationmessageOption) *ConversationMessageMutation {
+	m := &ConversationMessageMutation{
+		config:        c,
+		op:            op,
+		typ:           TypeConversationMessage,
+		clearedFields: make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// withConversationMessageID sets the ID field of the mutation.
+func withConversationMessageID(id int) conversationmessageOption {
+	return func(m *ConversationMessageMutation) {
+		var (
+			err   error
+			once  sync.Once
+			value *ConversationMessage
+		)
+		m.oldValue = func(ctx context.Context) (*ConversationMessage, error) {
+			once.Do(func() {
+				if m.done {
+					err = errors.New("querying old values post mutation is not allowed")
+				} else {
+					value, err = m.Client().ConversationMessage.Get(ctx, id)
+				}
+			})
+			return value, err
+		}
+		m.id = &id
+	}
+}
+
+// withConversationMessage sets the old ConversationMessage of the mutation.
+func withConversationMessage(node *ConversationMessage) conversationmessageOption {
+	return func(m *ConversationMessageMutation) {
+		m.oldValue = func(context.Context) (*ConversationMessage, error) {
+			return node, nil
+		}
+		m.id = &node.ID
+	}
+}
+
+// Client returns a new `ent.Client` from the mutation. If the mutation was
+// executed in a transaction (ent.Tx), a transactional client is returned.
+func (m ConversationMessageMutation) Client() *Client {
+	client := &Client{config: m.config}
+	client.init()
+	return client
+}
+
+// Tx returns an `ent.Tx` for mutations that were executed in transactions;
+// it returns an error otherwise.
+func (m ConversationMessageMutation) Tx() (*Tx, error) {
+	if _, ok := m.driver.(*txDriver); !ok {
+		return nil, errors.New("ent: mutation is not running in a transaction")
+	}
+	tx := &Tx{config: m.config}
+	tx.init()
+	return tx, nil
+}
+
+// SetID sets the value of the id field. Note that this
+// operation is only accepted on creation of ConversationMessage entities.
+func (m *ConversationMessageMutation) SetID(id int) {
+	m.id = &id
+}
+
+// ID returns the ID value in the mutation. Note that the ID is only available
+// if it was provided to the builder or after it was returned from the database.
+func (m *ConversationMessageMutation) ID() (id int, exists bool) {
+	if m.id == nil {
+		return
+	}
+	return *m.id, true
+}
+
+// IDs queries the database and returns the entity ids that match the mutation's predicate.
+// That means, if the mutation is applied within a transaction with an isolation level such
+// as sql.LevelSerializable, the returned ids match the ids of the rows that will be updated
+// or updated by the mutation.
+func (m *ConversationMessageMutation) IDs(ctx context.Context) ([]int, error) {
+	switch {
+	case m.op.Is(OpUpdateOne | OpDeleteOne):
+		id, exists := m.ID()
+		if exists {
+			return []int{id}, nil
+		}
+		fallthrough
+	case m.op.Is(OpUpdate | OpDelete):
+		return m.Client().ConversationMessage.Query().Where(m.predicates...).IDs(ctx)
+	default:
+		return nil, fmt.Errorf("IDs is not allowed on %s operations", m.op)
+	}
+}
+
+// SetConversationID sets the "conversation_id" field.
+func (m *ConversationMessageMutation) SetConversationID(s string) {
+	m.conversation = &s
+}
+
+// ConversationID returns the value of the "conversation_id" field in the mutation.
+func (m *ConversationMessageMutation) ConversationID() (r string, exists bool) {
+	v := m.conversation
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldConversationID returns the old "conversation_id" field's value of the ConversationMessage entity.
+// If the ConversationMessage object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMessageMutation) OldConversationID(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldConversationID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldConversationID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldConversationID: %w", err)
+	}
+	return oldValue.ConversationID, nil
+}
+
+// ResetConversationID resets all changes to the "conversation_id" field.
+func (m *ConversationMessageMutation) ResetConversationID() {
+	m.conversation = nil
+}
+
+// SetSeq sets the "seq" field.
+func (m *ConversationMessageMutation) SetSeq(i int64) {
+	m.seq = &i
+	m.addseq = nil
+}
+
+// Seq returns the value of the "seq" field in the mutation.
+func (m *ConversationMessageMutation) Seq() (r int64, exists bool) {
+	v := m.seq
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldSeq returns the old "seq" field's value of the ConversationMessage entity.
+// If the ConversationMessage object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMessageMutation) OldSeq(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldSeq is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldSeq requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldSeq: %w", err)
+	}
+	return oldValue.Seq, nil
+}
+
+// AddSeq adds i to the "seq" field.
+func (m *ConversationMessageMutation) AddSeq(i int64) {
+	if m.addseq != nil {
+		*m.addseq += i
+	} else {
+		m.addseq = &i
+	}
+}
+
+// AddedSeq returns the value that was added to the "seq" field in this mutation.
+func (m *ConversationMessageMutation) AddedSeq() (r int64, exists bool) {
+	v := m.addseq
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetSeq resets all changes to the "seq" field.
+func (m *ConversationMessageMutation) ResetSeq() {
+	m.seq = nil
+	m.addseq = nil
+}
+
+// SetRole sets the "role" field.
+func (m *ConversationMessageMutation) SetRole(s string) {
+	m.role = &s
+}
+
+// Role returns the value of the "role" field in the mutation.
+func (m *ConversationMessageMutation) Role() (r string, exists bool) {
+	v := m.role
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRole returns the old "role" field's value of the ConversationMessage entity.
+// If the ConversationMessage object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMessageMutation) OldRole(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRole is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRole requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRole: %w", err)
+	}
+	return oldValue.Role, nil
+}
+
+// ResetRole resets all changes to the "role" field.
+func (m *ConversationMessageMutation) ResetRole() {
+	m.role = nil
+}
+
+// SetContent sets the "content" field.
+func (m *ConversationMessageMutation) SetContent(s string) {
+	m.content = &s
+}
+
+// Content returns the value of the "content" field in the mutation.
+func (m *ConversationMessageMutation) Content() (r string, exists bool) {
+	v := m.content
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldContent returns the old "content" field's value of the ConversationMessage entity.
+// If the ConversationMessage object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMessageMutation) OldContent(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldContent is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldContent requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldContent: %w", err)
+	}
+	return oldValue.Content, nil
+}
+
+// ResetContent resets all changes to the "content" field.
+func (m *ConversationMessageMutation) ResetContent() {
+	m.content = nil
+}
+
+// SetInteractionID sets the "interaction_id" field.
+func (m *ConversationMessageMutation) SetInteractionID(s string) {
+	m.interaction_id = &s
+}
+
+// InteractionID returns the value of the "interaction_id" field in the mutation.
+func (m *ConversationMessageMutation) InteractionID() (r string, exists bool) {
+	v := m.interaction_id
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldInteractionID returns the old "interaction_id" field's value of the ConversationMessage entity.
+// If the ConversationMessage object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMessageMutation) OldInteractionID(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldInteractionID is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldInteractionID requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldInteractionID: %w", err)
+	}
+	return oldValue.InteractionID, nil
+}
+
+// ResetInteractionID resets all changes to the "interaction_id" field.
+func (m *ConversationMessageMutation) ResetInteractionID() {
+	m.interaction_id = nil
+}
+
+// SetCreatedAt sets the "created_at" field.
+func (m *ConversationMessageMutation) SetCreatedAt(t time.Time) {
+	m.created_at = &t
+}
+
+// CreatedAt returns the value of the "created_at" field in the mutation.
+func (m *ConversationMessageMutation) CreatedAt() (r time.Time, exists bool) {
+	v := m.created_at
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCreatedAt returns the old "created_at" field's value of the ConversationMessage entity.
+// If the ConversationMessage object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ConversationMessageMutation) OldCreatedAt(ctx context.Context) (v time.Time, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCreatedAt is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCreatedAt requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCreatedAt: %w", err)
+	}
+	return oldValue.CreatedAt, nil
+}
+
+// ResetCreatedAt resets all changes to the "created_at" field.
+func (m *ConversationMessageMutation) ResetCreatedAt() {
+	m.created_at = nil
+}
+
+// ClearConversation clears the "conversation" edge to the Conversation entity.
+func (m *ConversationMessageMutation) ClearConversation() {
+	m.clearedconversation = true
+	m.clearedFields[conversationmessage.FieldConversationID] = struct{}{}
+}
+
+// ConversationCleared reports if the "conversation" edge to the Conversation entity was cleared.
+func (m *ConversationMessageMutation) ConversationCleared() bool {
+	return m.clearedconversation
+}
+
+// ConversationIDs returns the "conversation" edge IDs in the mutation.
+// Note that IDs always returns len(IDs) <= 1 for unique edges, and you should use
+// ConversationID instead. It exists only for internal usage by the builders.
+func (m *ConversationMessageMutation) ConversationIDs() (ids []string) {
+	if id := m.conversation; id != nil {
+		ids = append(ids, *id)
+	}
+	return
+}
+
+// ResetConversation resets all changes to the "conversation" edge.
+func (m *ConversationMessageMutation) ResetConversation() {
+	m.conversation = nil
+	m.clearedconversation = false
+}
+
+// Where appends a list predicates to the ConversationMessageMutation builder.
+func (m *ConversationMessageMutation) Where(ps ...predicate.ConversationMessage) {
+	m.predicates = append(m.predicates, ps...)
+}
+
+// WhereP appends storage-level predicates to the ConversationMessageMutation builder. Using this method,
+// users can use type-assertion to append predicates that do not depend on any generated package.
+func (m *ConversationMessageMutation) WhereP(ps ...func(*sql.Selector)) {
+	p := make([]predicate.ConversationMessage, len(ps))
+	for i := range ps {
+		p[i] = ps[i]
+	}
+	m.Where(p...)
+}
+
+// Op returns the operation name.
+func (m *ConversationMessageMutation) Op() Op {
+	return m.op
+}
+
+// SetOp allows setting the mutation operation.
+func (m *ConversationMessageMutation) SetOp(op Op) {
+	m.op = op
+}
+
+// Type returns the node type of this mutation (ConversationMessage).
+func (m *ConversationMessageMutation) Type() string {
+	return m.typ
+}
+
+// Fields returns all fields that were changed during this mutation. Note that in
+// order to get all numeric fields that were incremented/decremented, call
+// AddedFields().
+func (m *ConversationMessageMutation) Fields() []string {
+	fields := make([]string, 0, 6)
+	if m.conversation != nil {
+		fields = append(fields, conversationmessage.FieldConversationID)
+	}
+	if m.seq != nil {
+		fields = append(fields, conversationmessage.FieldSeq)
+	}
+	if m.role != nil {
+		fields = append(fields, conversationmessage.FieldRole)
+	}
+	if m.content != nil {
+		fields = append(fields, conversationmessage.FieldContent)
+	}
+	if m.interaction_id != nil {
+		fields = append(fields, conversationmessage.FieldInteractionID)
+	}
+	if m.created_at != nil {
+		fields = append(fields, conversationmessage.FieldCreatedAt)
+	}
+	return fields
+}
+
+// Field returns the value of a field with the given name. The second boolean
+// return value indicates that this field was not set, or was not defined in the
+// schema.
+func (m *ConversationMessageMutation) Field(name string) (ent.Value, bool) {
+	switch name {
+	case conversationmessage.FieldConversationID:
+		return m.ConversationID()
+	case conversationmessage.FieldSeq:
+		return m.Seq()
+	case conversationmessage.FieldRole:
+		return m.Role()
+	case conversationmessage.FieldContent:
+		return m.Content()
+	case conversationmessage.FieldInteractionID:
+		return m.InteractionID()
+	case conversationmessage.FieldCreatedAt:
+		return m.CreatedAt()
+	}
+	return nil, false
+}
+
+// OldField returns the old value of the field from the database. An error is
+// returned if the mutation operation is not UpdateOne, or the query to the
+// database failed.
+func (m *ConversationMessageMutation) OldField(ctx context.Context, name string) (ent.Value, error) {
+	switch name {
+	case conversationmessage.FieldConversationID:
+		return m.OldConversationID(ctx)
+	case conversationmessage.FieldSeq:
+		return m.OldSeq(ctx)
+	case conversationmessage.FieldRole:
+		return m.OldRole(ctx)
+	case conversationmessage.FieldContent:
+		return m.OldContent(ctx)
+	case conversationmessage.FieldInteractionID:
+		return m.OldInteractionID(ctx)
+	case conversationmessage.FieldCreatedAt:
+		return m.OldCreatedAt(ctx)
+	}
+	return nil, fmt.Errorf("unknown ConversationMessage field %s", name)
+}
+
+// SetField sets the value of a field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *ConversationMessageMutation) SetField(name string, value ent.Value) error {
+	switch name {
+	case conversationmessage.FieldConversationID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetConversationID(v)
+		return nil
+	case conversationmessage.FieldSeq:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetSeq(v)
+		return nil
+	case conversationmessage.FieldRole:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRole(v)
+		return nil
+	case conversationmessage.FieldContent:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetContent(v)
+		return nil
+	case conversationmessage.FieldInteractionID:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetInteractionID(v)
+		return nil
+	case conversationmessage.FieldCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCreatedAt(v)
+		return nil
+	}
+	return fmt.Errorf("unknown ConversationMessage field %s", name)
+}
+
+// AddedFields returns all numeric fields that were incremented/decremented during
+// this mutation.
+func (m *ConversationMessageMutation) AddedFields() []string {
+	var fields []string
+	if m.addseq != nil {
+		fields = append(fields, conversationmessage.FieldSeq)
+	}
+	return fields
+}
+
+// AddedField returns the numeric value that was incremented/decremented on a field
+// with the given name. The second boolean return value indicates that this field
+// was not set, or was not defined in the schema.
+func (m *ConversationMessageMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case conversationmessage.FieldSeq:
+		return m.AddedSeq()
+	}
+	return nil, false
+}
+
+// AddField adds the value to the field with the given name. It returns an error if
+// the field is not defined in the schema, or if the type mismatched the field
+// type.
+func (m *ConversationMessageMutation) AddField(name string, value ent.Value) error {
+	switch name {
+	case conversationmessage.FieldSeq:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddSeq(v)
+		return nil
+	}
+	return fmt.Errorf("unknown ConversationMessage numeric field %s", name)
+}
+
+// ClearedFields returns all nullable fields that were cleared during this
+// mutation.
+func (m *ConversationMessageMutation) ClearedFields() []string {
+	return nil
+}
+
+// FieldCleared returns a boolean indicating if a field with the given name was
+// cleared in this mutation.
+func (m *ConversationMessageMutation) FieldCleared(name string) bool {
+	_, ok := m.clearedFields[name]
+	return ok
+}
+
+// ClearField clears the value of the field with the given name. It returns an
+// error if the field is not defined in the schema.
+func (m *ConversationMessageMutation) ClearField(name string) error {
+	return fmt.Errorf("unknown ConversationMessage nullable field %s", name)
+}
+
+// ResetField resets all changes in the mutation for the field with the given name.
+// It returns an error if the field is not defined in the schema.
+func (m *ConversationMessageMutation) ResetField(name string) error {
+	switch name {
+	case conversationmessage.FieldConversationID:
+		m.ResetConversationID()
+		return nil
+	case conversationmessage.FieldSeq:
+		m.ResetSeq()
+		return nil
+	case conversationmessage.FieldRole:
+		m.ResetRole()
+		return nil
+	case conversationmessage.FieldContent:
+		m.ResetContent()
+		return nil
+	case conversationmessage.FieldInteractionID:
+		m.ResetInteractionID()
+		return nil
+	case conversationmessage.FieldCreatedAt:
+		m.ResetCreatedAt()
+		return nil
+	}
+	return fmt.Errorf("unknown ConversationMessage field %s", name)
+}
+
+// AddedEdges returns all edge names that were set/added in this mutation.
+func (m *ConversationMessageMutation) AddedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.conversation != nil {
+		edges = append(edges, conversationmessage.EdgeConversation)
+	}
+	return edges
+}
+
+// AddedIDs returns all IDs (to other nodes) that were added for the given edge
+// name in this mutation.
+func (m *ConversationMessageMutation) AddedIDs(name string) []ent.Value {
+	switch name {
+	case conversationmessage.EdgeConversation:
+		if id := m.conversation; id != nil {
+			return []ent.Value{*id}
+		}
+	}
+	return nil
+}
+
+// RemovedEdges returns all edge names that were removed in this mutation.
+func (m *ConversationMessageMutation) RemovedEdges() []string {
+	edges := make([]string, 0, 1)
+	return edges
+}
+
+// RemovedIDs returns all IDs (to other nodes) that were removed for the edge with
+// the given name in this mutation.
+func (m *ConversationMessageMutation) RemovedIDs(name string) []ent.Value {
+	return nil
+}
+
+// ClearedEdges returns all edge names that were cleared in this mutation.
+func (m *ConversationMessageMutation) ClearedEdges() []string {
+	edges := make([]string, 0, 1)
+	if m.clearedconversation {
+		edges = append(edges, conversationmessage.EdgeConversation)
+	}
+	return edges
+}
+
+// EdgeCleared returns a boolean which indicates if the edge with the given name
+// was cleared in this mutation.
+func (m *ConversationMessageMutation) EdgeCleared(name string) bool {
+	switch name {
+	case conversationmessage.EdgeConversation:
+		return m.clearedconversation
+	}
+	return false
+}
+
+// ClearEdge clears the value of the edge with the given name. It returns an error
+// if that edge is not defined in the schema.
+func (m *ConversationMessageMutation) ClearEdge(name string) error {
+	switch name {
+	case conversationmessage.EdgeConversation:
+		m.ClearConversation()
+		return nil
+	}
+	return fmt.Errorf("unknown ConversationMessage unique edge %s", name)
+}
+
+// ResetEdge resets all changes to the edge with the given name in this mutation.
+// It returns an error if the edge is not defined in the schema.
+func (m *ConversationMessageMutation) ResetEdge(name string) error {
+	switch name {
+	case conversationmessage.EdgeConversation:
+		m.ResetConversation()
+		return nil
+	}
+	return fmt.Errorf("unknown ConversationMessage edge %s", name)
+}
+
 // ProjectMutation represents an operation that mutates the Project nodes in the graph.
 type ProjectMutation struct {
 	config
-	op               Op
-	typ              string
-	id               *string
-	name             *string
-	user_id          *string
-	repo             *string
-	is_active        *bool
-	clearedFields    map[string]struct{}
-	artifacts        map[int]struct{}
-	removedartifacts map[int]struct{}
-	clearedartifacts bool
-	done             bool
-	oldValue         func(context.Context) (*Project, error)
-	predicates       []predicate.Project
+	op                       Op
+	typ                      string
+	id                       *string
+	name                     *string
+	user_id                  *string
+	repo                     *string
+	repos                    *[]entity.RepoEntry
+	appendrepos              []entity.RepoEntry
+	is_active                *bool
+	cost_budget_usd          *float64
+	addcost_budget_usd       *float64
+	input_wait_timeout_ms    *int64
+	addinput_wait_timeout_ms *int64
+	locale                   *string
+	min_code_ratio           *float64
+	addmin_code_ratio        *float64
+	min_code_bytes           *int64
+	addmin_code_bytes        *int64
+	clearedFields            map[string]struct{}
+	artifacts                map[int]struct{}
+	removedartifacts         map[int]struct{}
+	clearedartifacts         bool
+	done                     bool
+	oldValue                 func(context.Context) (*Project, error)
+	predicates               []predicate.Project
 }
 
 var _ ent.Mutation = (*ProjectMutation)(nil)
@@ -1454,6 +2695,71 @@ func (m *ProjectMutation) ResetRepo() {
 	m.repo = nil
 }
 
+// SetRepos sets the "repos" field.
+func (m *ProjectMutation) SetRepos(ee []entity.RepoEntry) {
+	m.repos = &ee
+	m.appendrepos = nil
+}
+
+// Repos returns the value of the "repos" field in the mutation.
+func (m *ProjectMutation) Repos() (r []entity.RepoEntry, exists bool) {
+	v := m.repos
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldRepos returns the old "repos" field's value of the Project entity.
+// If the Project object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ProjectMutation) OldRepos(ctx context.Context) (v []entity.RepoEntry, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldRepos is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldRepos requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldRepos: %w", err)
+	}
+	return oldValue.Repos, nil
+}
+
+// AppendRepos adds ee to the "repos" field.
+func (m *ProjectMutation) AppendRepos(ee []entity.RepoEntry) {
+	m.appendrepos = append(m.appendrepos, ee...)
+}
+
+// AppendedRepos returns the list of values that were appended to the "repos" field in this mutation.
+func (m *ProjectMutation) AppendedRepos() ([]entity.RepoEntry, bool) {
+	if len(m.appendrepos) == 0 {
+		return nil, false
+	}
+	return m.appendrepos, true
+}
+
+// ClearRepos clears the value of the "repos" field.
+func (m *ProjectMutation) ClearRepos() {
+	m.repos = nil
+	m.appendrepos = nil
+	m.clearedFields[project.FieldRepos] = struct{}{}
+}
+
+// ReposCleared returns if the "repos" field was cleared in this mutation.
+func (m *ProjectMutation) ReposCleared() bool {
+	_, ok := m.clearedFields[project.FieldRepos]
+	return ok
+}
+
+// ResetRepos resets all changes to the "repos" field.
+func (m *ProjectMutation) ResetRepos() {
+	m.repos = nil
+	m.appendrepos = nil
+	delete(m.clearedFields, project.FieldRepos)
+}
+
 // SetIsActive sets the "is_active" field.
 func (m *ProjectMutation) SetIsActive(b bool) {
 	m.is_active = &b
@@ -1490,6 +2796,266 @@ func (m *ProjectMutation) ResetIsActive() {
 	m.is_active = nil
 }
 
+// SetCostBudgetUsd sets the "cost_budget_usd" field.
+func (m *ProjectMutation) SetCostBudgetUsd(f float64) {
+	m.cost_budget_usd = &f
+	m.addcost_budget_usd = nil
+}
+
+// CostBudgetUsd returns the value of the "cost_budget_usd" field in the mutation.
+func (m *ProjectMutation) CostBudgetUsd() (r float64, exists bool) {
+	v := m.cost_budget_usd
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldCostBudgetUsd returns the old "cost_budget_usd" field's value of the Project entity.
+// If the Project object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ProjectMutation) OldCostBudgetUsd(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldCostBudgetUsd is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldCostBudgetUsd requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldCostBudgetUsd: %w", err)
+	}
+	return oldValue.CostBudgetUsd, nil
+}
+
+// AddCostBudgetUsd adds f to the "cost_budget_usd" field.
+func (m *ProjectMutation) AddCostBudgetUsd(f float64) {
+	if m.addcost_budget_usd != nil {
+		*m.addcost_budget_usd += f
+	} else {
+		m.addcost_budget_usd = &f
+	}
+}
+
+// AddedCostBudgetUsd returns the value that was added to the "cost_budget_usd" field in this mutation.
+func (m *ProjectMutation) AddedCostBudgetUsd() (r float64, exists bool) {
+	v := m.addcost_budget_usd
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetCostBudgetUsd resets all changes to the "cost_budget_usd" field.
+func (m *ProjectMutation) ResetCostBudgetUsd() {
+	m.cost_budget_usd = nil
+	m.addcost_budget_usd = nil
+}
+
+// SetInputWaitTimeoutMs sets the "input_wait_timeout_ms" field.
+func (m *ProjectMutation) SetInputWaitTimeoutMs(i int64) {
+	m.input_wait_timeout_ms = &i
+	m.addinput_wait_timeout_ms = nil
+}
+
+// InputWaitTimeoutMs returns the value of the "input_wait_timeout_ms" field in the mutation.
+func (m *ProjectMutation) InputWaitTimeoutMs() (r int64, exists bool) {
+	v := m.input_wait_timeout_ms
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldInputWaitTimeoutMs returns the old "input_wait_timeout_ms" field's value of the Project entity.
+// If the Project object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ProjectMutation) OldInputWaitTimeoutMs(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldInputWaitTimeoutMs is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldInputWaitTimeoutMs requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldInputWaitTimeoutMs: %w", err)
+	}
+	return oldValue.InputWaitTimeoutMs, nil
+}
+
+// AddInputWaitTimeoutMs adds i to the "input_wait_timeout_ms" field.
+func (m *ProjectMutation) AddInputWaitTimeoutMs(i int64) {
+	if m.addinput_wait_timeout_ms != nil {
+		*m.addinput_wait_timeout_ms += i
+	} else {
+		m.addinput_wait_timeout_ms = &i
+	}
+}
+
+// AddedInputWaitTimeoutMs returns the value that was added to the "input_wait_timeout_ms" field in this mutation.
+func (m *ProjectMutation) AddedInputWaitTimeoutMs() (r int64, exists bool) {
+	v := m.addinput_wait_timeout_ms
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetInputWaitTimeoutMs resets all changes to the "input_wait_timeout_ms" field.
+func (m *ProjectMutation) ResetInputWaitTimeoutMs() {
+	m.input_wait_timeout_ms = nil
+	m.addinput_wait_timeout_ms = nil
+}
+
+// SetLocale sets the "locale" field.
+func (m *ProjectMutation) SetLocale(s string) {
+	m.locale = &s
+}
+
+// Locale returns the value of the "locale" field in the mutation.
+func (m *ProjectMutation) Locale() (r string, exists bool) {
+	v := m.locale
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldLocale returns the old "locale" field's value of the Project entity.
+// If the Project object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ProjectMutation) OldLocale(ctx context.Context) (v string, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldLocale is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldLocale requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldLocale: %w", err)
+	}
+	return oldValue.Locale, nil
+}
+
+// ResetLocale resets all changes to the "locale" field.
+func (m *ProjectMutation) ResetLocale() {
+	m.locale = nil
+}
+
+// SetMinCodeRatio sets the "min_code_ratio" field.
+func (m *ProjectMutation) SetMinCodeRatio(f float64) {
+	m.min_code_ratio = &f
+	m.addmin_code_ratio = nil
+}
+
+// MinCodeRatio returns the value of the "min_code_ratio" field in the mutation.
+func (m *ProjectMutation) MinCodeRatio() (r float64, exists bool) {
+	v := m.min_code_ratio
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMinCodeRatio returns the old "min_code_ratio" field's value of the Project entity.
+// If the Project object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ProjectMutation) OldMinCodeRatio(ctx context.Context) (v float64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMinCodeRatio is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMinCodeRatio requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMinCodeRatio: %w", err)
+	}
+	return oldValue.MinCodeRatio, nil
+}
+
+// AddMinCodeRatio adds f to the "min_code_ratio" field.
+func (m *ProjectMutation) AddMinCodeRatio(f float64) {
+	if m.addmin_code_ratio != nil {
+		*m.addmin_code_ratio += f
+	} else {
+		m.addmin_code_ratio = &f
+	}
+}
+
+// AddedMinCodeRatio returns the value that was added to the "min_code_ratio" field in this mutation.
+func (m *ProjectMutation) AddedMinCodeRatio() (r float64, exists bool) {
+	v := m.addmin_code_ratio
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMinCodeRatio resets all changes to the "min_code_ratio" field.
+func (m *ProjectMutation) ResetMinCodeRatio() {
+	m.min_code_ratio = nil
+	m.addmin_code_ratio = nil
+}
+
+// SetMinCodeBytes sets the "min_code_bytes" field.
+func (m *ProjectMutation) SetMinCodeBytes(i int64) {
+	m.min_code_bytes = &i
+	m.addmin_code_bytes = nil
+}
+
+// MinCodeBytes returns the value of the "min_code_bytes" field in the mutation.
+func (m *ProjectMutation) MinCodeBytes() (r int64, exists bool) {
+	v := m.min_code_bytes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// OldMinCodeBytes returns the old "min_code_bytes" field's value of the Project entity.
+// If the Project object wasn't provided to the builder, the object is fetched from the database.
+// An error is returned if the mutation operation is not UpdateOne, or the database query fails.
+func (m *ProjectMutation) OldMinCodeBytes(ctx context.Context) (v int64, err error) {
+	if !m.op.Is(OpUpdateOne) {
+		return v, errors.New("OldMinCodeBytes is only allowed on UpdateOne operations")
+	}
+	if m.id == nil || m.oldValue == nil {
+		return v, errors.New("OldMinCodeBytes requires an ID field in the mutation")
+	}
+	oldValue, err := m.oldValue(ctx)
+	if err != nil {
+		return v, fmt.Errorf("querying old value for OldMinCodeBytes: %w", err)
+	}
+	return oldValue.MinCodeBytes, nil
+}
+
+// AddMinCodeBytes adds i to the "min_code_bytes" field.
+func (m *ProjectMutation) AddMinCodeBytes(i int64) {
+	if m.addmin_code_bytes != nil {
+		*m.addmin_code_bytes += i
+	} else {
+		m.addmin_code_bytes = &i
+	}
+}
+
+// AddedMinCodeBytes returns the value that was added to the "min_code_bytes" field in this mutation.
+func (m *ProjectMutation) AddedMinCodeBytes() (r int64, exists bool) {
+	v := m.addmin_code_bytes
+	if v == nil {
+		return
+	}
+	return *v, true
+}
+
+// ResetMinCodeBytes resets all changes to the "min_code_bytes" field.
+func (m *ProjectMutation) ResetMinCodeBytes() {
+	m.min_code_bytes = nil
+	m.addmin_code_bytes = nil
+}
+
 // AddArtifactIDs adds the "artifacts" edge to the Artifact entity by ids.
 func (m *ProjectMutation) AddArtifactIDs(ids ...int) {
 	if m.artifacts == nil {
@@ -1578,7 +3144,7 @@ func (m *ProjectMutation) Type() string {
 // order to get all numeric fields that were incremented/decremented, call
 // AddedFields().
 func (m *ProjectMutation) Fields() []string {
-	fields := make([]string, 0, 4)
+	fields := make([]string, 0, 10)
 	if m.name != nil {
 		fields = append(fields, project.FieldName)
 	}
@@ -1588,9 +3154,27 @@ func (m *ProjectMutation) Fields() []string {
 	if m.repo != nil {
 		fields = append(fields, project.FieldRepo)
 	}
+	if m.repos != nil {
+		fields = append(fields, project.FieldRepos)
+	}
 	if m.is_active != nil {
 		fields = append(fields, project.FieldIsActive)
 	}
+	if m.cost_budget_usd != nil {
+		fields = append(fields, project.FieldCostBudgetUsd)
+	}
+	if m.input_wait_timeout_ms != nil {
+		fields = append(fields, project.FieldInputWaitTimeoutMs)
+	}
+	if m.locale != nil {
+		fields = append(fields, project.FieldLocale)
+	}
+	if m.min_code_ratio != nil {
+		fields = append(fields, project.FieldMinCodeRatio)
+	}
+	if m.min_code_bytes != nil {
+		fields = append(fields, project.FieldMinCodeBytes)
+	}
 	return fields
 }
 
@@ -1605,8 +3189,20 @@ func (m *ProjectMutation) Field(name string) (ent.Value, bool) {
 		return m.UserID()
 	case project.FieldRepo:
 		return m.Repo()
+	case project.FieldRepos:
+		return m.Repos()
 	case project.FieldIsActive:
 		return m.IsActive()
+	case project.FieldCostBudgetUsd:
+		return m.CostBudgetUsd()
+	case project.FieldInputWaitTimeoutMs:
+		return m.InputWaitTimeoutMs()
+	case project.FieldLocale:
+		return m.Locale()
+	case project.FieldMinCodeRatio:
+		return m.MinCodeRatio()
+	case project.FieldMinCodeBytes:
+		return m.MinCodeBytes()
 	}
 	return nil, false
 }
@@ -1622,8 +3218,20 @@ func (m *ProjectMutation) OldField(ctx context.Context, name string) (ent.Value,
 		return m.OldUserID(ctx)
 	case project.FieldRepo:
 		return m.OldRepo(ctx)
+	case project.FieldRepos:
+		return m.OldRepos(ctx)
 	case project.FieldIsActive:
 		return m.OldIsActive(ctx)
+	case project.FieldCostBudgetUsd:
+		return m.OldCostBudgetUsd(ctx)
+	case project.FieldInputWaitTimeoutMs:
+		return m.OldInputWaitTimeoutMs(ctx)
+	case project.FieldLocale:
+		return m.OldLocale(ctx)
+	case project.FieldMinCodeRatio:
+		return m.OldMinCodeRatio(ctx)
+	case project.FieldMinCodeBytes:
+		return m.OldMinCodeBytes(ctx)
 	}
 	return nil, fmt.Errorf("unknown Project field %s", name)
 }
@@ -1654,6 +3262,13 @@ func (m *ProjectMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetRepo(v)
 		return nil
+	case project.FieldRepos:
+		v, ok := value.([]entity.RepoEntry)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetRepos(v)
+		return nil
 	case project.FieldIsActive:
 		v, ok := value.(bool)
 		if !ok {
@@ -1661,6 +3276,41 @@ func (m *ProjectMutation) SetField(name string, value ent.Value) error {
 		}
 		m.SetIsActive(v)
 		return nil
+	case project.FieldCostBudgetUsd:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetCostBudgetUsd(v)
+		return nil
+	case project.FieldInputWaitTimeoutMs:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetInputWaitTimeoutMs(v)
+		return nil
+	case project.FieldLocale:
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetLocale(v)
+		return nil
+	case project.FieldMinCodeRatio:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMinCodeRatio(v)
+		return nil
+	case project.FieldMinCodeBytes:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.SetMinCodeBytes(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Project field %s", name)
 }
@@ -1668,13 +3318,36 @@ func (m *ProjectMutation) SetField(name string, value ent.Value) error {
 // AddedFields returns all numeric fields that were incremented/decremented during
 // this mutation.
 func (m *ProjectMutation) AddedFields() []string {
-	return nil
+	var fields []string
+	if m.addcost_budget_usd != nil {
+		fields = append(fields, project.FieldCostBudgetUsd)
+	}
+	if m.addinput_wait_timeout_ms != nil {
+		fields = append(fields, project.FieldInputWaitTimeoutMs)
+	}
+	if m.addmin_code_ratio != nil {
+		fields = append(fields, project.FieldMinCodeRatio)
+	}
+	if m.addmin_code_bytes != nil {
+		fields = append(fields, project.FieldMinCodeBytes)
+	}
+	return fields
 }
 
 // AddedField returns the numeric value that was incremented/decremented on a field
 // with the given name. The second boolean return value indicates that this field
 // was not set, or was not defined in the schema.
 func (m *ProjectMutation) AddedField(name string) (ent.Value, bool) {
+	switch name {
+	case project.FieldCostBudgetUsd:
+		return m.AddedCostBudgetUsd()
+	case project.FieldInputWaitTimeoutMs:
+		return m.AddedInputWaitTimeoutMs()
+	case project.FieldMinCodeRatio:
+		return m.AddedMinCodeRatio()
+	case project.FieldMinCodeBytes:
+		return m.AddedMinCodeBytes()
+	}
 	return nil, false
 }
 
@@ -1683,6 +3356,34 @@ func (m *ProjectMutation) AddedField(name string) (ent.Value, bool) {
 // type.
 func (m *ProjectMutation) AddField(name string, value ent.Value) error {
 	switch name {
+	case project.FieldCostBudgetUsd:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddCostBudgetUsd(v)
+		return nil
+	case project.FieldInputWaitTimeoutMs:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddInputWaitTimeoutMs(v)
+		return nil
+	case project.FieldMinCodeRatio:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMinCodeRatio(v)
+		return nil
+	case project.FieldMinCodeBytes:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("unexpected type %T for field %s", value, name)
+		}
+		m.AddMinCodeBytes(v)
+		return nil
 	}
 	return fmt.Errorf("unknown Project numeric field %s", name)
 }
@@ -1690,7 +3391,11 @@ func (m *ProjectMutation) AddField(name string, value ent.Value) error {
 // ClearedFields returns all nullable fields that were cleared during this
 // mutation.
 func (m *ProjectMutation) ClearedFields() []string {
-	return nil
+	var fields []string
+	if m.FieldCleared(project.FieldRepos) {
+		fields = append(fields, project.FieldRepos)
+	}
+	return fields
 }
 
 // FieldCleared returns a boolean indicating if a field with the given name was
@@ -1703,6 +3408,11 @@ func (m *ProjectMutation) FieldCleared(name string) bool {
 // ClearField clears the value of the field with the given name. It returns an
 // error if the field is not defined in the schema.
 func (m *ProjectMutation) ClearField(name string) error {
+	switch name {
+	case project.FieldRepos:
+		m.ClearRepos()
+		return nil
+	}
 	return fmt.Errorf("unknown Project nullable field %s", name)
 }
 
@@ -1719,9 +3429,27 @@ func (m *ProjectMutation) ResetField(name string) error {
 	case project.FieldRepo:
 		m.ResetRepo()
 		return nil
+	case project.FieldRepos:
+		m.ResetRepos()
+		return nil
 	case project.FieldIsActive:
 		m.ResetIsActive()
 		return nil
+	case project.FieldCostBudgetUsd:
+		m.ResetCostBudgetUsd()
+		return nil
+	case project.FieldInputWaitTimeoutMs:
+		m.ResetInputWaitTimeoutMs()
+		return nil
+	case project.FieldLocale:
+		m.ResetLocale()
+		return nil
+	case project.FieldMinCodeRatio:
+		m.ResetMinCodeRatio()
+		return nil
+	case project.FieldMinCodeBytes:
+		m.ResetMinCodeBytes()
+		return nil
 	}
 	return fmt.Errorf("unknown Project field %s", name)
 }
@@ -12,6 +12,12 @@ type Artifact func(*sql.Selector)
 // ArtifactFile is the predicate function for artifactfile builders.
 type ArtifactFile func(*sql.Selector)
 
+// Conversation is the predicate function for conversation builders.
+type Conversation func(*sql.Selector)
+
+// ConversationMessage is the predicate function for conversationmessage builders.
+type ConversationMessage func(*sql.Selector)
+
 // Project is the predicate function for project builders.
 type Project func(*sql.Selector)
 
@@ -3,8 +3,10 @@
 package ent
 
 import (
+	"encoding/json"
 	"fmt"
 	"insightify/internal/gateway/ent/project"
+	"insightify/internal/gateway/entity"
 	"strings"
 
 	"entgo.io/ent"
@@ -22,8 +24,20 @@ type Project struct {
 	UserID string `json:"user_id,omitempty"`
 	// Repo holds the value of the "repo" field.
 	Repo string `json:"repo,omitempty"`
+	// Repos holds the value of the "repos" field.
+	Repos []entity.RepoEntry `json:"repos,omitempty"`
 	// IsActive holds the value of the "is_active" field.
 	IsActive bool `json:"is_active,omitempty"`
+	// CostBudgetUsd holds the value of the "cost_budget_usd" field.
+	CostBudgetUsd float64 `json:"cost_budget_usd,omitempty"`
+	// InputWaitTimeoutMs holds the value of the "input_wait_timeout_ms" field.
+	InputWaitTimeoutMs int64 `json:"input_wait_timeout_ms,omitempty"`
+	// Locale holds the value of the "locale" field.
+	Locale string `json:"locale,omitempty"`
+	// MinCodeRatio holds the value of the "min_code_ratio" field.
+	MinCodeRatio float64 `json:"min_code_ratio,omitempty"`
+	// MinCodeBytes holds the value of the "min_code_bytes" field.
+	MinCodeBytes int64 `json:"min_code_bytes,omitempty"`
 	// Edges holds the relations/edges for other nodes in the graph.
 	// The values are being populated by the ProjectQuery when eager-loading is set.
 	Edges        ProjectEdges `json:"edges"`
@@ -53,9 +67,15 @@ func (*Project) scanValues(columns []string) ([]any, error) {
 	values := make([]any, len(columns))
 	for i := range columns {
 		switch columns[i] {
+		case project.FieldRepos:
+			values[i] = new([]byte)
 		case project.FieldIsActive:
 			values[i] = new(sql.NullBool)
-		case project.FieldID, project.FieldName, project.FieldUserID, project.FieldRepo:
+		case project.FieldCostBudgetUsd, project.FieldMinCodeRatio:
+			values[i] = new(sql.NullFloat64)
+		case project.FieldInputWaitTimeoutMs, project.FieldMinCodeBytes:
+			values[i] = new(sql.NullInt64)
+		case project.FieldID, project.FieldName, project.FieldUserID, project.FieldRepo, project.FieldLocale:
 			values[i] = new(sql.NullString)
 		default:
 			values[i] = new(sql.UnknownType)
@@ -96,12 +116,50 @@ func (_m *Project) assignValues(columns []string, values []any) error {
 			} else if value.Valid {
 				_m.Repo = value.String
 			}
+		case project.FieldRepos:
+			if value, ok := values[i].(*[]byte); !ok {
+				return fmt.Errorf("unexpected type %T for field repos", values[i])
+			} else if value != nil && len(*value) > 0 {
+				if err := json.Unmarshal(*value, &_m.Repos); err != nil {
+					return fmt.Errorf("unmarshal field repos: %w", err)
+				}
+			}
 		case project.FieldIsActive:
 			if value, ok := values[i].(*sql.NullBool); !ok {
 				return fmt.Errorf("unexpected type %T for field is_active", values[i])
 			} else if value.Valid {
 				_m.IsActive = value.Bool
 			}
+		case project.FieldCostBudgetUsd:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field cost_budget_usd", values[i])
+			} else if value.Valid {
+				_m.CostBudgetUsd = value.Float64
+			}
+		case project.FieldInputWaitTimeoutMs:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field input_wait_timeout_ms", values[i])
+			} else if value.Valid {
+				_m.InputWaitTimeoutMs = value.Int64
+			}
+		case project.FieldLocale:
+			if value, ok := values[i].(*sql.NullString); !ok {
+				return fmt.Errorf("unexpected type %T for field locale", values[i])
+			} else if value.Valid {
+				_m.Locale = value.String
+			}
+		case project.FieldMinCodeRatio:
+			if value, ok := values[i].(*sql.NullFloat64); !ok {
+				return fmt.Errorf("unexpected type %T for field min_code_ratio", values[i])
+			} else if value.Valid {
+				_m.MinCodeRatio = value.Float64
+			}
+		case project.FieldMinCodeBytes:
+			if value, ok := values[i].(*sql.NullInt64); !ok {
+				return fmt.Errorf("unexpected type %T for field min_code_bytes", values[i])
+			} else if value.Valid {
+				_m.MinCodeBytes = value.Int64
+			}
 		default:
 			_m.selectValues.Set(columns[i], values[i])
 		}
@@ -152,8 +210,26 @@ func (_m *Project) String() string {
 	builder.WriteString("repo=")
 	builder.WriteString(_m.Repo)
 	builder.WriteString(", ")
+	builder.WriteString("repos=")
+	builder.WriteString(fmt.Sprintf("%v", _m.Repos))
+	builder.WriteString(", ")
 	builder.WriteString("is_active=")
 	builder.WriteString(fmt.Sprintf("%v", _m.IsActive))
+	builder.WriteString(", ")
+	builder.WriteString("cost_budget_usd=")
+	builder.WriteString(fmt.Sprintf("%v", _m.CostBudgetUsd))
+	builder.WriteString(", ")
+	builder.WriteString("input_wait_timeout_ms=")
+	builder.WriteString(fmt.Sprintf("%v", _m.InputWaitTimeoutMs))
+	builder.WriteString(", ")
+	builder.WriteString("locale=")
+	builder.WriteString(_m.Locale)
+	builder.WriteString(", ")
+	builder.WriteString("min_code_ratio=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MinCodeRatio))
+	builder.WriteString(", ")
+	builder.WriteString("min_code_bytes=")
+	builder.WriteString(fmt.Sprintf("%v", _m.MinCodeBytes))
 	builder.WriteByte(')')
 	return builder.String()
 }
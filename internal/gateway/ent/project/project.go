@@ -18,8 +18,20 @@ const (
 	FieldUserID = "user_id"
 	// FieldRepo holds the string denoting the repo field in the database.
 	FieldRepo = "repo"
+	// FieldRepos holds the string denoting the repos field in the database.
+	FieldRepos = "repos"
 	// FieldIsActive holds the string denoting the is_active field in the database.
 	FieldIsActive = "is_active"
+	// FieldCostBudgetUsd holds the string denoting the cost_budget_usd field in the database.
+	FieldCostBudgetUsd = "cost_budget_usd"
+	// FieldInputWaitTimeoutMs holds the string denoting the input_wait_timeout_ms field in the database.
+	FieldInputWaitTimeoutMs = "input_wait_timeout_ms"
+	// FieldLocale holds the string denoting the locale field in the database.
+	FieldLocale = "locale"
+	// FieldMinCodeRatio holds the string denoting the min_code_ratio field in the database.
+	FieldMinCodeRatio = "min_code_ratio"
+	// FieldMinCodeBytes holds the string denoting the min_code_bytes field in the database.
+	FieldMinCodeBytes = "min_code_bytes"
 	// EdgeArtifacts holds the string denoting the artifacts edge name in mutations.
 	EdgeArtifacts = "artifacts"
 	// ArtifactFieldID holds the string denoting the ID field of the Artifact.
@@ -41,7 +53,13 @@ var Columns = []string{
 	FieldName,
 	FieldUserID,
 	FieldRepo,
+	FieldRepos,
 	FieldIsActive,
+	FieldCostBudgetUsd,
+	FieldInputWaitTimeoutMs,
+	FieldLocale,
+	FieldMinCodeRatio,
+	FieldMinCodeBytes,
 }
 
 // ValidColumn reports if the column name is valid (part of the table columns).
@@ -63,6 +81,16 @@ var (
 	DefaultRepo string
 	// DefaultIsActive holds the default value on creation for the "is_active" field.
 	DefaultIsActive bool
+	// DefaultCostBudgetUsd holds the default value on creation for the "cost_budget_usd" field.
+	DefaultCostBudgetUsd float64
+	// DefaultInputWaitTimeoutMs holds the default value on creation for the "input_wait_timeout_ms" field.
+	DefaultInputWaitTimeoutMs int64
+	// DefaultLocale holds the default value on creation for the "locale" field.
+	DefaultLocale string
+	// DefaultMinCodeRatio holds the default value on creation for the "min_code_ratio" field.
+	DefaultMinCodeRatio float64
+	// DefaultMinCodeBytes holds the default value on creation for the "min_code_bytes" field.
+	DefaultMinCodeBytes int64
 )
 
 // OrderOption defines the ordering options for the Project queries.
@@ -93,6 +121,31 @@ func ByIsActive(opts ...sql.OrderTermOption) OrderOption {
 	return sql.OrderByField(FieldIsActive, opts...).ToFunc()
 }
 
+// ByCostBudgetUsd orders the results by the cost_budget_usd field.
+func ByCostBudgetUsd(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldCostBudgetUsd, opts...).ToFunc()
+}
+
+// ByInputWaitTimeoutMs orders the results by the input_wait_timeout_ms field.
+func ByInputWaitTimeoutMs(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldInputWaitTimeoutMs, opts...).ToFunc()
+}
+
+// ByLocale orders the results by the locale field.
+func ByLocale(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldLocale, opts...).ToFunc()
+}
+
+// ByMinCodeRatio orders the results by the min_code_ratio field.
+func ByMinCodeRatio(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMinCodeRatio, opts...).ToFunc()
+}
+
+// ByMinCodeBytes orders the results by the min_code_bytes field.
+func ByMinCodeBytes(opts ...sql.OrderTermOption) OrderOption {
+	return sql.OrderByField(FieldMinCodeBytes, opts...).ToFunc()
+}
+
 // ByArtifactsCount orders the results by artifacts count.
 func ByArtifactsCount(opts ...sql.OrderTermOption) OrderOption {
 	return func(s *sql.Selector) {
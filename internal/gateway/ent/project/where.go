@@ -84,6 +84,31 @@ func IsActive(v bool) predicate.Project {
 	return predicate.Project(sql.FieldEQ(FieldIsActive, v))
 }
 
+// CostBudgetUsd applies equality check predicate on the "cost_budget_usd" field. It's identical to CostBudgetUsdEQ.
+func CostBudgetUsd(v float64) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldCostBudgetUsd, v))
+}
+
+// InputWaitTimeoutMs applies equality check predicate on the "input_wait_timeout_ms" field. It's identical to InputWaitTimeoutMsEQ.
+func InputWaitTimeoutMs(v int64) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldInputWaitTimeoutMs, v))
+}
+
+// Locale applies equality check predicate on the "locale" field. It's identical to LocaleEQ.
+func Locale(v string) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldLocale, v))
+}
+
+// MinCodeRatio applies equality check predicate on the "min_code_ratio" field. It's identical to MinCodeRatioEQ.
+func MinCodeRatio(v float64) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldMinCodeRatio, v))
+}
+
+// MinCodeBytes applies equality check predicate on the "min_code_bytes" field. It's identical to MinCodeBytesEQ.
+func MinCodeBytes(v int64) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldMinCodeBytes, v))
+}
+
 // NameEQ applies the EQ predicate on the "name" field.
 func NameEQ(v string) predicate.Project {
 	return predicate.Project(sql.FieldEQ(FieldName, v))
@@ -279,6 +304,16 @@ func RepoContainsFold(v string) predicate.Project {
 	return predicate.Project(sql.FieldContainsFold(FieldRepo, v))
 }
 
+// ReposIsNil applies the IsNil predicate on the "repos" field.
+func ReposIsNil() predicate.Project {
+	return predicate.Project(sql.FieldIsNull(FieldRepos))
+}
+
+// ReposNotNil applies the NotNil predicate on the "repos" field.
+func ReposNotNil() predicate.Project {
+	return predicate.Project(sql.FieldNotNull(FieldRepos))
+}
+
 // IsActiveEQ applies the EQ predicate on the "is_active" field.
 func IsActiveEQ(v bool) predicate.Project {
 	return predicate.Project(sql.FieldEQ(FieldIsActive, v))
@@ -289,6 +324,231 @@ func IsActiveNEQ(v bool) predicate.Project {
 	return predicate.Project(sql.FieldNEQ(FieldIsActive, v))
 }
 
+// CostBudgetUsdEQ applies the EQ predicate on the "cost_budget_usd" field.
+func CostBudgetUsdEQ(v float64) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldCostBudgetUsd, v))
+}
+
+// CostBudgetUsdNEQ applies the NEQ predicate on the "cost_budget_usd" field.
+func CostBudgetUsdNEQ(v float64) predicate.Project {
+	return predicate.Project(sql.FieldNEQ(FieldCostBudgetUsd, v))
+}
+
+// CostBudgetUsdIn applies the In predicate on the "cost_budget_usd" field.
+func CostBudgetUsdIn(vs ...float64) predicate.Project {
+	return predicate.Project(sql.FieldIn(FieldCostBudgetUsd, vs...))
+}
+
+// CostBudgetUsdNotIn applies the NotIn predicate on the "cost_budget_usd" field.
+func CostBudgetUsdNotIn(vs ...float64) predicate.Project {
+	return predicate.Project(sql.FieldNotIn(FieldCostBudgetUsd, vs...))
+}
+
+// CostBudgetUsdGT applies the GT predicate on the "cost_budget_usd" field.
+func CostBudgetUsdGT(v float64) predicate.Project {
+	return predicate.Project(sql.FieldGT(FieldCostBudgetUsd, v))
+}
+
+// CostBudgetUsdGTE applies the GTE predicate on the "cost_budget_usd" field.
+func CostBudgetUsdGTE(v float64) predicate.Project {
+	return predicate.Project(sql.FieldGTE(FieldCostBudgetUsd, v))
+}
+
+// CostBudgetUsdLT applies the LT predicate on the "cost_budget_usd" field.
+func CostBudgetUsdLT(v float64) predicate.Project {
+	return predicate.Project(sql.FieldLT(FieldCostBudgetUsd, v))
+}
+
+// CostBudgetUsdLTE applies the LTE predicate on the "cost_budget_usd" field.
+func CostBudgetUsdLTE(v float64) predicate.Project {
+	return predicate.Project(sql.FieldLTE(FieldCostBudgetUsd, v))
+}
+
+// InputWaitTimeoutMsEQ applies the EQ predicate on the "input_wait_timeout_ms" field.
+func InputWaitTimeoutMsEQ(v int64) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldInputWaitTimeoutMs, v))
+}
+
+// InputWaitTimeoutMsNEQ applies the NEQ predicate on the "input_wait_timeout_ms" field.
+func InputWaitTimeoutMsNEQ(v int64) predicate.Project {
+	return predicate.Project(sql.FieldNEQ(FieldInputWaitTimeoutMs, v))
+}
+
+// InputWaitTimeoutMsIn applies the In predicate on the "input_wait_timeout_ms" field.
+func InputWaitTimeoutMsIn(vs ...int64) predicate.Project {
+	return predicate.Project(sql.FieldIn(FieldInputWaitTimeoutMs, vs...))
+}
+
+// InputWaitTimeoutMsNotIn applies the NotIn predicate on the "input_wait_timeout_ms" field.
+func InputWaitTimeoutMsNotIn(vs ...int64) predicate.Project {
+	return predicate.Project(sql.FieldNotIn(FieldInputWaitTimeoutMs, vs...))
+}
+
+// InputWaitTimeoutMsGT applies the GT predicate on the "input_wait_timeout_ms" field.
+func InputWaitTimeoutMsGT(v int64) predicate.Project {
+	return predicate.Project(sql.FieldGT(FieldInputWaitTimeoutMs, v))
+}
+
+// InputWaitTimeoutMsGTE applies the GTE predicate on the "input_wait_timeout_ms" field.
+func InputWaitTimeoutMsGTE(v int64) predicate.Project {
+	return predicate.Project(sql.FieldGTE(FieldInputWaitTimeoutMs, v))
+}
+
+// InputWaitTimeoutMsLT applies the LT predicate on the "input_wait_timeout_ms" field.
+func InputWaitTimeoutMsLT(v int64) predicate.Project {
+	return predicate.Project(sql.FieldLT(FieldInputWaitTimeoutMs, v))
+}
+
+// InputWaitTimeoutMsLTE applies the LTE predicate on the "input_wait_timeout_ms" field.
+func InputWaitTimeoutMsLTE(v int64) predicate.Project {
+	return predicate.Project(sql.FieldLTE(FieldInputWaitTimeoutMs, v))
+}
+
+// LocaleEQ applies the EQ predicate on the "locale" field.
+func LocaleEQ(v string) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldLocale, v))
+}
+
+// LocaleNEQ applies the NEQ predicate on the "locale" field.
+func LocaleNEQ(v string) predicate.Project {
+	return predicate.Project(sql.FieldNEQ(FieldLocale, v))
+}
+
+// LocaleIn applies the In predicate on the "locale" field.
+func LocaleIn(vs ...string) predicate.Project {
+	return predicate.Project(sql.FieldIn(FieldLocale, vs...))
+}
+
+// LocaleNotIn applies the NotIn predicate on the "locale" field.
+func LocaleNotIn(vs ...string) predicate.Project {
+	return predicate.Project(sql.FieldNotIn(FieldLocale, vs...))
+}
+
+// LocaleGT applies the GT predicate on the "locale" field.
+func LocaleGT(v string) predicate.Project {
+	return predicate.Project(sql.FieldGT(FieldLocale, v))
+}
+
+// LocaleGTE applies the GTE predicate on the "locale" field.
+func LocaleGTE(v string) predicate.Project {
+	return predicate.Project(sql.FieldGTE(FieldLocale, v))
+}
+
+// LocaleLT applies the LT predicate on the "locale" field.
+func LocaleLT(v string) predicate.Project {
+	return predicate.Project(sql.FieldLT(FieldLocale, v))
+}
+
+// LocaleLTE applies the LTE predicate on the "locale" field.
+func LocaleLTE(v string) predicate.Project {
+	return predicate.Project(sql.FieldLTE(FieldLocale, v))
+}
+
+// LocaleContains applies the Contains predicate on the "locale" field.
+func LocaleContains(v string) predicate.Project {
+	return predicate.Project(sql.FieldContains(FieldLocale, v))
+}
+
+// LocaleHasPrefix applies the HasPrefix predicate on the "locale" field.
+func LocaleHasPrefix(v string) predicate.Project {
+	return predicate.Project(sql.FieldHasPrefix(FieldLocale, v))
+}
+
+// LocaleHasSuffix applies the HasSuffix predicate on the "locale" field.
+func LocaleHasSuffix(v string) predicate.Project {
+	return predicate.Project(sql.FieldHasSuffix(FieldLocale, v))
+}
+
+// LocaleEqualFold applies the EqualFold predicate on the "locale" field.
+func LocaleEqualFold(v string) predicate.Project {
+	return predicate.Project(sql.FieldEqualFold(FieldLocale, v))
+}
+
+// LocaleContainsFold applies the ContainsFold predicate on the "locale" field.
+func LocaleContainsFold(v string) predicate.Project {
+	return predicate.Project(sql.FieldContainsFold(FieldLocale, v))
+}
+
+// MinCodeRatioEQ applies the EQ predicate on the "min_code_ratio" field.
+func MinCodeRatioEQ(v float64) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldMinCodeRatio, v))
+}
+
+// MinCodeRatioNEQ applies the NEQ predicate on the "min_code_ratio" field.
+func MinCodeRatioNEQ(v float64) predicate.Project {
+	return predicate.Project(sql.FieldNEQ(FieldMinCodeRatio, v))
+}
+
+// MinCodeRatioIn applies the In predicate on the "min_code_ratio" field.
+func MinCodeRatioIn(vs ...float64) predicate.Project {
+	return predicate.Project(sql.FieldIn(FieldMinCodeRatio, vs...))
+}
+
+// MinCodeRatioNotIn applies the NotIn predicate on the "min_code_ratio" field.
+func MinCodeRatioNotIn(vs ...float64) predicate.Project {
+	return predicate.Project(sql.FieldNotIn(FieldMinCodeRatio, vs...))
+}
+
+// MinCodeRatioGT applies the GT predicate on the "min_code_ratio" field.
+func MinCodeRatioGT(v float64) predicate.Project {
+	return predicate.Project(sql.FieldGT(FieldMinCodeRatio, v))
+}
+
+// MinCodeRatioGTE applies the GTE predicate on the "min_code_ratio" field.
+func MinCodeRatioGTE(v float64) predicate.Project {
+	return predicate.Project(sql.FieldGTE(FieldMinCodeRatio, v))
+}
+
+// MinCodeRatioLT applies the LT predicate on the "min_code_ratio" field.
+func MinCodeRatioLT(v float64) predicate.Project {
+	return predicate.Project(sql.FieldLT(FieldMinCodeRatio, v))
+}
+
+// MinCodeRatioLTE applies the LTE predicate on the "min_code_ratio" field.
+func MinCodeRatioLTE(v float64) predicate.Project {
+	return predicate.Project(sql.FieldLTE(FieldMinCodeRatio, v))
+}
+
+// MinCodeBytesEQ applies the EQ predicate on the "min_code_bytes" field.
+func MinCodeBytesEQ(v int64) predicate.Project {
+	return predicate.Project(sql.FieldEQ(FieldMinCodeBytes, v))
+}
+
+// MinCodeBytesNEQ applies the NEQ predicate on the "min_code_bytes" field.
+func MinCodeBytesNEQ(v int64) predicate.Project {
+	return predicate.Project(sql.FieldNEQ(FieldMinCodeBytes, v))
+}
+
+// MinCodeBytesIn applies the In predicate on the "min_code_bytes" field.
+func MinCodeBytesIn(vs ...int64) predicate.Project {
+	return predicate.Project(sql.FieldIn(FieldMinCodeBytes, vs...))
+}
+
+// MinCodeBytesNotIn applies the NotIn predicate on the "min_code_bytes" field.
+func MinCodeBytesNotIn(vs ...int64) predicate.Project {
+	return predicate.Project(sql.FieldNotIn(FieldMinCodeBytes, vs...))
+}
+
+// MinCodeBytesGT applies the GT predicate on the "min_code_bytes" field.
+func MinCodeBytesGT(v int64) predicate.Project {
+	return predicate.Project(sql.FieldGT(FieldMinCodeBytes, v))
+}
+
+// MinCodeBytesGTE applies the GTE predicate on the "min_code_bytes" field.
+func MinCodeBytesGTE(v int64) predicate.Project {
+	return predicate.Project(sql.FieldGTE(FieldMinCodeBytes, v))
+}
+
+// MinCodeBytesLT applies the LT predicate on the "min_code_bytes" field.
+func MinCodeBytesLT(v int64) predicate.Project {
+	return predicate.Project(sql.FieldLT(FieldMinCodeBytes, v))
+}
+
+// MinCodeBytesLTE applies the LTE predicate on the "min_code_bytes" field.
+func MinCodeBytesLTE(v int64) predicate.Project {
+	return predicate.Project(sql.FieldLTE(FieldMinCodeBytes, v))
+}
+
 // HasArtifacts applies the HasEdge predicate on the "artifacts" edge.
 func HasArtifacts() predicate.Project {
 	return predicate.Project(func(s *sql.Selector) {
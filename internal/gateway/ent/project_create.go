@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"insightify/internal/gateway/ent/artifact"
 	"insightify/internal/gateway/ent/project"
+	"insightify/internal/gateway/entity"
 
 	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
@@ -65,6 +66,12 @@ func (_c *ProjectCreate) SetNillableRepo(v *string) *ProjectCreate {
 	return _c
 }
 
+// SetRepos sets the "repos" field.
+func (_c *ProjectCreate) SetRepos(v []entity.RepoEntry) *ProjectCreate {
+	_c.mutation.SetRepos(v)
+	return _c
+}
+
 // SetIsActive sets the "is_active" field.
 func (_c *ProjectCreate) SetIsActive(v bool) *ProjectCreate {
 	_c.mutation.SetIsActive(v)
@@ -79,6 +86,76 @@ func (_c *ProjectCreate) SetNillableIsActive(v *bool) *ProjectCreate {
 	return _c
 }
 
+// SetCostBudgetUsd sets the "cost_budget_usd" field.
+func (_c *ProjectCreate) SetCostBudgetUsd(v float64) *ProjectCreate {
+	_c.mutation.SetCostBudgetUsd(v)
+	return _c
+}
+
+// SetNillableCostBudgetUsd sets the "cost_budget_usd" field if the given value is not nil.
+func (_c *ProjectCreate) SetNillableCostBudgetUsd(v *float64) *ProjectCreate {
+	if v != nil {
+		_c.SetCostBudgetUsd(*v)
+	}
+	return _c
+}
+
+// SetInputWaitTimeoutMs sets the "input_wait_timeout_ms" field.
+func (_c *ProjectCreate) SetInputWaitTimeoutMs(v int64) *ProjectCreate {
+	_c.mutation.SetInputWaitTimeoutMs(v)
+	return _c
+}
+
+// SetNillableInputWaitTimeoutMs sets the "input_wait_timeout_ms" field if the given value is not nil.
+func (_c *ProjectCreate) SetNillableInputWaitTimeoutMs(v *int64) *ProjectCreate {
+	if v != nil {
+		_c.SetInputWaitTimeoutMs(*v)
+	}
+	return _c
+}
+
+// SetLocale sets the "locale" field.
+func (_c *ProjectCreate) SetLocale(v string) *ProjectCreate {
+	_c.mutation.SetLocale(v)
+	return _c
+}
+
+// SetNillableLocale sets the "locale" field if the given value is not nil.
+func (_c *ProjectCreate) SetNillableLocale(v *string) *ProjectCreate {
+	if v != nil {
+		_c.SetLocale(*v)
+	}
+	return _c
+}
+
+// SetMinCodeRatio sets the "min_code_ratio" field.
+func (_c *ProjectCreate) SetMinCodeRatio(v float64) *ProjectCreate {
+	_c.mutation.SetMinCodeRatio(v)
+	return _c
+}
+
+// SetNillableMinCodeRatio sets the "min_code_ratio" field if the given value is not nil.
+func (_c *ProjectCreate) SetNillableMinCodeRatio(v *float64) *ProjectCreate {
+	if v != nil {
+		_c.SetMinCodeRatio(*v)
+	}
+	return _c
+}
+
+// SetMinCodeBytes sets the "min_code_bytes" field.
+func (_c *ProjectCreate) SetMinCodeBytes(v int64) *ProjectCreate {
+	_c.mutation.SetMinCodeBytes(v)
+	return _c
+}
+
+// SetNillableMinCodeBytes sets the "min_code_bytes" field if the given value is not nil.
+func (_c *ProjectCreate) SetNillableMinCodeBytes(v *int64) *ProjectCreate {
+	if v != nil {
+		_c.SetMinCodeBytes(*v)
+	}
+	return _c
+}
+
 // SetID sets the "id" field.
 func (_c *ProjectCreate) SetID(v string) *ProjectCreate {
 	_c.mutation.SetID(v)
@@ -151,6 +228,26 @@ func (_c *ProjectCreate) defaults() {
 		v := project.DefaultIsActive
 		_c.mutation.SetIsActive(v)
 	}
+	if _, ok := _c.mutation.CostBudgetUsd(); !ok {
+		v := project.DefaultCostBudgetUsd
+		_c.mutation.SetCostBudgetUsd(v)
+	}
+	if _, ok := _c.mutation.InputWaitTimeoutMs(); !ok {
+		v := project.DefaultInputWaitTimeoutMs
+		_c.mutation.SetInputWaitTimeoutMs(v)
+	}
+	if _, ok := _c.mutation.Locale(); !ok {
+		v := project.DefaultLocale
+		_c.mutation.SetLocale(v)
+	}
+	if _, ok := _c.mutation.MinCodeRatio(); !ok {
+		v := project.DefaultMinCodeRatio
+		_c.mutation.SetMinCodeRatio(v)
+	}
+	if _, ok := _c.mutation.MinCodeBytes(); !ok {
+		v := project.DefaultMinCodeBytes
+		_c.mutation.SetMinCodeBytes(v)
+	}
 }
 
 // check runs all checks and user-defined validators on the builder.
@@ -167,6 +264,21 @@ func (_c *ProjectCreate) check() error {
 	if _, ok := _c.mutation.IsActive(); !ok {
 		return &ValidationError{Name: "is_active", err: errors.New(`ent: missing required field "Project.is_active"`)}
 	}
+	if _, ok := _c.mutation.CostBudgetUsd(); !ok {
+		return &ValidationError{Name: "cost_budget_usd", err: errors.New(`ent: missing required field "Project.cost_budget_usd"`)}
+	}
+	if _, ok := _c.mutation.InputWaitTimeoutMs(); !ok {
+		return &ValidationError{Name: "input_wait_timeout_ms", err: errors.New(`ent: missing required field "Project.input_wait_timeout_ms"`)}
+	}
+	if _, ok := _c.mutation.Locale(); !ok {
+		return &ValidationError{Name: "locale", err: errors.New(`ent: missing required field "Project.locale"`)}
+	}
+	if _, ok := _c.mutation.MinCodeRatio(); !ok {
+		return &ValidationError{Name: "min_code_ratio", err: errors.New(`ent: missing required field "Project.min_code_ratio"`)}
+	}
+	if _, ok := _c.mutation.MinCodeBytes(); !ok {
+		return &ValidationError{Name: "min_code_bytes", err: errors.New(`ent: missing required field "Project.min_code_bytes"`)}
+	}
 	return nil
 }
 
@@ -215,10 +327,34 @@ func (_c *ProjectCreate) createSpec() (*Project, *sqlgraph.CreateSpec) {
 		_spec.SetField(project.FieldRepo, field.TypeString, value)
 		_node.Repo = value
 	}
+	if value, ok := _c.mutation.Repos(); ok {
+		_spec.SetField(project.FieldRepos, field.TypeJSON, value)
+		_node.Repos = value
+	}
 	if value, ok := _c.mutation.IsActive(); ok {
 		_spec.SetField(project.FieldIsActive, field.TypeBool, value)
 		_node.IsActive = value
 	}
+	if value, ok := _c.mutation.CostBudgetUsd(); ok {
+		_spec.SetField(project.FieldCostBudgetUsd, field.TypeFloat64, value)
+		_node.CostBudgetUsd = value
+	}
+	if value, ok := _c.mutation.InputWaitTimeoutMs(); ok {
+		_spec.SetField(project.FieldInputWaitTimeoutMs, field.TypeInt64, value)
+		_node.InputWaitTimeoutMs = value
+	}
+	if value, ok := _c.mutation.Locale(); ok {
+		_spec.SetField(project.FieldLocale, field.TypeString, value)
+		_node.Locale = value
+	}
+	if value, ok := _c.mutation.MinCodeRatio(); ok {
+		_spec.SetField(project.FieldMinCodeRatio, field.TypeFloat64, value)
+		_node.MinCodeRatio = value
+	}
+	if value, ok := _c.mutation.MinCodeBytes(); ok {
+		_spec.SetField(project.FieldMinCodeBytes, field.TypeInt64, value)
+		_node.MinCodeBytes = value
+	}
 	if nodes := _c.mutation.ArtifactsIDs(); len(nodes) > 0 {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -323,6 +459,24 @@ func (u *ProjectUpsert) UpdateRepo() *ProjectUpsert {
 	return u
 }
 
+// SetRepos sets the "repos" field.
+func (u *ProjectUpsert) SetRepos(v []entity.RepoEntry) *ProjectUpsert {
+	u.Set(project.FieldRepos, v)
+	return u
+}
+
+// UpdateRepos sets the "repos" field to the value that was provided on create.
+func (u *ProjectUpsert) UpdateRepos() *ProjectUpsert {
+	u.SetExcluded(project.FieldRepos)
+	return u
+}
+
+// ClearRepos clears the value of the "repos" field.
+func (u *ProjectUpsert) ClearRepos() *ProjectUpsert {
+	u.SetNull(project.FieldRepos)
+	return u
+}
+
 // SetIsActive sets the "is_active" field.
 func (u *ProjectUpsert) SetIsActive(v bool) *ProjectUpsert {
 	u.Set(project.FieldIsActive, v)
@@ -335,6 +489,90 @@ func (u *ProjectUpsert) UpdateIsActive() *ProjectUpsert {
 	return u
 }
 
+// SetCostBudgetUsd sets the "cost_budget_usd" field.
+func (u *ProjectUpsert) SetCostBudgetUsd(v float64) *ProjectUpsert {
+	u.Set(project.FieldCostBudgetUsd, v)
+	return u
+}
+
+// UpdateCostBudgetUsd sets the "cost_budget_usd" field to the value that was provided on create.
+func (u *ProjectUpsert) UpdateCostBudgetUsd() *ProjectUpsert {
+	u.SetExcluded(project.FieldCostBudgetUsd)
+	return u
+}
+
+// AddCostBudgetUsd adds v to the "cost_budget_usd" field.
+func (u *ProjectUpsert) AddCostBudgetUsd(v float64) *ProjectUpsert {
+	u.Add(project.FieldCostBudgetUsd, v)
+	return u
+}
+
+// SetInputWaitTimeoutMs sets the "input_wait_timeout_ms" field.
+func (u *ProjectUpsert) SetInputWaitTimeoutMs(v int64) *ProjectUpsert {
+	u.Set(project.FieldInputWaitTimeoutMs, v)
+	return u
+}
+
+// UpdateInputWaitTimeoutMs sets the "input_wait_timeout_ms" field to the value that was provided on create.
+func (u *ProjectUpsert) UpdateInputWaitTimeoutMs() *ProjectUpsert {
+	u.SetExcluded(project.FieldInputWaitTimeoutMs)
+	return u
+}
+
+// AddInputWaitTimeoutMs adds v to the "input_wait_timeout_ms" field.
+func (u *ProjectUpsert) AddInputWaitTimeoutMs(v int64) *ProjectUpsert {
+	u.Add(project.FieldInputWaitTimeoutMs, v)
+	return u
+}
+
+// SetLocale sets the "locale" field.
+func (u *ProjectUpsert) SetLocale(v string) *ProjectUpsert {
+	u.Set(project.FieldLocale, v)
+	return u
+}
+
+// UpdateLocale sets the "locale" field to the value that was provided on create.
+func (u *ProjectUpsert) UpdateLocale() *ProjectUpsert {
+	u.SetExcluded(project.FieldLocale)
+	return u
+}
+
+// SetMinCodeRatio sets the "min_code_ratio" field.
+func (u *ProjectUpsert) SetMinCodeRatio(v float64) *ProjectUpsert {
+	u.Set(project.FieldMinCodeRatio, v)
+	return u
+}
+
+// UpdateMinCodeRatio sets the "min_code_ratio" field to the value that was provided on create.
+func (u *ProjectUpsert) UpdateMinCodeRatio() *ProjectUpsert {
+	u.SetExcluded(project.FieldMinCodeRatio)
+	return u
+}
+
+// AddMinCodeRatio adds v to the "min_code_ratio" field.
+func (u *ProjectUpsert) AddMinCodeRatio(v float64) *ProjectUpsert {
+	u.Add(project.FieldMinCodeRatio, v)
+	return u
+}
+
+// SetMinCodeBytes sets the "min_code_bytes" field.
+func (u *ProjectUpsert) SetMinCodeBytes(v int64) *ProjectUpsert {
+	u.Set(project.FieldMinCodeBytes, v)
+	return u
+}
+
+// UpdateMinCodeBytes sets the "min_code_bytes" field to the value that was provided on create.
+func (u *ProjectUpsert) UpdateMinCodeBytes() *ProjectUpsert {
+	u.SetExcluded(project.FieldMinCodeBytes)
+	return u
+}
+
+// AddMinCodeBytes adds v to the "min_code_bytes" field.
+func (u *ProjectUpsert) AddMinCodeBytes(v int64) *ProjectUpsert {
+	u.Add(project.FieldMinCodeBytes, v)
+	return u
+}
+
 // UpdateNewValues updates the mutable fields using the new values that were set on create except the ID field.
 // Using this option is equivalent to using:
 //
@@ -425,6 +663,27 @@ func (u *ProjectUpsertOne) UpdateRepo() *ProjectUpsertOne {
 	})
 }
 
+// SetRepos sets the "repos" field.
+func (u *ProjectUpsertOne) SetRepos(v []entity.RepoEntry) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetRepos(v)
+	})
+}
+
+// UpdateRepos sets the "repos" field to the value that was provided on create.
+func (u *ProjectUpsertOne) UpdateRepos() *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateRepos()
+	})
+}
+
+// ClearRepos clears the value of the "repos" field.
+func (u *ProjectUpsertOne) ClearRepos() *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.ClearRepos()
+	})
+}
+
 // SetIsActive sets the "is_active" field.
 func (u *ProjectUpsertOne) SetIsActive(v bool) *ProjectUpsertOne {
 	return u.Update(func(s *ProjectUpsert) {
@@ -439,6 +698,104 @@ func (u *ProjectUpsertOne) UpdateIsActive() *ProjectUpsertOne {
 	})
 }
 
+// SetCostBudgetUsd sets the "cost_budget_usd" field.
+func (u *ProjectUpsertOne) SetCostBudgetUsd(v float64) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetCostBudgetUsd(v)
+	})
+}
+
+// AddCostBudgetUsd adds v to the "cost_budget_usd" field.
+func (u *ProjectUpsertOne) AddCostBudgetUsd(v float64) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.AddCostBudgetUsd(v)
+	})
+}
+
+// UpdateCostBudgetUsd sets the "cost_budget_usd" field to the value that was provided on create.
+func (u *ProjectUpsertOne) UpdateCostBudgetUsd() *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateCostBudgetUsd()
+	})
+}
+
+// SetInputWaitTimeoutMs sets the "input_wait_timeout_ms" field.
+func (u *ProjectUpsertOne) SetInputWaitTimeoutMs(v int64) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetInputWaitTimeoutMs(v)
+	})
+}
+
+// AddInputWaitTimeoutMs adds v to the "input_wait_timeout_ms" field.
+func (u *ProjectUpsertOne) AddInputWaitTimeoutMs(v int64) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.AddInputWaitTimeoutMs(v)
+	})
+}
+
+// UpdateInputWaitTimeoutMs sets the "input_wait_timeout_ms" field to the value that was provided on create.
+func (u *ProjectUpsertOne) UpdateInputWaitTimeoutMs() *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateInputWaitTimeoutMs()
+	})
+}
+
+// SetLocale sets the "locale" field.
+func (u *ProjectUpsertOne) SetLocale(v string) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetLocale(v)
+	})
+}
+
+// UpdateLocale sets the "locale" field to the value that was provided on create.
+func (u *ProjectUpsertOne) UpdateLocale() *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateLocale()
+	})
+}
+
+// SetMinCodeRatio sets the "min_code_ratio" field.
+func (u *ProjectUpsertOne) SetMinCodeRatio(v float64) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetMinCodeRatio(v)
+	})
+}
+
+// AddMinCodeRatio adds v to the "min_code_ratio" field.
+func (u *ProjectUpsertOne) AddMinCodeRatio(v float64) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.AddMinCodeRatio(v)
+	})
+}
+
+// UpdateMinCodeRatio sets the "min_code_ratio" field to the value that was provided on create.
+func (u *ProjectUpsertOne) UpdateMinCodeRatio() *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateMinCodeRatio()
+	})
+}
+
+// SetMinCodeBytes sets the "min_code_bytes" field.
+func (u *ProjectUpsertOne) SetMinCodeBytes(v int64) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetMinCodeBytes(v)
+	})
+}
+
+// AddMinCodeBytes adds v to the "min_code_bytes" field.
+func (u *ProjectUpsertOne) AddMinCodeBytes(v int64) *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.AddMinCodeBytes(v)
+	})
+}
+
+// UpdateMinCodeBytes sets the "min_code_bytes" field to the value that was provided on create.
+func (u *ProjectUpsertOne) UpdateMinCodeBytes() *ProjectUpsertOne {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateMinCodeBytes()
+	})
+}
+
 // Exec executes the query.
 func (u *ProjectUpsertOne) Exec(ctx context.Context) error {
 	if len(u.create.conflict) == 0 {
@@ -696,6 +1053,27 @@ func (u *ProjectUpsertBulk) UpdateRepo() *ProjectUpsertBulk {
 	})
 }
 
+// SetRepos sets the "repos" field.
+func (u *ProjectUpsertBulk) SetRepos(v []entity.RepoEntry) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetRepos(v)
+	})
+}
+
+// UpdateRepos sets the "repos" field to the value that was provided on create.
+func (u *ProjectUpsertBulk) UpdateRepos() *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateRepos()
+	})
+}
+
+// ClearRepos clears the value of the "repos" field.
+func (u *ProjectUpsertBulk) ClearRepos() *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.ClearRepos()
+	})
+}
+
 // SetIsActive sets the "is_active" field.
 func (u *ProjectUpsertBulk) SetIsActive(v bool) *ProjectUpsertBulk {
 	return u.Update(func(s *ProjectUpsert) {
@@ -710,6 +1088,104 @@ func (u *ProjectUpsertBulk) UpdateIsActive() *ProjectUpsertBulk {
 	})
 }
 
+// SetCostBudgetUsd sets the "cost_budget_usd" field.
+func (u *ProjectUpsertBulk) SetCostBudgetUsd(v float64) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetCostBudgetUsd(v)
+	})
+}
+
+// AddCostBudgetUsd adds v to the "cost_budget_usd" field.
+func (u *ProjectUpsertBulk) AddCostBudgetUsd(v float64) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.AddCostBudgetUsd(v)
+	})
+}
+
+// UpdateCostBudgetUsd sets the "cost_budget_usd" field to the value that was provided on create.
+func (u *ProjectUpsertBulk) UpdateCostBudgetUsd() *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateCostBudgetUsd()
+	})
+}
+
+// SetInputWaitTimeoutMs sets the "input_wait_timeout_ms" field.
+func (u *ProjectUpsertBulk) SetInputWaitTimeoutMs(v int64) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetInputWaitTimeoutMs(v)
+	})
+}
+
+// AddInputWaitTimeoutMs adds v to the "input_wait_timeout_ms" field.
+func (u *ProjectUpsertBulk) AddInputWaitTimeoutMs(v int64) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.AddInputWaitTimeoutMs(v)
+	})
+}
+
+// UpdateInputWaitTimeoutMs sets the "input_wait_timeout_ms" field to the value that was provided on create.
+func (u *ProjectUpsertBulk) UpdateInputWaitTimeoutMs() *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateInputWaitTimeoutMs()
+	})
+}
+
+// SetLocale sets the "locale" field.
+func (u *ProjectUpsertBulk) SetLocale(v string) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetLocale(v)
+	})
+}
+
+// UpdateLocale sets the "locale" field to the value that was provided on create.
+func (u *ProjectUpsertBulk) UpdateLocale() *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateLocale()
+	})
+}
+
+// SetMinCodeRatio sets the "min_code_ratio" field.
+func (u *ProjectUpsertBulk) SetMinCodeRatio(v float64) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetMinCodeRatio(v)
+	})
+}
+
+// AddMinCodeRatio adds v to the "min_code_ratio" field.
+func (u *ProjectUpsertBulk) AddMinCodeRatio(v float64) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.AddMinCodeRatio(v)
+	})
+}
+
+// UpdateMinCodeRatio sets the "min_code_ratio" field to the value that was provided on create.
+func (u *ProjectUpsertBulk) UpdateMinCodeRatio() *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateMinCodeRatio()
+	})
+}
+
+// SetMinCodeBytes sets the "min_code_bytes" field.
+func (u *ProjectUpsertBulk) SetMinCodeBytes(v int64) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.SetMinCodeBytes(v)
+	})
+}
+
+// AddMinCodeBytes adds v to the "min_code_bytes" field.
+func (u *ProjectUpsertBulk) AddMinCodeBytes(v int64) *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.AddMinCodeBytes(v)
+	})
+}
+
+// UpdateMinCodeBytes sets the "min_code_bytes" field to the value that was provided on create.
+func (u *ProjectUpsertBulk) UpdateMinCodeBytes() *ProjectUpsertBulk {
+	return u.Update(func(s *ProjectUpsert) {
+		s.UpdateMinCodeBytes()
+	})
+}
+
 // Exec executes the query.
 func (u *ProjectUpsertBulk) Exec(ctx context.Context) error {
 	if u.create.err != nil {
@@ -9,9 +9,11 @@ import (
 	"insightify/internal/gateway/ent/artifact"
 	"insightify/internal/gateway/ent/predicate"
 	"insightify/internal/gateway/ent/project"
+	"insightify/internal/gateway/entity"
 
 	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/dialect/sql/sqlgraph"
+	"entgo.io/ent/dialect/sql/sqljson"
 	"entgo.io/ent/schema/field"
 )
 
@@ -70,6 +72,24 @@ func (_u *ProjectUpdate) SetNillableRepo(v *string) *ProjectUpdate {
 	return _u
 }
 
+// SetRepos sets the "repos" field.
+func (_u *ProjectUpdate) SetRepos(v []entity.RepoEntry) *ProjectUpdate {
+	_u.mutation.SetRepos(v)
+	return _u
+}
+
+// AppendRepos appends value to the "repos" field.
+func (_u *ProjectUpdate) AppendRepos(v []entity.RepoEntry) *ProjectUpdate {
+	_u.mutation.AppendRepos(v)
+	return _u
+}
+
+// ClearRepos clears the value of the "repos" field.
+func (_u *ProjectUpdate) ClearRepos() *ProjectUpdate {
+	_u.mutation.ClearRepos()
+	return _u
+}
+
 // SetIsActive sets the "is_active" field.
 func (_u *ProjectUpdate) SetIsActive(v bool) *ProjectUpdate {
 	_u.mutation.SetIsActive(v)
@@ -84,6 +104,104 @@ func (_u *ProjectUpdate) SetNillableIsActive(v *bool) *ProjectUpdate {
 	return _u
 }
 
+// SetCostBudgetUsd sets the "cost_budget_usd" field.
+func (_u *ProjectUpdate) SetCostBudgetUsd(v float64) *ProjectUpdate {
+	_u.mutation.ResetCostBudgetUsd()
+	_u.mutation.SetCostBudgetUsd(v)
+	return _u
+}
+
+// SetNillableCostBudgetUsd sets the "cost_budget_usd" field if the given value is not nil.
+func (_u *ProjectUpdate) SetNillableCostBudgetUsd(v *float64) *ProjectUpdate {
+	if v != nil {
+		_u.SetCostBudgetUsd(*v)
+	}
+	return _u
+}
+
+// AddCostBudgetUsd adds value to the "cost_budget_usd" field.
+func (_u *ProjectUpdate) AddCostBudgetUsd(v float64) *ProjectUpdate {
+	_u.mutation.AddCostBudgetUsd(v)
+	return _u
+}
+
+// SetInputWaitTimeoutMs sets the "input_wait_timeout_ms" field.
+func (_u *ProjectUpdate) SetInputWaitTimeoutMs(v int64) *ProjectUpdate {
+	_u.mutation.ResetInputWaitTimeoutMs()
+	_u.mutation.SetInputWaitTimeoutMs(v)
+	return _u
+}
+
+// SetNillableInputWaitTimeoutMs sets the "input_wait_timeout_ms" field if the given value is not nil.
+func (_u *ProjectUpdate) SetNillableInputWaitTimeoutMs(v *int64) *ProjectUpdate {
+	if v != nil {
+		_u.SetInputWaitTimeoutMs(*v)
+	}
+	return _u
+}
+
+// AddInputWaitTimeoutMs adds value to the "input_wait_timeout_ms" field.
+func (_u *ProjectUpdate) AddInputWaitTimeoutMs(v int64) *ProjectUpdate {
+	_u.mutation.AddInputWaitTimeoutMs(v)
+	return _u
+}
+
+// SetLocale sets the "locale" field.
+func (_u *ProjectUpdate) SetLocale(v string) *ProjectUpdate {
+	_u.mutation.SetLocale(v)
+	return _u
+}
+
+// SetNillableLocale sets the "locale" field if the given value is not nil.
+func (_u *ProjectUpdate) SetNillableLocale(v *string) *ProjectUpdate {
+	if v != nil {
+		_u.SetLocale(*v)
+	}
+	return _u
+}
+
+// SetMinCodeRatio sets the "min_code_ratio" field.
+func (_u *ProjectUpdate) SetMinCodeRatio(v float64) *ProjectUpdate {
+	_u.mutation.ResetMinCodeRatio()
+	_u.mutation.SetMinCodeRatio(v)
+	return _u
+}
+
+// SetNillableMinCodeRatio sets the "min_code_ratio" field if the given value is not nil.
+func (_u *ProjectUpdate) SetNillableMinCodeRatio(v *float64) *ProjectUpdate {
+	if v != nil {
+		_u.SetMinCodeRatio(*v)
+	}
+	return _u
+}
+
+// AddMinCodeRatio adds value to the "min_code_ratio" field.
+func (_u *ProjectUpdate) AddMinCodeRatio(v float64) *ProjectUpdate {
+	_u.mutation.AddMinCodeRatio(v)
+	return _u
+}
+
+// SetMinCodeBytes sets the "min_code_bytes" field.
+func (_u *ProjectUpdate) SetMinCodeBytes(v int64) *ProjectUpdate {
+	_u.mutation.ResetMinCodeBytes()
+	_u.mutation.SetMinCodeBytes(v)
+	return _u
+}
+
+// SetNillableMinCodeBytes sets the "min_code_bytes" field if the given value is not nil.
+func (_u *ProjectUpdate) SetNillableMinCodeBytes(v *int64) *ProjectUpdate {
+	if v != nil {
+		_u.SetMinCodeBytes(*v)
+	}
+	return _u
+}
+
+// AddMinCodeBytes adds value to the "min_code_bytes" field.
+func (_u *ProjectUpdate) AddMinCodeBytes(v int64) *ProjectUpdate {
+	_u.mutation.AddMinCodeBytes(v)
+	return _u
+}
+
 // AddArtifactIDs adds the "artifacts" edge to the Artifact entity by IDs.
 func (_u *ProjectUpdate) AddArtifactIDs(ids ...int) *ProjectUpdate {
 	_u.mutation.AddArtifactIDs(ids...)
@@ -170,9 +288,47 @@ func (_u *ProjectUpdate) sqlSave(ctx context.Context) (_node int, err error) {
 	if value, ok := _u.mutation.Repo(); ok {
 		_spec.SetField(project.FieldRepo, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.Repos(); ok {
+		_spec.SetField(project.FieldRepos, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedRepos(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, project.FieldRepos, value)
+		})
+	}
+	if _u.mutation.ReposCleared() {
+		_spec.ClearField(project.FieldRepos, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.IsActive(); ok {
 		_spec.SetField(project.FieldIsActive, field.TypeBool, value)
 	}
+	if value, ok := _u.mutation.CostBudgetUsd(); ok {
+		_spec.SetField(project.FieldCostBudgetUsd, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedCostBudgetUsd(); ok {
+		_spec.AddField(project.FieldCostBudgetUsd, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.InputWaitTimeoutMs(); ok {
+		_spec.SetField(project.FieldInputWaitTimeoutMs, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedInputWaitTimeoutMs(); ok {
+		_spec.AddField(project.FieldInputWaitTimeoutMs, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.Locale(); ok {
+		_spec.SetField(project.FieldLocale, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.MinCodeRatio(); ok {
+		_spec.SetField(project.FieldMinCodeRatio, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedMinCodeRatio(); ok {
+		_spec.AddField(project.FieldMinCodeRatio, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.MinCodeBytes(); ok {
+		_spec.SetField(project.FieldMinCodeBytes, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedMinCodeBytes(); ok {
+		_spec.AddField(project.FieldMinCodeBytes, field.TypeInt64, value)
+	}
 	if _u.mutation.ArtifactsCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -280,6 +436,24 @@ func (_u *ProjectUpdateOne) SetNillableRepo(v *string) *ProjectUpdateOne {
 	return _u
 }
 
+// SetRepos sets the "repos" field.
+func (_u *ProjectUpdateOne) SetRepos(v []entity.RepoEntry) *ProjectUpdateOne {
+	_u.mutation.SetRepos(v)
+	return _u
+}
+
+// AppendRepos appends value to the "repos" field.
+func (_u *ProjectUpdateOne) AppendRepos(v []entity.RepoEntry) *ProjectUpdateOne {
+	_u.mutation.AppendRepos(v)
+	return _u
+}
+
+// ClearRepos clears the value of the "repos" field.
+func (_u *ProjectUpdateOne) ClearRepos() *ProjectUpdateOne {
+	_u.mutation.ClearRepos()
+	return _u
+}
+
 // SetIsActive sets the "is_active" field.
 func (_u *ProjectUpdateOne) SetIsActive(v bool) *ProjectUpdateOne {
 	_u.mutation.SetIsActive(v)
@@ -294,6 +468,104 @@ func (_u *ProjectUpdateOne) SetNillableIsActive(v *bool) *ProjectUpdateOne {
 	return _u
 }
 
+// SetCostBudgetUsd sets the "cost_budget_usd" field.
+func (_u *ProjectUpdateOne) SetCostBudgetUsd(v float64) *ProjectUpdateOne {
+	_u.mutation.ResetCostBudgetUsd()
+	_u.mutation.SetCostBudgetUsd(v)
+	return _u
+}
+
+// SetNillableCostBudgetUsd sets the "cost_budget_usd" field if the given value is not nil.
+func (_u *ProjectUpdateOne) SetNillableCostBudgetUsd(v *float64) *ProjectUpdateOne {
+	if v != nil {
+		_u.SetCostBudgetUsd(*v)
+	}
+	return _u
+}
+
+// AddCostBudgetUsd adds value to the "cost_budget_usd" field.
+func (_u *ProjectUpdateOne) AddCostBudgetUsd(v float64) *ProjectUpdateOne {
+	_u.mutation.AddCostBudgetUsd(v)
+	return _u
+}
+
+// SetInputWaitTimeoutMs sets the "input_wait_timeout_ms" field.
+func (_u *ProjectUpdateOne) SetInputWaitTimeoutMs(v int64) *ProjectUpdateOne {
+	_u.mutation.ResetInputWaitTimeoutMs()
+	_u.mutation.SetInputWaitTimeoutMs(v)
+	return _u
+}
+
+// SetNillableInputWaitTimeoutMs sets the "input_wait_timeout_ms" field if the given value is not nil.
+func (_u *ProjectUpdateOne) SetNillableInputWaitTimeoutMs(v *int64) *ProjectUpdateOne {
+	if v != nil {
+		_u.SetInputWaitTimeoutMs(*v)
+	}
+	return _u
+}
+
+// AddInputWaitTimeoutMs adds value to the "input_wait_timeout_ms" field.
+func (_u *ProjectUpdateOne) AddInputWaitTimeoutMs(v int64) *ProjectUpdateOne {
+	_u.mutation.AddInputWaitTimeoutMs(v)
+	return _u
+}
+
+// SetLocale sets the "locale" field.
+func (_u *ProjectUpdateOne) SetLocale(v string) *ProjectUpdateOne {
+	_u.mutation.SetLocale(v)
+	return _u
+}
+
+// SetNillableLocale sets the "locale" field if the given value is not nil.
+func (_u *ProjectUpdateOne) SetNillableLocale(v *string) *ProjectUpdateOne {
+	if v != nil {
+		_u.SetLocale(*v)
+	}
+	return _u
+}
+
+// SetMinCodeRatio sets the "min_code_ratio" field.
+func (_u *ProjectUpdateOne) SetMinCodeRatio(v float64) *ProjectUpdateOne {
+	_u.mutation.ResetMinCodeRatio()
+	_u.mutation.SetMinCodeRatio(v)
+	return _u
+}
+
+// SetNillableMinCodeRatio sets the "min_code_ratio" field if the given value is not nil.
+func (_u *ProjectUpdateOne) SetNillableMinCodeRatio(v *float64) *ProjectUpdateOne {
+	if v != nil {
+		_u.SetMinCodeRatio(*v)
+	}
+	return _u
+}
+
+// AddMinCodeRatio adds value to the "min_code_ratio" field.
+func (_u *ProjectUpdateOne) AddMinCodeRatio(v float64) *ProjectUpdateOne {
+	_u.mutation.AddMinCodeRatio(v)
+	return _u
+}
+
+// SetMinCodeBytes sets the "min_code_bytes" field.
+func (_u *ProjectUpdateOne) SetMinCodeBytes(v int64) *ProjectUpdateOne {
+	_u.mutation.ResetMinCodeBytes()
+	_u.mutation.SetMinCodeBytes(v)
+	return _u
+}
+
+// SetNillableMinCodeBytes sets the "min_code_bytes" field if the given value is not nil.
+func (_u *ProjectUpdateOne) SetNillableMinCodeBytes(v *int64) *ProjectUpdateOne {
+	if v != nil {
+		_u.SetMinCodeBytes(*v)
+	}
+	return _u
+}
+
+// AddMinCodeBytes adds value to the "min_code_bytes" field.
+func (_u *ProjectUpdateOne) AddMinCodeBytes(v int64) *ProjectUpdateOne {
+	_u.mutation.AddMinCodeBytes(v)
+	return _u
+}
+
 // AddArtifactIDs adds the "artifacts" edge to the Artifact entity by IDs.
 func (_u *ProjectUpdateOne) AddArtifactIDs(ids ...int) *ProjectUpdateOne {
 	_u.mutation.AddArtifactIDs(ids...)
@@ -410,9 +682,47 @@ func (_u *ProjectUpdateOne) sqlSave(ctx context.Context) (_node *Project, err er
 	if value, ok := _u.mutation.Repo(); ok {
 		_spec.SetField(project.FieldRepo, field.TypeString, value)
 	}
+	if value, ok := _u.mutation.Repos(); ok {
+		_spec.SetField(project.FieldRepos, field.TypeJSON, value)
+	}
+	if value, ok := _u.mutation.AppendedRepos(); ok {
+		_spec.AddModifier(func(u *sql.UpdateBuilder) {
+			sqljson.Append(u, project.FieldRepos, value)
+		})
+	}
+	if _u.mutation.ReposCleared() {
+		_spec.ClearField(project.FieldRepos, field.TypeJSON)
+	}
 	if value, ok := _u.mutation.IsActive(); ok {
 		_spec.SetField(project.FieldIsActive, field.TypeBool, value)
 	}
+	if value, ok := _u.mutation.CostBudgetUsd(); ok {
+		_spec.SetField(project.FieldCostBudgetUsd, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedCostBudgetUsd(); ok {
+		_spec.AddField(project.FieldCostBudgetUsd, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.InputWaitTimeoutMs(); ok {
+		_spec.SetField(project.FieldInputWaitTimeoutMs, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedInputWaitTimeoutMs(); ok {
+		_spec.AddField(project.FieldInputWaitTimeoutMs, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.Locale(); ok {
+		_spec.SetField(project.FieldLocale, field.TypeString, value)
+	}
+	if value, ok := _u.mutation.MinCodeRatio(); ok {
+		_spec.SetField(project.FieldMinCodeRatio, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.AddedMinCodeRatio(); ok {
+		_spec.AddField(project.FieldMinCodeRatio, field.TypeFloat64, value)
+	}
+	if value, ok := _u.mutation.MinCodeBytes(); ok {
+		_spec.SetField(project.FieldMinCodeBytes, field.TypeInt64, value)
+	}
+	if value, ok := _u.mutation.AddedMinCodeBytes(); ok {
+		_spec.AddField(project.FieldMinCodeBytes, field.TypeInt64, value)
+	}
 	if _u.mutation.ArtifactsCleared() {
 		edge := &sqlgraph.EdgeSpec{
 			Rel:     sqlgraph.O2M,
@@ -5,6 +5,8 @@ package ent
 import (
 	"insightify/internal/gateway/ent/artifact"
 	"insightify/internal/gateway/ent/artifactfile"
+	"insightify/internal/gateway/ent/conversation"
+	"insightify/internal/gateway/ent/conversationmessage"
 	"insightify/internal/gateway/ent/project"
 	"insightify/internal/gateway/ent/schema"
 	"insightify/internal/gateway/ent/userinteraction"
@@ -63,6 +65,46 @@ func init() {
 	artifactfile.DefaultUpdatedAt = artifactfileDescUpdatedAt.Default.(func() time.Time)
 	// artifactfile.UpdateDefaultUpdatedAt holds the default value on update for the updated_at field.
 	artifactfile.UpdateDefaultUpdatedAt = artifactfileDescUpdatedAt.UpdateDefault.(func() time.Time)
+	conversationFields := schema.Conversation{}.Fields()
+	_ = conversationFields
+	// conversationDescRunID is the schema descriptor for run_id field.
+	conversationDescRunID := conversationFields[1].Descriptor()
+	// conversation.RunIDValidator is a validator for the "run_id" field. It is called by the builders before save.
+	conversation.RunIDValidator = conversationDescRunID.Validators[0].(func(string) error)
+	// conversationDescNodeID is the schema descriptor for node_id field.
+	conversationDescNodeID := conversationFields[2].Descriptor()
+	// conversation.NodeIDValidator is a validator for the "node_id" field. It is called by the builders before save.
+	conversation.NodeIDValidator = conversationDescNodeID.Validators[0].(func(string) error)
+	// conversationDescCreatedAt is the schema descriptor for created_at field.
+	conversationDescCreatedAt := conversationFields[3].Descriptor()
+	// conversation.DefaultCreatedAt holds the default value on creation for the created_at field.
+	conversation.DefaultCreatedAt = conversationDescCreatedAt.Default.(func() time.Time)
+	conversationmessageFields := schema.ConversationMessage{}.Fields()
+	_ = conversationmessageFields
+	// conversationmessageDescConversationID is the schema descriptor for conversation_id field.
+	conversationmessageDescConversationID := conversationmessageFields[1].Descriptor()
+	// conversationmessage.ConversationIDValidator is a validator for the "conversation_id" field. It is called by the builders before save.
+	conversationmessage.ConversationIDValidator = conversationmessageDescConversationID.Validators[0].(func(string) error)
+	// conversationmessageDescSeq is the schema descriptor for seq field.
+	conversationmessageDescSeq := conversationmessageFields[2].Descriptor()
+	// conversationmessage.SeqValidator is a validator for the "seq" field. It is called by the builders before save.
+	conversationmessage.SeqValidator = conversationmessageDescSeq.Validators[0].(func(int64) error)
+	// conversationmessageDescRole is the schema descriptor for role field.
+	conversationmessageDescRole := conversationmessageFields[3].Descriptor()
+	// conversationmessage.RoleValidator is a validator for the "role" field. It is called by the builders before save.
+	conversationmessage.RoleValidator = conversationmessageDescRole.Validators[0].(func(string) error)
+	// conversationmessageDescContent is the schema descriptor for content field.
+	conversationmessageDescContent := conversationmessageFields[4].Descriptor()
+	// conversationmessage.DefaultContent holds the default value on creation for the content field.
+	conversationmessage.DefaultContent = conversationmessageDescContent.Default.(string)
+	// conversationmessageDescInteractionID is the schema descriptor for interaction_id field.
+	conversationmessageDescInteractionID := conversationmessageFields[5].Descriptor()
+	// conversationmessage.DefaultInteractionID holds the default value on creation for the interaction_id field.
+	conversationmessage.DefaultInteractionID = conversationmessageDescInteractionID.Default.(string)
+	// conversationmessageDescCreatedAt is the schema descriptor for created_at field.
+	conversationmessageDescCreatedAt := conversationmessageFields[6].Descriptor()
+	// conversationmessage.DefaultCreatedAt holds the default value on creation for the created_at field.
+	conversationmessage.DefaultCreatedAt = conversationmessageDescCreatedAt.Default.(func() time.Time)
 	projectFields := schema.Project{}.Fields()
 	_ = projectFields
 	// projectDescName is the schema descriptor for name field.
@@ -78,9 +120,29 @@ func init() {
 	// project.DefaultRepo holds the default value on creation for the repo field.
 	project.DefaultRepo = projectDescRepo.Default.(string)
 	// projectDescIsActive is the schema descriptor for is_active field.
-	projectDescIsActive := projectFields[4].Descriptor()
+	projectDescIsActive := projectFields[5].Descriptor()
 	// project.DefaultIsActive holds the default value on creation for the is_active field.
 	project.DefaultIsActive = projectDescIsActive.Default.(bool)
+	// projectDescCostBudgetUsd is the schema descriptor for cost_budget_usd field.
+	projectDescCostBudgetUsd := projectFields[6].Descriptor()
+	// project.DefaultCostBudgetUsd holds the default value on creation for the cost_budget_usd field.
+	project.DefaultCostBudgetUsd = projectDescCostBudgetUsd.Default.(float64)
+	// projectDescInputWaitTimeoutMs is the schema descriptor for input_wait_timeout_ms field.
+	projectDescInputWaitTimeoutMs := projectFields[7].Descriptor()
+	// project.DefaultInputWaitTimeoutMs holds the default value on creation for the input_wait_timeout_ms field.
+	project.DefaultInputWaitTimeoutMs = projectDescInputWaitTimeoutMs.Default.(int64)
+	// projectDescLocale is the schema descriptor for locale field.
+	projectDescLocale := projectFields[8].Descriptor()
+	// project.DefaultLocale holds the default value on creation for the locale field.
+	project.DefaultLocale = projectDescLocale.Default.(string)
+	// projectDescMinCodeRatio is the schema descriptor for min_code_ratio field.
+	projectDescMinCodeRatio := projectFields[9].Descriptor()
+	// project.DefaultMinCodeRatio holds the default value on creation for the min_code_ratio field.
+	project.DefaultMinCodeRatio = projectDescMinCodeRatio.Default.(float64)
+	// projectDescMinCodeBytes is the schema descriptor for min_code_bytes field.
+	projectDescMinCodeBytes := projectFields[10].Descriptor()
+	// project.DefaultMinCodeBytes holds the default value on creation for the min_code_bytes field.
+	project.DefaultMinCodeBytes = projectDescMinCodeBytes.Default.(int64)
 	userinteractionFields := schema.UserInteraction{}.Fields()
 	_ = userinteractionFields
 	// userinteractionDescVersion is the schema descriptor for version field.
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Conversation holds the transcript header of one interaction session
+// (run_id, node_id).
+type Conversation struct {
+	ent.Schema
+}
+
+// Fields of the Conversation.
+func (Conversation) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id").
+			StorageKey("conversation_id").
+			Unique().
+			Immutable(),
+		field.String("run_id").
+			NotEmpty(),
+		field.String("node_id").
+			NotEmpty(),
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable(),
+	}
+}
+
+// Edges of the Conversation.
+func (Conversation) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("messages", ConversationMessage.Type),
+	}
+}
+
+// Indexes of the Conversation.
+func (Conversation) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("run_id", "node_id").Unique(),
+	}
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// ConversationMessage is one transcript entry of a Conversation, keyed by its
+// per-conversation seq. A streamed assistant message is rewritten in place
+// while it grows.
+type ConversationMessage struct {
+	ent.Schema
+}
+
+// Fields of the ConversationMessage.
+func (ConversationMessage) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("id").
+			Unique().
+			Immutable(),
+		field.String("conversation_id").
+			NotEmpty(),
+		field.Int64("seq").
+			Positive(),
+		field.String("role").
+			NotEmpty(),
+		field.Text("content").
+			Default(""),
+		field.String("interaction_id").
+			Default(""),
+		field.Time("created_at").
+			Default(time.Now),
+	}
+}
+
+// Edges of the ConversationMessage.
+func (ConversationMessage) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("conversation", Conversation.Type).
+			Ref("messages").
+			Field("conversation_id").
+			Unique().
+			Required(),
+	}
+}
+
+// Indexes of the ConversationMessage.
+func (ConversationMessage) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("conversation_id", "seq").Unique(),
+		index.Fields("created_at"),
+	}
+}
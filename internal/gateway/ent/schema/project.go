@@ -4,6 +4,8 @@ import (
 	"entgo.io/ent"
 	"entgo.io/ent/schema/edge"
 	"entgo.io/ent/schema/field"
+
+	"insightify/internal/gateway/entity"
 )
 
 // Project holds the schema definition for the Project entity.
@@ -25,8 +27,28 @@ func (Project) Fields() []ent.Field {
 			Default(""),
 		field.String("repo").
 			Default(""),
+		field.JSON("repos", []entity.RepoEntry{}).
+			Optional(),
 		field.Bool("is_active").
 			Default(false),
+		// cost_budget_usd is the default LLM cost budget of the project's
+		// runs; 0 leaves them unbounded.
+		field.Float("cost_budget_usd").
+			Default(0),
+		// input_wait_timeout_ms bounds how long the project's runs wait on
+		// user input; 0 uses the server default.
+		field.Int64("input_wait_timeout_ms").
+			Default(0),
+		// locale is the language of assistant messages of the project's
+		// runs (e.g. "en", "ja"); "" uses the request's Accept-Language.
+		field.String("locale").
+			Default(""),
+		// min_code_ratio and min_code_bytes are the thresholds of the
+		// repo_assessment gate of the project's runs; 0 uses the defaults.
+		field.Float("min_code_ratio").
+			Default(0),
+		field.Int64("min_code_bytes").
+			Default(0),
 	}
 }
 
@@ -16,6 +16,10 @@ type Tx struct {
 	Artifact *ArtifactClient
 	// ArtifactFile is the client for interacting with the ArtifactFile builders.
 	ArtifactFile *ArtifactFileClient
+	// Conversation is the client for interacting with the Conversation builders.
+	Conversation *ConversationClient
+	// ConversationMessage is the client for interacting with the ConversationMessage builders.
+	ConversationMessage *ConversationMessageClient
 	// Project is the client for interacting with the Project builders.
 	Project *ProjectClient
 	// UserInteraction is the client for interacting with the UserInteraction builders.
@@ -157,6 +161,8 @@ func (tx *Tx) Client() *Client {
 func (tx *Tx) init() {
 	tx.Artifact = NewArtifactClient(tx.config)
 	tx.ArtifactFile = NewArtifactFileClient(tx.config)
+	tx.Conversation = NewConversationClient(tx.config)
+	tx.ConversationMessage = NewConversationMessageClient(tx.config)
 	tx.Project = NewProjectClient(tx.config)
 	tx.UserInteraction = NewUserInteractionClient(tx.config)
 	tx.Workspace = NewWorkspaceClient(tx.config)
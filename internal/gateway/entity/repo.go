@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidRepo is wrapped by NormalizeRepoEntries errors.
+var ErrInvalidRepo = errors.New("invalid repo entry")
+
+var repoNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// RepoEntry is one repository registered on a project. Name doubles as the
+// artifact namespace (OutDir/repos/<name>), so it must be path-safe.
+type RepoEntry struct {
+	Name      string `json:"name"`
+	URL       string `json:"url,omitempty"`
+	LocalPath string `json:"local_path,omitempty"`
+}
+
+// NormalizeRepoEntries trims entries and rejects empty, unsafe or duplicate names.
+func NormalizeRepoEntries(in []RepoEntry) ([]RepoEntry, error) {
+	out := make([]RepoEntry, 0, len(in))
+	seen := make(map[string]bool, len(in))
+	for _, r := range in {
+		r.Name = strings.TrimSpace(r.Name)
+		r.URL = strings.TrimSpace(r.URL)
+		r.LocalPath = strings.TrimSpace(r.LocalPath)
+		if !repoNamePattern.MatchString(r.Name) {
+			return nil, fmt.Errorf("%w: name %q", ErrInvalidRepo, r.Name)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("%w: duplicate name %q", ErrInvalidRepo, r.Name)
+		}
+		seen[r.Name] = true
+		out = append(out, r)
+	}
+	return out, nil
+}
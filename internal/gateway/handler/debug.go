@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"insightify/internal/common/safeio"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/entity"
+	"insightify/internal/llm/hooks"
+	llmmiddleware "insightify/internal/llm/middleware"
+)
+
+// PromptLogDirResolver returns the prompt log directory of a project the
+// user owns; project.Service.PromptLogDir satisfies it.
+type PromptLogDirResolver func(ctx context.Context, userID entity.UserID, projectID string) (string, error)
+
+// DebugHandler exposes saved LLM prompts and responses of a run, and the
+// middleware chain of the runtime LLM client.
+type DebugHandler struct {
+	promptDir PromptLogDirResolver
+	chain     LLMChainSource
+}
+
+// LLMChainSource returns the middleware chain of the runtime LLM client, and
+// false while none has been built; runtime.LLMChain satisfies it.
+type LLMChainSource func() ([]llmmiddleware.MiddlewareInfo, bool)
+
+func NewDebugHandler(promptDir PromptLogDirResolver, chain LLMChainSource) *DebugHandler {
+	return &DebugHandler{promptDir: promptDir, chain: chain}
+}
+
+type llmChainResponse struct {
+	Chain   []llmmiddleware.MiddlewareInfo `json:"chain"`
+	Summary string                         `json:"summary"`
+	Issues  []llmmiddleware.ChainIssue     `json:"issues"`
+}
+
+// HandleLLMChain serves GET /debug/llm-chain: the middlewares of the client
+// the project runtimes built, outermost first, and the misorderings Validate
+// finds in them. The chain is recorded when the client is built, so the
+// endpoint builds no client itself.
+func (h *DebugHandler) HandleLLMChain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if h.chain == nil {
+		http.Error(w, "llm client is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	chain, ok := h.chain()
+	if !ok {
+		http.Error(w, "no llm client has been built yet", http.StatusServiceUnavailable)
+		return
+	}
+	issues := llmmiddleware.Validate(chain)
+	if chain == nil {
+		chain = []llmmiddleware.MiddlewareInfo{}
+	}
+	if issues == nil {
+		issues = []llmmiddleware.ChainIssue{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(llmChainResponse{
+		Chain:   chain,
+		Summary: llmmiddleware.FormatChain(chain),
+		Issues:  issues,
+	})
+}
+
+type promptPhasesResponse struct {
+	RunID  string   `json:"run_id"`
+	Phases []string `json:"phases"`
+}
+
+type promptLogResponse struct {
+	RunID     string                 `json:"run_id"`
+	Phase     string                 `json:"phase"`
+	Exchanges []hooks.PromptExchange `json:"exchanges"`
+}
+
+// HandlePrompt serves GET /debug/prompt?project_id=...&run_id=...[&phase=...].
+// Without phase it lists the phases that have a prompt log for the run.
+func (h *DebugHandler) HandlePrompt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	userID, err := auth.ResolveUserID(r.Context(), q.Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	projectID := strings.TrimSpace(q.Get("project_id"))
+	runID := strings.TrimSpace(q.Get("run_id"))
+	phase := strings.TrimSpace(q.Get("phase"))
+	if userID.IsZero() || projectID == "" || runID == "" {
+		http.Error(w, "user_id, project_id and run_id are required", http.StatusBadRequest)
+		return
+	}
+	if !isPathElement(runID) || (phase != "" && !isPathElement(phase)) {
+		http.Error(w, "run_id and phase must be plain names", http.StatusBadRequest)
+		return
+	}
+
+	dir, err := h.promptDir(r.Context(), userID, projectID)
+	if err != nil {
+		http.Error(w, err.Error(), archiveErrorStatus(err))
+		return
+	}
+	fsys, err := safeio.NewSafeFS(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, "no prompt logs for project", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var body any
+	if phase == "" {
+		entries, err := fsys.SafeReadDir(runID)
+		if err != nil {
+			http.Error(w, "no prompt logs for run "+runID, http.StatusNotFound)
+			return
+		}
+		phases := []string{}
+		for _, e := range entries {
+			if name, ok := strings.CutSuffix(e.Name(), ".txt"); ok && !e.IsDir() {
+				phases = append(phases, name)
+			}
+		}
+		sort.Strings(phases)
+		body = promptPhasesResponse{RunID: runID, Phases: phases}
+	} else {
+		raw, err := fsys.SafeReadFile(filepath.Join(runID, phase+".txt"))
+		if err != nil {
+			http.Error(w, "no prompt log for phase "+phase, http.StatusNotFound)
+			return
+		}
+		exchanges := hooks.ParsePromptLog(raw)
+		if exchanges == nil {
+			exchanges = []hooks.PromptExchange{}
+		}
+		body = promptLogResponse{RunID: runID, Phase: phase, Exchanges: exchanges}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// isPathElement rejects names that could address another directory.
+func isPathElement(name string) bool {
+	return name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
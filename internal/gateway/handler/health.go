@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+	llmmodel "insightify/internal/llm/model"
+)
+
+// Readiness probe modes, from cheapest to most thorough.
+const (
+	// ProbeNone reports ready without touching the LLM.
+	ProbeNone = "none"
+	// ProbeCountTokens builds the LLM client and counts tokens locally; it
+	// catches missing credentials and model configuration without spending quota.
+	ProbeCountTokens = "count_tokens"
+	// ProbeGenerate also sends a minimal GenerateJSON request.
+	ProbeGenerate = "generate"
+)
+
+// DefaultProbeTimeout bounds one readiness probe when none is configured.
+const DefaultProbeTimeout = 3 * time.Second
+
+// DefaultProbeCacheTTL is how long a successful probe answers /readyz when
+// no TTL is configured. /readyz is unauthenticated, so without it every hit of
+// a generate probe would spend quota.
+const DefaultProbeCacheTTL = 30 * time.Second
+
+// LLMClientFactory builds the client probed by /readyz.
+type LLMClientFactory func(ctx context.Context) (llmclient.LLMClient, error)
+
+// HealthHandler serves liveness and readiness.
+type HealthHandler struct {
+	mode      string
+	timeout   time.Duration
+	cacheTTL  time.Duration
+	newClient LLMClientFactory
+
+	mu     sync.Mutex
+	client llmclient.LLMClient // built on the first probe, then reused
+
+	// probeMu serializes probes; readyAt is the time of the last
+	// successful one.
+	probeMu sync.Mutex
+	readyAt time.Time
+}
+
+// NewHealthHandler builds a HealthHandler. An unknown mode falls back to
+// ProbeCountTokens; a non-positive timeout to DefaultProbeTimeout. A
+// successful probe is reused for DefaultProbeCacheTTL; see SetCacheTTL.
+func NewHealthHandler(mode string, timeout time.Duration, newClient LLMClientFactory) *HealthHandler {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case ProbeNone, ProbeCountTokens, ProbeGenerate:
+	default:
+		mode = ProbeCountTokens
+	}
+	if timeout <= 0 {
+		timeout = DefaultProbeTimeout
+	}
+	return &HealthHandler{mode: mode, timeout: timeout, cacheTTL: DefaultProbeCacheTTL, newClient: newClient}
+}
+
+// SetCacheTTL sets how long a successful probe is reused. Zero keeps the
+// default; a negative TTL probes on every request.
+func (h *HealthHandler) SetCacheTTL(d time.Duration) {
+	h.probeMu.Lock()
+	defer h.probeMu.Unlock()
+	if d == 0 {
+		d = DefaultProbeCacheTTL
+	}
+	h.cacheTTL = d
+}
+
+// HandleHealth reports liveness.
+func (h *HealthHandler) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleReady probes the configured LLM and answers 503 with the reason when
+// it is unusable.
+func (h *HealthHandler) HandleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := h.cachedProbe(r.Context()); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "not_ready",
+			"probe":  h.mode,
+			"reason": err.Error(),
+		})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status": "ready",
+		"probe":  h.mode,
+	})
+}
+
+// cachedProbe reports ready without probing while the last successful
+// probe is younger than the cache TTL. Failures are not cached, so the
+// gateway turns ready as soon as the LLM recovers. Concurrent requests
+// share one probe.
+func (h *HealthHandler) cachedProbe(ctx context.Context) error {
+	h.probeMu.Lock()
+	defer h.probeMu.Unlock()
+	if !h.readyAt.IsZero() && time.Since(h.readyAt) < h.cacheTTL {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	if err := h.probe(ctx); err != nil {
+		h.readyAt = time.Time{}
+		return err
+	}
+	h.readyAt = time.Now()
+	return nil
+}
+
+func (h *HealthHandler) probe(ctx context.Context) error {
+	if h.mode == ProbeNone {
+		return nil
+	}
+	cli, err := h.llm(ctx)
+	if err != nil {
+		return fmt.Errorf("llm client: %w", err)
+	}
+	if cli.CountTokens("readiness probe") <= 0 {
+		return errors.New("llm client: token counter returned no tokens")
+	}
+	if h.mode != ProbeGenerate {
+		return nil
+	}
+	// Clients that select by level require one; the probe asks for the
+	// cheapest.
+	ctx = llmmodel.WithModelLevel(ctx, llmmodel.ModelLevelLow)
+	raw, err := cli.GenerateJSON(ctx, `Reply with the JSON object {"ok": true}.`, nil)
+	if err != nil {
+		return fmt.Errorf("llm generate: %w", err)
+	}
+	if !json.Valid(raw) {
+		return errors.New("llm generate: response is not JSON")
+	}
+	return nil
+}
+
+func (h *HealthHandler) llm(ctx context.Context) (llmclient.LLMClient, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.client != nil {
+		return h.client, nil
+	}
+	if h.newClient == nil {
+		return nil, errors.New("no llm client configured")
+	}
+	cli, err := h.newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h.client = cli
+	return cli, nil
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"insightify/internal/gateway/auth"
+	gatewayworker "insightify/internal/gateway/service/worker"
+)
+
+// maxSubmitBytes bounds the body of one input submit.
+const maxSubmitBytes = 1 << 20
+
+// InputSubmitter delivers user input to a run; worker.Service.SubmitInput
+// satisfies it.
+type InputSubmitter func(ctx context.Context, req gatewayworker.SubmitInputRequest) (*gatewayworker.SubmitInputResult, error)
+
+// InteractionSubmitHandler accepts chat input for clients that only know the
+// interaction ID of the question they answer.
+type InteractionSubmitHandler struct {
+	submit InputSubmitter
+}
+
+func NewInteractionSubmitHandler(submit InputSubmitter) *InteractionSubmitHandler {
+	return &InteractionSubmitHandler{submit: submit}
+}
+
+type interactionSubmitRequest struct {
+	ProjectID     string `json:"project_id"`
+	RunID         string `json:"run_id"`
+	NodeID        string `json:"node_id"`
+	InteractionID string `json:"interaction_id"`
+	Input         string `json:"input"`
+	Nonce         string `json:"nonce"`
+}
+
+type interactionSubmitResponse struct {
+	ProjectID     string `json:"project_id"`
+	RunID         string `json:"run_id"`
+	NodeID        string `json:"node_id"`
+	InteractionID string `json:"interaction_id"`
+	Accepted      bool   `json:"accepted"`
+	Resumed       bool   `json:"resumed"`
+}
+
+// HandleSubmit serves POST /interaction/submit. Either run_id and node_id or
+// interaction_id must be set; the run must belong to the caller.
+func (h *InteractionSubmitHandler) HandleSubmit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	userID, err := auth.ResolveUserID(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if userID.IsZero() {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	var in interactionSubmitRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSubmitBytes)).Decode(&in); err != nil {
+		http.Error(w, "invalid json body", http.StatusBadRequest)
+		return
+	}
+	res, err := h.submit(r.Context(), gatewayworker.SubmitInputRequest{
+		UserID:        userID,
+		ProjectID:     in.ProjectID,
+		RunID:         in.RunID,
+		NodeID:        in.NodeID,
+		InteractionID: in.InteractionID,
+		Input:         in.Input,
+		Nonce:         in.Nonce,
+	})
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, gatewayworker.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(interactionSubmitResponse{
+		ProjectID:     res.ProjectID,
+		RunID:         res.RunID,
+		NodeID:        res.NodeID,
+		InteractionID: res.InteractionID,
+		Accepted:      res.Accepted,
+		Resumed:       res.Resumed,
+	})
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	logctx "insightify/internal/common/logctx"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/service/project"
+)
+
+// maxImportBytes bounds the compressed archive a project import reads.
+const maxImportBytes = 1 << 30
+
+// ProjectArchiveHandler serves project export/import as tar.gz over plain HTTP.
+type ProjectArchiveHandler struct {
+	svc *project.Service
+}
+
+func NewProjectArchiveHandler(svc *project.Service) *ProjectArchiveHandler {
+	return &ProjectArchiveHandler{svc: svc}
+}
+
+func (h *ProjectArchiveHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	userID, err := auth.ResolveUserID(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+	if userID.IsZero() || projectID == "" {
+		http.Error(w, "user_id and project_id are required", http.StatusBadRequest)
+		return
+	}
+	includePrompts, _ := strconv.ParseBool(r.URL.Query().Get("include_prompts"))
+
+	sw := &startedWriter{ResponseWriter: w, onStart: func(hw http.ResponseWriter) {
+		hw.Header().Set("Content-Type", "application/gzip")
+		hw.Header().Set("Content-Disposition", `attachment; filename="`+projectID+`.tar.gz"`)
+	}}
+	err = h.svc.ExportProject(r.Context(), userID, projectID, sw, project.ExportOptions{IncludePrompts: includePrompts})
+	if err == nil {
+		return
+	}
+	if sw.started {
+		// Headers are already sent; the client sees a truncated archive.
+		logctx.Error(r.Context(), "project export aborted", err, "project_id", projectID)
+		return
+	}
+	http.Error(w, err.Error(), archiveErrorStatus(err))
+}
+
+func (h *ProjectArchiveHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	userID, err := auth.ResolveUserID(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if userID.IsZero() {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	e, err := h.svc.ImportProject(r.Context(), userID, body)
+	if err != nil {
+		http.Error(w, err.Error(), archiveErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"project_id":   e.State.ProjectID,
+		"project_name": e.State.ProjectName,
+	})
+}
+
+func archiveErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, project.ErrUnsupportedArchive):
+		return http.StatusBadRequest
+	case errors.Is(err, project.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, project.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// startedWriter defers response headers until the first body write so that
+// validation errors can still be reported with a proper status code.
+type startedWriter struct {
+	http.ResponseWriter
+	onStart func(http.ResponseWriter)
+	started bool
+}
+
+func (w *startedWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.onStart(w.ResponseWriter)
+	}
+	return w.ResponseWriter.Write(p)
+}
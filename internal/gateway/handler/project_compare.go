@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"insightify/internal/artifactdiff"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/service/project"
+)
+
+// ProjectCompareHandler serves structured diffs between two runs' artifacts
+// over plain HTTP.
+type ProjectCompareHandler struct {
+	svc *project.Service
+}
+
+func NewProjectCompareHandler(svc *project.Service) *ProjectCompareHandler {
+	return &ProjectCompareHandler{svc: svc}
+}
+
+// HandleCompareRuns serves GET
+// /project/compare-runs?project_id=...&key=...&head_run=...[&base_run=...][&weight_threshold=N].
+// Without base_run the head run is compared with the previous run that
+// stored key.
+func (h *ProjectCompareHandler) HandleCompareRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	userID, err := auth.ResolveUserID(r.Context(), q.Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	projectID := strings.TrimSpace(q.Get("project_id"))
+	req := project.CompareRequest{
+		Key:       strings.TrimSpace(q.Get("key")),
+		BaseRunID: strings.TrimSpace(q.Get("base_run")),
+		HeadRunID: strings.TrimSpace(q.Get("head_run")),
+	}
+	if userID.IsZero() || projectID == "" || req.Key == "" || req.HeadRunID == "" {
+		http.Error(w, "user_id, project_id, key and head_run are required", http.StatusBadRequest)
+		return
+	}
+	if raw := q.Get("weight_threshold"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "weight_threshold must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		req.Options.WeightThreshold = n
+	}
+
+	res, err := h.svc.CompareRuns(r.Context(), userID, projectID, req)
+	if err != nil {
+		status := archiveErrorStatus(err)
+		if errors.Is(err, artifactdiff.ErrUnsupportedKey) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// HandleListArtifacts serves GET
+// /project/artifacts?project_id=...[&worker=...]. Versioned outputs carry
+// their worker and version, so a client can pick the runs to pass to
+// /project/compare-runs.
+func (h *ProjectCompareHandler) HandleListArtifacts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	userID, err := auth.ResolveUserID(r.Context(), q.Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	projectID := strings.TrimSpace(q.Get("project_id"))
+	if userID.IsZero() || projectID == "" {
+		http.Error(w, "user_id and project_id are required", http.StatusBadRequest)
+		return
+	}
+	artifacts, err := h.svc.ListArtifacts(r.Context(), userID, projectID, q.Get("worker"))
+	if err != nil {
+		http.Error(w, err.Error(), archiveErrorStatus(err))
+		return
+	}
+	if artifacts == nil {
+		artifacts = []project.ArtifactView{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"project_id": projectID,
+		"artifacts":  artifacts,
+	})
+}
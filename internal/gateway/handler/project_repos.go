@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/entity"
+	"insightify/internal/gateway/service/project"
+)
+
+// ProjectReposHandler lists and replaces the repositories of a multi-repo
+// project over plain HTTP.
+type ProjectReposHandler struct {
+	svc *project.Service
+}
+
+func NewProjectReposHandler(svc *project.Service) *ProjectReposHandler {
+	return &ProjectReposHandler{svc: svc}
+}
+
+// maxReposBytes bounds the PUT body of /project/repos.
+const maxReposBytes = 64 << 10
+
+type projectReposBody struct {
+	Repos []entity.RepoEntry `json:"repos"`
+}
+
+// HandleRepos serves GET (list) and PUT (replace) on
+// /project/repos?project_id=...; the first repo is the default.
+func (h *ProjectReposHandler) HandleRepos(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ResolveUserID(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+	if userID.IsZero() || projectID == "" {
+		http.Error(w, "user_id and project_id are required", http.StatusBadRequest)
+		return
+	}
+
+	var repos []entity.RepoEntry
+	switch r.Method {
+	case http.MethodGet:
+		st, ok := h.svc.GetEntry(projectID)
+		if !ok {
+			http.Error(w, "project not found", http.StatusNotFound)
+			return
+		}
+		if st.UserID != userID {
+			http.Error(w, "project does not belong to user", http.StatusForbidden)
+			return
+		}
+		repos = st.Repos
+	case http.MethodPut:
+		var body projectReposBody
+		r.Body = http.MaxBytesReader(w, r.Body, maxReposBytes)
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e, err := h.svc.SetRepos(r.Context(), userID, projectID, body.Repos)
+		if err != nil {
+			status := archiveErrorStatus(err)
+			if errors.Is(err, entity.ErrInvalidRepo) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		repos = e.State.Repos
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if repos == nil {
+		repos = []entity.RepoEntry{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(projectReposBody{Repos: repos})
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"insightify/internal/artifactsearch"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/service/project"
+)
+
+// ProjectSearchHandler serves full-text search across a project's artifacts
+// over plain HTTP.
+type ProjectSearchHandler struct {
+	svc *project.Service
+}
+
+func NewProjectSearchHandler(svc *project.Service) *ProjectSearchHandler {
+	return &ProjectSearchHandler{svc: svc}
+}
+
+// HandleSearch serves GET
+// /project/search?project_id=...&q=...[&source=identifier,component,file,gap][&limit=N].
+// Every term of q must match; hits carry the artifact key and JSON pointer.
+func (h *ProjectSearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	userID, err := auth.ResolveUserID(r.Context(), q.Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	projectID := strings.TrimSpace(q.Get("project_id"))
+	req := project.SearchRequest{Query: strings.TrimSpace(q.Get("q"))}
+	if userID.IsZero() || projectID == "" || req.Query == "" {
+		http.Error(w, "user_id, project_id and q are required", http.StatusBadRequest)
+		return
+	}
+	for _, v := range q["source"] {
+		req.Sources = append(req.Sources, strings.Split(v, ",")...)
+	}
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		req.Limit = n
+	}
+
+	res, err := h.svc.SearchProject(r.Context(), userID, projectID, req)
+	if err != nil {
+		status := archiveErrorStatus(err)
+		if errors.Is(err, artifactsearch.ErrUnknownSource) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
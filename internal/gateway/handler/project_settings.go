@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/service/project"
+)
+
+// ProjectSettingsHandler reads and replaces the run defaults of a project
+// over plain HTTP.
+type ProjectSettingsHandler struct {
+	svc *project.Service
+}
+
+func NewProjectSettingsHandler(svc *project.Service) *ProjectSettingsHandler {
+	return &ProjectSettingsHandler{svc: svc}
+}
+
+// HandleSettings serves GET and PUT on /project/settings?project_id=...
+func (h *ProjectSettingsHandler) HandleSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.ResolveUserID(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	projectID := strings.TrimSpace(r.URL.Query().Get("project_id"))
+	if userID.IsZero() || projectID == "" {
+		http.Error(w, "user_id and project_id are required", http.StatusBadRequest)
+		return
+	}
+
+	var settings project.Settings
+	switch r.Method {
+	case http.MethodGet:
+		st, ok := h.svc.GetEntry(projectID)
+		if !ok {
+			http.Error(w, "project not found", http.StatusNotFound)
+			return
+		}
+		if st.UserID != userID {
+			http.Error(w, "project does not belong to user", http.StatusForbidden)
+			return
+		}
+		settings.CostBudgetUSD = st.CostBudgetUSD
+		settings.InputWaitTimeoutMs = st.InputWaitTimeoutMs
+		settings.Locale = st.Locale
+		settings.MinCodeRatio = st.MinCodeRatio
+		settings.MinCodeBytes = st.MinCodeBytes
+	case http.MethodPut:
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		e, err := h.svc.SetSettings(r.Context(), userID, projectID, settings)
+		if err != nil {
+			status := archiveErrorStatus(err)
+			if errors.Is(err, project.ErrInvalidSettings) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		settings.CostBudgetUSD = e.State.CostBudgetUSD
+		settings.InputWaitTimeoutMs = e.State.InputWaitTimeoutMs
+		settings.Locale = e.State.Locale
+		settings.MinCodeRatio = e.State.MinCodeRatio
+		settings.MinCodeBytes = e.State.MinCodeBytes
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(settings)
+}
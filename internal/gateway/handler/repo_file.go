@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/entity"
+)
+
+const (
+	// MaxRepoFileBytes caps the size of files served by HandleRepoFile.
+	MaxRepoFileBytes = 2 << 20
+	// MaxRepoFileLines caps the lines returned by one request; longer ranges
+	// are clamped.
+	MaxRepoFileLines = 2000
+)
+
+// Errors returned by readRepoFile.
+var (
+	ErrBinaryFile      = errors.New("file is binary")
+	ErrFileTooLarge    = errors.New("file exceeds size limit")
+	ErrNotRegularFile  = errors.New("path is not a regular file")
+	ErrLineOutOfRange  = errors.New("start_line is beyond the end of the file")
+	ErrInvalidRepoPath = errors.New("path must be relative to the repository root")
+)
+
+// RepoFSResolver returns the checkout of a project repository the user owns;
+// project.Service.RepoFS satisfies it.
+type RepoFSResolver func(ctx context.Context, userID entity.UserID, projectID, repo string) (*safeio.SafeFS, error)
+
+// RepoFileHandler serves source files of a project's repository checkout.
+type RepoFileHandler struct {
+	repoFS RepoFSResolver
+}
+
+func NewRepoFileHandler(repoFS RepoFSResolver) *RepoFileHandler {
+	return &RepoFileHandler{repoFS: repoFS}
+}
+
+type repoFileResponse struct {
+	Path       string `json:"path"`
+	Language   string `json:"language"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	TotalLines int    `json:"total_lines"`
+	Clamped    bool   `json:"clamped"`
+	Hash       string `json:"hash"`
+	Content    string `json:"content"`
+}
+
+// HandleRepoFile serves GET /project/repo-file?project_id=...&path=...
+// [&start_line=...&end_line=...&repo=...]. Lines are 1-based and inclusive;
+// content uses "\n" line endings whatever the file uses.
+func (h *RepoFileHandler) HandleRepoFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	userID, err := auth.ResolveUserID(r.Context(), q.Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	projectID := strings.TrimSpace(q.Get("project_id"))
+	path := strings.TrimSpace(q.Get("path"))
+	if userID.IsZero() || projectID == "" || path == "" {
+		http.Error(w, "user_id, project_id and path are required", http.StatusBadRequest)
+		return
+	}
+	start, err := lineParam(q.Get("start_line"))
+	if err != nil {
+		http.Error(w, "start_line: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	end, err := lineParam(q.Get("end_line"))
+	if err != nil {
+		http.Error(w, "end_line: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if end > 0 && start > end {
+		http.Error(w, "start_line must not exceed end_line", http.StatusBadRequest)
+		return
+	}
+
+	fsys, err := h.repoFS(r.Context(), userID, projectID, strings.TrimSpace(q.Get("repo")))
+	if err != nil {
+		http.Error(w, err.Error(), archiveErrorStatus(err))
+		return
+	}
+	resp, err := readRepoFile(fsys, path, start, end)
+	if err != nil {
+		http.Error(w, err.Error(), repoFileErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", strconv.Quote(resp.Hash))
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// lineParam parses an optional 1-based line number; zero means unset.
+func lineParam(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, errors.New("must be a positive integer")
+	}
+	return n, nil
+}
+
+// readRepoFile reads lines start..end of path; zero start/end mean the first
+// and last line.
+func readRepoFile(fsys *safeio.SafeFS, path string, start, end int) (repoFileResponse, error) {
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return repoFileResponse{}, ErrInvalidRepoPath
+	}
+	info, err := fsys.SafeStat(clean)
+	if err != nil {
+		return repoFileResponse{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return repoFileResponse{}, ErrNotRegularFile
+	}
+	if info.Size() > MaxRepoFileBytes {
+		return repoFileResponse{}, ErrFileTooLarge
+	}
+	raw, err := fsys.SafeReadFile(clean)
+	if err != nil {
+		return repoFileResponse{}, err
+	}
+	if bytes.IndexByte(raw, 0) >= 0 || !utf8.Valid(raw) {
+		return repoFileResponse{}, ErrBinaryFile
+	}
+
+	sum := sha256.Sum256(raw)
+	resp := repoFileResponse{
+		Path:     filepath.ToSlash(clean),
+		Language: scan.Language(clean),
+		Hash:     "sha256:" + hex.EncodeToString(sum[:]),
+	}
+	if resp.Language == "" {
+		resp.Language = "plaintext"
+	}
+
+	text := strings.ReplaceAll(string(raw), "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if text == "" {
+		lines = nil
+	}
+	resp.TotalLines = len(lines)
+
+	if start == 0 {
+		start = 1
+	}
+	if start > max(len(lines), 1) {
+		return repoFileResponse{}, ErrLineOutOfRange
+	}
+	if end > len(lines) {
+		resp.Clamped = true
+	}
+	if end == 0 || end > len(lines) {
+		end = len(lines)
+	}
+	if end-start+1 > MaxRepoFileLines {
+		end = start + MaxRepoFileLines - 1
+		resp.Clamped = true
+	}
+	resp.StartLine, resp.EndLine = start, end
+	if end >= start {
+		resp.Content = strings.Join(lines[start-1:end], "\n")
+	}
+	return resp, nil
+}
+
+func repoFileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidRepoPath), errors.Is(err, ErrNotRegularFile):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrBinaryFile):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrLineOutOfRange):
+		return http.StatusRequestedRangeNotSatisfiable
+	case errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound
+	case strings.Contains(err.Error(), "safeio:"):
+		// Traversal or a symlink resolving outside the checkout.
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
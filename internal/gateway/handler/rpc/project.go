@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/gateway/auth"
 	"insightify/internal/gateway/entity"
 	"insightify/internal/gateway/service/project"
 
@@ -21,7 +22,10 @@ func NewProjectHandler(svc *project.Service) *ProjectHandler {
 }
 
 func (h *ProjectHandler) ListProjects(ctx context.Context, req *connect.Request[insightifyv1.ListProjectsRequest]) (*connect.Response[insightifyv1.ListProjectsResponse], error) {
-	userID := entity.NormalizeUserID(req.Msg.GetUserId())
+	userID, err := requestUserID(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
 	if userID.IsZero() {
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("user_id is required"))
 	}
@@ -42,7 +46,10 @@ func (h *ProjectHandler) ListProjects(ctx context.Context, req *connect.Request[
 }
 
 func (h *ProjectHandler) CreateProject(ctx context.Context, req *connect.Request[insightifyv1.CreateProjectRequest]) (*connect.Response[insightifyv1.CreateProjectResponse], error) {
-	userID := entity.NormalizeUserID(req.Msg.GetUserId())
+	userID, err := requestUserID(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
 	if userID.IsZero() {
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("user_id is required"))
 	}
@@ -57,7 +64,10 @@ func (h *ProjectHandler) CreateProject(ctx context.Context, req *connect.Request
 }
 
 func (h *ProjectHandler) SelectProject(ctx context.Context, req *connect.Request[insightifyv1.SelectProjectRequest]) (*connect.Response[insightifyv1.SelectProjectResponse], error) {
-	userID := entity.NormalizeUserID(req.Msg.GetUserId())
+	userID, err := requestUserID(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
 	projectID := strings.TrimSpace(req.Msg.GetProjectId())
 	if userID.IsZero() || projectID == "" {
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("user_id and project_id are required"))
@@ -72,7 +82,10 @@ func (h *ProjectHandler) SelectProject(ctx context.Context, req *connect.Request
 }
 
 func (h *ProjectHandler) EnsureProject(ctx context.Context, req *connect.Request[insightifyv1.EnsureProjectRequest]) (*connect.Response[insightifyv1.EnsureProjectResponse], error) {
-	userID := entity.NormalizeUserID(req.Msg.GetUserId())
+	userID, err := requestUserID(ctx, req.Msg.GetUserId())
+	if err != nil {
+		return nil, err
+	}
 	projectID := strings.TrimSpace(req.Msg.GetProjectId())
 
 	p, err := h.svc.EnsureProject(ctx, userID, projectID)
@@ -84,3 +97,26 @@ func (h *ProjectHandler) EnsureProject(ctx context.Context, req *connect.Request
 		ProjectId: p.State.ProjectID,
 	}), nil
 }
+
+// requestUserID resolves the acting user from the authenticated identity and
+// the optional request user_id, which must match it.
+func requestUserID(ctx context.Context, requested string) (entity.UserID, error) {
+	userID, err := auth.ResolveUserID(ctx, requested)
+	if err != nil {
+		return "", connect.NewError(connect.CodePermissionDenied, err)
+	}
+	return userID, nil
+}
+
+// actingUserID resolves the caller of RPCs that carry no user_id. Anonymous
+// dev-allowlisted calls act as the demo user, as EnsureProject does.
+func actingUserID(ctx context.Context) (entity.UserID, error) {
+	userID, err := requestUserID(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	if userID.IsZero() {
+		userID = entity.DemoUserID
+	}
+	return userID, nil
+}
@@ -2,11 +2,13 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	insightifyv1 "insightify/gen/go/insightify/v1"
 	"insightify/internal/gateway/service/worker"
+	"insightify/internal/runner"
 
 	"connectrpc.com/connect"
 )
@@ -20,6 +22,17 @@ func NewRunHandler(svc *worker.Service) *RunHandler {
 }
 
 func (h *RunHandler) StartRun(ctx context.Context, req *connect.Request[insightifyv1.StartRunRequest]) (*connect.Response[insightifyv1.StartRunResponse], error) {
+	userID, err := actingUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// StartRun itself reports a missing project_id.
+	if projectID := strings.TrimSpace(req.Msg.GetProjectId()); projectID != "" {
+		if err := h.svc.AuthorizeProject(projectID, userID); err != nil {
+			return nil, toRunError(err)
+		}
+	}
+	ctx = worker.WithAcceptLanguage(ctx, req.Header().Get("Accept-Language"))
 	out, err := h.svc.StartRun(ctx, req.Msg)
 	if err != nil {
 		return nil, toRunError(err)
@@ -30,6 +43,12 @@ func (h *RunHandler) StartRun(ctx context.Context, req *connect.Request[insighti
 func toRunError(err error) error {
 	msg := strings.ToLower(strings.TrimSpace(err.Error()))
 	switch {
+	case errors.Is(err, worker.ErrForbidden):
+		return connect.NewError(connect.CodePermissionDenied, err)
+	case errors.Is(err, runner.ErrRunLocked):
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	case errors.Is(err, runner.ErrUnknownPhase), errors.Is(err, worker.ErrInvalidRun):
+		return connect.NewError(connect.CodeInvalidArgument, err)
 	case strings.Contains(msg, "required"):
 		return connect.NewError(connect.CodeInvalidArgument, err)
 	case strings.Contains(msg, "not found"):
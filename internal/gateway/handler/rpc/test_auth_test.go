@@ -0,0 +1,70 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/gen/go/insightify/v1/insightifyv1connect"
+	projectcache "insightify/internal/cache/project"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/middleware"
+	"insightify/internal/gateway/service/project"
+)
+
+func newAuthTestClient(t *testing.T) insightifyv1connect.ProjectServiceClient {
+	t.Helper()
+	mem := projectcache.NewMemoryStore()
+	for _, st := range []projectcache.State{
+		{ProjectID: "project-alice", ProjectName: "A", UserID: "alice"},
+		{ProjectID: "project-bob", ProjectName: "B", UserID: "bob"},
+	} {
+		if err := mem.Put(context.Background(), st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	authn := middleware.NewAuthenticator(auth.NewStaticTokenVerifier(map[string]string{
+		"tok-alice": "alice",
+		"tok-bob":   "bob",
+	}), nil)
+
+	mux := http.NewServeMux()
+	mux.Handle(insightifyv1connect.NewProjectServiceHandler(
+		NewProjectHandler(project.New(mem, mem, nil)),
+		connect.WithInterceptors(authn.Interceptor()),
+	))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return insightifyv1connect.NewProjectServiceClient(srv.Client(), srv.URL)
+}
+
+func selectAs(client insightifyv1connect.ProjectServiceClient, token, userID, projectID string) error {
+	req := connect.NewRequest(&insightifyv1.SelectProjectRequest{UserId: userID, ProjectId: projectID})
+	if token != "" {
+		req.Header().Set("Authorization", "Bearer "+token)
+	}
+	_, err := client.SelectProject(context.Background(), req)
+	return err
+}
+
+func TestSelectProjectUsesAuthenticatedUser(t *testing.T) {
+	client := newAuthTestClient(t)
+
+	// user_id is optional once authenticated.
+	if err := selectAs(client, "tok-bob", "", "project-bob"); err != nil {
+		t.Fatalf("bob selecting own project: %v", err)
+	}
+	if err := selectAs(client, "tok-bob", "", "project-alice"); err == nil {
+		t.Fatalf("bob must not select alice's project")
+	}
+	if err := selectAs(client, "tok-bob", "alice", "project-alice"); connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Fatalf("claiming another user_id: code = %v, want permission_denied (err=%v)", connect.CodeOf(err), err)
+	}
+	if err := selectAs(client, "", "alice", "project-alice"); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("no token: code = %v, want unauthenticated (err=%v)", connect.CodeOf(err), err)
+	}
+}
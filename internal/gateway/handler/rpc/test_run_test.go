@@ -0,0 +1,62 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/entity"
+	"insightify/internal/gateway/service/worker"
+	"insightify/internal/runner"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+type fixedProjectReader struct {
+	rt *runtimepkg.ProjectRuntime
+}
+
+func (r fixedProjectReader) GetEntry(projectID string) (worker.ProjectView, bool) {
+	return worker.ProjectView{ProjectID: projectID, UserID: entity.DemoUserID}, true
+}
+
+func (r fixedProjectReader) EnsureRunContext(string) (*runtimepkg.ProjectRuntime, error) {
+	return r.rt, nil
+}
+
+func TestStartRunRejectsUnknownPhase(t *testing.T) {
+	reader := fixedProjectReader{rt: &runtimepkg.ProjectRuntime{
+		ID:       "project-1",
+		OutDir:   t.TempDir(),
+		Resolver: runner.MergeRegistries(map[string]runner.WorkerSpec{"a": {Key: "a"}}),
+	}}
+	h := NewRunHandler(worker.New(reader, nil, nil, nil, nil, nil))
+
+	_, err := h.StartRun(context.Background(), connect.NewRequest(&insightifyv1.StartRunRequest{
+		ProjectId: "project-1",
+		Params:    map[string]string{runner.RunParamPhases: "a,nope"},
+	}))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("StartRun() error = %v, want %v", err, connect.CodeInvalidArgument)
+	}
+}
+
+func TestStartRunRejectsOtherUsersProject(t *testing.T) {
+	reader := fixedProjectReader{rt: &runtimepkg.ProjectRuntime{
+		ID:       "project-1",
+		OutDir:   t.TempDir(),
+		Resolver: runner.MergeRegistries(map[string]runner.WorkerSpec{"a": {Key: "a"}}),
+	}}
+	h := NewRunHandler(worker.New(reader, nil, nil, nil, nil, nil))
+
+	ctx := auth.WithUserID(context.Background(), "mallory")
+	_, err := h.StartRun(ctx, connect.NewRequest(&insightifyv1.StartRunRequest{
+		ProjectId: "project-1",
+		WorkerId:  "a",
+	}))
+	if connect.CodeOf(err) != connect.CodePermissionDenied {
+		t.Fatalf("StartRun() error = %v, want %v", err, connect.CodePermissionDenied)
+	}
+}
@@ -2,24 +2,54 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"connectrpc.com/connect"
 
 	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/gateway/entity"
 	gatewayui "insightify/internal/gateway/service/ui"
+	"insightify/internal/gateway/service/worker"
 )
 
+// RunAuthorizer fails unless userID owns the project of runID; see
+// worker.Service.AuthorizeRun.
+type RunAuthorizer func(runID string, userID entity.UserID) error
+
+// ProjectAuthorizer fails unless userID owns projectID; see
+// worker.Service.AuthorizeProject.
+type ProjectAuthorizer func(projectID string, userID entity.UserID) error
+
 type UiHandler struct {
-	svc *gatewayui.Service
+	svc          *gatewayui.Service
+	authorizeRun RunAuthorizer
+}
+
+func NewUiHandler(svc *gatewayui.Service, authorizeRun RunAuthorizer) *UiHandler {
+	return &UiHandler{svc: svc, authorizeRun: authorizeRun}
 }
 
-func NewUiHandler(svc *gatewayui.Service) *UiHandler {
-	return &UiHandler{svc: svc}
+// authorize checks that the caller owns the project of runID.
+func (h *UiHandler) authorize(ctx context.Context, runID string) error {
+	if strings.TrimSpace(runID) == "" {
+		return nil // the service reports the missing ID
+	}
+	userID, err := actingUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.authorizeRun(runID, userID); err != nil {
+		return toUIError(err)
+	}
+	return nil
 }
 
 func (h *UiHandler) GetDocument(ctx context.Context, req *connect.Request[insightifyv1.GetUiDocumentRequest]) (*connect.Response[insightifyv1.GetUiDocumentResponse], error) {
+	if err := h.authorize(ctx, req.Msg.GetRunId()); err != nil {
+		return nil, err
+	}
 	out, err := h.svc.GetDocument(ctx, req.Msg)
 	if err != nil {
 		return nil, toUIError(err)
@@ -28,6 +58,9 @@ func (h *UiHandler) GetDocument(ctx context.Context, req *connect.Request[insigh
 }
 
 func (h *UiHandler) ApplyOps(ctx context.Context, req *connect.Request[insightifyv1.ApplyUiOpsRequest]) (*connect.Response[insightifyv1.ApplyUiOpsResponse], error) {
+	if err := h.authorize(ctx, req.Msg.GetRunId()); err != nil {
+		return nil, err
+	}
 	out, err := h.svc.ApplyOps(ctx, req.Msg)
 	if err != nil {
 		return nil, toUIError(err)
@@ -37,6 +70,12 @@ func (h *UiHandler) ApplyOps(ctx context.Context, req *connect.Request[insightif
 
 func toUIError(err error) error {
 	msg := strings.ToLower(strings.TrimSpace(err.Error()))
+	if errors.Is(err, worker.ErrForbidden) {
+		return connect.NewError(connect.CodePermissionDenied, err)
+	}
+	if strings.Contains(msg, "not found") {
+		return connect.NewError(connect.CodeNotFound, err)
+	}
 	if strings.Contains(msg, "required") || strings.Contains(msg, "unsupported") {
 		return connect.NewError(connect.CodeInvalidArgument, err)
 	}
@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"context"
+	"strings"
 
 	"connectrpc.com/connect"
 
@@ -10,14 +11,33 @@ import (
 )
 
 type UiWorkspaceHandler struct {
-	svc *gatewayui.Service
+	svc              *gatewayui.Service
+	authorizeProject ProjectAuthorizer
 }
 
-func NewUiWorkspaceHandler(svc *gatewayui.Service) *UiWorkspaceHandler {
-	return &UiWorkspaceHandler{svc: svc}
+func NewUiWorkspaceHandler(svc *gatewayui.Service, authorizeProject ProjectAuthorizer) *UiWorkspaceHandler {
+	return &UiWorkspaceHandler{svc: svc, authorizeProject: authorizeProject}
+}
+
+// authorize checks that the caller owns projectID.
+func (h *UiWorkspaceHandler) authorize(ctx context.Context, projectID string) error {
+	if strings.TrimSpace(projectID) == "" {
+		return nil // the service reports the missing ID
+	}
+	userID, err := actingUserID(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.authorizeProject(projectID, userID); err != nil {
+		return toUIError(err)
+	}
+	return nil
 }
 
 func (h *UiWorkspaceHandler) GetWorkspace(ctx context.Context, req *connect.Request[insightifyv1.GetUiWorkspaceRequest]) (*connect.Response[insightifyv1.GetUiWorkspaceResponse], error) {
+	if err := h.authorize(ctx, req.Msg.GetProjectId()); err != nil {
+		return nil, err
+	}
 	out, err := h.svc.GetWorkspace(ctx, req.Msg)
 	if err != nil {
 		return nil, toUIError(err)
@@ -26,6 +46,9 @@ func (h *UiWorkspaceHandler) GetWorkspace(ctx context.Context, req *connect.Requ
 }
 
 func (h *UiWorkspaceHandler) CreateTab(ctx context.Context, req *connect.Request[insightifyv1.CreateUiTabRequest]) (*connect.Response[insightifyv1.CreateUiTabResponse], error) {
+	if err := h.authorize(ctx, req.Msg.GetProjectId()); err != nil {
+		return nil, err
+	}
 	out, err := h.svc.CreateTab(ctx, req.Msg)
 	if err != nil {
 		return nil, toUIError(err)
@@ -34,6 +57,9 @@ func (h *UiWorkspaceHandler) CreateTab(ctx context.Context, req *connect.Request
 }
 
 func (h *UiWorkspaceHandler) SelectTab(ctx context.Context, req *connect.Request[insightifyv1.SelectUiTabRequest]) (*connect.Response[insightifyv1.SelectUiTabResponse], error) {
+	if err := h.authorize(ctx, req.Msg.GetProjectId()); err != nil {
+		return nil, err
+	}
 	out, err := h.svc.SelectTab(ctx, req.Msg)
 	if err != nil {
 		return nil, toUIError(err)
@@ -42,6 +68,9 @@ func (h *UiWorkspaceHandler) SelectTab(ctx context.Context, req *connect.Request
 }
 
 func (h *UiWorkspaceHandler) Restore(ctx context.Context, req *connect.Request[insightifyv1.RestoreUiRequest]) (*connect.Response[insightifyv1.RestoreUiResponse], error) {
+	if err := h.authorize(ctx, req.Msg.GetProjectId()); err != nil {
+		return nil, err
+	}
 	out, err := h.svc.Restore(ctx, req.Msg)
 	if err != nil {
 		return nil, toUIError(err)
@@ -50,6 +79,9 @@ func (h *UiWorkspaceHandler) Restore(ctx context.Context, req *connect.Request[i
 }
 
 func (h *UiWorkspaceHandler) CreateNodeInTab(ctx context.Context, req *connect.Request[insightifyv1.CreateNodeInTabRequest]) (*connect.Response[insightifyv1.CreateNodeInTabResponse], error) {
+	if err := h.authorize(ctx, req.Msg.GetProjectId()); err != nil {
+		return nil, err
+	}
 	out, err := h.svc.CreateNodeInTab(ctx, req.Msg)
 	if err != nil {
 		return nil, toUIError(err)
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"insightify/internal/gateway/entity"
+	"insightify/internal/gateway/service/project"
+	"insightify/internal/llm/hooks"
+	llmmiddleware "insightify/internal/llm/middleware"
+	llmmodel "insightify/internal/llm/model"
+)
+
+func newPromptDebugHandler(t *testing.T) (*DebugHandler, string) {
+	t.Helper()
+	outDir := t.TempDir()
+	saver := &hooks.PromptSaver{Dir: outDir, RunID: "run-1"}
+	ctx := context.Background()
+	saver.Before(ctx, "arch_design", "Describe the architecture.", map[string]any{"iteration": 1})
+	saver.After(ctx, "arch_design", json.RawMessage(`{"delta":{}}`), nil)
+	saver.Before(ctx, "arch_design", "Describe the architecture.", map[string]any{"iteration": 2})
+	saver.After(ctx, "arch_design", nil, errors.New("rate limited"))
+	saver.Before(ctx, "code_symbols", "List identifiers.", nil)
+
+	// A secret next to the prompt directory must stay unreachable.
+	if err := os.WriteFile(filepath.Join(outDir, "secret.txt"), []byte("token"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := NewDebugHandler(func(_ context.Context, userID entity.UserID, projectID string) (string, error) {
+		if projectID != "project-1" {
+			return "", fmt.Errorf("project %s %w", projectID, project.ErrNotFound)
+		}
+		return filepath.Join(outDir, hooks.PromptDir), nil
+	}, nil)
+	return h, outDir
+}
+
+func getPrompt(h *DebugHandler, params url.Values) *httptest.ResponseRecorder {
+	params.Set("user_id", "demo-user")
+	params.Set("project_id", "project-1")
+	rec := httptest.NewRecorder()
+	h.HandlePrompt(rec, httptest.NewRequest(http.MethodGet, "/debug/prompt?"+params.Encode(), nil))
+	return rec
+}
+
+func TestHandlePromptReturnsExchanges(t *testing.T) {
+	h, _ := newPromptDebugHandler(t)
+
+	rec := getPrompt(h, url.Values{"run_id": {"run-1"}, "phase": {"arch_design"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var body promptLogResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Exchanges) != 2 {
+		t.Fatalf("exchanges = %+v, want 2", body.Exchanges)
+	}
+	first, second := body.Exchanges[0], body.Exchanges[1]
+	if first.Prompt != "Describe the architecture." || string(first.Response) != `{"delta":{}}` {
+		t.Fatalf("first exchange = %+v", first)
+	}
+	var input map[string]int
+	if err := json.Unmarshal(second.Input, &input); err != nil || input["iteration"] != 2 {
+		t.Fatalf("second input = %s (%v)", second.Input, err)
+	}
+	if second.Error != "rate limited" || second.Response != nil {
+		t.Fatalf("second exchange = %+v", second)
+	}
+
+	rec = getPrompt(h, url.Values{"run_id": {"run-1"}})
+	var phases promptPhasesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &phases); err != nil {
+		t.Fatalf("decode phases %q: %v", rec.Body.String(), err)
+	}
+	if len(phases.Phases) != 2 || phases.Phases[0] != "arch_design" || phases.Phases[1] != "code_symbols" {
+		t.Fatalf("phases = %v", phases.Phases)
+	}
+}
+
+func TestHandlePromptRejectsTraversal(t *testing.T) {
+	h, outDir := newPromptDebugHandler(t)
+	for _, params := range []url.Values{
+		{"run_id": {".."}, "phase": {"secret"}},
+		{"run_id": {"../.."}, "phase": {"secret"}},
+		{"run_id": {"run-1"}, "phase": {"../../secret"}},
+		{"run_id": {`..\..`}, "phase": {"secret"}},
+	} {
+		rec := getPrompt(h, params)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%v: code=%d body=%s", params, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Symlinks out of the prompt directory are refused by safeio.
+	link := filepath.Join(outDir, hooks.PromptDir, "run-1", "leak.txt")
+	if err := os.Symlink(filepath.Join(outDir, "secret.txt"), link); err != nil {
+		t.Fatal(err)
+	}
+	if rec := getPrompt(h, url.Values{"run_id": {"run-1"}, "phase": {"leak"}}); rec.Code != http.StatusNotFound {
+		t.Fatalf("symlink escape: code=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	if rec := getPrompt(h, url.Values{"run_id": {"run-2"}, "phase": {"arch_design"}}); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown run: code=%d", rec.Code)
+	}
+}
+
+func TestHandleLLMChainReportsOrderAndIssues(t *testing.T) {
+	cli := llmmiddleware.Wrap(llmmodel.NewFakeClient(1024),
+		llmmiddleware.Retry(3, time.Millisecond),
+		llmmiddleware.SharedMultiLimit(llmmiddleware.NewLimiterRegistry()),
+	)
+	built := false
+	h := NewDebugHandler(nil, func() ([]llmmiddleware.MiddlewareInfo, bool) {
+		if !built {
+			return nil, false
+		}
+		return llmmiddleware.DescribeChain(cli), true
+	})
+	rec := httptest.NewRecorder()
+	h.HandleLLMChain(rec, httptest.NewRequest(http.MethodGet, "/debug/llm-chain", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("before any client is built: code=%d, want 503", rec.Code)
+	}
+
+	built = true
+	rec = httptest.NewRecorder()
+	h.HandleLLMChain(rec, httptest.NewRequest(http.MethodGet, "/debug/llm-chain", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var body llmChainResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Chain) != 2 || body.Chain[0].Name != llmmiddleware.MiddlewareRetry || body.Chain[1].Name != llmmiddleware.MiddlewareSharedMultiLimit {
+		t.Fatalf("chain = %+v", body.Chain)
+	}
+	if len(body.Issues) != 1 || body.Issues[0].Severity != llmmiddleware.SeverityError {
+		t.Fatalf("issues = %+v, want the missing select_model error", body.Issues)
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	llmclient "insightify/internal/llm/client"
+	llmmodel "insightify/internal/llm/model"
+)
+
+type failingLLM struct {
+	*llmmodel.FakeClient
+}
+
+func (failingLLM) GenerateJSON(context.Context, string, any) (json.RawMessage, error) {
+	return nil, errors.New("401 invalid api key")
+}
+
+func fakeFactory(cli llmclient.LLMClient) LLMClientFactory {
+	return func(context.Context) (llmclient.LLMClient, error) { return cli, nil }
+}
+
+func serveReady(t *testing.T, h *HealthHandler) (int, map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.HandleReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, body
+}
+
+func TestHandleReadyWithFakeClient(t *testing.T) {
+	for _, mode := range []string{ProbeCountTokens, ProbeGenerate} {
+		h := NewHealthHandler(mode, 0, fakeFactory(llmmodel.NewFakeClient(0)))
+		code, body := serveReady(t, h)
+		if code != http.StatusOK || body["status"] != "ready" || body["probe"] != mode {
+			t.Fatalf("mode %s: code=%d body=%v", mode, code, body)
+		}
+	}
+}
+
+func TestHandleReadyReportsFailingClient(t *testing.T) {
+	h := NewHealthHandler(ProbeGenerate, 0, fakeFactory(failingLLM{llmmodel.NewFakeClient(0)}))
+	code, body := serveReady(t, h)
+	if code != http.StatusServiceUnavailable || body["status"] != "not_ready" {
+		t.Fatalf("code=%d body=%v", code, body)
+	}
+	if reason, _ := body["reason"].(string); !strings.Contains(reason, "invalid api key") {
+		t.Fatalf("reason = %q, want the client error", reason)
+	}
+
+	// count_tokens does not send a request, so the same client is ready.
+	h = NewHealthHandler(ProbeCountTokens, 0, fakeFactory(failingLLM{llmmodel.NewFakeClient(0)}))
+	if code, body := serveReady(t, h); code != http.StatusOK {
+		t.Fatalf("count_tokens: code=%d body=%v", code, body)
+	}
+}
+
+func TestHandleReadyReportsClientBuildError(t *testing.T) {
+	calls := 0
+	h := NewHealthHandler("", 0, func(context.Context) (llmclient.LLMClient, error) {
+		calls++
+		return nil, errors.New("GEMINI_API_KEY is not set")
+	})
+	for i := 0; i < 2; i++ {
+		code, body := serveReady(t, h)
+		if code != http.StatusServiceUnavailable || !strings.Contains(body["reason"].(string), "GEMINI_API_KEY") {
+			t.Fatalf("code=%d body=%v", code, body)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("failed builds should be retried, calls=%d", calls)
+	}
+
+	h = NewHealthHandler(ProbeNone, 0, nil)
+	if code, body := serveReady(t, h); code != http.StatusOK {
+		t.Fatalf("none: code=%d body=%v", code, body)
+	}
+}
+
+// levelCountingLLM fails like the model registry when no level is selected
+// and counts the requests it serves.
+type levelCountingLLM struct {
+	*llmmodel.FakeClient
+	calls *int
+}
+
+func (c levelCountingLLM) GenerateJSON(ctx context.Context, prompt string, in any) (json.RawMessage, error) {
+	if llmmodel.ModelLevelFrom(ctx) == "" {
+		return nil, llmmodel.ErrModelLevelRequired
+	}
+	*c.calls++
+	return c.FakeClient.GenerateJSON(ctx, prompt, in)
+}
+
+func TestHandleReadyGenerateSelectsLevelAndCaches(t *testing.T) {
+	calls := 0
+	h := NewHealthHandler(ProbeGenerate, 0, fakeFactory(levelCountingLLM{llmmodel.NewFakeClient(0), &calls}))
+	for i := 0; i < 3; i++ {
+		if code, body := serveReady(t, h); code != http.StatusOK {
+			t.Fatalf("request %d: code=%d body=%v", i, code, body)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("generate calls = %d, want the first result reused", calls)
+	}
+
+	h.SetCacheTTL(-1)
+	serveReady(t, h)
+	if calls != 2 {
+		t.Fatalf("generate calls = %d, want a probe per request without a cache", calls)
+	}
+}
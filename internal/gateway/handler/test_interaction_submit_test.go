@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gatewayworker "insightify/internal/gateway/service/worker"
+)
+
+func TestHandleSubmitPassesCallerAndMapsErrors(t *testing.T) {
+	var got gatewayworker.SubmitInputRequest
+	h := NewInteractionSubmitHandler(func(_ context.Context, req gatewayworker.SubmitInputRequest) (*gatewayworker.SubmitInputResult, error) {
+		got = req
+		if req.InteractionID == "interaction-other" {
+			return nil, fmt.Errorf("run run-2: %w", gatewayworker.ErrForbidden)
+		}
+		return &gatewayworker.SubmitInputResult{ProjectID: "project-1", RunID: "run-1", NodeID: "node-1", InteractionID: req.InteractionID, Accepted: true}, nil
+	})
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.HandleSubmit(rec, httptest.NewRequest(http.MethodPost, "/interaction/submit?user_id=demo-user", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{"interaction_id":"interaction-1","input":"hello"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var out interactionSubmitResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Accepted || out.RunID != "run-1" || got.UserID != "demo-user" || got.Input != "hello" {
+		t.Fatalf("response = %+v, request = %+v", out, got)
+	}
+	if rec := post(`{"interaction_id":"interaction-other","input":"hello"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("foreign run status = %d, want 403", rec.Code)
+	}
+	if rec := post(`{`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad body status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"insightify/internal/common/safeio"
+	"insightify/internal/gateway/entity"
+	"insightify/internal/gateway/service/project"
+)
+
+func newRepoFileHandler(t *testing.T, files map[string]string) (*RepoFileHandler, string) {
+	t.Helper()
+	base := t.TempDir()
+	root := filepath.Join(base, "repo")
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A secret next to the checkout must stay unreachable.
+	if err := os.WriteFile(filepath.Join(base, "secret.txt"), []byte("token"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fsys, err := safeio.NewSafeFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewRepoFileHandler(func(_ context.Context, _ entity.UserID, projectID, _ string) (*safeio.SafeFS, error) {
+		if projectID != "project-1" {
+			return nil, fmt.Errorf("project %s %w", projectID, project.ErrNotFound)
+		}
+		return fsys, nil
+	})
+	return h, root
+}
+
+func getRepoFile(h *RepoFileHandler, params url.Values) *httptest.ResponseRecorder {
+	params.Set("user_id", "demo-user")
+	if params.Get("project_id") == "" {
+		params.Set("project_id", "project-1")
+	}
+	rec := httptest.NewRecorder()
+	h.HandleRepoFile(rec, httptest.NewRequest(http.MethodGet, "/project/repo-file?"+params.Encode(), nil))
+	return rec
+}
+
+func decodeRepoFile(t *testing.T, rec *httptest.ResponseRecorder) repoFileResponse {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var body repoFileResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return body
+}
+
+func TestHandleRepoFileReturnsRange(t *testing.T) {
+	h, _ := newRepoFileHandler(t, map[string]string{
+		"cmd/main.go": "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n",
+	})
+
+	got := decodeRepoFile(t, getRepoFile(h, url.Values{"path": {"cmd/main.go"}, "start_line": {"3"}, "end_line": {"4"}}))
+	if got.StartLine != 3 || got.EndLine != 4 || got.TotalLines != 5 || got.Clamped {
+		t.Fatalf("range = %+v", got)
+	}
+	if got.Content != "func main() {\n\tprintln(\"hi\")" || got.Language != "go" {
+		t.Fatalf("content/language = %q/%q", got.Content, got.Language)
+	}
+	if !strings.HasPrefix(got.Hash, "sha256:") {
+		t.Fatalf("hash = %q", got.Hash)
+	}
+
+	whole := decodeRepoFile(t, getRepoFile(h, url.Values{"path": {"cmd/main.go"}}))
+	if whole.StartLine != 1 || whole.EndLine != 5 || whole.Hash != got.Hash {
+		t.Fatalf("whole file = %+v", whole)
+	}
+}
+
+func TestHandleRepoFileCRLF(t *testing.T) {
+	h, _ := newRepoFileHandler(t, map[string]string{
+		"crlf.py":  "import os\r\nprint(os.name)\r\n",
+		"unix.py":  "import os\nprint(os.name)\n",
+		"notes.zz": "a\r\nb",
+	})
+	crlf := decodeRepoFile(t, getRepoFile(h, url.Values{"path": {"crlf.py"}}))
+	unix := decodeRepoFile(t, getRepoFile(h, url.Values{"path": {"unix.py"}}))
+	if crlf.Content != unix.Content || crlf.TotalLines != 2 || crlf.Language != "python" {
+		t.Fatalf("crlf = %+v, unix = %+v", crlf, unix)
+	}
+	if crlf.Hash == unix.Hash {
+		t.Fatalf("hash must reflect the raw bytes")
+	}
+
+	last := decodeRepoFile(t, getRepoFile(h, url.Values{"path": {"notes.zz"}, "start_line": {"2"}}))
+	if last.Content != "b" || last.TotalLines != 2 || last.Language != "plaintext" {
+		t.Fatalf("no trailing newline = %+v", last)
+	}
+}
+
+func TestHandleRepoFileRejectsTraversal(t *testing.T) {
+	h, root := newRepoFileHandler(t, map[string]string{"a.go": "package a\n"})
+	if err := os.Symlink(filepath.Join(root, "..", "secret.txt"), filepath.Join(root, "leak.txt")); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"../secret.txt", "sub/../../secret.txt", filepath.Join(root, "..", "secret.txt"), "leak.txt", "."} {
+		rec := getRepoFile(h, url.Values{"path": {path}})
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: code=%d body=%s", path, rec.Code, rec.Body.String())
+		}
+		if strings.Contains(rec.Body.String(), "token") {
+			t.Fatalf("%s: leaked secret", path)
+		}
+	}
+	if rec := getRepoFile(h, url.Values{"path": {"missing.go"}}); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing file: code=%d", rec.Code)
+	}
+	if rec := getRepoFile(h, url.Values{"path": {"a.go"}, "project_id": {"project-2"}}); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown project: code=%d", rec.Code)
+	}
+}
+
+func TestHandleRepoFileLineRanges(t *testing.T) {
+	var long strings.Builder
+	for i := 1; i <= MaxRepoFileLines+500; i++ {
+		fmt.Fprintf(&long, "line %d\n", i)
+	}
+	h, _ := newRepoFileHandler(t, map[string]string{
+		"short.ts": "one\ntwo\nthree\n",
+		"long.txt": long.String(),
+		"empty.md": "",
+	})
+
+	past := decodeRepoFile(t, getRepoFile(h, url.Values{"path": {"short.ts"}, "start_line": {"2"}, "end_line": {"99"}}))
+	if past.StartLine != 2 || past.EndLine != 3 || !past.Clamped || past.Content != "two\nthree" {
+		t.Fatalf("end past EOF = %+v", past)
+	}
+
+	huge := decodeRepoFile(t, getRepoFile(h, url.Values{"path": {"long.txt"}, "start_line": {"10"}}))
+	if huge.EndLine != 10+MaxRepoFileLines-1 || !huge.Clamped || huge.TotalLines != MaxRepoFileLines+500 {
+		t.Fatalf("huge range = start %d end %d clamped %v total %d", huge.StartLine, huge.EndLine, huge.Clamped, huge.TotalLines)
+	}
+	if !strings.HasPrefix(huge.Content, "line 10\n") {
+		t.Fatalf("huge range content starts %q", huge.Content[:20])
+	}
+
+	empty := decodeRepoFile(t, getRepoFile(h, url.Values{"path": {"empty.md"}}))
+	if empty.TotalLines != 0 || empty.Content != "" || empty.Language != "markdown" {
+		t.Fatalf("empty file = %+v", empty)
+	}
+
+	for _, tc := range []struct {
+		params url.Values
+		code   int
+	}{
+		{url.Values{"path": {"short.ts"}, "start_line": {"4"}}, http.StatusRequestedRangeNotSatisfiable},
+		{url.Values{"path": {"short.ts"}, "start_line": {"0"}}, http.StatusBadRequest},
+		{url.Values{"path": {"short.ts"}, "end_line": {"-1"}}, http.StatusBadRequest},
+		{url.Values{"path": {"short.ts"}, "start_line": {"3"}, "end_line": {"2"}}, http.StatusBadRequest},
+	} {
+		if rec := getRepoFile(h, tc.params); rec.Code != tc.code {
+			t.Fatalf("%v: code=%d want %d body=%s", tc.params, rec.Code, tc.code, rec.Body.String())
+		}
+	}
+}
+
+func TestHandleRepoFileRejectsBinaryAndDirectories(t *testing.T) {
+	h, _ := newRepoFileHandler(t, map[string]string{
+		"logo.png":   "\x89PNG\r\n\x1a\n\x00\x00",
+		"latin1.txt": "caf\xe9\n",
+		"pkg/a.go":   "package pkg\n",
+	})
+	for _, path := range []string{"logo.png", "latin1.txt"} {
+		if rec := getRepoFile(h, url.Values{"path": {path}}); rec.Code != http.StatusUnsupportedMediaType {
+			t.Fatalf("%s: code=%d body=%s", path, rec.Code, rec.Body.String())
+		}
+	}
+	if _, err := readRepoFile(mustSafeFS(t, h), "logo.png", 0, 0); !errors.Is(err, ErrBinaryFile) {
+		t.Fatalf("err = %v, want ErrBinaryFile", err)
+	}
+	if rec := getRepoFile(h, url.Values{"path": {"pkg"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("directory: code=%d", rec.Code)
+	}
+}
+
+func mustSafeFS(t *testing.T, h *RepoFileHandler) *safeio.SafeFS {
+	t.Helper()
+	fsys, err := h.repoFS(context.Background(), "demo-user", "project-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fsys
+}
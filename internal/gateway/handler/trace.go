@@ -2,7 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/entity"
 	gatewayworker "insightify/internal/gateway/service/worker"
+	llmmiddleware "insightify/internal/llm/middleware"
+	llmmodel "insightify/internal/llm/model"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,10 +15,44 @@ import (
 
 type TraceHandler struct {
 	workerSvc *gatewayworker.Service
+	models    *llmmodel.InMemoryModelRegistry
 }
 
-func NewTraceHandler(workerSvc *gatewayworker.Service) *TraceHandler {
-	return &TraceHandler{workerSvc: workerSvc}
+func NewTraceHandler(workerSvc *gatewayworker.Service, models *llmmodel.InMemoryModelRegistry) *TraceHandler {
+	return &TraceHandler{workerSvc: workerSvc, models: models}
+}
+
+// authorizeRun resolves the caller and checks that they own the project of
+// runID, writing the error response when they do not.
+func (h *TraceHandler) authorizeRun(w http.ResponseWriter, r *http.Request, runID string) bool {
+	userID, ok := resolveUser(w, r)
+	if !ok {
+		return false
+	}
+	if err := h.workerSvc.AuthorizeRun(runID, userID); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, gatewayworker.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return false
+	}
+	return true
+}
+
+// resolveUser resolves the caller of r and writes the error response when
+// there is none.
+func resolveUser(w http.ResponseWriter, r *http.Request) (entity.UserID, bool) {
+	userID, err := auth.ResolveUserID(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return "", false
+	}
+	if userID.IsZero() {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return "", false
+	}
+	return userID, true
 }
 
 func (h *TraceHandler) HandleFrontendTrace(w http.ResponseWriter, r *http.Request) {
@@ -38,6 +77,9 @@ func (h *TraceHandler) HandleFrontendTrace(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "run_id and stage are required", http.StatusBadRequest)
 		return
 	}
+	if !h.authorizeRun(w, r, runID) {
+		return
+	}
 	fields := map[string]any{}
 	for k, v := range in.Fields {
 		fields[k] = v
@@ -65,6 +107,9 @@ func (h *TraceHandler) HandleRunLogs(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "run_id is required", http.StatusBadRequest)
 		return
 	}
+	if !h.authorizeRun(w, r, runID) {
+		return
+	}
 	events, err := h.workerSvc.Telemetry().Read(runID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -73,22 +118,34 @@ func (h *TraceHandler) HandleRunLogs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]any{
 		"run_id": runID,
+		"labels": h.workerSvc.Telemetry().Labels(runID),
 		"events": events,
 	})
 }
 
+// HandleLatestRunLogs lists the caller's runs with the most recent telemetry.
+// Repeated ?label=key=value selectors keep only runs carrying every such label.
 func (h *TraceHandler) HandleLatestRunLogs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
+	userID, ok := resolveUser(w, r)
+	if !ok {
+		return
+	}
 	limit := 20
 	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
 		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
 			limit = n
 		}
 	}
-	runIDs := h.workerSvc.Telemetry().LatestRuns(limit)
+	sel, err := gatewayworker.ParseLabelSelector(r.URL.Query()["label"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	runIDs := h.workerSvc.LatestRuns(userID, limit, sel)
 	items := make([]map[string]any, 0, len(runIDs))
 	for _, runID := range runIDs {
 		events, err := h.workerSvc.Telemetry().Read(runID)
@@ -103,6 +160,7 @@ func (h *TraceHandler) HandleLatestRunLogs(w http.ResponseWriter, r *http.Reques
 		}
 		items = append(items, map[string]any{
 			"run_id":      runID,
+			"labels":      h.workerSvc.Telemetry().Labels(runID),
 			"event_count": len(events),
 			"last_ts":     lastTS,
 		})
@@ -112,3 +170,133 @@ func (h *TraceHandler) HandleLatestRunLogs(w http.ResponseWriter, r *http.Reques
 		"items": items,
 	})
 }
+
+// HandleListRuns serves GET /trace/runs, the tracked runs of the caller's
+// projects, newest first. Repeated ?label=key=value selectors keep only runs carrying every
+// such label.
+func (h *TraceHandler) HandleListRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	userID, ok := resolveUser(w, r)
+	if !ok {
+		return
+	}
+	sel, err := gatewayworker.ParseLabelSelector(r.URL.Query()["label"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"items": h.workerSvc.ListRuns(userID, sel),
+	})
+}
+
+// HandleRunUsage serves GET /debug/run-usage?run_id=..., the LLM calls,
+// tokens and cost of a run per model, to the owner of the run's project. Models without pricing are listed in
+// unpriced_models and count as free.
+func (h *TraceHandler) HandleRunUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	runID := strings.TrimSpace(r.URL.Query().Get("run_id"))
+	if runID == "" {
+		http.Error(w, "run_id is required", http.StatusBadRequest)
+		return
+	}
+	if !h.authorizeRun(w, r, runID) {
+		return
+	}
+	usage, ok := h.workerSvc.RunUsage(runID)
+	if !ok {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"run_id": runID,
+		"usage":  usage,
+	})
+}
+
+// HandleLLMLimiters reports the shared per-provider/model rate limiter state.
+func (h *TraceHandler) HandleLLMLimiters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"items": llmmiddleware.DefaultLimiterRegistry().Snapshot(),
+	})
+}
+
+// HandleLLMCircuits reports the shared per-provider/model circuit breaker state.
+func (h *TraceHandler) HandleLLMCircuits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"items": llmmiddleware.DefaultCircuitBreaker().Snapshot(),
+	})
+}
+
+// HandleLLMModels lists the registered models for diagnostics and the model
+// picker. ?level= (with optional ?role=) narrows it to the selection
+// candidates, the role default first.
+func (h *TraceHandler) HandleLLMModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if h.models == nil {
+		http.Error(w, "model registry is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	var models []llmmodel.RegisteredModel
+	if level := strings.TrimSpace(r.URL.Query().Get("level")); level != "" {
+		role := llmmodel.ModelRole(strings.TrimSpace(r.URL.Query().Get("role")))
+		models = h.models.ListByRoleLevel(role, llmmodel.ModelLevel(level))
+	} else {
+		models = h.models.List()
+	}
+	items := make([]map[string]any, 0, len(models))
+	for _, m := range models {
+		p := m.Profile
+		item := map[string]any{
+			"provider":   p.Provider,
+			"tier":       p.Tier,
+			"model":      p.Model,
+			"name":       p.Name,
+			"level":      p.Level,
+			"max_tokens": p.MaxTokens,
+			"meta":       p.Meta,
+		}
+		if rl := p.RateLimit; rl != nil {
+			item["rate_limit"] = map[string]any{
+				"rpm":   rl.RPM,
+				"rpd":   rl.RPD,
+				"tpm":   rl.TPM,
+				"tpd":   rl.TPD,
+				"rps":   rl.RPS,
+				"burst": rl.Burst,
+			}
+		}
+		if pr := p.Pricing; pr != nil {
+			item["pricing"] = map[string]any{
+				"input_per_mtok":  pr.InputPerMTok,
+				"output_per_mtok": pr.OutputPerMTok,
+			}
+		}
+		items = append(items, item)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"items": items,
+	})
+}
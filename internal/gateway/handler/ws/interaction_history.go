@@ -0,0 +1,85 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type interactionHistoryMessage struct {
+	Seq             int64  `json:"seq"`
+	Role            string `json:"role"`
+	Content         string `json:"content"`
+	InteractionID   string `json:"interactionId,omitempty"`
+	CreatedAtUnixMs int64  `json:"createdAtUnixMs"`
+}
+
+type interactionHistoryResponse struct {
+	RunID    string                      `json:"runId"`
+	NodeID   string                      `json:"nodeId"`
+	Messages []interactionHistoryMessage `json:"messages"`
+	More     bool                        `json:"more"`
+}
+
+// HandleHistory serves GET
+// /interaction/history?run_id=...&node_id=...[&after_seq=N][&limit=N].
+// It pages through the session transcript oldest first; pass the last seq
+// returned as after_seq while more is true.
+func (h *UserInteractionHandler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	runID := strings.TrimSpace(q.Get("run_id"))
+	nodeID := strings.TrimSpace(q.Get("node_id"))
+	if runID == "" || nodeID == "" {
+		http.Error(w, "run_id and node_id are required", http.StatusBadRequest)
+		return
+	}
+	if !h.authorize(w, r, runID) {
+		return
+	}
+	var afterSeq int64
+	if v := strings.TrimSpace(q.Get("after_seq")); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, "after_seq must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		afterSeq = n
+	}
+	limit := 0
+	if v := strings.TrimSpace(q.Get("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	page, err := h.svc.History(r.Context(), runID, nodeID, afterSeq, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res := interactionHistoryResponse{
+		RunID:    runID,
+		NodeID:   nodeID,
+		Messages: make([]interactionHistoryMessage, 0, len(page.Messages)),
+		More:     page.More,
+	}
+	for _, m := range page.Messages {
+		res.Messages = append(res.Messages, interactionHistoryMessage{
+			Seq:             m.Seq,
+			Role:            m.Role,
+			Content:         m.Content,
+			InteractionID:   m.InteractionID,
+			CreatedAtUnixMs: m.CreatedAt.UnixMilli(),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
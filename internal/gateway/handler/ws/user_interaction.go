@@ -2,14 +2,19 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	insightifyv1 "insightify/gen/go/insightify/v1"
 	logctx "insightify/internal/common/logctx"
 	traceutil "insightify/internal/common/trace"
+	"insightify/internal/gateway/auth"
+	"insightify/internal/gateway/entity"
 	userinteraction "insightify/internal/gateway/service/userinteraction"
+	gatewayworker "insightify/internal/gateway/service/worker"
 
 	"github.com/gorilla/websocket"
 )
@@ -18,10 +23,36 @@ import (
 // The legacy RPC handlers were removed; websocket handler is used.
 type UserInteractionHandler struct {
 	svc *userinteraction.Service
+	// authorizeRun fails unless the user owns the project of the run; see
+	// worker.Service.AuthorizeRun.
+	authorizeRun func(runID string, userID entity.UserID) error
 }
 
-func NewUserInteractionHandler(svc *userinteraction.Service) *UserInteractionHandler {
-	return &UserInteractionHandler{svc: svc}
+func NewUserInteractionHandler(svc *userinteraction.Service, authorizeRun func(runID string, userID entity.UserID) error) *UserInteractionHandler {
+	return &UserInteractionHandler{svc: svc, authorizeRun: authorizeRun}
+}
+
+// authorize resolves the caller and checks that they own the project of
+// runID, writing the error response when they do not.
+func (h *UserInteractionHandler) authorize(w http.ResponseWriter, r *http.Request, runID string) bool {
+	userID, err := auth.ResolveUserID(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	if userID.IsZero() {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return false
+	}
+	if err := h.authorizeRun(runID, userID); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, gatewayworker.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return false
+	}
+	return true
 }
 
 const (
@@ -45,10 +76,13 @@ type interactionWSInbound struct {
 	InteractionID string `json:"interactionId,omitempty"`
 	Input         string `json:"input,omitempty"`
 	Reason        string `json:"reason,omitempty"`
+	// Nonce deduplicates retried sends; see userinteraction.SendOnce.
+	Nonce string `json:"nonce,omitempty"`
 }
 
 type interactionWSOutbound struct {
 	Type             string `json:"type"`
+	Seq              int64  `json:"seq,omitempty"`
 	RunID            string `json:"runId,omitempty"`
 	NodeID           string `json:"nodeId,omitempty"`
 	TraceID          string `json:"traceId,omitempty"`
@@ -59,6 +93,7 @@ type interactionWSOutbound struct {
 	AssistantMessage string `json:"assistantMessage,omitempty"`
 	Code             string `json:"code,omitempty"`
 	Message          string `json:"message,omitempty"`
+	Dropped          int64  `json:"dropped,omitempty"`
 }
 
 func (h *UserInteractionHandler) HandleInteractionWS(w http.ResponseWriter, r *http.Request) {
@@ -68,6 +103,19 @@ func (h *UserInteractionHandler) HandleInteractionWS(w http.ResponseWriter, r *h
 		http.Error(w, "run_id and node_id are required", http.StatusBadRequest)
 		return
 	}
+	// Reconnecting clients pass the last seq they rendered to avoid duplicates.
+	var fromSeq int64
+	if v := strings.TrimSpace(r.URL.Query().Get("from_seq")); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, "from_seq must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		fromSeq = n
+	}
+	if !h.authorize(w, r, runID) {
+		return
+	}
 	traceID := traceutil.ExtractHTTP(r)
 	ctxWithTrace := traceutil.WithContext(r.Context(), traceID)
 	traceutil.InjectHTTPResponse(w, traceID)
@@ -120,7 +168,7 @@ func (h *UserInteractionHandler) HandleInteractionWS(w http.ResponseWriter, r *h
 		}
 	}()
 
-	subCh, subErr := h.svc.Subscribe(ctx, runID, nodeID)
+	subCh, subErr := h.svc.SubscribeFrom(ctx, runID, nodeID, fromSeq)
 	if subErr != nil {
 		pushInteractionWS(writeCh, interactionWSOutbound{
 			Type:    "error",
@@ -167,12 +215,37 @@ func (h *UserInteractionHandler) HandleInteractionWS(w http.ResponseWriter, r *h
 				case userinteraction.SubscriptionEventAssistantMessage:
 					pushInteractionWS(writeCh, interactionWSOutbound{
 						Type:             "assistant_message",
+						Seq:              evt.Seq,
 						RunID:            runID,
 						NodeID:           nodeID,
 						TraceID:          traceID,
 						InteractionID:    strings.TrimSpace(evt.InteractionID),
 						AssistantMessage: strings.TrimSpace(evt.AssistantMessage),
 					})
+				case userinteraction.SubscriptionEventDropped:
+					// Resubscribe with the last seen seq to recover dropped outputs.
+					pushInteractionWS(writeCh, interactionWSOutbound{
+						Type:    "events_dropped",
+						RunID:   runID,
+						NodeID:  nodeID,
+						TraceID: traceID,
+						Dropped: evt.Dropped,
+					})
+				case userinteraction.SubscriptionEventAssistantChunk:
+					// Chunks are sent verbatim; whitespace is significant when reassembling.
+					select {
+					case writeCh <- interactionWSOutbound{
+						Type:             "assistant_chunk",
+						Seq:              evt.Seq,
+						RunID:            runID,
+						NodeID:           nodeID,
+						TraceID:          traceID,
+						InteractionID:    strings.TrimSpace(evt.InteractionID),
+						AssistantMessage: evt.AssistantMessage,
+					}:
+					case <-ctx.Done():
+						return
+					}
 				}
 			}
 		}
@@ -226,12 +299,12 @@ func (h *UserInteractionHandler) HandleInteractionWS(w http.ResponseWriter, r *h
 		case "ping":
 			pushInteractionWS(writeCh, interactionWSOutbound{Type: "pong", TraceID: traceID})
 		case "send":
-			out, sendErr := h.svc.Send(ctx, &insightifyv1.SendRequest{
+			out, sendErr := h.svc.SendOnce(ctx, &insightifyv1.SendRequest{
 				RunId:         runID,
 				NodeId:        nodeID,
 				InteractionId: strings.TrimSpace(in.InteractionID),
 				Input:         strings.TrimSpace(in.Input),
-			})
+			}, in.Nonce)
 			if sendErr != nil {
 				logctx.Error(ctx, "interaction send failed", sendErr, "run_id", runID)
 				pushInteractionWS(writeCh, interactionWSOutbound{
@@ -0,0 +1,169 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Defaults applied to zero Config fields.
+const (
+	DefaultReadHeaderTimeout    = 10 * time.Second
+	DefaultReadTimeout          = time.Minute
+	DefaultWriteTimeout         = time.Minute
+	DefaultIdleTimeout          = 2 * time.Minute
+	DefaultMaxConcurrentStreams = 250
+)
+
+// Config tunes the HTTP server. ReadTimeout and WriteTimeout bound whole
+// requests, so long-lived routes must be wrapped in Streaming.
+type Config struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	// IdleTimeout closes idle keep-alive and HTTP/2 connections.
+	IdleTimeout time.Duration
+	// MaxConcurrentStreams caps open HTTP/2 streams per connection.
+	MaxConcurrentStreams uint32
+
+	// TLSCertFile and TLSKeyFile serve TLS from a certificate on disk.
+	TLSCertFile string
+	TLSKeyFile  string
+	// AutocertDomains serves TLS with Let's Encrypt certificates for these
+	// hosts, cached in AutocertCacheDir. Ignored when a cert file is set.
+	AutocertDomains  []string
+	AutocertCacheDir string
+}
+
+// TLS reports whether c serves TLS; otherwise the server speaks h2c.
+func (c Config) TLS() bool {
+	return c.certFiles() || len(c.AutocertDomains) > 0
+}
+
+func (c Config) certFiles() bool {
+	return strings.TrimSpace(c.TLSCertFile) != "" && strings.TrimSpace(c.TLSKeyFile) != ""
+}
+
+func (c Config) withDefaults() Config {
+	if c.ReadHeaderTimeout <= 0 {
+		c.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if c.ReadTimeout <= 0 {
+		c.ReadTimeout = DefaultReadTimeout
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = DefaultWriteTimeout
+	}
+	if c.IdleTimeout <= 0 {
+		c.IdleTimeout = DefaultIdleTimeout
+	}
+	if c.MaxConcurrentStreams == 0 {
+		c.MaxConcurrentStreams = DefaultMaxConcurrentStreams
+	}
+	if strings.TrimSpace(c.AutocertCacheDir) == "" {
+		c.AutocertCacheDir = "autocert"
+	}
+	return c
+}
+
+// Server is an HTTP/1.1 and HTTP/2 server: over TLS when configured, h2c
+// otherwise.
+type Server struct {
+	httpServer *http.Server
+	h2         *http2.Server
+	cfg        Config
+}
+
+// New builds a server for handler on addr.
+func New(addr string, handler http.Handler, cfg Config) (*Server, error) {
+	cfg = cfg.withDefaults()
+	h2 := &http2.Server{
+		MaxConcurrentStreams: cfg.MaxConcurrentStreams,
+		IdleTimeout:          cfg.IdleTimeout,
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	switch {
+	case cfg.certFiles():
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	case len(cfg.AutocertDomains) > 0:
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+		}
+		srv.TLSConfig = m.TLSConfig()
+	default:
+		// h2c hands upgraded connections the http.Server as BaseConfig, so
+		// the timeouts above apply per HTTP/2 stream as well.
+		srv.Handler = h2c.NewHandler(handler, h2)
+		return &Server{httpServer: srv, h2: h2, cfg: cfg}, nil
+	}
+	if err := http2.ConfigureServer(srv, h2); err != nil {
+		return nil, err
+	}
+	return &Server{httpServer: srv, h2: h2, cfg: cfg}, nil
+}
+
+// Start listens on the configured address and serves until Shutdown.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve serves on ln until Shutdown.
+func (s *Server) Serve(ln net.Listener) error {
+	scheme := "h2c"
+	if s.cfg.TLS() {
+		scheme = "tls"
+	}
+	slog.Info("starting API server", "addr", ln.Addr().String(), "scheme", scheme)
+	var err error
+	if s.cfg.TLS() {
+		// Certificate paths are empty under autocert, which supplies
+		// GetCertificate through TLSConfig.
+		err = s.httpServer.ServeTLS(ln, s.cfg.TLSCertFile, s.cfg.TLSKeyFile)
+	} else {
+		err = s.httpServer.Serve(ln)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for active requests until
+// ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// Streaming lifts the server read and write deadlines for requests to h, for
+// routes that stay open longer than the timeouts (WebSocket, streamed
+// downloads). Idle and header timeouts still apply.
+func Streaming(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+		h.ServeHTTP(w, r)
+	})
+}
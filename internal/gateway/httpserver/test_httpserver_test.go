@@ -0,0 +1,215 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+func startServer(t *testing.T, handler http.Handler, cfg Config) (*Server, string) {
+	t.Helper()
+	srv, err := New("", handler, cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+		if err := <-done; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+	return srv, ln.Addr().String()
+}
+
+// h2cClient speaks HTTP/2 with prior knowledge over plain TCP.
+func h2cClient() *http.Client {
+	return &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func TestNewAppliesTimeouts(t *testing.T) {
+	srv, err := New(":0", http.NotFoundHandler(), Config{
+		ReadHeaderTimeout:    time.Second,
+		ReadTimeout:          2 * time.Second,
+		WriteTimeout:         3 * time.Second,
+		IdleTimeout:          4 * time.Second,
+		MaxConcurrentStreams: 7,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	hs := srv.httpServer
+	if hs.ReadHeaderTimeout != time.Second || hs.ReadTimeout != 2*time.Second || hs.WriteTimeout != 3*time.Second || hs.IdleTimeout != 4*time.Second {
+		t.Fatalf("http.Server timeouts = %v %v %v %v", hs.ReadHeaderTimeout, hs.ReadTimeout, hs.WriteTimeout, hs.IdleTimeout)
+	}
+	if srv.h2.MaxConcurrentStreams != 7 || srv.h2.IdleTimeout != 4*time.Second {
+		t.Fatalf("http2.Server = %+v", srv.h2)
+	}
+
+	srv, err = New(":0", http.NotFoundHandler(), Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	hs = srv.httpServer
+	if hs.ReadHeaderTimeout != DefaultReadHeaderTimeout || hs.ReadTimeout != DefaultReadTimeout || hs.WriteTimeout != DefaultWriteTimeout || hs.IdleTimeout != DefaultIdleTimeout || srv.h2.MaxConcurrentStreams != DefaultMaxConcurrentStreams {
+		t.Fatalf("defaults not applied: %+v", srv.cfg)
+	}
+	if hs.TLSConfig != nil {
+		t.Fatalf("TLS configured without cert or autocert")
+	}
+}
+
+func TestReadHeaderTimeoutClosesSlowClient(t *testing.T) {
+	_, addr := startServer(t, http.NotFoundHandler(), Config{ReadHeaderTimeout: 50 * time.Millisecond})
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: x\r\n"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	start := time.Now()
+	_, err = io.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("connection was not closed by the server: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("slow header client held the connection for %v", elapsed)
+	}
+}
+
+// chunks writes n flushed lines, pausing between them.
+func chunks(n int, pause time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		for i := range n {
+			if _, err := fmt.Fprintf(w, "chunk %d\n", i); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			time.Sleep(pause)
+		}
+	}
+}
+
+func TestStreamingRouteOutlivesWriteTimeout(t *testing.T) {
+	mux := http.NewServeMux()
+	// The interaction stream stays open far longer than the write timeout.
+	mux.Handle("/stream", Streaming(chunks(6, 60*time.Millisecond)))
+	mux.Handle("/plain", chunks(6, 60*time.Millisecond))
+	_, addr := startServer(t, mux, Config{WriteTimeout: 100 * time.Millisecond})
+
+	for name, client := range map[string]*http.Client{"http1": {}, "h2c": h2cClient()} {
+		resp, err := client.Get("http://" + addr + "/stream")
+		if err != nil {
+			t.Fatalf("%s: GET /stream error = %v", name, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || string(body) != "chunk 0\nchunk 1\nchunk 2\nchunk 3\nchunk 4\nchunk 5\n" {
+			t.Fatalf("%s: streaming body = %q, %v", name, body, err)
+		}
+		if name == "h2c" && resp.ProtoMajor != 2 {
+			t.Fatalf("h2c request used %s", resp.Proto)
+		}
+
+		resp, err = client.Get("http://" + addr + "/plain")
+		if err == nil {
+			body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		if err == nil && len(body) == len("chunk 0\n")*6 {
+			t.Fatalf("%s: plain route outlived the write timeout", name)
+		}
+	}
+}
+
+func TestTLSServesHTTP2(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	srv, addr := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}), Config{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	if !srv.cfg.TLS() {
+		t.Fatalf("cert files did not enable TLS")
+	}
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + addr + "/")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.ProtoMajor != 2 || string(body) != "HTTP/2.0" {
+		t.Fatalf("TLS response proto = %s, body %q", resp.Proto, body)
+	}
+}
+
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certFile, keyFile
+}
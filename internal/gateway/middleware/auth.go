@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"insightify/internal/gateway/auth"
+)
+
+// HealthPath and ReadyPath are always served without authentication.
+const (
+	HealthPath = "/healthz"
+	ReadyPath  = "/readyz"
+)
+
+// Authenticator verifies request credentials and injects the caller's user ID.
+// Paths on the dev allowlist may be called anonymously; handlers then fall
+// back to the request-supplied user_id.
+type Authenticator struct {
+	verifier auth.TokenVerifier
+	devAllow []string
+}
+
+// NewAuthenticator builds an Authenticator. devAllowlist entries are exact
+// paths/procedures, prefixes ending in "/", or "*" for everything; pass nil
+// outside local development.
+func NewAuthenticator(verifier auth.TokenVerifier, devAllowlist []string) *Authenticator {
+	allow := make([]string, 0, len(devAllowlist))
+	for _, p := range devAllowlist {
+		if p = strings.TrimSpace(p); p != "" {
+			allow = append(allow, p)
+		}
+	}
+	return &Authenticator{verifier: verifier, devAllow: allow}
+}
+
+// authenticate returns ctx carrying the verified user ID. With no token, it
+// returns ctx unchanged for public and dev-allowlisted paths.
+func (a *Authenticator) authenticate(ctx context.Context, token string, hasToken bool, path string) (context.Context, error) {
+	if !hasToken {
+		if path == HealthPath || path == ReadyPath || a.allowed(path) {
+			return ctx, nil
+		}
+		return ctx, auth.ErrUnauthenticated
+	}
+	if a.verifier == nil {
+		return ctx, auth.ErrInvalidToken
+	}
+	id, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return ctx, err
+	}
+	if id.IsZero() {
+		return ctx, auth.ErrInvalidToken
+	}
+	return auth.WithUserID(ctx, id), nil
+}
+
+func (a *Authenticator) allowed(path string) bool {
+	for _, p := range a.devAllow {
+		if p == "*" || p == path || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Interceptor authenticates connect RPCs, failing with CodeUnauthenticated.
+func (a *Authenticator) Interceptor() connect.Interceptor {
+	return &authInterceptor{a: a}
+}
+
+type authInterceptor struct {
+	a *Authenticator
+}
+
+func (i *authInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		token, ok := auth.TokenFromHeader(req.Header())
+		ctx, err := i.a.authenticate(ctx, token, ok, req.Spec().Procedure)
+		if err != nil {
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *authInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *authInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		token, ok := auth.TokenFromHeader(conn.RequestHeader())
+		ctx, err := i.a.authenticate(ctx, token, ok, conn.Spec().Procedure)
+		if err != nil {
+			return connect.NewError(connect.CodeUnauthenticated, err)
+		}
+		return next(ctx, conn)
+	}
+}
+
+// HTTP protects plain HTTP endpoints (trace/debug, archives, websocket),
+// answering 401. Browsers cannot set headers on websocket upgrades, so those
+// may pass the token as ?access_token= instead.
+func (a *Authenticator) HTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := auth.TokenFromHeader(r.Header)
+		if !ok && strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			token = strings.TrimSpace(r.URL.Query().Get("access_token"))
+			ok = token != ""
+		}
+		ctx, err := a.authenticate(r.Context(), token, ok, r.URL.Path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
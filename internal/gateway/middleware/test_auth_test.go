@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	"insightify/internal/gateway/auth"
+)
+
+// fakeRequest satisfies connect.AnyRequest for interceptor tests.
+type fakeRequest struct {
+	connect.AnyRequest
+	spec   connect.Spec
+	header http.Header
+}
+
+func (r fakeRequest) Spec() connect.Spec  { return r.spec }
+func (r fakeRequest) Header() http.Header { return r.header }
+
+func runUnary(t *testing.T, a *Authenticator, procedure, authorization string) (string, error) {
+	t.Helper()
+	h := http.Header{}
+	if authorization != "" {
+		h.Set("Authorization", authorization)
+	}
+	var seen string
+	next := func(ctx context.Context, _ connect.AnyRequest) (connect.AnyResponse, error) {
+		if id, ok := auth.UserIDFrom(ctx); ok {
+			seen = id.String()
+		}
+		return nil, nil
+	}
+	_, err := a.Interceptor().WrapUnary(next)(context.Background(), fakeRequest{
+		spec:   connect.Spec{Procedure: procedure},
+		header: h,
+	})
+	return seen, err
+}
+
+func TestAuthInterceptorInjectsUserID(t *testing.T) {
+	a := NewAuthenticator(auth.NewStaticTokenVerifier(map[string]string{"tok-a": "alice"}), nil)
+
+	for _, authorization := range []string{"Bearer tok-a", "ApiKey tok-a"} {
+		got, err := runUnary(t, a, "/insightify.v1.ProjectService/ListProjects", authorization)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", authorization, err)
+		}
+		if got != "alice" {
+			t.Fatalf("%s: user = %q, want alice", authorization, got)
+		}
+	}
+}
+
+func TestAuthInterceptorRejects(t *testing.T) {
+	a := NewAuthenticator(auth.NewStaticTokenVerifier(map[string]string{"tok-a": "alice"}), nil)
+
+	for name, authorization := range map[string]string{
+		"missing":      "",
+		"wrong token":  "Bearer nope",
+		"basic scheme": "Basic tok-a",
+	} {
+		_, err := runUnary(t, a, "/insightify.v1.ProjectService/ListProjects", authorization)
+		if connect.CodeOf(err) != connect.CodeUnauthenticated {
+			t.Fatalf("%s: code = %v, want unauthenticated (err=%v)", name, connect.CodeOf(err), err)
+		}
+	}
+}
+
+func TestAuthInterceptorDevAllowlist(t *testing.T) {
+	a := NewAuthenticator(auth.NewStaticTokenVerifier(nil), []string{"/insightify.v1.RunService/", " "})
+
+	if _, err := runUnary(t, a, "/insightify.v1.RunService/StartRun", ""); err != nil {
+		t.Fatalf("allowlisted procedure should pass without a token: %v", err)
+	}
+	if _, err := runUnary(t, a, "/insightify.v1.ProjectService/ListProjects", ""); connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("procedure outside the allowlist should be rejected, got %v", err)
+	}
+	// A presented token is always verified, even on allowlisted paths.
+	if _, err := runUnary(t, a, "/insightify.v1.RunService/StartRun", "Bearer bad"); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Fatalf("expected invalid token, got %v", err)
+	}
+}
+
+func TestAuthHTTP(t *testing.T) {
+	a := NewAuthenticator(auth.NewStaticTokenVerifier(map[string]string{"tok-a": "alice"}), nil)
+	h := a.HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := auth.UserIDFrom(r.Context())
+		_, _ = w.Write([]byte(id.String()))
+	}))
+
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	if rec := serve(httptest.NewRequest(http.MethodGet, "/trace/run-logs", nil)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("missing token: status = %d, want 401", rec.Code)
+	}
+	for _, path := range []string{HealthPath, ReadyPath} {
+		if rec := serve(httptest.NewRequest(http.MethodGet, path, nil)); rec.Code != http.StatusOK {
+			t.Fatalf("%s should be public: status = %d", path, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/trace/run-logs", nil)
+	req.Header.Set("Authorization", "Bearer tok-a")
+	if rec := serve(req); rec.Code != http.StatusOK || rec.Body.String() != "alice" {
+		t.Fatalf("bearer: status = %d body = %q", rec.Code, rec.Body.String())
+	}
+
+	// The query token is only accepted on websocket upgrades.
+	if rec := serve(httptest.NewRequest(http.MethodGet, "/trace/run-logs?access_token=tok-a", nil)); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("query token on plain request: status = %d, want 401", rec.Code)
+	}
+	ws := httptest.NewRequest(http.MethodGet, "/ws/interaction?access_token=tok-a", nil)
+	ws.Header.Set("Upgrade", "websocket")
+	if rec := serve(ws); rec.Code != http.StatusOK || rec.Body.String() != "alice" {
+		t.Fatalf("websocket query token: status = %d body = %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveCORS(allowed []string, method, origin string) (*httptest.ResponseRecorder, bool) {
+	reached := false
+	h := CORS(allowed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	req := httptest.NewRequest(method, "/trace/run-logs", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec, reached
+}
+
+func TestCORSAllowedOrigin(t *testing.T) {
+	allowed := []string{"https://app.example.com", " https://admin.example.com/ "}
+	for _, origin := range []string{"https://app.example.com", "https://admin.example.com"} {
+		rec, reached := serveCORS(allowed, http.MethodGet, origin)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != origin {
+			t.Fatalf("%s: Allow-Origin = %q", origin, got)
+		}
+		if rec.Header().Get("Access-Control-Allow-Credentials") != "true" || !reached {
+			t.Fatalf("%s: credentials=%q reached=%v", origin, rec.Header().Get("Access-Control-Allow-Credentials"), reached)
+		}
+	}
+
+	rec, reached := serveCORS(allowed, http.MethodOptions, "https://app.example.com")
+	if reached || rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Fatalf("preflight: code=%d reached=%v headers=%v", rec.Code, reached, rec.Header())
+	}
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	allowed := []string{"https://app.example.com"}
+	for _, method := range []string{http.MethodGet, http.MethodOptions} {
+		rec, _ := serveCORS(allowed, method, "https://evil.example.com")
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Fatalf("%s: Allow-Origin = %q, want none", method, got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Fatalf("%s: Allow-Credentials = %q, want none", method, got)
+		}
+		if rec.Header().Get("Vary") != "Origin" {
+			t.Fatalf("%s: Vary = %q", method, rec.Header().Get("Vary"))
+		}
+	}
+
+	if rec, reached := serveCORS(allowed, http.MethodGet, ""); rec.Header().Get("Access-Control-Allow-Origin") != "" || !reached {
+		t.Fatalf("same-origin request: headers=%v reached=%v", rec.Header(), reached)
+	}
+}
+
+func TestCORSEmptyAllowlistReflectsAnyOrigin(t *testing.T) {
+	rec, _ := serveCORS(nil, http.MethodGet, "http://localhost:5173")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
+		t.Fatalf("Allow-Origin = %q", got)
+	}
+	if rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("credentials should be allowed in local development")
+	}
+
+	rec, _ = serveCORS(nil, http.MethodGet, "")
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("no origin: Allow-Origin = %q, want *", got)
+	}
+}
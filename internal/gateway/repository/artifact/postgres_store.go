@@ -79,6 +79,30 @@ func (s *PostgresStore) Get(ctx context.Context, runID, path string) ([]byte, er
 	return append([]byte(nil), item.Content...), nil
 }
 
+func (s *PostgresStore) Delete(ctx context.Context, runID, path string) error {
+	if s == nil {
+		return fmt.Errorf("store is nil")
+	}
+	if s.client == nil {
+		return fmt.Errorf("ent client is nil")
+	}
+	runID = strings.TrimSpace(runID)
+	path = strings.TrimSpace(path)
+	if runID == "" {
+		return fmt.Errorf("run_id is required")
+	}
+	if path == "" {
+		return fmt.Errorf("path is required")
+	}
+	_, err := s.client.ArtifactFile.Delete().
+		Where(
+			artifactfile.RunID(runID),
+			artifactfile.Path(path),
+		).
+		Exec(ctx)
+	return err
+}
+
 func (s *PostgresStore) List(ctx context.Context, runID string) ([]string, error) {
 	if s == nil {
 		return nil, fmt.Errorf("store is nil")
@@ -13,4 +13,10 @@ type Store interface {
 	List(ctx context.Context, runID string) ([]string, error)
 }
 
+// Deleter is implemented by stores that can remove a single artifact, which
+// callers use to roll back artifacts they stored before a later step failed.
+type Deleter interface {
+	Delete(ctx context.Context, runID, path string) error
+}
+
 var ErrNotFound = errors.New("artifact not found")
@@ -145,6 +145,24 @@ func (s *S3Store) Get(ctx context.Context, runID, path string) ([]byte, error) {
 	return data, nil
 }
 
+func (s *S3Store) Delete(ctx context.Context, runID, path string) error {
+	if s == nil {
+		return fmt.Errorf("store is nil")
+	}
+	runID = strings.TrimSpace(runID)
+	path = strings.TrimSpace(path)
+	if runID == "" {
+		return fmt.Errorf("run_id is required")
+	}
+	if path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if err := s.ensureBucket(ctx); err != nil {
+		return fmt.Errorf("ensure bucket: %w", err)
+	}
+	return s.client.RemoveObject(ctx, s.bucketName, objectKey(runID, path), minio.RemoveObjectOptions{})
+}
+
 func (s *S3Store) List(ctx context.Context, runID string) ([]string, error) {
 	if s == nil {
 		return nil, fmt.Errorf("store is nil")
@@ -0,0 +1,158 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"insightify/internal/gateway/ent"
+	"insightify/internal/gateway/ent/conversation"
+	"insightify/internal/gateway/ent/conversationmessage"
+)
+
+type PostgresStore struct {
+	client *ent.Client
+}
+
+func NewPostgresStore(client *ent.Client) *PostgresStore {
+	return &PostgresStore{client: client}
+}
+
+func (s *PostgresStore) Append(ctx context.Context, runID, nodeID string, msgs ...Message) error {
+	if s == nil || s.client == nil {
+		return fmt.Errorf("store is nil")
+	}
+	runID, nodeID = normalizeID(runID), normalizeID(nodeID)
+	if runID == "" || nodeID == "" {
+		return fmt.Errorf("run_id and node_id are required")
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	id := ConversationID(runID, nodeID)
+	err := s.client.Conversation.Create().
+		SetID(id).
+		SetRunID(runID).
+		SetNodeID(nodeID).
+		OnConflictColumns(conversation.FieldID).
+		Ignore().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	builders := make([]*ent.ConversationMessageCreate, 0, len(msgs))
+	for _, m := range msgs {
+		b := s.client.ConversationMessage.Create().
+			SetConversationID(id).
+			SetSeq(m.Seq).
+			SetRole(m.Role).
+			SetContent(m.Content).
+			SetInteractionID(m.InteractionID)
+		if !m.CreatedAt.IsZero() {
+			b.SetCreatedAt(m.CreatedAt)
+		}
+		builders = append(builders, b)
+	}
+	return s.client.ConversationMessage.CreateBulk(builders...).
+		OnConflictColumns(conversationmessage.FieldConversationID, conversationmessage.FieldSeq).
+		UpdateNewValues().
+		Exec(ctx)
+}
+
+func (s *PostgresStore) List(ctx context.Context, runID, nodeID string, afterSeq int64, limit int) (Page, error) {
+	if s == nil || s.client == nil {
+		return Page{}, fmt.Errorf("store is nil")
+	}
+	runID, nodeID = normalizeID(runID), normalizeID(nodeID)
+	if runID == "" || nodeID == "" {
+		return Page{}, fmt.Errorf("run_id and node_id are required")
+	}
+	q := s.client.ConversationMessage.Query().
+		Where(
+			conversationmessage.ConversationID(ConversationID(runID, nodeID)),
+			conversationmessage.SeqGT(afterSeq),
+		).
+		Order(ent.Asc(conversationmessage.FieldSeq))
+	if limit > 0 {
+		q = q.Limit(limit + 1)
+	}
+	rows, err := q.All(ctx)
+	if err != nil {
+		return Page{}, err
+	}
+	page := Page{Messages: make([]Message, 0, len(rows))}
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+		page.More = true
+	}
+	for _, r := range rows {
+		page.Messages = append(page.Messages, Message{
+			Seq:           r.Seq,
+			Role:          r.Role,
+			Content:       r.Content,
+			InteractionID: r.InteractionID,
+			CreatedAt:     r.CreatedAt,
+		})
+	}
+	return page, nil
+}
+
+func (s *PostgresStore) Prune(ctx context.Context, r Retention, now time.Time) (int, error) {
+	if s == nil || s.client == nil {
+		return 0, fmt.Errorf("store is nil")
+	}
+	removed := 0
+	if r.MaxAge > 0 {
+		cutoff := now.Add(-r.MaxAge)
+		n, err := s.client.ConversationMessage.Delete().
+			Where(conversationmessage.CreatedAtLT(cutoff)).
+			Exec(ctx)
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+		if _, err := s.client.Conversation.Delete().
+			Where(conversation.CreatedAtLT(cutoff), conversation.Not(conversation.HasMessages())).
+			Exec(ctx); err != nil {
+			return removed, err
+		}
+	}
+	if r.MaxMessages > 0 {
+		var counts []struct {
+			ConversationID string `json:"conversation_id"`
+			Count          int    `json:"count"`
+		}
+		err := s.client.ConversationMessage.Query().
+			GroupBy(conversationmessage.FieldConversationID).
+			Aggregate(ent.Count()).
+			Scan(ctx, &counts)
+		if err != nil {
+			return removed, err
+		}
+		for _, c := range counts {
+			if c.Count <= r.MaxMessages {
+				continue
+			}
+			// Seqs only grow, so the newest MaxMessages have the highest seqs.
+			keepFrom, err := s.client.ConversationMessage.Query().
+				Where(conversationmessage.ConversationID(c.ConversationID)).
+				Order(ent.Desc(conversationmessage.FieldSeq)).
+				Offset(r.MaxMessages - 1).
+				First(ctx)
+			if err != nil {
+				return removed, err
+			}
+			n, err := s.client.ConversationMessage.Delete().
+				Where(
+					conversationmessage.ConversationID(c.ConversationID),
+					conversationmessage.SeqLT(keepFrom.Seq),
+				).
+				Exec(ctx)
+			if err != nil {
+				return removed, err
+			}
+			removed += n
+		}
+	}
+	return removed, nil
+}
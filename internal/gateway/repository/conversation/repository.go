@@ -0,0 +1,51 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Message is one stored transcript entry. Seq is unique per conversation and
+// increases with every new message; a streamed message keeps its seq while
+// its content grows.
+type Message struct {
+	Seq           int64
+	Role          string
+	Content       string
+	InteractionID string
+	CreatedAt     time.Time
+}
+
+// Page is one window of a transcript. More reports that messages after the
+// last one returned exist.
+type Page struct {
+	Messages []Message
+	More     bool
+}
+
+// Retention bounds stored transcripts. Zero fields disable that bound.
+type Retention struct {
+	// MaxMessages keeps only the newest messages of each conversation.
+	MaxMessages int
+	// MaxAge drops messages older than this; empty conversations go with them.
+	MaxAge time.Duration
+}
+
+// Store defines conversation transcript persistence operations.
+type Store interface {
+	// Append upserts msgs by seq, creating the conversation on first use.
+	Append(ctx context.Context, runID, nodeID string, msgs ...Message) error
+	// List returns up to limit messages with seq > afterSeq in seq order.
+	// limit <= 0 returns all of them.
+	List(ctx context.Context, runID, nodeID string, afterSeq int64, limit int) (Page, error)
+	// Prune applies r as of now and returns the number of removed messages.
+	Prune(ctx context.Context, r Retention, now time.Time) (int, error)
+}
+
+// ConversationID is the stored id of the (run_id, node_id) transcript.
+func ConversationID(runID, nodeID string) string {
+	return normalizeID(runID) + "/" + normalizeID(nodeID)
+}
+
+func normalizeID(v string) string { return strings.TrimSpace(v) }
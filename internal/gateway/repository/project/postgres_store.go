@@ -45,7 +45,13 @@ func (s *PostgresStore) Put(ctx context.Context, state State) error {
 		SetName(state.ProjectName).
 		SetUserID(state.UserID.String()).
 		SetRepo(state.Repo).
+		SetRepos(state.Repos).
 		SetIsActive(state.IsActive).
+		SetCostBudgetUsd(state.CostBudgetUSD).
+		SetInputWaitTimeoutMs(state.InputWaitTimeoutMs).
+		SetLocale(state.Locale).
+		SetMinCodeRatio(state.MinCodeRatio).
+		SetMinCodeBytes(state.MinCodeBytes).
 		OnConflictColumns(entproject.FieldID).
 		UpdateNewValues().
 		Exec(ctx)
@@ -72,7 +78,13 @@ func (s *PostgresStore) Update(ctx context.Context, projectID string, update fun
 		SetName(state.ProjectName).
 		SetUserID(state.UserID.String()).
 		SetRepo(state.Repo).
+		SetRepos(state.Repos).
 		SetIsActive(state.IsActive).
+		SetCostBudgetUsd(state.CostBudgetUSD).
+		SetInputWaitTimeoutMs(state.InputWaitTimeoutMs).
+		SetLocale(state.Locale).
+		SetMinCodeRatio(state.MinCodeRatio).
+		SetMinCodeBytes(state.MinCodeBytes).
 		Save(ctx)
 	if err != nil {
 		return State{}, false, err
@@ -171,10 +183,16 @@ func (s *PostgresStore) ListArtifacts(ctx context.Context, projectID string) ([]
 
 func toState(p *ent.Project) State {
 	return State{
-		ProjectID:   p.ID,
-		ProjectName: p.Name,
-		UserID:      entity.NormalizeUserID(p.UserID),
-		Repo:        p.Repo,
-		IsActive:    p.IsActive,
+		ProjectID:          p.ID,
+		ProjectName:        p.Name,
+		UserID:             entity.NormalizeUserID(p.UserID),
+		Repo:               p.Repo,
+		Repos:              p.Repos,
+		IsActive:           p.IsActive,
+		CostBudgetUSD:      p.CostBudgetUsd,
+		InputWaitTimeoutMs: p.InputWaitTimeoutMs,
+		Locale:             p.Locale,
+		MinCodeRatio:       p.MinCodeRatio,
+		MinCodeBytes:       p.MinCodeBytes,
 	}
 }
@@ -29,6 +29,20 @@ type State struct {
 	UserID      entity.UserID `json:"user_id"`
 	Repo        string        `json:"repo"`
 	IsActive    bool          `json:"is_active"`
+	// Repos lists the repositories of a multi-repo project; Repos[0] is the default.
+	Repos []entity.RepoEntry `json:"repos,omitempty"`
+	// CostBudgetUSD is the LLM cost budget of runs that set none; 0 means none.
+	CostBudgetUSD float64 `json:"cost_budget_usd,omitempty"`
+	// InputWaitTimeoutMs bounds the input waits of the project's runs; 0
+	// uses the server default.
+	InputWaitTimeoutMs int64 `json:"input_wait_timeout_ms,omitempty"`
+	// Locale is the language of assistant messages of the project's runs;
+	// "" uses the request's Accept-Language.
+	Locale string `json:"locale,omitempty"`
+	// MinCodeRatio and MinCodeBytes are the repo_assessment thresholds of
+	// the project's runs; 0 uses the defaults.
+	MinCodeRatio float64 `json:"min_code_ratio,omitempty"`
+	MinCodeBytes int64   `json:"min_code_bytes,omitempty"`
 }
 
 type ProjectArtifact struct {
@@ -1,12 +1,15 @@
 package server
 
 import (
+	"expvar"
 	"net/http"
 
+	"connectrpc.com/connect"
 	"insightify/gen/go/insightify/v1/insightifyv1connect"
 	"insightify/internal/gateway/handler"
 	"insightify/internal/gateway/handler/rpc"
 	"insightify/internal/gateway/handler/ws"
+	"insightify/internal/gateway/httpserver"
 	"insightify/internal/gateway/middleware"
 )
 
@@ -14,24 +17,62 @@ func NewMux(
 	projectHandler *rpc.ProjectHandler,
 	runHandler *rpc.RunHandler,
 	userInteractionHandler *ws.UserInteractionHandler,
+	interactionSubmitHandler *handler.InteractionSubmitHandler,
 	uiHandler *rpc.UiHandler,
 	uiWorkspaceHandler *rpc.UiWorkspaceHandler,
 	traceHandler *handler.TraceHandler,
+	projectArchiveHandler *handler.ProjectArchiveHandler,
+	projectReposHandler *handler.ProjectReposHandler,
+	projectSettingsHandler *handler.ProjectSettingsHandler,
+	projectCompareHandler *handler.ProjectCompareHandler,
+	projectSearchHandler *handler.ProjectSearchHandler,
+	repoFileHandler *handler.RepoFileHandler,
+	debugHandler *handler.DebugHandler,
+	healthHandler *handler.HealthHandler,
+	authn *middleware.Authenticator,
+	corsOrigins []string,
 ) http.Handler {
 	mux := http.NewServeMux()
+	withAuth := connect.WithInterceptors(authn.Interceptor())
+
+	// Health
+	mux.HandleFunc(middleware.HealthPath, healthHandler.HandleHealth)
+	mux.HandleFunc(middleware.ReadyPath, healthHandler.HandleReady)
 
 	// RPC Handlers
-	mux.Handle(insightifyv1connect.NewProjectServiceHandler(projectHandler))
-	mux.Handle(insightifyv1connect.NewRunServiceHandler(runHandler))
-	mux.Handle(insightifyv1connect.NewUiServiceHandler(uiHandler))
-	mux.Handle(insightifyv1connect.NewUiWorkspaceServiceHandler(uiWorkspaceHandler))
+	mux.Handle(insightifyv1connect.NewProjectServiceHandler(projectHandler, withAuth))
+	mux.Handle(insightifyv1connect.NewRunServiceHandler(runHandler, withAuth))
+	mux.Handle(insightifyv1connect.NewUiServiceHandler(uiHandler, withAuth))
+	mux.Handle(insightifyv1connect.NewUiWorkspaceServiceHandler(uiWorkspaceHandler, withAuth))
 
 	// Trace Handlers
-	mux.HandleFunc("/ws/interaction", userInteractionHandler.HandleInteractionWS)
-	mux.HandleFunc("/trace/frontend", traceHandler.HandleFrontendTrace)
-	mux.HandleFunc("/trace/run-logs", traceHandler.HandleRunLogs)
-	mux.HandleFunc("/trace/run-logs/latest", traceHandler.HandleLatestRunLogs)
+	mux.Handle("/ws/interaction", httpserver.Streaming(authn.HTTP(http.HandlerFunc(userInteractionHandler.HandleInteractionWS))))
+	mux.Handle("/interaction/history", authn.HTTP(http.HandlerFunc(userInteractionHandler.HandleHistory)))
+	mux.Handle("/interaction/submit", authn.HTTP(http.HandlerFunc(interactionSubmitHandler.HandleSubmit)))
+	mux.Handle("/trace/frontend", authn.HTTP(http.HandlerFunc(traceHandler.HandleFrontendTrace)))
+	mux.Handle("/trace/run-logs", authn.HTTP(http.HandlerFunc(traceHandler.HandleRunLogs)))
+	mux.Handle("/trace/run-logs/latest", authn.HTTP(http.HandlerFunc(traceHandler.HandleLatestRunLogs)))
+	mux.Handle("/trace/runs", authn.HTTP(http.HandlerFunc(traceHandler.HandleListRuns)))
+	mux.Handle("/trace/llm-limiters", authn.HTTP(http.HandlerFunc(traceHandler.HandleLLMLimiters)))
+	mux.Handle("/trace/llm-circuits", authn.HTTP(http.HandlerFunc(traceHandler.HandleLLMCircuits)))
+	mux.Handle("/trace/llm-models", authn.HTTP(http.HandlerFunc(traceHandler.HandleLLMModels)))
+
+	// Project Archive Handlers
+	mux.Handle("/project/export", httpserver.Streaming(authn.HTTP(http.HandlerFunc(projectArchiveHandler.HandleExport))))
+	mux.Handle("/project/import", httpserver.Streaming(authn.HTTP(http.HandlerFunc(projectArchiveHandler.HandleImport))))
+	mux.Handle("/project/repos", authn.HTTP(http.HandlerFunc(projectReposHandler.HandleRepos)))
+	mux.Handle("/project/settings", authn.HTTP(http.HandlerFunc(projectSettingsHandler.HandleSettings)))
+	mux.Handle("/project/compare-runs", authn.HTTP(http.HandlerFunc(projectCompareHandler.HandleCompareRuns)))
+	mux.Handle("/project/artifacts", authn.HTTP(http.HandlerFunc(projectCompareHandler.HandleListArtifacts)))
+	mux.Handle("/project/search", authn.HTTP(http.HandlerFunc(projectSearchHandler.HandleSearch)))
+	mux.Handle("/project/repo-file", authn.HTTP(http.HandlerFunc(repoFileHandler.HandleRepoFile)))
+
+	// Debug Handlers
+	mux.Handle("/debug/prompt", authn.HTTP(http.HandlerFunc(debugHandler.HandlePrompt)))
+	mux.Handle("/debug/llm-chain", authn.HTTP(http.HandlerFunc(debugHandler.HandleLLMChain)))
+	mux.Handle("/debug/run-usage", authn.HTTP(http.HandlerFunc(traceHandler.HandleRunUsage)))
+	mux.Handle("/debug/vars", authn.HTTP(expvar.Handler()))
 
 	// Middleware
-	return middleware.CORS(middleware.Trace(mux))
+	return middleware.CORS(corsOrigins)(middleware.Trace(mux))
 }
@@ -2,6 +2,7 @@ package project
 
 import (
 	"context"
+	"time"
 
 	gatewayworker "insightify/internal/gateway/service/worker"
 	runtimepkg "insightify/internal/workerruntime"
@@ -21,9 +22,20 @@ func (a *projectReaderAdapter) GetEntry(projectID string) (gatewayworker.Project
 	if !ok {
 		return gatewayworker.ProjectView{}, false
 	}
+	repos := make([]string, 0, len(e.State.Repos))
+	for _, r := range e.State.Repos {
+		repos = append(repos, r.Name)
+	}
 	return gatewayworker.ProjectView{
-		ProjectID: e.State.ProjectID,
-		RunCtx:    e.RunCtx,
+		ProjectID:        e.State.ProjectID,
+		UserID:           e.State.UserID,
+		Repos:            repos,
+		RunCtx:           e.RunCtx,
+		CostBudgetUSD:    e.State.CostBudgetUSD,
+		InputWaitTimeout: time.Duration(e.State.InputWaitTimeoutMs) * time.Millisecond,
+		Locale:           e.State.Locale,
+		MinCodeRatio:     e.State.MinCodeRatio,
+		MinCodeBytes:     e.State.MinCodeBytes,
 	}, true
 }
 
@@ -0,0 +1,414 @@
+package project
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"insightify/internal/gateway/entity"
+	artifactrepo "insightify/internal/gateway/repository/artifact"
+	projectrepo "insightify/internal/gateway/repository/project"
+	"insightify/internal/llm/hooks"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+// Schema versions written to (and accepted from) project archives.
+// Bump the matching entry when the layout of that section changes.
+var archiveSchemas = map[string]int{
+	"archive":   1,
+	"state":     1,
+	"runs":      1,
+	"artifacts": 1,
+}
+
+const (
+	archiveManifestName = "manifest.json"
+	archiveStateName    = "state.json"
+	archiveRunsName     = "runs.json"
+	archiveArtifactsDir = "artifacts/"
+	archivePromptsDir   = "prompts/"
+
+	// promptLogDir is where hooks.PromptSaver writes prompt logs inside OutDir.
+	promptLogDir = hooks.PromptDir
+)
+
+// Caps on the decompressed archive, which the request body limit does not
+// bound. Variables so tests can lower them.
+var (
+	maxImportEntryBytes int64 = 256 << 20
+	maxImportTotalBytes int64 = 2 << 30
+)
+
+// ErrUnsupportedArchive is returned when an archive is malformed or was
+// written by a newer server.
+var ErrUnsupportedArchive = errors.New("unsupported project archive")
+
+// ArchiveManifest is the first entry of every project archive.
+type ArchiveManifest struct {
+	Schemas        map[string]int `json:"schemas"`
+	ProjectID      string         `json:"project_id"`
+	ModelSalt      string         `json:"model_salt"`
+	IncludePrompts bool           `json:"include_prompts"`
+	ExportedAt     time.Time      `json:"exported_at"`
+}
+
+// ExportOptions controls optional archive content.
+type ExportOptions struct {
+	IncludePrompts bool
+}
+
+// ExportProject streams a tar.gz of the project's state, run metadata and
+// OutDir artifacts to w. Nothing is written to w if validation fails.
+func (s *Service) ExportProject(ctx context.Context, userID entity.UserID, projectID string, w io.Writer, opts ExportOptions) error {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	e, ok := s.get(ctx, projectID)
+	if !ok {
+		return fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	if e.State.UserID != userID {
+		return fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
+	}
+	runCtx, err := s.EnsureRunContext(projectID)
+	if err != nil {
+		return err
+	}
+	// The manifest records the model salt, which materializing sets.
+	if err := runCtx.Materialize(); err != nil {
+		return err
+	}
+	var runs []projectrepo.ProjectArtifact
+	if s.metaRepo != nil {
+		if runs, err = s.metaRepo.ListArtifacts(ctx, projectID); err != nil {
+			return fmt.Errorf("failed to list project artifacts: %w", err)
+		}
+	}
+
+	manifest := ArchiveManifest{
+		Schemas:        archiveSchemas,
+		ProjectID:      projectID,
+		ModelSalt:      runCtx.ModelSalt,
+		IncludePrompts: opts.IncludePrompts,
+		ExportedAt:     time.Now().UTC(),
+	}
+	return writeProjectArchive(ctx, w, manifest, toRepoState(e.State), runs, runCtx.GetOutDir())
+}
+
+// ImportProject reads an archive produced by ExportProject and registers it
+// as a new project owned by userID. Project and run IDs are rewritten and the
+// artifacts are placed in the new project's OutDir so cache strategies hit.
+func (s *Service) ImportProject(ctx context.Context, userID entity.UserID, r io.Reader) (Entry, error) {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	projectID := fmt.Sprintf("project-%d", time.Now().UnixNano())
+	outDir := runtimepkg.ProjectOutDir(projectID)
+	manifest, state, runs, err := readProjectArchive(r, outDir)
+	if err != nil {
+		_ = os.RemoveAll(outDir)
+		return Entry{}, err
+	}
+
+	// Store the artifacts before registering the project, so a failed
+	// import leaves neither a project, its extracted files nor its stored
+	// artifacts behind.
+	runIDs := make(map[string]string)
+	imported := make([]projectrepo.ProjectArtifact, 0, len(runs))
+	stored := make([]projectrepo.ProjectArtifact, 0, len(runs))
+	for _, a := range runs {
+		runID, ok := runIDs[a.RunID]
+		if !ok {
+			runID = rewriteRunID(a.RunID, manifest.ProjectID, projectID)
+			runIDs[a.RunID] = runID
+		}
+		if s.artifact != nil {
+			content, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(a.Path)))
+			if err != nil {
+				continue
+			}
+			if err := s.artifact.Put(ctx, runID, a.Path, content); err != nil {
+				s.deleteArtifacts(ctx, stored)
+				_ = os.RemoveAll(outDir)
+				return Entry{}, fmt.Errorf("failed to store artifact %s: %w", a.Path, err)
+			}
+			stored = append(stored, projectrepo.ProjectArtifact{ProjectID: projectID, RunID: runID, Path: a.Path})
+		}
+		imported = append(imported, projectrepo.ProjectArtifact{ProjectID: projectID, RunID: runID, Path: a.Path})
+	}
+
+	name := strings.TrimSpace(state.ProjectName)
+	if name == "" {
+		name = fmt.Sprintf("Project %d", time.Now().Unix()%100000)
+	}
+	s.put(ctx, Entry{
+		State: State{
+			ProjectID:          projectID,
+			ProjectName:        name,
+			UserID:             userID,
+			Repo:               state.Repo,
+			IsActive:           true,
+			CostBudgetUSD:      state.CostBudgetUSD,
+			InputWaitTimeoutMs: state.InputWaitTimeoutMs,
+			Locale:             state.Locale,
+			MinCodeRatio:       state.MinCodeRatio,
+			MinCodeBytes:       state.MinCodeBytes,
+		},
+	})
+	_, _ = s.setActiveForUser(ctx, userID, projectID)
+	_ = s.repo.Save(ctx)
+	if s.metaRepo != nil {
+		for _, a := range imported {
+			_ = s.metaRepo.AddArtifact(ctx, a)
+		}
+	}
+
+	got, ok := s.get(ctx, projectID)
+	if !ok {
+		return Entry{}, fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	return got, nil
+}
+
+// deleteArtifacts removes artifacts stored by an import that failed later.
+// Stores that cannot delete keep them; no project references them.
+func (s *Service) deleteArtifacts(ctx context.Context, artifacts []projectrepo.ProjectArtifact) {
+	deleter, ok := s.artifact.(artifactrepo.Deleter)
+	if !ok {
+		return
+	}
+	for _, a := range artifacts {
+		_ = deleter.Delete(ctx, a.RunID, a.Path)
+	}
+}
+
+func rewriteRunID(runID, oldProjectID, newProjectID string) string {
+	if oldProjectID != "" && strings.Contains(runID, oldProjectID) {
+		return strings.Replace(runID, oldProjectID, newProjectID, 1)
+	}
+	return newProjectID + "-" + runID
+}
+
+func writeProjectArchive(ctx context.Context, w io.Writer, manifest ArchiveManifest, state projectrepo.State, runs []projectrepo.ProjectArtifact, outDir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeJSONEntry(tw, archiveManifestName, manifest); err != nil {
+		return err
+	}
+	if err := writeJSONEntry(tw, archiveStateName, state); err != nil {
+		return err
+	}
+	if runs == nil {
+		runs = []projectrepo.ProjectArtifact{}
+	}
+	if err := writeJSONEntry(tw, archiveRunsName, runs); err != nil {
+		return err
+	}
+
+	root := filepath.Clean(outDir)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == root {
+				return nil
+			}
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		// Only regular files; symlinks could point outside the project.
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		var name string
+		switch {
+		case strings.HasPrefix(rel, promptLogDir+"/"):
+			if !manifest.IncludePrompts {
+				return nil
+			}
+			name = archivePromptsDir + strings.TrimPrefix(rel, promptLogDir+"/")
+		case strings.EqualFold(path.Ext(rel), ".json"):
+			name = archiveArtifactsDir + rel
+		default:
+			return nil
+		}
+		return writeFileEntry(tw, name, p)
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeJSONEntry(tw *tar.Writer, name string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(b)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err = tw.Write(b)
+	return err
+}
+
+func writeFileEntry(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// readProjectArchive validates the manifest (which must be the first entry)
+// and extracts artifacts and prompt logs into outDir. The returned runs only
+// list artifacts the archive actually contained; a run path that would
+// escape outDir fails the import.
+func readProjectArchive(r io.Reader, outDir string) (ArchiveManifest, projectrepo.State, []projectrepo.ProjectArtifact, error) {
+	var (
+		manifest ArchiveManifest
+		state    projectrepo.State
+		runs     []projectrepo.ProjectArtifact
+	)
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return manifest, state, nil, fmt.Errorf("%w: %v", ErrUnsupportedArchive, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	remaining := maxImportTotalBytes
+	extracted := make(map[string]bool)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != archiveManifestName {
+		return manifest, state, nil, fmt.Errorf("%w: %s must be the first entry", ErrUnsupportedArchive, archiveManifestName)
+	}
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return manifest, state, nil, fmt.Errorf("%w: invalid manifest: %v", ErrUnsupportedArchive, err)
+	}
+	for section, v := range manifest.Schemas {
+		if supported, ok := archiveSchemas[section]; !ok || v > supported {
+			return manifest, state, nil, fmt.Errorf("%w: %s schema version %d is newer than supported", ErrUnsupportedArchive, section, v)
+		}
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return manifest, state, nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		// The tar reader yields at most hdr.Size bytes of a regular entry.
+		if hdr.Size > maxImportEntryBytes || hdr.Size > remaining {
+			return manifest, state, nil, fmt.Errorf("%w: entry %q is too large", ErrUnsupportedArchive, hdr.Name)
+		}
+		remaining -= hdr.Size
+		switch {
+		case hdr.Name == archiveStateName:
+			if err := json.NewDecoder(tr).Decode(&state); err != nil {
+				return manifest, state, nil, fmt.Errorf("%w: invalid state: %v", ErrUnsupportedArchive, err)
+			}
+		case hdr.Name == archiveRunsName:
+			if err := json.NewDecoder(tr).Decode(&runs); err != nil {
+				return manifest, state, nil, fmt.Errorf("%w: invalid runs: %v", ErrUnsupportedArchive, err)
+			}
+		case strings.HasPrefix(hdr.Name, archiveArtifactsDir):
+			rel := strings.TrimPrefix(hdr.Name, archiveArtifactsDir)
+			if err := extractEntry(tr, outDir, rel); err != nil {
+				return manifest, state, nil, err
+			}
+			extracted[rel] = true
+		case strings.HasPrefix(hdr.Name, archivePromptsDir):
+			if err := extractEntry(tr, filepath.Join(outDir, promptLogDir), strings.TrimPrefix(hdr.Name, archivePromptsDir)); err != nil {
+				return manifest, state, nil, err
+			}
+		}
+	}
+
+	kept := runs[:0]
+	for _, a := range runs {
+		if _, err := cleanEntryPath(a.Path); err != nil {
+			return manifest, state, nil, err
+		}
+		if extracted[a.Path] {
+			kept = append(kept, a)
+		}
+	}
+	return manifest, state, kept, nil
+}
+
+// cleanEntryPath returns rel if it is a relative path that stays below the
+// directory it is joined to.
+func cleanEntryPath(rel string) (string, error) {
+	clean := strings.TrimPrefix(path.Clean("/"+rel), "/")
+	if clean == "" || clean != rel {
+		return "", fmt.Errorf("%w: invalid entry path %q", ErrUnsupportedArchive, rel)
+	}
+	return clean, nil
+}
+
+// extractEntry writes one archive entry below dir, rejecting paths that would
+// escape it.
+func extractEntry(r io.Reader, dir, rel string) error {
+	clean, err := cleanEntryPath(rel)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(dir, filepath.FromSlash(clean))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
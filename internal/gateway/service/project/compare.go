@@ -0,0 +1,175 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"insightify/internal/artifactdiff"
+	"insightify/internal/gateway/entity"
+	projectrepo "insightify/internal/gateway/repository/project"
+	"insightify/internal/runner"
+	runtimepkg "insightify/internal/workerruntime"
+	"insightify/internal/workerruntime/artifactblob"
+)
+
+// CompareRequest selects the two sides of CompareRuns. BaseRunID may be empty
+// to compare HeadRunID against the most recent earlier run of the project
+// that stored Key.
+type CompareRequest struct {
+	Key       string
+	BaseRunID string
+	HeadRunID string
+	Options   artifactdiff.Options
+}
+
+// ListArtifacts returns the synced artifacts of a project, oldest first.
+// A non-empty worker keeps only that worker's versioned outputs.
+func (s *Service) ListArtifacts(ctx context.Context, userID entity.UserID, projectID, worker string) ([]ArtifactView, error) {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	p, ok := s.get(ctx, projectID)
+	if !ok {
+		return nil, fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	if p.State.UserID != userID {
+		return nil, fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
+	}
+	views := s.resolveArtifacts(ctx, projectID)
+	if worker = strings.TrimSpace(worker); worker == "" {
+		return views, nil
+	}
+	out := views[:0]
+	for _, v := range views {
+		if v.Worker == worker {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// CompareRuns diffs the Key artifact of two runs of a project. Both runs must
+// have synced the artifact into the project's run metadata.
+func (s *Service) CompareRuns(ctx context.Context, userID entity.UserID, projectID string, req CompareRequest) (artifactdiff.Result, error) {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	key := strings.TrimSpace(req.Key)
+	if !artifactdiff.Supported(key) {
+		return artifactdiff.Result{}, fmt.Errorf("%w: %q", artifactdiff.ErrUnsupportedKey, key)
+	}
+	p, ok := s.get(ctx, projectID)
+	if !ok {
+		return artifactdiff.Result{}, fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	if p.State.UserID != userID {
+		return artifactdiff.Result{}, fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
+	}
+	if s.metaRepo == nil || s.artifact == nil {
+		return artifactdiff.Result{}, fmt.Errorf("artifact store is not configured")
+	}
+	list, err := s.metaRepo.ListArtifacts(ctx, projectID)
+	if err != nil {
+		return artifactdiff.Result{}, fmt.Errorf("failed to list project artifacts: %w", err)
+	}
+
+	head, ok := runArtifact(list, strings.TrimSpace(req.HeadRunID), key)
+	if !ok {
+		return artifactdiff.Result{}, fmt.Errorf("artifact %s of run %s %w", key, req.HeadRunID, ErrNotFound)
+	}
+	var base projectrepo.ProjectArtifact
+	if baseRunID := strings.TrimSpace(req.BaseRunID); baseRunID != "" {
+		if base, ok = runArtifact(list, baseRunID, key); !ok {
+			return artifactdiff.Result{}, fmt.Errorf("artifact %s of run %s %w", key, baseRunID, ErrNotFound)
+		}
+	} else if base, ok = previousArtifact(list, head, key); !ok {
+		return artifactdiff.Result{}, fmt.Errorf("previous run with artifact %s before run %s %w", key, head.RunID, ErrNotFound)
+	}
+
+	before, err := s.readRunArtifact(ctx, base)
+	if err != nil {
+		return artifactdiff.Result{}, fmt.Errorf("failed to read %s of run %s: %w", base.Path, base.RunID, err)
+	}
+	after, err := s.readRunArtifact(ctx, head)
+	if err != nil {
+		return artifactdiff.Result{}, fmt.Errorf("failed to read %s of run %s: %w", head.Path, head.RunID, err)
+	}
+	return artifactdiff.Compare(key, before, after, req.Options)
+}
+
+// readRunArtifact reads a stored artifact of a run, reassembling it when its
+// parts were stored (see runner.ReadArtifact). Runs are read from their
+// snapshot in the artifact blob store, or from the artifact store for runs
+// synced without one and imported runs.
+func (s *Service) readRunArtifact(ctx context.Context, a projectrepo.ProjectArtifact) ([]byte, error) {
+	dir := path.Dir(a.Path)
+	blob := runtimepkg.ArtifactBlobStore()
+	return runner.ReadArtifactFunc(ctx, path.Base(a.Path), func(ctx context.Context, name string) ([]byte, error) {
+		if blob != nil && a.ProjectID != "" {
+			content, err := artifactblob.ReadAll(ctx, blob, artifactblob.Key(a.ProjectID, a.RunID, path.Join(dir, name)))
+			if !errors.Is(err, artifactblob.ErrNotFound) {
+				return content, err
+			}
+		}
+		return s.artifact.Get(ctx, a.RunID, path.Join(dir, name))
+	})
+}
+
+// runArtifact finds key in runID: <key>.json, or else its highest <key>_vN.json.
+func runArtifact(list []projectrepo.ProjectArtifact, runID, key string) (projectrepo.ProjectArtifact, bool) {
+	var best projectrepo.ProjectArtifact
+	bestVersion := -1
+	for _, a := range list {
+		if a.RunID != runID {
+			continue
+		}
+		if v, ok := artifactVersion(a.Path, key); ok && v > bestVersion {
+			best, bestVersion = a, v
+		}
+	}
+	return best, bestVersion >= 0
+}
+
+// previousArtifact returns the latest artifact of key recorded by another run
+// before head.
+func previousArtifact(list []projectrepo.ProjectArtifact, head projectrepo.ProjectArtifact, key string) (projectrepo.ProjectArtifact, bool) {
+	var prev projectrepo.ProjectArtifact
+	found := false
+	for _, a := range list {
+		if a.RunID == head.RunID || !recordedBefore(a, head) {
+			continue
+		}
+		if _, ok := artifactVersion(a.Path, key); !ok {
+			continue
+		}
+		if !found || recordedBefore(prev, a) {
+			prev, found = a, true
+		}
+	}
+	if !found {
+		return prev, false
+	}
+	// Prefer the highest version the chosen run holds.
+	return runArtifact(list, prev.RunID, key)
+}
+
+// artifactVersion matches path against key; plain <key>.json is version 0.
+func artifactVersion(path, key string) (int, bool) {
+	if path == key+".json" {
+		return 0, true
+	}
+	if k, n, ok := runner.ParseVersionedName(path); ok && k == key {
+		return n, true
+	}
+	return 0, false
+}
+
+func recordedBefore(a, b projectrepo.ProjectArtifact) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
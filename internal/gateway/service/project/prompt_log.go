@@ -0,0 +1,30 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"insightify/internal/gateway/entity"
+	"insightify/internal/llm/hooks"
+)
+
+// PromptLogDir returns the directory holding the per-run prompt logs of a
+// project owned by userID.
+func (s *Service) PromptLogDir(ctx context.Context, userID entity.UserID, projectID string) (string, error) {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	p, ok := s.get(ctx, projectID)
+	if !ok {
+		return "", fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	if p.State.UserID != userID {
+		return "", fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
+	}
+	runCtx, err := s.EnsureRunContext(projectID)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(runCtx.GetOutDir(), hooks.PromptDir), nil
+}
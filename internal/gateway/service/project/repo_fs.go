@@ -0,0 +1,40 @@
+package project
+
+import (
+	"context"
+	"fmt"
+
+	"insightify/internal/common/safeio"
+	"insightify/internal/gateway/entity"
+)
+
+// RepoFS returns the checkout of a repository of a project owned by userID.
+// An empty repo selects the project's default repository.
+func (s *Service) RepoFS(ctx context.Context, userID entity.UserID, projectID, repo string) (*safeio.SafeFS, error) {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	p, ok := s.get(ctx, projectID)
+	if !ok {
+		return nil, fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	if p.State.UserID != userID {
+		return nil, fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
+	}
+	runCtx, err := s.EnsureRunContext(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if repo == "" {
+		if runCtx.RepoFS == nil {
+			return nil, fmt.Errorf("repository checkout of project %s %w", projectID, ErrNotFound)
+		}
+		return runCtx.RepoFS, nil
+	}
+	for _, r := range runCtx.Repos {
+		if r.Name == repo && r.FS != nil {
+			return r.FS, nil
+		}
+	}
+	return nil, fmt.Errorf("repo %q of project %s %w", repo, projectID, ErrNotFound)
+}
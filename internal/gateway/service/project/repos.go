@@ -0,0 +1,75 @@
+package project
+
+import (
+	"context"
+	"fmt"
+
+	"insightify/internal/common/scan"
+	"insightify/internal/gateway/entity"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+// SetRepos replaces the repositories of a project. The first entry becomes
+// the default repository, used by runs that do not select one. The run
+// context is rebuilt on the next run so every repository gets its own root.
+func (s *Service) SetRepos(ctx context.Context, userID entity.UserID, projectID string, repos []entity.RepoEntry) (Entry, error) {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	repos, err := entity.NormalizeRepoEntries(repos)
+	if err != nil {
+		return Entry{}, err
+	}
+	for i := range repos {
+		if repos[i].LocalPath, err = repoLocalPath(repos[i]); err != nil {
+			return Entry{}, err
+		}
+	}
+	p, ok := s.get(ctx, projectID)
+	if !ok {
+		return Entry{}, fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	if p.State.UserID != userID {
+		return Entry{}, fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
+	}
+
+	p.State.Repos = repos
+	if len(repos) > 0 {
+		p.State.Repo = repos[0].Name
+	}
+	p.RunCtx = nil
+	s.put(ctx, p)
+	_ = s.repo.Save(ctx)
+
+	got, _ := s.get(ctx, projectID)
+	return got, nil
+}
+
+// repoLocalPath resolves the local_path of r, which must lie under
+// scan.ReposDir(): it becomes the root of the repository's SafeFS, so any
+// other path would expose the host's files through /project/repo-file.
+func repoLocalPath(r entity.RepoEntry) (string, error) {
+	if r.LocalPath == "" {
+		return "", nil
+	}
+	root, err := scan.ResolveRoot(r.LocalPath)
+	if err != nil {
+		return "", fmt.Errorf("%w: repo %s local_path: %v", entity.ErrInvalidRepo, r.Name, err)
+	}
+	return root, nil
+}
+
+// newRunContext builds the project runtime descriptor for state, opening
+// every registered repository. Stored local paths are checked again, as
+// they may predate the check in SetRepos.
+func newRunContext(state State) (*runtimepkg.ProjectRuntime, error) {
+	repos := make([]runtimepkg.RepoEntry, 0, len(state.Repos))
+	for _, r := range state.Repos {
+		localPath, err := repoLocalPath(r)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, runtimepkg.RepoEntry{Name: r.Name, URL: r.URL, LocalPath: localPath})
+	}
+	return runtimepkg.NewProjectDescriptor(state.Repo, state.ProjectID, repos...)
+}
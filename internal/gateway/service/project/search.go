@@ -0,0 +1,159 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"insightify/internal/artifactsearch"
+	"insightify/internal/common/logctx"
+	"insightify/internal/gateway/entity"
+	projectrepo "insightify/internal/gateway/repository/project"
+)
+
+// maxSearchIndexes bounds the project search indexes kept in memory; the
+// least recently used one is dropped first.
+const maxSearchIndexes = 16
+
+// SearchRequest is a multi-term AND query over a project's artifacts.
+// Sources filters by artifactsearch source type; empty means all.
+type SearchRequest struct {
+	Query   string
+	Sources []string
+	Limit   int
+}
+
+type projectSearchIndex struct {
+	signature string
+	index     *artifactsearch.Index
+	usedAt    time.Time
+}
+
+// SearchProject searches the latest synced artifact of each indexed key (see
+// artifactsearch.Keys). The index is built on first use and rebuilt when the
+// project's artifact metadata changes.
+func (s *Service) SearchProject(ctx context.Context, userID entity.UserID, projectID string, req SearchRequest) (artifactsearch.Result, error) {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	if strings.TrimSpace(req.Query) == "" {
+		return artifactsearch.Result{}, fmt.Errorf("query is required")
+	}
+	sources, err := artifactsearch.ParseSources(req.Sources)
+	if err != nil {
+		return artifactsearch.Result{}, err
+	}
+	p, ok := s.get(ctx, projectID)
+	if !ok {
+		return artifactsearch.Result{}, fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	if p.State.UserID != userID {
+		return artifactsearch.Result{}, fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
+	}
+	index, err := s.searchIndex(ctx, projectID)
+	if err != nil {
+		return artifactsearch.Result{}, err
+	}
+	return index.Search(artifactsearch.Query{Text: req.Query, Sources: sources, Limit: req.Limit}), nil
+}
+
+// searchIndex returns the project's index, rebuilding it when the artifacts
+// it was built from changed.
+func (s *Service) searchIndex(ctx context.Context, projectID string) (*artifactsearch.Index, error) {
+	if s.metaRepo == nil || s.artifact == nil {
+		return nil, fmt.Errorf("artifact store is not configured")
+	}
+	list, err := s.metaRepo.ListArtifacts(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project artifacts: %w", err)
+	}
+	sources := searchArtifacts(list)
+	signature := searchSignature(sources)
+
+	s.searchMu.Lock()
+	if e, ok := s.search[projectID]; ok && e.signature == signature {
+		e.usedAt = time.Now()
+		s.searchMu.Unlock()
+		return e.index, nil
+	}
+	s.searchMu.Unlock()
+
+	index := artifactsearch.NewIndex(s.searchLimits)
+	for _, a := range sources {
+		raw, err := s.readRunArtifact(ctx, a.ProjectArtifact)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s of run %s: %w", a.Path, a.RunID, err)
+		}
+		docs, err := artifactsearch.Extract(a.key, raw)
+		if err != nil {
+			logctx.Warn(ctx, "skipping unreadable artifact in search index", "project_id", projectID, "run_id", a.RunID, "path", a.Path, "error", err)
+			continue
+		}
+		for i := range docs {
+			docs[i].RunID = a.RunID
+			docs[i].Path = a.Path
+		}
+		if !index.Add(docs...) {
+			logctx.Warn(ctx, "project search index is full", "project_id", projectID, "docs", index.Len(), "bytes", index.Bytes())
+			break
+		}
+	}
+
+	s.searchMu.Lock()
+	defer s.searchMu.Unlock()
+	if s.search == nil {
+		s.search = make(map[string]*projectSearchIndex)
+	}
+	s.search[projectID] = &projectSearchIndex{signature: signature, index: index, usedAt: time.Now()}
+	for len(s.search) > maxSearchIndexes {
+		oldest := ""
+		for id, e := range s.search {
+			if oldest == "" || e.usedAt.Before(s.search[oldest].usedAt) {
+				oldest = id
+			}
+		}
+		delete(s.search, oldest)
+	}
+	return index, nil
+}
+
+// searchSource is a stored artifact the search index reads.
+type searchSource struct {
+	key string
+	projectrepo.ProjectArtifact
+}
+
+// searchArtifacts picks, for each indexed key, the highest version stored by
+// the most recent run that synced the key, in artifactsearch.Keys order.
+func searchArtifacts(list []projectrepo.ProjectArtifact) []searchSource {
+	var out []searchSource
+	for _, key := range artifactsearch.Keys {
+		var latest projectrepo.ProjectArtifact
+		found := false
+		for _, a := range list {
+			if _, ok := artifactVersion(a.Path, key); !ok {
+				continue
+			}
+			if !found || recordedBefore(latest, a) {
+				latest, found = a, true
+			}
+		}
+		if !found {
+			continue
+		}
+		if best, ok := runArtifact(list, latest.RunID, key); ok {
+			out = append(out, searchSource{key: key, ProjectArtifact: best})
+		}
+	}
+	return out
+}
+
+func searchSignature(list []searchSource) string {
+	parts := make([]string, 0, len(list))
+	for _, a := range list {
+		parts = append(parts, strings.Join([]string{a.RunID, a.Path, strconv.Itoa(a.ID), strconv.FormatInt(a.CreatedAt.UnixNano(), 10)}, "|"))
+	}
+	return strings.Join(parts, ";")
+}
@@ -2,16 +2,29 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"insightify/internal/artifactsearch"
 	"insightify/internal/gateway/entity"
 	artifactrepo "insightify/internal/gateway/repository/artifact"
 	projectrepo "insightify/internal/gateway/repository/project"
+	"insightify/internal/runner"
 	runtimepkg "insightify/internal/workerruntime"
+	"insightify/internal/workerruntime/artifactblob"
+)
+
+var (
+	// ErrNotFound matches errors of projects, repositories, runs or
+	// artifacts that do not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrForbidden matches errors of projects owned by another user.
+	ErrForbidden = errors.New("does not belong to user")
 )
 
 // Service implements Project business logic and owns all project state.
@@ -22,6 +35,13 @@ type Service struct {
 
 	runCtxMu sync.RWMutex
 	runCtx   map[string]*runtimepkg.ProjectRuntime
+	// restoreMu serializes EnsureRunContext restoring descriptors, so one
+	// project never gets two; it is never held with runCtxMu.
+	restoreMu sync.Mutex
+
+	searchMu     sync.Mutex
+	search       map[string]*projectSearchIndex // by project ID; see SearchProject
+	searchLimits artifactsearch.Limits
 }
 
 // New creates a project service backed by the given store.
@@ -39,11 +59,15 @@ func New(repo projectrepo.Repository, metaRepo projectrepo.ArtifactRepository, a
 // ---------------------------------------------------------------------------
 
 type ArtifactView struct {
-	ID        string
-	RunID     string
-	Path      string
-	URL       string
-	CreatedAt time.Time
+	ID        string    `json:"id"`
+	RunID     string    `json:"run_id"`
+	Path      string    `json:"path"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Worker and Version are set for versioned outputs (<worker>_vN.json),
+	// so the UI can offer "compare with previous run".
+	Worker  string `json:"worker,omitempty"`
+	Version int    `json:"version,omitempty"`
 }
 
 // Entry is the public type for project entry (was unexported 'entry').
@@ -85,12 +109,10 @@ func (s *Service) CreateProject(ctx context.Context, userID entity.UserID, proje
 
 	projectID := fmt.Sprintf("project-%d", time.Now().UnixNano())
 
-	var runCtx *runtimepkg.ProjectRuntime
-	createdRunCtx, err := runtimepkg.NewProjectRuntime("", projectID)
+	runCtx, err := runtimepkg.NewProjectDescriptor("", projectID)
 	if err != nil {
 		return Entry{}, fmt.Errorf("failed to create run context: %w", err)
 	}
-	runCtx = createdRunCtx
 
 	p := Entry{
 		State: State{
@@ -116,15 +138,15 @@ func (s *Service) SelectProject(ctx context.Context, userID entity.UserID, proje
 
 	p, ok := s.get(ctx, projectID)
 	if !ok {
-		return Entry{}, fmt.Errorf("project %s not found", projectID)
+		return Entry{}, fmt.Errorf("project %s %w", projectID, ErrNotFound)
 	}
 	if p.State.UserID != userID {
-		return Entry{}, fmt.Errorf("project %s does not belong to user %s", projectID, userID.String())
+		return Entry{}, fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
 	}
 
 	selected, ok := s.setActiveForUser(ctx, userID, projectID)
 	if !ok {
-		return Entry{}, fmt.Errorf("project %s not found", projectID)
+		return Entry{}, fmt.Errorf("project %s %w", projectID, ErrNotFound)
 	}
 	_ = s.repo.Save(ctx)
 	return selected, nil
@@ -174,7 +196,7 @@ func (s *Service) EnsureProject(ctx context.Context, userID entity.UserID, proje
 
 	// Ensure run context.
 	if !s.hasRequiredWorkers(p.RunCtx) {
-		ctx, err := runtimepkg.NewProjectRuntime(p.State.Repo, projectID)
+		ctx, err := newRunContext(p.State)
 		if err != nil {
 			return Entry{}, fmt.Errorf("failed to create run context: %w", err)
 		}
@@ -217,19 +239,34 @@ func (s *Service) resolveArtifacts(ctx context.Context, projectID string) []Arti
 	}
 	out := make([]ArtifactView, 0, len(list))
 	for _, a := range list {
-		url, _ := s.artifact.GetURL(ctx, a.RunID, a.Path)
+		url := s.artifactURL(ctx, projectID, a)
 		// ID is int in DB, converting to string for View/Proto
-		out = append(out, ArtifactView{
+		view := ArtifactView{
 			ID:        fmt.Sprintf("%d", a.ID),
 			RunID:     a.RunID,
 			Path:      a.Path,
 			URL:       url,
 			CreatedAt: a.CreatedAt,
-		})
+		}
+		view.Worker, view.Version, _ = runner.ParseVersionedName(path.Base(a.Path))
+		out = append(out, view)
 	}
 	return out
 }
 
+// artifactURL signs the run's snapshot in the artifact blob store, falling
+// back to the artifact store URL when the backend has none.
+func (s *Service) artifactURL(ctx context.Context, projectID string, a projectrepo.ProjectArtifact) string {
+	if blob := runtimepkg.ArtifactBlobStore(); blob != nil {
+		url, err := blob.SignedURL(ctx, artifactblob.Key(projectID, a.RunID, a.Path), artifactblob.DefaultSignedURLTTL)
+		if err == nil && url != "" {
+			return url
+		}
+	}
+	url, _ := s.artifact.GetURL(ctx, a.RunID, a.Path)
+	return url
+}
+
 func (s *Service) put(ctx context.Context, e Entry) {
 	if strings.TrimSpace(e.State.ProjectID) == "" {
 		return
@@ -317,25 +354,37 @@ func (s *Service) GetEntry(projectID string) (State, bool) {
 		return State{}, false
 	}
 	return State{
-		ProjectID:   e.State.ProjectID,
-		ProjectName: e.State.ProjectName,
-		UserID:      e.State.UserID,
-		Repo:        e.State.Repo,
-		IsActive:    e.State.IsActive,
-		RunCtx:      e.RunCtx,
+		ProjectID:          e.State.ProjectID,
+		ProjectName:        e.State.ProjectName,
+		UserID:             e.State.UserID,
+		Repo:               e.State.Repo,
+		Repos:              e.State.Repos,
+		IsActive:           e.State.IsActive,
+		RunCtx:             e.RunCtx,
+		CostBudgetUSD:      e.State.CostBudgetUSD,
+		InputWaitTimeoutMs: e.State.InputWaitTimeoutMs,
+		Locale:             e.State.Locale,
+		MinCodeRatio:       e.State.MinCodeRatio,
+		MinCodeBytes:       e.State.MinCodeBytes,
 	}, true
 }
 
-// EnsureRunContext ensures a project has a valid run context with required workers.
+// EnsureRunContext ensures a project has a valid run context with required
+// workers. The context is a descriptor; runs call Materialize on it.
 func (s *Service) EnsureRunContext(projectID string) (*runtimepkg.ProjectRuntime, error) {
+	if rt, ok := s.GetRunContext(projectID); ok && s.hasRequiredWorkers(rt) {
+		return rt, nil
+	}
+	s.restoreMu.Lock()
+	defer s.restoreMu.Unlock()
 	e, ok := s.get(context.Background(), projectID)
 	if !ok {
-		return nil, fmt.Errorf("project %s not found", projectID)
+		return nil, fmt.Errorf("project %s %w", projectID, ErrNotFound)
 	}
 	if e.RunCtx != nil && s.hasRequiredWorkers(e.RunCtx) {
 		return e.RunCtx, nil
 	}
-	ctx, err := runtimepkg.NewProjectRuntime(e.State.Repo, projectID)
+	ctx, err := newRunContext(e.State)
 	if err != nil {
 		return nil, fmt.Errorf("failed to restore run context: %w", err)
 	}
@@ -351,13 +400,10 @@ func ensureContext(ctx context.Context) context.Context {
 	return ctx
 }
 
+// hasRequiredWorkers checks the worker list of env, which is known without
+// materializing it.
 func (s *Service) hasRequiredWorkers(env *runtimepkg.ProjectRuntime) bool {
-	if env == nil || env.Resolver == nil {
-		return false
-	}
-	_, hasBootstrap := env.Resolver.Get("bootstrap")
-	_, hasActBootstrap := env.Resolver.Get("actBootstrapNode")
-	return hasBootstrap && hasActBootstrap
+	return env.HasWorkers("bootstrap", "actBootstrapNode")
 }
 
 func isProjectID(id string) bool {
@@ -370,26 +416,49 @@ type State struct {
 	ProjectName string
 	UserID      entity.UserID
 	Repo        string
+	Repos       []entity.RepoEntry
 	IsActive    bool
 	RunCtx      *runtimepkg.ProjectRuntime
+	// CostBudgetUSD is the default LLM cost budget of the project's runs.
+	CostBudgetUSD float64
+	// InputWaitTimeoutMs bounds the input waits of the project's runs.
+	InputWaitTimeoutMs int64
+	// Locale is the language of assistant messages of the project's runs.
+	Locale string
+	// MinCodeRatio and MinCodeBytes are the repo_assessment thresholds of
+	// the project's runs.
+	MinCodeRatio float64
+	MinCodeBytes int64
 }
 
 func fromRepoState(s projectrepo.State) State {
 	return State{
-		ProjectID:   s.ProjectID,
-		ProjectName: s.ProjectName,
-		UserID:      s.UserID,
-		Repo:        s.Repo,
-		IsActive:    s.IsActive,
+		ProjectID:          s.ProjectID,
+		ProjectName:        s.ProjectName,
+		UserID:             s.UserID,
+		Repo:               s.Repo,
+		Repos:              s.Repos,
+		IsActive:           s.IsActive,
+		CostBudgetUSD:      s.CostBudgetUSD,
+		InputWaitTimeoutMs: s.InputWaitTimeoutMs,
+		Locale:             s.Locale,
+		MinCodeRatio:       s.MinCodeRatio,
+		MinCodeBytes:       s.MinCodeBytes,
 	}
 }
 
 func toRepoState(s State) projectrepo.State {
 	return projectrepo.State{
-		ProjectID:   s.ProjectID,
-		ProjectName: s.ProjectName,
-		UserID:      s.UserID,
-		Repo:        s.Repo,
-		IsActive:    s.IsActive,
+		ProjectID:          s.ProjectID,
+		ProjectName:        s.ProjectName,
+		UserID:             s.UserID,
+		Repo:               s.Repo,
+		Repos:              s.Repos,
+		IsActive:           s.IsActive,
+		CostBudgetUSD:      s.CostBudgetUSD,
+		InputWaitTimeoutMs: s.InputWaitTimeoutMs,
+		Locale:             s.Locale,
+		MinCodeRatio:       s.MinCodeRatio,
+		MinCodeBytes:       s.MinCodeBytes,
 	}
 }
@@ -0,0 +1,72 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"insightify/internal/gateway/entity"
+)
+
+// ErrInvalidSettings reports project settings out of range.
+var ErrInvalidSettings = errors.New("invalid project settings")
+
+// Settings are the run defaults of a project.
+type Settings struct {
+	// CostBudgetUSD caps the LLM spend of runs that set no
+	// cost_budget_usd param; 0 leaves them unbounded.
+	CostBudgetUSD float64 `json:"cost_budget_usd"`
+	// InputWaitTimeoutMs bounds how long runs wait on user input; 0 uses
+	// the server default.
+	InputWaitTimeoutMs int64 `json:"input_wait_timeout_ms"`
+	// Locale is the language of assistant messages (e.g. "en", "ja") of
+	// runs that set no locale param; "" uses the request's
+	// Accept-Language. Unsupported locales fall back to English.
+	Locale string `json:"locale"`
+	// MinCodeRatio is the share of code files below which runs that set
+	// no min_code_ratio param skip the analysis phases; 0 uses the
+	// default.
+	MinCodeRatio float64 `json:"min_code_ratio"`
+	// MinCodeBytes is the code size below which runs that set no
+	// min_code_bytes param skip the analysis phases; 0 uses the default.
+	MinCodeBytes int64 `json:"min_code_bytes"`
+}
+
+// SetSettings replaces the run defaults of a project.
+func (s *Service) SetSettings(ctx context.Context, userID entity.UserID, projectID string, settings Settings) (Entry, error) {
+	ctx = ensureContext(ctx)
+	s.repo.EnsureLoaded(ctx)
+
+	if settings.CostBudgetUSD < 0 || math.IsInf(settings.CostBudgetUSD, 0) || math.IsNaN(settings.CostBudgetUSD) {
+		return Entry{}, fmt.Errorf("%w: cost_budget_usd must be a non-negative amount", ErrInvalidSettings)
+	}
+	if settings.InputWaitTimeoutMs < 0 {
+		return Entry{}, fmt.Errorf("%w: input_wait_timeout_ms must not be negative", ErrInvalidSettings)
+	}
+	if settings.MinCodeRatio < 0 || settings.MinCodeRatio > 1 || math.IsNaN(settings.MinCodeRatio) {
+		return Entry{}, fmt.Errorf("%w: min_code_ratio must be between 0 and 1", ErrInvalidSettings)
+	}
+	if settings.MinCodeBytes < 0 {
+		return Entry{}, fmt.Errorf("%w: min_code_bytes must not be negative", ErrInvalidSettings)
+	}
+	p, ok := s.get(ctx, projectID)
+	if !ok {
+		return Entry{}, fmt.Errorf("project %s %w", projectID, ErrNotFound)
+	}
+	if p.State.UserID != userID {
+		return Entry{}, fmt.Errorf("project %s %w %s", projectID, ErrForbidden, userID.String())
+	}
+
+	p.State.CostBudgetUSD = settings.CostBudgetUSD
+	p.State.InputWaitTimeoutMs = settings.InputWaitTimeoutMs
+	p.State.Locale = strings.TrimSpace(settings.Locale)
+	p.State.MinCodeRatio = settings.MinCodeRatio
+	p.State.MinCodeBytes = settings.MinCodeBytes
+	s.put(ctx, p)
+	_ = s.repo.Save(ctx)
+
+	got, _ := s.get(ctx, projectID)
+	return got, nil
+}
@@ -0,0 +1,249 @@
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"insightify/internal/gateway/entity"
+	artifactrepo "insightify/internal/gateway/repository/artifact"
+	projectrepo "insightify/internal/gateway/repository/project"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+func writeTestFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProjectArchiveRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	writeTestFile(t, src, "code_graph.json", `{"repo":"r"}`)
+	writeTestFile(t, src, "code_graph.meta.json", `{}`)
+	writeTestFile(t, src, "bootstrap_v1.json", `{}`)
+	writeTestFile(t, src, "notes.txt", "skip me")
+	writeTestFile(t, src, "prompt/code_graph.txt", "prompt log")
+	outside := filepath.Join(t.TempDir(), "secret.json")
+	writeTestFile(t, filepath.Dir(outside), "secret.json", `{}`)
+	if err := os.Symlink(outside, filepath.Join(src, "link.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := ArchiveManifest{Schemas: archiveSchemas, ProjectID: "project-1", ModelSalt: "salt", IncludePrompts: true}
+	state := projectrepo.State{ProjectID: "project-1", ProjectName: "Demo", Repo: "repo"}
+	runs := []projectrepo.ProjectArtifact{{ProjectID: "project-1", RunID: "run-project-1-1-ab", Path: "code_graph.json"}}
+
+	var buf bytes.Buffer
+	if err := writeProjectArchive(context.Background(), &buf, manifest, state, runs, src); err != nil {
+		t.Fatalf("writeProjectArchive() error = %v", err)
+	}
+
+	dst := t.TempDir()
+	gotManifest, gotState, gotRuns, err := readProjectArchive(&buf, dst)
+	if err != nil {
+		t.Fatalf("readProjectArchive() error = %v", err)
+	}
+	if gotManifest.ModelSalt != "salt" || gotState.ProjectName != "Demo" || len(gotRuns) != 1 {
+		t.Fatalf("unexpected metadata: %+v %+v %+v", gotManifest, gotState, gotRuns)
+	}
+	for _, rel := range []string{"code_graph.json", "code_graph.meta.json", "bootstrap_v1.json", "prompt/code_graph.txt"} {
+		if _, err := os.Stat(filepath.Join(dst, rel)); err != nil {
+			t.Fatalf("expected %s to be extracted: %v", rel, err)
+		}
+	}
+	for _, rel := range []string{"notes.txt", "link.json"} {
+		if _, err := os.Stat(filepath.Join(dst, rel)); err == nil {
+			t.Fatalf("%s should not be exported", rel)
+		}
+	}
+	if got := rewriteRunID(gotRuns[0].RunID, "project-1", "project-2"); got != "run-project-2-1-ab" {
+		t.Fatalf("rewriteRunID() = %q", got)
+	}
+}
+
+func TestProjectArchiveRejectsNewerSchema(t *testing.T) {
+	manifest := ArchiveManifest{Schemas: map[string]int{"archive": archiveSchemas["archive"] + 1}}
+	var buf bytes.Buffer
+	if err := writeProjectArchive(context.Background(), &buf, manifest, projectrepo.State{}, nil, t.TempDir()); err != nil {
+		t.Fatalf("writeProjectArchive() error = %v", err)
+	}
+	if _, _, _, err := readProjectArchive(&buf, t.TempDir()); !errors.Is(err, ErrUnsupportedArchive) {
+		t.Fatalf("expected ErrUnsupportedArchive, got %v", err)
+	}
+}
+
+func TestProjectArchiveRejectsEscapingPaths(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	m, _ := json.Marshal(ArchiveManifest{Schemas: archiveSchemas})
+	_ = tw.WriteHeader(&tar.Header{Name: archiveManifestName, Mode: 0o644, Size: int64(len(m))})
+	_, _ = tw.Write(m)
+	_ = tw.WriteHeader(&tar.Header{Name: archiveArtifactsDir + "../evil.json", Mode: 0o644, Size: 2})
+	_, _ = tw.Write([]byte("{}"))
+	_ = tw.Close()
+	_ = gz.Close()
+
+	if _, _, _, err := readProjectArchive(&buf, t.TempDir()); !errors.Is(err, ErrUnsupportedArchive) {
+		t.Fatalf("expected ErrUnsupportedArchive, got %v", err)
+	}
+}
+
+// failingArtifactStore rejects every Put.
+type failingArtifactStore struct{ artifactrepo.Store }
+
+func (failingArtifactStore) Put(context.Context, string, string, []byte) error {
+	return errors.New("store unavailable")
+}
+
+func TestImportProjectCleansUpOnFailure(t *testing.T) {
+	svc := newRestartedService(t)
+	svc.artifact = failingArtifactStore{}
+
+	src := t.TempDir()
+	writeTestFile(t, src, "code_graph.json", `{}`)
+	manifest := ArchiveManifest{Schemas: archiveSchemas, ProjectID: "project-1"}
+	runs := []projectrepo.ProjectArtifact{{ProjectID: "project-1", RunID: "run-project-1-1-ab", Path: "code_graph.json"}}
+	var buf bytes.Buffer
+	if err := writeProjectArchive(context.Background(), &buf, manifest, projectrepo.State{ProjectID: "project-1"}, runs, src); err != nil {
+		t.Fatalf("writeProjectArchive() error = %v", err)
+	}
+
+	if _, err := svc.ImportProject(context.Background(), entity.DemoUserID, &buf); err == nil {
+		t.Fatalf("ImportProject() succeeded, want the store error")
+	}
+	entries, _, _ := svc.ListProjects(context.Background(), entity.DemoUserID)
+	if len(entries) != 0 {
+		t.Fatalf("projects = %+v, want none after a failed import", entries)
+	}
+	if dirs, _ := os.ReadDir(runtimepkg.ArtifactsDir); len(dirs) != 0 {
+		t.Fatalf("artifacts dir has %d entries, want the extracted files removed", len(dirs))
+	}
+}
+
+// rawArchive builds an archive from raw entries, the manifest first.
+func rawArchive(t *testing.T, runs []projectrepo.ProjectArtifact, entries map[string]string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	write := func(name string, content []byte) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, _ := json.Marshal(ArchiveManifest{Schemas: archiveSchemas, ProjectID: "project-1"})
+	write(archiveManifestName, m)
+	r, _ := json.Marshal(runs)
+	write(archiveRunsName, r)
+	for name, content := range entries {
+		write(name, []byte(content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestProjectArchiveRejectsEscapingRunPaths(t *testing.T) {
+	runs := []projectrepo.ProjectArtifact{{RunID: "run-1", Path: "../../etc/passwd"}}
+	buf := rawArchive(t, runs, nil)
+	if _, _, _, err := readProjectArchive(buf, t.TempDir()); !errors.Is(err, ErrUnsupportedArchive) {
+		t.Fatalf("expected ErrUnsupportedArchive, got %v", err)
+	}
+}
+
+func TestProjectArchiveDropsRunsWithoutArtifacts(t *testing.T) {
+	runs := []projectrepo.ProjectArtifact{
+		{RunID: "run-1", Path: "code_graph.json"},
+		{RunID: "run-1", Path: "missing.json"},
+	}
+	buf := rawArchive(t, runs, map[string]string{archiveArtifactsDir + "code_graph.json": "{}"})
+	_, _, got, err := readProjectArchive(buf, t.TempDir())
+	if err != nil {
+		t.Fatalf("readProjectArchive() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Path != "code_graph.json" {
+		t.Fatalf("runs = %+v, want only the extracted artifact", got)
+	}
+}
+
+func TestProjectArchiveRejectsOversizedEntries(t *testing.T) {
+	prevEntry, prevTotal := maxImportEntryBytes, maxImportTotalBytes
+	t.Cleanup(func() { maxImportEntryBytes, maxImportTotalBytes = prevEntry, prevTotal })
+
+	maxImportEntryBytes = 8
+	buf := rawArchive(t, nil, map[string]string{archiveArtifactsDir + "big.json": `{"too":"large"}`})
+	if _, _, _, err := readProjectArchive(buf, t.TempDir()); !errors.Is(err, ErrUnsupportedArchive) {
+		t.Fatalf("per-entry cap: expected ErrUnsupportedArchive, got %v", err)
+	}
+
+	maxImportEntryBytes = prevEntry
+	maxImportTotalBytes = 256
+	entries := map[string]string{
+		archiveArtifactsDir + "a.json": string(bytes.Repeat([]byte("a"), 200)),
+		archiveArtifactsDir + "b.json": string(bytes.Repeat([]byte("b"), 200)),
+	}
+	if _, _, _, err := readProjectArchive(rawArchive(t, nil, entries), t.TempDir()); !errors.Is(err, ErrUnsupportedArchive) {
+		t.Fatalf("total cap: expected ErrUnsupportedArchive, got %v", err)
+	}
+}
+
+// flakyArtifactStore accepts the first failAfter Puts, then fails.
+type flakyArtifactStore struct {
+	artifactrepo.Store
+	failAfter int
+	data      map[string][]byte
+}
+
+func (s *flakyArtifactStore) Put(_ context.Context, runID, path string, content []byte) error {
+	if len(s.data) >= s.failAfter {
+		return errors.New("store unavailable")
+	}
+	s.data[runID+"/"+path] = content
+	return nil
+}
+
+func (s *flakyArtifactStore) Delete(_ context.Context, runID, path string) error {
+	delete(s.data, runID+"/"+path)
+	return nil
+}
+
+func TestImportProjectDeletesStoredArtifactsOnFailure(t *testing.T) {
+	svc := newRestartedService(t)
+	store := &flakyArtifactStore{failAfter: 1, data: map[string][]byte{}}
+	svc.artifact = store
+
+	runs := []projectrepo.ProjectArtifact{
+		{RunID: "run-project-1-1-ab", Path: "a.json"},
+		{RunID: "run-project-1-1-ab", Path: "b.json"},
+	}
+	buf := rawArchive(t, runs, map[string]string{
+		archiveArtifactsDir + "a.json": "{}",
+		archiveArtifactsDir + "b.json": "{}",
+	})
+	if _, err := svc.ImportProject(context.Background(), entity.DemoUserID, buf); err == nil {
+		t.Fatalf("ImportProject() succeeded, want the store error")
+	}
+	if len(store.data) != 0 {
+		t.Fatalf("stored artifacts = %v, want the partial import deleted", store.data)
+	}
+}
@@ -0,0 +1,112 @@
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"insightify/internal/gateway/entity"
+	artifactrepo "insightify/internal/gateway/repository/artifact"
+	projectrepo "insightify/internal/gateway/repository/project"
+)
+
+func TestCompareArtifactSelection(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := []projectrepo.ProjectArtifact{
+		{ID: 1, RunID: "run-1", Path: "code_graph.json", CreatedAt: t0},
+		{ID: 2, RunID: "run-1", Path: "bootstrap_v1.json", CreatedAt: t0},
+		{ID: 3, RunID: "run-2", Path: "bootstrap_v1.json", CreatedAt: t0.Add(time.Minute)},
+		{ID: 4, RunID: "run-2", Path: "bootstrap_v2.json", CreatedAt: t0.Add(time.Minute)},
+		{ID: 5, RunID: "run-3", Path: "code_graph.json", CreatedAt: t0.Add(2 * time.Minute)},
+		{ID: 6, RunID: "run-3", Path: "bootstrap_v2.json", CreatedAt: t0.Add(2 * time.Minute)},
+		{ID: 7, RunID: "run-3", Path: "repos/other/code_graph.json", CreatedAt: t0.Add(2 * time.Minute)},
+	}
+
+	head, ok := runArtifact(list, "run-3", "code_graph")
+	if !ok || head.ID != 5 {
+		t.Fatalf("head = %+v ok=%v, want ID 5", head, ok)
+	}
+	// run-2 has no code_graph, so the previous run is run-1.
+	if prev, ok := previousArtifact(list, head, "code_graph"); !ok || prev.RunID != "run-1" {
+		t.Fatalf("previous code_graph = %+v ok=%v, want run-1", prev, ok)
+	}
+
+	if got, ok := runArtifact(list, "run-2", "bootstrap"); !ok || got.Path != "bootstrap_v2.json" {
+		t.Fatalf("run-2 bootstrap = %+v ok=%v, want highest version", got, ok)
+	}
+	head, _ = runArtifact(list, "run-3", "bootstrap")
+	if prev, ok := previousArtifact(list, head, "bootstrap"); !ok || prev.ID != 4 {
+		t.Fatalf("previous bootstrap = %+v ok=%v, want run-2 v2", prev, ok)
+	}
+
+	first, _ := runArtifact(list, "run-1", "code_graph")
+	if _, ok := previousArtifact(list, first, "code_graph"); ok {
+		t.Fatalf("first run should have no previous artifact")
+	}
+}
+
+// fixedArtifactMeta lists the same artifacts for every project.
+type fixedArtifactMeta struct {
+	projectrepo.ArtifactRepository
+	list []projectrepo.ProjectArtifact
+}
+
+func (m fixedArtifactMeta) ListArtifacts(context.Context, string) ([]projectrepo.ProjectArtifact, error) {
+	return m.list, nil
+}
+
+// noURLArtifactStore has no URLs to sign.
+type noURLArtifactStore struct{ artifactrepo.Store }
+
+func (noURLArtifactStore) GetURL(context.Context, string, string) (string, error) { return "", nil }
+
+func TestListArtifactsReportsWorkerVersions(t *testing.T) {
+	svc := newRestartedService(t, "project-a")
+	svc.artifact = noURLArtifactStore{}
+	svc.metaRepo = fixedArtifactMeta{list: []projectrepo.ProjectArtifact{
+		{ID: 1, RunID: "run-1", Path: "code_graph.json"},
+		{ID: 2, RunID: "run-1", Path: "bootstrap_v1.json"},
+		{ID: 3, RunID: "run-2", Path: "bootstrap_v2.json"},
+	}}
+
+	all, err := svc.ListArtifacts(context.Background(), entity.DemoUserID, "project-a", "")
+	if err != nil || len(all) != 3 {
+		t.Fatalf("ListArtifacts() = %+v, %v, want all three", all, err)
+	}
+	if all[0].Worker != "" || all[2].Worker != "bootstrap" || all[2].Version != 2 {
+		t.Fatalf("artifacts = %+v, want worker/version on the versioned outputs only", all)
+	}
+	boot, err := svc.ListArtifacts(context.Background(), entity.DemoUserID, "project-a", "bootstrap")
+	if err != nil || len(boot) != 2 || boot[0].Version != 1 || boot[1].RunID != "run-2" {
+		t.Fatalf("ListArtifacts(bootstrap) = %+v, %v, want v1 and v2", boot, err)
+	}
+	if _, err := svc.ListArtifacts(context.Background(), "other-user", "project-a", ""); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("ListArtifacts() by another user error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestReadRunArtifactReassemblesParts(t *testing.T) {
+	doc := []byte(`{"nodes":[{"id":"a"},{"id":"b"}]}`)
+	sum := sha256.Sum256(doc)
+	index := fmt.Sprintf(`{"chunked_artifact":1,"size":%d,"digest":"%s","parts":["code_graph.part-1.json","code_graph.part-2.json"]}`, len(doc), hex.EncodeToString(sum[:]))
+	store := &memArtifactStore{}
+	ctx := context.Background()
+	// Parts are named relative to the store the index was saved in.
+	_ = store.Put(ctx, "run-1", "repos/other/code_graph.json", []byte(index))
+	_ = store.Put(ctx, "run-1", "repos/other/code_graph.part-1.json", doc[:12])
+	_ = store.Put(ctx, "run-1", "repos/other/code_graph.part-2.json", doc[12:])
+	_ = store.Put(ctx, "run-1", "code_graph.json", []byte(`{}`))
+	svc := New(nil, nil, store)
+
+	got, err := svc.readRunArtifact(ctx, projectrepo.ProjectArtifact{RunID: "run-1", Path: "repos/other/code_graph.json"})
+	if err != nil || string(got) != string(doc) {
+		t.Fatalf("readRunArtifact() = %s, %v, want the reassembled document", got, err)
+	}
+	if got, err := svc.readRunArtifact(ctx, projectrepo.ProjectArtifact{RunID: "run-1", Path: "code_graph.json"}); err != nil || string(got) != "{}" {
+		t.Fatalf("readRunArtifact() of a plain artifact = %s, %v", got, err)
+	}
+}
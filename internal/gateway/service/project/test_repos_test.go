@@ -0,0 +1,42 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"insightify/internal/common/scan"
+	"insightify/internal/gateway/entity"
+)
+
+func TestSetReposKeepsLocalPathsUnderReposDir(t *testing.T) {
+	svc := newRestartedService(t, "project-a")
+	repos := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repos, "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	prev := scan.ReposDir()
+	scan.SetReposDir(repos)
+	t.Cleanup(func() { scan.SetReposDir(prev) })
+
+	e, err := svc.SetRepos(context.Background(), entity.DemoUserID, "project-a", []entity.RepoEntry{
+		{Name: "api", LocalPath: filepath.Join(repos, "api")},
+	})
+	if err != nil {
+		t.Fatalf("SetRepos() error = %v", err)
+	}
+	if got := e.State.Repos[0].LocalPath; got != filepath.Join(repos, "api") {
+		t.Fatalf("local_path = %q, want the path under the repos dir", got)
+	}
+
+	for _, localPath := range []string{"/etc", filepath.Join(repos, "..")} {
+		_, err := svc.SetRepos(context.Background(), entity.DemoUserID, "project-a", []entity.RepoEntry{
+			{Name: "api", LocalPath: localPath},
+		})
+		if !errors.Is(err, entity.ErrInvalidRepo) {
+			t.Fatalf("SetRepos(local_path=%s) error = %v, want ErrInvalidRepo", localPath, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package project
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	projectcache "insightify/internal/cache/project"
+	"insightify/internal/gateway/entity"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+// newRestartedService returns a service over stored projects that have no
+// run context yet, as after a gateway restart.
+func newRestartedService(t *testing.T, projectIDs ...string) *Service {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("LLM_MODEL_OVERRIDES", `{"*":{"provider":"fake","model":"fake-middle"}}`)
+	mem := projectcache.NewMemoryStore()
+	for _, id := range projectIDs {
+		if err := mem.Put(context.Background(), projectcache.State{ProjectID: id, ProjectName: id, UserID: entity.DemoUserID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return New(mem, mem, nil)
+}
+
+func TestListingDoesNotMaterialize(t *testing.T) {
+	svc := newRestartedService(t, "project-a")
+	if _, err := svc.EnsureRunContext("project-a"); err != nil {
+		t.Fatalf("EnsureRunContext() error = %v", err)
+	}
+
+	// Listing must not wait on run contexts being restored.
+	svc.restoreMu.Lock()
+	done := make(chan []Entry)
+	go func() {
+		entries, _, _ := svc.ListProjects(context.Background(), entity.DemoUserID)
+		_, _ = svc.GetEntry("project-a")
+		done <- entries
+	}()
+	var entries []Entry
+	select {
+	case entries = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("ListProjects blocked on restoring run contexts")
+	}
+	svc.restoreMu.Unlock()
+
+	if len(entries) != 1 || entries[0].RunCtx == nil {
+		t.Fatalf("entries = %+v, want project-a with its run context", entries)
+	}
+	rt := entries[0].RunCtx
+	if rt.Resolver != nil || rt.LLM != nil || rt.Materializations() != 0 {
+		t.Fatalf("listing materialized the run context")
+	}
+	if !svc.hasRequiredWorkers(rt) {
+		t.Fatalf("descriptor should list the required workers")
+	}
+}
+
+func TestConcurrentRunsMaterializeOnce(t *testing.T) {
+	projects := []string{"project-a", "project-b"}
+	svc := newRestartedService(t, projects...)
+
+	const runsPerProject = 4
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		got = map[string][]*runtimepkg.ProjectRuntime{}
+	)
+	stop := make(chan struct{})
+	listed := make(chan int)
+	go func() {
+		n := 0
+		for {
+			select {
+			case <-stop:
+				listed <- n
+				return
+			default:
+			}
+			if _, _, err := svc.ListProjects(context.Background(), entity.DemoUserID); err != nil {
+				t.Errorf("ListProjects() error = %v", err)
+			}
+			n++
+		}
+	}()
+	for _, id := range projects {
+		for range runsPerProject {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rt, err := svc.EnsureRunContext(id)
+				if err == nil {
+					err = rt.Materialize()
+				}
+				if err != nil {
+					t.Errorf("run of %s: %v", id, err)
+					return
+				}
+				mu.Lock()
+				got[id] = append(got[id], rt)
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	close(stop)
+	if n := <-listed; n == 0 {
+		t.Fatalf("ListProjects never completed during the runs")
+	}
+
+	for _, id := range projects {
+		rts := got[id]
+		if len(rts) != runsPerProject {
+			t.Fatalf("%s: %d runs started, want %d", id, len(rts), runsPerProject)
+		}
+		for _, rt := range rts[1:] {
+			if rt != rts[0] {
+				t.Fatalf("%s: runs got different run contexts", id)
+			}
+		}
+		if n := rts[0].Materializations(); n != 1 || rts[0].Resolver == nil {
+			t.Fatalf("%s: materialized %d times, want once", id, n)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"insightify/internal/artifactsearch"
+	projectrepo "insightify/internal/gateway/repository/project"
+)
+
+type memArtifactMeta struct {
+	mu   sync.Mutex
+	list []projectrepo.ProjectArtifact
+}
+
+func (m *memArtifactMeta) AddArtifact(_ context.Context, a projectrepo.ProjectArtifact) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a.ID = len(m.list) + 1
+	m.list = append(m.list, a)
+	return nil
+}
+
+func (m *memArtifactMeta) ListArtifacts(_ context.Context, projectID string) ([]projectrepo.ProjectArtifact, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []projectrepo.ProjectArtifact
+	for _, a := range m.list {
+		if a.ProjectID == projectID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+type memArtifactStore struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	reads int
+}
+
+func (s *memArtifactStore) Put(_ context.Context, runID, path string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files == nil {
+		s.files = map[string][]byte{}
+	}
+	s.files[runID+"/"+path] = content
+	return nil
+}
+
+func (s *memArtifactStore) Get(_ context.Context, runID, path string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	return s.files[runID+"/"+path], nil
+}
+
+func (s *memArtifactStore) GetURL(context.Context, string, string) (string, error) { return "", nil }
+func (s *memArtifactStore) List(context.Context, string) ([]string, error)         { return nil, nil }
+
+func syncFixture(t *testing.T, meta *memArtifactMeta, store *memArtifactStore, runID, path, fixture string, at time.Time) {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("..", "..", "..", "artifactsearch", "testdata", fixture))
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", fixture, err)
+	}
+	_ = store.Put(context.Background(), runID, path, raw)
+	_ = meta.AddArtifact(context.Background(), projectrepo.ProjectArtifact{ProjectID: "project-1", RunID: runID, Path: path, CreatedAt: at})
+}
+
+func TestSearchIndexInvalidatedOnArtifactUpdate(t *testing.T) {
+	meta := &memArtifactMeta{}
+	store := &memArtifactStore{}
+	svc := New(nil, meta, store)
+	ctx := context.Background()
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	syncFixture(t, meta, store, "run-1", "arch_design.json", "arch_design.json", t0)
+	syncFixture(t, meta, store, "run-1", "infra_context.json", "infra_context.json", t0)
+
+	idx, err := svc.searchIndex(ctx, "project-1")
+	if err != nil {
+		t.Fatalf("searchIndex() error = %v", err)
+	}
+	if res := idx.Search(searchQuery("token bucket")); res.Total != 2 {
+		t.Fatalf("before update Total = %d, want component and gap", res.Total)
+	}
+	reads := store.reads
+	again, _ := svc.searchIndex(ctx, "project-1")
+	if again != idx || store.reads != reads {
+		t.Fatalf("unchanged artifacts should reuse the index")
+	}
+
+	// A later run syncs code_symbols; the index picks it up.
+	syncFixture(t, meta, store, "run-2", "code_symbols_v1.json", "code_symbols.json", t0.Add(time.Minute))
+	idx, _ = svc.searchIndex(ctx, "project-1")
+	res := idx.Search(searchQuery("token bucket"))
+	if res.Total != 6 {
+		t.Fatalf("after update Total = %d, want 6", res.Total)
+	}
+	if top := res.Hits[0]; top.Key != "code_symbols" || top.RunID != "run-2" || top.Path != "code_symbols_v1.json" || top.Pointer != "/files/0/identifiers/0" {
+		t.Fatalf("top hit = %+v", top)
+	}
+
+	// Re-syncing a newer arch_design without the component drops its hit.
+	_ = store.Put(ctx, "run-3", "arch_design.json", []byte(`{"architecture_hypothesis":{"key_components":[{"name":"Worker"}]}}`))
+	_ = meta.AddArtifact(ctx, projectrepo.ProjectArtifact{ProjectID: "project-1", RunID: "run-3", Path: "arch_design.json", CreatedAt: t0.Add(2 * time.Minute)})
+	idx, _ = svc.searchIndex(ctx, "project-1")
+	for _, h := range idx.Search(searchQuery("token bucket")).Hits {
+		if h.Key == "arch_design" {
+			t.Fatalf("stale arch_design hit after update: %+v", h)
+		}
+	}
+}
+
+func searchQuery(text string) artifactsearch.Query {
+	return artifactsearch.Query{Text: text}
+}
@@ -0,0 +1,156 @@
+package userinteraction
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// BackpressurePolicy decides what a subscription does when its consumer
+// falls behind and the event buffer is full.
+type BackpressurePolicy string
+
+const (
+	// BackpressureBlock waits up to the send timeout for the consumer. A
+	// consumer that stays stuck longer has its subscription closed; it can
+	// resume losslessly with SubscribeFrom and its last seen Seq.
+	BackpressureBlock BackpressurePolicy = "block"
+	// BackpressureDropOldest never waits: the oldest buffered event is
+	// discarded and a SubscriptionEventDropped marker carrying the number of
+	// discarded events is queued ahead of the next delivered event.
+	BackpressureDropOldest BackpressurePolicy = "drop_oldest"
+)
+
+// Defaults for SetBackpressure.
+const (
+	DefaultBackpressure          = BackpressureBlock
+	DefaultSubscriberSendTimeout = 30 * time.Second
+)
+
+// subscriptionBufferSize is the per-subscription event buffer; it must leave
+// room for a drop marker and the event behind it.
+const subscriptionBufferSize = 8
+
+// ParseBackpressurePolicy maps a config value to a policy; empty means
+// DefaultBackpressure.
+func ParseBackpressurePolicy(v string) (BackpressurePolicy, error) {
+	switch p := BackpressurePolicy(strings.ToLower(strings.TrimSpace(v))); p {
+	case "":
+		return DefaultBackpressure, nil
+	case BackpressureBlock, BackpressureDropOldest:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown backpressure policy %q", v)
+	}
+}
+
+// SetBackpressure sets the policy for subscriptions started afterwards.
+// timeout bounds a blocked send under BackpressureBlock; zero keeps the
+// current value.
+func (s *Service) SetBackpressure(policy BackpressurePolicy, timeout time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if policy != "" {
+		s.backpressure = policy
+	}
+	if timeout > 0 {
+		s.sendTimeout = timeout
+	}
+}
+
+// DroppedEvents returns how many subscription events were discarded under
+// BackpressureDropOldest since the service started.
+func (s *Service) DroppedEvents() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.dropped.Load()
+}
+
+// eventSender delivers events to one subscription channel under a policy.
+// It is the only writer of out.
+type eventSender struct {
+	out     chan *SubscriptionEvent
+	policy  BackpressurePolicy
+	timeout time.Duration
+	total   *atomic.Int64
+	pending int64 // dropped events not yet reported by a marker
+}
+
+func (s *Service) newEventSender() *eventSender {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := &eventSender{
+		out:     make(chan *SubscriptionEvent, subscriptionBufferSize),
+		policy:  s.backpressure,
+		timeout: s.sendTimeout,
+		total:   &s.dropped,
+	}
+	if e.policy == "" {
+		e.policy = DefaultBackpressure
+	}
+	if e.timeout <= 0 {
+		e.timeout = DefaultSubscriberSendTimeout
+	}
+	return e
+}
+
+// send delivers evt and reports whether the subscription should go on.
+func (e *eventSender) send(ctx context.Context, evt *SubscriptionEvent) bool {
+	if e.policy == BackpressureDropOldest {
+		e.sendDropOldest(evt)
+		return ctx.Err() == nil
+	}
+	select {
+	case e.out <- evt:
+		return true
+	default:
+	}
+	timer := time.NewTimer(e.timeout)
+	defer timer.Stop()
+	select {
+	case e.out <- evt:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return false
+	}
+}
+
+// sendDropOldest evicts the oldest buffered events until evt, preceded by a
+// marker when drops are unreported, fits. An evicted marker's count rolls
+// into the next one so every drop is reported exactly once.
+func (e *eventSender) sendDropOldest(evt *SubscriptionEvent) {
+	for {
+		// The consumer only ever frees slots, so a length check here is
+		// conservative.
+		need := 1
+		if e.pending > 0 {
+			need = 2
+		}
+		if cap(e.out)-len(e.out) >= need {
+			if e.pending > 0 {
+				e.out <- &SubscriptionEvent{Kind: SubscriptionEventDropped, Dropped: e.pending}
+				e.pending = 0
+			}
+			e.out <- evt
+			return
+		}
+		select {
+		case old := <-e.out:
+			if old.Kind == SubscriptionEventDropped {
+				e.pending += old.Dropped
+				continue
+			}
+			e.pending++
+			e.total.Add(1)
+		default:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package userinteraction
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultSessionIdleTTL is how long a session may go without activity before
+// SweepIdle removes it.
+const DefaultSessionIdleTTL = 30 * time.Minute
+
+// ErrSessionExpired is returned by a pending WaitForInput whose session was
+// removed for inactivity.
+var ErrSessionExpired = errors.New("interaction session expired")
+
+// SetSessionIdleTTL sets the idle time after which SweepIdle removes a
+// session. Zero keeps sessions forever.
+func (s *Service) SetSessionIdleTTL(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idleTTL = max(0, d)
+}
+
+// SessionCount returns the number of tracked interaction sessions.
+func (s *Service) SessionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.state)
+}
+
+// SweepIdle removes sessions idle for the TTL or longer and returns how many
+// were removed. Sessions with a live subscriber are kept. A run blocked in
+// WaitForInput on a removed session gets ErrSessionExpired.
+func (s *Service) SweepIdle(now time.Time) int {
+	type expiredSession struct{ runID, nodeID, interactionID string }
+	var waiting []expiredSession
+
+	s.mu.Lock()
+	if s.idleTTL <= 0 {
+		s.mu.Unlock()
+		return 0
+	}
+	removed := 0
+	for key, st := range s.state {
+		if st.subscribers > 0 || now.Sub(st.updatedAt) < s.idleTTL {
+			continue
+		}
+		if st.waiting {
+			runID, nodeID, _ := strings.Cut(key, "|")
+			waiting = append(waiting, expiredSession{runID, nodeID, st.interactionID})
+		}
+		st.expired = true
+		st.closed = true
+		st.waiting = false
+		notifyLocked(st)
+		delete(s.state, key)
+		removed++
+	}
+	syncer := s.uiSync
+	s.mu.Unlock()
+
+	if syncer != nil {
+		for _, e := range waiting {
+			_ = syncer.OnWaiting(context.Background(), e.runID, e.nodeID, e.interactionID, false)
+		}
+	}
+	return removed
+}
+
+// StartSweeper calls SweepIdle every interval until ctx is done, and applies
+// the conversation retention when a conversation store is set.
+func (s *Service) StartSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.SweepIdle(now)
+				s.pruneConversations(ctx, now)
+			}
+		}
+	}()
+}
@@ -0,0 +1,291 @@
+package userinteraction
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	logctx "insightify/internal/common/logctx"
+	conversationrepo "insightify/internal/gateway/repository/conversation"
+)
+
+// Page sizes for History.
+const (
+	DefaultHistoryLimit = 100
+	MaxHistoryLimit     = 500
+)
+
+// DefaultConversationFlushDelay is how long new messages of a session wait
+// before they are written to the conversation store, so a streamed reply
+// costs one write instead of one per chunk.
+const DefaultConversationFlushDelay = 250 * time.Millisecond
+
+// HistoryPage is one window of a session transcript, oldest first. More
+// reports that later messages exist; pass the last Seq as afterSeq to read
+// them.
+type HistoryPage struct {
+	Messages []conversationrepo.Message
+	More     bool
+}
+
+// SetConversationStore makes transcripts durable: messages are written to
+// store in batches off the request path (see FlushConversations), History
+// reads from it, and a session first touched after a restart resumes from its
+// stored transcript. Without a store transcripts
+// live only in memory (and in the conversation artifact snapshot).
+func (s *Service) SetConversationStore(store conversationrepo.Store, retention conversationrepo.Retention) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.convStore = store
+	s.convRetention = retention
+}
+
+// History returns up to limit transcript messages of run+node with
+// seq > afterSeq.
+func (s *Service) History(ctx context.Context, runID, nodeID string, afterSeq int64, limit int) (HistoryPage, error) {
+	runID = strings.TrimSpace(runID)
+	nodeID = strings.TrimSpace(nodeID)
+	if runID == "" || nodeID == "" {
+		return HistoryPage{}, fmt.Errorf("run_id and node_id are required")
+	}
+	if afterSeq < 0 {
+		return HistoryPage{}, fmt.Errorf("after_seq must not be negative")
+	}
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	limit = min(limit, MaxHistoryLimit)
+
+	s.mu.Lock()
+	store := s.convStore
+	if store == nil {
+		defer s.mu.Unlock()
+		page := HistoryPage{Messages: []conversationrepo.Message{}}
+		st, ok := s.state[sessionKey(runID, nodeID)]
+		if !ok {
+			return page, nil
+		}
+		for _, m := range st.conversation {
+			if int64(m.Seq) <= afterSeq {
+				continue
+			}
+			if len(page.Messages) == limit {
+				page.More = true
+				break
+			}
+			page.Messages = append(page.Messages, storedMessage(m))
+		}
+		return page, nil
+	}
+	s.mu.Unlock()
+
+	// Read this session's own unflushed messages back.
+	s.flushConversation(ctx, runID, nodeID)
+	page, err := store.List(ctx, runID, nodeID, afterSeq, limit)
+	if err != nil {
+		return HistoryPage{}, fmt.Errorf("failed to read conversation history: %w", err)
+	}
+	if page.Messages == nil {
+		page.Messages = []conversationrepo.Message{}
+	}
+	return HistoryPage{Messages: page.Messages, More: page.More}, nil
+}
+
+// loadConversation restores the stored transcript of a session the first
+// time it is touched, so new messages continue its seq and Subscribe replays
+// its assistant messages after a restart.
+func (s *Service) loadConversation(ctx context.Context, runID, nodeID string) {
+	s.mu.Lock()
+	store := s.convStore
+	st := s.getOrCreateLocked(runID, nodeID)
+	if store == nil || st.loaded {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+
+	page, err := store.List(ensureContext(ctx), runID, nodeID, 0, 0)
+	if err != nil {
+		logctx.Error(ctx, "load conversation history failed", err, "run_id", runID, "node_id", nodeID)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st.loaded {
+		return
+	}
+	st.loaded = true
+	if len(page.Messages) == 0 {
+		return
+	}
+	restored := make([]conversationMessage, 0, len(page.Messages)+len(st.conversation))
+	for _, m := range page.Messages {
+		restored = append(restored, conversationMessage{
+			Seq:             int(m.Seq),
+			Role:            m.Role,
+			Content:         m.Content,
+			InteractionID:   m.InteractionID,
+			CreatedAtUnixMs: m.CreatedAt.UnixMilli(),
+		})
+	}
+	st.storedLen = len(restored)
+	st.storedTail = len(restored[len(restored)-1].Content)
+	// Messages added while the transcript was loading come after it.
+	for _, m := range st.conversation {
+		m.Seq = restored[len(restored)-1].Seq + 1
+		restored = append(restored, m)
+	}
+	st.conversation = restored
+	if st.lastSeq == 0 {
+		for _, m := range page.Messages {
+			if m.Role != "assistant" {
+				continue
+			}
+			st.lastSeq++
+			appendOutputLocked(st, outputMessage{
+				seq:           st.lastSeq,
+				interactionID: m.InteractionID,
+				message:       m.Content,
+				at:            m.CreatedAt,
+			})
+		}
+		st.deliveredSeq = st.lastSeq
+	}
+}
+
+// scheduleConversationFlush writes the new messages of run+node to the
+// conversation store after convFlushDelay, together with any others added
+// meanwhile. The request that added them does not wait for the write.
+func (s *Service) scheduleConversationFlush(ctx context.Context, runID, nodeID string) {
+	s.mu.Lock()
+	st, ok := s.state[sessionKey(runID, nodeID)]
+	if s.convStore == nil || !ok || st.flushPending {
+		s.mu.Unlock()
+		return
+	}
+	st.flushPending = true
+	delay := s.convFlushDelay
+	s.mu.Unlock()
+
+	// The request may be over by then; keep only its values.
+	ctx = context.WithoutCancel(ensureContext(ctx))
+	time.AfterFunc(delay, func() { s.flushSession(ctx, runID, nodeID, st) })
+}
+
+// FlushConversations writes every session's unflushed messages to the
+// conversation store now, e.g. before shutdown.
+func (s *Service) FlushConversations(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	type session struct {
+		runID, nodeID string
+		st            *sessionState
+	}
+	s.mu.Lock()
+	var sessions []session
+	if s.convStore != nil {
+		for key, st := range s.state {
+			runID, nodeID, _ := strings.Cut(key, "|")
+			sessions = append(sessions, session{runID, nodeID, st})
+		}
+	}
+	s.mu.Unlock()
+	for _, ss := range sessions {
+		s.flushSession(ctx, ss.runID, ss.nodeID, ss.st)
+	}
+}
+
+// flushConversation writes the unflushed messages of run+node now.
+func (s *Service) flushConversation(ctx context.Context, runID, nodeID string) {
+	s.mu.Lock()
+	st, ok := s.state[sessionKey(runID, nodeID)]
+	s.mu.Unlock()
+	if ok {
+		s.flushSession(ctx, runID, nodeID, st)
+	}
+}
+
+// flushSession writes the messages of st added or grown since the last
+// flush to the conversation store. Flushes of one session are serialized so
+// a slower writer never overwrites newer content.
+func (s *Service) flushSession(ctx context.Context, runID, nodeID string, st *sessionState) {
+	s.mu.Lock()
+	store := s.convStore
+	st.flushPending = false
+	s.mu.Unlock()
+	if store == nil {
+		return
+	}
+	st.storeMu.Lock()
+	defer st.storeMu.Unlock()
+
+	s.mu.Lock()
+	from := st.storedLen
+	if from > 0 && from <= len(st.conversation) && len(st.conversation[from-1].Content) != st.storedTail {
+		from-- // the last stored message is a stream that grew
+	}
+	var pending []conversationrepo.Message
+	for _, m := range st.conversation[min(from, len(st.conversation)):] {
+		pending = append(pending, storedMessage(m))
+	}
+	n := len(st.conversation)
+	tail := 0
+	if n > 0 {
+		tail = len(st.conversation[n-1].Content)
+	}
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := store.Append(ensureContext(ctx), runID, nodeID, pending...); err != nil {
+		logctx.Error(ctx, "persist conversation history failed", err, "run_id", runID, "node_id", nodeID)
+		return
+	}
+	s.mu.Lock()
+	st.storedLen = n
+	st.storedTail = tail
+	s.mu.Unlock()
+}
+
+// pruneConversations applies the conversation retention to the store.
+func (s *Service) pruneConversations(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	store := s.convStore
+	retention := s.convRetention
+	s.mu.Unlock()
+	if store == nil || (retention.MaxMessages <= 0 && retention.MaxAge <= 0) {
+		return
+	}
+	removed, err := store.Prune(ctx, retention, now)
+	if err != nil {
+		logctx.Error(ctx, "prune conversation history failed", err)
+		return
+	}
+	if removed > 0 {
+		logctx.Info(ctx, "conversation history pruned", "removed", removed)
+	}
+}
+
+func storedMessage(m conversationMessage) conversationrepo.Message {
+	return conversationrepo.Message{
+		Seq:           int64(m.Seq),
+		Role:          m.Role,
+		Content:       m.Content,
+		InteractionID: m.InteractionID,
+		CreatedAt:     time.UnixMilli(m.CreatedAtUnixMs),
+	}
+}
+
+func ensureContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
@@ -0,0 +1,39 @@
+package userinteraction
+
+import (
+	"context"
+	"strings"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+)
+
+// SendOnce is Send with a client-supplied nonce. A retried submit carrying a
+// nonce the session already accepted gets the original response and is not
+// delivered again, even when the run is by then waiting on a new input. An
+// empty nonce behaves like Send.
+func (s *Service) SendOnce(ctx context.Context, req *insightifyv1.SendRequest, nonce string) (*insightifyv1.SendResponse, error) {
+	return s.send(ctx, req, strings.TrimSpace(nonce))
+}
+
+// Clear drops every interaction session of runID, including its accepted
+// nonces. Pending WaitForInput calls of the run return ErrSessionExpired.
+func (s *Service) Clear(runID string) {
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := sessionKey(runID, "")
+	for key, st := range s.state {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		st.expired = true
+		st.closed = true
+		st.waiting = false
+		st.nonces = nil
+		notifyLocked(st)
+		delete(s.state, key)
+	}
+}
@@ -4,20 +4,40 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	insightifyv1 "insightify/gen/go/insightify/v1"
 	artifactrepo "insightify/internal/gateway/repository/artifact"
+	conversationrepo "insightify/internal/gateway/repository/conversation"
 )
 
 const defaultConversationArtifactPath = "interaction/conversation_history.json"
 
+// defaultChunkCoalesceWindow merges assistant chunks for the same interaction
+// that arrive within this interval into one event.
+const defaultChunkCoalesceWindow = 100 * time.Millisecond
+
 type Service struct {
 	mu                       sync.Mutex
 	state                    map[string]*sessionState
 	artifact                 artifactrepo.Store
 	conversationArtifactPath string
 	uiSync                   UISync
+	chunkCoalesceWindow      time.Duration
+	// idleTTL expires sessions without activity; see SweepIdle.
+	idleTTL time.Duration
+	// backpressure and sendTimeout apply to new subscriptions; dropped counts
+	// events discarded under BackpressureDropOldest.
+	backpressure BackpressurePolicy
+	sendTimeout  time.Duration
+	dropped      atomic.Int64
+	// convStore, when set, keeps transcripts across restarts; see
+	// SetConversationStore.
+	convStore     conversationrepo.Store
+	convRetention conversationrepo.Retention
+	// convFlushDelay batches store writes; see scheduleConversationFlush.
+	convFlushDelay time.Duration
 }
 
 // UISync updates UiDocument from interaction events on the core side.
@@ -32,18 +52,29 @@ type SubscriptionEventKind string
 const (
 	SubscriptionEventWaitState        SubscriptionEventKind = "wait_state"
 	SubscriptionEventAssistantMessage SubscriptionEventKind = "assistant_message"
+	SubscriptionEventAssistantChunk   SubscriptionEventKind = "assistant_chunk"
+	// SubscriptionEventDropped reports that Dropped earlier events were
+	// discarded because the subscriber fell behind.
+	SubscriptionEventDropped SubscriptionEventKind = "events_dropped"
 )
 
+// SubscriptionEvent is one update for a subscriber. Assistant events carry a
+// per-session Seq that increases monotonically; wait-state events have Seq 0.
 type SubscriptionEvent struct {
 	Kind             SubscriptionEventKind
+	Seq              int64
 	WaitState        *insightifyv1.WaitResponse
 	InteractionID    string
 	AssistantMessage string
+	Dropped          int64
 }
 
 type outputMessage struct {
+	seq           int64
 	interactionID string
 	message       string
+	chunk         bool
+	at            time.Time
 }
 
 type conversationMessage struct {
@@ -65,10 +96,26 @@ type sessionState struct {
 	closed        bool
 	waiting       bool
 	inputQueue    []string
-	outputQueue   []outputMessage
+	outputs       []outputMessage // ordered by seq
+	lastSeq       int64
+	deliveredSeq  int64 // highest seq handed to any subscriber; never coalesced into
+	streaming     bool  // last conversation message is an open assistant chunk stream
 	conversation  []conversationMessage
 	changed       chan struct{}
 	updatedAt     time.Time
+	subscribers   int  // live Subscribe goroutines; keep the session from expiring
+	expired       bool // removed by SweepIdle; pending waits fail with ErrSessionExpired
+	// nonces records the response of each accepted client nonce; see SendOnce.
+	nonces map[string]*insightifyv1.SendResponse
+	// loaded, storedLen and storedTail track the conversation store: whether
+	// the stored transcript was restored, how many messages were written and
+	// the content length of the last one. storeMu serializes flushes;
+	// flushPending marks a scheduled one.
+	loaded       bool
+	storedLen    int
+	storedTail   int
+	storeMu      sync.Mutex
+	flushPending bool
 }
 
 func (s *Service) waitResponseFromStateLocked(st *sessionState) *insightifyv1.WaitResponse {
@@ -88,8 +135,9 @@ func (s *Service) getOrCreateLocked(runID, nodeID string) *sessionState {
 		return st
 	}
 	st := &sessionState{
-		waiting: false,
-		changed: make(chan struct{}),
+		waiting:   false,
+		changed:   make(chan struct{}),
+		updatedAt: time.Now(),
 	}
 	s.state[key] = st
 	return st
@@ -107,25 +155,40 @@ func notifyLocked(st *sessionState) {
 	st.changed = make(chan struct{})
 }
 
-func pushEvent(out chan *SubscriptionEvent, state *SubscriptionEvent) {
-	if out == nil || state == nil {
-		return
-	}
-	select {
-	case out <- state:
-		return
-	default:
-	}
-	select {
-	case <-out:
-	default:
-	}
-	select {
-	case out <- state:
-	default:
+// nextConversationSeqLocked returns the seq of the next transcript message.
+func nextConversationSeqLocked(st *sessionState) int {
+	if n := len(st.conversation); n > 0 {
+		return st.conversation[n-1].Seq + 1
 	}
+	return 1
 }
 
 func newInteractionID() string {
 	return fmt.Sprintf("interaction-%d", time.Now().UnixNano())
 }
+
+// maxSessionOutputs caps the assistant outputs a session keeps for
+// subscribers to replay; older ones stay readable through History.
+const maxSessionOutputs = 256
+
+// appendOutputLocked queues an assistant output for subscribers, dropping the
+// oldest ones beyond maxSessionOutputs.
+func appendOutputLocked(st *sessionState, msg outputMessage) {
+	st.outputs = append(st.outputs, msg)
+	if n := len(st.outputs) - maxSessionOutputs; n > 0 {
+		st.outputs = append([]outputMessage(nil), st.outputs[n:]...)
+	}
+}
+
+// outputsAfterLocked returns outputs with seq > after and marks them delivered.
+func outputsAfterLocked(st *sessionState, after int64) []outputMessage {
+	i := len(st.outputs)
+	for i > 0 && st.outputs[i-1].seq > after {
+		i--
+	}
+	out := append([]outputMessage(nil), st.outputs[i:]...)
+	if n := len(out); n > 0 && out[n-1].seq > st.deliveredSeq {
+		st.deliveredSeq = out[n-1].seq
+	}
+	return out
+}
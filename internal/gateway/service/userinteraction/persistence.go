@@ -3,8 +3,9 @@ package userinteraction
 import (
 	"context"
 	"encoding/json"
-	"log"
 	"strings"
+
+	logctx "insightify/internal/common/logctx"
 )
 
 func (s *Service) conversationArtifactPathForNode(nodeID string) string {
@@ -26,7 +27,7 @@ func (s *Service) buildConversationSnapshotLocked(runID, nodeID string, st *sess
 	}
 	raw, err := json.MarshalIndent(doc, "", "  ")
 	if err != nil {
-		log.Printf("conversation snapshot marshal failed for run %s node %s: %v", runID, nodeID, err)
+		logctx.Error(context.Background(), "conversation snapshot marshal failed", err, "run_id", runID, "node_id", nodeID)
 		return nil
 	}
 	return raw
@@ -41,6 +42,6 @@ func (s *Service) persistConversation(ctx context.Context, runID, nodeID string,
 	}
 	path := s.conversationArtifactPathForNode(nodeID)
 	if err := s.artifact.Put(ctx, runID, path, raw); err != nil {
-		log.Printf("persist conversation artifact failed for run %s node %s: %v", runID, nodeID, err)
+		logctx.Error(ctx, "persist conversation artifact failed", err, "run_id", runID, "node_id", nodeID)
 	}
 }
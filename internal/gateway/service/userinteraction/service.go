@@ -19,9 +19,25 @@ func New(artifact artifactrepo.Store, conversationArtifactPath string) *Service
 		state:                    make(map[string]*sessionState),
 		artifact:                 artifact,
 		conversationArtifactPath: path,
+		chunkCoalesceWindow:      defaultChunkCoalesceWindow,
+		idleTTL:                  DefaultSessionIdleTTL,
+		backpressure:             DefaultBackpressure,
+		sendTimeout:              DefaultSubscriberSendTimeout,
+		convFlushDelay:           DefaultConversationFlushDelay,
 	}
 }
 
+// SetChunkCoalesceWindow sets how close together assistant chunks must arrive
+// to be merged into one event. Zero disables coalescing.
+func (s *Service) SetChunkCoalesceWindow(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunkCoalesceWindow = max(0, d)
+}
+
 func (s *Service) SetUISync(sync UISync) {
 	if s == nil {
 		return
@@ -92,17 +108,68 @@ func (s *Service) Snapshot(runID, nodeID string) (*insightifyv1.WaitResponse, er
 	return s.waitResponseFromStateLocked(st), nil
 }
 
-// Subscribe emits interaction updates for a run until ctx is canceled.
+// ResolveInteraction finds the run and node that own interactionID.
+// An interaction shared by more than one session is rejected as ambiguous.
+func (s *Service) ResolveInteraction(interactionID string) (runID, nodeID string, err error) {
+	interactionID = strings.TrimSpace(interactionID)
+	if interactionID == "" {
+		return "", "", fmt.Errorf("interaction_id is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matches []string
+	for key, st := range s.state {
+		if st != nil && st.interactionID == interactionID {
+			matches = append(matches, key)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", "", fmt.Errorf("interaction not found: %s", interactionID)
+	case 1:
+		runID, nodeID, _ = strings.Cut(matches[0], "|")
+		return runID, nodeID, nil
+	default:
+		return "", "", fmt.Errorf("interaction %s is ambiguous: %d sessions match", interactionID, len(matches))
+	}
+}
+
+// Subscribe emits interaction updates for a run until ctx is canceled,
+// replaying every assistant output from the start of the session. A consumer
+// that falls behind is handled by the service's BackpressurePolicy.
 func (s *Service) Subscribe(ctx context.Context, runID, nodeID string) (<-chan *SubscriptionEvent, error) {
+	return s.SubscribeFrom(ctx, runID, nodeID, 0)
+}
+
+// SubscribeFrom is Subscribe for a reconnecting client: assistant outputs with
+// seq <= fromSeq are never re-sent.
+func (s *Service) SubscribeFrom(ctx context.Context, runID, nodeID string, fromSeq int64) (<-chan *SubscriptionEvent, error) {
 	runID = strings.TrimSpace(runID)
 	nodeID = strings.TrimSpace(nodeID)
 	if runID == "" || nodeID == "" {
 		return nil, fmt.Errorf("run_id and node_id are required")
 	}
-	out := make(chan *SubscriptionEvent, 8)
+	if fromSeq < 0 {
+		return nil, fmt.Errorf("from_seq must not be negative")
+	}
+	s.loadConversation(ctx, runID, nodeID)
+	sender := s.newEventSender()
+	out := sender.out
+
+	s.mu.Lock()
+	sub := s.getOrCreateLocked(runID, nodeID)
+	sub.subscribers++
+	s.mu.Unlock()
 
 	go func() {
 		defer close(out)
+		defer func() {
+			s.mu.Lock()
+			sub.subscribers--
+			sub.updatedAt = time.Now()
+			s.mu.Unlock()
+		}()
+		cursor := fromSeq
 		for {
 			s.mu.Lock()
 			st := s.getOrCreateLocked(runID, nodeID)
@@ -111,21 +178,30 @@ func (s *Service) Subscribe(ctx context.Context, runID, nodeID string) (<-chan *
 			}
 			st.updatedAt = time.Now()
 			state := s.waitResponseFromStateLocked(st)
-			outputs := append([]outputMessage(nil), st.outputQueue...)
-			st.outputQueue = nil
+			outputs := outputsAfterLocked(st, cursor)
 			ch := st.changed
 			s.mu.Unlock()
 
-			pushEvent(out, &SubscriptionEvent{
+			if !sender.send(ctx, &SubscriptionEvent{
 				Kind:      SubscriptionEventWaitState,
 				WaitState: state,
-			})
+			}) {
+				return
+			}
 			for _, outMsg := range outputs {
-				pushEvent(out, &SubscriptionEvent{
-					Kind:             SubscriptionEventAssistantMessage,
+				kind := SubscriptionEventAssistantMessage
+				if outMsg.chunk {
+					kind = SubscriptionEventAssistantChunk
+				}
+				if !sender.send(ctx, &SubscriptionEvent{
+					Kind:             kind,
+					Seq:              outMsg.seq,
 					InteractionID:    outMsg.interactionID,
 					AssistantMessage: outMsg.message,
-				})
+				}) {
+					return
+				}
+				cursor = outMsg.seq
 			}
 
 			select {
@@ -173,6 +249,41 @@ func (s *Service) Close(_ context.Context, req *insightifyv1.CloseRequest) (*ins
 	}, nil
 }
 
+// CloseRun closes every interaction session of runID so pending
+// WaitForInput calls return, e.g. when the run is interrupted by shutdown.
+func (s *Service) CloseRun(runID string) {
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return
+	}
+	type closedSession struct{ nodeID, interactionID string }
+	var closed []closedSession
+
+	s.mu.Lock()
+	prefix := sessionKey(runID, "")
+	for key, st := range s.state {
+		if !strings.HasPrefix(key, prefix) || st.closed {
+			continue
+		}
+		wasWaiting := st.waiting
+		st.closed = true
+		st.waiting = false
+		st.updatedAt = time.Now()
+		notifyLocked(st)
+		if wasWaiting {
+			closed = append(closed, closedSession{strings.TrimPrefix(key, prefix), st.interactionID})
+		}
+	}
+	syncer := s.uiSync
+	s.mu.Unlock()
+
+	if syncer != nil {
+		for _, c := range closed {
+			_ = syncer.OnWaiting(context.Background(), runID, c.nodeID, c.interactionID, false)
+		}
+	}
+}
+
 // WaitForInput blocks until a new user input for runID is available.
 func (s *Service) WaitForInput(ctx context.Context, runID, nodeID string) (string, error) {
 	runID = strings.TrimSpace(runID)
@@ -180,6 +291,7 @@ func (s *Service) WaitForInput(ctx context.Context, runID, nodeID string) (strin
 	if runID == "" || nodeID == "" {
 		return "", fmt.Errorf("run_id and node_id are required")
 	}
+	var cur *sessionState
 	for {
 		var (
 			syncer     UISync
@@ -189,7 +301,12 @@ func (s *Service) WaitForInput(ctx context.Context, runID, nodeID string) (strin
 			emitWaitOn bool
 		)
 		s.mu.Lock()
+		if cur != nil && cur.expired {
+			s.mu.Unlock()
+			return "", ErrSessionExpired
+		}
 		st := s.getOrCreateLocked(runID, nodeID)
+		cur = st
 		if st.interactionID == "" {
 			st.interactionID = newInteractionID()
 		}
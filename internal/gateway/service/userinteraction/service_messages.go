@@ -7,7 +7,10 @@ import (
 	"time"
 
 	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/artifact"
 	logctx "insightify/internal/common/logctx"
+
+	"google.golang.org/protobuf/proto"
 )
 
 // PublishOutput enqueues a server assistant message for run+node.
@@ -22,6 +25,7 @@ func (s *Service) PublishOutput(ctx context.Context, runID, nodeID, interactionI
 	if message == "" {
 		return fmt.Errorf("message is required")
 	}
+	s.loadConversation(ctx, runID, nodeID)
 
 	var (
 		snapshot   []byte
@@ -40,12 +44,16 @@ func (s *Service) PublishOutput(ctx context.Context, runID, nodeID, interactionI
 	if st.interactionID == "" {
 		st.interactionID = newInteractionID()
 	}
-	st.outputQueue = append(st.outputQueue, outputMessage{
+	st.lastSeq++
+	appendOutputLocked(st, outputMessage{
+		seq:           st.lastSeq,
 		interactionID: st.interactionID,
 		message:       message,
+		at:            time.Now(),
 	})
+	st.streaming = false
 	st.conversation = append(st.conversation, conversationMessage{
-		Seq:             len(st.conversation) + 1,
+		Seq:             nextConversationSeqLocked(st),
 		Role:            "assistant",
 		Content:         message,
 		InteractionID:   st.interactionID,
@@ -62,6 +70,7 @@ func (s *Service) PublishOutput(ctx context.Context, runID, nodeID, interactionI
 	s.mu.Unlock()
 
 	s.persistConversation(ctx, runID, nodeID, snapshot)
+	s.scheduleConversationFlush(ctx, runID, nodeID)
 	if syncer != nil {
 		_ = syncer.OnAssistantOutput(ctx, syncRunID, syncNodeID, syncInter, syncOutput)
 	}
@@ -69,7 +78,136 @@ func (s *Service) PublishOutput(ctx context.Context, runID, nodeID, interactionI
 	return nil
 }
 
+// PublishOutputChunk appends a streamed piece of an assistant message.
+// Unlike PublishOutput the chunk is kept verbatim, and chunks for the same
+// interaction arriving within the coalesce window are merged into one event
+// as long as no subscriber has received it yet.
+func (s *Service) PublishOutputChunk(ctx context.Context, runID, nodeID, interactionID, chunk string) error {
+	runID = strings.TrimSpace(runID)
+	nodeID = strings.TrimSpace(nodeID)
+	interactionID = strings.TrimSpace(interactionID)
+	if runID == "" || nodeID == "" {
+		return fmt.Errorf("run_id and node_id are required")
+	}
+	if chunk == "" {
+		return nil
+	}
+	s.loadConversation(ctx, runID, nodeID)
+
+	s.mu.Lock()
+	st := s.getOrCreateLocked(runID, nodeID)
+	if interactionID != "" {
+		st.interactionID = interactionID
+	}
+	if st.interactionID == "" {
+		st.interactionID = newInteractionID()
+	}
+	now := time.Now()
+	if n := len(st.outputs); n > 0 && s.chunkCoalesceWindow > 0 {
+		last := &st.outputs[n-1]
+		if last.chunk && last.interactionID == st.interactionID && last.seq > st.deliveredSeq && now.Sub(last.at) < s.chunkCoalesceWindow {
+			last.message += chunk
+			last.at = now
+			s.appendStreamLocked(st, chunk, now)
+			s.mu.Unlock()
+			return nil
+		}
+	}
+	st.lastSeq++
+	appendOutputLocked(st, outputMessage{
+		seq:           st.lastSeq,
+		interactionID: st.interactionID,
+		message:       chunk,
+		chunk:         true,
+		at:            now,
+	})
+	s.appendStreamLocked(st, chunk, now)
+	notifyLocked(st)
+	s.mu.Unlock()
+	s.scheduleConversationFlush(ctx, runID, nodeID)
+	return nil
+}
+
+// RecordConversation seeds an empty run+node session with turns carried
+// over from an earlier run, oldest first, so History and reconnecting
+// subscribers replay them. Sessions that already hold messages are left
+// unchanged, which keeps a retried run from recording the turns twice.
+func (s *Service) RecordConversation(ctx context.Context, runID, nodeID string, turns []artifact.ConversationTurn) error {
+	runID = strings.TrimSpace(runID)
+	nodeID = strings.TrimSpace(nodeID)
+	if runID == "" || nodeID == "" {
+		return fmt.Errorf("run_id and node_id are required")
+	}
+	turns = artifact.NormalizeConversation(turns)
+	if len(turns) == 0 {
+		return nil
+	}
+	s.loadConversation(ctx, runID, nodeID)
+
+	s.mu.Lock()
+	st := s.getOrCreateLocked(runID, nodeID)
+	if len(st.conversation) > 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	if st.interactionID == "" {
+		st.interactionID = newInteractionID()
+	}
+	now := time.Now()
+	for _, t := range turns {
+		if t.Role == "assistant" {
+			st.lastSeq++
+			appendOutputLocked(st, outputMessage{
+				seq:           st.lastSeq,
+				interactionID: st.interactionID,
+				message:       t.Content,
+				at:            now,
+			})
+		}
+		st.conversation = append(st.conversation, conversationMessage{
+			Seq:             nextConversationSeqLocked(st),
+			Role:            t.Role,
+			Content:         t.Content,
+			InteractionID:   st.interactionID,
+			CreatedAtUnixMs: now.UnixMilli(),
+		})
+	}
+	st.streaming = false
+	st.updatedAt = now
+	snapshot := s.buildConversationSnapshotLocked(runID, nodeID, st)
+	notifyLocked(st)
+	s.mu.Unlock()
+
+	s.persistConversation(ctx, runID, nodeID, snapshot)
+	s.scheduleConversationFlush(ctx, runID, nodeID)
+	return nil
+}
+
+// appendStreamLocked folds a chunk into the open assistant conversation
+// message, starting one if the stream was interrupted.
+func (s *Service) appendStreamLocked(st *sessionState, chunk string, now time.Time) {
+	st.updatedAt = now
+	if n := len(st.conversation); st.streaming && n > 0 && st.conversation[n-1].InteractionID == st.interactionID {
+		st.conversation[n-1].Content += chunk
+		return
+	}
+	st.streaming = true
+	st.conversation = append(st.conversation, conversationMessage{
+		Seq:             nextConversationSeqLocked(st),
+		Role:            "assistant",
+		Content:         chunk,
+		InteractionID:   st.interactionID,
+		CreatedAtUnixMs: now.UnixMilli(),
+	})
+}
+
 func (s *Service) Send(ctx context.Context, req *insightifyv1.SendRequest) (*insightifyv1.SendResponse, error) {
+	return s.send(ctx, req, "")
+}
+
+// send enqueues the input of req. A non-empty nonce already accepted for the
+// session returns the recorded response without enqueuing again.
+func (s *Service) send(ctx context.Context, req *insightifyv1.SendRequest, nonce string) (*insightifyv1.SendResponse, error) {
 	runID := strings.TrimSpace(req.GetRunId())
 	nodeID := strings.TrimSpace(req.GetNodeId())
 	input := strings.TrimSpace(req.GetInput())
@@ -79,6 +217,7 @@ func (s *Service) Send(ctx context.Context, req *insightifyv1.SendRequest) (*ins
 	if input == "" {
 		return nil, fmt.Errorf("input is required")
 	}
+	s.loadConversation(ctx, runID, nodeID)
 
 	var (
 		snapshot   []byte
@@ -91,6 +230,11 @@ func (s *Service) Send(ctx context.Context, req *insightifyv1.SendRequest) (*ins
 	s.mu.Lock()
 
 	st := s.getOrCreateLocked(runID, nodeID)
+	if prior, ok := st.nonces[nonce]; ok && nonce != "" {
+		s.mu.Unlock()
+		logctx.Info(ctx, "interaction duplicate input ignored", "run_id", runID, "node_id", nodeID, "interaction_id", prior.GetInteractionId())
+		return proto.Clone(prior).(*insightifyv1.SendResponse), nil
+	}
 	if st.closed {
 		s.mu.Unlock()
 		return &insightifyv1.SendResponse{
@@ -105,8 +249,9 @@ func (s *Service) Send(ctx context.Context, req *insightifyv1.SendRequest) (*ins
 		st.interactionID = newInteractionID()
 	}
 	st.inputQueue = append(st.inputQueue, input)
+	st.streaming = false
 	st.conversation = append(st.conversation, conversationMessage{
-		Seq:             len(st.conversation) + 1,
+		Seq:             nextConversationSeqLocked(st),
 		Role:            "user",
 		Content:         input,
 		InteractionID:   st.interactionID,
@@ -120,17 +265,25 @@ func (s *Service) Send(ctx context.Context, req *insightifyv1.SendRequest) (*ins
 	syncNodeID = nodeID
 	syncInter = st.interactionID
 	syncInput = input
+	res := &insightifyv1.SendResponse{
+		Accepted:         true,
+		InteractionId:    st.interactionID,
+		AssistantMessage: "",
+	}
+	if nonce != "" {
+		if st.nonces == nil {
+			st.nonces = make(map[string]*insightifyv1.SendResponse)
+		}
+		st.nonces[nonce] = proto.Clone(res).(*insightifyv1.SendResponse)
+	}
 	notifyLocked(st)
 	s.mu.Unlock()
 
 	s.persistConversation(ctx, runID, nodeID, snapshot)
+	s.scheduleConversationFlush(ctx, runID, nodeID)
 	if syncer != nil {
 		_ = syncer.OnUserAccepted(ctx, syncRunID, syncNodeID, syncInter, syncInput)
 	}
-	logctx.Info(ctx, "interaction user input accepted", "run_id", runID, "node_id", nodeID, "interaction_id", st.interactionID)
-	return &insightifyv1.SendResponse{
-		Accepted:         true,
-		InteractionId:    st.interactionID,
-		AssistantMessage: "",
-	}, nil
+	logctx.Info(ctx, "interaction user input accepted", "run_id", runID, "node_id", nodeID, "interaction_id", syncInter)
+	return res, nil
 }
@@ -0,0 +1,181 @@
+package userinteraction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func publishOutputs(t *testing.T, svc *Service, runID, nodeID string, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		if err := svc.PublishOutput(context.Background(), runID, nodeID, "", fmt.Sprintf("message %d", i)); err != nil {
+			t.Fatalf("PublishOutput() error = %v", err)
+		}
+	}
+}
+
+func nextEvent(t *testing.T, sub <-chan *SubscriptionEvent) (*SubscriptionEvent, bool) {
+	t.Helper()
+	select {
+	case evt, ok := <-sub:
+		return evt, ok
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for subscription event")
+		return nil, false
+	}
+}
+
+func TestBackpressureBlockWaitsForSlowConsumer(t *testing.T) {
+	svc := New(nil, "")
+	publishOutputs(t, svc, "run-bp", "node-bp", 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := svc.Subscribe(ctx, "run-bp", "node-bp")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	var last int64
+	for last < 20 {
+		time.Sleep(2 * time.Millisecond) // slow consumer, well under the send timeout
+		evt, ok := nextEvent(t, sub)
+		if !ok {
+			t.Fatalf("subscription closed at seq %d", last)
+		}
+		if evt.Kind == SubscriptionEventDropped {
+			t.Fatalf("block policy dropped events: %+v", evt)
+		}
+		if evt.Kind != SubscriptionEventAssistantMessage {
+			continue
+		}
+		if evt.Seq != last+1 {
+			t.Fatalf("seq = %d, want %d", evt.Seq, last+1)
+		}
+		last = evt.Seq
+	}
+	if n := svc.DroppedEvents(); n != 0 {
+		t.Fatalf("DroppedEvents() = %d, want 0", n)
+	}
+}
+
+func TestBackpressureBlockClosesStalledSubscription(t *testing.T) {
+	svc := New(nil, "")
+	svc.SetBackpressure(BackpressureBlock, 20*time.Millisecond)
+	publishOutputs(t, svc, "run-bp", "node-bp", 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := svc.Subscribe(ctx, "run-bp", "node-bp")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// Stall well past the send timeout, then drain what was buffered.
+	time.Sleep(200 * time.Millisecond)
+	var last int64
+	for {
+		evt, ok := nextEvent(t, sub)
+		if !ok {
+			break
+		}
+		if evt.Kind != SubscriptionEventAssistantMessage {
+			continue
+		}
+		if evt.Seq != last+1 {
+			t.Fatalf("seq = %d, want %d: buffered events must be a gap-free prefix", evt.Seq, last+1)
+		}
+		last = evt.Seq
+	}
+	if last == 0 || last >= 20 {
+		t.Fatalf("stalled subscription delivered through seq %d, want it cut short", last)
+	}
+
+	// Resuming from the last seen seq recovers the rest.
+	sub2, err := svc.SubscribeFrom(ctx, "run-bp", "node-bp", last)
+	if err != nil {
+		t.Fatalf("SubscribeFrom() error = %v", err)
+	}
+	for last < 20 {
+		evt, ok := nextEvent(t, sub2)
+		if !ok {
+			t.Fatalf("resumed subscription closed at seq %d", last)
+		}
+		if evt.Kind != SubscriptionEventAssistantMessage {
+			continue
+		}
+		if evt.Seq != last+1 {
+			t.Fatalf("resumed seq = %d, want %d", evt.Seq, last+1)
+		}
+		last = evt.Seq
+	}
+}
+
+func TestBackpressureDropOldestReportsDrops(t *testing.T) {
+	svc := New(nil, "")
+	svc.SetBackpressure(BackpressureDropOldest, 0)
+	publishOutputs(t, svc, "run-bp", "node-bp", 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := svc.Subscribe(ctx, "run-bp", "node-bp")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// The consumer does not read until the producer has overrun the buffer.
+	deadline := time.Now().Add(2 * time.Second)
+	for svc.DroppedEvents() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("no events dropped for a stalled consumer")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var last, reported int64
+	delivered := 0
+	for last < 20 {
+		evt, ok := nextEvent(t, sub)
+		if !ok {
+			t.Fatalf("drop_oldest subscription closed at seq %d", last)
+		}
+		switch evt.Kind {
+		case SubscriptionEventDropped:
+			if evt.Dropped <= 0 {
+				t.Fatalf("marker without a count: %+v", evt)
+			}
+			reported += evt.Dropped
+		case SubscriptionEventAssistantMessage:
+			if evt.Seq <= last {
+				t.Fatalf("seq %d after %d", evt.Seq, last)
+			}
+			last = evt.Seq
+			delivered++
+		case SubscriptionEventWaitState:
+			delivered++
+		}
+	}
+	// One wait state plus 20 outputs were sent; each was either delivered or
+	// reported by a marker ahead of the newest output.
+	if got := int64(delivered) + reported; got != 21 {
+		t.Fatalf("delivered %d + reported %d = %d, want 21", delivered, reported, got)
+	}
+	if n := svc.DroppedEvents(); n != reported {
+		t.Fatalf("DroppedEvents() = %d, markers reported %d", n, reported)
+	}
+}
+
+func TestParseBackpressurePolicy(t *testing.T) {
+	for in, want := range map[string]BackpressurePolicy{
+		"":            DefaultBackpressure,
+		" Block ":     BackpressureBlock,
+		"drop_oldest": BackpressureDropOldest,
+	} {
+		got, err := ParseBackpressurePolicy(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseBackpressurePolicy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseBackpressurePolicy("drop_newest"); err == nil {
+		t.Fatalf("ParseBackpressurePolicy(drop_newest) error = %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package userinteraction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSweepIdleExpiresAbandonedSession(t *testing.T) {
+	svc := New(nil, "")
+	svc.SetSessionIdleTTL(time.Minute)
+
+	// A run waits for input from a user who never comes back.
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := svc.WaitForInput(context.Background(), "run-1", "node-1")
+		errCh <- err
+	}()
+	deadline := time.Now().Add(time.Second)
+	for svc.SessionCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if n := svc.SweepIdle(time.Now()); n != 0 {
+		t.Fatalf("SweepIdle() removed %d fresh sessions", n)
+	}
+	if n := svc.SweepIdle(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Fatalf("SweepIdle() removed %d, want 1", n)
+	}
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrSessionExpired) {
+			t.Fatalf("WaitForInput() error = %v, want ErrSessionExpired", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("WaitForInput() still blocked after its session expired")
+	}
+	if n := svc.SessionCount(); n != 0 {
+		t.Fatalf("SessionCount() = %d after sweep", n)
+	}
+}
+
+func TestSweepIdleKeepsSubscribedSession(t *testing.T) {
+	svc := New(nil, "")
+	svc.SetSessionIdleTTL(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := svc.Subscribe(ctx, "run-1", "node-1")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	<-ch
+
+	if n := svc.SweepIdle(time.Now().Add(time.Hour)); n != 0 {
+		t.Fatalf("SweepIdle() removed a session with a live subscriber")
+	}
+	cancel()
+	for range ch {
+	}
+	if n := svc.SweepIdle(time.Now().Add(time.Hour)); n != 1 {
+		t.Fatalf("SweepIdle() removed %d after unsubscribe, want 1", n)
+	}
+}
@@ -0,0 +1,279 @@
+package userinteraction
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/artifact"
+	conversationrepo "insightify/internal/gateway/repository/conversation"
+)
+
+// memConversationStore outlives the Services under test, standing in for the
+// database across a simulated restart.
+type memConversationStore struct {
+	mu      sync.Mutex
+	convs   map[string]map[int64]conversationrepo.Message
+	appends int
+}
+
+func (m *memConversationStore) Append(_ context.Context, runID, nodeID string, msgs ...conversationrepo.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appends++
+	if m.convs == nil {
+		m.convs = map[string]map[int64]conversationrepo.Message{}
+	}
+	id := conversationrepo.ConversationID(runID, nodeID)
+	if m.convs[id] == nil {
+		m.convs[id] = map[int64]conversationrepo.Message{}
+	}
+	for _, msg := range msgs {
+		m.convs[id][msg.Seq] = msg
+	}
+	return nil
+}
+
+func (m *memConversationStore) List(_ context.Context, runID, nodeID string, afterSeq int64, limit int) (conversationrepo.Page, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var all []conversationrepo.Message
+	for seq, msg := range m.convs[conversationrepo.ConversationID(runID, nodeID)] {
+		if seq > afterSeq {
+			all = append(all, msg)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Seq < all[j].Seq })
+	page := conversationrepo.Page{Messages: all}
+	if limit > 0 && len(all) > limit {
+		page.Messages, page.More = all[:limit], true
+	}
+	return page, nil
+}
+
+func (m *memConversationStore) Prune(_ context.Context, r conversationrepo.Retention, _ time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := 0
+	for _, conv := range m.convs {
+		var seqs []int64
+		for seq := range conv {
+			seqs = append(seqs, seq)
+		}
+		sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+		for r.MaxMessages > 0 && len(seqs) > r.MaxMessages {
+			delete(conv, seqs[0])
+			seqs = seqs[1:]
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func historyContents(t *testing.T, page HistoryPage) []string {
+	t.Helper()
+	var out []string
+	for i, m := range page.Messages {
+		if i > 0 && m.Seq <= page.Messages[i-1].Seq {
+			t.Fatalf("history not in seq order: %+v", page.Messages)
+		}
+		out = append(out, m.Role+":"+m.Content)
+	}
+	return out
+}
+
+func TestConversationHistoryReplaysAfterRestart(t *testing.T) {
+	store := &memConversationStore{}
+	ctx := context.Background()
+	runID, nodeID := "run-hist", "node-hist"
+
+	svc := New(nil, "")
+	svc.SetConversationStore(store, conversationrepo.Retention{})
+	if err := svc.PublishOutput(ctx, runID, nodeID, "", "hello"); err != nil {
+		t.Fatalf("PublishOutput() error = %v", err)
+	}
+	if _, err := svc.Send(ctx, &insightifyv1.SendRequest{RunId: runID, NodeId: nodeID, Input: "explain the runner"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := svc.PublishOutput(ctx, runID, nodeID, "", "the runner executes phases"); err != nil {
+		t.Fatalf("PublishOutput() error = %v", err)
+	}
+
+	// Restart: a graceful shutdown flushes, then a fresh service over the
+	// same store.
+	svc.FlushConversations(ctx)
+	svc = New(nil, "")
+	svc.SetConversationStore(store, conversationrepo.Retention{})
+
+	page, err := svc.History(ctx, runID, nodeID, 0, 2)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if got := historyContents(t, page); len(got) != 2 || got[0] != "assistant:hello" || got[1] != "user:explain the runner" || !page.More {
+		t.Fatalf("first page = %v more=%v", got, page.More)
+	}
+	page, _ = svc.History(ctx, runID, nodeID, page.Messages[1].Seq, 2)
+	if got := historyContents(t, page); len(got) != 1 || got[0] != "assistant:the runner executes phases" || page.More {
+		t.Fatalf("second page = %v more=%v", got, page.More)
+	}
+
+	// Subscribe replays the stored assistant messages.
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sub, err := svc.Subscribe(subCtx, runID, nodeID)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if evt := readAssistantEvent(t, sub); evt.AssistantMessage != "hello" {
+		t.Fatalf("first replay = %q", evt.AssistantMessage)
+	}
+	if evt := readAssistantEvent(t, sub); evt.AssistantMessage != "the runner executes phases" {
+		t.Fatalf("second replay = %q", evt.AssistantMessage)
+	}
+
+	// New messages continue the stored seq instead of overwriting it.
+	if err := svc.PublishOutput(ctx, runID, nodeID, "", "anything else?"); err != nil {
+		t.Fatalf("PublishOutput() error = %v", err)
+	}
+	page, _ = svc.History(ctx, runID, nodeID, 0, 0)
+	got := historyContents(t, page)
+	if len(got) != 4 || got[3] != "assistant:anything else?" || page.Messages[3].Seq != 4 {
+		t.Fatalf("history after restart = %v", page.Messages)
+	}
+}
+
+func TestConversationHistoryContinuesSeqAfterRetention(t *testing.T) {
+	store := &memConversationStore{}
+	ctx := context.Background()
+	svc := New(nil, "")
+	svc.SetConversationStore(store, conversationrepo.Retention{MaxMessages: 2})
+	for _, msg := range []string{"one", "two", "three"} {
+		if err := svc.PublishOutput(ctx, "run-ret", "node-ret", "", msg); err != nil {
+			t.Fatalf("PublishOutput() error = %v", err)
+		}
+	}
+	svc.FlushConversations(ctx)
+	svc.pruneConversations(ctx, time.Now())
+
+	svc = New(nil, "")
+	svc.SetConversationStore(store, conversationrepo.Retention{MaxMessages: 2})
+	if err := svc.PublishOutput(ctx, "run-ret", "node-ret", "", "four"); err != nil {
+		t.Fatalf("PublishOutput() error = %v", err)
+	}
+	page, _ := svc.History(ctx, "run-ret", "node-ret", 0, 0)
+	got := historyContents(t, page)
+	if len(got) != 3 || got[0] != "assistant:two" || page.Messages[2].Seq != 4 {
+		t.Fatalf("history = %+v", page.Messages)
+	}
+}
+
+func TestConversationHistoryInMemoryWithoutStore(t *testing.T) {
+	svc := New(nil, "")
+	ctx := context.Background()
+	if page, err := svc.History(ctx, "run-mem", "node-mem", 0, 0); err != nil || len(page.Messages) != 0 || page.Messages == nil {
+		t.Fatalf("History() of unknown session = %+v, %v", page, err)
+	}
+	for _, msg := range []string{"a", "b", "c"} {
+		if err := svc.PublishOutput(ctx, "run-mem", "node-mem", "", msg); err != nil {
+			t.Fatalf("PublishOutput() error = %v", err)
+		}
+	}
+	page, err := svc.History(ctx, "run-mem", "node-mem", 1, 1)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if got := historyContents(t, page); len(got) != 1 || got[0] != "assistant:b" || !page.More {
+		t.Fatalf("History(after 1, limit 1) = %v more=%v", got, page.More)
+	}
+	if _, err := svc.History(ctx, "run-mem", "node-mem", -1, 0); err == nil {
+		t.Fatalf("negative after_seq should fail")
+	}
+}
+
+func TestRecordConversationReplaysCarriedTurns(t *testing.T) {
+	svc := New(nil, "")
+	ctx := context.Background()
+	turns := []artifact.ConversationTurn{
+		{Role: "user", Content: "I want to learn Raft"},
+		{Role: "assistant", Content: "Which implementation?"},
+	}
+	if err := svc.RecordConversation(ctx, "run-rec", "node-rec", turns); err != nil {
+		t.Fatalf("RecordConversation() error = %v", err)
+	}
+	// A retried run must not record the turns twice.
+	if err := svc.RecordConversation(ctx, "run-rec", "node-rec", turns); err != nil {
+		t.Fatalf("second RecordConversation() error = %v", err)
+	}
+	page, _ := svc.History(ctx, "run-rec", "node-rec", 0, 0)
+	if got := historyContents(t, page); len(got) != 2 || got[0] != "user:I want to learn Raft" || got[1] != "assistant:Which implementation?" {
+		t.Fatalf("history = %v", got)
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sub, err := svc.Subscribe(subCtx, "run-rec", "node-rec")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if evt := readAssistantEvent(t, sub); evt.AssistantMessage != "Which implementation?" {
+		t.Fatalf("replay = %q", evt.AssistantMessage)
+	}
+}
+
+func TestSessionOutputsAreCapped(t *testing.T) {
+	svc := New(nil, "")
+	ctx := context.Background()
+	for i := 0; i < maxSessionOutputs+10; i++ {
+		if err := svc.PublishOutput(ctx, "run-cap", "node-cap", "", "message"); err != nil {
+			t.Fatalf("PublishOutput() error = %v", err)
+		}
+	}
+	st := svc.state[sessionKey("run-cap", "node-cap")]
+	if len(st.outputs) != maxSessionOutputs || st.outputs[0].seq != 11 {
+		t.Fatalf("outputs = %d starting at seq %d, want the newest %d", len(st.outputs), st.outputs[0].seq, maxSessionOutputs)
+	}
+}
+
+func TestConversationStoreWritesAreBatched(t *testing.T) {
+	store := &memConversationStore{}
+	ctx := context.Background()
+	svc := New(nil, "")
+	svc.SetConversationStore(store, conversationrepo.Retention{})
+	for _, chunk := range []string{"the ", "runner ", "executes ", "phases"} {
+		if err := svc.PublishOutputChunk(ctx, "run-batch", "node-batch", "", chunk); err != nil {
+			t.Fatalf("PublishOutputChunk() error = %v", err)
+		}
+	}
+	store.mu.Lock()
+	appends := store.appends
+	store.mu.Unlock()
+	if appends != 0 {
+		t.Fatalf("appends = %d before the flush delay, want none on the request path", appends)
+	}
+
+	// History reads its own writes.
+	page, err := svc.History(ctx, "run-batch", "node-batch", 0, 0)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if got := historyContents(t, page); len(got) != 1 || got[0] != "assistant:the runner executes phases" {
+		t.Fatalf("history = %v", got)
+	}
+	store.mu.Lock()
+	appends = store.appends
+	store.mu.Unlock()
+	if appends != 1 {
+		t.Fatalf("appends = %d, want the stream written once", appends)
+	}
+
+	// The scheduled flush finds nothing left to write.
+	time.Sleep(DefaultConversationFlushDelay + 100*time.Millisecond)
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.appends != 1 {
+		t.Fatalf("appends = %d after the delay, want 1", store.appends)
+	}
+}
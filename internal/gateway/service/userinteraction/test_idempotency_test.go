@@ -0,0 +1,100 @@
+package userinteraction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+)
+
+func sendOnce(t *testing.T, svc *Service, input, nonce string) *insightifyv1.SendResponse {
+	t.Helper()
+	resp, err := svc.SendOnce(context.Background(), &insightifyv1.SendRequest{RunId: "run-1", NodeId: "node-1", Input: input}, nonce)
+	if err != nil {
+		t.Fatalf("SendOnce(%q) error = %v", nonce, err)
+	}
+	return resp
+}
+
+func queuedInputs(svc *Service) int {
+	queued, _ := sessionCounts(svc)
+	return queued
+}
+
+// sessionCounts returns the queued inputs and conversation messages of the
+// run-1/node-1 session.
+func sessionCounts(svc *Service) (queued, messages int) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	st, ok := svc.state[sessionKey("run-1", "node-1")]
+	if !ok {
+		return 0, 0
+	}
+	return len(st.inputQueue), len(st.conversation)
+}
+
+func TestSendOnceDuplicateNonceIsIdempotent(t *testing.T) {
+	svc := New(nil, "")
+	first := sendOnce(t, svc, "deploy to staging", "nonce-1")
+	if !first.GetAccepted() {
+		t.Fatalf("first SendOnce() accepted = false")
+	}
+	if in, err := svc.WaitForInput(context.Background(), "run-1", "node-1"); err != nil || in != "deploy to staging" {
+		t.Fatalf("WaitForInput() = %q, %v", in, err)
+	}
+
+	// The run now waits on a fresh input; the retried submit must not
+	// land there.
+	waitCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := svc.WaitForInput(waitCtx, "run-1", "node-1")
+		errCh <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	dup := sendOnce(t, svc, "deploy to staging", "nonce-1")
+	if dup.GetAccepted() != first.GetAccepted() || dup.GetInteractionId() != first.GetInteractionId() {
+		t.Fatalf("duplicate SendOnce() = %v, want prior result %v", dup, first)
+	}
+	if err := <-errCh; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForInput() error = %v, want the duplicate not delivered", err)
+	}
+	if queued, messages := sessionCounts(svc); queued != 0 || messages != 1 {
+		t.Fatalf("after duplicate: queued=%d messages=%d, want 0 and 1", queued, messages)
+	}
+}
+
+func TestSendOnceDistinctNoncesDeliverEach(t *testing.T) {
+	svc := New(nil, "")
+	for _, nonce := range []string{"nonce-1", "nonce-2", ""} {
+		if resp := sendOnce(t, svc, "input "+nonce, nonce); !resp.GetAccepted() {
+			t.Fatalf("SendOnce(%q) accepted = false", nonce)
+		}
+	}
+	if n := queuedInputs(svc); n != 3 {
+		t.Fatalf("queued inputs = %d, want 3", n)
+	}
+	for _, want := range []string{"input nonce-1", "input nonce-2", "input"} {
+		if in, err := svc.WaitForInput(context.Background(), "run-1", "node-1"); err != nil || in != want {
+			t.Fatalf("WaitForInput() = %q, %v; want %q", in, err, want)
+		}
+	}
+}
+
+func TestClearDropsNonces(t *testing.T) {
+	svc := New(nil, "")
+	sendOnce(t, svc, "first", "nonce-1")
+	svc.Clear("run-1")
+	if n := svc.SessionCount(); n != 0 {
+		t.Fatalf("SessionCount() = %d after Clear", n)
+	}
+	// After Clear the nonce is forgotten and the input is delivered again.
+	sendOnce(t, svc, "again", "nonce-1")
+	if n := queuedInputs(svc); n != 1 {
+		t.Fatalf("queued inputs = %d after Clear, want 1", n)
+	}
+}
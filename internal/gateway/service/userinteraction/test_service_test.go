@@ -60,6 +60,41 @@ func TestSendQueuesInputForWaitForInput(t *testing.T) {
 	}
 }
 
+func TestCloseRunReleasesPendingWaits(t *testing.T) {
+	svc := New(nil, "")
+	errCh := make(chan error, 2)
+	for _, nodeID := range []string{"node-1", "node-2"} {
+		go func(nodeID string) {
+			_, err := svc.WaitForInput(context.Background(), "run-1", nodeID)
+			errCh <- err
+		}(nodeID)
+	}
+	other := make(chan error, 1)
+	go func() {
+		_, err := svc.WaitForInput(context.Background(), "run-10", "node-1")
+		other <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	svc.CloseRun("run-1")
+
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errCh:
+			if err != context.Canceled {
+				t.Fatalf("WaitForInput() error = %v, want context.Canceled", err)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatalf("WaitForInput() not released by CloseRun")
+		}
+	}
+	select {
+	case err := <-other:
+		t.Fatalf("other run released: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
 func TestSubscribeEmitsStateTransitions(t *testing.T) {
 	svc := New(nil, "")
 	runID := "run-subscribe"
@@ -260,3 +295,120 @@ func readWaitState(t *testing.T, sub <-chan *SubscriptionEvent) *insightifyv1.Wa
 		return nil
 	}
 }
+
+func readAssistantEvent(t *testing.T, sub <-chan *SubscriptionEvent) *SubscriptionEvent {
+	t.Helper()
+	deadline := time.After(1 * time.Second)
+	for {
+		select {
+		case evt, ok := <-sub:
+			if !ok {
+				t.Fatalf("Subscribe channel closed unexpectedly")
+			}
+			if evt.Kind == SubscriptionEventAssistantChunk || evt.Kind == SubscriptionEventAssistantMessage {
+				return evt
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for assistant event")
+			return nil
+		}
+	}
+}
+
+func TestSubscribeFromResumesChunksWithoutDuplicates(t *testing.T) {
+	svc := New(nil, "")
+	svc.SetChunkCoalesceWindow(0)
+	runID := "run-resume"
+	nodeID := "node-resume"
+	chunks := []string{"Hel", "lo, ", "wor", "ld", "!"}
+	want := "Hello, world!"
+
+	for _, c := range chunks[:3] {
+		if err := svc.PublishOutputChunk(context.Background(), runID, nodeID, "", c); err != nil {
+			t.Fatalf("PublishOutputChunk() error = %v", err)
+		}
+	}
+
+	// First connection renders two chunks, then drops mid-stream.
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	sub1, err := svc.Subscribe(ctx1, runID, nodeID)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	got := ""
+	var lastSeq int64
+	for i := 0; i < 2; i++ {
+		evt := readAssistantEvent(t, sub1)
+		if evt.Seq <= lastSeq {
+			t.Fatalf("seq not increasing: %d after %d", evt.Seq, lastSeq)
+		}
+		got += evt.AssistantMessage
+		lastSeq = evt.Seq
+	}
+	cancel1()
+
+	for _, c := range chunks[3:] {
+		if err := svc.PublishOutputChunk(context.Background(), runID, nodeID, "", c); err != nil {
+			t.Fatalf("PublishOutputChunk() error = %v", err)
+		}
+	}
+
+	// Reconnect from the last rendered seq: snapshot plus live must not overlap.
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	sub2, err := svc.SubscribeFrom(ctx2, runID, nodeID, lastSeq)
+	if err != nil {
+		t.Fatalf("SubscribeFrom() error = %v", err)
+	}
+	for got != want {
+		if len(got) > len(want) {
+			t.Fatalf("reassembled text %q overran %q", got, want)
+		}
+		evt := readAssistantEvent(t, sub2)
+		if evt.Seq <= lastSeq {
+			t.Fatalf("re-sent seq %d at or below from_seq %d", evt.Seq, lastSeq)
+		}
+		got += evt.AssistantMessage
+		lastSeq = evt.Seq
+	}
+	select {
+	case evt := <-sub2:
+		if evt.Kind != SubscriptionEventWaitState {
+			t.Fatalf("unexpected extra event after full message: %+v", evt)
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPublishOutputChunkCoalescesUndeliveredChunks(t *testing.T) {
+	svc := New(nil, "")
+	svc.SetChunkCoalesceWindow(time.Hour)
+	runID := "run-coalesce"
+	nodeID := "node-coalesce"
+
+	for _, c := range []string{"a ", "b ", "c"} {
+		if err := svc.PublishOutputChunk(context.Background(), runID, nodeID, "", c); err != nil {
+			t.Fatalf("PublishOutputChunk() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := svc.Subscribe(ctx, runID, nodeID)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	evt := readAssistantEvent(t, sub)
+	if evt.Kind != SubscriptionEventAssistantChunk || evt.AssistantMessage != "a b c" || evt.Seq != 1 {
+		t.Fatalf("expected one coalesced chunk, got %+v", evt)
+	}
+
+	// Once delivered, a chunk is never mutated; later text gets a new seq.
+	if err := svc.PublishOutputChunk(context.Background(), runID, nodeID, "", " d"); err != nil {
+		t.Fatalf("PublishOutputChunk() error = %v", err)
+	}
+	evt = readAssistantEvent(t, sub)
+	if evt.AssistantMessage != " d" || evt.Seq != 2 {
+		t.Fatalf("expected new chunk with seq 2, got %+v", evt)
+	}
+}
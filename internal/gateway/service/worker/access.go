@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"insightify/internal/gateway/entity"
+)
+
+// AuthorizeProject returns an error matching ErrForbidden unless userID owns
+// projectID.
+func (s *Service) AuthorizeProject(projectID string, userID entity.UserID) error {
+	projectID = strings.TrimSpace(projectID)
+	view, ok := s.project.GetEntry(projectID)
+	if !ok {
+		return fmt.Errorf("project %s not found", projectID)
+	}
+	if view.UserID != userID {
+		return fmt.Errorf("project %s: %w", projectID, ErrForbidden)
+	}
+	return nil
+}
+
+// AuthorizeRun returns an error matching ErrForbidden unless userID owns the
+// project of runID. Runs pruned from the run table are resolved through the
+// project_id label of their telemetry, then through their persisted
+// RunStatus, which also survives a restart.
+func (s *Service) AuthorizeRun(runID string, userID entity.UserID) error {
+	runID = strings.TrimSpace(runID)
+	projectID, ok := s.ProjectIDForRun(runID)
+	if !ok && s.telemetry != nil {
+		projectID = s.telemetry.Labels(runID)[LabelProjectID]
+		ok = projectID != ""
+	}
+	if !ok {
+		if status, found := s.loadRunStatus(context.Background(), runID); found {
+			projectID, ok = status.ProjectID, status.ProjectID != ""
+		}
+	}
+	if !ok {
+		return fmt.Errorf("project not found for run %s", runID)
+	}
+	if err := s.AuthorizeProject(projectID, userID); err != nil {
+		return fmt.Errorf("run %s: %w", runID, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	workerv1 "insightify/gen/go/worker/v1"
+)
+
+// ClientViewPolicy decides which parts of a worker's ClientView may reach the
+// browser. Graph structure (node uids, labels, parents and edges) is always
+// kept; only free text is redacted. Text a worker wraps in
+// <internal>...</internal> is always removed.
+type ClientViewPolicy struct {
+	// StripCodeBlocks removes fenced code blocks from node descriptions,
+	// where they are raw file contents rather than prose.
+	StripCodeBlocks bool
+	// MaxDescriptionRunes truncates node descriptions; zero keeps them whole.
+	MaxDescriptionRunes int
+}
+
+// DefaultClientViewPolicy is applied to every ClientView before it is
+// published to the UI.
+var DefaultClientViewPolicy = ClientViewPolicy{
+	StripCodeBlocks:     true,
+	MaxDescriptionRunes: 2000,
+}
+
+// RedactedMarker replaces removed content so the reader knows text is missing.
+const RedactedMarker = "[internal content removed]"
+
+var (
+	internalBlock = regexp.MustCompile(`(?s)<internal>.*?(?:</internal>|$)`)
+	codeFence     = regexp.MustCompile("(?s)```.*?(?:```|$)")
+	blankRuns     = regexp.MustCompile(`\n{3,}`)
+)
+
+// SanitizeClientView applies DefaultClientViewPolicy to view.
+func SanitizeClientView(view *workerv1.ClientView) *workerv1.ClientView {
+	return DefaultClientViewPolicy.Sanitize(view)
+}
+
+// Sanitize returns a redacted copy of view; view itself, which may still be
+// referenced by the worker output, is not modified.
+func (p ClientViewPolicy) Sanitize(view *workerv1.ClientView) *workerv1.ClientView {
+	if view == nil {
+		return nil
+	}
+	out := proto.Clone(view).(*workerv1.ClientView)
+	switch c := out.GetContent().(type) {
+	case *workerv1.ClientView_LlmResponse:
+		c.LlmResponse = redactInternal(c.LlmResponse)
+	case *workerv1.ClientView_Graph:
+		for _, n := range c.Graph.GetNodes() {
+			n.Description = p.sanitizeDescription(n.GetDescription())
+		}
+	}
+	return out
+}
+
+func (p ClientViewPolicy) sanitizeDescription(desc string) string {
+	desc = redactInternal(desc)
+	if p.StripCodeBlocks {
+		desc = codeFence.ReplaceAllString(desc, RedactedMarker)
+	}
+	if p.MaxDescriptionRunes > 0 {
+		if r := []rune(desc); len(r) > p.MaxDescriptionRunes {
+			desc = strings.TrimSpace(string(r[:p.MaxDescriptionRunes])) + "…"
+		}
+	}
+	return desc
+}
+
+func redactInternal(s string) string {
+	if !strings.Contains(s, "<internal>") {
+		return s
+	}
+	s = internalBlock.ReplaceAllString(s, RedactedMarker)
+	return strings.TrimSpace(blankRuns.ReplaceAllString(s, "\n\n"))
+}
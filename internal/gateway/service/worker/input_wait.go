@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	logctx "insightify/internal/common/logctx"
+	"insightify/internal/runner"
+)
+
+const (
+	// StageInputWaitWarning events warn that a wait on user input is about
+	// to time out; remaining_seconds drives the UI countdown.
+	StageInputWaitWarning = "input_wait_warning"
+	// StageInputWaitTimeout events record a wait on user input that timed
+	// out and the action the worker declared for it.
+	StageInputWaitTimeout = "input_wait_timeout"
+	// StageRunPaused and StageRunResumed bracket the time a run waits on
+	// user input past its timeout.
+	StageRunPaused  = "run_paused"
+	StageRunResumed = "run_resumed"
+	// RunStatusPaused marks a run waiting on user input past its timeout;
+	// its RunStatus names the node to answer.
+	RunStatusPaused = "paused"
+	// RunStatusRunning is persisted when a run starts and when a paused run
+	// resumes.
+	RunStatusRunning = "running"
+)
+
+// SetInputWaitTimeout sets how long new runs wait on user input when neither
+// the worker nor the project sets a timeout. Zero leaves such waits unbounded.
+func (s *Service) SetInputWaitTimeout(d time.Duration) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.inputWaitTimeout = d
+}
+
+// inputWaitTimeoutFor returns the project's input wait timeout, or else the
+// server default.
+func (s *Service) inputWaitTimeoutFor(projectID string) time.Duration {
+	if s.project != nil {
+		if view, ok := s.project.GetEntry(projectID); ok && view.InputWaitTimeout > 0 {
+			return view.InputWaitTimeout
+		}
+	}
+	s.runMu.RLock()
+	defer s.runMu.RUnlock()
+	return s.inputWaitTimeout
+}
+
+// RunPaused reports whether a run waits on user input past its timeout, and
+// for which node.
+func (s *Service) RunPaused(runID string) (nodeID string, paused bool) {
+	s.runMu.RLock()
+	defer s.runMu.RUnlock()
+	st, ok := s.runs[runID]
+	if !ok || st.pausedNode == "" {
+		return "", false
+	}
+	return st.pausedNode, true
+}
+
+// inputWaitEvents warns watchers and the chat before an input wait times out
+// and tracks the paused state of runs whose workers opted into pausing.
+func (s *Service) inputWaitEvents(runID, workerID string) runner.InputWaitEvents {
+	return runner.InputWaitEvents{
+		OnWarning: func(ctx context.Context, nodeID string, remaining time.Duration) {
+			seconds := int(math.Ceil(remaining.Seconds()))
+			s.telemetry.Append(runID, "worker", StageInputWaitWarning, map[string]any{
+				"worker_id":         workerID,
+				"node_id":           nodeID,
+				"level":             "warn",
+				"remaining_seconds": seconds,
+			})
+			if s.interaction != nil {
+				msg := fmt.Sprintf("Still there? This step stops waiting for your reply in %d seconds.", seconds)
+				if err := s.interaction.PublishOutput(ctx, runID, nodeID, "", msg); err != nil {
+					logctx.Warn(ctx, "input wait warning not delivered", "node_id", nodeID, "error", err)
+				}
+			}
+		},
+		OnTimeout: func(ctx context.Context, nodeID, action string) {
+			if action != runner.InputTimeoutPause {
+				s.telemetry.Append(runID, "worker", StageInputWaitTimeout, map[string]any{
+					"worker_id": workerID,
+					"node_id":   nodeID,
+					"action":    timeoutActionName(action),
+				})
+				return
+			}
+			s.setRunPaused(ctx, runID, nodeID, true)
+		},
+		OnResume: func(ctx context.Context, nodeID string) {
+			s.setRunPaused(ctx, runID, nodeID, false)
+		},
+	}
+}
+
+// setRunPaused records a run pausing on, or resuming from, the input wait of
+// nodeID in telemetry and in its persisted RunStatus.
+func (s *Service) setRunPaused(ctx context.Context, runID, nodeID string, paused bool) {
+	s.runMu.Lock()
+	st, ok := s.runs[runID]
+	if ok {
+		st.pausedNode = ""
+		if paused {
+			st.pausedNode = nodeID
+		}
+	}
+	s.runMu.Unlock()
+	if !ok {
+		return
+	}
+
+	status := st.runStatus(RunStatusRunning)
+	status.NodeID = nodeID
+	stage := StageRunResumed
+	if paused {
+		status.Status = RunStatusPaused
+		status.PausedAt = time.Now()
+		stage = StageRunPaused
+	}
+	s.telemetry.Append(runID, "worker", stage, map[string]any{
+		"worker_id": st.WorkerID,
+		"node_id":   nodeID,
+		"status":    status.Status,
+	})
+	s.persistRunStatus(ctx, status)
+}
+
+// timeoutActionName names the fail action, whose constant is empty, in telemetry.
+func timeoutActionName(action string) string {
+	if action == runner.InputTimeoutFail {
+		return "fail"
+	}
+	return action
+}
@@ -0,0 +1,221 @@
+package worker
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"insightify/internal/gateway/entity"
+)
+
+// LabelParamPrefix marks StartRunRequest params that are run labels rather
+// than worker params: "label.env=nightly" labels the run env=nightly.
+const LabelParamPrefix = "label."
+
+// Labels the gateway adds to every run. User labels may not set them.
+const (
+	LabelWorker         = "worker"
+	LabelProjectID      = "project_id"
+	LabelGatewayVersion = "gateway_version"
+)
+
+// Limits on the user labels of one run.
+const (
+	MaxRunLabels        = 16
+	MaxRunLabelKeyLen   = 63
+	MaxRunLabelValueLen = 128
+)
+
+// splitLabels separates the label params of a StartRun request from its
+// worker params and validates them.
+func splitLabels(in map[string]string) (params, labels map[string]string, err error) {
+	params = make(map[string]string, len(in))
+	for k, v := range in {
+		key, ok := strings.CutPrefix(k, LabelParamPrefix)
+		if !ok {
+			params[k] = v
+			continue
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[key] = v
+	}
+	if len(labels) > MaxRunLabels {
+		return nil, nil, fmt.Errorf("%w: %d labels exceed the limit of %d", ErrInvalidRun, len(labels), MaxRunLabels)
+	}
+	for k, v := range labels {
+		if err := validLabelKey(k); err != nil {
+			return nil, nil, err
+		}
+		switch k {
+		case LabelWorker, LabelProjectID, LabelGatewayVersion:
+			return nil, nil, fmt.Errorf("%w: label %q is set by the gateway", ErrInvalidRun, k)
+		}
+		if len(v) > MaxRunLabelValueLen {
+			return nil, nil, fmt.Errorf("%w: label %q value exceeds %d bytes", ErrInvalidRun, k, MaxRunLabelValueLen)
+		}
+	}
+	return params, labels, nil
+}
+
+// validLabelKey accepts 1-63 characters of [a-z0-9._/-] starting with a
+// letter or digit.
+func validLabelKey(k string) error {
+	if k == "" || len(k) > MaxRunLabelKeyLen {
+		return fmt.Errorf("%w: label key %q must be 1-%d characters", ErrInvalidRun, k, MaxRunLabelKeyLen)
+	}
+	for i, r := range k {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		case i > 0 && (r == '.' || r == '_' || r == '-' || r == '/'):
+		default:
+			return fmt.Errorf("%w: label key %q may only use a-z, 0-9, '.', '_', '-' and '/'", ErrInvalidRun, k)
+		}
+	}
+	return nil
+}
+
+// runLabels adds the gateway's own labels to the user labels.
+func (s *Service) runLabels(user map[string]string, projectID, workerID string) map[string]string {
+	labels := maps.Clone(user)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[LabelWorker] = workerID
+	labels[LabelProjectID] = projectID
+	labels[LabelGatewayVersion] = s.version
+	return labels
+}
+
+// gatewayVersion is the VCS revision the binary was built from, else its
+// module version.
+func gatewayVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && s.Value != "" {
+			return s.Value
+		}
+	}
+	if v := info.Main.Version; v != "" {
+		return v
+	}
+	return "unknown"
+}
+
+// ParseLabelSelector parses "key=value" terms, each of which a run's labels
+// must match exactly.
+func ParseLabelSelector(terms []string) (map[string]string, error) {
+	var sel map[string]string
+	for _, term := range terms {
+		for _, part := range strings.Split(term, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			k, v, ok := strings.Cut(part, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return nil, fmt.Errorf("label selector %q: want key=value", part)
+			}
+			if sel == nil {
+				sel = map[string]string{}
+			}
+			sel[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return sel, nil
+}
+
+// MatchLabels reports whether labels has every key of sel with its value.
+func MatchLabels(labels, sel map[string]string) bool {
+	for k, v := range sel {
+		got, ok := labels[k]
+		if !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// RunSummary describes a tracked run for ListRuns.
+type RunSummary struct {
+	RunID      string            `json:"run_id"`
+	ProjectID  string            `json:"project_id"`
+	WorkerID   string            `json:"worker_id"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at,omitzero"`
+}
+
+// ListRuns returns the tracked runs of projects owned by userID whose labels
+// match sel, newest first.
+func (s *Service) ListRuns(userID entity.UserID, sel map[string]string) []RunSummary {
+	s.runMu.RLock()
+	matched := []RunSummary{}
+	for _, st := range s.runs {
+		if !MatchLabels(st.labels, sel) {
+			continue
+		}
+		matched = append(matched, RunSummary{
+			RunID:      st.RunID,
+			ProjectID:  st.ProjectID,
+			WorkerID:   st.WorkerID,
+			Labels:     st.labels,
+			StartedAt:  st.StartedAt,
+			FinishedAt: st.finishedAt,
+		})
+	}
+	s.runMu.RUnlock()
+
+	out := []RunSummary{}
+	owned := map[string]bool{}
+	for _, run := range matched {
+		ok, seen := owned[run.ProjectID]
+		if !seen {
+			ok = s.AuthorizeProject(run.ProjectID, userID) == nil
+			owned[run.ProjectID] = ok
+		}
+		if ok {
+			out = append(out, run)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartedAt.Equal(out[j].StartedAt) {
+			return out[i].StartedAt.After(out[j].StartedAt)
+		}
+		return out[i].RunID < out[j].RunID
+	})
+	return out
+}
+
+// LatestRuns returns up to limit runs with the most recent telemetry whose
+// labels match sel and that userID may access (see AuthorizeRun), newest
+// first.
+func (s *Service) LatestRuns(userID entity.UserID, limit int, sel map[string]string) []string {
+	if limit <= 0 {
+		limit = 20
+	}
+	out := []string{}
+	owned := map[string]bool{}
+	for _, runID := range s.telemetry.LatestRunsMatching(math.MaxInt, sel) {
+		if len(out) >= limit {
+			break
+		}
+		ok, seen := owned[runID]
+		if !seen {
+			ok = s.AuthorizeRun(runID, userID) == nil
+			owned[runID] = ok
+		}
+		if ok {
+			out = append(out, runID)
+		}
+	}
+	return out
+}
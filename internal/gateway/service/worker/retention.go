@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	logctx "insightify/internal/common/logctx"
+)
+
+const (
+	// DefaultMaxRuns caps the runs the service tracks; the oldest finished
+	// runs are evicted first. Active runs are never evicted.
+	DefaultMaxRuns = 1000
+	// DefaultRunRetention is how long a finished run stays queryable.
+	DefaultRunRetention = 10 * time.Minute
+
+	// StageRunPanic is the terminal telemetry stage of runs whose goroutine
+	// panicked.
+	StageRunPanic = "run_panic"
+	// RunStatusFailed is the status of a run stopped by an error or a panic.
+	RunStatusFailed = "failed"
+)
+
+// RunCounts reports the runs the service currently tracks.
+type RunCounts struct {
+	Active   int `json:"active"`
+	Finished int `json:"finished"`
+}
+
+// SetRunRetention bounds the run table: at most maxRuns entries (finished
+// runs evicted oldest first) and finished runs dropped after retention.
+// Zero disables the respective limit.
+func (s *Service) SetRunRetention(maxRuns int, retention time.Duration) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.maxRuns = max(0, maxRuns)
+	s.runRetention = max(0, retention)
+	s.pruneRunsLocked(time.Now())
+}
+
+// RunCounts returns how many tracked runs are active and finished.
+func (s *Service) RunCounts() RunCounts {
+	s.runMu.RLock()
+	defer s.runMu.RUnlock()
+	var c RunCounts
+	for _, st := range s.runs {
+		if st.finishedAt.IsZero() {
+			c.Active++
+		} else {
+			c.Finished++
+		}
+	}
+	return c
+}
+
+// SweepRuns drops finished runs past the retention window and returns how
+// many were removed.
+func (s *Service) SweepRuns(now time.Time) int {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	return s.pruneRunsLocked(now)
+}
+
+// StartRunSweeper calls SweepRuns every interval until ctx is done.
+func (s *Service) StartRunSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.SweepRuns(now)
+			}
+		}
+	}()
+}
+
+// finishRun marks st finished, persists its outcome (RunStatusFinished when
+// it ended without a terminal failure event) and prunes the run table.
+func (s *Service) finishRun(st *WorkerRuntime) {
+	s.runMu.Lock()
+	st.finishedAt = time.Now()
+	status := st.runStatus(RunStatusFinished)
+	if st.outcome != "" {
+		status.Status = st.outcome
+	}
+	status.FinishedAt = st.finishedAt
+	interrupted := st.interrupted
+	s.pruneRunsLocked(st.finishedAt)
+	s.runMu.Unlock()
+	if !interrupted {
+		// Shutdown persists the interrupted status once the run unwound.
+		s.persistRunStatus(context.Background(), status)
+	}
+}
+
+// pruneRunsLocked removes finished runs past retention, then the oldest
+// finished runs while the table exceeds maxRuns. Their telemetry goes too.
+func (s *Service) pruneRunsLocked(now time.Time) int {
+	var finished []*WorkerRuntime
+	removed := 0
+	for id, st := range s.runs {
+		if st.finishedAt.IsZero() {
+			continue
+		}
+		if s.runRetention > 0 && now.Sub(st.finishedAt) >= s.runRetention {
+			s.removeRunLocked(id)
+			removed++
+			continue
+		}
+		finished = append(finished, st)
+	}
+	if s.maxRuns <= 0 || len(s.runs) <= s.maxRuns {
+		return removed
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].finishedAt.Before(finished[j].finishedAt) })
+	for _, st := range finished {
+		if len(s.runs) <= s.maxRuns {
+			break
+		}
+		s.removeRunLocked(st.RunID)
+		removed++
+	}
+	return removed
+}
+
+// runInteractionClearer is implemented by interaction waiters that keep
+// per-run state (sessions, submit nonces) to drop with the run.
+type runInteractionClearer interface {
+	Clear(runID string)
+}
+
+func (s *Service) removeRunLocked(runID string) {
+	delete(s.runs, runID)
+	s.telemetry.Delete(runID)
+	if clearer, ok := s.interaction.(runInteractionClearer); ok {
+		clearer.Clear(runID)
+	}
+}
+
+// recoverRun turns a panic of the run goroutine into a terminal run_panic
+// event so the run's deferred cleanup still runs. Call it deferred.
+func (s *Service) recoverRun(ctx context.Context, st *WorkerRuntime) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	err := fmt.Errorf("run panicked: %v", r)
+	logctx.Error(ctx, "worker run panicked", err, "run_id", st.RunID, "project_id", st.ProjectID, "worker_id", st.WorkerID, "stack", string(debug.Stack()))
+	s.appendTerminal(st.RunID, StageRunPanic, map[string]any{
+		"worker_id": st.WorkerID,
+		"status":    RunStatusFailed,
+		"error":     err.Error(),
+	})
+}
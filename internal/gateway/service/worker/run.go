@@ -1,19 +1,31 @@
 package worker
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/common/graphdelta"
 	logctx "insightify/internal/common/logctx"
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
 	traceutil "insightify/internal/common/trace"
 	projectrepo "insightify/internal/gateway/repository/project"
+	"insightify/internal/llm/hooks"
+	llmmiddleware "insightify/internal/llm/middleware"
 	"insightify/internal/runner"
+	runtimepkg "insightify/internal/workerruntime"
+	"insightify/internal/workerruntime/artifactblob"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 )
@@ -24,6 +36,113 @@ type WorkerRuntime struct {
 	ProjectID string
 	WorkerID  string
 	StartedAt time.Time
+
+	params     map[string]string
+	labels     map[string]string       // user and gateway labels; immutable
+	usage      *llmmiddleware.RunUsage // LLM usage and cost of the run
+	cancel     context.CancelFunc
+	done       chan struct{} // closed when the run goroutine returns
+	finishedAt time.Time     // zero while running; guarded by Service.runMu
+	pausedNode string        // node a paused run waits on; guarded by Service.runMu
+	// interrupted is set by Shutdown before it cancels the run, which then
+	// ends with server_shutdown instead of its own terminal event; guarded
+	// by Service.runMu.
+	interrupted bool
+	// outcome is the status of the run's terminal event, empty when the run
+	// succeeded; guarded by Service.runMu.
+	outcome string
+}
+
+const (
+	// StageRunTimeout is the terminal telemetry stage of runs that exceeded
+	// the run deadline.
+	StageRunTimeout = "run_timeout"
+	// RunStatusTimeout is the status of a run stopped by its deadline.
+	RunStatusTimeout = "timeout"
+	// StageProgress events carry the run's cumulative progress_percent, or
+	// a graph delta of a phase (see graphDeltaEvents).
+	StageProgress = "progress"
+	// StagePhaseTimeout is the terminal telemetry stage of runs whose phase
+	// exceeded its timeout.
+	StagePhaseTimeout = "phase_timeout"
+	// StageBudgetExhausted is the terminal telemetry stage of runs stopped
+	// because their budget_ms could not cover the next phase.
+	StageBudgetExhausted = "budget_exhausted"
+	// StageRetryBudgetExhausted is the terminal telemetry stage of runs whose
+	// LLM calls used up the run's retry budget.
+	StageRetryBudgetExhausted = "retry_budget_exhausted"
+	// StageRunLocked is the terminal telemetry stage of runs refused because
+	// another run held the project's artifacts.
+	StageRunLocked = "run_locked"
+	// StageCostBudgetExceeded is the terminal telemetry stage of runs whose
+	// next LLM call would have exceeded their cost budget.
+	StageCostBudgetExceeded = "cost_budget_exceeded"
+	// StageRunUsage events carry the LLM usage and cost of a finished run.
+	StageRunUsage = "run_usage"
+	// StageThrottled events report an LLM call held back by a rate limit
+	// for longer than llmmiddleware.DefaultThrottleEventAfter.
+	StageThrottled = "throttled"
+	// StagePhaseStart and StagePhaseEnd bracket each phase of a run;
+	// phase_end events of cache hits carry cached=true.
+	StagePhaseStart = "phase_start"
+	StagePhaseEnd   = "phase_end"
+	// StageRepoSkipped marks runs whose analysis phases the repo_assessment
+	// gate skipped; the run then completes with the assessment view.
+	StageRepoSkipped = "repo_skipped"
+	// RunStatusSkipped is the status of skipped phases and runs.
+	RunStatusSkipped = "skipped"
+	// StageScanTruncated events report a repository scan stopped by the
+	// scan size limits; the phase continued with the files scanned so far.
+	StageScanTruncated = "scan_truncated"
+	// StageReadBudgetExceeded ends runs that read more repository bytes
+	// than their read budget (RUN_READ_BUDGET_BYTES) allows.
+	StageReadBudgetExceeded = "read_budget_exceeded"
+	// StageArtifactTooLarge ends runs whose phase output is above the
+	// artifact size limit (see runner.ErrArtifactTooLarge).
+	StageArtifactTooLarge = "artifact_too_large"
+	// StageRunFailed is the terminal telemetry stage of runs that failed
+	// for any other reason.
+	StageRunFailed = "run_failed"
+)
+
+// SetPromptLog enables saving the LLM prompts and responses of new runs
+// below OutDir/prompt/<run_id>.
+func (s *Service) SetPromptLog(enabled bool) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.promptLog = enabled
+}
+
+// SetRunTimeout sets the execution deadline applied to every new run. Zero
+// leaves runs without a deadline.
+func (s *Service) SetRunTimeout(d time.Duration) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.runTimeout = d
+}
+
+// SetRetryBudget caps the LLM retries of each new run across all its phases
+// and calls. Zero leaves retries bounded per call only.
+func (s *Service) SetRetryBudget(n int) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.retryBudget = n
+}
+
+// SetRunLockWait sets how long a new run waits for another run of the same
+// project to finish. Zero makes StartRun refuse it with a *runner.RunLockError.
+func (s *Service) SetRunLockWait(d time.Duration) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.runLockWait = d
+}
+
+// SetPhaseTimeout sets the timeout of phases that declare none. Zero leaves
+// them unbounded.
+func (s *Service) SetPhaseTimeout(d time.Duration) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.phaseTimeout = d
 }
 
 func (s *Service) StartRun(ctx context.Context, req *insightifyv1.StartRunRequest) (*insightifyv1.StartRunResponse, error) {
@@ -32,28 +151,85 @@ func (s *Service) StartRun(ctx context.Context, req *insightifyv1.StartRunReques
 	}
 	projectID := strings.TrimSpace(req.GetProjectId())
 	workerID := strings.TrimSpace(req.GetWorkerId())
+	reqParams, userLabels, err := splitLabels(req.GetParams())
+	if err != nil {
+		return nil, err
+	}
+	phases := runner.PhasesParam(reqParams)
 	if projectID == "" {
 		return nil, fmt.Errorf("project_id is required")
 	}
-	if workerID == "" {
+	if workerID == "" && len(phases) == 0 {
 		return nil, fmt.Errorf("worker_id is required")
 	}
+	if len(phases) > 0 {
+		if err := s.checkPhases(projectID, phases, reqParams); err != nil {
+			return nil, err
+		}
+		if workerID == "" {
+			// Runs are labelled by worker; name the selection instead.
+			workerID = strings.Join(phases, ",")
+		}
+	}
+	if repo := strings.TrimSpace(reqParams[runner.RunParamRepo]); repo != "" {
+		if err := s.checkRepo(projectID, repo); err != nil {
+			return nil, err
+		}
+	}
+	costBudget, err := s.costBudget(projectID, reqParams)
+	if err != nil {
+		return nil, err
+	}
+
+	params := s.withRepoThresholds(projectID, s.withLocale(ctx, projectID, reqParams))
+	labels := s.runLabels(userLabels, projectID, workerID)
 
 	runID := s.newRunID(projectID)
 	reqTraceID := traceutil.FromContext(ctx)
 	runBaseCtx := traceutil.WithContext(context.Background(), reqTraceID)
+	s.runMu.RLock()
+	timeout := s.runTimeout
+	s.runMu.RUnlock()
 	runCtx, cancel := context.WithCancel(runBaseCtx)
+	if timeout > 0 {
+		// The deadline derives from the cancellable context, so cancel still
+		// stops the run early and the deadline's timer is released with it.
+		var stopTimer context.CancelFunc
+		runCtx, stopTimer = context.WithTimeout(runCtx, timeout)
+		cancelRun := cancel
+		cancel = func() {
+			stopTimer()
+			cancelRun()
+		}
+	}
 	st := &WorkerRuntime{
 		RunID:     runID,
 		ProjectID: projectID,
 		WorkerID:  workerID,
 		StartedAt: time.Now(),
+		params:    params,
+		labels:    labels,
+		usage:     llmmiddleware.NewRunUsage(costBudget),
+		cancel:    cancel,
+		done:      make(chan struct{}),
 	}
-	logctx.Info(runCtx, "worker run started", "run_id", runID, "project_id", projectID, "worker_id", workerID)
 
 	s.runMu.Lock()
+	if s.closing {
+		s.runMu.Unlock()
+		cancel()
+		return nil, ErrShuttingDown
+	}
+	if holder := s.activeRunLocked(projectID); holder != nil && s.runLockWait <= 0 {
+		s.runMu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("project %s: %w", projectID, &runner.RunLockError{RunID: holder.RunID, PID: os.Getpid(), Since: holder.StartedAt})
+	}
 	s.runs[runID] = st
+	s.pruneRunsLocked(st.StartedAt)
 	s.runMu.Unlock()
+	s.telemetry.SetLabels(runID, labels)
+	logctx.Info(runCtx, "worker run started", "run_id", runID, "project_id", projectID, "worker_id", workerID, "labels", labels)
 
 	if s.workspaces != nil {
 		if err := s.workspaces.AssignRunToCurrentTab(projectID, runID); err != nil {
@@ -62,13 +238,182 @@ func (s *Service) StartRun(ctx context.Context, req *insightifyv1.StartRunReques
 	}
 
 	go func() {
+		defer close(st.done)
+		defer s.finishRun(st)
 		defer cancel()
-		s.executeRun(runCtx, runID, projectID, workerID, req.GetParams())
+		defer s.recoverRun(runCtx, st)
+		s.persistRunStatus(runCtx, st.runStatus(RunStatusRunning))
+		s.executeRun(llmmiddleware.WithRunUsage(runCtx, st.usage), runID, projectID, workerID, params)
 	}()
 
 	return &insightifyv1.StartRunResponse{RunId: runID}, nil
 }
 
+type acceptLanguageKey struct{}
+
+// WithAcceptLanguage attaches the Accept-Language header of a StartRun
+// request, the last fallback of the run's locale param.
+func WithAcceptLanguage(ctx context.Context, header string) context.Context {
+	return context.WithValue(ctx, acceptLanguageKey{}, header)
+}
+
+// withLocale returns params with runner.RunParamLocale set from, in order,
+// the request params, the project's locale and the request's
+// Accept-Language. params itself is left unchanged.
+func (s *Service) withLocale(ctx context.Context, projectID string, params map[string]string) map[string]string {
+	if strings.TrimSpace(params[runner.RunParamLocale]) != "" {
+		return params
+	}
+	locale := ""
+	if s.project != nil {
+		if view, ok := s.project.GetEntry(projectID); ok {
+			locale = strings.TrimSpace(view.Locale)
+		}
+	}
+	if locale == "" {
+		locale, _ = ctx.Value(acceptLanguageKey{}).(string)
+		locale = strings.TrimSpace(locale)
+	}
+	if locale == "" {
+		return params
+	}
+	out := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		out[k] = v
+	}
+	out[runner.RunParamLocale] = locale
+	return out
+}
+
+// withRepoThresholds returns params with the repo gate thresholds the
+// request leaves unset taken from the project's settings. params itself is
+// left unchanged.
+func (s *Service) withRepoThresholds(projectID string, params map[string]string) map[string]string {
+	if s.project == nil {
+		return params
+	}
+	view, ok := s.project.GetEntry(projectID)
+	if !ok {
+		return params
+	}
+	set := map[string]string{}
+	if view.MinCodeRatio > 0 && strings.TrimSpace(params[runner.RunParamMinCodeRatio]) == "" {
+		set[runner.RunParamMinCodeRatio] = strconv.FormatFloat(view.MinCodeRatio, 'g', -1, 64)
+	}
+	if view.MinCodeBytes > 0 && strings.TrimSpace(params[runner.RunParamMinCodeBytes]) == "" {
+		set[runner.RunParamMinCodeBytes] = strconv.FormatInt(view.MinCodeBytes, 10)
+	}
+	if len(set) == 0 {
+		return params
+	}
+	out := make(map[string]string, len(params)+len(set))
+	for k, v := range params {
+		out[k] = v
+	}
+	for k, v := range set {
+		out[k] = v
+	}
+	return out
+}
+
+// activeRunLocked returns an unfinished run of projectID. Callers hold runMu.
+func (s *Service) activeRunLocked(projectID string) *WorkerRuntime {
+	for _, st := range s.runs {
+		if st.ProjectID == projectID && st.finishedAt.IsZero() {
+			return st
+		}
+	}
+	return nil
+}
+
+// checkRepo rejects a repo selector naming no repository of the project.
+func (s *Service) checkRepo(projectID, repo string) error {
+	if s.project == nil {
+		return nil
+	}
+	view, ok := s.project.GetEntry(projectID)
+	if !ok {
+		return fmt.Errorf("project %s not found", projectID)
+	}
+	for _, name := range view.Repos {
+		if name == repo {
+			return nil
+		}
+	}
+	return fmt.Errorf("project %s has no repo %q", projectID, repo)
+}
+
+// ErrInvalidRun is returned by StartRun for run params that cannot be
+// combined.
+var ErrInvalidRun = errors.New("invalid run request")
+
+// checkPhases validates the RunParamPhases targets of a run against the
+// project's resolver before the run starts.
+func (s *Service) checkPhases(projectID string, phases []string, params map[string]string) error {
+	if isDryRun(params) {
+		return fmt.Errorf("%w: %s cannot be combined with dry_run", ErrInvalidRun, runner.RunParamPhases)
+	}
+	if s.project == nil {
+		return nil
+	}
+	runEnv, err := s.project.EnsureRunContext(projectID)
+	if err != nil {
+		return err
+	}
+	if runEnv == nil {
+		return fmt.Errorf("project %s has no run context", projectID)
+	}
+	if err := runEnv.Materialize(); err != nil {
+		return err
+	}
+	_, err = runner.PlanPhases(runEnv.Resolver, phases)
+	return err
+}
+
+// executeTargets runs the phases selected by RunParamPhases, each with what
+// it requires, or else workerID.
+func executeTargets(ctx context.Context, rt runner.Runtime, workerID string, params map[string]string) (runner.WorkerOutput, error) {
+	phases := runner.PhasesParam(params)
+	if len(phases) == 0 {
+		return runner.ExecuteWorker(ctx, rt, workerID, params)
+	}
+	keys, err := runner.PlanPhases(rt.GetResolver(), phases)
+	if err != nil {
+		return runner.WorkerOutput{}, err
+	}
+	// The selection must not reach worker inputs, or it would change their
+	// fingerprints and miss the cache of runs started per worker.
+	rest := make(map[string]string, len(params))
+	for k, v := range params {
+		if k != runner.RunParamPhases {
+			rest[k] = v
+		}
+	}
+	return runner.ExecutePlan(ctx, rt, keys, rest)
+}
+
+// costBudget returns the cost budget param of a run, or else the project's
+// default.
+func (s *Service) costBudget(projectID string, params map[string]string) (float64, error) {
+	usd, ok, err := runner.CostBudget(params)
+	if err != nil || ok || s.project == nil {
+		return usd, err
+	}
+	view, _ := s.project.GetEntry(projectID)
+	return view.CostBudgetUSD, nil
+}
+
+// RunUsage returns the LLM usage and cost of a run so far.
+func (s *Service) RunUsage(runID string) (llmmiddleware.RunUsageSummary, bool) {
+	s.runMu.RLock()
+	st, ok := s.runs[runID]
+	s.runMu.RUnlock()
+	if !ok || st.usage == nil {
+		return llmmiddleware.RunUsageSummary{}, false
+	}
+	return st.usage.Summary(), true
+}
+
 func (s *Service) newRunID(projectID string) string {
 	pid := strings.TrimSpace(projectID)
 	if pid == "" {
@@ -94,28 +439,162 @@ func (s *Service) executeRun(ctx context.Context, runID, projectID, workerID str
 	runEnv, err := s.project.EnsureRunContext(projectID)
 	if err != nil {
 		logctx.Error(ctx, "run ensure context failed", err, "run_id", runID, "project_id", projectID, "worker_id", workerID)
+		s.appendRunFailed(runID, workerID, err)
 		return
 	}
+	// The first run of a project builds its resolver and LLM client.
+	if runEnv != nil {
+		if err := runEnv.Materialize(); err != nil {
+			logctx.Error(ctx, "run materialize failed", err, "run_id", runID, "project_id", projectID, "worker_id", workerID)
+			s.appendRunFailed(runID, workerID, err)
+			return
+		}
+	}
 	if runEnv == nil || runEnv.Runtime() == nil || runEnv.Runtime().GetResolver() == nil {
 		logctx.Error(ctx, "run has no resolver", nil, "run_id", runID, "project_id", projectID, "worker_id", workerID)
+		s.appendRunFailed(runID, workerID, fmt.Errorf("project %s has no resolver", projectID))
 		return
 	}
 
-	execCtx := runner.WithRunID(ctx, runID)
+	execCtx := logctx.With(runner.WithRunID(ctx, runID), "run_id", runID, "project_id", projectID)
+	if rev := runner.RepoRevision(runEnv.Runtime()); !rev.IsZero() {
+		execCtx = logctx.With(execCtx, "git_commit", rev.Commit, "git_branch", rev.Branch)
+	}
 	if nodeID := strings.TrimSpace(params["node_id"]); nodeID != "" {
 		execCtx = runner.WithNodeID(execCtx, nodeID)
 	}
 	if s.interaction != nil {
 		execCtx = runner.WithInteractionWaiter(execCtx, s.interaction)
 	}
+	s.runMu.RLock()
+	promptLog := s.promptLog
+	phaseTimeout := s.phaseTimeout
+	retryBudget := s.retryBudget
+	runLockWait := s.runLockWait
+	s.runMu.RUnlock()
+	if phaseTimeout > 0 {
+		execCtx = runner.WithPhaseTimeout(execCtx, phaseTimeout)
+	}
+	execCtx = runner.WithInputWaitTimeout(execCtx, s.inputWaitTimeoutFor(projectID))
+	execCtx = runner.WithInputWaitEvents(execCtx, s.inputWaitEvents(runID, workerID))
+	execCtx = llmmiddleware.WithRetryBudget(execCtx, retryBudget)
+	execCtx = llmmiddleware.WithThrottleHook(execCtx, s.throttleEvents(runID, workerID), llmmiddleware.DefaultThrottleEventAfter)
+	execCtx = scan.WithTruncationHook(execCtx, s.scanTruncatedEvents(runID, workerID))
+	execCtx = runner.WithRunLockWait(execCtx, runLockWait)
+	if promptLog {
+		execCtx = llmmiddleware.WithPromptHook(execCtx, &hooks.PromptSaver{Dir: runEnv.GetOutDir(), RunID: runID})
+	}
+
+	execCtx = runner.WithPhaseHooks(execCtx, s.phaseEvents(runID, workerID))
+	execCtx = runner.WithProgress(execCtx, func(percent float64) {
+		s.telemetry.Append(runID, "worker", StageProgress, map[string]any{
+			"worker_id":        workerID,
+			"progress_percent": percent,
+		})
+	})
+	execCtx = graphdelta.WithEmitter(execCtx, s.graphDeltaEvents(runID, workerID))
 
-	out, err := runner.ExecuteWorker(execCtx, runEnv.Runtime(), workerID, params)
+	if isDryRun(params) {
+		s.executeDryRun(execCtx, runID, projectID, workerID, runEnv, params)
+		return
+	}
+
+	out, err := executeTargets(execCtx, runEnv.Runtime(), workerID, params)
+	// Usage comes before the terminal event, which closes the run's events.
+	if usage, ok := llmmiddleware.RunUsageFrom(ctx); ok {
+		s.appendRunUsage(runID, workerID, usage)
+	}
+	var skipErr *runner.RepoSkippedError
+	if errors.As(err, &skipErr) {
+		// Not a failure: the run completes with the assessment as its view.
+		s.telemetry.Append(runID, "worker", StageRepoSkipped, map[string]any{
+			"worker_id":  workerID,
+			"status":     RunStatusSkipped,
+			"code_files": skipErr.Assessment.CodeFiles,
+			"files":      skipErr.Assessment.Files,
+			"code_bytes": skipErr.Assessment.CodeBytes,
+			"reasons":    skipErr.Assessment.Reasons,
+		})
+		out, err = runner.WorkerOutput{ClientView: skipErr.View}, nil
+	}
 	if err != nil {
 		logctx.Error(ctx, "execute worker failed", err, "run_id", runID, "project_id", projectID, "worker_id", workerID)
+		var (
+			phaseErr  *runner.PhaseTimeoutError
+			lockErr   *runner.RunLockError
+			budgetErr *llmmiddleware.BudgetExceededError
+			sizeErr   *runner.ArtifactTooLargeError
+		)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded):
+			s.appendTerminal(runID, StageRunTimeout, map[string]any{
+				"worker_id": workerID,
+				"status":    RunStatusTimeout,
+				"error":     err.Error(),
+			})
+		case errors.As(err, &phaseErr):
+			s.appendTerminal(runID, StagePhaseTimeout, map[string]any{
+				"worker_id":  workerID,
+				"phase":      phaseErr.Phase,
+				"status":     RunStatusTimeout,
+				"elapsed_ms": phaseErr.Elapsed.Milliseconds(),
+				"timeout_ms": phaseErr.Timeout.Milliseconds(),
+				"error":      err.Error(),
+			})
+		case errors.Is(err, runner.ErrBudgetExhausted):
+			s.appendTerminal(runID, StageBudgetExhausted, map[string]any{
+				"worker_id": workerID,
+				"status":    RunStatusTimeout,
+				"error":     err.Error(),
+			})
+		case errors.As(err, &sizeErr):
+			s.appendTerminal(runID, StageArtifactTooLarge, map[string]any{
+				"worker_id":   workerID,
+				"phase":       sizeErr.Phase,
+				"status":      RunStatusFailed,
+				"size_bytes":  sizeErr.Size,
+				"limit_bytes": sizeErr.Limit,
+				"error":       err.Error(),
+			})
+		case errors.Is(err, safeio.ErrReadBudgetExceeded):
+			s.appendTerminal(runID, StageReadBudgetExceeded, map[string]any{
+				"worker_id": workerID,
+				"status":    RunStatusFailed,
+				"error":     err.Error(),
+			})
+		case errors.Is(err, llmmiddleware.ErrRetryBudgetExhausted):
+			s.appendTerminal(runID, StageRetryBudgetExhausted, map[string]any{
+				"worker_id":    workerID,
+				"status":       RunStatusFailed,
+				"retry_budget": retryBudget,
+				"error":        err.Error(),
+			})
+		case errors.As(err, &lockErr):
+			s.appendTerminal(runID, StageRunLocked, map[string]any{
+				"worker_id":     workerID,
+				"status":        RunStatusFailed,
+				"holder_run_id": lockErr.RunID,
+				"error":         err.Error(),
+			})
+		case errors.As(err, &budgetErr):
+			// Finished phases stay cached; rerun with a higher budget to resume.
+			s.appendTerminal(runID, StageCostBudgetExceeded, map[string]any{
+				"worker_id":     workerID,
+				"status":        RunStatusFailed,
+				"model":         budgetErr.Model,
+				"budget_usd":    budgetErr.BudgetUSD,
+				"spent_usd":     budgetErr.SpentUSD,
+				"estimated_usd": budgetErr.EstimatedUSD,
+				"error":         err.Error(),
+			})
+		default:
+			s.appendRunFailed(runID, workerID, err)
+		}
 		return
 	}
 
-	clientView := asClientView(out.ClientView)
+	// The view reaches the browser; redact internal text first.
+	clientView := SanitizeClientView(asClientView(out.ClientView))
 	if s.ui != nil {
 		_ = s.ui.UpsertFromClientView(runID, workerID, clientView)
 	}
@@ -126,15 +605,238 @@ func (s *Service) executeRun(ctx context.Context, runID, projectID, workerID str
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 			defer cancel()
 			ctx = traceutil.WithContext(ctx, traceutil.FromContext(execCtx))
-			if err := s.syncArtifacts(ctx, runID, projectID, runEnv.GetOutDir()); err != nil {
+			if err := s.syncArtifacts(ctx, runID, projectID, runEnv); err != nil {
 				logctx.Error(ctx, "failed to sync artifacts", err, "run_id", runID, "project_id", projectID, "worker_id", workerID)
 			}
 		}()
 	}
-	logctx.Info(execCtx, "worker run completed", "run_id", runID, "project_id", projectID, "worker_id", workerID)
+	logctx.Info(execCtx, "worker run completed", "worker_id", workerID)
+}
+
+// appendRunFailed ends a run that failed without a more specific terminal
+// stage.
+func (s *Service) appendRunFailed(runID, workerID string, err error) {
+	s.appendTerminal(runID, StageRunFailed, map[string]any{
+		"worker_id": workerID,
+		"status":    RunStatusFailed,
+		"error":     err.Error(),
+	})
+}
+
+// phaseEvents records the start and end of every phase of a run.
+func (s *Service) phaseEvents(runID, workerID string) runner.PhaseHooks {
+	return runner.PhaseHooks{
+		OnStart: func(_ context.Context, spec runner.WorkerSpec, rt runner.Runtime) {
+			fields := map[string]any{
+				"worker_id": workerID,
+				"phase":     spec.Key,
+			}
+			// Phases of a multi-repo run may each analyze another checkout.
+			addRepoRevision(fields, rt)
+			s.telemetry.Append(runID, "worker", StagePhaseStart, fields)
+		},
+		OnEnd: func(_ context.Context, spec runner.WorkerSpec, rt runner.Runtime, _ runner.WorkerOutput, err error, cached bool) {
+			fields := map[string]any{
+				"worker_id": workerID,
+				"phase":     spec.Key,
+				"cached":    cached,
+			}
+			addRepoRevision(fields, rt)
+			if err != nil {
+				fields["error"] = err.Error()
+			}
+			if errors.Is(err, runner.ErrRepoSkipped) {
+				fields["status"] = RunStatusSkipped
+			}
+			s.telemetry.Append(runID, "worker", StagePhaseEnd, fields)
+		},
+	}
+}
+
+// addRepoRevision adds the git commit and branch of rt's repository to
+// fields; repositories that are not git checkouts add nothing.
+func addRepoRevision(fields map[string]any, rt runner.Runtime) {
+	rev := runner.RepoRevision(rt)
+	if rev.Commit != "" {
+		fields["git_commit"] = rev.Commit
+	}
+	if rev.Branch != "" {
+		fields["git_branch"] = rev.Branch
+	}
+}
+
+// graphDeltaEvents records the graph deltas workers stream as progress
+// events whose view holds the added nodes and edges; removed node UIDs and
+// edges ride along. Replaying them with graphdelta.Apply rebuilds the
+// phase's graph before its final ClientView arrives.
+func (s *Service) graphDeltaEvents(runID, workerID string) graphdelta.Emitter {
+	return func(d graphdelta.Delta) {
+		fields := map[string]any{
+			"worker_id": workerID,
+			"phase":     d.Phase,
+			"view":      SanitizeClientView(d.View()),
+		}
+		if len(d.RemovedNodes) > 0 {
+			fields["removed_nodes"] = d.RemovedNodes
+		}
+		if len(d.RemovedEdges) > 0 {
+			fields["removed_edges"] = d.RemovedEdges
+		}
+		s.telemetry.Append(runID, "worker", StageProgress, fields)
+	}
+}
+
+// throttleEvents records the LLM calls of a run that wait on a rate limit,
+// so clients can tell a throttled run from a stuck one.
+func (s *Service) throttleEvents(runID, workerID string) llmmiddleware.ThrottleHook {
+	return func(ctx context.Context, ev llmmiddleware.ThrottleEvent) {
+		who := ev.Provider
+		if who == "" {
+			who = ev.Model
+		}
+		msg := "waiting for rate limit"
+		if who != "" {
+			msg = "throttled by " + who
+		}
+		if ev.Remaining > 0 {
+			msg += fmt.Sprintf(", resuming in ~%ds", int(math.Ceil(ev.Remaining.Seconds())))
+		}
+		// "source" is taken by the telemetry envelope.
+		fields := map[string]any{
+			"worker_id":       workerID,
+			"model":           ev.Model,
+			"throttle_source": ev.Source,
+			"waited_ms":       ev.Waited.Milliseconds(),
+			"message":         msg,
+		}
+		if ev.Provider != "" {
+			fields["provider"] = ev.Provider
+		}
+		if ev.Remaining > 0 {
+			fields["remaining_ms"] = ev.Remaining.Milliseconds()
+		}
+		if phase := llmmiddleware.PhaseFrom(ctx); phase != "" {
+			fields["phase"] = phase
+		}
+		s.telemetry.Append(runID, "worker", StageThrottled, fields)
+	}
+}
+
+// scanTruncatedEvents records the repository scans of a run that hit the
+// scan size limits.
+func (s *Service) scanTruncatedEvents(runID, workerID string) scan.TruncationHook {
+	return func(ctx context.Context, t scan.Truncation) {
+		fields := map[string]any{
+			"worker_id":     workerID,
+			"root":          filepath.Base(t.Root),
+			"files_scanned": t.Stats.FilesScanned,
+			"bytes_scanned": t.Stats.BytesScanned,
+			"files_skipped": t.Stats.FilesSkipped,
+			"bytes_skipped": t.Stats.BytesSkipped,
+		}
+		if phase := llmmiddleware.PhaseFrom(ctx); phase != "" {
+			fields["phase"] = phase
+		}
+		s.telemetry.Append(runID, "worker", StageScanTruncated, fields)
+	}
+}
+
+// appendRunUsage records the LLM usage of a run that returned.
+func (s *Service) appendRunUsage(runID, workerID string, usage *llmmiddleware.RunUsage) {
+	sum := usage.Summary()
+	fields := map[string]any{
+		"worker_id":     workerID,
+		"calls":         sum.Calls,
+		"input_tokens":  sum.InputTokens,
+		"output_tokens": sum.OutputTokens,
+		"cost_usd":      sum.CostUSD,
+	}
+	if sum.BudgetUSD > 0 {
+		fields["budget_usd"] = sum.BudgetUSD
+	}
+	if len(sum.UnpricedModels) > 0 {
+		fields["unpriced_models"] = sum.UnpricedModels
+	}
+	if sum.ThrottleMs > 0 {
+		fields["throttle_ms"] = sum.ThrottleMs
+	}
+	s.telemetry.Append(runID, "worker", StageRunUsage, fields)
+}
+
+func isDryRun(params map[string]string) bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(params["dry_run"]))
+	return err == nil && v
+}
+
+// executeDryRun plans the worker chain without calling the LLM and records a
+// summary event; the full report is persisted as runner.DryRunReportName.
+func (s *Service) executeDryRun(ctx context.Context, runID, projectID, workerID string, runEnv *runtimepkg.ProjectRuntime, params map[string]string) {
+	report, err := runner.DryRunWorker(ctx, runEnv.Runtime(), workerID, params)
+	if err != nil {
+		logctx.Error(ctx, "dry run failed", err, "worker_id", workerID)
+		return
+	}
+	cacheHits := 0
+	for _, phase := range report.Phases {
+		if phase.CacheHit {
+			cacheHits++
+		}
+	}
+	s.telemetry.Append(runID, "worker", "dry_run", map[string]any{
+		"worker_id":        workerID,
+		"phases":           len(report.Phases),
+		"cache_hits":       cacheHits,
+		"estimated_tokens": report.TotalTokens,
+		"token_capacity":   report.TokenCapacity,
+		"over_capacity":    report.OverCapacity,
+		"report":           runner.DryRunReportName,
+	})
+	if s.artifact != nil {
+		if err := s.syncArtifacts(ctx, runID, projectID, runEnv); err != nil {
+			logctx.Error(ctx, "failed to sync artifacts", err, "worker_id", workerID)
+		}
+	}
+	logctx.Info(ctx, "worker dry run completed", "worker_id", workerID, "estimated_tokens", report.TotalTokens)
 }
 
-func (s *Service) syncArtifacts(ctx context.Context, runID, projectID, outDir string) error {
+// syncArtifacts copies the artifacts of a finished run under runID and
+// records them on the project. With a blob store they are read from the
+// project's Latest namespace and snapshotted, parts included, below
+// <project>/<runID>/, the keys ArtifactView URLs are signed for and the
+// project service reads run copies from; the artifact store is left out so
+// each run is stored once. Otherwise OutDir is walked into the artifact
+// store.
+func (s *Service) syncArtifacts(ctx context.Context, runID, projectID string, runEnv *runtimepkg.ProjectRuntime) error {
+	if runEnv.Blob == nil {
+		return s.syncArtifactDir(ctx, runID, projectID, runEnv.GetOutDir())
+	}
+	latest := artifactblob.Key(projectID, artifactblob.Latest, "") + "/"
+	keys, err := runEnv.Blob.List(ctx, latest)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		rel := strings.TrimPrefix(key, latest)
+		// Hidden files are the run lock and in-flight writes of another run.
+		if strings.HasPrefix(path.Base(rel), ".") {
+			continue
+		}
+		content, err := artifactblob.ReadAll(ctx, runEnv.Blob, key)
+		if err != nil {
+			continue
+		}
+		if err := runEnv.Blob.Put(ctx, artifactblob.Key(projectID, runID, rel), bytes.NewReader(content)); err != nil {
+			return err
+		}
+		// Parts are listed under the name of their index.
+		if !runner.IsArtifactPart(rel) {
+			s.addProjectArtifact(ctx, runID, projectID, rel)
+		}
+	}
+	return nil
+}
+
+func (s *Service) syncArtifactDir(ctx context.Context, runID, projectID, outDir string) error {
 	return filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // skip errors
@@ -146,25 +848,41 @@ func (s *Service) syncArtifacts(ctx context.Context, runID, projectID, outDir st
 		if err != nil {
 			return nil
 		}
-		// Skip hidden files or internal dirs if needed, but for now persist all
-		content, err := os.ReadFile(path)
+		// Hidden files are the run lock and in-flight writes of another run.
+		// Parts are recorded reassembled, under the name of their index.
+		if strings.HasPrefix(d.Name(), ".") || runner.IsArtifactPart(d.Name()) {
+			return nil
+		}
+		dir := filepath.Dir(path)
+		content, err := runner.ReadArtifactFunc(ctx, d.Name(), func(_ context.Context, name string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(dir, name))
+		})
 		if err != nil {
+			logctx.Warn(ctx, "skipping unreadable artifact", "run_id", runID, "path", rel, "error", err)
 			return nil
 		}
 		// Normalize path to forward slashes
-		rel = filepath.ToSlash(rel)
-		if err := s.artifact.Put(ctx, runID, rel, content); err != nil {
-			return err
-		}
-
-		if s.projectStore != nil {
-			// Save metadata to project store
-			_ = s.projectStore.AddArtifact(ctx, projectrepo.ProjectArtifact{
-				ProjectID: projectID,
-				RunID:     runID,
-				Path:      rel,
-			})
-		}
-		return nil
+		return s.recordArtifact(ctx, runID, projectID, filepath.ToSlash(rel), content)
 	})
 }
+
+// recordArtifact stores one artifact of runID and adds it to the project.
+func (s *Service) recordArtifact(ctx context.Context, runID, projectID, rel string, content []byte) error {
+	if err := s.artifact.Put(ctx, runID, rel, content); err != nil {
+		return err
+	}
+	s.addProjectArtifact(ctx, runID, projectID, rel)
+	return nil
+}
+
+// addProjectArtifact lists the artifact rel of runID on the project.
+func (s *Service) addProjectArtifact(ctx context.Context, runID, projectID, rel string) {
+	if s.projectStore != nil {
+		// Save metadata to project store
+		_ = s.projectStore.AddArtifact(ctx, projectrepo.ProjectArtifact{
+			ProjectID: projectID,
+			RunID:     runID,
+			Path:      rel,
+		})
+	}
+}
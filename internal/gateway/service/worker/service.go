@@ -1,12 +1,14 @@
 package worker
 
 import (
+	"insightify/internal/gateway/entity"
 	artifactrepo "insightify/internal/gateway/repository/artifact"
 	projectrepo "insightify/internal/gateway/repository/project"
 	gatewayui "insightify/internal/gateway/service/ui"
 	"insightify/internal/runner"
 	runtimepkg "insightify/internal/workerruntime"
 	"sync"
+	"time"
 )
 
 // ProjectReader is an interface to read project state without circular dependency on project service.
@@ -22,7 +24,20 @@ type WorkspaceRunBinder interface {
 // ProjectView is a simplified view of a project.
 type ProjectView struct {
 	ProjectID string
+	UserID    entity.UserID // owner of the project
+	Repos     []string      // repository names of a multi-repo project
 	RunCtx    *runtimepkg.ProjectRuntime
+	// CostBudgetUSD is the LLM cost budget of runs that set none.
+	CostBudgetUSD float64
+	// InputWaitTimeout bounds the input waits of its runs; zero uses the
+	// server default.
+	InputWaitTimeout time.Duration
+	// Locale is the default locale run param of its runs.
+	Locale string
+	// MinCodeRatio and MinCodeBytes are the default repo gate thresholds
+	// of its runs; zero uses the defaults.
+	MinCodeRatio float64
+	MinCodeBytes int64
 }
 
 // Service manages runs and telemetry.
@@ -35,8 +50,31 @@ type Service struct {
 	artifact     artifactrepo.Store
 	telemetry    *TelemetryStore
 
-	runMu sync.RWMutex
-	runs  map[string]*WorkerRuntime
+	runMu   sync.RWMutex
+	runs    map[string]*WorkerRuntime
+	closing bool // set by Shutdown; StartRun then fails with ErrShuttingDown
+	// drainGrace lets active runs finish on their own before Shutdown
+	// cancels them.
+	drainGrace time.Duration
+	// runTimeout is the execution deadline of each run; zero means none.
+	runTimeout time.Duration
+	// phaseTimeout bounds phases without their own WorkerSpec.Timeout.
+	phaseTimeout time.Duration
+	// inputWaitTimeout bounds input waits that neither the worker nor the
+	// project bounds.
+	inputWaitTimeout time.Duration
+	// retryBudget caps LLM retries per run; zero means per-call only.
+	retryBudget int
+	// runLockWait is how long a run waits for another run of its project;
+	// zero refuses it at StartRun.
+	runLockWait time.Duration
+	// promptLog saves each run's prompts below OutDir/prompt/<run_id>.
+	promptLog bool
+	// maxRuns and runRetention bound the runs table; see SetRunRetention.
+	maxRuns      int
+	runRetention time.Duration
+	// version is the gateway_version label of its runs.
+	version string
 }
 
 func New(project ProjectReader, projectStore projectrepo.ArtifactRepository, workspaces WorkspaceRunBinder, ui *gatewayui.Service, interaction runner.InteractionWaiter, artifact artifactrepo.Store) *Service {
@@ -49,6 +87,9 @@ func New(project ProjectReader, projectStore projectrepo.ArtifactRepository, wor
 		artifact:     artifact,
 		telemetry:    NewTelemetryStore(),
 		runs:         make(map[string]*WorkerRuntime),
+		maxRuns:      DefaultMaxRuns,
+		runRetention: DefaultRunRetention,
+		version:      gatewayVersion(),
 	}
 }
 
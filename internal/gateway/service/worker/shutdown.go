@@ -0,0 +1,212 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	logctx "insightify/internal/common/logctx"
+)
+
+// ErrShuttingDown is returned by StartRun once Shutdown has begun.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+const (
+	// RunStatusName is the run artifact recording the metadata and state of
+	// a run, written when it starts, pauses, resumes and ends.
+	RunStatusName = "run_status.json"
+	// RunStatusFinished marks a run whose goroutine returned on its own.
+	RunStatusFinished = "finished"
+	// RunStatusInterrupted marks a run cancelled by a server shutdown; its
+	// RunStatus keeps the worker and params needed to start it again.
+	RunStatusInterrupted = "interrupted"
+	// StageServerShutdown is the terminal telemetry stage of interrupted runs.
+	StageServerShutdown = "server_shutdown"
+)
+
+// RunStatus is persisted as RunStatusName for every run. It outlives the run
+// table and the in-memory telemetry, so the project and labels of a pruned
+// run stay resolvable.
+type RunStatus struct {
+	RunID         string            `json:"run_id"`
+	ProjectID     string            `json:"project_id"`
+	WorkerID      string            `json:"worker_id"`
+	Params        map[string]string `json:"params,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Status        string            `json:"status"`
+	StartedAt     time.Time         `json:"started_at"`
+	InterruptedAt time.Time         `json:"interrupted_at"`
+	FinishedAt    time.Time         `json:"finished_at,omitzero"`
+	// NodeID and PausedAt describe the input wait of a paused run.
+	NodeID   string    `json:"node_id,omitempty"`
+	PausedAt time.Time `json:"paused_at,omitzero"`
+	// Events is the telemetry of an interrupted run up to its terminal
+	// event, flushed here because the in-memory store does not survive
+	// the restart.
+	Events []map[string]any `json:"events,omitempty"`
+}
+
+// runInteractionCloser is implemented by interaction waiters that can
+// release every pending wait of a run.
+type runInteractionCloser interface {
+	CloseRun(runID string)
+}
+
+// SetDrainGrace sets how long Shutdown lets active runs finish before
+// cancelling them. Zero cancels immediately.
+func (s *Service) SetDrainGrace(d time.Duration) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	s.drainGrace = d
+}
+
+// Shutdown stops accepting runs and drains the active ones: after the drain
+// grace it cancels them, closes their pending interactions, and waits for
+// them to return until ctx is done. Each run still active after the grace
+// gets a single terminal server_shutdown event, recorded after its goroutine
+// unwound so nothing follows it, and an interrupted RunStatus carrying the
+// run's telemetry.
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.runMu.Lock()
+	s.closing = true
+	grace := s.drainGrace
+	var active []*WorkerRuntime
+	for _, st := range s.runs {
+		if st.done != nil && !isDone(st.done) {
+			active = append(active, st)
+		}
+	}
+	s.runMu.Unlock()
+	if len(active) == 0 {
+		return nil
+	}
+
+	if grace > 0 {
+		graceCtx, cancel := context.WithTimeout(ctx, grace)
+		waitRuns(graceCtx, active)
+		cancel()
+	}
+
+	var interrupted []*WorkerRuntime
+	s.runMu.Lock()
+	for _, st := range active {
+		if isDone(st.done) {
+			continue
+		}
+		st.interrupted = true
+		interrupted = append(interrupted, st)
+	}
+	s.runMu.Unlock()
+	for _, st := range interrupted {
+		st.cancel()
+		if closer, ok := s.interaction.(runInteractionCloser); ok {
+			closer.CloseRun(st.RunID)
+		}
+	}
+	err := waitRuns(ctx, interrupted)
+
+	now := time.Now()
+	for _, st := range interrupted {
+		s.telemetry.AppendTerminal(st.RunID, "worker", StageServerShutdown, map[string]any{
+			"worker_id": st.WorkerID,
+			"status":    RunStatusInterrupted,
+			"error":     ErrShuttingDown.Error(),
+		})
+		status := st.runStatus(RunStatusInterrupted)
+		status.InterruptedAt = now
+		status.Events, _ = s.telemetry.Read(st.RunID)
+		s.persistRunStatus(ctx, status)
+	}
+	return err
+}
+
+// appendTerminal records the terminal event of a run the run goroutine
+// ended itself, and its status as the run's outcome for finishRun. Runs
+// interrupted by Shutdown are skipped: Shutdown records their
+// server_shutdown event once the goroutine has unwound.
+func (s *Service) appendTerminal(runID, stage string, fields map[string]any) {
+	s.runMu.Lock()
+	st := s.runs[runID]
+	interrupted := st != nil && st.interrupted
+	if st != nil && !interrupted {
+		if status, ok := fields["status"].(string); ok {
+			st.outcome = status
+		}
+	}
+	s.runMu.Unlock()
+	if interrupted {
+		return
+	}
+	s.telemetry.AppendTerminal(runID, "worker", stage, fields)
+}
+
+// runStatus returns the RunStatus of st with the given status.
+func (st *WorkerRuntime) runStatus(status string) RunStatus {
+	return RunStatus{
+		RunID:     st.RunID,
+		ProjectID: st.ProjectID,
+		WorkerID:  st.WorkerID,
+		Params:    st.params,
+		Labels:    st.labels,
+		Status:    status,
+		StartedAt: st.StartedAt,
+	}
+}
+
+// loadRunStatus reads the persisted RunStatus of runID.
+func (s *Service) loadRunStatus(ctx context.Context, runID string) (RunStatus, bool) {
+	if s.artifact == nil {
+		return RunStatus{}, false
+	}
+	raw, err := s.artifact.Get(ctx, runID, RunStatusName)
+	if err != nil {
+		return RunStatus{}, false
+	}
+	var status RunStatus
+	if err := json.Unmarshal(raw, &status); err != nil || status.RunID != runID {
+		return RunStatus{}, false
+	}
+	return status, true
+}
+
+func (s *Service) persistRunStatus(ctx context.Context, status RunStatus) {
+	if s.artifact == nil {
+		return
+	}
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	if ctx.Err() != nil {
+		// The drain used up the deadline; the status write still gets a
+		// short window of its own.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+	}
+	if err := s.artifact.Put(ctx, status.RunID, RunStatusName, raw); err != nil {
+		logctx.Error(ctx, "failed to persist run status", err, "run_id", status.RunID, "project_id", status.ProjectID)
+	}
+}
+
+// waitRuns blocks until every run has returned or ctx is done.
+func waitRuns(ctx context.Context, runs []*WorkerRuntime) error {
+	for _, st := range runs {
+		select {
+		case <-st.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func isDone(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
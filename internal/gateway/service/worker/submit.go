@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/gateway/entity"
+)
+
+// ErrForbidden matches errors of requests on runs of another user's project.
+var ErrForbidden = errors.New("run does not belong to user")
+
+// InputSender delivers user input to a waiting interaction and can map an
+// interaction back to the run/node that owns it.
+type InputSender interface {
+	ResolveInteraction(interactionID string) (runID, nodeID string, err error)
+	Send(ctx context.Context, req *insightifyv1.SendRequest) (*insightifyv1.SendResponse, error)
+}
+
+// nonceInputSender is implemented by input senders that deduplicate submits
+// carrying the same client nonce.
+type nonceInputSender interface {
+	SendOnce(ctx context.Context, req *insightifyv1.SendRequest, nonce string) (*insightifyv1.SendResponse, error)
+}
+
+// SubmitInputRequest carries user input. Either RunID+NodeID or InteractionID
+// must be set; ProjectID is inferred from the run when omitted.
+type SubmitInputRequest struct {
+	// UserID, when set, must own the project of the run.
+	UserID        entity.UserID
+	ProjectID     string
+	RunID         string
+	NodeID        string
+	InteractionID string
+	Input         string
+	// Nonce makes the submit idempotent: a retry with the same nonce returns
+	// the first result instead of delivering the input twice.
+	Nonce string
+}
+
+// SubmitInputResult reports the resolved IDs alongside the send outcome.
+type SubmitInputResult struct {
+	ProjectID     string
+	RunID         string
+	NodeID        string
+	InteractionID string
+	Accepted      bool
+	// Resumed is set when the input answered a run paused on it.
+	Resumed bool
+}
+
+// ProjectIDForRun returns the project a run was started for.
+func (s *Service) ProjectIDForRun(runID string) (string, bool) {
+	s.runMu.RLock()
+	defer s.runMu.RUnlock()
+	st, ok := s.runs[strings.TrimSpace(runID)]
+	if !ok || st == nil {
+		return "", false
+	}
+	return st.ProjectID, true
+}
+
+// SubmitInput sends user input to a run. When only the interaction ID is
+// known (e.g. from the chat UI), run/node are resolved from it and the
+// project is inferred from the run.
+func (s *Service) SubmitInput(ctx context.Context, req SubmitInputRequest) (*SubmitInputResult, error) {
+	sender, ok := s.interaction.(InputSender)
+	if !ok || sender == nil {
+		return nil, fmt.Errorf("interaction input is not configured")
+	}
+	projectID := strings.TrimSpace(req.ProjectID)
+	runID := strings.TrimSpace(req.RunID)
+	nodeID := strings.TrimSpace(req.NodeID)
+	interactionID := strings.TrimSpace(req.InteractionID)
+	input := strings.TrimSpace(req.Input)
+	if input == "" {
+		return nil, fmt.Errorf("input is required")
+	}
+
+	if runID == "" || nodeID == "" {
+		if interactionID == "" {
+			return nil, fmt.Errorf("run_id and node_id, or interaction_id, are required")
+		}
+		resolvedRun, resolvedNode, err := sender.ResolveInteraction(interactionID)
+		if err != nil {
+			return nil, err
+		}
+		if runID != "" && runID != resolvedRun {
+			return nil, fmt.Errorf("interaction %s does not belong to run %s", interactionID, runID)
+		}
+		if nodeID != "" && nodeID != resolvedNode {
+			return nil, fmt.Errorf("interaction %s does not belong to node %s", interactionID, nodeID)
+		}
+		runID, nodeID = resolvedRun, resolvedNode
+	}
+
+	if owner, ok := s.ProjectIDForRun(runID); ok {
+		if projectID != "" && projectID != owner {
+			return nil, fmt.Errorf("run %s does not belong to project %s", runID, projectID)
+		}
+		projectID = owner
+	} else if projectID == "" {
+		return nil, fmt.Errorf("project not found for run %s", runID)
+	}
+	if req.UserID != "" {
+		if err := s.AuthorizeProject(projectID, req.UserID); err != nil {
+			return nil, fmt.Errorf("run %s: %w", runID, err)
+		}
+	}
+
+	// Read the paused state before sending: once the paused wait takes the
+	// input it resumes the run on its own and clears the state.
+	pausedNode, paused := s.RunPaused(runID)
+	sendReq := &insightifyv1.SendRequest{
+		RunId:         runID,
+		NodeId:        nodeID,
+		InteractionId: interactionID,
+		Input:         input,
+	}
+	var out *insightifyv1.SendResponse
+	var err error
+	if once, ok := sender.(nonceInputSender); ok && strings.TrimSpace(req.Nonce) != "" {
+		out, err = once.SendOnce(ctx, sendReq, req.Nonce)
+	} else {
+		out, err = sender.Send(ctx, sendReq)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SubmitInputResult{
+		ProjectID:     projectID,
+		RunID:         runID,
+		NodeID:        nodeID,
+		InteractionID: out.GetInteractionId(),
+		Accepted:      out.GetAccepted(),
+		Resumed:       paused && pausedNode == nodeID && out.GetAccepted(),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+
+	workerv1 "insightify/gen/go/worker/v1"
+)
+
+func TestSanitizeClientViewRedactsInternalTextKeepsGraph(t *testing.T) {
+	view := &workerv1.ClientView{
+		Phase: "arch_design",
+		Content: &workerv1.ClientView_Graph{Graph: &workerv1.GraphView{
+			Nodes: []*workerv1.GraphNode{
+				{Uid: "n1", Label: "Runner", Description: "Executes phases.\n```go\npackage runner\nfunc secret() {}\n```\nSee executor."},
+				{Uid: "n2", Label: "Store", ParentUid: "n1", Description: "Persists runs. <internal>summary: reads DATABASE_URL from env</internal>"},
+				{Uid: "n3", Label: "Long", Description: strings.Repeat("x", 3000)},
+			},
+			Edges: []*workerv1.GraphEdge{{From: "n1", To: "n2"}, {From: "n2", To: "n3"}},
+		}},
+	}
+
+	got := SanitizeClientView(view)
+	nodes := got.GetGraph().GetNodes()
+	if len(nodes) != 3 || len(got.GetGraph().GetEdges()) != 2 || got.GetPhase() != "arch_design" {
+		t.Fatalf("graph structure changed: %v", got)
+	}
+	for i, want := range []struct{ uid, label, parent string }{{"n1", "Runner", ""}, {"n2", "Store", "n1"}, {"n3", "Long", ""}} {
+		if n := nodes[i]; n.GetUid() != want.uid || n.GetLabel() != want.label || n.GetParentUid() != want.parent {
+			t.Fatalf("node %d = %v, want %+v", i, n, want)
+		}
+	}
+	if e := got.GetGraph().GetEdges()[0]; e.GetFrom() != "n1" || e.GetTo() != "n2" {
+		t.Fatalf("edge = %v", e)
+	}
+
+	if d := nodes[0].GetDescription(); strings.Contains(d, "func secret") || !strings.Contains(d, RedactedMarker) || !strings.HasPrefix(d, "Executes phases.") || !strings.HasSuffix(d, "See executor.") {
+		t.Fatalf("code block not stripped: %q", d)
+	}
+	if d := nodes[1].GetDescription(); strings.Contains(d, "DATABASE_URL") || d != "Persists runs. "+RedactedMarker {
+		t.Fatalf("internal block not stripped: %q", d)
+	}
+	if d := []rune(nodes[2].GetDescription()); len(d) != DefaultClientViewPolicy.MaxDescriptionRunes+1 {
+		t.Fatalf("long description has %d runes", len(d))
+	}
+
+	// The worker's own view is left as it was.
+	if !strings.Contains(view.GetGraph().GetNodes()[1].GetDescription(), "DATABASE_URL") {
+		t.Fatalf("SanitizeClientView modified its input")
+	}
+}
+
+func TestSanitizeClientViewLLMResponse(t *testing.T) {
+	view := &workerv1.ClientView{Content: &workerv1.ClientView_LlmResponse{
+		LlmResponse: "Which service owns billing?\n<internal>candidates from internal/billing/secret.go</internal>\n```sh\nmake billing\n```",
+	}}
+	got := SanitizeClientView(view).GetLlmResponse()
+	if strings.Contains(got, "secret.go") || !strings.HasPrefix(got, "Which service owns billing?") {
+		t.Fatalf("LLM response = %q", got)
+	}
+	// Code in a chat answer is for the user and stays.
+	if !strings.Contains(got, "make billing") {
+		t.Fatalf("code block removed from LLM response: %q", got)
+	}
+	if SanitizeClientView(nil) != nil {
+		t.Fatalf("nil view should stay nil")
+	}
+}
@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	llmclient "insightify/internal/llm/client"
+	llmmiddleware "insightify/internal/llm/middleware"
+	"insightify/internal/runner"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+// pricedLLM answers every call with a fixed 4-token response; one character
+// is one token.
+type pricedLLM struct{}
+
+func (pricedLLM) Name() string                { return "priced" }
+func (pricedLLM) Close() error                { return nil }
+func (pricedLLM) CountTokens(text string) int { return len(text) }
+func (pricedLLM) TokenCapacity() int          { return 1 << 20 }
+func (pricedLLM) GenerateJSON(context.Context, string, any) (json.RawMessage, error) {
+	return json.RawMessage(`"ok"`), nil
+}
+func (l pricedLLM) GenerateJSONStream(ctx context.Context, prompt string, input any, _ func(string)) (json.RawMessage, error) {
+	return l.GenerateJSON(ctx, prompt, input)
+}
+
+type budgetProjectReader struct {
+	slowProjectReader
+	budget float64
+}
+
+func (r budgetProjectReader) GetEntry(projectID string) (ProjectView, bool) {
+	return ProjectView{ProjectID: projectID, CostBudgetUSD: r.budget}, true
+}
+
+// newBudgetProjectReader runs "spend", which makes three calls of 11 input
+// tokens ("prompt\nnull") to a model priced at $1 per token both ways.
+func newBudgetProjectReader(t *testing.T, budget float64) budgetProjectReader {
+	t.Helper()
+	model := llmmiddleware.WithLimiterKeyIn(llmmiddleware.NewLimiterRegistry(), llmmiddleware.LimiterKey{Provider: "test", Model: "priced"})(pricedLLM{})
+	price := func(llmmiddleware.LimiterKey) (llmclient.Pricing, bool) {
+		return llmclient.Pricing{InputPerMTok: 1e6, OutputPerMTok: 1e6}, true
+	}
+	cli := llmmiddleware.Wrap(pricedLLM{}, llmmiddleware.RecordRunUsage(price))
+	return budgetProjectReader{budget: budget, slowProjectReader: slowProjectReader{rt: &runtimepkg.ProjectRuntime{
+		ID:     "project-1",
+		OutDir: t.TempDir(),
+		Resolver: runner.MergeRegistries(map[string]runner.WorkerSpec{
+			"spend": {
+				Key: "spend",
+				Run: func(ctx context.Context, _ any, _ runner.Runtime) (runner.WorkerOutput, error) {
+					ctx = llmmiddleware.WithSelectedClient(ctx, model)
+					for i := 0; i < 3; i++ {
+						if _, err := cli.GenerateJSON(ctx, "prompt", nil); err != nil {
+							return runner.WorkerOutput{}, err
+						}
+					}
+					return runner.WorkerOutput{}, nil
+				},
+			},
+		}),
+	}}}
+}
+
+func TestCostBudgetStopsRunWithTerminalEvent(t *testing.T) {
+	// Each call costs $11 + $4; the project default allows two of them.
+	svc := New(newBudgetProjectReader(t, 35), nil, nil, nil, nil, nil)
+	res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: "spend"})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	runID := res.GetRunId()
+	waitRun(t, svc, runID, nil)
+
+	events, _ := svc.Telemetry().Read(runID)
+	if len(events) < 2 {
+		t.Fatalf("events = %v, want usage and terminal events", events)
+	}
+	usage, last := events[len(events)-2], events[len(events)-1]
+	if last["stage"] != StageCostBudgetExceeded || last["terminal"] != true || last["status"] != RunStatusFailed {
+		t.Fatalf("last event = %v, want terminal %s", last, StageCostBudgetExceeded)
+	}
+	if last["budget_usd"] != 35.0 || last["spent_usd"] != 30.0 || last["model"] != "test:priced" {
+		t.Fatalf("last event = %v, want $30 of $35 spent on test:priced", last)
+	}
+	if usage["stage"] != StageRunUsage || usage["calls"] != 2 || usage["cost_usd"] != 30.0 {
+		t.Fatalf("usage event = %v, want 2 calls costing $30", usage)
+	}
+	if sum, ok := svc.RunUsage(runID); !ok || sum.CostUSD != 30 || sum.BudgetUSD != 35 || len(sum.UnpricedModels) != 0 {
+		t.Fatalf("RunUsage() = %+v, %v", sum, ok)
+	}
+
+	// A run's own budget replaces the project default.
+	res, err = svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{
+		ProjectId: "project-1",
+		WorkerId:  "spend",
+		Params:    map[string]string{runner.RunParamCostBudgetUSD: "45"},
+	})
+	if err != nil {
+		t.Fatalf("StartRun() with budget error = %v", err)
+	}
+	waitRun(t, svc, res.GetRunId(), nil)
+	events, _ = svc.Telemetry().Read(res.GetRunId())
+	if last := events[len(events)-1]; last["stage"] != StageRunUsage || last["cost_usd"] != 45.0 {
+		t.Fatalf("last event = %v, want a finished run costing $45", last)
+	}
+
+	if _, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{
+		ProjectId: "project-1",
+		WorkerId:  "spend",
+		Params:    map[string]string{runner.RunParamCostBudgetUSD: "-1"},
+	}); err == nil {
+		t.Fatalf("StartRun() accepted a negative budget")
+	}
+}
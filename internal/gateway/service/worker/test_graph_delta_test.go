@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/common/graphdelta"
+)
+
+func TestGraphDeltaEventsAreSanitizedProgressEvents(t *testing.T) {
+	s := &Service{telemetry: NewTelemetryStore()}
+	emit := s.graphDeltaEvents("run-1", "code_graph")
+	emit(graphdelta.Delta{
+		Phase:      "code_graph",
+		AddedNodes: []*workerv1.GraphNode{{Uid: "a", Label: "a.go", Description: "ok <internal>secret</internal>"}},
+		AddedEdges: []*workerv1.GraphEdge{{From: "a", To: "b"}},
+	})
+	emit(graphdelta.Delta{Phase: "code_graph", RemovedEdges: []*workerv1.GraphEdge{{From: "a", To: "b"}}})
+
+	events, _ := s.telemetry.Read("run-1")
+	if len(events) != 2 {
+		t.Fatalf("events = %v, want 2", events)
+	}
+	for _, evt := range events {
+		if evt["stage"] != StageProgress || evt["phase"] != "code_graph" {
+			t.Fatalf("event = %v, want a code_graph progress event", evt)
+		}
+	}
+	view, _ := events[0]["view"].(*workerv1.ClientView)
+	nodes := view.GetGraph().GetNodes()
+	if len(nodes) != 1 || strings.Contains(nodes[0].Description, "secret") || len(view.GetGraph().GetEdges()) != 1 {
+		t.Fatalf("partial view = %v, want the sanitized added node and edge", view)
+	}
+	if removed, _ := events[1]["removed_edges"].([]*workerv1.GraphEdge); len(removed) != 1 {
+		t.Fatalf("second event = %v, want the removed edge", events[1])
+	}
+}
@@ -0,0 +1,176 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/gateway/entity"
+)
+
+func TestStartRunValidatesLabels(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := 0; i <= MaxRunLabels; i++ {
+		tooMany[fmt.Sprintf("%sk%d", LabelParamPrefix, i)] = "v"
+	}
+	cases := []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "uppercase key", params: map[string]string{LabelParamPrefix + "Env": "nightly"}},
+		{name: "empty key", params: map[string]string{LabelParamPrefix: "nightly"}},
+		{name: "leading dot", params: map[string]string{LabelParamPrefix + ".env": "nightly"}},
+		{name: "long key", params: map[string]string{LabelParamPrefix + strings.Repeat("k", MaxRunLabelKeyLen+1): "v"}},
+		{name: "long value", params: map[string]string{LabelParamPrefix + "env": strings.Repeat("v", MaxRunLabelValueLen+1)}},
+		{name: "too many", params: tooMany},
+		{name: "gateway label", params: map[string]string{LabelParamPrefix + LabelWorker: "other"}},
+	}
+	svc := New(testProjectReader{}, nil, nil, nil, nil, nil)
+	for _, tc := range cases {
+		_, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{
+			ProjectId: "project-1",
+			WorkerId:  "any",
+			Params:    tc.params,
+		})
+		if !errors.Is(err, ErrInvalidRun) {
+			t.Fatalf("%s: StartRun() error = %v, want ErrInvalidRun", tc.name, err)
+		}
+	}
+	if n := len(svc.ListRuns(entity.DemoUserID, nil)); n != 0 {
+		t.Fatalf("rejected requests started %d runs", n)
+	}
+}
+
+func TestRunLabelsArePersistedWithRunStatus(t *testing.T) {
+	started := make(chan struct{})
+	artifacts := &recordingArtifactStore{}
+	svc := New(newSlowProjectReader(t, started), nil, nil, nil, &closingInteraction{}, artifacts)
+	res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{
+		ProjectId: "project-1",
+		WorkerId:  "slow",
+		Params:    map[string]string{"node_id": "n1", LabelParamPrefix + "env": "nightly"},
+	})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("slow worker did not start")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := svc.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	raw, err := artifacts.Get(context.Background(), res.GetRunId(), RunStatusName)
+	if err != nil {
+		t.Fatalf("run status not persisted: %v", err)
+	}
+	var status RunStatus
+	if err := json.Unmarshal(raw, &status); err != nil {
+		t.Fatalf("decode run status: %v", err)
+	}
+	want := map[string]string{"env": "nightly", LabelWorker: "slow", LabelProjectID: "project-1", LabelGatewayVersion: svc.version}
+	if len(status.Labels) != len(want) || !MatchLabels(status.Labels, want) {
+		t.Fatalf("labels = %v, want %v", status.Labels, want)
+	}
+	if _, ok := status.Params[LabelParamPrefix+"env"]; ok || status.Params["node_id"] != "n1" {
+		t.Fatalf("params = %v, want worker params only", status.Params)
+	}
+
+	events, _ := svc.Telemetry().Read(res.GetRunId())
+	for _, evt := range events {
+		labels, _ := evt["labels"].(map[string]string)
+		if labels["env"] != "nightly" {
+			t.Fatalf("event %v lacks the run labels", evt)
+		}
+	}
+}
+
+func TestListRunsFiltersByLabels(t *testing.T) {
+	svc := New(testProjectReader{}, nil, nil, nil, nil, nil)
+	start := func(projectID string, labels map[string]string) string {
+		params := map[string]string{}
+		for k, v := range labels {
+			params[LabelParamPrefix+k] = v
+		}
+		res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{
+			ProjectId: projectID,
+			WorkerId:  "any",
+			Params:    params,
+		})
+		if err != nil {
+			t.Fatalf("StartRun() error = %v", err)
+		}
+		return res.GetRunId()
+	}
+	nightly := start("project-a", map[string]string{"env": "nightly", "team": "core"})
+	interactive := start("project-b", map[string]string{"env": "interactive", "team": "core"})
+
+	cases := []struct {
+		selector []string
+		want     []string
+	}{
+		{selector: []string{"env=nightly"}, want: []string{nightly}},
+		{selector: []string{"team=core", "env=interactive"}, want: []string{interactive}},
+		{selector: []string{"team=core,project_id=project-a"}, want: []string{nightly}},
+		{selector: []string{"env=staging"}, want: nil},
+		{selector: nil, want: []string{nightly, interactive}},
+	}
+	for _, tc := range cases {
+		sel, err := ParseLabelSelector(tc.selector)
+		if err != nil {
+			t.Fatalf("ParseLabelSelector(%v) error = %v", tc.selector, err)
+		}
+		var got []string
+		for _, run := range svc.ListRuns(entity.DemoUserID, sel) {
+			got = append(got, run.RunID)
+		}
+		if !sameRunIDs(got, tc.want) {
+			t.Fatalf("ListRuns(%v) = %v, want %v", tc.selector, got, tc.want)
+		}
+	}
+	if runs := svc.ListRuns("other-user", nil); len(runs) != 0 {
+		t.Fatalf("ListRuns() by another user = %v, want none", runs)
+	}
+
+	// Telemetry listing honours the same selectors once the runs logged.
+	svc.Telemetry().Append(nightly, "worker", "probe", nil)
+	svc.Telemetry().Append(interactive, "worker", "probe", nil)
+	if got := svc.Telemetry().LatestRunsMatching(10, map[string]string{"env": "interactive"}); !sameRunIDs(got, []string{interactive}) {
+		t.Fatalf("LatestRunsMatching = %v, want %s", got, interactive)
+	}
+	if got := svc.LatestRuns(entity.DemoUserID, 10, nil); !sameRunIDs(got, []string{nightly, interactive}) {
+		t.Fatalf("LatestRuns() = %v, want both runs", got)
+	}
+	if got := svc.LatestRuns("other-user", 10, nil); len(got) != 0 {
+		t.Fatalf("LatestRuns() by another user = %v, want none", got)
+	}
+
+	if _, err := ParseLabelSelector([]string{"env"}); err == nil {
+		t.Fatalf("ParseLabelSelector accepted a term without '='")
+	}
+}
+
+func sameRunIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, id := range got {
+		seen[id] = true
+	}
+	for _, id := range want {
+		if !seen[id] {
+			return false
+		}
+	}
+	return true
+}
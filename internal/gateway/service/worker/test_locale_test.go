@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"insightify/internal/runner"
+)
+
+type localeProjectReader struct {
+	testProjectReader
+	locale string
+}
+
+func (r localeProjectReader) GetEntry(projectID string) (ProjectView, bool) {
+	return ProjectView{ProjectID: projectID, Locale: r.locale}, true
+}
+
+func TestWithLocalePrecedence(t *testing.T) {
+	cases := []struct {
+		name    string
+		param   string
+		project string
+		header  string
+		want    string
+	}{
+		{"param wins", "en", "ja", "fr", "en"},
+		{"project over header", "", "ja", "en-US", "ja"},
+		{"header fallback", "", "", "ja-JP,ja;q=0.9", "ja-JP,ja;q=0.9"},
+		{"unset", "", "", "", ""},
+	}
+	for _, tc := range cases {
+		s := &Service{project: localeProjectReader{locale: tc.project}}
+		params := map[string]string{"user_input": "hi"}
+		if tc.param != "" {
+			params[runner.RunParamLocale] = tc.param
+		}
+		got := s.withLocale(WithAcceptLanguage(context.Background(), tc.header), "p1", params)
+		if got[runner.RunParamLocale] != tc.want || got["user_input"] != "hi" {
+			t.Fatalf("%s: params = %v, want locale %q", tc.name, got, tc.want)
+		}
+		if tc.param == "" && params[runner.RunParamLocale] != "" {
+			t.Fatalf("%s: request params were modified: %v", tc.name, params)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/runner"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+func newRetentionService(t *testing.T) *Service {
+	t.Helper()
+	reader := slowProjectReader{rt: &runtimepkg.ProjectRuntime{
+		ID:     "project-1",
+		OutDir: t.TempDir(),
+		Resolver: runner.MergeRegistries(map[string]runner.WorkerSpec{
+			"boom": {
+				Key: "boom",
+				Run: func(context.Context, any, runner.Runtime) (runner.WorkerOutput, error) {
+					panic("pipeline exploded")
+				},
+			},
+			"fail": {
+				Key: "fail",
+				Run: func(context.Context, any, runner.Runtime) (runner.WorkerOutput, error) {
+					return runner.WorkerOutput{}, errors.New("pipeline failed")
+				},
+			},
+		}),
+	}}
+	return New(reader, nil, nil, nil, nil, &recordingArtifactStore{})
+}
+
+func startAndWait(t *testing.T, svc *Service) string {
+	t.Helper()
+	return startWorkerAndWait(t, svc, "boom")
+}
+
+func startWorkerAndWait(t *testing.T, svc *Service, workerID string) string {
+	t.Helper()
+	res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: workerID})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	svc.runMu.RLock()
+	st := svc.runs[res.GetRunId()]
+	svc.runMu.RUnlock()
+	select {
+	case <-st.done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("run %s did not return", res.GetRunId())
+	}
+	return res.GetRunId()
+}
+
+func TestPanickingRunIsRecoveredAndFinished(t *testing.T) {
+	svc := newRetentionService(t)
+	runID := startAndWait(t, svc)
+
+	events, _ := svc.Telemetry().Read(runID)
+	if len(events) == 0 {
+		t.Fatalf("no telemetry for panicked run")
+	}
+	last := events[len(events)-1]
+	if last["stage"] != StageRunPanic || last["status"] != RunStatusFailed || last["terminal"] != true {
+		t.Fatalf("last event = %v, want terminal %s", last, StageRunPanic)
+	}
+	if got := svc.RunCounts(); got.Active != 0 || got.Finished != 1 {
+		t.Fatalf("RunCounts() = %+v, want the run finished", got)
+	}
+	// Late lookups still find the run within the retention window.
+	if _, ok := svc.ProjectIDForRun(runID); !ok {
+		t.Fatalf("finished run should stay queryable during retention")
+	}
+	if status, ok := svc.loadRunStatus(context.Background(), runID); !ok || status.Status != RunStatusFailed {
+		t.Fatalf("persisted status = %+v (found %v), want %s", status, ok, RunStatusFailed)
+	}
+}
+
+func TestFailedRunPersistsFailedStatus(t *testing.T) {
+	svc := newRetentionService(t)
+	runID := startWorkerAndWait(t, svc, "fail")
+
+	events, _ := svc.Telemetry().Read(runID)
+	if len(events) == 0 {
+		t.Fatalf("no telemetry for failed run")
+	}
+	last := events[len(events)-1]
+	if last["stage"] != StageRunFailed || last["status"] != RunStatusFailed || last["terminal"] != true {
+		t.Fatalf("last event = %v, want terminal %s", last, StageRunFailed)
+	}
+	status, ok := svc.loadRunStatus(context.Background(), runID)
+	if !ok {
+		t.Fatalf("no persisted status for run %s", runID)
+	}
+	if status.Status != RunStatusFailed || status.FinishedAt.IsZero() {
+		t.Fatalf("persisted status = %+v, want %s with finished_at", status, RunStatusFailed)
+	}
+}
+
+func TestRunRetentionEvictsFinishedRuns(t *testing.T) {
+	svc := newRetentionService(t)
+	svc.SetRunRetention(2, time.Hour)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		ids = append(ids, startAndWait(t, svc))
+	}
+	if got := svc.RunCounts(); got.Finished != 2 {
+		t.Fatalf("RunCounts() = %+v, want 2 finished runs", got)
+	}
+	if _, ok := svc.ProjectIDForRun(ids[0]); ok {
+		t.Fatalf("oldest finished run should be evicted first")
+	}
+	if events, _ := svc.Telemetry().Read(ids[0]); len(events) != 0 {
+		t.Fatalf("evicted run kept telemetry: %v", events)
+	}
+	if _, ok := svc.ProjectIDForRun(ids[2]); !ok {
+		t.Fatalf("newest run should be kept")
+	}
+
+	// Active runs are never evicted.
+	svc.runMu.Lock()
+	svc.runs["active"] = &WorkerRuntime{RunID: "active", ProjectID: "project-1"}
+	svc.runMu.Unlock()
+	if n := svc.SweepRuns(time.Now().Add(2 * time.Hour)); n != 2 {
+		t.Fatalf("SweepRuns() removed %d, want the 2 finished runs", n)
+	}
+	if got := svc.RunCounts(); got.Active != 1 || got.Finished != 0 {
+		t.Fatalf("RunCounts() after sweep = %+v", got)
+	}
+}
+
+func TestPrunedRunKeepsItsPersistedStatus(t *testing.T) {
+	svc := newRetentionService(t)
+	svc.SetRunRetention(0, time.Nanosecond)
+	runID := startAndWait(t, svc)
+	svc.SweepRuns(time.Now().Add(time.Hour))
+	if _, ok := svc.ProjectIDForRun(runID); ok {
+		t.Fatalf("run %s was not pruned", runID)
+	}
+
+	status, ok := svc.loadRunStatus(context.Background(), runID)
+	if !ok {
+		t.Fatalf("run status of %s not persisted", runID)
+	}
+	// The boom worker panics, so the run ends failed.
+	if status.Status != RunStatusFailed || status.FinishedAt.IsZero() || status.Labels[LabelProjectID] != "project-1" {
+		t.Fatalf("run status = %+v, want failed with the run labels", status)
+	}
+	if err := svc.AuthorizeRun(runID, "other-user"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("AuthorizeRun() of a pruned run error = %v, want ErrForbidden", err)
+	}
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/runner"
+)
+
+func TestStartRunRefusesSecondRunOfProject(t *testing.T) {
+	started := make(chan struct{})
+	svc := New(newSlowProjectReader(t, started), nil, nil, nil, nil, nil)
+
+	res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: "slow"})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	first := res.GetRunId()
+	<-started
+
+	_, err = svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: "slow"})
+	var lockErr *runner.RunLockError
+	if !errors.Is(err, runner.ErrRunLocked) || !errors.As(err, &lockErr) || lockErr.RunID != first || !strings.Contains(err.Error(), first) {
+		t.Fatalf("second StartRun() error = %v, want lock held by %s", err, first)
+	}
+
+	// Other projects are not affected.
+	other, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-2", WorkerId: "missing"})
+	if err != nil {
+		t.Fatalf("StartRun() of another project error = %v", err)
+	}
+	waitRun(t, svc, other.GetRunId(), nil)
+
+	waitRun(t, svc, first, func(st *WorkerRuntime) { st.cancel() })
+	again, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: "missing"})
+	if err != nil {
+		t.Fatalf("StartRun() after the first run finished error = %v", err)
+	}
+	waitRun(t, svc, again.GetRunId(), nil)
+}
+
+// waitRun applies stop to runID, if given, and waits for the run to return.
+func waitRun(t *testing.T, svc *Service, runID string, stop func(*WorkerRuntime)) {
+	t.Helper()
+	svc.runMu.RLock()
+	st := svc.runs[runID]
+	svc.runMu.RUnlock()
+	if stop != nil {
+		stop(st)
+	}
+	select {
+	case <-st.done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("run %s did not return", runID)
+	}
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/runner"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+// newPhasesProjectReader serves specs a, b (requires a) of pipeline "left"
+// and c of pipeline "right", recording which of them ran.
+func newPhasesProjectReader(t *testing.T) (slowProjectReader, func() []string) {
+	t.Helper()
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+	spec := func(key, pipeline string, requires ...string) runner.WorkerSpec {
+		return runner.WorkerSpec{
+			Key:      key,
+			Pipeline: pipeline,
+			Requires: requires,
+			BuildInput: func(_ context.Context, deps runner.Deps) (any, error) {
+				for _, r := range requires {
+					var v string
+					if err := deps.Artifact(r, &v); err != nil {
+						return nil, err
+					}
+				}
+				return key, nil
+			},
+			Run: func(context.Context, any, runner.Runtime) (runner.WorkerOutput, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, key)
+				return runner.WorkerOutput{RuntimeState: key}, nil
+			},
+		}
+	}
+	reader := slowProjectReader{rt: &runtimepkg.ProjectRuntime{
+		ID:     "project-1",
+		OutDir: t.TempDir(),
+		Resolver: runner.MergeRegistries(map[string]runner.WorkerSpec{
+			"a": spec("a", "left"),
+			"b": spec("b", "left", "a"),
+			"c": spec("c", "right"),
+		}),
+	}}
+	return reader, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := slices.Clone(ran)
+		ran = nil
+		slices.Sort(out)
+		return out
+	}
+}
+
+func TestStartRunSelectsPhases(t *testing.T) {
+	cases := []struct {
+		name   string
+		phases string
+		want   []string
+	}{
+		{name: "phase with its requires", phases: "b", want: []string{"a", "b"}},
+		{name: "pipeline", phases: "right", want: []string{"c"}},
+		{name: "phase and pipeline", phases: "a, right", want: []string{"a", "c"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// A fresh project per case, so no phase is served from cache.
+			reader, ran := newPhasesProjectReader(t)
+			svc := New(reader, nil, nil, nil, nil, nil)
+			res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{
+				ProjectId: "project-1",
+				Params:    map[string]string{runner.RunParamPhases: tc.phases},
+			})
+			if err != nil {
+				t.Fatalf("StartRun() error = %v", err)
+			}
+			waitRun(t, svc, res.GetRunId(), nil)
+			if got := ran(); !slices.Equal(got, tc.want) {
+				t.Fatalf("ran %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStartRunRejectsInvalidPhases(t *testing.T) {
+	reader, ran := newPhasesProjectReader(t)
+	svc := New(reader, nil, nil, nil, nil, nil)
+
+	cases := []struct {
+		name   string
+		params map[string]string
+		want   error
+	}{
+		{name: "unknown phase", params: map[string]string{runner.RunParamPhases: "b,nope"}, want: runner.ErrUnknownPhase},
+		{name: "dry run", params: map[string]string{runner.RunParamPhases: "b", "dry_run": "true"}, want: ErrInvalidRun},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", Params: tc.params})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("StartRun() error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+	if got := ran(); len(got) != 0 {
+		t.Fatalf("ran %v after rejected runs", got)
+	}
+}
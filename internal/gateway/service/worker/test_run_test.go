@@ -2,8 +2,13 @@ package worker
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"insightify/internal/gateway/entity"
 	runtimepkg "insightify/internal/workerruntime"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -14,7 +19,7 @@ import (
 type testProjectReader struct{}
 
 func (testProjectReader) GetEntry(projectID string) (ProjectView, bool) {
-	return ProjectView{ProjectID: projectID}, true
+	return ProjectView{ProjectID: projectID, UserID: entity.DemoUserID}, true
 }
 
 func (testProjectReader) EnsureRunContext(projectID string) (*runtimepkg.ProjectRuntime, error) {
@@ -79,3 +84,33 @@ func TestStartRunAssignsRunToCurrentTabImmediately(t *testing.T) {
 		time.Sleep(5 * time.Millisecond)
 	}
 }
+
+func TestSyncArtifactDirRecordsChunkedArtifactsReassembled(t *testing.T) {
+	outDir := t.TempDir()
+	doc := []byte(`{"symbols":["a","b","c"]}`)
+	sum := sha256.Sum256(doc)
+	index := fmt.Sprintf(`{"chunked_artifact":1,"size":%d,"digest":"%s","parts":["code_symbols.part-1.json","code_symbols.part-2.json"]}`, len(doc), hex.EncodeToString(sum[:]))
+	files := map[string][]byte{
+		"code_symbols.json":        []byte(index),
+		"code_symbols.part-1.json": doc[:10],
+		"code_symbols.part-2.json": doc[10:],
+		"code_roots.json":          []byte(`{}`),
+	}
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(outDir, name), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := &recordingArtifactStore{}
+	svc := New(testProjectReader{}, nil, nil, nil, nil, store)
+
+	if err := svc.syncArtifactDir(context.Background(), "run-1", "project-1", outDir); err != nil {
+		t.Fatalf("syncArtifactDir() error = %v", err)
+	}
+	if got := string(store.files["run-1/code_symbols.json"]); got != string(doc) {
+		t.Fatalf("synced code_symbols = %s, want the reassembled document", got)
+	}
+	if _, ok := store.files["run-1/code_symbols.part-1.json"]; ok || len(store.files) != 2 {
+		t.Fatalf("synced %d files, want the parts folded into their index", len(store.files))
+	}
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+)
+
+func TestRunTimeoutEmitsErrorEvent(t *testing.T) {
+	started := make(chan struct{})
+	svc := New(newSlowProjectReader(t, started), nil, nil, nil, nil, nil)
+	svc.SetRunTimeout(50 * time.Millisecond)
+
+	res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: "slow"})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	runID := res.GetRunId()
+
+	svc.runMu.RLock()
+	done := svc.runs[runID].done
+	svc.runMu.RUnlock()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("run did not stop at its deadline")
+	}
+
+	events, _ := svc.Telemetry().Read(runID)
+	if len(events) == 0 {
+		t.Fatalf("expected telemetry events")
+	}
+	last := events[len(events)-1]
+	if last["stage"] != StageRunTimeout || last["terminal"] != true || last["status"] != RunStatusTimeout {
+		t.Fatalf("last event = %v, want terminal %s", last, StageRunTimeout)
+	}
+	if msg, _ := last["error"].(string); !strings.Contains(msg, context.DeadlineExceeded.Error()) {
+		t.Fatalf("error = %q, want deadline exceeded", msg)
+	}
+}
+
+func TestPhaseTimeoutEmitsPhaseEvent(t *testing.T) {
+	started := make(chan struct{})
+	svc := New(newSlowProjectReader(t, started), nil, nil, nil, nil, nil)
+	svc.SetRunTimeout(time.Minute)
+	svc.SetPhaseTimeout(50 * time.Millisecond)
+
+	res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: "slow"})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	runID := res.GetRunId()
+	svc.runMu.RLock()
+	done := svc.runs[runID].done
+	svc.runMu.RUnlock()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("run did not stop at the phase timeout")
+	}
+
+	events, _ := svc.Telemetry().Read(runID)
+	if len(events) == 0 {
+		t.Fatalf("expected telemetry events")
+	}
+	last := events[len(events)-1]
+	if last["stage"] != StagePhaseTimeout || last["phase"] != "slow" || last["terminal"] != true {
+		t.Fatalf("last event = %v, want terminal %s for slow", last, StagePhaseTimeout)
+	}
+	if ms, _ := last["elapsed_ms"].(int64); ms < 50 || last["timeout_ms"] != int64(50) {
+		t.Fatalf("last event = %v, want elapsed_ms >= timeout_ms = 50", last)
+	}
+}
@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	llmmiddleware "insightify/internal/llm/middleware"
+	"insightify/internal/runner"
+	runtimepkg "insightify/internal/workerruntime"
+)
+
+type slowProjectReader struct {
+	rt *runtimepkg.ProjectRuntime
+}
+
+func (r slowProjectReader) GetEntry(projectID string) (ProjectView, bool) {
+	return ProjectView{ProjectID: projectID}, true
+}
+
+func (r slowProjectReader) EnsureRunContext(string) (*runtimepkg.ProjectRuntime, error) {
+	return r.rt, nil
+}
+
+func newSlowProjectReader(t *testing.T, started chan<- struct{}) slowProjectReader {
+	t.Helper()
+	return slowProjectReader{rt: &runtimepkg.ProjectRuntime{
+		ID:     "project-1",
+		OutDir: t.TempDir(),
+		Resolver: runner.MergeRegistries(map[string]runner.WorkerSpec{
+			"slow": {
+				Key: "slow",
+				Run: func(ctx context.Context, _ any, _ runner.Runtime) (runner.WorkerOutput, error) {
+					close(started)
+					<-ctx.Done()
+					// A cancelled LLM retry surfaces as a typed failure
+					// that would otherwise get its own terminal event.
+					return runner.WorkerOutput{}, fmt.Errorf("%w: %w", llmmiddleware.ErrRetryBudgetExhausted, ctx.Err())
+				},
+			},
+		}),
+	}}
+}
+
+type recordingArtifactStore struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (s *recordingArtifactStore) Put(_ context.Context, runID, path string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files == nil {
+		s.files = map[string][]byte{}
+	}
+	s.files[runID+"/"+path] = content
+	return nil
+}
+
+func (s *recordingArtifactStore) Get(_ context.Context, runID, path string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.files[runID+"/"+path]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return b, nil
+}
+
+func (s *recordingArtifactStore) GetURL(context.Context, string, string) (string, error) {
+	return "", nil
+}
+
+func (s *recordingArtifactStore) List(context.Context, string) ([]string, error) {
+	return nil, nil
+}
+
+type closingInteraction struct {
+	mu     sync.Mutex
+	closed []string
+}
+
+func (c *closingInteraction) WaitForInput(ctx context.Context, _, _ string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (c *closingInteraction) PublishOutput(context.Context, string, string, string, string) error {
+	return nil
+}
+
+func (c *closingInteraction) CloseRun(runID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = append(c.closed, runID)
+}
+
+func TestShutdownInterruptsActiveRun(t *testing.T) {
+	started := make(chan struct{})
+	artifacts := &recordingArtifactStore{}
+	interaction := &closingInteraction{}
+	svc := New(newSlowProjectReader(t, started), nil, nil, nil, interaction, artifacts)
+
+	res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{
+		ProjectId: "project-1",
+		WorkerId:  "slow",
+		Params:    map[string]string{"node_id": "n1"},
+	})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	runID := res.GetRunId()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("slow worker did not start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := svc.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	events, _ := svc.Telemetry().Read(runID)
+	if len(events) == 0 {
+		t.Fatalf("expected telemetry events")
+	}
+	last := events[len(events)-1]
+	if last["stage"] != StageServerShutdown || last["terminal"] != true || last["status"] != RunStatusInterrupted {
+		t.Fatalf("last event = %v, want terminal %s", last, StageServerShutdown)
+	}
+	terminal := 0
+	for _, evt := range events {
+		if evt["terminal"] == true {
+			terminal++
+		}
+	}
+	if terminal != 1 {
+		t.Fatalf("events = %v, want exactly one terminal event", events)
+	}
+
+	raw, err := artifacts.Get(context.Background(), runID, RunStatusName)
+	if err != nil {
+		t.Fatalf("run status not persisted: %v", err)
+	}
+	var status RunStatus
+	if err := json.Unmarshal(raw, &status); err != nil {
+		t.Fatalf("decode run status: %v", err)
+	}
+	if status.Status != RunStatusInterrupted || status.WorkerID != "slow" || status.Params["node_id"] != "n1" {
+		t.Fatalf("unexpected run status: %+v", status)
+	}
+	if n := len(status.Events); n != len(events) || status.Events[n-1]["stage"] != StageServerShutdown {
+		t.Fatalf("run status events = %v, want the run's telemetry flushed", status.Events)
+	}
+
+	if len(interaction.closed) != 1 || interaction.closed[0] != runID {
+		t.Fatalf("pending interactions not closed: %v", interaction.closed)
+	}
+
+	if _, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: "slow"}); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("StartRun after Shutdown error = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestShutdownGraceLetsRunFinish(t *testing.T) {
+	svc := New(testProjectReader{}, nil, nil, nil, nil, nil)
+	svc.SetDrainGrace(time.Second)
+
+	res, err := svc.StartRun(context.Background(), &insightifyv1.StartRunRequest{ProjectId: "project-1", WorkerId: "any"})
+	if err != nil {
+		t.Fatalf("StartRun() error = %v", err)
+	}
+	if err := svc.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	events, _ := svc.Telemetry().Read(res.GetRunId())
+	for _, evt := range events {
+		if evt["stage"] == StageServerShutdown {
+			t.Fatalf("finished run should not be interrupted: %v", evt)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	insightifyv1 "insightify/gen/go/insightify/v1"
+	"insightify/internal/gateway/entity"
+	gatewayuserinteraction "insightify/internal/gateway/service/userinteraction"
+)
+
+func newSubmitTestService(t *testing.T) (*Service, *gatewayuserinteraction.Service) {
+	t.Helper()
+	interaction := gatewayuserinteraction.New(nil, "")
+	svc := New(testProjectReader{}, nil, nil, nil, interaction, nil)
+	svc.runs["run-1"] = &WorkerRuntime{RunID: "run-1", ProjectID: "project-1", StartedAt: time.Now()}
+	return svc, interaction
+}
+
+func TestSubmitInputByInteractionOnly(t *testing.T) {
+	svc, interaction := newSubmitTestService(t)
+	snap, err := interaction.Snapshot("run-1", "node-1")
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	res, err := svc.SubmitInput(context.Background(), SubmitInputRequest{
+		InteractionID: snap.GetInteractionId(),
+		Input:         "hello",
+	})
+	if err != nil {
+		t.Fatalf("SubmitInput() error = %v", err)
+	}
+	if !res.Accepted || res.ProjectID != "project-1" || res.RunID != "run-1" || res.NodeID != "node-1" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	got, err := interaction.WaitForInput(ctx, "run-1", "node-1")
+	if err != nil || got != "hello" {
+		t.Fatalf("WaitForInput() = %q, %v", got, err)
+	}
+}
+
+func TestSubmitInputWithExplicitIDs(t *testing.T) {
+	svc, _ := newSubmitTestService(t)
+
+	res, err := svc.SubmitInput(context.Background(), SubmitInputRequest{
+		ProjectID: "project-1",
+		RunID:     "run-1",
+		NodeID:    "node-1",
+		Input:     "hello",
+	})
+	if err != nil {
+		t.Fatalf("SubmitInput() error = %v", err)
+	}
+	if !res.Accepted || res.ProjectID != "project-1" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	_, err = svc.SubmitInput(context.Background(), SubmitInputRequest{
+		ProjectID: "project-2",
+		RunID:     "run-1",
+		NodeID:    "node-1",
+		Input:     "hello",
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not belong") {
+		t.Fatalf("expected project mismatch error, got %v", err)
+	}
+}
+
+func TestSubmitInputRejectsOtherUsers(t *testing.T) {
+	svc, _ := newSubmitTestService(t)
+	req := SubmitInputRequest{UserID: "other-user", RunID: "run-1", NodeID: "node-1", Input: "hello"}
+	if _, err := svc.SubmitInput(context.Background(), req); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	req.UserID = entity.DemoUserID
+	if _, err := svc.SubmitInput(context.Background(), req); err != nil {
+		t.Fatalf("SubmitInput() by the owner error = %v", err)
+	}
+}
+
+func TestSubmitInputRejectsUnknownAndAmbiguousInteraction(t *testing.T) {
+	svc, interaction := newSubmitTestService(t)
+
+	_, err := svc.SubmitInput(context.Background(), SubmitInputRequest{InteractionID: "interaction-missing", Input: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	for _, node := range []string{"node-a", "node-b"} {
+		if _, err := interaction.Send(context.Background(), &insightifyv1.SendRequest{
+			RunId: "run-1", NodeId: node, InteractionId: "interaction-shared", Input: "x",
+		}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	_, err = svc.SubmitInput(context.Background(), SubmitInputRequest{InteractionID: "interaction-shared", Input: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("expected ambiguous error, got %v", err)
+	}
+}
+
+func TestSubmitInputNonceDeliversOnce(t *testing.T) {
+	svc, interaction := newSubmitTestService(t)
+	req := SubmitInputRequest{RunID: "run-1", NodeID: "node-1", Input: "hello", Nonce: "nonce-1"}
+
+	first, err := svc.SubmitInput(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SubmitInput() error = %v", err)
+	}
+	retry, err := svc.SubmitInput(context.Background(), req)
+	if err != nil {
+		t.Fatalf("retried SubmitInput() error = %v", err)
+	}
+	if *retry != *first {
+		t.Fatalf("retry = %+v, want %+v", retry, first)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if got, err := interaction.WaitForInput(ctx, "run-1", "node-1"); err != nil || got != "hello" {
+		t.Fatalf("WaitForInput() = %q, %v", got, err)
+	}
+	if got, err := interaction.WaitForInput(ctx, "run-1", "node-1"); err == nil {
+		t.Fatalf("retried input delivered twice: %q", got)
+	}
+}
+
+func TestAuthorizeRunChecksTheProjectOwner(t *testing.T) {
+	svc, _ := newSubmitTestService(t)
+	if err := svc.AuthorizeRun("run-1", entity.DemoUserID); err != nil {
+		t.Fatalf("AuthorizeRun() by the owner error = %v", err)
+	}
+	if err := svc.AuthorizeRun("run-1", "other-user"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("AuthorizeRun() by another user error = %v, want ErrForbidden", err)
+	}
+	if err := svc.AuthorizeRun("run-unknown", entity.DemoUserID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("AuthorizeRun() of an unknown run error = %v, want not found", err)
+	}
+
+	// Pruned runs are resolved through their telemetry labels.
+	svc.Telemetry().SetLabels("run-old", map[string]string{LabelProjectID: "project-1"})
+	if err := svc.AuthorizeRun("run-old", "other-user"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("AuthorizeRun() of a pruned run error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestSubmitInputReportsResumeOfPausedRun(t *testing.T) {
+	svc, interaction := newSubmitTestService(t)
+	svc.runs["run-1"].pausedNode = "node-1"
+
+	// The paused wait resumes the run as soon as the input arrives, possibly
+	// before SubmitInput returns.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if _, err := interaction.WaitForInput(ctx, "run-1", "node-1"); err == nil {
+			svc.setRunPaused(ctx, "run-1", "node-1", false)
+		}
+	}()
+
+	res, err := svc.SubmitInput(context.Background(), SubmitInputRequest{RunID: "run-1", NodeID: "node-1", Input: "back"})
+	if err != nil {
+		t.Fatalf("SubmitInput() error = %v", err)
+	}
+	<-done
+	if !res.Accepted || !res.Resumed {
+		t.Fatalf("result = %+v, want the input to resume the paused run", res)
+	}
+	if _, paused := svc.RunPaused("run-1"); paused {
+		t.Fatalf("run still paused after the input")
+	}
+}
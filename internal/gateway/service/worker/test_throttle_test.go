@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	llmmiddleware "insightify/internal/llm/middleware"
+)
+
+func TestThrottleEventsDescribeTheWait(t *testing.T) {
+	s := &Service{telemetry: NewTelemetryStore()}
+	hook := s.throttleEvents("run-1", "arch")
+	hook(llmmiddleware.WithPhase(context.Background(), "arch_design"), llmmiddleware.ThrottleEvent{
+		Provider:  "groq",
+		Model:     "llama",
+		Source:    llmmiddleware.ThrottleSourceLimiter,
+		Waited:    5 * time.Second,
+		Remaining: 19500 * time.Millisecond,
+	})
+	hook(context.Background(), llmmiddleware.ThrottleEvent{Model: "local", Source: llmmiddleware.ThrottleSourceSignal, Waited: 6 * time.Second})
+
+	events, _ := s.telemetry.Read("run-1")
+	if len(events) != 2 {
+		t.Fatalf("events = %v, want 2", events)
+	}
+	first, second := events[0], events[1]
+	if first["stage"] != StageThrottled || first["phase"] != "arch_design" || first["provider"] != "groq" || first["model"] != "llama" {
+		t.Fatalf("first event = %v, want a groq/llama throttle in arch_design", first)
+	}
+	if first["message"] != "throttled by groq, resuming in ~20s" || first["remaining_ms"] != int64(19500) || first["waited_ms"] != int64(5000) {
+		t.Fatalf("first event = %v, want the wait described", first)
+	}
+	if second["message"] != "throttled by local" || second["throttle_source"] != llmmiddleware.ThrottleSourceSignal {
+		t.Fatalf("second event = %v, want an unknown-remaining signal wait on local", second)
+	}
+	if _, ok := second["remaining_ms"]; ok {
+		t.Fatalf("second event = %v, want no remaining_ms", second)
+	}
+	for _, key := range []string{"phase", "provider"} {
+		if _, ok := second[key]; ok {
+			t.Fatalf("second event = %v, want no %s", second, key)
+		}
+	}
+}
@@ -10,18 +10,61 @@ type TelemetryStore struct {
 	mu     sync.RWMutex
 	events map[string][]map[string]any
 	order  []string
+	labels map[string]map[string]string
+	// terminal holds the runs whose terminal event was recorded.
+	terminal map[string]bool
 }
 
 func NewTelemetryStore() *TelemetryStore {
 	return &TelemetryStore{
-		events: make(map[string][]map[string]any),
-		order:  make([]string, 0, 32),
+		events:   make(map[string][]map[string]any),
+		order:    make([]string, 0, 32),
+		labels:   make(map[string]map[string]string),
+		terminal: make(map[string]bool),
 	}
 }
 
+// SetLabels attaches labels to every later event of runID. labels must not
+// be modified afterwards.
+func (l *TelemetryStore) SetLabels(runID string, labels map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.labels[runID] = labels
+}
+
+// Labels returns the labels of runID, or nil.
+func (l *TelemetryStore) Labels(runID string) map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.labels[runID]
+}
+
 func (l *TelemetryStore) Append(runID, source, stage string, fields map[string]any) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	l.appendLocked(runID, source, stage, fields)
+}
+
+// AppendTerminal appends the terminal event of runID, marked terminal=true,
+// and reports whether it did: every run gets exactly one, so later calls for
+// the same run are dropped.
+func (l *TelemetryStore) AppendTerminal(runID, source, stage string, fields map[string]any) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.terminal[runID] {
+		return false
+	}
+	l.terminal[runID] = true
+	evt := make(map[string]any, len(fields)+1)
+	for k, v := range fields {
+		evt[k] = v
+	}
+	evt["terminal"] = true
+	l.appendLocked(runID, source, stage, evt)
+	return true
+}
+
+func (l *TelemetryStore) appendLocked(runID, source, stage string, fields map[string]any) {
 	if fields == nil {
 		fields = map[string]any{}
 	}
@@ -32,6 +75,9 @@ func (l *TelemetryStore) Append(runID, source, stage string, fields map[string]a
 	evt["run_id"] = runID
 	evt["source"] = source
 	evt["stage"] = stage
+	if labels, ok := l.labels[runID]; ok {
+		evt["labels"] = labels
+	}
 	if _, ok := evt["timestamp"]; !ok {
 		evt["timestamp"] = time.Now().Format(time.RFC3339Nano)
 	}
@@ -52,6 +98,24 @@ func (l *TelemetryStore) Append(runID, source, stage string, fields map[string]a
 	l.order = append(l.order, runID)
 }
 
+// Delete drops every event of runID.
+func (l *TelemetryStore) Delete(runID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.labels, runID)
+	delete(l.terminal, runID)
+	if _, ok := l.events[runID]; !ok {
+		return
+	}
+	delete(l.events, runID)
+	for i, id := range l.order {
+		if id == runID {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+}
+
 func (l *TelemetryStore) Read(runID string) ([]map[string]any, error) {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
@@ -65,6 +129,12 @@ func (l *TelemetryStore) Read(runID string) ([]map[string]any, error) {
 }
 
 func (l *TelemetryStore) LatestRuns(limit int) []string {
+	return l.LatestRunsMatching(limit, nil)
+}
+
+// LatestRunsMatching is LatestRuns restricted to runs whose labels match sel
+// (see MatchLabels).
+func (l *TelemetryStore) LatestRunsMatching(limit int, sel map[string]string) []string {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 	if limit <= 0 {
@@ -79,7 +149,7 @@ func (l *TelemetryStore) LatestRuns(limit int) []string {
 	out := make([]string, 0, limit)
 	for i := len(l.order) - 1; i >= 0 && len(out) < limit; i-- {
 		runID := l.order[i]
-		if runID == "" {
+		if runID == "" || !MatchLabels(l.labels[runID], sel) {
 			continue
 		}
 		out = append(out, runID)
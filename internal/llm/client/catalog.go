@@ -2,6 +2,7 @@ package llmclient
 
 import (
 	"context"
+	"math"
 	"strings"
 )
 
@@ -33,7 +34,20 @@ type ModelRegistration struct {
 	MaxTokens int
 	Meta      map[string]any
 	RateLimit *RateLimitConfig
-	Factory   ClientFactory
+	// Pricing is nil for models whose price is unknown.
+	Pricing *Pricing
+	Factory ClientFactory
+}
+
+// Pricing is the list price of a model in USD per million tokens.
+type Pricing struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// Cost returns the USD cost of a call with the given token counts.
+func (p Pricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMTok + float64(outputTokens)*p.OutputPerMTok) / 1e6
 }
 
 type ModelRegistrar interface {
@@ -47,3 +61,64 @@ func normalizeTier(tier, fallback string) string {
 	}
 	return t
 }
+
+// Tiers recognized by every provider; providers may add their own.
+const (
+	TierFree       = "free"
+	TierDeveloper  = "developer"
+	TierEnterprise = "enterprise"
+)
+
+// LimitScale multiplies rate limits: Requests scales RPM/RPD/RPS, Tokens
+// scales TPM/TPD and Burst scales Burst. A zero factor keeps the field.
+type LimitScale struct {
+	Requests float64
+	Tokens   float64
+	Burst    float64
+}
+
+// TierMultiplier declares, per tier, how a provider scales its free-tier
+// limits. Tiers missing from the map (including free) keep the base limits.
+type TierMultiplier map[string]LimitScale
+
+// ApplyTierMultiplier returns a copy of cfg scaled for tier. cfg is not
+// modified; nil stays nil.
+func ApplyTierMultiplier(cfg *RateLimitConfig, tier string, mult TierMultiplier) *RateLimitConfig {
+	if cfg == nil {
+		return nil
+	}
+	out := *cfg
+	scale, ok := mult[normalizeTier(tier, TierFree)]
+	if !ok {
+		return &out
+	}
+	out.RPM = scaleInt(out.RPM, scale.Requests)
+	out.RPD = scaleInt(out.RPD, scale.Requests)
+	out.TPM = scaleInt(out.TPM, scale.Tokens)
+	out.TPD = scaleInt(out.TPD, scale.Tokens)
+	if scale.Requests > 0 {
+		out.RPS *= scale.Requests
+	}
+	out.Burst = scaleInt(out.Burst, scale.Burst)
+	return &out
+}
+
+// PricingForTier returns the price a tier pays for a model listed at p: the
+// free tier is not billed, so it is priced at zero rather than left unknown.
+func PricingForTier(p *Pricing, tier string) *Pricing {
+	if p == nil {
+		return nil
+	}
+	if normalizeTier(tier, TierFree) == TierFree {
+		return &Pricing{}
+	}
+	out := *p
+	return &out
+}
+
+func scaleInt(v int, factor float64) int {
+	if factor <= 0 || v <= 0 {
+		return v
+	}
+	return max(1, int(math.Round(float64(v)*factor)))
+}
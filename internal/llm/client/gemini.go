@@ -1,11 +1,19 @@
 package llmclient
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	genai "google.golang.org/genai"
 )
@@ -24,19 +32,38 @@ type GeminiClient struct {
 	rlHandler RateLimitHeaderHandler
 }
 
-func NewGeminiClient(ctx context.Context, apiKey, model string, tokenCap int) (*GeminiClient, error) {
+// NewGeminiClient creates a Gemini client whose requests go through httpCfg;
+// a zero Timeout leaves them to the genai defaults.
+func NewGeminiClient(ctx context.Context, apiKey, model string, tokenCap int, httpCfg HTTPClientConfig) (*GeminiClient, error) {
 	// NOTE: apiKey is currently unused here; the genai client may read it from env.
 	// Keep the parameter for future use and to keep a consistent factory signature.
 	_ = apiKey
 
-	cli, err := genai.NewClient(ctx, &genai.ClientConfig{Backend: genai.BackendGeminiAPI})
+	tr, err := httpCfg.Transport()
 	if err != nil {
 		return nil, err
 	}
+	cfg := &genai.ClientConfig{Backend: genai.BackendGeminiAPI}
+	if httpCfg.Timeout > 0 {
+		cfg.HTTPOptions.Timeout = &httpCfg.Timeout
+	}
+	return newGeminiClient(ctx, cfg, model, tokenCap, tr)
+}
+
+// newGeminiClient builds the client on top of cfg, routing HTTP traffic through
+// base (http.DefaultTransport when nil) so quota headers can be observed.
+func newGeminiClient(ctx context.Context, cfg *genai.ClientConfig, model string, tokenCap int, base http.RoundTripper) (*GeminiClient, error) {
 	if tokenCap <= 0 {
 		tokenCap = 12000
 	}
-	return &GeminiClient{cli: cli, model: model, tokenCap: tokenCap}, nil
+	g := &GeminiClient{model: model, tokenCap: tokenCap}
+	cfg.HTTPClient = &http.Client{Transport: &geminiHeaderTransport{base: base, onResponse: g.captureRateLimitHeaders}}
+	cli, err := genai.NewClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	g.cli = cli
+	return g, nil
 }
 
 func (g *GeminiClient) Name() string { return "Gemini:" + g.model }
@@ -62,39 +89,110 @@ func (g *GeminiClient) LastRateLimitHeaders() (RateLimitHeaders, bool) {
 	return g.rlLast, g.rlHasLast
 }
 
-// GenerateJSON concatenates prompt and input, asks for application/json,
-// and returns the model's JSON as json.RawMessage.
+func (g *GeminiClient) captureRateLimitHeaders(h http.Header, body []byte) {
+	parsed, ok := parseGeminiRateLimitHeaders(h, body)
+	if !ok {
+		return
+	}
+	g.rlMu.Lock()
+	g.rlLast = parsed
+	g.rlHasLast = true
+	handler := g.rlHandler
+	g.rlMu.Unlock()
+	if handler != nil {
+		handler(parsed)
+	}
+}
+
+// geminiHeaderTransport reports every response's headers to onResponse.
+// 429 bodies are buffered and passed along since Gemini puts the retry delay
+// in google.rpc.RetryInfo rather than a header.
+type geminiHeaderTransport struct {
+	base       http.RoundTripper
+	onResponse func(h http.Header, body []byte)
+}
+
+func (t *geminiHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	var body []byte
+	if resp.StatusCode == http.StatusTooManyRequests && resp.Body != nil {
+		// Peek at a bounded prefix; genai still reads the whole body.
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyRead))
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	}
+	t.onResponse(resp.Header, body)
+	return resp, nil
+}
+
+// GenerateJSON sends prompt as the system instruction and input as the user
+// content, asks for application/json, and returns the model's JSON as
+// json.RawMessage. GenerationOptionsFrom(ctx) sets temperature, top-p and
+// the output cap.
 //
 // Retries / rate limiting / logging / hooks are handled by middleware layers.
 func (g *GeminiClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
-	in, _ := json.MarshalIndent(input, "", "  ")
-	full := prompt + "\n\n[INPUT JSON]\n" + string(in)
+	return g.generate(ctx, prompt, input)
+}
 
-	resp, err := g.cli.Models.GenerateContent(ctx, g.model,
-		[]*genai.Content{{Parts: []*genai.Part{{Text: full}}}},
-		&genai.GenerateContentConfig{ResponseMIMEType: "application/json"},
-	)
-	if err != nil {
+// GenerateJSONStream streams partial JSON chunks to the callback.
+// Returns the final complete JSON response. The stream is bound to ctx:
+// cancelling it aborts the request, and no chunk is delivered after ctx is
+// done.
+func (g *GeminiClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	var content strings.Builder
+	for resp, err := range g.cli.Models.GenerateContentStream(ctx, g.model, geminiContents(input), geminiConfig(prompt, GenerationOptionsFrom(ctx))) {
+		if err != nil {
+			return nil, contextErr(ctx, classifyGeminiError(err))
+		}
+		if err := geminiBlocked(resp); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+			continue
+		}
+		for _, part := range resp.Candidates[0].Content.Parts {
+			if part == nil || part.Text == "" {
+				continue
+			}
+			content.WriteString(part.Text)
+			if onChunk != nil {
+				onChunk(part.Text)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
+	if content.Len() == 0 {
 		return nil, ErrInvalidJSON
 	}
-	txt := resp.Candidates[0].Content.Parts[0].Text
-	return json.RawMessage(txt), nil
+	return json.RawMessage(content.String()), nil
 }
 
-// GenerateJSONStream streams partial JSON chunks to the callback.
-// Returns the final complete JSON response.
-func (g *GeminiClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+func geminiContents(input any) []*genai.Content {
 	in, _ := json.MarshalIndent(input, "", "  ")
-	full := prompt + "\n\n[INPUT JSON]\n" + string(in)
+	return []*genai.Content{genai.NewContentFromText("[INPUT JSON]\n"+string(in), genai.RoleUser)}
+}
 
-	resp, err := g.cli.Models.GenerateContent(ctx, g.model,
-		[]*genai.Content{{Parts: []*genai.Part{{Text: full}}}},
-		&genai.GenerateContentConfig{ResponseMIMEType: "application/json"},
-	)
+func (g *GeminiClient) generate(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	resp, err := g.cli.Models.GenerateContent(ctx, g.model, geminiContents(input), geminiConfig(prompt, GenerationOptionsFrom(ctx)))
 	if err != nil {
+		return nil, contextErr(ctx, classifyGeminiError(err))
+	}
+	if err := geminiBlocked(resp); err != nil {
 		return nil, err
 	}
 	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
@@ -103,12 +201,59 @@ func (g *GeminiClient) GenerateJSONStream(ctx context.Context, prompt string, in
 	return json.RawMessage(resp.Candidates[0].Content.Parts[0].Text), nil
 }
 
+func geminiConfig(prompt string, opts GenerationOptions) *genai.GenerateContentConfig {
+	cfg := &genai.GenerateContentConfig{
+		ResponseMIMEType: "application/json",
+		Temperature:      opts.temperature(),
+		TopP:             opts.TopP,
+	}
+	if strings.TrimSpace(prompt) != "" {
+		cfg.SystemInstruction = genai.NewContentFromText(prompt, genai.RoleUser)
+	}
+	if opts.MaxOutputTokens > 0 {
+		cfg.MaxOutputTokens = int32(opts.MaxOutputTokens)
+	}
+	return cfg
+}
+
+// classifyGeminiError marks genai API errors that retrying cannot fix as permanent.
+func classifyGeminiError(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) && isPermanentHTTPFailure(apiErr.Code) {
+		return NewPermanentError(err)
+	}
+	return err
+}
+
+// geminiBlocked reports a content-policy rejection, which Gemini returns as a
+// 200 with a block reason instead of an HTTP error.
+func geminiBlocked(resp *genai.GenerateContentResponse) error {
+	if resp == nil {
+		return nil
+	}
+	if pf := resp.PromptFeedback; pf != nil && pf.BlockReason != "" {
+		return NewPermanentError(fmt.Errorf("gemini: prompt blocked: %s", pf.BlockReason))
+	}
+	if len(resp.Candidates) > 0 && resp.Candidates[0].FinishReason == genai.FinishReasonSafety {
+		return NewPermanentError(fmt.Errorf("gemini: response blocked by safety filters"))
+	}
+	return nil
+}
+
+// geminiTierMultipliers scales the free-tier limits for paid tiers. "tier1"
+// is Gemini's own name for the first paid tier.
+var geminiTierMultipliers = TierMultiplier{
+	"tier1":        {Requests: 4},
+	TierDeveloper:  {Requests: 4},
+	TierEnterprise: {Requests: 16, Burst: 4},
+}
+
 func RegisterGeminiModels(reg ModelRegistrar) error {
-	return RegisterGeminiModelsForTier(reg, "free")
+	return RegisterGeminiModelsForTier(reg, TierFree)
 }
 
 func RegisterGeminiModelsForTier(reg ModelRegistrar, tier string) error {
-	tier = normalizeTier(tier, "free")
+	tier = normalizeTier(tier, TierFree)
 
 	type geminiModel struct {
 		name   string
@@ -116,25 +261,18 @@ func RegisterGeminiModelsForTier(reg ModelRegistrar, tier string) error {
 		tokens int
 		meta   map[string]any
 		limit  *RateLimitConfig
+		price  *Pricing
 	}
+	// List prices per million tokens (prompts up to 200k tokens); see
+	// https://ai.google.dev/gemini-api/docs/pricing
+	flashPrice := &Pricing{InputPerMTok: 0.30, OutputPerMTok: 2.50}
+	proPrice := &Pricing{InputPerMTok: 1.25, OutputPerMTok: 10.00}
 	freeLimits := &RateLimitConfig{RPM: 15, RPS: 0.25, Burst: 1}
-	tier1Limits := &RateLimitConfig{RPM: 60, RPS: 1, Burst: 1}
-	models := []geminiModel{}
-	switch tier {
-	case "tier1":
-		models = append(models,
-			geminiModel{name: "gemini-2.5-flash", level: ModelLevelLow, tokens: 12000, meta: map[string]any{"params": 0}, limit: tier1Limits},
-			geminiModel{name: "gemini-2.5-flash", level: ModelLevelMiddle, tokens: 12000, meta: map[string]any{"params": 0}, limit: tier1Limits},
-			geminiModel{name: "gemini-2.5-pro", level: ModelLevelHigh, tokens: 12000, meta: map[string]any{"params": 0}, limit: tier1Limits},
-			geminiModel{name: "gemini-2.5-pro", level: ModelLevelXHigh, tokens: 12000, meta: map[string]any{"params": 0}, limit: tier1Limits},
-		)
-	default: // free
-		models = append(models,
-			geminiModel{name: "gemini-2.5-flash", level: ModelLevelLow, tokens: 12000, meta: map[string]any{"params": 0}, limit: freeLimits},
-			geminiModel{name: "gemini-2.5-flash", level: ModelLevelMiddle, tokens: 12000, meta: map[string]any{"params": 0}, limit: freeLimits},
-			geminiModel{name: "gemini-2.5-pro", level: ModelLevelHigh, tokens: 12000, meta: map[string]any{"params": 0}, limit: freeLimits},
-			geminiModel{name: "gemini-2.5-pro", level: ModelLevelXHigh, tokens: 12000, meta: map[string]any{"params": 0}, limit: freeLimits},
-		)
+	models := []geminiModel{
+		{name: "gemini-2.5-flash", level: ModelLevelLow, tokens: 12000, meta: map[string]any{"params": 0}, limit: freeLimits, price: flashPrice},
+		{name: "gemini-2.5-flash", level: ModelLevelMiddle, tokens: 12000, meta: map[string]any{"params": 0}, limit: freeLimits, price: flashPrice},
+		{name: "gemini-2.5-pro", level: ModelLevelHigh, tokens: 12000, meta: map[string]any{"params": 0}, limit: freeLimits, price: proPrice},
+		{name: "gemini-2.5-pro", level: ModelLevelXHigh, tokens: 12000, meta: map[string]any{"params": 0}, limit: freeLimits, price: proPrice},
 	}
 	for _, m := range models {
 		modelName := m.name
@@ -148,12 +286,17 @@ func RegisterGeminiModelsForTier(reg ModelRegistrar, tier string) error {
 			Level:     level,
 			MaxTokens: tokens,
 			Meta:      meta,
-			RateLimit: m.limit,
+			RateLimit: ApplyTierMultiplier(m.limit, tier, geminiTierMultipliers),
+			Pricing:   PricingForTier(m.price, tier),
 			Factory: func(ctx context.Context, tokenCap int) (LLMClient, error) {
 				if tokenCap <= 0 {
 					tokenCap = tokens
 				}
-				return NewGeminiClient(ctx, os.Getenv("GEMINI_API_KEY"), modelName, tokenCap)
+				httpCfg, err := HTTPClientConfigFromEnv()
+				if err != nil {
+					return nil, err
+				}
+				return NewGeminiClient(ctx, os.Getenv("GEMINI_API_KEY"), modelName, tokenCap, httpCfg)
 			},
 		}); err != nil {
 			return err
@@ -161,3 +304,107 @@ func RegisterGeminiModelsForTier(reg ModelRegistrar, tier string) error {
 	}
 	return nil
 }
+
+// parseGeminiRateLimitHeaders parses the quota signals Gemini (and proxies in
+// front of it) return. Retry-After may be seconds or an HTTP date; when absent
+// on a 429 the delay is taken from the RetryInfo detail in body.
+func parseGeminiRateLimitHeaders(h http.Header, body []byte) (RateLimitHeaders, bool) {
+	out := RateLimitHeaders{}
+	found := false
+
+	readInt := func(key string) (int, bool) {
+		v := strings.TrimSpace(h.Get(key))
+		if v == "" {
+			return 0, false
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+	readDur := func(key string) (time.Duration, bool) {
+		v := strings.TrimSpace(h.Get(key))
+		if v == "" {
+			return 0, false
+		}
+		if n, err := strconv.Atoi(v); err == nil {
+			return time.Duration(n) * time.Second, true
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, false
+		}
+		return d, true
+	}
+
+	if v := strings.TrimSpace(h.Get("retry-after")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			out.RetryAfterSeconds = n
+			found = true
+		} else if at, err := http.ParseTime(v); err == nil {
+			out.RetryAfterSeconds = max(0, int(math.Ceil(time.Until(at).Seconds())))
+			found = true
+		}
+	}
+	if out.RetryAfterSeconds == 0 {
+		if d, ok := geminiRetryDelay(body); ok {
+			out.RetryAfterSeconds = int(math.Ceil(d.Seconds()))
+			found = true
+		}
+	}
+	if v, ok := readInt("x-ratelimit-limit-requests"); ok {
+		out.LimitRequests = v
+		found = true
+	}
+	if v, ok := readInt("x-ratelimit-limit-tokens"); ok {
+		out.LimitTokens = v
+		found = true
+	}
+	if v, ok := readInt("x-ratelimit-remaining-requests"); ok {
+		out.RemainingRequests = v
+		found = true
+	}
+	if v, ok := readInt("x-ratelimit-remaining-tokens"); ok {
+		out.RemainingTokens = v
+		found = true
+	}
+	if v, ok := readDur("x-ratelimit-reset-requests"); ok {
+		out.ResetRequests = v
+		found = true
+	}
+	if v, ok := readDur("x-ratelimit-reset-tokens"); ok {
+		out.ResetTokens = v
+		found = true
+	}
+
+	return out, found
+}
+
+// geminiRetryDelay extracts retryDelay from a google.rpc.RetryInfo error detail.
+func geminiRetryDelay(body []byte) (time.Duration, bool) {
+	if len(body) == 0 {
+		return 0, false
+	}
+	var payload struct {
+		Error struct {
+			Details []struct {
+				Type       string `json:"@type"`
+				RetryDelay string `json:"retryDelay"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return 0, false
+	}
+	for _, d := range payload.Error.Details {
+		if !strings.HasSuffix(d.Type, "google.rpc.RetryInfo") || d.RetryDelay == "" {
+			continue
+		}
+		delay, err := time.ParseDuration(d.RetryDelay)
+		if err == nil && delay > 0 {
+			return delay, true
+		}
+	}
+	return 0, false
+}
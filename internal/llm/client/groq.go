@@ -1,6 +1,7 @@
 package llmclient
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -30,15 +31,20 @@ type GroqClient struct {
 }
 
 // NewGroqClient creates a Groq client. If apiKey is empty, it falls back to GROQ_API_KEY env var.
-func NewGroqClient(apiKey, model string, tokenCap int) (*GroqClient, error) {
+// Requests go through httpCfg, bounded by DefaultHTTPTimeout unless it sets a Timeout.
+func NewGroqClient(apiKey, model string, tokenCap int, httpCfg HTTPClientConfig) (*GroqClient, error) {
 	if apiKey == "" {
 		apiKey = os.Getenv("GROQ_API_KEY")
 	}
 	if tokenCap <= 0 {
 		tokenCap = 6000
 	}
+	hc, err := httpCfg.client(DefaultHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
 	return &GroqClient{
-		http:     &http.Client{Timeout: 60 * time.Second},
+		http:     hc,
 		apiKey:   apiKey,
 		model:    model,
 		baseURL:  "https://api.groq.com/openai/v1/chat/completions",
@@ -70,10 +76,13 @@ func (g *GroqClient) LastRateLimitHeaders() (RateLimitHeaders, bool) {
 }
 
 type groqChatReq struct {
-	Model          string            `json:"model"`
-	Messages       []groqMessage     `json:"messages"`
-	Temperature    float32           `json:"temperature,omitempty"`
-	ResponseFormat map[string]string `json:"response_format,omitempty"`
+	Model               string            `json:"model"`
+	Messages            []groqMessage     `json:"messages"`
+	Temperature         *float32          `json:"temperature,omitempty"`
+	TopP                *float32          `json:"top_p,omitempty"`
+	MaxCompletionTokens int               `json:"max_completion_tokens,omitempty"`
+	ResponseFormat      map[string]string `json:"response_format,omitempty"`
+	Stream              bool              `json:"stream,omitempty"`
 }
 type groqMessage struct {
 	Role    string `json:"role"`
@@ -87,19 +96,101 @@ type groqChatResp struct {
 	} `json:"choices"`
 }
 
-// GenerateJSON assembles a single user message from prompt + input and requests JSON output.
+// groqStreamChunk is one server-sent event of a streamed completion.
+type groqStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+// GenerateJSON sends prompt as the system message and input as the user
+// message and requests JSON output. GenerationOptionsFrom(ctx) sets
+// temperature, top-p and the output cap.
 func (g *GroqClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	resp, err := g.send(ctx, prompt, input, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var out groqChatResp
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	if len(out.Choices) == 0 || out.Choices[0].Message.Content == "" {
+		return nil, ErrInvalidJSON
+	}
+	return validJSON(out.Choices[0].Message.Content)
+}
+
+// GenerateJSONStream streams the completion, passing each content delta to
+// onChunk. The request is bound to ctx: cancelling it aborts the HTTP
+// request, and no chunk is delivered after ctx is done.
+func (g *GroqClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	resp, err := g.send(ctx, prompt, input, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var content strings.Builder
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk groqStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, ErrInvalidJSON
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		delta := chunk.Choices[0].Delta.Content
+		content.WriteString(delta)
+		if onChunk != nil {
+			onChunk(delta)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if content.Len() == 0 {
+		return nil, ErrInvalidJSON
+	}
+	return validJSON(content.String())
+}
+
+// send posts the chat request and returns the successful response, whose
+// body the caller closes.
+func (g *GroqClient) send(ctx context.Context, prompt string, input any, stream bool) (*http.Response, error) {
 	in, _ := json.MarshalIndent(input, "", "  ")
 	userContent := "[INPUT JSON]\n" + string(in)
 
+	opts := GenerationOptionsFrom(ctx)
 	reqBody := groqChatReq{
 		Model: g.model,
 		Messages: []groqMessage{
 			{Role: "system", Content: prompt},
 			{Role: "user", Content: userContent},
 		},
-		Temperature:    0,
-		ResponseFormat: map[string]string{"type": "json_object"},
+		Temperature:         opts.temperature(),
+		TopP:                opts.TopP,
+		MaxCompletionTokens: max(opts.MaxOutputTokens, 0),
+		ResponseFormat:      map[string]string{"type": "json_object"},
+		Stream:              stream,
 	}
 	b, _ := json.Marshal(reqBody)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL, bytes.NewReader(b))
@@ -113,32 +204,20 @@ func (g *GroqClient) GenerateJSON(ctx context.Context, prompt string, input any)
 
 	resp, err := g.http.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, contextErr(ctx, err)
 	}
-	defer resp.Body.Close()
 	g.captureRateLimitHeaders(resp.Header)
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		const max = 2048
-		if len(body) > max {
-			body = body[:max]
-		}
-		err := fmt.Errorf("groq: unexpected status %s: %s", resp.Status, string(body))
-		// Check for context length exceeded (permanent error)
-		if resp.StatusCode == 400 && strings.Contains(string(body), `"code":"context_length_exceeded"`) {
-			return nil, NewPermanentError(err)
-		}
-		return nil, err
-	}
-	var out groqChatResp
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, err
-	}
-	if len(out.Choices) == 0 || out.Choices[0].Message.Content == "" {
-		return nil, ErrInvalidJSON
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyRead))
+		resp.Body.Close()
+		return nil, fmt.Errorf("groq: %w", ClassifyHTTPError(resp.StatusCode, body))
 	}
-	// Ensure the content is valid JSON; if not, wrap with an error
-	raw := json.RawMessage(out.Choices[0].Message.Content)
+	return resp, nil
+}
+
+// validJSON returns content as JSON, or ErrInvalidJSON when it does not parse.
+func validJSON(content string) (json.RawMessage, error) {
+	raw := json.RawMessage(content)
 	var scratch any
 	if err := json.Unmarshal(raw, &scratch); err != nil {
 		return nil, ErrInvalidJSON
@@ -146,6 +225,16 @@ func (g *GroqClient) GenerateJSON(ctx context.Context, prompt string, input any)
 	return raw, nil
 }
 
+// contextErr returns ctx's error in place of err when ctx is done, so
+// transport errors caused by cancellation read as context.Canceled or
+// context.DeadlineExceeded.
+func contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
 func (g *GroqClient) captureRateLimitHeaders(h http.Header) {
 	parsed, ok := parseGroqRateLimitHeaders(h)
 	if !ok {
@@ -161,23 +250,19 @@ func (g *GroqClient) captureRateLimitHeaders(h http.Header) {
 	}
 }
 
-func (g *GroqClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
-	raw, err := g.GenerateJSON(ctx, prompt, input)
-	if err != nil {
-		return nil, err
-	}
-	if onChunk != nil {
-		onChunk(string(raw))
-	}
-	return raw, nil
+// groqTierMultipliers scales the free-tier limits listed in
+// RegisterGroqModelsForTier for paid tiers.
+var groqTierMultipliers = TierMultiplier{
+	TierDeveloper:  {Requests: 3, Tokens: 3, Burst: 2},
+	TierEnterprise: {Requests: 10, Tokens: 10, Burst: 4},
 }
 
 func RegisterGroqModels(reg ModelRegistrar) error {
-	return RegisterGroqModelsForTier(reg, "free")
+	return RegisterGroqModelsForTier(reg, TierFree)
 }
 
 func RegisterGroqModelsForTier(reg ModelRegistrar, tier string) error {
-	tier = normalizeTier(tier, "free")
+	tier = normalizeTier(tier, TierFree)
 
 	type groqModel struct {
 		name   string
@@ -185,53 +270,36 @@ func RegisterGroqModelsForTier(reg ModelRegistrar, tier string) error {
 		tokens int
 		meta   map[string]any
 		limit  *RateLimitConfig
+		price  *Pricing
 	}
 	// Base limits are sourced from Groq rate-limit docs and used as defaults.
 	// See: https://console.groq.com/docs/rate-limits
 	// Note: limits can vary by account/tier. These values are hints, not guarantees.
+	// Prices are list prices per million tokens from https://groq.com/pricing;
+	// models without one are reported as unpriced.
 	models := []groqModel{
 		{name: "allam-2-7b", level: ModelLevelLow, tokens: 6000, meta: map[string]any{"params": 7_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 7_000, TPM: 6_000, TPD: 500_000}},
 		{name: "groq/compound", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 0}, limit: &RateLimitConfig{RPM: 15, RPD: 200}},
 		{name: "groq/compound-mini", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 0}, limit: &RateLimitConfig{RPM: 15, RPD: 200}},
-		{name: "llama-3.1-8b-instant", level: ModelLevelLow, tokens: 6000, meta: map[string]any{"params": 8_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 14_400, TPM: 6_000, TPD: 500_000}},
-		{name: "llama-3.3-70b-versatile", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 70_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 12_000, TPD: 100_000}},
-		{name: "llama-3.3-70b-versatile", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 70_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 12_000, TPD: 100_000}},
-		{name: "llama-3.3-70b-versatile", level: ModelLevelXHigh, tokens: 6000, meta: map[string]any{"params": 70_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 12_000, TPD: 100_000}},
-		{name: "meta-llama/llama-4-maverick-17b-128e-instruct", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 17_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 6_000, TPD: 500_000}},
-		{name: "meta-llama/llama-4-scout-17b-16e-instruct", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 17_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 30_000, TPD: 500_000}},
+		{name: "llama-3.1-8b-instant", level: ModelLevelLow, tokens: 6000, meta: map[string]any{"params": 8_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 14_400, TPM: 6_000, TPD: 500_000}, price: &Pricing{InputPerMTok: 0.05, OutputPerMTok: 0.08}},
+		{name: "llama-3.3-70b-versatile", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 70_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 12_000, TPD: 100_000}, price: &Pricing{InputPerMTok: 0.59, OutputPerMTok: 0.79}},
+		{name: "llama-3.3-70b-versatile", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 70_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 12_000, TPD: 100_000}, price: &Pricing{InputPerMTok: 0.59, OutputPerMTok: 0.79}},
+		{name: "llama-3.3-70b-versatile", level: ModelLevelXHigh, tokens: 6000, meta: map[string]any{"params": 70_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 12_000, TPD: 100_000}, price: &Pricing{InputPerMTok: 0.59, OutputPerMTok: 0.79}},
+		{name: "meta-llama/llama-4-maverick-17b-128e-instruct", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 17_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 6_000, TPD: 500_000}, price: &Pricing{InputPerMTok: 0.20, OutputPerMTok: 0.60}},
+		{name: "meta-llama/llama-4-scout-17b-16e-instruct", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 17_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 30_000, TPD: 500_000}, price: &Pricing{InputPerMTok: 0.11, OutputPerMTok: 0.34}},
 		{name: "meta-llama/llama-guard-4-12b", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 12_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 14_400, TPM: 15_000, TPD: 500_000}},
 		{name: "meta-llama/llama-prompt-guard-2-22m", level: ModelLevelLow, tokens: 6000, meta: map[string]any{"params": 22_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 14_400, TPM: 15_000, TPD: 500_000}},
 		{name: "meta-llama/llama-prompt-guard-2-86m", level: ModelLevelLow, tokens: 6000, meta: map[string]any{"params": 86_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 14_400, TPM: 15_000, TPD: 500_000}},
-		{name: "moonshotai/kimi-k2-instruct", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 0}, limit: &RateLimitConfig{RPM: 60, RPD: 1_000, TPM: 10_000, TPD: 300_000}},
-		{name: "moonshotai/kimi-k2-instruct-0905", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 0}, limit: &RateLimitConfig{RPM: 60, RPD: 1_000, TPM: 10_000, TPD: 300_000}},
-		{name: "openai/gpt-oss-120b", level: ModelLevelXHigh, tokens: 6000, meta: map[string]any{"params": 120_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 8_000, TPD: 200_000}},
-		{name: "openai/gpt-oss-20b", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 20_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 8_000, TPD: 200_000}},
+		{name: "moonshotai/kimi-k2-instruct", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 0}, limit: &RateLimitConfig{RPM: 60, RPD: 1_000, TPM: 10_000, TPD: 300_000}, price: &Pricing{InputPerMTok: 1.00, OutputPerMTok: 3.00}},
+		{name: "moonshotai/kimi-k2-instruct-0905", level: ModelLevelHigh, tokens: 6000, meta: map[string]any{"params": 0}, limit: &RateLimitConfig{RPM: 60, RPD: 1_000, TPM: 10_000, TPD: 300_000}, price: &Pricing{InputPerMTok: 1.00, OutputPerMTok: 3.00}},
+		{name: "openai/gpt-oss-120b", level: ModelLevelXHigh, tokens: 6000, meta: map[string]any{"params": 120_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 8_000, TPD: 200_000}, price: &Pricing{InputPerMTok: 0.15, OutputPerMTok: 0.75}},
+		{name: "openai/gpt-oss-20b", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 20_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 8_000, TPD: 200_000}, price: &Pricing{InputPerMTok: 0.10, OutputPerMTok: 0.50}},
 		{name: "openai/gpt-oss-safeguard-20b", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 20_000_000_000}, limit: &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 8_000, TPD: 200_000}},
-		{name: "qwen/qwen3-32b", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 32_000_000_000}, limit: &RateLimitConfig{RPM: 60, RPD: 1_000, TPM: 6_000, TPD: 500_000}},
+		{name: "qwen/qwen3-32b", level: ModelLevelMiddle, tokens: 6000, meta: map[string]any{"params": 32_000_000_000}, limit: &RateLimitConfig{RPM: 60, RPD: 1_000, TPM: 6_000, TPD: 500_000}, price: &Pricing{InputPerMTok: 0.29, OutputPerMTok: 0.59}},
 		{name: "whisper-large-v3", level: ModelLevelLow, tokens: 6000, meta: map[string]any{"params": 0, "modality": "audio"}, limit: &RateLimitConfig{RPM: 20, RPD: 2_000}},
 		{name: "whisper-large-v3-turbo", level: ModelLevelLow, tokens: 6000, meta: map[string]any{"params": 0, "modality": "audio"}, limit: &RateLimitConfig{RPM: 20, RPD: 2_000}},
 	}
 
-	boostLimitForTier := func(in *RateLimitConfig) *RateLimitConfig {
-		if in == nil {
-			return nil
-		}
-		out := *in
-		if tier == "developer" {
-			out.RPM *= 3
-			out.RPD *= 3
-			out.TPM *= 3
-			out.TPD *= 3
-			if out.RPS > 0 {
-				out.RPS = out.RPS * 3
-			}
-			if out.Burst > 0 {
-				out.Burst = out.Burst * 2
-			}
-		}
-		return &out
-	}
-
 	for _, m := range models {
 		modelName := m.name
 		tokens := m.tokens
@@ -244,13 +312,18 @@ func RegisterGroqModelsForTier(reg ModelRegistrar, tier string) error {
 			Level:     level,
 			MaxTokens: tokens,
 			Meta:      meta,
-			RateLimit: boostLimitForTier(m.limit),
+			RateLimit: ApplyTierMultiplier(m.limit, tier, groqTierMultipliers),
+			Pricing:   PricingForTier(m.price, tier),
 			Factory: func(ctx context.Context, tokenCap int) (LLMClient, error) {
 				_ = ctx
 				if tokenCap <= 0 {
 					tokenCap = tokens
 				}
-				return NewGroqClient(os.Getenv("GROQ_API_KEY"), modelName, tokenCap)
+				httpCfg, err := HTTPClientConfigFromEnv()
+				if err != nil {
+					return nil, err
+				}
+				return NewGroqClient(os.Getenv("GROQ_API_KEY"), modelName, tokenCap, httpCfg)
 			},
 		}); err != nil {
 			return err
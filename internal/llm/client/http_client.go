@@ -0,0 +1,118 @@
+package llmclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Environment variables read by HTTPClientConfigFromEnv.
+const (
+	HTTPTimeoutEnv      = "LLM_HTTP_TIMEOUT_MS"
+	HTTPProxyEnv        = "LLM_HTTP_PROXY"
+	HTTPCAFileEnv       = "LLM_HTTP_CA_FILE"
+	HTTPMaxIdleConnsEnv = "LLM_HTTP_MAX_IDLE_CONNS"
+)
+
+// DefaultHTTPTimeout bounds Groq requests when HTTPClientConfig.Timeout is 0.
+const DefaultHTTPTimeout = 60 * time.Second
+
+// HTTPClientConfig tunes the HTTP client of the provider clients. The zero
+// value keeps each client's defaults.
+type HTTPClientConfig struct {
+	// Timeout bounds each request; 0 uses the client's default.
+	Timeout time.Duration
+	// ProxyURL routes every request through this proxy. When empty the
+	// HTTPS_PROXY / HTTP_PROXY / NO_PROXY environment is honored.
+	ProxyURL string
+	// RootCAs verifies servers instead of the system pool, e.g. for a
+	// TLS-intercepting corporate proxy.
+	RootCAs *x509.CertPool
+	// MaxIdleConns caps idle keep-alive connections; 0 keeps the
+	// net/http default.
+	MaxIdleConns int
+}
+
+// HTTPClientConfigFromEnv reads LLM_HTTP_TIMEOUT_MS, LLM_HTTP_PROXY,
+// LLM_HTTP_CA_FILE (PEM, added to the system pool) and
+// LLM_HTTP_MAX_IDLE_CONNS.
+func HTTPClientConfigFromEnv() (HTTPClientConfig, error) {
+	var cfg HTTPClientConfig
+	if raw := strings.TrimSpace(os.Getenv(HTTPTimeoutEnv)); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil || ms < 0 {
+			return cfg, fmt.Errorf("%s: invalid value %q", HTTPTimeoutEnv, raw)
+		}
+		cfg.Timeout = time.Duration(ms) * time.Millisecond
+	}
+	cfg.ProxyURL = strings.TrimSpace(os.Getenv(HTTPProxyEnv))
+	if path := strings.TrimSpace(os.Getenv(HTTPCAFileEnv)); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, fmt.Errorf("%s: %w", HTTPCAFileEnv, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return cfg, fmt.Errorf("%s: no certificates in %s", HTTPCAFileEnv, path)
+		}
+		cfg.RootCAs = pool
+	}
+	if raw := strings.TrimSpace(os.Getenv(HTTPMaxIdleConnsEnv)); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("%s: invalid value %q", HTTPMaxIdleConnsEnv, raw)
+		}
+		cfg.MaxIdleConns = n
+	}
+	return cfg, nil
+}
+
+// Transport builds the transport for c, cloned from http.DefaultTransport.
+// The proxy environment is read here rather than once per process, so
+// clients built later see changes to it.
+func (c HTTPClientConfig) Transport() (*http.Transport, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if c.ProxyURL != "" {
+		u, err := url.Parse(c.ProxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy url %q", c.ProxyURL)
+		}
+		tr.Proxy = http.ProxyURL(u)
+	} else {
+		proxy := httpproxy.FromEnvironment().ProxyFunc()
+		tr.Proxy = func(req *http.Request) (*url.URL, error) { return proxy(req.URL) }
+	}
+	if c.RootCAs != nil {
+		tr.TLSClientConfig = &tls.Config{RootCAs: c.RootCAs, MinVersion: tls.VersionTLS12}
+	}
+	if c.MaxIdleConns > 0 {
+		tr.MaxIdleConns = c.MaxIdleConns
+		tr.MaxIdleConnsPerHost = c.MaxIdleConns
+	}
+	return tr, nil
+}
+
+// client builds the http.Client for c, bounding requests by defaultTimeout
+// when c.Timeout is 0 (0 leaves them unbounded).
+func (c HTTPClientConfig) client(defaultTimeout time.Duration) (*http.Client, error) {
+	tr, err := c.Transport()
+	if err != nil {
+		return nil, err
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &http.Client{Transport: tr, Timeout: timeout}, nil
+}
@@ -0,0 +1,60 @@
+package llmclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// maxErrorBody bounds how much of a provider error body is kept in errors.
+	maxErrorBody = 2048
+	// maxErrorBodyRead bounds how much of a provider error body is read.
+	maxErrorBodyRead = 64 << 10
+)
+
+// HTTPStatusError is a non-2xx response from a provider API.
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// ClassifyHTTPError turns a provider response into an error. It returns nil
+// for 2xx, a *PermanentError for failures retrying cannot fix (bad key,
+// malformed request, content policy, context length), and a plain
+// *HTTPStatusError for retryable ones (429, 5xx).
+func ClassifyHTTPError(status int, body []byte) error {
+	if status >= 200 && status < 300 {
+		return nil
+	}
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	err := &HTTPStatusError{StatusCode: status, Body: string(body)}
+	if isPermanentHTTPFailure(status) {
+		return NewPermanentError(err)
+	}
+	return err
+}
+
+// IsPermanent reports whether err, or anything it wraps, is a *PermanentError.
+func IsPermanent(err error) bool {
+	var pErr *PermanentError
+	return errors.As(err, &pErr)
+}
+
+// isPermanentHTTPFailure treats every 4xx except throttling/transient
+// conflicts as permanent: bad credentials (401/403), malformed or oversized
+// requests (400/413/422, including context length and content policy
+// rejections) and unknown models (404) fail the same way on every attempt.
+func isPermanentHTTPFailure(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusRequestTimeout, http.StatusConflict, http.StatusTooEarly:
+		return false
+	}
+	return status >= 400 && status < 500
+}
@@ -0,0 +1,46 @@
+package llmclient
+
+import "context"
+
+// GenerationOptions tunes sampling for one call. A nil Temperature means
+// DefaultTemperature; other nil or zero fields keep the provider default.
+// Temperature and TopP are pointers so 0 can be requested.
+type GenerationOptions struct {
+	Temperature     *float32 `json:"temperature,omitempty"`
+	TopP            *float32 `json:"top_p,omitempty"`
+	MaxOutputTokens int      `json:"max_output_tokens,omitempty"`
+}
+
+// IsZero reports whether o leaves every setting to the provider.
+func (o GenerationOptions) IsZero() bool {
+	return o.Temperature == nil && o.TopP == nil && o.MaxOutputTokens <= 0
+}
+
+// DefaultTemperature is sent when no temperature is set, so JSON output is
+// deterministic unless a phase asks for diversity.
+const DefaultTemperature float32 = 0
+
+// temperature returns the temperature to send for o.
+func (o GenerationOptions) temperature() *float32 {
+	if o.Temperature != nil {
+		return o.Temperature
+	}
+	return Float32(DefaultTemperature)
+}
+
+// Float32 returns a pointer to v, for GenerationOptions literals.
+func Float32(v float32) *float32 { return &v }
+
+type ctxKeyGenerationOptions struct{}
+
+// WithGenerationOptions attaches opts to the calls made with ctx. Clients
+// read them when building the request.
+func WithGenerationOptions(ctx context.Context, opts GenerationOptions) context.Context {
+	return context.WithValue(ctx, ctxKeyGenerationOptions{}, opts)
+}
+
+// GenerationOptionsFrom returns the options attached to ctx, or zero options.
+func GenerationOptionsFrom(ctx context.Context) GenerationOptions {
+	opts, _ := ctx.Value(ctxKeyGenerationOptions{}).(GenerationOptions)
+	return opts
+}
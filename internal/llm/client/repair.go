@@ -0,0 +1,100 @@
+package llmclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// reFence matches a markdown code fence around the payload, e.g. ```json ... ```.
+var reFence = regexp.MustCompile("(?s)```[A-Za-z0-9_-]*[ \t]*\r?\n?(.*?)```")
+
+// RepairJSON fixes the usual ways models wrap or bend JSON: markdown fences,
+// prose before or after the outer object or array, typographic quotes used
+// as string delimiters and trailing commas. Valid input is returned as is.
+// The result is validated; unrepairable input fails with ErrInvalidJSON.
+func RepairJSON(raw []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if json.Valid(trimmed) {
+		return trimmed, nil
+	}
+	text := trimmed
+	if m := reFence.FindSubmatch(text); m != nil {
+		text = bytes.TrimSpace(m[1])
+	}
+	start := bytes.IndexAny(text, "{[")
+	if start < 0 {
+		return nil, fmt.Errorf("%w: no JSON object or array found", ErrInvalidJSON)
+	}
+	out := repairValue(text[start:])
+	if !json.Valid(out) {
+		return nil, fmt.Errorf("%w: still invalid after repair", ErrInvalidJSON)
+	}
+	return out, nil
+}
+
+// repairValue copies the JSON value text starts with, turning typographic
+// string delimiters into ASCII quotes and dropping trailing commas. It stops
+// at the bracket closing the outer value, which drops trailing prose.
+func repairValue(text []byte) []byte {
+	var (
+		out     bytes.Buffer
+		depth   int
+		inStr   bool
+		smart   bool // string opened by a typographic quote
+		escaped bool
+	)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		i += size
+		if inStr {
+			switch {
+			case escaped:
+				escaped = false
+				out.WriteRune(r)
+			case r == '\\':
+				escaped = true
+				out.WriteRune(r)
+			case !smart && r == '"', smart && isSmartDouble(r):
+				inStr = false
+				out.WriteByte('"')
+			case smart && r == '"':
+				out.WriteString(`\"`)
+			default:
+				out.WriteRune(r)
+			}
+			continue
+		}
+		switch {
+		case r == '"' || isSmartDouble(r):
+			inStr, smart = true, r != '"'
+			out.WriteByte('"')
+		case r == '{' || r == '[':
+			depth++
+			out.WriteRune(r)
+		case r == '}' || r == ']':
+			depth--
+			out.WriteRune(r)
+			if depth == 0 {
+				return out.Bytes()
+			}
+		case r == ',' && closesNext(text[i:]):
+			// trailing comma
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.Bytes()
+}
+
+func isSmartDouble(r rune) bool {
+	return r == '“' || r == '”' || r == '„'
+}
+
+// closesNext reports whether the next non-space byte closes an object or array.
+func closesNext(rest []byte) bool {
+	rest = bytes.TrimLeft(rest, " \t\r\n")
+	return len(rest) > 0 && (rest[0] == '}' || rest[0] == ']')
+}
@@ -46,3 +46,75 @@ func TestRegisterGeminiModelsForTier_AnnotatesTier(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyTierMultiplier_ScalesPerTier(t *testing.T) {
+	base := &RateLimitConfig{RPM: 30, RPD: 1_000, TPM: 6_000, TPD: 500_000, RPS: 0.5, Burst: 2}
+	mult := TierMultiplier{
+		TierDeveloper:  {Requests: 3, Tokens: 3, Burst: 2},
+		TierEnterprise: {Requests: 10, Tokens: 10, Burst: 4},
+	}
+	tests := []struct {
+		tier string
+		want RateLimitConfig
+	}{
+		{tier: "free", want: *base},
+		{tier: "", want: *base},
+		{tier: "unknown", want: *base},
+		{tier: " Developer ", want: RateLimitConfig{RPM: 90, RPD: 3_000, TPM: 18_000, TPD: 1_500_000, RPS: 1.5, Burst: 4}},
+		{tier: "enterprise", want: RateLimitConfig{RPM: 300, RPD: 10_000, TPM: 60_000, TPD: 5_000_000, RPS: 5, Burst: 8}},
+	}
+	for _, tt := range tests {
+		got := ApplyTierMultiplier(base, tt.tier, mult)
+		if got == base {
+			t.Fatalf("tier %q: expected a copy", tt.tier)
+		}
+		if *got != tt.want {
+			t.Fatalf("tier %q: got=%+v want=%+v", tt.tier, *got, tt.want)
+		}
+	}
+	if base.RPM != 30 || base.Burst != 2 {
+		t.Fatalf("base config was modified: %+v", *base)
+	}
+	if ApplyTierMultiplier(nil, TierDeveloper, mult) != nil {
+		t.Fatalf("nil config should stay nil")
+	}
+}
+
+func TestApplyTierMultiplier_ZeroFactorKeepsField(t *testing.T) {
+	base := &RateLimitConfig{RPM: 15, TPM: 1_000, RPS: 0.25, Burst: 1}
+	got := ApplyTierMultiplier(base, "tier1", TierMultiplier{"tier1": {Requests: 4}})
+	want := RateLimitConfig{RPM: 60, TPM: 1_000, RPS: 1, Burst: 1}
+	if *got != want {
+		t.Fatalf("got=%+v want=%+v", *got, want)
+	}
+}
+
+func TestRegisterModelsForTier_EnterpriseLimits(t *testing.T) {
+	groq := &collectRegistrar{}
+	if err := RegisterGroqModelsForTier(groq, TierEnterprise); err != nil {
+		t.Fatalf("register groq enterprise: %v", err)
+	}
+	for _, spec := range groq.specs {
+		if spec.Model != "allam-2-7b" {
+			continue
+		}
+		want := RateLimitConfig{RPM: 300, RPD: 70_000, TPM: 60_000, TPD: 5_000_000}
+		if spec.Tier != TierEnterprise || *spec.RateLimit != want {
+			t.Fatalf("groq allam-2-7b: tier=%s limit=%+v want=%+v", spec.Tier, *spec.RateLimit, want)
+		}
+	}
+
+	gemini := &collectRegistrar{}
+	if err := RegisterGeminiModelsForTier(gemini, TierEnterprise); err != nil {
+		t.Fatalf("register gemini enterprise: %v", err)
+	}
+	if len(gemini.specs) == 0 {
+		t.Fatalf("expected registered models")
+	}
+	want := RateLimitConfig{RPM: 240, RPS: 4, Burst: 4}
+	for _, spec := range gemini.specs {
+		if *spec.RateLimit != want {
+			t.Fatalf("gemini %s: limit=%+v want=%+v", spec.Model, *spec.RateLimit, want)
+		}
+	}
+}
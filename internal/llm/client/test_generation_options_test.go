@@ -0,0 +1,124 @@
+package llmclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	genai "google.golang.org/genai"
+)
+
+// captureServer records the JSON body of the last request and answers with reply.
+func captureServer(t *testing.T, reply string, got *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, got); err != nil {
+			t.Errorf("request body %s: %v", body, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGeminiClient_SendsSystemInstructionAndGenerationConfig(t *testing.T) {
+	var got map[string]any
+	srv := captureServer(t, `{"candidates":[{"content":{"parts":[{"text":"{\"ok\":true}"}]}}]}`, &got)
+	g, err := newGeminiClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL + "/"},
+	}, "gemini-2.5-flash", 0, nil)
+	if err != nil {
+		t.Fatalf("newGeminiClient: %v", err)
+	}
+
+	ctx := WithGenerationOptions(context.Background(), GenerationOptions{Temperature: Float32(0), TopP: Float32(0.5), MaxOutputTokens: 4096})
+	if _, err := g.GenerateJSON(ctx, "the prompt", map[string]int{"n": 1}); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	system, _ := json.Marshal(got["systemInstruction"])
+	if !strings.Contains(string(system), `"text":"the prompt"`) {
+		t.Fatalf("systemInstruction = %s, want the prompt", system)
+	}
+	contents, _ := json.Marshal(got["contents"])
+	if strings.Contains(string(contents), "the prompt") || !strings.Contains(string(contents), `[INPUT JSON]`) {
+		t.Fatalf("contents = %s, want only the input", contents)
+	}
+	cfg, _ := got["generationConfig"].(map[string]any)
+	if cfg["temperature"] != 0.0 || cfg["topP"] != 0.5 || cfg["maxOutputTokens"] != 4096.0 || cfg["responseMimeType"] != "application/json" {
+		t.Fatalf("generationConfig = %v", cfg)
+	}
+
+	ctx = WithGenerationOptions(context.Background(), GenerationOptions{Temperature: Float32(0.4)})
+	if _, err := g.GenerateJSON(ctx, "the prompt", nil); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if cfg, _ = got["generationConfig"].(map[string]any); cfg["temperature"] != 0.4 {
+		t.Fatalf("generationConfig = %v, want temperature 0.4", cfg)
+	}
+
+	// Without options the temperature is 0 and the other provider defaults apply.
+	got = nil
+	if _, err := g.GenerateJSON(context.Background(), "the prompt", nil); err != nil {
+		t.Fatalf("GenerateJSON without options: %v", err)
+	}
+	cfg, _ = got["generationConfig"].(map[string]any)
+	if cfg["temperature"] != 0.0 {
+		t.Fatalf("generationConfig = %v, want temperature 0", cfg)
+	}
+	for _, k := range []string{"topP", "maxOutputTokens"} {
+		if _, ok := cfg[k]; ok {
+			t.Fatalf("generationConfig = %v, want no %s", cfg, k)
+		}
+	}
+}
+
+func TestGroqClient_SendsGenerationOptions(t *testing.T) {
+	var got map[string]any
+	srv := captureServer(t, `{"choices":[{"message":{"content":"{\"ok\":true}"}}]}`, &got)
+	g, err := NewGroqClient("test-key", "llama-3.1-8b-instant", 0, HTTPClientConfig{})
+	if err != nil {
+		t.Fatalf("NewGroqClient: %v", err)
+	}
+	g.baseURL = srv.URL
+
+	ctx := WithGenerationOptions(context.Background(), GenerationOptions{Temperature: Float32(0), TopP: Float32(0.5), MaxOutputTokens: 4096})
+	if _, err := g.GenerateJSON(ctx, "the prompt", nil); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if got["temperature"] != 0.0 || got["top_p"] != 0.5 || got["max_completion_tokens"] != 4096.0 {
+		t.Fatalf("request = %v", got)
+	}
+	msgs, _ := got["messages"].([]any)
+	if len(msgs) != 2 || msgs[0].(map[string]any)["role"] != "system" || msgs[0].(map[string]any)["content"] != "the prompt" {
+		t.Fatalf("messages = %v, want the prompt as system message", msgs)
+	}
+
+	ctx = WithGenerationOptions(context.Background(), GenerationOptions{Temperature: Float32(0.4)})
+	if _, err := g.GenerateJSON(ctx, "the prompt", nil); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if got["temperature"] != 0.4 {
+		t.Fatalf("request = %v, want temperature 0.4", got)
+	}
+
+	got = nil
+	if _, err := g.GenerateJSON(context.Background(), "the prompt", nil); err != nil {
+		t.Fatalf("GenerateJSON without options: %v", err)
+	}
+	if got["temperature"] != 0.0 {
+		t.Fatalf("request = %v, want temperature 0", got)
+	}
+	for _, k := range []string{"top_p", "max_completion_tokens"} {
+		if _, ok := got[k]; ok {
+			t.Fatalf("request = %v, want no %s", got, k)
+		}
+	}
+}
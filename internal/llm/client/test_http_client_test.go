@@ -0,0 +1,121 @@
+package llmclient
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+const groqOK = `{"choices":[{"message":{"content":"{\"ok\":true}"}}]}`
+
+// stubProxy records the targets it was asked for. Plain requests are answered
+// directly; CONNECT tunnels to upstream.
+type stubProxy struct {
+	mu       sync.Mutex
+	targets  []string
+	upstream string
+}
+
+func (p *stubProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.targets = append(p.targets, r.Method+" "+r.Host)
+	p.mu.Unlock()
+	if r.Method != http.MethodConnect {
+		_, _ = io.WriteString(w, groqOK)
+		return
+	}
+	up, err := net.Dial("tcp", p.upstream)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		up.Close()
+		return
+	}
+	_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	go func() { _, _ = io.Copy(up, conn); up.Close() }()
+	go func() { _, _ = io.Copy(conn, up); conn.Close() }()
+}
+
+func (p *stubProxy) seen() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.targets...)
+}
+
+func TestGroqClientUsesExplicitProxy(t *testing.T) {
+	proxy := &stubProxy{}
+	srv := httptest.NewServer(proxy)
+	defer srv.Close()
+
+	g, err := NewGroqClient("key", "llama-3.1-8b-instant", 0, HTTPClientConfig{ProxyURL: srv.URL, Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("NewGroqClient: %v", err)
+	}
+	g.baseURL = "http://llm.example/v1/chat/completions"
+	if _, err := g.GenerateJSON(context.Background(), "p", nil); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if got := proxy.seen(); len(got) != 1 || got[0] != "POST llm.example" {
+		t.Fatalf("proxy saw %v, want the request to llm.example", got)
+	}
+	if g.http.Timeout != 5*time.Second {
+		t.Fatalf("timeout = %v, want 5s", g.http.Timeout)
+	}
+}
+
+func TestGroqClientHonorsHTTPSProxyEnv(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, groqOK)
+	}))
+	defer upstream.Close()
+	proxy := &stubProxy{upstream: upstream.Listener.Addr().String()}
+	srv := httptest.NewServer(proxy)
+	defer srv.Close()
+	t.Setenv("HTTPS_PROXY", srv.URL)
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(upstream.Certificate())
+	g, err := NewGroqClient("key", "llama-3.1-8b-instant", 0, HTTPClientConfig{RootCAs: roots})
+	if err != nil {
+		t.Fatalf("NewGroqClient: %v", err)
+	}
+	g.baseURL = "https://api.example.com/v1/chat/completions"
+	if _, err := g.GenerateJSON(context.Background(), "p", nil); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if got := proxy.seen(); len(got) != 1 || got[0] != "CONNECT api.example.com:443" {
+		t.Fatalf("proxy saw %v, want a tunnel to api.example.com", got)
+	}
+	if g.http.Timeout != DefaultHTTPTimeout {
+		t.Fatalf("timeout = %v, want the default", g.http.Timeout)
+	}
+}
+
+func TestHTTPClientConfigFromEnv(t *testing.T) {
+	t.Setenv(HTTPTimeoutEnv, "1500")
+	t.Setenv(HTTPProxyEnv, "http://proxy.internal:3128")
+	t.Setenv(HTTPMaxIdleConnsEnv, "8")
+	cfg, err := HTTPClientConfigFromEnv()
+	if err != nil || cfg.Timeout != 1500*time.Millisecond || cfg.ProxyURL != "http://proxy.internal:3128" || cfg.MaxIdleConns != 8 {
+		t.Fatalf("HTTPClientConfigFromEnv() = %+v, %v", cfg, err)
+	}
+
+	t.Setenv(HTTPTimeoutEnv, "soon")
+	if _, err := HTTPClientConfigFromEnv(); err == nil {
+		t.Fatalf("HTTPClientConfigFromEnv() accepted an invalid timeout")
+	}
+	if _, err := NewGroqClient("key", "m", 0, HTTPClientConfig{ProxyURL: "://bad"}); err == nil {
+		t.Fatalf("NewGroqClient() accepted an invalid proxy url")
+	}
+}
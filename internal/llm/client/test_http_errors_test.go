@@ -0,0 +1,108 @@
+package llmclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClassifyHTTPError(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    int
+		body      string
+		wantNil   bool
+		permanent bool
+	}{
+		{name: "ok", status: 200, wantNil: true},
+		{name: "bad request", status: 400, body: `{"error":{"message":"invalid json"}}`, permanent: true},
+		{name: "context length", status: 400, body: `{"error":{"code":"context_length_exceeded"}}`, permanent: true},
+		{name: "content policy", status: 400, body: `{"error":{"code":"content_policy_violation"}}`, permanent: true},
+		{name: "invalid api key", status: 401, body: `{"error":{"code":"invalid_api_key"}}`, permanent: true},
+		{name: "forbidden", status: 403, body: `PERMISSION_DENIED`, permanent: true},
+		{name: "unknown model", status: 404, permanent: true},
+		{name: "too large", status: 413, permanent: true},
+		{name: "rate limited", status: 429, body: `{"error":{"code":"rate_limit_exceeded"}}`},
+		{name: "timeout", status: 408},
+		{name: "conflict", status: 409},
+		{name: "server error", status: 500},
+		{name: "unavailable", status: 503, body: `overloaded`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ClassifyHTTPError(tc.status, []byte(tc.body))
+			if tc.wantNil {
+				if err != nil {
+					t.Fatalf("expected nil, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error for status %d", tc.status)
+			}
+			if got := IsPermanent(err); got != tc.permanent {
+				t.Fatalf("IsPermanent = %v, want %v (%v)", got, tc.permanent, err)
+			}
+			var statusErr *HTTPStatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tc.status {
+				t.Fatalf("expected HTTPStatusError with status %d, got %v", tc.status, err)
+			}
+		})
+	}
+}
+
+func TestClassifyHTTPErrorTruncatesBody(t *testing.T) {
+	err := ClassifyHTTPError(500, []byte(strings.Repeat("x", maxErrorBody*2)))
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || len(statusErr.Body) != maxErrorBody {
+		t.Fatalf("expected body truncated to %d bytes, got %v", maxErrorBody, err)
+	}
+}
+
+func TestGroqClientClassifiesStatus(t *testing.T) {
+	cases := []struct {
+		status    int
+		permanent bool
+	}{
+		{status: 401, permanent: true},
+		{status: 400, permanent: true},
+		{status: 429, permanent: false},
+		{status: 502, permanent: false},
+	}
+	for _, tc := range cases {
+		g, _ := NewGroqClient("key", "llama-3.1-8b-instant", 0, HTTPClientConfig{})
+		g.http = &http.Client{Transport: stubGeminiResponse(tc.status, http.Header{}, `{"error":{}}`)}
+		_, err := g.GenerateJSON(context.Background(), "prompt", nil)
+		if err == nil {
+			t.Fatalf("status %d: expected error", tc.status)
+		}
+		if got := IsPermanent(err); got != tc.permanent {
+			t.Fatalf("status %d: IsPermanent = %v, want %v (%v)", tc.status, got, tc.permanent, err)
+		}
+		if !strings.HasPrefix(err.Error(), "groq: unexpected status") {
+			t.Fatalf("status %d: unexpected message %q", tc.status, err.Error())
+		}
+	}
+}
+
+func TestGeminiClientClassifiesErrors(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+
+	g := newStubGeminiClient(t, stubGeminiResponse(http.StatusBadRequest, h, `{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`))
+	if _, err := g.GenerateJSON(context.Background(), "prompt", nil); !IsPermanent(err) {
+		t.Fatalf("expected 400 to be permanent, got %v", err)
+	}
+
+	g = newStubGeminiClient(t, stubGeminiResponse(http.StatusServiceUnavailable, h, `{"error":{"code":503,"message":"overloaded","status":"UNAVAILABLE"}}`))
+	if _, err := g.GenerateJSON(context.Background(), "prompt", nil); err == nil || IsPermanent(err) {
+		t.Fatalf("expected 503 to be retryable, got %v", err)
+	}
+
+	g = newStubGeminiClient(t, stubGeminiResponse(http.StatusOK, h, `{"promptFeedback":{"blockReason":"SAFETY"}}`))
+	if _, err := g.GenerateJSON(context.Background(), "prompt", nil); !IsPermanent(err) {
+		t.Fatalf("expected blocked prompt to be permanent, got %v", err)
+	}
+}
@@ -1,9 +1,14 @@
 package llmclient
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
+
+	genai "google.golang.org/genai"
 )
 
 func TestParseGroqRateLimitHeaders_GroqFormat(t *testing.T) {
@@ -52,3 +57,106 @@ func TestHeaderRateLimitControlAdapter_NextWait(t *testing.T) {
 		t.Fatalf("no wait expected: got=%s", got)
 	}
 }
+
+type stubRoundTripper func(req *http.Request) (*http.Response, error)
+
+func (f stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func stubGeminiResponse(status int, header http.Header, body string) stubRoundTripper {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	}
+}
+
+func newStubGeminiClient(t *testing.T, rt http.RoundTripper) *GeminiClient {
+	t.Helper()
+	cfg := &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: "https://gemini.test/"},
+	}
+	g, err := newGeminiClient(context.Background(), cfg, "gemini-2.5-flash", 0, rt)
+	if err != nil {
+		t.Fatalf("newGeminiClient: %v", err)
+	}
+	return g
+}
+
+func TestGeminiClient_SurfacesRateLimitHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("x-ratelimit-limit-requests", "15")
+	h.Set("x-ratelimit-remaining-requests", "14")
+	h.Set("x-ratelimit-remaining-tokens", "900000")
+	h.Set("x-ratelimit-reset-requests", "4s")
+	body := `{"candidates":[{"content":{"parts":[{"text":"{\"ok\":true}"}]}}]}`
+	g := newStubGeminiClient(t, stubGeminiResponse(http.StatusOK, h, body))
+
+	var aware RateLimitHeaderAwareClient = g
+	var seen []RateLimitHeaders
+	aware.SetRateLimitHeaderHandler(func(headers RateLimitHeaders) { seen = append(seen, headers) })
+	if _, ok := aware.LastRateLimitHeaders(); ok {
+		t.Fatalf("expected no headers before the first call")
+	}
+
+	raw, err := g.GenerateJSON(context.Background(), "prompt", map[string]any{"x": 1})
+	if err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if string(raw) != `{"ok":true}` {
+		t.Fatalf("unexpected output: %s", raw)
+	}
+	got, ok := aware.LastRateLimitHeaders()
+	if !ok {
+		t.Fatalf("expected headers to be recorded")
+	}
+	if got.LimitRequests != 15 || got.RemainingRequests != 14 || got.RemainingTokens != 900000 {
+		t.Fatalf("unexpected headers: %+v", got)
+	}
+	if got.ResetRequests != 4*time.Second {
+		t.Fatalf("reset requests: got=%s", got.ResetRequests)
+	}
+	if len(seen) != 1 {
+		t.Fatalf("expected handler to be called once, got %d", len(seen))
+	}
+}
+
+func TestGeminiClient_RetryInfoOn429(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	body := `{"error":{"code":429,"status":"RESOURCE_EXHAUSTED","details":[` +
+		`{"@type":"type.googleapis.com/google.rpc.QuotaFailure"},` +
+		`{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"27.5s"}]}}`
+	g := newStubGeminiClient(t, stubGeminiResponse(http.StatusTooManyRequests, h, body))
+
+	_, err := g.GenerateJSON(context.Background(), "prompt", nil)
+	if err == nil {
+		t.Fatalf("expected 429 error")
+	}
+	if !strings.Contains(err.Error(), "RESOURCE_EXHAUSTED") {
+		t.Fatalf("expected body to remain readable for the error, got %v", err)
+	}
+	got, ok := g.LastRateLimitHeaders()
+	if !ok || got.RetryAfterSeconds != 28 {
+		t.Fatalf("expected retry-after 28s from RetryInfo, got ok=%v %+v", ok, got)
+	}
+}
+
+func TestParseGeminiRateLimitHeaders_RetryAfterHeaderWins(t *testing.T) {
+	h := http.Header{}
+	h.Set("retry-after", "5")
+	body := []byte(`{"error":{"details":[{"@type":"type.googleapis.com/google.rpc.RetryInfo","retryDelay":"60s"}]}}`)
+	got, ok := parseGeminiRateLimitHeaders(h, body)
+	if !ok || got.RetryAfterSeconds != 5 {
+		t.Fatalf("expected header retry-after to win, got ok=%v %+v", ok, got)
+	}
+	if _, ok := parseGeminiRateLimitHeaders(http.Header{}, nil); ok {
+		t.Fatalf("expected no signals from empty response")
+	}
+}
@@ -0,0 +1,52 @@
+package llmclient
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string // JSON compared structurally; "" expects ErrInvalidJSON
+	}{
+		{"valid unchanged", `{"a": 1}`, `{"a": 1}`},
+		{"fenced", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"fenced without language", "```\n[1, 2]\n```", `[1, 2]`},
+		{"trailing commas", `{"a": [1, 2,], "b": {"c": true,},}`, `{"a": [1, 2], "b": {"c": true}}`},
+		{"prose wrapped", "Sure! Here is the result:\n{\"a\": \"x\"}\nLet me know if you need more.", `{"a": "x"}`},
+		{"prose and fence", "Here you go:\n```json\n{\"a\": [1,],}\n```\nDone.", `{"a": [1]}`},
+		{"smart quote delimiters", `{“name”: “gateway”, “tags”: [“api”]}`, `{"name": "gateway", "tags": ["api"]}`},
+		{"smart quotes inside strings kept", `{"quote": "he said “hi”"}`, `{"quote": "he said “hi”"}`},
+		{"ascii quote inside smart string", `{“q”: “say "hi"”}`, `{"q": "say \"hi\""}`},
+		{"comma inside string kept", `{"a": "x,}",}`, `{"a": "x,}"}`},
+		{"braces after object dropped", `{"a": 1} and {"b": 2}`, `{"a": 1}`},
+		{"no json", `I could not produce an answer.`, ""},
+		{"truncated", "```json\n{\"a\": [1, 2\n```", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RepairJSON([]byte(tt.raw))
+			if tt.want == "" {
+				if !errors.Is(err, ErrInvalidJSON) {
+					t.Fatalf("RepairJSON() = %s, %v; want ErrInvalidJSON", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RepairJSON() error = %v", err)
+			}
+			var gotV, wantV any
+			if err := json.Unmarshal(got, &gotV); err != nil {
+				t.Fatalf("result %s is not JSON: %v", got, err)
+			}
+			_ = json.Unmarshal([]byte(tt.want), &wantV)
+			if !reflect.DeepEqual(gotV, wantV) {
+				t.Fatalf("RepairJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package llmclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// sseServer streams deltas as Groq server-sent events. With hang set it stops
+// after the first delta and waits for the client to go away, reporting that
+// on aborted.
+func sseServer(t *testing.T, deltas []string, hang bool, aborted chan<- struct{}) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for i, d := range deltas {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", d)
+			flusher.Flush()
+			if hang && i == 0 {
+				select {
+				case <-r.Context().Done():
+					aborted <- struct{}{}
+				case <-time.After(5 * time.Second):
+				}
+				return
+			}
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGroqClientStreamsDeltas(t *testing.T) {
+	srv := sseServer(t, []string{`{"ok":`, `true}`}, false, nil)
+	g, _ := NewGroqClient("key", "llama-3.1-8b-instant", 0, HTTPClientConfig{})
+	g.baseURL = srv.URL
+
+	var chunks []string
+	raw, err := g.GenerateJSONStream(context.Background(), "p", nil, func(c string) { chunks = append(chunks, c) })
+	if err != nil || string(raw) != `{"ok":true}` || len(chunks) != 2 {
+		t.Fatalf("GenerateJSONStream() = %s, %v; chunks %q", raw, err, chunks)
+	}
+}
+
+func TestGroqClientStreamCancellation(t *testing.T) {
+	aborted := make(chan struct{}, 1)
+	srv := sseServer(t, []string{`{"ok":`, `true}`}, true, aborted)
+	g, _ := NewGroqClient("key", "llama-3.1-8b-instant", 0, HTTPClientConfig{})
+	g.baseURL = srv.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chunks := 0
+	start := time.Now()
+	_, err := g.GenerateJSONStream(ctx, "p", nil, func(string) {
+		chunks++
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GenerateJSONStream() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("cancelled stream returned after %v", elapsed)
+	}
+	if chunks != 1 {
+		t.Fatalf("onChunk called %d times, want no chunk after cancellation", chunks)
+	}
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatalf("upstream request was not aborted")
+	}
+}
@@ -6,26 +6,60 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// PromptDir is the directory below PromptSaver.Dir holding prompt logs.
+const PromptDir = "prompt"
+
+const (
+	entryPrefix    = "==== "
+	entrySuffix    = " ===="
+	optionsMarker  = "[OPTIONS] "
+	inputMarker    = "[INPUT JSON]\n"
+	responseMarker = "[RESPONSE]\n"
+	errorPrefix    = "ERROR: "
 )
 
 // PromptSaver implements PromptHook to persist prompts & raw responses to disk.
-type PromptSaver struct{ Dir string }
+// With RunID set, logs go to artifacts/prompt/<run_id>/ so runs sharing an
+// output directory keep separate logs.
+type PromptSaver struct {
+	Dir   string
+	RunID string
+}
+
+func (p *PromptSaver) logDir() string {
+	if p.RunID != "" {
+		return filepath.Join(p.Dir, PromptDir, p.RunID)
+	}
+	return filepath.Join(p.Dir, PromptDir)
+}
 
-// Before writes prompt and input JSON to artifacts/prompt/<worker>.txt
+// Before writes prompt and input JSON to artifacts/prompt/<worker>.txt,
+// preceded by the call's GenerationOptions when any are set.
 func (p *PromptSaver) Before(ctx context.Context, worker, prompt string, input any) {
 	if worker == "" {
 		worker = "unknown"
 	}
-	_ = os.MkdirAll(filepath.Join(p.Dir, "prompt"), 0o755)
-	path := filepath.Join(p.Dir, "prompt", worker+".txt")
+	_ = os.MkdirAll(p.logDir(), 0o755)
+	path := filepath.Join(p.logDir(), worker+".txt")
 
 	var buf bytes.Buffer
-	buf.WriteString("==== ")
+	buf.WriteString(entryPrefix)
 	buf.WriteString(time.Now().Format(time.RFC3339))
-	buf.WriteString(" ====\n")
+	buf.WriteString(entrySuffix + "\n")
+	if opts := llmclient.GenerationOptionsFrom(ctx); !opts.IsZero() {
+		ob, _ := json.Marshal(opts)
+		buf.WriteString(optionsMarker)
+		buf.Write(ob)
+		buf.WriteString("\n")
+	}
 	buf.WriteString(prompt)
-	buf.WriteString("\n\n[INPUT JSON]\n")
+	buf.WriteString("\n\n" + inputMarker)
 	jb, _ := json.MarshalIndent(input, "", "  ")
 	buf.Write(jb)
 	buf.WriteString("\n\n")
@@ -42,13 +76,13 @@ func (p *PromptSaver) After(ctx context.Context, worker string, raw json.RawMess
 	if worker == "" {
 		worker = "unknown"
 	}
-	_ = os.MkdirAll(filepath.Join(p.Dir, "prompt"), 0o755)
-	path := filepath.Join(p.Dir, "prompt", worker+".txt")
+	_ = os.MkdirAll(p.logDir(), 0o755)
+	path := filepath.Join(p.logDir(), worker+".txt")
 
 	var buf bytes.Buffer
-	buf.WriteString("[RESPONSE]\n")
+	buf.WriteString(responseMarker)
 	if err != nil {
-		buf.WriteString("ERROR: " + err.Error() + "\n\n")
+		buf.WriteString(errorPrefix + err.Error() + "\n\n")
 	} else {
 		buf.Write(raw)
 		buf.WriteString("\n\n")
@@ -59,5 +93,83 @@ func (p *PromptSaver) After(ctx context.Context, worker string, raw json.RawMess
 		_ = f.Close()
 	}
 
-	_ = os.WriteFile(filepath.Join(p.Dir, worker+".raw.json"), raw, 0o644)
+	rawDir := p.Dir
+	if p.RunID != "" {
+		rawDir = p.logDir()
+	}
+	_ = os.WriteFile(filepath.Join(rawDir, worker+".raw.json"), raw, 0o644)
+}
+
+// PromptExchange is one request/response pair read back from a prompt log.
+type PromptExchange struct {
+	Time     string          `json:"time"`
+	Prompt   string          `json:"prompt"`
+	Options  json.RawMessage `json:"options,omitempty"`
+	Input    json.RawMessage `json:"input,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// ParsePromptLog splits a <worker>.txt log written by PromptSaver into its
+// exchanges, oldest first. A request without a response yet is returned with
+// empty Response and Error.
+func ParsePromptLog(log []byte) []PromptExchange {
+	var out []PromptExchange
+	text := string(log)
+	for text != "" {
+		if !strings.HasPrefix(text, entryPrefix) {
+			// Skip anything before the first header.
+			i := strings.Index(text, "\n"+entryPrefix)
+			if i < 0 {
+				break
+			}
+			text = text[i+1:]
+			continue
+		}
+		header, rest, _ := strings.Cut(text, "\n")
+		next := strings.Index(rest, "\n"+entryPrefix)
+		body := rest
+		if next >= 0 {
+			body, text = rest[:next+1], rest[next+1:]
+		} else {
+			text = ""
+		}
+		out = append(out, parseExchange(header, body))
+	}
+	return out
+}
+
+func parseExchange(header, body string) PromptExchange {
+	ex := PromptExchange{Time: strings.TrimSuffix(strings.TrimPrefix(header, entryPrefix), entrySuffix)}
+	body, resp, hasResp := strings.Cut(body, responseMarker)
+	if rest, ok := strings.CutPrefix(body, optionsMarker); ok {
+		opts, after, _ := strings.Cut(rest, "\n")
+		ex.Options = rawOrString(opts)
+		body = after
+	}
+	prompt, input, _ := strings.Cut(body, "\n\n"+inputMarker)
+	ex.Prompt = prompt
+	ex.Input = rawOrString(input)
+	if hasResp {
+		resp = strings.TrimSpace(resp)
+		if msg, ok := strings.CutPrefix(resp, errorPrefix); ok {
+			ex.Error = msg
+		} else {
+			ex.Response = rawOrString(resp)
+		}
+	}
+	return ex
+}
+
+// rawOrString keeps valid JSON as is and quotes anything else.
+func rawOrString(s string) json.RawMessage {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	b, _ := json.Marshal(s)
+	return b
 }
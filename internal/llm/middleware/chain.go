@@ -1,6 +1,10 @@
 package llm
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	llmclient "insightify/internal/llm/client"
 )
 
@@ -8,17 +12,156 @@ import (
 // (rate limiting, retries, logging, hooks, etc.).
 type Middleware func(llmclient.LLMClient) llmclient.LLMClient
 
+// Names reported by the middlewares' Describe.
+const (
+	MiddlewareSelectModel      = "select_model"
+	MiddlewareDedupe           = "dedupe"
+	MiddlewareRateLimitSignals = "rate_limit_signals"
+	MiddlewareRetry            = "retry"
+	MiddlewareRepairJSON       = "repair_json"
+	MiddlewareRunUsage         = "run_usage"
+	MiddlewareUsageLedger      = "usage_ledger"
+	MiddlewareCircuitBreaker   = "circuit_breaker"
+	MiddlewareRateLimit        = "rate_limit"
+	MiddlewareMultiLimit       = "multi_limit"
+	MiddlewareTokenDayLimit    = "token_day_limit"
+	MiddlewareSharedMultiLimit = "shared_multi_limit"
+	MiddlewareLogging          = "logging"
+	MiddlewareHooks            = "hooks"
+	MiddlewareRedactInput      = "redact_input"
+)
+
+// MiddlewareInfo describes one layer of a composed chain.
+type MiddlewareInfo struct {
+	Name   string            `json:"name"`
+	Config map[string]string `json:"config,omitempty"`
+}
+
+func (i MiddlewareInfo) String() string {
+	if len(i.Config) == 0 {
+		return i.Name
+	}
+	keys := make([]string, 0, len(i.Config))
+	for k := range i.Config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+i.Config[k])
+	}
+	return i.Name + "(" + strings.Join(parts, ",") + ")"
+}
+
+// Describer is implemented by the clients middlewares return.
+type Describer interface {
+	Describe() MiddlewareInfo
+}
+
+// ChainDescriber is implemented by clients built with Wrap.
+type ChainDescriber interface {
+	ChainDescription() []MiddlewareInfo
+}
+
 // Wrap applies middlewares in left-to-right order.
 // Example: Wrap(inner, A, B) => A(B(inner))
+// The result implements ChainDescriber, listing the layers outermost first.
 func Wrap(inner llmclient.LLMClient, mws ...Middleware) llmclient.LLMClient {
 	out := inner
+	chain := make([]MiddlewareInfo, len(mws))
 	for i := len(mws) - 1; i >= 0; i-- {
 		out = mws[i](out)
+		if d, ok := out.(Describer); ok {
+			chain[i] = d.Describe()
+		} else {
+			chain[i] = MiddlewareInfo{Name: fmt.Sprintf("%T", out)}
+		}
 	}
-	return out
+	return &described{LLMClient: out, chain: chain}
 }
 
 // Chain is an alias for Wrap for convenience.
 func Chain(inner llmclient.LLMClient, mws ...Middleware) llmclient.LLMClient {
 	return Wrap(inner, mws...)
 }
+
+type described struct {
+	llmclient.LLMClient
+	chain []MiddlewareInfo
+}
+
+func (d *described) ChainDescription() []MiddlewareInfo {
+	return append([]MiddlewareInfo(nil), d.chain...)
+}
+
+// DescribeChain returns cli's chain, or nil when cli was not built with Wrap.
+func DescribeChain(cli llmclient.LLMClient) []MiddlewareInfo {
+	if d, ok := cli.(ChainDescriber); ok {
+		return d.ChainDescription()
+	}
+	return nil
+}
+
+// FormatChain renders chain outermost first, e.g. "retry(max=3) -> logging".
+func FormatChain(chain []MiddlewareInfo) string {
+	parts := make([]string, len(chain))
+	for i, info := range chain {
+		parts[i] = info.String()
+	}
+	return strings.Join(parts, " -> ")
+}
+
+// Chain issue severities.
+const (
+	SeverityWarn  = "warn"
+	SeverityError = "error"
+)
+
+// ChainIssue is a known-bad ordering found by Validate.
+type ChainIssue struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// fixedLimiters hold their own token buckets. A Retry outside them makes every
+// attempt take tokens again, so a failing call drains the budget of healthy
+// ones. SharedMultiLimit is left out: it mirrors the provider's quota, which
+// each attempt does spend.
+var fixedLimiters = map[string]bool{
+	MiddlewareRateLimit:     true,
+	MiddlewareMultiLimit:    true,
+	MiddlewareTokenDayLimit: true,
+}
+
+// selectedClientUsers read the model client SelectModel puts in the context
+// and silently pass everything through without it.
+var selectedClientUsers = map[string]bool{
+	MiddlewareSharedMultiLimit: true,
+	MiddlewareRateLimitSignals: true,
+}
+
+// Validate reports known-bad orderings in chain (outermost first).
+func Validate(chain []MiddlewareInfo) []ChainIssue {
+	var issues []ChainIssue
+	retry, selected := -1, -1
+	for i, info := range chain {
+		switch {
+		case info.Name == MiddlewareRetry && retry < 0:
+			retry = i
+		case info.Name == MiddlewareSelectModel && selected < 0:
+			selected = i
+		case fixedLimiters[info.Name] && retry >= 0:
+			issues = append(issues, ChainIssue{
+				Severity: SeverityWarn,
+				Message:  fmt.Sprintf("%s is inside %s: each retry re-acquires rate limit tokens", info.Name, MiddlewareRetry),
+			})
+		}
+		if selectedClientUsers[info.Name] && selected < 0 {
+			issues = append(issues, ChainIssue{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%s needs %s outside it to find the selected client", info.Name, MiddlewareSelectModel),
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,265 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// ----------------------------------------------------------------------------
+// CircuitBreaker – fail fast once a provider model keeps failing
+// ----------------------------------------------------------------------------
+
+// CircuitState is the state of one provider/model circuit.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// ErrCircuitOpen matches (via errors.Is) every *CircuitOpenError.
+var ErrCircuitOpen = errors.New("llm circuit open")
+
+// CircuitOpenError is returned without calling the provider while its circuit is open.
+type CircuitOpenError struct {
+	Key string
+	// RetryIn is the time until the circuit half-opens; zero while a
+	// half-open probe is already in flight.
+	RetryIn time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryIn <= 0 {
+		return fmt.Sprintf("llm circuit open for %s: half-open probe in flight", e.Key)
+	}
+	return fmt.Sprintf("llm circuit open for %s: half-open in %s", e.Key, e.RetryIn.Round(time.Millisecond))
+}
+
+func (e *CircuitOpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+// CircuitSnapshot reports one circuit for debugging.
+type CircuitSnapshot struct {
+	Key       string       `json:"key"`
+	State     CircuitState `json:"state"`
+	Failures  int          `json:"failures"`
+	OpenUntil time.Time    `json:"open_until,omitempty"`
+}
+
+// CircuitBreaker tracks consecutive transient failures per provider/model,
+// keyed like LimiterRegistry. After threshold failures the circuit opens for
+// cooldown; the next call after that is a single half-open probe whose
+// outcome closes or re-opens it. PermanentError and context cancellation
+// never count as failures.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state     CircuitState
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker creates a breaker; threshold < 1 is treated as 1.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		circuits:  map[string]*circuit{},
+	}
+}
+
+var defaultCircuitBreaker = NewCircuitBreaker(5, 30*time.Second)
+
+// DefaultCircuitBreaker returns the process-wide breaker. Like provider
+// quotas, provider health is shared by every project in a process.
+func DefaultCircuitBreaker() *CircuitBreaker { return defaultCircuitBreaker }
+
+// WithCircuitBreaker wraps clients with a new breaker of its own.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Middleware {
+	return NewCircuitBreaker(threshold, cooldown).Middleware()
+}
+
+// Middleware returns a middleware enforcing b. A nil breaker passes through.
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next llmclient.LLMClient) llmclient.LLMClient {
+		if b == nil {
+			return next
+		}
+		return &circuitBroken{next: next, b: b}
+	}
+}
+
+// IsOpen reports whether calls for key would currently fail fast. Model
+// selection uses it to route around failing models.
+func (b *CircuitBreaker) IsOpen(key LimiterKey) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key.String()]
+	if !ok {
+		return false
+	}
+	switch c.state {
+	case CircuitOpen:
+		return b.now().Before(c.openUntil)
+	case CircuitHalfOpen:
+		return c.probing
+	}
+	return false
+}
+
+// Snapshot lists every circuit that has seen a failure, in key order.
+func (b *CircuitBreaker) Snapshot() []CircuitSnapshot {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]CircuitSnapshot, 0, len(b.circuits))
+	for k, c := range b.circuits {
+		snap := CircuitSnapshot{Key: k, State: c.state, Failures: c.failures}
+		if c.state == CircuitOpen {
+			snap.OpenUntil = c.openUntil
+		}
+		out = append(out, snap)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// allow admits a call or returns a *CircuitOpenError. The boolean reports
+// whether the admitted call is the half-open probe.
+func (b *CircuitBreaker) allow(key string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok {
+		return false, nil
+	}
+	switch c.state {
+	case CircuitOpen:
+		if wait := c.openUntil.Sub(b.now()); wait > 0 {
+			return false, &CircuitOpenError{Key: key, RetryIn: wait}
+		}
+		c.state = CircuitHalfOpen
+		c.probing = true
+		return true, nil
+	case CircuitHalfOpen:
+		if c.probing {
+			return false, &CircuitOpenError{Key: key}
+		}
+		c.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+func (b *CircuitBreaker) record(ctx context.Context, key string, probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if err == nil {
+		if ok {
+			delete(b.circuits, key)
+		}
+		return
+	}
+	if !countsAsFailure(ctx, err) {
+		if ok && probe {
+			// Inconclusive probe: let the next caller try.
+			c.probing = false
+		}
+		return
+	}
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		b.circuits[key] = c
+	}
+	c.failures++
+	if probe || c.failures >= b.threshold {
+		c.state = CircuitOpen
+		c.openUntil = b.now().Add(b.cooldown)
+		c.probing = false
+	}
+}
+
+func countsAsFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return !llmclient.IsPermanent(err) && !errors.Is(err, ErrCircuitOpen)
+}
+
+type circuitBroken struct {
+	next llmclient.LLMClient
+	b    *CircuitBreaker
+}
+
+func (m *circuitBroken) Name() string                { return m.next.Name() }
+func (m *circuitBroken) Close() error                { return m.next.Close() }
+func (m *circuitBroken) CountTokens(text string) int { return m.next.CountTokens(text) }
+func (m *circuitBroken) TokenCapacity() int          { return m.next.TokenCapacity() }
+
+func (m *circuitBroken) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareCircuitBreaker, Config: map[string]string{
+		"threshold": strconv.Itoa(m.b.threshold),
+		"cooldown":  m.b.cooldown.String(),
+	}}
+}
+
+func (m *circuitBroken) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	key := m.key(ctx)
+	probe, err := m.b.allow(key)
+	if err != nil {
+		return nil, err
+	}
+	out, err := m.next.GenerateJSON(ctx, prompt, input)
+	m.b.record(ctx, key, probe, err)
+	return out, err
+}
+
+func (m *circuitBroken) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	key := m.key(ctx)
+	probe, err := m.b.allow(key)
+	if err != nil {
+		return nil, err
+	}
+	out, err := m.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+	m.b.record(ctx, key, probe, err)
+	return out, err
+}
+
+// key identifies the selected client's circuit, falling back to client names
+// for clients that carry no LimiterKey.
+func (m *circuitBroken) key(ctx context.Context) string {
+	selected, ok := SelectedClientFrom(ctx)
+	if !ok {
+		return m.next.Name()
+	}
+	if keyed, ok := selected.(LimiterKeyed); ok {
+		return keyed.LimiterKey().String()
+	}
+	return selected.Name()
+}
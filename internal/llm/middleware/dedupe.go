@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// Dedupe coalesces concurrent identical GenerateJSON calls into one upstream
+// request whose result every caller gets. Calls are identical when prompt,
+// input, the selected model and the generation options match. Streaming calls
+// are never coalesced, since each has its own onChunk.
+//
+// Place it after SelectModel and outside Retry and RecordRunUsage, so the
+// shared call is retried and priced once (to the phase that issued it first).
+func Dedupe() Middleware {
+	return func(next llmclient.LLMClient) llmclient.LLMClient {
+		return &deduping{next: next}
+	}
+}
+
+type deduping struct {
+	next  llmclient.LLMClient
+	group singleflight.Group
+}
+
+func (d *deduping) Name() string { return d.next.Name() }
+func (d *deduping) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareDedupe}
+}
+func (d *deduping) Close() error { return d.next.Close() }
+func (d *deduping) CountTokens(text string) int {
+	return d.next.CountTokens(text)
+}
+func (d *deduping) TokenCapacity() int { return d.next.TokenCapacity() }
+
+func (d *deduping) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	key, ok := dedupeKey(ctx, prompt, input)
+	if !ok {
+		return d.next.GenerateJSON(ctx, prompt, input)
+	}
+	ch := d.group.DoChan(key, func() (any, error) {
+		return d.next.GenerateJSON(ctx, prompt, input)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		raw, _ := res.Val.(json.RawMessage)
+		if !res.Shared {
+			return raw, res.Err
+		}
+		// The first caller's context ended the shared call; ours is alive.
+		if res.Err != nil && ctx.Err() == nil && (errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded)) {
+			return d.next.GenerateJSON(ctx, prompt, input)
+		}
+		return bytes.Clone(raw), res.Err
+	}
+}
+
+func (d *deduping) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	return d.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+}
+
+// dedupeKey hashes what makes two calls interchangeable; ok is false when
+// input cannot be encoded.
+func dedupeKey(ctx context.Context, prompt string, input any) (string, bool) {
+	model := ""
+	if selected, ok := SelectedClientFrom(ctx); ok {
+		model = selected.Name()
+	}
+	b, err := json.Marshal(struct {
+		Model      string
+		Generation llmclient.GenerationOptions
+		Prompt     string
+		Input      any
+	}{model, llmclient.GenerationOptionsFrom(ctx), prompt, input})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), true
+}
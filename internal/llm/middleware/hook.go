@@ -15,12 +15,30 @@ type PromptHook interface {
 
 type ctxKeyHook struct{}
 type ctxKeyWorker struct{}
+type ctxKeyPhase struct{}
+type ctxKeyStreamObserver struct{}
 
 // WithWorker attaches a worker name to the context.
 func WithWorker(ctx context.Context, worker string) context.Context {
 	return context.WithValue(ctx, ctxKeyWorker{}, worker)
 }
 
+// WithPhase attaches the runner phase (worker spec key) to the context.
+// Unlike WithWorker, nested calls do not relabel it.
+func WithPhase(ctx context.Context, phase string) context.Context {
+	return context.WithValue(ctx, ctxKeyPhase{}, phase)
+}
+
+// PhaseFrom returns the phase stored in the context, or "".
+func PhaseFrom(ctx context.Context) string {
+	if v := ctx.Value(ctxKeyPhase{}); v != nil {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
 // WithPromptHook attaches a PromptHook to the context. Middlewares that call
 // HookFrom(ctx) can use this to invoke Before/After around requests.
 func WithPromptHook(ctx context.Context, hook PromptHook) context.Context {
@@ -37,6 +55,22 @@ func HookFrom(ctx context.Context) PromptHook {
 	return nil
 }
 
+// WithStreamObserver attaches a callback that WithHooks invokes for every
+// streamed chunk, in addition to the caller's own onChunk.
+func WithStreamObserver(ctx context.Context, observe func(chunk string)) context.Context {
+	return context.WithValue(ctx, ctxKeyStreamObserver{}, observe)
+}
+
+// StreamObserverFrom returns the stream observer stored in the context.
+func StreamObserverFrom(ctx context.Context) func(chunk string) {
+	if v := ctx.Value(ctxKeyStreamObserver{}); v != nil {
+		if f, ok := v.(func(chunk string)); ok {
+			return f
+		}
+	}
+	return nil
+}
+
 // WorkerFrom returns the worker string stored in the context.
 func WorkerFrom(ctx context.Context) string {
 	if v := ctx.Value(ctxKeyWorker{}); v != nil {
@@ -47,17 +81,30 @@ func WorkerFrom(ctx context.Context) string {
 	return "unknown"
 }
 
-// WithHooks calls HookFrom(ctx).Before/After around GenerateJSON.
-// If no hook is present in the context, it is a no-op.
-func WithHooks() Middleware {
+// WithHooks calls HookFrom(ctx).Before/After around GenerateJSON and feeds
+// streamed chunks to StreamObserverFrom(ctx).
+// If neither is present in the context, it is a no-op.
+//
+// Hooks persist what they see, so the prompt, input and raw response are
+// handed to them with secrets replaced by the detectors (DefaultSecretDetectors
+// when none are given); the model still gets the original input. Redactions
+// are counted on the run's RunUsage.
+func WithHooks(detectors ...SecretDetector) Middleware {
+	redact := NewRedactor(detectors...)
 	return func(next llmclient.LLMClient) llmclient.LLMClient {
-		return &hooked{next: next}
+		return &hooked{next: next, redact: redact}
 	}
 }
 
-type hooked struct{ next llmclient.LLMClient }
+type hooked struct {
+	next   llmclient.LLMClient
+	redact *Redactor
+}
 
 func (h *hooked) Name() string { return h.next.Name() }
+func (h *hooked) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareHooks}
+}
 func (h *hooked) Close() error { return h.next.Close() }
 func (h *hooked) CountTokens(text string) int {
 	return h.next.CountTokens(text)
@@ -65,23 +112,39 @@ func (h *hooked) CountTokens(text string) int {
 func (h *hooked) TokenCapacity() int { return h.next.TokenCapacity() }
 
 func (h *hooked) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
-	if hook := HookFrom(ctx); hook != nil {
-		hook.Before(ctx, WorkerFrom(ctx), prompt, input)
-	}
+	h.before(ctx, prompt, input)
 	raw, err := h.next.GenerateJSON(ctx, prompt, input)
-	if hook := HookFrom(ctx); hook != nil {
-		hook.After(ctx, WorkerFrom(ctx), raw, err)
-	}
+	h.after(ctx, raw, err)
 	return raw, err
 }
 
 func (h *hooked) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	h.before(ctx, prompt, input)
+	if observe := StreamObserverFrom(ctx); observe != nil {
+		next := onChunk
+		onChunk = func(chunk string) {
+			observe(chunk)
+			if next != nil {
+				next(chunk)
+			}
+		}
+	}
+	raw, err := h.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+	h.after(ctx, raw, err)
+	return raw, err
+}
+
+func (h *hooked) before(ctx context.Context, prompt string, input any) {
 	if hook := HookFrom(ctx); hook != nil {
+		prompt, input = redactCall(ctx, h.redact, prompt, input)
 		hook.Before(ctx, WorkerFrom(ctx), prompt, input)
 	}
-	raw, err := h.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+}
+
+func (h *hooked) after(ctx context.Context, raw json.RawMessage, err error) {
 	if hook := HookFrom(ctx); hook != nil {
+		raw, counts := h.redact.RedactJSON(raw)
+		countRedactions(ctx, counts)
 		hook.After(ctx, WorkerFrom(ctx), raw, err)
 	}
-	return raw, err
 }
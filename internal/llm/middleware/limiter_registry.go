@@ -0,0 +1,368 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// ----------------------------------------------------------------------------
+// LimiterRegistry – provider quota buckets shared across clients
+// ----------------------------------------------------------------------------
+
+// LimiterKey identifies one provider quota bucket.
+type LimiterKey struct {
+	Provider string
+	Model    string
+	Tier     string
+}
+
+func (k LimiterKey) String() string {
+	return strings.ToLower(strings.TrimSpace(k.Provider)) + "::" + strings.TrimSpace(k.Model) + "::" + strings.ToLower(strings.TrimSpace(k.Tier))
+}
+
+// LimiterKeyed is implemented by clients that know which quota bucket they draw from.
+type LimiterKeyed interface {
+	LimiterKey() LimiterKey
+}
+
+// LimiterSnapshot reports a bucket's configuration and the tokens currently
+// left in each of its limiters.
+type LimiterSnapshot struct {
+	Key       string                    `json:"key"`
+	Config    llmclient.RateLimitConfig `json:"config"`
+	Active    bool                      `json:"active"`
+	Remaining map[string]int            `json:"remaining,omitempty"`
+}
+
+// LimiterRegistry holds rate limiters keyed by provider+model+tier so every
+// client of the same model shares one budget. Limiters are created on first use.
+// It also aggregates the rate-limit headers reported by all clients of a
+// provider; see ObserveRateLimitHeaders.
+type LimiterRegistry struct {
+	mu      sync.Mutex
+	configs map[string]llmclient.RateLimitConfig
+	sets    map[string]*limiterSet
+	signals map[string]providerSignal
+}
+
+// providerSignal is the latest rate-limit headers seen for a provider.
+type providerSignal struct {
+	headers    llmclient.RateLimitHeaders
+	observedAt time.Time
+}
+
+// NewLimiterRegistry creates an empty registry.
+func NewLimiterRegistry() *LimiterRegistry {
+	return &LimiterRegistry{
+		configs: map[string]llmclient.RateLimitConfig{},
+		sets:    map[string]*limiterSet{},
+		signals: map[string]providerSignal{},
+	}
+}
+
+var defaultLimiterRegistry = NewLimiterRegistry()
+
+// DefaultLimiterRegistry returns the process-wide registry. Provider quotas
+// are per API key, so all projects in a process share it.
+func DefaultLimiterRegistry() *LimiterRegistry { return defaultLimiterRegistry }
+
+// Seed records the limits for key. Seeding a key whose limiters already
+// exist is a no-op so live budgets are never reset.
+func (r *LimiterRegistry) Seed(key LimiterKey, cfg llmclient.RateLimitConfig) {
+	if r == nil {
+		return
+	}
+	k := key.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sets[k]; ok {
+		return
+	}
+	r.configs[k] = cfg
+}
+
+// limiters returns the shared limiters for key, creating them on first use.
+func (r *LimiterRegistry) limiters(key LimiterKey) *limiterSet {
+	if r == nil {
+		return nil
+	}
+	k := key.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if set, ok := r.sets[k]; ok {
+		return set
+	}
+	cfg, ok := r.configs[k]
+	if !ok {
+		return nil
+	}
+	set := newLimiterSet(cfg)
+	r.sets[k] = set
+	return set
+}
+
+// Snapshot lists every seeded bucket in key order.
+func (r *LimiterRegistry) Snapshot() []LimiterSnapshot {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]LimiterSnapshot, 0, len(r.configs))
+	for k, cfg := range r.configs {
+		snap := LimiterSnapshot{Key: k, Config: cfg}
+		if set, ok := r.sets[k]; ok {
+			snap.Active = true
+			snap.Remaining = set.remaining()
+		}
+		out = append(out, snap)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// ObserveRateLimitHeaders records headers reported by a client of provider.
+// The latest report of any model wins, so a 429 on one model delays every
+// model of the provider until a newer report clears it.
+func (r *LimiterRegistry) ObserveRateLimitHeaders(provider string, headers llmclient.RateLimitHeaders) {
+	if r == nil {
+		return
+	}
+	k := strings.ToLower(strings.TrimSpace(provider))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signals[k] = providerSignal{headers: headers, observedAt: time.Now()}
+}
+
+// ProviderRateLimitHeaders returns the latest headers observed for provider
+// and when they were observed.
+func (r *LimiterRegistry) ProviderRateLimitHeaders(provider string) (llmclient.RateLimitHeaders, time.Time, bool) {
+	if r == nil {
+		return llmclient.RateLimitHeaders{}, time.Time{}, false
+	}
+	k := strings.ToLower(strings.TrimSpace(provider))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sig, ok := r.signals[k]
+	return sig.headers, sig.observedAt, ok
+}
+
+type limiterSet struct {
+	rpm, rpd, tpm, tpd, rps *rpsLimiter
+	tpr                     int
+}
+
+func newLimiterSet(cfg llmclient.RateLimitConfig) *limiterSet {
+	const defaultTokensPerRequest = 1000
+	s := &limiterSet{tpr: defaultTokensPerRequest}
+	if cfg.RPM > 0 {
+		s.rpm = newRPSLimiter(float64(cfg.RPM)/60.0, max1(cfg.RPM))
+	}
+	if cfg.RPD > 0 {
+		s.rpd = newRPSLimiter(float64(cfg.RPD)/86400.0, max1(cfg.RPD))
+	}
+	if cfg.TPM > 0 {
+		s.tpm = newRPSLimiter(float64(cfg.TPM)/60.0, max1(cfg.TPM))
+	}
+	if cfg.TPD > 0 {
+		s.tpd = newRPSLimiter(float64(cfg.TPD)/86400.0, max1(cfg.TPD))
+	}
+	if cfg.RPS > 0 {
+		s.rps = newRPSLimiter(cfg.RPS, cfg.Burst)
+	}
+	return s
+}
+
+func (s *limiterSet) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	// Credits skip the request limiters; take them before estimating.
+	var requests []*rpsLimiter
+	var wait time.Duration
+	for _, l := range []*rpsLimiter{s.rpm, s.rpd, s.rps} {
+		if l == nil || TakeCredit(ctx) {
+			continue
+		}
+		requests = append(requests, l)
+		wait = max(wait, l.estimate(1))
+	}
+	for _, l := range []*rpsLimiter{s.tpm, s.tpd} {
+		wait = max(wait, l.estimate(s.tpr))
+	}
+	return throttled(ctx, nil, ThrottleSourceLimiter, wait, func() error {
+		for _, l := range requests {
+			if err := l.Acquire(ctx); err != nil {
+				return err
+			}
+		}
+		for _, l := range []*rpsLimiter{s.tpm, s.tpd} {
+			if err := l.AcquireN(ctx, s.tpr); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *limiterSet) remaining() map[string]int {
+	out := map[string]int{}
+	for name, l := range map[string]*rpsLimiter{"rpm": s.rpm, "rpd": s.rpd, "tpm": s.tpm, "tpd": s.tpd, "rps": s.rps} {
+		if l != nil {
+			out[name] = len(l.tokens)
+		}
+	}
+	return out
+}
+
+// ----------------------------------------------------------------------------
+// WithLimiterKey – tag a client with its quota bucket
+// ----------------------------------------------------------------------------
+
+// WithLimiterKey tags a client with the bucket it draws from. Rate-limit
+// header awareness of the wrapped client is preserved.
+func WithLimiterKey(key LimiterKey) Middleware {
+	return WithLimiterKeyIn(nil, key)
+}
+
+// WithLimiterKeyIn is WithLimiterKey that also reports the client's
+// rate-limit headers to reg, aggregated per provider, so RespectRateLimitSignals
+// sees what the other models of the provider were told.
+func WithLimiterKeyIn(reg *LimiterRegistry, key LimiterKey) Middleware {
+	return func(next llmclient.LLMClient) llmclient.LLMClient {
+		c := &keyedClient{next: next, key: key, reg: reg}
+		if aware, ok := next.(llmclient.RateLimitHeaderAwareClient); ok && reg != nil {
+			aware.SetRateLimitHeaderHandler(c.onHeaders)
+		}
+		return c
+	}
+}
+
+// ProviderSignalSource is implemented by clients that share rate-limit
+// headers with the other clients of their provider.
+type ProviderSignalSource interface {
+	ProviderRateLimitHeaders() (llmclient.RateLimitHeaders, time.Time, bool)
+}
+
+type keyedClient struct {
+	next llmclient.LLMClient
+	key  LimiterKey
+	reg  *LimiterRegistry
+
+	mu      sync.RWMutex
+	handler llmclient.RateLimitHeaderHandler
+}
+
+func (c *keyedClient) LimiterKey() LimiterKey      { return c.key }
+func (c *keyedClient) Name() string                { return c.next.Name() }
+func (c *keyedClient) Close() error                { return c.next.Close() }
+func (c *keyedClient) CountTokens(text string) int { return c.next.CountTokens(text) }
+func (c *keyedClient) TokenCapacity() int          { return c.next.TokenCapacity() }
+
+func (c *keyedClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	return c.next.GenerateJSON(ctx, prompt, input)
+}
+
+func (c *keyedClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	return c.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+}
+
+func (c *keyedClient) SetRateLimitHeaderHandler(handler llmclient.RateLimitHeaderHandler) {
+	if c.reg == nil {
+		if aware, ok := c.next.(llmclient.RateLimitHeaderAwareClient); ok {
+			aware.SetRateLimitHeaderHandler(handler)
+		}
+		return
+	}
+	// The wrapped client already reports to onHeaders; chain handler there.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handler = handler
+}
+
+func (c *keyedClient) onHeaders(headers llmclient.RateLimitHeaders) {
+	c.reg.ObserveRateLimitHeaders(c.key.Provider, headers)
+	c.mu.RLock()
+	handler := c.handler
+	c.mu.RUnlock()
+	if handler != nil {
+		handler(headers)
+	}
+}
+
+// ProviderRateLimitHeaders returns the provider aggregate of reg; clients
+// tagged without a registry have none.
+func (c *keyedClient) ProviderRateLimitHeaders() (llmclient.RateLimitHeaders, time.Time, bool) {
+	if c.reg == nil {
+		return llmclient.RateLimitHeaders{}, time.Time{}, false
+	}
+	return c.reg.ProviderRateLimitHeaders(c.key.Provider)
+}
+
+func (c *keyedClient) LastRateLimitHeaders() (llmclient.RateLimitHeaders, bool) {
+	if aware, ok := c.next.(llmclient.RateLimitHeaderAwareClient); ok {
+		return aware.LastRateLimitHeaders()
+	}
+	return llmclient.RateLimitHeaders{}, false
+}
+
+// ----------------------------------------------------------------------------
+// SharedMultiLimit middleware
+// ----------------------------------------------------------------------------
+
+// SharedMultiLimit enforces RPM/RPD/TPM/TPD/RPS limits from reg for the
+// selected client in context, or for the wrapped client when none was
+// selected. Clients without a LimiterKey, or keys that were never seeded, pass
+// through unthrottled.
+func SharedMultiLimit(reg *LimiterRegistry) Middleware {
+	return func(next llmclient.LLMClient) llmclient.LLMClient {
+		return &sharedLimited{next: next, reg: reg}
+	}
+}
+
+type sharedLimited struct {
+	next llmclient.LLMClient
+	reg  *LimiterRegistry
+}
+
+func (m *sharedLimited) Name() string { return m.next.Name() }
+func (m *sharedLimited) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareSharedMultiLimit}
+}
+func (m *sharedLimited) Close() error { return m.next.Close() }
+func (m *sharedLimited) CountTokens(text string) int {
+	return m.next.CountTokens(text)
+}
+func (m *sharedLimited) TokenCapacity() int { return m.next.TokenCapacity() }
+
+func (m *sharedLimited) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
+	}
+	return m.next.GenerateJSON(ctx, prompt, input)
+}
+
+func (m *sharedLimited) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
+	}
+	return m.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+}
+
+func (m *sharedLimited) acquire(ctx context.Context) error {
+	selected, ok := SelectedClientFrom(ctx)
+	if !ok {
+		selected = m.next
+	}
+	keyed, ok := selected.(LimiterKeyed)
+	if !ok {
+		return nil
+	}
+	return m.reg.limiters(keyed.LimiterKey()).acquire(ctx)
+}
@@ -3,28 +3,32 @@ package llm
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
+	"time"
 
+	"insightify/internal/common/logctx"
 	llmclient "insightify/internal/llm/client"
 )
 
-// WithLogging logs request size and errors. Provide a custom logger or nil
-// to use log.Default().
-func WithLogging(logger *log.Logger) Middleware {
-	if logger == nil {
-		logger = log.Default()
-	}
+// WithLogging emits one slog record per call at level, and an error record
+// when the call fails. Records carry the context's log attributes (run_id, …)
+// plus worker, provider and model. A nil logger uses slog.Default().
+func WithLogging(logger *slog.Logger, level slog.Level) Middleware {
 	return func(next llmclient.LLMClient) llmclient.LLMClient {
-		return &logging{next: next, log: logger}
+		return &logging{next: next, log: logger, level: level}
 	}
 }
 
 type logging struct {
-	next llmclient.LLMClient
-	log  *log.Logger
+	next  llmclient.LLMClient
+	log   *slog.Logger
+	level slog.Level
 }
 
 func (l *logging) Name() string { return l.next.Name() }
+func (l *logging) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareLogging, Config: map[string]string{"level": l.level.String()}}
+}
 func (l *logging) Close() error { return l.next.Close() }
 func (l *logging) CountTokens(text string) int {
 	return l.next.CountTokens(text)
@@ -32,21 +36,48 @@ func (l *logging) CountTokens(text string) int {
 func (l *logging) TokenCapacity() int { return l.next.TokenCapacity() }
 
 func (l *logging) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
-	in, _ := json.MarshalIndent(input, "", "  ")
-	l.log.Printf("LLM request (%s): %d bytes", WorkerFrom(ctx), len(prompt)+len(in))
+	ctx = l.withCallAttrs(ctx)
+	in, _ := json.Marshal(input)
+	start := time.Now()
 	raw, err := l.next.GenerateJSON(ctx, prompt, input)
-	if err != nil {
-		l.log.Printf("LLM error (%s): %v", WorkerFrom(ctx), err)
-	}
+	l.record(ctx, "llm request", len(prompt)+len(in), start, err)
 	return raw, err
 }
 
 func (l *logging) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
-	in, _ := json.MarshalIndent(input, "", "  ")
-	l.log.Printf("LLM stream request (%s): %d bytes", WorkerFrom(ctx), len(prompt)+len(in))
+	ctx = l.withCallAttrs(ctx)
+	in, _ := json.Marshal(input)
+	start := time.Now()
 	raw, err := l.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+	l.record(ctx, "llm stream request", len(prompt)+len(in), start, err)
+	return raw, err
+}
+
+func (l *logging) record(ctx context.Context, msg string, bytes int, start time.Time, err error) {
+	kv := []any{"bytes", bytes, "duration_ms", time.Since(start).Milliseconds()}
 	if err != nil {
-		l.log.Printf("LLM stream error (%s): %v", WorkerFrom(ctx), err)
+		logctx.Log(ctx, l.log, slog.LevelError, msg+" failed", append(kv, "error", err.Error())...)
+		return
 	}
-	return raw, err
+	logctx.Log(ctx, l.log, l.level, msg, kv...)
+}
+
+// withCallAttrs tags ctx with the worker and the selected provider/model.
+func (l *logging) withCallAttrs(ctx context.Context) context.Context {
+	var kv []any
+	if worker := WorkerFrom(ctx); worker != "" && worker != "unknown" {
+		kv = append(kv, "worker", worker)
+	}
+	if selected, ok := SelectedClientFrom(ctx); ok {
+		if keyed, ok := selected.(LimiterKeyed); ok {
+			key := keyed.LimiterKey()
+			kv = append(kv, "provider", key.Provider, "model", key.Model)
+		} else {
+			kv = append(kv, "model", selected.Name())
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return logctx.With(ctx, kv...)
 }
@@ -3,6 +3,7 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"strconv"
 	"sync/atomic"
@@ -20,6 +21,7 @@ import (
 type rpsLimiter struct {
 	tokens chan struct{}
 	stopCh chan struct{}
+	period time.Duration // refill interval of one token
 }
 
 // newRPSLimiter creates a limiter that allows up to rps events per second
@@ -33,9 +35,15 @@ func newRPSLimiter(rps float64, burst int) *rpsLimiter {
 		burst = 1
 	}
 
+	// Refill at the configured rate.
+	period := time.Duration(float64(time.Second) / rps)
+	if period <= 0 {
+		period = time.Millisecond // safeguard
+	}
 	l := &rpsLimiter{
 		tokens: make(chan struct{}, burst),
 		stopCh: make(chan struct{}),
+		period: period,
 	}
 
 	// Pre-fill bucket to allow an initial burst.
@@ -43,11 +51,6 @@ func newRPSLimiter(rps float64, burst int) *rpsLimiter {
 		l.tokens <- struct{}{}
 	}
 
-	// Refill at the configured rate.
-	period := time.Duration(float64(time.Second) / rps)
-	if period <= 0 {
-		period = time.Millisecond // safeguard
-	}
 	ticker := time.NewTicker(period)
 	go func() {
 		defer ticker.Stop()
@@ -96,6 +99,19 @@ func (l *rpsLimiter) AcquireN(ctx context.Context, n int) error {
 	return nil
 }
 
+// estimate returns how long acquiring n tokens would wait if no other caller
+// took the tokens refilled meanwhile.
+func (l *rpsLimiter) estimate(n int) time.Duration {
+	if l == nil {
+		return 0
+	}
+	missing := n - len(l.tokens)
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing) * l.period
+}
+
 // Stop terminates the limiter's refill goroutine.
 func (l *rpsLimiter) Stop() {
 	if l == nil {
@@ -196,16 +212,24 @@ func TakeCredit(ctx context.Context) bool {
 func RateLimit(rps float64, burst int) Middleware {
 	return func(next llmclient.LLMClient) llmclient.LLMClient {
 		rl := newRPSLimiter(rps, burst)
-		return &rateLimited{next: next, rl: rl}
+		return &rateLimited{next: next, rl: rl, rps: rps, burst: burst}
 	}
 }
 
 type rateLimited struct {
-	next llmclient.LLMClient
-	rl   *rpsLimiter
+	next  llmclient.LLMClient
+	rl    *rpsLimiter
+	rps   float64
+	burst int
 }
 
 func (c *rateLimited) Name() string { return c.next.Name() }
+func (c *rateLimited) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareRateLimit, Config: map[string]string{
+		"rps":   strconv.FormatFloat(c.rps, 'g', -1, 64),
+		"burst": strconv.Itoa(c.burst),
+	}}
+}
 func (c *rateLimited) Close() error { return c.next.Close() }
 func (c *rateLimited) CountTokens(text string) int {
 	return c.next.CountTokens(text)
@@ -213,27 +237,28 @@ func (c *rateLimited) CountTokens(text string) int {
 func (c *rateLimited) TokenCapacity() int { return c.next.TokenCapacity() }
 
 func (c *rateLimited) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
-	if c.rl != nil {
-		if !TakeCredit(ctx) {
-			if err := c.rl.Acquire(ctx); err != nil {
-				return nil, err
-			}
-		}
+	if err := c.acquire(ctx); err != nil {
+		return nil, err
 	}
 	return c.next.GenerateJSON(ctx, prompt, input)
 }
 
 func (c *rateLimited) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
-	if c.rl != nil {
-		if !TakeCredit(ctx) {
-			if err := c.rl.Acquire(ctx); err != nil {
-				return nil, err
-			}
-		}
+	if err := c.acquire(ctx); err != nil {
+		return nil, err
 	}
 	return c.next.GenerateJSONStream(ctx, prompt, input, onChunk)
 }
 
+func (c *rateLimited) acquire(ctx context.Context) error {
+	if c.rl == nil || TakeCredit(ctx) {
+		return nil
+	}
+	return throttled(ctx, c.next, ThrottleSourceLimiter, c.rl.estimate(1), func() error {
+		return c.rl.Acquire(ctx)
+	})
+}
+
 // RateLimitFromEnv reads RPS/BURST from environment variables.
 func RateLimitFromEnv(prefixes ...string) Middleware {
 	readFloat := func(key string) float64 {
@@ -304,7 +329,8 @@ func MultiLimitConstTokens(rpm, rpd, tpm int, tokensPerRequest int) Middleware {
 		tokensPerRequest = 1
 	}
 	return func(next llmclient.LLMClient) llmclient.LLMClient {
-		return &multiLimited{next: next, rpm: rpmL, rpd: rpdL, tpm: tpmL, tpr: tokensPerRequest}
+		return &multiLimited{next: next, rpm: rpmL, rpd: rpdL, tpm: tpmL, tpr: tokensPerRequest,
+			limits: [3]int{rpm, rpd, tpm}}
 	}
 }
 
@@ -352,9 +378,19 @@ type multiLimited struct {
 	rpd  *rpsLimiter
 	tpm  *rpsLimiter
 	tpr  int
+
+	limits [3]int // rpm, rpd, tpm as configured
 }
 
 func (m *multiLimited) Name() string { return m.next.Name() }
+func (m *multiLimited) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareMultiLimit, Config: map[string]string{
+		"rpm":                strconv.Itoa(m.limits[0]),
+		"rpd":                strconv.Itoa(m.limits[1]),
+		"tpm":                strconv.Itoa(m.limits[2]),
+		"tokens_per_request": strconv.Itoa(m.tpr),
+	}}
+}
 func (m *multiLimited) Close() error { return m.next.Close() }
 func (m *multiLimited) CountTokens(text string) int {
 	return m.next.CountTokens(text)
@@ -362,57 +398,44 @@ func (m *multiLimited) CountTokens(text string) int {
 func (m *multiLimited) TokenCapacity() int { return m.next.TokenCapacity() }
 
 func (m *multiLimited) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
-	if m.rpm != nil {
-		if !TakeCredit(ctx) {
-			if err := m.rpm.Acquire(ctx); err != nil {
-				return nil, err
-			}
-		}
-	}
-	if m.rpd != nil {
-		if !TakeCredit(ctx) {
-			if err := m.rpd.Acquire(ctx); err != nil {
-				return nil, err
-			}
-		}
-	}
-	if m.tpm != nil {
-		est := m.tpr
-		if est < 1 {
-			est = 1
-		}
-		if err := m.tpm.AcquireN(ctx, est); err != nil {
-			return nil, err
-		}
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
 	}
 	return m.next.GenerateJSON(ctx, prompt, input)
 }
 
 func (m *multiLimited) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
-	if m.rpm != nil {
-		if !TakeCredit(ctx) {
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
+	}
+	return m.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+}
+
+func (m *multiLimited) acquire(ctx context.Context) error {
+	// Credits skip the request limiters; take them before estimating.
+	rpm := m.rpm != nil && !TakeCredit(ctx)
+	rpd := m.rpd != nil && !TakeCredit(ctx)
+	est := max(m.tpr, 1)
+	wait := m.tpm.estimate(est)
+	if rpm {
+		wait = max(wait, m.rpm.estimate(1))
+	}
+	if rpd {
+		wait = max(wait, m.rpd.estimate(1))
+	}
+	return throttled(ctx, m.next, ThrottleSourceLimiter, wait, func() error {
+		if rpm {
 			if err := m.rpm.Acquire(ctx); err != nil {
-				return nil, err
+				return err
 			}
 		}
-	}
-	if m.rpd != nil {
-		if !TakeCredit(ctx) {
+		if rpd {
 			if err := m.rpd.Acquire(ctx); err != nil {
-				return nil, err
+				return err
 			}
 		}
-	}
-	if m.tpm != nil {
-		est := m.tpr
-		if est < 1 {
-			est = 1
-		}
-		if err := m.tpm.AcquireN(ctx, est); err != nil {
-			return nil, err
-		}
-	}
-	return m.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+		return m.tpm.AcquireN(ctx, est)
+	})
 }
 
 // ----------------------------------------------------------------------------
@@ -435,17 +458,24 @@ func TokenDayLimitConstTokens(tpd int, tokensPerRequest int) Middleware {
 		tokensPerRequest = 1
 	}
 	return func(next llmclient.LLMClient) llmclient.LLMClient {
-		return &tokenDayLimited{next: next, tpd: tpdL, tpr: tokensPerRequest}
+		return &tokenDayLimited{next: next, tpd: tpdL, tpr: tokensPerRequest, tpdLimit: tpd}
 	}
 }
 
 type tokenDayLimited struct {
-	next llmclient.LLMClient
-	tpd  *rpsLimiter
-	tpr  int
+	next     llmclient.LLMClient
+	tpd      *rpsLimiter
+	tpr      int
+	tpdLimit int
 }
 
 func (m *tokenDayLimited) Name() string { return m.next.Name() }
+func (m *tokenDayLimited) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareTokenDayLimit, Config: map[string]string{
+		"tpd":                strconv.Itoa(m.tpdLimit),
+		"tokens_per_request": strconv.Itoa(m.tpr),
+	}}
+}
 func (m *tokenDayLimited) Close() error { return m.next.Close() }
 func (m *tokenDayLimited) CountTokens(text string) int {
 	return m.next.CountTokens(text)
@@ -453,37 +483,38 @@ func (m *tokenDayLimited) CountTokens(text string) int {
 func (m *tokenDayLimited) TokenCapacity() int { return m.next.TokenCapacity() }
 
 func (m *tokenDayLimited) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
-	if m.tpd != nil {
-		est := m.tpr
-		if est < 1 {
-			est = 1
-		}
-		if err := m.tpd.AcquireN(ctx, est); err != nil {
-			return nil, err
-		}
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
 	}
 	return m.next.GenerateJSON(ctx, prompt, input)
 }
 
 func (m *tokenDayLimited) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
-	if m.tpd != nil {
-		est := m.tpr
-		if est < 1 {
-			est = 1
-		}
-		if err := m.tpd.AcquireN(ctx, est); err != nil {
-			return nil, err
-		}
+	if err := m.acquire(ctx); err != nil {
+		return nil, err
 	}
 	return m.next.GenerateJSONStream(ctx, prompt, input, onChunk)
 }
 
+func (m *tokenDayLimited) acquire(ctx context.Context) error {
+	if m.tpd == nil {
+		return nil
+	}
+	est := max(m.tpr, 1)
+	return throttled(ctx, m.next, ThrottleSourceLimiter, m.tpd.estimate(est), func() error {
+		return m.tpd.AcquireN(ctx, est)
+	})
+}
+
 // ----------------------------------------------------------------------------
 // RespectRateLimitSignals middleware
 // ----------------------------------------------------------------------------
 
 // RespectRateLimitSignals delays requests based on the selected model's last
-// observed provider rate-limit signals.
+// observed provider rate-limit signals. When the selected client shares
+// signals with its provider (ProviderSignalSource), the provider's latest
+// report counts too, less the time since it was observed, so a 429 on one
+// model also holds back the provider's other models.
 func RespectRateLimitSignals(adapter llmclient.RateLimitControlAdapter) Middleware {
 	if adapter == nil {
 		adapter = llmclient.HeaderRateLimitControlAdapter{}
@@ -499,6 +530,11 @@ type rateLimitSignalControlled struct {
 }
 
 func (m *rateLimitSignalControlled) Name() string { return m.next.Name() }
+func (m *rateLimitSignalControlled) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareRateLimitSignals, Config: map[string]string{
+		"adapter": fmt.Sprintf("%T", m.adapter),
+	}}
+}
 func (m *rateLimitSignalControlled) Close() error { return m.next.Close() }
 func (m *rateLimitSignalControlled) CountTokens(text string) int {
 	return m.next.CountTokens(text)
@@ -527,24 +563,28 @@ func (m *rateLimitSignalControlled) wait(ctx context.Context) error {
 	if !ok || selected == nil {
 		return nil
 	}
-	aware, ok := selected.(llmclient.RateLimitHeaderAwareClient)
-	if !ok {
-		return nil
+	var wait time.Duration
+	if aware, ok := selected.(llmclient.RateLimitHeaderAwareClient); ok {
+		if headers, ok := aware.LastRateLimitHeaders(); ok {
+			wait = m.adapter.NextWait(headers)
+		}
 	}
-	headers, ok := aware.LastRateLimitHeaders()
-	if !ok {
-		return nil
+	if shared, ok := selected.(ProviderSignalSource); ok {
+		if headers, observedAt, ok := shared.ProviderRateLimitHeaders(); ok {
+			wait = max(wait, m.adapter.NextWait(headers)-time.Since(observedAt))
+		}
 	}
-	wait := m.adapter.NextWait(headers)
 	if wait <= 0 {
 		return nil
 	}
-	timer := time.NewTimer(wait)
-	defer timer.Stop()
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-timer.C:
-		return nil
-	}
+	return throttled(ctx, m.next, ThrottleSourceSignal, wait, func() error {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	})
 }
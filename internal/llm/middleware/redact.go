@@ -0,0 +1,264 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// redactedPrefix starts every placeholder; text that already is one is left
+// alone, so redacting twice changes nothing.
+const redactedPrefix = "[REDACTED:"
+
+// SecretDetector finds one shape of secret. When Pattern has a group named
+// "secret" only that group is replaced, otherwise the whole match. Check,
+// when set, must accept the secret text for it to count.
+type SecretDetector struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Check   func(secret string) bool
+}
+
+// DefaultSecretDetectors covers common secret shapes found in config files
+// and .env contents.
+var DefaultSecretDetectors = []SecretDetector{
+	{Name: "private_key", Pattern: regexp.MustCompile(`-----BEGIN[A-Z ]*PRIVATE KEY-----[\s\S]*?-----END[A-Z ]*PRIVATE KEY-----`)},
+	{Name: "aws_access_key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`)},
+	{Name: "aws_secret_key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`)},
+	{Name: "bearer_token", Pattern: regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]{8,}=*)`)},
+	{Name: "url_credentials", Pattern: regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.\-]*://[^\s:/@]+:(?P<secret>[^\s@/]+)@`)},
+	{Name: "password", Pattern: regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token)[a-z0-9_]*["']?\s*[:=]\s*["']?(?P<secret>[^\s"'&;,]{4,})`)},
+	{Name: "high_entropy", Pattern: regexp.MustCompile(`[A-Za-z0-9+/_\-]{32,}={0,2}`), Check: isHighEntropy},
+}
+
+// highEntropyBits is the Shannon entropy per character above which a long
+// token counts as random. Hex digests stay below it (at most 4 bits).
+const highEntropyBits = 4.2
+
+// RedactionCounts counts redactions by detector name.
+type RedactionCounts map[string]int
+
+func (c RedactionCounts) add(other RedactionCounts) {
+	for k, v := range other {
+		c[k] += v
+	}
+}
+
+// Total returns the number of redactions.
+func (c RedactionCounts) Total() int {
+	n := 0
+	for _, v := range c {
+		n += v
+	}
+	return n
+}
+
+// Redactor replaces secrets with placeholders of the form
+// [REDACTED:<detector>:<hash>], the hash being the first 8 hex digits of the
+// secret's SHA-256, so repeated occurrences of a secret stay correlatable.
+type Redactor struct {
+	detectors []SecretDetector
+}
+
+// NewRedactor returns a redactor using detectors, or DefaultSecretDetectors
+// when none are given.
+func NewRedactor(detectors ...SecretDetector) *Redactor {
+	if len(detectors) == 0 {
+		detectors = DefaultSecretDetectors
+	}
+	return &Redactor{detectors: detectors}
+}
+
+// RedactString returns s with every detected secret replaced.
+func (r *Redactor) RedactString(s string) (string, RedactionCounts) {
+	var counts RedactionCounts
+	for _, d := range r.detectors {
+		group := d.Pattern.SubexpIndex("secret")
+		matches := d.Pattern.FindAllStringSubmatchIndex(s, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[0], m[1]
+			if group > 0 {
+				start, end = m[2*group], m[2*group+1]
+			}
+			if start < 0 {
+				// An optional secret group that did not take part in the match.
+				continue
+			}
+			secret := s[start:end]
+			if strings.HasPrefix(secret, redactedPrefix) || (d.Check != nil && !d.Check(secret)) {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(placeholder(d.Name, secret))
+			last = end
+			if counts == nil {
+				counts = RedactionCounts{}
+			}
+			counts[d.Name]++
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s, counts
+}
+
+// RedactValue returns v with the secrets in its strings replaced. Values
+// other than strings are walked in their JSON form; v is returned as is when
+// nothing was found.
+func (r *Redactor) RedactValue(v any) (any, RedactionCounts) {
+	switch x := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return r.RedactString(x)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v, nil
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return v, nil
+	}
+	counts := RedactionCounts{}
+	out := r.walk(generic, counts)
+	if counts.Total() == 0 {
+		return v, nil
+	}
+	return out, counts
+}
+
+// RedactJSON redacts a raw model response: structurally when it is valid
+// JSON, as text otherwise.
+func (r *Redactor) RedactJSON(raw json.RawMessage) (json.RawMessage, RedactionCounts) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		s, counts := r.RedactString(string(raw))
+		return json.RawMessage(s), counts
+	}
+	counts := RedactionCounts{}
+	out := r.walk(generic, counts)
+	if counts.Total() == 0 {
+		return raw, nil
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return raw, nil
+	}
+	return b, counts
+}
+
+func (r *Redactor) walk(v any, counts RedactionCounts) any {
+	switch x := v.(type) {
+	case string:
+		s, c := r.RedactString(x)
+		counts.add(c)
+		return s
+	case []any:
+		for i := range x {
+			x[i] = r.walk(x[i], counts)
+		}
+	case map[string]any:
+		for k := range x {
+			x[k] = r.walk(x[k], counts)
+		}
+	}
+	return v
+}
+
+func placeholder(detector, secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return redactedPrefix + detector + ":" + hex.EncodeToString(sum[:4]) + "]"
+}
+
+// isHighEntropy accepts tokens mixing letters and digits whose character
+// entropy reaches highEntropyBits, which leaves identifiers and paths alone.
+func isHighEntropy(s string) bool {
+	var letter, digit bool
+	freq := map[rune]int{}
+	for _, c := range s {
+		freq[c]++
+		letter = letter || unicode.IsLetter(c)
+		digit = digit || unicode.IsDigit(c)
+	}
+	if !letter || !digit {
+		return false
+	}
+	n := float64(len(s))
+	bits := 0.0
+	for _, f := range freq {
+		p := float64(f) / n
+		bits -= p * math.Log2(p)
+	}
+	return bits >= highEntropyBits
+}
+
+// RedactInput replaces secrets in the prompt and input before they are sent
+// to the model (see REDACT_LLM_INPUT). Redactions are counted on the run's
+// RunUsage. A nil r uses DefaultSecretDetectors.
+func RedactInput(r *Redactor) Middleware {
+	if r == nil {
+		r = NewRedactor()
+	}
+	return func(next llmclient.LLMClient) llmclient.LLMClient {
+		return &redactingClient{next: next, redact: r}
+	}
+}
+
+type redactingClient struct {
+	next   llmclient.LLMClient
+	redact *Redactor
+}
+
+func (c *redactingClient) Name() string { return c.next.Name() }
+func (c *redactingClient) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareRedactInput}
+}
+func (c *redactingClient) Close() error { return c.next.Close() }
+func (c *redactingClient) CountTokens(text string) int {
+	return c.next.CountTokens(text)
+}
+func (c *redactingClient) TokenCapacity() int { return c.next.TokenCapacity() }
+
+func (c *redactingClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	prompt, input = redactCall(ctx, c.redact, prompt, input)
+	return c.next.GenerateJSON(ctx, prompt, input)
+}
+
+func (c *redactingClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	prompt, input = redactCall(ctx, c.redact, prompt, input)
+	return c.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+}
+
+// redactCall redacts prompt and input, counting on the run's RunUsage.
+func redactCall(ctx context.Context, r *Redactor, prompt string, input any) (string, any) {
+	prompt, pc := r.RedactString(prompt)
+	input, ic := r.RedactValue(input)
+	countRedactions(ctx, pc)
+	countRedactions(ctx, ic)
+	return prompt, input
+}
+
+func countRedactions(ctx context.Context, counts RedactionCounts) {
+	if len(counts) == 0 {
+		return
+	}
+	if u, ok := RunUsageFrom(ctx); ok {
+		u.addRedactions(counts)
+	}
+}
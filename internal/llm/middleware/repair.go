@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// RepairJSON runs llmclient.RepairJSON over every successful response, so
+// fenced, prose-wrapped or trailing-comma output reaches callers as valid
+// JSON. Unrepairable output fails with llmclient.ErrInvalidJSON, which Retry
+// treats as transient.
+func RepairJSON() Middleware {
+	return func(next llmclient.LLMClient) llmclient.LLMClient {
+		return &repairing{next: next}
+	}
+}
+
+type repairing struct{ next llmclient.LLMClient }
+
+func (r *repairing) Name() string { return r.next.Name() }
+func (r *repairing) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareRepairJSON}
+}
+func (r *repairing) Close() error { return r.next.Close() }
+func (r *repairing) CountTokens(text string) int {
+	return r.next.CountTokens(text)
+}
+func (r *repairing) TokenCapacity() int { return r.next.TokenCapacity() }
+
+func (r *repairing) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	return repaired(r.next.GenerateJSON(ctx, prompt, input))
+}
+
+func (r *repairing) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	return repaired(r.next.GenerateJSONStream(ctx, prompt, input, onChunk))
+}
+
+func repaired(raw json.RawMessage, err error) (json.RawMessage, error) {
+	if err != nil {
+		return raw, err
+	}
+	out, err := llmclient.RepairJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(out), nil
+}
@@ -4,13 +4,55 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	llmclient "insightify/internal/llm/client"
 )
 
+// ErrRetryBudgetExhausted wraps the last error of a call that was not retried
+// because the context's retry budget ran out.
+var ErrRetryBudgetExhausted = errors.New("llm retry budget exhausted")
+
+type retryBudgetKey struct{}
+type retryBudget struct{ n atomic.Int64 }
+
+// WithRetryBudget returns a context whose calls share n retries, however many
+// Retry layers and calls they go through. n <= 0 leaves retries unbounded.
+func WithRetryBudget(ctx context.Context, n int) context.Context {
+	if n <= 0 {
+		return ctx
+	}
+	b := &retryBudget{}
+	b.n.Store(int64(n))
+	return context.WithValue(ctx, retryBudgetKey{}, b)
+}
+
+// RetryBudgetRemaining reports the retries left in ctx; ok is false when ctx
+// carries no budget.
+func RetryBudgetRemaining(ctx context.Context) (n int, ok bool) {
+	b, _ := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if b == nil {
+		return 0, false
+	}
+	return int(max(b.n.Load(), 0)), true
+}
+
+// takeRetry consumes one retry from the context budget, if any.
+func takeRetry(ctx context.Context) bool {
+	b, _ := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if b == nil {
+		return true
+	}
+	return b.n.Add(-1) >= 0
+}
+
 // Retry retries GenerateJSON up to maxAttempts with exponential backoff
-// starting at baseDelay. If context is canceled, it stops immediately.
+// starting at baseDelay. If context is canceled, it stops immediately. Each
+// retry spends one unit of the WithRetryBudget budget; once it is spent the
+// call fails fast with ErrRetryBudgetExhausted.
 func Retry(maxAttempts int, baseDelay time.Duration) Middleware {
 	if maxAttempts < 1 {
 		maxAttempts = 1
@@ -30,6 +72,12 @@ type retrying struct {
 }
 
 func (r *retrying) Name() string { return r.next.Name() }
+func (r *retrying) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareRetry, Config: map[string]string{
+		"max_attempts": strconv.Itoa(r.max),
+		"base_delay":   r.base.String(),
+	}}
+}
 func (r *retrying) Close() error { return r.next.Close() }
 func (r *retrying) CountTokens(text string) int {
 	return r.next.CountTokens(text)
@@ -43,9 +91,8 @@ func (r *retrying) GenerateJSON(ctx context.Context, prompt string, input any) (
 		if err == nil {
 			return resp, nil
 		}
-		// If it's a permanent error, do not retry.
-		var pErr *llmclient.PermanentError
-		if errors.As(err, &pErr) {
+		// If it's a permanent error or the circuit is open, do not retry.
+		if llmclient.IsPermanent(err) || errors.Is(err, ErrCircuitOpen) {
 			return nil, err
 		}
 		last = err
@@ -55,7 +102,15 @@ func (r *retrying) GenerateJSON(ctx context.Context, prompt string, input any) (
 			return nil, ctx.Err()
 		default:
 		}
-		time.Sleep(r.base * time.Duration(1<<i))
+		if i == r.max-1 {
+			break
+		}
+		if !takeRetry(ctx) {
+			return nil, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+		if err := sleepContext(ctx, r.base*time.Duration(1<<i)); err != nil {
+			return nil, err
+		}
 	}
 	return nil, last
 }
@@ -67,8 +122,7 @@ func (r *retrying) GenerateJSONStream(ctx context.Context, prompt string, input
 		if err == nil {
 			return resp, nil
 		}
-		var pErr *llmclient.PermanentError
-		if errors.As(err, &pErr) {
+		if llmclient.IsPermanent(err) || errors.Is(err, ErrCircuitOpen) {
 			return nil, err
 		}
 		last = err
@@ -77,7 +131,27 @@ func (r *retrying) GenerateJSONStream(ctx context.Context, prompt string, input
 			return nil, ctx.Err()
 		default:
 		}
-		time.Sleep(r.base * time.Duration(1<<i))
+		if i == r.max-1 {
+			break
+		}
+		if !takeRetry(ctx) {
+			return nil, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+		if err := sleepContext(ctx, r.base*time.Duration(1<<i)); err != nil {
+			return nil, err
+		}
 	}
 	return nil, last
 }
+
+// sleepContext waits for d, returning ctx's error early when it is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
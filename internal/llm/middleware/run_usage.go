@@ -0,0 +1,318 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// ErrBudgetExceeded matches errors of LLM calls refused because they would
+// take the run past its cost budget.
+var ErrBudgetExceeded = errors.New("llm cost budget exceeded")
+
+// BudgetExceededError reports a call refused by the run's cost budget.
+type BudgetExceededError struct {
+	Model        string
+	BudgetUSD    float64
+	SpentUSD     float64
+	EstimatedUSD float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("llm cost budget exceeded: call to %s estimated at $%.4f with $%.4f of $%.4f spent",
+		e.Model, e.EstimatedUSD, e.SpentUSD, e.BudgetUSD)
+}
+
+func (e *BudgetExceededError) Is(target error) bool { return target == ErrBudgetExceeded }
+
+// PricingLookup returns the price of the model behind key; ok is false when
+// the model has no pricing metadata.
+type PricingLookup func(key LimiterKey) (llmclient.Pricing, bool)
+
+// ModelUsage is the usage of one model within a run.
+type ModelUsage struct {
+	Model        string  `json:"model"`
+	Calls        int     `json:"calls"`
+	Errors       int     `json:"errors"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	// Unpriced models have no pricing metadata; their calls count as free.
+	Unpriced bool `json:"unpriced,omitempty"`
+	// ThrottleMs is the time calls waited on rate limits.
+	ThrottleMs int64 `json:"throttle_ms,omitempty"`
+}
+
+// PhaseUsage is the usage of one runner phase (see WithPhase) within a run.
+type PhaseUsage struct {
+	Phase        string  `json:"phase"`
+	Calls        int     `json:"calls"`
+	Errors       int     `json:"errors"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	ThrottleMs   int64   `json:"throttle_ms,omitempty"`
+}
+
+// RunUsageSummary is the aggregated usage of a run, models and phases sorted
+// by name.
+type RunUsageSummary struct {
+	BudgetUSD      float64  `json:"budget_usd,omitempty"`
+	CostUSD        float64  `json:"cost_usd"`
+	Calls          int      `json:"calls"`
+	InputTokens    int      `json:"input_tokens"`
+	OutputTokens   int      `json:"output_tokens"`
+	UnpricedModels []string `json:"unpriced_models,omitempty"`
+	// ThrottleMs is the time the run's calls waited on rate limits, summed
+	// over calls, so concurrent waits add up.
+	ThrottleMs int64        `json:"throttle_ms,omitempty"`
+	Models     []ModelUsage `json:"models"`
+	// Phases splits the usage by runner phase; calls made outside a phase
+	// are not listed.
+	Phases []PhaseUsage `json:"phases,omitempty"`
+	// Redactions counts the secrets replaced in prompts, inputs and
+	// responses, by detector name.
+	Redactions RedactionCounts `json:"redactions,omitempty"`
+}
+
+// RunUsage accumulates the LLM usage and cost of one run and enforces its
+// cost budget. It is safe for concurrent use.
+type RunUsage struct {
+	mu     sync.Mutex
+	budget float64
+	models map[string]*ModelUsage
+	phases map[string]*PhaseUsage
+	redact RedactionCounts
+	// throttle sums the rate-limit waits by model and phase; Summary
+	// rounds them to milliseconds.
+	throttleModels map[string]time.Duration
+	throttlePhases map[string]time.Duration
+}
+
+// NewRunUsage returns an empty accumulator. budgetUSD <= 0 means no budget.
+func NewRunUsage(budgetUSD float64) *RunUsage {
+	return &RunUsage{
+		budget:         max(budgetUSD, 0),
+		models:         map[string]*ModelUsage{},
+		phases:         map[string]*PhaseUsage{},
+		throttleModels: map[string]time.Duration{},
+		throttlePhases: map[string]time.Duration{},
+	}
+}
+
+type runUsageKey struct{}
+
+// WithRunUsage returns a context whose calls through RecordRunUsage are
+// accounted to u. A nil u leaves ctx unchanged.
+func WithRunUsage(ctx context.Context, u *RunUsage) context.Context {
+	if u == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, runUsageKey{}, u)
+}
+
+// RunUsageFrom returns the accumulator attached by WithRunUsage.
+func RunUsageFrom(ctx context.Context) (*RunUsage, bool) {
+	u, _ := ctx.Value(runUsageKey{}).(*RunUsage)
+	return u, u != nil
+}
+
+// Summary returns a snapshot of the usage so far.
+func (u *RunUsage) Summary() RunUsageSummary {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	out := RunUsageSummary{BudgetUSD: u.budget, Models: make([]ModelUsage, 0, len(u.models))}
+	var throttle time.Duration
+	for _, d := range u.throttleModels {
+		throttle += d
+	}
+	out.ThrottleMs = throttle.Milliseconds()
+	for name, m := range u.models {
+		m := *m
+		m.ThrottleMs = u.throttleModels[name].Milliseconds()
+		out.Models = append(out.Models, m)
+		out.CostUSD += m.CostUSD
+		out.Calls += m.Calls
+		out.InputTokens += m.InputTokens
+		out.OutputTokens += m.OutputTokens
+	}
+	sort.Slice(out.Models, func(i, j int) bool { return out.Models[i].Model < out.Models[j].Model })
+	for _, m := range out.Models {
+		if m.Unpriced {
+			out.UnpricedModels = append(out.UnpricedModels, m.Model)
+		}
+	}
+	for name, p := range u.phases {
+		p := *p
+		p.ThrottleMs = u.throttlePhases[name].Milliseconds()
+		out.Phases = append(out.Phases, p)
+	}
+	sort.Slice(out.Phases, func(i, j int) bool { return out.Phases[i].Phase < out.Phases[j].Phase })
+	if len(u.redact) > 0 {
+		out.Redactions = maps.Clone(u.redact)
+	}
+	return out
+}
+
+// recordThrottle accounts d waited on a rate limit by a call to model. The
+// model and phase are listed even before their first call completes.
+func (u *RunUsage) recordThrottle(phase, model string, d time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.models[model] == nil {
+		u.models[model] = &ModelUsage{Model: model}
+	}
+	u.throttleModels[model] += d
+	if phase != "" {
+		if u.phases[phase] == nil {
+			u.phases[phase] = &PhaseUsage{Phase: phase}
+		}
+		u.throttlePhases[phase] += d
+	}
+}
+
+func (u *RunUsage) addRedactions(counts RedactionCounts) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.redact == nil {
+		u.redact = RedactionCounts{}
+	}
+	u.redact.add(counts)
+}
+
+// reserve fails with a *BudgetExceededError when the cost so far plus the
+// estimate of a call with inputTokens would exceed the budget. The output is
+// estimated as the run's mean output per successful call.
+func (u *RunUsage) reserve(model string, inputTokens int, price llmclient.Pricing) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.budget <= 0 {
+		return nil
+	}
+	spent, calls, outTokens := 0.0, 0, 0
+	for _, m := range u.models {
+		spent += m.CostUSD
+		calls += m.Calls - m.Errors
+		outTokens += m.OutputTokens
+	}
+	estOut := 0
+	if calls > 0 {
+		estOut = outTokens / calls
+	}
+	est := price.Cost(inputTokens, estOut)
+	if spent+est > u.budget {
+		return &BudgetExceededError{Model: model, BudgetUSD: u.budget, SpentUSD: spent, EstimatedUSD: est}
+	}
+	return nil
+}
+
+func (u *RunUsage) record(phase, model string, inputTokens, outputTokens int, price llmclient.Pricing, priced, failed bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	m := u.models[model]
+	if m == nil {
+		m = &ModelUsage{Model: model}
+		u.models[model] = m
+	}
+	p := u.phases[phase]
+	if p == nil && phase != "" {
+		p = &PhaseUsage{Phase: phase}
+		u.phases[phase] = p
+	}
+	if p == nil {
+		p = &PhaseUsage{} // not listed
+	}
+	m.Calls++
+	p.Calls++
+	m.Unpriced = !priced
+	if failed {
+		// Failed calls are not billed.
+		m.Errors++
+		p.Errors++
+		return
+	}
+	cost := price.Cost(inputTokens, outputTokens)
+	m.InputTokens += inputTokens
+	m.OutputTokens += outputTokens
+	m.CostUSD += cost
+	p.InputTokens += inputTokens
+	p.OutputTokens += outputTokens
+	p.CostUSD += cost
+}
+
+// RecordRunUsage accounts every call to the RunUsage in its context: prompt
+// tokens are counted before the call, output tokens from the response, and
+// both are priced with the selected model's pricing from lookup. Before each
+// call it checks the run's budget and fails with a permanent error matching
+// ErrBudgetExceeded instead of calling the model. Calls without a RunUsage
+// pass through untouched.
+func RecordRunUsage(lookup PricingLookup) Middleware {
+	return func(next llmclient.LLMClient) llmclient.LLMClient {
+		return &runUsageClient{next: next, lookup: lookup}
+	}
+}
+
+type runUsageClient struct {
+	next   llmclient.LLMClient
+	lookup PricingLookup
+}
+
+func (c *runUsageClient) Name() string { return c.next.Name() }
+func (c *runUsageClient) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareRunUsage}
+}
+func (c *runUsageClient) Close() error { return c.next.Close() }
+func (c *runUsageClient) CountTokens(text string) int {
+	return c.next.CountTokens(text)
+}
+func (c *runUsageClient) TokenCapacity() int { return c.next.TokenCapacity() }
+
+func (c *runUsageClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	return c.call(ctx, prompt, input, func() (json.RawMessage, error) {
+		return c.next.GenerateJSON(ctx, prompt, input)
+	})
+}
+
+func (c *runUsageClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	return c.call(ctx, prompt, input, func() (json.RawMessage, error) {
+		return c.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+	})
+}
+
+func (c *runUsageClient) call(ctx context.Context, prompt string, input any, do func() (json.RawMessage, error)) (json.RawMessage, error) {
+	u, ok := RunUsageFrom(ctx)
+	if !ok {
+		return do()
+	}
+	cli := c.next
+	if selected, ok := SelectedClientFrom(ctx); ok {
+		cli = selected
+	}
+	model := cli.Name()
+	var (
+		price  llmclient.Pricing
+		priced bool
+	)
+	if keyed, ok := cli.(LimiterKeyed); ok {
+		key := keyed.LimiterKey()
+		model = key.Provider + ":" + key.Model
+		if c.lookup != nil {
+			price, priced = c.lookup(key)
+		}
+	}
+
+	in := estimateCallTokens(cli, prompt, input)
+	if err := u.reserve(model, in, price); err != nil {
+		return nil, llmclient.NewPermanentError(err)
+	}
+	out, err := do()
+	u.record(PhaseFrom(ctx), model, in, cli.CountTokens(string(out)), price, priced, err != nil)
+	return out, err
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// selecting stands in for llmmodel.SelectModel, which imports this package.
+type selecting struct{ fakeClient }
+
+func (*selecting) Describe() MiddlewareInfo { return MiddlewareInfo{Name: MiddlewareSelectModel} }
+
+func selectModel(llmclient.LLMClient) llmclient.LLMClient { return &selecting{} }
+
+func noPricing(LimiterKey) (llmclient.Pricing, bool) { return llmclient.Pricing{}, false }
+
+func TestWrapDescribesChain(t *testing.T) {
+	cli := Wrap(&fakeClient{name: "inner"},
+		selectModel,
+		Dedupe(),
+		RespectRateLimitSignals(nil),
+		Retry(3, 300*time.Millisecond),
+		RecordRunUsage(noPricing),
+		WithCircuitBreaker(5, 30*time.Second),
+		SharedMultiLimit(NewLimiterRegistry()),
+		WithLogging(nil, slog.LevelInfo),
+		WithHooks(),
+	)
+	chain := DescribeChain(cli)
+	want := []string{
+		MiddlewareSelectModel, MiddlewareDedupe, MiddlewareRateLimitSignals, MiddlewareRetry,
+		MiddlewareRunUsage, MiddlewareCircuitBreaker, MiddlewareSharedMultiLimit, MiddlewareLogging, MiddlewareHooks,
+	}
+	if len(chain) != len(want) {
+		t.Fatalf("chain = %v, want %d layers", chain, len(want))
+	}
+	for i, name := range want {
+		if chain[i].Name != name {
+			t.Fatalf("chain[%d] = %q, want %q", i, chain[i].Name, name)
+		}
+	}
+	if got := chain[3].Config; got["max_attempts"] != "3" || got["base_delay"] != "300ms" {
+		t.Fatalf("retry config = %v", got)
+	}
+	if got := chain[5].Config; got["threshold"] != "5" || got["cooldown"] != "30s" {
+		t.Fatalf("circuit breaker config = %v", got)
+	}
+	if got := chain[7].Config["level"]; got != "INFO" {
+		t.Fatalf("logging level = %q, want INFO", got)
+	}
+	if s := FormatChain(chain); !strings.HasPrefix(s, "select_model -> dedupe -> ") ||
+		!strings.Contains(s, "retry(base_delay=300ms,max_attempts=3)") {
+		t.Fatalf("FormatChain = %q", s)
+	}
+	if issues := Validate(chain); len(issues) != 0 {
+		t.Fatalf("Validate(runtime order) = %v, want none", issues)
+	}
+	if _, err := cli.GenerateJSON(context.Background(), "p", nil); err != nil {
+		t.Fatalf("GenerateJSON through described chain: %v", err)
+	}
+}
+
+func TestValidateFlagsMisorderings(t *testing.T) {
+	cases := []struct {
+		name     string
+		mws      []Middleware
+		severity string
+		contains string
+	}{
+		{
+			name:     "rate limit inside retry",
+			mws:      []Middleware{selectModel, Retry(3, time.Millisecond), RateLimit(5, 1)},
+			severity: SeverityWarn,
+			contains: "re-acquires",
+		},
+		{
+			name:     "multi limit without select model",
+			mws:      []Middleware{Retry(3, time.Millisecond), SharedMultiLimit(NewLimiterRegistry())},
+			severity: SeverityError,
+			contains: MiddlewareSelectModel,
+		},
+		{
+			name:     "select model inside multi limit",
+			mws:      []Middleware{SharedMultiLimit(NewLimiterRegistry()), selectModel},
+			severity: SeverityError,
+			contains: MiddlewareSharedMultiLimit,
+		},
+	}
+	for _, tc := range cases {
+		issues := Validate(DescribeChain(Wrap(&fakeClient{}, tc.mws...)))
+		if len(issues) != 1 {
+			t.Fatalf("%s: issues = %v, want one", tc.name, issues)
+		}
+		if issues[0].Severity != tc.severity || !strings.Contains(issues[0].Message, tc.contains) {
+			t.Fatalf("%s: issue = %+v, want %s containing %q", tc.name, issues[0], tc.severity, tc.contains)
+		}
+	}
+
+	ok := Validate(DescribeChain(Wrap(&fakeClient{}, RateLimit(5, 1), Retry(3, time.Millisecond))))
+	if len(ok) != 0 {
+		t.Fatalf("rate limit outside retry: issues = %v, want none", ok)
+	}
+}
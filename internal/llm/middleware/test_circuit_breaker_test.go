@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// scriptedClient returns the scripted errors in order, then succeeds.
+type scriptedClient struct {
+	script []error
+	calls  int
+}
+
+func (c *scriptedClient) Name() string                { return "scripted" }
+func (c *scriptedClient) Close() error                { return nil }
+func (c *scriptedClient) CountTokens(text string) int { return len(text) }
+func (c *scriptedClient) TokenCapacity() int          { return 1024 }
+func (c *scriptedClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	c.calls++
+	if len(c.script) > 0 {
+		err := c.script[0]
+		c.script = c.script[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.RawMessage(`{}`), nil
+}
+func (c *scriptedClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	return c.GenerateJSON(ctx, prompt, input)
+}
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	b := NewCircuitBreaker(threshold, cooldown)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreakerOpensHalfOpensAndCloses(t *testing.T) {
+	b, now := newTestBreaker(2, 10*time.Second)
+	errServer := errors.New("unexpected status 500")
+	inner := &scriptedClient{script: []error{errServer, errServer, errServer}}
+	cli := b.Middleware()(inner)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := cli.GenerateJSON(ctx, "p", nil); !errors.Is(err, errServer) {
+			t.Fatalf("call %d: expected provider error, got %v", i, err)
+		}
+	}
+
+	_, err := cli.GenerateJSON(ctx, "p", nil)
+	var open *CircuitOpenError
+	if !errors.As(err, &open) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected CircuitOpenError, got %v", err)
+	}
+	if open.RetryIn != 10*time.Second {
+		t.Fatalf("RetryIn = %s, want 10s", open.RetryIn)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("open circuit must not call provider, calls=%d", inner.calls)
+	}
+	if snap := b.Snapshot(); len(snap) != 1 || snap[0].State != CircuitOpen || snap[0].Failures != 2 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+
+	// Failed half-open probe re-opens for a full cooldown.
+	*now = now.Add(11 * time.Second)
+	if _, err := cli.GenerateJSON(ctx, "p", nil); !errors.Is(err, errServer) {
+		t.Fatalf("expected probe to reach provider, got %v", err)
+	}
+	if _, err := cli.GenerateJSON(ctx, "p", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected circuit to re-open after failed probe, got %v", err)
+	}
+
+	// Successful probe closes the circuit.
+	*now = now.Add(11 * time.Second)
+	if _, err := cli.GenerateJSON(ctx, "p", nil); err != nil {
+		t.Fatalf("expected probe to succeed, got %v", err)
+	}
+	if _, err := cli.GenerateJSON(ctx, "p", nil); err != nil {
+		t.Fatalf("expected closed circuit, got %v", err)
+	}
+	if snap := b.Snapshot(); len(snap) != 0 {
+		t.Fatalf("expected no tracked circuits after close, got %+v", snap)
+	}
+}
+
+func TestCircuitBreakerHalfOpenAdmitsSingleProbe(t *testing.T) {
+	b, now := newTestBreaker(1, time.Second)
+	key := LimiterKey{Provider: "p", Model: "m"}.String()
+	b.record(context.Background(), key, false, errors.New("boom"))
+	*now = now.Add(2 * time.Second)
+
+	probe, err := b.allow(key)
+	if err != nil || !probe {
+		t.Fatalf("expected first caller to probe, got probe=%v err=%v", probe, err)
+	}
+	var open *CircuitOpenError
+	if _, err := b.allow(key); !errors.As(err, &open) || open.RetryIn != 0 {
+		t.Fatalf("expected concurrent caller to fail fast while probing, got %v", err)
+	}
+	if !b.IsOpen(LimiterKey{Provider: "p", Model: "m"}) {
+		t.Fatalf("expected IsOpen while probe in flight")
+	}
+}
+
+func TestCircuitBreakerIgnoresPermanentAndCanceled(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+	inner := &scriptedClient{script: []error{
+		llmclient.NewPermanentError(errors.New("bad request")),
+		context.Canceled,
+	}}
+	cli := b.Middleware()(inner)
+
+	if _, err := cli.GenerateJSON(context.Background(), "p", nil); !llmclient.IsPermanent(err) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if _, err := cli.GenerateJSON(context.Background(), "p", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+	if _, err := cli.GenerateJSON(context.Background(), "p", nil); err != nil {
+		t.Fatalf("expected circuit to stay closed, got %v", err)
+	}
+	if snap := b.Snapshot(); len(snap) != 0 {
+		t.Fatalf("expected no failures recorded, got %+v", snap)
+	}
+}
+
+func TestCircuitBreakerKeysBySelectedClient(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+	inner := &scriptedClient{script: []error{errors.New("500")}}
+	cli := b.Middleware()(inner)
+
+	failing := WithLimiterKey(LimiterKey{Provider: "groq", Model: "a"})(&scriptedClient{})
+	healthy := WithLimiterKey(LimiterKey{Provider: "groq", Model: "b"})(&scriptedClient{})
+
+	_, _ = cli.GenerateJSON(WithSelectedClient(context.Background(), failing), "p", nil)
+	if _, err := cli.GenerateJSON(WithSelectedClient(context.Background(), failing), "p", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected failing model circuit open, got %v", err)
+	}
+	if _, err := cli.GenerateJSON(WithSelectedClient(context.Background(), healthy), "p", nil); err != nil {
+		t.Fatalf("expected other model unaffected, got %v", err)
+	}
+}
+
+func TestRetryStopsOnOpenCircuit(t *testing.T) {
+	b, _ := newTestBreaker(1, time.Minute)
+	inner := &scriptedClient{script: []error{errors.New("500"), errors.New("500"), errors.New("500")}}
+	cli := Wrap(inner, Retry(3, time.Millisecond), b.Middleware())
+
+	if _, err := cli.GenerateJSON(context.Background(), "p", nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected retry to surface open circuit, got %v", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected one provider call before the circuit opened, got %d", inner.calls)
+	}
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// blockingClient holds every call until release is closed.
+type blockingClient struct {
+	fakeClient
+	calls   atomic.Int32
+	streams atomic.Int32
+	release chan struct{}
+}
+
+func (c *blockingClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	c.calls.Add(1)
+	<-c.release
+	return json.RawMessage(`{"answer":"` + prompt + `"}`), nil
+}
+
+func (c *blockingClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	c.streams.Add(1)
+	onChunk(prompt)
+	return json.RawMessage(`{}`), nil
+}
+
+func TestDedupeCoalescesConcurrentCalls(t *testing.T) {
+	const n = 8
+	inner := &blockingClient{release: make(chan struct{})}
+	cli := Dedupe()(inner)
+	ctx := context.Background()
+
+	var started, done sync.WaitGroup
+	results := make([]string, n)
+	for i := 0; i < n; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			raw, err := cli.GenerateJSON(ctx, "same", map[string]any{"file": "a.go"})
+			if err != nil {
+				t.Errorf("GenerateJSON() error = %v", err)
+			}
+			results[i] = string(raw)
+		}(i)
+	}
+	started.Wait()
+	// Let every caller join the in-flight call before it returns.
+	time.Sleep(50 * time.Millisecond)
+	close(inner.release)
+	done.Wait()
+
+	if got := inner.calls.Load(); got != 1 {
+		t.Fatalf("downstream calls = %d, want 1", got)
+	}
+	for i, r := range results {
+		if r != `{"answer":"same"}` {
+			t.Fatalf("result %d = %s, want the shared response", i, r)
+		}
+	}
+}
+
+func TestDedupeKeepsDistinctAndStreamingCalls(t *testing.T) {
+	inner := &blockingClient{release: make(chan struct{})}
+	close(inner.release)
+	cli := Dedupe()(inner)
+	ctx := context.Background()
+
+	calls := []struct {
+		ctx    context.Context
+		prompt string
+		input  any
+	}{
+		{ctx, "p", map[string]any{"file": "a.go"}},
+		{ctx, "p", map[string]any{"file": "b.go"}},
+		{llmclient.WithGenerationOptions(ctx, llmclient.GenerationOptions{Temperature: llmclient.Float32(0.5)}), "p", map[string]any{"file": "a.go"}},
+	}
+	for _, c := range calls {
+		if _, err := cli.GenerateJSON(c.ctx, c.prompt, c.input); err != nil {
+			t.Fatalf("GenerateJSON() error = %v", err)
+		}
+	}
+	if got := inner.calls.Load(); got != 3 {
+		t.Fatalf("downstream calls = %d, want one per distinct request", got)
+	}
+
+	for i := 0; i < 2; i++ {
+		var chunks []string
+		if _, err := cli.GenerateJSONStream(ctx, "p", nil, func(s string) { chunks = append(chunks, s) }); err != nil || len(chunks) != 1 {
+			t.Fatalf("GenerateJSONStream() = %v, chunks %v", err, chunks)
+		}
+	}
+	if got := inner.streams.Load(); got != 2 {
+		t.Fatalf("streaming calls = %d, want 2", got)
+	}
+}
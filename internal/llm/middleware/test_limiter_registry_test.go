@@ -0,0 +1,91 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+func TestSharedMultiLimit_ClientsOfOneModelShareBudget(t *testing.T) {
+	reg := NewLimiterRegistry()
+	key := LimiterKey{Provider: "groq", Model: "m", Tier: "free"}
+	reg.Seed(key, llmclient.RateLimitConfig{RPM: 2})
+
+	// Two independently wrapped clients, e.g. built for different roles/levels.
+	a := Wrap(&passthroughClient{}, SharedMultiLimit(reg))
+	b := Wrap(&passthroughClient{}, SharedMultiLimit(reg))
+	ctx := WithSelectedClient(context.Background(), WithLimiterKey(key)(&passthroughClient{}))
+
+	if _, err := a.GenerateJSON(ctx, "p", nil); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if _, err := b.GenerateJSON(ctx, "p", nil); err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := a.GenerateJSON(short, "p", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("third call should block on the shared 2 RPM budget, got %v", err)
+	}
+
+	snap := reg.Snapshot()
+	if len(snap) != 1 || !snap[0].Active || snap[0].Remaining["rpm"] != 0 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+}
+
+func TestSharedMultiLimit_UnkeyedClientPassesThrough(t *testing.T) {
+	reg := NewLimiterRegistry()
+	cli := Wrap(&passthroughClient{}, SharedMultiLimit(reg))
+	ctx := WithSelectedClient(context.Background(), &passthroughClient{})
+	for i := 0; i < 5; i++ {
+		if _, err := cli.GenerateJSON(ctx, "p", nil); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+}
+
+func TestSharedMultiLimit_UnselectedCallUsesWrappedClientKey(t *testing.T) {
+	reg := NewLimiterRegistry()
+	key := LimiterKey{Provider: "groq", Model: "fallback", Tier: "free"}
+	reg.Seed(key, llmclient.RateLimitConfig{RPM: 1})
+
+	cli := Wrap(&passthroughClient{}, SharedMultiLimit(reg), WithLimiterKeyIn(reg, key))
+	if _, err := cli.GenerateJSON(context.Background(), "p", nil); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := cli.GenerateJSON(short, "p", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second call should block on the fallback's 1 RPM budget, got %v", err)
+	}
+}
+
+func TestLimiterRegistry_ConcurrentFirstUseCreatesOneSet(t *testing.T) {
+	reg := NewLimiterRegistry()
+	key := LimiterKey{Provider: "gemini", Model: "m"}
+	reg.Seed(key, llmclient.RateLimitConfig{RPM: 10})
+
+	const n = 16
+	sets := make([]*limiterSet, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sets[i] = reg.limiters(key)
+		}(i)
+	}
+	wg.Wait()
+	for i := 1; i < n; i++ {
+		if sets[i] == nil || sets[i] != sets[0] {
+			t.Fatalf("expected a single shared limiter set")
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"insightify/internal/common/logctx"
+)
+
+func TestWithLoggingEmitsCallAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	inner := &scriptedClient{script: []error{nil, errors.New("boom")}}
+	cli := WithLogging(logger, slog.LevelInfo)(inner)
+
+	selected := WithLimiterKey(LimiterKey{Provider: "groq", Model: "llama-3.1-8b-instant"})(&scriptedClient{})
+	ctx := logctx.With(context.Background(), "run_id", "run-42")
+	ctx = WithWorker(ctx, "code_specs")
+	ctx = WithSelectedClient(ctx, selected)
+
+	if _, err := cli.GenerateJSON(ctx, "prompt", map[string]any{"x": 1}); err != nil {
+		t.Fatalf("GenerateJSON: %v", err)
+	}
+	if _, err := cli.GenerateJSON(ctx, "prompt", nil); err == nil {
+		t.Fatalf("expected scripted error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d: %s", len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("record %d is not JSON: %v", i, err)
+		}
+		want := map[string]any{
+			"run_id":   "run-42",
+			"worker":   "code_specs",
+			"provider": "groq",
+			"model":    "llama-3.1-8b-instant",
+		}
+		for k, v := range want {
+			if rec[k] != v {
+				t.Fatalf("record %d: %s = %v, want %v (%s)", i, k, rec[k], v, line)
+			}
+		}
+	}
+
+	var first, second map[string]any
+	_ = json.Unmarshal([]byte(lines[0]), &first)
+	_ = json.Unmarshal([]byte(lines[1]), &second)
+	if first["level"] != "INFO" || first["msg"] != "llm request" {
+		t.Fatalf("unexpected success record: %s", lines[0])
+	}
+	if second["level"] != "ERROR" || second["error"] != "boom" {
+		t.Fatalf("unexpected error record: %s", lines[1])
+	}
+}
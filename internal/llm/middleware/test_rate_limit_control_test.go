@@ -66,3 +66,71 @@ func TestRespectRateLimitSignals_WaitsByAdapter(t *testing.T) {
 		t.Fatalf("expected middleware wait, got=%s", elapsed)
 	}
 }
+
+// reportingClient reports headers to its handler on every call, like a
+// provider client parsing response headers.
+type reportingClient struct {
+	passthroughClient
+	headers llmclient.RateLimitHeaders
+	handler llmclient.RateLimitHeaderHandler
+}
+
+func (c *reportingClient) SetRateLimitHeaderHandler(handler llmclient.RateLimitHeaderHandler) {
+	c.handler = handler
+}
+func (c *reportingClient) LastRateLimitHeaders() (llmclient.RateLimitHeaders, bool) {
+	return llmclient.RateLimitHeaders{}, false
+}
+func (c *reportingClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	if c.handler != nil {
+		c.handler(c.headers)
+	}
+	return c.passthroughClient.GenerateJSON(ctx, prompt, input)
+}
+
+// msRetryAdapter reads RetryAfterSeconds as milliseconds to keep tests fast.
+type msRetryAdapter struct{}
+
+func (msRetryAdapter) NextWait(headers llmclient.RateLimitHeaders) time.Duration {
+	return time.Duration(headers.RetryAfterSeconds) * time.Millisecond
+}
+
+func TestRespectRateLimitSignals_ProviderAggregateDelaysOtherModel(t *testing.T) {
+	reg := NewLimiterRegistry()
+	throttled := WithLimiterKeyIn(reg, LimiterKey{Provider: "groq", Model: "a"})(&reportingClient{headers: llmclient.RateLimitHeaders{RetryAfterSeconds: 200}})
+	sibling := WithLimiterKeyIn(reg, LimiterKey{Provider: "groq", Model: "b"})(&reportingClient{})
+	other := WithLimiterKeyIn(reg, LimiterKey{Provider: "gemini", Model: "c"})(&reportingClient{})
+
+	var seen llmclient.RateLimitHeaders
+	throttled.(llmclient.RateLimitHeaderAwareClient).SetRateLimitHeaderHandler(func(h llmclient.RateLimitHeaders) { seen = h })
+	cli := Wrap(&passthroughClient{}, RespectRateLimitSignals(msRetryAdapter{}))
+	call := func(selected llmclient.LLMClient) time.Duration {
+		t.Helper()
+		start := time.Now()
+		if _, err := cli.GenerateJSON(WithSelectedClient(context.Background(), selected), "p", nil); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		return time.Since(start)
+	}
+
+	// Model a is told to back off; the dispatcher calls it directly here.
+	if _, err := throttled.GenerateJSON(context.Background(), "p", nil); err != nil {
+		t.Fatalf("throttled call: %v", err)
+	}
+	if seen.RetryAfterSeconds != 200 {
+		t.Fatalf("chained handler saw %+v", seen)
+	}
+	if d := call(other); d > 100*time.Millisecond {
+		t.Fatalf("other provider waited %s", d)
+	}
+	if d := call(sibling); d < 150*time.Millisecond {
+		t.Fatalf("same-provider model waited only %s, want the provider's retry-after", d)
+	}
+	// Model b's own (clear) response replaced the provider's signal.
+	if _, err := sibling.GenerateJSON(context.Background(), "p", nil); err != nil {
+		t.Fatalf("sibling call: %v", err)
+	}
+	if d := call(throttled); d > 100*time.Millisecond {
+		t.Fatalf("cleared provider still waited %s", d)
+	}
+}
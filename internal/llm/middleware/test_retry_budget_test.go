@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func failing(n int, err error) []error {
+	script := make([]error, n)
+	for i := range script {
+		script[i] = err
+	}
+	return script
+}
+
+func TestRetryBudgetSharedAcrossCalls(t *testing.T) {
+	errServer := errors.New("unexpected status 503")
+	inner := &scriptedClient{script: failing(100, errServer)}
+	cli := Wrap(inner, Retry(3, time.Millisecond))
+	ctx := WithRetryBudget(context.Background(), 5)
+
+	// Two failing calls spend two retries each: 3 attempts per call.
+	for i := 0; i < 2; i++ {
+		if _, err := cli.GenerateJSON(ctx, "p", nil); !errors.Is(err, errServer) || errors.Is(err, ErrRetryBudgetExhausted) {
+			t.Fatalf("call %d error = %v, want provider error", i, err)
+		}
+	}
+	if inner.calls != 6 {
+		t.Fatalf("provider calls = %d, want 6", inner.calls)
+	}
+	if n, ok := RetryBudgetRemaining(ctx); !ok || n != 1 {
+		t.Fatalf("RetryBudgetRemaining() = %d, %v; want 1", n, ok)
+	}
+
+	// The third call gets the last retry, then runs out mid-call.
+	_, err := cli.GenerateJSON(ctx, "p", nil)
+	if !errors.Is(err, ErrRetryBudgetExhausted) || !errors.Is(err, errServer) {
+		t.Fatalf("third call error = %v, want exhausted budget wrapping the provider error", err)
+	}
+	if inner.calls != 8 {
+		t.Fatalf("provider calls = %d, want 8", inner.calls)
+	}
+
+	// Later calls, streaming included, fail after a single attempt.
+	if _, err := cli.GenerateJSONStream(ctx, "p", nil, nil); !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("stream call error = %v", err)
+	}
+	if inner.calls != 9 {
+		t.Fatalf("provider calls = %d, want 9: no retry once the budget is spent", inner.calls)
+	}
+	if n, _ := RetryBudgetRemaining(ctx); n != 0 {
+		t.Fatalf("RetryBudgetRemaining() = %d, want 0", n)
+	}
+
+	// A success still goes through on the first attempt.
+	inner.script = nil
+	if _, err := cli.GenerateJSON(ctx, "p", nil); err != nil {
+		t.Fatalf("successful call error = %v", err)
+	}
+}
+
+func TestRetryWithoutBudgetIsPerCall(t *testing.T) {
+	errServer := errors.New("unexpected status 503")
+	inner := &scriptedClient{script: failing(9, errServer)}
+	cli := Wrap(inner, Retry(3, time.Millisecond))
+	ctx := WithRetryBudget(context.Background(), 0)
+	if _, ok := RetryBudgetRemaining(ctx); ok {
+		t.Fatalf("zero budget should leave retries unbounded")
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := cli.GenerateJSON(ctx, "p", nil); !errors.Is(err, errServer) || errors.Is(err, ErrRetryBudgetExhausted) {
+			t.Fatalf("call %d error = %v", i, err)
+		}
+	}
+	if inner.calls != 9 {
+		t.Fatalf("provider calls = %d, want 9", inner.calls)
+	}
+}
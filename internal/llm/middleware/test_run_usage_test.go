@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// countingClient counts the calls that reach it.
+type countingClient struct {
+	usageMockClient
+	calls int
+}
+
+func (c *countingClient) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	c.calls++
+	return c.usageMockClient.GenerateJSON(ctx, prompt, input)
+}
+
+func TestRecordRunUsage_PricesCallsAndEnforcesBudget(t *testing.T) {
+	reg := NewLimiterRegistry()
+	priced := WithLimiterKeyIn(reg, LimiterKey{Provider: "groq", Model: "a"})(&usageMockClient{name: "a"})
+	unpriced := WithLimiterKeyIn(reg, LimiterKey{Provider: "groq", Model: "b"})(&usageMockClient{name: "b"})
+	lookup := func(key LimiterKey) (llmclient.Pricing, bool) {
+		if key.Model == "a" {
+			return llmclient.Pricing{InputPerMTok: 1_000_000, OutputPerMTok: 2_000_000}, true
+		}
+		return llmclient.Pricing{}, false
+	}
+	inner := &countingClient{usageMockClient: usageMockClient{name: "dispatch"}}
+	cli := Wrap(inner, Retry(3, time.Millisecond), RecordRunUsage(lookup))
+
+	// "prompt\nnull" is 5 tokens and {"ok":true} 5 tokens: $5 + $10.
+	usage := NewRunUsage(20)
+	ctx := WithRunUsage(context.Background(), usage)
+	if _, err := cli.GenerateJSON(WithSelectedClient(ctx, priced), "prompt", nil); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if _, err := cli.GenerateJSON(WithSelectedClient(ctx, unpriced), "prompt", nil); err != nil {
+		t.Fatalf("unpriced call: %v", err)
+	}
+	sum := usage.Summary()
+	if math.Abs(sum.CostUSD-15) > 1e-9 || sum.Calls != 2 || sum.InputTokens != 10 || sum.OutputTokens != 10 {
+		t.Fatalf("summary = %+v, want $15 over 2 calls", sum)
+	}
+	if len(sum.UnpricedModels) != 1 || sum.UnpricedModels[0] != "groq:b" {
+		t.Fatalf("unpriced models = %v, want [groq:b]", sum.UnpricedModels)
+	}
+
+	// $15 spent plus an estimated $15 exceeds $20: the model is not called,
+	// and Retry does not try again.
+	_, err := cli.GenerateJSON(WithSelectedClient(ctx, priced), "prompt", nil)
+	var budgetErr *BudgetExceededError
+	if !errors.Is(err, ErrBudgetExceeded) || !errors.As(err, &budgetErr) || !llmclient.IsPermanent(err) {
+		t.Fatalf("over-budget call error = %v, want permanent ErrBudgetExceeded", err)
+	}
+	if budgetErr.Model != "groq:a" || budgetErr.SpentUSD != 15 || budgetErr.EstimatedUSD != 15 {
+		t.Fatalf("budget error = %+v", budgetErr)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("inner client called %d times, want 2", inner.calls)
+	}
+	// Unpriced models cost nothing, so they still fit.
+	if _, err := cli.GenerateJSON(WithSelectedClient(ctx, unpriced), "prompt", nil); err != nil {
+		t.Fatalf("unpriced call over budget: %v", err)
+	}
+
+	// Without a RunUsage in the context calls are not accounted.
+	if _, err := cli.GenerateJSON(WithSelectedClient(context.Background(), priced), "prompt", nil); err != nil {
+		t.Fatalf("untracked call: %v", err)
+	}
+	if got := usage.Summary().Calls; got != 3 {
+		t.Fatalf("calls = %d, want 3", got)
+	}
+}
@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// streamingClient emits chunks until its context is done.
+type streamingClient struct {
+	fakeClient
+}
+
+func (c *streamingClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	c.calls++
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		onChunk("{")
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestMiddlewaresPropagateStreamCancellation(t *testing.T) {
+	inner := &streamingClient{}
+	cli := Wrap(inner,
+		Retry(3, time.Second),
+		RecordRunUsage(func(LimiterKey) (llmclient.Pricing, bool) { return llmclient.Pricing{}, false }),
+		WithCircuitBreaker(1, time.Minute),
+		WithLogging(slog.Default(), slog.LevelDebug),
+		WithHooks(),
+	)
+	ctx, cancel := context.WithCancel(WithRunUsage(context.Background(), NewRunUsage(0)))
+	defer cancel()
+
+	chunks := 0
+	start := time.Now()
+	_, err := cli.GenerateJSONStream(ctx, "p", nil, func(string) {
+		chunks++
+		if chunks == 2 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GenerateJSONStream() error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("cancelled stream returned after %v", elapsed)
+	}
+	if chunks != 2 || inner.calls != 1 {
+		t.Fatalf("chunks = %d, calls = %d; want no chunk or retry after cancellation", chunks, inner.calls)
+	}
+	// A cancelled call is not a provider failure.
+	if _, err := cli.GenerateJSON(context.Background(), "p", nil); err != nil {
+		t.Fatalf("GenerateJSON() after cancellation error = %v, want a closed circuit", err)
+	}
+}
@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// throttleRecorder collects the events of a ThrottleHook.
+type throttleRecorder struct {
+	mu     sync.Mutex
+	events []ThrottleEvent
+}
+
+func (r *throttleRecorder) hook(_ context.Context, ev ThrottleEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func (r *throttleRecorder) snapshot() []ThrottleEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ThrottleEvent(nil), r.events...)
+}
+
+func TestThrottleHook_ReportsLimiterWaitAndRunUsage(t *testing.T) {
+	reg := NewLimiterRegistry()
+	key := LimiterKey{Provider: "groq", Model: "m"}
+	// One token every 100ms: the second call waits for the refill.
+	reg.Seed(key, llmclient.RateLimitConfig{RPS: 10, Burst: 1})
+	cli := Wrap(&passthroughClient{}, SharedMultiLimit(reg))
+
+	rec := &throttleRecorder{}
+	usage := NewRunUsage(0)
+	ctx := WithSelectedClient(context.Background(), WithLimiterKeyIn(reg, key)(&passthroughClient{}))
+	ctx = WithPhase(WithRunUsage(ctx, usage), "arch_design")
+	ctx = WithThrottleHook(ctx, rec.hook, 10*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cli.GenerateJSON(ctx, "p", nil); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+
+	events := rec.snapshot()
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want one for the blocked call", events)
+	}
+	ev := events[0]
+	if ev.Provider != "groq" || ev.Model != "m" || ev.Source != ThrottleSourceLimiter {
+		t.Fatalf("event = %+v, want groq/m from the limiter", ev)
+	}
+	if ev.Waited < 10*time.Millisecond || ev.Remaining <= 0 {
+		t.Fatalf("event = %+v, want a wait past the threshold with time remaining", ev)
+	}
+
+	sum := usage.Summary()
+	if sum.ThrottleMs <= 0 {
+		t.Fatalf("summary throttle = %dms, want > 0", sum.ThrottleMs)
+	}
+	if len(sum.Models) != 1 || sum.Models[0].Model != "groq:m" || sum.Models[0].ThrottleMs != sum.ThrottleMs {
+		t.Fatalf("models = %+v, want the wait on groq:m", sum.Models)
+	}
+	if len(sum.Phases) != 1 || sum.Phases[0].Phase != "arch_design" || sum.Phases[0].ThrottleMs != sum.ThrottleMs {
+		t.Fatalf("phases = %+v, want the wait on arch_design", sum.Phases)
+	}
+}
+
+func TestThrottleHook_ReportsRateLimitSignalWait(t *testing.T) {
+	cli := Wrap(&passthroughClient{}, RespectRateLimitSignals(fixedWaitAdapter{wait: 60 * time.Millisecond}))
+	sel := &awareWaitClient{headers: llmclient.RateLimitHeaders{RetryAfterSeconds: 1}, has: true}
+
+	rec := &throttleRecorder{}
+	ctx := WithThrottleHook(WithSelectedClient(context.Background(), sel), rec.hook, 10*time.Millisecond)
+	if _, err := cli.GenerateJSON(ctx, "p", nil); err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	events := rec.snapshot()
+	if len(events) != 1 || events[0].Source != ThrottleSourceSignal || events[0].Model != "pass" {
+		t.Fatalf("events = %+v, want one rate-limit signal wait on pass", events)
+	}
+	if events[0].Remaining <= 0 || events[0].Remaining > 60*time.Millisecond {
+		t.Fatalf("remaining = %s, want within the 60ms signal wait", events[0].Remaining)
+	}
+}
+
+func TestThrottleHook_ShortWaitsAreNotReported(t *testing.T) {
+	cli := Wrap(&passthroughClient{}, RateLimit(1000, 5))
+	rec := &throttleRecorder{}
+	usage := NewRunUsage(0)
+	ctx := WithThrottleHook(WithRunUsage(context.Background(), usage), rec.hook, time.Second)
+	for i := 0; i < 3; i++ {
+		if _, err := cli.GenerateJSON(ctx, "p", nil); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if events := rec.snapshot(); len(events) != 0 {
+		t.Fatalf("events = %+v, want none below the threshold", events)
+	}
+	if sum := usage.Summary(); sum.ThrottleMs != 0 || len(sum.Models) != 0 {
+		t.Fatalf("summary = %+v, want no throttle for calls that did not block", sum)
+	}
+}
+
+func TestThrottled_WithoutHookOrUsagePassesThrough(t *testing.T) {
+	cli := Wrap(&passthroughClient{}, RateLimit(50, 1))
+	for i := 0; i < 2; i++ {
+		if _, err := cli.GenerateJSON(context.Background(), "p", nil); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"context"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
+)
+
+// DefaultThrottleEventAfter is how long a call waits on one limiter or
+// rate-limit signal before WithThrottleHook reports it, when after <= 0.
+const DefaultThrottleEventAfter = 5 * time.Second
+
+// Sources of a ThrottleEvent.
+const (
+	ThrottleSourceLimiter = "limiter"           // RateLimit, MultiLimit, TokenDayLimit, SharedMultiLimit
+	ThrottleSourceSignal  = "rate_limit_signal" // RespectRateLimitSignals
+)
+
+// ThrottleEvent reports a call held back by a rate limit for longer than the
+// hook's threshold. Remaining is the expected rest of the wait, zero when
+// unknown; limiter estimates assume no other call takes the next tokens.
+type ThrottleEvent struct {
+	Provider  string
+	Model     string
+	Source    string
+	Waited    time.Duration
+	Remaining time.Duration
+}
+
+// ThrottleHook receives the throttle events of a context. It is called from
+// a timer goroutine while the call is still blocked, so it must be safe for
+// concurrent use and return quickly.
+type ThrottleHook func(ctx context.Context, ev ThrottleEvent)
+
+type throttleHookKey struct{}
+
+type throttleHook struct {
+	fn    ThrottleHook
+	after time.Duration
+}
+
+// WithThrottleHook reports every wait on a limiter or rate-limit signal
+// longer than after (DefaultThrottleEventAfter when <= 0) to fn, once per
+// wait. A nil fn leaves ctx unchanged.
+func WithThrottleHook(ctx context.Context, fn ThrottleHook, after time.Duration) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	if after <= 0 {
+		after = DefaultThrottleEventAfter
+	}
+	return context.WithValue(ctx, throttleHookKey{}, &throttleHook{fn: fn, after: after})
+}
+
+func throttleHookFrom(ctx context.Context) *throttleHook {
+	h, _ := ctx.Value(throttleHookKey{}).(*throttleHook)
+	return h
+}
+
+// throttled runs wait, which blocks on a rate limit, accounting the time it
+// takes to the RunUsage of ctx and reporting it to the ThrottleHook of ctx
+// once it passes the hook's threshold. estimate is the expected wait, zero
+// when unknown; next names the model when no client is selected.
+func throttled(ctx context.Context, next llmclient.LLMClient, source string, estimate time.Duration, wait func() error) error {
+	u, hasUsage := RunUsageFrom(ctx)
+	hook := throttleHookFrom(ctx)
+	if !hasUsage && hook == nil {
+		return wait()
+	}
+	provider, model := usageModel(ctx, next)
+	start := time.Now()
+	var timer *time.Timer
+	if hook != nil {
+		timer = time.AfterFunc(hook.after, func() {
+			waited := time.Since(start)
+			hook.fn(ctx, ThrottleEvent{
+				Provider:  provider,
+				Model:     model,
+				Source:    source,
+				Waited:    waited,
+				Remaining: max(estimate-waited, 0),
+			})
+		})
+	}
+	err := wait()
+	if timer != nil {
+		timer.Stop()
+	}
+	// Waits that did not block are not worth a RunUsage entry.
+	if d := time.Since(start); hasUsage && d >= time.Millisecond {
+		u.recordThrottle(PhaseFrom(ctx), usageModelName(provider, model), d)
+	}
+	return err
+}
+
+// usageModel returns the provider and model of the client selected in ctx,
+// or next's name when it has no LimiterKey.
+func usageModel(ctx context.Context, next llmclient.LLMClient) (provider, model string) {
+	cli := next
+	if selected, ok := SelectedClientFrom(ctx); ok && selected != nil {
+		cli = selected
+	}
+	if keyed, ok := cli.(LimiterKeyed); ok {
+		key := keyed.LimiterKey()
+		return key.Provider, key.Model
+	}
+	if cli == nil {
+		return "", ""
+	}
+	return "", cli.Name()
+}
+
+// usageModelName is the RunUsage model key: "provider:model" for keyed
+// clients, the client name otherwise.
+func usageModelName(provider, model string) string {
+	if provider == "" {
+		return model
+	}
+	return provider + ":" + model
+}
@@ -54,6 +54,9 @@ type usageLedgerClient struct {
 }
 
 func (u *usageLedgerClient) Name() string { return u.next.Name() }
+func (u *usageLedgerClient) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: MiddlewareUsageLedger, Config: map[string]string{"path": u.ledger.path}}
+}
 func (u *usageLedgerClient) Close() error { return u.next.Close() }
 func (u *usageLedgerClient) CountTokens(text string) int {
 	return u.next.CountTokens(text)
@@ -0,0 +1,178 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	llmmiddleware "insightify/internal/llm/middleware"
+)
+
+// Environment variables read by LoadModelOverridesFromEnv. The inline JSON
+// wins when both are set.
+const (
+	ModelOverridesEnv     = "LLM_MODEL_OVERRIDES"
+	ModelOverridesFileEnv = "LLM_MODEL_OVERRIDES_FILE"
+)
+
+// OverrideAllPhases is the override key applied to phases without their own entry.
+const OverrideAllPhases = "*"
+
+// ModelOverride replaces the level a phase asks for and optionally pins a
+// provider/model, without recompiling the worker.
+type ModelOverride struct {
+	Level    ModelLevel `json:"level,omitempty"`
+	Provider string     `json:"provider,omitempty"`
+	Model    string     `json:"model,omitempty"`
+}
+
+// ModelOverrides maps a phase (runner worker key) or OverrideAllPhases to
+// its override, e.g. {"*": {"level": "low"}, "code_roots": {"level": "high"}}.
+type ModelOverrides map[string]ModelOverride
+
+// ParseModelOverrides decodes and normalizes an override document. Unknown
+// fields, invalid levels and half-pinned provider/model pairs are rejected.
+func ParseModelOverrides(raw []byte) (ModelOverrides, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var parsed map[string]ModelOverride
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("model overrides: %w", err)
+	}
+	out := make(ModelOverrides, len(parsed))
+	for key, o := range parsed {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("model overrides: empty phase key")
+		}
+		o.Provider = strings.ToLower(strings.TrimSpace(o.Provider))
+		o.Model = strings.TrimSpace(o.Model)
+		if o.Level != "" {
+			level := normalizeLevel(ModelLevel(strings.ToLower(strings.TrimSpace(string(o.Level)))))
+			if level == "" {
+				return nil, fmt.Errorf("model overrides: %s: invalid level %q", key, o.Level)
+			}
+			o.Level = level
+		}
+		if (o.Provider == "") != (o.Model == "") {
+			return nil, fmt.Errorf("model overrides: %s: provider and model must be set together", key)
+		}
+		if o.Level == "" && o.Provider == "" {
+			return nil, fmt.Errorf("model overrides: %s: nothing to override", key)
+		}
+		out[key] = o
+	}
+	return out, nil
+}
+
+// LoadModelOverridesFromEnv reads ModelOverridesEnv, or the file named by
+// ModelOverridesFileEnv. It returns nil when neither is set.
+func LoadModelOverridesFromEnv() (ModelOverrides, error) {
+	if raw := strings.TrimSpace(os.Getenv(ModelOverridesEnv)); raw != "" {
+		return ParseModelOverrides([]byte(raw))
+	}
+	path := strings.TrimSpace(os.Getenv(ModelOverridesFileEnv))
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("model overrides: %w", err)
+	}
+	return ParseModelOverrides(raw)
+}
+
+// ValidatePhases rejects keys that are neither OverrideAllPhases nor one of phases.
+func (o ModelOverrides) ValidatePhases(phases []string) error {
+	known := make(map[string]struct{}, len(phases))
+	for _, p := range phases {
+		known[p] = struct{}{}
+	}
+	var unknown []string
+	for key := range o {
+		if key == OverrideAllPhases {
+			continue
+		}
+		if _, ok := known[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("model overrides: unknown phase(s): %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// For returns the override for phase, falling back to OverrideAllPhases.
+func (o ModelOverrides) For(phase string) (ModelOverride, bool) {
+	if len(o) == 0 {
+		return ModelOverride{}, false
+	}
+	if ov, ok := o[phase]; ok && phase != "" {
+		return ov, true
+	}
+	ov, ok := o[OverrideAllPhases]
+	return ov, ok
+}
+
+// Salt returns a deterministic string for cache fingerprints, so cached
+// outputs are not reused across different overrides. Runtimes fold it into
+// the model salt shared by every phase, so changing any override invalidates
+// the cache of all phases, not only the overridden ones.
+func (o ModelOverrides) Salt() string {
+	parts := make([]string, 0, len(o))
+	for key, ov := range o {
+		parts = append(parts, fmt.Sprintf("%s=%s/%s", key, ov.Level, keyFor(ov.Provider, ov.Model)))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "|")
+}
+
+// SetModelOverrides installs per-phase overrides consulted by SelectModel.
+// Pinned provider/model pairs must already be registered.
+func (r *InMemoryModelRegistry) SetModelOverrides(o ModelOverrides) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, ov := range o {
+		if ov.Provider == "" {
+			continue
+		}
+		if _, ok := r.models[keyFor(ov.Provider, ov.Model)]; !ok {
+			return fmt.Errorf("model overrides: %s: %w: provider=%s model=%s", key, ErrModelNotRegistered, ov.Provider, ov.Model)
+		}
+	}
+	r.overrides = o
+	return nil
+}
+
+// ModelOverrides returns the installed per-phase overrides.
+func (r *InMemoryModelRegistry) ModelOverrides() ModelOverrides {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.overrides
+}
+
+// applyOverride replaces the requested level and provider/model with the
+// override for the phase in ctx, if any.
+func (r *InMemoryModelRegistry) applyOverride(ctx context.Context, level ModelLevel, provider, model string) (ModelLevel, string, string) {
+	ov, ok := r.ModelOverrides().For(llmmiddleware.PhaseFrom(ctx))
+	if !ok {
+		return level, provider, model
+	}
+	if ov.Level != "" {
+		level = ov.Level
+	}
+	if ov.Provider != "" {
+		provider, model = ov.Provider, ov.Model
+	}
+	return level, provider, model
+}
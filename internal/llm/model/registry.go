@@ -44,6 +44,7 @@ type ModelProfile struct {
 	CountTokens TokenCountFunc
 	Meta        map[string]any
 	RateLimit   *llmclient.RateLimitConfig
+	Pricing     *llmclient.Pricing
 }
 
 // RegisteredModel pairs a profile with its factory.
@@ -63,6 +64,10 @@ type InMemoryModelRegistry struct {
 	models   map[string]RegisteredModel
 	defaults map[ModelRole]map[ModelLevel]string
 	byLevel  map[ModelLevel][]string
+	limiters *llmmiddleware.LimiterRegistry
+	breaker  *llmmiddleware.CircuitBreaker
+	// overrides replace per-phase level/provider/model during selection.
+	overrides ModelOverrides
 }
 
 // NewInMemoryModelRegistry creates a new empty registry.
@@ -71,9 +76,62 @@ func NewInMemoryModelRegistry() *InMemoryModelRegistry {
 		models:   map[string]RegisteredModel{},
 		defaults: map[ModelRole]map[ModelLevel]string{},
 		byLevel:  map[ModelLevel][]string{},
+		limiters: llmmiddleware.DefaultLimiterRegistry(),
 	}
 }
 
+// SetLimiterRegistry replaces the shared limiter registry seeded by RegisterModel.
+func (r *InMemoryModelRegistry) SetLimiterRegistry(limiters *llmmiddleware.LimiterRegistry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limiters = limiters
+}
+
+// Limiters returns the limiter registry holding per-model quota buckets.
+func (r *InMemoryModelRegistry) Limiters() *llmmiddleware.LimiterRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.limiters
+}
+
+// SetCircuitBreaker lets model selection skip candidates whose circuit is open.
+func (r *InMemoryModelRegistry) SetCircuitBreaker(breaker *llmmiddleware.CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breaker = breaker
+}
+
+// CircuitBreaker returns the breaker consulted during selection, if any.
+func (r *InMemoryModelRegistry) CircuitBreaker() *llmmiddleware.CircuitBreaker {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.breaker
+}
+
+// withoutOpenCircuits drops candidates whose circuit is open. If every
+// candidate is open the list is returned unchanged so callers still get a
+// CircuitOpenError rather than "not registered".
+func (r *InMemoryModelRegistry) withoutOpenCircuits(candidates []RegisteredModel) []RegisteredModel {
+	breaker := r.CircuitBreaker()
+	if breaker == nil {
+		return candidates
+	}
+	out := make([]RegisteredModel, 0, len(candidates))
+	for _, c := range candidates {
+		if !breaker.IsOpen(limiterKeyFor(c.Profile)) {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		return candidates
+	}
+	return out
+}
+
+func limiterKeyFor(p ModelProfile) llmmiddleware.LimiterKey {
+	return llmmiddleware.LimiterKey{Provider: p.Provider, Model: p.Model, Tier: p.Tier}
+}
+
 func normalizeRole(role ModelRole) ModelRole {
 	switch role {
 	case ModelRolePlanner:
@@ -131,6 +189,7 @@ func (r *InMemoryModelRegistry) RegisterModel(spec llmclient.ModelRegistration)
 			MaxTokens: spec.MaxTokens,
 			Meta:      spec.Meta,
 			RateLimit: spec.RateLimit,
+			Pricing:   spec.Pricing,
 		},
 		Factory: spec.Factory,
 	}
@@ -142,9 +201,25 @@ func (r *InMemoryModelRegistry) RegisterModel(spec llmclient.ModelRegistration)
 		r.byLevel[level] = append(r.byLevel[level], k)
 	}
 	r.models[k] = entry
+	if spec.RateLimit != nil {
+		r.limiters.Seed(limiterKeyFor(entry.Profile), *spec.RateLimit)
+	}
 	return nil
 }
 
+// Pricing returns the price of the model behind key; ok is false for
+// unregistered models and models registered without pricing. It satisfies
+// llmmiddleware.PricingLookup.
+func (r *InMemoryModelRegistry) Pricing(key llmmiddleware.LimiterKey) (llmclient.Pricing, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.models[keyFor(key.Provider, key.Model)]
+	if !ok || m.Profile.Pricing == nil {
+		return llmclient.Pricing{}, false
+	}
+	return *m.Profile.Pricing, true
+}
+
 // SetDefault sets the default model for a role/level combination.
 func (r *InMemoryModelRegistry) SetDefault(role ModelRole, level ModelLevel, provider, model string) error {
 	role = normalizeRole(role)
@@ -256,7 +331,54 @@ func (r *InMemoryModelRegistry) Candidates(role ModelRole, level ModelLevel) []R
 	return out
 }
 
-// BuildClient creates a client for the resolved model with rate limits applied.
+// List returns every registered model sorted by provider, then model.
+func (r *InMemoryModelRegistry) List() []RegisteredModel {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]RegisteredModel, 0, len(r.models))
+	for _, m := range r.models {
+		out = append(out, m)
+	}
+	sortRegisteredModels(out)
+	return out
+}
+
+// ListByRoleLevel returns the models registered for level. The role's
+// default comes first; the rest are sorted by provider, then model.
+func (r *InMemoryModelRegistry) ListByRoleLevel(role ModelRole, level ModelLevel) []RegisteredModel {
+	out := r.Candidates(role, level)
+	if len(out) < 2 {
+		return out
+	}
+	rest := out
+	if r.isDefault(role, level, out[0].Profile) {
+		rest = out[1:]
+	}
+	sortRegisteredModels(rest)
+	return out
+}
+
+func (r *InMemoryModelRegistry) isDefault(role ModelRole, level ModelLevel, p ModelProfile) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	byRole, ok := r.defaults[normalizeRole(role)]
+	return ok && byRole[normalizeLevel(level)] == keyFor(p.Provider, p.Model)
+}
+
+func sortRegisteredModels(models []RegisteredModel) {
+	sort.Slice(models, func(i, j int) bool {
+		a, b := models[i].Profile, models[j].Profile
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Model < b.Model
+	})
+}
+
+// BuildClient creates a client for the resolved model, tagged with its
+// limiter key. Limits are enforced by SharedMultiLimit on the selected client,
+// so every client of the same provider/model/tier shares one budget, and its
+// rate-limit headers feed the provider aggregate of the limiter registry.
 func (r *InMemoryModelRegistry) BuildClient(
 	ctx context.Context,
 	role ModelRole,
@@ -272,19 +394,7 @@ func (r *InMemoryModelRegistry) BuildClient(
 	if err != nil {
 		return nil, err
 	}
-
-		if rl := entry.Profile.RateLimit; rl != nil {
-			if rl.RPM > 0 || rl.RPD > 0 || rl.TPM > 0 {
-				cli = llmmiddleware.MultiLimit(rl.RPM, rl.RPD, rl.TPM)(cli)
-			}
-			if rl.TPD > 0 {
-				cli = llmmiddleware.TokenDayLimit(rl.TPD)(cli)
-			}
-			if rl.RPS > 0 || rl.Burst > 0 {
-				cli = llmmiddleware.RateLimit(rl.RPS, rl.Burst)(cli)
-			}
-		}
-	return cli, nil
+	return llmmiddleware.WithLimiterKeyIn(r.Limiters(), limiterKeyFor(entry.Profile))(cli), nil
 }
 
 // DefaultsSalt returns a deterministic string representing the current defaults.
@@ -3,10 +3,13 @@ package model
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	llmclient "insightify/internal/llm/client"
 	llmmiddleware "insightify/internal/llm/middleware"
@@ -18,8 +21,24 @@ const (
 	// ModelSelectionModePreferAvailable picks the model with the best known
 	// remaining provider quota when provider/model are not explicitly pinned.
 	ModelSelectionModePreferAvailable ModelSelectionMode = "prefer_available"
+	// ModelSelectionModeWeightedRoundRobin spreads requests across candidates
+	// in proportion to their configured RPM (or TPM) limits.
+	ModelSelectionModeWeightedRoundRobin ModelSelectionMode = "weighted_round_robin"
 )
 
+// ParseModelSelectionMode parses an LLM_SELECTION_MODE value, ignoring case;
+// empty selects ModelSelectionModePreferAvailable.
+func ParseModelSelectionMode(raw string) (ModelSelectionMode, error) {
+	switch mode := ModelSelectionMode(strings.ToLower(strings.TrimSpace(raw))); mode {
+	case "":
+		return ModelSelectionModePreferAvailable, nil
+	case ModelSelectionModePreferAvailable, ModelSelectionModeWeightedRoundRobin:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("LLM_SELECTION_MODE must be %s or %s, got %q", ModelSelectionModePreferAvailable, ModelSelectionModeWeightedRoundRobin, raw)
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Model context – context keys for model selection
 // ----------------------------------------------------------------------------
@@ -146,10 +165,18 @@ type modelSelecting struct {
 
 	mu      sync.Mutex
 	clients map[string]selectedModel
+	cursor  atomic.Uint64
 }
 
 func (m *modelSelecting) Name() string { return m.next.Name() }
 
+func (m *modelSelecting) Describe() llmmiddleware.MiddlewareInfo {
+	return llmmiddleware.MiddlewareInfo{Name: llmmiddleware.MiddlewareSelectModel, Config: map[string]string{
+		"token_cap": strconv.Itoa(m.tokenCap),
+		"mode":      string(m.mode),
+	}}
+}
+
 func (m *modelSelecting) Close() error {
 	seen := map[llmclient.LLMClient]struct{}{}
 	for _, sel := range m.clients {
@@ -171,24 +198,43 @@ func (m *modelSelecting) TokenCapacity() int {
 }
 
 func (m *modelSelecting) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
-	sel, err := m.resolve(ctx)
+	sel, err := m.resolve(ctx, prompt, input)
 	if err != nil {
 		return nil, err
 	}
-	ctx = llmmiddleware.WithSelectedClient(ctx, sel.client)
-	return m.next.GenerateJSON(ctx, prompt, input)
+	out, err := m.next.GenerateJSON(llmmiddleware.WithSelectedClient(ctx, sel.client), prompt, input)
+	if alt, ok := m.fallbackOnOpenCircuit(ctx, prompt, input, sel, err); ok {
+		return m.next.GenerateJSON(llmmiddleware.WithSelectedClient(ctx, alt.client), prompt, input)
+	}
+	return out, err
 }
 
 func (m *modelSelecting) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
-	sel, err := m.resolve(ctx)
+	sel, err := m.resolve(ctx, prompt, input)
 	if err != nil {
 		return nil, err
 	}
-	ctx = llmmiddleware.WithSelectedClient(ctx, sel.client)
-	return m.next.GenerateJSONStream(ctx, prompt, input, onChunk)
+	out, err := m.next.GenerateJSONStream(llmmiddleware.WithSelectedClient(ctx, sel.client), prompt, input, onChunk)
+	if alt, ok := m.fallbackOnOpenCircuit(ctx, prompt, input, sel, err); ok {
+		return m.next.GenerateJSONStream(llmmiddleware.WithSelectedClient(ctx, alt.client), prompt, input, onChunk)
+	}
+	return out, err
+}
+
+// fallbackOnOpenCircuit re-resolves once when the selected model's circuit is
+// open, returning a different candidate if selection finds one.
+func (m *modelSelecting) fallbackOnOpenCircuit(ctx context.Context, prompt string, input any, sel selectedModel, err error) (selectedModel, bool) {
+	if !errors.Is(err, llmmiddleware.ErrCircuitOpen) {
+		return selectedModel{}, false
+	}
+	alt, rerr := m.resolve(ctx, prompt, input)
+	if rerr != nil || alt.client == sel.client {
+		return selectedModel{}, false
+	}
+	return alt, true
 }
 
-func (m *modelSelecting) resolve(ctx context.Context) (selectedModel, error) {
+func (m *modelSelecting) resolve(ctx context.Context, prompt string, input any) (selectedModel, error) {
 	if m.registry == nil {
 		return selectedModel{}, fmt.Errorf("model registry is nil")
 	}
@@ -196,13 +242,19 @@ func (m *modelSelecting) resolve(ctx context.Context) (selectedModel, error) {
 	level := ModelLevelFrom(ctx)
 	provider := ModelProviderFrom(ctx)
 	model := ModelNameFrom(ctx)
+	level, provider, model = m.registry.applyOverride(ctx, level, provider, model)
 	mode := m.mode
 	if normalizeLevel(level) == "" {
 		return selectedModel{}, ErrModelLevelRequired
 	}
 
-	if mode == ModelSelectionModePreferAvailable && provider == "" && model == "" {
-		return m.resolvePreferAvailable(ctx, role, level)
+	if provider == "" && model == "" {
+		switch mode {
+		case ModelSelectionModePreferAvailable:
+			return m.resolvePreferAvailable(ctx, role, level, m.estimateTokens(prompt, input))
+		case ModelSelectionModeWeightedRoundRobin:
+			return m.resolveWeightedRoundRobin(ctx, role, level)
+		}
 	}
 	entry, err := m.registry.Resolve(role, level, provider, model)
 	if err != nil {
@@ -231,14 +283,22 @@ func (m *modelSelecting) getOrCreateSelected(ctx context.Context, role ModelRole
 	return sel, nil
 }
 
-func (m *modelSelecting) resolvePreferAvailable(ctx context.Context, role ModelRole, level ModelLevel) (selectedModel, error) {
+// tokenHeadroom is how much a candidate's remaining token budget must exceed
+// the request estimate to count as comfortably available.
+const tokenHeadroom = 1.5
+
+// resolvePreferAvailable picks, among candidates whose remaining tokens
+// comfortably cover need, the one with the most remaining. When none
+// qualify it falls back to the candidate with the best availability score.
+func (m *modelSelecting) resolvePreferAvailable(ctx context.Context, role ModelRole, level ModelLevel, need int) (selectedModel, error) {
 	candidates := m.registry.Candidates(role, level)
 	if len(candidates) == 0 {
 		return selectedModel{}, fmt.Errorf("%w: role=%s level=%s", ErrModelNotRegistered, role, level)
 	}
+	candidates = m.registry.withoutOpenCircuits(candidates)
 
-	bestIdx := 0
-	bestScore := math.Inf(-1)
+	bestIdx, fitIdx := 0, -1
+	bestScore, fitRemaining := math.Inf(-1), -1
 	for i, entry := range candidates {
 		sel, err := m.getOrCreateSelected(ctx, role, level, entry)
 		if err != nil {
@@ -249,10 +309,84 @@ func (m *modelSelecting) resolvePreferAvailable(ctx context.Context, role ModelR
 			bestScore = score
 			bestIdx = i
 		}
+		if remaining, ok := remainingTokens(sel.client); ok && float64(remaining) >= float64(need)*tokenHeadroom && remaining > fitRemaining {
+			fitRemaining = remaining
+			fitIdx = i
+		}
+	}
+	if fitIdx >= 0 {
+		bestIdx = fitIdx
 	}
 	return m.getOrCreateSelected(ctx, role, level, candidates[bestIdx])
 }
 
+// resolveWeightedRoundRobin walks an atomic cursor over the candidates' summed
+// weights, so each candidate receives a share of requests matching its weight.
+func (m *modelSelecting) resolveWeightedRoundRobin(ctx context.Context, role ModelRole, level ModelLevel) (selectedModel, error) {
+	candidates := m.registry.Candidates(role, level)
+	if len(candidates) == 0 {
+		return selectedModel{}, fmt.Errorf("%w: role=%s level=%s", ErrModelNotRegistered, role, level)
+	}
+	candidates = m.registry.withoutOpenCircuits(candidates)
+	if len(candidates) == 1 {
+		return m.getOrCreateSelected(ctx, role, level, candidates[0])
+	}
+
+	weights := make([]uint64, len(candidates))
+	var total uint64
+	for i, entry := range candidates {
+		weights[i] = selectionWeight(entry.Profile)
+		total += weights[i]
+	}
+	slot := (m.cursor.Add(1) - 1) % total
+	for i, w := range weights {
+		if slot < w {
+			return m.getOrCreateSelected(ctx, role, level, candidates[i])
+		}
+		slot -= w
+	}
+	return m.getOrCreateSelected(ctx, role, level, candidates[len(candidates)-1])
+}
+
+// selectionWeight is a candidate's RPM, or its TPM expressed in requests of
+// ~1000 tokens when only a token limit is configured. Unlimited or unknown
+// models get weight 1.
+func selectionWeight(p ModelProfile) uint64 {
+	if p.RateLimit == nil {
+		return 1
+	}
+	if p.RateLimit.RPM > 0 {
+		return uint64(p.RateLimit.RPM)
+	}
+	if p.RateLimit.TPM >= 1000 {
+		return uint64(p.RateLimit.TPM / 1000)
+	}
+	return 1
+}
+
+// estimateTokens approximates the request size from the prompt and input.
+func (m *modelSelecting) estimateTokens(prompt string, input any) int {
+	n := m.next.CountTokens(prompt)
+	if input != nil {
+		if b, err := json.Marshal(input); err == nil {
+			n += m.next.CountTokens(string(b))
+		}
+	}
+	return n
+}
+
+func remainingTokens(cli llmclient.LLMClient) (int, bool) {
+	aware, ok := cli.(llmclient.RateLimitHeaderAwareClient)
+	if !ok {
+		return 0, false
+	}
+	h, ok := aware.LastRateLimitHeaders()
+	if !ok || h.RemainingTokens <= 0 {
+		return 0, false
+	}
+	return h.RemainingTokens, true
+}
+
 func availabilityScore(cli llmclient.LLMClient) float64 {
 	aware, ok := cli.(llmclient.RateLimitHeaderAwareClient)
 	if !ok {
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	llmclient "insightify/internal/llm/client"
+	llmmiddleware "insightify/internal/llm/middleware"
+)
+
+func newOverrideTestRegistry(t *testing.T) *InMemoryModelRegistry {
+	t.Helper()
+	reg := NewInMemoryModelRegistry()
+	for _, m := range []struct {
+		name  string
+		level llmclient.ModelLevel
+	}{
+		{"low-model", llmclient.ModelLevelLow},
+		{"high-model", llmclient.ModelLevelHigh},
+		{"pinned-model", llmclient.ModelLevelMiddle},
+	} {
+		name := m.name
+		err := reg.RegisterModel(llmclient.ModelRegistration{
+			Provider: "test",
+			Model:    name,
+			Level:    m.level,
+			Factory: func(ctx context.Context, tokenCap int) (llmclient.LLMClient, error) {
+				return &awareTestLLM{name: name, tokenCap: 1024}, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+	}
+	return reg
+}
+
+func selectedModelName(t *testing.T, cli llmclient.LLMClient, phase string, level ModelLevel) string {
+	t.Helper()
+	ctx := WithModelSelection(llmmiddleware.WithPhase(context.Background(), phase), ModelRoleWorker, level, "", "")
+	raw, err := cli.GenerateJSON(ctx, "p", nil)
+	if err != nil {
+		t.Fatalf("GenerateJSON(%s): %v", phase, err)
+	}
+	return string(raw)
+}
+
+func TestSelectModel_PhaseOverrideChangesResolvedLevel(t *testing.T) {
+	reg := newOverrideTestRegistry(t)
+	overrides, err := ParseModelOverrides([]byte(`{
+		"*": {"level": "low"},
+		"bootstrap": {"level": "HIGH"},
+		"code_roots": {"provider": "Test", "model": "pinned-model"}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseModelOverrides() error = %v", err)
+	}
+	if err := reg.SetModelOverrides(overrides); err != nil {
+		t.Fatalf("SetModelOverrides() error = %v", err)
+	}
+	cli := llmmiddleware.Wrap(NewModelDispatchClient(nil), SelectModel(reg, 1024, ""))
+
+	if got := selectedModelName(t, cli, "bootstrap", ModelLevelLow); !strings.Contains(got, "high-model") {
+		t.Fatalf("bootstrap override should select high-model, got %s", got)
+	}
+	if got := selectedModelName(t, cli, "code_specs", ModelLevelHigh); !strings.Contains(got, "low-model") {
+		t.Fatalf("wildcard override should select low-model, got %s", got)
+	}
+	if got := selectedModelName(t, cli, "code_roots", ModelLevelHigh); !strings.Contains(got, "pinned-model") {
+		t.Fatalf("code_roots override should pin pinned-model, got %s", got)
+	}
+
+	if err := reg.SetModelOverrides(nil); err != nil {
+		t.Fatalf("clear overrides: %v", err)
+	}
+	cli = llmmiddleware.Wrap(NewModelDispatchClient(nil), SelectModel(reg, 1024, ""))
+	if got := selectedModelName(t, cli, "bootstrap", ModelLevelLow); !strings.Contains(got, "low-model") {
+		t.Fatalf("without overrides bootstrap should keep its level, got %s", got)
+	}
+}
+
+func TestModelOverrides_Validation(t *testing.T) {
+	for name, raw := range map[string]string{
+		"unknown field":  `{"bootstrap": {"tier": "low"}}`,
+		"invalid level":  `{"bootstrap": {"level": "huge"}}`,
+		"half pinned":    `{"bootstrap": {"provider": "test"}}`,
+		"empty override": `{"bootstrap": {}}`,
+	} {
+		if _, err := ParseModelOverrides([]byte(raw)); err == nil {
+			t.Fatalf("%s: expected parse error", name)
+		}
+	}
+
+	overrides, err := ParseModelOverrides([]byte(`{"*": {"level": "low"}, "bootstarp": {"level": "high"}}`))
+	if err != nil {
+		t.Fatalf("ParseModelOverrides() error = %v", err)
+	}
+	err = overrides.ValidatePhases([]string{"bootstrap", "code_roots"})
+	if err == nil || !strings.Contains(err.Error(), "bootstarp") {
+		t.Fatalf("expected unknown phase error, got %v", err)
+	}
+
+	reg := newOverrideTestRegistry(t)
+	pinned, err := ParseModelOverrides([]byte(`{"bootstrap": {"provider": "test", "model": "missing"}}`))
+	if err != nil {
+		t.Fatalf("ParseModelOverrides() error = %v", err)
+	}
+	if err := reg.SetModelOverrides(pinned); err == nil {
+		t.Fatalf("expected unregistered pinned model to be rejected")
+	}
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"testing"
+
+	llmclient "insightify/internal/llm/client"
+	llmmiddleware "insightify/internal/llm/middleware"
+)
+
+func newGroqTestRegistry(t *testing.T, tier string) *InMemoryModelRegistry {
+	t.Helper()
+	reg := NewInMemoryModelRegistry()
+	reg.SetLimiterRegistry(llmmiddleware.NewLimiterRegistry())
+	if err := llmclient.RegisterGroqModelsForTier(reg, tier); err != nil {
+		t.Fatalf("register groq %s: %v", tier, err)
+	}
+	return reg
+}
+
+func TestList_IncludesGroqLimits(t *testing.T) {
+	reg := newGroqTestRegistry(t, "developer")
+
+	models := reg.List()
+	if len(models) == 0 {
+		t.Fatalf("expected registered models")
+	}
+	var found bool
+	for i, m := range models {
+		if i > 0 {
+			prev := models[i-1].Profile
+			if prev.Provider > m.Profile.Provider || (prev.Provider == m.Profile.Provider && prev.Model >= m.Profile.Model) {
+				t.Fatalf("not sorted: %s before %s", prev.Name, m.Profile.Name)
+			}
+		}
+		if m.Profile.Provider != "groq" || m.Profile.Tier != "developer" {
+			t.Fatalf("unexpected profile: %+v", m.Profile)
+		}
+		if m.Profile.Model != "allam-2-7b" {
+			continue
+		}
+		found = true
+		if m.Profile.Level != ModelLevelLow || m.Profile.MaxTokens != 6000 {
+			t.Fatalf("allam-2-7b profile: %+v", m.Profile)
+		}
+		// developer tier triples the free-tier limits.
+		rl := m.Profile.RateLimit
+		if rl == nil || rl.RPM != 90 || rl.RPD != 21_000 || rl.TPM != 18_000 || rl.TPD != 1_500_000 {
+			t.Fatalf("allam-2-7b rate limit: %+v", rl)
+		}
+		if m.Profile.Meta["params"] != 7_000_000_000 {
+			t.Fatalf("allam-2-7b meta: %+v", m.Profile.Meta)
+		}
+	}
+	if !found {
+		t.Fatalf("allam-2-7b not listed")
+	}
+}
+
+func TestListByRoleLevel_DefaultFirstThenSorted(t *testing.T) {
+	reg := newGroqTestRegistry(t, "free")
+	if err := reg.SetDefault(ModelRoleWorker, ModelLevelMiddle, "groq", "qwen/qwen3-32b"); err != nil {
+		t.Fatalf("set default: %v", err)
+	}
+
+	models := reg.ListByRoleLevel(ModelRoleWorker, ModelLevelMiddle)
+	if len(models) < 3 {
+		t.Fatalf("expected several middle models, got %d", len(models))
+	}
+	if models[0].Profile.Model != "qwen/qwen3-32b" {
+		t.Fatalf("default should come first, got %s", models[0].Profile.Model)
+	}
+	for i := 2; i < len(models); i++ {
+		if models[i-1].Profile.Model >= models[i].Profile.Model {
+			t.Fatalf("not sorted after default: %s before %s", models[i-1].Profile.Model, models[i].Profile.Model)
+		}
+	}
+	if got := reg.ListByRoleLevel(ModelRoleWorker, "bogus"); got != nil {
+		t.Fatalf("unknown level should list nothing, got %d", len(got))
+	}
+}
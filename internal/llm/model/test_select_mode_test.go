@@ -3,7 +3,10 @@ package model
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	llmclient "insightify/internal/llm/client"
 	llmmiddleware "insightify/internal/llm/middleware"
@@ -98,3 +101,273 @@ func TestSelectModel_DefaultModePrefersAvailableHeaders(t *testing.T) {
 		t.Fatalf("unexpected model: %s", string(raw))
 	}
 }
+
+func TestSelectModel_PreferAvailableConsidersRequestSize(t *testing.T) {
+	reg := NewInMemoryModelRegistry()
+	register := func(provider, model string, headers llmclient.RateLimitHeaders) {
+		t.Helper()
+		err := reg.RegisterModel(llmclient.ModelRegistration{
+			Provider: provider,
+			Model:    model,
+			Level:    llmclient.ModelLevelMiddle,
+			Factory: func(ctx context.Context, tokenCap int) (llmclient.LLMClient, error) {
+				return &awareTestLLM{name: provider + ":" + model, tokenCap: 4096, headers: headers, has: true}, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("register %s:%s: %v", provider, model, err)
+		}
+	}
+	// "tokens" reports a token budget; "requests" only reports request counts,
+	// so its raw availability score is higher but its token budget is unknown.
+	register("a", "tokens", llmclient.RateLimitHeaders{RemainingTokens: 1200})
+	register("b", "requests", llmclient.RateLimitHeaders{RemainingRequests: 9000})
+
+	client := llmmiddleware.Wrap(NewModelDispatchClient(&awareTestLLM{name: "fallback", tokenCap: 4096}),
+		SelectModel(reg, 4096, ModelSelectionModePreferAvailable),
+	)
+	ctx := WithModelSelection(context.Background(), ModelRoleWorker, ModelLevelMiddle, "", "")
+
+	cases := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		// ~10 tokens: comfortably within the 1200 known remaining tokens.
+		{name: "small", prompt: strings.Repeat("x", 30), want: `{"model":"a:tokens"}`},
+		// ~1000 tokens: 1200 is not comfortable, fall back to the best score.
+		{name: "large", prompt: strings.Repeat("x", 3000), want: `{"model":"b:requests"}`},
+	}
+	for _, tc := range cases {
+		raw, err := client.GenerateJSON(ctx, tc.prompt, nil)
+		if err != nil {
+			t.Fatalf("%s: generate: %v", tc.name, err)
+		}
+		if string(raw) != tc.want {
+			t.Fatalf("%s: got %s, want %s", tc.name, raw, tc.want)
+		}
+	}
+}
+
+func TestSelectModel_WeightedRoundRobinFollowsConfiguredLimits(t *testing.T) {
+	reg := NewInMemoryModelRegistry()
+	// Keep the seeded limits out of the process-wide limiter registry.
+	reg.SetLimiterRegistry(llmmiddleware.NewLimiterRegistry())
+	register := func(provider, model string, limit *llmclient.RateLimitConfig) {
+		t.Helper()
+		err := reg.RegisterModel(llmclient.ModelRegistration{
+			Provider:  provider,
+			Model:     model,
+			Level:     llmclient.ModelLevelMiddle,
+			RateLimit: limit,
+			Factory: func(ctx context.Context, tokenCap int) (llmclient.LLMClient, error) {
+				return &awareTestLLM{name: provider + ":" + model, tokenCap: 4096}, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("register %s:%s: %v", provider, model, err)
+		}
+	}
+	register("a", "rpm30", &llmclient.RateLimitConfig{RPM: 30})
+	register("b", "rpm10", &llmclient.RateLimitConfig{RPM: 10})
+	register("c", "tpm20k", &llmclient.RateLimitConfig{TPM: 20000})
+
+	client := llmmiddleware.Wrap(NewModelDispatchClient(&awareTestLLM{name: "fallback", tokenCap: 4096}),
+		SelectModel(reg, 4096, ModelSelectionModeWeightedRoundRobin),
+	)
+	ctx := WithModelSelection(context.Background(), ModelRoleWorker, ModelLevelMiddle, "", "")
+
+	counts := map[string]int{}
+	const calls = 600
+	for i := 0; i < calls; i++ {
+		raw, err := client.GenerateJSON(ctx, "p", nil)
+		if err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+		counts[string(raw)]++
+	}
+	want := map[string]int{
+		`{"model":"a:rpm30"}`:  300,
+		`{"model":"b:rpm10"}`:  100,
+		`{"model":"c:tpm20k"}`: 200,
+	}
+	for model, n := range want {
+		if got := counts[model]; got < n*9/10 || got > n*11/10 {
+			t.Fatalf("%s: got %d calls, want ~%d (all: %v)", model, got, n, counts)
+		}
+	}
+}
+
+func TestSelectModel_WeightedRoundRobinSingleCandidate(t *testing.T) {
+	reg := NewInMemoryModelRegistry()
+	err := reg.RegisterModel(llmclient.ModelRegistration{
+		Provider: "a",
+		Model:    "only",
+		Level:    llmclient.ModelLevelMiddle,
+		Factory: func(ctx context.Context, tokenCap int) (llmclient.LLMClient, error) {
+			return &awareTestLLM{name: "a:only", tokenCap: 4096}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	client := llmmiddleware.Wrap(NewModelDispatchClient(&awareTestLLM{name: "fallback", tokenCap: 4096}),
+		SelectModel(reg, 4096, ModelSelectionModeWeightedRoundRobin),
+	)
+	ctx := WithModelSelection(context.Background(), ModelRoleWorker, ModelLevelMiddle, "", "")
+	for i := 0; i < 5; i++ {
+		raw, err := client.GenerateJSON(ctx, "p", nil)
+		if err != nil || string(raw) != `{"model":"a:only"}` {
+			t.Fatalf("call %d: got %s, %v", i, raw, err)
+		}
+	}
+}
+
+func TestSelectModel_SkipsModelsWithOpenCircuit(t *testing.T) {
+	reg := NewInMemoryModelRegistry()
+	reg.SetLimiterRegistry(llmmiddleware.NewLimiterRegistry())
+	breaker := llmmiddleware.NewCircuitBreaker(1, time.Minute)
+	reg.SetCircuitBreaker(breaker)
+
+	for _, m := range []struct {
+		provider, model string
+		fail            bool
+	}{
+		{provider: "a", model: "m-flaky", fail: true},
+		{provider: "b", model: "m-steady"},
+	} {
+		m := m
+		err := reg.RegisterModel(llmclient.ModelRegistration{
+			Provider: m.provider,
+			Model:    m.model,
+			Level:    llmclient.ModelLevelMiddle,
+			Factory: func(ctx context.Context, tokenCap int) (llmclient.LLMClient, error) {
+				return &flakyTestLLM{awareTestLLM: awareTestLLM{name: m.provider + ":" + m.model, tokenCap: 1024}, fail: m.fail}, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("register %s:%s: %v", m.provider, m.model, err)
+		}
+	}
+	if err := reg.SetDefault(ModelRoleWorker, ModelLevelMiddle, "a", "m-flaky"); err != nil {
+		t.Fatalf("set default: %v", err)
+	}
+
+	client := llmmiddleware.Wrap(NewModelDispatchClient(&awareTestLLM{name: "fallback", tokenCap: 4096}),
+		SelectModel(reg, 4096, ModelSelectionModePreferAvailable),
+		breaker.Middleware(),
+	)
+	ctx := WithModelSelection(context.Background(), ModelRoleWorker, ModelLevelMiddle, "", "")
+
+	if _, err := client.GenerateJSON(ctx, "p", nil); err == nil {
+		t.Fatalf("expected first call on the flaky model to fail")
+	}
+	raw, err := client.GenerateJSON(ctx, "p", nil)
+	if err != nil {
+		t.Fatalf("expected fallback to the steady model, got %v", err)
+	}
+	if string(raw) != `{"model":"b:m-steady"}` {
+		t.Fatalf("unexpected model: %s", raw)
+	}
+}
+
+type flakyTestLLM struct {
+	awareTestLLM
+	fail bool
+}
+
+func (t *flakyTestLLM) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	if t.fail {
+		return nil, errors.New("unexpected status 500")
+	}
+	return t.awareTestLLM.GenerateJSON(ctx, prompt, input)
+}
+
+// throttledTestLLM reports headers to its handler on every call, as provider
+// clients do after parsing a response.
+type throttledTestLLM struct {
+	awareTestLLM
+	handler llmclient.RateLimitHeaderHandler
+}
+
+func (t *throttledTestLLM) SetRateLimitHeaderHandler(handler llmclient.RateLimitHeaderHandler) {
+	t.handler = handler
+}
+func (t *throttledTestLLM) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	if t.handler != nil {
+		t.handler(t.headers)
+	}
+	return t.awareTestLLM.GenerateJSON(ctx, prompt, input)
+}
+
+// retryAfterMsAdapter reads RetryAfterSeconds as milliseconds.
+type retryAfterMsAdapter struct{}
+
+func (retryAfterMsAdapter) NextWait(h llmclient.RateLimitHeaders) time.Duration {
+	return time.Duration(h.RetryAfterSeconds) * time.Millisecond
+}
+
+func TestSelectModel_ProviderRateLimitSignalDelaysSiblingModel(t *testing.T) {
+	reg := NewInMemoryModelRegistry()
+	reg.SetLimiterRegistry(llmmiddleware.NewLimiterRegistry())
+	register := func(provider, model string, headers llmclient.RateLimitHeaders) {
+		t.Helper()
+		err := reg.RegisterModel(llmclient.ModelRegistration{
+			Provider: provider,
+			Model:    model,
+			Level:    llmclient.ModelLevelMiddle,
+			Factory: func(ctx context.Context, tokenCap int) (llmclient.LLMClient, error) {
+				return &throttledTestLLM{awareTestLLM: awareTestLLM{name: provider + ":" + model, tokenCap: 4096, headers: headers, has: true}}, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("register %s:%s: %v", provider, model, err)
+		}
+	}
+	// groq:limited answers with a 429-style retry-after; its siblings report nothing.
+	register("groq", "limited", llmclient.RateLimitHeaders{RetryAfterSeconds: 200})
+	register("groq", "sibling", llmclient.RateLimitHeaders{})
+	register("gemini", "other", llmclient.RateLimitHeaders{})
+
+	client := llmmiddleware.Wrap(NewModelDispatchClient(&awareTestLLM{name: "fallback", tokenCap: 4096}),
+		SelectModel(reg, 4096, ModelSelectionModePreferAvailable),
+		llmmiddleware.RespectRateLimitSignals(retryAfterMsAdapter{}),
+	)
+	call := func(provider, model string) time.Duration {
+		t.Helper()
+		ctx := WithModelSelection(context.Background(), ModelRoleWorker, ModelLevelMiddle, provider, model)
+		start := time.Now()
+		raw, err := client.GenerateJSON(ctx, "p", nil)
+		if err != nil {
+			t.Fatalf("generate %s:%s: %v", provider, model, err)
+		}
+		if want := `{"model":"` + provider + ":" + model + `"}`; string(raw) != want {
+			t.Fatalf("got %s, want %s", raw, want)
+		}
+		return time.Since(start)
+	}
+
+	call("groq", "limited")
+	if d := call("gemini", "other"); d > 100*time.Millisecond {
+		t.Fatalf("another provider waited %s", d)
+	}
+	if d := call("groq", "sibling"); d < 150*time.Millisecond {
+		t.Fatalf("sibling of the throttled model waited only %s", d)
+	}
+}
+
+func TestParseModelSelectionMode(t *testing.T) {
+	for raw, want := range map[string]ModelSelectionMode{
+		"":                       ModelSelectionModePreferAvailable,
+		" Weighted_Round_Robin ": ModelSelectionModeWeightedRoundRobin,
+		"prefer_available":       ModelSelectionModePreferAvailable,
+	} {
+		got, err := ParseModelSelectionMode(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseModelSelectionMode(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseModelSelectionMode("round_robin"); err == nil || !strings.Contains(err.Error(), "LLM_SELECTION_MODE") {
+		t.Fatalf("unknown mode error = %v, want LLM_SELECTION_MODE rejected", err)
+	}
+}
@@ -146,6 +146,9 @@ func (f *FakeClient) GenerateJSON(ctx context.Context, prompt string, input any)
 }
 
 func (f *FakeClient) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return f.GenerateJSON(ctx, prompt, input)
 }
 
@@ -189,6 +192,7 @@ func RegisterFakeModels(reg llmclient.ModelRegistrar) error {
 			MaxTokens: tokens,
 			Meta:      meta,
 			RateLimit: m.limit,
+			Pricing:   &llmclient.Pricing{},
 			Factory: func(ctx context.Context, tokenCap int) (llmclient.LLMClient, error) {
 				_ = ctx
 				if tokenCap <= 0 {
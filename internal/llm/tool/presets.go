@@ -49,3 +49,8 @@ func PresetCautious() PromptPreset {
 		},
 	}
 }
+
+// RegenHintRule is the rule of prompts whose phase retries a rejected output
+// with a 'regen_hint' input naming the issue. It is a plain rule rather than
+// a preset so it keeps its place among the phase's own rules.
+const RegenHintRule = "If 'regen_hint' is present, the previous output was rejected; correct exactly the issue it names."
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"insightify/internal/artifact"
+	"insightify/internal/common/fileclass"
 	"insightify/internal/common/safeio"
 )
 
@@ -20,7 +21,7 @@ func newFSReadTool(h Host) *fsReadTool { return &fsReadTool{host: h} }
 func (t *fsReadTool) Spec() artifact.ToolSpec {
 	return artifact.ToolSpec{
 		Name:        "fs.read",
-		Description: "Read a file (or a slice) from the repo, with size limits.",
+		Description: "Read a file (or a slice) from the repo, with size limits. Binary, generated and vendored files are skipped unless force is true.",
 	}
 }
 
@@ -28,11 +29,16 @@ type fsReadInput struct {
 	Path   string `json:"path"`
 	Start  int64  `json:"start"`
 	Length int64  `json:"length"`
+	// Force reads a file even if it is binary, generated or vendored.
+	Force bool `json:"force"`
 }
 
 type fsReadOutput struct {
 	Path    string `json:"path"`
 	Content string `json:"content"`
+	// Skipped names the flags of a file left unread, e.g. is_binary.
+	Skipped []string `json:"skipped,omitempty"`
+	Note    string   `json:"note,omitempty"`
 }
 
 func (t *fsReadTool) Call(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
@@ -53,6 +59,19 @@ func (t *fsReadTool) Call(ctx context.Context, input json.RawMessage) (json.RawM
 	if fs == nil {
 		return nil, fmt.Errorf("fs.read: repo fs not configured")
 	}
+	if !in.Force {
+		flags, err := fileclass.Classify(fs, in.Path)
+		if err != nil {
+			return nil, err
+		}
+		if flags = flags.WithPath(in.Path); flags.Flagged() {
+			return json.Marshal(fsReadOutput{
+				Path:    in.Path,
+				Skipped: flags.Names(),
+				Note:    "file skipped as " + strings.Join(flags.Names(), ", ") + "; call fs.read with force=true to read it anyway",
+			})
+		}
+	}
 	f, err := fs.SafeOpen(in.Path)
 	if err != nil {
 		return nil, err
@@ -20,7 +20,7 @@ func newSnippetCollectTool(h Host) *snippetCollectTool { return &snippetCollectT
 func (t *snippetCollectTool) Spec() artifact.ToolSpec {
 	return artifact.ToolSpec{
 		Name:        "snippet.collect",
-		Description: "Collect related code snippets for identifiers (uses existing codebase artifacts).",
+		Description: "Collect related code snippets for identifiers (uses existing codebase artifacts; files not yet indexed fall back to approximate line heuristics, marked approximate).",
 	}
 }
 
@@ -39,6 +39,8 @@ type snippetOut struct {
 	Provider string `json:"provider"`
 	Tokens   int    `json:"tokens"`
 	Code     string `json:"code"`
+	// Approximate is set for heuristic snippets whose bounds may be off.
+	Approximate bool `json:"approximate,omitempty"`
 }
 
 func (t *snippetCollectTool) Call(ctx context.Context, input json.RawMessage) (json.RawMessage, error) {
@@ -49,11 +51,15 @@ func (t *snippetCollectTool) Call(ctx context.Context, input json.RawMessage) (j
 	if len(in.Seeds) == 0 {
 		return nil, fmt.Errorf("snippet.collect: seeds required")
 	}
+	var indexed snippet.Provider
 	codeSymbols, err := loadCodeSymbolsOut(t.host)
-	if err != nil {
+	switch {
+	case err == nil:
+		indexed = codebase.NewCodeSymbolsSnippetProvider(t.host.RepoRoot, codeSymbols)
+	case t.host.RepoFS == nil:
 		return nil, err
 	}
-	provider := codebase.NewCodeSymbolsSnippetProvider(t.host.RepoRoot, codeSymbols)
+	provider := snippet.WithFallback(indexed, snippet.NewHeuristicProvider(t.host.RepoFS))
 	outSnips, err := provider.Collect(ctx, snippet.Query{
 		Seeds:     in.Seeds,
 		MaxTokens: in.MaxTokens,
@@ -64,11 +70,12 @@ func (t *snippetCollectTool) Call(ctx context.Context, input json.RawMessage) (j
 	out := snippetCollectOutput{Snippets: make([]snippetOut, 0, len(outSnips))}
 	for _, s := range outSnips {
 		out.Snippets = append(out.Snippets, snippetOut{
-			Path:     s.Identifier.Path,
-			Name:     s.Identifier.Name,
-			Provider: s.Provider,
-			Tokens:   s.Tokens,
-			Code:     s.Code,
+			Path:        s.Identifier.Path,
+			Name:        s.Identifier.Name,
+			Provider:    s.Provider,
+			Tokens:      s.Tokens,
+			Code:        s.Code,
+			Approximate: s.Approximate,
 		})
 	}
 	return json.Marshal(out)
@@ -88,4 +95,4 @@ func loadCodeSymbolsOut(h Host) (artifact.CodeSymbolsOut, error) {
 		return artifact.CodeSymbolsOut{}, fmt.Errorf("snippet.collect: decode code_symbols.json: %w", err)
 	}
 	return out, nil
-}
\ No newline at end of file
+}
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -100,6 +101,37 @@ func TestFSReadTool(t *testing.T) {
 	}
 }
 
+func TestFSReadToolSkipsFlaggedFiles(t *testing.T) {
+	repoRoot, repoFS, _ := setupRepo(t)
+	if err := os.MkdirAll(filepath.Join(repoRoot, "vendor"), 0o755); err != nil {
+		t.Fatalf("mkdir vendor: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, "vendor", "lib.go"), []byte("package lib\n"), 0o644); err != nil {
+		t.Fatalf("write vendor/lib.go: %v", err)
+	}
+	tool := newFSReadTool(Host{RepoRoot: repoRoot, RepoFS: repoFS})
+	read := func(in fsReadInput) fsReadOutput {
+		raw, _ := json.Marshal(in)
+		outRaw, err := tool.Call(context.Background(), raw)
+		if err != nil {
+			t.Fatalf("fs.read call: %v", err)
+		}
+		var out fsReadOutput
+		if err := json.Unmarshal(outRaw, &out); err != nil {
+			t.Fatalf("fs.read decode: %v", err)
+		}
+		return out
+	}
+	out := read(fsReadInput{Path: "vendor/lib.go"})
+	if out.Content != "" || len(out.Skipped) != 1 || out.Skipped[0] != "is_vendored" || !strings.Contains(out.Note, "force=true") {
+		t.Fatalf("expected vendored file to be skipped, got %+v", out)
+	}
+	out = read(fsReadInput{Path: "vendor/lib.go", Force: true})
+	if out.Content != "package lib\n" || len(out.Skipped) != 0 {
+		t.Fatalf("expected force to read the file, got %+v", out)
+	}
+}
+
 func TestWordIdxSearchTool(t *testing.T) {
 	repoRoot, repoFS, _ := setupRepo(t)
 	data := "hello world\nhello again\n"
@@ -281,3 +313,41 @@ func TestGitHubCloneTool_IfExistsSkipAndPull(t *testing.T) {
 		}
 	}
 }
+
+func TestWordIdxSearchToolModes(t *testing.T) {
+	repoRoot, repoFS, _ := setupRepo(t)
+	if err := os.WriteFile(filepath.Join(repoRoot, "svc.ts"), []byte("export class AuthService {}\n"), 0o644); err != nil {
+		t.Fatalf("write svc.ts: %v", err)
+	}
+	tool := newWordIdxSearchTool(Host{RepoRoot: repoRoot, RepoFS: repoFS})
+	search := func(mode string) int {
+		raw, _ := json.Marshal(wordIdxInput{Roots: []string{"."}, Word: "auth_service", Mode: mode})
+		outRaw, err := tool.Call(context.Background(), raw)
+		if err != nil {
+			t.Fatalf("wordidx.search mode=%q: %v", mode, err)
+		}
+		var out wordIdxOutput
+		if err := json.Unmarshal(outRaw, &out); err != nil {
+			t.Fatalf("wordidx.search decode: %v", err)
+		}
+		return len(out.Matches)
+	}
+	if n := search(""); n != 1 {
+		t.Fatalf("default identifier mode matches = %d, want 1", n)
+	}
+	if n := search("exact"); n != 0 {
+		t.Fatalf("exact mode matches = %d, want 0", n)
+	}
+	raw, _ := json.Marshal(wordIdxInput{Roots: []string{"."}, Word: "x", Mode: "regex"})
+	if _, err := tool.Call(context.Background(), raw); err == nil {
+		t.Fatalf("expected error for unknown mode")
+	}
+
+	// An index that never finishes building is an error, not zero matches.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	raw, _ = json.Marshal(wordIdxInput{Roots: []string{"."}, Word: "auth_service"})
+	if _, err := tool.Call(ctx, raw); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled search error = %v, want context.Canceled", err)
+	}
+}
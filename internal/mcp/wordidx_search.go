@@ -19,7 +19,7 @@ func newWordIdxSearchTool(h Host) *wordIdxSearchTool { return &wordIdxSearchTool
 func (t *wordIdxSearchTool) Spec() artifact.ToolSpec {
 	return artifact.ToolSpec{
 		Name:        "wordidx.search",
-		Description: "Search for exact word occurrences under repo roots using the word index.",
+		Description: "Search for word occurrences under repo roots using the word index. mode is exact, case_insensitive, prefix or identifier (default; matches camelCase/snake_case variants and sub-tokens).",
 	}
 }
 
@@ -28,6 +28,7 @@ type wordIdxInput struct {
 	Word       string   `json:"word"`
 	AllowExt   []string `json:"allow_ext"`
 	MaxResults int      `json:"max_results"`
+	Mode       string   `json:"mode"`
 }
 
 type wordIdxOutput struct {
@@ -51,11 +52,19 @@ func (t *wordIdxSearchTool) Call(ctx context.Context, input json.RawMessage) (js
 	if in.MaxResults <= 0 {
 		in.MaxResults = 200
 	}
+	mode := wordidx.MatchIdentifier
+	if strings.TrimSpace(in.Mode) != "" {
+		m, err := wordidx.ParseMatchMode(in.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("wordidx.search: %w", err)
+		}
+		mode = m
+	}
 	roots := make([]string, 0, len(in.Roots))
 	for _, r := range in.Roots {
 		roots = append(roots, resolveRepoPath(t.host.RepoRoot, r))
 	}
-	builder := wordidx.New().Root(roots...).FS(t.host.RepoFS)
+	builder := wordidx.New().Root(roots...).FS(t.host.RepoFS).Fuzzy(mode != wordidx.MatchExact)
 	if len(in.AllowExt) > 0 {
 		exts := make([]string, 0, len(in.AllowExt))
 		for _, e := range in.AllowExt {
@@ -70,7 +79,10 @@ func (t *wordIdxSearchTool) Call(ctx context.Context, input json.RawMessage) (js
 		}
 	}
 	agg := builder.Start(ctx)
-	matches := agg.Find(ctx, in.Word)
+	matches, err := agg.FindMode(ctx, in.Word, mode)
+	if err != nil {
+		return nil, fmt.Errorf("wordidx.search: %w", err)
+	}
 	out := wordIdxOutput{Matches: make([]wordMatch, 0, len(matches))}
 	for _, m := range matches {
 		out.Matches = append(out.Matches, wordMatch{Path: m.FilePath, Line: m.Line, Column: 0})
@@ -0,0 +1,214 @@
+// Package mdcondense shrinks markdown documents for LLM input without calling
+// a model. It keeps every heading with the first paragraph under it, the
+// first lines of code fences and the header row of tables, and drops badges,
+// link reference definitions and license boilerplate. Headings are kept
+// verbatim, including explicit {#anchor} suffixes, so the model can still
+// cite sections.
+package mdcondense
+
+import (
+	"regexp"
+	"strings"
+
+	"insightify/internal/common/utils"
+)
+
+// DefaultCodeLines is how many lines of each code fence are kept when
+// Options.CodeLines is zero.
+const DefaultCodeLines = 8
+
+type Options struct {
+	// MaxTokens caps the condensed document; zero disables the cap.
+	MaxTokens int
+	// CodeLines is how many lines of each code fence are kept.
+	CodeLines int
+	// CountTokens measures text against MaxTokens, normally the LLM client's
+	// CountTokens. Nil estimates four bytes per token.
+	CountTokens func(string) int
+}
+
+var (
+	reATXHeading  = regexp.MustCompile(`^ {0,3}#{1,6}(\s|$)`)
+	reSetextUnder = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	reFence       = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+	reLinkRefDef  = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s*\S+`)
+	reTableSep    = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	reListItem    = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+`)
+	reRule        = regexp.MustCompile(`^ {0,3}(([-*_])\s*){3,}$`)
+	// reEmptyLink matches what is left of a badge once MarkDownClean removed
+	// its image: [](https://...).
+	reEmptyLink    = regexp.MustCompile(`\[\s*\]\([^)]*\)`)
+	reHTMLTag      = regexp.MustCompile(`<[^>]*>`)
+	reLicenseTitle = regexp.MustCompile(`(?i)^(licen[cs]es?|copyright|copying)$`)
+	reBoilerplate  = regexp.MustCompile(`(?i)^(copyright\s*(\(c\)|©|\d)|permission is hereby granted|the software is provided "as is"|licensed under the apache license|this program is free software|spdx-license-identifier)`)
+)
+
+// block is one kept unit of output; headings survive the token cap longest.
+type block struct {
+	text    string
+	heading bool
+}
+
+// Condense returns the condensed text of doc and whether MaxTokens cut
+// content beyond what condensation drops anyway.
+func Condense(doc string, opts Options) (string, bool) {
+	if opts.CodeLines <= 0 {
+		opts.CodeLines = DefaultCodeLines
+	}
+	if opts.CountTokens == nil {
+		opts.CountTokens = estimateTokens
+	}
+	blocks := condenseBlocks(strings.Split(utils.MarkDownClean(doc), "\n"), opts.CodeLines)
+	return capBlocks(blocks, opts.MaxTokens, opts.CountTokens)
+}
+
+func condenseBlocks(lines []string, codeLines int) []block {
+	var out []block
+	kept := false // first paragraph under the current heading already kept
+	inLicense := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+
+		case reFence.MatchString(line):
+			fence := strings.TrimSpace(reFence.FindStringSubmatch(line)[1])
+			body := []string{line}
+			n := 0
+			for i+1 < len(lines) {
+				i++
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				if n < codeLines {
+					body = append(body, lines[i])
+				} else if n == codeLines {
+					body = append(body, "...")
+				}
+				n++
+			}
+			body = append(body, fence)
+			if !inLicense {
+				out = append(out, block{text: strings.Join(body, "\n")})
+			}
+
+		case reATXHeading.MatchString(line):
+			out = append(out, block{text: trimmed, heading: true})
+			kept = false
+			inLicense = isLicenseTitle(trimmed)
+
+		case i+1 < len(lines) && reSetextUnder.MatchString(lines[i+1]) && !reListItem.MatchString(line) && !strings.HasPrefix(trimmed, "|"):
+			i++
+			out = append(out, block{text: trimmed + "\n" + strings.TrimSpace(lines[i]), heading: true})
+			kept = false
+			inLicense = isLicenseTitle(trimmed)
+
+		case reLinkRefDef.MatchString(line):
+			continue
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && reTableSep.MatchString(lines[i+1]):
+			header := trimmed + "\n" + strings.TrimSpace(lines[i+1])
+			i++
+			for i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "|") {
+				i++
+			}
+			if !inLicense {
+				out = append(out, block{text: header})
+			}
+
+		case reRule.MatchString(line):
+			continue
+
+		default:
+			var para []string
+			for j := i; j < len(lines); j++ {
+				if j > i && (strings.TrimSpace(lines[j]) == "" || startsBlock(lines, j)) {
+					break
+				}
+				i = j
+				// Lines left with only links to nowhere or bare HTML tags were
+				// badges or image wrappers.
+				l := strings.TrimRight(reEmptyLink.ReplaceAllString(lines[j], ""), " \t")
+				if strings.TrimSpace(reHTMLTag.ReplaceAllString(l, "")) != "" {
+					para = append(para, l)
+				}
+			}
+			if len(para) == 0 || kept || inLicense || reBoilerplate.MatchString(strings.TrimSpace(para[0])) {
+				continue
+			}
+			kept = true
+			out = append(out, block{text: strings.Join(para, "\n")})
+		}
+	}
+	return out
+}
+
+// startsBlock reports whether lines[i] opens a block other than a paragraph
+// continuation.
+func startsBlock(lines []string, i int) bool {
+	line := lines[i]
+	if reFence.MatchString(line) || reATXHeading.MatchString(line) || reLinkRefDef.MatchString(line) {
+		return true
+	}
+	if i+1 < len(lines) && reSetextUnder.MatchString(lines[i+1]) && !reListItem.MatchString(line) {
+		return true
+	}
+	return strings.HasPrefix(strings.TrimSpace(line), "|") && i+1 < len(lines) && reTableSep.MatchString(lines[i+1])
+}
+
+func isLicenseTitle(heading string) bool {
+	title := strings.TrimSpace(strings.TrimLeft(heading, "#"))
+	if j := strings.Index(title, "{#"); j >= 0 {
+		title = strings.TrimSpace(title[:j])
+	}
+	return reLicenseTitle.MatchString(title)
+}
+
+// capBlocks joins blocks and, when over maxTokens, drops body blocks from the
+// end first and headings last, so the outline survives as long as possible.
+func capBlocks(blocks []block, maxTokens int, count func(string) int) (string, bool) {
+	text := join(blocks)
+	if maxTokens <= 0 || count(text) <= maxTokens {
+		return text, false
+	}
+	keep := make([]bool, len(blocks))
+	for i := range keep {
+		keep[i] = true
+	}
+	fits := func() bool {
+		var sel []block
+		for i, b := range blocks {
+			if keep[i] {
+				sel = append(sel, b)
+			}
+		}
+		text = join(sel)
+		return count(text) <= maxTokens
+	}
+	for _, headings := range []bool{false, true} {
+		for i := len(blocks) - 1; i >= 0; i-- {
+			if blocks[i].heading != headings || !keep[i] {
+				continue
+			}
+			keep[i] = false
+			if fits() {
+				return text, true
+			}
+		}
+	}
+	return "", true
+}
+
+func join(blocks []block) string {
+	parts := make([]string, len(blocks))
+	for i, b := range blocks {
+		parts[i] = b.text
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
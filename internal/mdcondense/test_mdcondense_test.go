@@ -0,0 +1,129 @@
+package mdcondense
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readDoc(t *testing.T, name string) string {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(raw)
+}
+
+// headings lists ATX and setext heading titles in document order.
+func headings(doc string) []string {
+	var out []string
+	lines := strings.Split(doc, "\n")
+	inFence := false
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if reFence.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence || line == "" {
+			continue
+		}
+		if reATXHeading.MatchString(line) {
+			out = append(out, line)
+			continue
+		}
+		if i+1 < len(lines) && !strings.HasPrefix(line, "|") {
+			next := strings.TrimSpace(lines[i+1])
+			if len(next) >= 3 && (strings.Trim(next, "=") == "" || strings.Trim(next, "-") == "") {
+				out = append(out, line)
+			}
+		}
+	}
+	return out
+}
+
+func TestCondenseProjectReadme(t *testing.T) {
+	doc := readDoc(t, "project_readme.md")
+	got, truncated := Condense(doc, Options{})
+	if truncated {
+		t.Fatalf("no cap was set, truncated should be false")
+	}
+	if len(got)*2 > len(doc) {
+		t.Fatalf("expected at least 50%% reduction: %d -> %d bytes\n%s", len(doc), len(got), got)
+	}
+	wantHeadings := []string{"# Acme Gateway", "## Installation {#install}", "## Configuration", "Usage", "## License"}
+	if h := headings(got); !reflect.DeepEqual(h, wantHeadings) {
+		t.Fatalf("headings = %q, want %q", h, wantHeadings)
+	}
+	for _, want := range []string{
+		"Acme Gateway routes RPC traffic",          // first paragraph under a heading
+		"Install the binary with Go 1.22",          // first paragraph under a heading
+		"gateway logs --follow",                    // 8th line of the fence
+		"| Variable | Default | Description |",     // table header
+		"Start the server and open the dashboard.", // setext section
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("condensed doc lost %q:\n%s", want, got)
+		}
+	}
+	for _, drop := range []string{
+		"badge",                           // badges
+		"It grew out of an internal tool", // second paragraph
+		"gateway stop",                    // fence beyond CodeLines
+		"DATABASE_URL",                    // table rows
+		"[config]:",                       // link reference definition
+		"Permission is hereby granted",    // license boilerplate
+		"MIT License",                     // license section body
+		"TODO",                            // HTML comment
+		`<p align="center">`,              // image wrapper
+	} {
+		if strings.Contains(got, drop) {
+			t.Fatalf("condensed doc kept %q:\n%s", drop, got)
+		}
+	}
+}
+
+func TestCondenseDocsGuide(t *testing.T) {
+	doc := readDoc(t, "docs_guide.md")
+	got, _ := Condense(doc, Options{CodeLines: 3})
+	if len(got) >= len(doc) {
+		t.Fatalf("expected a size reduction: %d -> %d bytes", len(doc), len(got))
+	}
+	if h, want := headings(got), headings(doc); !reflect.DeepEqual(h, want) {
+		t.Fatalf("headings = %q, want %q", h, want)
+	}
+	if !strings.Contains(got, "if !ok {\n...\n```") {
+		t.Fatalf("fence should keep 3 lines and close:\n%s", got)
+	}
+	if strings.Contains(got, "Capacity is tracked per model") {
+		t.Fatalf("only the first paragraph per heading should be kept:\n%s", got)
+	}
+}
+
+func TestCondenseCapKeepsHeadingsLongest(t *testing.T) {
+	doc := readDoc(t, "docs_guide.md")
+	full, _ := Condense(doc, Options{})
+	words := func(s string) int { return len(strings.Fields(s)) }
+
+	limit := words(full) / 2
+	got, truncated := Condense(doc, Options{MaxTokens: limit, CountTokens: words})
+	if !truncated {
+		t.Fatalf("expected truncation at %d of %d tokens", limit, words(full))
+	}
+	if n := words(got); n > limit {
+		t.Fatalf("condensed doc has %d tokens, cap %d", n, limit)
+	}
+	if h, want := headings(got), headings(doc); !reflect.DeepEqual(h, want) {
+		t.Fatalf("cap should drop bodies before headings: got %q, want %q", h, want)
+	}
+	if !strings.Contains(got, "This guide explains") {
+		t.Fatalf("earliest body blocks should survive the cap:\n%s", got)
+	}
+
+	if got, truncated := Condense(doc, Options{MaxTokens: 1, CountTokens: words}); !truncated || got != "" {
+		t.Fatalf("nothing fits in 1 token, got %q truncated=%v", got, truncated)
+	}
+}
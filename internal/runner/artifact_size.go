@@ -0,0 +1,230 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"insightify/internal/common/logctx"
+)
+
+const (
+	// ArtifactWarnBytesEnv overrides DefaultArtifactWarnBytes.
+	ArtifactWarnBytesEnv = "ARTIFACT_WARN_BYTES"
+	// ArtifactMaxBytesEnv overrides DefaultArtifactMaxBytes for specs whose
+	// MaxArtifactBytes is zero.
+	ArtifactMaxBytesEnv = "ARTIFACT_MAX_BYTES"
+
+	// DefaultArtifactWarnBytes is the size above which a saved output is
+	// logged as a warning.
+	DefaultArtifactWarnBytes = 16 << 20
+	// DefaultArtifactMaxBytes is the size above which a save fails, or is
+	// split into parts for specs with ChunkedArtifact.
+	DefaultArtifactMaxBytes = 128 << 20
+)
+
+// ErrArtifactTooLarge matches errors of saves refused by the size guard.
+var ErrArtifactTooLarge = errors.New("artifact too large")
+
+// ArtifactTooLargeError reports an output above its phase's hard limit.
+type ArtifactTooLargeError struct {
+	Phase string
+	Size  int64
+	Limit int64
+}
+
+func (e *ArtifactTooLargeError) Error() string {
+	return fmt.Sprintf("phase %q output is %d bytes, above the %d byte limit", e.Phase, e.Size, e.Limit)
+}
+
+func (e *ArtifactTooLargeError) Is(target error) bool { return target == ErrArtifactTooLarge }
+
+// chunkIndexPrefix starts every chunk index, so readers can tell one from an
+// artifact without decoding it.
+const chunkIndexPrefix = `{"chunked_artifact":`
+
+// chunkIndex replaces an artifact split into parts. Parts are consecutive
+// byte ranges of the JSON document, not JSON on their own.
+type chunkIndex struct {
+	Version int      `json:"chunked_artifact"`
+	Size    int64    `json:"size"`
+	Digest  string   `json:"digest"`
+	Parts   []string `json:"parts"`
+}
+
+var partNameRE = regexp.MustCompile(`^(.+)\.part-(\d+)\.json$`)
+
+// PartName returns the name of part n of the chunked artifact name, e.g.
+// code_symbols.part-2.json for code_symbols.json.
+func PartName(name string, n int) string {
+	return fmt.Sprintf("%s.part-%d.json", strings.TrimSuffix(name, ".json"), n)
+}
+
+// artifactLimits returns the warning and hard size limits of spec; a hard
+// limit of 0 means none.
+func artifactLimits(spec WorkerSpec) (warn, hard int64) {
+	warn = envBytes(ArtifactWarnBytesEnv, DefaultArtifactWarnBytes)
+	switch {
+	case spec.MaxArtifactBytes > 0:
+		hard = spec.MaxArtifactBytes
+	case spec.MaxArtifactBytes == 0:
+		hard = envBytes(ArtifactMaxBytesEnv, DefaultArtifactMaxBytes)
+	}
+	return warn, hard
+}
+
+// envBytes parses key as a byte count; unset, invalid or negative values
+// give def, and 0 disables the limit.
+func envBytes(key string, def int64) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(os.Getenv(key)), 10, 64)
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+// encodeOutput encodes out as indented JSON. The whole document is buffered,
+// since ArtifactStore.Write takes it at once, so outputs above the warning
+// size stay compact instead, sparing the second full-size buffer indentation
+// takes.
+func encodeOutput(out any, warn int64) ([]byte, error) {
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	if warn > 0 && int64(len(b)) > warn {
+		return b, nil
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, b, "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// encodeGuarded encodes out for spec and applies the size guard: a warning
+// above the soft limit and ErrArtifactTooLarge above the hard limit unless
+// spec.ChunkedArtifact. chunk is the part size to write b with, 0 for a
+// single file.
+func encodeGuarded(ctx context.Context, spec WorkerSpec, out any) (b []byte, chunk int64, err error) {
+	warn, hard := artifactLimits(spec)
+	b, err = encodeOutput(out, warn)
+	if err != nil {
+		return nil, 0, err
+	}
+	size := int64(len(b))
+	if warn > 0 && size > warn {
+		logctx.Warn(ctx, "large worker output", "worker", spec.Key, "bytes", size, "warn_bytes", warn)
+	}
+	if hard <= 0 || size <= hard {
+		return b, 0, nil
+	}
+	if !spec.ChunkedArtifact {
+		return nil, 0, &ArtifactTooLargeError{Phase: spec.Key, Size: size, Limit: hard}
+	}
+	return b, hard, nil
+}
+
+// splitParts cuts b into chunk-sized parts and returns the index to store in
+// their place under name.
+func splitParts(name string, b []byte, chunk int64) ([][]byte, []byte, error) {
+	idx := chunkIndex{Version: 1, Size: int64(len(b)), Digest: outputDigest(b)}
+	var parts [][]byte
+	for off := int64(0); off < int64(len(b)); off += chunk {
+		parts = append(parts, b[off:min(off+chunk, int64(len(b)))])
+		idx.Parts = append(idx.Parts, PartName(name, len(parts)))
+	}
+	ib, err := json.Marshal(idx)
+	return parts, ib, err
+}
+
+// writeArtifact stores b as name, split into parts of chunk bytes when chunk
+// is positive. Parts are written before the index, so the index only ever
+// points at complete parts; parts left from a larger earlier save are
+// removed afterwards.
+func writeArtifact(ctx context.Context, artifacts ArtifactStore, name string, b []byte, chunk int64) error {
+	content := b
+	count := 0
+	if chunk > 0 && int64(len(b)) > chunk {
+		parts, idx, err := splitParts(name, b, chunk)
+		if err != nil {
+			return err
+		}
+		for i, p := range parts {
+			if err := artifacts.Write(ctx, PartName(name, i+1), p); err != nil {
+				return fmt.Errorf("write %s: %w", PartName(name, i+1), err)
+			}
+		}
+		content, count = idx, len(parts)
+	}
+	if err := artifacts.Write(ctx, name, content); err != nil {
+		return err
+	}
+	return removeParts(ctx, artifacts, name, count)
+}
+
+// removeParts removes the parts of name numbered above keep.
+func removeParts(ctx context.Context, artifacts ArtifactStore, name string, keep int) error {
+	names, err := artifacts.List(ctx)
+	if err != nil {
+		return nil
+	}
+	base := strings.TrimSuffix(name, ".json")
+	for _, n := range names {
+		m := partNameRE.FindStringSubmatch(n)
+		if len(m) != 3 || m[1] != base {
+			continue
+		}
+		if i, _ := strconv.Atoi(m[2]); i > keep {
+			if err := artifacts.Remove(ctx, n); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// IsArtifactPart reports whether the base name of name is a part of a
+// chunked artifact (see PartName).
+func IsArtifactPart(name string) bool {
+	return partNameRE.MatchString(path.Base(name))
+}
+
+// ReadArtifact reads name from artifacts, reassembling it when it was saved
+// in parts (see WorkerSpec.ChunkedArtifact).
+func ReadArtifact(ctx context.Context, artifacts ArtifactStore, name string) ([]byte, error) {
+	return ReadArtifactFunc(ctx, name, artifacts.Read)
+}
+
+// ReadArtifactFunc is ReadArtifact for stores that are not an ArtifactStore,
+// such as copies of a run's artifacts. read resolves the part names of the
+// index like name, so it must be rooted where the artifact was saved.
+func ReadArtifactFunc(ctx context.Context, name string, read func(ctx context.Context, name string) ([]byte, error)) ([]byte, error) {
+	b, err := read(ctx, name)
+	if err != nil || !bytes.HasPrefix(b, []byte(chunkIndexPrefix)) {
+		return b, err
+	}
+	var idx chunkIndex
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return nil, fmt.Errorf("decode chunk index %s: %w", name, err)
+	}
+	out := make([]byte, 0, idx.Size)
+	for _, part := range idx.Parts {
+		p, err := read(ctx, part)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", part, err)
+		}
+		out = append(out, p...)
+	}
+	if outputDigest(out) != idx.Digest {
+		return nil, fmt.Errorf("reassemble %s: parts do not match the index digest", name)
+	}
+	return out, nil
+}
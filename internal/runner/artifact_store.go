@@ -9,3 +9,10 @@ type ArtifactStore interface {
 	Remove(ctx context.Context, name string) error
 	List(ctx context.Context) ([]string, error)
 }
+
+// ArtifactCreator is implemented by stores that can create an artifact only
+// if it does not exist yet. Create returns an error matching fs.ErrExist when
+// name is taken, which lets concurrent runs claim distinct version numbers.
+type ArtifactCreator interface {
+	Create(ctx context.Context, name string, content []byte) error
+}
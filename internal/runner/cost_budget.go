@@ -0,0 +1,26 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RunParamCostBudgetUSD caps the LLM spend of a run, in USD. Calls that would
+// exceed it fail with llm.ErrBudgetExceeded; artifacts of finished phases stay
+// cached, so rerunning with a higher budget resumes where the run stopped.
+const RunParamCostBudgetUSD = "cost_budget_usd"
+
+// CostBudget parses RunParamCostBudgetUSD; ok is false when it is unset.
+func CostBudget(params map[string]string) (usd float64, ok bool, err error) {
+	raw := strings.TrimSpace(params[RunParamCostBudgetUSD])
+	if raw == "" {
+		return 0, false, nil
+	}
+	usd, err = strconv.ParseFloat(raw, 64)
+	if err != nil || usd < 0 || math.IsInf(usd, 0) || math.IsNaN(usd) {
+		return 0, false, fmt.Errorf("invalid %s %q", RunParamCostBudgetUSD, raw)
+	}
+	return usd, true, nil
+}
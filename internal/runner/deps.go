@@ -3,7 +3,9 @@ package runner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -26,6 +28,24 @@ type Deps interface {
 	// Returns error if the worker key is not declared in 'Requires'.
 	Artifact(key string, target any) error
 
+	// ArtifactFor loads a required worker output produced for another
+	// repository of a multi-repo project. key must be declared in Requires or
+	// Optional.
+	ArtifactFor(repo, key string, target any) error
+
+	// ArtifactIfExists loads an optional worker output into target and
+	// reports whether it existed. key must be declared in Requires or
+	// Optional.
+	ArtifactIfExists(key string, target any) (bool, error)
+
+	// ArtifactOrCompute loads a worker output into target, running that
+	// worker inline first (respecting its cache) when the output is missing.
+	// key must be declared in Requires.
+	ArtifactOrCompute(ctx context.Context, key string, target any) error
+
+	// Repos lists the project's repository names; nil for single-repo runtimes.
+	Repos() []string
+
 	// Repo returns the repository name.
 	Repo() string
 
@@ -40,20 +60,28 @@ type Deps interface {
 type depsImpl struct {
 	runtime  Runtime
 	requires map[string]bool
+	optional map[string]bool
 	accessed map[string]bool
 	worker   string
+	params   map[string]string // run params, for workers computed on demand
 }
 
-func newDeps(runtime Runtime, worker string, requires []string) *depsImpl {
-	reqMap := make(map[string]bool, len(requires))
-	for _, r := range requires {
+func newDeps(runtime Runtime, spec WorkerSpec, params map[string]string) *depsImpl {
+	reqMap := make(map[string]bool, len(spec.Requires))
+	for _, r := range spec.Requires {
 		reqMap[normalizeKey(r)] = true
 	}
+	optMap := make(map[string]bool, len(spec.Optional))
+	for _, r := range spec.Optional {
+		optMap[normalizeKey(r)] = true
+	}
 	return &depsImpl{
 		runtime:  runtime,
 		requires: reqMap,
+		optional: optMap,
 		accessed: make(map[string]bool),
-		worker:   worker,
+		worker:   spec.Key,
+		params:   params,
 	}
 }
 
@@ -63,13 +91,64 @@ func (d *depsImpl) Artifact(key string, target any) error {
 		return fmt.Errorf("worker %q requested artifact %q but it is not declared in Requires", d.worker, key)
 	}
 	d.accessed[norm] = true
+	return readArtifact(d.runtime, key, target)
+}
+
+func (d *depsImpl) ArtifactFor(repo, key string, target any) error {
+	norm := normalizeKey(key)
+	if !d.requires[norm] && !d.optional[norm] {
+		return fmt.Errorf("worker %q requested artifact %q but it is not declared in Requires or Optional", d.worker, key)
+	}
+	d.accessed[norm] = true
+	rt, err := RuntimeForRepo(d.runtime, repo)
+	if err != nil {
+		return err
+	}
+	if err := readArtifact(rt, key, target); err != nil {
+		return fmt.Errorf("repo %s: %w", repo, err)
+	}
+	return nil
+}
+
+func (d *depsImpl) ArtifactIfExists(key string, target any) (bool, error) {
+	norm := normalizeKey(key)
+	if !d.requires[norm] && !d.optional[norm] {
+		return false, fmt.Errorf("worker %q requested artifact %q but it is not declared in Requires or Optional", d.worker, key)
+	}
+	d.accessed[norm] = true
+	err := readArtifact(d.runtime, key, target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (d *depsImpl) ArtifactOrCompute(ctx context.Context, key string, target any) error {
+	norm := normalizeKey(key)
+	if !d.requires[norm] {
+		return fmt.Errorf("worker %q requested artifact %q but it is not declared in Requires", d.worker, key)
+	}
+	d.accessed[norm] = true
+	if err := ensureArtifact(ctx, d.runtime, key, d.params); err != nil {
+		return fmt.Errorf("worker %q: %w", d.worker, err)
+	}
+	return readArtifact(d.runtime, key, target)
+}
+
+func (d *depsImpl) Repos() []string {
+	if multi, ok := d.runtime.(MultiRepoRuntime); ok {
+		return multi.RepoNames()
+	}
+	return nil
+}
 
-	artifacts := d.runtime.Artifacts()
+func readArtifact(runtime Runtime, key string, target any) error {
+	artifacts := runtime.Artifacts()
 	if artifacts == nil {
 		return fmt.Errorf("artifact access is not configured")
 	}
-	artifactName := resolveArtifactName(d.runtime, key)
-	b, err := artifacts.Read(context.Background(), artifactName)
+	artifactName := resolveArtifactName(runtime, key)
+	b, err := ReadArtifact(context.Background(), artifacts, artifactName)
 	if err != nil {
 		return fmt.Errorf("read artifact %s: %w", key, err)
 	}
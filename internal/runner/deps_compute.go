@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// MaxComputeDepth bounds how many workers Deps.ArtifactOrCompute may nest
+// while computing missing artifacts on demand.
+const MaxComputeDepth = 4
+
+// ErrComputeCycle is returned when on-demand computation would re-enter a
+// worker that is already being executed.
+var ErrComputeCycle = errors.New("artifact dependency cycle")
+
+type ctxKeyComputeChain struct{}
+
+// computeChain returns the worker keys being executed by ctx, outermost first.
+func computeChain(ctx context.Context) []string {
+	chain, _ := ctx.Value(ctxKeyComputeChain{}).([]string)
+	return chain
+}
+
+func withComputeStep(ctx context.Context, key string) context.Context {
+	chain := computeChain(ctx)
+	next := make([]string, len(chain), len(chain)+1)
+	copy(next, chain)
+	return context.WithValue(ctx, ctxKeyComputeChain{}, append(next, normalizeKey(key)))
+}
+
+// computeLocks serializes on-demand computation of the same artifact so two
+// phases of a run do not compute it concurrently. Entries live only while a
+// computation holds or waits for them.
+var (
+	computeLocksMu sync.Mutex
+	computeLocks   = map[string]*computeLockEntry{} // out dir + artifact name
+)
+
+type computeLockEntry struct {
+	mu   sync.Mutex
+	refs int // holders and waiters; guarded by computeLocksMu
+}
+
+// lockCompute locks the computation of key's artifact and returns the
+// unlock, which drops the entry once nobody else waits for it.
+func lockCompute(runtime Runtime, key string) func() {
+	id := filepath.Join(runtime.GetOutDir(), resolveArtifactName(runtime, key))
+	computeLocksMu.Lock()
+	e := computeLocks[id]
+	if e == nil {
+		e = &computeLockEntry{}
+		computeLocks[id] = e
+	}
+	e.refs++
+	computeLocksMu.Unlock()
+
+	e.mu.Lock()
+	return func() {
+		e.mu.Unlock()
+		computeLocksMu.Lock()
+		if e.refs--; e.refs == 0 {
+			delete(computeLocks, id)
+		}
+		computeLocksMu.Unlock()
+	}
+}
+
+// artifactExists reports whether key's artifact is present and decodes. A
+// torn or partly written artifact counts as missing.
+func artifactExists(ctx context.Context, runtime Runtime, key string) (bool, error) {
+	artifacts := runtime.Artifacts()
+	if artifacts == nil {
+		return false, fmt.Errorf("artifact access is not configured")
+	}
+	b, err := ReadArtifact(ctx, artifacts, resolveArtifactName(runtime, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return json.Valid(b), nil
+}
+
+// ensureArtifact runs the worker producing key when its artifact is missing,
+// first ensuring the artifacts that worker requires. params are the run's
+// params, applied to every worker computed on the way.
+func ensureArtifact(ctx context.Context, runtime Runtime, key string, params map[string]string) error {
+	// Reads outside the lock may race a writer; only a decodable artifact
+	// skips it, and errors are reported by the check under the lock.
+	if ok, _ := artifactExists(ctx, runtime, key); ok {
+		return nil
+	}
+	spec, ok := runtime.GetResolver().Get(key)
+	if !ok {
+		return fmt.Errorf("artifact %s is missing and no worker produces it", key)
+	}
+	chain := computeChain(ctx)
+	for _, k := range chain {
+		if k == normalizeKey(spec.Key) {
+			return fmt.Errorf("%w: %s -> %s", ErrComputeCycle, strings.Join(chain, " -> "), spec.Key)
+		}
+	}
+	if len(chain) > MaxComputeDepth {
+		return fmt.Errorf("computing %s exceeds depth %d: %s", spec.Key, MaxComputeDepth, strings.Join(chain, " -> "))
+	}
+
+	unlock := lockCompute(runtime, spec.Key)
+	defer unlock()
+	// Another phase may have produced it while we waited.
+	if ok, err := artifactExists(ctx, runtime, spec.Key); ok || err != nil {
+		return err
+	}
+	for _, req := range spec.Requires {
+		if err := ensureArtifact(withComputeStep(ctx, spec.Key), runtime, req, params); err != nil {
+			return err
+		}
+	}
+	if _, err := executePhase(ctx, runtime, spec, params, nil); err != nil {
+		return fmt.Errorf("compute %s: %w", spec.Key, err)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DryRunReportName is the artifact DryRunWorker writes its report to.
+const DryRunReportName = "dryrun_report.json"
+
+// DryRunPhase is the estimate for one worker in the dry-run chain.
+type DryRunPhase struct {
+	Key             string   `json:"key"`
+	Reads           []string `json:"reads,omitempty"`
+	EstimatedTokens int      `json:"estimated_tokens"`
+	OpenedFiles     int      `json:"opened_files"`
+	CacheHit        bool     `json:"cache_hit"`
+	Executed        bool     `json:"executed"`
+	ExceedsCapacity bool     `json:"exceeds_capacity"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// DryRunReport summarizes what running a worker would cost.
+type DryRunReport struct {
+	Worker        string        `json:"worker"`
+	TokenCapacity int           `json:"token_capacity"`
+	TotalTokens   int           `json:"total_estimated_tokens"`
+	OverCapacity  []string      `json:"over_capacity,omitempty"`
+	Phases        []DryRunPhase `json:"phases"`
+}
+
+// DryRunWorker walks workerID and its upstream workers in dependency order,
+// building each input and fingerprint without calling the LLM. Workers marked
+// DryRunExecute run for real so their outputs can feed downstream inputs.
+// The report is written to DryRunReportName. Like ExecutePlan it holds the
+// OutDir run lock.
+func DryRunWorker(ctx context.Context, runtime Runtime, workerID string, params map[string]string) (DryRunReport, error) {
+	runtime, err := runtimeForRun(runtime, params)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+	release, err := lockOutDir(ctx, runtime)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+	defer release()
+	report, err := planChain(ctx, runtime, workerID, params, true)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+	if artifacts := runtime.Artifacts(); artifacts != nil {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return DryRunReport{}, err
+		}
+		if err := artifacts.Write(ctx, DryRunReportName, b); err != nil {
+			return DryRunReport{}, fmt.Errorf("write dry-run report: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// PlanWorker is DryRunWorker without side effects: no worker runs, not even
+// DryRunExecute ones, and no report is written. A phase whose upstream is not
+// cached may fail to build its input; it is reported with Error set and counts
+// as a recompute.
+func PlanWorker(ctx context.Context, runtime Runtime, workerID string, params map[string]string) (DryRunReport, error) {
+	runtime, err := runtimeForRun(runtime, params)
+	if err != nil {
+		return DryRunReport{}, err
+	}
+	return planChain(ctx, runtime, workerID, params, false)
+}
+
+// planChain walks workerID's chain on runtime, already resolved to the
+// run's repository.
+func planChain(ctx context.Context, runtime Runtime, workerID string, params map[string]string, execute bool) (DryRunReport, error) {
+	resolver := runtime.GetResolver()
+	if _, ok := resolver.Get(workerID); !ok {
+		return DryRunReport{}, fmt.Errorf("unknown worker_id: %s", workerID)
+	}
+
+	report := DryRunReport{Worker: workerID}
+	llm := runtime.GetLLM()
+	if llm != nil {
+		report.TokenCapacity = llm.TokenCapacity()
+	}
+
+	for _, key := range upstreamOrder(resolver, workerID) {
+		if err := ctx.Err(); err != nil {
+			return DryRunReport{}, err
+		}
+		spec, _ := resolver.Get(key)
+		phase := dryRunPhase(ctx, runtime, spec, key == normalizeKey(workerID), params, execute)
+		if !phase.CacheHit && !phase.Executed {
+			report.TotalTokens += phase.EstimatedTokens
+		}
+		if phase.ExceedsCapacity {
+			report.OverCapacity = append(report.OverCapacity, phase.Key)
+		}
+		report.Phases = append(report.Phases, phase)
+	}
+	return report, nil
+}
+
+func dryRunPhase(ctx context.Context, runtime Runtime, spec WorkerSpec, target bool, params map[string]string, execute bool) DryRunPhase {
+	phase := DryRunPhase{Key: spec.Key, Reads: spec.Requires}
+
+	var input any
+	if spec.BuildInput != nil {
+		var err error
+		input, err = spec.BuildInput(ctx, newDeps(runtime, spec, params))
+		if err != nil {
+			phase.Error = fmt.Sprintf("build input failed: %v", err)
+			return phase
+		}
+	}
+	if target {
+		input = applyRunParams(input, params)
+	}
+
+	inputFP := workerFingerprint(spec, input, runtime)
+	strategy := spec.Strategy
+	if strategy == nil {
+		strategy = JSONStrategy()
+	}
+	_, phase.CacheHit = strategy.TryLoad(ctx, spec, runtime, inputFP)
+
+	raw, err := json.Marshal(input)
+	if err != nil {
+		phase.Error = fmt.Sprintf("encode input failed: %v", err)
+		return phase
+	}
+	phase.OpenedFiles = countFileRefs(raw)
+
+	if spec.DryRunExecute {
+		if execute && !phase.CacheHit && spec.Run != nil {
+			out, err := spec.Run(ctx, input, runtime)
+			if err != nil {
+				phase.Error = fmt.Sprintf("run failed: %v", err)
+				return phase
+			}
+			if err := strategy.Save(ctx, spec, runtime, out, inputFP); err != nil {
+				phase.Error = fmt.Sprintf("save worker output failed: %v", err)
+				return phase
+			}
+			phase.Executed = true
+		}
+		return phase
+	}
+
+	if llm := runtime.GetLLM(); llm != nil {
+		phase.EstimatedTokens = llm.CountTokens(string(raw))
+		if capacity := llm.TokenCapacity(); capacity > 0 && phase.EstimatedTokens > capacity {
+			phase.ExceedsCapacity = true
+		}
+	}
+	return phase
+}
+
+// upstreamOrder returns workerID and everything it transitively requires,
+// dependencies first.
+func upstreamOrder(resolver SpecResolver, workerID string) []string {
+	var (
+		order []string
+		state = map[string]int{} // 1 = visiting, 2 = done
+		visit func(key string)
+	)
+	visit = func(key string) {
+		key = normalizeKey(key)
+		if state[key] != 0 {
+			return
+		}
+		spec, ok := resolver.Get(key)
+		if !ok {
+			return
+		}
+		state[key] = 1
+		for _, req := range spec.Requires {
+			visit(req)
+		}
+		state[key] = 2
+		order = append(order, key)
+	}
+	visit(workerID)
+	return order
+}
+
+// countFileRefs approximates how many files a worker will open by counting
+// distinct "path"/"file_path" strings in its serialized input.
+func countFileRefs(raw []byte) int {
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return 0
+	}
+	seen := map[string]struct{}{}
+	var walk func(v any)
+	walk = func(v any) {
+		switch val := v.(type) {
+		case map[string]any:
+			for k, child := range val {
+				if s, ok := child.(string); ok && (k == "path" || k == "file_path") && strings.TrimSpace(s) != "" {
+					seen[s] = struct{}{}
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range val {
+				walk(child)
+			}
+		}
+	}
+	walk(doc)
+	return len(seen)
+}
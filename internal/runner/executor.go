@@ -4,25 +4,162 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"strconv"
 	"strings"
+	"time"
 
+	"insightify/internal/artifact"
+	"insightify/internal/common/logctx"
+	llmclient "insightify/internal/llm/client"
+	"insightify/internal/llm/middleware"
 	"insightify/internal/workers/plan"
 )
 
+// RunParamRepo selects the repository of a multi-repo project a run works on.
+// Without it the project's default repository is used.
+const RunParamRepo = "repo"
+
+// RunParamLocale selects the language of assistant messages (e.g. "ja" or an
+// Accept-Language value); unsupported locales fall back to English.
+const RunParamLocale = "locale"
+
+// RunParamPackage scopes code_imports and code_graph to one workspace package
+// of code_roots, by name or repo-relative path.
+const RunParamPackage = "package"
+
+// RunParamSession identifies the chat a bootstrap run continues; the
+// transcript of an earlier run is carried over only within the same session.
+const RunParamSession = "session"
+
+// RunParamTargets limits the worker_DAG plan to these comma-separated
+// workers and what they require.
+const RunParamTargets = "targets"
+
+// RunParamStrictRequires ("true") makes worker_DAG report Requires outside
+// RunParamTargets instead of pulling them into the plan.
+const RunParamStrictRequires = "strict_requires"
+
 // ExecuteWorker runs a single worker by key using the resolver in env.
 // It centralizes input construction, dependency checks, and cache strategy handling.
 func ExecuteWorker(ctx context.Context, runtime Runtime, workerID string, params map[string]string) (WorkerOutput, error) {
+	return ExecutePlan(ctx, runtime, []string{workerID}, params)
+}
+
+// ExecutePlan runs workerIDs in order and returns the output of the last one.
+// Cumulative progress goes to the ProgressFunc attached with WithProgress:
+// each phase is weighted by its duration in PhaseDurationsName, or by its
+// spec Weight without history, reports when it starts and completes and
+// advances on streamed LLM chunks. Cache hits complete instantly.
+// RunParamBudgetMs bounds the whole plan; a phase whose timeout does not fit
+// in the remaining budget fails with ErrBudgetExhausted instead of starting.
+// The plan holds the OutDir run lock throughout; see WithRunLockWait.
+// PhaseHooks attached with WithPhaseHooks fire around every phase, and
+// WithParallelism runs independent phases concurrently.
+// Before the first RepoGated phase the RepoAssessmentKey phase runs; when it
+// finds too little code the gated phases are skipped and ExecutePlan returns
+// a *RepoSkippedError instead. RunParamForce disables the check.
+func ExecutePlan(ctx context.Context, runtime Runtime, workerIDs []string, params map[string]string) (WorkerOutput, error) {
+	runtime, err := runtimeForRun(runtime, params)
+	if err != nil {
+		return WorkerOutput{}, err
+	}
+
+	var (
+		specs   []WorkerSpec
+		keys    []string
+		weights = map[string]float64{}
+		seen    = map[string]bool{}
+	)
+	for _, id := range workerIDs {
+		spec, ok := runtime.GetResolver().Get(id)
+		if !ok {
+			return WorkerOutput{}, fmt.Errorf("unknown worker_id: %s", id)
+		}
+		if seen[spec.Key] {
+			continue
+		}
+		seen[spec.Key] = true
+		specs = append(specs, spec)
+		keys = append(keys, spec.Key)
+		weights[spec.Key] = spec.Weight
+	}
+	if len(specs) == 0 {
+		return WorkerOutput{}, fmt.Errorf("no workers to run")
+	}
+	budget, err := runBudget(params)
+	if err != nil {
+		return WorkerOutput{}, err
+	}
+	release, err := lockOutDir(ctx, runtime)
+	if err != nil {
+		return WorkerOutput{}, err
+	}
+	defer release()
+	parent := ctx
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	ctx, gate := withRepoGate(ctx, runtime, params)
+	progress := newProgressTracker(ctx, keys, weights, loadPhaseDurations(ctx, runtime))
+	var (
+		out    WorkerOutput
+		failed string
+	)
+	if n := parallelism(ctx); n > 1 && len(specs) > 1 {
+		out, failed, err = executeParallel(ctx, runtime, specs, params, progress, n)
+	} else {
+		for _, spec := range specs {
+			if out, err = executePhase(ctx, runtime, spec, params, progress); err != nil {
+				failed = spec.Key
+				break
+			}
+		}
+	}
+	if errors.Is(err, ErrRepoSkipped) {
+		gate.skipRest(ctx, specs)
+		return WorkerOutput{}, err
+	}
+	if err != nil {
+		if budget > 0 && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && !errors.Is(err, ErrBudgetExhausted) {
+			err = fmt.Errorf("%w during phase %q: %w", ErrBudgetExhausted, failed, err)
+		}
+		return WorkerOutput{}, err
+	}
+	return out, nil
+}
+
+// runtimeForRun checks runtime and narrows it to the repository selected by
+// RunParamRepo.
+func runtimeForRun(runtime Runtime, params map[string]string) (Runtime, error) {
 	if runtime == nil || runtime.GetResolver() == nil {
-		return WorkerOutput{}, fmt.Errorf("run environment resolver is not available")
+		return nil, fmt.Errorf("run environment resolver is not available")
 	}
+	return RuntimeForRepo(runtime, params[RunParamRepo])
+}
 
-	spec, ok := runtime.GetResolver().Get(workerID)
-	if !ok {
-		return WorkerOutput{}, fmt.Errorf("unknown worker_id: %s", workerID)
+// executePhase runs one phase between its PhaseHooks, unless the repo gate
+// skips it.
+func executePhase(ctx context.Context, runtime Runtime, spec WorkerSpec, params map[string]string, progress *progressTracker) (WorkerOutput, error) {
+	if err := repoGateFrom(ctx).check(ctx, spec); err != nil {
+		phaseEnded(ctx, spec, runtime, WorkerOutput{}, err, false)
+		return WorkerOutput{}, err
 	}
+	phaseStarted(ctx, spec, runtime)
+	out, cached, err := runPhase(ctx, runtime, spec, params, progress)
+	phaseEnded(ctx, spec, runtime, out, err, cached)
+	return out, err
+}
+
+// runPhase builds the phase input and loads the phase from cache, reporting
+// cached=true, or runs and saves it.
+func runPhase(ctx context.Context, runtime Runtime, spec WorkerSpec, params map[string]string, progress *progressTracker) (WorkerOutput, bool, error) {
+	ctx = llm.WithPhase(logctx.With(ctx, "worker", spec.Key), spec.Key)
+	ctx = withComputeStep(ctx, spec.Key)
 
-	deps := newDeps(runtime, spec.Key, spec.Requires)
+	deps := newDeps(runtime, spec, params)
 	var (
 		input any
 		err   error
@@ -30,44 +167,97 @@ func ExecuteWorker(ctx context.Context, runtime Runtime, workerID string, params
 	if spec.BuildInput != nil {
 		input, err = spec.BuildInput(ctx, deps)
 		if err != nil {
-			return WorkerOutput{}, fmt.Errorf("build input failed: %w", err)
+			return WorkerOutput{}, false, fmt.Errorf("build input failed: %w", err)
 		}
 	}
 	input = applyRunParams(input, params)
 
-	if err := verifyDepsUsage(runtime, spec.Key, deps); err != nil {
-		return WorkerOutput{}, err
+	if err := verifyDepsUsage(ctx, runtime, spec.Key, deps); err != nil {
+		return WorkerOutput{}, false, err
 	}
 	if spec.Run == nil {
-		return WorkerOutput{}, fmt.Errorf("worker %q has no run function", workerID)
+		return WorkerOutput{}, false, fmt.Errorf("worker %q has no run function", spec.Key)
 	}
 
-	inputFP := ""
-	if spec.Fingerprint != nil {
-		inputFP = spec.Fingerprint(input, runtime)
-	} else {
-		inputFP = JSONFingerprint(input)
-	}
+	inputFP := workerFingerprint(spec, input, runtime)
 
 	strategy := spec.Strategy
 	if strategy == nil {
 		strategy = JSONStrategy()
 	}
 	if out, ok := strategy.TryLoad(ctx, spec, runtime, inputFP); ok {
-		return out, nil
+		progress.complete(spec.Key)
+		return out, true, nil
 	}
 
-	out, err := spec.Run(ctx, input, runtime)
+	if err := ctx.Err(); err != nil {
+		return WorkerOutput{}, false, fmt.Errorf("worker %q not started: %w", spec.Key, err)
+	}
+	timeout := phaseTimeout(ctx, spec)
+	if err := checkBudget(ctx, spec.Key, timeout); err != nil {
+		return WorkerOutput{}, false, err
+	}
+	progress.start(spec.Key)
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if progress != nil {
+		runCtx = llm.WithStreamObserver(runCtx, func(string) { progress.chunk() })
+	}
+	if !spec.Generation.IsZero() {
+		runCtx = llmclient.WithGenerationOptions(runCtx, spec.Generation)
+	}
+	runCtx = withInputWaitPolicy(runCtx, spec.InputWait)
+	started := time.Now()
+	out, err := spec.Run(runCtx, input, runtime)
 	if err != nil {
-		return WorkerOutput{}, err
+		// Workers may wrap or replace the context error; keep it visible
+		// so callers can tell a deadline from a worker failure.
+		if ctxErr := runCtx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+			err = fmt.Errorf("%w: %v", ctxErr, err)
+		}
+		if ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			err = &PhaseTimeoutError{Phase: spec.Key, Timeout: timeout, Elapsed: time.Since(started), Err: err}
+		}
+		return WorkerOutput{}, false, err
 	}
 	if err := strategy.Save(ctx, spec, runtime, out, inputFP); err != nil {
-		return WorkerOutput{}, fmt.Errorf("save worker output failed: %w", err)
+		return WorkerOutput{}, false, fmt.Errorf("save worker output failed: %w", err)
 	}
-	return out, nil
+	recordPhaseDuration(ctx, runtime, spec.Key, time.Since(started))
+	progress.complete(spec.Key)
+	return out, false, nil
+}
+
+// workerFingerprint hashes a worker input for caching. The spec's generation
+// options are mixed in when set, and in multi-repo projects the repository
+// name too, so outputs produced differently never share a cache entry.
+func workerFingerprint(spec WorkerSpec, input any, runtime Runtime) string {
+	fp := ""
+	if spec.Fingerprint != nil {
+		fp = spec.Fingerprint(input, runtime)
+	} else {
+		fp = JSONFingerprint(input)
+	}
+	if !spec.Generation.IsZero() {
+		fp = JSONFingerprint(struct {
+			Generation llmclient.GenerationOptions
+			Input      string
+		}{spec.Generation, fp})
+	}
+	if multi, ok := runtime.(MultiRepoRuntime); ok && len(multi.RepoNames()) > 1 {
+		fp = JSONFingerprint(struct {
+			Repo  string
+			Input string
+		}{multi.CurrentRepo(), fp})
+	}
+	return fp
 }
 
-func verifyDepsUsage(runtime Runtime, workerKey string, deps *depsImpl) error {
+func verifyDepsUsage(ctx context.Context, runtime Runtime, workerKey string, deps *depsImpl) error {
 	if runtime == nil || deps == nil {
 		return nil
 	}
@@ -81,7 +271,7 @@ func verifyDepsUsage(runtime Runtime, workerKey string, deps *depsImpl) error {
 	case DepsUsageIgnore:
 		return nil
 	case DepsUsageWarn:
-		log.Printf("WARN: %s", msg)
+		logctx.Warn(ctx, msg)
 		return nil
 	default:
 		return errors.New(msg)
@@ -99,13 +289,68 @@ func applyRunParams(input any, params map[string]string) any {
 	switch in := input.(type) {
 	case map[string]any:
 		for k, v := range params {
+			// The cost and time budgets must not change fingerprints, or a
+			// rerun with a higher budget would redo the phases that already
+			// finished; the locale and session only concern bootstrap, the
+			// repo gate params only the gate, and the package only
+			// code_imports and code_graph.
+			switch k {
+			case RunParamCostBudgetUSD, RunParamBudgetMs, RunParamLocale, RunParamSession, RunParamForce, RunParamMinCodeRatio, RunParamMinCodeBytes, RunParamPackage:
+				continue
+			}
 			in[k] = v
 		}
 		return in
+	case artifact.CodeImportsIn:
+		if v := strings.TrimSpace(params[RunParamPackage]); v != "" {
+			in.Package = v
+		}
+		return in
+	case artifact.CodeGraphIn:
+		if v := strings.TrimSpace(params[RunParamPackage]); v != "" {
+			in.Package = v
+		}
+		if v := strings.TrimSpace(params["pruning"]); v != "" {
+			in.Pruning.Strategy = v
+		}
+		if v, err := strconv.Atoi(strings.TrimSpace(params["min_weight"])); err == nil {
+			in.Pruning.MinWeight = v
+		}
+		return in
+	case artifact.RepoAssessmentIn:
+		if v, err := strconv.ParseFloat(strings.TrimSpace(params[RunParamMinCodeRatio]), 64); err == nil {
+			in.MinCodeRatio = v
+		}
+		if v, err := strconv.ParseInt(strings.TrimSpace(params[RunParamMinCodeBytes]), 10, 64); err == nil {
+			in.MinCodeBytes = v
+		}
+		return in
+	case artifact.ArchDesignIn:
+		if v, err := strconv.Atoi(strings.TrimSpace(params["md_doc_tokens"])); err == nil {
+			in.MDDocTokens = v
+		}
+		return in
+	case artifact.CodeTasksIn:
+		if v, err := strconv.ParseBool(strings.TrimSpace(params["fail_on_cycle"])); err == nil {
+			in.FailOnCycle = v
+		}
+		return in
+	case artifact.PlanDependenciesIn:
+		if v := strings.TrimSpace(params[RunParamTargets]); v != "" {
+			in.Targets = strings.Split(v, ",")
+		}
+		if v, err := strconv.ParseBool(strings.TrimSpace(params[RunParamStrictRequires])); err == nil {
+			in.StrictRequires = v
+		}
+		return in
 	case plan.BootstrapIn:
 		if v := strings.TrimSpace(params["input"]); v != "" {
 			in.UserInput = v
 		}
+		if v := strings.TrimSpace(params[RunParamLocale]); v != "" {
+			in.Locale = v
+		}
+		in.Session = strings.TrimSpace(params[RunParamSession])
 		return in
 	default:
 		return input
@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// What a wait on user input does when its timeout expires; see
+// InputWaitPolicy.OnTimeout.
+const (
+	// InputTimeoutFail ends the wait with an *InputWaitTimeoutError.
+	InputTimeoutFail = ""
+	// InputTimeoutDefault answers the wait with InputWaitPolicy.DefaultAnswer.
+	InputTimeoutDefault = "default"
+	// InputTimeoutPause pauses the run and keeps waiting without a timeout
+	// until the input arrives.
+	InputTimeoutPause = "pause"
+)
+
+// inputWaitWarnAt is the fraction of the timeout after which OnWarning fires.
+const inputWaitWarnAt = 0.8
+
+// ErrInputWaitTimeout is matched by *InputWaitTimeoutError.
+var ErrInputWaitTimeout = errors.New("input wait timed out")
+
+// InputWaitTimeoutError is returned by WaitForUserInput when nobody answered
+// within the timeout and the worker did not opt into a default or a pause.
+// It also matches context.DeadlineExceeded, as the bare wait did.
+type InputWaitTimeoutError struct {
+	NodeID  string
+	Timeout time.Duration
+}
+
+func (e *InputWaitTimeoutError) Error() string {
+	return fmt.Sprintf("input wait for node %s timed out after %s", e.NodeID, e.Timeout)
+}
+
+func (e *InputWaitTimeoutError) Is(target error) bool {
+	return target == ErrInputWaitTimeout || target == context.DeadlineExceeded
+}
+
+// InputWaitPolicy bounds how long a worker waits on user input; set it as
+// WorkerSpec.InputWait.
+type InputWaitPolicy struct {
+	// Timeout bounds each wait; zero uses the default set with
+	// WithInputWaitTimeout, and no default leaves the wait unbounded.
+	Timeout time.Duration
+	// OnTimeout is InputTimeoutFail, InputTimeoutDefault or InputTimeoutPause.
+	OnTimeout string
+	// DefaultAnswer is the input used under InputTimeoutDefault.
+	DefaultAnswer string
+}
+
+// InputWaitEvents observe waits on user input. Any of them may be nil.
+type InputWaitEvents struct {
+	// OnWarning fires once 80% of the timeout has elapsed without input.
+	OnWarning func(ctx context.Context, nodeID string, remaining time.Duration)
+	// OnTimeout fires when the timeout expires, with the policy's OnTimeout.
+	OnTimeout func(ctx context.Context, nodeID, action string)
+	// OnResume fires when a paused wait receives its input.
+	OnResume func(ctx context.Context, nodeID string)
+}
+
+type ctxKeyInputWaitTimeout struct{}
+type ctxKeyInputWaitEvents struct{}
+type ctxKeyInputWaitPolicy struct{}
+
+// WithInputWaitTimeout sets the timeout of input waits whose policy sets none.
+func WithInputWaitTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyInputWaitTimeout{}, d)
+}
+
+// WithInputWaitEvents attaches observers to the input waits run with ctx.
+func WithInputWaitEvents(ctx context.Context, events InputWaitEvents) context.Context {
+	return context.WithValue(ctx, ctxKeyInputWaitEvents{}, events)
+}
+
+func withInputWaitPolicy(ctx context.Context, policy InputWaitPolicy) context.Context {
+	return context.WithValue(ctx, ctxKeyInputWaitPolicy{}, policy)
+}
+
+// WaitForUserInput waits on waiter for the next input of nodeID under the
+// running phase's InputWait policy. The run's InputWaitEvents hear the
+// warning at 80% of the timeout and the expiry; cancelling ctx ends the wait
+// with ctx's error.
+func WaitForUserInput(ctx context.Context, waiter InteractionWaiter, runID, nodeID string) (string, error) {
+	policy, _ := ctx.Value(ctxKeyInputWaitPolicy{}).(InputWaitPolicy)
+	timeout := policy.Timeout
+	if timeout <= 0 {
+		timeout, _ = ctx.Value(ctxKeyInputWaitTimeout{}).(time.Duration)
+	}
+	if timeout <= 0 {
+		return waiter.WaitForInput(ctx, runID, nodeID)
+	}
+	events, _ := ctx.Value(ctxKeyInputWaitEvents{}).(InputWaitEvents)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	warnAfter := time.Duration(float64(timeout) * inputWaitWarnAt)
+	warn := time.AfterFunc(warnAfter, func() {
+		if events.OnWarning != nil && waitCtx.Err() == nil {
+			events.OnWarning(ctx, nodeID, timeout-warnAfter)
+		}
+	})
+	in, err := waiter.WaitForInput(waitCtx, runID, nodeID)
+	warn.Stop()
+	expired := errors.Is(waitCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	cancel()
+	if err == nil || !expired {
+		return in, err
+	}
+
+	if events.OnTimeout != nil {
+		events.OnTimeout(ctx, nodeID, policy.OnTimeout)
+	}
+	switch policy.OnTimeout {
+	case InputTimeoutDefault:
+		return policy.DefaultAnswer, nil
+	case InputTimeoutPause:
+		in, err = waiter.WaitForInput(ctx, runID, nodeID)
+		if err == nil && events.OnResume != nil {
+			events.OnResume(ctx, nodeID)
+		}
+		return in, err
+	default:
+		return "", &InputWaitTimeoutError{NodeID: nodeID, Timeout: timeout}
+	}
+}
@@ -1,6 +1,10 @@
 package runner
 
-import "context"
+import (
+	"context"
+
+	"insightify/internal/artifact"
+)
 
 type runIDContextKey struct{}
 type nodeIDContextKey struct{}
@@ -12,6 +16,19 @@ type InteractionWaiter interface {
 	PublishOutput(ctx context.Context, runID, nodeID, interactionID, message string) error
 }
 
+// ConversationRecorder is implemented by interaction waiters that can record
+// a transcript carried over from an earlier run, so clients reconnecting to
+// the chat of the current run replay it.
+type ConversationRecorder interface {
+	RecordConversation(ctx context.Context, runID, nodeID string, turns []artifact.ConversationTurn) error
+}
+
+// ChunkPublisher is implemented by interaction waiters that stream assistant
+// output into the chat of a run as it is produced.
+type ChunkPublisher interface {
+	PublishOutputChunk(ctx context.Context, runID, nodeID, interactionID, chunk string) error
+}
+
 func WithRunID(ctx context.Context, runID string) context.Context {
 	return context.WithValue(ctx, runIDContextKey{}, runID)
 }
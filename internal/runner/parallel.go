@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type ctxKeyParallelism struct{}
+
+// WithParallelism lets ExecutePlan run up to n phases at once. A phase starts
+// once every phase of the plan it requires has finished; the first failure
+// cancels the phases still running. n <= 1 keeps phases sequential in plan
+// order.
+func WithParallelism(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, ctxKeyParallelism{}, n)
+}
+
+func parallelism(ctx context.Context) int {
+	n, _ := ctx.Value(ctxKeyParallelism{}).(int)
+	return max(1, n)
+}
+
+// UpstreamOrder returns workerID and every worker it transitively requires,
+// dependencies first; pass it to ExecutePlan to run a worker's whole chain.
+func UpstreamOrder(resolver SpecResolver, workerID string) []string {
+	return upstreamOrder(resolver, workerID)
+}
+
+type phaseResult struct {
+	index int
+	out   WorkerOutput
+	err   error
+}
+
+// executeParallel runs specs with at most n in flight, each as soon as the
+// specs it requires within the plan are done. It returns the output of the
+// last spec, or the first error and the key of the phase that failed.
+func executeParallel(ctx context.Context, runtime Runtime, specs []WorkerSpec, params map[string]string, progress *progressTracker, n int) (WorkerOutput, string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	index := make(map[string]int, len(specs))
+	for i, spec := range specs {
+		index[normalizeKey(spec.Key)] = i
+	}
+	waiting := make([]int, len(specs))
+	dependents := make([][]int, len(specs))
+	var ready []int
+	for i, spec := range specs {
+		for _, req := range append(slices.Clone(spec.Requires), spec.Optional...) {
+			if j, ok := index[normalizeKey(req)]; ok && j != i {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	results := make(chan phaseResult)
+	outs := make([]WorkerOutput, len(specs))
+	var (
+		running, done int
+		failed        string
+		firstErr      error
+	)
+	for done < len(specs) {
+		for firstErr == nil && running < n && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func() {
+				out, err := executePhase(ctx, runtime, specs[i], params, progress)
+				results <- phaseResult{index: i, out: out, err: err}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+		r := <-results
+		running--
+		done++
+		if r.err != nil {
+			if firstErr == nil {
+				failed, firstErr = specs[r.index].Key, r.err
+				cancel()
+			}
+			continue
+		}
+		outs[r.index] = r.out
+		for _, d := range dependents[r.index] {
+			if waiting[d]--; waiting[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	if firstErr != nil {
+		return WorkerOutput{}, failed, firstErr
+	}
+	if done < len(specs) {
+		var blocked []string
+		for i, w := range waiting {
+			if w > 0 {
+				blocked = append(blocked, specs[i].Key)
+			}
+		}
+		return WorkerOutput{}, "", fmt.Errorf("%w: phases never became ready: %s", ErrPhaseCycle, strings.Join(blocked, ", "))
+	}
+	return outs[len(outs)-1], "", nil
+}
@@ -0,0 +1,262 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Graph export formats accepted by ExportGraph.
+const (
+	GraphFormatDOT  = "dot"
+	GraphFormatJSON = "json"
+)
+
+// ErrPhaseCycle is returned by ExportGraph when Requires forms a cycle.
+var ErrPhaseCycle = errors.New("phase dependency cycle")
+
+// PhaseGraphNode is one worker in the exported dependency graph.
+type PhaseGraphNode struct {
+	Key           string   `json:"key"`
+	Description   string   `json:"description,omitempty"`
+	Requires      []string `json:"requires,omitempty"`
+	Downstream    []string `json:"downstream,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	UsesLLM       bool     `json:"uses_llm"`
+	PromptVersion string   `json:"prompt_version,omitempty"`
+	Weight        float64  `json:"weight,omitempty"`
+	// Missing marks a key named in Requires that no registry defines.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// PhaseGraphEdge points from a required worker to the worker that needs it.
+type PhaseGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PhaseGraph is the registry DAG as exported by ExportGraph.
+type PhaseGraph struct {
+	Nodes  []PhaseGraphNode `json:"nodes"`
+	Edges  []PhaseGraphEdge `json:"edges"`
+	Cycles [][]string       `json:"cycles,omitempty"`
+}
+
+// BuildPhaseGraph collects every spec in resolver into a PhaseGraph with
+// nodes and edges sorted by key. Workers that may execute during a dry run
+// are the ones that never call the LLM; all others are reported as LLM users.
+func BuildPhaseGraph(resolver SpecResolver) PhaseGraph {
+	var g PhaseGraph
+	if resolver == nil {
+		return g
+	}
+	known := map[string]bool{}
+	specs := resolver.List()
+	for _, spec := range specs {
+		known[normalizeKey(spec.Key)] = true
+	}
+	missing := map[string]bool{}
+	for _, spec := range specs {
+		key := normalizeKey(spec.Key)
+		node := PhaseGraphNode{
+			Key:           key,
+			Description:   spec.Description,
+			Downstream:    spec.Downstream,
+			Tags:          specTags(spec),
+			UsesLLM:       !spec.DryRunExecute,
+			PromptVersion: spec.PromptVersion,
+			Weight:        spec.Weight,
+		}
+		for _, req := range spec.Requires {
+			req = normalizeKey(req)
+			node.Requires = append(node.Requires, req)
+			g.Edges = append(g.Edges, PhaseGraphEdge{From: req, To: key})
+			if !known[req] {
+				missing[req] = true
+			}
+		}
+		g.Nodes = append(g.Nodes, node)
+	}
+	for key := range missing {
+		g.Nodes = append(g.Nodes, PhaseGraphNode{Key: key, Missing: true})
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].Key < g.Nodes[j].Key })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	g.Cycles = findCycles(g.Nodes)
+	return g
+}
+
+// ExportGraph renders the registry DAG as Graphviz DOT or JSON. The graph is
+// still returned when it has cycles, together with an ErrPhaseCycle error
+// naming each one.
+func ExportGraph(resolver SpecResolver, format string) ([]byte, error) {
+	g := BuildPhaseGraph(resolver)
+	var (
+		out []byte
+		err error
+	)
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case GraphFormatDOT:
+		out = g.DOT()
+	case GraphFormatJSON:
+		out, err = json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown graph format %q (want %s or %s)", format, GraphFormatDOT, GraphFormatJSON)
+	}
+	return out, g.cycleErr()
+}
+
+// Validate returns an ErrMissingDependency error naming every Requires entry
+// that no registry defines, else an ErrPhaseCycle error naming every cycle.
+func (g PhaseGraph) Validate() error {
+	missing := map[string]bool{}
+	for _, n := range g.Nodes {
+		if n.Missing {
+			missing[n.Key] = true
+		}
+	}
+	var parts []string
+	for _, n := range g.Nodes {
+		for _, req := range n.Requires {
+			if missing[req] {
+				parts = append(parts, fmt.Sprintf("%s requires %s", n.Key, req))
+			}
+		}
+	}
+	if len(parts) > 0 {
+		sort.Strings(parts)
+		return fmt.Errorf("%w: %s", ErrMissingDependency, strings.Join(parts, "; "))
+	}
+	return g.cycleErr()
+}
+
+// cycleErr returns an ErrPhaseCycle error naming every cycle of g, or nil.
+func (g PhaseGraph) cycleErr() error {
+	if len(g.Cycles) == 0 {
+		return nil
+	}
+	parts := make([]string, len(g.Cycles))
+	for i, c := range g.Cycles {
+		parts[i] = strings.Join(c, " -> ")
+	}
+	return fmt.Errorf("%w: %s", ErrPhaseCycle, strings.Join(parts, "; "))
+}
+
+// DOT renders g for Graphviz. LLM workers are drawn as boxes, others as
+// ellipses; missing dependencies and cycle edges are red.
+func (g PhaseGraph) DOT() []byte {
+	onCycle := map[PhaseGraphEdge]bool{}
+	for _, c := range g.Cycles {
+		for i := 0; i+1 < len(c); i++ {
+			onCycle[PhaseGraphEdge{From: c[i], To: c[i+1]}] = true
+		}
+	}
+
+	var b bytes.Buffer
+	b.WriteString("digraph phases {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		label := n.Key
+		if len(n.Tags) > 0 {
+			label += "\n[" + strings.Join(n.Tags, ", ") + "]"
+		}
+		attrs := []string{"label=" + strconv.Quote(label)}
+		switch {
+		case n.Missing:
+			attrs = append(attrs, "shape=ellipse", "style=dashed", "color=red")
+		case n.UsesLLM:
+			attrs = append(attrs, "shape=box")
+		default:
+			attrs = append(attrs, "shape=ellipse")
+		}
+		if n.Description != "" {
+			attrs = append(attrs, "tooltip="+strconv.Quote(n.Description))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", strconv.Quote(n.Key), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		attr := ""
+		if onCycle[e] {
+			attr = " [color=red]"
+		}
+		fmt.Fprintf(&b, "  %s -> %s%s;\n", strconv.Quote(e.From), strconv.Quote(e.To), attr)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func specTags(spec WorkerSpec) []string {
+	var tags []string
+	switch spec.Strategy.(type) {
+	case versionedStrategy:
+		tags = append(tags, "cache:versioned")
+	case jsonStrategy:
+		tags = append(tags, "cache:json")
+	case nil:
+		tags = append(tags, "cache:none")
+	}
+	if spec.DryRunExecute {
+		tags = append(tags, "dry_run_execute")
+	}
+	if spec.Pipeline != "" {
+		tags = append(tags, "pipeline:"+spec.Pipeline)
+	}
+	return tags
+}
+
+// findCycles reports each cycle found by a DFS over Requires edges, as the
+// path from the repeated key back to itself along edges (e.g. [a b a]).
+func findCycles(nodes []PhaseGraphNode) [][]string {
+	requires := make(map[string][]string, len(nodes))
+	for _, n := range nodes {
+		requires[n.Key] = n.Requires
+	}
+	var (
+		cycles [][]string
+		state  = map[string]int{} // 1 = visiting, 2 = done
+		stack  []string
+		visit  func(key string)
+	)
+	visit = func(key string) {
+		state[key] = 1
+		stack = append(stack, key)
+		for _, req := range requires[key] {
+			switch state[req] {
+			case 0:
+				visit(req)
+			case 1:
+				start := len(stack) - 1
+				for stack[start] != req {
+					start--
+				}
+				// Requires points upstream; walk the stack backwards so the
+				// cycle reads in data-flow order, matching the edges.
+				cycle := []string{req}
+				for i := len(stack) - 1; i >= start; i-- {
+					cycle = append(cycle, stack[i])
+				}
+				cycles = append(cycles, cycle)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[key] = 2
+	}
+	for _, n := range nodes {
+		if state[n.Key] == 0 {
+			visit(n.Key)
+		}
+	}
+	return cycles
+}
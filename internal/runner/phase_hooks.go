@@ -0,0 +1,45 @@
+package runner
+
+import "context"
+
+// PhaseHooks observe the lifecycle of each phase ExecutePlan runs. OnStart
+// fires before the phase builds its input; OnEnd fires once the phase
+// returns, whether it ran, failed or was loaded from cache (cached=true).
+// Either may be nil. Hooks run on the phase's goroutine, concurrently under
+// WithParallelism, and should not block.
+type PhaseHooks struct {
+	OnStart func(ctx context.Context, spec WorkerSpec, runtime Runtime)
+	OnEnd   func(ctx context.Context, spec WorkerSpec, runtime Runtime, out WorkerOutput, err error, cached bool)
+}
+
+type ctxKeyPhaseHooks struct{}
+
+// WithPhaseHooks attaches hooks to the phases run with ctx. Hooks attached
+// earlier stay in place and fire first.
+func WithPhaseHooks(ctx context.Context, hooks PhaseHooks) context.Context {
+	prev := phaseHooksFrom(ctx)
+	all := make([]PhaseHooks, 0, len(prev)+1)
+	all = append(append(all, prev...), hooks)
+	return context.WithValue(ctx, ctxKeyPhaseHooks{}, all)
+}
+
+func phaseHooksFrom(ctx context.Context) []PhaseHooks {
+	hooks, _ := ctx.Value(ctxKeyPhaseHooks{}).([]PhaseHooks)
+	return hooks
+}
+
+func phaseStarted(ctx context.Context, spec WorkerSpec, runtime Runtime) {
+	for _, h := range phaseHooksFrom(ctx) {
+		if h.OnStart != nil {
+			h.OnStart(ctx, spec, runtime)
+		}
+	}
+}
+
+func phaseEnded(ctx context.Context, spec WorkerSpec, runtime Runtime, out WorkerOutput, err error, cached bool) {
+	for _, h := range phaseHooksFrom(ctx) {
+		if h.OnEnd != nil {
+			h.OnEnd(ctx, spec, runtime, out, err, cached)
+		}
+	}
+}
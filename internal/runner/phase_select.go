@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RunParamPhases selects the phases a run executes: comma-separated worker
+// keys or pipeline names (see WorkerSpec.Pipeline). Each target runs with
+// everything it requires; see PlanPhases.
+const RunParamPhases = "phases"
+
+// ErrUnknownPhase is returned for a phase target that names neither a worker
+// nor a pipeline.
+var ErrUnknownPhase = errors.New("unknown phase")
+
+// PhasesParam returns the targets of RunParamPhases, or nil when unset.
+func PhasesParam(params map[string]string) []string {
+	var targets []string
+	for _, t := range strings.Split(params[RunParamPhases], ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// PlanPhases expands targets, worker keys or pipeline names, into the keys
+// to pass to ExecutePlan: every target worker, or every worker of a target
+// pipeline in key order, preceded by what it transitively requires. Keys
+// appear once, dependencies first. Targets that resolve to nothing fail with
+// ErrUnknownPhase before anything runs.
+func PlanPhases(resolver SpecResolver, targets []string) ([]string, error) {
+	if resolver == nil {
+		return nil, fmt.Errorf("run environment resolver is not available")
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: no phases requested", ErrUnknownPhase)
+	}
+	var (
+		plan    []string
+		seen    = map[string]bool{}
+		unknown []string
+	)
+	add := func(key string) {
+		for _, k := range upstreamOrder(resolver, key) {
+			if !seen[k] {
+				seen[k] = true
+				plan = append(plan, k)
+			}
+		}
+	}
+	for _, target := range targets {
+		if spec, ok := resolver.Get(target); ok {
+			add(spec.Key)
+			continue
+		}
+		members := pipelineKeys(resolver, target)
+		if len(members) == 0 {
+			unknown = append(unknown, target)
+			continue
+		}
+		for _, key := range members {
+			add(key)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPhase, strings.Join(unknown, ", "))
+	}
+	return plan, nil
+}
+
+// pipelineKeys returns the keys of the workers in pipeline, sorted.
+func pipelineKeys(resolver SpecResolver, pipeline string) []string {
+	specs := ListByTag(resolver, "pipeline:"+strings.TrimSpace(pipeline))
+	keys := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		keys = append(keys, spec.Key)
+	}
+	return keys
+}
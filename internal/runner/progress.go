@@ -0,0 +1,217 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"insightify/internal/common/logctx"
+)
+
+// PhaseDurationsName is the artifact recording how long each executed phase
+// last took, in milliseconds. Later runs weight their progress by it.
+const PhaseDurationsName = "phase_durations.json"
+
+// chunkShare is the part of a phase's remaining allotment one streamed chunk
+// advances, so chunk bumps approach but never reach the phase's end.
+const chunkShare = 0.05
+
+// ProgressFunc receives the cumulative percent (0-100) of a run. Values never
+// decrease and 100 is reported exactly once, when the last phase completes.
+type ProgressFunc func(percent float64)
+
+type ctxKeyProgress struct{}
+
+// WithProgress attaches a ProgressFunc that ExecuteWorker and ExecutePlan
+// report to.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, ctxKeyProgress{}, fn)
+}
+
+func progressFrom(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(ctxKeyProgress{}).(ProgressFunc)
+	return fn
+}
+
+// progressTracker turns phase starts, streamed chunks and completions into
+// cumulative percents. A nil tracker ignores every call.
+type progressTracker struct {
+	mu        sync.Mutex
+	emit      ProgressFunc
+	allot     map[string]float64 // percent each phase contributes
+	total     int
+	completed int
+	done      float64 // sum of completed allotments
+	current   string
+	partial   float64 // chunk progress inside current
+	last      float64
+	emitted   bool
+}
+
+// newProgressTracker plans keys with weights from durations, falling back to
+// the static weights; it returns nil when no ProgressFunc is attached to ctx.
+func newProgressTracker(ctx context.Context, keys []string, weights map[string]float64, durations map[string]int64) *progressTracker {
+	emit := progressFrom(ctx)
+	if emit == nil || len(keys) == 0 {
+		return nil
+	}
+	return &progressTracker{emit: emit, allot: phaseAllotments(keys, weights, durations), total: len(keys)}
+}
+
+// CompletedPercent maps the completed phases of a plan to a percent (0-100).
+// weights holds every phase of the plan with its relative cost, as in
+// WorkerSpec.Weight; zero counts as 1 and unknown keys in completed are
+// ignored.
+func CompletedPercent(weights map[string]float64, completed []string) float64 {
+	keys := make([]string, 0, len(weights))
+	for k := range weights {
+		keys = append(keys, k)
+	}
+	allot := phaseAllotments(keys, weights, nil)
+	seen := map[string]bool{}
+	var percent float64
+	for _, k := range completed {
+		if !seen[k] {
+			seen[k] = true
+			percent += allot[k]
+		}
+	}
+	return math.Min(percent, 100)
+}
+
+// phaseAllotments splits 100 percent across keys, proportionally to their
+// recorded durations. Phases without history get the mean of the known ones;
+// without any history phases are split by their static weights (zero
+// counting as 1).
+func phaseAllotments(keys []string, weights map[string]float64, durations map[string]int64) map[string]float64 {
+	var known, sum float64
+	for _, k := range keys {
+		if d := durations[k]; d > 0 {
+			known++
+			sum += float64(d)
+		}
+	}
+	allot := make(map[string]float64, len(keys))
+	var total float64
+	for _, k := range keys {
+		if _, dup := allot[k]; dup {
+			continue
+		}
+		w := 1.0
+		if weights[k] > 0 {
+			w = weights[k]
+		}
+		if known > 0 {
+			w = sum / known
+			if d := durations[k]; d > 0 {
+				w = float64(d)
+			}
+		}
+		allot[k] = w
+		total += w
+	}
+	for k, w := range allot {
+		allot[k] = w / total * 100
+	}
+	return allot
+}
+
+func (p *progressTracker) start(key string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current, p.partial = key, 0
+	p.report()
+}
+
+func (p *progressTracker) chunk() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current == "" {
+		return
+	}
+	p.partial += (p.allot[p.current] - p.partial) * chunkShare
+	p.report()
+}
+
+// complete marks key done; cache hits call it without start.
+func (p *progressTracker) complete(key string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.completed++
+	p.done += p.allot[key]
+	p.current, p.partial = "", 0
+	p.report()
+}
+
+// report emits the current percent, rounded down to 0.1, if it moved.
+func (p *progressTracker) report() {
+	if p.completed >= p.total {
+		if p.last < 100 {
+			p.last, p.emitted = 100, true
+			p.emit(100)
+		}
+		return
+	}
+	v := math.Min(math.Floor((p.done+p.partial)*10)/10, 99.9)
+	if p.emitted && v <= p.last {
+		return
+	}
+	p.last, p.emitted = v, true
+	p.emit(v)
+}
+
+// loadPhaseDurations reads PhaseDurationsName; a missing or broken file
+// yields no history.
+func loadPhaseDurations(ctx context.Context, runtime Runtime) map[string]int64 {
+	artifacts := runtime.Artifacts()
+	if artifacts == nil {
+		return nil
+	}
+	b, err := artifacts.Read(ctx, PhaseDurationsName)
+	if err != nil {
+		return nil
+	}
+	var durations map[string]int64
+	if json.Unmarshal(b, &durations) != nil {
+		return nil
+	}
+	return durations
+}
+
+// phaseDurationsMu serializes updates of PhaseDurationsName by phases that
+// run in parallel.
+var phaseDurationsMu sync.Mutex
+
+// recordPhaseDuration stores how long key took for later progress weights.
+// Failures are logged only.
+func recordPhaseDuration(ctx context.Context, runtime Runtime, key string, d time.Duration) {
+	artifacts := runtime.Artifacts()
+	if artifacts == nil {
+		return
+	}
+	phaseDurationsMu.Lock()
+	defer phaseDurationsMu.Unlock()
+	durations := loadPhaseDurations(ctx, runtime)
+	if durations == nil {
+		durations = map[string]int64{}
+	}
+	durations[key] = max(d.Milliseconds(), 1)
+	b, err := json.MarshalIndent(durations, "", "  ")
+	if err == nil {
+		err = artifacts.Write(ctx, PhaseDurationsName, b)
+	}
+	if err != nil {
+		logctx.Warn(ctx, "record phase duration failed", "worker", key, "error", err)
+	}
+}
@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PromptVersions holds the prompt version of every phase that calls the LLM.
+// Bump a phase's entry whenever its prompt text, prompt spec or output
+// contract changes: the version is stored with cached outputs, so a bump
+// makes that phase miss its cache on the next run. Runtimes also fold the
+// whole map into the model salt (see BuildModelSalt), which makes every phase
+// miss after a bump.
+//
+// MergeRegistries copies these into WorkerSpec.PromptVersion unless a spec
+// sets its own.
+var PromptVersions = map[string]string{
+	"arch_design":         "4",
+	"autonomous_executor": "1",
+	"bootstrap":           "3",
+	"code_roots":          "2",
+	"code_specs":          "3",
+	"code_symbols":        "1",
+	"infra_context":       "1",
+	"infra_refine":        "2",
+	"worker_dag":          "1",
+}
+
+// BuildModelSalt returns the cache salt for model under the given prompt
+// versions. Map order does not matter; adding, removing or bumping any
+// version changes the salt, so cached outputs never outlive the prompts that
+// produced them even if nobody bumps CACHE_SALT.
+func BuildModelSalt(model string, promptVersions map[string]string) string {
+	keys := make([]string, 0, len(promptVersions))
+	for k := range promptVersions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, promptVersions[k])
+	}
+	return strings.TrimSpace(model) + "|prompts:" + hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/logctx"
+)
+
+// QuestionLedgerName is the artifact carrying the evidence question ledger
+// across runs; infra_refine reads and rewrites it.
+const QuestionLedgerName = "questions.json"
+
+// readQuestionLedger loads the ledger; a missing one is empty.
+func readQuestionLedger(ctx context.Context, runtime Runtime) (artifact.QuestionLedger, error) {
+	var ledger artifact.QuestionLedger
+	if runtime == nil || runtime.Artifacts() == nil {
+		return ledger, nil
+	}
+	raw, err := runtime.Artifacts().Read(ctx, QuestionLedgerName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ledger, nil
+	}
+	if err != nil {
+		return ledger, err
+	}
+	if err := json.Unmarshal(raw, &ledger); err != nil {
+		return artifact.QuestionLedger{}, fmt.Errorf("%s: %w", QuestionLedgerName, err)
+	}
+	return ledger, nil
+}
+
+// writeQuestionLedger stores ledger. Failures are logged only: the next run
+// re-asks the questions instead of failing this one.
+func writeQuestionLedger(ctx context.Context, runtime Runtime, ledger artifact.QuestionLedger) {
+	if runtime == nil || runtime.Artifacts() == nil {
+		return
+	}
+	b, err := json.MarshalIndent(ledger, "", "  ")
+	if err == nil {
+		err = runtime.Artifacts().Write(ctx, QuestionLedgerName, b)
+	}
+	if err != nil {
+		logctx.Warn(ctx, "write question ledger failed", "error", err)
+	}
+}
@@ -2,16 +2,19 @@ package runner
 
 import (
 	"context"
+	"encoding/json"
 
 	"insightify/internal/artifact"
+	"insightify/internal/artifactdiff"
+	"insightify/internal/common/logctx"
 	"insightify/internal/llm/middleware"
 	archpipe "insightify/internal/workers/architecture"
 )
 
-// BuildRegistryArchitecture defines arch_design.
+// BuildRegistryArchitecture defines arch_design and arch_diff.
 // Add/modify phases here without touching main or execution logic.
 func init() {
-	RegisterBuilder(BuildRegistryArchitecture)
+	RegisterPipeline("architecture", BuildRegistryArchitecture)
 }
 
 func BuildRegistryArchitecture(_ Runtime) map[string]WorkerSpec {
@@ -39,7 +42,8 @@ func BuildRegistryArchitecture(_ Runtime) map[string]WorkerSpec {
 			if err != nil {
 				return WorkerOutput{}, err
 			}
-			return WorkerOutput{RuntimeState: out, ClientView: nil}, nil
+			prev := recordArchDesignBase(ctx, runtime)
+			return WorkerOutput{RuntimeState: out, ClientView: archpipe.ArchDesignView(out, archDesignChanges(prev, out), 0)}, nil
 		},
 		Fingerprint: func(in any, runtime Runtime) string {
 			return JSONFingerprint(struct {
@@ -48,7 +52,97 @@ func BuildRegistryArchitecture(_ Runtime) map[string]WorkerSpec {
 			}{in.(artifact.ArchDesignIn), runtime.GetModelSalt()})
 		},
 		Strategy: jsonStrategy{},
+
+		RepoGated: true,
+	}
+
+	reg["arch_diff"] = WorkerSpec{
+		Key:         "arch_diff",
+		Requires:    []string{"arch_design"},
+		Description: "Reports components added, removed, renamed or modified since the arch_design output the last arch_design run replaced.",
+		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+			in := artifact.ArchDiffIn{}
+			if err := deps.Artifact("arch_design", &in.Head); err != nil {
+				return nil, err
+			}
+			in.Base = archDesignBase(ctx, deps.Env())
+			return in, nil
+		},
+		Run: func(ctx context.Context, in any, _ Runtime) (WorkerOutput, error) {
+			diffIn := in.(artifact.ArchDiffIn)
+			out, err := archpipe.ArchDiff{}.Run(ctx, diffIn)
+			if err != nil {
+				return WorkerOutput{}, err
+			}
+			return WorkerOutput{RuntimeState: out, ClientView: archpipe.ArchDiffView(diffIn)}, nil
+		},
+		Fingerprint: func(in any, _ Runtime) string {
+			return JSONFingerprint(in.(artifact.ArchDiffIn))
+		},
+		Strategy: jsonStrategy{},
 	}
 
 	return reg
 }
+
+// ArchDesignBaseName is the arch_design output the last arch_design run
+// replaced. arch_design records it while it runs, before its new output is
+// stored, and annotates its view with the changes since; arch_diff reports
+// the same changes from it.
+const ArchDesignBaseName = "arch_design_base.json"
+
+// recordArchDesignBase reads the stored arch_design output, which Run is
+// about to replace, and records it as ArchDesignBaseName; nil when there is
+// none. Failures are logged only: they cost the next diff its base.
+func recordArchDesignBase(ctx context.Context, runtime Runtime) []byte {
+	store := runtime.Artifacts()
+	if store == nil {
+		return nil
+	}
+	prev, err := ReadArtifact(ctx, store, "arch_design.json")
+	if err != nil {
+		prev = nil
+		err = store.Remove(ctx, ArchDesignBaseName)
+	} else {
+		err = store.Write(ctx, ArchDesignBaseName, prev)
+	}
+	if err != nil {
+		logctx.Warn(ctx, "record arch_design base failed", "error", err)
+	}
+	return prev
+}
+
+// archDesignBase returns the output recorded by recordArchDesignBase; nil
+// when there is none.
+func archDesignBase(ctx context.Context, runtime Runtime) *artifact.ArchDesignOut {
+	if runtime == nil || runtime.Artifacts() == nil {
+		return nil
+	}
+	raw, err := ReadArtifact(ctx, runtime.Artifacts(), ArchDesignBaseName)
+	if err != nil {
+		return nil
+	}
+	var base artifact.ArchDesignOut
+	if err := json.Unmarshal(raw, &base); err != nil {
+		logctx.Warn(ctx, "ignoring unreadable arch_design base", "error", err)
+		return nil
+	}
+	return &base
+}
+
+// archDesignChanges diffs out against prev, the arch_design output it
+// replaces; nil when there is none.
+func archDesignChanges(prev []byte, out artifact.ArchDesignOut) *artifactdiff.ArchDiff {
+	if prev == nil {
+		return nil
+	}
+	cur, err := json.Marshal(out)
+	if err != nil {
+		return nil
+	}
+	res, err := artifactdiff.Compare(artifactdiff.KeyArchDesign, prev, cur, artifactdiff.Options{})
+	if err != nil {
+		return nil
+	}
+	return res.Arch
+}
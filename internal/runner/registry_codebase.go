@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"insightify/internal/artifact"
+	"insightify/internal/common/scheduler"
 	"insightify/internal/llm/middleware"
 	llmclient "insightify/internal/llm/client"
 	"insightify/internal/llm/tool"
@@ -13,7 +14,7 @@ import (
 // BuildRegistryCodebase defines code_roots-code_symbols.
 // code_roots uses versionedStrategy; c1 uses jsonStrategy; etc.
 func init() {
-	RegisterBuilder(BuildRegistryCodebase)
+	RegisterPipeline("codebase", BuildRegistryCodebase)
 }
 
 func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
@@ -22,7 +23,10 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 		Key:         "code_roots",
 		Description: "Scan repo layout and ask LLM to classify main source roots, library/vendor roots, and config hotspots.",
 		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
-			return artifact.CodeRootsIn{Repo: deps.Repo()}, nil
+			return artifact.CodeRootsIn{
+				Repo:     deps.Repo(),
+				Packages: codepipe.DetectWorkspacePackages(deps.Env().GetRepoFS()),
+			}, nil
 		},
 		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
 			ctx = llm.WithWorker(ctx, "code_roots")
@@ -42,9 +46,66 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 		Strategy: versionedStrategy{},
 	}
 
+	reg["code_stats"] = WorkerSpec{
+		Key:         "code_stats",
+		Requires:    []string{"code_roots"},
+		Description: "Scan the repo outside library roots for per-extension counts, sizes, top directories, and samples from non-generated main sources.",
+		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+			var codeRootsPrev artifact.CodeRootsOut
+			if err := deps.Artifact("code_roots", &codeRootsPrev); err != nil {
+				return nil, err
+			}
+			in := artifact.CodeStatsIn{
+				Repo:   deps.Repo(),
+				RepoFS: deps.Env().GetRepoFS(),
+				Roots:  codeRootsPrev,
+			}
+			return in, nil
+		},
+		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
+			x := pipelineCodeStats{}
+			out, err := x.Run(ctx, in.(artifact.CodeStatsIn))
+			if err != nil {
+				return WorkerOutput{}, err
+			}
+			return WorkerOutput{RuntimeState: out, ClientView: nil}, nil
+		},
+		// The scan reads the working tree, which the input does not capture,
+		// so every run rescans.
+		Strategy: versionedStrategy{},
+
+		DryRunExecute: true,
+	}
+
+	reg["repo_assessment"] = WorkerSpec{
+		Key:         "repo_assessment",
+		Requires:    []string{"code_stats"},
+		Description: "Classify the code_stats extensions as code, docs, data or other and decide whether the repo has enough code for the LLM phases.",
+		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+			var stats artifact.CodeStatsOut
+			if err := deps.Artifact("code_stats", &stats); err != nil {
+				return nil, err
+			}
+			return artifact.RepoAssessmentIn{Stats: stats}, nil
+		},
+		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
+			out, err := codepipe.RepoAssessment{}.Run(ctx, in.(artifact.RepoAssessmentIn))
+			if err != nil {
+				return WorkerOutput{}, err
+			}
+			return WorkerOutput{RuntimeState: out, ClientView: codepipe.AssessmentView(out)}, nil
+		},
+		Fingerprint: func(in any, runtime Runtime) string {
+			return JSONFingerprint(in.(artifact.RepoAssessmentIn))
+		},
+		Strategy: jsonStrategy{},
+
+		DryRunExecute: true,
+	}
+
 	reg["code_specs"] = WorkerSpec{
 		Key:         "code_specs",
-		Requires:    []string{"code_roots"},
+		Requires:    []string{"code_roots", "code_stats"},
 		Description: "LLM infers language families/import heuristics from extension counts and roots.",
 		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
 			var codeRootsPrev artifact.CodeRootsOut
@@ -55,6 +116,14 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 				Repo:  deps.Repo(),
 				Roots: codeRootsPrev,
 			}
+			var stats artifact.CodeStatsOut
+			ok, err := deps.ArtifactIfExists("code_stats", &stats)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				in.ExtReport = &stats
+			}
 			return in, nil
 		},
 
@@ -75,6 +144,10 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 			}{in.(artifact.CodeSpecsIn), runtime.GetModelSalt()})
 		},
 		Strategy: versionedStrategy{},
+		// Import regexes must not vary between runs, and one spec per
+		// family easily outgrows the default output cap.
+		Generation: llmclient.GenerationOptions{Temperature: llmclient.Float32(0), MaxOutputTokens: 8192},
+		RepoGated:  true,
 	}
 
 	reg["code_imports"] = WorkerSpec{
@@ -114,20 +187,75 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 			}{in.(artifact.CodeImportsIn), runtime.GetModelSalt()})
 		},
 		Strategy: jsonStrategy{},
+
+		DryRunExecute: true,
+	}
+
+	reg["code_import_edges"] = WorkerSpec{
+		Key:         "code_import_edges",
+		Requires:    []string{"code_specs", "code_imports"},
+		Description: "Verify candidate import ranges with spec keyword matchers and emit normalized import edges with provenance.",
+		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+			var codeSpecsPrev artifact.CodeSpecsOut
+			if err := deps.Artifact("code_specs", &codeSpecsPrev); err != nil {
+				return nil, err
+			}
+			var codeImportsPrev artifact.CodeImportsOut
+			if err := deps.Artifact("code_imports", &codeImportsPrev); err != nil {
+				return nil, err
+			}
+			// Without narrower ranges, verify each scanned file in full.
+			var ranges []artifact.ImportStatementRange
+			for _, dep := range codeImportsPrev.PossibleDependencies {
+				for _, sd := range dep.Files {
+					ranges = append(ranges, artifact.ImportStatementRange{FilePath: sd.File.Path, StartLine: 1})
+				}
+			}
+			return artifact.CodeImportEdgesIn{
+				Repo:     deps.Repo(),
+				RepoFS:   deps.Env().GetRepoFS(),
+				Families: codeSpecsPrev.Families,
+				Ranges:   ranges,
+			}, nil
+		},
+		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
+			ctx = llm.WithWorker(ctx, "code_import_edges")
+			var x pipelineCodeImportEdges
+			out, err := x.Run(ctx, in.(artifact.CodeImportEdgesIn))
+			if err != nil {
+				return WorkerOutput{}, err
+			}
+			return WorkerOutput{RuntimeState: out, ClientView: nil}, nil
+		},
+		Fingerprint: func(in any, runtime Runtime) string {
+			return JSONFingerprint(struct {
+				In   artifact.CodeImportEdgesIn
+				Salt string
+			}{in.(artifact.CodeImportEdgesIn), runtime.GetModelSalt()})
+		},
+		Strategy: jsonStrategy{},
+
+		DryRunExecute: true,
 	}
 
 	reg["code_graph"] = WorkerSpec{
 		Key:         "code_graph",
-		Requires:    []string{"code_imports"},
-		Description: "Normalize dependency hits into a DAG and drop weaker bidirectional edges.",
+		Requires:    []string{"code_imports", "code_roots"},
+		Description: "Normalize dependency hits into a graph, prune bidirectional edges, and report remaining cycles.",
 		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
 			var codeImportsOut artifact.CodeImportsOut
 			if err := deps.Artifact("code_imports", &codeImportsOut); err != nil {
 				return nil, err
 			}
+			// Packages resolve the package param by name.
+			var codeRootsPrev artifact.CodeRootsOut
+			if err := deps.Artifact("code_roots", &codeRootsPrev); err != nil {
+				return nil, err
+			}
 			return artifact.CodeGraphIn{
 				Repo:         deps.Repo(),
 				Dependencies: codeImportsOut.PossibleDependencies,
+				Packages:     codeRootsPrev.Packages,
 			}, nil
 		},
 		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
@@ -137,7 +265,7 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 			if err != nil {
 				return WorkerOutput{}, err
 			}
-			return WorkerOutput{RuntimeState: out, ClientView: nil}, nil
+			return WorkerOutput{RuntimeState: out, ClientView: codepipe.CodeGraphView(out)}, nil
 		},
 		Fingerprint: func(in any, runtime Runtime) string {
 			return JSONFingerprint(struct {
@@ -146,6 +274,39 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 			}{in.(artifact.CodeGraphIn), runtime.GetModelSalt()})
 		},
 		Strategy: jsonStrategy{},
+
+		DryRunExecute: true,
+	}
+
+	reg["code_mermaid"] = WorkerSpec{
+		Key:         "code_mermaid",
+		Requires:    []string{"code_graph", "code_roots"},
+		Description: "Render the dependency graph as a Mermaid flowchart grouped by package or top-level directory and styled by extension.",
+		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+			var graph artifact.CodeGraphOut
+			if err := deps.Artifact("code_graph", &graph); err != nil {
+				return nil, err
+			}
+			var codeRootsPrev artifact.CodeRootsOut
+			if err := deps.Artifact("code_roots", &codeRootsPrev); err != nil {
+				return nil, err
+			}
+			return artifact.CodeMermaidIn{Graph: graph, Packages: codeRootsPrev.Packages}, nil
+		},
+		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
+			var x codepipe.CodeMermaid
+			out, err := x.Run(ctx, in.(artifact.CodeMermaidIn))
+			if err != nil {
+				return WorkerOutput{}, err
+			}
+			return WorkerOutput{RuntimeState: out, ClientView: codepipe.MermaidView(out)}, nil
+		},
+		Fingerprint: func(in any, runtime Runtime) string {
+			return JSONFingerprint(in.(artifact.CodeMermaidIn))
+		},
+		Strategy: jsonStrategy{},
+
+		DryRunExecute: true,
 	}
 
 	reg["code_tasks"] = WorkerSpec{
@@ -166,6 +327,7 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 				RepoFS:      deps.Env().GetRepoFS(),
 				Graph:       graph.Graph,
 				CapPerChunk: capPerChunk,
+				Cycles:      graph.Cycles,
 			}, nil
 		},
 		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
@@ -189,22 +351,27 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 	reg["code_symbols"] = WorkerSpec{
 		Key:         "code_symbols",
 		Requires:    []string{"code_tasks"},
-		Description: "LLM traverses tasks to build identifier reference maps (outgoing/incoming).",
+		Description: "LLM traverses tasks to build identifier reference maps (outgoing/incoming), then resolves cross-chunk references.",
 		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
 			var codeTasksOut artifact.CodeTasksOut
 			if err := deps.Artifact("code_tasks", &codeTasksOut); err != nil {
 				return nil, err
 			}
 			return artifact.CodeSymbolsIn{
-				Repo:   deps.Repo(),
-				RepoFS: deps.Env().GetRepoFS(),
-				Tasks:  codeTasksOut,
+				Repo:       deps.Repo(),
+				RepoFS:     deps.Env().GetRepoFS(),
+				Tasks:      codeTasksOut,
+				Resolution: codepipe.DefaultSymbolResolution(),
 			}, nil
 		},
 		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
 			ctx = llm.WithWorker(ctx, "code_symbols")
 			x := pipelineCodeSymbols{LLM: runtime.GetLLM()}
+			if llmCli := runtime.GetLLM(); llmCli != nil {
+				x.Trace = newScheduleRecorder(llmCli.TokenCapacity())
+			}
 			out, err := x.Run(ctx, in.(artifact.CodeSymbolsIn))
+			writeScheduleTrace(ctx, runtime, x.Trace)
 			if err != nil {
 				return WorkerOutput{}, err
 			}
@@ -217,6 +384,8 @@ func BuildRegistryCodebase(_ Runtime) map[string]WorkerSpec {
 			}{in.(artifact.CodeSymbolsIn), runtime.GetModelSalt()})
 		},
 		Strategy: jsonStrategy{},
+		// Reference maps of large repositories outgrow the size limit.
+		ChunkedArtifact: true,
 	}
 
 	return reg
@@ -227,16 +396,25 @@ type pipelineCodeRoots struct {
 	LLM   llmclient.LLMClient
 	Tools llmtool.ToolProvider
 }
+type pipelineCodeStats struct{}
 type pipelineCodeSpecs struct{ LLM llmclient.LLMClient }
 type pipelineCodeImports struct{}
+type pipelineCodeImportEdges struct{}
 type pipelineCodeGraph struct{}
 type pipelineCodeTasks struct{ LLM llmclient.LLMClient }
-type pipelineCodeSymbols struct{ LLM llmclient.LLMClient }
+type pipelineCodeSymbols struct {
+	LLM   llmclient.LLMClient
+	Trace *scheduler.TraceRecorder
+}
 
 func (p pipelineCodeRoots) Run(ctx context.Context, in artifact.CodeRootsIn) (artifact.CodeRootsOut, error) {
 	real := codepipe.CodeRoots{LLM: p.LLM, Tools: p.Tools}
 	return real.Run(ctx, in)
 }
+func (pipelineCodeStats) Run(ctx context.Context, in artifact.CodeStatsIn) (artifact.CodeStatsOut, error) {
+	real := codepipe.CodeStats{}
+	return real.Run(ctx, in)
+}
 func (p pipelineCodeSpecs) Run(ctx context.Context, in artifact.CodeSpecsIn) (artifact.CodeSpecsOut, error) {
 	real := codepipe.CodeSpecs{LLM: p.LLM}
 	return real.Run(ctx, in)
@@ -245,6 +423,10 @@ func (pipelineCodeImports) Run(ctx context.Context, in artifact.CodeImportsIn) (
 	real := codepipe.CodeImports{}
 	return real.Run(ctx, in)
 }
+func (pipelineCodeImportEdges) Run(ctx context.Context, in artifact.CodeImportEdgesIn) (artifact.CodeImportEdgesOut, error) {
+	real := codepipe.CodeImportEdges{}
+	return real.Run(ctx, in)
+}
 func (pipelineCodeGraph) Run(ctx context.Context, in artifact.CodeGraphIn) (artifact.CodeGraphOut, error) {
 	real := codepipe.CodeGraph{}
 	return real.Run(ctx, in)
@@ -255,5 +437,8 @@ func (p pipelineCodeTasks) Run(ctx context.Context, in artifact.CodeTasksIn) (ar
 }
 func (p pipelineCodeSymbols) Run(ctx context.Context, in artifact.CodeSymbolsIn) (artifact.CodeSymbolsOut, error) {
 	real := codepipe.CodeSymbols{LLM: p.LLM}
+	if p.Trace != nil {
+		real.Explain = p.Trace.Record
+	}
 	return real.Run(ctx, in)
 }
@@ -2,6 +2,8 @@ package runner
 
 import (
 	"context"
+	"errors"
+	"io/fs"
 
 	"insightify/internal/artifact"
 	"insightify/internal/llm/middleware"
@@ -10,7 +12,7 @@ import (
 
 // BuildRegistryExternal wires the external (x*) pipeline stages.
 func init() {
-	RegisterBuilder(BuildRegistryExternal)
+	RegisterPipeline("external", BuildRegistryExternal)
 }
 
 func BuildRegistryExternal(_ Runtime) map[string]WorkerSpec {
@@ -18,7 +20,8 @@ func BuildRegistryExternal(_ Runtime) map[string]WorkerSpec {
 
 	reg["infra_context"] = WorkerSpec{
 		Key:         "infra_context",
-		Requires:    []string{"arch_design", "code_symbols", "code_roots"}, // Explicit code_roots dependency added for roots
+		Requires:    []string{"arch_design", "code_roots"}, // Explicit code_roots dependency added for roots
+		Optional:    []string{"code_symbols"},
 		Description: "LLM summarizes external systems/infra using architecture (arch_design) + identifier refs (code_symbols), surfacing evidence gaps.",
 		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
 			var c0 artifact.CodeRootsOut
@@ -29,8 +32,16 @@ func BuildRegistryExternal(_ Runtime) map[string]WorkerSpec {
 			if err := deps.Artifact("arch_design", &m1); err != nil {
 				return nil, err
 			}
+			// The flagged file list only audits arch_design's index.
+			m1.FlaggedFiles = nil
+			// Identifier summaries are optional so the external pipeline can
+			// run without the codebase pipeline.
 			var c5 artifact.CodeSymbolsOut
-			if err := deps.Artifact("code_symbols", &c5); err != nil {
+			if _, err := deps.ArtifactIfExists("code_symbols", &c5); err != nil {
+				return nil, err
+			}
+			related, err := relatedRepoSummaries(deps)
+			if err != nil {
 				return nil, err
 			}
 			return artifact.InfraContextIn{
@@ -39,11 +50,12 @@ func BuildRegistryExternal(_ Runtime) map[string]WorkerSpec {
 				Architecture:        m1,
 				IdentifierReports:   c5.Files,
 				ConfidenceThreshold: 0.65,
+				RelatedRepos:        related,
 			}, nil
 		},
 		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
 			ctx = llm.WithWorker(ctx, "infra_context")
-			p := extpipe.InfraContext{LLM: runtime.GetLLM(), RepoFS: runtime.GetRepoFS()}
+			p := extpipe.InfraContext{LLM: runtime.GetLLM(), RepoFS: runtime.GetRepoFS(), Caps: SampleCapsFor(runtime), Detect: InfraDetectFor(runtime)}
 			out, err := p.Run(ctx, in.(artifact.InfraContextIn))
 			if err != nil {
 				return WorkerOutput{}, err
@@ -51,10 +63,22 @@ func BuildRegistryExternal(_ Runtime) map[string]WorkerSpec {
 			return WorkerOutput{RuntimeState: out, ClientView: nil}, nil
 		},
 		Fingerprint: func(in any, runtime Runtime) string {
+			// Detection and cap overrides change the sampled files; they only
+			// join the fingerprint when set, so default caches stay valid.
+			var detect *extpipe.InfraDetect
+			if d := InfraDetectFor(runtime); !d.IsZero() {
+				detect = &d
+			}
+			var caps *extpipe.SampleCaps
+			if c := SampleCapsFor(runtime); c.Default != 0 || len(c.PerExt) > 0 {
+				caps = &c
+			}
 			return JSONFingerprint(struct {
-				In   artifact.InfraContextIn
-				Salt string
-			}{in.(artifact.InfraContextIn), runtime.GetModelSalt()})
+				In     artifact.InfraContextIn
+				Salt   string
+				Detect *extpipe.InfraDetect `json:",omitempty"`
+				Caps   *extpipe.SampleCaps  `json:",omitempty"`
+			}{in.(artifact.InfraContextIn), runtime.GetModelSalt(), detect, caps})
 		},
 		Strategy: jsonStrategy{},
 	}
@@ -62,26 +86,36 @@ func BuildRegistryExternal(_ Runtime) map[string]WorkerSpec {
 	reg["infra_refine"] = WorkerSpec{
 		Key:         "infra_refine",
 		Requires:    []string{"infra_context"},
-		Description: "LLM drills into evidence gaps from infra_context by opening targeted files/snippets.",
+		Description: "LLM drills into evidence gaps from infra_context by opening targeted files/snippets; tracks them across runs in questions.json.",
 		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
 			var prev artifact.InfraContextOut
 			if err := deps.Artifact("infra_context", &prev); err != nil {
 				return nil, err
 			}
-			files := extpipe.CollectGapFiles(deps.Env().GetRepoFS(), deps.Repo(), prev.EvidenceGaps, 24, 64000)
+			ledger, err := readQuestionLedger(ctx, deps.Env())
+			if err != nil {
+				return nil, err
+			}
+			// Questions answered by earlier runs are neither re-opened nor asked.
+			ledger = extpipe.MergeGaps(ledger, prev.EvidenceGaps, "infra_context")
+			gaps := extpipe.OpenGaps(ledger, prev.EvidenceGaps)
+			files := extpipe.CollectGapFiles(deps.Env().GetRepoFS(), deps.Repo(), gaps, 24, 384000, SampleCapsFor(deps.Env()))
 			return artifact.InfraRefineIn{
 				Repo:     deps.Repo(),
 				Previous: prev,
 				Files:    files,
+				Ledger:   ledger,
 			}, nil
 		},
 		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
 			ctx = llm.WithWorker(ctx, "infra_refine")
 			p := extpipe.InfraRefine{LLM: runtime.GetLLM()}
-			out, err := p.Run(ctx, in.(artifact.InfraRefineIn))
+			refineIn := in.(artifact.InfraRefineIn)
+			out, err := p.Run(ctx, refineIn)
 			if err != nil {
 				return WorkerOutput{}, err
 			}
+			writeQuestionLedger(ctx, runtime, extpipe.MergeQuestionStatus(refineIn.Ledger, out.QuestionStatus, refineIn.Files, "infra_refine"))
 			return WorkerOutput{RuntimeState: out, ClientView: nil}, nil
 		},
 		Fingerprint: func(in any, runtime Runtime) string {
@@ -95,3 +129,35 @@ func BuildRegistryExternal(_ Runtime) map[string]WorkerSpec {
 
 	return reg
 }
+
+// maxRelatedIdentifiers bounds the summaries taken from each other repository.
+const maxRelatedIdentifiers = 16
+
+// relatedRepoSummaries collects code_symbols summaries from every other
+// repository of a multi-repo project. Repositories whose code_symbols has
+// not run yet are skipped.
+func relatedRepoSummaries(deps Deps) ([]artifact.RepoIdentifierSummaries, error) {
+	current := ""
+	if multi, ok := deps.Env().(MultiRepoRuntime); ok {
+		current = multi.CurrentRepo()
+	}
+	var out []artifact.RepoIdentifierSummaries
+	for _, repo := range deps.Repos() {
+		if repo == current {
+			continue
+		}
+		var syms artifact.CodeSymbolsOut
+		if err := deps.ArtifactFor(repo, "code_symbols", &syms); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		summaries := extpipe.SelectIdentifierSummaries(syms.Files, repo, artifact.CodeRootsOut{}, maxRelatedIdentifiers)
+		for i := range summaries {
+			summaries[i].Path = repo + ":" + summaries[i].Path
+		}
+		out = append(out, artifact.RepoIdentifierSummaries{Repo: repo, Identifiers: summaries})
+	}
+	return out, nil
+}
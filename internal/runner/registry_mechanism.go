@@ -18,6 +18,22 @@ func RegisterBuilder(b RegistryBuilder) {
 	registryBuilders = append(registryBuilders, b)
 }
 
+// RegisterPipeline registers a builder whose workers belong to pipeline,
+// which is set as the Pipeline of every spec that names none.
+// It should be called in an init() function.
+func RegisterPipeline(pipeline string, b RegistryBuilder) {
+	RegisterBuilder(func(r Runtime) map[string]WorkerSpec {
+		reg := b(r)
+		for key, spec := range reg {
+			if spec.Pipeline == "" {
+				spec.Pipeline = pipeline
+				reg[key] = spec
+			}
+		}
+		return reg
+	})
+}
+
 // BuildAllRegistries builds and merges all registered registries.
 func BuildAllRegistries(r Runtime) SpecResolver {
 	registryBuildersMu.Lock()
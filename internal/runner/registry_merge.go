@@ -3,7 +3,8 @@ package runner
 import "sort"
 
 // MergeRegistries flattens multiple worker registries into a single resolver.
-// It also computes downstream dependencies automatically from 'Requires'.
+// It also computes downstream dependencies automatically from 'Requires' and
+// fills PromptVersion from PromptVersions.
 func MergeRegistries(regs ...map[string]WorkerSpec) SpecResolver {
 	merged := make(map[string]WorkerSpec, 16)
 	downstream := make(map[string][]string)
@@ -11,6 +12,9 @@ func MergeRegistries(regs ...map[string]WorkerSpec) SpecResolver {
 	for _, reg := range regs {
 		for k, v := range reg {
 			nk := normalizeKey(k)
+			if v.PromptVersion == "" {
+				v.PromptVersion = PromptVersions[nk]
+			}
 			merged[nk] = v
 			for _, req := range v.Requires {
 				nr := normalizeKey(req)
@@ -27,5 +31,5 @@ func MergeRegistries(regs ...map[string]WorkerSpec) SpecResolver {
 		}
 	}
 
-	return MapResolver{specs: merged}
+	return newMapResolver(merged)
 }
@@ -2,17 +2,19 @@ package runner
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"insightify/internal/artifact"
+	"insightify/internal/common/logctx"
 	"insightify/internal/llm/middleware"
 	"insightify/internal/workers/plan"
 )
 
 // BuildRegistryPlan builds workers for the plan pipeline.
 func init() {
-	RegisterBuilder(BuildRegistryPlan)
+	RegisterPipeline("plan", BuildRegistryPlan)
 }
 
 func BuildRegistryPlan(_ Runtime) map[string]WorkerSpec {
@@ -23,14 +25,22 @@ func BuildRegistryPlan(_ Runtime) map[string]WorkerSpec {
 		Key:         "bootstrap",
 		Description: "Interactive intent bootstrap worker: collects user intent and repository context.",
 		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
-			return plan.BootstrapIn{}, nil
+			// Carry the transcript of the previous bootstrap turn so follow-up
+			// answers are interpreted with what the user already said.
+			prev := previousBootstrapContext(ctx, deps.Env())
+			return plan.BootstrapIn{Conversation: prev.Conversation, ConversationSession: prev.Session}, nil
 		},
 		Run: func(ctx context.Context, in any, runtime Runtime) (WorkerOutput, error) {
 			ctx = llm.WithWorker(ctx, "bootstrap")
+			bootIn := in.(plan.BootstrapIn)
+			// Record the turns so far first; the reply then streams after
+			// them.
+			recordBootstrapConversation(ctx, bootIn.Transcript())
 			p := plan.BootstrapPipeline{
-				LLM: runtime.GetLLM(),
+				LLM:     runtime.GetLLM(),
+				Emitter: chatChunkEmitter(ctx),
 			}
-			out, err := p.Run(ctx, in.(plan.BootstrapIn))
+			out, err := p.Run(ctx, bootIn)
 			if err != nil {
 				return WorkerOutput{}, err
 			}
@@ -167,6 +177,73 @@ func containsWorkerKey(keys []string, want string) bool {
 	return false
 }
 
+func previousBootstrapContext(ctx context.Context, runtime Runtime) artifact.BootstrapContext {
+	if runtime == nil || runtime.Artifacts() == nil {
+		return artifact.BootstrapContext{}
+	}
+	raw, err := ReadArtifact(ctx, runtime.Artifacts(), "bootstrap.json")
+	if err != nil {
+		return artifact.BootstrapContext{}
+	}
+	var prev plan.BootstrapOut
+	if err := json.Unmarshal(raw, &prev); err != nil {
+		return artifact.BootstrapContext{}
+	}
+	return prev.BootstrapContext.Normalize()
+}
+
+// recordBootstrapConversation records the transcript in the chat of the run,
+// so a client reconnecting to it replays the earlier turns as well.
+func recordBootstrapConversation(ctx context.Context, turns []artifact.ConversationTurn) {
+	waiter, runID, nodeID, ok := chatOfRun(ctx)
+	if !ok {
+		return
+	}
+	recorder, ok := waiter.(ConversationRecorder)
+	if !ok {
+		return
+	}
+	if err := recorder.RecordConversation(ctx, runID, nodeID, turns); err != nil {
+		logctx.Warn(ctx, "record bootstrap conversation failed", "error", err)
+	}
+}
+
+// chatOfRun returns the interaction waiter and the run and node IDs of ctx.
+func chatOfRun(ctx context.Context) (waiter InteractionWaiter, runID, nodeID string, ok bool) {
+	waiter, okWaiter := InteractionWaiterFromContext(ctx)
+	runID, okRun := RunIDFromContext(ctx)
+	nodeID, okNode := NodeIDFromContext(ctx)
+	return waiter, runID, nodeID, okWaiter && okRun && okNode
+}
+
+// chatEmitter streams worker output into the chat of the run.
+type chatEmitter struct {
+	ctx       context.Context
+	publisher ChunkPublisher
+	runID     string
+	nodeID    string
+}
+
+func (e chatEmitter) EmitLLMChunk(chunk string) {
+	if err := e.publisher.PublishOutputChunk(e.ctx, e.runID, e.nodeID, "", chunk); err != nil {
+		logctx.Warn(e.ctx, "publish output chunk failed", "error", err)
+	}
+}
+
+// chatChunkEmitter returns the emitter of the chat of the run, or nil when
+// the run has none.
+func chatChunkEmitter(ctx context.Context) plan.ChunkEmitter {
+	waiter, runID, nodeID, ok := chatOfRun(ctx)
+	if !ok {
+		return nil
+	}
+	publisher, ok := waiter.(ChunkPublisher)
+	if !ok {
+		return nil
+	}
+	return chatEmitter{ctx: ctx, publisher: publisher, runID: runID, nodeID: nodeID}
+}
+
 func bootstrapWorkerOutput(out plan.BootstrapOut) WorkerOutput {
 	return WorkerOutput{
 		RuntimeState: out,
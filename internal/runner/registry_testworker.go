@@ -18,7 +18,7 @@ func (a *interactionAdapter) WaitForInput(ctx context.Context) (string, error) {
 	if a == nil || a.waiter == nil {
 		return "", context.Canceled
 	}
-	return a.waiter.WaitForInput(ctx, a.runID, a.nodeID)
+	return WaitForUserInput(ctx, a.waiter, a.runID, a.nodeID)
 }
 
 func (a *interactionAdapter) PublishOutput(ctx context.Context, message string) error {
@@ -30,7 +30,7 @@ func (a *interactionAdapter) PublishOutput(ctx context.Context, message string)
 
 // BuildRegistryTestWorker wires test-only workers used for interaction prototyping.
 func init() {
-	RegisterBuilder(BuildRegistryTestWorker)
+	RegisterPipeline("testworker", BuildRegistryTestWorker)
 }
 
 func BuildRegistryTestWorker(_ Runtime) map[string]WorkerSpec {
@@ -39,6 +39,9 @@ func BuildRegistryTestWorker(_ Runtime) map[string]WorkerSpec {
 	reg["actBootstrapNode"] = WorkerSpec{
 		Key:         "actBootstrapNode",
 		Description: "Act bootstrap worker for interactive daily conversation loop.",
+		// A chat left unanswered pauses the run instead of failing it; the
+		// user can pick it up again later.
+		InputWait: InputWaitPolicy{OnTimeout: InputTimeoutPause},
 		BuildInput: func(ctx context.Context, deps Deps) (any, error) {
 			return plan.BootstrapIn{}, nil
 		},
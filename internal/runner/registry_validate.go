@@ -0,0 +1,28 @@
+package runner
+
+import "errors"
+
+// ErrMissingDependency is returned by ValidateRegistry when a Requires entry
+// names a worker that no registry defines.
+var ErrMissingDependency = errors.New("missing phase dependency")
+
+// ValidateRegistry checks that every Requires entry in reg resolves to a
+// registered worker and that the dependency graph is acyclic, so a typo fails
+// at startup instead of deep inside an execution. It applies the checks of
+// PhaseGraph.Validate to the graph ExportGraph would draw.
+func ValidateRegistry(reg map[string]WorkerSpec) error {
+	specs := make(map[string]WorkerSpec, len(reg))
+	for k, spec := range reg {
+		spec.Key = normalizeKey(k)
+		specs[spec.Key] = spec
+	}
+	return BuildPhaseGraph(newMapResolver(specs)).Validate()
+}
+
+// ValidateResolver applies the checks of ValidateRegistry to resolver.
+func ValidateResolver(resolver SpecResolver) error {
+	if resolver == nil {
+		return nil
+	}
+	return BuildPhaseGraph(resolver).Validate()
+}
@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/artifact"
+	codepipe "insightify/internal/workers/codebase"
+)
+
+// RepoAssessmentKey is the phase judging whether a repository holds enough
+// code for the phases marked WorkerSpec.RepoGated.
+const RepoAssessmentKey = "repo_assessment"
+
+// Run params of the repository gate. RunParamForce ("true") bypasses it;
+// the thresholds override the defaults of codebase.RepoAssessment.
+const (
+	RunParamForce        = "force"
+	RunParamMinCodeRatio = "min_code_ratio"
+	RunParamMinCodeBytes = "min_code_bytes"
+)
+
+// ErrRepoSkipped matches errors of phases skipped because the repository
+// has too little recognizable code.
+var ErrRepoSkipped = errors.New("repository analysis skipped")
+
+// RepoSkippedError reports a plan stopped by the repository gate. It is an
+// outcome rather than a failure: the assessment was saved as
+// repo_assessment.json and View explains it to the client.
+type RepoSkippedError struct {
+	Assessment artifact.RepoAssessmentOut
+	View       *workerv1.ClientView
+}
+
+func (e *RepoSkippedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrRepoSkipped, strings.Join(e.Assessment.Reasons, "; "))
+}
+
+func (e *RepoSkippedError) Is(target error) bool { return target == ErrRepoSkipped }
+
+// repoGate runs the assessment once per plan, before the first gated phase,
+// and remembers the phases it skipped.
+type repoGate struct {
+	runtime Runtime
+	params  map[string]string
+
+	once sync.Once
+	err  error
+
+	mu      sync.Mutex
+	gated   map[string]bool
+	skipped map[string]bool
+}
+
+type ctxKeyRepoGate struct{}
+
+// withRepoGate attaches the gate to the phases run with ctx, unless
+// RunParamForce is set or the resolver has no RepoAssessmentKey phase.
+func withRepoGate(ctx context.Context, runtime Runtime, params map[string]string) (context.Context, *repoGate) {
+	if force, _ := strconv.ParseBool(strings.TrimSpace(params[RunParamForce])); force {
+		return ctx, nil
+	}
+	if _, ok := runtime.GetResolver().Get(RepoAssessmentKey); !ok {
+		return ctx, nil
+	}
+	g := &repoGate{runtime: runtime, params: params, gated: map[string]bool{}, skipped: map[string]bool{}}
+	return context.WithValue(ctx, ctxKeyRepoGate{}, g), g
+}
+
+func repoGateFrom(ctx context.Context) *repoGate {
+	g, _ := ctx.Value(ctxKeyRepoGate{}).(*repoGate)
+	return g
+}
+
+// check returns the RepoSkippedError of a repository with too little code
+// when spec, or a phase it requires, is RepoGated.
+func (g *repoGate) check(ctx context.Context, spec WorkerSpec) error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	gated := g.isGated(spec.Key, map[string]bool{})
+	g.mu.Unlock()
+	if !gated {
+		return nil
+	}
+	// The assessment runs its own phases through executePhase, so mu must
+	// not be held here.
+	g.once.Do(func() { g.err = g.assess(ctx) })
+	if g.err != nil {
+		g.mu.Lock()
+		g.skipped[normalizeKey(spec.Key)] = true
+		g.mu.Unlock()
+	}
+	return g.err
+}
+
+// isGated reports whether key or anything it requires is RepoGated.
+func (g *repoGate) isGated(key string, visiting map[string]bool) bool {
+	key = normalizeKey(key)
+	if gated, ok := g.gated[key]; ok {
+		return gated
+	}
+	if visiting[key] {
+		return false
+	}
+	visiting[key] = true
+	spec, ok := g.runtime.GetResolver().Get(key)
+	gated := ok && spec.RepoGated
+	for _, req := range spec.Requires {
+		if gated {
+			break
+		}
+		gated = g.isGated(req, visiting)
+	}
+	g.gated[key] = gated
+	return gated
+}
+
+// assess runs the assessment phase, after the artifacts it requires, and
+// turns a skip verdict into a RepoSkippedError.
+func (g *repoGate) assess(ctx context.Context) error {
+	spec, _ := g.runtime.GetResolver().Get(RepoAssessmentKey)
+	for _, req := range spec.Requires {
+		if err := ensureArtifact(withComputeStep(ctx, spec.Key), g.runtime, req, g.params); err != nil {
+			return fmt.Errorf("repo gate: %w", err)
+		}
+	}
+	if _, err := executePhase(ctx, g.runtime, spec, g.params, nil); err != nil {
+		return fmt.Errorf("repo gate: %w", err)
+	}
+	var out artifact.RepoAssessmentOut
+	if err := readArtifact(g.runtime, spec.Key, &out); err != nil {
+		return fmt.Errorf("repo gate: %w", err)
+	}
+	if !out.Skip {
+		return nil
+	}
+	return &RepoSkippedError{Assessment: out, View: codepipe.AssessmentView(out)}
+}
+
+// skipRest reports the gated phases of specs that never ran as ended with the
+// gate's error, so PhaseHooks.OnEnd sees every downstream phase as skipped.
+// OnStart does not fire for them.
+func (g *repoGate) skipRest(ctx context.Context, specs []WorkerSpec) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	var rest []WorkerSpec
+	for _, spec := range specs {
+		key := normalizeKey(spec.Key)
+		if g.err != nil && !g.skipped[key] && g.isGated(key, map[string]bool{}) {
+			g.skipped[key] = true
+			rest = append(rest, spec)
+		}
+	}
+	g.mu.Unlock()
+	for _, spec := range rest {
+		phaseEnded(ctx, spec, g.runtime, WorkerOutput{}, g.err, false)
+	}
+}
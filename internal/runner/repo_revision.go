@@ -0,0 +1,16 @@
+package runner
+
+import (
+	"insightify/internal/common/gitmeta"
+)
+
+// RepoRevision returns the git revision of the repository runtime analyzes,
+// or the zero Info when it is not a git checkout. HEAD is read on every call
+// because a long-lived runtime outlives pulls of its checkout.
+func RepoRevision(runtime Runtime) gitmeta.Info {
+	if runtime == nil || runtime.GetRepoFS() == nil {
+		return gitmeta.Info{}
+	}
+	info, _ := gitmeta.Read(runtime.GetRepoFS().Root())
+	return info
+}
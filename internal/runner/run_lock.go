@@ -0,0 +1,253 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"insightify/internal/common/logctx"
+)
+
+// RunLockName is the lock file a run holds in its OutDir while it executes,
+// so two runs never write the same artifacts at once.
+const RunLockName = ".run.lock"
+
+const (
+	// runLockPoll is how often a waiting run retries the lock.
+	runLockPoll = 50 * time.Millisecond
+	// runLockGrace is how long an unreadable lock file is assumed to be
+	// mid-write by its holder before it counts as left over from a crash.
+	runLockGrace = 5 * time.Second
+)
+
+// ErrRunLocked matches errors of runs refused because another run holds the
+// lock of their OutDir.
+var ErrRunLocked = errors.New("run lock held")
+
+// RunLockError reports the run holding an OutDir lock.
+type RunLockError struct {
+	Dir   string
+	RunID string
+	PID   int
+	Since time.Time
+}
+
+func (e *RunLockError) Error() string {
+	holder := "another run"
+	if e.RunID != "" {
+		holder = "run " + e.RunID
+	}
+	if e.PID > 0 {
+		holder += fmt.Sprintf(" (pid %d)", e.PID)
+	}
+	if !e.Since.IsZero() {
+		holder += " since " + e.Since.Format(time.RFC3339)
+	}
+	locked := "artifacts"
+	if e.Dir != "" {
+		locked = e.Dir
+	}
+	return fmt.Sprintf("%s locked by %s", locked, holder)
+}
+
+func (e *RunLockError) Is(target error) bool { return target == ErrRunLocked }
+
+// runLockInfo is the content of RunLockName.
+type runLockInfo struct {
+	PID        int       `json:"pid"`
+	Host       string    `json:"host,omitempty"`
+	RunID      string    `json:"run_id,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	// ExpiresAt is set on locks kept in an artifact store; see
+	// AcquireStoreRunLock.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// heldRunLocks maps the lock paths this process holds to the content it
+// wrote. A lock file naming this PID that is not in it was left by an earlier
+// process that had the same PID, typically the server before a restart in a
+// container, and is stale.
+var (
+	heldRunLocksMu sync.Mutex
+	heldRunLocks   = map[string]string{}
+)
+
+type ctxKeyRunLockWait struct{}
+
+// WithRunLockWait makes runs wait up to d for the OutDir lock held by another
+// run. Without it, or with d <= 0, they fail at once with a *RunLockError.
+func WithRunLockWait(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyRunLockWait{}, d)
+}
+
+func runLockWait(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(ctxKeyRunLockWait{}).(time.Duration)
+	return max(0, d)
+}
+
+// lockOutDir takes the lock of runtime's OutDir for the run in ctx, or the
+// lock of its artifact store when that is a RunLocker. Other runtimes
+// without an OutDir are not locked.
+func lockOutDir(ctx context.Context, runtime Runtime) (func(), error) {
+	runID, _ := RunIDFromContext(ctx)
+	if locker, ok := runtime.Artifacts().(RunLocker); ok {
+		return locker.LockRun(ctx, runID, runLockWait(ctx))
+	}
+	dir := strings.TrimSpace(runtime.GetOutDir())
+	if dir == "" {
+		return func() {}, nil
+	}
+	return AcquireRunLock(ctx, dir, runID, runLockWait(ctx))
+}
+
+// AcquireRunLock creates RunLockName in dir, recording this process and
+// runID. While another live run holds it, AcquireRunLock polls until wait
+// elapses and then returns a *RunLockError naming the holder. Locks whose
+// process on this host is gone are broken. The returned release removes the
+// lock unless it was broken and taken over meanwhile. A lock recording this
+// process's PID that it does not hold is broken as well.
+func AcquireRunLock(ctx context.Context, dir, runID string, wait time.Duration) (func(), error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create run lock dir: %w", err)
+	}
+	path := filepath.Join(dir, RunLockName)
+	host, _ := os.Hostname()
+	content, err := json.Marshal(runLockInfo{PID: os.Getpid(), Host: host, RunID: runID, AcquiredAt: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		err := createHeldRunLock(path, content)
+		if err == nil {
+			return func() { releaseRunLock(path, content) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("acquire run lock: %w", err)
+		}
+		held, raw, stale := readRunLock(path, host)
+		if stale {
+			if raw != nil {
+				if err := breakRunLock(path, raw); err == nil {
+					logctx.Warn(ctx, "broke stale run lock", "dir", dir, "holder_run_id", held.RunID, "holder_pid", held.PID)
+				} else if !errors.Is(err, errLockChanged) {
+					return nil, fmt.Errorf("break stale run lock: %w", err)
+				}
+			}
+			continue
+		}
+		lockErr := &RunLockError{Dir: dir, RunID: held.RunID, PID: held.PID, Since: held.AcquiredAt}
+		if wait <= 0 || !time.Now().Before(deadline) {
+			return nil, lockErr
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ctx.Err(), lockErr)
+		case <-time.After(min(runLockPoll, time.Until(deadline))):
+		}
+	}
+}
+
+// createHeldRunLock creates the lock file and records it as held by this
+// process. Both happen under heldRunLocksMu so readRunLock never sees the new
+// file without its record.
+func createHeldRunLock(path string, content []byte) error {
+	heldRunLocksMu.Lock()
+	defer heldRunLocksMu.Unlock()
+	if err := createExclusive(path, content); err != nil {
+		return err
+	}
+	heldRunLocks[path] = string(content)
+	return nil
+}
+
+func createExclusive(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// readRunLock returns the holder recorded in path and whether the lock is
+// stale. A missing file is reported as stale with nil raw so the caller
+// simply retries the create.
+func readRunLock(path, host string) (runLockInfo, []byte, bool) {
+	var held runLockInfo
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return held, nil, errors.Is(err, fs.ErrNotExist)
+	}
+	if json.Unmarshal(raw, &held) != nil || held.PID <= 0 {
+		// The holder may still be writing it; only give up on old files.
+		st, err := os.Stat(path)
+		return runLockInfo{}, raw, err == nil && time.Since(st.ModTime()) > runLockGrace
+	}
+	if held.Host != "" && host != "" && held.Host != host {
+		// A process on another host sharing the volume cannot be checked.
+		return held, raw, false
+	}
+	if held.PID == os.Getpid() {
+		heldRunLocksMu.Lock()
+		defer heldRunLocksMu.Unlock()
+		return held, raw, heldRunLocks[path] != string(raw)
+	}
+	return held, raw, !processAlive(held.PID)
+}
+
+// errLockChanged reports a stale lock another run broke and retook first.
+var errLockChanged = errors.New("run lock changed")
+
+// breakRunLock removes a stale lock, unless its content changed since it was
+// judged stale.
+func breakRunLock(path string, stale []byte) error {
+	current, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) || err == nil && !bytes.Equal(current, stale) {
+		return errLockChanged
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func releaseRunLock(path string, content []byte) {
+	heldRunLocksMu.Lock()
+	defer heldRunLocksMu.Unlock()
+	if heldRunLocks[path] == string(content) {
+		delete(heldRunLocks, path)
+	}
+	current, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(current, content) {
+		return
+	}
+	_ = os.Remove(path)
+}
+
+// processAlive reports whether pid runs on this host. EPERM means it exists
+// but belongs to another user.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,162 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"insightify/internal/common/logctx"
+)
+
+// storeRunLockTTL is how long a run lock kept in an artifact store stays
+// valid unless its holder renews it, which it does every third of that.
+const storeRunLockTTL = 30 * time.Second
+
+// RunLocker is implemented by artifact stores that hold the run lock
+// themselves, such as a bucket shared by several gateways, where a file in
+// the local OutDir would not exclude runs on other hosts.
+type RunLocker interface {
+	LockRun(ctx context.Context, runID string, wait time.Duration) (func(), error)
+}
+
+// AcquireStoreRunLock takes RunLockName in store, which must be an
+// ArtifactCreator so that taking it is atomic. The holder's process renews
+// the lock's expiry until release; a lock past its expiry, or whose process
+// on this host is gone, is broken. Waiting and the returned release work as
+// in AcquireRunLock.
+func AcquireStoreRunLock(ctx context.Context, store ArtifactStore, runID string, wait time.Duration) (func(), error) {
+	creator, ok := store.(ArtifactCreator)
+	if !ok {
+		return nil, fmt.Errorf("acquire run lock: %T cannot create artifacts exclusively", store)
+	}
+	host, _ := os.Hostname()
+	info := runLockInfo{PID: os.Getpid(), Host: host, RunID: runID, AcquiredAt: time.Now().UTC()}
+	deadline := time.Now().Add(wait)
+	for {
+		info.ExpiresAt = time.Now().Add(storeRunLockTTL).UTC()
+		content, err := json.Marshal(info)
+		if err != nil {
+			return nil, err
+		}
+		err = creator.Create(ctx, RunLockName, content)
+		if err == nil {
+			return holdStoreRunLock(ctx, store, info, content), nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("acquire run lock: %w", err)
+		}
+		held, raw, stale := readStoreRunLock(ctx, store, host)
+		if stale {
+			if raw != nil {
+				if err := breakStoreRunLock(ctx, store, raw); err == nil {
+					logctx.Warn(ctx, "broke stale run lock", "holder_run_id", held.RunID, "holder_pid", held.PID, "holder_host", held.Host)
+				} else if !errors.Is(err, errLockChanged) {
+					return nil, fmt.Errorf("break stale run lock: %w", err)
+				}
+			}
+			continue
+		}
+		lockErr := &RunLockError{RunID: held.RunID, PID: held.PID, Since: held.AcquiredAt}
+		if wait <= 0 || !time.Now().Before(deadline) {
+			return nil, lockErr
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", ctx.Err(), lockErr)
+		case <-time.After(min(runLockPoll, time.Until(deadline))):
+		}
+	}
+}
+
+// holdStoreRunLock renews the lock written as content until the returned
+// release, which removes it unless another run took it over meanwhile.
+func holdStoreRunLock(ctx context.Context, store ArtifactStore, info runLockInfo, content []byte) func() {
+	// The run's own cancellation must not stop the renewal or the removal.
+	ctx = context.WithoutCancel(ctx)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(storeRunLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			current, err := store.Read(ctx, RunLockName)
+			if err != nil || !bytes.Equal(current, content) {
+				logctx.Warn(ctx, "lost run lock", "run_id", info.RunID)
+				return
+			}
+			info.ExpiresAt = time.Now().Add(storeRunLockTTL).UTC()
+			next, err := json.Marshal(info)
+			if err != nil {
+				continue
+			}
+			if err := store.Write(ctx, RunLockName, next); err != nil {
+				logctx.Warn(ctx, "failed to renew run lock", "run_id", info.RunID, "error", err)
+				continue
+			}
+			content = next
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-stopped
+			current, err := store.Read(ctx, RunLockName)
+			if err != nil || !bytes.Equal(current, content) {
+				return
+			}
+			_ = store.Remove(ctx, RunLockName)
+		})
+	}
+}
+
+// readStoreRunLock returns the holder recorded in store and whether the lock
+// is stale. Store writes are atomic, so a lock that does not decode was not
+// written by a run and is stale as well.
+func readStoreRunLock(ctx context.Context, store ArtifactStore, host string) (runLockInfo, []byte, bool) {
+	var held runLockInfo
+	raw, err := store.Read(ctx, RunLockName)
+	if err != nil {
+		return held, nil, errors.Is(err, fs.ErrNotExist)
+	}
+	if json.Unmarshal(raw, &held) != nil || held.ExpiresAt.IsZero() {
+		return runLockInfo{}, raw, true
+	}
+	if time.Now().After(held.ExpiresAt) {
+		return held, raw, true
+	}
+	if held.Host != "" && held.Host == host && held.PID != os.Getpid() {
+		return held, raw, !processAlive(held.PID)
+	}
+	return held, raw, false
+}
+
+// breakStoreRunLock removes a stale lock, unless its content changed since it
+// was judged stale. Stores have no conditional delete, so a run that retakes
+// the lock between the check and the removal loses it; its renewal then
+// reports the lost lock.
+func breakStoreRunLock(ctx context.Context, store ArtifactStore, stale []byte) error {
+	current, err := store.Read(ctx, RunLockName)
+	if errors.Is(err, fs.ErrNotExist) || err == nil && !bytes.Equal(current, stale) {
+		return errLockChanged
+	}
+	if err != nil {
+		return err
+	}
+	if err := store.Remove(ctx, RunLockName); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -1,9 +1,13 @@
 package runner
 
 import (
+	"fmt"
+	"strings"
+
 	"insightify/internal/common/safeio"
 	llmclient "insightify/internal/llm/client"
 	"insightify/internal/mcp"
+	extpipe "insightify/internal/workers/external"
 )
 
 // Runtime is the execution context required by runner and worker specs.
@@ -19,3 +23,64 @@ type Runtime interface {
 	GetDepsUsage() DepsUsageMode
 	GetLLM() llmclient.LLMClient
 }
+
+// MultiRepoRuntime is implemented by runtimes of projects with several
+// repositories. ForRepo returns a view whose RepoFS is that repository and
+// whose artifacts live in its own namespace; the default repository keeps the
+// single-repo layout. CurrentRepo names the repository the view is bound to.
+type MultiRepoRuntime interface {
+	Runtime
+	RepoNames() []string
+	CurrentRepo() string
+	ForRepo(name string) (Runtime, bool)
+}
+
+// RuntimeForRepo resolves repo on runtime. An empty repo, or a runtime that
+// is not multi-repo when repo is empty, yields runtime itself.
+func RuntimeForRepo(runtime Runtime, repo string) (Runtime, error) {
+	repo = strings.TrimSpace(repo)
+	if repo == "" {
+		return runtime, nil
+	}
+	multi, ok := runtime.(MultiRepoRuntime)
+	if !ok {
+		return nil, fmt.Errorf("runtime has no repo %q", repo)
+	}
+	rt, ok := multi.ForRepo(repo)
+	if !ok {
+		return nil, fmt.Errorf("unknown repo %q (have %s)", repo, strings.Join(multi.RepoNames(), ", "))
+	}
+	return rt, nil
+}
+
+// SampleCapsRuntime is implemented by runtimes that override the per-file byte
+// caps used when sampling infra and gap files.
+type SampleCapsRuntime interface {
+	Runtime
+	GetSampleCaps() extpipe.SampleCaps
+}
+
+// InfraDetectRuntime is implemented by runtimes that extend the files and
+// directories infra sampling treats as infra, or deny noise among them.
+type InfraDetectRuntime interface {
+	Runtime
+	GetInfraDetect() extpipe.InfraDetect
+}
+
+// InfraDetectFor returns the infra detection overrides of runtime, or the
+// zero value (the built-in sets) when it has none.
+func InfraDetectFor(runtime Runtime) extpipe.InfraDetect {
+	if rt, ok := runtime.(InfraDetectRuntime); ok {
+		return rt.GetInfraDetect()
+	}
+	return extpipe.InfraDetect{}
+}
+
+// SampleCapsFor returns the sample caps of runtime, or the zero value (which
+// selects extpipe.DefaultSampleCaps) when it has none.
+func SampleCapsFor(runtime Runtime) extpipe.SampleCaps {
+	if rt, ok := runtime.(SampleCapsRuntime); ok {
+		return rt.GetSampleCaps()
+	}
+	return extpipe.SampleCaps{}
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"insightify/internal/common/logctx"
+	"insightify/internal/common/scheduler"
+)
+
+// ScheduleTraceName is the artifact scheduler-driven workers write their
+// chunk decisions to when ScheduleTraceEnv is enabled.
+const ScheduleTraceName = "schedule_trace.json"
+
+// ScheduleTraceEnv enables schedule traces (any strconv.ParseBool true value).
+const ScheduleTraceEnv = "SCHEDULE_TRACE"
+
+// scheduleTraceEnabled reports whether ScheduleTraceEnv is set.
+func scheduleTraceEnabled() bool {
+	on, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv(ScheduleTraceEnv)))
+	return on
+}
+
+// newScheduleRecorder returns a recorder when tracing is enabled, or nil.
+func newScheduleRecorder(capPerChunk int) *scheduler.TraceRecorder {
+	if !scheduleTraceEnabled() {
+		return nil
+	}
+	return scheduler.NewTraceRecorder(capPerChunk)
+}
+
+// writeScheduleTrace stores rec's trace as ScheduleTraceName. Failures are
+// logged only; the trace is a debugging aid.
+func writeScheduleTrace(ctx context.Context, runtime Runtime, rec *scheduler.TraceRecorder) {
+	if rec == nil || runtime == nil || runtime.Artifacts() == nil {
+		return
+	}
+	b, err := json.MarshalIndent(rec.Trace(), "", "  ")
+	if err == nil {
+		err = runtime.Artifacts().Write(ctx, ScheduleTraceName, b)
+	}
+	if err != nil {
+		logctx.Warn(ctx, "write schedule trace failed", "error", err)
+	}
+}
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"sort"
+	"strings"
 )
 
 // SpecResolver resolves worker keys to specs, enabling cross-registry lookup.
@@ -10,9 +11,34 @@ type SpecResolver interface {
 	List() []WorkerSpec
 }
 
+// TagResolver is implemented by resolvers that index their specs by tag (the
+// tags of ExportGraph, e.g. "pipeline:codebase" or "cache:json").
+type TagResolver interface {
+	SpecResolver
+	ListByTag(tag string) []WorkerSpec
+}
+
 // MapResolver is a simple SpecResolver backed by a map keyed by normalized worker keys.
+// Keys in order and the tag index are computed once, when the resolver is built.
 type MapResolver struct {
 	specs map[string]WorkerSpec
+	order []string            // sorted keys
+	byTag map[string][]string // lower-cased tag -> sorted keys
+}
+
+func newMapResolver(specs map[string]WorkerSpec) MapResolver {
+	r := MapResolver{specs: specs, order: make([]string, 0, len(specs)), byTag: map[string][]string{}}
+	for k := range specs {
+		r.order = append(r.order, k)
+	}
+	sort.Slice(r.order, func(i, j int) bool { return specs[r.order[i]].Key < specs[r.order[j]].Key })
+	for _, k := range r.order {
+		for _, tag := range specTags(specs[k]) {
+			tag = strings.ToLower(tag)
+			r.byTag[tag] = append(r.byTag[tag], k)
+		}
+	}
+	return r
 }
 
 // Get returns the WorkerSpec for the provided key, if present.
@@ -24,12 +50,43 @@ func (r MapResolver) Get(key string) (WorkerSpec, bool) {
 	return spec, ok
 }
 
-// List returns all registered worker specs.
+// List returns all registered worker specs, sorted by key.
 func (r MapResolver) List() []WorkerSpec {
-	specs := make([]WorkerSpec, 0, len(r.specs))
-	for _, s := range r.specs {
-		specs = append(specs, s)
+	return r.collect(r.order)
+}
+
+// ListByTag returns the specs carrying tag, compared case-insensitively,
+// sorted by key.
+func (r MapResolver) ListByTag(tag string) []WorkerSpec {
+	return r.collect(r.byTag[strings.ToLower(strings.TrimSpace(tag))])
+}
+
+func (r MapResolver) collect(keys []string) []WorkerSpec {
+	specs := make([]WorkerSpec, 0, len(keys))
+	for _, k := range keys {
+		specs = append(specs, r.specs[k])
 	}
-	sort.Slice(specs, func(i, j int) bool { return specs[i].Key < specs[j].Key })
 	return specs
 }
+
+// ListByTag returns the specs of resolver carrying tag, sorted by key. It uses
+// the index of a TagResolver and scans List otherwise.
+func ListByTag(resolver SpecResolver, tag string) []WorkerSpec {
+	if resolver == nil {
+		return nil
+	}
+	if tr, ok := resolver.(TagResolver); ok {
+		return tr.ListByTag(tag)
+	}
+	var out []WorkerSpec
+	for _, spec := range resolver.List() {
+		for _, t := range specTags(spec) {
+			if strings.EqualFold(t, strings.TrimSpace(tag)) {
+				out = append(out, spec)
+				break
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
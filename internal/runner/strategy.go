@@ -2,12 +2,14 @@ package runner
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
-	"regexp"
 	"strings"
 	"time"
+
+	"insightify/internal/common/logctx"
 )
 
 // --------------------- JSON file strategy ---------------------
@@ -17,10 +19,60 @@ type jsonStrategy struct{}
 // JSONStrategy returns the standard JSON caching strategy.
 func JSONStrategy() CacheStrategy { return jsonStrategy{} }
 
+// cacheMeta is written after the artifact it describes. Output is the digest
+// of that artifact, so a reader racing a save never pairs a meta with
+// another run's output.
 type cacheMeta struct {
-	Inputs    string    `json:"inputs"`
-	Salt      string    `json:"salt,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	Inputs        string    `json:"inputs"`
+	Salt          string    `json:"salt,omitempty"`
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	Version       int       `json:"version,omitempty"` // vN that latest points to (versioned strategy)
+	Output        string    `json:"output,omitempty"`
+	GitCommit     string    `json:"git_commit,omitempty"` // HEAD of the analyzed repo, when a git checkout
+	GitBranch     string    `json:"git_branch,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// newCacheMeta returns the meta of an artifact saved now by spec.
+func newCacheMeta(spec WorkerSpec, runtime Runtime, inputFP string) cacheMeta {
+	rev := RepoRevision(runtime)
+	return cacheMeta{
+		Inputs:        inputFP,
+		Salt:          runtime.GetModelSalt(),
+		PromptVersion: spec.PromptVersion,
+		GitCommit:     rev.Commit,
+		GitBranch:     rev.Branch,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// outputDigest identifies the artifact bytes a cacheMeta belongs to.
+func outputDigest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// matches reports whether m describes out, read alongside it. Metas written
+// before Output was recorded match any output.
+func (m cacheMeta) matches(out []byte) bool {
+	return m.Output == "" || m.Output == outputDigest(out)
+}
+
+// writeOutputAndMeta writes the artifact first and its meta last, so the meta
+// only ever points at a complete artifact; out is split into parts of chunk bytes when chunk is positive.
+func writeOutputAndMeta(ctx context.Context, artifacts ArtifactStore, outName string, out []byte, chunk int64, metaName string, meta cacheMeta) error {
+	if err := writeArtifact(ctx, artifacts, outName, out, chunk); err != nil {
+		return fmt.Errorf("write %s: %w", outName, err)
+	}
+	meta.Output = outputDigest(out)
+	mb, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := artifacts.Write(ctx, metaName, mb); err != nil {
+		return fmt.Errorf("write %s: %w", metaName, err)
+	}
+	return nil
 }
 
 func (s jsonStrategy) TryLoad(ctx context.Context, spec WorkerSpec, runtime Runtime, inputFP string) (WorkerOutput, bool) {
@@ -38,15 +90,15 @@ func (s jsonStrategy) TryLoad(ctx context.Context, spec WorkerSpec, runtime Runt
 	if err != nil {
 		return zero, false
 	}
-	ob, err := artifacts.Read(ctx, outName)
+	ob, err := ReadArtifact(ctx, artifacts, outName)
 	if err != nil {
 		return zero, false
 	}
 	var m cacheMeta
-	if json.Unmarshal(mb, &m) == nil && m.Inputs == inputFP && m.Salt == runtime.GetModelSalt() {
+	if json.Unmarshal(mb, &m) == nil && m.Inputs == inputFP && m.Salt == runtime.GetModelSalt() && m.PromptVersion == spec.PromptVersion && m.matches(ob) {
 		var out any
 		if json.Unmarshal(ob, &out) == nil {
-			log.Printf("%s: using cache → %s", strings.ToUpper(spec.Key), outName)
+			logctx.Info(ctx, "worker cache hit", "artifact", outName)
 			return WorkerOutput{RuntimeState: out, ClientView: nil}, true
 		}
 	}
@@ -60,12 +112,15 @@ func (s jsonStrategy) Save(ctx context.Context, spec WorkerSpec, runtime Runtime
 	}
 	metaName := spec.Key + ".meta.json"
 	outName := spec.Key + ".json"
-	if b, e := json.MarshalIndent(out.RuntimeState, "", "  "); e == nil {
-		_ = artifacts.Write(ctx, outName, b)
+	b, chunk, err := encodeGuarded(ctx, spec, out.RuntimeState)
+	if err != nil {
+		return err
 	}
-	mb, _ := json.MarshalIndent(cacheMeta{Inputs: inputFP, Salt: runtime.GetModelSalt(), CreatedAt: time.Now()}, "", "  ")
-	_ = artifacts.Write(ctx, metaName, mb)
-	log.Printf("%s → %s", strings.ToUpper(spec.Key), outName)
+	meta := newCacheMeta(spec, runtime, inputFP)
+	if err := writeOutputAndMeta(ctx, artifacts, outName, b, chunk, metaName, meta); err != nil {
+		return err
+	}
+	logctx.Info(ctx, "worker output saved", "artifact", outName)
 	return nil
 }
 
@@ -76,13 +131,15 @@ func (s jsonStrategy) Invalidate(ctx context.Context, spec WorkerSpec, runtime R
 	}
 	_ = artifacts.Remove(ctx, spec.Key+".json")
 	_ = artifacts.Remove(ctx, spec.Key+".meta.json")
+	_ = removeParts(ctx, artifacts, spec.Key+".json", 0)
 	return nil
 }
 
 // --------------------- Versioned JSON strategy -------------------------
 
-// versionedStrategy always writes a new versioned file and updates latest.
-// Cache read is intentionally disabled (exploratory / append-only).
+// versionedStrategy appends a new <key>_vN.json on every save, updates the
+// latest <key>.json and keeps the newest versions (see versionRetention).
+// Cache read is intentionally disabled (exploratory).
 type versionedStrategy struct{}
 
 // VersionedStrategy returns the versioned (no-cache) strategy.
@@ -93,33 +150,31 @@ func (versionedStrategy) TryLoad(ctx context.Context, spec WorkerSpec, runtime R
 	return WorkerOutput{}, false
 }
 
-func (versionedStrategy) Save(ctx context.Context, spec WorkerSpec, runtime Runtime, out WorkerOutput, inputFP string) error {
-	// Always start at v1 for each run; overwrite v1 and latest, and optionally prune older versions.
-	versioned := fmt.Sprintf("%s_v1.json", spec.Key)
-	latest := spec.Key + ".json"
+func (s versionedStrategy) Save(ctx context.Context, spec WorkerSpec, runtime Runtime, out WorkerOutput, inputFP string) error {
 	artifacts := runtime.Artifacts()
 	if artifacts == nil {
 		return fmt.Errorf("artifact access is nil")
 	}
-
-	if b, e := json.MarshalIndent(out.RuntimeState, "", "  "); e == nil {
-		_ = artifacts.Write(ctx, versioned, b)
-		_ = artifacts.Write(ctx, latest, b)
+	b, chunk, err := encodeGuarded(ctx, spec, out.RuntimeState)
+	if err != nil {
+		return err
 	}
-	// meta is optional for versioned write; record last inputs for debugging
-	metaName := spec.Key + ".meta.json"
-	mb, _ := json.MarshalIndent(cacheMeta{Inputs: inputFP, Salt: runtime.GetModelSalt(), CreatedAt: time.Now()}, "", "  ")
-	_ = artifacts.Write(ctx, metaName, mb)
-
-	// Best-effort pruning of other versions
-	entries, _ := artifacts.List(ctx)
-	re := regexp.MustCompile(fmt.Sprintf(`^%s_v(\d+)\.json$`, regexp.QuoteMeta(spec.Key)))
-	for _, name := range entries {
-		if m := re.FindStringSubmatch(name); len(m) == 2 && name != versioned {
-			_ = artifacts.Remove(ctx, name)
-		}
+	version, err := writeNextVersion(ctx, artifacts, spec.Key, b, chunk)
+	if err != nil {
+		return err
+	}
+	latest := spec.Key + ".json"
+	// meta records the last inputs and the version latest points to
+	meta := newCacheMeta(spec, runtime, inputFP)
+	meta.Version = version
+	if err := writeOutputAndMeta(ctx, artifacts, latest, b, chunk, spec.Key+".meta.json", meta); err != nil {
+		return err
+	}
+
+	if err := pruneVersions(ctx, artifacts, spec.Key, versionRetention()); err != nil {
+		logctx.Warn(ctx, "prune worker versions failed", "worker", spec.Key, "error", err)
 	}
-	log.Printf("%s → %s (reset to v1; updated %s)", strings.ToUpper(spec.Key), versioned, latest)
+	logctx.Info(ctx, "worker output saved", "artifact", VersionedName(spec.Key, version), "latest", latest)
 	return nil
 }
 
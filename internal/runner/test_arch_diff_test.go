@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+// archDiffRuntime resolves the real arch_diff phase over an arch_design stub
+// returning *head and recording its base as arch_design does.
+func archDiffRuntime(t *testing.T, outDir string, head *artifact.ArchDesignOut) *testRuntime {
+	t.Helper()
+	reg := BuildRegistryArchitecture(nil)
+	reg["arch_design"] = WorkerSpec{
+		Key:        "arch_design",
+		BuildInput: func(context.Context, Deps) (any, error) { return *head, nil },
+		Run: func(ctx context.Context, in any, rt Runtime) (WorkerOutput, error) {
+			recordArchDesignBase(ctx, rt)
+			return WorkerOutput{RuntimeState: in}, nil
+		},
+		Strategy: jsonStrategy{},
+	}
+	return &testRuntime{outDir: outDir, resolver: MergeRegistries(reg)}
+}
+
+func readArchDiff(t *testing.T, outDir string) artifact.ArchDiffOut {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(outDir, "arch_diff.json"))
+	if err != nil {
+		t.Fatalf("read arch_diff.json: %v", err)
+	}
+	var out artifact.ArchDiffOut
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("decode arch_diff.json: %v", err)
+	}
+	return out
+}
+
+func TestArchDiffComparesWithReplacedArchDesign(t *testing.T) {
+	outDir := t.TempDir()
+	var head artifact.ArchDesignOut
+	head.ArchitectureHypothesis.KeyComponents = []artifact.ArchDesignKeyComponent{
+		{Name: "Job Runner", Evidence: []artifact.EvidenceRef{{Path: "jobs/run.go"}}},
+		{Name: "Billing", Evidence: []artifact.EvidenceRef{{Path: "billing/invoice.go"}}},
+	}
+	run := func() artifact.ArchDiffOut {
+		t.Helper()
+		rt := archDiffRuntime(t, outDir, &head)
+		if _, err := ExecutePlan(context.Background(), rt, []string{"arch_design", "arch_diff"}, nil); err != nil {
+			t.Fatalf("ExecutePlan() error = %v", err)
+		}
+		return readArchDiff(t, outDir)
+	}
+
+	if first := run(); first.HasBase || len(first.Added) != 0 || len(first.Head.ArchitectureHypothesis.KeyComponents) != 2 {
+		t.Fatalf("first run = %+v, want no base and the head kept", first)
+	}
+
+	head.ArchitectureHypothesis.KeyComponents = []artifact.ArchDesignKeyComponent{
+		{Name: "Task Executor", Evidence: []artifact.EvidenceRef{{Path: "jobs/run.go"}}},
+	}
+	second := run()
+	if !second.HasBase {
+		t.Fatalf("second run has no base")
+	}
+	if !reflect.DeepEqual(second.Renamed, []artifact.Rename{{From: "Job Runner", To: "Task Executor"}}) {
+		t.Fatalf("Renamed = %+v", second.Renamed)
+	}
+	if !reflect.DeepEqual(second.Removed, []string{"Billing"}) || len(second.Added) != 0 {
+		t.Fatalf("added %v, removed %v; want only Billing removed", second.Added, second.Removed)
+	}
+
+	// An unchanged arch_design is served from its cache, so the changes it
+	// made stay the ones reported.
+	if third := run(); !reflect.DeepEqual(third.Removed, second.Removed) || !reflect.DeepEqual(third.Renamed, second.Renamed) {
+		t.Fatalf("third run = %+v, want the changes of the cached arch_design", third)
+	}
+
+	// The next arch_design run compares with the second head, even without
+	// the arch_diff output that reported it.
+	if err := os.Remove(filepath.Join(outDir, "arch_diff.json")); err != nil {
+		t.Fatal(err)
+	}
+	head.ArchitectureHypothesis.KeyComponents = append(head.ArchitectureHypothesis.KeyComponents,
+		artifact.ArchDesignKeyComponent{Name: "Scheduler", Evidence: []artifact.EvidenceRef{{Path: "jobs/cron.go"}}})
+	fourth := run()
+	if !reflect.DeepEqual(fourth.Added, []string{"Scheduler"}) || len(fourth.Removed) != 0 || len(fourth.Renamed) != 0 {
+		t.Fatalf("fourth run = %+v, want only Scheduler added", fourth)
+	}
+}
@@ -0,0 +1,142 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"insightify/internal/workerruntime/artifactfs"
+)
+
+// bigOutput encodes to a few kilobytes of JSON.
+func bigOutput() map[string][]string {
+	out := map[string][]string{}
+	for i := 0; i < 20; i++ {
+		out[fmt.Sprintf("file%02d.go", i)] = []string{strings.Repeat("identifier", 10), fmt.Sprint(i)}
+	}
+	return out
+}
+
+func newSizeRuntime(t *testing.T) *testRuntime {
+	t.Helper()
+	rt := &testRuntime{outDir: t.TempDir()}
+	rt.artifact = artifactfs.NewFileStore(rt.outDir)
+	return rt
+}
+
+func TestSaveWarnsAboveSoftLimit(t *testing.T) {
+	t.Setenv(ArtifactWarnBytesEnv, "100")
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	ctx := context.Background()
+	rt := newSizeRuntime(t)
+	spec := WorkerSpec{Key: "code_symbols"}
+	if err := JSONStrategy().Save(ctx, spec, rt, WorkerOutput{RuntimeState: bigOutput()}, "fp"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), `"msg":"large worker output"`) || !strings.Contains(logs.String(), `"worker":"code_symbols"`) {
+		t.Fatalf("logs = %s, want a large output warning for code_symbols", logs.String())
+	}
+	raw, err := rt.artifact.Read(ctx, "code_symbols.json")
+	if err != nil || bytes.Contains(raw, []byte("\n")) {
+		t.Fatalf("large output should be saved compact: %v\n%s", err, raw)
+	}
+	var got map[string][]string
+	if err := newDeps(rt, WorkerSpec{Key: "w", Requires: []string{"code_symbols"}}, nil).Artifact("code_symbols", &got); err != nil || !reflect.DeepEqual(got, bigOutput()) {
+		t.Fatalf("Artifact() = %v, %v; want the saved output", got, err)
+	}
+}
+
+func TestSaveFailsAboveHardLimit(t *testing.T) {
+	ctx := context.Background()
+	for _, strategy := range []CacheStrategy{JSONStrategy(), VersionedStrategy()} {
+		rt := newSizeRuntime(t)
+		spec := WorkerSpec{Key: "code_symbols", MaxArtifactBytes: 256}
+		err := strategy.Save(ctx, spec, rt, WorkerOutput{RuntimeState: bigOutput()}, "fp")
+		var tooLarge *ArtifactTooLargeError
+		if !errors.Is(err, ErrArtifactTooLarge) || !errors.As(err, &tooLarge) || tooLarge.Phase != "code_symbols" || tooLarge.Limit != 256 {
+			t.Fatalf("%T Save() error = %v, want ErrArtifactTooLarge for code_symbols", strategy, err)
+		}
+		if names, _ := rt.artifact.List(ctx); len(names) != 0 {
+			t.Fatalf("%T wrote %v for a refused output", strategy, names)
+		}
+	}
+
+	t.Setenv(ArtifactMaxBytesEnv, "256")
+	rt := newSizeRuntime(t)
+	if err := JSONStrategy().Save(ctx, WorkerSpec{Key: "k"}, rt, WorkerOutput{RuntimeState: bigOutput()}, "fp"); !errors.Is(err, ErrArtifactTooLarge) {
+		t.Fatalf("Save() with %s error = %v, want ErrArtifactTooLarge", ArtifactMaxBytesEnv, err)
+	}
+	if err := JSONStrategy().Save(ctx, WorkerSpec{Key: "k", MaxArtifactBytes: -1}, rt, WorkerOutput{RuntimeState: bigOutput()}, "fp"); err != nil {
+		t.Fatalf("Save() without limit error = %v", err)
+	}
+}
+
+func TestChunkedArtifactRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	rt := newSizeRuntime(t)
+	spec := WorkerSpec{Key: "code_symbols", MaxArtifactBytes: 512, ChunkedArtifact: true}
+	strategy := JSONStrategy()
+	if err := strategy.Save(ctx, spec, rt, WorkerOutput{RuntimeState: bigOutput()}, "fp"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	names, _ := rt.artifact.List(ctx)
+	parts := 0
+	for _, n := range names {
+		if strings.HasPrefix(n, "code_symbols.part-") {
+			parts++
+			if raw, _ := rt.artifact.Read(ctx, n); len(raw) > 512 {
+				t.Fatalf("part %s is %d bytes, above the limit", n, len(raw))
+			}
+		}
+	}
+	if parts < 2 {
+		t.Fatalf("artifacts = %v, want the output split into parts", names)
+	}
+
+	var got map[string][]string
+	if err := newDeps(rt, WorkerSpec{Key: "w", Requires: []string{"code_symbols"}}, nil).Artifact("code_symbols", &got); err != nil || !reflect.DeepEqual(got, bigOutput()) {
+		t.Fatalf("Artifact() = %v, %v; want the reassembled output", got, err)
+	}
+	if _, ok := strategy.TryLoad(ctx, spec, rt, "fp"); !ok {
+		t.Fatalf("TryLoad() missed a chunked cache entry")
+	}
+
+	// A smaller save leaves no stale parts behind.
+	if err := strategy.Save(ctx, spec, rt, WorkerOutput{RuntimeState: map[string]int{"small": 1}}, "fp2"); err != nil {
+		t.Fatalf("Save(small) error = %v", err)
+	}
+	if names, _ := rt.artifact.List(ctx); !reflect.DeepEqual(names, []string{"code_symbols.json", "code_symbols.meta.json"}) {
+		t.Fatalf("artifacts after small save = %v", names)
+	}
+}
+
+func TestVersionedStrategyChunksVersions(t *testing.T) {
+	ctx := context.Background()
+	rt := newSizeRuntime(t)
+	spec := WorkerSpec{Key: "code_roots", MaxArtifactBytes: 512, ChunkedArtifact: true}
+	t.Setenv(VersionRetentionEnv, "1")
+	strategy := VersionedStrategy()
+	for i := 0; i < 2; i++ {
+		if err := strategy.Save(ctx, spec, rt, WorkerOutput{RuntimeState: bigOutput()}, "fp"); err != nil {
+			t.Fatalf("Save(%d) error = %v", i, err)
+		}
+	}
+	for _, name := range []string{"code_roots.json", VersionedName("code_roots", 2)} {
+		raw, err := ReadArtifact(ctx, rt.artifact, name)
+		if err != nil || !bytes.Contains(raw, []byte("file19.go")) {
+			t.Fatalf("ReadArtifact(%s) = %v, want the reassembled output", name, err)
+		}
+	}
+	if names, _ := rt.artifact.List(ctx); strings.Contains(strings.Join(names, " "), "code_roots_v1") {
+		t.Fatalf("artifacts = %v, want the parts of pruned v1 removed", names)
+	}
+}
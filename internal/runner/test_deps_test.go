@@ -0,0 +1,205 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+func TestDepsArtifactIfExistsMissingOptional(t *testing.T) {
+	rt := &testRuntime{outDir: t.TempDir()}
+	writeTestArtifact(t, rt.outDir, "code_roots.json", artifact.CodeRootsOut{MainSourceRoots: []string{"cmd"}})
+	writeTestArtifact(t, rt.outDir, "arch_design.json", artifact.ArchDesignOut{})
+	rt.resolver = MergeRegistries(BuildRegistryExternal(rt))
+
+	deps := newDeps(rt, WorkerSpec{Key: "infra_context", Optional: []string{"code_symbols"}}, nil)
+	var syms artifact.CodeSymbolsOut
+	ok, err := deps.ArtifactIfExists("code_symbols", &syms)
+	if err != nil || ok {
+		t.Fatalf("ArtifactIfExists() = %v, %v; want false, nil", ok, err)
+	}
+	if unused := deps.verifyUsage(); len(unused) != 0 {
+		t.Fatalf("optional lookup should count as usage, unused = %v", unused)
+	}
+	if _, err := deps.ArtifactIfExists("code_roots", &syms); err == nil {
+		t.Fatalf("expected undeclared requires error")
+	}
+
+	// infra_context degrades to no identifier reports without code_symbols.
+	spec, _ := rt.resolver.Get("infra_context")
+	in, err := spec.BuildInput(context.Background(), newDeps(rt, spec, nil))
+	if err != nil {
+		t.Fatalf("infra_context BuildInput() error = %v", err)
+	}
+	if got := in.(artifact.InfraContextIn); got.IdentifierReports != nil || len(got.Roots.MainSourceRoots) != 1 {
+		t.Fatalf("unexpected input: %+v", got)
+	}
+
+	writeTestArtifact(t, rt.outDir, "code_symbols.json", artifact.CodeSymbolsOut{
+		Files: []artifact.IdentifierReport{{Path: "main.go"}},
+	})
+	ok, err = deps.ArtifactIfExists("code_symbols", &syms)
+	if err != nil || !ok || len(syms.Files) != 1 {
+		t.Fatalf("ArtifactIfExists() = %v, %v, %+v", ok, err, syms)
+	}
+}
+
+func newComputeRuntime(t *testing.T, runs map[string]*atomic.Int32, specs ...WorkerSpec) *testRuntime {
+	t.Helper()
+	reg := map[string]WorkerSpec{}
+	for _, spec := range specs {
+		counter := &atomic.Int32{}
+		runs[spec.Key] = counter
+		if spec.Run == nil {
+			key := spec.Key
+			spec.Run = func(context.Context, any, Runtime) (WorkerOutput, error) {
+				counter.Add(1)
+				return WorkerOutput{RuntimeState: map[string]string{"by": key}}, nil
+			}
+		}
+		spec.Strategy = jsonStrategy{}
+		reg[spec.Key] = spec
+	}
+	rt := &testRuntime{outDir: t.TempDir()}
+	rt.resolver = MergeRegistries(reg)
+	return rt
+}
+
+func TestDepsArtifactOrComputeRunsMissingWorkers(t *testing.T) {
+	runs := map[string]*atomic.Int32{}
+	rt := newComputeRuntime(t, runs,
+		WorkerSpec{Key: "scan"},
+		WorkerSpec{
+			Key:      "summarize",
+			Requires: []string{"scan"},
+			BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+				var scan map[string]string
+				err := deps.Artifact("scan", &scan)
+				return scan, err
+			},
+		},
+	)
+
+	deps := newDeps(rt, WorkerSpec{Key: "report", Requires: []string{"summarize"}}, nil)
+	var out map[string]string
+	if err := deps.ArtifactOrCompute(context.Background(), "summarize", &out); err != nil {
+		t.Fatalf("ArtifactOrCompute() error = %v", err)
+	}
+	if out["by"] != "summarize" || runs["scan"].Load() != 1 || runs["summarize"].Load() != 1 {
+		t.Fatalf("out = %v, runs scan=%d summarize=%d", out, runs["scan"].Load(), runs["summarize"].Load())
+	}
+
+	// Present artifacts are read, not recomputed.
+	if err := deps.ArtifactOrCompute(context.Background(), "summarize", &out); err != nil {
+		t.Fatalf("second ArtifactOrCompute() error = %v", err)
+	}
+	if runs["summarize"].Load() != 1 {
+		t.Fatalf("summarize ran %d times", runs["summarize"].Load())
+	}
+}
+
+func TestDepsArtifactOrComputeSerializesConcurrentPhases(t *testing.T) {
+	runs := map[string]*atomic.Int32{}
+	rt := newComputeRuntime(t, runs, WorkerSpec{Key: "scan"})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out map[string]string
+			errs <- newDeps(rt, WorkerSpec{Key: "phase", Requires: []string{"scan"}}, nil).ArtifactOrCompute(context.Background(), "scan", &out)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("ArtifactOrCompute() error = %v", err)
+		}
+	}
+	if n := runs["scan"].Load(); n != 1 {
+		t.Fatalf("scan computed %d times, want 1", n)
+	}
+	computeLocksMu.Lock()
+	left := len(computeLocks)
+	computeLocksMu.Unlock()
+	if left != 0 {
+		t.Fatalf("%d compute locks left after the computations finished", left)
+	}
+}
+
+func TestDepsArtifactOrComputeCycleGuard(t *testing.T) {
+	runs := map[string]*atomic.Int32{}
+	compute := func(key string) func(context.Context, Deps) (any, error) {
+		return func(ctx context.Context, deps Deps) (any, error) {
+			var out map[string]string
+			return nil, deps.ArtifactOrCompute(ctx, key, &out)
+		}
+	}
+	rt := newComputeRuntime(t, runs,
+		WorkerSpec{Key: "alpha", Requires: []string{"beta"}, BuildInput: compute("beta")},
+		WorkerSpec{Key: "beta", Requires: []string{"alpha"}, BuildInput: compute("alpha")},
+	)
+
+	_, err := ExecuteWorker(context.Background(), rt, "alpha", nil)
+	if !errors.Is(err, ErrComputeCycle) {
+		t.Fatalf("ExecuteWorker() error = %v, want ErrComputeCycle", err)
+	}
+	if runs["alpha"].Load() != 0 || runs["beta"].Load() != 0 {
+		t.Fatalf("no worker of the cycle should run")
+	}
+
+	// Long chains stop at MaxComputeDepth.
+	var specs []WorkerSpec
+	for i := 0; i <= MaxComputeDepth+1; i++ {
+		spec := WorkerSpec{Key: "step" + string(rune('a'+i))}
+		if i > 0 {
+			prev := "step" + string(rune('a'+i-1))
+			spec.Requires = []string{prev}
+			spec.BuildInput = compute(prev)
+		}
+		specs = append(specs, spec)
+	}
+	rt = newComputeRuntime(t, map[string]*atomic.Int32{}, specs...)
+	if _, err := ExecuteWorker(context.Background(), rt, specs[len(specs)-1].Key, nil); err == nil || errors.Is(err, ErrComputeCycle) {
+		t.Fatalf("ExecuteWorker() error = %v, want depth limit", err)
+	}
+}
+
+func TestDepsArtifactOrComputeAppliesRunParams(t *testing.T) {
+	var got atomic.Value
+	rt := newComputeRuntime(t, map[string]*atomic.Int32{},
+		WorkerSpec{
+			Key:        "scan",
+			BuildInput: func(context.Context, Deps) (any, error) { return map[string]any{}, nil },
+			Run: func(_ context.Context, in any, _ Runtime) (WorkerOutput, error) {
+				got.Store(in.(map[string]any)["pruning"])
+				return WorkerOutput{RuntimeState: map[string]string{}}, nil
+			},
+		},
+		WorkerSpec{
+			Key:      "report",
+			Requires: []string{"scan"},
+			BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+				var scan map[string]string
+				return scan, deps.ArtifactOrCompute(ctx, "scan", &scan)
+			},
+			Run: func(context.Context, any, Runtime) (WorkerOutput, error) {
+				return WorkerOutput{RuntimeState: map[string]string{}}, nil
+			},
+		},
+	)
+
+	if _, err := ExecuteWorker(context.Background(), rt, "report", map[string]string{"pruning": "aggressive"}); err != nil {
+		t.Fatalf("ExecuteWorker() error = %v", err)
+	}
+	if got.Load() != "aggressive" {
+		t.Fatalf("scan input pruning = %v, want the run param", got.Load())
+	}
+}
@@ -0,0 +1,188 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type dryRunLLM struct {
+	capacity int
+	calls    int
+}
+
+func (c *dryRunLLM) Name() string                { return "dry-run-fake" }
+func (c *dryRunLLM) Close() error                { return nil }
+func (c *dryRunLLM) CountTokens(text string) int { return len(text) }
+func (c *dryRunLLM) TokenCapacity() int          { return c.capacity }
+func (c *dryRunLLM) GenerateJSON(context.Context, string, any) (json.RawMessage, error) {
+	c.calls++
+	return json.RawMessage(`{}`), nil
+}
+func (c *dryRunLLM) GenerateJSONStream(context.Context, string, any, func(string)) (json.RawMessage, error) {
+	c.calls++
+	return json.RawMessage(`{}`), nil
+}
+
+func TestDryRunWorkerEstimatesChainWithoutCallingLLM(t *testing.T) {
+	outDir := t.TempDir()
+	llm := &dryRunLLM{capacity: 60}
+	ranCheap, ranLLM := false, false
+
+	rt := &testRuntime{outDir: outDir, llm: llm}
+	rt.resolver = MergeRegistries(map[string]WorkerSpec{
+		"scan": {
+			Key: "scan",
+			BuildInput: func(context.Context, Deps) (any, error) {
+				return map[string]any{"files": []map[string]string{{"path": "a.go"}, {"path": "b.go"}}}, nil
+			},
+			Run: func(context.Context, any, Runtime) (WorkerOutput, error) {
+				ranCheap = true
+				return WorkerOutput{RuntimeState: map[string]int{"count": 2}}, nil
+			},
+			Strategy:      jsonStrategy{},
+			DryRunExecute: true,
+		},
+		"summarize": {
+			Key:      "summarize",
+			Requires: []string{"scan"},
+			BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+				var prev map[string]int
+				if err := deps.Artifact("scan", &prev); err != nil {
+					return nil, err
+				}
+				return map[string]any{"count": prev["count"], "text": "a long enough body to go over the fake capacity"}, nil
+			},
+			Run: func(ctx context.Context, in any, rt Runtime) (WorkerOutput, error) {
+				ranLLM = true
+				_, err := rt.GetLLM().GenerateJSON(ctx, "", in)
+				return WorkerOutput{}, err
+			},
+			Strategy: jsonStrategy{},
+		},
+	})
+
+	report, err := DryRunWorker(context.Background(), rt, "summarize", nil)
+	if err != nil {
+		t.Fatalf("DryRunWorker() error = %v", err)
+	}
+	if ranLLM || llm.calls != 0 {
+		t.Fatalf("expected no LLM worker to run, ranLLM=%v calls=%d", ranLLM, llm.calls)
+	}
+	if !ranCheap {
+		t.Fatalf("expected DryRunExecute worker to run")
+	}
+	if len(report.Phases) != 2 || report.Phases[0].Key != "scan" || report.Phases[1].Key != "summarize" {
+		t.Fatalf("unexpected phase order: %+v", report.Phases)
+	}
+	scan, summarize := report.Phases[0], report.Phases[1]
+	if !scan.Executed || scan.OpenedFiles != 2 {
+		t.Fatalf("unexpected scan phase: %+v", scan)
+	}
+	if summarize.Error != "" || summarize.EstimatedTokens == 0 || !summarize.ExceedsCapacity {
+		t.Fatalf("unexpected summarize phase: %+v", summarize)
+	}
+	if len(report.OverCapacity) != 1 || report.OverCapacity[0] != "summarize" {
+		t.Fatalf("expected summarize over capacity, got %v", report.OverCapacity)
+	}
+	if report.TotalTokens != summarize.EstimatedTokens {
+		t.Fatalf("expected total %d, got %d", summarize.EstimatedTokens, report.TotalTokens)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, DryRunReportName)); err != nil {
+		t.Fatalf("expected report artifact: %v", err)
+	}
+
+	again, err := DryRunWorker(context.Background(), rt, "summarize", nil)
+	if err != nil {
+		t.Fatalf("second DryRunWorker() error = %v", err)
+	}
+	if !again.Phases[0].CacheHit || again.Phases[0].Executed {
+		t.Fatalf("expected scan to hit cache on second dry run: %+v", again.Phases[0])
+	}
+}
+
+func TestPlanWorkerReportsCacheAgainstPopulatedOutDir(t *testing.T) {
+	outDir := t.TempDir()
+	llm := &dryRunLLM{capacity: 1 << 20}
+	runs := map[string]int{}
+	topic := "runner"
+
+	rt := &testRuntime{outDir: outDir, llm: llm}
+	rt.resolver = MergeRegistries(map[string]WorkerSpec{
+		"scan": {
+			Key: "scan",
+			BuildInput: func(context.Context, Deps) (any, error) {
+				return map[string]any{"files": []map[string]string{{"path": "a.go"}}}, nil
+			},
+			Run: func(context.Context, any, Runtime) (WorkerOutput, error) {
+				runs["scan"]++
+				return WorkerOutput{RuntimeState: map[string]int{"count": 1}}, nil
+			},
+			Strategy:      jsonStrategy{},
+			DryRunExecute: true,
+		},
+		"summarize": {
+			Key:      "summarize",
+			Requires: []string{"scan"},
+			BuildInput: func(ctx context.Context, deps Deps) (any, error) {
+				var prev map[string]int
+				if err := deps.Artifact("scan", &prev); err != nil {
+					return nil, err
+				}
+				return map[string]any{"count": prev["count"], "topic": topic}, nil
+			},
+			Run: func(ctx context.Context, in any, rt Runtime) (WorkerOutput, error) {
+				runs["summarize"]++
+				return WorkerOutput{RuntimeState: map[string]string{"summary": "ok"}}, nil
+			},
+			Strategy: jsonStrategy{},
+		},
+	})
+
+	ctx := context.Background()
+	if _, err := ExecutePlan(ctx, rt, []string{"scan", "summarize"}, nil); err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	runs = map[string]int{}
+
+	plan, err := PlanWorker(ctx, rt, "summarize", nil)
+	if err != nil {
+		t.Fatalf("PlanWorker() error = %v", err)
+	}
+	if len(plan.Phases) != 2 || !plan.Phases[0].CacheHit || !plan.Phases[1].CacheHit || plan.TotalTokens != 0 {
+		t.Fatalf("plan over a populated out dir = %+v", plan)
+	}
+
+	// A changed input misses the cache for that phase only.
+	topic = "executor"
+	plan, err = PlanWorker(ctx, rt, "summarize", nil)
+	if err != nil {
+		t.Fatalf("PlanWorker() error = %v", err)
+	}
+	scan, summarize := plan.Phases[0], plan.Phases[1]
+	if !scan.CacheHit || scan.Executed {
+		t.Fatalf("scan phase = %+v", scan)
+	}
+	if summarize.CacheHit || summarize.EstimatedTokens == 0 || plan.TotalTokens != summarize.EstimatedTokens {
+		t.Fatalf("summarize phase = %+v, total %d", summarize, plan.TotalTokens)
+	}
+
+	if len(runs) != 0 || llm.calls != 0 {
+		t.Fatalf("plan ran workers: runs=%v llm calls=%d", runs, llm.calls)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, DryRunReportName)); !os.IsNotExist(err) {
+		t.Fatalf("plan wrote a report: %v", err)
+	}
+
+	// With an empty out dir nothing hits and DryRunExecute phases stay unrun.
+	rt.outDir = t.TempDir()
+	plan, err = PlanWorker(ctx, rt, "summarize", nil)
+	if err != nil {
+		t.Fatalf("PlanWorker() error = %v", err)
+	}
+	if plan.Phases[0].CacheHit || plan.Phases[0].Executed || runs["scan"] != 0 || plan.Phases[1].Error == "" {
+		t.Fatalf("plan over an empty out dir = %+v", plan)
+	}
+}
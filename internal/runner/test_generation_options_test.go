@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	llmclient "insightify/internal/llm/client"
+	"insightify/internal/workerruntime/artifactfs"
+)
+
+func TestSpecGenerationOptionsReachRunAndFingerprint(t *testing.T) {
+	var seen llmclient.GenerationOptions
+	spec := WorkerSpec{
+		Key:        "gen",
+		BuildInput: func(context.Context, Deps) (any, error) { return map[string]int{"v": 1}, nil },
+		Run: func(ctx context.Context, in any, _ Runtime) (WorkerOutput, error) {
+			seen = llmclient.GenerationOptionsFrom(ctx)
+			return WorkerOutput{RuntimeState: in}, nil
+		},
+		Generation: llmclient.GenerationOptions{Temperature: llmclient.Float32(0), MaxOutputTokens: 4096},
+	}
+	outDir := t.TempDir()
+	rt := &testRuntime{
+		outDir:   outDir,
+		artifact: artifactfs.NewFileStore(outDir),
+		resolver: MergeRegistries(map[string]WorkerSpec{"gen": spec}),
+	}
+	if _, err := ExecuteWorker(context.Background(), rt, "gen", nil); err != nil {
+		t.Fatalf("ExecuteWorker() error = %v", err)
+	}
+	if seen.Temperature == nil || *seen.Temperature != 0 || seen.MaxOutputTokens != 4096 {
+		t.Fatalf("Run saw options %+v", seen)
+	}
+
+	in := map[string]int{"v": 1}
+	withOpts := workerFingerprint(spec, in, rt)
+	spec.Generation.MaxOutputTokens = 8192
+	if workerFingerprint(spec, in, rt) == withOpts {
+		t.Fatalf("fingerprint ignores the output cap")
+	}
+	spec.Generation = llmclient.GenerationOptions{}
+	if got := workerFingerprint(spec, in, rt); got != JSONFingerprint(in) {
+		t.Fatalf("fingerprint without options = %s, want the plain input fingerprint", got)
+	}
+}
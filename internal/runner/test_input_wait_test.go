@@ -0,0 +1,148 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// chanWaiter answers WaitForInput from inputs, or fails when ctx ends first.
+type chanWaiter struct {
+	inputs chan string
+}
+
+func (w *chanWaiter) WaitForInput(ctx context.Context, _, _ string) (string, error) {
+	select {
+	case in := <-w.inputs:
+		return in, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (w *chanWaiter) PublishOutput(context.Context, string, string, string, string) error {
+	return nil
+}
+
+type inputWaitLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *inputWaitLog) add(e string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+}
+
+func (l *inputWaitLog) snapshot() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func inputWaitCtx(policy InputWaitPolicy, log *inputWaitLog) context.Context {
+	ctx := WithInputWaitTimeout(context.Background(), time.Hour)
+	ctx = WithInputWaitEvents(ctx, InputWaitEvents{
+		OnWarning: func(_ context.Context, nodeID string, remaining time.Duration) {
+			if remaining <= 0 {
+				log.add("bad-remaining")
+			}
+			log.add("warning:" + nodeID)
+		},
+		OnTimeout: func(_ context.Context, nodeID, action string) {
+			log.add("timeout:" + nodeID + ":" + action)
+		},
+		OnResume: func(_ context.Context, nodeID string) {
+			log.add("resume:" + nodeID)
+		},
+	})
+	return withInputWaitPolicy(ctx, policy)
+}
+
+func TestWaitForUserInputOnTimeout(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  InputWaitPolicy
+		want    string
+		wantErr bool
+		events  []string
+	}{
+		{
+			name:    "fail",
+			policy:  InputWaitPolicy{Timeout: 30 * time.Millisecond},
+			wantErr: true,
+			events:  []string{"warning:n1", "timeout:n1:"},
+		},
+		{
+			name:   "default",
+			policy: InputWaitPolicy{Timeout: 30 * time.Millisecond, OnTimeout: InputTimeoutDefault, DefaultAnswer: "skip"},
+			want:   "skip",
+			events: []string{"warning:n1", "timeout:n1:default"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			log := &inputWaitLog{}
+			got, err := WaitForUserInput(inputWaitCtx(tc.policy, log), &chanWaiter{inputs: make(chan string)}, "run-1", "n1")
+			if tc.wantErr {
+				var timeoutErr *InputWaitTimeoutError
+				if !errors.As(err, &timeoutErr) || !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("err = %v, want *InputWaitTimeoutError matching DeadlineExceeded", err)
+				}
+			} else if err != nil {
+				t.Fatalf("WaitForUserInput: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("input = %q, want %q", got, tc.want)
+			}
+			if events := log.snapshot(); !slices.Equal(events, tc.events) {
+				t.Fatalf("events = %v, want %v", events, tc.events)
+			}
+		})
+	}
+}
+
+func TestWaitForUserInputPausesUntilInput(t *testing.T) {
+	log := &inputWaitLog{}
+	waiter := &chanWaiter{inputs: make(chan string)}
+	ctx := inputWaitCtx(InputWaitPolicy{Timeout: 20 * time.Millisecond, OnTimeout: InputTimeoutPause}, log)
+
+	go func() {
+		// Answer well after the timeout, once the run has paused.
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if events := log.snapshot(); len(events) > 0 && events[len(events)-1] == "timeout:n1:pause" {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		waiter.inputs <- "back"
+	}()
+
+	got, err := WaitForUserInput(ctx, waiter, "run-1", "n1")
+	if err != nil || got != "back" {
+		t.Fatalf("WaitForUserInput = %q, %v; want back", got, err)
+	}
+	want := []string{"warning:n1", "timeout:n1:pause", "resume:n1"}
+	if events := log.snapshot(); !slices.Equal(events, want) {
+		t.Fatalf("events = %v, want %v", events, want)
+	}
+}
+
+func TestWaitForUserInputAnsweredInTime(t *testing.T) {
+	log := &inputWaitLog{}
+	waiter := &chanWaiter{inputs: make(chan string, 1)}
+	waiter.inputs <- "hi"
+
+	got, err := WaitForUserInput(inputWaitCtx(InputWaitPolicy{}, log), waiter, "run-1", "n1")
+	if err != nil || got != "hi" {
+		t.Fatalf("WaitForUserInput = %q, %v; want hi", got, err)
+	}
+	if events := log.snapshot(); len(events) != 0 {
+		t.Fatalf("events = %v, want none", events)
+	}
+}
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+type multiRepoTestRuntime struct {
+	*testRuntime
+	repo  string
+	names []string
+	views map[string]*multiRepoTestRuntime
+}
+
+func (r *multiRepoTestRuntime) CurrentRepo() string { return r.repo }
+func (r *multiRepoTestRuntime) RepoNames() []string { return r.names }
+func (r *multiRepoTestRuntime) ForRepo(name string) (Runtime, bool) {
+	v, ok := r.views[name]
+	return v, ok
+}
+
+func newMultiRepoTestRuntime(t *testing.T, names ...string) *multiRepoTestRuntime {
+	t.Helper()
+	views := map[string]*multiRepoTestRuntime{}
+	for _, name := range names {
+		views[name] = &multiRepoTestRuntime{
+			testRuntime: &testRuntime{outDir: t.TempDir(), resolver: MapResolver{}},
+			repo:        name,
+			names:       names,
+			views:       views,
+		}
+	}
+	return views[names[0]]
+}
+
+func writeTestArtifact(t *testing.T, outDir, name string, v any) {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal %s: %v", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, name), b, 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestDepsArtifactForReadsOtherRepo(t *testing.T) {
+	rt := newMultiRepoTestRuntime(t, "api", "web")
+	writeTestArtifact(t, rt.views["web"].outDir, "code_symbols.json", artifact.CodeSymbolsOut{
+		Files: []artifact.IdentifierReport{{Path: "src/client.ts"}},
+	})
+
+	deps := newDeps(rt, WorkerSpec{Key: "infra_context", Optional: []string{"code_symbols"}}, nil)
+	if got := deps.Repos(); len(got) != 2 || got[0] != "api" || got[1] != "web" {
+		t.Fatalf("unexpected repos: %v", got)
+	}
+	var out artifact.CodeSymbolsOut
+	if err := deps.ArtifactFor("web", "code_symbols", &out); err != nil {
+		t.Fatalf("ArtifactFor returned error: %v", err)
+	}
+	if len(out.Files) != 1 || out.Files[0].Path != "src/client.ts" {
+		t.Fatalf("unexpected artifact: %+v", out)
+	}
+	if err := deps.ArtifactFor("api", "code_symbols", &out); err == nil {
+		t.Fatalf("expected missing artifact error for repo api")
+	}
+	if err := deps.ArtifactFor("web", "code_roots", &out); err == nil {
+		t.Fatalf("expected undeclared requires error")
+	}
+}
+
+func TestRelatedRepoSummariesSkipsCurrentAndMissing(t *testing.T) {
+	rt := newMultiRepoTestRuntime(t, "api", "web", "docs")
+	writeTestArtifact(t, rt.views["web"].outDir, "code_symbols.json", artifact.CodeSymbolsOut{
+		Files: []artifact.IdentifierReport{{
+			Path:        "src/client.ts",
+			Identifiers: []artifact.IdentifierSignal{{Name: "fetchUsers", Role: "function"}},
+		}},
+	})
+
+	related, err := relatedRepoSummaries(newDeps(rt, WorkerSpec{Key: "infra_context", Optional: []string{"code_symbols"}}, nil))
+	if err != nil {
+		t.Fatalf("relatedRepoSummaries returned error: %v", err)
+	}
+	if len(related) != 1 || related[0].Repo != "web" || len(related[0].Identifiers) != 1 {
+		t.Fatalf("expected only web summaries, got %+v", related)
+	}
+	for _, s := range related[0].Identifiers {
+		if s.Path != "web:src/client.ts" {
+			t.Fatalf("expected repo-prefixed path, got %q", s.Path)
+		}
+	}
+}
+
+func TestWorkerFingerprintDiffersPerRepo(t *testing.T) {
+	rt := newMultiRepoTestRuntime(t, "api", "web")
+	spec := WorkerSpec{Key: "code_roots"}
+	input := map[string]string{"k": "v"}
+
+	api := workerFingerprint(spec, input, rt)
+	web := workerFingerprint(spec, input, rt.views["web"])
+	if api == web {
+		t.Fatalf("expected fingerprints to differ across repos")
+	}
+	single := workerFingerprint(spec, input, rt.testRuntime)
+	if single != JSONFingerprint(input) {
+		t.Fatalf("single-repo fingerprint changed")
+	}
+}
+
+func TestRuntimeForRepoRejectsUnknownRepo(t *testing.T) {
+	rt := newMultiRepoTestRuntime(t, "api", "web")
+	if got, err := RuntimeForRepo(rt, ""); err != nil || got != Runtime(rt) {
+		t.Fatalf("empty repo should keep the runtime, got %v, %v", got, err)
+	}
+	if _, err := RuntimeForRepo(rt, "mobile"); err == nil {
+		t.Fatalf("expected unknown repo error")
+	}
+	if _, err := RuntimeForRepo(rt.testRuntime, "web"); err == nil {
+		t.Fatalf("expected error for single-repo runtime")
+	}
+}
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"insightify/internal/workerruntime/artifactfs"
+)
+
+func TestExecutePlanRunsIndependentPhasesInParallel(t *testing.T) {
+	outDir := t.TempDir()
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	bothStarted := make(chan struct{})
+	var once sync.Once
+	started := 0
+	leaf := func(key string) WorkerSpec {
+		return WorkerSpec{
+			Key:        key,
+			BuildInput: func(context.Context, Deps) (any, error) { return map[string]string{"k": key}, nil },
+			Run: func(ctx context.Context, in any, _ Runtime) (WorkerOutput, error) {
+				mu.Lock()
+				started++
+				if started == 2 {
+					once.Do(func() { close(bothStarted) })
+				}
+				mu.Unlock()
+				// Each leaf waits for the other, so only a parallel run finishes.
+				select {
+				case <-bothStarted:
+				case <-time.After(2 * time.Second):
+					return WorkerOutput{}, errors.New(key + " ran alone")
+				}
+				mu.Lock()
+				order = append(order, key)
+				mu.Unlock()
+				return WorkerOutput{RuntimeState: in}, nil
+			},
+		}
+	}
+	rt := &testRuntime{
+		outDir:   outDir,
+		artifact: artifactfs.NewFileStore(outDir),
+		resolver: MergeRegistries(map[string]WorkerSpec{
+			"left":  leaf("left"),
+			"right": leaf("right"),
+			"join": {
+				Key:        "join",
+				Requires:   []string{"left", "right"},
+				BuildInput: func(context.Context, Deps) (any, error) { return map[string]string{"k": "join"}, nil },
+				Run: func(_ context.Context, in any, _ Runtime) (WorkerOutput, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					if len(order) != 2 {
+						return WorkerOutput{}, errors.New("join ran before its requires")
+					}
+					order = append(order, "join")
+					return WorkerOutput{RuntimeState: "joined"}, nil
+				},
+			},
+		}),
+	}
+	rt.depsUsage = DepsUsageIgnore
+
+	ctx := WithParallelism(context.Background(), 2)
+	out, err := ExecutePlan(ctx, rt, UpstreamOrder(rt.resolver, "join"), nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if out.RuntimeState != "joined" || len(order) != 3 || order[2] != "join" {
+		t.Fatalf("out = %v, order = %v; want join last", out.RuntimeState, order)
+	}
+}
+
+func TestExecutePlanParallelStopsOnFirstError(t *testing.T) {
+	outDir := t.TempDir()
+	joinRan := false
+	rt := &testRuntime{
+		outDir:   outDir,
+		artifact: artifactfs.NewFileStore(outDir),
+		resolver: MergeRegistries(map[string]WorkerSpec{
+			"bad": {
+				Key: "bad",
+				Run: func(context.Context, any, Runtime) (WorkerOutput, error) {
+					return WorkerOutput{}, errors.New("boom")
+				},
+			},
+			"slow": {
+				Key: "slow",
+				Run: func(ctx context.Context, _ any, _ Runtime) (WorkerOutput, error) {
+					<-ctx.Done()
+					return WorkerOutput{}, ctx.Err()
+				},
+			},
+			"join": {
+				Key:      "join",
+				Requires: []string{"bad", "slow"},
+				Run: func(context.Context, any, Runtime) (WorkerOutput, error) {
+					joinRan = true
+					return WorkerOutput{}, nil
+				},
+			},
+		}),
+	}
+	rt.depsUsage = DepsUsageIgnore
+
+	_, err := ExecutePlan(WithParallelism(context.Background(), 4), rt, []string{"bad", "slow", "join"}, nil)
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("ExecutePlan() error = %v, want boom", err)
+	}
+	if joinRan {
+		t.Fatalf("join ran after a required phase failed")
+	}
+}
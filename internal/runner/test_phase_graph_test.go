@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExportGraphDOT(t *testing.T) {
+	resolver := MergeRegistries(map[string]WorkerSpec{
+		"code_roots":   {Key: "code_roots", Strategy: versionedStrategy{}},
+		"code_imports": {Key: "code_imports", Requires: []string{"code_roots"}, Strategy: jsonStrategy{}, DryRunExecute: true},
+		"arch_design":  {Key: "arch_design", Requires: []string{"code_roots", "code_imports"}, Strategy: jsonStrategy{}},
+	})
+
+	out, err := ExportGraph(resolver, "dot")
+	if err != nil {
+		t.Fatalf("ExportGraph() error = %v", err)
+	}
+	dot := string(out)
+	for _, want := range []string{
+		`digraph phases {`,
+		`"code_roots" [label="code_roots\n[cache:versioned]", shape=box`,
+		`"code_imports" [label="code_imports\n[cache:json, dry_run_execute]", shape=ellipse`,
+		`"code_roots" -> "code_imports";`,
+		`"code_roots" -> "arch_design";`,
+		`"code_imports" -> "arch_design";`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("DOT missing %q:\n%s", want, dot)
+		}
+	}
+	if strings.Count(dot, "->") != 3 {
+		t.Fatalf("expected 3 edges:\n%s", dot)
+	}
+}
+
+func TestExportGraphJSONMarksMissingDependency(t *testing.T) {
+	resolver := MergeRegistries(map[string]WorkerSpec{
+		"infra_context": {Key: "infra_context", Requires: []string{"Arch_Design"}},
+	})
+
+	out, err := ExportGraph(resolver, "json")
+	if err != nil {
+		t.Fatalf("ExportGraph() error = %v", err)
+	}
+	var g PhaseGraph
+	if err := json.Unmarshal(out, &g); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(g.Nodes) != 2 || g.Nodes[0].Key != "arch_design" || !g.Nodes[0].Missing {
+		t.Fatalf("expected a missing arch_design node, got %+v", g.Nodes)
+	}
+	if !g.Nodes[1].UsesLLM || len(g.Nodes[1].Tags) != 1 || g.Nodes[1].Tags[0] != "cache:none" {
+		t.Fatalf("unexpected infra_context node: %+v", g.Nodes[1])
+	}
+	if _, err := ExportGraph(resolver, "svg"); err == nil {
+		t.Fatalf("expected unknown format error")
+	}
+}
+
+func TestExportGraphReportsCycle(t *testing.T) {
+	resolver := MergeRegistries(map[string]WorkerSpec{
+		"a": {Key: "a", Requires: []string{"c"}},
+		"b": {Key: "b", Requires: []string{"a"}},
+		"c": {Key: "c", Requires: []string{"b"}},
+		"d": {Key: "d", Requires: []string{"a"}},
+	})
+
+	out, err := ExportGraph(resolver, "dot")
+	if !errors.Is(err, ErrPhaseCycle) {
+		t.Fatalf("expected ErrPhaseCycle, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Fatalf("cycle not named in error: %v", err)
+	}
+	if !strings.Contains(string(out), `"a" -> "b" [color=red];`) || strings.Contains(string(out), `"a" -> "d" [color=red]`) {
+		t.Fatalf("cycle edges should be highlighted:\n%s", out)
+	}
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"insightify/internal/workerruntime/artifactfs"
+)
+
+func TestPhaseHooksFireAroundRunAndCacheHit(t *testing.T) {
+	outDir := t.TempDir()
+	runs := 0
+	fail := false
+	rt := &testRuntime{
+		outDir:   outDir,
+		artifact: artifactfs.NewFileStore(outDir),
+		resolver: MergeRegistries(map[string]WorkerSpec{
+			"first": {
+				Key:        "first",
+				BuildInput: func(context.Context, Deps) (any, error) { return map[string]int{"v": 1}, nil },
+				Run: func(_ context.Context, in any, _ Runtime) (WorkerOutput, error) {
+					runs++
+					return WorkerOutput{RuntimeState: in}, nil
+				},
+			},
+			"second": {
+				Key:        "second",
+				BuildInput: func(context.Context, Deps) (any, error) { return map[string]bool{"fail": fail}, nil },
+				Run: func(_ context.Context, in any, _ Runtime) (WorkerOutput, error) {
+					if fail {
+						return WorkerOutput{}, errors.New("boom")
+					}
+					return WorkerOutput{RuntimeState: in}, nil
+				},
+			},
+		}),
+	}
+
+	var events []string
+	hooks := PhaseHooks{
+		OnStart: func(_ context.Context, spec WorkerSpec, _ Runtime) {
+			events = append(events, "start "+spec.Key)
+		},
+		OnEnd: func(_ context.Context, spec WorkerSpec, _ Runtime, out WorkerOutput, err error, cached bool) {
+			events = append(events, fmt.Sprintf("end %s cached=%v err=%v state=%v", spec.Key, cached, err != nil, out.RuntimeState != nil))
+		},
+	}
+	run := func() error {
+		events = nil
+		_, err := ExecutePlan(WithPhaseHooks(context.Background(), hooks), rt, []string{"first", "second"}, nil)
+		return err
+	}
+
+	if err := run(); err != nil {
+		t.Fatalf("first ExecutePlan() error = %v", err)
+	}
+	want := []string{
+		"start first", "end first cached=false err=false state=true",
+		"start second", "end second cached=false err=false state=true",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("recompute events = %q, want %q", events, want)
+	}
+
+	// Unchanged inputs load from cache; a changed one recomputes and fails.
+	fail = true
+	if err := run(); err == nil {
+		t.Fatalf("second ExecutePlan() succeeded, want the second phase to fail")
+	}
+	want = []string{
+		"start first", "end first cached=true err=false state=true",
+		"start second", "end second cached=false err=true state=false",
+	}
+	if !reflect.DeepEqual(events, want) || runs != 1 {
+		t.Fatalf("cache-hit events = %q (runs %d), want %q", events, runs, want)
+	}
+}
+
+func TestWithPhaseHooksKeepsEarlierHooks(t *testing.T) {
+	var order []string
+	ctx := WithPhaseHooks(context.Background(), PhaseHooks{OnStart: func(context.Context, WorkerSpec, Runtime) { order = append(order, "outer") }})
+	ctx = WithPhaseHooks(ctx, PhaseHooks{OnStart: func(context.Context, WorkerSpec, Runtime) { order = append(order, "inner") }})
+	phaseStarted(ctx, WorkerSpec{Key: "k"}, nil)
+	phaseEnded(ctx, WorkerSpec{Key: "k"}, nil, WorkerOutput{}, nil, false) // nil OnEnd is skipped
+	if !reflect.DeepEqual(order, []string{"outer", "inner"}) {
+		t.Fatalf("order = %v", order)
+	}
+}
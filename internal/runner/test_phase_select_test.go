@@ -0,0 +1,58 @@
+package runner
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPlanPhases(t *testing.T) {
+	resolver := BuildAllRegistries(nil)
+	cases := []struct {
+		name    string
+		targets []string
+		want    []string
+	}{
+		{name: "worker with requires", targets: []string{"code_specs"}, want: []string{"code_roots", "code_stats", "code_specs"}},
+		{name: "repeated dependency", targets: []string{"code_roots", "code_stats"}, want: []string{"code_roots", "code_stats"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := PlanPhases(resolver, tc.targets)
+			if err != nil {
+				t.Fatalf("PlanPhases() error = %v", err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("PlanPhases() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	keys, err := PlanPhases(resolver, []string{"codebase"})
+	if err != nil {
+		t.Fatalf("PlanPhases(codebase) error = %v", err)
+	}
+	for _, key := range keys {
+		spec, _ := resolver.Get(key)
+		if spec.Pipeline != "codebase" {
+			t.Fatalf("codebase plan has %s of pipeline %q", key, spec.Pipeline)
+		}
+	}
+	if !slices.Contains(keys, "code_imports") || slices.Index(keys, "code_roots") > slices.Index(keys, "code_imports") {
+		t.Fatalf("codebase plan = %v, want code_roots before code_imports", keys)
+	}
+
+	if _, err := PlanPhases(resolver, []string{"code_roots", "nope"}); !errors.Is(err, ErrUnknownPhase) {
+		t.Fatalf("PlanPhases(nope) error = %v, want %v", err, ErrUnknownPhase)
+	}
+}
+
+func TestPhasesParam(t *testing.T) {
+	got := PhasesParam(map[string]string{RunParamPhases: " code_roots, ,codebase "})
+	if want := []string{"code_roots", "codebase"}; !slices.Equal(got, want) {
+		t.Fatalf("PhasesParam() = %v, want %v", got, want)
+	}
+	if got := PhasesParam(nil); got != nil {
+		t.Fatalf("PhasesParam(nil) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,218 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	llm "insightify/internal/llm/middleware"
+)
+
+// streamingLLM streams a fixed number of chunks per request.
+type streamingLLM struct{ chunks int }
+
+func (c *streamingLLM) Name() string             { return "streaming-fake" }
+func (c *streamingLLM) Close() error             { return nil }
+func (c *streamingLLM) CountTokens(s string) int { return len(s) }
+func (c *streamingLLM) TokenCapacity() int       { return 0 }
+func (c *streamingLLM) GenerateJSON(context.Context, string, any) (json.RawMessage, error) {
+	return json.RawMessage(`{}`), nil
+}
+func (c *streamingLLM) GenerateJSONStream(_ context.Context, _ string, _ any, onChunk func(string)) (json.RawMessage, error) {
+	for i := 0; i < c.chunks; i++ {
+		if onChunk != nil {
+			onChunk("{")
+		}
+	}
+	return json.RawMessage(`{}`), nil
+}
+
+type progressRecorder struct {
+	mu       sync.Mutex
+	percents []float64
+}
+
+func (r *progressRecorder) record(p float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.percents = append(r.percents, p)
+}
+
+func (r *progressRecorder) check(t *testing.T) []float64 {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hundreds := 0
+	for i, p := range r.percents {
+		if i > 0 && p < r.percents[i-1] {
+			t.Fatalf("progress decreased at %d: %v", i, r.percents)
+		}
+		if p == 100 {
+			hundreds++
+		}
+	}
+	if hundreds != 1 || len(r.percents) == 0 || r.percents[len(r.percents)-1] != 100 {
+		t.Fatalf("want 100 exactly once, at the end: %v", r.percents)
+	}
+	return append([]float64(nil), r.percents...)
+}
+
+func newProgressRuntime(t *testing.T, calls map[string]int) *testRuntime {
+	t.Helper()
+	phase := func(key string, stream bool) WorkerSpec {
+		return WorkerSpec{
+			Key: key,
+			Run: func(ctx context.Context, in any, rt Runtime) (WorkerOutput, error) {
+				calls[key]++
+				if stream {
+					if _, err := rt.GetLLM().GenerateJSONStream(ctx, "", in, nil); err != nil {
+						return WorkerOutput{}, err
+					}
+				}
+				return WorkerOutput{RuntimeState: map[string]string{"key": key}}, nil
+			},
+			Strategy: jsonStrategy{},
+		}
+	}
+	rt := &testRuntime{
+		outDir: t.TempDir(),
+		llm:    llm.Wrap(&streamingLLM{chunks: 30}, llm.WithHooks()),
+	}
+	rt.resolver = MergeRegistries(map[string]WorkerSpec{
+		"scan":      phase("scan", false),
+		"summarize": phase("summarize", true),
+		"render":    phase("render", false),
+	})
+	return rt
+}
+
+func TestExecutePlanReportsMonotonicProgress(t *testing.T) {
+	calls := map[string]int{}
+	rt := newProgressRuntime(t, calls)
+	rec := &progressRecorder{}
+	ctx := WithProgress(context.Background(), rec.record)
+
+	if _, err := ExecutePlan(ctx, rt, []string{"scan", "summarize", "render"}, nil); err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	cold := rec.check(t)
+	// Start/complete of three phases plus chunk bumps inside summarize.
+	if len(cold) < 8 {
+		t.Fatalf("expected chunk bumps between phase events, got %v", cold)
+	}
+
+	b, err := os.ReadFile(filepath.Join(rt.outDir, PhaseDurationsName))
+	if err != nil {
+		t.Fatalf("read %s: %v", PhaseDurationsName, err)
+	}
+	var durations map[string]int64
+	if err := json.Unmarshal(b, &durations); err != nil || len(durations) != 3 || durations["summarize"] <= 0 {
+		t.Fatalf("durations = %s (%v)", b, err)
+	}
+
+	// Warm re-run: scan and summarize are cached and complete instantly.
+	if err := os.Remove(filepath.Join(rt.outDir, "render.meta.json")); err != nil {
+		t.Fatal(err)
+	}
+	warm := &progressRecorder{}
+	if _, err := ExecutePlan(WithProgress(context.Background(), warm.record), rt, []string{"scan", "summarize", "render"}, nil); err != nil {
+		t.Fatalf("warm ExecutePlan() error = %v", err)
+	}
+	got := warm.check(t)
+	if calls["scan"] != 1 || calls["summarize"] != 1 || calls["render"] != 2 {
+		t.Fatalf("calls = %v, want only render to rerun", calls)
+	}
+	// render starts where the cache hits left off, so it adds no report.
+	if len(got) != 3 {
+		t.Fatalf("warm run should report two cache hits and 100: %v", got)
+	}
+}
+
+func TestExecuteWorkerSinglePhaseProgress(t *testing.T) {
+	rt := newProgressRuntime(t, map[string]int{})
+	rec := &progressRecorder{}
+	if _, err := ExecuteWorker(WithProgress(context.Background(), rec.record), rt, "summarize", nil); err != nil {
+		t.Fatalf("ExecuteWorker() error = %v", err)
+	}
+	got := rec.check(t)
+	if got[0] != 0 {
+		t.Fatalf("first report should be the phase start at 0: %v", got)
+	}
+	if len(got) < 3 || got[len(got)-2] >= 100 {
+		t.Fatalf("chunks should bump progress below 100 before completion: %v", got)
+	}
+}
+
+func TestPhaseAllotments(t *testing.T) {
+	keys := []string{"scan", "summarize", "render"}
+	equal := phaseAllotments(keys, nil, nil)
+	for _, k := range keys {
+		if math.Abs(equal[k]-100.0/3) > 1e-9 {
+			t.Fatalf("equal weights = %v", equal)
+		}
+	}
+
+	// Durations take precedence over static weights; render has no history
+	// and gets the mean of the known durations.
+	got := phaseAllotments(keys, map[string]float64{"scan": 5}, map[string]int64{"scan": 100, "summarize": 700})
+	want := map[string]float64{"scan": 100.0 / 1200 * 100, "summarize": 700.0 / 1200 * 100, "render": 400.0 / 1200 * 100}
+	for k, w := range want {
+		if math.Abs(got[k]-w) > 1e-9 {
+			t.Fatalf("allotments = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestExecutePlanWeightsPhasesWithoutHistory(t *testing.T) {
+	calls := map[string]int{}
+	rt := newProgressRuntime(t, calls)
+	weights := map[string]float64{"scan": 1, "summarize": 2, "render": 1}
+	reg := map[string]WorkerSpec{}
+	for _, spec := range rt.resolver.List() {
+		spec.Weight = weights[spec.Key]
+		reg[spec.Key] = spec
+	}
+	rt.resolver = MergeRegistries(reg)
+	rt.llm = llm.Wrap(&streamingLLM{}, llm.WithHooks())
+
+	rec := &progressRecorder{}
+	keys := []string{"scan", "summarize", "render"}
+	if _, err := ExecutePlan(WithProgress(context.Background(), rec.record), rt, keys, nil); err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	got := rec.check(t)
+	want := []float64{0}
+	for i := range keys {
+		want = append(want, CompletedPercent(weights, keys[:i+1]))
+	}
+	if len(got) != len(want) {
+		t.Fatalf("progress = %v, want %v", got, want)
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 0.1 {
+			t.Fatalf("progress = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCompletedPercent(t *testing.T) {
+	weights := map[string]float64{"scan": 1, "summarize": 2, "render": 0}
+	for _, tc := range []struct {
+		completed []string
+		want      float64
+	}{
+		{nil, 0},
+		{[]string{"scan"}, 25},
+		{[]string{"scan", "summarize"}, 75},
+		{[]string{"scan", "scan", "unknown"}, 25},
+		{[]string{"scan", "summarize", "render"}, 100},
+	} {
+		if got := CompletedPercent(weights, tc.completed); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("CompletedPercent(%v) = %v, want %v", tc.completed, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPromptVersionBumpMissesCache(t *testing.T) {
+	rt := &testRuntime{outDir: t.TempDir(), modelSalt: "salt"}
+	rt.resolver = MergeRegistries(map[string]WorkerSpec{
+		"code_roots": {Key: "code_roots", Strategy: jsonStrategy{}},
+	})
+	spec, _ := rt.resolver.Get("code_roots")
+	if spec.PromptVersion != PromptVersions["code_roots"] || spec.PromptVersion == "" {
+		t.Fatalf("expected PromptVersion from PromptVersions, got %q", spec.PromptVersion)
+	}
+
+	ctx := context.Background()
+	strategy := jsonStrategy{}
+	if err := strategy.Save(ctx, spec, rt, WorkerOutput{RuntimeState: map[string]string{"ok": "yes"}}, "fp"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, ok := strategy.TryLoad(ctx, spec, rt, "fp"); !ok {
+		t.Fatalf("expected cache hit with unchanged prompt version")
+	}
+
+	spec.PromptVersion = spec.PromptVersion + "-next"
+	if _, ok := strategy.TryLoad(ctx, spec, rt, "fp"); ok {
+		t.Fatalf("expected cache miss after prompt version bump")
+	}
+}
+
+func TestBuildModelSaltTracksPromptVersions(t *testing.T) {
+	base := map[string]string{"code_roots": "1", "arch_design": "2"}
+	salt := BuildModelSalt("worker_middle=gemini", base)
+	if again := BuildModelSalt("worker_middle=gemini", map[string]string{"arch_design": "2", "code_roots": "1"}); again != salt {
+		t.Fatalf("salt depends on map order: %q vs %q", salt, again)
+	}
+	if other := BuildModelSalt("worker_middle=groq", base); other == salt {
+		t.Fatalf("salt ignores the model")
+	}
+	for phase := range base {
+		bumped := map[string]string{"code_roots": "1", "arch_design": "2"}
+		bumped[phase] += "-next"
+		if BuildModelSalt("worker_middle=gemini", bumped) == salt {
+			t.Fatalf("salt unchanged after bumping %s", phase)
+		}
+	}
+	added := map[string]string{"code_roots": "1", "arch_design": "2", "code_specs": "1"}
+	if BuildModelSalt("worker_middle=gemini", added) == salt {
+		t.Fatalf("salt unchanged after adding a prompt version")
+	}
+	if BuildModelSalt("worker_middle=gemini", map[string]string{"code_roots": "1"}) == salt {
+		t.Fatalf("salt unchanged after removing a prompt version")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"insightify/internal/artifact"
@@ -55,7 +56,22 @@ func (a *testArtifactAccess) Write(_ context.Context, name string, content []byt
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
-	return os.WriteFile(path, content, 0o644)
+	// Write atomically like the real stores, so concurrent readers never
+	// see a partial artifact.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 func (a *testArtifactAccess) Remove(_ context.Context, name string) error {
@@ -120,7 +136,7 @@ func TestPlanRegistryBuildInputInjectsBootstrapWorker(t *testing.T) {
 	}
 
 	spec := BuildRegistryPlan(rt)["worker_DAG"]
-	inAny, err := spec.BuildInput(context.Background(), newDeps(rt, spec.Key, spec.Requires))
+	inAny, err := spec.BuildInput(context.Background(), newDeps(rt, spec, nil))
 	if err != nil {
 		t.Fatalf("BuildInput() error = %v", err)
 	}
@@ -164,3 +180,47 @@ func TestPlanRegistryIncludesBootstrapAndAutonomousExecutor(t *testing.T) {
 		t.Fatalf("did not expect legacy plan_pipeline worker spec")
 	}
 }
+
+// chatRecorder is an interaction waiter recording what a run writes to its
+// chat, in order.
+type chatRecorder struct {
+	events []string
+}
+
+func (c *chatRecorder) WaitForInput(context.Context, string, string) (string, error) {
+	return "", nil
+}
+
+func (c *chatRecorder) PublishOutput(_ context.Context, _, _, _, message string) error {
+	c.events = append(c.events, "output:"+message)
+	return nil
+}
+
+func (c *chatRecorder) PublishOutputChunk(_ context.Context, _, _, _, chunk string) error {
+	c.events = append(c.events, "chunk:"+chunk)
+	return nil
+}
+
+func (c *chatRecorder) RecordConversation(_ context.Context, _, _ string, turns []artifact.ConversationTurn) error {
+	for _, t := range turns {
+		c.events = append(c.events, t.Role+":"+t.Content)
+	}
+	return nil
+}
+
+func TestBootstrapWritesTranscriptThenStreamsReplyToChat(t *testing.T) {
+	chat := &chatRecorder{}
+	ctx := WithInteractionWaiter(WithNodeID(WithRunID(context.Background(), "run-1"), "node-1"), chat)
+	in := plan.BootstrapIn{
+		Conversation:        []artifact.ConversationTurn{{Role: "user", Content: "I want to learn Raft"}},
+		ConversationSession: "chat-a",
+		Session:             "chat-a",
+	}
+	spec := BuildRegistryPlan(&testRuntime{})["bootstrap"]
+	if _, err := spec.Run(ctx, in, &testRuntime{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(chat.events) != 2 || chat.events[0] != "user:I want to learn Raft" || !strings.HasPrefix(chat.events[1], "chunk:") {
+		t.Fatalf("chat events = %q, want the carried turn then the streamed greeting", chat.events)
+	}
+}
@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateRegistryAcceptsDAG(t *testing.T) {
+	err := ValidateRegistry(map[string]WorkerSpec{
+		"code_roots":   {Key: "code_roots"},
+		"code_imports": {Key: "code_imports", Requires: []string{"code_roots"}},
+		"arch_design":  {Key: "arch_design", Requires: []string{"code_roots", "code_imports"}},
+	})
+	if err != nil {
+		t.Fatalf("ValidateRegistry() error = %v", err)
+	}
+}
+
+func TestValidateRegistryMissingDependency(t *testing.T) {
+	err := ValidateRegistry(map[string]WorkerSpec{
+		"code_roots":  {Key: "code_roots"},
+		"arch_design": {Key: "arch_design", Requires: []string{"code_roots", "code_rots"}},
+	})
+	if !errors.Is(err, ErrMissingDependency) {
+		t.Fatalf("expected ErrMissingDependency, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "arch_design requires code_rots") {
+		t.Fatalf("error should name the missing key: %v", err)
+	}
+}
+
+func TestValidateRegistryCycle(t *testing.T) {
+	err := ValidateRegistry(map[string]WorkerSpec{
+		"code_roots": {Key: "code_roots"},
+		"a":          {Key: "a", Requires: []string{"code_roots", "b"}},
+		"b":          {Key: "b", Requires: []string{"a"}},
+	})
+	if !errors.Is(err, ErrPhaseCycle) {
+		t.Fatalf("expected ErrPhaseCycle, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Fatalf("error should name the cycle: %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
+)
+
+// newGateRuntime writes files as repo "demo" and resolves the real code_stats
+// and repo_assessment workers, a code_roots stub, a RepoGated "analysis"
+// phase and a "report" phase requiring it. runs counts the analysis runs.
+func newGateRuntime(t *testing.T, files map[string]string, runs *int) *testRuntime {
+	t.Helper()
+	repos := t.TempDir()
+	repo := filepath.Join(repos, "demo")
+	for name, body := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reposFS, err := safeio.NewSafeFS(repos)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	prevFS, prevDir := scan.CurrentSafeFS(), scan.ReposDir()
+	scan.SetReposDir(repos)
+	scan.SetSafeFS(reposFS)
+	t.Cleanup(func() {
+		scan.SetSafeFS(prevFS)
+		scan.SetReposDir(prevDir)
+	})
+	repoFS, err := safeio.NewSafeFS(repo)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+
+	codebase := BuildRegistryCodebase(nil)
+	reg := map[string]WorkerSpec{
+		"code_roots": {
+			Key:        "code_roots",
+			BuildInput: func(context.Context, Deps) (any, error) { return map[string]string{}, nil },
+			Run: func(context.Context, any, Runtime) (WorkerOutput, error) {
+				return WorkerOutput{RuntimeState: artifact.CodeRootsOut{MainSourceRoots: []string{"."}}}, nil
+			},
+			Strategy: jsonStrategy{},
+		},
+		"code_stats":      codebase["code_stats"],
+		"repo_assessment": codebase["repo_assessment"],
+		"analysis": {
+			Key:      "analysis",
+			Requires: []string{"code_stats"},
+			BuildInput: func(_ context.Context, deps Deps) (any, error) {
+				var stats artifact.CodeStatsOut
+				return map[string]string{}, deps.Artifact("code_stats", &stats)
+			},
+			Run: func(context.Context, any, Runtime) (WorkerOutput, error) {
+				*runs++
+				return WorkerOutput{RuntimeState: map[string]string{"ok": "1"}}, nil
+			},
+			Strategy:  versionedStrategy{},
+			RepoGated: true,
+		},
+		"report": {
+			Key:      "report",
+			Requires: []string{"analysis"},
+			BuildInput: func(_ context.Context, deps Deps) (any, error) {
+				var prev map[string]string
+				return map[string]string{}, deps.Artifact("analysis", &prev)
+			},
+			Run: func(context.Context, any, Runtime) (WorkerOutput, error) {
+				return WorkerOutput{RuntimeState: map[string]string{"ok": "1"}}, nil
+			},
+			Strategy: versionedStrategy{},
+		},
+	}
+	return &testRuntime{outDir: t.TempDir(), repoFS: repoFS, resolver: MergeRegistries(reg)}
+}
+
+// gateEnds runs the whole chain and returns the error each phase ended with.
+func gateEnds(t *testing.T, rt *testRuntime, params map[string]string) (map[string]error, error) {
+	t.Helper()
+	var mu sync.Mutex
+	ends := map[string]error{}
+	ctx := WithPhaseHooks(context.Background(), PhaseHooks{
+		OnEnd: func(_ context.Context, spec WorkerSpec, _ Runtime, _ WorkerOutput, err error, _ bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			ends[spec.Key] = err
+		},
+	})
+	_, err := ExecutePlan(ctx, rt, []string{"code_roots", "code_stats", "analysis", "report"}, params)
+	return ends, err
+}
+
+var markdownOnly = map[string]string{
+	"README.md":         "# Notes\n\nNothing to build here.\n",
+	"docs/guide.md":     "# Guide\n\n" + strings.Repeat("Some prose.\n", 20),
+	"docs/faq.md":       "# FAQ\n",
+	"data/samples.json": `{"rows": [1, 2, 3]}`,
+}
+
+func TestRepoGateSkipsMarkdownOnlyRepo(t *testing.T) {
+	runs := 0
+	rt := newGateRuntime(t, markdownOnly, &runs)
+	ends, err := gateEnds(t, rt, nil)
+
+	var skipErr *RepoSkippedError
+	if !errors.As(err, &skipErr) || !errors.Is(err, ErrRepoSkipped) {
+		t.Fatalf("ExecutePlan() error = %v, want a RepoSkippedError", err)
+	}
+	if runs != 0 {
+		t.Fatalf("analysis ran %d times, want the gate to skip it", runs)
+	}
+	for _, key := range []string{"analysis", "report"} {
+		if got, ok := ends[key]; !ok || !errors.Is(got, ErrRepoSkipped) {
+			t.Fatalf("%s ended with %v (reported %v), want ErrRepoSkipped", key, got, ok)
+		}
+	}
+	for _, key := range []string{"code_roots", "code_stats", RepoAssessmentKey} {
+		if got := ends[key]; got != nil {
+			t.Fatalf("%s ended with %v, want success", key, got)
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(rt.outDir, "repo_assessment.json"))
+	if err != nil {
+		t.Fatalf("repo_assessment.json: %v", err)
+	}
+	var saved artifact.RepoAssessmentOut
+	if err := json.Unmarshal(b, &saved); err != nil {
+		t.Fatalf("decode repo_assessment.json: %v", err)
+	}
+	if !saved.Skip || saved.CodeFiles != 0 || saved.DocsFiles != 3 || saved.DataFiles != 1 || len(saved.Reasons) == 0 {
+		t.Fatalf("assessment = %+v, want a skip over 3 docs and 1 data file", saved)
+	}
+	if skipErr.View == nil || !strings.Contains(skipErr.View.GetLlmResponse(), "force=true") {
+		t.Fatalf("skip view = %v, want the force hint", skipErr.View)
+	}
+}
+
+func TestRepoGatePassesTinyGoRepo(t *testing.T) {
+	runs := 0
+	rt := newGateRuntime(t, map[string]string{
+		"README.md": "# Tiny\n",
+		"go.mod":    "module tiny\n\ngo 1.22\n",
+		"main.go":   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n",
+	}, &runs)
+	ends, err := gateEnds(t, rt, nil)
+	if err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+	if runs != 1 || ends["report"] != nil {
+		t.Fatalf("analysis runs = %d, report ended with %v; want both to run", runs, ends["report"])
+	}
+	if _, ok := ends[RepoAssessmentKey]; !ok {
+		t.Fatalf("repo_assessment did not run before the gated phase")
+	}
+}
+
+func TestRepoGateForceAndThresholdParams(t *testing.T) {
+	cases := []struct {
+		name     string
+		params   map[string]string
+		wantRuns int
+	}{
+		{name: "force bypasses the gate", params: map[string]string{RunParamForce: "true"}, wantRuns: 1},
+		{name: "force=false keeps it", params: map[string]string{RunParamForce: "false"}, wantRuns: 0},
+		{name: "zero thresholds use the defaults", params: map[string]string{RunParamMinCodeRatio: "0", RunParamMinCodeBytes: "0"}, wantRuns: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runs := 0
+			rt := newGateRuntime(t, markdownOnly, &runs)
+			ends, err := gateEnds(t, rt, tc.params)
+			if tc.wantRuns > 0 && err != nil {
+				t.Fatalf("ExecutePlan() error = %v", err)
+			}
+			if tc.wantRuns == 0 && !errors.Is(err, ErrRepoSkipped) {
+				t.Fatalf("ExecutePlan() error = %v, want ErrRepoSkipped", err)
+			}
+			if runs != tc.wantRuns {
+				t.Fatalf("analysis runs = %d, want %d", runs, tc.wantRuns)
+			}
+			if _, ran := ends[RepoAssessmentKey]; ran == (tc.params[RunParamForce] == "true") {
+				t.Fatalf("repo_assessment ran = %v with params %v", ran, tc.params)
+			}
+		})
+	}
+}
+
+func TestApplyRunParamsRepoAssessmentThresholds(t *testing.T) {
+	in := applyRunParams(artifact.RepoAssessmentIn{}, map[string]string{
+		RunParamMinCodeRatio: "0.25",
+		RunParamMinCodeBytes: "2048",
+	}).(artifact.RepoAssessmentIn)
+	if in.MinCodeRatio != 0.25 || in.MinCodeBytes != 2048 {
+		t.Fatalf("input = %+v, want the param thresholds", in)
+	}
+	m := applyRunParams(map[string]any{}, map[string]string{RunParamForce: "true", RunParamMinCodeRatio: "0.5"}).(map[string]any)
+	if len(m) != 0 {
+		t.Fatalf("map input = %v, want the gate params left out of fingerprints", m)
+	}
+}
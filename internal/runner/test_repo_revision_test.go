@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"insightify/internal/common/safeio"
+)
+
+const fixtureSHA = "0123456789abcdef0123456789abcdef01234567"
+
+// revisionRuntime returns a runtime over a repo whose .git fixture is on
+// branch main at fixtureSHA, or over a plain directory when git is false.
+func revisionRuntime(t *testing.T, git bool) *testRuntime {
+	t.Helper()
+	repo := t.TempDir()
+	if git {
+		refs := filepath.Join(repo, ".git", "refs", "heads")
+		if err := os.MkdirAll(refs, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(refs, "main"), []byte(fixtureSHA+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repoFS, err := safeio.NewSafeFS(repo)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	rt := &testRuntime{outDir: t.TempDir(), repoFS: repoFS}
+	rt.resolver = MergeRegistries(map[string]WorkerSpec{
+		"code_roots": {Key: "code_roots", Strategy: jsonStrategy{}},
+	})
+	return rt
+}
+
+func readMeta(t *testing.T, rt *testRuntime, key string) cacheMeta {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(rt.outDir, key+".meta.json"))
+	if err != nil {
+		t.Fatalf("read meta: %v", err)
+	}
+	var m cacheMeta
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatalf("decode meta: %v", err)
+	}
+	return m
+}
+
+func TestCacheMetaRecordsRepoRevision(t *testing.T) {
+	ctx := context.Background()
+	out := WorkerOutput{RuntimeState: map[string]string{"ok": "yes"}}
+	for _, strategy := range []CacheStrategy{jsonStrategy{}, versionedStrategy{}} {
+		rt := revisionRuntime(t, true)
+		spec, _ := rt.resolver.Get("code_roots")
+		if err := strategy.Save(ctx, spec, rt, out, "fp"); err != nil {
+			t.Fatalf("%T Save() error = %v", strategy, err)
+		}
+		if m := readMeta(t, rt, "code_roots"); m.GitCommit != fixtureSHA || m.GitBranch != "main" {
+			t.Fatalf("%T meta = %+v, want commit %s on main", strategy, m, fixtureSHA)
+		}
+	}
+}
+
+func TestCacheMetaWithoutGitCheckout(t *testing.T) {
+	ctx := context.Background()
+	rt := revisionRuntime(t, false)
+	spec, _ := rt.resolver.Get("code_roots")
+	if err := (jsonStrategy{}).Save(ctx, spec, rt, WorkerOutput{RuntimeState: map[string]string{"ok": "yes"}}, "fp"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if m := readMeta(t, rt, "code_roots"); m.GitCommit != "" || m.GitBranch != "" {
+		t.Fatalf("meta = %+v, want no revision", m)
+	}
+	if _, ok := (jsonStrategy{}).TryLoad(ctx, spec, rt, "fp"); !ok {
+		t.Fatalf("expected cache hit without a revision")
+	}
+	if rev := RepoRevision(&testRuntime{}); !rev.IsZero() {
+		t.Fatalf("RepoRevision without RepoFS = %+v", rev)
+	}
+}
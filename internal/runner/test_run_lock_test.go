@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"insightify/internal/workerruntime/artifactfs"
+)
+
+// newLockRuntime returns a runtime over outDir whose "stamp" worker records
+// how many runs are inside it at once and outputs the run ID it ran for.
+func newLockRuntime(outDir string, active, peak *int32) *testRuntime {
+	return &testRuntime{
+		outDir:   outDir,
+		artifact: artifactfs.NewFileStore(outDir),
+		resolver: MergeRegistries(map[string]WorkerSpec{
+			"stamp": {
+				Key: "stamp",
+				BuildInput: func(ctx context.Context, _ Deps) (any, error) {
+					runID, _ := RunIDFromContext(ctx)
+					return map[string]string{"run": runID}, nil
+				},
+				Run: func(_ context.Context, in any, _ Runtime) (WorkerOutput, error) {
+					n := atomic.AddInt32(active, 1)
+					defer atomic.AddInt32(active, -1)
+					for {
+						p := atomic.LoadInt32(peak)
+						if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+							break
+						}
+					}
+					time.Sleep(30 * time.Millisecond)
+					return WorkerOutput{RuntimeState: in}, nil
+				},
+			},
+		}),
+	}
+}
+
+func TestExecutePlanSerializesRunsOnSameOutDir(t *testing.T) {
+	outDir := t.TempDir()
+	var active, peak int32
+	const runs = 4
+
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := WithRunLockWait(WithRunID(context.Background(), "run-"+string(rune('a'+i))), 5*time.Second)
+			// Separate runtimes share only the directory, like two runs of a project.
+			_, err := ExecutePlan(ctx, newLockRuntime(outDir, &active, &peak), []string{"stamp"}, nil)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("ExecutePlan() error = %v", err)
+		}
+	}
+	if peak != 1 {
+		t.Fatalf("%d runs were inside the worker at once, want 1", peak)
+	}
+
+	// The meta describes exactly the output next to it.
+	out, err := os.ReadFile(filepath.Join(outDir, "stamp.json"))
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(outDir, "stamp.meta.json"))
+	if err != nil {
+		t.Fatalf("read meta: %v", err)
+	}
+	var meta cacheMeta
+	var state map[string]string
+	if json.Unmarshal(raw, &meta) != nil || json.Unmarshal(out, &state) != nil {
+		t.Fatalf("unreadable artifacts: %s / %s", raw, out)
+	}
+	if meta.Output != outputDigest(out) || meta.Inputs != JSONFingerprint(state) {
+		t.Fatalf("meta %+v does not describe output %s", meta, out)
+	}
+	entries, _ := os.ReadDir(outDir)
+	for _, e := range entries {
+		if e.Name()[0] == '.' {
+			t.Fatalf("%s left behind", e.Name())
+		}
+	}
+}
+
+func TestExecutePlanFailsFastOnHeldLock(t *testing.T) {
+	outDir := t.TempDir()
+	release, err := AcquireRunLock(context.Background(), outDir, "run-holder", 0)
+	if err != nil {
+		t.Fatalf("AcquireRunLock() error = %v", err)
+	}
+	var active, peak int32
+	rt := newLockRuntime(outDir, &active, &peak)
+
+	_, err = ExecutePlan(WithRunID(context.Background(), "run-second"), rt, []string{"stamp"}, nil)
+	var lockErr *RunLockError
+	if !errors.Is(err, ErrRunLocked) || !errors.As(err, &lockErr) || lockErr.RunID != "run-holder" || lockErr.PID != os.Getpid() {
+		t.Fatalf("ExecutePlan() error = %v, want lock held by run-holder", err)
+	}
+	if peak != 0 {
+		t.Fatalf("worker ran while the lock was held")
+	}
+
+	// A waiting run starts once the holder releases.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		release()
+	}()
+	ctx := WithRunLockWait(WithRunID(context.Background(), "run-second"), 5*time.Second)
+	if _, err := ExecutePlan(ctx, rt, []string{"stamp"}, nil); err != nil || peak != 1 {
+		t.Fatalf("ExecutePlan() after release = %v (peak %d)", err, peak)
+	}
+}
+
+func TestAcquireRunLockBreaksStaleLock(t *testing.T) {
+	outDir := t.TempDir()
+	// No process has this PID; Linux caps PIDs well below it.
+	stale, _ := json.Marshal(runLockInfo{PID: 1 << 30, RunID: "run-crashed", AcquiredAt: time.Now()})
+	if err := os.WriteFile(filepath.Join(outDir, RunLockName), stale, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	release, err := AcquireRunLock(context.Background(), outDir, "run-next", 0)
+	if err != nil {
+		t.Fatalf("AcquireRunLock() over stale lock error = %v", err)
+	}
+	raw, _ := os.ReadFile(filepath.Join(outDir, RunLockName))
+	var held runLockInfo
+	if json.Unmarshal(raw, &held) != nil || held.RunID != "run-next" || held.PID != os.Getpid() {
+		t.Fatalf("lock holder = %s", raw)
+	}
+	release()
+	if _, err := os.Stat(filepath.Join(outDir, RunLockName)); !os.IsNotExist(err) {
+		t.Fatalf("release left the lock: %v", err)
+	}
+}
+
+func TestJSONStrategyIgnoresMetaOfOtherOutput(t *testing.T) {
+	ctx := context.Background()
+	rt := &testRuntime{outDir: t.TempDir()}
+	rt.artifact = artifactfs.NewFileStore(rt.outDir)
+	spec := WorkerSpec{Key: "roots"}
+	if err := JSONStrategy().Save(ctx, spec, rt, WorkerOutput{RuntimeState: map[string]int{"run": 1}}, "fp"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, ok := JSONStrategy().TryLoad(ctx, spec, rt, "fp"); !ok {
+		t.Fatalf("TryLoad() missed a fresh save")
+	}
+	// Another run replaced the output but has not written its meta yet.
+	if err := rt.artifact.Write(ctx, "roots.json", []byte(`{"run": 2}`)); err != nil {
+		t.Fatal(err)
+	}
+	if out, ok := JSONStrategy().TryLoad(ctx, spec, rt, "fp"); ok {
+		t.Fatalf("TryLoad() paired the old meta with another output: %v", out.RuntimeState)
+	}
+}
+
+func TestAcquireRunLockBreaksOwnPIDLockLeftByRestart(t *testing.T) {
+	outDir := t.TempDir()
+	// An earlier server process with the same PID (PID 1 in a container)
+	// crashed while holding the lock.
+	host, _ := os.Hostname()
+	stale, _ := json.Marshal(runLockInfo{PID: os.Getpid(), Host: host, RunID: "run-before-restart", AcquiredAt: time.Now()})
+	if err := os.WriteFile(filepath.Join(outDir, RunLockName), stale, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	release, err := AcquireRunLock(context.Background(), outDir, "run-after-restart", 0)
+	if err != nil {
+		t.Fatalf("AcquireRunLock() over own-PID stale lock error = %v", err)
+	}
+	defer release()
+
+	// A lock this process does hold is still respected.
+	_, err = AcquireRunLock(context.Background(), outDir, "run-third", 0)
+	var lockErr *RunLockError
+	if !errors.As(err, &lockErr) || lockErr.RunID != "run-after-restart" {
+		t.Fatalf("AcquireRunLock() on held lock error = %v, want held by run-after-restart", err)
+	}
+}
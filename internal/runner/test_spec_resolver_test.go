@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"slices"
+	"testing"
+)
+
+func specKeysOf(specs []WorkerSpec) []string {
+	keys := make([]string, 0, len(specs))
+	for _, s := range specs {
+		keys = append(keys, s.Key)
+	}
+	return keys
+}
+
+func TestListByTag(t *testing.T) {
+	resolver := MergeRegistries(
+		map[string]WorkerSpec{
+			"c_roots": {Key: "c_roots", Pipeline: "codebase", Strategy: versionedStrategy{}},
+			"a_specs": {Key: "a_specs", Pipeline: "codebase", Strategy: jsonStrategy{}, DryRunExecute: true},
+		},
+		map[string]WorkerSpec{
+			"b_design": {Key: "b_design", Pipeline: "architecture", Strategy: jsonStrategy{}},
+		},
+	)
+	cases := []struct {
+		tag  string
+		want []string
+	}{
+		{tag: "pipeline:codebase", want: []string{"a_specs", "c_roots"}},
+		{tag: "Pipeline:Architecture", want: []string{"b_design"}},
+		{tag: "cache:json", want: []string{"a_specs", "b_design"}},
+		{tag: "dry_run_execute", want: []string{"a_specs"}},
+		{tag: "pipeline:plan", want: []string{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.tag, func(t *testing.T) {
+			got := specKeysOf(ListByTag(resolver, tc.tag))
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("ListByTag(%q) = %v, want %v", tc.tag, got, tc.want)
+			}
+			// Resolvers without an index scan List and agree.
+			if scanned := specKeysOf(ListByTag(listOnlyResolver{resolver}, tc.tag)); !slices.Equal(scanned, tc.want) {
+				t.Fatalf("scanning ListByTag(%q) = %v, want %v", tc.tag, scanned, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolverOrderIsStable(t *testing.T) {
+	reg := map[string]WorkerSpec{}
+	for _, k := range []string{"e", "b", "d", "a", "c"} {
+		reg[k] = WorkerSpec{Key: k, Pipeline: "p"}
+	}
+	want := []string{"a", "b", "c", "d", "e"}
+	for range 10 {
+		resolver := MergeRegistries(reg)
+		if got := specKeysOf(resolver.List()); !slices.Equal(got, want) {
+			t.Fatalf("List() = %v, want %v", got, want)
+		}
+		if got := specKeysOf(ListByTag(resolver, "pipeline:p")); !slices.Equal(got, want) {
+			t.Fatalf("ListByTag() = %v, want %v", got, want)
+		}
+	}
+}
+
+// listOnlyResolver hides the tag index of the resolver it wraps.
+type listOnlyResolver struct{ SpecResolver }
@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	llm "insightify/internal/llm/middleware"
+)
+
+// sleepingLLM answers after delay unless the context ends first.
+type sleepingLLM struct{ delay time.Duration }
+
+func (c *sleepingLLM) Name() string             { return "sleeping-fake" }
+func (c *sleepingLLM) Close() error             { return nil }
+func (c *sleepingLLM) CountTokens(s string) int { return len(s) }
+func (c *sleepingLLM) TokenCapacity() int       { return 0 }
+func (c *sleepingLLM) GenerateJSON(ctx context.Context, _ string, _ any) (json.RawMessage, error) {
+	select {
+	case <-time.After(c.delay):
+		return json.RawMessage(`{}`), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+func (c *sleepingLLM) GenerateJSONStream(ctx context.Context, prompt string, in any, _ func(string)) (json.RawMessage, error) {
+	return c.GenerateJSON(ctx, prompt, in)
+}
+
+func newTimeoutRuntime(t *testing.T, delay time.Duration, timeouts map[string]time.Duration) (*testRuntime, map[string]int) {
+	t.Helper()
+	calls := map[string]int{}
+	reg := map[string]WorkerSpec{}
+	for _, key := range []string{"first", "second"} {
+		reg[key] = WorkerSpec{
+			Key:     key,
+			Timeout: timeouts[key],
+			Run: func(ctx context.Context, in any, rt Runtime) (WorkerOutput, error) {
+				calls[key]++
+				if _, err := rt.GetLLM().GenerateJSON(ctx, key, in); err != nil {
+					return WorkerOutput{}, err
+				}
+				return WorkerOutput{RuntimeState: map[string]string{"key": key}}, nil
+			},
+			Strategy: jsonStrategy{},
+		}
+	}
+	rt := &testRuntime{
+		outDir:   t.TempDir(),
+		llm:      llm.Wrap(&sleepingLLM{delay: delay}, llm.WithHooks()),
+		resolver: MergeRegistries(reg),
+	}
+	return rt, calls
+}
+
+func TestExecutePlanPhaseTimeout(t *testing.T) {
+	rt, _ := newTimeoutRuntime(t, time.Second, map[string]time.Duration{"first": 30 * time.Millisecond})
+
+	start := time.Now()
+	_, err := ExecutePlan(context.Background(), rt, []string{"first"}, nil)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("ExecutePlan() took %s, phase timeout was not enforced", elapsed)
+	}
+	var phaseErr *PhaseTimeoutError
+	if !errors.As(err, &phaseErr) || !errors.Is(err, ErrPhaseTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ExecutePlan() error = %v, want a phase timeout", err)
+	}
+	if phaseErr.Phase != "first" || phaseErr.Timeout != 30*time.Millisecond || phaseErr.Elapsed < phaseErr.Timeout {
+		t.Fatalf("PhaseTimeoutError = %+v", phaseErr)
+	}
+}
+
+func TestExecutePlanDefaultPhaseTimeout(t *testing.T) {
+	rt, _ := newTimeoutRuntime(t, time.Second, map[string]time.Duration{"second": time.Minute})
+
+	ctx := WithPhaseTimeout(context.Background(), 30*time.Millisecond)
+	_, err := ExecutePlan(ctx, rt, []string{"first"}, nil)
+	var phaseErr *PhaseTimeoutError
+	if !errors.As(err, &phaseErr) || phaseErr.Timeout != 30*time.Millisecond {
+		t.Fatalf("ExecutePlan() error = %v, want the default phase timeout", err)
+	}
+}
+
+func TestExecutePlanRunBudget(t *testing.T) {
+	timeouts := map[string]time.Duration{"first": time.Second, "second": time.Second}
+	rt, calls := newTimeoutRuntime(t, 50*time.Millisecond, timeouts)
+
+	// The first phase fits in the budget; what is left cannot cover the
+	// second phase's timeout, so it must not start.
+	params := map[string]string{RunParamBudgetMs: "1030"}
+	_, err := ExecutePlan(context.Background(), rt, []string{"first", "second"}, params)
+	if !errors.Is(err, ErrBudgetExhausted) || !strings.Contains(err.Error(), `before phase "second"`) {
+		t.Fatalf("ExecutePlan() error = %v, want budget exhausted before second", err)
+	}
+	if calls["first"] != 1 || calls["second"] != 0 {
+		t.Fatalf("calls = %v, want only the first phase run", calls)
+	}
+
+	// Phases without a timeout run until the budget itself runs out.
+	rt, _ = newTimeoutRuntime(t, time.Second, nil)
+	params = map[string]string{RunParamBudgetMs: "30"}
+	if _, err := ExecutePlan(context.Background(), rt, []string{"first"}, params); !errors.Is(err, ErrBudgetExhausted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ExecutePlan() error = %v, want budget exhausted during first", err)
+	}
+
+	if _, err := ExecutePlan(context.Background(), rt, []string{"first"}, map[string]string{RunParamBudgetMs: "soon"}); err == nil {
+		t.Fatalf("ExecutePlan() accepted an invalid budget")
+	}
+}
+
+func TestApplyRunParamsLeavesBudgetOutOfMapInputs(t *testing.T) {
+	params := map[string]string{RunParamBudgetMs: "60000", RunParamPackage: "web", "pruning": "none"}
+	m := applyRunParams(map[string]any{}, params).(map[string]any)
+	if _, ok := m[RunParamBudgetMs]; ok || m["pruning"] != "none" {
+		t.Fatalf("map input = %v, want budget_ms left out of fingerprints", m)
+	}
+	if _, ok := m[RunParamPackage]; ok {
+		t.Fatalf("map input = %v, want package left to code_imports and code_graph", m)
+	}
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"insightify/internal/workerruntime/artifactfs"
+)
+
+func TestVersionedStrategyKeepsHistory(t *testing.T) {
+	ctx := context.Background()
+	rt := &testRuntime{outDir: t.TempDir()}
+	rt.artifact = artifactfs.NewFileStore(rt.outDir)
+	spec := WorkerSpec{Key: "code_roots"}
+	t.Setenv(VersionRetentionEnv, "3")
+	strategy := VersionedStrategy()
+
+	for i := 1; i <= 4; i++ {
+		if err := strategy.Save(ctx, spec, rt, WorkerOutput{RuntimeState: map[string]int{"run": i}}, "fp"); err != nil {
+			t.Fatalf("Save(%d) error = %v", i, err)
+		}
+	}
+
+	versions, err := ListVersions(ctx, rt.artifact, "code_roots")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if fmt.Sprint(versions) != "[2 3 4]" {
+		t.Fatalf("versions = %v, want [2 3 4]", versions)
+	}
+
+	var latest map[string]int
+	raw, err := rt.artifact.Read(ctx, "code_roots.json")
+	if err != nil || json.Unmarshal(raw, &latest) != nil || latest["run"] != 4 {
+		t.Fatalf("latest should hold run 4: %s (%v)", raw, err)
+	}
+	var meta cacheMeta
+	raw, err = rt.artifact.Read(ctx, "code_roots.meta.json")
+	if err != nil || json.Unmarshal(raw, &meta) != nil || meta.Version != 4 {
+		t.Fatalf("meta should point at v4: %s (%v)", raw, err)
+	}
+	if next, _ := NextVersion(ctx, rt.artifact, "code_roots"); next != 5 {
+		t.Fatalf("NextVersion() = %d, want 5", next)
+	}
+}
+
+func TestVersionedStrategyConcurrentSavesClaimDistinctVersions(t *testing.T) {
+	ctx := context.Background()
+	outDir := t.TempDir()
+	spec := WorkerSpec{Key: "bootstrap"}
+	const runs = 8
+	t.Setenv(VersionRetentionEnv, strconv.Itoa(runs))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		// Each run has its own store over the shared directory, like two
+		// runs of the same project.
+		rt := &testRuntime{outDir: outDir, artifact: artifactfs.NewFileStore(outDir)}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- VersionedStrategy().Save(ctx, spec, rt, WorkerOutput{RuntimeState: i}, "fp")
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	versions, err := ListVersions(ctx, artifactfs.NewFileStore(outDir), "bootstrap")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != runs || versions[0] != 1 || versions[runs-1] != runs {
+		t.Fatalf("expected versions 1..%d, got %v", runs, versions)
+	}
+}
+
+func TestParseVersionedName(t *testing.T) {
+	if key, n, ok := ParseVersionedName("code_roots_v12.json"); !ok || key != "code_roots" || n != 12 {
+		t.Fatalf("ParseVersionedName() = %q %d %v", key, n, ok)
+	}
+	for _, name := range []string{"code_roots.json", "code_roots_v0.json", "code_roots_vx.json"} {
+		if _, _, ok := ParseVersionedName(name); ok {
+			t.Fatalf("%s should not parse as a version", name)
+		}
+	}
+}
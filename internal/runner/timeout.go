@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunParamBudgetMs bounds the wall time of a whole plan, in milliseconds.
+// A phase whose timeout exceeds the remaining budget is not started.
+const RunParamBudgetMs = "budget_ms"
+
+var (
+	// ErrPhaseTimeout matches errors of phases stopped by their timeout.
+	ErrPhaseTimeout = errors.New("phase timeout")
+	// ErrBudgetExhausted matches errors of plans stopped because the run
+	// budget could not cover the next phase.
+	ErrBudgetExhausted = errors.New("run budget exhausted")
+)
+
+// PhaseTimeoutError reports a phase whose Run exceeded its timeout.
+type PhaseTimeoutError struct {
+	Phase   string
+	Timeout time.Duration
+	Elapsed time.Duration
+	Err     error
+}
+
+func (e *PhaseTimeoutError) Error() string {
+	return fmt.Sprintf("phase %q timed out after %s (timeout %s): %v", e.Phase, e.Elapsed.Round(time.Millisecond), e.Timeout, e.Err)
+}
+
+func (e *PhaseTimeoutError) Unwrap() error { return e.Err }
+
+func (e *PhaseTimeoutError) Is(target error) bool { return target == ErrPhaseTimeout }
+
+type ctxKeyPhaseTimeout struct{}
+
+// WithPhaseTimeout sets the timeout of phases whose WorkerSpec.Timeout is
+// zero. Zero or negative leaves them unbounded.
+func WithPhaseTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyPhaseTimeout{}, d)
+}
+
+// phaseTimeout returns the timeout of spec under ctx; 0 means none.
+func phaseTimeout(ctx context.Context, spec WorkerSpec) time.Duration {
+	if spec.Timeout > 0 {
+		return spec.Timeout
+	}
+	d, _ := ctx.Value(ctxKeyPhaseTimeout{}).(time.Duration)
+	return max(0, d)
+}
+
+// runBudget parses RunParamBudgetMs; 0 means no budget.
+func runBudget(params map[string]string) (time.Duration, error) {
+	raw := strings.TrimSpace(params[RunParamBudgetMs])
+	if raw == "" {
+		return 0, nil
+	}
+	ms, err := strconv.Atoi(raw)
+	if err != nil || ms < 0 {
+		return 0, fmt.Errorf("invalid %s %q", RunParamBudgetMs, raw)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// checkBudget fails when ctx's deadline leaves less than timeout for phase.
+func checkBudget(ctx context.Context, phase string, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	if left := time.Until(deadline); left < timeout {
+		return fmt.Errorf("%w before phase %q: %s left, phase needs %s", ErrBudgetExhausted, phase, max(0, left).Round(time.Millisecond), timeout)
+	}
+	return nil
+}
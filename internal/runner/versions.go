@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultVersionRetention is how many <key>_vN.json files versionedStrategy keeps.
+	DefaultVersionRetention = 5
+	// VersionRetentionEnv overrides DefaultVersionRetention.
+	VersionRetentionEnv = "ARTIFACT_VERSION_RETENTION"
+)
+
+// maxVersionClaims bounds retries when concurrent saves race for a version.
+const maxVersionClaims = 32
+
+var versionedNameRE = regexp.MustCompile(`^(.+)_v(\d+)\.json$`)
+
+// versionRetention returns how many versions of a key to keep; unset,
+// invalid or non-positive values of VersionRetentionEnv give the default.
+func versionRetention() int {
+	n, err := strconv.Atoi(strings.TrimSpace(os.Getenv(VersionRetentionEnv)))
+	if err != nil || n <= 0 {
+		return DefaultVersionRetention
+	}
+	return n
+}
+
+// VersionedName returns the artifact name of version n of key, e.g. code_roots_v3.json.
+func VersionedName(key string, n int) string {
+	return fmt.Sprintf("%s_v%d.json", key, n)
+}
+
+// ParseVersionedName splits a VersionedName back into key and version.
+func ParseVersionedName(name string) (string, int, bool) {
+	m := versionedNameRE.FindStringSubmatch(name)
+	if len(m) != 3 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(m[2])
+	if err != nil || n <= 0 {
+		return "", 0, false
+	}
+	return m[1], n, true
+}
+
+// ListVersions returns the versions of key present in artifacts, ascending.
+func ListVersions(ctx context.Context, artifacts ArtifactStore, key string) ([]int, error) {
+	if artifacts == nil {
+		return nil, fmt.Errorf("artifact access is nil")
+	}
+	names, err := artifacts.List(ctx)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var versions []int
+	for _, name := range names {
+		if k, n, ok := ParseVersionedName(name); ok && k == key {
+			versions = append(versions, n)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// NextVersion returns one past the highest version of key, starting at 1.
+func NextVersion(ctx context.Context, artifacts ArtifactStore, key string) (int, error) {
+	versions, err := ListVersions(ctx, artifacts, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 1, nil
+	}
+	return versions[len(versions)-1] + 1, nil
+}
+
+// writeNextVersion stores content as the next version of key and returns
+// that version. Stores implementing ArtifactCreator claim the name
+// exclusively, retrying when another save took it first; other stores fall
+// back to a plain write. With a positive chunk, content above it is split
+// into parts: the version is claimed with the chunk index and the parts,
+// named after the claimed version, are written next.
+func writeNextVersion(ctx context.Context, artifacts ArtifactStore, key string, content []byte, chunk int64) (int, error) {
+	creator, exclusive := artifacts.(ArtifactCreator)
+	for attempt := 0; attempt < maxVersionClaims; attempt++ {
+		n, err := NextVersion(ctx, artifacts, key)
+		if err != nil {
+			return 0, err
+		}
+		name := VersionedName(key, n)
+		if !exclusive {
+			return n, writeArtifact(ctx, artifacts, name, content, chunk)
+		}
+		claim, parts := content, [][]byte(nil)
+		if chunk > 0 && int64(len(content)) > chunk {
+			if parts, claim, err = splitParts(name, content, chunk); err != nil {
+				return 0, err
+			}
+		}
+		err = creator.Create(ctx, name, claim)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		for i, p := range parts {
+			if err := artifacts.Write(ctx, PartName(name, i+1), p); err != nil {
+				return n, fmt.Errorf("write %s: %w", PartName(name, i+1), err)
+			}
+		}
+		return n, nil
+	}
+	return 0, fmt.Errorf("claim next version of %s: too many concurrent saves", key)
+}
+
+// pruneVersions removes all but the newest keep versions of key.
+func pruneVersions(ctx context.Context, artifacts ArtifactStore, key string, keep int) error {
+	versions, err := ListVersions(ctx, artifacts, key)
+	if err != nil {
+		return err
+	}
+	if keep <= 0 || len(versions) <= keep {
+		return nil
+	}
+	for _, n := range versions[:len(versions)-keep] {
+		if err := artifacts.Remove(ctx, VersionedName(key, n)); err != nil {
+			return err
+		}
+		if err := removeParts(ctx, artifacts, VersionedName(key, n), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package runner
 
 import (
 	"context"
+	"time"
+
+	llmclient "insightify/internal/llm/client"
 )
 
 // WorkerOutput bundles internal RuntimeState with an optional ClientView payload for the client.
@@ -21,6 +24,42 @@ type WorkerSpec struct {
 	Downstream  []string                             // automatically computed
 	Requires    []string
 	Strategy    CacheStrategy // how to cache (json, versioned, none)
+	// Optional lists artifacts read with Deps.ArtifactIfExists. They are not
+	// computed for the worker; a parallel plan that includes one runs it
+	// first.
+	Optional []string
+	// DryRunExecute lets a dry run execute this worker for real; set only for
+	// workers that never call the LLM.
+	DryRunExecute bool
+	// PromptVersion is stored with cached outputs; a cache entry written under
+	// another version is a miss. See PromptVersions.
+	PromptVersion string
+	// Weight is the phase's relative cost for run progress while no duration
+	// history exists; zero counts as 1.
+	Weight float64
+	// Timeout bounds one Run of the phase; zero uses the default set with
+	// WithPhaseTimeout.
+	Timeout time.Duration
+	// Generation applies to every LLM call Run makes (see
+	// llmclient.WithGenerationOptions) and is part of the cache fingerprint.
+	Generation llmclient.GenerationOptions
+	// InputWait bounds the waits on user input made with WaitForUserInput
+	// and says what happens when one times out.
+	InputWait InputWaitPolicy
+	// Pipeline names the registry the worker comes from (e.g. "codebase");
+	// RegisterPipeline sets it. RunParamPhases accepts it to select all the
+	// pipeline's workers.
+	Pipeline string
+	// MaxArtifactBytes is the size above which saving the output fails with
+	// ErrArtifactTooLarge; zero uses ArtifactMaxBytesEnv or
+	// DefaultArtifactMaxBytes, negative disables the limit.
+	MaxArtifactBytes int64
+	// ChunkedArtifact saves outputs above the limit as <key>.part-N.json
+	// files behind an index instead of failing; readers reassemble them.
+	ChunkedArtifact bool
+	// RepoGated phases, and the phases requiring them, are skipped when the
+	// RepoAssessmentKey phase finds too little code; see ExecutePlan.
+	RepoGated bool
 }
 
 // CacheStrategy abstracts artifact persistence policies (json, versioned, …).
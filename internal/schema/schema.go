@@ -0,0 +1,335 @@
+// Package schema validates LLM JSON output against per-artifact JSON Schemas
+// before it is unmarshalled into Go types.
+//
+// Only the subset of JSON Schema the embedded schemas use is supported:
+// type, properties, required, additionalProperties, items, enum, minItems.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+// Artifact keys with an embedded schema. Keys match schemas/<key>.json.
+const (
+	KeyArchDesignDelta    = "arch_design_delta"
+	KeyCodeRoots          = "code_roots"
+	KeyCodeSpecs          = "code_specs"
+	KeyCodeSymbols        = "code_symbols"
+	KeyCodeSymbolsResolve = "code_symbols_resolve"
+	KeyInfraContext       = "infra_context"
+	KeyInfraRefine        = "infra_refine"
+	KeyInitPurpose        = "init_purpose"
+	KeySourceScout        = "source_scout"
+)
+
+// Keys lists every artifact type whose LLM output is validated, that is every
+// embedded schema, sorted.
+func Keys() []string {
+	all, err := schemas()
+	if err != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(all))
+	for k := range all {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Violation is one place where a document does not conform to its schema.
+type Violation struct {
+	Pointer  string `json:"pointer"` // RFC 6901 JSON pointer, "" for the root
+	Expected string `json:"expected"`
+	Got      string `json:"got"`
+}
+
+func (v Violation) String() string {
+	p := v.Pointer
+	if p == "" {
+		p = "/"
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", p, v.Expected, v.Got)
+}
+
+// ValidationError carries every violation found for an artifact.
+type ValidationError struct {
+	Key        string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return fmt.Sprintf("%s output does not match schema: %s", e.Key, strings.Join(parts, "; "))
+}
+
+// Hint renders the violations as a corrective instruction for the model.
+func (e *ValidationError) Hint() string {
+	var b strings.Builder
+	b.WriteString("The previous output did not match the required JSON schema. Fix these fields and keep everything else unchanged:")
+	for _, v := range e.Violations {
+		b.WriteString("\n- " + v.String())
+	}
+	return b.String()
+}
+
+type node struct {
+	Type                 typeList         `json:"type"`
+	Properties           map[string]*node `json:"properties"`
+	Required             []string         `json:"required"`
+	AdditionalProperties *node            `json:"additionalProperties"`
+	Items                *node            `json:"items"`
+	Enum                 []any            `json:"enum"`
+	MinItems             *int             `json:"minItems"`
+	// closed is set when additionalProperties is false.
+	closed bool
+}
+
+func (n *node) UnmarshalJSON(b []byte) error {
+	type plain node
+	var raw struct {
+		plain
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*n = node(raw.plain)
+	switch ap := bytes.TrimSpace(raw.AdditionalProperties); {
+	case len(ap) == 0, bytes.Equal(ap, []byte("true")):
+	case bytes.Equal(ap, []byte("false")):
+		n.closed = true
+	default:
+		n.AdditionalProperties = &node{}
+		if err := json.Unmarshal(ap, n.AdditionalProperties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeList accepts "type": "x" or "type": ["x", "y"].
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*t = typeList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+var (
+	loadOnce sync.Once
+	loaded   map[string]*node
+	loadErr  error
+)
+
+func schemas() (map[string]*node, error) {
+	loadOnce.Do(func() {
+		entries, err := schemaFS.ReadDir("schemas")
+		if err != nil {
+			loadErr = err
+			return
+		}
+		loaded = make(map[string]*node, len(entries))
+		for _, e := range entries {
+			b, err := schemaFS.ReadFile(path.Join("schemas", e.Name()))
+			if err != nil {
+				loadErr = err
+				return
+			}
+			var n node
+			if err := json.Unmarshal(b, &n); err != nil {
+				loadErr = fmt.Errorf("schema %s: %w", e.Name(), err)
+				return
+			}
+			loaded[strings.TrimSuffix(e.Name(), ".json")] = &n
+		}
+	})
+	return loaded, loadErr
+}
+
+// Has reports whether a schema is embedded for key.
+func Has(key string) bool {
+	all, err := schemas()
+	if err != nil {
+		return false
+	}
+	_, ok := all[key]
+	return ok
+}
+
+// Validate checks raw against the schema for key. It returns the violations in
+// document order; an error is returned only for an unknown key or malformed JSON.
+func Validate(key string, raw json.RawMessage) ([]Violation, error) {
+	all, err := schemas()
+	if err != nil {
+		return nil, err
+	}
+	s, ok := all[key]
+	if !ok {
+		return nil, fmt.Errorf("schema: no schema registered for %q", key)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("schema: %s output is not valid JSON: %w", key, err)
+	}
+	var out []Violation
+	check(s, doc, "", &out)
+	return out, nil
+}
+
+// Check is Validate that folds violations into a *ValidationError.
+func Check(key string, raw json.RawMessage) error {
+	violations, err := Validate(key, raw)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Key: key, Violations: violations}
+	}
+	return nil
+}
+
+// GenerateValidated calls generate and validates the result against key.
+// On violation it calls generate once more with a hint listing the violations,
+// and fails with a *ValidationError if the retry still does not conform.
+func GenerateValidated(key string, generate func(hint string) (json.RawMessage, error)) (json.RawMessage, error) {
+	hint := ""
+	for attempt := 0; ; attempt++ {
+		raw, err := generate(hint)
+		if err != nil {
+			return nil, err
+		}
+		err = Check(key, raw)
+		if err == nil {
+			return raw, nil
+		}
+		verr, ok := err.(*ValidationError)
+		if !ok || attempt >= 1 {
+			return nil, err
+		}
+		hint = verr.Hint()
+	}
+}
+
+func check(s *node, v any, ptr string, out *[]Violation) {
+	if s == nil {
+		return
+	}
+	if len(s.Type) > 0 && !matchesType(s.Type, v) {
+		*out = append(*out, Violation{Pointer: ptr, Expected: strings.Join(s.Type, "|"), Got: kindOf(v)})
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		*out = append(*out, Violation{Pointer: ptr, Expected: "one of " + enumString(s.Enum), Got: fmt.Sprintf("%v", v)})
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		for _, req := range s.Required {
+			if _, ok := val[req]; !ok {
+				*out = append(*out, Violation{Pointer: ptr + "/" + escape(req), Expected: "required field", Got: "missing"})
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := ptr + "/" + escape(k)
+			if ps, ok := s.Properties[k]; ok {
+				check(ps, val[k], child, out)
+				continue
+			}
+			if s.closed {
+				*out = append(*out, Violation{Pointer: child, Expected: "no additional fields", Got: "unexpected field"})
+				continue
+			}
+			check(s.AdditionalProperties, val[k], child, out)
+		}
+	case []any:
+		if s.MinItems != nil && len(val) < *s.MinItems {
+			*out = append(*out, Violation{Pointer: ptr, Expected: fmt.Sprintf("at least %d items", *s.MinItems), Got: fmt.Sprintf("%d items", len(val))})
+		}
+		for i, item := range val {
+			check(s.Items, item, fmt.Sprintf("%s/%d", ptr, i), out)
+		}
+	}
+}
+
+func matchesType(types typeList, v any) bool {
+	got := kindOf(v)
+	for _, t := range types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func kindOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if fmt.Sprintf("%v", e) == fmt.Sprintf("%v", v) {
+			return true
+		}
+	}
+	return false
+}
+
+func enumString(enum []any) string {
+	parts := make([]string, 0, len(enum))
+	for _, e := range enum {
+		parts = append(parts, fmt.Sprintf("%v", e))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// escape encodes a property name as a JSON pointer reference token.
+func escape(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
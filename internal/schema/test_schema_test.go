@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEveryKeyHasSchemaAndGoldenPasses(t *testing.T) {
+	keys := Keys()
+	if len(keys) == 0 {
+		t.Fatalf("no schemas registered")
+	}
+	// Every key the workers validate against must be registered.
+	for _, key := range []string{KeyArchDesignDelta, KeyCodeRoots, KeyCodeSpecs, KeyCodeSymbols, KeyCodeSymbolsResolve, KeyInfraContext, KeyInfraRefine, KeyInitPurpose, KeySourceScout} {
+		if !Has(key) {
+			t.Fatalf("no schema embedded for %q", key)
+		}
+	}
+	goldens, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range goldens {
+		if key := strings.TrimSuffix(filepath.Base(g), ".json"); !Has(key) {
+			t.Fatalf("golden %s has no registered schema", g)
+		}
+	}
+	for _, key := range keys {
+		raw, err := os.ReadFile(filepath.Join("testdata", key+".json"))
+		if err != nil {
+			t.Fatalf("golden for %q: %v", key, err)
+		}
+		violations, err := Validate(key, raw)
+		if err != nil {
+			t.Fatalf("Validate(%q) error = %v", key, err)
+		}
+		if len(violations) > 0 {
+			t.Fatalf("golden %q has violations: %v", key, violations)
+		}
+	}
+}
+
+func TestValidateReportsPointerExpectedGot(t *testing.T) {
+	raw := json.RawMessage(`{"familyKeys":{"js":"js"},"specs":{"js":{"rules":{}}}}`)
+	got, err := Validate(KeyCodeSpecs, raw)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	want := []Violation{
+		{Pointer: "/familyKeys/js", Expected: "array", Got: "string"},
+		{Pointer: "/specs/js/exts", Expected: "required field", Got: "missing"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("violations mismatch\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestValidateUnknownKey(t *testing.T) {
+	if _, err := Validate("nope", json.RawMessage(`{}`)); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+}
+
+func TestGenerateValidatedRetriesOnceWithHint(t *testing.T) {
+	var hints []string
+	replies := []string{
+		`{"need_more_input":"yes","followup_question":"?"}`,
+		`{"need_more_input":true,"followup_question":"Which repo?"}`,
+	}
+	raw, err := GenerateValidated(KeyInitPurpose, func(hint string) (json.RawMessage, error) {
+		hints = append(hints, hint)
+		r := replies[0]
+		replies = replies[1:]
+		return json.RawMessage(r), nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateValidated() error = %v", err)
+	}
+	if string(raw) != `{"need_more_input":true,"followup_question":"Which repo?"}` {
+		t.Fatalf("unexpected output %s", raw)
+	}
+	if len(hints) != 2 || hints[0] != "" || hints[1] == "" {
+		t.Fatalf("unexpected hints %q", hints)
+	}
+
+	_, err = GenerateValidated(KeyInitPurpose, func(string) (json.RawMessage, error) {
+		return json.RawMessage(`{"need_more_input":1}`), nil
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) == 0 {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package runtime
+
+import (
+	"sync"
+
+	"insightify/internal/runner"
+	"insightify/internal/workerruntime/artifactblob"
+	"insightify/internal/workerruntime/artifactfs"
+)
+
+var (
+	blobMu      sync.RWMutex
+	defaultBlob artifactblob.Store
+)
+
+// SetArtifactBlobStore makes runtimes built by NewProjectDescriptor afterwards
+// keep their artifacts in store, below Key(projectID, Latest, ""). nil
+// restores plain files in the project OutDir.
+func SetArtifactBlobStore(store artifactblob.Store) {
+	blobMu.Lock()
+	defer blobMu.Unlock()
+	defaultBlob = store
+}
+
+// ArtifactBlobStore returns the store set by SetArtifactBlobStore.
+func ArtifactBlobStore() artifactblob.Store {
+	blobMu.RLock()
+	defer blobMu.RUnlock()
+	return defaultBlob
+}
+
+// projectArtifacts returns the artifact store of the project's default
+// repository: its blob namespace, or the files of outDir.
+func (r *ProjectRuntime) projectArtifacts(outDir string) runner.ArtifactStore {
+	if r.Blob != nil {
+		return artifactblob.NewRunnerStore(r.Blob, artifactblob.Key(r.ID, artifactblob.Latest, ""))
+	}
+	return artifactfs.NewFileStore(outDir)
+}
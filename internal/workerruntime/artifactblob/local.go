@@ -0,0 +1,185 @@
+package artifactblob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"insightify/internal/workerruntime/artifactfs"
+)
+
+// runsDir holds the per-run snapshots of a project in LocalStore. It is
+// hidden so listings of the Latest namespace skip it.
+const runsDir = ".runs"
+
+// LocalStore keeps blobs below root in the layout runs have always used:
+// <project>/latest/<file> is <root>/<project>/<file>, the project's OutDir,
+// and other runs live in <root>/<project>/.runs/<run>/<file>. Writes are
+// atomic. Hidden files, such as the run lock and write temps, are never
+// listed.
+type LocalStore struct {
+	root string
+	fs   *artifactfs.FileStore
+}
+
+// NewLocalStore returns a LocalStore rooted at root, the parent directory of
+// the project OutDirs.
+func NewLocalStore(root string) *LocalStore {
+	root = strings.TrimSpace(root)
+	return &LocalStore{root: root, fs: artifactfs.NewFileStore(root)}
+}
+
+// rel maps key to its path relative to root.
+func (s *LocalStore) rel(key string) (string, error) {
+	key, err := checkKey(key)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.SplitN(key, "/", 3)
+	switch {
+	case len(parts) < 2:
+		return parts[0], nil
+	case parts[1] == Latest && len(parts) == 2:
+		return parts[0], nil
+	case parts[1] == Latest:
+		return parts[0] + "/" + parts[2], nil
+	case len(parts) == 2:
+		return parts[0] + "/" + runsDir + "/" + parts[1], nil
+	default:
+		return parts[0] + "/" + runsDir + "/" + parts[1] + "/" + parts[2], nil
+	}
+}
+
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader) error {
+	name, err := s.rel(key)
+	if err != nil {
+		return err
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return s.fs.Write(ctx, name, b)
+}
+
+func (s *LocalStore) Create(ctx context.Context, key string, r io.Reader) error {
+	name, err := s.rel(key)
+	if err != nil {
+		return err
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return s.fs.Create(ctx, name, b)
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.rel(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.fs.Read(ctx, name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	name, err := s.rel(key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(s.root, filepath.FromSlash(name))); os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	return s.fs.Remove(ctx, name)
+}
+
+// List walks the directory of prefix, which must name a project and run,
+// optionally followed by a subdirectory, and returns the keys of the files
+// found below it that start with prefix.
+func (s *LocalStore) List(_ context.Context, prefix string) ([]string, error) {
+	parts := strings.SplitN(strings.Trim(prefix, "/"), "/", 3)
+	if len(parts) < 2 || parts[0] == "" {
+		return nil, ErrListPrefix
+	}
+	base := Key(parts[0], parts[1], "")
+	dir, err := s.rel(base)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Join(s.root, filepath.FromSlash(dir))
+	var out []string
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if p != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if key := base + "/" + filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			out = append(out, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// ListDir returns the keys of the visible files directly in the directory
+// of prefix, a key ending in "/".
+func (s *LocalStore) ListDir(_ context.Context, prefix string) ([]string, error) {
+	dir, err := s.rel(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(s.root, filepath.FromSlash(dir)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		out = append(out, strings.TrimSuffix(prefix, "/")+"/"+e.Name())
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// SignedURL returns "": local files are not served by URL.
+func (s *LocalStore) SignedURL(_ context.Context, key string, _ time.Duration) (string, error) {
+	_, err := s.rel(key)
+	return "", err
+}
@@ -0,0 +1,98 @@
+package artifactblob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"insightify/internal/runner"
+)
+
+// RunnerStore exposes the blobs below one key prefix, such as
+// Key(project, Latest, "") or Key(project, Latest, "repos/<name>"), as the
+// flat artifact namespace of runner.ArtifactStore: names are relative to the
+// prefix and List returns only its direct files.
+type RunnerStore struct {
+	blob   Store
+	prefix string
+}
+
+// NewRunnerStore returns the artifact namespace of prefix in blob.
+func NewRunnerStore(blob Store, prefix string) *RunnerStore {
+	return &RunnerStore{blob: blob, prefix: strings.Trim(prefix, "/") + "/"}
+}
+
+// Sub returns the namespace of dir below s, such as "repos/<name>".
+func (s *RunnerStore) Sub(dir string) *RunnerStore {
+	return NewRunnerStore(s.blob, s.prefix+strings.Trim(dir, "/"))
+}
+
+func (s *RunnerStore) Read(ctx context.Context, name string) ([]byte, error) {
+	return ReadAll(ctx, s.blob, s.prefix+name)
+}
+
+func (s *RunnerStore) Write(ctx context.Context, name string, content []byte) error {
+	return s.blob.Put(ctx, s.prefix+name, bytes.NewReader(content))
+}
+
+// Create claims name atomically when the backend is a Creator. Otherwise it
+// checks for the key first, which is racy across processes.
+func (s *RunnerStore) Create(ctx context.Context, name string, content []byte) error {
+	if c, ok := s.blob.(Creator); ok {
+		return c.Create(ctx, s.prefix+name, bytes.NewReader(content))
+	}
+	rc, err := s.blob.Get(ctx, s.prefix+name)
+	if err == nil {
+		rc.Close()
+		return ErrExist
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return s.Write(ctx, name, content)
+}
+
+// Remove deletes name; a missing name is not an error.
+func (s *RunnerStore) Remove(ctx context.Context, name string) error {
+	if err := s.blob.Delete(ctx, s.prefix+name); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *RunnerStore) List(ctx context.Context) ([]string, error) {
+	list := s.blob.List
+	if l, ok := s.blob.(DirLister); ok {
+		list = l.ListDir
+	}
+	keys, err := list(ctx, s.prefix)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, s.prefix)
+		if name == "" || strings.Contains(name, "/") || strings.HasPrefix(name, ".") {
+			continue
+		}
+		out = append(out, name)
+	}
+	return out, nil
+}
+
+// LockRun takes the run lock of the namespace. On a LocalStore it is the
+// lock file of the directory, as for runs without a blob store; other
+// backends are shared between gateways, so the lock is kept in the store.
+func (s *RunnerStore) LockRun(ctx context.Context, runID string, wait time.Duration) (func(), error) {
+	if local, ok := s.blob.(*LocalStore); ok {
+		rel, err := local.rel(s.prefix + runner.RunLockName)
+		if err != nil {
+			return nil, err
+		}
+		return runner.AcquireRunLock(ctx, filepath.Dir(filepath.Join(local.root, filepath.FromSlash(rel))), runID, wait)
+	}
+	return runner.AcquireStoreRunLock(ctx, s, runID, wait)
+}
@@ -0,0 +1,222 @@
+package artifactblob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config configures an S3Store. Prefix is prepended to every key, so
+// several deployments can share a bucket.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	Prefix    string
+	UseSSL    bool
+}
+
+// S3Store keeps blobs in an S3-compatible bucket, which it creates on first
+// use.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	region string
+	prefix string
+
+	initMu sync.Mutex
+	inited bool
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint := strings.TrimSpace(cfg.Endpoint)
+	if endpoint == "" {
+		return nil, fmt.Errorf("s3 endpoint is required")
+	}
+	access := strings.TrimSpace(cfg.AccessKey)
+	secret := strings.TrimSpace(cfg.SecretKey)
+	if access == "" || secret == "" {
+		return nil, fmt.Errorf("s3 access key and secret key are required")
+	}
+	bucket := strings.TrimSpace(cfg.Bucket)
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	region := strings.TrimSpace(cfg.Region)
+	if region == "" {
+		region = "us-east-1"
+	}
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(access, secret, ""),
+		Secure: cfg.UseSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("init s3 client: %w", err)
+	}
+	prefix := strings.Trim(strings.TrimSpace(cfg.Prefix), "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3Store{client: client, bucket: bucket, region: region, prefix: prefix}, nil
+}
+
+// ensureBucket creates the bucket unless a previous call found or created
+// it. Failures are not remembered, so a transient error or a cancelled ctx
+// only fails the operation at hand.
+func (s *S3Store) ensureBucket(ctx context.Context) error {
+	s.initMu.Lock()
+	defer s.initMu.Unlock()
+	if s.inited {
+		return nil
+	}
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("ensure bucket: %w", err)
+	}
+	if !exists {
+		err := s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{Region: s.region})
+		if err != nil && minio.ToErrorResponse(err).Code != "BucketAlreadyOwnedByYou" {
+			return fmt.Errorf("ensure bucket: %w", err)
+		}
+	}
+	s.inited = true
+	return nil
+}
+
+func (s *S3Store) object(key string) (string, error) {
+	key, err := checkKey(key)
+	if err != nil {
+		return "", err
+	}
+	return s.prefix + key, nil
+}
+
+// Put uploads the content of r, replacing the object at key.
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader) error {
+	return s.put(ctx, key, r, minio.PutObjectOptions{})
+}
+
+// Create uploads the content of r unless key exists, using a conditional
+// PUT (If-None-Match: *).
+func (s *S3Store) Create(ctx context.Context, key string, r io.Reader) error {
+	opts := minio.PutObjectOptions{}
+	opts.SetMatchETagExcept("*")
+	err := s.put(ctx, key, r, opts)
+	if minio.ToErrorResponse(err).Code == "PreconditionFailed" {
+		return ErrExist
+	}
+	return err
+}
+
+func (s *S3Store) put(ctx context.Context, key string, r io.Reader, opts minio.PutObjectOptions) error {
+	name, err := s.object(key)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureBucket(ctx); err != nil {
+		return err
+	}
+	// Artifacts arrive whole and may reach runner.DefaultArtifactMaxBytes
+	// per part. Buffering them gives minio a known size, so small ones go
+	// up in a single request and large ones in parts sized to fit, rather
+	// than in buffers sized for an object of unknown length.
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	opts.ContentType = "application/octet-stream"
+	_, err = s.client.PutObject(ctx, s.bucket, name, bytes.NewReader(b), int64(len(b)), opts)
+	return err
+}
+
+// Get returns the object at key. GetObject is lazy, so the object is
+// stat'ed first to report a missing key as ErrNotFound.
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.object(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureBucket(ctx); err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.mapErr(err)
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, s.mapErr(err)
+	}
+	return obj, nil
+}
+
+// Delete removes the object at key. S3 deletes are idempotent, so the key
+// is stat'ed first to report a missing one as ErrNotFound.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	name, err := s.object(key)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureBucket(ctx); err != nil {
+		return err
+	}
+	if _, err := s.client.StatObject(ctx, s.bucket, name, minio.StatObjectOptions{}); err != nil {
+		return s.mapErr(err)
+	}
+	return s.client.RemoveObject(ctx, s.bucket, name, minio.RemoveObjectOptions{})
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := s.ensureBucket(ctx); err != nil {
+		return nil, err
+	}
+	var out []string
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    s.prefix + strings.TrimLeft(prefix, "/"),
+		Recursive: true,
+	}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		if obj.Key != "" {
+			out = append(out, strings.TrimPrefix(obj.Key, s.prefix))
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// SignedURL presigns a GET of key. It does not check that the key exists.
+func (s *S3Store) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	name, err := s.object(key)
+	if err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		ttl = DefaultSignedURLTTL
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, name, ttl, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+func (s *S3Store) mapErr(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket":
+		return ErrNotFound
+	}
+	return err
+}
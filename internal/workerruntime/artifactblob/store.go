@@ -0,0 +1,94 @@
+// Package artifactblob stores run artifacts as blobs under keys of the form
+// <project>/<run-or-latest>/<file>, on the local filesystem or in an
+// S3-compatible bucket. Runs read and write the Latest namespace of their
+// project through RunnerStore; the gateway snapshots it per run.
+package artifactblob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// Latest is the run segment of a project's current artifacts, the ones
+// workers read and write.
+const Latest = "latest"
+
+// DefaultSignedURLTTL is how long the URLs of artifact views stay valid.
+const DefaultSignedURLTTL = time.Hour
+
+// ErrNotFound is returned for missing keys; it matches fs.ErrNotExist, which
+// the runner treats as a missing artifact.
+var ErrNotFound = fmt.Errorf("artifact blob %w", fs.ErrNotExist)
+
+// ErrExist is returned when creating a key that exists; it matches
+// fs.ErrExist.
+var ErrExist = fmt.Errorf("artifact blob %w", fs.ErrExist)
+
+// ErrListPrefix is returned by LocalStore.List for prefixes that do not name
+// a project and a run.
+var ErrListPrefix = errors.New("artifact list prefix must name a project and a run")
+
+// Store is the artifact blob storage backend (the ArtifactBlobStore).
+// Put overwrites an existing key. Get and Delete of a missing key fail with
+// ErrNotFound. List returns the keys below prefix, sorted. SignedURL returns
+// a time-limited download URL, or "" when the backend has none.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Creator is implemented by stores that can write a key only if it does not
+// exist yet; Create fails with an error matching fs.ErrExist otherwise.
+type Creator interface {
+	Create(ctx context.Context, key string, r io.Reader) error
+}
+
+// DirLister is implemented by stores that can list the keys directly below
+// a prefix without walking the ones nested deeper; RunnerStore.List uses it.
+type DirLister interface {
+	ListDir(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Key joins project, run and file into a blob key. run "" is Latest.
+func Key(project, run, file string) string {
+	run = strings.TrimSpace(run)
+	if run == "" {
+		run = Latest
+	}
+	key := strings.TrimSpace(project) + "/" + run
+	if file = strings.Trim(path.Clean("/"+strings.TrimSpace(file)), "/"); file != "" {
+		key += "/" + file
+	}
+	return key
+}
+
+// checkKey rejects keys that are empty, absolute or escape their prefix.
+func checkKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", errors.New("artifact key is required")
+	}
+	if strings.HasPrefix(key, "/") || strings.Contains(key, "..") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid artifact key: %s", key)
+	}
+	return key, nil
+}
+
+// ReadAll returns the content of key.
+func ReadAll(ctx context.Context, store Store, key string) ([]byte, error) {
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
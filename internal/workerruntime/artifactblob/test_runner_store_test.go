@@ -0,0 +1,141 @@
+package artifactblob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"insightify/internal/common/safeio"
+	llmclient "insightify/internal/llm/client"
+	"insightify/internal/mcp"
+	"insightify/internal/runner"
+)
+
+// blobRuntime runs phases with their artifacts in a RunnerStore.
+type blobRuntime struct {
+	artifacts runner.ArtifactStore
+	resolver  runner.SpecResolver
+	repoFS    *safeio.SafeFS
+}
+
+func (r *blobRuntime) GetOutDir() string                  { return "" }
+func (r *blobRuntime) GetRepoFS() *safeio.SafeFS          { return r.repoFS }
+func (r *blobRuntime) Artifacts() runner.ArtifactStore    { return r.artifacts }
+func (r *blobRuntime) GetResolver() runner.SpecResolver   { return r.resolver }
+func (r *blobRuntime) GetMCP() *mcp.Registry              { return nil }
+func (r *blobRuntime) GetModelSalt() string               { return "" }
+func (r *blobRuntime) GetForceFrom() string               { return "" }
+func (r *blobRuntime) GetDepsUsage() runner.DepsUsageMode { return runner.DepsUsageError }
+func (r *blobRuntime) GetLLM() llmclient.LLMClient        { return nil }
+
+// TestRunnerCachesThroughLocalStore runs a JSON and a versioned phase twice
+// over the local backend: the JSON phase is a cache hit the second time and
+// the versions, metas and latest outputs land in the project OutDir.
+func TestRunnerCachesThroughLocalStore(t *testing.T) {
+	root := t.TempDir()
+	repoFS, err := safeio.NewSafeFS(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	runs := map[string]int{}
+	reg := map[string]runner.WorkerSpec{
+		"roots": {
+			Key:        "roots",
+			BuildInput: func(context.Context, runner.Deps) (any, error) { return map[string]string{"in": "1"}, nil },
+			Run: func(context.Context, any, runner.Runtime) (runner.WorkerOutput, error) {
+				runs["roots"]++
+				return runner.WorkerOutput{RuntimeState: map[string]string{"root": "."}}, nil
+			},
+			Strategy: runner.JSONStrategy(),
+		},
+		"tasks": {
+			Key:      "tasks",
+			Requires: []string{"roots"},
+			BuildInput: func(_ context.Context, deps runner.Deps) (any, error) {
+				var roots map[string]string
+				return roots, deps.Artifact("roots", &roots)
+			},
+			Run: func(_ context.Context, in any, _ runner.Runtime) (runner.WorkerOutput, error) {
+				runs["tasks"]++
+				return runner.WorkerOutput{RuntimeState: in}, nil
+			},
+			Strategy: runner.VersionedStrategy(),
+		},
+	}
+	rt := &blobRuntime{
+		artifacts: NewRunnerStore(NewLocalStore(root), Key("p1", Latest, "")),
+		resolver:  runner.MergeRegistries(reg),
+		repoFS:    repoFS,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := runner.ExecutePlan(context.Background(), rt, []string{"roots", "tasks"}, nil); err != nil {
+			t.Fatalf("ExecutePlan #%d: %v", i+1, err)
+		}
+	}
+	if runs["roots"] != 1 || runs["tasks"] != 2 {
+		t.Fatalf("runs = %v, want roots cached and tasks run twice", runs)
+	}
+	for _, name := range []string{"roots.json", "roots.meta.json", "tasks.json", "tasks_v1.json", "tasks_v2.json", "tasks.meta.json"} {
+		if _, err := os.Stat(filepath.Join(root, "p1", name)); err != nil {
+			t.Fatalf("%s not in the project OutDir: %v", name, err)
+		}
+	}
+}
+
+func TestRunnerStoreLocksRuns(t *testing.T) {
+	root := t.TempDir()
+	cases := []struct {
+		name  string
+		store func(t *testing.T) Store
+	}{
+		{name: "local", store: func(t *testing.T) Store { return NewLocalStore(root) }},
+		{name: "s3", store: func(t *testing.T) Store { return newTestS3Store(t, "") }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			blob := tc.store(t)
+			rs := NewRunnerStore(blob, Key("p1", Latest, ""))
+			release, err := rs.LockRun(ctx, "run-a", 0)
+			if err != nil {
+				t.Fatalf("LockRun(run-a): %v", err)
+			}
+			if _, local := blob.(*LocalStore); local {
+				// The same lock file as runs without a blob store.
+				if _, err := os.Stat(filepath.Join(root, "p1", runner.RunLockName)); err != nil {
+					t.Fatalf("local lock is not in the project OutDir: %v", err)
+				}
+			}
+			var lockErr *runner.RunLockError
+			if _, err := rs.LockRun(ctx, "run-b", 0); !errors.As(err, &lockErr) || lockErr.RunID != "run-a" {
+				t.Fatalf("LockRun(run-b) error = %v, want locked by run-a", err)
+			}
+			release()
+			release, err = rs.LockRun(ctx, "run-b", 0)
+			if err != nil {
+				t.Fatalf("LockRun(run-b) after release: %v", err)
+			}
+			release()
+		})
+	}
+}
+
+func TestRunnerStoreBreaksExpiredLock(t *testing.T) {
+	ctx := context.Background()
+	rs := NewRunnerStore(newTestS3Store(t, ""), Key("p1", Latest, ""))
+	expired := `{"pid":1,"host":"other-gateway","run_id":"run-gone","acquired_at":"2026-01-01T00:00:00Z","expires_at":"2026-01-01T00:00:30Z"}`
+	if err := rs.Write(ctx, runner.RunLockName, []byte(expired)); err != nil {
+		t.Fatalf("Write lock: %v", err)
+	}
+	release, err := rs.LockRun(ctx, "run-b", 0)
+	if err != nil {
+		t.Fatalf("LockRun over an expired lock: %v", err)
+	}
+	release()
+	if _, err := rs.Read(ctx, runner.RunLockName); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("lock after release error = %v, want removed", err)
+	}
+}
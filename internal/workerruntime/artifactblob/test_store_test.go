@@ -0,0 +1,350 @@
+package artifactblob
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is a MinIO-style S3 endpoint serving path-style bucket and object
+// requests from memory: HEAD/PUT bucket, PUT (honouring If-None-Match: *),
+// GET/HEAD/DELETE object and ListObjectsV2.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+}
+
+func newFakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+	f := &fakeS3{buckets: map[string]map[string][]byte{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, ok := f.buckets[bucket]
+	if key == "" {
+		switch {
+		case r.Method == http.MethodPut:
+			f.buckets[bucket] = map[string][]byte{}
+		case !ok:
+			s3Error(w, http.StatusNotFound, "NoSuchBucket")
+		case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+			f.list(w, bucket, objects, r.URL.Query().Get("prefix"))
+		}
+		return
+	}
+	if !ok {
+		s3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		body, err := readS3Body(r)
+		if err != nil {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		if _, exists := objects[key]; exists && r.Header.Get("If-None-Match") == "*" {
+			s3Error(w, http.StatusPreconditionFailed, "PreconditionFailed")
+			return
+		}
+		objects[key] = body
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet, http.MethodHead:
+		body, ok := objects[key]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			s3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, bucket string, objects map[string][]byte, prefix string) {
+	type content struct {
+		Key  string
+		Size int
+	}
+	res := struct {
+		XMLName  xml.Name `xml:"ListBucketResult"`
+		Name     string
+		Prefix   string
+		KeyCount int
+		Contents []content
+	}{Name: bucket, Prefix: prefix}
+	for key, body := range objects {
+		if strings.HasPrefix(key, prefix) {
+			res.Contents = append(res.Contents, content{Key: key, Size: len(body)})
+		}
+	}
+	sort.Slice(res.Contents, func(i, j int) bool { return res.Contents[i].Key < res.Contents[j].Key })
+	res.KeyCount = len(res.Contents)
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(res)
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+// readS3Body decodes the aws-chunked bodies minio-go streams over plain
+// HTTP: "<hex size>;chunk-signature=...\r\n<data>\r\n", ending with size 0.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	br := bufio.NewReader(r.Body)
+	var out []byte
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		var size int
+		if _, err := fmt.Sscanf(strings.SplitN(line, ";", 2)[0], "%x", &size); err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return out, nil
+		}
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return nil, err
+		}
+		out = append(out, chunk[:size]...)
+	}
+}
+
+func newTestS3Store(t *testing.T, prefix string) *S3Store {
+	t.Helper()
+	srv := newFakeS3(t)
+	store, err := NewS3Store(S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		AccessKey: "access",
+		SecretKey: "secret",
+		Bucket:    "artifacts",
+		Prefix:    prefix,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	return store
+}
+
+func get(t *testing.T, store Store, key string) string {
+	t.Helper()
+	b, err := ReadAll(context.Background(), store, key)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", key, err)
+	}
+	return string(b)
+}
+
+func put(t *testing.T, store Store, key, body string) {
+	t.Helper()
+	if err := store.Put(context.Background(), key, strings.NewReader(body)); err != nil {
+		t.Fatalf("Put(%s): %v", key, err)
+	}
+}
+
+// testStoreSemantics checks the behavior every backend shares.
+func testStoreSemantics(t *testing.T, store Store) {
+	ctx := context.Background()
+	if _, err := store.Get(ctx, Key("p1", "", "missing.json")); !errors.Is(err, ErrNotFound) || !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Get(missing) error = %v, want ErrNotFound matching fs.ErrNotExist", err)
+	}
+	if err := store.Delete(ctx, Key("p1", "", "missing.json")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Delete(missing) error = %v, want ErrNotFound", err)
+	}
+
+	put(t, store, Key("p1", "", "code_roots.json"), `{"v":1}`)
+	put(t, store, Key("p1", "", "code_roots.json"), `{"v":2}`)
+	if got := get(t, store, Key("p1", "", "code_roots.json")); got != `{"v":2}` {
+		t.Fatalf("after overwrite Get = %s, want the second write", got)
+	}
+
+	put(t, store, Key("p1", "", "repos/api/code_roots.json"), "api")
+	put(t, store, Key("p1", "run-1", "code_roots.json"), "snapshot")
+	put(t, store, Key("p2", "", "code_roots.json"), "other project")
+	keys, err := store.List(ctx, Key("p1", "", "")+"/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := []string{"p1/latest/code_roots.json", "p1/latest/repos/api/code_roots.json"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("List(p1/latest/) = %v, want %v", keys, want)
+	}
+	if got := get(t, store, Key("p1", "run-1", "code_roots.json")); got != "snapshot" {
+		t.Fatalf("run snapshot = %s", got)
+	}
+
+	if err := store.Delete(ctx, Key("p1", "", "code_roots.json")); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, Key("p1", "", "code_roots.json")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(deleted) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "../escape"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get(../escape) error = %v, want an invalid key", err)
+	}
+}
+
+func TestLocalStoreSemantics(t *testing.T) {
+	testStoreSemantics(t, NewLocalStore(t.TempDir()))
+}
+
+func TestS3StoreSemantics(t *testing.T) {
+	testStoreSemantics(t, newTestS3Store(t, ""))
+}
+
+func TestS3StoreRetriesBucketSetupAfterFailure(t *testing.T) {
+	store := newTestS3Store(t, "")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := store.Put(cancelled, Key("p1", "", "code_roots.json"), strings.NewReader("v1")); err == nil {
+		t.Fatalf("Put with a cancelled ctx succeeded")
+	}
+	put(t, store, Key("p1", "", "code_roots.json"), "v1")
+	if got := get(t, store, Key("p1", "", "code_roots.json")); got != "v1" {
+		t.Fatalf("Get after a failed first use = %s", got)
+	}
+}
+
+func TestLocalStoreKeepsOutDirLayout(t *testing.T) {
+	root := t.TempDir()
+	store := NewLocalStore(root)
+	put(t, store, Key("p1", "", "code_roots.json"), "latest")
+	put(t, store, Key("p1", "run-1", "code_roots.json"), "snapshot")
+
+	for rel, want := range map[string]string{
+		"p1/code_roots.json":             "latest",
+		"p1/.runs/run-1/code_roots.json": "snapshot",
+	} {
+		b, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil || string(b) != want {
+			t.Fatalf("%s = %q, %v; want %q", rel, b, err, want)
+		}
+	}
+
+	// Files written by earlier runs straight into the OutDir stay readable;
+	// hidden ones, such as the run lock, are never listed.
+	if err := os.WriteFile(filepath.Join(root, "p1", "code_stats.json"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "p1", ".run.lock"), []byte("lock"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(t, store, Key("p1", "", "code_stats.json")); got != "old" {
+		t.Fatalf("pre-existing artifact = %s", got)
+	}
+	keys, err := store.List(context.Background(), "p1/latest/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if want := []string{"p1/latest/code_roots.json", "p1/latest/code_stats.json"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("List = %v, want %v", keys, want)
+	}
+}
+
+func TestS3StorePrefixAndSignedURL(t *testing.T) {
+	store := newTestS3Store(t, "/team-a/")
+	put(t, store, Key("p1", "run-1", "code_graph.json"), "graph")
+	keys, err := store.List(context.Background(), "p1/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if want := []string{"p1/run-1/code_graph.json"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("List = %v, want keys without the store prefix %v", keys, want)
+	}
+	url, err := store.SignedURL(context.Background(), Key("p1", "run-1", "code_graph.json"), time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	if !strings.Contains(url, "/artifacts/team-a/p1/run-1/code_graph.json?") || !strings.Contains(url, "X-Amz-Expires=60") {
+		t.Fatalf("SignedURL = %s, want a presigned GET of the prefixed key", url)
+	}
+}
+
+func TestRunnerStore(t *testing.T) {
+	cases := []struct {
+		name  string
+		store func(t *testing.T) Store
+	}{
+		{name: "local", store: func(t *testing.T) Store { return NewLocalStore(t.TempDir()) }},
+		{name: "s3", store: func(t *testing.T) Store { return newTestS3Store(t, "") }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			blob := tc.store(t)
+			rs := NewRunnerStore(blob, Key("p1", Latest, ""))
+			if _, err := rs.Read(ctx, "code_roots.json"); !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("Read(missing) error = %v, want fs.ErrNotExist", err)
+			}
+			if err := rs.Write(ctx, "code_roots.json", []byte("v1")); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if err := rs.Write(ctx, "code_roots.json.meta.json", []byte("{}")); err != nil {
+				t.Fatalf("Write meta: %v", err)
+			}
+			if err := rs.Create(ctx, "code_tasks_v1.json", []byte("v1")); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if err := rs.Create(ctx, "code_tasks_v1.json", []byte("v2")); !errors.Is(err, fs.ErrExist) {
+				t.Fatalf("Create(existing) error = %v, want fs.ErrExist", err)
+			}
+			if err := rs.Sub("repos/api").Write(ctx, "code_roots.json", []byte("api")); err != nil {
+				t.Fatalf("Sub Write: %v", err)
+			}
+			if got := get(t, blob, "p1/latest/repos/api/code_roots.json"); got != "api" {
+				t.Fatalf("repo artifact = %s", got)
+			}
+			names, err := rs.List(ctx)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if want := []string{"code_roots.json", "code_roots.json.meta.json", "code_tasks_v1.json"}; !reflect.DeepEqual(names, want) {
+				t.Fatalf("List = %v, want the flat namespace %v", names, want)
+			}
+			if err := rs.Remove(ctx, "code_roots.json"); err != nil {
+				t.Fatalf("Remove: %v", err)
+			}
+			if err := rs.Remove(ctx, "code_roots.json"); err != nil {
+				t.Fatalf("Remove(missing) error = %v, want nil", err)
+			}
+		})
+	}
+}
@@ -34,7 +34,58 @@ func (s *FileStore) Write(_ context.Context, name string, content []byte) error
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
-	return os.WriteFile(path, content, 0o644)
+	return writeAtomic(path, content)
+}
+
+// writeAtomic writes content to a hidden temp file next to path and renames
+// it into place, so readers see either the old or the new content in full.
+func writeAtomic(path string, content []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Create writes content only if name does not exist yet; otherwise it returns
+// an error matching fs.ErrExist. O_EXCL makes the claim atomic across
+// processes sharing the directory.
+func (s *FileStore) Create(_ context.Context, name string, content []byte) error {
+	path, err := s.pathFor(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func (s *FileStore) Remove(_ context.Context, name string) error {
@@ -62,7 +113,8 @@ func (s *FileStore) List(_ context.Context) ([]string, error) {
 	}
 	out := make([]string, 0, len(entries))
 	for _, e := range entries {
-		if e.IsDir() {
+		// Hidden files are write temps and the run lock, not artifacts.
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
 			continue
 		}
 		out = append(out, e.Name())
@@ -0,0 +1,87 @@
+package runtime
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+
+	extpipe "insightify/internal/workers/external"
+)
+
+// Env variables extending infra detection; each is a comma separated list
+// merged with the built-in sets.
+const (
+	InfraExtsEnv        = "INFRA_EXTS"
+	InfraFilesEnv       = "INFRA_FILES"
+	InfraDirKeywordsEnv = "INFRA_DIR_KEYWORDS"
+	InfraDenyEnv        = "INFRA_DENY"
+)
+
+// Env variables overriding the per-file byte caps of infra sampling.
+// InfraSampleCapEnv is the cap of files without a specific entry;
+// InfraSampleCapsEnv lists "<ext or base name>=<bytes>" pairs, comma
+// separated (e.g. ".json=8000,package-lock.json=500"). Both apply on top of
+// extpipe.DefaultSampleCaps; 0 removes a cap.
+const (
+	InfraSampleCapEnv  = "INFRA_SAMPLE_CAP"
+	InfraSampleCapsEnv = "INFRA_SAMPLE_CAPS"
+)
+
+// InfraDetectFromEnv reads the infra detection overrides from the
+// environment.
+func InfraDetectFromEnv() extpipe.InfraDetect {
+	return extpipe.InfraDetect{
+		Exts:        envList(InfraExtsEnv),
+		Files:       envList(InfraFilesEnv),
+		DirKeywords: envList(InfraDirKeywordsEnv),
+		Deny:        envList(InfraDenyEnv),
+	}
+}
+
+// SampleCapsFromEnv reads the sample cap overrides from the environment. It
+// returns the zero SampleCaps, which selects the defaults, when neither
+// variable is set.
+func SampleCapsFromEnv() (extpipe.SampleCaps, error) {
+	rawDefault := strings.TrimSpace(os.Getenv(InfraSampleCapEnv))
+	pairs := envList(InfraSampleCapsEnv)
+	if rawDefault == "" && len(pairs) == 0 {
+		return extpipe.SampleCaps{}, nil
+	}
+	caps := extpipe.SampleCaps{
+		Default: extpipe.DefaultSampleCaps.Default,
+		PerExt:  maps.Clone(extpipe.DefaultSampleCaps.PerExt),
+	}
+	if rawDefault != "" {
+		n, err := strconv.Atoi(rawDefault)
+		if err != nil || n < 0 {
+			return extpipe.SampleCaps{}, fmt.Errorf("%s: invalid byte cap %q", InfraSampleCapEnv, rawDefault)
+		}
+		caps.Default = n
+	}
+	for _, pair := range pairs {
+		name, raw, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if !ok || name == "" || err != nil || n < 0 {
+			return extpipe.SampleCaps{}, fmt.Errorf("%s: invalid entry %q, want <ext or name>=<bytes>", InfraSampleCapsEnv, pair)
+		}
+		// Extensions are matched lowercased; base names exactly.
+		if strings.HasPrefix(name, ".") {
+			name = strings.ToLower(name)
+		}
+		caps.PerExt[name] = n
+	}
+	return caps, nil
+}
+
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
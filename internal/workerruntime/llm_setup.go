@@ -3,32 +3,42 @@ package runtime
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	llmclient "insightify/internal/llm/client"
 	llmmiddleware "insightify/internal/llm/middleware"
 	llmmodel "insightify/internal/llm/model"
+	"insightify/internal/runner"
 )
 
-func newRuntimeLLMClient(ctx context.Context) (llmclient.LLMClient, string, error) {
+// NewLLMClient builds the same LLM client a project runtime uses, with model
+// overrides loaded from the environment.
+func NewLLMClient(ctx context.Context) (llmclient.LLMClient, error) {
+	overrides, err := llmmodel.LoadModelOverridesFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cli, _, err := newRuntimeLLMClient(ctx, overrides)
+	return cli, err
+}
+
+func newRuntimeLLMClient(ctx context.Context, overrides llmmodel.ModelOverrides) (llmclient.LLMClient, string, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	// Removed globalctx usage
 
-	reg := llmmodel.NewInMemoryModelRegistry()
-	geminiTier := firstNonEmpty(strings.TrimSpace(os.Getenv("LLM_GEMINI_TIER")), "free")
-	groqTier := firstNonEmpty(strings.TrimSpace(os.Getenv("LLM_GROQ_TIER")), "free")
-	if err := llmclient.RegisterGeminiModelsForTier(reg, geminiTier); err != nil {
-		return nil, "", err
-	}
-	if err := llmclient.RegisterGroqModelsForTier(reg, groqTier); err != nil {
+	reg, err := NewModelRegistry()
+	if err != nil {
 		return nil, "", err
 	}
-	if err := llmmodel.RegisterFakeModels(reg); err != nil {
+	reg.SetCircuitBreaker(llmmiddleware.DefaultCircuitBreaker())
+	if err := reg.SetModelOverrides(overrides); err != nil {
 		return nil, "", err
 	}
 
@@ -39,20 +49,162 @@ func newRuntimeLLMClient(ctx context.Context) (llmclient.LLMClient, string, erro
 		}
 	}
 
+	selectionMode, err := llmmodel.ParseModelSelectionMode(os.Getenv("LLM_SELECTION_MODE"))
+	if err != nil {
+		return nil, "", err
+	}
+
 	fallback, err := reg.BuildClient(ctx, llmmodel.ModelRoleWorker, llmmodel.ModelLevelMiddle, "", "", tokenCap)
 	if err != nil {
 		return nil, "", fmt.Errorf("llm fallback client failed: %w", err)
 	}
 
-	dispatch := llmmodel.NewModelDispatchClient(fallback)
-	client := llmmiddleware.Wrap(dispatch,
-		llmmodel.SelectModel(reg, tokenCap, llmmodel.ModelSelectionModePreferAvailable),
+	// Calls that select no model still draw from the fallback's quota bucket.
+	dispatch := llmmodel.NewModelDispatchClient(llmmiddleware.SharedMultiLimit(reg.Limiters())(fallback))
+	mws := []llmmiddleware.Middleware{
+		llmmodel.SelectModel(reg, tokenCap, selectionMode),
+		// Phases running in parallel may send the same request; send it once.
+		llmmiddleware.Dedupe(),
 		llmmiddleware.RespectRateLimitSignals(llmclient.HeaderRateLimitControlAdapter{}),
 		llmmiddleware.Retry(3, 300*time.Millisecond),
+	}
+	// Repair inside Retry so unrepairable output is retried.
+	if jsonRepairEnabled() {
+		mws = append(mws, llmmiddleware.RepairJSON())
+	}
+	mws = append(mws,
+		// Inside Retry so every attempt is priced and checked against the
+		// run's cost budget.
+		llmmiddleware.RecordRunUsage(reg.Pricing),
+		reg.CircuitBreaker().Middleware(),
+		llmmiddleware.SharedMultiLimit(reg.Limiters()),
+		llmmiddleware.WithLogging(nil, logLevelFromEnv("LLM_LOG_LEVEL", slog.LevelDebug)),
 		llmmiddleware.WithHooks(),
 	)
-	modelSalt := strings.TrimSpace(os.Getenv("CACHE_SALT")) + "|" + reg.DefaultsSalt()
-	return client, modelSalt, nil
+	// Outermost so retries reuse the redacted request and hooks see no secrets.
+	if redactLLMInput() {
+		mws = append([]llmmiddleware.Middleware{llmmiddleware.RedactInput(nil)}, mws...)
+	}
+	client := llmmiddleware.Wrap(dispatch, mws...)
+	chain := llmmiddleware.DescribeChain(client)
+	logChain(chain)
+	recordChain(chain)
+	return client, modelSalt(reg, overrides), nil
+}
+
+var (
+	chainMu    sync.Mutex
+	lastChain  []llmmiddleware.MiddlewareInfo
+	chainBuilt bool
+)
+
+// recordChain keeps chain for LLMChain.
+func recordChain(chain []llmmiddleware.MiddlewareInfo) {
+	chainMu.Lock()
+	defer chainMu.Unlock()
+	lastChain, chainBuilt = chain, true
+}
+
+// LLMChain returns the middleware chain of the runtime LLM client built most
+// recently, and false until one has been built.
+func LLMChain() ([]llmmiddleware.MiddlewareInfo, bool) {
+	chainMu.Lock()
+	defer chainMu.Unlock()
+	return lastChain, chainBuilt
+}
+
+// logChain logs the composed middleware order and any misordering Validate
+// finds, so wiring mistakes show up at startup rather than deep in a run.
+func logChain(chain []llmmiddleware.MiddlewareInfo) {
+	slog.Info("llm middleware chain", "chain", llmmiddleware.FormatChain(chain))
+	for _, issue := range llmmiddleware.Validate(chain) {
+		level := slog.LevelWarn
+		if issue.Severity == llmmiddleware.SeverityError {
+			level = slog.LevelError
+		}
+		slog.Log(context.Background(), level, "llm middleware chain: "+issue.Message)
+	}
+}
+
+// ModelSaltFromEnv returns the model salt a project runtime would use with the
+// models and overrides configured in the environment.
+func ModelSaltFromEnv() (string, error) {
+	overrides, err := llmmodel.LoadModelOverridesFromEnv()
+	if err != nil {
+		return "", err
+	}
+	reg, err := NewModelRegistry()
+	if err != nil {
+		return "", err
+	}
+	if err := reg.SetModelOverrides(overrides); err != nil {
+		return "", err
+	}
+	return modelSalt(reg, overrides), nil
+}
+
+func modelSalt(reg *llmmodel.InMemoryModelRegistry, overrides llmmodel.ModelOverrides) string {
+	salt := CacheSalt(reg.DefaultsSalt())
+	if o := overrides.Salt(); o != "" {
+		salt += "|overrides:" + o
+	}
+	return salt
+}
+
+// CacheSalt is the model salt for cached worker outputs: model and the active
+// runner.PromptVersions, then CACHE_SALT as a manual override when set.
+func CacheSalt(model string) string {
+	salt := runner.BuildModelSalt(model, runner.PromptVersions)
+	if extra := strings.TrimSpace(os.Getenv("CACHE_SALT")); extra != "" {
+		salt += "|" + extra
+	}
+	return salt
+}
+
+// NewModelRegistry registers the Gemini and Groq models for the tiers in
+// LLM_GEMINI_TIER/LLM_GROQ_TIER (default "free") plus the fake models.
+func NewModelRegistry() (*llmmodel.InMemoryModelRegistry, error) {
+	reg := llmmodel.NewInMemoryModelRegistry()
+	geminiTier := firstNonEmpty(strings.TrimSpace(os.Getenv("LLM_GEMINI_TIER")), "free")
+	groqTier := firstNonEmpty(strings.TrimSpace(os.Getenv("LLM_GROQ_TIER")), "free")
+	if err := llmclient.RegisterGeminiModelsForTier(reg, geminiTier); err != nil {
+		return nil, err
+	}
+	if err := llmclient.RegisterGroqModelsForTier(reg, groqTier); err != nil {
+		return nil, err
+	}
+	if err := llmmodel.RegisterFakeModels(reg); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// jsonRepairEnabled reports whether LLM_JSON_REPAIR allows repairing model
+// JSON; it is on unless set to a false value.
+func jsonRepairEnabled() bool {
+	on, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("LLM_JSON_REPAIR")))
+	return err != nil || on
+}
+
+// redactLLMInput reports whether REDACT_LLM_INPUT asks to strip secrets from
+// what is sent to the model, not only from what is persisted; it is off
+// unless set to a true value.
+func redactLLMInput() bool {
+	on, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("REDACT_LLM_INPUT")))
+	return on
+}
+
+// logLevelFromEnv parses debug/info/warn/error, returning def when unset or invalid.
+func logLevelFromEnv(key string, def slog.Level) slog.Level {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return def
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(raw)); err != nil {
+		return def
+	}
+	return level
 }
 
 func firstNonEmpty(values ...string) string {
@@ -2,18 +2,30 @@ package runtime
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"insightify/internal/common/safeio"
 	"insightify/internal/common/scan"
 	llmclient "insightify/internal/llm/client"
+	llmmodel "insightify/internal/llm/model"
 	"insightify/internal/mcp"
 	"insightify/internal/runner"
+	"insightify/internal/workerruntime/artifactblob"
 	"insightify/internal/workerruntime/artifactfs"
+	extpipe "insightify/internal/workers/external"
 )
 
 // ProjectRuntime holds long-lived runtime dependencies for a project.
+//
+// NewProjectDescriptor sets up only the paths and repositories; Resolver,
+// MCP, LLM and ModelSalt stay unset until Materialize, which the first run of
+// the project calls.
 type ProjectRuntime struct {
 	ID       string
 	RepoName string
@@ -21,14 +33,52 @@ type ProjectRuntime struct {
 
 	RepoFS     *safeio.SafeFS
 	ArtifactFS *safeio.SafeFS
-	Resolver   runner.SpecResolver
-	MCP        *mcp.Registry
-	ModelSalt  string
-	ForceFrom  string
-	DepsUsage  runner.DepsUsageMode
-	LLM        llmclient.LLMClient
+	// Blob, when set, holds the project's artifacts instead of OutDir for
+	// executions without an OutDir override; see SetArtifactBlobStore. The
+	// run lock is taken from it too; prompt logs stay in OutDir.
+	Blob artifactblob.Store
+	// Repos lists every repository of a multi-repo project; Repos[0] is the
+	// default one and shares RepoFS/OutDir with single-repo projects.
+	Repos     []RepoRuntime
+	Resolver  runner.SpecResolver
+	MCP       *mcp.Registry
+	ModelSalt string
+	ForceFrom string
+	DepsUsage runner.DepsUsageMode
+	LLM       llmclient.LLMClient
+	// SampleCaps overrides the per-file byte caps of infra sampling.
+	SampleCaps extpipe.SampleCaps
+	// InfraDetect extends infra sampling; NewProjectDescriptor reads it
+	// with InfraDetectFromEnv.
+	InfraDetect extpipe.InfraDetect
+	// Workers lists the keys of the workers Resolver provides, known without
+	// materializing; see HasWorkers.
+	Workers []string
 
 	Cleanup func()
+
+	materializeMu sync.Mutex
+	builds        int
+}
+
+// RepoEntry describes one repository registered on a project.
+type RepoEntry struct {
+	Name string
+	URL  string
+	// LocalPath defaults to ReposDir/<Name>. It becomes the SafeFS root
+	// as is, so callers must restrict user-supplied paths to ReposDir.
+	LocalPath string
+}
+
+// RepoRuntime is a repository opened for a project runtime.
+type RepoRuntime struct {
+	RepoEntry
+	FS *safeio.SafeFS
+}
+
+// RepoOutDir returns the artifact namespace of a non-default repository.
+func RepoOutDir(outDir, repo string) string {
+	return filepath.Join(outDir, "repos", repo)
 }
 
 // ExecutionOptions controls per-execution runtime overrides.
@@ -37,11 +87,34 @@ type ExecutionOptions struct {
 	ForceFrom     string
 	DepsUsage     runner.DepsUsageMode
 	ArtifactStore runner.ArtifactStore
+	// ReadBudgetBytes caps the bytes the execution reads through its
+	// repository filesystems; past it reads fail with
+	// safeio.ErrReadBudgetExceeded. Zero uses ReadBudgetEnv, and negative
+	// or no env value is unlimited.
+	ReadBudgetBytes int64
+}
+
+// ReadBudgetEnv sets the read budget, in bytes, of executions whose options
+// leave ReadBudgetBytes zero.
+const ReadBudgetEnv = "RUN_READ_BUDGET_BYTES"
+
+// readBudgetFromEnv returns ReadBudgetEnv, or zero when it is unset or not a
+// byte count.
+func readBudgetFromEnv() int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(os.Getenv(ReadBudgetEnv)), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
 }
 
 // ExecutionRuntime provides a runner.Runtime view for a single execution.
 type ExecutionRuntime struct {
 	project   *ProjectRuntime
+	rootView  *ExecutionRuntime // default-repo view for ForRepo views
+	repo      string
+	repoFS    *safeio.SafeFS
+	budget    *safeio.ReadBudget
 	outDir    string
 	forceFrom string
 	depsUsage runner.DepsUsageMode
@@ -61,22 +134,35 @@ func (r *ProjectRuntime) NewExecutionRuntime(opts ExecutionOptions) *ExecutionRu
 	if outDir == "" {
 		outDir = r.OutDir
 	}
+	readBudget := opts.ReadBudgetBytes
+	if readBudget == 0 {
+		readBudget = readBudgetFromEnv()
+	}
+	budget := safeio.NewReadBudget(readBudget)
 	exec := &ExecutionRuntime{
 		project:   r,
+		repoFS:    r.RepoFS.WithReadBudget(budget),
+		budget:    budget,
 		outDir:    outDir,
 		forceFrom: opts.ForceFrom,
 		depsUsage: opts.DepsUsage,
 	}
 	exec.artifact = opts.ArtifactStore
+	if exec.artifact == nil && opts.OutDir == "" {
+		exec.artifact = r.projectArtifacts(outDir)
+	}
 	if exec.artifact == nil {
 		exec.artifact = artifactfs.NewFileStore(outDir)
 	}
+	if len(r.Repos) > 0 {
+		exec.repo = r.Repos[0].Name
+	}
 	return exec
 }
 
 // runner.Runtime interface implementation.
 func (r *ExecutionRuntime) GetOutDir() string                  { return r.outDir }
-func (r *ExecutionRuntime) GetRepoFS() *safeio.SafeFS          { return r.project.RepoFS }
+func (r *ExecutionRuntime) GetRepoFS() *safeio.SafeFS          { return r.repoFS }
 func (r *ExecutionRuntime) Artifacts() runner.ArtifactStore    { return r.artifact }
 func (r *ExecutionRuntime) GetResolver() runner.SpecResolver   { return r.project.Resolver }
 func (r *ExecutionRuntime) GetMCP() *mcp.Registry              { return r.project.MCP }
@@ -85,9 +171,98 @@ func (r *ExecutionRuntime) GetForceFrom() string               { return r.forceF
 func (r *ExecutionRuntime) GetDepsUsage() runner.DepsUsageMode { return r.depsUsage }
 func (r *ExecutionRuntime) GetLLM() llmclient.LLMClient        { return r.project.LLM }
 
-// NewProjectRuntime constructs the full runtime environment for a project.
-func NewProjectRuntime(repoName, projectID string) (*ProjectRuntime, error) {
+func (r *ExecutionRuntime) GetSampleCaps() extpipe.SampleCaps   { return r.project.SampleCaps }
+func (r *ExecutionRuntime) GetInfraDetect() extpipe.InfraDetect { return r.project.InfraDetect }
+
+// runner.MultiRepoRuntime implementation.
+func (r *ExecutionRuntime) CurrentRepo() string { return r.repo }
+
+func (r *ExecutionRuntime) RepoNames() []string {
+	if len(r.project.Repos) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(r.project.Repos))
+	for _, repo := range r.project.Repos {
+		names = append(names, repo.Name)
+	}
+	return names
+}
+
+// ForRepo returns a view bound to the named repository. The default
+// repository is the view itself; others read their sources from their own
+// root and keep artifacts under RepoOutDir. The view shares the project's
+// resolver, LLM and MCP registry, and the read budget of the execution.
+func (r *ExecutionRuntime) ForRepo(name string) (runner.Runtime, bool) {
+	for i, repo := range r.project.Repos {
+		if repo.Name != name {
+			continue
+		}
+		if i == 0 {
+			return r.root(), true
+		}
+		outDir := RepoOutDir(r.root().outDir, name)
+		var artifacts runner.ArtifactStore = artifactfs.NewFileStore(outDir)
+		if blob, ok := r.root().artifact.(*artifactblob.RunnerStore); ok {
+			artifacts = blob.Sub("repos/" + name)
+		}
+		return &ExecutionRuntime{
+			project:   r.project,
+			rootView:  r.root(),
+			repo:      name,
+			repoFS:    repo.FS.WithReadBudget(r.budget),
+			budget:    r.budget,
+			outDir:    outDir,
+			forceFrom: r.forceFrom,
+			depsUsage: r.depsUsage,
+			artifact:  artifacts,
+		}, true
+	}
+	return nil, false
+}
+
+// root returns the default-repository view this runtime was derived from.
+func (r *ExecutionRuntime) root() *ExecutionRuntime {
+	if r.rootView != nil {
+		return r.rootView
+	}
+	return r
+}
+
+// ArtifactsDir holds the project OutDirs, and is the root of the local
+// artifact blob store.
+const ArtifactsDir = "tmp/artifacts"
+
+// ProjectOutDir returns the artifact directory used for a project's runs.
+func ProjectOutDir(projectID string) string {
+	return filepath.Join(ArtifactsDir, projectID)
+}
+
+// NewProjectRuntime constructs the full runtime environment for a project:
+// NewProjectDescriptor followed by Materialize.
+func NewProjectRuntime(repoName, projectID string, repos ...RepoEntry) (*ProjectRuntime, error) {
+	rt, err := NewProjectDescriptor(repoName, projectID, repos...)
+	if err != nil {
+		return nil, err
+	}
+	if err := rt.Materialize(); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// NewProjectDescriptor opens the repositories and out dir of a project
+// without building its worker registry or LLM client, so projects that are
+// only listed stay cheap. repos registers the repositories of a multi-repo
+// project; the first one is the default and becomes RepoFS.
+func NewProjectDescriptor(repoName, projectID string, repos ...RepoEntry) (*ProjectRuntime, error) {
+	opened, err := openRepos(repos)
+	if err != nil {
+		return nil, err
+	}
 	repoFS := safeio.Default()
+	if len(opened) > 0 {
+		repoFS = opened[0].FS
+	}
 	if repoFS == nil {
 		cwd, err := os.Getwd()
 		if err != nil {
@@ -99,7 +274,7 @@ func NewProjectRuntime(repoName, projectID string) (*ProjectRuntime, error) {
 		}
 	}
 
-	outDir := filepath.Join("tmp", "artifacts", projectID)
+	outDir := ProjectOutDir(projectID)
 	if err := os.MkdirAll(outDir, 0o755); err != nil {
 		return nil, err
 	}
@@ -108,28 +283,134 @@ func NewProjectRuntime(repoName, projectID string) (*ProjectRuntime, error) {
 		return nil, err
 	}
 
-	llmCli, modelSalt, err := newRuntimeLLMClient(context.Background())
-	if err != nil {
-		return nil, err
-	}
-
 	rt := &ProjectRuntime{
 		ID:         projectID,
 		RepoName:   repoName,
 		OutDir:     outDir,
 		RepoFS:     repoFS,
 		ArtifactFS: artifactFS,
-		LLM:        llmCli,
-		ModelSalt:  modelSalt,
+		Blob:       ArtifactBlobStore(),
+		Repos:      opened,
+		Workers:    registeredWorkers(),
+	}
+	rt.InfraDetect = InfraDetectFromEnv()
+	if rt.SampleCaps, err = SampleCapsFromEnv(); err != nil {
+		return nil, err
 	}
 	rt.Cleanup = func() {
+		rt.materializeMu.Lock()
+		defer rt.materializeMu.Unlock()
 		if rt.LLM != nil {
 			_ = rt.LLM.Close()
 		}
 	}
-	rt.MCP = mcp.NewRegistry()
-	mcp.RegisterDefaultTools(rt.MCP, mcp.Host{RepoRoot: repoFS.Root(), ReposRoot: scan.ReposDir(), RepoFS: repoFS, ArtifactFS: artifactFS})
-	runtimeView := rt.Runtime()
-	rt.Resolver = runner.BuildAllRegistries(runtimeView)
 	return rt, nil
 }
+
+// Materialize builds the LLM client, MCP registry and worker resolver of the
+// runtime. Only the first call builds; concurrent callers wait for it. A
+// runtime whose Resolver is already set is left as is. A failed build leaves
+// the runtime unmaterialized, so the next call retries.
+func (r *ProjectRuntime) Materialize() error {
+	r.materializeMu.Lock()
+	defer r.materializeMu.Unlock()
+	if r.Resolver != nil {
+		return nil
+	}
+
+	overrides, err := llmmodel.LoadModelOverridesFromEnv()
+	if err != nil {
+		return err
+	}
+	llmCli, modelSalt, err := newRuntimeLLMClient(context.Background(), overrides)
+	if err != nil {
+		return err
+	}
+	reg := mcp.NewRegistry()
+	mcp.RegisterDefaultTools(reg, mcp.Host{RepoRoot: r.RepoFS.Root(), ReposRoot: scan.ReposDir(), RepoFS: r.RepoFS, ArtifactFS: r.ArtifactFS})
+
+	r.LLM, r.ModelSalt, r.MCP = llmCli, modelSalt, reg
+	resolver := runner.BuildAllRegistries(r.Runtime())
+	err = runner.ValidateResolver(resolver)
+	if err == nil {
+		err = overrides.ValidatePhases(specKeys(resolver))
+	}
+	if err != nil {
+		if llmCli != nil {
+			_ = llmCli.Close()
+		}
+		r.LLM, r.ModelSalt, r.MCP = nil, "", nil
+		return err
+	}
+	r.Resolver = resolver
+	r.builds++
+	return nil
+}
+
+// Materializations reports how many times Materialize built the runtime:
+// 0 or 1.
+func (r *ProjectRuntime) Materializations() int {
+	r.materializeMu.Lock()
+	defer r.materializeMu.Unlock()
+	return r.builds
+}
+
+// HasWorkers reports whether the runtime provides every worker in keys. It
+// consults Workers, falling back to Resolver for runtimes built without one.
+func (r *ProjectRuntime) HasWorkers(keys ...string) bool {
+	if r == nil {
+		return false
+	}
+	for _, key := range keys {
+		if r.Workers != nil {
+			if !slices.Contains(r.Workers, key) {
+				return false
+			}
+			continue
+		}
+		if r.Resolver == nil {
+			return false
+		}
+		if _, ok := r.Resolver.Get(key); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// registeredWorkers lists the keys of every registered worker. Registry
+// builders do not depend on the runtime they are given, so the list is
+// computed once per process.
+var registeredWorkers = sync.OnceValue(func() []string {
+	keys := specKeys(runner.BuildAllRegistries(nil))
+	slices.Sort(keys)
+	return keys
+})
+
+func openRepos(entries []RepoEntry) ([]RepoRuntime, error) {
+	out := make([]RepoRuntime, 0, len(entries))
+	for _, e := range entries {
+		root := strings.TrimSpace(e.LocalPath)
+		if root == "" {
+			var err error
+			if root, err = scan.ResolveRepo(e.Name); err != nil {
+				return nil, fmt.Errorf("repo %s: %w", e.Name, err)
+			}
+		}
+		fs, err := safeio.NewSafeFS(root)
+		if err != nil {
+			return nil, fmt.Errorf("repo %s: %w", e.Name, err)
+		}
+		out = append(out, RepoRuntime{RepoEntry: e, FS: fs})
+	}
+	return out, nil
+}
+
+func specKeys(resolver runner.SpecResolver) []string {
+	specs := resolver.List()
+	keys := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		keys = append(keys, spec.Key)
+	}
+	return keys
+}
@@ -0,0 +1,36 @@
+package runtime
+
+import (
+	"testing"
+
+	extpipe "insightify/internal/workers/external"
+)
+
+func TestSampleCapsFromEnv(t *testing.T) {
+	t.Setenv(InfraSampleCapEnv, "")
+	t.Setenv(InfraSampleCapsEnv, "")
+	if caps, err := SampleCapsFromEnv(); err != nil || caps.Default != 0 || caps.PerExt != nil {
+		t.Fatalf("unset env = %+v, %v; want the zero caps", caps, err)
+	}
+
+	t.Setenv(InfraSampleCapEnv, "12000")
+	t.Setenv(InfraSampleCapsEnv, ".JSON=9000, package-lock.json=0")
+	caps, err := SampleCapsFromEnv()
+	if err != nil {
+		t.Fatalf("SampleCapsFromEnv: %v", err)
+	}
+	if caps.Limit("src/main.go") != 12000 || caps.Limit("conf/app.json") != 9000 || caps.Limit("package-lock.json") != 0 {
+		t.Fatalf("caps = %+v, want the overrides", caps)
+	}
+	if caps.Limit("main.tf") != extpipe.DefaultSampleCaps.PerExt[".tf"] {
+		t.Fatalf(".tf cap = %d, want the default kept", caps.Limit("main.tf"))
+	}
+	if extpipe.DefaultSampleCaps.PerExt[".json"] != 4000 {
+		t.Fatalf("the defaults were modified")
+	}
+
+	t.Setenv(InfraSampleCapsEnv, ".json")
+	if _, err := SampleCapsFromEnv(); err == nil {
+		t.Fatalf("expected an error for an entry without a cap")
+	}
+}
@@ -0,0 +1,28 @@
+package runtime
+
+import "testing"
+
+func TestNewExecutionRuntimeReadsReadBudgetEnv(t *testing.T) {
+	r := &ProjectRuntime{OutDir: t.TempDir()}
+
+	t.Setenv(ReadBudgetEnv, "")
+	if got := r.NewExecutionRuntime(ExecutionOptions{}).budget.Limit(); got != 0 {
+		t.Fatalf("unset env budget = %d, want unlimited", got)
+	}
+
+	t.Setenv(ReadBudgetEnv, "4096")
+	if got := r.NewExecutionRuntime(ExecutionOptions{}).budget.Limit(); got != 4096 {
+		t.Fatalf("env budget = %d, want 4096", got)
+	}
+	if got := r.NewExecutionRuntime(ExecutionOptions{ReadBudgetBytes: 10}).budget.Limit(); got != 10 {
+		t.Fatalf("explicit budget = %d, want the option over the env", got)
+	}
+	if got := r.NewExecutionRuntime(ExecutionOptions{ReadBudgetBytes: -1}).budget.Limit(); got != 0 {
+		t.Fatalf("negative budget = %d, want unlimited despite the env", got)
+	}
+
+	t.Setenv(ReadBudgetEnv, "lots")
+	if got := r.NewExecutionRuntime(ExecutionOptions{}).budget.Limit(); got != 0 {
+		t.Fatalf("invalid env budget = %d, want unlimited", got)
+	}
+}
@@ -8,10 +8,13 @@ import (
 
 	"insightify/internal/artifact"
 	"insightify/internal/common/delta"
+	"insightify/internal/common/fileclass"
 	llmclient "insightify/internal/llm/client"
 	"insightify/internal/llm/tool"
 	"insightify/internal/common/scan"
 	"insightify/internal/common/utils"
+	"insightify/internal/mdcondense"
+	"insightify/internal/schema"
 )
 
 type archDesignDeltaOut struct {
@@ -41,6 +44,9 @@ var archDesignPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 		"When inputs are large, work incrementally: entrypoints, build/manifest, configuration, wiring/adapters, public APIs.",
 		"Explicitly mention external nodes/services (APIs, queues, DBs, third-party SaaS) when evidence exists.",
 		"If there are no changes, return empty delta arrays.",
+		llmtool.RegenHintRule,
+		"md_docs are condensed to headings and leading paragraphs; a doc with truncated=true was cut further. Read the file with fs.read when a section matters.",
+		"file_index entries with is_binary, is_generated or is_vendored are bundles, lockfiles, generated or third-party code; do not read or cite them unless the evidence needed is only there. fs.read skips them unless called with force=true.",
 	},
 	Assumptions: []string{
 		"If uncertain, add to architecture_hypothesis.assumptions and reduce confidence.",
@@ -50,6 +56,10 @@ var archDesignPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 	Language:     "English",
 }, llmtool.PresetStrictJSON(), llmtool.PresetNoInvent(), llmtool.PresetCautious())
 
+// DefaultMDDocTokens caps each condensed markdown doc when
+// ArchDesignIn.MDDocTokens is unset.
+const DefaultMDDocTokens = 1500
+
 type ArchDesign struct {
 	LLM   llmclient.LLMClient
 	Tools llmtool.ToolProvider
@@ -69,7 +79,7 @@ func (p *ArchDesign) Run(ctx context.Context, in artifact.ArchDesignIn) (artifac
 
 	if len(in.FileIndex) == 0 || len(in.MDDocs) == 0 {
 		// Use calculated ignoreDirs
-		idx, mds := scanForArchDesign(in.Repo, ignoreDirs)
+		idx, mds := scanForArchDesign(ctx, in.Repo, ignoreDirs)
 		if len(in.FileIndex) == 0 {
 			in.FileIndex = idx
 		}
@@ -83,12 +93,22 @@ func (p *ArchDesign) Run(ctx context.Context, in artifact.ArchDesignIn) (artifac
 	}
 	state := defaultArchDesignOut()
 
-	// Eliminate noise from documents just before prompt construction
+	// Condense documents just before prompt construction so doc-heavy repos
+	// leave room for the file index.
+	maxTokens := in.MDDocTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMDDocTokens
+	}
 	promptDocs := make([]artifact.MDDoc, len(in.MDDocs))
 	for i, d := range in.MDDocs {
+		text, truncated := mdcondense.Condense(d.Text, mdcondense.Options{
+			MaxTokens:   maxTokens,
+			CountTokens: p.LLM.CountTokens,
+		})
 		promptDocs[i] = artifact.MDDoc{
-			Path: d.Path,
-			Text: utils.MarkDownClean(d.Text),
+			Path:      d.Path,
+			Text:      text,
+			Truncated: truncated,
 		}
 	}
 
@@ -110,7 +130,13 @@ func (p *ArchDesign) Run(ctx context.Context, in artifact.ArchDesignIn) (artifac
 			Allowed:  []string{"scan.list", "fs.read", "wordidx.search", "snippet.collect", "delta.diff"},
 		}
 
-		raw, _, err := loop.Run(ctx, input, llmtool.StructuredPromptBuilder(archDesignPromptSpec))
+		raw, err := schema.GenerateValidated(schema.KeyArchDesignDelta, func(hint string) (json.RawMessage, error) {
+			if hint != "" {
+				input["regen_hint"] = hint
+			}
+			raw, _, err := loop.Run(ctx, input, llmtool.StructuredPromptBuilder(archDesignPromptSpec))
+			return raw, err
+		})
 		if err != nil {
 			return artifact.ArchDesignOut{}, err
 		}
@@ -128,9 +154,22 @@ func (p *ArchDesign) Run(ctx context.Context, in artifact.ArchDesignIn) (artifac
 			break
 		}
 	}
+	state.FlaggedFiles = flaggedFiles(in.FileIndex)
 	return state, nil
 }
 
+// flaggedFiles returns the entries of idx that are binary, generated or
+// vendored.
+func flaggedFiles(idx []artifact.FileIndexEntry) []artifact.FileIndexEntry {
+	var out []artifact.FileIndexEntry
+	for _, e := range idx {
+		if e.Flagged() {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
 func defaultArchDesignOut() artifact.ArchDesignOut {
 	return artifact.ArchDesignOut{
 		ArchitectureHypothesis: artifact.ArchDesignHypothesis{
@@ -169,14 +208,24 @@ func isEmptyDelta(d delta.Delta) bool {
 	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
 }
 
-func scanForArchDesign(repo string, ignore []string) ([]artifact.FileIndexEntry, []artifact.MDDoc) {
+func scanForArchDesign(ctx context.Context, repo string, ignore []string) ([]artifact.FileIndexEntry, []artifact.MDDoc) {
 	var idx []artifact.FileIndexEntry
 	var mds []artifact.MDDoc
-	_ = scan.ScanWithOptions(repo, scan.Options{IgnoreDirs: ignore}, func(f scan.FileVisit) {
+	stats, err := scan.ScanWithStats(repo, scan.Options{IgnoreDirs: ignore}, func(f scan.FileVisit) {
 		if f.IsDir {
 			return
 		}
-		idx = append(idx, artifact.FileIndexEntry{Path: f.Path, Size: f.Size})
+		// An unreadable file keeps the flags of its path.
+		flags, _ := fileclass.Classify(scan.CurrentSafeFS(), f.AbsPath)
+		flags = flags.WithPath(f.Path)
+		idx = append(idx, artifact.FileIndexEntry{
+			Path:        f.Path,
+			Size:        f.Size,
+			IsBinary:    flags.Binary,
+			IsGenerated: flags.Generated,
+			IsVendored:  flags.Vendored,
+			LineCount:   flags.Lines,
+		})
 		if strings.EqualFold(f.Ext, ".md") {
 			if b, e := scan.CurrentSafeFS().SafeReadFile(f.AbsPath); e == nil {
 				// Keep raw text here
@@ -184,5 +233,7 @@ func scanForArchDesign(repo string, ignore []string) ([]artifact.FileIndexEntry,
 			}
 		}
 	})
+	// A truncated scan still indexes what it reached.
+	_ = scan.ReportTruncated(ctx, repo, stats, err)
 	return idx, mds
 }
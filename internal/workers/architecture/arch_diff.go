@@ -0,0 +1,55 @@
+package mainline
+
+import (
+	"context"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/artifact"
+	"insightify/internal/artifactdiff"
+)
+
+// ArchDiff reports, without the LLM, how the architecture hypothesis moved
+// between the arch_design output the last arch_design run replaced and the
+// current one. Components renamed between the two are matched by normalized
+// name, then by their evidence paths.
+type ArchDiff struct{}
+
+func (ArchDiff) Run(_ context.Context, in artifact.ArchDiffIn) (artifact.ArchDiffOut, error) {
+	out := artifact.ArchDiffOut{
+		HasBase:  in.Base != nil,
+		Added:    []string{},
+		Removed:  []string{},
+		Renamed:  []artifact.Rename{},
+		Modified: []string{},
+		Head:     in.Head,
+	}
+	if in.Base == nil {
+		out.Summary = []string{"no earlier arch_design to compare with"}
+		return out, nil
+	}
+	d := artifactdiff.DiffArchDesign(*in.Base, in.Head)
+	out.Summary = d.Summary()
+	out.Added = append(out.Added, d.Components.Added...)
+	out.Removed = append(out.Removed, d.Components.Removed...)
+	out.Renamed = append(out.Renamed, d.Renamed...)
+	for _, m := range d.Components.Modified {
+		out.Modified = append(out.Modified, m.Field)
+	}
+	for _, m := range d.Hypothesis.Modified {
+		out.Modified = append(out.Modified, m.Field)
+	}
+	return out, nil
+}
+
+// ArchDiffView renders the head hypothesis of in as ArchDesignView does,
+// annotated with the changes since its base.
+func ArchDiffView(in artifact.ArchDiffIn) *workerv1.ClientView {
+	var changes *artifactdiff.ArchDiff
+	if in.Base != nil {
+		d := artifactdiff.DiffArchDesign(*in.Base, in.Head)
+		changes = &d
+	}
+	view := ArchDesignView(in.Head, changes, 0)
+	view.Phase = "arch_diff"
+	return view
+}
@@ -0,0 +1,287 @@
+package mainline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/artifact"
+	"insightify/internal/artifactdiff"
+	"insightify/internal/common/utils"
+)
+
+// DefaultArchViewMaxNodes caps the nodes of ArchDesignView when maxNodes is
+// not positive.
+const DefaultArchViewMaxNodes = 40
+
+// Change annotations of component nodes, relative to the previous hypothesis.
+const (
+	ChangeAdded    = "added"
+	ChangeModified = "modified"
+	ChangeRemoved  = "removed"
+	ChangeRenamed  = "renamed"
+)
+
+const (
+	archSystemUID  = "system"
+	archOtherKind  = "other"
+	maxClusterList = 20 // member names listed in a cluster description
+)
+
+// archComponent is a component node before UIDs are assigned.
+type archComponent struct {
+	name     string
+	kind     string
+	desc     string
+	change   string
+	modified []string // changed fields for ChangeModified and ChangeRenamed
+	from     string   // previous name for ChangeRenamed
+}
+
+// ArchDesignView renders an arch_design output as a graph ClientView: a
+// system node described by the purpose and summary, with one child node per
+// key component (responsibility, kind and evidence paths in the description)
+// linked by parent and by a system->component edge. The hypothesis has no
+// component relationships, so those are the only edges.
+//
+// changes, when set, annotates components as added, renamed or modified
+// since the previous hypothesis and adds nodes for removed ones. When the graph would
+// exceed maxNodes, components collapse into one cluster node per kind, the
+// smallest kinds folding into an "other" cluster if needed. Nodes are ordered
+// by kind, then name, and get stable UIDs from utils.AssignGraphNodeUIDs.
+func ArchDesignView(out artifact.ArchDesignOut, changes *artifactdiff.ArchDiff, maxNodes int) *workerv1.ClientView {
+	if maxNodes <= 0 {
+		maxNodes = DefaultArchViewMaxNodes
+	}
+	h := out.ArchitectureHypothesis
+	comps := archComponents(h.KeyComponents, changes)
+
+	nodes := []*workerv1.GraphNode{{
+		Uid:         archSystemUID,
+		Label:       "System",
+		Description: joinNonEmpty("\n\n", h.Purpose, h.Summary),
+	}}
+	if 1+len(comps) <= maxNodes {
+		for _, c := range comps {
+			nodes = append(nodes, &workerv1.GraphNode{
+				Uid:         "component:" + c.name,
+				Label:       c.name,
+				Description: c.desc,
+				ParentUid:   archSystemUID,
+			})
+		}
+	} else {
+		nodes = append(nodes, archClusters(comps, maxNodes-1)...)
+	}
+	edges := make([]*workerv1.GraphEdge, 0, len(nodes)-1)
+	for _, n := range nodes[1:] {
+		edges = append(edges, &workerv1.GraphEdge{From: archSystemUID, To: n.Uid})
+	}
+
+	view := &workerv1.ClientView{
+		Phase: "arch_design",
+		Content: &workerv1.ClientView_Graph{
+			Graph: &workerv1.GraphView{Nodes: nodes, Edges: edges},
+		},
+	}
+	// AssignGraphNodeUIDs rewrites edges only; parents follow the same map.
+	ids := utils.AssignGraphNodeUIDs(view)
+	for _, n := range nodes {
+		if uid, ok := ids[n.ParentUid]; ok {
+			n.ParentUid = uid
+		}
+	}
+	return view
+}
+
+// archComponents returns the named components, later duplicates winning,
+// plus the removed ones of changes, sorted by kind then name.
+func archComponents(src []artifact.ArchDesignKeyComponent, changes *artifactdiff.ArchDiff) []archComponent {
+	var added, removed map[string]bool
+	renamedFrom := map[string]string{}
+	if changes != nil {
+		added, removed = toSet(changes.Components.Added), toSet(changes.Components.Removed)
+		for _, r := range changes.Renamed {
+			renamedFrom[r.To] = r.From
+		}
+	}
+
+	byName := map[string]archComponent{}
+	for _, kc := range src {
+		name := strings.TrimSpace(kc.Name)
+		if name == "" {
+			continue
+		}
+		c := archComponent{name: name, kind: strings.TrimSpace(kc.Kind)}
+		if added[name] {
+			c.change = ChangeAdded
+		} else if from, ok := renamedFrom[name]; ok {
+			c.change, c.from, c.modified = ChangeRenamed, from, modifiedFields(changes, name)
+		} else if fields := modifiedFields(changes, name); len(fields) > 0 {
+			c.change, c.modified = ChangeModified, fields
+		}
+		c.desc = componentDescription(kc, c)
+		byName[name] = c
+	}
+	for name := range removed {
+		if _, ok := byName[name]; ok {
+			continue
+		}
+		c := archComponent{name: name, change: ChangeRemoved}
+		c.desc = "Change: removed since the previous hypothesis."
+		byName[name] = c
+	}
+
+	comps := make([]archComponent, 0, len(byName))
+	for _, c := range byName {
+		comps = append(comps, c)
+	}
+	sort.Slice(comps, func(i, j int) bool {
+		if comps[i].kind != comps[j].kind {
+			return comps[i].kind < comps[j].kind
+		}
+		return comps[i].name < comps[j].name
+	})
+	return comps
+}
+
+func componentDescription(kc artifact.ArchDesignKeyComponent, c archComponent) string {
+	var lines []string
+	if r := strings.TrimSpace(kc.Responsibility); r != "" {
+		lines = append(lines, r)
+	}
+	if c.kind != "" {
+		lines = append(lines, "Kind: "+c.kind)
+	}
+	if ev := evidencePaths(kc.Evidence); len(ev) > 0 {
+		lines = append(lines, "Evidence: "+strings.Join(ev, ", "))
+	}
+	switch c.change {
+	case ChangeAdded:
+		lines = append(lines, "Change: added since the previous hypothesis.")
+	case ChangeModified:
+		lines = append(lines, "Change: modified since the previous hypothesis ("+strings.Join(c.modified, ", ")+").")
+	case ChangeRenamed:
+		line := "Change: renamed from " + c.from + " since the previous hypothesis"
+		if len(c.modified) > 0 {
+			line += " (" + strings.Join(c.modified, ", ") + ")"
+		}
+		lines = append(lines, line+".")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// evidencePaths formats evidence as path or path:start-end, once each.
+func evidencePaths(refs []artifact.EvidenceRef) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		p := strings.TrimSpace(ref.Path)
+		if p == "" {
+			continue
+		}
+		if ref.Lines != nil {
+			p = fmt.Sprintf("%s:%d-%d", p, ref.Lines[0], ref.Lines[1])
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// archClusters collapses comps into at most limit cluster nodes, one per
+// kind; past the limit the smallest kinds fold into an "other" cluster.
+func archClusters(comps []archComponent, limit int) []*workerv1.GraphNode {
+	if limit < 1 {
+		limit = 1
+	}
+	byKind := map[string][]archComponent{}
+	var kinds []string
+	for _, c := range comps {
+		kind := c.kind
+		if kind == "" {
+			kind = archOtherKind
+		}
+		if _, ok := byKind[kind]; !ok {
+			kinds = append(kinds, kind)
+		}
+		byKind[kind] = append(byKind[kind], c)
+	}
+	if len(kinds) > limit {
+		// Keep the largest kinds; the rest, with unnamed ones, become "other".
+		sort.SliceStable(kinds, func(i, j int) bool { return len(byKind[kinds[i]]) > len(byKind[kinds[j]]) })
+		var keep []string
+		for _, kind := range kinds {
+			if kind != archOtherKind && len(keep) < limit-1 {
+				keep = append(keep, kind)
+				continue
+			}
+			if kind != archOtherKind {
+				byKind[archOtherKind] = append(byKind[archOtherKind], byKind[kind]...)
+				delete(byKind, kind)
+			}
+		}
+		kinds = append(keep, archOtherKind)
+		sort.Slice(byKind[archOtherKind], func(i, j int) bool { return byKind[archOtherKind][i].name < byKind[archOtherKind][j].name })
+	}
+	sort.Strings(kinds)
+
+	nodes := make([]*workerv1.GraphNode, 0, len(kinds))
+	for _, kind := range kinds {
+		members := byKind[kind]
+		names := make([]string, 0, len(members))
+		for _, c := range members {
+			name := c.name
+			if c.change != "" {
+				name += " (" + c.change + ")"
+			}
+			names = append(names, name)
+		}
+		if len(names) > maxClusterList {
+			names = append(names[:maxClusterList], fmt.Sprintf("… %d more", len(members)-maxClusterList))
+		}
+		nodes = append(nodes, &workerv1.GraphNode{
+			Uid:         "cluster:" + kind,
+			Label:       fmt.Sprintf("%s (%d)", kind, len(members)),
+			Description: "Components: " + strings.Join(names, ", "),
+			ParentUid:   archSystemUID,
+		})
+	}
+	return nodes
+}
+
+// modifiedFields returns the changed fields of component name; changes
+// reports them as "<name>.<field>".
+func modifiedFields(changes *artifactdiff.ArchDiff, name string) []string {
+	if changes == nil {
+		return nil
+	}
+	var fields []string
+	for _, m := range changes.Components.Modified {
+		if field, ok := strings.CutPrefix(m.Field, name+"."); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func toSet(names []string) map[string]bool {
+	out := make(map[string]bool, len(names))
+	for _, n := range names {
+		out[n] = true
+	}
+	return out
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
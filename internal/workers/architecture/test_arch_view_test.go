@@ -0,0 +1,144 @@
+package mainline
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/artifact"
+	"insightify/internal/artifactdiff"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func readArchFixture(t *testing.T) artifact.ArchDesignOut {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "arch_design.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out artifact.ArchDesignOut
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func checkViewGolden(t *testing.T, name string, view *workerv1.ClientView) {
+	t.Helper()
+	got, err := json.MarshalIndent(view.GetGraph(), "", "  ")
+	if err != nil {
+		t.Fatalf("marshal graph: %v", err)
+	}
+	got = append(got, '\n')
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s mismatch (run with -update to accept)\ngot:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+func TestArchDesignViewGolden(t *testing.T) {
+	out := readArchFixture(t)
+	view := ArchDesignView(out, nil, 0)
+	g := view.GetGraph()
+	if view.GetPhase() != "arch_design" || len(g.GetNodes()) != 6 || len(g.GetEdges()) != 5 {
+		t.Fatalf("phase %q, %d nodes, %d edges; want arch_design, system + 5 components, 5 edges", view.GetPhase(), len(g.GetNodes()), len(g.GetEdges()))
+	}
+	root := g.GetNodes()[0].GetUid()
+	for _, n := range g.GetNodes()[1:] {
+		if n.GetParentUid() != root {
+			t.Fatalf("node %s parent = %q, want system %q", n.GetLabel(), n.GetParentUid(), root)
+		}
+	}
+	checkViewGolden(t, "arch_view.golden.json", view)
+
+	// Input order and repeated rendering do not change the output.
+	comps := out.ArchitectureHypothesis.KeyComponents
+	for i, j := 0, len(comps)-1; i < j; i, j = i+1, j-1 {
+		comps[i], comps[j] = comps[j], comps[i]
+	}
+	again, _ := json.Marshal(ArchDesignView(out, nil, 0).GetGraph())
+	first, _ := json.Marshal(g)
+	if !bytes.Equal(first, again) {
+		t.Fatalf("unstable output:\n%s\n%s", first, again)
+	}
+}
+
+func TestArchDesignViewChanges(t *testing.T) {
+	after := readArchFixture(t)
+	before := readArchFixture(t)
+	h := &before.ArchitectureHypothesis
+	h.KeyComponents = append(h.KeyComponents[1:], artifact.ArchDesignKeyComponent{Name: "Queue", Kind: "store"})
+	h.KeyComponents[0].Responsibility = "Serves HTTP"
+
+	b, _ := json.Marshal(before)
+	a, _ := json.Marshal(after)
+	res, err := artifactdiff.Compare(artifactdiff.KeyArchDesign, b, a, artifactdiff.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := ArchDesignView(after, res.Arch, 0)
+	want := map[string]string{
+		"Runner":  "Change: added",
+		"Gateway": "Change: modified since the previous hypothesis (responsibility)",
+		"Queue":   "Change: removed",
+		"Scanner": "",
+	}
+	for _, n := range view.GetGraph().GetNodes() {
+		w, ok := want[n.GetLabel()]
+		if !ok {
+			continue
+		}
+		delete(want, n.GetLabel())
+		if w == "" {
+			if strings.Contains(n.GetDescription(), "Change:") {
+				t.Fatalf("%s should be unchanged: %q", n.GetLabel(), n.GetDescription())
+			}
+		} else if !strings.Contains(n.GetDescription(), w) {
+			t.Fatalf("%s description = %q, want %q", n.GetLabel(), n.GetDescription(), w)
+		}
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing nodes %v", want)
+	}
+	checkViewGolden(t, "arch_view_changes.golden.json", view)
+}
+
+func TestArchDesignViewCollapsesLargeHypotheses(t *testing.T) {
+	out := readArchFixture(t)
+	cases := []struct {
+		name     string
+		maxNodes int
+		labels   []string
+	}{
+		{name: "cluster per kind", maxNodes: 4, labels: []string{"System", "library (3)", "service (1)", "store (1)"}},
+		{name: "smallest kinds fold into other", maxNodes: 3, labels: []string{"System", "library (3)", "other (2)"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := ArchDesignView(out, nil, tc.maxNodes).GetGraph()
+			var labels []string
+			for _, n := range g.GetNodes() {
+				labels = append(labels, n.GetLabel())
+			}
+			if strings.Join(labels, "|") != strings.Join(tc.labels, "|") || len(g.GetEdges()) != len(tc.labels)-1 {
+				t.Fatalf("labels = %v with %d edges, want %v", labels, len(g.GetEdges()), tc.labels)
+			}
+		})
+	}
+}
@@ -1,8 +1,8 @@
 package codebase
 
 import (
-	"container/heap"
 	"context"
+	"fmt"
 	"sort"
 
 	"insightify/internal/artifact"
@@ -11,10 +11,18 @@ import (
 type CodeGraph struct{}
 
 // Run builds a directed dependency graph from C2 output with normalized nodes.
-// It collapses bidirectional edges (keeping the heavier direction) and ensures
-// the resulting graph is acyclic so later stages do not repeat that work.
+// Bidirectional edges are reduced according to in.Pruning (keeping the heavier
+// direction by default, ties broken by strongerEdge); any cycles that remain are reported in Cycles so
+// later stages can collapse or reject them with actionable detail.
+// With a graphdelta.Emitter on ctx the graph is streamed as it is built.
 func (CodeGraph) Run(ctx context.Context, in artifact.CodeGraphIn) (artifact.CodeGraphOut, error) {
-	_ = ctx
+	if in.Package != "" {
+		pkg, ok := artifact.FindPackage(in.Packages, in.Package)
+		if !ok {
+			return artifact.CodeGraphOut{}, fmt.Errorf("codeGraph: unknown package %q", in.Package)
+		}
+		in.Dependencies = scopeDependencies(in.Dependencies, pkg.Path)
+	}
 
 	pathToRef := make(map[string]artifact.FileRef)
 	register := func(ref artifact.FileRef) {
@@ -50,6 +58,8 @@ func (CodeGraph) Run(ctx context.Context, in artifact.CodeGraphIn) (artifact.Cod
 			File: pathToRef[p],
 		}
 	}
+	stream := newGraphStream(ctx, nodes)
+	stream.addNodes(nodes)
 
 	edgeCounts := make(map[int]map[int]int)
 	addEdge := func(from, to int) {
@@ -72,157 +82,217 @@ func (CodeGraph) Run(ctx context.Context, in artifact.CodeGraphIn) (artifact.Cod
 		}
 	}
 
-	for from, tos := range edgeCounts {
-		for to, cnt := range tos {
-			if back, ok := edgeCounts[to][from]; ok {
-				if back > cnt || (back == cnt && nodes[to].File.Path < nodes[from].File.Path) {
-					delete(edgeCounts[from], to)
-				} else {
-					delete(edgeCounts[to], from)
-				}
-			}
-		}
+	unpruned := stream.edges(edgeCounts)
+	if err := pruneEdges(edgeCounts, nodes, in.Pruning); err != nil {
+		return artifact.CodeGraphOut{}, err
 	}
+	stream.pruned(unpruned, edgeCounts)
 
-	adjMaps := make([]map[int]struct{}, len(nodes))
+	adjacency := make([][]int, len(nodes))
 	for from, tos := range edgeCounts {
 		for to := range tos {
-			if adjMaps[from] == nil {
-				adjMaps[from] = make(map[int]struct{})
-			}
-			adjMaps[from][to] = struct{}{}
+			adjacency[from] = append(adjacency[from], to)
 		}
+		sort.Ints(adjacency[from])
 	}
-
-	breakCycles(adjMaps)
-
-	adjacency := make([][]int, len(nodes))
-	for i, m := range adjMaps {
-		for to := range m {
-			adjacency[i] = append(adjacency[i], to)
+	var edges []artifact.WeightedEdge
+	for from, tos := range adjacency {
+		for _, to := range tos {
+			edges = append(edges, artifact.WeightedEdge{From: from, To: to, Weight: edgeCounts[from][to]})
 		}
-		sort.Ints(adjacency[i])
 	}
 
-	return artifact.CodeGraphOut{
+	out := artifact.CodeGraphOut{
 		Repo: in.Repo,
 		Graph: artifact.DependencyGraph{
 			Nodes:     nodes,
 			Adjacency: adjacency,
+			Edges:     edges,
 		},
-	}, nil
+		Cycles: cycleReports(adjacency, edgeCounts),
+	}
+	stream.finish(out)
+	return out, nil
 }
 
-// breakCycles removes edges to ensure the adjacency map encodes a DAG.
-func breakCycles(adj []map[int]struct{}) {
-	n := len(adj)
-	if n == 0 {
-		return
+// pruneEdges applies the configured policy to edge weights in place.
+func pruneEdges(edgeCounts map[int]map[int]int, nodes []artifact.DependencyNode, policy artifact.GraphPruning) error {
+	switch policy.Strategy {
+	case "", artifact.PruneKeepStronger:
+		inEdges := inEdgeCounts(edgeCounts, len(nodes))
+		for _, c := range bidirectionalCandidates(edgeCounts) {
+			back := dependencyCandidate{From: c.To, To: c.From, Weight: edgeCounts[c.To][c.From]}
+			if strongerEdge(back, c, inEdges, nodes) {
+				delete(edgeCounts[c.From], c.To)
+			} else {
+				delete(edgeCounts[c.To], c.From)
+			}
+		}
+	case artifact.PruneKeepBoth:
+	case artifact.PruneDropBoth:
+		for from, tos := range edgeCounts {
+			for to := range tos {
+				if _, ok := edgeCounts[to][from]; ok {
+					delete(edgeCounts[from], to)
+					delete(edgeCounts[to], from)
+				}
+			}
+		}
+	case artifact.PruneMinWeight:
+		if policy.MinWeight <= 0 {
+			return fmt.Errorf("code_graph: min_weight strategy requires a positive min_weight")
+		}
+		for _, tos := range edgeCounts {
+			for to, cnt := range tos {
+				if cnt < policy.MinWeight {
+					delete(tos, to)
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("code_graph: unknown pruning strategy %q", policy.Strategy)
 	}
+	return nil
+}
 
-	indeg := make([]int, n)
-	reverse := make([]map[int]struct{}, n)
-	for from := range adj {
-		for to := range adj[from] {
-			indeg[to]++
-			if reverse[to] == nil {
-				reverse[to] = make(map[int]struct{})
+// dependencyCandidate is one direction of a dependency before pruning; Weight
+// is its confidence, the number of import hits behind it.
+type dependencyCandidate struct {
+	From, To, Weight int
+}
+
+// bidirectionalCandidates returns one candidate per pair of nodes linked in
+// both directions, the one with the smaller From, sorted by (From, To).
+func bidirectionalCandidates(edgeCounts map[int]map[int]int) []dependencyCandidate {
+	var out []dependencyCandidate
+	for from, tos := range edgeCounts {
+		for to, cnt := range tos {
+			if _, ok := edgeCounts[to][from]; ok && from < to {
+				out = append(out, dependencyCandidate{From: from, To: to, Weight: cnt})
 			}
-			reverse[to][from] = struct{}{}
 		}
 	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].From != out[j].From {
+			return out[i].From < out[j].From
+		}
+		return out[i].To < out[j].To
+	})
+	return out
+}
 
-	remain := make(map[int]struct{}, n)
-	for i := 0; i < n; i++ {
-		remain[i] = struct{}{}
+// strongerEdge reports whether a wins over b, the opposite direction of the
+// same pair, under keep_stronger:
+//  1. the heavier edge wins;
+//  2. on equal weight, the edge toward the node with more in-edges (distinct
+//     sources, counted before pruning) wins;
+//  3. then the edge whose source path sorts first wins.
+func strongerEdge(a, b dependencyCandidate, inEdges []int, nodes []artifact.DependencyNode) bool {
+	if a.Weight != b.Weight {
+		return a.Weight > b.Weight
+	}
+	if inEdges[a.To] != inEdges[b.To] {
+		return inEdges[a.To] > inEdges[b.To]
 	}
+	return nodes[a.From].File.Path < nodes[b.From].File.Path
+}
 
-	h := &intHeap{}
-	heap.Init(h)
-	for i := 0; i < n; i++ {
-		if indeg[i] == 0 {
-			heap.Push(h, i)
+// inEdgeCounts returns the number of distinct sources of each node.
+func inEdgeCounts(edgeCounts map[int]map[int]int, n int) []int {
+	in := make([]int, n)
+	for _, tos := range edgeCounts {
+		for to := range tos {
+			in[to]++
 		}
 	}
+	return in
+}
 
-	for len(remain) > 0 {
-		var curr int
-		if h.Len() > 0 {
-			curr = heap.Pop(h).(int)
-		} else {
-			curr = smallestIndex(remain)
-			removeIncomingEdges(curr, adj, reverse, indeg)
-			heap.Push(h, curr)
+// cycleReports lists every strongly connected component with more than one
+// member, together with its internal edges and the lightest edge to cut.
+func cycleReports(adjacency [][]int, edgeCounts map[int]map[int]int) []artifact.CycleReport {
+	var reports []artifact.CycleReport
+	for _, comp := range stronglyConnected(adjacency) {
+		if len(comp) < 2 {
 			continue
 		}
-		if _, ok := remain[curr]; !ok {
-			continue
+		inComp := make(map[int]struct{}, len(comp))
+		for _, id := range comp {
+			inComp[id] = struct{}{}
 		}
-		delete(remain, curr)
-		for to := range adj[curr] {
-			if reverse[to] != nil {
-				delete(reverse[to], curr)
-			}
-			if indeg[to] > 0 {
-				indeg[to]--
-				if indeg[to] == 0 {
-					heap.Push(h, to)
+		report := artifact.CycleReport{Members: comp}
+		for _, from := range comp {
+			for _, to := range adjacency[from] {
+				if _, ok := inComp[to]; !ok {
+					continue
+				}
+				edge := artifact.WeightedEdge{From: from, To: to, Weight: edgeCounts[from][to]}
+				report.Edges = append(report.Edges, edge)
+				if len(report.Edges) == 1 || edge.Weight < report.SuggestedCut.Weight {
+					report.SuggestedCut = edge
 				}
 			}
 		}
+		reports = append(reports, report)
 	}
+	return reports
 }
 
-func removeIncomingEdges(node int, adj []map[int]struct{}, reverse []map[int]struct{}, indeg []int) {
-	if node < 0 || node >= len(adj) {
-		return
-	}
-	preds := make([]int, 0, len(reverse[node]))
-	for p := range reverse[node] {
-		preds = append(preds, p)
+// stronglyConnected returns the SCCs of adj (Tarjan). Members are sorted and
+// components are ordered by their smallest member.
+func stronglyConnected(adj [][]int) [][]int {
+	n := len(adj)
+	index := make([]int, n)
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	for i := range index {
+		index[i] = -1
 	}
-	sort.Ints(preds)
-	for _, pred := range preds {
-		if adj[pred] != nil {
-			delete(adj[pred], node)
+	var (
+		stack []int
+		comps [][]int
+		next  int
+	)
+
+	var visit func(v int)
+	visit = func(v int) {
+		index[v], low[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range adj[v] {
+			if w < 0 || w >= n {
+				continue
+			}
+			if index[w] < 0 {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
 		}
-		delete(reverse[node], pred)
-		if indeg[node] > 0 {
-			indeg[node]--
+		if low[v] != index[v] {
+			return
+		}
+		var comp []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			comp = append(comp, w)
+			if w == v {
+				break
+			}
 		}
+		sort.Ints(comp)
+		comps = append(comps, comp)
 	}
-	indeg[node] = 0
-}
 
-func smallestIndex(m map[int]struct{}) int {
-	var smallest int
-	first := true
-	for k := range m {
-		if first || k < smallest {
-			smallest = k
-			first = false
+	for v := 0; v < n; v++ {
+		if index[v] < 0 {
+			visit(v)
 		}
 	}
-	return smallest
+	sort.Slice(comps, func(i, j int) bool { return comps[i][0] < comps[j][0] })
+	return comps
 }
-
-type intHeap []int
-
-func (h intHeap) Len() int           { return len(h) }
-func (h intHeap) Less(i, j int) bool { return h[i] < h[j] }
-func (h intHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
-
-func (h *intHeap) Push(x any) {
-	*h = append(*h, x.(int))
-}
-
-func (h *intHeap) Pop() any {
-	old := *h
-	n := len(old)
-	if n == 0 {
-		return 0
-	}
-	x := old[n-1]
-	*h = old[:n-1]
-	return x
-}
\ No newline at end of file
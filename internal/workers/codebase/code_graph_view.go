@@ -0,0 +1,213 @@
+package codebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/artifact"
+	"insightify/internal/common/graphdelta"
+	"insightify/internal/common/utils"
+)
+
+// ToGraphView maps a code_graph output onto the client graph: one node per
+// file labelled with its repo-relative path and one edge per dependency,
+// in the graph's direction; GraphEdge has no weight. The description lists
+// the extension, the edge counts and the other members of the file's cycle,
+// if any.
+//
+// Nodes are keyed by path, not by the scan-order node IDs, and sorted by
+// path before utils.AssignGraphNodeUIDs runs, so a file keeps its UID across
+// runs as long as its path does. Edges to unknown node IDs are dropped.
+func ToGraphView(out artifact.CodeGraphOut) *workerv1.GraphView {
+	paths := make(map[int]string, len(out.Graph.Nodes))
+	for _, n := range out.Graph.Nodes {
+		paths[n.ID] = n.File.Path
+	}
+	fanIn, fanOut := map[int]int{}, map[int]int{}
+	var edges []artifact.WeightedEdge
+	seen := map[[2]int]bool{}
+	for _, e := range graphEdges(out.Graph) {
+		_, fromOK := paths[e.From]
+		_, toOK := paths[e.To]
+		if !fromOK || !toOK || seen[[2]int{e.From, e.To}] {
+			continue
+		}
+		seen[[2]int{e.From, e.To}] = true
+		fanOut[e.From]++
+		fanIn[e.To]++
+		edges = append(edges, e)
+	}
+	cycleOf := map[int][]string{}
+	for _, c := range out.Cycles {
+		for _, m := range c.Members {
+			for _, other := range c.Members {
+				if other != m && paths[other] != "" {
+					cycleOf[m] = append(cycleOf[m], paths[other])
+				}
+			}
+		}
+	}
+
+	nodes := append([]artifact.DependencyNode(nil), out.Graph.Nodes...)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].File.Path < nodes[j].File.Path })
+	view := &workerv1.ClientView{
+		Content: &workerv1.ClientView_Graph{Graph: &workerv1.GraphView{}},
+	}
+	graph := view.GetGraph()
+	for _, n := range nodes {
+		lines := []string{
+			fmt.Sprintf("Imports: %d, imported by: %d", fanOut[n.ID], fanIn[n.ID]),
+		}
+		if n.File.Ext != "" {
+			lines = append([]string{"Extension: " + n.File.Ext}, lines...)
+		}
+		if members := cycleOf[n.ID]; len(members) > 0 {
+			sort.Strings(members)
+			lines = append(lines, "Cycle with: "+strings.Join(members, ", "))
+		}
+		graph.Nodes = append(graph.Nodes, &workerv1.GraphNode{
+			Uid:         "file:" + n.File.Path,
+			Label:       n.File.Path,
+			Description: strings.Join(lines, "\n"),
+		})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if paths[edges[i].From] != paths[edges[j].From] {
+			return paths[edges[i].From] < paths[edges[j].From]
+		}
+		return paths[edges[i].To] < paths[edges[j].To]
+	})
+	for _, e := range edges {
+		graph.Edges = append(graph.Edges, &workerv1.GraphEdge{
+			From: "file:" + paths[e.From],
+			To:   "file:" + paths[e.To],
+		})
+	}
+	utils.AssignGraphNodeUIDs(view)
+	return graph
+}
+
+// CodeGraphView returns ToGraphView as the code_graph client view.
+func CodeGraphView(out artifact.CodeGraphOut) *workerv1.ClientView {
+	return &workerv1.ClientView{
+		Phase:   "code_graph",
+		Content: &workerv1.ClientView_Graph{Graph: ToGraphView(out)},
+	}
+}
+
+// graphDeltaBatch bounds the nodes or edges of one streamed delta.
+const graphDeltaBatch = 200
+
+// graphStream streams the code_graph view while CodeGraph runs: the files,
+// the dependencies before pruning, the pruned ones and finally the nodes
+// with their ToGraphView descriptions. UIDs are generated the way
+// ToGraphView assigns them, so the streamed graph ends up equal to the final
+// view. A nil stream, for contexts without a graphdelta.Emitter, emits
+// nothing.
+type graphStream struct {
+	ctx   context.Context
+	gen   *utils.UIDGenerator
+	paths map[int]string
+}
+
+func newGraphStream(ctx context.Context, nodes []artifact.DependencyNode) *graphStream {
+	if !graphdelta.Enabled(ctx) {
+		return nil
+	}
+	s := &graphStream{ctx: ctx, gen: utils.NewUIDGenerator(), paths: make(map[int]string, len(nodes))}
+	for _, n := range nodes {
+		s.paths[n.ID] = n.File.Path
+	}
+	return s
+}
+
+// uid must be called for nodes in path order first, as ToGraphView does.
+func (s *graphStream) uid(id int) string {
+	key := "file:" + s.paths[id]
+	return s.gen.GenerateForKey("id:"+key, key)
+}
+
+func (s *graphStream) addNodes(nodes []artifact.DependencyNode) {
+	if s == nil {
+		return
+	}
+	sorted := append([]artifact.DependencyNode(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].File.Path < sorted[j].File.Path })
+	views := make([]*workerv1.GraphNode, 0, len(sorted))
+	for _, n := range sorted {
+		views = append(views, &workerv1.GraphNode{Uid: s.uid(n.ID), Label: n.File.Path})
+	}
+	for len(views) > 0 {
+		n := min(graphDeltaBatch, len(views))
+		graphdelta.Emit(s.ctx, graphdelta.Delta{Phase: "code_graph", AddedNodes: views[:n]})
+		views = views[n:]
+	}
+}
+
+// edges emits the dependencies of edgeCounts and returns them, to be
+// passed to pruned once edgeCounts is pruned.
+func (s *graphStream) edges(edgeCounts map[int]map[int]int) [][2]int {
+	if s == nil {
+		return nil
+	}
+	var out [][2]int
+	for from, tos := range edgeCounts {
+		for to := range tos {
+			out = append(out, [2]int{from, to})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i][0] != out[j][0] {
+			return out[i][0] < out[j][0]
+		}
+		return out[i][1] < out[j][1]
+	})
+	s.emitEdges(out, false)
+	return out
+}
+
+// pruned emits the removal of the edges of before that pruning dropped.
+func (s *graphStream) pruned(before [][2]int, edgeCounts map[int]map[int]int) {
+	if s == nil {
+		return
+	}
+	var gone [][2]int
+	for _, e := range before {
+		if _, ok := edgeCounts[e[0]][e[1]]; !ok {
+			gone = append(gone, e)
+		}
+	}
+	s.emitEdges(gone, true)
+}
+
+func (s *graphStream) emitEdges(edges [][2]int, remove bool) {
+	for len(edges) > 0 {
+		n := min(graphDeltaBatch, len(edges))
+		views := make([]*workerv1.GraphEdge, 0, n)
+		for _, e := range edges[:n] {
+			views = append(views, &workerv1.GraphEdge{From: s.uid(e[0]), To: s.uid(e[1])})
+		}
+		d := graphdelta.Delta{Phase: "code_graph", AddedEdges: views}
+		if remove {
+			d = graphdelta.Delta{Phase: "code_graph", RemovedEdges: views}
+		}
+		graphdelta.Emit(s.ctx, d)
+		edges = edges[n:]
+	}
+}
+
+// finish re-sends the nodes with the descriptions of the final view.
+func (s *graphStream) finish(out artifact.CodeGraphOut) {
+	if s == nil {
+		return
+	}
+	nodes := ToGraphView(out).GetNodes()
+	for len(nodes) > 0 {
+		n := min(graphDeltaBatch, len(nodes))
+		graphdelta.Emit(s.ctx, graphdelta.Delta{Phase: "code_graph", AddedNodes: nodes[:n]})
+		nodes = nodes[n:]
+	}
+}
@@ -0,0 +1,362 @@
+package codebase
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+)
+
+// quotedModule captures module strings written as "x", 'x', `x` or <x>.
+var quotedModule = regexp.MustCompile("\"([^\"]+)\"|'([^']+)'|`([^`]+)`|<([^<>\\s]+)>")
+
+// bareModule captures unquoted module names such as Python's "import os.path".
+var bareModule = regexp.MustCompile(`^\s*([A-Za-z_][\w.]*)`)
+
+type CodeImportEdges struct{}
+
+// Run reads each import range, applies the matching spec's keyword matcher to
+// capture module strings, normalizes them and resolves local targets.
+// Matches inside comments or string literals are ignored.
+func (CodeImportEdges) Run(ctx context.Context, in artifact.CodeImportEdgesIn) (artifact.CodeImportEdgesOut, error) {
+	fs := in.RepoFS
+	if fs == nil {
+		fs = safeio.Default()
+	}
+	if fs == nil {
+		return artifact.CodeImportEdgesOut{}, fmt.Errorf("codeImportEdges: safe filesystem not configured")
+	}
+
+	byExt := make(map[string]*importMatcher)
+	for _, fam := range in.Families {
+		m := newImportMatcher(fam.Spec)
+		if m == nil {
+			continue
+		}
+		for _, ext := range fam.Spec.Exts {
+			ext = strings.ToLower(ext)
+			if _, ok := byExt[ext]; !ok {
+				byExt[ext] = m
+			}
+		}
+	}
+
+	type edgeKey struct{ from, to string }
+	fileLines := make(map[string][]string)
+	index := make(map[edgeKey]int)
+	var edges []artifact.ImportEdge
+
+	for _, r := range in.Ranges {
+		if err := ctx.Err(); err != nil {
+			return artifact.CodeImportEdgesOut{}, err
+		}
+		m := byExt[strings.ToLower(path.Ext(r.FilePath))]
+		if m == nil {
+			continue
+		}
+		lines, ok := fileLines[r.FilePath]
+		if !ok {
+			data, err := fs.SafeReadFile(r.FilePath)
+			if err != nil {
+				fileLines[r.FilePath] = nil
+				continue
+			}
+			lines = strings.Split(string(data), "\n")
+			fileLines[r.FilePath] = lines
+		}
+
+		for _, hit := range m.extract(lines, r.StartLine, r.EndLine) {
+			info := NormalizeModule(hit.raw, m.hints)
+			target := resolveImportTarget(fs, r.FilePath, info, m.exts)
+			key := edgeKey{from: r.FilePath, to: target}
+			if target == "" {
+				key.to = "module:" + info.Normalized
+			}
+			i, seen := index[key]
+			if !seen {
+				i = len(edges)
+				index[key] = i
+				edges = append(edges, artifact.ImportEdge{From: r.FilePath, Target: target, Module: info})
+			}
+			if !containsInt(edges[i].Lines, hit.line) {
+				edges[i].Lines = append(edges[i].Lines, hit.line)
+			}
+		}
+	}
+
+	for i := range edges {
+		sort.Ints(edges[i].Lines)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].Target != edges[j].Target {
+			return edges[i].Target < edges[j].Target
+		}
+		return edges[i].Module.Normalized < edges[j].Module.Normalized
+	})
+	return artifact.CodeImportEdgesOut{Repo: in.Repo, Edges: edges}, nil
+}
+
+type importHit struct {
+	raw  string
+	line int
+}
+
+// importMatcher is the compiled form of an ExtractorSpec.
+type importMatcher struct {
+	keyword       *regexp.Regexp
+	lineComments  []string
+	blockComments [][2]string
+	hints         artifact.NormalizeHints
+	exts          []string
+}
+
+func newImportMatcher(spec artifact.ExtractorSpec) *importMatcher {
+	var alts []string
+	for _, kw := range spec.Rules.Keywords {
+		kw = strings.TrimSpace(kw)
+		if kw == "" {
+			continue
+		}
+		alt := regexp.QuoteMeta(kw)
+		if isWordByte(kw[0]) {
+			alt = `\b` + alt
+		}
+		if isWordByte(kw[len(kw)-1]) {
+			alt += `\b`
+		}
+		alts = append(alts, alt)
+	}
+	if len(alts) == 0 {
+		return nil
+	}
+	m := &importMatcher{
+		keyword: regexp.MustCompile(strings.Join(alts, "|")),
+		hints:   spec.NormalizeHints,
+		exts:    spec.Exts,
+	}
+	for _, c := range spec.CommentLinePattern {
+		if c = strings.TrimSpace(c); c != "" {
+			m.lineComments = append(m.lineComments, c)
+		}
+	}
+	for i := 0; i+1 < len(spec.CommentBlockPattern); i += 2 {
+		open, close := strings.TrimSpace(spec.CommentBlockPattern[i]), strings.TrimSpace(spec.CommentBlockPattern[i+1])
+		if open != "" && close != "" {
+			m.blockComments = append(m.blockComments, [2]string{open, close})
+		}
+	}
+	return m
+}
+
+// extract scans the 1-based inclusive line range and returns captured module
+// strings. Parenthesized import blocks (Go, Python) spanning lines are followed.
+func (m *importMatcher) extract(lines []string, start, end int) []importHit {
+	if start < 1 {
+		start = 1
+	}
+	if end <= 0 || end > len(lines) {
+		end = len(lines)
+	}
+	var (
+		hits     []importHit
+		blockEnd = -1 // index into blockComments while inside a block comment
+		inParens bool
+		quotedIn = func(code string, line int) {
+			for _, sm := range quotedModule.FindAllStringSubmatch(code, -1) {
+				for gi, g := range sm[1:] {
+					if g == "" {
+						continue
+					}
+					if gi == 3 {
+						// Keep angle brackets so NormalizeModule sees a system header.
+						g = "<" + g + ">"
+					}
+					hits = append(hits, importHit{raw: g, line: line})
+					break
+				}
+			}
+		}
+	)
+	for ln := start; ln <= end; ln++ {
+		code := m.stripComments(lines[ln-1], &blockEnd)
+		masked := maskStrings(code)
+
+		if inParens {
+			if i := strings.Index(masked, ")"); i >= 0 {
+				quotedIn(code[:i], ln)
+				inParens = false
+			} else {
+				quotedIn(code, ln)
+			}
+			continue
+		}
+
+		loc := m.keyword.FindStringIndex(masked)
+		if loc == nil {
+			continue
+		}
+		rest, restMasked := code[loc[1]:], masked[loc[1]:]
+		if strings.HasPrefix(strings.TrimSpace(restMasked), "(") && !strings.Contains(restMasked, ")") {
+			inParens = true
+			quotedIn(rest, ln)
+			continue
+		}
+		before := len(hits)
+		quotedIn(rest, ln)
+		if len(hits) == before {
+			if sm := bareModule.FindStringSubmatch(rest); sm != nil {
+				hits = append(hits, importHit{raw: sm[1], line: ln})
+			}
+		}
+	}
+	return hits
+}
+
+// stripComments removes line and block comments outside string literals.
+// blockEnd carries block-comment state across lines (-1 when outside).
+func (m *importMatcher) stripComments(line string, blockEnd *int) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(line); {
+		if *blockEnd >= 0 {
+			close := m.blockComments[*blockEnd][1]
+			j := strings.Index(line[i:], close)
+			if j < 0 {
+				return b.String()
+			}
+			i += j + len(close)
+			*blockEnd = -1
+			continue
+		}
+		c := line[i]
+		if quote != 0 {
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(line) {
+				b.WriteByte(line[i+1])
+				i += 2
+				continue
+			}
+			if c == quote {
+				quote = 0
+			}
+			i++
+			continue
+		}
+		if c == '"' || c == '\'' || c == '`' {
+			quote = c
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		for _, lc := range m.lineComments {
+			if strings.HasPrefix(line[i:], lc) {
+				return b.String()
+			}
+		}
+		opened := false
+		for bi, bc := range m.blockComments {
+			if strings.HasPrefix(line[i:], bc[0]) {
+				*blockEnd = bi
+				i += len(bc[0])
+				opened = true
+				break
+			}
+		}
+		if opened {
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
+
+// maskStrings blanks the contents of string literals (keeping the quotes and
+// byte offsets) so keywords inside strings are not matched.
+func maskStrings(code string) string {
+	out := []byte(code)
+	var quote byte
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if quote == 0 {
+			if c == '"' || c == '\'' || c == '`' {
+				quote = c
+			}
+			continue
+		}
+		if c == '\\' && i+1 < len(out) {
+			out[i], out[i+1] = ' ', ' '
+			i++
+			continue
+		}
+		if c == quote {
+			quote = 0
+			continue
+		}
+		out[i] = ' '
+	}
+	return string(out)
+}
+
+// resolveImportTarget maps a path-like module to a repo-relative file,
+// trying the spec's interchangeable extensions and index files.
+func resolveImportTarget(fs *safeio.SafeFS, from string, info artifact.ModuleInfo, exts []string) string {
+	if info.Kind != artifact.ModuleKindPath {
+		return ""
+	}
+	s := info.Normalized
+	var bases []string
+	switch {
+	case strings.HasPrefix(s, "/"):
+		bases = []string{strings.TrimPrefix(s, "/")}
+	case strings.HasPrefix(s, "."):
+		bases = []string{path.Join(path.Dir(from), s)}
+	case info.Alias != "" && s == info.Raw:
+		// Implicit alias without a hint mapping; the root is unknown.
+		return ""
+	default:
+		bases = []string{path.Join(path.Dir(from), s), path.Clean(s)}
+	}
+	for _, base := range bases {
+		if base == "" || base == "." || strings.HasPrefix(base, "../") || base == ".." {
+			continue
+		}
+		candidates := []string{base}
+		for _, ext := range exts {
+			candidates = append(candidates, base+ext)
+		}
+		for _, ext := range exts {
+			candidates = append(candidates, base+"/index"+ext)
+		}
+		for _, c := range candidates {
+			if c == from {
+				continue
+			}
+			if st, err := fs.SafeStat(c); err == nil && !st.IsDir() {
+				return c
+			}
+		}
+	}
+	return ""
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func containsInt(xs []int, v int) bool {
+	for _, x := range xs {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -3,13 +3,12 @@ package codebase
 import (
 	"context"
 	"fmt"
-	"log"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"insightify/internal/artifact"
-	"insightify/internal/common/safeio"
+	"insightify/internal/common/logctx"
 	"insightify/internal/common/scan"
 
 	"insightify/internal/common/wordidx"
@@ -33,26 +32,47 @@ type CodeImports struct{}
 
 // Run executes the CodeImports pipeline for dependency extraction.
 func (CodeImports) Run(ctx context.Context, in artifact.CodeImportsIn) (artifact.CodeImportsOut, error) {
-	log.Printf("CodeImports: starting scan in repo %s", in.Repo)
+	logctx.Info(ctx, "code imports scan started", "repo", in.Repo)
+
+	roots := in.Roots.MainSourceRoots
+	if in.Package != "" {
+		pkg, ok := artifact.FindPackage(in.Roots.Packages, in.Package)
+		if !ok {
+			return artifact.CodeImportsOut{}, fmt.Errorf("codeImports: unknown package %q", in.Package)
+		}
+		roots = packageRoots(roots, pkg.Path)
+		logctx.Info(ctx, "code imports scoped to package", "package", pkg.Name, "roots", roots)
+	}
+
+	// Missing roots are skipped and recorded instead of failing the scan;
+	// only a configuration with no usable root left is an error.
+	fs := currentSafeFS()
+	if fs == nil {
+		return artifact.CodeImportsOut{}, fmt.Errorf("codeImports: safe filesystem not configured")
+	}
+	usable, warnings := usableRoots(fs, "main_source_roots", roots)
+	if len(warnings) > 0 {
+		if len(usable) == 0 {
+			return artifact.CodeImportsOut{}, &MissingRootsError{Phase: "code_imports", Warnings: warnings}
+		}
+		logctx.Warn(ctx, "code imports skipped missing roots", "warnings", warnings, "roots", usable)
+	}
 
 	var out []artifact.Dependencies
 	for _, fam := range in.Families {
-		dep, err := ScanDependencies(ctx, in.Repo, in.Roots.MainSourceRoots, fam)
+		dep, err := ScanDependencies(ctx, in.Repo, usable, fam)
 		if err != nil {
 			return artifact.CodeImportsOut{}, err
 		}
-		log.Printf("CodeImports: found %d dependencies for family %s (%s)", len(dep.Files), fam.Family, fam.Key)
+		logctx.Info(ctx, "code imports family scanned", "family", fam.Family, "family_key", fam.Key, "files", len(dep.Files))
 		out = append(out, dep)
 	}
-	return artifact.CodeImportsOut{PossibleDependencies: out}, nil
+	return artifact.CodeImportsOut{PossibleDependencies: out, Warnings: warnings}, nil
 }
 
 // Dependencies scans once for a given (repo, roots, exts) and returns a single Dependencies.
 func ScanDependencies(ctx context.Context, repo string, roots []string, family artifact.FamilySpec) (artifact.Dependencies, error) {
-	fs := scan.CurrentSafeFS()
-	if fs == nil {
-		fs = safeio.Default()
-	}
+	fs := currentSafeFS()
 	if fs == nil {
 		return artifact.Dependencies{}, fmt.Errorf("codeImports: safe filesystem not configured")
 	}
@@ -124,7 +144,7 @@ func ScanDependencies(ctx context.Context, repo string, roots []string, family a
 	// Sort for deterministic output
 	sort.Slice(srcDeps, func(i, j int) bool { return srcDeps[i].File.Path < srcDeps[j].File.Path })
 
-	log.Printf("CodeImports: scanned %d files in repo %s", len(srcDeps), repo)
+	logctx.Info(ctx, "code imports files scanned", "repo", repo, "files", len(srcDeps))
 	return artifact.Dependencies{
 		Repo:    repo,
 		Roots:   roots,
@@ -0,0 +1,228 @@
+package codebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/artifact"
+)
+
+// DefaultMermaidMaxNodes caps the files of the diagram when CodeMermaidIn
+// sets none; Mermaid layouts become unreadable well before a few hundred.
+const DefaultMermaidMaxNodes = 150
+
+// rootLayer names the layer of files at the repository root.
+const rootLayer = "(root)"
+
+// mermaidPalette styles node classes in order of their sorted kind.
+var mermaidPalette = []string{
+	"fill:#e3f2fd,stroke:#1e88e5",
+	"fill:#e8f5e9,stroke:#43a047",
+	"fill:#fff3e0,stroke:#fb8c00",
+	"fill:#f3e5f5,stroke:#8e24aa",
+	"fill:#fce4ec,stroke:#d81b60",
+	"fill:#e0f7fa,stroke:#00acc1",
+	"fill:#f9fbe7,stroke:#c0ca33",
+	"fill:#efebe9,stroke:#6d4c41",
+}
+
+// mermaidEscaper replaces the characters that end or confuse a quoted
+// Mermaid label with entity codes.
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	`"`, "#quot;",
+	"&", "#amp;",
+	"<", "#lt;",
+	">", "#gt;",
+	"\n", " ",
+)
+
+// CodeMermaid renders the code_graph dependency graph as a Mermaid
+// flowchart without the LLM. Files are grouped into one subgraph per layer
+// (their workspace package, else their top-level directory) and styled by
+// extension. Edges inside a reported cycle, and self-loops, are drawn dashed
+// and labelled "cycle"; Mermaid lays out cycles itself. Past MaxNodes the
+// least connected files are dropped with their edges.
+type CodeMermaid struct{}
+
+type mermaidNode struct {
+	id    int
+	path  string
+	label string
+	layer string
+	kind  string
+}
+
+func (CodeMermaid) Run(_ context.Context, in artifact.CodeMermaidIn) (artifact.CodeMermaidOut, error) {
+	maxNodes := in.MaxNodes
+	if maxNodes <= 0 {
+		maxNodes = DefaultMermaidMaxNodes
+	}
+	edges := graphEdges(in.Graph.Graph)
+	keep, omitted := mostConnected(in.Graph.Graph.Nodes, edges, maxNodes)
+
+	cycleOf := map[int]int{}
+	for i, c := range in.Graph.Cycles {
+		for _, m := range c.Members {
+			cycleOf[m] = i + 1
+		}
+	}
+
+	byLayer := map[string][]mermaidNode{}
+	kinds := map[string]bool{}
+	for _, n := range in.Graph.Graph.Nodes {
+		if !keep[n.ID] {
+			continue
+		}
+		layer, rel := nodeLayer(n.File.Path, in.Packages)
+		mn := mermaidNode{id: n.ID, path: n.File.Path, label: rel, layer: layer, kind: mermaidClass(n.File.Ext)}
+		byLayer[layer] = append(byLayer[layer], mn)
+		kinds[mn.kind] = true
+	}
+	layers := make([]string, 0, len(byLayer))
+	for l := range byLayer {
+		layers = append(layers, l)
+	}
+	sort.Strings(layers)
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	nodes := 0
+	for i, layer := range layers {
+		members := byLayer[layer]
+		sort.Slice(members, func(a, c int) bool { return members[a].path < members[c].path })
+		fmt.Fprintf(&b, "  subgraph layer%d[\"%s\"]\n", i, mermaidLabel(layer))
+		for _, n := range members {
+			fmt.Fprintf(&b, "    n%d[\"%s\"]:::%s\n", n.id, mermaidLabel(n.label), n.kind)
+			nodes++
+		}
+		b.WriteString("  end\n")
+	}
+	edgeCount := 0
+	for _, e := range edges {
+		if !keep[e.From] || !keep[e.To] {
+			continue
+		}
+		if e.From == e.To || (cycleOf[e.From] != 0 && cycleOf[e.From] == cycleOf[e.To]) {
+			fmt.Fprintf(&b, "  n%d -.->|cycle| n%d\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&b, "  n%d --> n%d\n", e.From, e.To)
+		}
+		edgeCount++
+	}
+	classes := make([]string, 0, len(kinds))
+	for k := range kinds {
+		classes = append(classes, k)
+	}
+	sort.Strings(classes)
+	for i, k := range classes {
+		fmt.Fprintf(&b, "  classDef %s %s\n", k, mermaidPalette[i%len(mermaidPalette)])
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "  %%%% %d less connected files omitted\n", omitted)
+	}
+
+	return artifact.CodeMermaidOut{
+		Diagram: b.String(),
+		Nodes:   nodes,
+		Edges:   edgeCount,
+		Layers:  len(layers),
+		Omitted: omitted,
+	}, nil
+}
+
+// graphEdges returns the weighted edges of g, falling back to Adjacency for
+// artifacts written before weights were recorded.
+func graphEdges(g artifact.DependencyGraph) []artifact.WeightedEdge {
+	if len(g.Edges) > 0 {
+		return g.Edges
+	}
+	var out []artifact.WeightedEdge
+	for from, tos := range g.Adjacency {
+		for _, to := range tos {
+			out = append(out, artifact.WeightedEdge{From: from, To: to})
+		}
+	}
+	return out
+}
+
+// mostConnected keeps the limit nodes with the most edges, ties broken by
+// path, and reports how many it dropped.
+func mostConnected(nodes []artifact.DependencyNode, edges []artifact.WeightedEdge, limit int) (map[int]bool, int) {
+	keep := make(map[int]bool, len(nodes))
+	if len(nodes) <= limit {
+		for _, n := range nodes {
+			keep[n.ID] = true
+		}
+		return keep, 0
+	}
+	degree := map[int]int{}
+	for _, e := range edges {
+		degree[e.From]++
+		degree[e.To]++
+	}
+	ranked := append([]artifact.DependencyNode(nil), nodes...)
+	sort.Slice(ranked, func(i, j int) bool {
+		if degree[ranked[i].ID] != degree[ranked[j].ID] {
+			return degree[ranked[i].ID] > degree[ranked[j].ID]
+		}
+		return ranked[i].File.Path < ranked[j].File.Path
+	})
+	for _, n := range ranked[:limit] {
+		keep[n.ID] = true
+	}
+	return keep, len(nodes) - limit
+}
+
+// nodeLayer returns the layer of path and path relative to it: the deepest
+// workspace package holding it, else its top-level directory.
+func nodeLayer(path string, pkgs []artifact.WorkspacePackage) (string, string) {
+	var best artifact.WorkspacePackage
+	for _, p := range pkgs {
+		if inPackage(path, p.Path) && len(p.Path) > len(best.Path) {
+			best = p
+		}
+	}
+	if best.Path != "" {
+		name := best.Name
+		if name == "" {
+			name = best.Path
+		}
+		return name, strings.TrimPrefix(strings.TrimPrefix(path, best.Path), "/")
+	}
+	top, rest, ok := strings.Cut(path, "/")
+	if !ok {
+		return rootLayer, path
+	}
+	return top, rest
+}
+
+// mermaidClass names the node class of a file extension.
+func mermaidClass(ext string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(ext) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "ext_none"
+	}
+	return "ext_" + b.String()
+}
+
+func mermaidLabel(s string) string {
+	return mermaidEscaper.Replace(s)
+}
+
+// MermaidView returns the diagram as the phase's client view, fenced as a
+// mermaid code block.
+func MermaidView(out artifact.CodeMermaidOut) *workerv1.ClientView {
+	return &workerv1.ClientView{
+		Phase:   "code_mermaid",
+		Content: &workerv1.ClientView_LlmResponse{LlmResponse: "```mermaid\n" + out.Diagram + "```\n"},
+	}
+}
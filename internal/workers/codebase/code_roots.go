@@ -9,6 +9,7 @@ import (
 	"insightify/internal/llm/tool"
 	"insightify/internal/common/scan"
 	"insightify/internal/common/utils"
+	"insightify/internal/schema"
 	"path/filepath"
 )
 
@@ -28,6 +29,8 @@ var codeRootsPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 	Rules: []string{
 		"If unsure, keep lists small and explain uncertainty in notes.",
 		"You may use the 'scan.list' tool to inspect specific subdirectories if the initial scan is insufficient.",
+		llmtool.RegenHintRule,
+		"If 'detected_packages' is present, the repo is a monorepo: list each package path that holds application code in main_source_roots (or a source dir inside it) instead of their common parent such as packages/.",
 	},
 	Assumptions:  []string{"Missing categories can be empty arrays."},
 	OutputFormat: "JSON only.",
@@ -41,7 +44,7 @@ type CodeRoots struct {
 
 func (p *CodeRoots) Run(ctx context.Context, in artifact.CodeRootsIn) (artifact.CodeRootsOut, error) {
 	if len(in.ExtCounts) == 0 || len(in.Dirs) == 0 {
-		exts, dirs := scanRepoLayout(in.Repo)
+		exts, dirs := scanRepoLayout(ctx, in.Repo)
 		if len(in.ExtCounts) == 0 {
 			in.ExtCounts = exts
 		}
@@ -54,6 +57,9 @@ func (p *CodeRoots) Run(ctx context.Context, in artifact.CodeRootsIn) (artifact.
 		"ext_counts": in.ExtCounts,
 		"dir_tree":   utils.PathsToTree(in.Dirs),
 	}
+	if len(in.Packages) > 0 {
+		input["detected_packages"] = in.Packages
+	}
 
 	loop := &llmtool.ToolLoop{
 		LLM:      p.LLM,
@@ -62,7 +68,13 @@ func (p *CodeRoots) Run(ctx context.Context, in artifact.CodeRootsIn) (artifact.
 		Allowed:  []string{"scan.list"},
 	}
 
-	raw, _, err := loop.Run(ctx, input, llmtool.StructuredPromptBuilder(codeRootsPromptSpec))
+	raw, err := schema.GenerateValidated(schema.KeyCodeRoots, func(hint string) (json.RawMessage, error) {
+		if hint != "" {
+			input["regen_hint"] = hint
+		}
+		raw, _, err := loop.Run(ctx, input, llmtool.StructuredPromptBuilder(codeRootsPromptSpec))
+		return raw, err
+	})
 	if err != nil {
 		return artifact.CodeRootsOut{}, err
 	}
@@ -70,14 +82,15 @@ func (p *CodeRoots) Run(ctx context.Context, in artifact.CodeRootsIn) (artifact.
 	if err := json.Unmarshal(raw, &out); err != nil {
 		return artifact.CodeRootsOut{}, fmt.Errorf("CodeRoots JSON invalid: %w\nraw: %s", err, string(raw))
 	}
+	out.Packages = in.Packages
 	return out, nil
 }
 
-func scanRepoLayout(repo string) (map[string]int, []string) {
+func scanRepoLayout(ctx context.Context, repo string) (map[string]int, []string) {
 	extCounts := map[string]int{}
 	var idx []string
 	// Deeper scan to find nested configs/source, but limit per-dir noise.
-	_ = scan.ScanWithOptions(repo, scan.Options{MaxDepth: 3, MaxPerDir: 10}, func(f scan.FileVisit) {
+	stats, err := scan.ScanWithStats(repo, scan.Options{MaxDepth: 3, MaxPerDir: 10}, func(f scan.FileVisit) {
 		if f.IsDir {
 			return
 		}
@@ -87,6 +100,8 @@ func scanRepoLayout(repo string) (map[string]int, []string) {
 			idx = append(idx, dir)
 		}
 	})
+	// The layout only guides the LLM; a truncated scan is still usable.
+	_ = scan.ReportTruncated(ctx, repo, stats, err)
 	dirSet := map[string]struct{}{}
 	for _, d := range idx {
 		dirSet[d] = struct{}{}
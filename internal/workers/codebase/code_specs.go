@@ -12,12 +12,13 @@ import (
 	llmclient "insightify/internal/llm/client"
 	"insightify/internal/llm/tool"
 	"insightify/internal/common/scan"
+	"insightify/internal/schema"
 )
 
 // CodeSpecs prompt — imports/includes only, plus normalization hints for later post-processing.
 var codeSpecsPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 	Purpose:      "Emit one spec per language family present in extension counts for dependency analysis.",
-	Background:   "Worker CodeSpecs analyzes file extension counts to detect language families and generate heuristic rules for import extraction.",
+	Background:   "Worker CodeSpecs analyzes file extension counts to detect language families and generate heuristic rules for import extraction. When present, 'ext_report' adds per extension sample paths, the head of the largest file and representative lines from hand-written sources.",
 	OutputFields: llmtool.MustFieldsFromStruct(artifact.CodeSpecsOut{}),
 	Constraints: []string{
 		"Emit specs **only** for families that appear in 'ext_counts'.",
@@ -25,22 +26,66 @@ var codeSpecsPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 		"Keep lists concise ('keywords' ≤ ~8, 'path_split' ≤ ~6).",
 		"Echo extensions exactly as seen (with leading dot).",
 		"Produce **valid JSON**. No comments. No regex patterns. No fields other than those listed.",
-		"If unknown, use empty arrays instead of inventing values.",
+		"If unknown, use empty arrays instead of inventing values, except 'keywords': every spec needs at least one (the family's import/include tokens), so omit a family whose import syntax you cannot tell.",
 	},
 	Rules: []string{
 		"Group related extensions into a **single** spec when they are the same language family (e.g., JavaScript/TypeScript -> .js, .mjs, .cjs, .jsx, .ts, .tsx).",
 		"Only include extensions that are actually present in 'ext_counts'.",
 		"Every extension listed in 'spec.ext' is an **interchangeable** candidate for resolution.",
+		"Use 'normalize_hints.alias' only for project path aliases (e.g., '@/' -> 'src/'); module strings are classified and split in code, not by you.",
+		"Derive 'keywords' and 'path_split' from the import statements visible in 'ext_report' head and lines when they show them.",
+		llmtool.RegenHintRule,
 	},
 	Assumptions:  []string{"Missing families should be ignored."},
 	OutputFormat: "JSON only.",
 	Language:     "English",
 }, llmtool.PresetStrictJSON(), llmtool.PresetNoInvent())
 
-type CodeSpecs struct{ LLM llmclient.LLMClient }
+// defaultCodeSpecsRetries is the number of corrective regenerations attempted
+// after the first response fails validation.
+const defaultCodeSpecsRetries = 2
+
+type CodeSpecs struct {
+	LLM llmclient.LLMClient
+	// MaxRetries bounds the regenerations after a validation failure.
+	// Zero uses defaultCodeSpecsRetries; a negative value disables retries.
+	MaxRetries int
+}
+
+// codeSpecsIssue pinpoints the first rule a generated CodeSpecsOut violated,
+// so the retry hint can name the exact spec and extension to fix.
+type codeSpecsIssue struct {
+	Rule    string
+	SpecKey string
+	Ext     string
+	Detail  string
+}
+
+func (e *codeSpecsIssue) Error() string {
+	var b strings.Builder
+	b.WriteString("rule=" + e.Rule)
+	if e.SpecKey != "" {
+		b.WriteString(" spec=" + e.SpecKey)
+	}
+	if e.Ext != "" {
+		b.WriteString(fmt.Sprintf(" ext=%q", e.Ext))
+	}
+	if e.Detail != "" {
+		b.WriteString(": " + e.Detail)
+	}
+	return b.String()
+}
+
+// regenHint renders the corrective instruction passed to the next attempt.
+func (e *codeSpecsIssue) regenHint() string {
+	return "The previous output failed validation (" + e.Error() + "). Fix only that spec and keep the rest unchanged."
+}
 
 func (x *CodeSpecs) Run(ctx context.Context, in artifact.CodeSpecsIn) (artifact.CodeSpecsOut, error) {
 	// Populate ext counts if missing so runner BuildInput can stay lightweight.
+	if len(in.ExtCounts) == 0 && in.ExtReport != nil {
+		in.ExtCounts = in.ExtReport.ExtCounts()
+	}
 	if len(in.ExtCounts) == 0 {
 		exts, err := computeExtCounts(ctx, in.Repo, in.Roots)
 		if err != nil {
@@ -54,21 +99,40 @@ func (x *CodeSpecs) Run(ctx context.Context, in artifact.CodeSpecsIn) (artifact.
 		"ext_counts": in.ExtCounts,
 		"roots":      in.Roots, // Pass roots context for hints
 	}
-
-	prompt, err := llmtool.StructuredPromptBuilder(codeSpecsPromptSpec)(ctx, &llmtool.ToolState{Input: input}, nil)
-	if err != nil {
-		return artifact.CodeSpecsOut{}, err
+	if in.ExtReport != nil {
+		input["ext_report"] = in.ExtReport.Exts
 	}
 
-	raw, err := x.LLM.GenerateJSON(ctx, prompt, input)
-	if err != nil {
-		return artifact.CodeSpecsOut{}, err
+	retries := x.MaxRetries
+	if retries == 0 {
+		retries = defaultCodeSpecsRetries
+	}
+	if retries < 0 {
+		retries = 0
 	}
 
 	var out artifact.CodeSpecsOut
-	if err := json.Unmarshal(raw, &out); err != nil {
-		return artifact.CodeSpecsOut{}, fmt.Errorf("CodeSpecs JSON invalid: %w\nraw: %s", err, string(raw))
+	for attempt := 0; ; attempt++ {
+		var (
+			issue *codeSpecsIssue
+			err   error
+		)
+		out, issue, err = x.generate(ctx, input)
+		if err != nil {
+			return artifact.CodeSpecsOut{}, err
+		}
+		if issue == nil {
+			issue = validateCodeSpecs(out, in.ExtCounts)
+		}
+		if issue == nil {
+			break
+		}
+		if attempt >= retries {
+			return artifact.CodeSpecsOut{}, fmt.Errorf("CodeSpecs validation failed after %d attempts: %w", attempt+1, issue)
+		}
+		input["regen_hint"] = issue.regenHint()
 	}
+
 	out.Families = out.Families[:0]
 	for family, specKeys := range out.FamilyKeys {
 		keys := append([]string(nil), specKeys...)
@@ -94,27 +158,86 @@ func (x *CodeSpecs) Run(ctx context.Context, in artifact.CodeSpecsIn) (artifact.
 	return out, nil
 }
 
-func computeExtCounts(ctx context.Context, repo string, roots artifact.CodeRootsOut) ([]artifact.ExtCount, error) {
-	_ = ctx
-	ignore := make(map[string]struct{})
-	for _, r := range roots.LibraryRoots {
-		r = strings.TrimSpace(r)
-		if r == "" {
-			continue
+// generate runs one attempt. A schema mismatch is reported as an issue so the
+// caller retries it like any other validation failure.
+func (x *CodeSpecs) generate(ctx context.Context, input map[string]any) (artifact.CodeSpecsOut, *codeSpecsIssue, error) {
+	prompt, err := llmtool.StructuredPromptBuilder(codeSpecsPromptSpec)(ctx, &llmtool.ToolState{Input: input}, nil)
+	if err != nil {
+		return artifact.CodeSpecsOut{}, nil, err
+	}
+
+	raw, err := x.LLM.GenerateJSON(ctx, prompt, input)
+	if err != nil {
+		return artifact.CodeSpecsOut{}, nil, err
+	}
+	violations, err := schema.Validate(schema.KeyCodeSpecs, raw)
+	if err != nil {
+		return artifact.CodeSpecsOut{}, nil, err
+	}
+	if len(violations) > 0 {
+		details := make([]string, 0, len(violations))
+		for _, v := range violations {
+			details = append(details, v.String())
 		}
-		base := filepath.Base(filepath.ToSlash(r))
-		if base == "" {
-			continue
+		return artifact.CodeSpecsOut{}, &codeSpecsIssue{Rule: "schema", Detail: strings.Join(details, "; ")}, nil
+	}
+
+	var out artifact.CodeSpecsOut
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return artifact.CodeSpecsOut{}, nil, fmt.Errorf("CodeSpecs JSON invalid: %w\nraw: %s", err, string(raw))
+	}
+	return out, nil, nil
+}
+
+// validateCodeSpecs checks the structural rules the extractor relies on and
+// returns the first violation in deterministic (sorted key) order.
+func validateCodeSpecs(out artifact.CodeSpecsOut, extCounts []artifact.ExtCount) *codeSpecsIssue {
+	seen := make(map[string]struct{}, len(extCounts))
+	for _, ec := range extCounts {
+		seen[strings.ToLower(ec.Ext)] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(out.Specs))
+	for k := range out.Specs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		spec := out.Specs[key]
+		if len(spec.Exts) == 0 {
+			return &codeSpecsIssue{Rule: "exts_required", SpecKey: key, Detail: "spec lists no extensions"}
+		}
+		for _, ext := range spec.Exts {
+			if !strings.HasPrefix(ext, ".") {
+				return &codeSpecsIssue{Rule: "ext_leading_dot", SpecKey: key, Ext: ext, Detail: "extensions must start with '.'"}
+			}
+			if _, ok := seen[strings.ToLower(ext)]; !ok {
+				return &codeSpecsIssue{Rule: "ext_in_counts", SpecKey: key, Ext: ext, Detail: "extension does not appear in ext_counts"}
+			}
+		}
+		if len(spec.Rules.Keywords) == 0 {
+			return &codeSpecsIssue{Rule: "keywords_required", SpecKey: key, Detail: "rules.keywords is empty"}
 		}
-		ignore[base] = struct{}{}
 	}
-	var ignoreDirs []string
-	for k := range ignore {
-		ignoreDirs = append(ignoreDirs, k)
+
+	families := make([]string, 0, len(out.FamilyKeys))
+	for f := range out.FamilyKeys {
+		families = append(families, f)
+	}
+	sort.Strings(families)
+	for _, family := range families {
+		for _, key := range out.FamilyKeys[family] {
+			if _, ok := out.Specs[key]; !ok {
+				return &codeSpecsIssue{Rule: "family_key_exists", SpecKey: key, Detail: fmt.Sprintf("family %q references a missing spec", family)}
+			}
+		}
 	}
+	return nil
+}
 
+func computeExtCounts(ctx context.Context, repo string, roots artifact.CodeRootsOut) ([]artifact.ExtCount, error) {
 	extCountMap := map[string]int{}
-	if err := scan.ScanWithOptions(repo, scan.Options{IgnoreDirs: ignoreDirs}, func(f scan.FileVisit) {
+	stats, err := scan.ScanWithStats(repo, scan.Options{IgnoreDirs: libraryIgnoreDirs(roots)}, func(f scan.FileVisit) {
 		if f.IsDir {
 			return
 		}
@@ -125,7 +248,9 @@ func computeExtCounts(ctx context.Context, repo string, roots artifact.CodeRoots
 		if ext != "" {
 			extCountMap[strings.ToLower(ext)]++
 		}
-	}); err != nil {
+	})
+	// Counts of a truncated scan still rank the extensions.
+	if err := scan.ReportTruncated(ctx, repo, stats, err); err != nil {
 		return nil, err
 	}
 
@@ -141,3 +266,25 @@ func computeExtCounts(ctx context.Context, repo string, roots artifact.CodeRoots
 	})
 	return extCounts, nil
 }
+
+// libraryIgnoreDirs returns the basenames of the library roots, which scans
+// of the project's own code skip.
+func libraryIgnoreDirs(roots artifact.CodeRootsOut) []string {
+	ignore := make(map[string]struct{})
+	for _, r := range roots.LibraryRoots {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		base := filepath.Base(filepath.ToSlash(r))
+		if base == "" {
+			continue
+		}
+		ignore[base] = struct{}{}
+	}
+	var ignoreDirs []string
+	for k := range ignore {
+		ignoreDirs = append(ignoreDirs, k)
+	}
+	return ignoreDirs
+}
@@ -0,0 +1,296 @@
+package codebase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/logctx"
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
+)
+
+// Defaults for CodeStats fields left zero.
+const (
+	DefaultStatsSamples = 5
+	DefaultStatsLines   = 8
+	DefaultStatsHead    = 20 // lines
+	DefaultStatsTopDirs = 3
+)
+
+const (
+	// statsSniffBytes is read from every file to detect generated code.
+	statsSniffBytes = 1 << 10
+	// statsSampleBytes caps what is read from a sampled file.
+	statsSampleBytes = 64 << 10
+	// statsMaxLineLen drops longer lines from the representative lines.
+	statsMaxLineLen = 200
+)
+
+// generatedMarkers flag generated files when found near the top of a file.
+var generatedMarkers = [][]byte{[]byte("Code generated"), []byte("@generated")}
+
+// commentPrefixes start comment-only lines. A bare "#" counts only before a
+// space, so preprocessor lines like "#include" stay.
+var commentPrefixes = []string{"//", "/*", "*", "<!--", "--", "# ", "#!"}
+
+// CodeStats builds the extension report code_specs reads: per extension the
+// file count, size and busiest top-level directories, plus sample paths, a
+// head snippet and representative lines taken from hand-written files of the
+// main source roots first. Library roots are not scanned.
+type CodeStats struct {
+	// SamplesPerExt, Lines, HeadLines and TopDirs bound the report per
+	// extension; zero uses the Default* constants.
+	SamplesPerExt int
+	Lines         int
+	HeadLines     int
+	TopDirs       int
+}
+
+type statsFile struct {
+	path      string
+	size      int64
+	main      bool
+	generated bool
+}
+
+func (x CodeStats) Run(ctx context.Context, in artifact.CodeStatsIn) (artifact.CodeStatsOut, error) {
+	fs := in.RepoFS
+	if fs == nil {
+		return artifact.CodeStatsOut{}, fmt.Errorf("codeStats: repo filesystem is nil")
+	}
+	// Missing roots only steer sampling and skipping, so they are recorded
+	// rather than failing the scan.
+	mainRoots, warnings := usableRoots(fs, "main_source_roots", relRoots(fs.Root(), in.Roots.MainSourceRoots))
+	libRoots, libWarnings := usableRoots(fs, "library_roots", relRoots(fs.Root(), in.Roots.LibraryRoots))
+	warnings = append(warnings, libWarnings...)
+	if len(warnings) > 0 {
+		logctx.Warn(ctx, "code stats skipped missing roots", "warnings", warnings)
+	}
+	roots := in.Roots
+	roots.LibraryRoots = libRoots
+
+	byExt := map[string][]statsFile{}
+	stats, err := scan.ScanWithStats(in.Repo, scan.Options{IgnoreDirs: libraryIgnoreDirs(roots)}, func(f scan.FileVisit) {
+		if f.IsDir || f.Ext == "" {
+			return
+		}
+		byExt[f.Ext] = append(byExt[f.Ext], statsFile{path: f.Path, size: f.Size, main: underAny(f.Path, mainRoots)})
+	})
+	if stats.Truncated {
+		warnings = append(warnings, fmt.Sprintf("scan truncated by size limits after %d files (%d bytes); stats cover those files only", stats.FilesScanned, stats.BytesScanned))
+	}
+	if err := scan.ReportTruncated(ctx, in.Repo, stats, err); err != nil {
+		return artifact.CodeStatsOut{}, err
+	}
+
+	out := artifact.CodeStatsOut{Exts: make([]artifact.ExtStats, 0, len(byExt)), Warnings: warnings}
+	for ext, files := range byExt {
+		if err := ctx.Err(); err != nil {
+			return artifact.CodeStatsOut{}, err
+		}
+		st, err := x.extStats(fs, ext, files)
+		if err != nil {
+			return artifact.CodeStatsOut{}, err
+		}
+		out.Exts = append(out.Exts, st)
+	}
+	sort.Slice(out.Exts, func(i, j int) bool {
+		if out.Exts[i].Count == out.Exts[j].Count {
+			return out.Exts[i].Ext < out.Exts[j].Ext
+		}
+		return out.Exts[i].Count > out.Exts[j].Count
+	})
+	return out, nil
+}
+
+func (x CodeStats) extStats(fs *safeio.SafeFS, ext string, files []statsFile) (artifact.ExtStats, error) {
+	st := artifact.ExtStats{Ext: ext, Count: len(files)}
+	dirs := map[string]int{}
+	for i := range files {
+		st.Bytes += files[i].size
+		dirs[topDir(files[i].path)]++
+		gen, err := isGenerated(fs, files[i])
+		if err != nil {
+			return artifact.ExtStats{}, err
+		}
+		files[i].generated = gen
+		if gen {
+			st.Generated++
+		}
+	}
+	st.TopDirs = topDirCounts(dirs, orDefault(x.TopDirs, DefaultStatsTopDirs))
+
+	// Main source roots first, then by path, so samples are stable.
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].main != files[j].main {
+			return files[i].main
+		}
+		return files[i].path < files[j].path
+	})
+	var (
+		lines   []string
+		largest *statsFile
+	)
+	for i := range files {
+		f := &files[i]
+		if f.generated {
+			continue
+		}
+		if largest == nil || f.size > largest.size {
+			largest = f
+		}
+		if len(st.Samples) >= orDefault(x.SamplesPerExt, DefaultStatsSamples) {
+			continue
+		}
+		st.Samples = append(st.Samples, f.path)
+		data, _, err := fs.SafeReadFileLimited(f.path, statsSampleBytes, 0)
+		if err != nil {
+			return artifact.ExtStats{}, err
+		}
+		lines = append(lines, codeLines(data)...)
+	}
+	st.Lines = pickLines(lines, orDefault(x.Lines, DefaultStatsLines), ext)
+	if largest != nil {
+		data, _, err := fs.SafeReadFileLimited(largest.path, statsSampleBytes, 0)
+		if err != nil {
+			return artifact.ExtStats{}, err
+		}
+		st.HeadPath = largest.path
+		st.Head = headLines(data, orDefault(x.HeadLines, DefaultStatsHead))
+	}
+	return st, nil
+}
+
+// isGenerated reports whether the top of f carries a generated-code marker or
+// looks minified: a first line longer than the sniffed bytes.
+func isGenerated(fs *safeio.SafeFS, f statsFile) (bool, error) {
+	if f.size == 0 {
+		return false, nil
+	}
+	head, _, err := fs.SafeReadFileLimited(f.path, statsSniffBytes, 0)
+	if err != nil {
+		return false, err
+	}
+	for _, m := range generatedMarkers {
+		if bytes.Contains(head, m) {
+			return true, nil
+		}
+	}
+	return f.size > statsSniffBytes && !bytes.Contains(head, []byte("\n")), nil
+}
+
+// codeLines returns the lines of data that carry code: not blank, not
+// comment-only and at most statsMaxLineLen long.
+func codeLines(data []byte) []string {
+	var out []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || len(line) > statsMaxLineLen || isCommentLine(trimmed) {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func isCommentLine(trimmed string) bool {
+	if trimmed == "#" {
+		return true
+	}
+	for _, p := range commentPrefixes {
+		if strings.HasPrefix(trimmed, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// pickLines draws n distinct lines, seeded by ext so reruns pick the same ones.
+func pickLines(lines []string, n int, ext string) []string {
+	if len(lines) <= n {
+		return lines
+	}
+	h := fnv.New64a()
+	h.Write([]byte(ext))
+	rng := rand.New(rand.NewPCG(h.Sum64(), 0))
+	idx := rng.Perm(len(lines))[:n]
+	sort.Ints(idx)
+	out := make([]string, 0, n)
+	for _, i := range idx {
+		out = append(out, lines[i])
+	}
+	return out
+}
+
+func headLines(data []byte, n int) string {
+	lines := strings.SplitN(string(data), "\n", n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func topDir(path string) string {
+	if i := strings.Index(path, "/"); i > 0 {
+		return path[:i]
+	}
+	return "."
+}
+
+func topDirCounts(dirs map[string]int, n int) []artifact.DirCount {
+	out := make([]artifact.DirCount, 0, len(dirs))
+	for d, c := range dirs {
+		out = append(out, artifact.DirCount{Dir: d, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count == out[j].Count {
+			return out[i].Dir < out[j].Dir
+		}
+		return out[i].Count > out[j].Count
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// relRoots turns code_roots paths, which may be absolute or carry the repo
+// root, into repo-relative slash paths.
+func relRoots(repoRoot string, roots []string) []string {
+	var out []string
+	for _, r := range roots {
+		r = filepath.ToSlash(strings.TrimSpace(r))
+		if rel, err := filepath.Rel(repoRoot, filepath.FromSlash(r)); err == nil && filepath.IsAbs(r) && !strings.HasPrefix(rel, "..") {
+			r = filepath.ToSlash(rel)
+		}
+		r = strings.Trim(strings.TrimPrefix(r, "./"), "/")
+		if r != "" && r != "." {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func underAny(path string, roots []string) bool {
+	for _, r := range roots {
+		if path == r || strings.HasPrefix(path, r+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
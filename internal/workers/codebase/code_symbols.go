@@ -14,6 +14,7 @@ import (
 	"insightify/internal/llm/tool"
 	"insightify/internal/common/safeio"
 	"insightify/internal/common/scheduler"
+	"insightify/internal/schema"
 )
 
 type codeSymbolsOutput struct {
@@ -40,14 +41,34 @@ var codeSymbolsPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 		"If no summary is provided, omit notes as well.",
 		"For each identifier, list the identifiers it requires/uses in 'requires' with both path and identifier name when known.",
 		"Classify each requirement as user|library|runtime|vendor|stdlib|framework in 'origin'.",
+		llmtool.RegenHintRule,
 	},
 	Assumptions:  []string{"Files provided are source code."},
 	OutputFormat: "JSON only.",
 	Language:     "English",
 }, llmtool.PresetStrictJSON(), llmtool.PresetNoInvent())
 
+const (
+	// DefaultSymbolsFileBytes caps the content of one file sent to the LLM
+	// when CodeSymbols.MaxFileBytes is unset.
+	DefaultSymbolsFileBytes = 64 << 10
+	// DefaultSymbolsSkipBytes is the size above which a file is skipped when
+	// CodeSymbols.SkipFileBytes is unset.
+	DefaultSymbolsSkipBytes = 1 << 20
+)
+
+// truncatedFileMarker ends the content of a file cut at MaxFileBytes.
+const truncatedFileMarker = "\n... (truncated at %d bytes)\n"
+
 type CodeSymbols struct {
 	LLM llmclient.LLMClient
+	// Explain, when set, receives the scheduler's chunk decisions.
+	Explain scheduler.ExplainFn
+	// MaxFileBytes caps the content of each file sent to the LLM; longer
+	// files are cut and end with a truncation marker.
+	MaxFileBytes int64
+	// SkipFileBytes skips files larger than this instead of cutting them.
+	SkipFileBytes int64
 }
 
 func (p CodeSymbols) Run(ctx context.Context, in artifact.CodeSymbolsIn) (artifact.CodeSymbolsOut, error) {
@@ -75,12 +96,20 @@ func (p CodeSymbols) Run(ctx context.Context, in artifact.CodeSymbolsIn) (artifa
 	}
 
 	var (
-		mu    sync.Mutex
-		notes = make(map[int][]string)
+		mu      sync.Mutex
+		notes   = make(map[int][]string)
+		chunkOf = make(map[int]int)
+		chunks  int
 	)
 
 	runChunk := func(chunkCtx context.Context, chunk []int) (<-chan struct{}, error) {
 		ids := append([]int(nil), chunk...)
+		mu.Lock()
+		for _, id := range ids {
+			chunkOf[id] = chunks
+		}
+		chunks++
+		mu.Unlock()
 		totalWeight := 0
 		fmt.Printf("codeSymbols chunk schedule: %d nodes\n", len(ids))
 		for _, id := range ids {
@@ -149,6 +178,7 @@ func (p CodeSymbols) Run(ctx context.Context, in artifact.CodeSymbolsIn) (artifa
 		CapPerChunk: p.LLM.TokenCapacity(),
 		NParallel:   1,
 		Run:         scheduler.ChunkRunner(runChunk),
+		Explain:     p.Explain,
 	}
 	if err := scheduler.ScheduleHeavierStart(ctx, params); err != nil {
 		return artifact.CodeSymbolsOut{}, err
@@ -158,13 +188,36 @@ func (p CodeSymbols) Run(ctx context.Context, in artifact.CodeSymbolsIn) (artifa
 		results[id].Notes = append(results[id].Notes, ns...)
 	}
 
+	stats := p.resolveRequirements(ctx, results, chunkOf, adj, in.Resolution)
+
 	return artifact.CodeSymbolsOut{
-			Repo:  in.Repo,
-			Files: results,
+			Repo:       in.Repo,
+			Files:      results,
+			Resolution: stats,
 		},
 		nil
 }
 
+// readFile reads path for the LLM payload within the file size limits.
+func (p CodeSymbols) readFile(fs *safeio.SafeFS, path string) (string, error) {
+	maxBytes := p.MaxFileBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultSymbolsFileBytes
+	}
+	skipBytes := p.SkipFileBytes
+	if skipBytes <= 0 {
+		skipBytes = DefaultSymbolsSkipBytes
+	}
+	data, truncated, err := fs.SafeReadFileLimited(filepath.Clean(path), maxBytes, skipBytes)
+	if err != nil {
+		return "", err
+	}
+	if truncated {
+		return string(data) + fmt.Sprintf(truncatedFileMarker, maxBytes), nil
+	}
+	return string(data), nil
+}
+
 func (p CodeSymbols) processChunk(ctx context.Context, repo string, fs *safeio.SafeFS, nodes []artifact.CodeTasksNode, ids []int) (map[int][]artifact.IdentifierSignal, map[int]error, error) {
 	type filePayload struct {
 		Path     string `json:"path"`
@@ -172,8 +225,9 @@ func (p CodeSymbols) processChunk(ctx context.Context, repo string, fs *safeio.S
 		Content  string `json:"content"`
 	}
 	payload := struct {
-		Repo  string        `json:"repo"`
-		Files []filePayload `json:"files"`
+		Repo      string        `json:"repo"`
+		Files     []filePayload `json:"files"`
+		RegenHint string        `json:"regen_hint,omitempty"`
 	}{
 		Repo: repo,
 	}
@@ -194,7 +248,7 @@ func (p CodeSymbols) processChunk(ctx context.Context, repo string, fs *safeio.S
 			perNodeErr[id] = fmt.Errorf("empty path for node %d", id)
 			continue
 		}
-		data, err := fs.SafeReadFile(filepath.Clean(path))
+		content, err := p.readFile(fs, path)
 		if err != nil {
 			perNodeErr[id] = fmt.Errorf("read %s: %w", path, err)
 			continue
@@ -202,7 +256,7 @@ func (p CodeSymbols) processChunk(ctx context.Context, repo string, fs *safeio.S
 		payload.Files = append(payload.Files, filePayload{
 			Path:     path,
 			Language: strings.TrimPrefix(filepath.Ext(path), "."),
-			Content:  string(data),
+			Content:  content,
 		})
 		pathToIDs[path] = append(pathToIDs[path], id)
 	}
@@ -211,19 +265,21 @@ func (p CodeSymbols) processChunk(ctx context.Context, repo string, fs *safeio.S
 		return nil, perNodeErr, nil
 	}
 
-	// Build prompt using llmtool
-	prompt, err := llmtool.StructuredPromptBuilder(codeSymbolsPromptSpec)(ctx, &llmtool.ToolState{Input: payload}, nil)
-	if err != nil {
-		return nil, perNodeErr, err
-	}
-
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return nil, perNodeErr, fmt.Errorf("encode payload: %w", err)
 	}
 	fmt.Printf("codeSymbols chunk: files=%d tokens=%d\n", len(payload.Files), llmclient.CountTokens(string(payloadBytes)))
 
-	raw, err := p.LLM.GenerateJSON(llm.WithWorker(ctx, "codeSymbols"), prompt, payload)
+	raw, err := schema.GenerateValidated(schema.KeyCodeSymbols, func(hint string) (json.RawMessage, error) {
+		payload.RegenHint = hint
+		// Build prompt using llmtool
+		prompt, err := llmtool.StructuredPromptBuilder(codeSymbolsPromptSpec)(ctx, &llmtool.ToolState{Input: payload}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.LLM.GenerateJSON(llm.WithWorker(ctx, "codeSymbols"), prompt, payload)
+	})
 	if err != nil {
 		return nil, perNodeErr, err
 	}
@@ -0,0 +1,301 @@
+package codebase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"insightify/internal/artifact"
+	"insightify/internal/llm/middleware"
+	"insightify/internal/llm/tool"
+	"insightify/internal/schema"
+)
+
+// SymbolResolutionVersion is bumped whenever resolution rules change, so
+// cached code_symbols outputs are recomputed.
+const SymbolResolutionVersion = 1
+
+// maxResolveCandidates bounds how many definitions one reference sends to the LLM.
+const maxResolveCandidates = 8
+
+// DefaultSymbolResolution is the resolution config code_symbols runs with.
+func DefaultSymbolResolution() artifact.SymbolResolution {
+	return artifact.SymbolResolution{Version: SymbolResolutionVersion, LLMBatchSize: 20}
+}
+
+type codeSymbolsResolveOutput struct {
+	Resolutions []struct {
+		Ref  int    `json:"ref"`
+		Path string `json:"path"`
+	} `json:"resolutions"`
+}
+
+var codeSymbolsResolvePromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
+	Purpose:      "Pick which candidate definition each identifier reference points to.",
+	Background:   "Worker CodeSymbols extracted identifiers chunk by chunk; these references matched several definitions in other files.",
+	OutputFields: llmtool.MustFieldsFromStruct(codeSymbolsResolveOutput{}),
+	Constraints: []string{
+		"Return one entry per input reference, echoing its 'ref'.",
+		"'path' must be one of the reference's candidate paths, or empty when none fits.",
+	},
+	Rules: []string{
+		"Prefer candidates whose path matches 'path_hint' or sits near 'from_path'.",
+		"Use candidate summaries to match what the calling identifier does.",
+		llmtool.RegenHintRule,
+	},
+	OutputFormat: "JSON only.",
+	Language:     "English",
+}, llmtool.PresetStrictJSON(), llmtool.PresetNoInvent())
+
+// symbolDef is one identifier definition found by the per-chunk pass.
+type symbolDef struct {
+	node    int
+	path    string
+	role    string
+	summary string
+}
+
+// pendingRef is an ambiguous requirement waiting for the LLM pass.
+type pendingRef struct {
+	node       int
+	req        *artifact.IdentifierRequirement
+	fromIdent  string
+	candidates []symbolDef
+}
+
+// resolveRequirements labels every user requirement in results with a
+// resolution state. Requirements are matched by exact name against
+// definitions from every chunk; a path hint or a code_graph import edge
+// narrows several candidates to one, and the rest go to the LLM in batches.
+func (p CodeSymbols) resolveRequirements(ctx context.Context, results []artifact.IdentifierReport, chunkOf map[int]int, adj [][]int, cfg artifact.SymbolResolution) artifact.ResolutionStats {
+	defs := make(map[string][]symbolDef)
+	for node, rep := range results {
+		for _, sig := range rep.Identifiers {
+			if sig.Name == "" {
+				continue
+			}
+			defs[sig.Name] = append(defs[sig.Name], symbolDef{node: node, path: rep.Path, role: sig.Role, summary: sig.Summary})
+		}
+	}
+	neighbors := importNeighbors(adj, len(results))
+
+	var pending []pendingRef
+	for node := range results {
+		for i := range results[node].Identifiers {
+			sig := &results[node].Identifiers[i]
+			for k := range sig.Requires {
+				req := &sig.Requires[k]
+				if !isUserRequirement(*req) {
+					continue
+				}
+				cands := defs[req.Identifier]
+				if def, ok := pickDefinition(cands, req.Path, node, neighbors[node]); ok {
+					req.Path = def.path
+					req.Resolution = artifact.ResolvedCrossChunk
+					if chunkOf[def.node] == chunkOf[node] {
+						req.Resolution = artifact.ResolvedLocally
+					}
+					continue
+				}
+				req.Resolution = artifact.StillUnresolved
+				if len(cands) == 0 {
+					continue
+				}
+				narrowed := plausibleDefinitions(cands, req.Path, neighbors[node])
+				if len(narrowed) == 0 {
+					narrowed = cands
+				}
+				if len(narrowed) > maxResolveCandidates {
+					narrowed = narrowed[:maxResolveCandidates]
+				}
+				pending = append(pending, pendingRef{node: node, req: req, fromIdent: sig.Name, candidates: narrowed})
+			}
+		}
+	}
+
+	if len(pending) > 0 && !cfg.SkipLLM && p.LLM != nil {
+		batch := cfg.LLMBatchSize
+		if batch <= 0 {
+			batch = DefaultSymbolResolution().LLMBatchSize
+		}
+		for start := 0; start < len(pending); start += batch {
+			end := min(start+batch, len(pending))
+			if err := p.resolveBatch(ctx, results, pending[start:end]); err != nil {
+				for _, ref := range pending[start:end] {
+					results[ref.node].Notes = append(results[ref.node].Notes, fmt.Sprintf("resolve %s: %v", ref.req.Identifier, err))
+				}
+			}
+		}
+	}
+
+	var stats artifact.ResolutionStats
+	for _, rep := range results {
+		for _, sig := range rep.Identifiers {
+			for _, req := range sig.Requires {
+				switch req.Resolution {
+				case artifact.ResolvedLocally:
+					stats.ResolvedLocally++
+				case artifact.ResolvedCrossChunk:
+					stats.ResolvedCrossChunk++
+				case artifact.ResolvedViaLLM:
+					stats.ResolvedViaLLM++
+				case artifact.StillUnresolved:
+					stats.StillUnresolved++
+				}
+			}
+		}
+	}
+	return stats
+}
+
+// resolveBatch asks the LLM to choose among each reference's candidates.
+// Answers naming a path outside the candidates are ignored.
+func (p CodeSymbols) resolveBatch(ctx context.Context, results []artifact.IdentifierReport, refs []pendingRef) error {
+	type candidatePayload struct {
+		Path    string `json:"path"`
+		Role    string `json:"role,omitempty"`
+		Summary string `json:"summary,omitempty"`
+	}
+	type refPayload struct {
+		Ref            int                `json:"ref"`
+		FromPath       string             `json:"from_path"`
+		FromIdentifier string             `json:"from_identifier"`
+		Identifier     string             `json:"identifier"`
+		PathHint       string             `json:"path_hint,omitempty"`
+		Candidates     []candidatePayload `json:"candidates"`
+	}
+	payload := struct {
+		References []refPayload `json:"references"`
+		RegenHint  string       `json:"regen_hint,omitempty"`
+	}{}
+	for i, ref := range refs {
+		rp := refPayload{
+			Ref:            i,
+			FromPath:       results[ref.node].Path,
+			FromIdentifier: ref.fromIdent,
+			Identifier:     ref.req.Identifier,
+			PathHint:       ref.req.Path,
+		}
+		for _, c := range ref.candidates {
+			rp.Candidates = append(rp.Candidates, candidatePayload{Path: c.path, Role: c.role, Summary: c.summary})
+		}
+		payload.References = append(payload.References, rp)
+	}
+
+	raw, err := schema.GenerateValidated(schema.KeyCodeSymbolsResolve, func(hint string) (json.RawMessage, error) {
+		payload.RegenHint = hint
+		prompt, err := llmtool.StructuredPromptBuilder(codeSymbolsResolvePromptSpec)(ctx, &llmtool.ToolState{Input: payload}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.LLM.GenerateJSON(llm.WithWorker(ctx, "codeSymbolsResolve"), prompt, payload)
+	})
+	if err != nil {
+		return err
+	}
+	var parsed codeSymbolsResolveOutput
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return err
+	}
+	for _, r := range parsed.Resolutions {
+		if r.Ref < 0 || r.Ref >= len(refs) || r.Path == "" {
+			continue
+		}
+		ref := refs[r.Ref]
+		for _, c := range ref.candidates {
+			if c.path == r.Path {
+				ref.req.Path = c.path
+				ref.req.Resolution = artifact.ResolvedViaLLM
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// pickDefinition returns the single definition req refers to without asking
+// the LLM: an exact path match, a definition in the requiring file, the only
+// definition of that name, or the only plausible one.
+func pickDefinition(cands []symbolDef, hint string, node int, neighbors map[int]struct{}) (symbolDef, bool) {
+	if len(cands) == 0 {
+		return symbolDef{}, false
+	}
+	hint = cleanRefPath(hint)
+	for _, c := range cands {
+		if hint != "" && cleanRefPath(c.path) == hint {
+			return c, true
+		}
+	}
+	for _, c := range cands {
+		if c.node == node {
+			// Same-file definitions shadow everything else.
+			return c, true
+		}
+	}
+	if len(cands) == 1 {
+		return cands[0], true
+	}
+	if plausible := plausibleDefinitions(cands, hint, neighbors); len(plausible) == 1 {
+		return plausible[0], true
+	}
+	return symbolDef{}, false
+}
+
+// plausibleDefinitions keeps candidates connected to the requiring file by an
+// import edge, or whose path falls under the requirement's path hint (a
+// package directory or module path the per-chunk call guessed).
+func plausibleDefinitions(cands []symbolDef, hint string, neighbors map[int]struct{}) []symbolDef {
+	hint = cleanRefPath(hint)
+	var out []symbolDef
+	for _, c := range cands {
+		if _, ok := neighbors[c.node]; ok {
+			out = append(out, c)
+			continue
+		}
+		if hint == "" {
+			continue
+		}
+		p := cleanRefPath(c.path)
+		if path.Dir(p) == hint || strings.HasPrefix(p, hint+"/") || strings.HasSuffix(path.Dir(p), "/"+hint) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// importNeighbors turns the task adjacency (code_graph import edges) into an
+// undirected neighbor set per node.
+func importNeighbors(adj [][]int, n int) []map[int]struct{} {
+	out := make([]map[int]struct{}, n)
+	for i := range out {
+		out[i] = map[int]struct{}{}
+	}
+	for from, tos := range adj {
+		for _, to := range tos {
+			if from < 0 || from >= n || to < 0 || to >= n {
+				continue
+			}
+			out[from][to] = struct{}{}
+			out[to][from] = struct{}{}
+		}
+	}
+	return out
+}
+
+func isUserRequirement(req artifact.IdentifierRequirement) bool {
+	if strings.TrimSpace(req.Identifier) == "" {
+		return false
+	}
+	origin := strings.ToLower(strings.TrimSpace(req.Origin))
+	return origin == "" || origin == "user"
+}
+
+func cleanRefPath(p string) string {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return ""
+	}
+	return strings.TrimPrefix(path.Clean(strings.ReplaceAll(p, "\\", "/")), "./")
+}
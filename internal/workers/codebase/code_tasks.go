@@ -2,7 +2,10 @@ package codebase
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"insightify/internal/artifact"
 	llmclient "insightify/internal/llm/client"
@@ -47,9 +50,9 @@ func (p CodeTasks) Run(ctx context.Context, in artifact.CodeTasksIn) (artifact.C
 		}
 	}
 
-	adj := make([][]int, len(graph.Adjacency))
-	for i := range graph.Adjacency {
-		adj[i] = append([]int(nil), graph.Adjacency[i]...)
+	adj, cyclic := condenseCycles(graph.Adjacency)
+	if cyclic && in.FailOnCycle {
+		return artifact.CodeTasksOut{}, &CycleError{Cycles: in.Cycles}
 	}
 
 	return artifact.CodeTasksOut{
@@ -57,5 +60,71 @@ func (p CodeTasks) Run(ctx context.Context, in artifact.CodeTasksIn) (artifact.C
 		CapPerChunk: in.CapPerChunk,
 		Nodes:       taskNodes,
 		Adjacency:   adj,
+		Cycles:      in.Cycles,
 	}, nil
+}
+
+// CycleError reports that the dependency graph is not a DAG.
+type CycleError struct {
+	Cycles []artifact.CycleReport
+}
+
+func (e *CycleError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "code_tasks: dependency graph has %d cycle(s)", len(e.Cycles))
+	for _, c := range e.Cycles {
+		fmt.Fprintf(&b, "; members=%v cut=%d->%d (weight %d)", c.Members, c.SuggestedCut.From, c.SuggestedCut.To, c.SuggestedCut.Weight)
+	}
+	return b.String()
+}
+
+// condenseCycles treats every strongly connected component as one super-node:
+// internal edges are dropped and each cross-component edge is expanded to all
+// member pairs, so members become ready together and the result is a DAG.
+// The boolean reports whether any component had more than one member.
+func condenseCycles(adjacency [][]int) ([][]int, bool) {
+	comps := stronglyConnected(adjacency)
+	compOf := make([]int, len(adjacency))
+	cyclic := false
+	for ci, comp := range comps {
+		if len(comp) > 1 {
+			cyclic = true
+		}
+		for _, id := range comp {
+			compOf[id] = ci
+		}
+	}
+
+	adj := make([][]int, len(adjacency))
+	if !cyclic {
+		for i := range adjacency {
+			adj[i] = append([]int(nil), adjacency[i]...)
+		}
+		return adj, false
+	}
+
+	compEdges := make(map[int]map[int]struct{})
+	for from, tos := range adjacency {
+		for _, to := range tos {
+			cf, ct := compOf[from], compOf[to]
+			if cf == ct {
+				continue
+			}
+			if compEdges[cf] == nil {
+				compEdges[cf] = make(map[int]struct{})
+			}
+			compEdges[cf][ct] = struct{}{}
+		}
+	}
+	for cf, cts := range compEdges {
+		for ct := range cts {
+			for _, from := range comps[cf] {
+				adj[from] = append(adj[from], comps[ct]...)
+			}
+		}
+	}
+	for i := range adj {
+		sort.Ints(adj[i])
+	}
+	return adj, true
 }
\ No newline at end of file
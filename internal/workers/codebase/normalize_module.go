@@ -0,0 +1,127 @@
+package codebase
+
+import (
+	"path"
+	"strings"
+
+	"insightify/internal/artifact"
+)
+
+// implicitAliasPrefixes are bundler-style root aliases recognized without hints.
+var implicitAliasPrefixes = []string{"@/", "~/"}
+
+// NormalizeModule classifies a raw module string emitted by an extractor and
+// splits it into its path or package components. Alias pairs from hints are
+// applied first (longest original prefix wins), so "@app/x" with an alias to
+// "src/app" is treated as the path "src/app/x".
+func NormalizeModule(raw string, hints artifact.NormalizeHints) artifact.ModuleInfo {
+	info := artifact.ModuleInfo{Raw: raw}
+	s := strings.Trim(strings.TrimSpace(raw), "\"'`;")
+
+	if strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+		info.Kind = artifact.ModuleKindSystemHeader
+		info.Normalized = s
+		splitModulePath(&info, s)
+		return info
+	}
+
+	if alias, normalized, ok := matchAlias(s, hints); ok {
+		info.Alias = alias
+		s = normalized
+		info.Kind = artifact.ModuleKindPath
+	} else {
+		for _, p := range implicitAliasPrefixes {
+			if strings.HasPrefix(s, p) {
+				info.Alias = p
+				info.Kind = artifact.ModuleKindPath
+				break
+			}
+		}
+	}
+	info.Normalized = s
+
+	switch {
+	case info.Kind == artifact.ModuleKindPath:
+	case strings.HasPrefix(s, "./"), strings.HasPrefix(s, "../"), strings.HasPrefix(s, "/"), s == ".", s == "..":
+		info.Kind = artifact.ModuleKindPath
+	case strings.HasPrefix(s, "@"):
+		info.Kind = artifact.ModuleKindPackage
+		parts := strings.SplitN(s, "/", 3)
+		info.Scope = parts[0]
+		if len(parts) > 1 {
+			info.Package = parts[1]
+		}
+		if len(parts) > 2 {
+			info.Subpath = parts[2]
+		}
+		return info
+	case strings.Contains(s, "/"):
+		first := s[:strings.Index(s, "/")]
+		if strings.Contains(first, ".") {
+			// Host-qualified import such as "github.com/org/repo/pkg".
+			info.Kind = artifact.ModuleKindModule
+			return info
+		}
+		if path.Ext(s) != "" {
+			// Bare include such as "utils/a.h".
+			info.Kind = artifact.ModuleKindPath
+			break
+		}
+		info.Kind = artifact.ModuleKindPackage
+		info.Package = first
+		info.Subpath = s[len(first)+1:]
+		return info
+	case strings.Contains(s, "."):
+		if ext := path.Ext(s); ext != "" && isKnownSourceExt(ext) {
+			info.Kind = artifact.ModuleKindPath
+			break
+		}
+		info.Kind = artifact.ModuleKindModule
+		return info
+	default:
+		info.Kind = artifact.ModuleKindPackage
+		info.Package = s
+		return info
+	}
+
+	splitModulePath(&info, s)
+	return info
+}
+
+func matchAlias(s string, hints artifact.NormalizeHints) (string, string, bool) {
+	best := -1
+	for i, a := range hints.Alias {
+		orig := strings.TrimSpace(a.Original)
+		if orig == "" || !strings.HasPrefix(s, orig) {
+			continue
+		}
+		if best < 0 || len(orig) > len(strings.TrimSpace(hints.Alias[best].Original)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return "", "", false
+	}
+	orig := strings.TrimSpace(hints.Alias[best].Original)
+	return orig, strings.TrimSpace(hints.Alias[best].Normalized) + s[len(orig):], true
+}
+
+func splitModulePath(info *artifact.ModuleInfo, s string) {
+	dir, file := "", s
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		dir, file = s[:i], s[i+1:]
+	}
+	info.FolderPath = dir
+	info.FileExt = path.Ext(file)
+	info.FileName = strings.TrimSuffix(file, info.FileExt)
+}
+
+func isKnownSourceExt(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".h", ".hpp", ".hh", ".c", ".cc", ".cpp", ".js", ".mjs", ".cjs", ".jsx",
+		".ts", ".tsx", ".py", ".go", ".rs", ".java", ".css", ".scss", ".json", ".vue", ".svelte":
+		return true
+	}
+	return false
+}
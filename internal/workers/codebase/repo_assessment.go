@@ -0,0 +1,170 @@
+package codebase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/artifact"
+	"insightify/internal/common/scan"
+)
+
+// Defaults for the RepoAssessmentIn thresholds left zero.
+const (
+	DefaultMinCodeRatio = 0.05
+	DefaultMinCodeBytes = 64
+)
+
+// assessLargestDirs bounds RepoAssessmentOut.LargestDirs.
+const assessLargestDirs = 5
+
+// notCodeLanguages are languages scan recognizes that hold no program code.
+var notCodeLanguages = map[string]bool{
+	"markdown": true,
+	"json":     true,
+	"yaml":     true,
+	"toml":     true,
+}
+
+// docsExts and dataExts classify the extensions that are not code.
+var (
+	docsExts = map[string]bool{
+		".md": true, ".markdown": true, ".mdx": true, ".rst": true, ".txt": true,
+		".adoc": true, ".org": true, ".tex": true, ".pdf": true, ".docx": true,
+	}
+	dataExts = map[string]bool{
+		".csv": true, ".tsv": true, ".json": true, ".jsonl": true, ".ndjson": true,
+		".parquet": true, ".xml": true, ".yaml": true, ".yml": true, ".toml": true,
+		".sqlite": true, ".db": true, ".xlsx": true, ".xls": true, ".avro": true,
+		".npy": true, ".h5": true, ".arrow": true,
+	}
+)
+
+// RepoAssessment decides from the code_stats report, without the LLM,
+// whether a repository holds enough recognizable code to be worth the
+// analysis phases: code files must make up at least MinCodeRatio of the
+// files and MinCodeBytes of code must exist. Extensions whose language
+// scan.Language knows count as code, apart from markup and config formats.
+type RepoAssessment struct{}
+
+func (RepoAssessment) Run(_ context.Context, in artifact.RepoAssessmentIn) (artifact.RepoAssessmentOut, error) {
+	out := artifact.RepoAssessmentOut{
+		Exts:         make([]artifact.ExtKind, 0, len(in.Stats.Exts)),
+		MinCodeRatio: in.MinCodeRatio,
+		MinCodeBytes: in.MinCodeBytes,
+	}
+	if out.MinCodeRatio <= 0 {
+		out.MinCodeRatio = DefaultMinCodeRatio
+	}
+	if out.MinCodeBytes <= 0 {
+		out.MinCodeBytes = DefaultMinCodeBytes
+	}
+
+	dirs := map[string]int{}
+	for _, e := range in.Stats.Exts {
+		kind := extKind(e.Ext)
+		out.Exts = append(out.Exts, artifact.ExtKind{Ext: e.Ext, Kind: kind, Count: e.Count, Bytes: e.Bytes})
+		out.Files += e.Count
+		out.Bytes += e.Bytes
+		switch kind {
+		case artifact.FileKindCode:
+			out.CodeFiles += e.Count
+			out.CodeBytes += e.Bytes
+		case artifact.FileKindDocs:
+			out.DocsFiles += e.Count
+			out.Docs = append(out.Docs, e.Ext)
+		case artifact.FileKindData:
+			out.DataFiles += e.Count
+			out.Data = append(out.Data, e.Ext)
+		default:
+			out.OtherFiles += e.Count
+		}
+		for _, d := range e.TopDirs {
+			dirs[d.Dir] += d.Count
+		}
+	}
+	sort.SliceStable(out.Exts, func(i, j int) bool { return out.Exts[i].Count > out.Exts[j].Count })
+	sort.Strings(out.Docs)
+	sort.Strings(out.Data)
+	for dir, n := range dirs {
+		out.LargestDirs = append(out.LargestDirs, artifact.DirCount{Dir: dir, Count: n})
+	}
+	sort.Slice(out.LargestDirs, func(i, j int) bool {
+		if out.LargestDirs[i].Count != out.LargestDirs[j].Count {
+			return out.LargestDirs[i].Count > out.LargestDirs[j].Count
+		}
+		return out.LargestDirs[i].Dir < out.LargestDirs[j].Dir
+	})
+	if len(out.LargestDirs) > assessLargestDirs {
+		out.LargestDirs = out.LargestDirs[:assessLargestDirs]
+	}
+
+	if out.Files > 0 {
+		out.CodeRatio = float64(out.CodeFiles) / float64(out.Files)
+	}
+	if out.CodeRatio < out.MinCodeRatio {
+		out.Reasons = append(out.Reasons, fmt.Sprintf("%d of %d files are code (%.1f%%, minimum %.1f%%)", out.CodeFiles, out.Files, out.CodeRatio*100, out.MinCodeRatio*100))
+	}
+	if out.CodeBytes < out.MinCodeBytes {
+		out.Reasons = append(out.Reasons, fmt.Sprintf("%d bytes of code (minimum %d)", out.CodeBytes, out.MinCodeBytes))
+	}
+	out.Skip = len(out.Reasons) > 0
+	return out, nil
+}
+
+func extKind(ext string) string {
+	ext = strings.ToLower(ext)
+	switch {
+	case docsExts[ext]:
+		return artifact.FileKindDocs
+	case dataExts[ext]:
+		return artifact.FileKindData
+	}
+	if lang := scan.Language("f" + ext); lang != "" && !notCodeLanguages[lang] {
+		return artifact.FileKindCode
+	}
+	return artifact.FileKindOther
+}
+
+// AssessmentView summarizes an assessment for the client, explaining why the
+// analysis was skipped when it was.
+func AssessmentView(out artifact.RepoAssessmentOut) *workerv1.ClientView {
+	var b strings.Builder
+	if out.Skip {
+		b.WriteString("Full analysis was skipped: the repository has too little recognizable code.\n")
+		for _, r := range out.Reasons {
+			fmt.Fprintf(&b, "- %s\n", r)
+		}
+		b.WriteString("Start the run with force=true to analyze it anyway.\n")
+	} else {
+		fmt.Fprintf(&b, "The repository holds %d code files (%d bytes).\n", out.CodeFiles, out.CodeBytes)
+	}
+	fmt.Fprintf(&b, "\nFiles: %d code, %d docs, %d data, %d other.\n", out.CodeFiles, out.DocsFiles, out.DataFiles, out.OtherFiles)
+	if len(out.Exts) > 0 {
+		b.WriteString("Extensions:")
+		for i, e := range out.Exts {
+			if i == 10 {
+				fmt.Fprintf(&b, " … %d more", len(out.Exts)-i)
+				break
+			}
+			fmt.Fprintf(&b, " %s %d (%s)", e.Ext, e.Count, e.Kind)
+			if i < len(out.Exts)-1 && i < 9 {
+				b.WriteString(",")
+			}
+		}
+		b.WriteString("\n")
+	}
+	if len(out.LargestDirs) > 0 {
+		parts := make([]string, 0, len(out.LargestDirs))
+		for _, d := range out.LargestDirs {
+			parts = append(parts, fmt.Sprintf("%s (%d)", d.Dir, d.Count))
+		}
+		b.WriteString("Largest directories: " + strings.Join(parts, ", ") + "\n")
+	}
+	return &workerv1.ClientView{
+		Phase:   "repo_assessment",
+		Content: &workerv1.ClientView_LlmResponse{LlmResponse: b.String()},
+	}
+}
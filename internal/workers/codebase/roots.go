@@ -0,0 +1,64 @@
+package codebase
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
+)
+
+// ErrNoUsableRoots matches errors of phases whose configured roots all turned
+// out to be missing or unreadable.
+var ErrNoUsableRoots = errors.New("no usable source roots")
+
+// MissingRootsError reports a phase that had roots configured, none of which
+// could be scanned. Warnings explains each skipped root.
+type MissingRootsError struct {
+	Phase    string
+	Warnings []string
+}
+
+func (e *MissingRootsError) Error() string {
+	return fmt.Sprintf("%s: no usable source roots: %s", e.Phase, strings.Join(e.Warnings, "; "))
+}
+
+func (e *MissingRootsError) Is(target error) bool { return target == ErrNoUsableRoots }
+
+// usableRoots keeps the roots that are directories under sfs, resolved as
+// the phase's scan resolves them. Every other root adds a warning naming
+// field, the code_roots field it came from; blank roots are dropped
+// silently. code_roots is LLM output, so roots like "src/server" may not
+// exist at all.
+func usableRoots(sfs *safeio.SafeFS, field string, roots []string) (usable, warnings []string) {
+	for _, r := range roots {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		st, err := sfs.SafeStat(filepath.Clean(filepath.FromSlash(r)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			warnings = append(warnings, fmt.Sprintf("%s: %q does not exist, skipped", field, r))
+		case err != nil:
+			warnings = append(warnings, fmt.Sprintf("%s: %q is unreadable (%v), skipped", field, r, err))
+		case !st.IsDir():
+			warnings = append(warnings, fmt.Sprintf("%s: %q is not a directory, skipped", field, r))
+		default:
+			usable = append(usable, r)
+		}
+	}
+	return usable, warnings
+}
+
+// currentSafeFS returns the filesystem scans resolve repository paths in:
+// the one set on scan, or else safeio.Default(); nil when neither is set.
+func currentSafeFS() *safeio.SafeFS {
+	if fs := scan.CurrentSafeFS(); fs != nil {
+		return fs
+	}
+	return safeio.Default()
+}
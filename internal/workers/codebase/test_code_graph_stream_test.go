@@ -0,0 +1,83 @@
+package codebase
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	workerv1 "insightify/gen/go/worker/v1"
+	"insightify/internal/artifact"
+	"insightify/internal/common/graphdelta"
+)
+
+// flatten lists nodes and edges in a comparable, order-free form.
+func flatten(view *workerv1.GraphView) (nodes, edges []string) {
+	for _, n := range view.GetNodes() {
+		nodes = append(nodes, fmt.Sprintf("%s|%s|%s", n.Uid, n.Label, n.Description))
+	}
+	for _, e := range view.GetEdges() {
+		edges = append(edges, e.From+"->"+e.To)
+	}
+	sort.Strings(nodes)
+	sort.Strings(edges)
+	return nodes, edges
+}
+
+func TestCodeGraphDeltasRebuildFinalView(t *testing.T) {
+	requires := map[string][]string{
+		// a<->b is pruned to one direction; b, c, d form a cycle.
+		"a.go": {"b.go", "b.go"},
+		"b.go": {"a.go", "c.go"},
+		"c.go": {"d.go"},
+		"d.go": {"b.go"},
+		"e.go": {"a.go"},
+	}
+	for i := 0; i < graphDeltaBatch+5; i++ {
+		requires[fmt.Sprintf("gen/f%03d.go", i)] = []string{"a.go"}
+	}
+
+	var deltas []graphdelta.Delta
+	ctx := graphdelta.WithEmitter(context.Background(), func(d graphdelta.Delta) { deltas = append(deltas, d) })
+	out, err := CodeGraph{}.Run(ctx, artifact.CodeGraphIn{Dependencies: deps(requires)})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var (
+		view    *workerv1.GraphView
+		removed int
+	)
+	for i, d := range deltas {
+		if d.Phase != "code_graph" {
+			t.Fatalf("delta %d phase = %q", i, d.Phase)
+		}
+		if n := len(d.AddedNodes) + len(d.AddedEdges) + len(d.RemovedEdges); n > graphDeltaBatch {
+			t.Fatalf("delta %d carries %d items, want at most %d", i, n, graphDeltaBatch)
+		}
+		removed += len(d.RemovedEdges)
+		view = graphdelta.Apply(view, d)
+	}
+	if removed == 0 {
+		t.Fatalf("no pruned edge was streamed as removed")
+	}
+	if len(deltas) < 4 {
+		t.Fatalf("got %d deltas, want nodes, edges and final nodes in batches", len(deltas))
+	}
+
+	gotNodes, gotEdges := flatten(view)
+	wantNodes, wantEdges := flatten(ToGraphView(out))
+	if !reflect.DeepEqual(gotNodes, wantNodes) {
+		t.Fatalf("streamed nodes differ from the final view:\n got %v\nwant %v", gotNodes, wantNodes)
+	}
+	if !reflect.DeepEqual(gotEdges, wantEdges) {
+		t.Fatalf("streamed edges = %v, want %v", gotEdges, wantEdges)
+	}
+}
+
+func TestCodeGraphWithoutEmitterStreamsNothing(t *testing.T) {
+	if s := newGraphStream(context.Background(), []artifact.DependencyNode{{ID: 0}}); s != nil {
+		t.Fatalf("newGraphStream() = %v without an emitter, want nil", s)
+	}
+}
@@ -0,0 +1,171 @@
+package codebase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+// deps builds code_imports output where each file requires the listed paths;
+// repeating a path yields a heavier (parallel) edge.
+func deps(requires map[string][]string) []artifact.Dependencies {
+	var files []artifact.SourceDependency
+	for file, reqs := range requires {
+		sd := artifact.SourceDependency{File: artifact.NewFileRef(file)}
+		for _, r := range reqs {
+			sd.Requires = append(sd.Requires, artifact.NewFileRef(r))
+		}
+		files = append(files, sd)
+	}
+	return []artifact.Dependencies{{Files: files}}
+}
+
+func runGraph(t *testing.T, requires map[string][]string, pruning artifact.GraphPruning) artifact.CodeGraphOut {
+	t.Helper()
+	out, err := CodeGraph{}.Run(context.Background(), artifact.CodeGraphIn{
+		Dependencies: deps(requires),
+		Pruning:      pruning,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	return out
+}
+
+func TestCodeGraphTwoCyclePolicies(t *testing.T) {
+	// a.go requires b.go twice (edge b->a, weight 2); b.go requires a.go once (edge a->b, weight 1).
+	requires := map[string][]string{
+		"a.go": {"b.go", "b.go"},
+		"b.go": {"a.go"},
+	}
+
+	out := runGraph(t, requires, artifact.GraphPruning{})
+	if len(out.Cycles) != 0 {
+		t.Fatalf("keep_stronger should remove the cycle, got %+v", out.Cycles)
+	}
+	if want := [][]int{nil, {0}}; !reflect.DeepEqual(out.Graph.Adjacency, want) {
+		t.Fatalf("keep_stronger adjacency = %v, want %v", out.Graph.Adjacency, want)
+	}
+	if want := []artifact.WeightedEdge{{From: 1, To: 0, Weight: 2}}; !reflect.DeepEqual(out.Graph.Edges, want) {
+		t.Fatalf("keep_stronger edges = %+v, want %+v", out.Graph.Edges, want)
+	}
+
+	out = runGraph(t, requires, artifact.GraphPruning{Strategy: artifact.PruneDropBoth})
+	if want := [][]int{nil, nil}; !reflect.DeepEqual(out.Graph.Adjacency, want) {
+		t.Fatalf("drop_both adjacency = %v, want %v", out.Graph.Adjacency, want)
+	}
+
+	out = runGraph(t, requires, artifact.GraphPruning{Strategy: artifact.PruneKeepBoth})
+	if len(out.Cycles) != 1 {
+		t.Fatalf("keep_both should report one cycle, got %+v", out.Cycles)
+	}
+	c := out.Cycles[0]
+	if !reflect.DeepEqual(c.Members, []int{0, 1}) || len(c.Edges) != 2 {
+		t.Fatalf("unexpected cycle report: %+v", c)
+	}
+	if want := (artifact.WeightedEdge{From: 0, To: 1, Weight: 1}); c.SuggestedCut != want {
+		t.Fatalf("suggested cut = %+v, want %+v", c.SuggestedCut, want)
+	}
+}
+
+func TestCodeGraphKeepStrongerTieBreak(t *testing.T) {
+	cases := []struct {
+		name     string
+		requires map[string][]string
+		want     [][]int
+	}{
+		{
+			// a->b and b->a, one hit each: the edge from a.go wins by path.
+			name:     "symmetric pair",
+			requires: map[string][]string{"a.go": {"b.go"}, "b.go": {"a.go"}},
+			want:     [][]int{{1}, nil},
+		},
+		{
+			// c->a gives a.go two in-edges to b.go's one, so b->a wins.
+			name:     "more in-edges",
+			requires: map[string][]string{"a.go": {"b.go", "c.go"}, "b.go": {"a.go"}},
+			want:     [][]int{nil, {0}, {0}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Map iteration differs between runs; the choice must not.
+			for i := 0; i < 20; i++ {
+				out := runGraph(t, tc.requires, artifact.GraphPruning{})
+				if !reflect.DeepEqual(out.Graph.Adjacency, tc.want) {
+					t.Fatalf("run %d adjacency = %v, want %v", i, out.Graph.Adjacency, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCodeGraphThreeCycleReport(t *testing.T) {
+	// Edges: a->b (2), b->c (1), c->a (3).
+	out := runGraph(t, map[string][]string{
+		"b.go": {"a.go", "a.go"},
+		"c.go": {"b.go"},
+		"a.go": {"c.go", "c.go", "c.go"},
+	}, artifact.GraphPruning{})
+
+	if len(out.Cycles) != 1 {
+		t.Fatalf("expected one cycle, got %+v", out.Cycles)
+	}
+	c := out.Cycles[0]
+	if !reflect.DeepEqual(c.Members, []int{0, 1, 2}) {
+		t.Fatalf("members = %v", c.Members)
+	}
+	wantEdges := []artifact.WeightedEdge{{From: 0, To: 1, Weight: 2}, {From: 1, To: 2, Weight: 1}, {From: 2, To: 0, Weight: 3}}
+	if !reflect.DeepEqual(c.Edges, wantEdges) {
+		t.Fatalf("edges = %+v, want %+v", c.Edges, wantEdges)
+	}
+	if c.SuggestedCut != wantEdges[1] {
+		t.Fatalf("suggested cut = %+v, want %+v", c.SuggestedCut, wantEdges[1])
+	}
+}
+
+func TestCodeGraphMinWeightDropsLightParallelEdges(t *testing.T) {
+	// a.go requires b.go three times, c.go once: b->a (3), c->a (1).
+	out := runGraph(t, map[string][]string{
+		"a.go": {"b.go", "b.go", "b.go", "c.go"},
+	}, artifact.GraphPruning{Strategy: artifact.PruneMinWeight, MinWeight: 2})
+	if want := [][]int{nil, {0}, nil}; !reflect.DeepEqual(out.Graph.Adjacency, want) {
+		t.Fatalf("adjacency = %v, want %v", out.Graph.Adjacency, want)
+	}
+
+	if _, err := (CodeGraph{}).Run(context.Background(), artifact.CodeGraphIn{
+		Pruning: artifact.GraphPruning{Strategy: "bogus"},
+	}); err == nil {
+		t.Fatalf("expected error for unknown strategy")
+	}
+}
+
+func TestCodeTasksCollapsesCycles(t *testing.T) {
+	graph := runGraph(t, map[string][]string{
+		"b.go": {"a.go"},
+		"c.go": {"b.go"},
+		"a.go": {"c.go"},
+		"d.go": {"c.go"},
+	}, artifact.GraphPruning{})
+
+	in := artifact.CodeTasksIn{Graph: graph.Graph, CapPerChunk: 100, Cycles: graph.Cycles}
+	out, err := CodeTasks{}.Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// a, b, c form one component; each member must precede d and no internal edges remain.
+	want := [][]int{{3}, {3}, {3}, nil}
+	if !reflect.DeepEqual(out.Adjacency, want) {
+		t.Fatalf("adjacency = %v, want %v", out.Adjacency, want)
+	}
+
+	in.FailOnCycle = true
+	_, err = CodeTasks{}.Run(context.Background(), in)
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) || len(cycleErr.Cycles) != 1 {
+		t.Fatalf("expected CycleError with report, got %v", err)
+	}
+}
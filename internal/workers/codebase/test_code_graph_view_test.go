@@ -0,0 +1,99 @@
+package codebase
+
+import (
+	"strings"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+func TestToGraphViewMapsNodesAndEdges(t *testing.T) {
+	graph := mermaidGraph(
+		[]string{"cmd/main.go", "internal/a.go", "internal/b.go"},
+		[][2]int{{0, 1}, {1, 2}, {2, 1}, {0, 1}, {0, 9}},
+		[]int{1, 2},
+	)
+	view := ToGraphView(graph)
+
+	if len(view.Nodes) != 3 {
+		t.Fatalf("nodes = %v, want 3", view.Nodes)
+	}
+	uid := map[string]string{}
+	for _, n := range view.Nodes {
+		if n.Uid == "" || strings.HasPrefix(n.Uid, "file:") {
+			t.Fatalf("node %q has no generated UID: %q", n.Label, n.Uid)
+		}
+		uid[n.Label] = n.Uid
+	}
+	main, a, b := uid["cmd/main.go"], uid["internal/a.go"], uid["internal/b.go"]
+	// Duplicates and edges to unknown nodes are dropped; order is by path.
+	want := [][2]string{{main, a}, {a, b}, {b, a}}
+	if len(view.Edges) != len(want) {
+		t.Fatalf("edges = %v, want %d", view.Edges, len(want))
+	}
+	for i, e := range view.Edges {
+		if e.From != want[i][0] || e.To != want[i][1] {
+			t.Fatalf("edge %d = %s->%s, want %s->%s", i, e.From, e.To, want[i][0], want[i][1])
+		}
+	}
+	for _, n := range view.Nodes {
+		switch n.Label {
+		case "cmd/main.go":
+			if !strings.Contains(n.Description, "Imports: 1, imported by: 0") || strings.Contains(n.Description, "Cycle") {
+				t.Fatalf("main description = %q", n.Description)
+			}
+		case "internal/a.go":
+			if !strings.Contains(n.Description, "Extension: go") || !strings.Contains(n.Description, "Cycle with: internal/b.go") {
+				t.Fatalf("a description = %q", n.Description)
+			}
+		}
+	}
+
+	cv := CodeGraphView(graph)
+	if cv.Phase != "code_graph" || len(cv.GetGraph().GetNodes()) != 3 {
+		t.Fatalf("client view = %v", cv)
+	}
+}
+
+func TestToGraphViewUIDsAreStableAcrossRuns(t *testing.T) {
+	first := ToGraphView(mermaidGraph(
+		[]string{"a.go", "b.go", "c.go"},
+		[][2]int{{0, 1}, {1, 2}},
+	))
+	// The next scan found the files in another order and one more file.
+	second := ToGraphView(mermaidGraph(
+		[]string{"c.go", "new.go", "b.go", "a.go"},
+		[][2]int{{3, 2}, {2, 0}, {1, 3}},
+	))
+
+	byLabel := map[string]string{}
+	for _, n := range second.Nodes {
+		byLabel[n.Label] = n.Uid
+	}
+	for _, n := range first.Nodes {
+		if byLabel[n.Label] != n.Uid {
+			t.Fatalf("%s: uid %q, then %q", n.Label, n.Uid, byLabel[n.Label])
+		}
+	}
+	again := ToGraphView(mermaidGraph(
+		[]string{"a.go", "b.go", "c.go"},
+		[][2]int{{0, 1}, {1, 2}},
+	))
+	for i, n := range again.Nodes {
+		if n.Uid != first.Nodes[i].Uid {
+			t.Fatalf("rerun changed node %d: %q vs %q", i, n.Uid, first.Nodes[i].Uid)
+		}
+	}
+	for i, e := range again.Edges {
+		if e.From != first.Edges[i].From || e.To != first.Edges[i].To {
+			t.Fatalf("rerun changed edge %d: %v vs %v", i, e, first.Edges[i])
+		}
+	}
+}
+
+func TestToGraphViewEmptyGraph(t *testing.T) {
+	view := ToGraphView(artifact.CodeGraphOut{})
+	if len(view.Nodes) != 0 || len(view.Edges) != 0 {
+		t.Fatalf("view = %v, want empty", view)
+	}
+}
@@ -0,0 +1,104 @@
+package codebase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+)
+
+func TestCodeImportEdgesFromRanges(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"src/app.ts": `import { a } from "./utils/a";
+import b from '@org/pkg/utils/b';
+// import c from "./commented";
+/* import d from "./block";
+   import e from "./block2"; */
+const msg = "import f from './in-string'";
+import { a as a2 } from "./utils/a";
+`,
+		"src/utils/a.ts": "export const a = 1;\n",
+		"main.c": `#include <stdio.h>
+#include "util.h"
+// #include "nope.h"
+`,
+		"util.h": "int util(void);\n",
+		"main.go": `package main
+
+import (
+	"fmt"
+	// "ignored"
+	"example.com/mod/pkg"
+)
+`,
+	}
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs, err := safeio.NewSafeFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	families := []artifact.FamilySpec{
+		{Family: "js", Key: "js", Spec: artifact.ExtractorSpec{
+			Exts:                []string{".ts", ".js"},
+			Rules:               artifact.Rules{Keywords: []string{"import", "from"}},
+			CommentLinePattern:  []string{"//"},
+			CommentBlockPattern: []string{"/*", "*/"},
+		}},
+		{Family: "c", Key: "c", Spec: artifact.ExtractorSpec{
+			Exts:               []string{".c", ".h"},
+			Rules:              artifact.Rules{Keywords: []string{"#include"}},
+			CommentLinePattern: []string{"//"},
+		}},
+		{Family: "go", Key: "go", Spec: artifact.ExtractorSpec{
+			Exts:               []string{".go"},
+			Rules:              artifact.Rules{Keywords: []string{"import"}},
+			CommentLinePattern: []string{"//"},
+		}},
+	}
+	out, err := CodeImportEdges{}.Run(context.Background(), artifact.CodeImportEdgesIn{
+		RepoFS:   fs,
+		Families: families,
+		Ranges: []artifact.ImportStatementRange{
+			{FilePath: "src/app.ts", StartLine: 1, EndLine: 7},
+			{FilePath: "main.c", StartLine: 1},
+			{FilePath: "main.go", StartLine: 3, EndLine: 7},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	type edge struct {
+		From, Target, Module, Kind string
+		Lines                      []int
+	}
+	var got []edge
+	for _, e := range out.Edges {
+		got = append(got, edge{e.From, e.Target, e.Module.Normalized, e.Module.Kind, e.Lines})
+	}
+	want := []edge{
+		{"main.c", "", "stdio.h", artifact.ModuleKindSystemHeader, []int{1}},
+		{"main.c", "util.h", "util.h", artifact.ModuleKindPath, []int{2}},
+		{"main.go", "", "example.com/mod/pkg", artifact.ModuleKindModule, []int{6}},
+		{"main.go", "", "fmt", artifact.ModuleKindPackage, []int{4}},
+		{"src/app.ts", "", "@org/pkg/utils/b", artifact.ModuleKindPackage, []int{2}},
+		{"src/app.ts", "src/utils/a.ts", "./utils/a", artifact.ModuleKindPath, []int{1, 7}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("edges mismatch\n got: %+v\nwant: %+v", got, want)
+	}
+}
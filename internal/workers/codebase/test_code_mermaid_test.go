@@ -0,0 +1,96 @@
+package codebase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+func mermaidGraph(paths []string, edges [][2]int, cycles ...[]int) artifact.CodeGraphOut {
+	out := artifact.CodeGraphOut{}
+	for i, p := range paths {
+		out.Graph.Nodes = append(out.Graph.Nodes, artifact.DependencyNode{ID: i, File: artifact.NewFileRef(p)})
+	}
+	for _, e := range edges {
+		out.Graph.Edges = append(out.Graph.Edges, artifact.WeightedEdge{From: e[0], To: e[1], Weight: 1})
+	}
+	for _, c := range cycles {
+		out.Cycles = append(out.Cycles, artifact.CycleReport{Members: c})
+	}
+	return out
+}
+
+func TestCodeMermaidGroupsLayersAndMarksCycles(t *testing.T) {
+	graph := mermaidGraph(
+		[]string{"cmd/main.go", "internal/a.go", "internal/b.go", "web/<App>.tsx", "main.go"},
+		[][2]int{{0, 1}, {1, 2}, {2, 1}, {3, 3}, {4, 0}},
+		[]int{1, 2},
+	)
+	out, err := CodeMermaid{}.Run(context.Background(), artifact.CodeMermaidIn{Graph: graph})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	d := out.Diagram
+	if !strings.HasPrefix(d, "flowchart LR\n") {
+		t.Fatalf("diagram does not start with a flowchart header:\n%s", d)
+	}
+	for _, want := range []string{
+		`subgraph layer0["(root)"]`,
+		`subgraph layer1["cmd"]`,
+		`subgraph layer2["internal"]`,
+		`subgraph layer3["web"]`,
+		`n1["a.go"]:::ext_go`,
+		`n3["#lt;App#gt;.tsx"]:::ext_tsx`,
+		"n0 --> n1\n",
+		"n4 --> n0\n",
+		"n1 -.->|cycle| n2\n",
+		"n2 -.->|cycle| n1\n",
+		"n3 -.->|cycle| n3\n",
+		"classDef ext_go ",
+		"classDef ext_tsx ",
+	} {
+		if !strings.Contains(d, want) {
+			t.Fatalf("diagram lacks %q:\n%s", want, d)
+		}
+	}
+	if strings.Count(d, "subgraph ") != strings.Count(d, "  end\n") {
+		t.Fatalf("unbalanced subgraphs:\n%s", d)
+	}
+	if out.Nodes != 5 || out.Edges != 5 || out.Layers != 4 || out.Omitted != 0 {
+		t.Fatalf("counts = %+v", out)
+	}
+}
+
+func TestCodeMermaidUsesPackagesAndCapsNodes(t *testing.T) {
+	graph := mermaidGraph(
+		[]string{"packages/ui/button.ts", "packages/ui/icon.ts", "packages/api/server.ts", "README.md"},
+		[][2]int{{0, 1}, {2, 0}, {2, 1}},
+	)
+	out, err := CodeMermaid{}.Run(context.Background(), artifact.CodeMermaidIn{
+		Graph: graph,
+		Packages: []artifact.WorkspacePackage{
+			{Name: `@acme/ui "core"`, Path: "packages/ui"},
+			{Name: "@acme/api", Path: "packages/api"},
+		},
+		MaxNodes: 3,
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	d := out.Diagram
+	for _, want := range []string{
+		`subgraph layer0["@acme/api"]`,
+		`subgraph layer1["@acme/ui #quot;core#quot;"]`,
+		`n0["button.ts"]:::ext_ts`,
+		"%% 1 less connected files omitted",
+	} {
+		if !strings.Contains(d, want) {
+			t.Fatalf("diagram lacks %q:\n%s", want, d)
+		}
+	}
+	if strings.Contains(d, "README") || out.Omitted != 1 || out.Nodes != 3 {
+		t.Fatalf("the unconnected file should be dropped: %+v\n%s", out, d)
+	}
+}
@@ -0,0 +1,132 @@
+package codebase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+type scriptedLLM struct {
+	responses []json.RawMessage
+	hints     []string
+	inputs    []any
+}
+
+func (f *scriptedLLM) Name() string                { return "scripted" }
+func (f *scriptedLLM) Close() error                { return nil }
+func (f *scriptedLLM) CountTokens(text string) int { return len(text) }
+func (f *scriptedLLM) TokenCapacity() int          { return 1000 }
+func (f *scriptedLLM) GenerateJSON(ctx context.Context, prompt string, input any) (json.RawMessage, error) {
+	hint := ""
+	if m, ok := input.(map[string]any); ok {
+		hint, _ = m["regen_hint"].(string)
+	}
+	f.hints = append(f.hints, hint)
+	f.inputs = append(f.inputs, input)
+	if len(f.responses) == 0 {
+		return nil, nil
+	}
+	out := f.responses[0]
+	f.responses = f.responses[1:]
+	return out, nil
+}
+func (f *scriptedLLM) GenerateJSONStream(ctx context.Context, prompt string, input any, onChunk func(chunk string)) (json.RawMessage, error) {
+	return f.GenerateJSON(ctx, prompt, input)
+}
+
+var codeSpecsTestCounts = []artifact.ExtCount{{Ext: ".go", Count: 10}}
+
+const (
+	brokenGoSpec = `{"familyKeys":{"go":["go"]},"specs":{"go":{"exts":[".go",".gox"],"rules":{"keywords":["import"]}}}}`
+	fixedGoSpec  = `{"familyKeys":{"go":["go"]},"specs":{"go":{"exts":[".go"],"rules":{"keywords":["import"]}}}}`
+)
+
+func TestCodeSpecsRetriesWithTargetedHint(t *testing.T) {
+	llm := &scriptedLLM{responses: []json.RawMessage{
+		json.RawMessage(brokenGoSpec),
+		json.RawMessage(fixedGoSpec),
+	}}
+	x := &CodeSpecs{LLM: llm}
+	out, err := x.Run(context.Background(), artifact.CodeSpecsIn{ExtCounts: codeSpecsTestCounts})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(llm.hints) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(llm.hints))
+	}
+	if llm.hints[0] != "" {
+		t.Fatalf("first attempt should have no hint, got %q", llm.hints[0])
+	}
+	hint := llm.hints[1]
+	for _, want := range []string{"rule=ext_in_counts", "spec=go", `".gox"`} {
+		if !strings.Contains(hint, want) {
+			t.Fatalf("hint %q missing %q", hint, want)
+		}
+	}
+	if len(out.Families) != 1 || out.Families[0].Key != "go" {
+		t.Fatalf("unexpected families: %+v", out.Families)
+	}
+}
+
+func TestCodeSpecsStopsAfterMaxRetries(t *testing.T) {
+	llm := &scriptedLLM{responses: []json.RawMessage{
+		json.RawMessage(brokenGoSpec),
+		json.RawMessage(brokenGoSpec),
+		json.RawMessage(fixedGoSpec),
+	}}
+	x := &CodeSpecs{LLM: llm, MaxRetries: 1}
+	_, err := x.Run(context.Background(), artifact.CodeSpecsIn{ExtCounts: codeSpecsTestCounts})
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if !strings.Contains(err.Error(), "ext_in_counts") {
+		t.Fatalf("error should carry rule detail: %v", err)
+	}
+	if len(llm.hints) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(llm.hints))
+	}
+}
+
+func TestCodeSpecsNoRetryOnValidOutput(t *testing.T) {
+	llm := &scriptedLLM{responses: []json.RawMessage{json.RawMessage(fixedGoSpec)}}
+	x := &CodeSpecs{LLM: llm}
+	if _, err := x.Run(context.Background(), artifact.CodeSpecsIn{ExtCounts: codeSpecsTestCounts}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(llm.hints) != 1 {
+		t.Fatalf("expected single attempt, got %d", len(llm.hints))
+	}
+}
+
+func TestCodeSpecsRetriesOnSchemaViolation(t *testing.T) {
+	llm := &scriptedLLM{responses: []json.RawMessage{
+		json.RawMessage(`{"familyKeys":{"go":"go"},"specs":{"go":{"exts":[".go"],"rules":{"keywords":["import"]}}}}`),
+		json.RawMessage(fixedGoSpec),
+	}}
+	x := &CodeSpecs{LLM: llm}
+	if _, err := x.Run(context.Background(), artifact.CodeSpecsIn{ExtCounts: codeSpecsTestCounts}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(llm.hints) != 2 || !strings.Contains(llm.hints[1], "rule=schema") || !strings.Contains(llm.hints[1], "/familyKeys/go") {
+		t.Fatalf("unexpected hints %q", llm.hints)
+	}
+}
+
+func TestCodeSpecsUsesExtReport(t *testing.T) {
+	llm := &scriptedLLM{responses: []json.RawMessage{json.RawMessage(fixedGoSpec)}}
+	x := &CodeSpecs{LLM: llm}
+	report := &artifact.CodeStatsOut{Exts: []artifact.ExtStats{{Ext: ".go", Count: 3, Lines: []string{`import "fmt"`}}}}
+	if _, err := x.Run(context.Background(), artifact.CodeSpecsIn{ExtReport: report}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	input, _ := llm.inputs[0].(map[string]any)
+	if counts, _ := input["ext_counts"].([]artifact.ExtCount); len(counts) != 1 || counts[0] != (artifact.ExtCount{Ext: ".go", Count: 3}) {
+		t.Fatalf("ext_counts = %v, want them taken from the report", input["ext_counts"])
+	}
+	if exts, _ := input["ext_report"].([]artifact.ExtStats); len(exts) != 1 || exts[0].Lines[0] != `import "fmt"` {
+		t.Fatalf("ext_report = %v, want the report passed to the LLM", input["ext_report"])
+	}
+}
@@ -0,0 +1,109 @@
+package codebase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
+)
+
+func TestCodeStatsReport(t *testing.T) {
+	repos := t.TempDir()
+	repo := filepath.Join(repos, "demo")
+	files := map[string]string{
+		"src/app.go":        "package app\n\n// App wires things.\nimport \"demo/lib\"\n\nfunc Run() { lib.Go() }\n",
+		"src/big.go":        "package app\n\nimport (\n\t\"fmt\"\n)\n\nfunc Big() {\n\tfmt.Println(\"" + strings.Repeat("b", 300) + "\")\n\tfmt.Println(\"ok\")\n}\n",
+		"src/gen.pb.go":     "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage app\n" + strings.Repeat("var x = 1\n", 200),
+		"tools/helper.go":   "package tools\n\nfunc Help() {}\n",
+		"web/app.min.js":    strings.Repeat("var a=1;", 400),
+		"web/main.js":       "import x from './x'\n",
+		"vendor/dep/dep.go": "package dep\n",
+	}
+	for name, body := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reposFS, err := safeio.NewSafeFS(repos)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	prevFS, prevDir := scan.CurrentSafeFS(), scan.ReposDir()
+	scan.SetReposDir(repos)
+	scan.SetSafeFS(reposFS)
+	t.Cleanup(func() {
+		scan.SetSafeFS(prevFS)
+		scan.SetReposDir(prevDir)
+	})
+	repoFS, err := safeio.NewSafeFS(repo)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+
+	in := artifact.CodeStatsIn{
+		Repo:   "demo",
+		RepoFS: repoFS,
+		Roots:  artifact.CodeRootsOut{MainSourceRoots: []string{"src"}, LibraryRoots: []string{"vendor"}},
+	}
+	out, err := CodeStats{SamplesPerExt: 2, Lines: 3}.Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	byExt := map[string]artifact.ExtStats{}
+	for _, e := range out.Exts {
+		byExt[e.Ext] = e
+	}
+
+	goStats := byExt[".go"]
+	if goStats.Count != 4 || goStats.Generated != 1 {
+		t.Fatalf(".go count = %d, generated = %d; want 4 files without vendor, 1 generated", goStats.Count, goStats.Generated)
+	}
+	if want := []string{"src/app.go", "src/big.go"}; !slices.Equal(goStats.Samples, want) {
+		t.Fatalf(".go samples = %v, want main source files %v", goStats.Samples, want)
+	}
+	if goStats.TopDirs[0] != (artifact.DirCount{Dir: "src", Count: 3}) {
+		t.Fatalf(".go top dirs = %v, want src first", goStats.TopDirs)
+	}
+	if goStats.HeadPath != "src/big.go" || !strings.HasPrefix(goStats.Head, "package app") {
+		t.Fatalf(".go head = %s %q, want the largest non-generated file", goStats.HeadPath, goStats.Head)
+	}
+	if len(goStats.Lines) != 3 {
+		t.Fatalf(".go lines = %q, want 3", goStats.Lines)
+	}
+	for _, l := range goStats.Lines {
+		if strings.TrimSpace(l) == "" || len(l) > statsMaxLineLen || strings.HasPrefix(strings.TrimSpace(l), "//") {
+			t.Fatalf(".go line %q should have been filtered", l)
+		}
+	}
+
+	jsStats := byExt[".js"]
+	if jsStats.Count != 2 || jsStats.Generated != 1 || !slices.Equal(jsStats.Samples, []string{"web/main.js"}) {
+		t.Fatalf(".js = %+v, want the minified file counted but not sampled", jsStats)
+	}
+
+	again, err := CodeStats{SamplesPerExt: 2, Lines: 3}.Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run again: %v", err)
+	}
+	if !slices.Equal(again.Exts[0].Lines, out.Exts[0].Lines) {
+		t.Fatalf("lines differ between runs: %q vs %q", again.Exts[0].Lines, out.Exts[0].Lines)
+	}
+}
+
+func TestCodeLinesFiltersComments(t *testing.T) {
+	src := "#include <stdio.h>\n# comment\n  // note\n\n * doc\nint x;\n" + strings.Repeat("y", 201) + "\n"
+	got := codeLines([]byte(src))
+	if want := []string{"#include <stdio.h>", "int x;"}; !slices.Equal(got, want) {
+		t.Fatalf("codeLines = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package codebase
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"insightify/internal/common/safeio"
+)
+
+func TestCodeSymbolsReadFileLimits(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("small.go", 10)
+	write("big.go", 100)
+	write("huge.go", 1000)
+	fs, err := safeio.NewSafeFS(dir)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	p := CodeSymbols{MaxFileBytes: 50, SkipFileBytes: 500}
+
+	got, err := p.readFile(fs, "small.go")
+	if err != nil || got != strings.Repeat("x", 10) {
+		t.Fatalf("small.go = %q, %v; want it whole", got, err)
+	}
+
+	got, err = p.readFile(fs, "big.go")
+	if err != nil {
+		t.Fatalf("big.go: %v", err)
+	}
+	if want := strings.Repeat("x", 50) + fmt.Sprintf(truncatedFileMarker, 50); got != want {
+		t.Fatalf("big.go = %q, want %q", got, want)
+	}
+
+	if _, err := p.readFile(fs, "huge.go"); !errors.Is(err, safeio.ErrFileTooLarge) {
+		t.Fatalf("huge.go err = %v, want ErrFileTooLarge", err)
+	}
+}
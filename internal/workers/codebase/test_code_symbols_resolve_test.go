@@ -0,0 +1,135 @@
+package codebase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+)
+
+// codeSymbolsResolveFixture puts every file in its own chunk: a/util.go runs
+// first (it has a dependent), then b/main.go, c/parse.go and d/parse.go.
+func codeSymbolsResolveFixture(t *testing.T) artifact.CodeSymbolsIn {
+	t.Helper()
+	root := t.TempDir()
+	paths := []string{"a/util.go", "b/main.go", "c/parse.go", "d/parse.go"}
+	nodes := make([]artifact.CodeTasksNode, len(paths))
+	for i, p := range paths {
+		full := filepath.Join(root, p)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = artifact.CodeTasksNode{ID: i, Path: p, Weight: 600}
+	}
+	fs, err := safeio.NewSafeFS(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return artifact.CodeSymbolsIn{
+		Repo:   "fixture",
+		RepoFS: fs,
+		Tasks: artifact.CodeTasksOut{
+			Nodes:     nodes,
+			Adjacency: [][]int{{1}, {}, {}, {}}, // b imports a
+		},
+		Resolution: DefaultSymbolResolution(),
+	}
+}
+
+func codeSymbolsResolveResponses() []json.RawMessage {
+	return []json.RawMessage{
+		json.RawMessage(`{"files":[{"path":"a/util.go","identifiers":[{"name":"Helper","summary":"formats output"}]}]}`),
+		json.RawMessage(`{"files":[{"path":"b/main.go","identifiers":[
+			{"name":"main","requires":[
+				{"path":"a","identifier":"Helper","origin":"user"},
+				{"path":"","identifier":"Parse","origin":"user"},
+				{"path":"","identifier":"run","origin":"user"},
+				{"path":"","identifier":"Missing","origin":"user"},
+				{"path":"fmt","identifier":"Println","origin":"stdlib"}
+			]},
+			{"name":"run"}
+		]}]}`),
+		json.RawMessage(`{"files":[{"path":"c/parse.go","identifiers":[{"name":"Parse","summary":"parses config"},{"name":"Helper"}]}]}`),
+		json.RawMessage(`{"files":[{"path":"d/parse.go","identifiers":[{"name":"Parse","summary":"parses flags"}]}]}`),
+		json.RawMessage(`{"resolutions":[{"ref":0,"path":"d/parse.go"}]}`),
+	}
+}
+
+func mainRequirements(t *testing.T, out artifact.CodeSymbolsOut) map[string]artifact.IdentifierRequirement {
+	t.Helper()
+	for _, f := range out.Files {
+		if f.Path != "b/main.go" {
+			continue
+		}
+		for _, sig := range f.Identifiers {
+			if sig.Name != "main" {
+				continue
+			}
+			reqs := map[string]artifact.IdentifierRequirement{}
+			for _, r := range sig.Requires {
+				reqs[r.Identifier] = r
+			}
+			return reqs
+		}
+	}
+	t.Fatalf("main identifier not found in %+v", out.Files)
+	return nil
+}
+
+func TestCodeSymbolsResolvesCrossChunkRequirements(t *testing.T) {
+	llm := &scriptedLLM{responses: codeSymbolsResolveResponses()}
+	out, err := CodeSymbols{LLM: llm}.Run(context.Background(), codeSymbolsResolveFixture(t))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(llm.responses) != 0 {
+		t.Fatalf("expected every scripted response to be used, %d left", len(llm.responses))
+	}
+
+	reqs := mainRequirements(t, out)
+	want := map[string]artifact.IdentifierRequirement{
+		// Two definitions; the import edge b->a and the "a" hint pick a/util.go.
+		"Helper":  {Path: "a/util.go", Identifier: "Helper", Origin: "user", Resolution: artifact.ResolvedCrossChunk},
+		"Parse":   {Path: "d/parse.go", Identifier: "Parse", Origin: "user", Resolution: artifact.ResolvedViaLLM},
+		"run":     {Path: "b/main.go", Identifier: "run", Origin: "user", Resolution: artifact.ResolvedLocally},
+		"Missing": {Path: "", Identifier: "Missing", Origin: "user", Resolution: artifact.StillUnresolved},
+		"Println": {Path: "fmt", Identifier: "Println", Origin: "stdlib"},
+	}
+	for name, w := range want {
+		if got := reqs[name]; got != w {
+			t.Fatalf("requirement %s = %+v, want %+v", name, got, w)
+		}
+	}
+	wantStats := artifact.ResolutionStats{ResolvedLocally: 1, ResolvedCrossChunk: 1, ResolvedViaLLM: 1, StillUnresolved: 1}
+	if out.Resolution != wantStats {
+		t.Fatalf("stats = %+v, want %+v", out.Resolution, wantStats)
+	}
+}
+
+func TestCodeSymbolsResolveSkipLLMLeavesAmbiguousUnresolved(t *testing.T) {
+	responses := codeSymbolsResolveResponses()
+	llm := &scriptedLLM{responses: responses[:4]}
+	in := codeSymbolsResolveFixture(t)
+	in.Resolution.SkipLLM = true
+
+	out, err := CodeSymbols{LLM: llm}.Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(llm.hints) != 4 {
+		t.Fatalf("expected only per-chunk calls, got %d", len(llm.hints))
+	}
+	if got := mainRequirements(t, out)["Parse"]; got.Resolution != artifact.StillUnresolved {
+		t.Fatalf("Parse should stay unresolved without the LLM pass: %+v", got)
+	}
+	if out.Resolution.StillUnresolved != 2 {
+		t.Fatalf("expected 2 unresolved, got %+v", out.Resolution)
+	}
+}
@@ -0,0 +1,64 @@
+package codebase
+
+import (
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+func TestNormalizeModule(t *testing.T) {
+	hints := artifact.NormalizeHints{Alias: []artifact.AliasPair{
+		{Original: "@app/", Normalized: "src/app/"},
+	}}
+	tests := []struct {
+		raw  string
+		want artifact.ModuleInfo
+	}{
+		{
+			raw: "@org/pkg/utils/a",
+			want: artifact.ModuleInfo{Normalized: "@org/pkg/utils/a", Kind: artifact.ModuleKindPackage,
+				Scope: "@org", Package: "pkg", Subpath: "utils/a"},
+		},
+		{
+			raw: "./utils/a.ts",
+			want: artifact.ModuleInfo{Normalized: "./utils/a.ts", Kind: artifact.ModuleKindPath,
+				FolderPath: "./utils", FileName: "a", FileExt: ".ts"},
+		},
+		{
+			raw: "<stdio.h>",
+			want: artifact.ModuleInfo{Normalized: "stdio.h", Kind: artifact.ModuleKindSystemHeader,
+				FileName: "stdio", FileExt: ".h"},
+		},
+		{
+			raw:  `"lodash/fp"`,
+			want: artifact.ModuleInfo{Normalized: "lodash/fp", Kind: artifact.ModuleKindPackage, Package: "lodash", Subpath: "fp"},
+		},
+		{
+			raw:  "github.com/org/repo/pkg",
+			want: artifact.ModuleInfo{Normalized: "github.com/org/repo/pkg", Kind: artifact.ModuleKindModule},
+		},
+		{
+			raw:  "os.path",
+			want: artifact.ModuleInfo{Normalized: "os.path", Kind: artifact.ModuleKindModule},
+		},
+		{
+			raw: "@app/views/home.vue",
+			want: artifact.ModuleInfo{Normalized: "src/app/views/home.vue", Kind: artifact.ModuleKindPath,
+				FolderPath: "src/app/views", FileName: "home", FileExt: ".vue", Alias: "@app/"},
+		},
+		{
+			raw: "@/components/Button",
+			want: artifact.ModuleInfo{Normalized: "@/components/Button", Kind: artifact.ModuleKindPath,
+				FolderPath: "@/components", FileName: "Button", Alias: "@/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			tt.want.Raw = tt.raw
+			got := NormalizeModule(tt.raw, hints)
+			if got != tt.want {
+				t.Fatalf("NormalizeModule(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package codebase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+	"insightify/internal/common/scan"
+)
+
+// writeRootsFixture writes a repo with the roots src/api and src/web plus a
+// file outside them, and points scan at it.
+func writeRootsFixture(t *testing.T) *safeio.SafeFS {
+	t.Helper()
+	repos := t.TempDir()
+	repo := filepath.Join(repos, "demo")
+	files := map[string]string{
+		"src/api/handler.go": "package api\n\nimport \"demo/src/web/render\"\n",
+		"src/web/render.go":  "package web\n",
+		"tools/gen.go":       "package tools\n",
+	}
+	for name, body := range files {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repoFS, err := safeio.NewSafeFS(repo)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	prevFS, prevDir := scan.CurrentSafeFS(), scan.ReposDir()
+	scan.SetReposDir(repos)
+	scan.SetSafeFS(repoFS)
+	t.Cleanup(func() {
+		scan.SetSafeFS(prevFS)
+		scan.SetReposDir(prevDir)
+	})
+	return repoFS
+}
+
+func TestCodeImportsSkipsMissingRoots(t *testing.T) {
+	writeRootsFixture(t)
+	family := artifact.FamilySpec{Family: "go", Key: "go", Spec: artifact.ExtractorSpec{Exts: []string{".go"}}}
+	in := artifact.CodeImportsIn{
+		Repo:     "demo",
+		Roots:    artifact.CodeRootsOut{MainSourceRoots: []string{"src/api", "src/server", "src/web"}},
+		Families: []artifact.FamilySpec{family},
+	}
+	out, err := CodeImports{}.Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(out.Warnings) != 1 || !strings.Contains(out.Warnings[0], `"src/server" does not exist`) {
+		t.Fatalf("warnings = %v, want src/server reported missing", out.Warnings)
+	}
+	if len(out.PossibleDependencies) != 1 {
+		t.Fatalf("dependencies = %+v, want one family", out.PossibleDependencies)
+	}
+	dep := out.PossibleDependencies[0]
+	if !slices.Equal(dep.Roots, []string{"src/api", "src/web"}) {
+		t.Fatalf("roots = %v, want the two existing roots", dep.Roots)
+	}
+	var scanned []string
+	for _, f := range dep.Files {
+		scanned = append(scanned, f.File.Path)
+	}
+	slices.Sort(scanned)
+	if !slices.Equal(scanned, []string{"src/api/handler.go", "src/web/render.go"}) {
+		t.Fatalf("scanned = %v, want the files of the existing roots only", scanned)
+	}
+
+	// With no usable root left the phase fails with a typed error.
+	in.Roots.MainSourceRoots = []string{"src/server", "src/api/handler.go"}
+	_, err = CodeImports{}.Run(context.Background(), in)
+	var missing *MissingRootsError
+	if !errors.Is(err, ErrNoUsableRoots) || !errors.As(err, &missing) || len(missing.Warnings) != 2 {
+		t.Fatalf("err = %v, want a MissingRootsError for both roots", err)
+	}
+}
+
+func TestCodeStatsRecordsMissingRoots(t *testing.T) {
+	repoFS := writeRootsFixture(t)
+	in := artifact.CodeStatsIn{
+		Repo:   "demo",
+		RepoFS: repoFS,
+		Roots: artifact.CodeRootsOut{
+			MainSourceRoots: []string{"src/api", "src/server"},
+			LibraryRoots:    []string{"third_party", "tools"},
+		},
+	}
+	out, err := CodeStats{}.Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := []string{
+		`main_source_roots: "src/server" does not exist, skipped`,
+		`library_roots: "third_party" does not exist, skipped`,
+	}
+	if !slices.Equal(out.Warnings, want) {
+		t.Fatalf("warnings = %q, want %q", out.Warnings, want)
+	}
+	if len(out.Exts) != 1 || out.Exts[0].Count != 2 {
+		t.Fatalf("exts = %+v, want the two .go files outside tools", out.Exts)
+	}
+}
+
+func TestCodeImportsFallsBackToDefaultSafeFS(t *testing.T) {
+	repoFS := writeRootsFixture(t)
+	prevDefault := safeio.Default()
+	scan.SetSafeFS(nil)
+	safeio.SetDefault(repoFS)
+	t.Cleanup(func() { safeio.SetDefault(prevDefault) })
+
+	family := artifact.FamilySpec{Family: "go", Key: "go", Spec: artifact.ExtractorSpec{Exts: []string{".go"}}}
+	out, err := CodeImports{}.Run(context.Background(), artifact.CodeImportsIn{
+		Repo:     "demo",
+		Roots:    artifact.CodeRootsOut{MainSourceRoots: []string{"src/api"}},
+		Families: []artifact.FamilySpec{family},
+	})
+	if err != nil {
+		t.Fatalf("Run() without a scan filesystem error = %v", err)
+	}
+	if len(out.PossibleDependencies) != 1 || len(out.PossibleDependencies[0].Files) == 0 {
+		t.Fatalf("dependencies = %+v, want the files of src/api", out.PossibleDependencies)
+	}
+}
@@ -0,0 +1,106 @@
+package codebase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+)
+
+func writeWorkspaceRepo(t *testing.T, files map[string]string) *safeio.SafeFS {
+	t.Helper()
+	root := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs, err := safeio.NewSafeFS(root)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	return fs
+}
+
+func TestDetectWorkspacePackagesPnpm(t *testing.T) {
+	fs := writeWorkspaceRepo(t, map[string]string{
+		"pnpm-workspace.yaml":             "packages:\n  - 'packages/*'\n  - \"apps/**\"\n  - '!**/fixtures/**'\n# trailing comment\nonlyBuiltDependencies:\n  - esbuild\n",
+		"package.json":                    `{"name":"root","private":true}`,
+		"packages/ui/package.json":        `{"name":"@acme/ui"}`,
+		"packages/ui/tsconfig.json":       `{}`,
+		"packages/utils/package.json":     `{"name":"@acme/utils"}`,
+		"packages/docs/README.md":         "no manifest",
+		"apps/web/package.json":           `{"name":"web"}`,
+		"apps/web/fixtures/package.json":  `{"name":"fixture"}`,
+		"packages/ui/node_modules/x/a.js": "",
+	})
+	got := DetectWorkspacePackages(fs)
+	want := []artifact.WorkspacePackage{
+		{Name: "web", Path: "apps/web", Language: "javascript", Manifest: "apps/web/package.json"},
+		{Name: "@acme/ui", Path: "packages/ui", Language: "typescript", Manifest: "packages/ui/package.json"},
+		{Name: "@acme/utils", Path: "packages/utils", Language: "javascript", Manifest: "packages/utils/package.json"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DetectWorkspacePackages() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDetectWorkspacePackagesGoWork(t *testing.T) {
+	fs := writeWorkspaceRepo(t, map[string]string{
+		"go.work":                "go 1.22\n\nuse (\n\t./svc/api // the API\n\t./lib\n)\nuse ./tools\n",
+		"svc/api/go.mod":         "module example.com/api\n\ngo 1.22\n",
+		"lib/go.mod":             "module example.com/lib\n",
+		"tools/main.go":          "package main\n",
+		"Cargo.toml":             "[workspace]\nmembers = [\n  \"crates/*\",\n]\nexclude = [\"crates/old\"]\n",
+		"crates/core/Cargo.toml": "[package]\nname = \"acme-core\"\n",
+		"crates/old/Cargo.toml":  "[package]\nname = \"old\"\n",
+	})
+	got := DetectWorkspacePackages(fs)
+	want := []artifact.WorkspacePackage{
+		{Name: "acme-core", Path: "crates/core", Language: "rust", Manifest: "crates/core/Cargo.toml"},
+		{Name: "example.com/lib", Path: "lib", Language: "go", Manifest: "lib/go.mod"},
+		{Name: "example.com/api", Path: "svc/api", Language: "go", Manifest: "svc/api/go.mod"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DetectWorkspacePackages() = %+v\nwant %+v", got, want)
+	}
+	if got := DetectWorkspacePackages(writeWorkspaceRepo(t, map[string]string{"main.go": "package main\n"})); len(got) != 0 {
+		t.Fatalf("single-module repo packages = %+v, want none", got)
+	}
+}
+
+func TestCodeGraphScopesToPackage(t *testing.T) {
+	ref := artifact.NewFileRef
+	in := artifact.CodeGraphIn{
+		Package:  "@acme/ui",
+		Packages: []artifact.WorkspacePackage{{Name: "@acme/ui", Path: "packages/ui"}, {Name: "@acme/api", Path: "packages/api"}},
+		Dependencies: []artifact.Dependencies{{Files: []artifact.SourceDependency{
+			{File: ref("packages/ui/a.ts"), Requires: []artifact.FileRef{ref("packages/ui/b.ts"), ref("packages/api/c.ts")}},
+			{File: ref("packages/api/c.ts"), Requires: []artifact.FileRef{ref("packages/ui/a.ts")}},
+		}}},
+	}
+	out, err := CodeGraph{}.Run(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	var paths []string
+	for _, n := range out.Graph.Nodes {
+		paths = append(paths, n.File.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"packages/ui/a.ts", "packages/ui/b.ts"}) {
+		t.Fatalf("nodes = %v, want only packages/ui files", paths)
+	}
+
+	in.Package = "missing"
+	if _, err := (CodeGraph{}).Run(context.Background(), in); err == nil {
+		t.Fatalf("Run() with an unknown package should fail")
+	}
+}
@@ -0,0 +1,429 @@
+package codebase
+
+import (
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+)
+
+const (
+	// maxWorkspacePackages caps the packages reported for one repo.
+	maxWorkspacePackages = 500
+	// maxManifestBytes skips manifests too large to be hand-written.
+	maxManifestBytes = 1 << 20
+	// maxGlobDepth bounds how deep "**" descends.
+	maxGlobDepth = 6
+)
+
+// workspaceKind describes the package manifest a workspace manager expects in
+// each member directory.
+type workspaceKind struct {
+	manifest string
+	describe func(fs *safeio.SafeFS, dir string, raw []byte) (name, language string)
+}
+
+var (
+	nodeWorkspace  = workspaceKind{manifest: "package.json", describe: describeNodePackage}
+	goWorkspace    = workspaceKind{manifest: "go.mod", describe: describeGoModule}
+	cargoWorkspace = workspaceKind{manifest: "Cargo.toml", describe: describeCrate}
+)
+
+// workspaceGlobs is one manifest's member patterns; patterns starting with
+// "!" exclude.
+type workspaceGlobs struct {
+	kind     workspaceKind
+	patterns []string
+}
+
+// DetectWorkspacePackages reads the monorepo manifests at the root of fs
+// (pnpm-workspace.yaml, package.json workspaces, lerna.json, go.work and
+// Cargo.toml [workspace]) and expands their member globs to the package
+// directories that hold the expected manifest. Results are sorted by path.
+func DetectWorkspacePackages(fs *safeio.SafeFS) []artifact.WorkspacePackage {
+	if fs == nil {
+		return nil
+	}
+	var sets []workspaceGlobs
+	if raw, ok := readManifest(fs, "pnpm-workspace.yaml"); ok {
+		sets = append(sets, workspaceGlobs{nodeWorkspace, parsePnpmWorkspace(raw)})
+	}
+	if raw, ok := readManifest(fs, "package.json"); ok {
+		sets = append(sets, workspaceGlobs{nodeWorkspace, parsePackageJSONWorkspaces(raw)})
+	}
+	if raw, ok := readManifest(fs, "lerna.json"); ok {
+		sets = append(sets, workspaceGlobs{nodeWorkspace, parseLernaPackages(raw)})
+	}
+	if raw, ok := readManifest(fs, "go.work"); ok {
+		sets = append(sets, workspaceGlobs{goWorkspace, parseGoWorkUses(raw)})
+	}
+	if raw, ok := readManifest(fs, "Cargo.toml"); ok {
+		sets = append(sets, workspaceGlobs{cargoWorkspace, parseCargoMembers(raw)})
+	}
+
+	byPath := map[string]artifact.WorkspacePackage{}
+	for _, set := range sets {
+		var include, exclude []string
+		for _, p := range set.patterns {
+			if rest, ok := strings.CutPrefix(p, "!"); ok {
+				exclude = append(exclude, cleanGlob(rest))
+			} else if p = cleanGlob(p); p != "" {
+				include = append(include, p)
+			}
+		}
+		for _, pattern := range include {
+			for _, dir := range expandWorkspaceGlob(fs, pattern) {
+				if _, dup := byPath[dir]; dup || matchesAny(exclude, dir) || dir == "." {
+					continue
+				}
+				manifest := path.Join(dir, set.kind.manifest)
+				raw, ok := readManifest(fs, manifest)
+				if !ok {
+					continue
+				}
+				name, lang := set.kind.describe(fs, dir, raw)
+				if name == "" {
+					name = path.Base(dir)
+				}
+				byPath[dir] = artifact.WorkspacePackage{Name: name, Path: dir, Language: lang, Manifest: manifest}
+			}
+		}
+	}
+
+	out := make([]artifact.WorkspacePackage, 0, len(byPath))
+	for _, p := range byPath {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	if len(out) > maxWorkspacePackages {
+		out = out[:maxWorkspacePackages]
+	}
+	return out
+}
+
+func readManifest(fs *safeio.SafeFS, name string) ([]byte, bool) {
+	raw, _, err := fs.SafeReadFileLimited(filepath.FromSlash(name), 0, maxManifestBytes)
+	return raw, err == nil
+}
+
+// cleanGlob normalizes a member pattern to a repo-relative slash path.
+func cleanGlob(p string) string {
+	p = strings.TrimSpace(strings.ReplaceAll(p, `\`, "/"))
+	p = strings.TrimPrefix(p, "./")
+	p = strings.TrimSuffix(p, "/")
+	if p == "" {
+		return ""
+	}
+	return path.Clean(p)
+}
+
+// expandWorkspaceGlob returns the directories matching pattern, which may use
+// path.Match syntax per segment and "**" for any number of directories.
+func expandWorkspaceGlob(fs *safeio.SafeFS, pattern string) []string {
+	segs := strings.Split(pattern, "/")
+	dirs := []string{"."}
+	for _, seg := range segs {
+		var next []string
+		for _, dir := range dirs {
+			switch {
+			case seg == "**":
+				next = append(next, dir)
+				next = append(next, descendantDirs(fs, dir, maxGlobDepth)...)
+			case strings.ContainsAny(seg, "*?["):
+				for _, child := range childDirs(fs, dir) {
+					if ok, _ := path.Match(seg, path.Base(child)); ok {
+						next = append(next, child)
+					}
+				}
+			default:
+				child := path.Join(dir, seg)
+				if info, err := fs.SafeStat(filepath.FromSlash(child)); err == nil && info.IsDir() {
+					next = append(next, child)
+				}
+			}
+		}
+		dirs = next
+		if len(dirs) == 0 {
+			return nil
+		}
+	}
+	return dirs
+}
+
+// childDirs lists the subdirectories of dir, skipping hidden and dependency
+// directories.
+func childDirs(fs *safeio.SafeFS, dir string) []string {
+	entries, err := fs.SafeReadDir(filepath.FromSlash(dir))
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "target" {
+			continue
+		}
+		out = append(out, path.Join(dir, name))
+	}
+	return out
+}
+
+func descendantDirs(fs *safeio.SafeFS, dir string, depth int) []string {
+	if depth <= 0 {
+		return nil
+	}
+	var out []string
+	for _, child := range childDirs(fs, dir) {
+		out = append(out, child)
+		out = append(out, descendantDirs(fs, child, depth-1)...)
+	}
+	return out
+}
+
+// matchesAny reports whether dir matches one of the exclusion patterns.
+func matchesAny(patterns []string, dir string) bool {
+	for _, p := range patterns {
+		if globMatch(strings.Split(p, "/"), strings.Split(dir, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func globMatch(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if globMatch(pattern[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+		return false
+	}
+	return globMatch(pattern[1:], segs[1:])
+}
+
+// parsePnpmWorkspace reads the "packages:" list of pnpm-workspace.yaml.
+func parsePnpmWorkspace(raw []byte) []string {
+	var out []string
+	inPackages := false
+	for _, line := range strings.Split(string(raw), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "-"); ok && inPackages {
+			out = append(out, strings.Trim(strings.TrimSpace(item), `"'`))
+		}
+	}
+	return out
+}
+
+// parsePackageJSONWorkspaces reads "workspaces" as a list or as
+// {"packages": [...]} (yarn).
+func parsePackageJSONWorkspaces(raw []byte) []string {
+	var doc struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(raw, &doc) != nil || len(doc.Workspaces) == 0 {
+		return nil
+	}
+	var list []string
+	if json.Unmarshal(doc.Workspaces, &list) == nil {
+		return list
+	}
+	var obj struct {
+		Packages []string `json:"packages"`
+	}
+	_ = json.Unmarshal(doc.Workspaces, &obj)
+	return obj.Packages
+}
+
+// parseLernaPackages reads lerna.json "packages", which defaults to
+// packages/*.
+func parseLernaPackages(raw []byte) []string {
+	var doc struct {
+		Packages []string `json:"packages"`
+	}
+	if json.Unmarshal(raw, &doc) != nil {
+		return nil
+	}
+	if len(doc.Packages) == 0 {
+		return []string{"packages/*"}
+	}
+	return doc.Packages
+}
+
+// parseGoWorkUses reads the use directives of go.work, single or grouped.
+func parseGoWorkUses(raw []byte) []string {
+	var out []string
+	inBlock := false
+	for _, line := range strings.Split(string(raw), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			out = append(out, strings.Trim(fields[0], `"`))
+		case fields[0] == "use" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "use" && len(fields) > 1:
+			out = append(out, strings.Trim(fields[1], `"`))
+		}
+	}
+	return out
+}
+
+var tomlStringRE = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+// parseCargoMembers reads members and exclude of the [workspace] table.
+func parseCargoMembers(raw []byte) []string {
+	var out []string
+	for _, key := range []string{"members", "exclude"} {
+		for _, v := range tomlArray(string(raw), "workspace", key) {
+			if key == "exclude" {
+				v = "!" + v
+			}
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// tomlArray returns the strings of key = [ ... ] in [table]; the array may
+// span lines.
+func tomlArray(doc, table, key string) []string {
+	body, ok := tomlTable(doc, table)
+	if !ok {
+		return nil
+	}
+	re := regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(key) + `\s*=\s*\[([^\]]*)\]`)
+	m := re.FindStringSubmatch(body)
+	if m == nil {
+		return nil
+	}
+	var out []string
+	for _, s := range tomlStringRE.FindAllStringSubmatch(m[1], -1) {
+		out = append(out, s[1]+s[2])
+	}
+	return out
+}
+
+// tomlTable returns the text of [table] up to the next table header.
+func tomlTable(doc, table string) (string, bool) {
+	header := "[" + table + "]"
+	var b strings.Builder
+	in := false
+	for _, line := range strings.Split(doc, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			in = trimmed == header
+			if in {
+				b.WriteString("\n")
+			}
+			continue
+		}
+		if in {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), b.Len() > 0
+}
+
+func describeNodePackage(fs *safeio.SafeFS, dir string, raw []byte) (string, string) {
+	var doc struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(raw, &doc)
+	lang := "javascript"
+	if _, err := fs.SafeStat(filepath.FromSlash(path.Join(dir, "tsconfig.json"))); err == nil {
+		lang = "typescript"
+	}
+	return strings.TrimSpace(doc.Name), lang
+}
+
+func describeGoModule(_ *safeio.SafeFS, _ string, raw []byte) (string, string) {
+	for _, line := range strings.Split(string(raw), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), "go"
+		}
+	}
+	return "", "go"
+}
+
+var tomlNameRE = regexp.MustCompile(`(?m)^\s*name\s*=\s*["']([^"']+)["']`)
+
+func describeCrate(_ *safeio.SafeFS, _ string, raw []byte) (string, string) {
+	body, _ := tomlTable(string(raw), "package")
+	if m := tomlNameRE.FindStringSubmatch(body); m != nil {
+		return m[1], "rust"
+	}
+	return "", "rust"
+}
+
+// inPackage reports whether the repo-relative path p lies in package dir.
+func inPackage(p, dir string) bool {
+	p = strings.Trim(filepath.ToSlash(p), "/")
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// packageRoots narrows roots to those inside package dir, or to dir itself
+// when none is.
+func packageRoots(roots []string, dir string) []string {
+	var out []string
+	for _, r := range roots {
+		if inPackage(r, dir) {
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 {
+		return []string{dir}
+	}
+	return out
+}
+
+// scopeDependencies keeps the files of package dir and their requirements
+// inside it.
+func scopeDependencies(deps []artifact.Dependencies, dir string) []artifact.Dependencies {
+	out := make([]artifact.Dependencies, 0, len(deps))
+	for _, dep := range deps {
+		scoped := dep
+		scoped.Files = nil
+		for _, sd := range dep.Files {
+			if !inPackage(sd.File.Path, dir) {
+				continue
+			}
+			var reqs []artifact.FileRef
+			for _, req := range sd.Requires {
+				if inPackage(req.Path, dir) {
+					reqs = append(reqs, req)
+				}
+			}
+			sd.Requires = reqs
+			scoped.Files = append(scoped.Files, sd)
+		}
+		out = append(out, scoped)
+	}
+	return out
+}
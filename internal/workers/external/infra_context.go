@@ -9,6 +9,7 @@ import (
 	llmclient "insightify/internal/llm/client"
 	"insightify/internal/llm/tool"
 	"insightify/internal/common/safeio"
+	"insightify/internal/schema"
 )
 
 var infraContextPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
@@ -28,6 +29,8 @@ var infraContextPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 		"Identify external systems, infra components, build/deploy tools, and runtime configs.",
 		"Assess confidence for each identified item.",
 		"Identify evidence gaps where confidence is low.",
+		llmtool.RegenHintRule,
+		"If 'related_repos' is present, they are other repositories of the same system; connect cross-repo calls (e.g. a frontend calling this repo's API) and cite their paths with the '<repo>:' prefix as provided.",
 	},
 	Assumptions:  []string{"Missing info implies lower confidence."},
 	OutputFormat: "JSON only.",
@@ -38,6 +41,10 @@ var infraContextPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 type InfraContext struct {
 	LLM    llmclient.LLMClient
 	RepoFS *safeio.SafeFS
+	// Caps bounds each sampled config file; zero uses DefaultSampleCaps.
+	Caps SampleCaps
+	// Detect extends which config files are sampled.
+	Detect InfraDetect
 }
 
 // Run executes Stage InfraContext with defensive guards around the LLM call.
@@ -49,12 +56,12 @@ func (p *InfraContext) Run(ctx context.Context, in artifact.InfraContextIn) (art
 		in.ConfidenceThreshold = 0.65
 	}
 	const (
-		maxSamples     = 16
-		maxSampleBytes = 16000
+		maxSamples     = 24
+		maxSampleBytes = 160000 // shared by all samples
 		maxIdentifiers = 40
 	)
 	if len(in.ConfigSamples) == 0 && p.RepoFS != nil {
-		in.ConfigSamples = CollectInfraSamples(p.RepoFS, in.Repo, in.Roots, maxSamples, maxSampleBytes)
+		in.ConfigSamples = CollectInfraSamples(p.RepoFS, in.Repo, in.Roots, maxSamples, maxSampleBytes, p.Caps, p.Detect)
 	}
 	if len(in.IdentifierSummaries) == 0 {
 		in.IdentifierSummaries = SelectIdentifierSummaries(in.IdentifierReports, in.Repo, in.Roots, maxIdentifiers)
@@ -73,13 +80,20 @@ func (p *InfraContext) Run(ctx context.Context, in artifact.InfraContextIn) (art
 		"identifier_summaries": in.IdentifierSummaries,
 		"confidence_threshold": in.ConfidenceThreshold,
 	}
-
-	prompt, err := llmtool.StructuredPromptBuilder(infraContextPromptSpec)(ctx, &llmtool.ToolState{Input: payload}, nil)
-	if err != nil {
-		return artifact.InfraContextOut{}, err
+	if len(in.RelatedRepos) > 0 {
+		payload["related_repos"] = in.RelatedRepos
 	}
 
-	raw, err := p.LLM.GenerateJSON(ctx, prompt, payload)
+	raw, err := schema.GenerateValidated(schema.KeyInfraContext, func(hint string) (json.RawMessage, error) {
+		if hint != "" {
+			payload["regen_hint"] = hint
+		}
+		prompt, err := llmtool.StructuredPromptBuilder(infraContextPromptSpec)(ctx, &llmtool.ToolState{Input: payload}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.LLM.GenerateJSON(ctx, prompt, payload)
+	})
 	if err != nil {
 		return artifact.InfraContextOut{}, err
 	}
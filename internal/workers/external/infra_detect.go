@@ -0,0 +1,106 @@
+package external
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// InfraDetect adjusts which files infra sampling collects. Exts (".tf"),
+// Files (exact base names) and DirKeywords (substrings of directory names
+// worth descending into) add to the built-in sets. Deny excludes paths that
+// would otherwise match: a glob is matched against the base name and the
+// repository-relative path, and a glob ending in "/" excludes matching
+// directories with everything below them.
+type InfraDetect struct {
+	Exts        []string `json:"exts,omitempty"`
+	Files       []string `json:"files,omitempty"`
+	DirKeywords []string `json:"dir_keywords,omitempty"`
+	Deny        []string `json:"deny,omitempty"`
+}
+
+// IsZero reports whether d leaves the built-in detection unchanged.
+func (d InfraDetect) IsZero() bool {
+	return len(d.Exts) == 0 && len(d.Files) == 0 && len(d.DirKeywords) == 0 && len(d.Deny) == 0
+}
+
+func (d InfraDetect) isInfraFile(name string) bool {
+	if _, ok := infraExactFiles[name]; ok {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if _, ok := infraExts[ext]; ok {
+		return true
+	}
+	for _, f := range d.Files {
+		if strings.TrimSpace(f) == name {
+			return true
+		}
+	}
+	for _, e := range d.Exts {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e != "" && ext == "."+strings.TrimPrefix(e, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (d InfraDetect) looksInfraDir(name string) bool {
+	name = strings.ToLower(name)
+	for _, kw := range infraDirKeywords {
+		if strings.Contains(name, kw) {
+			return true
+		}
+	}
+	for _, kw := range d.DirKeywords {
+		if kw = strings.ToLower(strings.TrimSpace(kw)); kw != "" && strings.Contains(name, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// Denied reports whether the file at p, or a directory holding it, is
+// excluded by Deny.
+func (d InfraDetect) Denied(p string) bool {
+	if len(d.Deny) == 0 {
+		return false
+	}
+	p = path.Clean(filepath.ToSlash(strings.TrimSpace(p)))
+	for dir := path.Dir(p); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if d.deniedDir(dir) {
+			return true
+		}
+	}
+	return d.deniedFile(p)
+}
+
+func (d InfraDetect) deniedFile(p string) bool {
+	return d.deny(p, false)
+}
+
+func (d InfraDetect) deniedDir(p string) bool {
+	return d.deny(p, true)
+}
+
+// deny matches p against the Deny globs for files, or for directories when
+// dir is set.
+func (d InfraDetect) deny(p string, dir bool) bool {
+	p = path.Clean(filepath.ToSlash(p))
+	base := path.Base(p)
+	for _, pattern := range d.Deny {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" || strings.HasSuffix(pattern, "/") != dir {
+			continue
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -7,8 +7,10 @@ import (
 	"strings"
 
 	"insightify/internal/artifact"
+	"insightify/internal/common/utils"
 	llmclient "insightify/internal/llm/client"
 	"insightify/internal/llm/tool"
+	"insightify/internal/schema"
 )
 
 type infraRefinePromptOut struct {
@@ -16,6 +18,7 @@ type infraRefinePromptOut struct {
 	NeedsInput []string         `json:"needs_input" prompt_desc:"Questions or requests for more input."`
 	StopWhen   []string         `json:"stop_when" prompt_desc:"Convergence criteria."`
 	Notes      []string         `json:"notes" prompt_desc:"Short notes or caveats."`
+	QuestionStatus []artifact.QuestionStatusUpdate `json:"question_status" prompt_desc:"One entry per open_questions id the evidence settles: status answered or obsolete, with the evidence file paths consulted. Omit questions that stay open."`
 }
 
 var infraRefinePromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
@@ -32,6 +35,8 @@ var infraRefinePromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 	Rules: []string{
 		"Interpret the new evidence to refine or correct the external architecture hypothesis.",
 		"Flag unresolved questions under needs_input with concrete follow-up actions (e.g., 'file:template.yaml reason=check IAM policies').",
+		llmtool.RegenHintRule,
+		"Only open_questions are unresolved; questions settled in earlier runs are not listed and must not be raised again.",
 	},
 	Assumptions:  []string{"Assume previous hypothesis is the baseline."},
 	OutputFormat: "JSON only.",
@@ -51,19 +56,27 @@ func (p *InfraRefine) Run(ctx context.Context, in artifact.InfraRefineIn) (artif
 	if len(in.Files) > maxEvidence {
 		in.Files = cloneOpenedFiles(in.Files[:maxEvidence])
 	}
+	// Questions the ledger already closed stay out of the prompt.
+	previous := in.Previous
+	previous.EvidenceGaps = OpenGaps(in.Ledger, in.Previous.EvidenceGaps)
 	payload := map[string]any{
 		"repo":            in.Repo,
-		"previous_result": in.Previous,
+		"previous_result": previous,
+		"open_questions":  openQuestions(in.Ledger),
 		"file_evidence":   in.Files,
 		"notes":           in.Notes,
 	}
 
-	prompt, err := llmtool.StructuredPromptBuilder(infraRefinePromptSpec)(ctx, &llmtool.ToolState{Input: payload}, nil)
-	if err != nil {
-		return artifact.InfraRefineOut{}, err
-	}
-
-	raw, err := p.LLM.GenerateJSON(ctx, prompt, payload)
+	raw, err := schema.GenerateValidated(schema.KeyInfraRefine, func(hint string) (json.RawMessage, error) {
+		if hint != "" {
+			payload["regen_hint"] = hint
+		}
+		prompt, err := llmtool.StructuredPromptBuilder(infraRefinePromptSpec)(ctx, &llmtool.ToolState{Input: payload}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.LLM.GenerateJSON(ctx, prompt, payload)
+	})
 	if err != nil {
 		return artifact.InfraRefineOut{}, err
 	}
@@ -71,10 +84,28 @@ func (p *InfraRefine) Run(ctx context.Context, in artifact.InfraRefineIn) (artif
 	if err := json.Unmarshal(raw, &out); err != nil {
 		return artifact.InfraRefineOut{}, fmt.Errorf("InfraRefine JSON invalid: %w\nraw: %s", err, string(raw))
 	}
+	out.Delta = dedupeRefineDelta(out.Delta)
 	out.ExternalOverview = applyExternalDelta(in.Previous, out.Delta)
 	return out, nil
 }
 
+type openQuestion struct {
+	ID       string `json:"id"`
+	Topic    string `json:"topic,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Question string `json:"question"`
+}
+
+func openQuestions(ledger artifact.QuestionLedger) []openQuestion {
+	out := []openQuestion{}
+	for _, q := range ledger.Questions {
+		if q.Status == artifact.QuestionOpen {
+			out = append(out, openQuestion{ID: q.ID, Topic: q.Topic, Path: q.Path, Question: q.Question})
+		}
+	}
+	return out
+}
+
 type pathToken struct {
 	Key   string
 	Index *int
@@ -93,6 +124,33 @@ func applyExternalDelta(prev artifact.InfraContextOut, delta artifact.InfraRefin
 	return extractExternalOverview(root)
 }
 
+// dedupeRefineDelta drops statements the model repeated in added/removed and
+// collapses modified entries for the same field into one that keeps the
+// first before and the last after. Entries stay in first-appearance order.
+func dedupeRefineDelta(d artifact.InfraRefineDelta) artifact.InfraRefineDelta {
+	if len(d.Added) > 0 {
+		d.Added = utils.UniqueStrings(d.Added...)
+	}
+	if len(d.Removed) > 0 {
+		d.Removed = utils.UniqueStrings(d.Removed...)
+	}
+	if len(d.Modified) > 0 {
+		index := make(map[string]int, len(d.Modified))
+		mods := make([]artifact.InfraRefineDeltaMod, 0, len(d.Modified))
+		for _, mod := range d.Modified {
+			mod.Field = strings.TrimSpace(mod.Field)
+			if i, ok := index[mod.Field]; ok {
+				mods[i].After = mod.After
+				continue
+			}
+			index[mod.Field] = len(mods)
+			mods = append(mods, mod)
+		}
+		d.Modified = mods
+	}
+	return d
+}
+
 func structToJSONMap(v any) map[string]any {
 	var out map[string]any
 	data, err := json.Marshal(v)
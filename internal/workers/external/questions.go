@@ -0,0 +1,147 @@
+package external
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"insightify/internal/artifact"
+)
+
+// QuestionID is the stable ledger ID of a question about path.
+func QuestionID(path, question string) string {
+	sum := sha256.Sum256([]byte(normalizeCandidatePath(path) + "\n" + strings.Join(strings.Fields(strings.ToLower(question)), " ")))
+	return "q-" + hex.EncodeToString(sum[:6])
+}
+
+// gapQuestion returns the ledger path and text of gap.
+func gapQuestion(gap artifact.EvidenceGap) (path, question string) {
+	for _, s := range gap.Suggested {
+		if p := normalizeCandidatePath(s.Path); p != "" {
+			path = p
+			break
+		}
+	}
+	question = strings.TrimSpace(gap.Question)
+	if question == "" {
+		question = strings.TrimSpace(gap.Topic)
+	}
+	return path, question
+}
+
+// GapQuestionID is QuestionID for an evidence gap.
+func GapQuestionID(gap artifact.EvidenceGap) string {
+	return QuestionID(gapQuestion(gap))
+}
+
+// MergeGaps adds the gaps raised by phase to ledger as open questions. A gap
+// already answered stays answered; an obsolete one is reopened. Open
+// questions no longer raised become obsolete.
+func MergeGaps(ledger artifact.QuestionLedger, gaps []artifact.EvidenceGap, phase string) artifact.QuestionLedger {
+	out := artifact.QuestionLedger{Iteration: ledger.Iteration}
+	index := make(map[string]int, len(ledger.Questions))
+	for _, q := range ledger.Questions {
+		index[q.ID] = len(out.Questions)
+		out.Questions = append(out.Questions, q)
+	}
+	raised := make(map[string]bool, len(gaps))
+	for _, gap := range gaps {
+		path, question := gapQuestion(gap)
+		if question == "" {
+			continue
+		}
+		id := QuestionID(path, question)
+		raised[id] = true
+		if i, ok := index[id]; ok {
+			q := &out.Questions[i]
+			q.Topic = strings.TrimSpace(gap.Topic)
+			if q.Status == artifact.QuestionObsolete {
+				q.Status = artifact.QuestionOpen
+				q.ClosedBy, q.ClosedIteration, q.Evidence = "", 0, nil
+			}
+			continue
+		}
+		index[id] = len(out.Questions)
+		out.Questions = append(out.Questions, artifact.LedgerQuestion{
+			ID:       id,
+			Topic:    strings.TrimSpace(gap.Topic),
+			Path:     path,
+			Question: question,
+			Status:   artifact.QuestionOpen,
+		})
+	}
+	for i := range out.Questions {
+		q := &out.Questions[i]
+		if q.Status == artifact.QuestionOpen && !raised[q.ID] {
+			q.Status = artifact.QuestionObsolete
+			q.ClosedBy = phase
+			q.ClosedIteration = out.Iteration
+		}
+	}
+	return out
+}
+
+// OpenGaps returns the gaps the ledger has not closed; gaps missing from the
+// ledger count as open.
+func OpenGaps(ledger artifact.QuestionLedger, gaps []artifact.EvidenceGap) []artifact.EvidenceGap {
+	closed := make(map[string]bool, len(ledger.Questions))
+	for _, q := range ledger.Questions {
+		if q.Status != artifact.QuestionOpen {
+			closed[q.ID] = true
+		}
+	}
+	var out []artifact.EvidenceGap
+	for _, gap := range gaps {
+		if !closed[GapQuestionID(gap)] {
+			out = append(out, gap)
+		}
+	}
+	return out
+}
+
+// MergeQuestionStatus applies the statuses phase reported as one more
+// iteration of ledger. Unknown IDs and invalid statuses are ignored; a
+// closed question without cited evidence records the files opened for it.
+func MergeQuestionStatus(ledger artifact.QuestionLedger, updates []artifact.QuestionStatusUpdate, files []artifact.OpenedFile, phase string) artifact.QuestionLedger {
+	out := artifact.QuestionLedger{
+		Iteration: ledger.Iteration + 1,
+		Questions: append([]artifact.LedgerQuestion(nil), ledger.Questions...),
+	}
+	opened := make(map[string]bool, len(files))
+	for _, f := range files {
+		opened[normalizeCandidatePath(f.Path)] = true
+	}
+	index := make(map[string]int, len(out.Questions))
+	for i, q := range out.Questions {
+		index[q.ID] = i
+	}
+	for _, u := range updates {
+		i, ok := index[strings.TrimSpace(u.ID)]
+		if !ok {
+			continue
+		}
+		q := &out.Questions[i]
+		status := strings.ToLower(strings.TrimSpace(u.Status))
+		switch status {
+		case artifact.QuestionAnswered, artifact.QuestionObsolete:
+		default:
+			continue
+		}
+		if q.Status != artifact.QuestionOpen {
+			continue
+		}
+		q.Status = status
+		q.ClosedBy = phase
+		q.ClosedIteration = out.Iteration
+		q.Evidence = nil
+		for _, p := range u.Evidence {
+			if p = normalizeCandidatePath(p); p != "" {
+				q.Evidence = append(q.Evidence, p)
+			}
+		}
+		if len(q.Evidence) == 0 && opened[q.Path] {
+			q.Evidence = []string{q.Path}
+		}
+	}
+	return out
+}
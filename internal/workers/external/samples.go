@@ -0,0 +1,108 @@
+package external
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+)
+
+// SampleCaps bounds how many bytes of a single sampled file are read.
+// Data-heavy formats get small caps so a budget covers more distinct files.
+type SampleCaps struct {
+	// Default applies to files without a PerExt entry. Zero means no cap.
+	Default int
+	// PerExt maps a lowercase extension (".json") or an exact base name
+	// ("package-lock.json") to its cap. Base names win over extensions.
+	PerExt map[string]int
+}
+
+// DefaultSampleCaps is used when a zero SampleCaps is passed.
+var DefaultSampleCaps = SampleCaps{
+	Default: 8000,
+	PerExt: map[string]int{
+		".json":             4000,
+		".yaml":             4000,
+		".yml":              4000,
+		".toml":             3000,
+		".ini":              2000,
+		".tf":               24000,
+		".tfvars":           8000,
+		".hcl":              16000,
+		".bicep":            16000,
+		"package-lock.json": 1000,
+		"pnpm-lock.yaml":    1000,
+	},
+}
+
+// Limit returns the byte cap for path; 0 means unlimited.
+func (c SampleCaps) Limit(path string) int {
+	if c.Default == 0 && len(c.PerExt) == 0 {
+		c = DefaultSampleCaps
+	}
+	if n, ok := c.PerExt[filepath.Base(path)]; ok {
+		return n
+	}
+	if n, ok := c.PerExt[strings.ToLower(filepath.Ext(path))]; ok {
+		return n
+	}
+	return c.Default
+}
+
+// readSamples reads up to maxFiles of candidates, each cut at its cap.
+// totalBytes (0 = unlimited) is shared so that small files are read whole
+// and the remainder is split evenly among larger ones, preferring more
+// distinct files over reading a few completely.
+func readSamples(fs *safeio.SafeFS, repoRoot string, candidates []string, maxFiles, totalBytes int, caps SampleCaps) []artifact.OpenedFile {
+	if fs == nil || maxFiles <= 0 {
+		return nil
+	}
+	type sample struct {
+		path  string
+		size  int
+		need  int
+		alloc int
+	}
+	var picked []*sample
+	for _, path := range candidates {
+		if len(picked) >= maxFiles {
+			break
+		}
+		info, err := fs.SafeStat(toFSPath(path))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		size := int(info.Size())
+		need := size
+		if limit := caps.Limit(path); limit > 0 {
+			need = min(need, limit)
+		}
+		picked = append(picked, &sample{path: path, size: size, need: need, alloc: need})
+	}
+
+	if totalBytes > 0 {
+		bySize := append([]*sample(nil), picked...)
+		sort.SliceStable(bySize, func(i, j int) bool { return bySize[i].need < bySize[j].need })
+		left := totalBytes
+		for i, s := range bySize {
+			s.alloc = min(s.need, left/(len(bySize)-i))
+			left -= s.alloc
+		}
+	}
+
+	var samples []artifact.OpenedFile
+	for _, s := range picked {
+		if s.alloc <= 0 && s.need > 0 {
+			continue
+		}
+		of, err := readFileSample(fs, repoRoot, s.path, max(s.alloc, 1))
+		if err != nil {
+			continue
+		}
+		of.Truncated = len(of.Content) < s.size
+		samples = append(samples, of)
+	}
+	return samples
+}
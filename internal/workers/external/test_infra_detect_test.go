@@ -0,0 +1,110 @@
+package external
+
+import (
+	"sort"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+func samplePaths(samples []artifact.OpenedFile) []string {
+	out := make([]string, 0, len(samples))
+	for _, s := range samples {
+		out = append(out, s.Path)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func hasPath(paths []string, want string) bool {
+	for _, p := range paths {
+		if p == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInfraDetectDirKeywordsAddDirectory(t *testing.T) {
+	fs := writeSampleRepo(t, map[string]int{
+		"services/platform/main.tf": 40,
+		"services/web/app.yaml":     40,
+	})
+	roots := artifact.CodeRootsOut{ConfigRoots: []string{"."}}
+
+	got := samplePaths(CollectInfraSamples(fs, fs.Root(), roots, 8, 0, SampleCaps{}, InfraDetect{}))
+	if hasPath(got, "services/platform/main.tf") {
+		t.Fatalf("default detection sampled %v, want platform/ skipped", got)
+	}
+	got = samplePaths(CollectInfraSamples(fs, fs.Root(), roots, 8, 0, SampleCaps{}, InfraDetect{DirKeywords: []string{"Platform"}}))
+	if !hasPath(got, "services/platform/main.tf") || hasPath(got, "services/web/app.yaml") {
+		t.Fatalf("samples = %v, want the platform/ keyword to add only that directory", got)
+	}
+}
+
+func TestInfraDetectExtsAndFilesExtendDefaults(t *testing.T) {
+	fs := writeSampleRepo(t, map[string]int{
+		"deploy/stack.cue": 40,
+		"deploy/Tiltfile":  40,
+		"deploy/main.tf":   40,
+		"deploy/notes.txt": 40,
+	})
+	roots := artifact.CodeRootsOut{ConfigRoots: []string{"deploy"}}
+	detect := InfraDetect{Exts: []string{"CUE"}, Files: []string{"Tiltfile"}}
+
+	got := samplePaths(CollectInfraSamples(fs, fs.Root(), roots, 8, 0, SampleCaps{}, detect))
+	want := []string{"deploy/Tiltfile", "deploy/main.tf", "deploy/stack.cue"}
+	if len(got) != len(want) {
+		t.Fatalf("samples = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("samples = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestInfraDetectDenyExcludesMatchingFile(t *testing.T) {
+	fs := writeSampleRepo(t, map[string]int{
+		"deploy/values.yaml":         40,
+		"deploy/vendor-values.yaml":  40,
+		"deploy/vendor/redis.yaml":   40,
+		"deploy/app/deployment.yaml": 40,
+		"config/generated.json":      40,
+	})
+	roots := artifact.CodeRootsOut{
+		ConfigRoots: []string{"deploy"},
+		ConfigFiles: []string{"config/generated.json"},
+	}
+
+	base := samplePaths(CollectInfraSamples(fs, fs.Root(), roots, 8, 0, SampleCaps{}, InfraDetect{}))
+	for _, p := range []string{"deploy/vendor-values.yaml", "deploy/vendor/redis.yaml", "config/generated.json"} {
+		if !hasPath(base, p) {
+			t.Fatalf("default samples = %v, want %s to match without the denylist", base, p)
+		}
+	}
+
+	detect := InfraDetect{Deny: []string{"vendor-*.yaml", "vendor/", "config/generated.json"}}
+	got := samplePaths(CollectInfraSamples(fs, fs.Root(), roots, 8, 0, SampleCaps{}, detect))
+	want := []string{"deploy/app/deployment.yaml", "deploy/values.yaml"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("samples = %v, want denied files excluded: %v", got, want)
+	}
+}
+
+func TestInfraDetectDenied(t *testing.T) {
+	detect := InfraDetect{Deny: []string{"*.lock.json", "third_party/", "ops/generated/*.yaml"}}
+	cases := map[string]bool{
+		"web/package.lock.json":         true,
+		"third_party/tf/main.tf":        true,
+		"infra/third_party/values.yaml": true,
+		"ops/generated/a.yaml":          true,
+		"ops/generated/a.tf":            false,
+		"ops/values.yaml":               false,
+	}
+	for path, want := range cases {
+		if got := detect.Denied(path); got != want {
+			t.Fatalf("Denied(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
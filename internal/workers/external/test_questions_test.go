@@ -0,0 +1,115 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+// scriptedLLM replies with its canned responses in order and records every
+// prompt it was given.
+type scriptedLLM struct {
+	replies []string
+	prompts []string
+}
+
+func (s *scriptedLLM) Name() string                { return "scripted" }
+func (s *scriptedLLM) Close() error                { return nil }
+func (s *scriptedLLM) CountTokens(text string) int { return len(text) / 4 }
+func (s *scriptedLLM) TokenCapacity() int          { return 1 << 20 }
+
+func (s *scriptedLLM) GenerateJSON(_ context.Context, prompt string, _ any) (json.RawMessage, error) {
+	s.prompts = append(s.prompts, prompt)
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return json.RawMessage(reply), nil
+}
+
+func (s *scriptedLLM) GenerateJSONStream(ctx context.Context, prompt string, input any, _ func(string)) (json.RawMessage, error) {
+	return s.GenerateJSON(ctx, prompt, input)
+}
+
+func TestInfraRefineSecondRunOmitsAnsweredQuestions(t *testing.T) {
+	gaps := []artifact.EvidenceGap{
+		{
+			Topic:     "queue",
+			Question:  "Which message broker backs the job queue?",
+			Suggested: []artifact.LookupRequest{{Kind: "config", Path: "deploy/values.yaml"}},
+		},
+		{
+			Topic:     "auth",
+			Question:  "Where are OAuth client secrets loaded from?",
+			Suggested: []artifact.LookupRequest{{Kind: "file", Path: "internal/auth/config.go"}},
+		},
+	}
+	queueID := GapQuestionID(gaps[0])
+	authID := GapQuestionID(gaps[1])
+	if queueID == authID || queueID != QuestionID("./deploy/values.yaml", "which message  broker backs the job queue?") {
+		t.Fatalf("question IDs are not stable: %s %s", queueID, authID)
+	}
+
+	llm := &scriptedLLM{replies: []string{
+		`{"delta":{"added":["RabbitMQ broker"]},"question_status":[{"id":"` + queueID + `","status":"answered","evidence":["deploy/values.yaml"]}]}`,
+		`{"delta":{},"needs_input":["check secret manager"]}`,
+	}}
+	refine := &InfraRefine{LLM: llm}
+	prev := artifact.InfraContextOut{EvidenceGaps: gaps}
+	files := []artifact.OpenedFile{{Path: "deploy/values.yaml"}, {Path: "internal/auth/config.go"}}
+
+	// Run 1: both questions are open; the model answers the queue one.
+	ledger := MergeGaps(artifact.QuestionLedger{}, gaps, "infra_context")
+	out, err := refine.Run(context.Background(), artifact.InfraRefineIn{Previous: prev, Files: files, Ledger: ledger})
+	if err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	ledger = MergeQuestionStatus(ledger, out.QuestionStatus, files, "infra_refine")
+	if !strings.Contains(llm.prompts[0], gaps[0].Question) || !strings.Contains(llm.prompts[0], gaps[1].Question) {
+		t.Fatalf("first prompt should ask both questions")
+	}
+
+	// Run 2: infra_context raises the same gaps again.
+	ledger = MergeGaps(ledger, gaps, "infra_context")
+	if _, err := refine.Run(context.Background(), artifact.InfraRefineIn{Previous: prev, Files: files, Ledger: ledger}); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if strings.Contains(llm.prompts[1], gaps[0].Question) || strings.Contains(llm.prompts[1], queueID) {
+		t.Fatalf("second prompt repeats the answered question:\n%s", llm.prompts[1])
+	}
+	if !strings.Contains(llm.prompts[1], gaps[1].Question) || !strings.Contains(llm.prompts[1], authID) {
+		t.Fatalf("second prompt lost the open question")
+	}
+
+	byID := map[string]artifact.LedgerQuestion{}
+	for _, q := range ledger.Questions {
+		byID[q.ID] = q
+	}
+	answered := byID[queueID]
+	if answered.Status != artifact.QuestionAnswered || answered.ClosedBy != "infra_refine" || answered.ClosedIteration != 1 || len(answered.Evidence) != 1 || answered.Evidence[0] != "deploy/values.yaml" {
+		t.Fatalf("answered entry = %+v", answered)
+	}
+	if byID[authID].Status != artifact.QuestionOpen {
+		t.Fatalf("auth entry = %+v", byID[authID])
+	}
+}
+
+func TestMergeGapsObsoletesDroppedQuestions(t *testing.T) {
+	gap := artifact.EvidenceGap{Topic: "db", Question: "Is Postgres replicated?"}
+	ledger := MergeGaps(artifact.QuestionLedger{Iteration: 2}, []artifact.EvidenceGap{gap}, "infra_context")
+	ledger = MergeGaps(ledger, nil, "infra_context")
+	if q := ledger.Questions[0]; q.Status != artifact.QuestionObsolete || q.ClosedBy != "infra_context" || q.ClosedIteration != 2 {
+		t.Fatalf("dropped question = %+v", q)
+	}
+	// Raised again, it reopens.
+	ledger = MergeGaps(ledger, []artifact.EvidenceGap{gap}, "infra_context")
+	if q := ledger.Questions[0]; q.Status != artifact.QuestionOpen || q.ClosedBy != "" || len(ledger.Questions) != 1 {
+		t.Fatalf("reraised question = %+v", ledger.Questions)
+	}
+	// Unknown IDs and bogus statuses leave the ledger alone.
+	next := MergeQuestionStatus(ledger, []artifact.QuestionStatusUpdate{{ID: "q-missing", Status: "answered"}, {ID: ledger.Questions[0].ID, Status: "maybe"}}, nil, "infra_refine")
+	if next.Iteration != 3 || next.Questions[0].Status != artifact.QuestionOpen {
+		t.Fatalf("ledger after ignored updates = %+v", next)
+	}
+}
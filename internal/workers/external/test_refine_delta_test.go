@@ -0,0 +1,45 @@
+package external
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+func TestInfraRefineDedupesRepeatedDelta(t *testing.T) {
+	llm := &scriptedLLM{replies: []string{`{"delta":{
+		"added":["SQS queue","S3 bucket"," SQS queue","S3 bucket"],
+		"removed":["cron job","cron job"],
+		"modified":[
+			{"field":"external_overview.summary","before":"a","after":"b"},
+			{"field":"external_overview.notes","before":"x","after":"y"},
+			{"field":" external_overview.summary","before":"b","after":"c"}
+		]}}`}}
+	out, err := (&InfraRefine{LLM: llm}).Run(context.Background(), artifact.InfraRefineIn{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"SQS queue", "S3 bucket"}; !reflect.DeepEqual(out.Delta.Added, want) {
+		t.Fatalf("added = %q, want %q", out.Delta.Added, want)
+	}
+	if want := []string{"cron job"}; !reflect.DeepEqual(out.Delta.Removed, want) {
+		t.Fatalf("removed = %q, want %q", out.Delta.Removed, want)
+	}
+	want := []artifact.InfraRefineDeltaMod{
+		{Field: "external_overview.summary", Before: "a", After: "c"},
+		{Field: "external_overview.notes", Before: "x", After: "y"},
+	}
+	if !reflect.DeepEqual(out.Delta.Modified, want) {
+		t.Fatalf("modified = %+v, want %+v", out.Delta.Modified, want)
+	}
+}
+
+func TestDedupeRefineDeltaKeepsEmptyDelta(t *testing.T) {
+	d := artifact.InfraRefineDelta{Added: []string{}, Removed: nil}
+	got := dedupeRefineDelta(d)
+	if got.Added == nil || got.Removed != nil || got.Modified != nil {
+		t.Fatalf("dedupeRefineDelta(%+v) = %+v", d, got)
+	}
+}
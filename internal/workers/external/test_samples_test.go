@@ -0,0 +1,121 @@
+package external
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"insightify/internal/artifact"
+	"insightify/internal/common/safeio"
+)
+
+func writeSampleRepo(t *testing.T, files map[string]int) *safeio.SafeFS {
+	t.Helper()
+	root := t.TempDir()
+	for name, size := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	fs, err := safeio.NewSafeFS(root)
+	if err != nil {
+		t.Fatalf("NewSafeFS: %v", err)
+	}
+	return fs
+}
+
+func sampleSizes(samples []artifact.OpenedFile) map[string]int {
+	out := make(map[string]int, len(samples))
+	for _, s := range samples {
+		out[s.Path] = len(s.Content)
+	}
+	return out
+}
+
+func TestSampleCapsLimit(t *testing.T) {
+	caps := SampleCaps{Default: 100, PerExt: map[string]int{".json": 10, "package-lock.json": 1}}
+	cases := map[string]int{
+		"infra/main.tf":         100,
+		"config/app.JSON":       10,
+		"web/package-lock.json": 1,
+		"deploy/values.yaml":    100,
+	}
+	for path, want := range cases {
+		if got := caps.Limit(path); got != want {
+			t.Fatalf("Limit(%q) = %d, want %d", path, got, want)
+		}
+	}
+	if got, want := (SampleCaps{}).Limit("main.tf"), DefaultSampleCaps.PerExt[".tf"]; got != want {
+		t.Fatalf("zero caps Limit(main.tf) = %d, want default %d", got, want)
+	}
+}
+
+func TestCollectGapFilesTruncatesByExtension(t *testing.T) {
+	fs := writeSampleRepo(t, map[string]int{"app.json": 5000, "main.tf": 5000})
+	caps := SampleCaps{Default: 4000, PerExt: map[string]int{".json": 500, ".tf": 3000}}
+	gaps := []artifact.EvidenceGap{{Suggested: []artifact.LookupRequest{{Kind: "file", Path: "app.json"}, {Kind: "file", Path: "main.tf"}}}}
+
+	samples := CollectGapFiles(fs, fs.Root(), gaps, 8, 0, caps)
+	sizes := sampleSizes(samples)
+	if sizes["app.json"] != 500 || sizes["main.tf"] != 3000 {
+		t.Fatalf("sample sizes = %v, want json cut to 500 and tf to 3000", sizes)
+	}
+	for _, s := range samples {
+		if !s.Truncated {
+			t.Fatalf("%s should be marked truncated", s.Path)
+		}
+	}
+}
+
+func TestCollectInfraSamplesSpreadsBudget(t *testing.T) {
+	files := map[string]int{"deploy/big.tf": 20000}
+	for _, name := range []string{"a.yaml", "b.yaml", "c.yaml", "d.yaml"} {
+		files["deploy/"+name] = 300
+	}
+	fs := writeSampleRepo(t, files)
+	roots := artifact.CodeRootsOut{ConfigRoots: []string{"deploy"}}
+	caps := SampleCaps{Default: 100000}
+
+	// The big file sorts first; reading it whole would exhaust the budget.
+	samples := CollectInfraSamples(fs, fs.Root(), roots, 8, 4000, caps, InfraDetect{})
+	sizes := sampleSizes(samples)
+	if len(sizes) != 5 {
+		t.Fatalf("got %d samples %v, want all 5 files", len(sizes), sizes)
+	}
+	total := 0
+	for path, n := range sizes {
+		total += n
+		if path != "deploy/big.tf" && n != 300 {
+			t.Fatalf("small file %s read %d bytes, want it whole", path, n)
+		}
+	}
+	if total > 4000 || sizes["deploy/big.tf"] != 4000-4*300 {
+		t.Fatalf("sizes = %v (total %d), want big.tf to get the remaining budget", sizes, total)
+	}
+}
+
+func TestCollectInfraSamplesPerPackage(t *testing.T) {
+	files := map[string]int{"packages/ui/package.json": 50, "packages/api/package.json": 50}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		files["packages/api/deploy/"+name+".yaml"] = 50
+	}
+	fs := writeSampleRepo(t, files)
+	roots := artifact.CodeRootsOut{Packages: []artifact.WorkspacePackage{
+		{Name: "@acme/api", Path: "packages/api", Manifest: "packages/api/package.json"},
+		{Name: "@acme/ui", Path: "packages/ui", Manifest: "packages/ui/package.json"},
+	}}
+
+	samples := CollectInfraSamples(fs, fs.Root(), roots, 4, 0, SampleCaps{Default: 1000}, InfraDetect{})
+	byPackage := map[string][]string{}
+	for _, s := range samples {
+		byPackage[s.Package] = append(byPackage[s.Package], s.Path)
+	}
+	if len(samples) != 4 || len(byPackage["@acme/ui"]) != 1 || len(byPackage["@acme/api"]) != 3 {
+		t.Fatalf("samples by package = %v, want the ui manifest kept beside api's files", byPackage)
+	}
+}
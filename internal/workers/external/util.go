@@ -12,44 +12,97 @@ import (
 	"insightify/internal/common/utils"
 )
 
-func CollectInfraSamples(fs *safeio.SafeFS, repoRoot string, roots artifact.CodeRootsOut, maxFiles, maxBytes int) []artifact.OpenedFile {
+// CollectInfraSamples reads up to maxFiles infra/config files under roots,
+// sharing totalBytes between them; see readSamples.
+// detect extends the files and directories considered infra and denies
+// noise, including among the config files code_roots listed.
+func CollectInfraSamples(fs *safeio.SafeFS, repoRoot string, roots artifact.CodeRootsOut, maxFiles, totalBytes int, caps SampleCaps, detect InfraDetect) []artifact.OpenedFile {
 	if fs == nil || maxFiles <= 0 {
 		return nil
 	}
 	candidates := make([]string, 0, maxFiles*3)
 	seen := make(map[string]struct{})
 	for _, f := range append(append([]string{}, roots.ConfigFiles...), roots.RuntimeConfigFiles...) {
-		appendCandidate(&candidates, seen, f)
+		if !detect.Denied(f) {
+			appendCandidate(&candidates, seen, f)
+		}
 	}
 	rootDirs := append(append([]string{}, roots.ConfigRoots...), roots.RuntimeConfigRoots...)
 	rootDirs = append(rootDirs, roots.BuildRoots...)
 	for _, dir := range utils.UniqueStrings(rootDirs...) {
-		gatherInfraDir(fs, dir, 0, maxFiles*4, &candidates, seen)
+		gatherInfraDir(fs, detect, dir, 0, maxFiles*4, &candidates, seen)
 		if len(candidates) >= maxFiles*4 {
 			break
 		}
 	}
 
 	sort.Strings(candidates)
-	if len(candidates) > maxFiles {
-		candidates = candidates[:maxFiles]
+	if len(roots.Packages) == 0 {
+		return readSamples(fs, repoRoot, candidates, maxFiles, totalBytes, caps)
+	}
+	samples := readSamples(fs, repoRoot, packageCandidates(fs, detect, candidates, roots.Packages, seen, maxFiles), maxFiles, totalBytes, caps)
+	for i := range samples {
+		if pkg, ok := packageOf(roots.Packages, samples[i].Path); ok {
+			samples[i].Package = pkg.Name
+		}
 	}
+	return samples
+}
 
-	var samples []artifact.OpenedFile
-	for _, path := range candidates {
-		of, err := readFileSample(fs, repoRoot, path, maxBytes)
-		if err != nil {
-			continue
+// packageCandidates gives each workspace package its own candidate list (its
+// manifest, then its infra files) and interleaves them with the repo-level
+// candidates, so one large package cannot take every sample slot.
+func packageCandidates(fs *safeio.SafeFS, detect InfraDetect, shared []string, pkgs []artifact.WorkspacePackage, seen map[string]struct{}, perGroup int) []string {
+	groups := make([][]string, len(pkgs)+1)
+	for _, c := range shared {
+		i := 0
+		for j, pkg := range pkgs {
+			if inPackageDir(c, pkg.Path) {
+				i = j + 1
+				break
+			}
 		}
-		samples = append(samples, of)
-		if len(samples) >= maxFiles {
-			break
+		groups[i] = append(groups[i], c)
+	}
+	for j, pkg := range pkgs {
+		var found []string
+		if !detect.Denied(pkg.Manifest) {
+			appendCandidate(&found, seen, pkg.Manifest)
+		}
+		gatherInfraDir(fs, detect, pkg.Path, 0, perGroup, &found, seen)
+		groups[j+1] = append(groups[j+1], found...)
+	}
+	var out []string
+	for n := 0; ; n++ {
+		added := false
+		for _, g := range groups {
+			if n < len(g) {
+				out = append(out, g[n])
+				added = true
+			}
+		}
+		if !added {
+			return out
 		}
 	}
-	return samples
 }
 
-func gatherInfraDir(fs *safeio.SafeFS, dir string, depth, limit int, dest *[]string, seen map[string]struct{}) {
+// packageOf returns the workspace package holding path.
+func packageOf(pkgs []artifact.WorkspacePackage, path string) (artifact.WorkspacePackage, bool) {
+	for _, pkg := range pkgs {
+		if inPackageDir(path, pkg.Path) {
+			return pkg, true
+		}
+	}
+	return artifact.WorkspacePackage{}, false
+}
+
+func inPackageDir(path, dir string) bool {
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	return dir != "" && (path == dir || strings.HasPrefix(path, dir+"/"))
+}
+
+func gatherInfraDir(fs *safeio.SafeFS, detect InfraDetect, dir string, depth, limit int, dest *[]string, seen map[string]struct{}) {
 	if fs == nil || dir == "" || depth > 2 || len(*dest) >= limit {
 		return
 	}
@@ -68,12 +121,12 @@ func gatherInfraDir(fs *safeio.SafeFS, dir string, depth, limit int, dest *[]str
 		name := entry.Name()
 		child := filepath.Join(dirPath, name)
 		if entry.IsDir() {
-			if depth < 1 || looksInfraDir(name) {
-				gatherInfraDir(fs, child, depth+1, limit, dest, seen)
+			if (depth < 1 || detect.looksInfraDir(name)) && !detect.deniedDir(child) {
+				gatherInfraDir(fs, detect, child, depth+1, limit, dest, seen)
 			}
 			continue
 		}
-		if isInfraFile(name) {
+		if detect.isInfraFile(name) && !detect.deniedFile(child) {
 			appendCandidate(dest, seen, child)
 			if len(*dest) >= limit {
 				return
@@ -82,6 +135,7 @@ func gatherInfraDir(fs *safeio.SafeFS, dir string, depth, limit int, dest *[]str
 	}
 }
 
+// readFileSample reads path, at most maxBytes of it when maxBytes > 0.
 func readFileSample(fs *safeio.SafeFS, repoRoot, path string, maxBytes int) (artifact.OpenedFile, error) {
 	if fs == nil {
 		return artifact.OpenedFile{}, fmt.Errorf("repo filesystem is nil")
@@ -155,12 +209,14 @@ func SelectIdentifierSummaries(reports []artifact.IdentifierReport, repoRoot str
 	return priority
 }
 
-func CollectGapFiles(fs *safeio.SafeFS, repoRoot string, gaps []artifact.EvidenceGap, maxFiles, maxBytes int) []artifact.OpenedFile {
+// CollectGapFiles reads the files suggested by gaps, in order, up to maxFiles
+// of them sharing totalBytes; see readSamples.
+func CollectGapFiles(fs *safeio.SafeFS, repoRoot string, gaps []artifact.EvidenceGap, maxFiles, totalBytes int, caps SampleCaps) []artifact.OpenedFile {
 	if fs == nil || maxFiles <= 0 {
 		return nil
 	}
 	seen := make(map[string]struct{})
-	var samples []artifact.OpenedFile
+	var candidates []string
 	for _, gap := range gaps {
 		for _, suggestion := range gap.Suggested {
 			if !isFileLikeSuggestion(suggestion.Kind) {
@@ -173,18 +229,11 @@ func CollectGapFiles(fs *safeio.SafeFS, repoRoot string, gaps []artifact.Evidenc
 			if _, ok := seen[path]; ok {
 				continue
 			}
-			of, err := readFileSample(fs, repoRoot, path, maxBytes)
-			if err != nil {
-				continue
-			}
 			seen[path] = struct{}{}
-			samples = append(samples, of)
-			if len(samples) >= maxFiles {
-				return samples
-			}
+			candidates = append(candidates, path)
 		}
 	}
-	return samples
+	return readSamples(fs, repoRoot, candidates, maxFiles, totalBytes, caps)
 }
 
 // --- small helpers ---
@@ -318,28 +367,10 @@ var infraExactFiles = map[string]struct{}{
 	"pnpm-lock.yaml":      {},
 }
 
-func isInfraFile(name string) bool {
-	if _, ok := infraExactFiles[name]; ok {
-		return true
-	}
-	ext := strings.ToLower(filepath.Ext(name))
-	if _, ok := infraExts[ext]; ok {
-		return true
-	}
-	return false
-}
-
-func looksInfraDir(name string) bool {
-	name = strings.ToLower(name)
-	keywords := []string{
-		"infra", "infrastructure", "deploy", "deployment", "terraform", "iac",
-		"cloud", "aws", "gcp", "azure", "ops", "devops", "config", "scripts",
-		"pipelines", "ci", "cd", "build", "sam", "serverless",
-	}
-	for _, kw := range keywords {
-		if strings.Contains(name, kw) {
-			return true
-		}
-	}
-	return false
+// infraDirKeywords mark directory names worth descending into below the
+// first level of a config root.
+var infraDirKeywords = []string{
+	"infra", "infrastructure", "deploy", "deployment", "terraform", "iac",
+	"cloud", "aws", "gcp", "azure", "ops", "devops", "config", "scripts",
+	"pipelines", "ci", "cd", "build", "sam", "serverless",
 }
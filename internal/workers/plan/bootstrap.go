@@ -12,11 +12,20 @@ import (
 	llmmiddleware "insightify/internal/llm/middleware"
 	llmmodel "insightify/internal/llm/model"
 	"insightify/internal/llm/tool"
+	"insightify/internal/schema"
 )
 
 // BootstrapIn is the input for the bootstrap pipeline.
 type BootstrapIn struct {
-	UserInput string `json:"user_input"`
+	UserInput    string                      `json:"user_input"`
+	Conversation []artifact.ConversationTurn `json:"conversation,omitempty"`
+	// ConversationSession is the session Conversation was recorded in; Run
+	// drops a conversation of another Session.
+	ConversationSession string `json:"conversation_session,omitempty"`
+	Session             string `json:"session,omitempty"`
+	// Locale selects the language of assistant messages (see
+	// NormalizeLocale); unsupported or empty locales use English.
+	Locale string `json:"locale,omitempty"`
 }
 
 // BootstrapOut is the output of the bootstrap pipeline.
@@ -31,7 +40,8 @@ func (o BootstrapOut) NeedMoreInput() bool {
 	return o.Result.NeedMoreInput
 }
 
-// ChunkEmitter emits LLM streaming chunks. Implemented by runner.RunEventEmitter.
+// ChunkEmitter streams assistant output to the user as it is produced; the
+// runner implements it with the chat of the run.
 type ChunkEmitter interface {
 	EmitLLMChunk(chunk string)
 }
@@ -40,8 +50,17 @@ type ChunkEmitter interface {
 type BootstrapPipeline struct {
 	LLM     llmclient.LLMClient
 	Emitter ChunkEmitter
+	// ConversationTokens caps the transcript passed to the LLM; the oldest
+	// turns are dropped first. Zero uses defaultConversationTokens.
+	ConversationTokens int
 }
 
+const (
+	defaultConversationTokens = 2000
+	// maxConversationTurns bounds the transcript persisted in the artifact.
+	maxConversationTurns = 40
+)
+
 var initPurposePromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 	Purpose:      "Collect user learning intent and optional repository target, then decide whether more input is needed.",
 	Background:   "This stage returns the assistant response for the planning bootstrap conversation.",
@@ -54,15 +73,16 @@ var initPurposePromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec{
 	},
 	Rules: []string{
 		"Use detected_repo_url and scout_explanation as hints, but prioritize user_input.",
+		"conversation lists earlier turns oldest first; keep what was already agreed and do not ask again for information given there.",
+		llmtool.RegenHintRule,
 		"If intent is still ambiguous, set need_more_input=true.",
+		"Write followup_question in response_language.",
 	},
 	Assumptions:  []string{"If both repo_url and purpose are empty, more input is required."},
 	OutputFormat: "JSON only.",
 	Language:     "English",
 }, llmtool.PresetStrictJSON(), llmtool.PresetNoInvent())
 
-const bootstrapGreetingMessage = "Would you like to explore how computers work, or dive into real OSS code to deepen your understanding? Share a topic you're curious about or paste a GitHub repository URL."
-
 type bootstrapScoutResult struct {
 	RecommendedRepoURL string `json:"recommended_repo_url"`
 	Explanation        string `json:"explanation"`
@@ -81,12 +101,27 @@ var bootstrapScoutPromptSpec = llmtool.ApplyPresets(llmtool.StructuredPromptSpec
 	Rules: []string{
 		"Prefer concrete and popular repositories when recommendation is appropriate.",
 		"Do not invent non-existent repository URLs.",
+		"Write explanation in response_language.",
+		llmtool.RegenHintRule,
 	},
 	Assumptions:  []string{"When user intent is conceptual, recommendation may be omitted."},
 	OutputFormat: "JSON only.",
 	Language:     "English",
 }, llmtool.PresetStrictJSON(), llmtool.PresetNoInvent())
 
+// Transcript returns the turns Run starts from: the carried Conversation when
+// it belongs to Session, followed by UserInput.
+func (in BootstrapIn) Transcript() []artifact.ConversationTurn {
+	var turns []artifact.ConversationTurn
+	if strings.TrimSpace(in.ConversationSession) == strings.TrimSpace(in.Session) {
+		turns = artifact.NormalizeConversation(in.Conversation)
+	}
+	if input := strings.TrimSpace(in.UserInput); input != "" {
+		turns = append(turns, artifact.ConversationTurn{Role: "user", Content: input})
+	}
+	return turns
+}
+
 // Run executes the bootstrap pipeline.
 func (p *BootstrapPipeline) Run(ctx context.Context, in BootstrapIn) (BootstrapOut, error) {
 	if p == nil {
@@ -95,16 +130,31 @@ func (p *BootstrapPipeline) Run(ctx context.Context, in BootstrapIn) (BootstrapO
 
 	out := BootstrapOut{}
 
+	conversation := in.Transcript()
+	in.Conversation = conversation
+
 	result, err := p.runBootstrap(ctx, in)
 	if err != nil {
 		return out, err
 	}
+	if reply := strings.TrimSpace(result.FollowupQuestion); reply != "" {
+		conversation = append(conversation, artifact.ConversationTurn{Role: "assistant", Content: reply})
+		// runBootstrap already streamed the greeting.
+		if strings.TrimSpace(in.UserInput) != "" {
+			p.emitChunk(reply)
+		}
+	}
+	if len(conversation) > maxConversationTurns {
+		conversation = conversation[len(conversation)-maxConversationTurns:]
+	}
 
 	out.Result = result
 	out.BootstrapContext = artifact.BootstrapContext{
-		Purpose:   result.Purpose,
-		RepoURL:   result.RepoURL,
-		UserInput: strings.TrimSpace(in.UserInput),
+		Purpose:      result.Purpose,
+		RepoURL:      result.RepoURL,
+		UserInput:    strings.TrimSpace(in.UserInput),
+		Conversation: conversation,
+		Session:      in.Session,
 	}.Normalize()
 	out.ClientView = buildClientView(result)
 	return out, nil
@@ -113,9 +163,10 @@ func (p *BootstrapPipeline) Run(ctx context.Context, in BootstrapIn) (BootstrapO
 func (p *BootstrapPipeline) runBootstrap(ctx context.Context, in BootstrapIn) (artifact.InitPurposeOut, error) {
 	// Initial greeting when no user input yet.
 	if strings.TrimSpace(in.UserInput) == "" {
-		p.emitChunk(bootstrapGreetingMessage)
+		greeting := Message(in.Locale, MsgBootstrapGreeting)
+		p.emitChunk(greeting)
 		return artifact.InitPurposeOut{
-			FollowupQuestion: bootstrapGreetingMessage,
+			FollowupQuestion: greeting,
 			NeedMoreInput:    true,
 		}, nil
 	}
@@ -129,23 +180,25 @@ func (p *BootstrapPipeline) runBootstrap(ctx context.Context, in BootstrapIn) (a
 	}
 
 	ctx = llmmiddleware.WithWorker(ctx, "bootstrap")
-	scout := p.resolveScout(ctx, input)
+	language := ResponseLanguage(in.Locale)
+	scout := p.resolveScout(ctx, input, language)
 	extractedRepo := strings.TrimSpace(scout.RecommendedRepoURL)
 	scoutExplanation := strings.TrimSpace(scout.Explanation)
 
 	// Run the main bootstrap LLM call
-	result, err := p.runBootstrapLLM(ctx, input, extractedRepo, scoutExplanation)
+	conversation := p.trimConversation(in.Conversation)
+	result, err := p.runBootstrapLLM(ctx, input, conversation, extractedRepo, scoutExplanation, language)
 	if err != nil {
 		return artifact.InitPurposeOut{}, err
 	}
 	return result, nil
 }
 
-func (p *BootstrapPipeline) resolveScout(ctx context.Context, input string) bootstrapScoutResult {
+func (p *BootstrapPipeline) resolveScout(ctx context.Context, input, language string) bootstrapScoutResult {
 	if strings.TrimSpace(input) == "" || p == nil || p.LLM == nil {
 		return bootstrapScoutResult{}
 	}
-	scout, err := p.runScoutLLM(ctx, input)
+	scout, err := p.runScoutLLM(ctx, input, language)
 	if err != nil {
 		return bootstrapScoutResult{}
 	}
@@ -160,21 +213,59 @@ func (p *BootstrapPipeline) emitChunk(chunk string) {
 
 // --- Internal helpers ---
 
-func (p *BootstrapPipeline) runBootstrapLLM(ctx context.Context, userInput, detectedRepoURL, scoutExplanation string) (artifact.InitPurposeOut, error) {
+// trimConversation keeps the newest turns that fit the token budget.
+func (p *BootstrapPipeline) trimConversation(turns []artifact.ConversationTurn) []artifact.ConversationTurn {
+	budget := p.ConversationTokens
+	if budget <= 0 {
+		budget = defaultConversationTokens
+	}
+	start := len(turns)
+	used := 0
+	for start > 0 {
+		cost := p.countTokens(turns[start-1].Role + ": " + turns[start-1].Content)
+		if used+cost > budget {
+			break
+		}
+		used += cost
+		start--
+	}
+	return turns[start:]
+}
+
+func (p *BootstrapPipeline) countTokens(text string) int {
+	if p.LLM != nil {
+		if n := p.LLM.CountTokens(text); n > 0 {
+			return n
+		}
+	}
+	return len(text)/4 + 1
+}
+
+func (p *BootstrapPipeline) runBootstrapLLM(ctx context.Context, userInput string, conversation []artifact.ConversationTurn, detectedRepoURL, scoutExplanation, language string) (artifact.InitPurposeOut, error) {
 	if p.LLM == nil {
 		return artifact.InitPurposeOut{}, fmt.Errorf("bootstrap: llm client is nil")
 	}
+	if conversation == nil {
+		conversation = []artifact.ConversationTurn{}
+	}
 	payload := map[string]any{
 		"user_input":        userInput,
+		"conversation":      conversation,
 		"detected_repo_url": detectedRepoURL,
 		"scout_explanation": scoutExplanation,
+		"response_language": language,
 	}
 	llmCtx := llmmodel.WithModelSelection(ctx, llmmodel.ModelRoleWorker, llmmodel.ModelLevelLow, "", "")
-	prompt, err := llmtool.StructuredPromptBuilder(initPurposePromptSpec)(llmCtx, &llmtool.ToolState{Input: payload}, nil)
-	if err != nil {
-		return artifact.InitPurposeOut{}, err
-	}
-	raw, err := p.LLM.GenerateJSONStream(llmCtx, prompt, payload, p.emitChunk)
+	raw, err := schema.GenerateValidated(schema.KeyInitPurpose, func(hint string) (json.RawMessage, error) {
+		if hint != "" {
+			payload["regen_hint"] = hint
+		}
+		prompt, err := llmtool.StructuredPromptBuilder(initPurposePromptSpec)(llmCtx, &llmtool.ToolState{Input: payload}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.LLM.GenerateJSONStream(llmCtx, prompt, payload, p.emitChunk)
+	})
 	if err != nil {
 		return artifact.InitPurposeOut{}, err
 	}
@@ -185,19 +276,34 @@ func (p *BootstrapPipeline) runBootstrapLLM(ctx context.Context, userInput, dete
 	return out, nil
 }
 
-func (p *BootstrapPipeline) runScoutLLM(ctx context.Context, userInput string) (bootstrapScoutResult, error) {
+// scoutTemperature samples source scout recommendations.
+const scoutTemperature = 0.4
+
+func (p *BootstrapPipeline) runScoutLLM(ctx context.Context, userInput, language string) (bootstrapScoutResult, error) {
 	if p.LLM == nil {
 		return bootstrapScoutResult{}, fmt.Errorf("bootstrap: llm client is nil")
 	}
 	payload := map[string]any{
-		"user_input": strings.TrimSpace(userInput),
+		"user_input":        strings.TrimSpace(userInput),
+		"response_language": language,
 	}
 	llmCtx := llmmodel.WithModelSelection(llmmiddleware.WithWorker(ctx, "source_scout"), llmmodel.ModelRoleWorker, llmmodel.ModelLevelMiddle, "", "")
-	prompt, err := llmtool.StructuredPromptBuilder(bootstrapScoutPromptSpec)(llmCtx, &llmtool.ToolState{Input: payload}, nil)
-	if err != nil {
-		return bootstrapScoutResult{}, err
+	// Recommendations benefit from a little diversity; an explicit phase
+	// temperature still wins.
+	if opts := llmclient.GenerationOptionsFrom(llmCtx); opts.Temperature == nil {
+		opts.Temperature = llmclient.Float32(scoutTemperature)
+		llmCtx = llmclient.WithGenerationOptions(llmCtx, opts)
 	}
-	raw, err := p.LLM.GenerateJSON(llmCtx, prompt, payload)
+	raw, err := schema.GenerateValidated(schema.KeySourceScout, func(hint string) (json.RawMessage, error) {
+		if hint != "" {
+			payload["regen_hint"] = hint
+		}
+		prompt, err := llmtool.StructuredPromptBuilder(bootstrapScoutPromptSpec)(llmCtx, &llmtool.ToolState{Input: payload}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return p.LLM.GenerateJSON(llmCtx, prompt, payload)
+	})
 	if err != nil {
 		return bootstrapScoutResult{}, err
 	}
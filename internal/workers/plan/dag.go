@@ -2,6 +2,7 @@ package plan
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"strings"
 
@@ -9,18 +10,23 @@ import (
 	"insightify/internal/artifact"
 )
 
+// DiagnosticNodePrefix starts the UIDs of the graph nodes that carry plan
+// diagnostics in the worker_DAG ClientView.
+const DiagnosticNodePrefix = "diagnostic:"
+
 // PlanContext holds dependencies for the planning phase.
 type PlanContext struct {
 	// LLM client or other dependencies can be added here if needed for more complex planning
 	LLM any
 }
 
+// Run builds the worker graph for in.Targets (every worker when empty) and
+// reports what keeps the plan from running as Diagnostics instead of failing:
+// unknown targets, Requires no worker produces, Requires outside the targets
+// under StrictRequires, and cycles.
 func (p *PlanContext) Run(ctx context.Context, in artifact.PlanDependenciesIn) (artifact.PlanDependenciesOut, error) {
 	_ = ctx
 
-	nodes := make([]*workerv1.GraphNode, 0, len(in.Workers))
-	edges := make([]*workerv1.GraphEdge, 0)
-
 	workersByKey := make(map[string]artifact.WorkerMeta, len(in.Workers))
 	for _, w := range in.Workers {
 		key := strings.TrimSpace(w.Key)
@@ -31,12 +37,64 @@ func (p *PlanContext) Run(ctx context.Context, in artifact.PlanDependenciesIn) (
 		workersByKey[key] = w
 	}
 
-	keys := make([]string, 0, len(workersByKey))
+	known := make([]string, 0, len(workersByKey))
 	for key := range workersByKey {
-		keys = append(keys, key)
+		known = append(known, key)
+	}
+	sort.Strings(known)
+
+	var diags []artifact.PlanDiagnostic
+	selected := map[string]bool{}
+	if len(in.Targets) == 0 {
+		for _, key := range known {
+			selected[key] = true
+		}
+	}
+	for _, target := range in.Targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		if _, ok := workersByKey[target]; !ok {
+			diags = append(diags, artifact.PlanDiagnostic{
+				Severity:    artifact.PlanSeverityError,
+				Code:        artifact.PlanDiagUnknownTarget,
+				Missing:     target,
+				Message:     fmt.Sprintf("no worker named %q", target),
+				Suggestions: closestKeys(target, known),
+			})
+			continue
+		}
+		selected[target] = true
+	}
+	if !in.StrictRequires {
+		// Pull in every worker the targets need, transitively.
+		queue := make([]string, 0, len(selected))
+		for key := range selected {
+			queue = append(queue, key)
+		}
+		for len(queue) > 0 {
+			key := queue[0]
+			queue = queue[1:]
+			for _, req := range workersByKey[key].Requires {
+				req = strings.TrimSpace(req)
+				if _, ok := workersByKey[req]; ok && !selected[req] {
+					selected[req] = true
+					queue = append(queue, req)
+				}
+			}
+		}
 	}
-	sort.Strings(keys)
 
+	keys := make([]string, 0, len(selected))
+	for _, key := range known {
+		if selected[key] {
+			keys = append(keys, key)
+		}
+	}
+
+	nodes := make([]*workerv1.GraphNode, 0, len(keys))
+	edges := make([]*workerv1.GraphEdge, 0)
 	for _, key := range keys {
 		w := workersByKey[key]
 		nodes = append(nodes, &workerv1.GraphNode{
@@ -46,6 +104,8 @@ func (p *PlanContext) Run(ctx context.Context, in artifact.PlanDependenciesIn) (
 		})
 	}
 
+	requires := make(map[string][]string, len(keys))
+	placeholders := map[string]bool{}
 	for _, key := range keys {
 		w := workersByKey[key]
 		if len(w.Requires) == 0 {
@@ -61,13 +121,34 @@ func (p *PlanContext) Run(ctx context.Context, in artifact.PlanDependenciesIn) (
 		}
 		sort.Strings(reqs)
 		for _, req := range reqs {
-			// Create placeholder nodes for required workers not present in input.
 			if _, ok := workersByKey[req]; !ok {
-				workersByKey[req] = artifact.WorkerMeta{Key: req}
-				nodes = append(nodes, &workerv1.GraphNode{
-					Uid:   req,
-					Label: req,
+				diags = append(diags, artifact.PlanDiagnostic{
+					Severity:    artifact.PlanSeverityError,
+					Code:        artifact.PlanDiagMissingProducer,
+					Worker:      key,
+					Missing:     req,
+					Message:     fmt.Sprintf("%s requires %s, which no worker produces", key, req),
+					Suggestions: closestKeys(req, known),
+				})
+				// Create placeholder nodes for required workers not present in input.
+				if !placeholders[req] {
+					placeholders[req] = true
+					nodes = append(nodes, &workerv1.GraphNode{
+						Uid:   req,
+						Label: req,
+					})
+				}
+			} else if !selected[req] {
+				diags = append(diags, artifact.PlanDiagnostic{
+					Severity: artifact.PlanSeverityWarning,
+					Code:     artifact.PlanDiagOutsideSelection,
+					Worker:   key,
+					Missing:  req,
+					Message:  fmt.Sprintf("%s requires %s, which is not planned; its artifact must already exist", key, req),
 				})
+				continue
+			} else {
+				requires[key] = append(requires[key], req)
 			}
 			edges = append(edges, &workerv1.GraphEdge{
 				From: req,
@@ -76,6 +157,20 @@ func (p *PlanContext) Run(ctx context.Context, in artifact.PlanDependenciesIn) (
 		}
 	}
 
+	for _, cycle := range requireCycles(keys, requires) {
+		diags = append(diags, artifact.PlanDiagnostic{
+			Severity: artifact.PlanSeverityError,
+			Code:     artifact.PlanDiagCycle,
+			Worker:   cycle[0],
+			Message:  "dependency cycle: " + strings.Join(cycle, " -> "),
+			Cycle:    cycle,
+		})
+	}
+
+	// The ClientView is all that reaches the client, so diagnostics ride
+	// along as nodes under the worker they are about.
+	nodes = append(nodes, diagnosticNodes(diags)...)
+
 	return artifact.PlanDependenciesOut{
 		RuntimeState: in,
 		ClientView: &workerv1.ClientView{
@@ -87,5 +182,117 @@ func (p *PlanContext) Run(ctx context.Context, in artifact.PlanDependenciesIn) (
 				},
 			},
 		},
+		Diagnostics: diags,
 	}, nil
 }
+
+// diagnosticNodes renders diags as graph nodes labelled "<severity>:
+// <code>", parented to their worker when they have one.
+func diagnosticNodes(diags []artifact.PlanDiagnostic) []*workerv1.GraphNode {
+	nodes := make([]*workerv1.GraphNode, 0, len(diags))
+	for i, d := range diags {
+		desc := d.Message
+		if len(d.Suggestions) > 0 {
+			desc += "; did you mean " + strings.Join(d.Suggestions, ", ") + "?"
+		}
+		nodes = append(nodes, &workerv1.GraphNode{
+			Uid:         fmt.Sprintf("%s%d", DiagnosticNodePrefix, i),
+			Label:       d.Severity + ": " + d.Code,
+			Description: desc,
+			ParentUid:   d.Worker,
+		})
+	}
+	return nodes
+}
+
+// requireCycles reports each cycle a DFS over requires finds, as the path
+// from the repeated key back to itself in data-flow order (e.g. [a b a]).
+func requireCycles(keys []string, requires map[string][]string) [][]string {
+	var (
+		cycles [][]string
+		state  = map[string]int{} // 1 = visiting, 2 = done
+		stack  []string
+		visit  func(key string)
+	)
+	visit = func(key string) {
+		state[key] = 1
+		stack = append(stack, key)
+		for _, req := range requires[key] {
+			switch state[req] {
+			case 0:
+				visit(req)
+			case 1:
+				start := len(stack) - 1
+				for stack[start] != req {
+					start--
+				}
+				cycle := []string{req}
+				for i := len(stack) - 1; i >= start; i-- {
+					cycle = append(cycle, stack[i])
+				}
+				cycles = append(cycles, cycle)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[key] = 2
+	}
+	for _, key := range keys {
+		if state[key] == 0 {
+			visit(key)
+		}
+	}
+	return cycles
+}
+
+// maxSuggestions caps the keys closestKeys returns.
+const maxSuggestions = 3
+
+// closestKeys returns up to maxSuggestions known keys within a third of
+// name's length (at least 2) in edit distance, nearest first.
+func closestKeys(name string, known []string) []string {
+	limit := len(name) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	type match struct {
+		key  string
+		dist int
+	}
+	var matches []match
+	for _, key := range known {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(key)); d <= limit {
+			matches = append(matches, match{key, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].dist < matches[j].dist })
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	out := make([]string, len(matches))
+	for i, m := range matches {
+		out[i] = m.key
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
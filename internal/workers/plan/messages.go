@@ -0,0 +1,64 @@
+package plan
+
+import "strings"
+
+// Locales with a message catalog. Other locales fall back to DefaultLocale.
+const (
+	LocaleEnglish  = "en"
+	LocaleJapanese = "ja"
+	DefaultLocale  = LocaleEnglish
+)
+
+// MessageID names a fixed assistant message of the plan workers.
+type MessageID string
+
+const (
+	// MsgBootstrapGreeting opens the bootstrap conversation before any user input.
+	MsgBootstrapGreeting MessageID = "bootstrap_greeting"
+)
+
+// messageCatalog holds the fixed assistant messages per locale; every
+// locale lists every MessageID.
+var messageCatalog = map[string]map[MessageID]string{
+	LocaleEnglish: {
+		MsgBootstrapGreeting: "Would you like to explore how computers work, or dive into real OSS code to deepen your understanding? Share a topic you're curious about or paste a GitHub repository URL.",
+	},
+	LocaleJapanese: {
+		MsgBootstrapGreeting: "コンピュータの仕組みを探ってみますか？それとも実際の OSS のコードを読んで理解を深めますか？気になるトピックを教えていただくか、GitHub リポジトリの URL を貼ってください。",
+	},
+}
+
+// responseLanguages names each catalog locale for the response_language
+// prompt field.
+var responseLanguages = map[string]string{
+	LocaleEnglish:  "English",
+	LocaleJapanese: "Japanese",
+}
+
+// NormalizeLocale returns the first catalog locale named by raw, which may be
+// a tag ("ja-JP") or an Accept-Language value ("ja-JP,ja;q=0.9,en;q=0.8"),
+// or DefaultLocale when none is supported.
+func NormalizeLocale(raw string) string {
+	for _, part := range strings.Split(raw, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		base, _, _ = strings.Cut(base, "_")
+		if _, ok := messageCatalog[base]; ok {
+			return base
+		}
+	}
+	return DefaultLocale
+}
+
+// Message returns message id in locale, falling back to DefaultLocale.
+func Message(locale string, id MessageID) string {
+	if msg, ok := messageCatalog[NormalizeLocale(locale)][id]; ok {
+		return msg
+	}
+	return messageCatalog[DefaultLocale][id]
+}
+
+// ResponseLanguage returns the language LLM replies should use for locale.
+func ResponseLanguage(locale string) string {
+	return responseLanguages[NormalizeLocale(locale)]
+}
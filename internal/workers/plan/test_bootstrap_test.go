@@ -2,7 +2,13 @@ package plan
 
 import (
 	"context"
+	"encoding/json"
+	"maps"
+	"strings"
 	"testing"
+
+	"insightify/internal/artifact"
+	llmclient "insightify/internal/llm/client"
 )
 
 func TestBootstrapRunGreeting(t *testing.T) {
@@ -17,3 +23,188 @@ func TestBootstrapRunGreeting(t *testing.T) {
 		t.Fatalf("expected greeting llm_response")
 	}
 }
+
+type recordingBootstrapLLM struct {
+	replies  []string
+	payloads []map[string]any
+	// generation holds the options of each call, scout first.
+	generation []llmclient.GenerationOptions
+	// scoutReplies are returned by the scout calls in order; an empty
+	// recommendation is returned once they run out.
+	scoutReplies  []string
+	scoutPayloads []map[string]any
+}
+
+func (f *recordingBootstrapLLM) Name() string                { return "fake" }
+func (f *recordingBootstrapLLM) Close() error                { return nil }
+func (f *recordingBootstrapLLM) CountTokens(text string) int { return len(text) }
+func (f *recordingBootstrapLLM) TokenCapacity() int          { return 4096 }
+
+func (f *recordingBootstrapLLM) GenerateJSON(ctx context.Context, _ string, input any) (json.RawMessage, error) {
+	f.generation = append(f.generation, llmclient.GenerationOptionsFrom(ctx))
+	payload, _ := input.(map[string]any)
+	f.scoutPayloads = append(f.scoutPayloads, maps.Clone(payload))
+	if len(f.scoutReplies) > 0 {
+		reply := f.scoutReplies[0]
+		f.scoutReplies = f.scoutReplies[1:]
+		return json.RawMessage(reply), nil
+	}
+	return json.RawMessage(`{"recommended_repo_url":"","explanation":""}`), nil
+}
+
+func (f *recordingBootstrapLLM) GenerateJSONStream(ctx context.Context, _ string, input any, _ func(string)) (json.RawMessage, error) {
+	f.generation = append(f.generation, llmclient.GenerationOptionsFrom(ctx))
+	payload, _ := input.(map[string]any)
+	f.payloads = append(f.payloads, payload)
+	reply := f.replies[0]
+	f.replies = f.replies[1:]
+	return json.RawMessage(reply), nil
+}
+
+func TestBootstrapCarriesConversationAcrossTurns(t *testing.T) {
+	llm := &recordingBootstrapLLM{replies: []string{
+		`{"purpose":"","repo_url":"","followup_question":"Which Raft implementation?","need_more_input":true}`,
+		`{"purpose":"Learn how Raft is implemented","repo_url":"https://github.com/etcd-io/raft","followup_question":"Shall we start?","need_more_input":false}`,
+	}}
+	p := &BootstrapPipeline{LLM: llm}
+
+	first, err := p.Run(context.Background(), BootstrapIn{UserInput: "I want to learn how Raft is implemented"})
+	if err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	second, err := p.Run(context.Background(), BootstrapIn{
+		UserInput:    "etcd's one",
+		Conversation: first.BootstrapContext.Conversation,
+	})
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+
+	sent, _ := llm.payloads[1]["conversation"].([]artifact.ConversationTurn)
+	if len(sent) != 3 || sent[0].Content != "I want to learn how Raft is implemented" || sent[2].Content != "etcd's one" {
+		t.Fatalf("conversation sent to llm = %+v", sent)
+	}
+	got := second.BootstrapContext.Conversation
+	if len(got) != 4 || got[3].Role != "assistant" || got[3].Content != "Shall we start?" {
+		t.Fatalf("persisted conversation = %+v", got)
+	}
+}
+
+func TestBootstrapDropsConversationOfAnotherSession(t *testing.T) {
+	llm := &recordingBootstrapLLM{replies: []string{
+		`{"purpose":"","repo_url":"","followup_question":"Which project?","need_more_input":true}`,
+	}}
+	p := &BootstrapPipeline{LLM: llm}
+	out, err := p.Run(context.Background(), BootstrapIn{
+		UserInput:           "explain the scheduler",
+		Conversation:        []artifact.ConversationTurn{{Role: "user", Content: "I want to learn Raft"}},
+		ConversationSession: "chat-a",
+		Session:             "chat-b",
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	sent, _ := llm.payloads[0]["conversation"].([]artifact.ConversationTurn)
+	if len(sent) != 1 || sent[0].Content != "explain the scheduler" {
+		t.Fatalf("conversation sent to llm = %+v, want only this session's turn", sent)
+	}
+	if ctx := out.BootstrapContext; ctx.Session != "chat-b" || len(ctx.Conversation) != 2 {
+		t.Fatalf("persisted context = %+v", ctx)
+	}
+}
+
+func TestBootstrapTrimConversationDropsOldestTurns(t *testing.T) {
+	p := &BootstrapPipeline{LLM: &recordingBootstrapLLM{}, ConversationTokens: 30}
+	turns := []artifact.ConversationTurn{
+		{Role: "user", Content: "oldest message here"},
+		{Role: "assistant", Content: "middle"},
+		{Role: "user", Content: "newest"},
+	}
+	got := p.trimConversation(turns)
+	if len(got) != 2 || got[0].Content != "middle" || got[1].Content != "newest" {
+		t.Fatalf("trimConversation() = %+v", got)
+	}
+}
+
+func TestBootstrapLocalizesGreetingAndReplies(t *testing.T) {
+	cases := []struct {
+		locale   string
+		greeting string
+		language string
+	}{
+		{"", messageCatalog[LocaleEnglish][MsgBootstrapGreeting], "English"},
+		{"ja-JP", messageCatalog[LocaleJapanese][MsgBootstrapGreeting], "Japanese"},
+		{"fr-FR,ja;q=0.8", messageCatalog[LocaleJapanese][MsgBootstrapGreeting], "Japanese"},
+		{"fr", messageCatalog[LocaleEnglish][MsgBootstrapGreeting], "English"},
+	}
+	for _, tc := range cases {
+		out, err := (&BootstrapPipeline{}).Run(context.Background(), BootstrapIn{Locale: tc.locale})
+		if err != nil {
+			t.Fatalf("Run(%q) error = %v", tc.locale, err)
+		}
+		if got := out.ClientView.GetLlmResponse(); got != tc.greeting {
+			t.Fatalf("greeting for %q = %q, want %q", tc.locale, got, tc.greeting)
+		}
+
+		llm := &recordingBootstrapLLM{replies: []string{
+			`{"purpose":"","repo_url":"","followup_question":"?","need_more_input":true}`,
+		}}
+		if _, err := (&BootstrapPipeline{LLM: llm}).Run(context.Background(), BootstrapIn{UserInput: "raft", Locale: tc.locale}); err != nil {
+			t.Fatalf("Run(%q) with input error = %v", tc.locale, err)
+		}
+		if got := llm.payloads[0]["response_language"]; got != tc.language {
+			t.Fatalf("response_language for %q = %v, want %s", tc.locale, got, tc.language)
+		}
+	}
+}
+
+func TestBootstrapScoutSamplesWithTemperature(t *testing.T) {
+	reply := `{"purpose":"","repo_url":"","followup_question":"Which one?","need_more_input":true}`
+	llm := &recordingBootstrapLLM{replies: []string{reply, reply}}
+	p := &BootstrapPipeline{LLM: llm}
+	if _, err := p.Run(context.Background(), BootstrapIn{UserInput: "raft"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(llm.generation) != 2 {
+		t.Fatalf("calls = %d, want scout and bootstrap", len(llm.generation))
+	}
+	if got := llm.generation[0].Temperature; got == nil || *got != scoutTemperature {
+		t.Fatalf("scout temperature = %v, want %v", got, scoutTemperature)
+	}
+	if got := llm.generation[1].Temperature; got != nil {
+		t.Fatalf("bootstrap temperature = %v, want unset (client default 0)", *got)
+	}
+
+	// A phase-level temperature is kept for the scout too.
+	llm.generation = nil
+	ctx := llmclient.WithGenerationOptions(context.Background(), llmclient.GenerationOptions{Temperature: llmclient.Float32(0)})
+	if _, err := p.Run(ctx, BootstrapIn{UserInput: "raft"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := llm.generation[0].Temperature; got == nil || *got != 0 {
+		t.Fatalf("scout temperature = %v, want the phase's 0", got)
+	}
+}
+
+func TestBootstrapScoutRetriesSchemaViolations(t *testing.T) {
+	llm := &recordingBootstrapLLM{
+		scoutReplies: []string{
+			`{"recommended_repo_url":["https://github.com/etcd-io/raft"],"explanation":"etcd"}`,
+			`{"recommended_repo_url":"https://github.com/etcd-io/raft","explanation":"etcd"}`,
+		},
+		replies: []string{`{"purpose":"","repo_url":"","followup_question":"Shall we start?","need_more_input":true}`},
+	}
+	p := &BootstrapPipeline{LLM: llm}
+	if _, err := p.Run(context.Background(), BootstrapIn{UserInput: "raft"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(llm.scoutPayloads) != 2 {
+		t.Fatalf("scout calls = %d, want a retry after the schema violation", len(llm.scoutPayloads))
+	}
+	if hint, _ := llm.scoutPayloads[1]["regen_hint"].(string); !strings.Contains(hint, "/recommended_repo_url") {
+		t.Fatalf("retry hint = %q, want it to name /recommended_repo_url", hint)
+	}
+	if got := llm.payloads[0]["detected_repo_url"]; got != "https://github.com/etcd-io/raft" {
+		t.Fatalf("detected_repo_url = %v, want the corrected recommendation", got)
+	}
+}
@@ -0,0 +1,136 @@
+package plan
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"insightify/internal/artifact"
+)
+
+func TestPlanContextRunReportsDiagnostics(t *testing.T) {
+	workers := []artifact.WorkerMeta{
+		{Key: "code_roots"},
+		{Key: "code_symbols", Requires: []string{"code_roots"}},
+		{Key: "arch_design", Requires: []string{"code_symbols"}},
+		{Key: "infra_context", Requires: []string{"code_rots"}},
+		{Key: "loop_a", Requires: []string{"loop_b"}},
+		{Key: "loop_b", Requires: []string{"loop_a"}},
+	}
+	cases := []struct {
+		name   string
+		in     artifact.PlanDependenciesIn
+		nodes  []string
+		diag   artifact.PlanDiagnostic
+		noDiag bool
+	}{
+		{
+			name:   "targets pull in requires transitively",
+			in:     artifact.PlanDependenciesIn{Targets: []string{"arch_design"}},
+			nodes:  []string{"arch_design", "code_roots", "code_symbols"},
+			noDiag: true,
+		},
+		{
+			name:  "unknown target suggests close names",
+			in:    artifact.PlanDependenciesIn{Targets: []string{"code_symbol"}},
+			nodes: []string{},
+			diag: artifact.PlanDiagnostic{
+				Severity:    artifact.PlanSeverityError,
+				Code:        artifact.PlanDiagUnknownTarget,
+				Missing:     "code_symbol",
+				Message:     `no worker named "code_symbol"`,
+				Suggestions: []string{"code_symbols"},
+			},
+		},
+		{
+			name:  "missing producer keeps a placeholder",
+			in:    artifact.PlanDependenciesIn{Targets: []string{"infra_context"}},
+			nodes: []string{"code_rots", "infra_context"},
+			diag: artifact.PlanDiagnostic{
+				Severity:    artifact.PlanSeverityError,
+				Code:        artifact.PlanDiagMissingProducer,
+				Worker:      "infra_context",
+				Missing:     "code_rots",
+				Message:     "infra_context requires code_rots, which no worker produces",
+				Suggestions: []string{"code_roots"},
+			},
+		},
+		{
+			name:  "strict requires are reported",
+			in:    artifact.PlanDependenciesIn{Targets: []string{"arch_design"}, StrictRequires: true},
+			nodes: []string{"arch_design"},
+			diag: artifact.PlanDiagnostic{
+				Severity: artifact.PlanSeverityWarning,
+				Code:     artifact.PlanDiagOutsideSelection,
+				Worker:   "arch_design",
+				Missing:  "code_symbols",
+				Message:  "arch_design requires code_symbols, which is not planned; its artifact must already exist",
+			},
+		},
+		{
+			name:  "cycles name their members",
+			in:    artifact.PlanDependenciesIn{Targets: []string{"loop_a"}},
+			nodes: []string{"loop_a", "loop_b"},
+			diag: artifact.PlanDiagnostic{
+				Severity: artifact.PlanSeverityError,
+				Code:     artifact.PlanDiagCycle,
+				Worker:   "loop_a",
+				Message:  "dependency cycle: loop_a -> loop_b -> loop_a",
+				Cycle:    []string{"loop_a", "loop_b", "loop_a"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		tc.in.Workers = workers
+		out, err := (&PlanContext{}).Run(context.Background(), tc.in)
+		if err != nil {
+			t.Fatalf("%s: Run() error = %v", tc.name, err)
+		}
+		nodes := []string{}
+		var diagNodes []string
+		for _, n := range out.ClientView.GetGraph().GetNodes() {
+			if strings.HasPrefix(n.GetUid(), DiagnosticNodePrefix) {
+				diagNodes = append(diagNodes, n.GetParentUid()+"|"+n.GetLabel()+"|"+n.GetDescription())
+				continue
+			}
+			nodes = append(nodes, n.GetUid())
+		}
+		sort.Strings(nodes)
+		if !reflect.DeepEqual(nodes, tc.nodes) {
+			t.Fatalf("%s: nodes = %v, want %v", tc.name, nodes, tc.nodes)
+		}
+		if tc.noDiag {
+			if len(out.Diagnostics) != 0 || len(diagNodes) != 0 {
+				t.Fatalf("%s: diagnostics = %+v (nodes %q), want none", tc.name, out.Diagnostics, diagNodes)
+			}
+			continue
+		}
+		if len(out.Diagnostics) != 1 || !reflect.DeepEqual(out.Diagnostics[0], tc.diag) {
+			t.Fatalf("%s: diagnostics = %+v, want [%+v]", tc.name, out.Diagnostics, tc.diag)
+		}
+		// The client only gets the ClientView, which must carry it too.
+		want := tc.diag.Worker + "|" + tc.diag.Severity + ": " + tc.diag.Code + "|" + tc.diag.Message
+		if len(diagNodes) != 1 || !strings.HasPrefix(diagNodes[0], want) {
+			t.Fatalf("%s: diagnostic nodes = %q, want one starting with %q", tc.name, diagNodes, want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"code_roots", "code_rots", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tc := range cases {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Fatalf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
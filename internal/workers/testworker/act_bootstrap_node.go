@@ -21,7 +21,6 @@ const (
 	testChatWorkerKey    = "actBootstrapNode"
 	testChatModelName    = "Low"
 	defaultChatMaxTurns  = 8
-	defaultIdleTimeout   = 30 * time.Second
 	defaultOpeningPrompt = "Hi! How has your day been so far?"
 )
 
@@ -56,6 +55,9 @@ type LLMChatNodePipeline struct {
 	LLM         llmclient.LLMClient
 	Interaction Interaction
 	MaxTurns    int
+	// IdleTimeout ends the chat when the user stays silent that long. Zero
+	// leaves the bound to Interaction, which the runner limits by the run's
+	// input wait timeout.
 	IdleTimeout time.Duration
 }
 
@@ -73,17 +75,15 @@ func (p *LLMChatNodePipeline) Run(ctx context.Context, in plan.BootstrapIn) erro
 		maxTurns = defaultChatMaxTurns
 	}
 
-	idleTimeout := p.IdleTimeout
-	if idleTimeout <= 0 {
-		idleTimeout = defaultIdleTimeout
-	}
-
 	userInput := strings.TrimSpace(in.UserInput)
 	waitNextInput := func() (string, error) {
 		if p.Interaction == nil {
 			return "", nil
 		}
-		waitCtx, cancel := context.WithTimeout(ctx, idleTimeout)
+		waitCtx, cancel := ctx, context.CancelFunc(func() {})
+		if p.IdleTimeout > 0 {
+			waitCtx, cancel = context.WithTimeout(ctx, p.IdleTimeout)
+		}
 		nextInput, waitErr := p.Interaction.WaitForInput(waitCtx)
 		cancel()
 		if waitErr != nil {
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	workerv1 "insightify/gen/go/worker/v1"
+
+	"google.golang.org/protobuf/proto"
 )
 
 // StreamStep represents a step in the test streaming pipeline.
@@ -108,8 +110,7 @@ func cloneClientView(view *workerv1.ClientView) *workerv1.ClientView {
 				clonedGraph.Nodes = append(clonedGraph.Nodes, nil)
 				continue
 			}
-			cp := *n
-			clonedGraph.Nodes = append(clonedGraph.Nodes, &cp)
+			clonedGraph.Nodes = append(clonedGraph.Nodes, proto.Clone(n).(*workerv1.GraphNode))
 		}
 	}
 	if len(view.GetGraph().Edges) > 0 {
@@ -119,8 +120,7 @@ func cloneClientView(view *workerv1.ClientView) *workerv1.ClientView {
 				clonedGraph.Edges = append(clonedGraph.Edges, nil)
 				continue
 			}
-			cp := *e
-			clonedGraph.Edges = append(clonedGraph.Edges, &cp)
+			clonedGraph.Edges = append(clonedGraph.Edges, proto.Clone(e).(*workerv1.GraphEdge))
 		}
 	}
 	return cloned